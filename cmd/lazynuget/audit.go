@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/audit"
+	"github.com/willibrandon/lazynuget/internal/cache"
+	"github.com/willibrandon/lazynuget/internal/ci"
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/hooks"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/project"
+	"github.com/willibrandon/lazynuget/internal/resolver"
+)
+
+// maxReferences bounds the references printed per advisory; --json has all of them.
+const maxReferences = 3
+
+// auditResult is the JSON document of `lazynuget audit --json`.
+type auditResult struct {
+	*audit.Report
+	Fixes      []audit.Suggestion `json:"fixes"`
+	Violations []audit.Violation  `json:"violations,omitempty"` // With --policy
+}
+
+// runAudit implements `lazynuget audit [--fix] [--source URL] [--json] [--policy FILE]
+// [--sarif FILE] [--report-format FORMAT] [--report-out FILE] [PROJECT]`.
+func runAudit(_ *cli.Command, values *cli.Values) int {
+	target := ""
+	if args := values.Args(); len(args) > 0 {
+		target = args[0]
+	}
+	policyPath, sarifPath := values.String("policy"), values.String("sarif")
+	if sarifPath != "" && policyPath == "" {
+		fmt.Fprintf(os.Stderr, "Error: --sarif requires --policy\n")
+		return exitcode.UserError
+	}
+	ciReport, exitCode := reportOptions(values)
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+	jsonOutput := values.Bool("json")
+	if jsonOutput && ciReport.toStdout() {
+		fmt.Fprintf(os.Stderr, "Error: --json and a report on stdout cannot be combined; use --report-out FILE\n")
+		return exitcode.UserError
+	}
+	var policy *audit.Policy
+	if policyPath != "" {
+		var err error
+		if policy, err = audit.LoadPolicy(policyPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+	}
+
+	interrupt, stop := interruptContext()
+	defer stop()
+	ctx, cancel := context.WithTimeout(interrupt, 2*time.Minute)
+	defer cancel()
+	feed, err := sourceFeed(ctx, values.String("source"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	feed.OSV = osvClient()
+
+	spawner := platform.NewProcessSpawner()
+	var report *audit.Report
+	if platform.DotnetAvailable() {
+		report, err = audit.Run(interrupt, spawner, target)
+	} else {
+		warnf("the dotnet CLI was not found; auditing the packages recorded by the last restore, or direct references, against the feed's advisories\n")
+		report, err = scanAudit(ctx, feed, target)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	for _, p := range report.Problems {
+		warnf("%s\n", p.Text)
+	}
+	// Look-alike IDs are worth knowing about, but not worth failing the audit over
+	if identity, err := audit.CheckIdentities(ctx, feed, audit.References(report.Projects)); err != nil {
+		warnf("%v\n", err)
+	} else {
+		report.Identity = identity
+	}
+
+	suggestions, err := audit.Suggest(ctx, feed, report)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	if suggestions == nil {
+		suggestions = []audit.Suggestion{}
+	}
+	textOutput := !jsonOutput && !ciReport.toStdout()
+	if textOutput {
+		printAudit(report, suggestions)
+	}
+
+	// Without a policy, any vulnerability fails the audit
+	current := report
+	if len(report.Vulnerabilities) > 0 {
+		current, exitCode = fixAudit(interrupt, spawner, target, report, suggestions, values.Bool("fix"))
+		if current == nil {
+			return exitCode
+		}
+		current.Identity = report.Identity
+	}
+
+	annotations := ci.InGitHubActions(os.Getenv)
+	var packages []audit.Package
+	if policy != nil || ciReport != nil || annotations {
+		packages = audit.Inventory(current.Projects)
+	}
+	var violations []audit.Violation
+	if policy != nil {
+		// Packages that are not in the global packages folder are downloaded to read their licenses
+		policyCtx, cancel := context.WithTimeout(interrupt, 5*time.Minute)
+		defer cancel()
+		violations, err = policy.Evaluate(policyCtx, feed, nuget.GlobalPackagesDir(), current, packages, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+		if textOutput {
+			printViolations(violations)
+		}
+		if sarifPath != "" {
+			if code := writeSARIF(sarifPath, violations); code != exitcode.Success {
+				return code
+			}
+		}
+		exitCode = exitcode.Success
+		if len(violations) > 0 {
+			exitCode = exitcode.PolicyViolation
+		}
+	}
+
+	checks := audit.Checks(current, packages, violations)
+	if ciReport != nil {
+		if code := ciReport.write(audit.Kind, audit.Rules, checks); code != exitcode.Success {
+			return code
+		}
+	}
+	if annotations {
+		annotate(audit.Kind, audit.Rules, checks, !textOutput)
+	}
+	if jsonOutput {
+		if code := writeJSON(audit.Kind, auditResult{Report: report, Fixes: suggestions, Violations: violations}); code != exitcode.Success {
+			return code
+		}
+	}
+	return exitCode
+}
+
+// fixAudit applies the fixes of an audit that found vulnerabilities, when asked to, and
+// returns the report that is current afterwards with the exit code the vulnerabilities
+// call for. The report is nil when the fixes could not be applied.
+func fixAudit(ctx context.Context, spawner platform.ProcessSpawner, target string, report *audit.Report, suggestions []audit.Suggestion, apply bool) (*audit.Report, int) {
+	var fixes []resolver.Fix
+	for _, s := range suggestions {
+		if s.Fixable() {
+			fixes = append(fixes, s.Fix())
+		}
+	}
+	if !apply {
+		if len(fixes) > 0 {
+			infof("Run `lazynuget audit --fix` to apply %d fix(es).\n", len(fixes))
+		}
+		return report, exitcode.PolicyViolation
+	}
+	if len(fixes) == 0 {
+		infof("No fix can be applied automatically.\n")
+		return report, exitcode.PolicyViolation
+	}
+	if !platform.DotnetAvailable() {
+		return nil, dotnetMissing("lazynuget audit --fix", "restores the fixed projects with dotnet restore")
+	}
+
+	runner := hookRunner()
+	if exitCode := applyFixes(ctx, runner, fixes); exitCode != exitcode.Success {
+		return nil, exitCode
+	}
+	// Audit reads the graph restore writes, so restore before checking again
+	restoreArgs := []string{"restore"}
+	if target != "" {
+		restoreArgs = append(restoreArgs, target)
+	}
+	if result, err := spawner.RunContext(ctx, "dotnet", restoreArgs, "", nil); err != nil || result.ExitCode != 0 {
+		fmt.Fprintf(os.Stderr, "Fixes applied, but dotnet restore failed; run it to see why.\n")
+		return nil, exitcode.SystemError
+	}
+	remaining, err := audit.Run(ctx, spawner, target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return nil, exitcode.SystemError
+	}
+	_ = runHooks(runner, hooks.Operation{Event: hooks.EventPostRestore})
+	if len(remaining.Vulnerabilities) > 0 {
+		fmt.Fprintf(os.Stderr, "Vulnerable packages remain after applying fixes; run `lazynuget audit` again for details.\n")
+		return remaining, exitcode.PolicyViolation
+	}
+	fmt.Fprintf(os.Stderr, "No vulnerable packages remain.\n")
+	return remaining, exitcode.Success
+}
+
+// scanAudit audits target without the dotnet CLI: the project itself, or the projects
+// under a directory or beside a solution.
+func scanAudit(ctx context.Context, feed *nuget.Feed, target string) (*audit.Report, error) {
+	if target == "" {
+		target = "."
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+	var projects []string
+	switch {
+	case info.IsDir():
+		projects, err = project.Discover(target)
+	case project.IsProjectFile(target):
+		projects = []string{target}
+	default:
+		projects, err = project.Discover(filepath.Dir(target))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return audit.Scan(ctx, feed, projects)
+}
+
+// printViolations lists the policy violations by project.
+func printViolations(violations []audit.Violation) {
+	if len(violations) == 0 {
+		fmt.Println("No policy violations")
+		return
+	}
+	fmt.Printf("%d policy violation(s)\n", len(violations))
+	for _, v := range violations {
+		fmt.Printf("%s  %-14s  %s\n", displayPath(v.Project), v.Rule, v.Message)
+	}
+}
+
+// writeSARIF writes policy violations to a SARIF file, with locations relative to the
+// workspace root (the repository code scanning knows).
+func writeSARIF(path string, violations []audit.Violation) int {
+	root, exitCode := workspaceRoot()
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+	data, err := audit.SARIF(violations, root, version)
+	if err == nil {
+		err = os.WriteFile(path, append(data, '\n'), 0o644) // #nosec G306 -- uploaded as a CI artifact
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write SARIF: %v\n", err)
+		return exitcode.SystemError
+	}
+	return exitcode.Success
+}
+
+// printAudit lists the vulnerable packages by project, each with its suggested fix, and
+// the packages that may be typosquats.
+func printAudit(report *audit.Report, suggestions []audit.Suggestion) {
+	defer printIdentity(report.Identity)
+	if len(report.Vulnerabilities) == 0 {
+		fmt.Println("No vulnerable packages")
+		return
+	}
+	for _, s := range suggestions {
+		kind := "direct"
+		if s.Transitive {
+			kind = "transitive"
+		}
+		fmt.Printf("%s  %s %s (%s)\n", displayPath(s.Project), s.Package, s.ResolvedVersion, kind)
+		for _, a := range s.Details {
+			title := a.Identifier()
+			if len(a.Aliases) > 0 {
+				title += " (" + strings.Join(a.Aliases, ", ") + ")"
+			}
+			if a.Summary != "" {
+				title += ": " + a.Summary
+			}
+			fmt.Printf("  %s  %s\n    %s\n", a.Rating(), title, a.URL)
+			shown := 0
+			for _, ref := range a.References {
+				if ref != a.URL && shown < maxReferences {
+					fmt.Printf("    see: %s\n", ref)
+					shown++
+				}
+			}
+		}
+		if len(s.Details) == 0 {
+			// Without advisory data, show what dotnet reported
+			for _, v := range report.Vulnerabilities {
+				if v.Project == s.Project && strings.EqualFold(v.Package, s.Package) {
+					fmt.Printf("  %-8s  %s  %s\n", v.Severity, v.Framework, v.AdvisoryURL)
+				}
+			}
+		}
+		fmt.Printf("  fix: %s\n", s.Description)
+	}
+}
+
+// printIdentity lists the packages whose IDs may not be the packages meant.
+func printIdentity(warnings []audit.IdentityWarning) {
+	if len(warnings) == 0 {
+		return
+	}
+	fmt.Printf("%d package(s) to check\n", len(warnings))
+	for _, w := range warnings {
+		fmt.Printf("%s  %s\n", displayPath(w.Project), w.Message)
+	}
+}
+
+// osvClient returns an OSV.dev client whose results are cached for the configured
+// advisoryCacheTTL, within the configured cacheSize.
+func osvClient() *nuget.OSV {
+	osv := nuget.NewOSV()
+	cacheSize := config.GetDefaultConfig().CacheSize
+	cfg, err := loadUserConfig()
+	if err == nil {
+		osv.TTL, cacheSize = cfg.AdvisoryCacheTTL, cfg.CacheSize
+	}
+	if dir := cache.DefaultDir("osv"); dir != "" {
+		osv.Cache = cache.New(dir, int64(cacheSize)<<20)
+	}
+	return osv
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/batch"
+	"github.com/willibrandon/lazynuget/internal/cache"
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/editor"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// runBatch implements `lazynuget batch --repos FILE --cmd COMMAND`.
+func runBatch(_ *cli.Command, values *cli.Values) int {
+	list, command := values.String("repos"), values.String("cmd")
+	if list == "" || command == "" {
+		fmt.Fprintln(os.Stderr, "Error: expected --repos and --cmd")
+		return exitcode.UserError
+	}
+	jobs, err := strconv.Atoi(values.String("jobs"))
+	if err != nil || jobs < 1 || jobs > 64 {
+		fmt.Fprintln(os.Stderr, "Error: --jobs must be a number between 1 and 64")
+		return exitcode.UserError
+	}
+
+	// Check the command the way runCommand would, before touching any repository
+	args, err := editor.Split(command)
+	if err == nil && len(args) > 0 && args[0] == cli.Program {
+		args = args[1:]
+	}
+	if err != nil || len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: invalid --cmd %q\n", command)
+		return exitcode.UserError
+	}
+	target, rest := cli.Root().Find(args)
+	if target.Parent() == nil || len(target.Subcommands) > 0 || target.Key() == "batch" {
+		fmt.Fprintf(os.Stderr, "Error: --cmd must be a lazynuget command other than batch, got %q\n", command)
+		return exitcode.UserError
+	}
+	if _, err := target.Parse(rest); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --cmd %q: %v\n", command, err)
+		return exitcode.UserError
+	}
+
+	repos, exitCode := readRepos(list)
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	workDir := values.String("workdir")
+	if workDir == "" {
+		if workDir = cache.DefaultDir("repos"); workDir == "" {
+			fmt.Fprintln(os.Stderr, "Error: no cache directory to clone into; pass --workdir")
+			return exitcode.SystemError
+		}
+	}
+
+	done := 0
+	runner := &batch.Runner{
+		Spawner:    platform.NewProcessSpawner(),
+		Executable: executable,
+		Args:       args,
+		WorkDir:    workDir,
+		Jobs:       jobs,
+		Notify: func(r batch.Result) {
+			done++
+			infof("[%d/%d] %s: %s\n", done, len(repos), r.Name, batchStatus(r))
+		},
+	}
+	if slices.ContainsFunc(repos, batch.Repo.Remote) {
+		if err := platform.CheckFreeSpace(workDir, 0); err != nil {
+			warnf("%v; clones may fail. Free up space, or clone elsewhere with --workdir\n", err)
+		}
+	}
+	results := runner.Run(repos)
+
+	// When the command failed only in some repositories, the others still got their results
+	exitCode = exitcode.Success
+	failed := 0
+	for _, r := range results {
+		switch r.Status {
+		case batch.StatusError:
+			exitCode = exitcode.SystemError
+			failed++
+		case batch.StatusFailed:
+			if exitCode == exitcode.Success {
+				exitCode = exitcode.UserError
+			}
+			failed++
+		}
+	}
+	if failed > 0 && failed < len(results) {
+		exitCode = exitcode.PartialFailure
+	}
+	if values.Bool("json") {
+		if code := writeJSON(batch.Kind, results); code != exitcode.Success {
+			return code
+		}
+		return exitCode
+	}
+
+	for _, r := range results {
+		fmt.Printf("== %s (%s)\n", r.Name, batchStatus(r))
+		if output := strings.TrimRight(r.Output, "\n"); output != "" {
+			fmt.Println(output)
+		}
+		fmt.Println()
+	}
+	rows := [][]string{{"Repository", "Status", "Time"}}
+	for _, r := range results {
+		rows = append(rows, []string{r.Name, batchStatus(r), (time.Duration(r.DurationMS) * time.Millisecond).Round(100 * time.Millisecond).String()})
+	}
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+	for _, row := range rows {
+		var sb strings.Builder
+		for i, cell := range row {
+			fmt.Fprintf(&sb, "  %-*s", widths[i], cell)
+		}
+		fmt.Println(strings.TrimRight(sb.String(), " "))
+	}
+	return exitCode
+}
+
+// readRepos reads the repository list of --repos, from stdin for "-".
+func readRepos(list string) ([]batch.Repo, int) {
+	var in io.Reader = os.Stdin
+	dir, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return nil, exitcode.SystemError
+	}
+	if list != "-" {
+		f, err := os.Open(list) // #nosec G304 -- the user's repository list
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return nil, exitcode.SystemError
+		}
+		defer f.Close()
+		in = f
+		if abs, err := filepath.Abs(list); err == nil {
+			dir = filepath.Dir(abs)
+		}
+	}
+	repos, err := batch.ParseRepos(in, dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s: %v\n", list, err)
+		return nil, exitcode.SystemError
+	}
+	if len(repos) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: %s lists no repositories\n", list)
+		return nil, exitcode.UserError
+	}
+	return repos, exitcode.Success
+}
+
+// batchStatus describes the outcome of a repository in a few words.
+func batchStatus(r batch.Result) string {
+	switch r.Status {
+	case batch.StatusOK:
+		return "ok"
+	case batch.StatusFailed:
+		return fmt.Sprintf("failed (exit %d)", r.ExitCode)
+	default:
+		return "error: " + r.Error
+	}
+}
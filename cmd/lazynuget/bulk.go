@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/audit"
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/confirm"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/impact"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/operation"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/project"
+	"github.com/willibrandon/lazynuget/internal/resolver"
+)
+
+// runAdd implements `lazynuget add [--project PATH] [SPEC...]`.
+func runAdd(_ *cli.Command, values *cli.Values) int {
+	return runBulk(operation.ActionAdd, values)
+}
+
+// runRemove implements `lazynuget remove [--project PATH] [SPEC...]`.
+func runRemove(_ *cli.Command, values *cli.Values) int {
+	return runBulk(operation.ActionRemove, values)
+}
+
+// runUpdate implements `lazynuget update [--project PATH] [SPEC...]`.
+func runUpdate(_ *cli.Command, values *cli.Values) int {
+	return runBulk(operation.ActionUpdate, values)
+}
+
+// runBulk applies action to the package specs given as arguments or, without any (or with
+// -), read from stdin one per line.
+func runBulk(action operation.Action, values *cli.Values) int {
+	specs, exitCode := bulkSpecs(action, values.Args())
+	if exitCode != exitcode.Success || len(specs) == 0 {
+		return exitCode
+	}
+	path, exitCode := bulkProject(values.String("project"))
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+	framework := values.String("framework")
+	if framework != "" {
+		p, err := project.Load(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+		if !p.HasFramework(framework) {
+			fmt.Fprintf(os.Stderr, "Error: %s does not target %s (its target frameworks: %s)\n", displayPath(path), framework, strings.Join(p.TargetFrameworks, ", "))
+			return exitcode.UserError
+		}
+	}
+
+	cfg, err := loadUserConfig()
+	if err != nil {
+		cfg = config.GetDefaultConfig()
+	}
+	confirmer := confirm.New(cfg, values.Bool("yes"), platform.IsStdinTerminal(), os.Stdin, os.Stderr)
+	request := bulkRequest(action, path, framework, specs)
+	if action != operation.ActionRemove && confirmer.Asks(request) {
+		request.Details = []string{sizeImpact(values.String("source"), path, framework, specs, values.Bool("prerelease"))}
+	}
+	if err := confirmer.Confirm(request); err != nil {
+		if errors.Is(err, confirm.ErrDeclined) {
+			infof("Nothing changed\n")
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		return exitcode.UserError
+	}
+	session, err := operation.NewSession(cfg.OperationBackend, cfg.RestoreMode, platform.NewProcessSpawner())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.UserError
+	}
+
+	ctx, cancel := interruptContext()
+	defer cancel()
+	source := values.String("source")
+	openFeed := sync.OnceValues(func() (*nuget.Feed, error) {
+		return sourceFeed(ctx, source)
+	})
+	jsonOutput := values.Bool("json")
+	bulk := &operation.Bulk{
+		Backend: session,
+		Versions: func(ctx context.Context, id string) ([]string, error) {
+			feed, err := openFeed()
+			if err != nil {
+				return nil, err
+			}
+			return feed.Versions(ctx, id)
+		},
+		Hooks:      hookRunner(),
+		Prerelease: values.Bool("prerelease"),
+		Reason:     values.String("reason"),
+		Framework:  framework,
+		Notify: func(r operation.Result) {
+			if !jsonOutput {
+				printBulkResult(action, r)
+			}
+		},
+	}
+	results := bulk.Run(ctx, action, path, specs)
+
+	if err := session.Close(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	if pending := session.Pending(); len(pending) > 0 {
+		infof("Run dotnet restore to restore %s (restoreMode: %s).\n", displayPath(path), session.Mode())
+	}
+
+	if jsonOutput {
+		if code := writeJSON(operation.Kind, results); code != exitcode.Success {
+			return code
+		}
+	}
+	return bulkExitCode(results)
+}
+
+// bulkRequest describes a bulk operation for confirmation.
+func bulkRequest(action operation.Action, path, framework string, specs []operation.Spec) confirm.Request {
+	verb := strings.ToUpper(string(action[:1])) + string(action[1:])
+	preposition := map[operation.Action]string{operation.ActionAdd: "to", operation.ActionRemove: "from", operation.ActionUpdate: "in"}[action]
+	what := specs[0].String()
+	if len(specs) > 1 {
+		what = fmt.Sprintf("%d packages", len(specs))
+	}
+	summary := fmt.Sprintf("%s %s %s %s", verb, what, preposition, displayPath(path))
+	if framework != "" {
+		summary += " for " + framework
+	}
+	return confirm.Request{
+		Operation: string(action),
+		Count:     len(specs),
+		Summary:   summary,
+	}
+}
+
+// sizeImpactTimeout bounds the size estimate shown before asking to add or update.
+const sizeImpactTimeout = 20 * time.Second
+
+// sizeImpact describes what adding or updating packages in a project downloads: the
+// packages and their new dependencies, which the project does not resolve already, for
+// one of its target frameworks or all of them.
+func sizeImpact(source, path, framework string, specs []operation.Spec, prerelease bool) string {
+	ctx, cancel := context.WithTimeout(context.Background(), sizeImpactTimeout)
+	defer cancel()
+	feed, err := sourceFeed(ctx, source)
+	if err != nil {
+		return fmt.Sprintf("Download size unknown: %v", err)
+	}
+
+	requests := make([]impact.Request, 0, len(specs))
+	for _, spec := range specs {
+		version := spec.Version
+		if version == "" {
+			versions, err := feed.Versions(ctx, spec.ID)
+			if err != nil {
+				return fmt.Sprintf("Download size unknown: %v", err)
+			}
+			if version = nuget.Latest(versions, prerelease); version == "" {
+				continue // Reported when the package is added
+			}
+		}
+		requests = append(requests, impact.Request{ID: spec.ID, Version: version})
+	}
+
+	var frameworks []string
+	resolved := make(map[string]string)
+	for _, p := range audit.Inventory([]string{path}) {
+		resolved[strings.ToLower(p.ID)] = p.Version
+	}
+	if p, err := project.Load(path); err == nil {
+		frameworks = p.TargetFrameworks
+		if framework != "" {
+			frameworks = []string{framework}
+		}
+		if len(resolved) == 0 {
+			for _, ref := range p.PackageReferences {
+				resolved[strings.ToLower(ref.ID)] = resolver.MinVersion(ref.Version)
+			}
+		}
+	}
+
+	estimate, err := impact.Estimate(ctx, feed, nuget.GlobalPackagesDir(), frameworks, resolved, requests)
+	if err != nil {
+		return fmt.Sprintf("Download size unknown: %v", err)
+	}
+	fresh := estimate.New()
+	if fresh == 0 {
+		return "Nothing to download: the packages are in the global packages folder"
+	}
+	packages := "packages"
+	if fresh == 1 {
+		packages = "package"
+	}
+	line := fmt.Sprintf("Downloads %s for %d %s, about %s on disk", formatSize(estimate.Download), fresh, packages, formatSize(estimate.Disk))
+	if estimate.Download == 0 {
+		line = fmt.Sprintf("Downloads %d %s of unknown size", fresh, packages)
+	}
+	if cached := len(estimate.Packages) - fresh; cached > 0 {
+		line += fmt.Sprintf(" (%d more already downloaded)", cached)
+	}
+	switch {
+	case estimate.Truncated:
+		line += "; more dependencies were not counted"
+	case estimate.Unknown > 0 && estimate.Download > 0:
+		line += fmt.Sprintf("; the size of %d is unknown", estimate.Unknown)
+	}
+	return line
+}
+
+// bulkSpecs returns the package specs of the arguments, or of stdin when there are none
+// or the only one is -. Remove takes no versions.
+func bulkSpecs(action operation.Action, args []string) ([]operation.Spec, int) {
+	var specs []operation.Spec
+	if len(args) == 0 || len(args) == 1 && args[0] == "-" {
+		if len(args) == 0 && platform.IsTerminal(int(os.Stdin.Fd())) {
+			fmt.Fprintf(os.Stderr, "Error: no packages given; pass them as arguments or one per line on stdin\n")
+			return nil, exitcode.UserError
+		}
+		var err error
+		if specs, err = operation.ReadSpecs(os.Stdin); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: stdin: %v\n", err)
+			return nil, exitcode.UserError
+		}
+	} else {
+		for _, arg := range args {
+			spec, err := operation.ParseSpec(arg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return nil, exitcode.UserError
+			}
+			specs = append(specs, spec)
+		}
+	}
+
+	if len(specs) == 0 {
+		infof("No packages to %s\n", action)
+	}
+	for _, spec := range specs {
+		if action == operation.ActionRemove && spec.Version != "" {
+			fmt.Fprintf(os.Stderr, "Error: %s: remove takes package IDs without versions\n", spec)
+			return nil, exitcode.UserError
+		}
+	}
+	return specs, exitcode.Success
+}
+
+// bulkProject returns the project of --project, or the only project in the repository.
+func bulkProject(name string) (string, int) {
+	if name != "" {
+		if _, err := os.Stat(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return "", exitcode.UserError
+		}
+		return name, exitcode.Success
+	}
+	paths, exitCode := workspaceProjects()
+	if exitCode != exitcode.Success {
+		return "", exitCode
+	}
+	if len(paths) > 1 {
+		fmt.Fprintf(os.Stderr, "Error: the repository has %d projects; pass one with --project\n", len(paths))
+		return "", exitcode.UserError
+	}
+	return paths[0], exitcode.Success
+}
+
+// printBulkResult prints the outcome of one package spec: changes on stdout, problems on
+// stderr.
+func printBulkResult(action operation.Action, r operation.Result) {
+	switch r.Status {
+	case operation.StatusFailed:
+		fmt.Fprintf(os.Stderr, "Error: %s: %s\n", r.Package, r.Error)
+	case operation.StatusSkipped:
+		warnf("%s\n", r.Error)
+	case operation.StatusUnchanged:
+		if action == operation.ActionRemove {
+			infof("%s is not referenced\n", r.Package)
+		} else {
+			infof("%s is already at %s\n", r.Package, r.Version)
+		}
+	default:
+		files := make([]string, len(r.Changed))
+		for i, path := range r.Changed {
+			files[i] = displayPath(path)
+		}
+		switch {
+		case action == operation.ActionRemove:
+			fmt.Printf("Removed %s (%s)\n", r.Package, strings.Join(files, ", "))
+		case r.PreviousVersion == "":
+			fmt.Printf("Added %s %s (%s)\n", r.Package, r.Version, strings.Join(files, ", "))
+		default:
+			fmt.Printf("Updated %s %s -> %s (%s)\n", r.Package, r.PreviousVersion, r.Version, strings.Join(files, ", "))
+		}
+	}
+}
+
+// bulkExitCode returns the exit code of a bulk operation: PartialFailure when only some
+// specs failed or were skipped, and otherwise the code of the worst outcome.
+func bulkExitCode(results []operation.Result) int {
+	failed, skipped := 0, 0
+	for _, r := range results {
+		switch r.Status {
+		case operation.StatusFailed:
+			failed++
+		case operation.StatusSkipped:
+			skipped++
+		}
+	}
+	switch {
+	case failed+skipped == 0:
+		return exitcode.Success
+	case failed+skipped < len(results):
+		return exitcode.PartialFailure
+	case failed > 0:
+		return exitcode.SystemError
+	default:
+		return exitcode.UserError
+	}
+}
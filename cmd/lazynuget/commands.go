@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/completion"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/policy"
+)
+
+// handler implements a command from the cli registry.
+type handler struct {
+	run    func(cmd *cli.Command, values *cli.Values) int
+	record bool   // Count the command in usage statistics for users who opted in
+	dotnet string // What the command runs dotnet for; empty when it works without it
+}
+
+// handlers maps cli registry keys to their implementations. Groups without a handler
+// (e.g., "config") print their help.
+var handlers = map[string]handler{
+	"encrypt-value":       {run: runEncryptValue, record: true},
+	"import-config":       {run: runImportConfig, record: true},
+	"config schema":       {run: runConfigSchema, record: true},
+	"add":                 {run: runAdd, record: true},
+	"audit":               {run: runAudit, record: true},
+	"batch":               {run: runBatch, record: true},
+	"diff":                {run: runDiff, record: true},
+	"doctor":              {run: runDoctor, record: true},
+	"edit":                {run: runEdit, record: true},
+	"feeds azure":         {run: runFeedsPreset, record: true},
+	"feeds github":        {run: runFeedsPreset, record: true},
+	"feeds gitlab":        {run: runFeedsPreset, record: true},
+	"frameworks list":     {run: runFrameworksList, record: true},
+	"frameworks retarget": {run: runFrameworksRetarget, record: true},
+	"imports":             {run: runImports, record: true},
+	"outdated":            {run: runOutdated, record: true},
+	"packages compare":    {run: runPackagesCompare, record: true},
+	"packages icon":       {run: runPackagesIcon, record: true},
+	"packages list":       {run: runPackagesList, record: true},
+	"packages quickstart": {run: runPackagesQuickstart, record: true},
+	"packages health":     {run: runPackagesHealth, record: true},
+	"packages usage":      {run: runPackagesUsage, record: true},
+	"plugin list":         {run: runPluginList, record: true},
+	"plugin run":          {run: runPluginRun, record: true},
+	"plugin panel":        {run: runPluginPanel, record: true},
+	"custom list":         {run: runCustomList, record: true},
+	"custom run":          {run: runCustomRun, record: true},
+	"remove":              {run: runRemove, record: true},
+	"resolve":             {run: runResolve, record: true, dotnet: "reads conflicts from dotnet restore"},
+	"search":              {run: runSearch, record: true},
+	"snapshot create":     {run: runSnapshotCreate, record: true},
+	"snapshot apply":      {run: runSnapshotApply, record: true},
+	"status-line":         {run: runStatusLine}, // Prompts run it on every line
+	"update":              {run: runUpdate, record: true},
+	"tools list":          {run: runToolsList, record: true},
+	"tools install":       {run: runToolsInstall, record: true, dotnet: "installs tools with dotnet tool"},
+	"tools update":        {run: runToolsUpdate, record: true, dotnet: "updates tools with dotnet tool"},
+	"tools uninstall":     {run: runToolsUninstall, record: true, dotnet: "removes tools with dotnet tool"},
+	"trust add":           {run: runTrustAdd, record: true},
+	"trust check":         {run: runTrustCheck, record: true},
+	"trust list":          {run: runTrustList, record: true},
+	"trust mode":          {run: runTrustMode, record: true},
+	"trust remove":        {run: runTrustRemove, record: true},
+	"warnings list":       {run: runWarningsList, record: true},
+	"warnings suppress":   {run: runWarningsSuppress, record: true},
+	"warnings unsuppress": {run: runWarningsUnsuppress, record: true},
+	"workloads list":      {run: runWorkloadsList, record: true, dotnet: "asks dotnet which workloads are installed"},
+	"workloads check":     {run: runWorkloadsCheck, record: true, dotnet: "asks dotnet which workloads are installed"},
+	"update-self":         {run: runUpdateSelf, record: true},
+	"path config":         {run: runPath},
+	"path cache":          {run: runPath},
+	"path data":           {run: runPath},
+	"path logs":           {run: runPath},
+	"path state":          {run: runPath},
+	"metrics dump":        {run: runMetricsDump},
+	"theme list":          {run: runThemeList, record: true},
+	"theme preview":       {run: runThemePreview, record: true},
+	"keys list":           {run: runKeysList, record: true},
+	"keys set":            {run: runKeysSet, record: true},
+	"keys edit":           {run: runKeysEdit, record: true},
+	"filters list":        {run: runFiltersList, record: true},
+	"notes list":          {run: runNotesList, record: true},
+	"notes add":           {run: runNotesAdd, record: true},
+	"notes done":          {run: runNotesDone, record: true},
+	"notes remove":        {run: runNotesRemove, record: true},
+	"notes clear":         {run: runNotesClear, record: true},
+	"notes export":        {run: runNotesExport, record: true},
+	"telemetry show":      {run: runTelemetryShow},
+	"telemetry enable":    {run: runTelemetryEnable},
+	"telemetry disable":   {run: runTelemetryDisable},
+	"help":                {run: runHelp},
+	"docs man":            {run: runDocsMan},
+}
+
+func init() {
+	for _, shell := range completion.Shells {
+		handlers["completion "+shell] = handler{run: runCompletion}
+	}
+}
+
+// runCommand dispatches a subcommand (args[0] is its name) without bootstrapping the app.
+func runCommand(args []string) int {
+	cmd, rest := cli.Root().Find(args)
+	if cmd.Parent() == nil {
+		fmt.Fprintf(os.Stderr, "Error: unknown command %q\n", args[0])
+		fmt.Fprintf(os.Stderr, "Run 'lazynuget --help' for a list of commands.\n")
+		return exitcode.UserError
+	}
+
+	// Completion scripts pass the words being completed verbatim, flags included
+	if cmd.Key() == completion.CompleteCommand {
+		return runComplete(rest)
+	}
+
+	h, ok := handlers[cmd.Key()]
+	if !ok {
+		return runGroup(cmd, rest)
+	}
+
+	values, err := cmd.Parse(rest)
+	if err != nil {
+		if cli.IsHelp(err) {
+			_ = writeHelp(cmd, os.Stdout)
+			return exitcode.Success
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+		_ = writeHelp(cmd, os.Stderr)
+		return exitcode.UserError
+	}
+
+	verbosity = values.Verbosity()
+	outputVersion, _ = values.OutputVersion() // Validated by Parse
+	if h.dotnet != "" && !platform.DotnetAvailable() {
+		return dotnetMissing(cmd.Path(), h.dotnet)
+	}
+
+	start := time.Now()
+	exitCode := h.run(cmd, values)
+	debugf("%s exited with %d after %s\n", cmd.Path(), exitCode, time.Since(start).Round(time.Millisecond))
+	if err := projectCache.Save(); err != nil {
+		warnf("%v\n", err)
+	}
+	if h.record {
+		recordSubcommand(strings.Fields(cmd.Key())[0], exitCode)
+	}
+	return exitCode
+}
+
+// dotnetMissing explains that a command is unavailable without the dotnet CLI and returns
+// the exit code.
+func dotnetMissing(command, reason string) int {
+	fmt.Fprintf(os.Stderr, "Error: `%s` %s, but the dotnet CLI was not found in PATH.\n", command, reason)
+	fmt.Fprintf(os.Stderr, "Without the .NET SDK lazynuget is read-only: listing, searching, and auditing packages still work.\n")
+	return exitcode.SystemError
+}
+
+// failureCode returns the exit code of a command that failed with err: PolicyViolation when
+// the machine policy forbids what was asked, and fallback otherwise.
+func failureCode(err error, fallback int) int {
+	var restricted *policy.RestrictedError
+	if errors.As(err, &restricted) {
+		return exitcode.PolicyViolation
+	}
+	return fallback
+}
+
+// interruptContext returns a context that is cancelled on Ctrl+C or SIGTERM, for commands
+// that run dotnet long enough to be worth stopping cleanly.
+func interruptContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// runGroup handles a command group invoked without one of its subcommands.
+func runGroup(cmd *cli.Command, args []string) int {
+	if len(args) == 1 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+		_ = writeHelp(cmd, os.Stdout)
+		return exitcode.Success
+	}
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unknown %s command %q\n\n", cmd.Key(), args[0])
+	}
+	_ = writeHelp(cmd, os.Stderr)
+	return exitcode.UserError
+}
+
+// writeHelp writes a command's --help text with the commands the machine policy
+// disables marked as restricted. An unreadable policy marks every such command.
+func writeHelp(cmd *cli.Command, w io.Writer) error {
+	p, _ := policy.LoadMachinePolicyOrRestrict()
+	return cmd.WritePolicyHelp(w, p)
+}
+
+// runHelp implements `lazynuget help [command...]`.
+func runHelp(_ *cli.Command, values *cli.Values) int {
+	target := cli.Root().Lookup(strings.Join(values.Args(), " "))
+	if target == nil {
+		fmt.Fprintf(os.Stderr, "Error: unknown command %q\n", strings.Join(values.Args(), " "))
+		return exitcode.UserError
+	}
+	if err := writeHelp(target, os.Stdout); err != nil {
+		return exitcode.SystemError
+	}
+	return exitcode.Success
+}
+
+// runDocsMan implements `lazynuget docs man [DIR]`.
+func runDocsMan(_ *cli.Command, values *cli.Values) int {
+	dir := cli.DefaultManDir
+	if args := values.Args(); len(args) > 0 {
+		dir = args[0]
+	}
+
+	// Use the build date so pages are reproducible for a given release
+	pageDate, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		pageDate = time.Now()
+	}
+
+	written, err := cli.Root().WriteManPages(dir, version, pageDate)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	infof("Wrote %d man pages to %s\n", len(written), dir)
+	return exitcode.Success
+}
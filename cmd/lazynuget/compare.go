@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/compare"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/theme"
+)
+
+// runPackagesCompare implements `lazynuget packages compare [--json] PACKAGE FROM [TO]`.
+func runPackagesCompare(_ *cli.Command, values *cli.Values) int {
+	args := values.Args()
+	if len(args) < 2 || len(args) > 3 {
+		fmt.Fprintln(os.Stderr, "Error: expected a package ID, a version, and an optional version to compare with")
+		return exitcode.UserError
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	feed, err := sourceFeed(ctx, values.String("source"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	feed.OSV = osvClient()
+
+	id, from, to := args[0], args[1], ""
+	if len(args) == 3 {
+		to = args[2]
+	} else {
+		versions, err := feed.Versions(ctx, id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+		if to = nuget.Latest(versions, nuget.IsPrerelease(from)); to == "" {
+			fmt.Fprintf(os.Stderr, "Error: package %s not found\n", id)
+			return exitcode.UserError
+		}
+	}
+
+	c, err := compare.Load(ctx, feed, nuget.GlobalPackagesDir(), id, from, to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if errors.Is(err, nuget.ErrVersionNotFound) {
+			return exitcode.UserError
+		}
+		return exitcode.SystemError
+	}
+
+	if values.Bool("json") {
+		return writeJSON(compare.Kind, c)
+	}
+
+	for _, warning := range c.Warnings {
+		warnf("%s\n", warning)
+	}
+	width := 80
+	if w, _, err := platform.NewTerminalCapabilities().GetSize(); err == nil {
+		width = w
+	}
+	printComparison(c, terminalTheme(values.Bool("no-color")), width)
+	return exitcode.Success
+}
+
+// compareRow is a line of the side-by-side view: a label, each version's value, and a
+// note on how they differ.
+type compareRow struct {
+	label, from, to, note string
+	style                 *theme.Style // nil for unstyled rows
+}
+
+// printComparison prints two versions of a package in columns, cut to width.
+func printComparison(c *compare.Comparison, th *theme.Theme, width int) {
+	styles := map[compare.Status]*theme.Style{
+		compare.StatusAdded:   &th.Success,
+		compare.StatusRemoved: &th.Error,
+		compare.StatusChanged: &th.Warning,
+	}
+
+	rows := []compareRow{{label: c.Package, from: c.From.Version, to: c.To.Version}}
+	sizeNote := ""
+	if c.SizeDelta != 0 {
+		sizeNote = signedSize(c.SizeDelta)
+	}
+	rows = append(rows, compareRow{label: "Size", from: formatSize(c.From.Size), to: formatSize(c.To.Size), note: sizeNote})
+
+	rows = append(rows, compareRow{label: "Frameworks"})
+	for _, f := range c.Frameworks {
+		row := compareRow{label: "", note: noteFor(f.Status), style: styles[f.Status]}
+		if f.Status != compare.StatusAdded {
+			row.from = f.Framework
+		}
+		if f.Status != compare.StatusRemoved {
+			row.to = f.Framework
+		}
+		rows = append(rows, row)
+	}
+	if len(c.Frameworks) == 0 {
+		rows = append(rows, compareRow{from: "any", to: "any"})
+	}
+
+	rows = append(rows, compareRow{label: "Dependencies"})
+	group := "-"
+	for _, d := range c.Dependencies {
+		if d.Framework != group {
+			group = d.Framework
+			name := group
+			if name == "" {
+				name = "all frameworks"
+			}
+			rows = append(rows, compareRow{label: "  " + name})
+		}
+		row := compareRow{label: "", note: noteFor(d.Status), style: styles[d.Status]}
+		if d.Status != compare.StatusAdded {
+			row.from = d.ID + " " + displayRange(d.From)
+		}
+		if d.Status != compare.StatusRemoved {
+			row.to = d.ID + " " + displayRange(d.To)
+		}
+		rows = append(rows, row)
+	}
+	if len(c.Dependencies) == 0 {
+		rows = append(rows, compareRow{from: "none", to: "none"})
+	}
+
+	rows = append(rows, compareRow{label: "Vulnerabilities"})
+	for _, a := range c.From.Advisories {
+		row := compareRow{from: a.Rating() + " " + a.URL, note: "fixed", style: &th.Success}
+		if !slices.ContainsFunc(c.Fixed, func(b nuget.Advisory) bool { return b.URL == a.URL }) {
+			row.to, row.note, row.style = row.from, "", &th.Error
+		}
+		rows = append(rows, row)
+	}
+	for _, a := range c.Introduced {
+		rows = append(rows, compareRow{to: a.Rating() + " " + a.URL, note: "introduced", style: &th.Error})
+	}
+	switch {
+	case !c.AdvisoriesChecked:
+		rows = append(rows, compareRow{from: "not checked", to: "not checked"})
+	case len(c.From.Advisories) == 0 && len(c.Introduced) == 0:
+		rows = append(rows, compareRow{from: "none known", to: "none known"})
+	}
+
+	// Labels and notes keep their width; the versions share the rest
+	labelWidth, noteWidth := 0, 0
+	for _, r := range rows {
+		labelWidth = max(labelWidth, utf8.RuneCountInString(r.label))
+		noteWidth = max(noteWidth, utf8.RuneCountInString(r.note))
+	}
+	column := max((width-labelWidth-noteWidth-6)/2, 10)
+	for _, r := range rows {
+		line := padCell(r.label, labelWidth) + "  " + padCell(cutCell(r.from, column), column) + "  " +
+			padCell(cutCell(r.to, column), column) + "  " + r.note
+		line = strings.TrimRight(line, " ")
+		if r.style != nil {
+			line = th.Render(*r.style, line)
+		}
+		fmt.Println(line)
+	}
+}
+
+// noteFor describes a status in the note column; unchanged items have no note.
+func noteFor(s compare.Status) string {
+	if s == compare.StatusUnchanged {
+		return ""
+	}
+	return string(s)
+}
+
+// displayRange shows a dependency's version range; a bare version means "at least".
+func displayRange(r string) string {
+	switch {
+	case r == "":
+		return "(any)"
+	case !strings.HasPrefix(r, "[") && !strings.HasPrefix(r, "("):
+		return ">= " + r
+	default:
+		return r
+	}
+}
+
+// cutCell shortens s to n runes, marking the cut with an ellipsis.
+func cutCell(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n-1]) + "…"
+}
+
+// padCell pads s with spaces to n runes.
+func padCell(s string, n int) string {
+	return s + strings.Repeat(" ", max(n-utf8.RuneCountInString(s), 0))
+}
+
+// formatSize formats a byte count, or "unknown" for 0.
+func formatSize(n int64) string {
+	if n <= 0 {
+		return "unknown"
+	}
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// signedSize formats a size change with its sign.
+func signedSize(delta int64) string {
+	if delta < 0 {
+		return "-" + formatSize(-delta)
+	}
+	return "+" + formatSize(delta)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/completion"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/instance"
+)
+
+// runCompletion implements `lazynuget completion <shell>`.
+func runCompletion(cmd *cli.Command, _ *cli.Values) int {
+	script, err := completion.Script(cmd.Name, cli.Program)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.UserError
+	}
+	fmt.Print(script)
+	return exitcode.Success
+}
+
+// runComplete implements the hidden `lazynuget __complete <words...>` used by the scripts.
+// It prints one candidate per line and never fails, so a broken config cannot break the shell.
+func runComplete(args []string) int {
+	sources := completion.Sources{
+		Profiles: completeProfiles,
+		Packages: func(prefix string) []string {
+			return completion.PackageIDs(completion.GlobalPackagesDir(), prefix)
+		},
+		Projects: completeProjects,
+	}
+	for _, candidate := range completion.Complete(cli.Root().Completion(), completion.NormalizeArgs(args), sources) {
+		fmt.Println(candidate)
+	}
+	return exitcode.Success
+}
+
+// completeProfiles returns the profile names in the user config.
+func completeProfiles() []string {
+	cfg, err := loadUserConfig()
+	if err != nil {
+		return nil
+	}
+	return slices.Sorted(maps.Keys(cfg.Profiles))
+}
+
+// completeProjects returns project and solution files in the current workspace.
+func completeProjects(string) []string {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	root, err := instance.WorkspaceRoot(workDir)
+	if err != nil {
+		return nil
+	}
+	return completion.ProjectPaths(root, workDir)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+)
+
+// runConfigSchema implements `lazynuget config schema`.
+// Generates a JSON Schema from GetConfigSchema() for editor completion and validation.
+func runConfigSchema(_ *cli.Command, values *cli.Values) int {
+	args := values.Args()
+	schema := config.GetConfigSchema()
+	// Theme files next to the config file are valid themes too
+	loadedFrom := ""
+	if cfg, err := loadUserConfig(); err == nil {
+		loadedFrom = cfg.LoadedFrom
+	}
+	if themes, err := config.ListThemes(config.ThemesDir(loadedFrom)); err == nil {
+		for _, theme := range themes {
+			if theme.Error == "" {
+				schema.AddEnumValues("theme", theme.Name)
+			}
+		}
+	}
+	data, err := schema.JSONSchema()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to generate schema: %v\n", err)
+		return exitcode.SystemError
+	}
+	data = append(data, '\n')
+
+	if len(args) == 0 || args[0] == "-" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			return exitcode.SystemError
+		}
+		return exitcode.Success
+	}
+
+	if err := os.WriteFile(args[0], data, 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write %s: %v\n", args[0], err)
+		return exitcode.UserError
+	}
+	infof("Wrote %s\n", args[0])
+	return exitcode.Success
+}
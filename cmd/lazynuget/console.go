@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/logging"
+	"github.com/willibrandon/lazynuget/internal/output"
+)
+
+// verbosity is set from the global --quiet and --verbose flags before a command runs.
+// Results go to stdout and errors to stderr whatever it is; it governs everything else.
+var verbosity = cli.VerbosityNormal
+
+// outputVersion is the JSON schema version set by the global --output-version flag.
+var outputVersion = output.CurrentSchemaVersion
+
+// infof prints progress, a summary, or a hint to stderr, unless --quiet was given.
+func infof(format string, args ...any) {
+	if verbosity != cli.VerbosityQuiet {
+		fmt.Fprintf(os.Stderr, format, args...)
+	}
+}
+
+// warnf prints a warning to stderr, unless --quiet was given.
+func warnf(format string, args ...any) {
+	infof("Warning: "+format, args...)
+}
+
+// debugf prints a detail to stderr when --verbose was given.
+func debugf(format string, args ...any) {
+	if verbosity == cli.VerbosityVerbose {
+		fmt.Fprintf(os.Stderr, format, args...)
+	}
+}
+
+// loadUserConfig loads the user config, ignoring the repository's. With --verbose the
+// loader logs where each setting came from.
+func loadUserConfig() (*config.Config, error) {
+	opts := config.LoadOptions{
+		EnvVarPrefix:    "LAZYNUGET_",
+		NoProjectConfig: true,
+	}
+	if verbosity == cli.VerbosityVerbose {
+		logger := logging.NewWithOptions(logging.Options{Level: verbosity.LogLevel(), Console: os.Stderr})
+		defer logger.Close()
+		opts.Logger = logger
+	}
+	return config.NewLoader().Load(context.Background(), opts)
+}
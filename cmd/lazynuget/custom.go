@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/custom"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/policy"
+)
+
+// customCommands returns the user config for custom commands. It fails when the machine
+// policy restricts custom commands and some are configured.
+func customCommands() (*config.Config, error) {
+	cfg, err := loadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.CustomCommands) > 0 {
+		p, err := policy.LoadMachinePolicy()
+		if err == nil {
+			err = p.Check(policy.CapabilityCustomCommands)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("custom commands not run: %w", err)
+		}
+	}
+	return cfg, nil
+}
+
+// runCustomList implements `lazynuget custom list [--json]`.
+func runCustomList(_ *cli.Command, values *cli.Values) int {
+	cfg, err := customCommands()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return failureCode(err, exitcode.UserError)
+	}
+	infos := custom.List(cfg.CustomCommands)
+	if values.Bool("json") {
+		return writeJSON(custom.Kind, infos)
+	}
+	if len(infos) == 0 {
+		fmt.Println("No custom commands configured (see the customCommands section of the config file)")
+		return exitcode.Success
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, info := range infos {
+		key := info.Key
+		if key != "" && info.Context != "global" {
+			key += " (" + info.Context + ")"
+		}
+		description := info.Description
+		if description == "" {
+			description = info.Command
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", info.Name, key, description)
+	}
+	if err := tw.Flush(); err != nil {
+		return exitcode.SystemError
+	}
+	return exitcode.Success
+}
+
+// runCustomRun implements `lazynuget custom run [--project PATH] [--package ID]
+// [--version VERSION] NAME [PROMPT=ANSWER...]`.
+func runCustomRun(_ *cli.Command, values *cli.Values) int {
+	args := values.Args()
+	root, exitCode := workspaceRoot()
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+	cfg, err := customCommands()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return failureCode(err, exitcode.UserError)
+	}
+	command, ok := custom.Find(cfg.CustomCommands, args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: no custom command %q (see `lazynuget custom list`)\n", args[0])
+		return exitcode.UserError
+	}
+	sel := custom.Selection{
+		Project: values.String("project"),
+		Package: values.String("package"),
+		Version: values.String("version"),
+	}
+
+	answers := make(map[string]string)
+	for _, arg := range args[1:] {
+		name, answer, ok := strings.Cut(arg, "=")
+		if !ok || !hasPrompt(command, name) {
+			fmt.Fprintf(os.Stderr, "Error: %s takes no answer %q (want PROMPT=ANSWER for one of its prompts)\n", command.Name, arg)
+			return exitcode.UserError
+		}
+		answers[name] = answer
+	}
+	in := bufio.NewReader(os.Stdin)
+	for _, prompt := range command.Prompts {
+		if _, ok := answers[prompt.Name]; ok {
+			continue
+		}
+		answer, exitCode := askPrompt(in, prompt, custom.Default(prompt, sel))
+		if exitCode != exitcode.Success {
+			return exitCode
+		}
+		answers[prompt.Name] = answer
+	}
+
+	line, err := custom.Expand(command, sel, answers)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.UserError
+	}
+	runner := custom.NewRunner(cfg, root)
+	runner.Stdin, runner.Stdout, runner.Stderr = os.Stdin, os.Stdout, os.Stderr
+	ctx, cancel := interruptContext()
+	defer cancel()
+	result := runner.Run(ctx, command, line, sel)
+
+	switch output := custom.OutputOf(command); {
+	case output == custom.OutputPager:
+		page(result.Output)
+	case output == custom.OutputNone && result.Err != nil:
+		fmt.Fprint(os.Stderr, result.Output)
+	}
+	if result.Err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s failed: %v\n", command.Name, result.Err)
+		return exitcode.SystemError
+	}
+	return exitcode.Success
+}
+
+// hasPrompt reports whether a custom command has a prompt of a name.
+func hasPrompt(command config.CustomCommand, name string) bool {
+	for _, prompt := range command.Prompts {
+		if prompt.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// askPrompt asks a custom command prompt on the terminal: a line of text, or a number
+// from the options. Without a terminal the default is the answer; with neither, the
+// answer is missing.
+func askPrompt(in *bufio.Reader, prompt config.CustomPrompt, defaultAnswer string) (string, int) {
+	title := prompt.Title
+	if title == "" {
+		title = prompt.Name
+	}
+	if !platform.IsStdinTerminal() {
+		if prompt.Default != "" {
+			return defaultAnswer, exitcode.Success
+		}
+		fmt.Fprintf(os.Stderr, "Error: missing answer %s=... (%s)\n", prompt.Name, title)
+		return "", exitcode.UserError
+	}
+
+	for i, option := range prompt.Options {
+		fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, option)
+	}
+	if defaultAnswer != "" {
+		title += " [" + defaultAnswer + "]"
+	}
+	fmt.Fprintf(os.Stderr, "%s: ", title)
+	line, err := in.ReadString('\n')
+	answer := strings.TrimSpace(line)
+	if err != nil && answer == "" {
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintf(os.Stderr, "Error: no answer entered\n")
+		return "", exitcode.UserError
+	}
+	if answer == "" {
+		return defaultAnswer, exitcode.Success
+	}
+	if len(prompt.Options) > 0 {
+		n, err := strconv.Atoi(answer)
+		if err != nil || n < 1 || n > len(prompt.Options) {
+			fmt.Fprintf(os.Stderr, "Error: enter a number from 1 to %d\n", len(prompt.Options))
+			return "", exitcode.UserError
+		}
+		answer = prompt.Options[n-1]
+	}
+	return answer, exitcode.Success
+}
+
+// page shows text in the pager ($PAGER, or less) when stdout is a terminal and the text
+// is taller than it, and prints it otherwise.
+func page(text string) {
+	_, height, err := platform.TerminalSize()
+	if !platform.IsStdoutTerminal() || err != nil || strings.Count(text, "\n") < height {
+		fmt.Print(text)
+		return
+	}
+	pager, err := custom.Pager(os.Getenv)
+	if err == nil && len(pager) > 0 {
+		// #nosec G204 -- the pager is the user's $PAGER
+		cmd := exec.CommandContext(context.Background(), pager[0], pager[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if cmd.Start() == nil {
+			_ = cmd.Wait()
+			return
+		}
+	}
+	fmt.Print(text)
+}
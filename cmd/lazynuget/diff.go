@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/snapshot"
+	"github.com/willibrandon/lazynuget/internal/theme"
+)
+
+// runDiff implements `lazynuget diff [--json] FROM [TO]`.
+func runDiff(_ *cli.Command, values *cli.Values) int {
+	args := values.Args()
+	if len(args) == 0 || len(args) > 2 {
+		fmt.Fprintln(os.Stderr, "Error: expected a git revision or snapshot file to compare with")
+		return exitcode.UserError
+	}
+	root, exitCode := workspaceRoot()
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+
+	from, err := loadSnapshot(root, args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.UserError
+	}
+	var to *snapshot.Snapshot
+	if len(args) == 2 {
+		to, err = loadSnapshot(root, args[1])
+	} else {
+		to, err = snapshot.Create(os.DirFS(root))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.UserError
+	}
+
+	d := snapshot.Compare(from, to)
+	d.From = args[0]
+	if len(args) == 2 {
+		d.To = args[1]
+	}
+
+	if values.Bool("json") {
+		return writeJSON(snapshot.DiffKind, d)
+	}
+
+	printDiff(d, terminalTheme(values.Bool("no-color")))
+	return exitcode.Success
+}
+
+// loadSnapshot reads a snapshot file, or captures a git revision when no such file exists.
+func loadSnapshot(root, source string) (*snapshot.Snapshot, error) {
+	if info, err := os.Stat(source); err == nil && !info.IsDir() {
+		return snapshot.ReadFile(source)
+	}
+	return snapshot.CreateFromGit(root, source)
+}
+
+// terminalTheme returns the user's color scheme rendered for stdout. noColor is a
+// command's --no-color flag.
+func terminalTheme(noColor bool) *theme.Theme {
+	scheme := config.GetDefaultConfig().ColorScheme
+	cfg, err := loadUserConfig()
+	if err == nil {
+		scheme = cfg.ColorScheme
+	}
+	return theme.New(scheme, terminalMode(noColor))
+}
+
+// terminalMode returns how styles are rendered on stdout. noColor is a command's
+// --no-color flag.
+func terminalMode(noColor bool) theme.Mode {
+	return theme.DetectMode(platform.NewTerminalCapabilities(), platform.CurrentConsoleMode(), noColor)
+}
+
+// printDiff prints each file's package changes, styled by severity.
+func printDiff(d *snapshot.Diff, th *theme.Theme) {
+	to := d.To
+	if to == "" {
+		to = "the working tree"
+	}
+	fmt.Printf("Comparing %s with %s\n", d.From, to)
+	if len(d.Files) == 0 {
+		fmt.Println("No package changes")
+		return
+	}
+
+	counts := make(map[snapshot.Status]int)
+	severities := make(map[snapshot.Severity]int)
+	for _, file := range d.Files {
+		fmt.Println()
+		if file.Status == snapshot.StatusChanged {
+			fmt.Println(file.Path)
+		} else {
+			fmt.Printf("%s (%s)\n", file.Path, file.Status)
+		}
+
+		width := 0
+		for _, p := range file.Packages {
+			width = max(width, len(p.ID))
+		}
+		for _, p := range file.Packages {
+			counts[p.Status]++
+			var line string
+			switch p.Status {
+			case snapshot.StatusAdded:
+				line = th.Render(th.Success, fmt.Sprintf("+ %-*s  %s", width, p.ID, p.To))
+			case snapshot.StatusRemoved:
+				line = th.Render(th.Error, fmt.Sprintf("- %-*s  %s", width, p.ID, p.From))
+			default:
+				severities[p.Severity]++
+				note := string(p.Severity)
+				if p.Downgrade {
+					note += ", downgrade"
+				}
+				line = th.Render(severityStyle(th, p.Severity), fmt.Sprintf("~ %-*s  %s -> %s  (%s)", width, p.ID, p.From, p.To, note))
+			}
+			fmt.Println("  " + line)
+		}
+	}
+
+	summary := fmt.Sprintf("%d added, %d removed, %d changed",
+		counts[snapshot.StatusAdded], counts[snapshot.StatusRemoved], counts[snapshot.StatusChanged])
+	var parts []string
+	for _, s := range []snapshot.Severity{snapshot.SeverityMajor, snapshot.SeverityMinor, snapshot.SeverityPatch, snapshot.SeverityPrerelease, snapshot.SeverityRange} {
+		if severities[s] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", severities[s], s))
+		}
+	}
+	if len(parts) > 0 {
+		summary += " (" + strings.Join(parts, ", ") + ")"
+	}
+	fmt.Println()
+	fmt.Println(summary)
+}
+
+// severityStyle returns the style of a version change: errors for breaking changes,
+// warnings for features, and success for fixes.
+func severityStyle(th *theme.Theme, s snapshot.Severity) theme.Style {
+	switch s {
+	case snapshot.SeverityMajor:
+		return th.Error
+	case snapshot.SeverityMinor:
+		return th.Warning
+	case snapshot.SeverityPatch:
+		return th.Success
+	case snapshot.SeverityPrerelease:
+		return th.Info
+	default:
+		return th.Text
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/permissions"
+)
+
+// runDoctor implements `lazynuget doctor [--fix] [--json]`.
+func runDoctor(_ *cli.Command, values *cli.Values) int {
+	logDir, _ := logsDir(nil) // Empty when unknown: no logs to check
+	targets := permissions.DefaultTargets(logDir)
+	findings := permissions.Check(targets, values.Bool("fix"))
+
+	if values.Bool("json") {
+		if findings == nil {
+			findings = []permissions.Finding{}
+		}
+		if code := writeJSON(permissions.Kind, permissions.Report{Targets: targets, Findings: findings}); code != exitcode.Success {
+			return code
+		}
+	} else {
+		for _, target := range targets {
+			if target.Path != "" {
+				debugf("Checked %s: %s\n", target.Name, target.Path)
+			}
+		}
+		printFindings(findings)
+	}
+	return doctorExitCode(findings)
+}
+
+// printFindings prints each entry that is not private to the user, and whether it was
+// fixed.
+func printFindings(findings []permissions.Finding) {
+	open := 0
+	for _, f := range findings {
+		switch {
+		case f.Problem == "":
+			fmt.Fprintf(os.Stderr, "Error: %s: %s\n", f.Path, f.Error)
+		case f.Fixed:
+			fmt.Printf("Fixed %s (%s)\n", f.Path, f.Problem)
+		case f.Error != "":
+			fmt.Fprintf(os.Stderr, "Error: %s: %s: %s\n", f.Path, f.Problem, f.Error)
+			open++
+		default:
+			fmt.Printf("%s: %s\n", f.Path, f.Problem)
+			open++
+		}
+	}
+	switch {
+	case open > 0:
+		infof("%d files or directories are not private to you; run `lazynuget doctor --fix` to restrict them\n", open)
+	case len(findings) == 0:
+		infof("No problems found\n")
+	}
+}
+
+// doctorExitCode returns SystemError when something could not be checked or fixed, and
+// PolicyViolation when entries are left that are not private to the user.
+func doctorExitCode(findings []permissions.Finding) int {
+	exitCode := exitcode.Success
+	for _, f := range findings {
+		switch {
+		case f.Error != "":
+			return exitcode.SystemError
+		case !f.Fixed:
+			exitCode = exitcode.PolicyViolation
+		}
+	}
+	return exitCode
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/editor"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/project"
+)
+
+// runEdit implements `lazynuget edit [--props | --nuget-config] [PROJECT]`.
+func runEdit(_ *cli.Command, values *cli.Values) int {
+	props, nugetConfig := values.Bool("props"), values.Bool("nuget-config")
+	if props && nugetConfig {
+		fmt.Fprintln(os.Stderr, "Error: --props and --nuget-config cannot be combined")
+		return exitcode.UserError
+	}
+
+	var projectPath string
+	if args := values.Args(); len(args) > 0 {
+		projectPath = args[0]
+	} else {
+		paths, exitCode := workspaceProjects()
+		if exitCode != exitcode.Success {
+			return exitCode
+		}
+		if len(paths) > 1 && !props && !nugetConfig {
+			fmt.Fprintf(os.Stderr, "Error: the repository has %d projects; pass the one to edit\n", len(paths))
+			return exitcode.UserError
+		}
+		projectPath = paths[0]
+	}
+
+	path := projectPath
+	switch {
+	case props:
+		if path = project.FindPackagesProps(filepath.Dir(projectPath)); path == "" {
+			fmt.Fprintf(os.Stderr, "Error: no %s applies to %s\n", project.PackagesPropsFile, displayPath(projectPath))
+			return exitcode.UserError
+		}
+	case nugetConfig:
+		if path = nuget.FindConfig(filepath.Dir(projectPath)); path == "" {
+			fmt.Fprintf(os.Stderr, "Error: no %s applies to %s\n", nuget.ConfigFile, displayPath(projectPath))
+			return exitcode.UserError
+		}
+	}
+	if _, err := os.Stat(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.UserError
+	}
+
+	configured := ""
+	cfg, err := loadUserConfig()
+	if err == nil {
+		configured = cfg.Editor
+	}
+	command, err := editor.Command(configured, os.Getenv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.UserError
+	}
+
+	changed, err := editor.Edit(context.Background(), command, path, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	if !changed {
+		fmt.Printf("%s unchanged\n", displayPath(path))
+		return exitcode.Success
+	}
+
+	// Parse the edited file again so mistakes show up now rather than at the next restore
+	if nugetConfig {
+		err = checkXML(path)
+	} else {
+		var p *project.Project
+		if p, err = project.Load(path); err == nil {
+			count := len(p.PackageReferences)
+			if props {
+				count = len(p.PackageVersions)
+			}
+			fmt.Printf("Reloaded %s: %d packages\n", displayPath(path), count)
+			return exitcode.Success
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s no longer parses: %v\n", displayPath(path), err)
+		return exitcode.UserError
+	}
+	fmt.Printf("Reloaded %s\n", displayPath(path))
+	return exitcode.Success
+}
+
+// checkXML reports whether a file is well-formed XML.
+func checkXML(path string) error {
+	// #nosec G304 -- path is the nuget.config the user edited
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	decoder := xml.NewDecoder(f)
+	for {
+		if _, err := decoder.Token(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/willibrandon/lazynuget/internal/cache"
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// runFeedsPreset implements `lazynuget feeds azure|github|gitlab`: it asks for what the
+// arguments and flags did not give, tests the token, and adds the feed to nuget.config.
+func runFeedsPreset(cmd *cli.Command, values *cli.Values) int {
+	preset, ok := nuget.FindPreset(cmd.Name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: no feed preset for %s\n", cmd.Name)
+		return exitcode.SystemError
+	}
+
+	// Required fields are arguments, optional ones flags; other flags refine the URL
+	fields := make(map[string]string)
+	for _, f := range cmd.Flags {
+		if f.Placeholder != "" {
+			fields[f.Name] = values.String(f.Name)
+		} else if values.Bool(f.Name) {
+			fields[f.Name] = "true"
+		}
+	}
+	in := bufio.NewReader(os.Stdin)
+	args := values.Args()
+	for _, f := range preset.Fields {
+		if f.Optional {
+			continue
+		}
+		if len(args) > 0 {
+			fields[f.Name], args = args[0], args[1:]
+			continue
+		}
+		value, exitCode := ask(in, f.Prompt, "argument <"+f.Name+">")
+		if exitCode != exitcode.Success {
+			return exitCode
+		}
+		fields[f.Name] = value
+	}
+
+	indexURL, err := preset.URL(fields)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.UserError
+	}
+	username := fields["username"]
+	if username == "" {
+		username = preset.Username(fields)
+	}
+	if username == "" {
+		var exitCode int
+		if username, exitCode = ask(in, preset.Title+" user name", "--username"); exitCode != exitcode.Success {
+			return exitCode
+		}
+	}
+	name := fields["name"]
+	if name == "" {
+		// The feed name, or the registry and owner (e.g., github-contoso)
+		name = fields["feed"]
+		if name == "" {
+			name = preset.Name + "-" + strings.ReplaceAll(fields[preset.Fields[0].Name], "/", "-")
+		}
+	}
+	configPath := fields["nuget-config"]
+	if configPath == "" {
+		if configPath = nuget.UserConfigPath(); configPath == "" {
+			fmt.Fprintf(os.Stderr, "Error: the user NuGet configuration file could not be found; use --nuget-config\n")
+			return exitcode.SystemError
+		}
+	}
+
+	tokenEnv := fields["token-env"]
+	if tokenEnv == "" {
+		tokenEnv = preset.TokenEnv
+	}
+	token, exitCode := readToken(tokenEnv, preset.TokenScopes)
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if !values.Bool("no-test") {
+		if err := nuget.CheckSource(ctx, nil, indexURL, username, token); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			if errors.Is(err, nuget.ErrUnauthorized) {
+				fmt.Fprintf(os.Stderr, "Check that the token has not expired and is %s.\n", preset.TokenScopes)
+				return exitcode.UserError
+			}
+			return exitcode.SystemError
+		}
+		infof("Authenticated to %s\n", indexURL)
+	}
+
+	source := nuget.Source{Name: name, URL: indexURL}
+	clearText := values.Bool("store-password-in-clear-text")
+	if clearText {
+		source.Username, source.Password = username, token
+	}
+	if err := nuget.AddSource(configPath, source); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	fmt.Printf("Added source %s to %s\n", name, configPath)
+	if !clearText {
+		if preset.Name == "azure" {
+			fmt.Println("dotnet restore authenticates with the Azure Artifacts Credential Provider " +
+				"(https://github.com/microsoft/artifacts-credprovider); without it, run again with --store-password-in-clear-text.")
+		} else {
+			fmt.Println("dotnet restore needs the token to read the feed; run again with --store-password-in-clear-text " +
+				"to write it to nuget.config.")
+		}
+	}
+
+	// Keep the token for LazyNuGet encrypted with the key in the platform keychain
+	encryptor := config.NewEncryptor(config.NewKeychainManager(), config.NewKeyDerivation())
+	encrypted, err := encryptor.EncryptToString(ctx, token, "default")
+	if err != nil {
+		warnf("the token could not be encrypted for LazyNuGet's config: %v\n", err)
+		infof("Store an encryption key in the keychain or set LAZYNUGET_ENCRYPTION_KEY_DEFAULT (e.g., openssl rand -hex 32).\n")
+		return exitcode.Success
+	}
+	fmt.Printf("\nTo use the feed in LazyNuGet, add it to config.yml:\n")
+	fmt.Printf("feeds:\n  - name: %s\n    url: %s\n    username: %s\n    password: %s\n", name, indexURL, username, encrypted)
+	return exitcode.Success
+}
+
+// ask reads a value typed at the terminal; without a terminal to ask, the missing
+// argument or flag is a usage error.
+func ask(in *bufio.Reader, prompt, missing string) (string, int) {
+	if !platform.IsStdinTerminal() {
+		fmt.Fprintf(os.Stderr, "Error: missing %s\n", missing)
+		return "", exitcode.UserError
+	}
+	fmt.Fprintf(os.Stderr, "%s: ", prompt)
+	line, err := in.ReadString('\n')
+	value := strings.TrimSpace(line)
+	if value == "" {
+		if err != nil {
+			fmt.Fprintln(os.Stderr)
+		}
+		fmt.Fprintf(os.Stderr, "Error: no value entered\n")
+		return "", exitcode.UserError
+	}
+	return value, exitcode.Success
+}
+
+// readToken reads a token from an environment variable, or asks for it without echoing
+// when stdin is a terminal.
+func readToken(envVar, scopes string) (string, int) {
+	if token := strings.TrimSpace(os.Getenv(envVar)); token != "" {
+		return token, exitcode.Success
+	}
+	if !platform.IsStdinTerminal() {
+		fmt.Fprintf(os.Stderr, "Error: set %s to %s\n", envVar, scopes)
+		return "", exitcode.UserError
+	}
+	fmt.Fprintf(os.Stderr, "Token (%s): ", scopes)
+	data, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return "", exitcode.SystemError
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		fmt.Fprintf(os.Stderr, "Error: no token entered\n")
+		return "", exitcode.UserError
+	}
+	return token, exitcode.Success
+}
+
+// sourceFeed returns the feed of a --source URL. A service index URL is probed for the
+// resources the server offers; any other URL is the package base address of the feed.
+// The feedFallbacks of the user config serve reads while the feed is unavailable, with a
+// warning on stderr the first time each fallback is used.
+func sourceFeed(ctx context.Context, source string) (*nuget.Feed, error) {
+	cfg, cfgErr := loadUserConfig()
+	if cfgErr != nil {
+		cfg = nil
+	}
+	feed, err := nuget.OpenFeed(ctx, feedClient(cfg), source)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return feed, nil
+	}
+
+	urls := map[string]string{"nuget.org": "https://api.nuget.org/v3/index.json"}
+	for _, f := range cfg.Feeds {
+		if !f.Disabled {
+			urls[strings.ToLower(f.Name)] = f.URL
+		}
+	}
+	for _, fallback := range cfg.FeedFallbacks {
+		for _, name := range fallback.Feeds {
+			url, ok := urls[strings.ToLower(name)]
+			if !ok {
+				url = name
+			}
+			if strings.EqualFold(strings.TrimSuffix(url, "/"), strings.TrimSuffix(source, "/")) {
+				continue
+			}
+			feed.Fallbacks = append(feed.Fallbacks, &nuget.Fallback{Name: name, Packages: fallback.Packages, URL: url})
+		}
+	}
+
+	var mu sync.Mutex
+	warned := make(map[*nuget.Fallback]bool)
+	feed.OnFallback = func(id string, fallback *nuget.Fallback, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !warned[fallback] {
+			warned[fallback] = true
+			warnf("%v\nReading %s from the fallback feed %s instead (read-only).\n", err, fallback.Packages, fallback.Name)
+		}
+	}
+	return feed, nil
+}
+
+// feedClient returns the HTTP client feeds are read with. Each request is bounded by the
+// timeout of its feed in cfg (the default when nil), or timeouts.networkRequest, and
+// carries the credentials of its feed in cfg. Responses are kept in the http folder of
+// the cache directory between runs, within the cacheSize of cfg, and served from there
+// while they are fresh.
+func feedClient(cfg *config.Config) *http.Client {
+	if cfg == nil {
+		cfg = config.GetDefaultConfig()
+	}
+	client := nuget.NewFeed().HTTPClient
+	client.Transport = nuget.TimeoutTransport(client.Transport, func(req *http.Request) time.Duration {
+		return cfg.RequestTimeout(req.URL.String())
+	})
+	if dir := cache.DefaultDir("http"); dir != "" {
+		client = &http.Client{Transport: cache.Transport(cache.New(dir, int64(cfg.CacheSize)<<20), client.Transport)}
+	}
+	// Outside the cache, which passes authenticated requests through
+	client.Transport = nuget.AuthTransport(client.Transport, func(req *http.Request) nuget.Credentials {
+		return feedCredentials(cfg, req.URL.String())
+	})
+	return client
+}
+
+// feedCredentials returns the credentials of the feed in cfg that rawURL belongs to.
+func feedCredentials(cfg *config.Config, rawURL string) nuget.Credentials {
+	feed, ok := cfg.FeedCredentials(rawURL)
+	if !ok {
+		return nuget.Credentials{}
+	}
+	return nuget.Credentials{APIKey: feed.APIKey, Username: feed.Username, Password: feed.Password}
+}
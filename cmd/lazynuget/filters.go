@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/outdated"
+	"github.com/willibrandon/lazynuget/internal/query"
+	"github.com/willibrandon/lazynuget/internal/snapshot"
+)
+
+// filtersKind is the JSON document kind of `lazynuget filters list --json`.
+const filtersKind = "savedFilters"
+
+// savedFilters returns the savedFilters setting of the user config, as the command palette
+// lists them; none when the config cannot be loaded.
+func savedFilters() []query.Saved {
+	cfg, err := loadUserConfig()
+	if err != nil {
+		return nil
+	}
+	return toSaved(cfg.SavedFilters)
+}
+
+// toSaved converts the savedFilters setting.
+func toSaved(filters []config.SavedFilter) []query.Saved {
+	saved := make([]query.Saved, 0, len(filters))
+	for _, f := range filters {
+		saved = append(saved, query.Saved{Name: f.Name, Query: f.Query, Description: f.Description})
+	}
+	return saved
+}
+
+// resolveFilter returns the filter of a --filter value: a saved filter's name, or a filter.
+func resolveFilter(value string) (*query.Filter, int) {
+	f, err := query.Resolve(savedFilters(), value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --filter: %v\n", err)
+		return nil, exitcode.UserError
+	}
+	return f, exitcode.Success
+}
+
+// runFiltersList implements `lazynuget filters list [--json]`.
+func runFiltersList(_ *cli.Command, values *cli.Values) int {
+	cfg, err := loadUserConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.UserError
+	}
+	saved := toSaved(cfg.SavedFilters)
+	if values.Bool("json") {
+		return writeJSON(filtersKind, map[string]any{"filters": saved})
+	}
+	if len(saved) == 0 {
+		infof("No saved filters; add them to savedFilters in %s\n", cfg.LoadedFrom)
+		return exitcode.Success
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, s := range saved {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Name, s.Query, s.Description)
+	}
+	if err := tw.Flush(); err != nil {
+		return exitcode.SystemError
+	}
+	return exitcode.Success
+}
+
+// filterResults returns the results of a project that match a filter, looking up the
+// advisories of their resolved versions when the filter needs them. advisories caches
+// them across projects, by lowercase package ID.
+func filterResults(ctx context.Context, f *query.Filter, feed *nuget.Feed, advisories map[string][]nuget.Advisory,
+	projectPath string, results []outdated.Result,
+) ([]outdated.Result, error) {
+	var matched []outdated.Result
+	for _, r := range results {
+		row := query.Row{Package: r.Package, Project: filepath.Base(projectPath), Version: r.Resolved, Floating: r.Floating}
+		if (r.Status == outdated.StatusOutdated || r.Status == outdated.StatusRestore) && r.Latest != "" {
+			row.Update = string(snapshot.VersionSeverity(r.Resolved, r.Latest))
+			if !slices.Contains(query.Updates, row.Update) {
+				row.Update = "patch" // Only the range differs; still an update
+			}
+		}
+		if f.NeedsAdvisories() && r.Resolved != "" {
+			key := strings.ToLower(r.Package)
+			list, ok := advisories[key]
+			if !ok {
+				var err error
+				if list, err = feed.Advisories(ctx, r.Package); err != nil {
+					return nil, err
+				}
+				advisories[key] = list
+			}
+			for _, a := range list {
+				if a.Affects(r.Resolved) && severityRank(a.Severity) > severityRank(row.Severity) {
+					row.Severity = a.Severity
+				}
+			}
+		}
+		if f.Match(row) {
+			matched = append(matched, r)
+		}
+	}
+	return matched, nil
+}
+
+// severityRank orders advisory severities, 0 for none.
+func severityRank(severity string) int {
+	return slices.Index(query.Severities, strings.ToLower(severity)) + 1
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/cache"
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/instance"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/project"
+)
+
+// runFrameworksList implements `lazynuget frameworks list [PROJECT...]`.
+func runFrameworksList(_ *cli.Command, values *cli.Values) int {
+	paths := values.Args()
+	if len(paths) == 0 {
+		var exitCode int
+		if paths, exitCode = workspaceProjects(); exitCode != exitcode.Success {
+			return exitCode
+		}
+	}
+
+	now := time.Now()
+	for p, err := range loadProjects(paths) {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+		path := p.Path
+
+		fmt.Println(displayPath(path))
+		if len(p.TargetFrameworks) == 0 {
+			fmt.Println("  (no TargetFramework; it may be set in Directory.Build.props)")
+			continue
+		}
+		for _, tfm := range p.TargetFrameworks {
+			fmt.Printf("  %-16s %s\n", tfm, describeSupport(tfm, now))
+		}
+	}
+	return exitcode.Success
+}
+
+// describeSupport summarizes a framework's support status and suggested upgrade.
+func describeSupport(tfm string, now time.Time) string {
+	f, err := nuget.ParseFramework(tfm)
+	if err != nil {
+		return "unknown"
+	}
+	support := nuget.SupportFor(f, now)
+
+	var desc string
+	switch support.Status {
+	case nuget.StatusOutOfSupport:
+		desc = "out of support since " + support.EndOfSupport.Format(time.DateOnly)
+	case nuget.StatusEndingSoon:
+		desc = "ending soon: support ends " + support.EndOfSupport.Format(time.DateOnly)
+	case nuget.StatusSupported:
+		desc = "supported"
+		if !support.EndOfSupport.IsZero() {
+			desc += " until " + support.EndOfSupport.Format(time.DateOnly)
+		}
+	case nuget.StatusNotApplicable:
+		return string(f.Family) + " (no support lifecycle)"
+	default:
+		return "unknown support status"
+	}
+	if support.LTS {
+		desc = "LTS, " + desc
+	}
+	if upgrade := nuget.SuggestedUpgrade(f); upgrade != "" && support.Status != nuget.StatusSupported {
+		desc += " (upgrade to " + upgrade + ")"
+	}
+	return desc
+}
+
+// runFrameworksRetarget implements `lazynuget frameworks retarget [--from TFM] PROJECT TFM`.
+func runFrameworksRetarget(_ *cli.Command, values *cli.Values) int {
+	path, to := values.Args()[0], values.Args()[1]
+
+	target, err := nuget.ParseFramework(to)
+	if err != nil || target.Family == nuget.FamilyUnknown {
+		fmt.Fprintf(os.Stderr, "Error: unrecognized target framework %q\n", to)
+		return exitcode.UserError
+	}
+
+	p, err := project.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+
+	var incompatible, unknown []string
+	for _, check := range project.CheckRetarget(p, target, nuget.GlobalPackagesDir()) {
+		ref := check.Reference
+		switch check.Result {
+		case project.Incompatible:
+			var monikers []string
+			for _, f := range check.Frameworks {
+				monikers = append(monikers, f.Moniker)
+			}
+			incompatible = append(incompatible, fmt.Sprintf("%s %s (has assets for %s)", ref.ID, ref.Version, strings.Join(monikers, ", ")))
+		case project.Unknown:
+			unknown = append(unknown, strings.TrimSpace(ref.ID+" "+ref.Version))
+		}
+	}
+
+	if len(incompatible) > 0 {
+		fmt.Fprintf(os.Stderr, "Incompatible with %s:\n", target)
+		for _, line := range incompatible {
+			fmt.Fprintf(os.Stderr, "  %s\n", line)
+		}
+	}
+	if len(unknown) > 0 {
+		fmt.Fprintf(os.Stderr, "Not checked (not restored; run dotnet restore first):\n")
+		for _, line := range unknown {
+			fmt.Fprintf(os.Stderr, "  %s\n", line)
+		}
+	}
+	if len(incompatible) == 0 && len(unknown) == 0 {
+		fmt.Fprintf(os.Stderr, "All package references are compatible with %s.\n", target)
+	}
+
+	if values.Bool("dry-run") {
+		if len(incompatible) > 0 {
+			return exitcode.UserError
+		}
+		return exitcode.Success
+	}
+	if len(incompatible) > 0 && !values.Bool("force") {
+		fmt.Fprintf(os.Stderr, "Not retargeted; update or remove the incompatible references, or use --force.\n")
+		return exitcode.UserError
+	}
+
+	from := values.String("from")
+	previous := strings.Join(p.TargetFrameworks, ";")
+	if err := project.Retarget(p, from, to); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.UserError
+	}
+	infof("Retargeted %s from %s to %s. Run dotnet restore to update the lock and assets files.\n",
+		displayPath(path), previous, strings.Join(p.TargetFrameworks, ";"))
+	return exitcode.Success
+}
+
+// projectCache keeps the projects of the current repository between runs. It is opened
+// by workspaceProjects and saved when the command finishes; until then it is nil, which
+// reads project files directly.
+var projectCache *project.Cache
+
+// progressMin is the number of projects from which loading shows progress on a terminal.
+const progressMin = 50
+
+// loadProjects reads project files through projectCache, maxConcurrentOps at a time, and
+// yields them in order as they are read. Large solutions show progress on stderr, unless
+// --quiet was given.
+func loadProjects(paths []string) iter.Seq2[*project.Project, error] {
+	return func(yield func(*project.Project, error) bool) {
+		var status string
+		if len(paths) >= progressMin && verbosity != cli.VerbosityQuiet && platform.IsTerminal(int(os.Stderr.Fd())) {
+			status = fmt.Sprintf("Reading projects 0/%d", len(paths))
+			fmt.Fprint(os.Stderr, status)
+		}
+		read := 0
+		for p, err := range projectCache.LoadAll(paths, maxConcurrentOps()) {
+			if status != "" {
+				// Output goes above the progress line
+				fmt.Fprintf(os.Stderr, "\r%s\r", strings.Repeat(" ", len(status)))
+			}
+			if !yield(p, err) {
+				return
+			}
+			if read++; status != "" && read < len(paths) {
+				status = fmt.Sprintf("Reading projects %d/%d", read, len(paths))
+				fmt.Fprint(os.Stderr, status)
+			}
+		}
+	}
+}
+
+// maxConcurrentOps returns the configured maxConcurrentOps, or its default without a
+// loadable config.
+func maxConcurrentOps() int {
+	cfg, err := loadUserConfig()
+	if err != nil {
+		return config.GetDefaultConfig().MaxConcurrentOps
+	}
+	return cfg.MaxConcurrentOps
+}
+
+// renderFPS returns the configured renderFps, or its default without a loadable config.
+func renderFPS() int {
+	cfg, err := loadUserConfig()
+	if err != nil {
+		return config.GetDefaultConfig().RenderFPS
+	}
+	return cfg.RenderFPS
+}
+
+// workspaceProjects returns the project files in the current repository.
+// On failure it prints the error and returns a non-zero exit code.
+func workspaceProjects() ([]string, int) {
+	root, exitCode := workspaceRoot()
+	if exitCode != exitcode.Success {
+		return nil, exitCode
+	}
+	if projectCache == nil {
+		projectCache = project.OpenCache(project.CachePath(cache.DefaultDir("projects"), root))
+	}
+	paths, err := projectCache.Discover(root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return nil, exitcode.SystemError
+	}
+	if len(paths) == 0 {
+		fmt.Fprintf(os.Stderr, "No project files found under %s\n", root)
+		return nil, exitcode.UserError
+	}
+	return paths, exitcode.Success
+}
+
+// workspaceRoot returns the repository containing the working directory.
+func workspaceRoot() (string, int) {
+	workDir, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return "", exitcode.SystemError
+	}
+	root, err := instance.WorkspaceRoot(workDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return "", exitcode.SystemError
+	}
+	return root, exitcode.Success
+}
+
+// displayPath returns path relative to the working directory when it is inside it.
+func displayPath(path string) string {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(workDir, abs); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
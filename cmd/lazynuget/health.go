@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+)
+
+// packageHealth is a package's row in `lazynuget packages health`.
+type packageHealth struct {
+	Package string `json:"package"`
+	nuget.Health
+}
+
+// runPackagesHealth implements `lazynuget packages health [--json] [PACKAGE...]`.
+func runPackagesHealth(_ *cli.Command, values *cli.Values) int {
+	defaults := config.GetDefaultConfig()
+	staleAfter, dateFormat := defaults.StaleAfterYears, defaults.DateFormat
+	if cfg, err := loadUserConfig(); err == nil {
+		staleAfter, dateFormat = cfg.StaleAfterYears, cfg.DateFormat
+	}
+	if s := values.String("stale-after"); s != "" {
+		years, err := strconv.Atoi(s)
+		if err != nil || years < 1 {
+			fmt.Fprintln(os.Stderr, "Error: --stale-after must be a number of years, 1 or more")
+			return exitcode.UserError
+		}
+		staleAfter = years
+	}
+
+	ids := values.Args()
+	if len(ids) == 0 {
+		paths, exitCode := workspaceProjects()
+		if exitCode != exitcode.Success {
+			return exitCode
+		}
+		for p, err := range loadProjects(paths) {
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return exitcode.SystemError
+			}
+			for _, ref := range p.PackageReferences {
+				if !nuget.IsPlatformPackage(ref.ID) && !slices.ContainsFunc(ids, func(id string) bool { return strings.EqualFold(id, ref.ID) }) {
+					ids = append(ids, ref.ID)
+				}
+			}
+		}
+		slices.SortFunc(ids, func(a, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) })
+		if len(ids) == 0 {
+			infof("No package references\n")
+			return exitcode.Success
+		}
+	}
+
+	ctx, cancel := interruptContext()
+	defer cancel()
+	feed, err := sourceFeed(ctx, values.String("source"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+
+	now := time.Now()
+	rows := make([]packageHealth, 0, len(ids))
+	for _, id := range ids {
+		releases, err := feed.Releases(ctx, id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+		rows = append(rows, packageHealth{Package: id, Health: nuget.HealthOf(releases, now, time.Duration(staleAfter)*365*24*time.Hour)})
+	}
+
+	if values.Bool("json") {
+		return writeJSON(nuget.HealthKind, map[string]any{"staleAfterYears": staleAfter, "packages": rows})
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	stale := 0
+	for _, r := range rows {
+		if r.Releases == 0 {
+			fmt.Fprintf(tw, "%s\tno dated releases on the feed\n", r.Package)
+			continue
+		}
+		cadence := ""
+		if r.CadenceDays > 0 {
+			cadence = "every " + nuget.FormatSpan(time.Duration(r.CadenceDays)*24*time.Hour)
+		}
+		note := ""
+		if r.Stale {
+			note = "stale"
+			stale++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s (%s ago)\t%s\t%d releases\t%s\n", r.Package, r.LastVersion, r.LastPublished.Format(dateFormat),
+			nuget.FormatSpan(now.Sub(r.LastPublished)), cadence, r.Releases, note)
+	}
+	if err := tw.Flush(); err != nil {
+		return exitcode.SystemError
+	}
+	if stale > 0 {
+		warnf("%d of %d packages have had no release in over %d years\n", stale, len(rows), staleAfter)
+	}
+	return exitcode.Success
+}
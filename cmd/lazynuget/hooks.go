@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/hooks"
+	"github.com/willibrandon/lazynuget/internal/policy"
+)
+
+// hookRunner returns a runner for the hooks in the user config, which reports failed
+// hooks and their output on stderr. Without a loadable config, or where the machine policy
+// restricts custom commands, no hooks run.
+func hookRunner() *hooks.Runner {
+	cfg, err := loadUserConfig()
+	if err != nil {
+		return &hooks.Runner{}
+	}
+	p, err := policy.LoadMachinePolicy()
+	if err == nil {
+		err = p.Check(policy.CapabilityCustomCommands)
+	}
+	if err != nil {
+		if len(cfg.Hooks.PreInstall)+len(cfg.Hooks.PostUpdate)+len(cfg.Hooks.PostRestore) > 0 {
+			warnf("hooks not run: %v\n", err)
+		}
+		return &hooks.Runner{}
+	}
+	runner := hooks.NewRunner(cfg)
+	runner.Notify = func(r hooks.Result) {
+		if r.Err == nil {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Hook %q failed: %v\n", r.Command, r.Err)
+		if output := strings.TrimSpace(r.Output); output != "" {
+			fmt.Fprintf(os.Stderr, "  %s\n", strings.ReplaceAll(output, "\n", "\n  "))
+		}
+	}
+	return runner
+}
+
+// runHooks runs the hooks for an operation. Failures are reported by the runner's Notify;
+// the returned error is only for pre-operation hooks, which cancel the operation.
+func runHooks(runner *hooks.Runner, op hooks.Operation) error {
+	_, err := runner.Run(context.Background(), op)
+	if err != nil && op.Pre() {
+		return err
+	}
+	return nil
+}
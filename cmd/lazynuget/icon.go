@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/cache"
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/icon"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// runPackagesIcon implements `lazynuget packages icon [--protocol NAME] PACKAGE [VERSION]`.
+func runPackagesIcon(_ *cli.Command, values *cli.Values) int {
+	args := values.Args()
+	if len(args) == 0 || len(args) > 2 {
+		fmt.Fprintln(os.Stderr, "Error: expected a package ID and an optional version")
+		return exitcode.UserError
+	}
+	rows, err := strconv.Atoi(values.String("rows"))
+	if err != nil || rows < 1 || rows > 16 {
+		fmt.Fprintf(os.Stderr, "Error: --rows must be a number from 1 to 16\n")
+		return exitcode.UserError
+	}
+
+	setting, cacheSize := "auto", config.GetDefaultConfig().CacheSize
+	cfg, err := loadUserConfig()
+	if err == nil {
+		setting, cacheSize = cfg.PackageIcons, cfg.CacheSize
+	}
+	if p := values.String("protocol"); p != "" {
+		setting = p
+	}
+
+	// Windows consoles interpret escape sequences only with VT processing enabled
+	console, restoreConsole := platform.EnableVirtualTerminal()
+	defer restoreConsole()
+
+	protocol := icon.ParseProtocol(setting, os.Getenv)
+	terminal := platform.NewTerminalCapabilities()
+	if !terminal.IsTTY() || console.Legacy() {
+		// Image escape sequences are meaningless in files and pipes, and legacy consoles print them
+		protocol = icon.ProtocolNone
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	feed, err := sourceFeed(ctx, values.String("source"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+
+	id, version := args[0], ""
+	if len(args) == 2 {
+		version = args[1]
+	} else {
+		versions, err := feed.Versions(ctx, id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+		if version = nuget.Latest(versions, false); version == "" {
+			version = nuget.Latest(versions, true)
+		}
+		if version == "" {
+			fmt.Fprintf(os.Stderr, "Error: package %s not found\n", id)
+			return exitcode.UserError
+		}
+	}
+
+	renderer := &icon.Renderer{
+		Feed:     feed,
+		Protocol: protocol,
+		Depth:    terminal.GetColorDepth(),
+		Rows:     rows,
+	}
+	if dir := cache.DefaultDir("icons"); dir != "" {
+		renderer.Cache = cache.New(dir, int64(cacheSize)<<20)
+	}
+
+	text, err := renderer.Render(ctx, id, version)
+	fmt.Println(text)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	fmt.Printf("%s %s\n", id, version)
+	return exitcode.Success
+}
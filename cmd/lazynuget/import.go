@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+)
+
+// runImportConfig implements the `lazynuget import-config` subcommand.
+// Translates settings from lazygit, Renovate, or Dependabot into a LazyNuGet config.
+// The generated YAML is written to stdout (or --output); skipped settings are reported on stderr.
+func runImportConfig(cmd *cli.Command, values *cli.Values) int {
+	from := values.String("from")
+	input := values.String("input")
+	outputPath := values.String("output")
+	force := values.Bool("force")
+
+	if from == "" {
+		fmt.Fprintf(os.Stderr, "Error: --from is required\n\n")
+		_ = writeHelp(cmd, os.Stderr)
+		return exitcode.UserError
+	}
+
+	if input == "" {
+		for _, candidate := range config.DefaultImportPaths(from) {
+			if _, err := os.Stat(candidate); err == nil {
+				input = candidate
+				break
+			}
+		}
+		if input == "" {
+			fmt.Fprintf(os.Stderr, "Error: no %s config found; pass --input PATH\n", from)
+			return exitcode.UserError
+		}
+	}
+
+	// #nosec G304 -- path is supplied by the user running the command
+	data, err := os.ReadFile(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to read %s: %v\n", input, err)
+		return exitcode.UserError
+	}
+
+	result, err := config.ImportConfig(from, data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.UserError
+	}
+
+	out, err := result.YAML()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to render config: %v\n", err)
+		return exitcode.SystemError
+	}
+	out = append([]byte(fmt.Sprintf("# Imported from %s (%s)\n", from, input)), out...)
+
+	if outputPath == "" {
+		if _, err := os.Stdout.Write(out); err != nil {
+			return exitcode.SystemError
+		}
+	} else {
+		flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if !force {
+			flags |= os.O_EXCL
+		}
+		// #nosec G304 -- path is supplied by the user running the command
+		f, err := os.OpenFile(outputPath, flags, 0o600)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to create %s: %v (use --force to overwrite)\n", outputPath, err)
+			return exitcode.UserError
+		}
+		_, writeErr := f.Write(out)
+		closeErr := f.Close()
+		if writeErr != nil || closeErr != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to write %s\n", outputPath)
+			return exitcode.SystemError
+		}
+		infof("Wrote %s\n", outputPath)
+	}
+
+	// Report what could not be translated (stderr so stdout stays valid YAML)
+	for _, note := range result.Notes {
+		infof("Note: %s\n", note)
+	}
+
+	return exitcode.Success
+}
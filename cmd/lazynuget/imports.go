@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/audit"
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/project"
+)
+
+// sdkLookupTimeout bounds asking dotnet where its SDK is.
+const sdkLookupTimeout = 10 * time.Second
+
+// runImports implements `lazynuget imports [--package ID] [--json] [PROJECT]`.
+func runImports(_ *cli.Command, values *cli.Values) int {
+	name := ""
+	if args := values.Args(); len(args) > 0 {
+		name = args[0]
+	}
+	path, exitCode := bulkProject(name)
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+
+	sdkDir := ""
+	if platform.DotnetAvailable() {
+		ctx, cancel := context.WithTimeout(context.Background(), sdkLookupTimeout)
+		sdkDir, _ = platform.DotnetSDKDir(ctx, filepath.Dir(path)) // Without it the SDK's files are marked missing
+		cancel()
+	}
+	chain, err := project.ImportChain(path, sdkDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+
+	id := values.String("package")
+	if id == "" {
+		if values.Bool("json") {
+			return writeJSON(project.ImportsKind, map[string]any{"project": path, "imports": chain})
+		}
+		for _, imp := range chain {
+			line := fmt.Sprintf("%-11s%s", strings.Repeat("  ", imp.Depth)+imp.Kind, importPath(imp))
+			if summary := definitionSummary(imp.Definitions); summary != "" {
+				line += "  (" + summary + ")"
+			}
+			fmt.Println(line)
+		}
+		return exitcode.Success
+	}
+
+	traced := project.Trace(chain, id)
+	var resolved []string
+	for _, p := range audit.Inventory([]string{path}) {
+		if strings.EqualFold(p.ID, id) && !slices.Contains(resolved, p.Version) {
+			resolved = append(resolved, p.Version)
+		}
+	}
+	if values.Bool("json") {
+		return writeJSON(project.ImportsKind, map[string]any{"project": path, "package": id, "imports": traced, "resolved": resolved})
+	}
+	if len(traced) == 0 {
+		infof("No file %s imports defines %s\n", displayPath(path), id)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, imp := range traced {
+		for _, d := range imp.Definitions {
+			fmt.Fprintf(tw, "%s:%d\t%s\n", displayPath(imp.Path), d.Line, describeDefinition(d))
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return exitcode.SystemError
+	}
+	if len(resolved) > 0 {
+		fmt.Printf("Restore resolved %s %s\n", id, strings.Join(resolved, ", "))
+	}
+	return exitcode.Success
+}
+
+// importPath describes where an import is, marking imports that are not there.
+func importPath(imp project.Import) string {
+	s := displayPath(imp.Path)
+	switch {
+	case imp.Path == "" && imp.Kind == project.ImportSDK:
+		s = imp.Project + " (the .NET SDK was not found)"
+	case imp.Path == "":
+		s = imp.Project + " (unresolved)"
+	case imp.Missing:
+		s += " (not found)"
+	case imp.Duplicate:
+		s += " (imported already; skipped)"
+	}
+	if imp.Condition != "" {
+		s += " when " + imp.Condition
+	}
+	return s
+}
+
+// definitionSummary counts what a file defines ("3 package references, 2 version
+// properties").
+func definitionSummary(defs []project.Definition) string {
+	nouns := map[string]string{
+		"PackageReference":       "package reference",
+		"PackageVersion":         "package version",
+		"GlobalPackageReference": "global package reference",
+		"property":               "version property",
+	}
+	counts := make(map[string]int)
+	var order []string
+	for _, d := range defs {
+		noun, ok := nouns[d.Kind]
+		if !ok {
+			noun = "package item"
+		}
+		if counts[noun] == 0 {
+			order = append(order, noun)
+		}
+		counts[noun]++
+	}
+	parts := make([]string, 0, len(order))
+	for _, noun := range order {
+		if counts[noun] == 1 {
+			parts = append(parts, "1 "+noun)
+		} else {
+			parts = append(parts, fmt.Sprintf("%d %ss", counts[noun], noun))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeDefinition describes a definition on one line ("PackageVersion Serilog =
+// $(SerilogVersion)").
+func describeDefinition(d project.Definition) string {
+	s := d.Name
+	if d.Kind != "property" {
+		s = d.Kind + " " + d.Name
+		if d.Update {
+			s = d.Kind + " Update " + d.Name
+		}
+	}
+	if d.Value != "" {
+		s += " = " + d.Value
+	}
+	if d.Condition != "" {
+		s += " when " + d.Condition
+	}
+	return s
+}
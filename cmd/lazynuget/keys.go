@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/term"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/keymap"
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// errCanceled is returned by captureKeys when Esc ends the capture.
+var errCanceled = errors.New("canceled")
+
+// loadKeymap returns the user config and the keymap it gives, warning about keybindings
+// that could not be used.
+func loadKeymap() (*config.Config, *keymap.Keymap, error) {
+	cfg, err := loadUserConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	km, errs := keymap.New(cfg.KeybindingProfile, cfg.Keybindings, cfg.CustomCommands)
+	for _, err := range errs {
+		warnf("%v\n", err)
+	}
+	return cfg, km, nil
+}
+
+// runKeysList implements `lazynuget keys list [--context CONTEXT] [--json]`.
+func runKeysList(_ *cli.Command, values *cli.Values) int {
+	_, km, err := loadKeymap()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.UserError
+	}
+	bindings := km.Bindings
+	if context := values.String("context"); context != "" {
+		bindings = slices.DeleteFunc(slices.Clone(bindings), func(b keymap.Binding) bool {
+			return b.Context != context && b.Context != keymap.Global
+		})
+	}
+	conflicts := km.Conflicts()
+
+	if values.Bool("json") {
+		return writeJSON(keymap.Kind, struct {
+			Profile   string            `json:"profile"`
+			Bindings  []keymap.Binding  `json:"bindings"`
+			Conflicts []keymap.Conflict `json:"conflicts"`
+		}{km.Profile, bindings, conflicts})
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, b := range bindings {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", b.Keys, b.Action, b.Context, b.Source)
+	}
+	if err := tw.Flush(); err != nil {
+		return exitcode.SystemError
+	}
+	for _, c := range conflicts {
+		warnf("%s\n", c)
+	}
+	return exitcode.Success
+}
+
+// runKeysSet implements `lazynuget keys set [--context CONTEXT] [--unbind] [--force]
+// ACTION [KEYS...]`.
+func runKeysSet(_ *cli.Command, values *cli.Values) int {
+	args := values.Args()
+	cfg, km, err := loadKeymap()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.UserError
+	}
+	action, context, exitCode := keyAction(args[0], values.String("context"))
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+
+	var keys string
+	switch {
+	case values.Bool("unbind") && len(args) > 1:
+		fmt.Fprintf(os.Stderr, "Error: --unbind takes no keys\n")
+		return exitcode.UserError
+	case values.Bool("unbind"):
+	case len(args) == 1:
+		fmt.Fprintf(os.Stderr, "Error: no keys given for %s (use --unbind to remove its keys)\n", action)
+		return exitcode.UserError
+	default:
+		keys, err = keymap.ParseSequence(strings.Join(args[1:], " "))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.UserError
+		}
+		if conflicts := km.ConflictsWith(action, context, keys); len(conflicts) > 0 {
+			for _, c := range conflicts {
+				fmt.Fprintf(os.Stderr, "Conflict: %s\n", c)
+			}
+			if !values.Bool("force") {
+				fmt.Fprintf(os.Stderr, "Error: %q conflicts with other keys; rebind those first, or use --force\n", keys)
+				return exitcode.UserError
+			}
+		}
+	}
+	return saveKeybinding(cfg, action, context, keys)
+}
+
+// runKeysEdit implements `lazynuget keys edit [--context CONTEXT] [ACTION]`: pick an
+// action, press its new keys while conflicts show as they are typed, and save them.
+func runKeysEdit(_ *cli.Command, values *cli.Values) int {
+	if !platform.IsStdinTerminal() || !platform.IsStdoutTerminal() {
+		fmt.Fprintf(os.Stderr, "Error: keys edit needs a terminal (use `lazynuget keys set`)\n")
+		return exitcode.UserError
+	}
+	cfg, km, err := loadKeymap()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.UserError
+	}
+	in := bufio.NewReader(os.Stdin)
+
+	name := ""
+	if args := values.Args(); len(args) > 0 {
+		name = args[0]
+	} else {
+		tw := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', 0)
+		for i, a := range keymap.Actions {
+			fmt.Fprintf(tw, "%3d) %s\t%s\t%s\n", i+1, a.Name, strings.Join(km.Keys(a.Name), ", "), a.Description)
+		}
+		if err := tw.Flush(); err != nil {
+			return exitcode.SystemError
+		}
+		answer, exitCode := ask(in, "Action to rebind (number or name)", "action")
+		if exitCode != exitcode.Success {
+			return exitCode
+		}
+		name = answer
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(keymap.Actions) {
+			name = keymap.Actions[n-1].Name
+		}
+	}
+	action, context, exitCode := keyAction(name, values.String("context"))
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+
+	fmt.Fprintf(os.Stderr, "Press the new keys for %s in %s (Enter saves, Esc cancels, Backspace removes a key)\n", action, context)
+	keys, err := captureKeys(os.Stdin, os.Stderr, func(keys string) []string {
+		var lines []string
+		for _, c := range km.ConflictsWith(action, context, keys) {
+			lines = append(lines, "  conflict: "+c.String())
+		}
+		return lines
+	})
+	switch {
+	case errors.Is(err, errCanceled):
+		infof("Canceled; %s keeps %s\n", action, strings.Join(km.Keys(action), ", "))
+		return exitcode.Success
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	case keys == "":
+		infof("No keys pressed; %s keeps %s\n", action, strings.Join(km.Keys(action), ", "))
+		return exitcode.Success
+	}
+
+	if conflicts := km.ConflictsWith(action, context, keys); len(conflicts) > 0 {
+		answer, exitCode := ask(in, fmt.Sprintf("Save %q with %d conflict(s)? [y/N]", keys, len(conflicts)), "answer")
+		if exitCode != exitcode.Success || !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			infof("Not saved\n")
+			return exitcode.UserError
+		}
+	}
+	return saveKeybinding(cfg, action, context, keys)
+}
+
+// keyAction checks the action a keybinding is for and returns it with its context: the
+// one given, or the action's own.
+func keyAction(name, context string) (action, actionContext string, exitCode int) {
+	i := slices.IndexFunc(keymap.Actions, func(a keymap.Action) bool { return a.Name == name })
+	if i < 0 {
+		if strings.HasPrefix(name, keymap.CustomActionPrefix) {
+			fmt.Fprintf(os.Stderr, "Error: custom commands set their key in customCommands\n")
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: unknown action %q (see `lazynuget keys list`)\n", name)
+		}
+		return "", "", exitcode.UserError
+	}
+	if context == "" {
+		return name, keymap.Actions[i].Context, exitcode.Success
+	}
+	if !slices.Contains(config.CustomCommandContexts, context) {
+		fmt.Fprintf(os.Stderr, "Error: unknown context %q (want %s)\n", context, strings.Join(config.CustomCommandContexts, ", "))
+		return "", "", exitcode.UserError
+	}
+	return name, context, exitcode.Success
+}
+
+// saveKeybinding saves keybindings.<action> to the user config file; empty keys unbind
+// the action.
+func saveKeybinding(cfg *config.Config, action, context, keys string) int {
+	path, err := config.UserConfigFile(cfg.LoadedFrom)
+	if err == nil {
+		err = config.SetKeybinding(path, action, config.KeyBinding{Key: keys, Context: context})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.UserError
+	}
+	if keys == "" {
+		infof("Unbound %s in %s\n", action, path)
+	} else {
+		infof("Bound %s to %q in %s\n", action, keys, path)
+	}
+	return exitcode.Success
+}
+
+// captureKeys reads keys from a terminal in raw mode until Enter, showing the sequence
+// typed so far and the lines describe returns for it (the conflicts) as it changes.
+func captureKeys(in *os.File, out io.Writer, describe func(keys string) []string) (string, error) {
+	fd := int(in.Fd()) // #nosec G115 -- file descriptors fit in int
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = term.Restore(fd, state) }()
+
+	var keys []string
+	shown := 0 // Lines drawn last time, redrawn in place
+	draw := func() {
+		seq := strings.Join(keys, " ")
+		lines := []string{"keys: " + seq}
+		if seq != "" {
+			lines = append(lines, describe(seq)...)
+		}
+		if shown > 1 {
+			fmt.Fprintf(out, "\x1b[%dA", shown-1)
+		}
+		fmt.Fprint(out, "\r\x1b[J"+strings.Join(lines, "\r\n"))
+		shown = len(lines)
+	}
+	draw()
+	defer fmt.Fprint(out, "\r\n")
+
+	var pending []byte
+	buf := make([]byte, 64)
+	for {
+		n, err := in.Read(buf)
+		if err != nil {
+			return "", err
+		}
+		pending = append(pending, buf[:n]...)
+		for len(pending) > 0 {
+			key, size := keymap.Decode(pending)
+			if size == 0 {
+				break // The rest of a sequence is still to come
+			}
+			pending = pending[size:]
+			switch key {
+			case "":
+			case "enter":
+				return strings.Join(keys, " "), nil
+			case "esc", "ctrl+c":
+				return "", errCanceled
+			case "backspace":
+				if len(keys) > 0 {
+					keys = keys[:len(keys)-1]
+				}
+			default:
+				keys = append(keys, key)
+			}
+		}
+		draw()
+	}
+}
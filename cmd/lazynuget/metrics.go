@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/metrics"
+)
+
+// runMetricsDump implements `lazynuget metrics dump`.
+func runMetricsDump(_ *cli.Command, values *cli.Values) int {
+	addr := values.String("addr")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+metrics.Path, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid address %q: %v\n", addr, err)
+		return exitcode.UserError
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: no metrics endpoint at %s (start lazynuget with --metrics-addr %s): %v\n", addr, addr, err)
+		return exitcode.UserError
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "Error: metrics endpoint returned %s\n", resp.Status)
+		return exitcode.SystemError
+	}
+	if _, err := io.Copy(os.Stdout, resp.Body); err != nil {
+		return exitcode.SystemError
+	}
+	return exitcode.Success
+}
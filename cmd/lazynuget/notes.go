@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/notes"
+)
+
+// openNotes opens the notes of the workspace the working directory is in.
+func openNotes() (store *notes.Store, workspace string, code int) {
+	workspace, code = workspaceRoot()
+	if code != exitcode.Success {
+		return nil, "", code
+	}
+	dir := notes.DefaultDir()
+	if dir == "" {
+		fmt.Fprintln(os.Stderr, "Error: no config directory to keep notes in")
+		return nil, "", exitcode.SystemError
+	}
+	store, err := notes.Open(dir, workspace)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return nil, "", exitcode.SystemError
+	}
+	return store, workspace, exitcode.Success
+}
+
+// noteID parses the note number argument.
+func noteID(arg string) (int, int) {
+	id, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err != nil || id < 1 {
+		fmt.Fprintf(os.Stderr, "Error: invalid note number %q\n", arg)
+		return 0, exitcode.UserError
+	}
+	return id, exitcode.Success
+}
+
+// runNotesList implements `lazynuget notes list [--package ID] [--json]`.
+func runNotesList(_ *cli.Command, values *cli.Values) int {
+	store, workspace, code := openNotes()
+	if code != exitcode.Success {
+		return code
+	}
+	list := store.Notes()
+	if pkg := values.String("package"); pkg != "" {
+		filtered := list[:0]
+		for _, n := range list {
+			if strings.EqualFold(n.Package, pkg) {
+				filtered = append(filtered, n)
+			}
+		}
+		list = filtered
+	}
+
+	if values.Bool("json") {
+		return writeJSON(notes.Kind, struct {
+			Workspace string       `json:"workspace"`
+			Notes     []notes.Note `json:"notes"`
+		}{workspace, list})
+	}
+	if len(list) == 0 {
+		infof("No notes for %s; add one with lazynuget notes add\n", displayPath(workspace))
+		return exitcode.Success
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, n := range list {
+		check := "[ ]"
+		if n.Done {
+			check = "[x]"
+		}
+		// Only the first line fits the table; export shows the rest
+		text, _, more := strings.Cut(n.Text, "\n")
+		if more {
+			text += " …"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", n.ID, check, n.Package, text)
+	}
+	if err := tw.Flush(); err != nil {
+		return exitcode.SystemError
+	}
+	return exitcode.Success
+}
+
+// runNotesAdd implements `lazynuget notes add [--package ID] TEXT...`.
+func runNotesAdd(_ *cli.Command, values *cli.Values) int {
+	store, _, code := openNotes()
+	if code != exitcode.Success {
+		return code
+	}
+	n, err := store.Add(strings.Join(values.Args(), " "), values.String("package"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.UserError
+	}
+	infof("Added note %d\n", n.ID)
+	return exitcode.Success
+}
+
+// runNotesDone implements `lazynuget notes done [--undo] N...`.
+func runNotesDone(_ *cli.Command, values *cli.Values) int {
+	return editNotes(values.Args(), func(store *notes.Store, id int) error {
+		return store.SetDone(id, !values.Bool("undo"))
+	})
+}
+
+// runNotesRemove implements `lazynuget notes remove N...`.
+func runNotesRemove(_ *cli.Command, values *cli.Values) int {
+	return editNotes(values.Args(), (*notes.Store).Remove)
+}
+
+// editNotes applies an edit to each note numbered in args, checking every number first
+// so a typo changes nothing.
+func editNotes(args []string, edit func(*notes.Store, int) error) int {
+	ids := make([]int, 0, len(args))
+	for _, arg := range args {
+		id, code := noteID(arg)
+		if code != exitcode.Success {
+			return code
+		}
+		ids = append(ids, id)
+	}
+	store, _, code := openNotes()
+	if code != exitcode.Success {
+		return code
+	}
+	for _, id := range ids {
+		if err := edit(store, id); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.UserError
+		}
+	}
+	return exitcode.Success
+}
+
+// runNotesClear implements `lazynuget notes clear [--all]`.
+func runNotesClear(_ *cli.Command, values *cli.Values) int {
+	store, _, code := openNotes()
+	if code != exitcode.Success {
+		return code
+	}
+	removed, err := store.Clear(values.Bool("all"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	infof("Removed %d notes\n", removed)
+	return exitcode.Success
+}
+
+// runNotesExport implements `lazynuget notes export [--title TEXT] [FILE]`.
+func runNotesExport(_ *cli.Command, values *cli.Values) int {
+	args := values.Args()
+	store, workspace, code := openNotes()
+	if code != exitcode.Success {
+		return code
+	}
+	title := values.String("title")
+	if title == "" {
+		title = "Notes for " + filepath.Base(workspace)
+	}
+
+	if len(args) == 0 || args[0] == "-" {
+		if err := notes.WriteMarkdown(os.Stdout, title, store.Notes()); err != nil {
+			return exitcode.SystemError
+		}
+		return exitcode.Success
+	}
+	var b strings.Builder
+	if err := notes.WriteMarkdown(&b, title, store.Notes()); err != nil {
+		return exitcode.SystemError
+	}
+	if err := os.WriteFile(args[0], []byte(b.String()), 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write %s: %v\n", args[0], err)
+		return exitcode.UserError
+	}
+	infof("Wrote %s\n", args[0])
+	return exitcode.Success
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/ci"
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/outdated"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/project"
+	"github.com/willibrandon/lazynuget/internal/query"
+	"github.com/willibrandon/lazynuget/internal/resolver"
+	"github.com/willibrandon/lazynuget/internal/screen"
+)
+
+// runOutdated implements `lazynuget outdated [--prerelease] [--offline] [--filter FILTER]
+// [--report-format FORMAT] [--report-out FILE] [PROJECT...]`.
+func runOutdated(_ *cli.Command, values *cli.Values) int {
+	target, exitCode := reportOptions(values)
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+	var filter *query.Filter
+	if value := values.String("filter"); value != "" {
+		if filter, exitCode = resolveFilter(value); exitCode != exitcode.Success {
+			return exitCode
+		}
+		if filter.NeedsAdvisories() && values.Bool("offline") {
+			fmt.Fprintf(os.Stderr, "Error: --filter %q needs advisories from the feed; drop --offline\n", value)
+			return exitcode.UserError
+		}
+	}
+	paths := values.Args()
+	if len(paths) == 0 {
+		var exitCode int
+		if paths, exitCode = workspaceProjects(); exitCode != exitcode.Success {
+			return exitCode
+		}
+	}
+
+	opts := outdated.Options{Prerelease: values.Bool("prerelease")}
+	offline := values.Bool("offline")
+	packagesDir := nuget.GlobalPackagesDir()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	feed := nuget.NewFeed()
+	if !offline {
+		var err error
+		if feed, err = sourceFeed(ctx, values.String("source")); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+	}
+
+	// Projects in one repository share most packages; list each package's versions once
+	versions := make(map[string][]string)
+	available := func(id string) ([]string, error) {
+		key := strings.ToLower(id)
+		if v, ok := versions[key]; ok {
+			return v, nil
+		}
+		if offline {
+			versions[key] = nuget.LocalVersions(packagesDir, id)
+			return versions[key], nil
+		}
+		v, err := feed.Versions(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		versions[key] = v
+		return v, nil
+	}
+
+	// Read every project first, so a terminal can show all of them while versions load
+	var projects []outdatedProject
+	for p, err := range loadProjects(paths) {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+		path := p.Path
+
+		// Without an assets file, results show what restore would resolve
+		assets, _ := resolver.LoadAssets(resolver.AssetsPath(path))
+
+		checked := outdatedProject{path: path}
+		for _, ref := range p.PackageReferences {
+			// The SDK provides shared frameworks and runtime packs; the feed has no say
+			if nuget.IsPlatformPackage(ref.ID) {
+				continue
+			}
+			r := outdatedRef{id: ref.ID, requested: ref.Version}
+			if r.requested == "" {
+				r.requested = project.CentralVersion(path, ref.ID)
+			}
+			if assets != nil {
+				r.resolved = assets.ResolvedVersion(ref.ID)
+			}
+			checked.refs = append(checked.refs, r)
+		}
+		projects = append(projects, checked)
+	}
+
+	if !offline {
+		// Filters drop rows, so their tables cannot be drawn before versions are known
+		draw := filter == nil && !target.toStdout() && verbosity != cli.VerbosityQuiet
+		if err := listOutdatedVersions(ctx, feed, projects, opts, versions, draw); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+	}
+
+	var checks []ci.Check
+	advisories := make(map[string][]nuget.Advisory)
+	for _, p := range projects {
+		var results []outdated.Result
+		for _, ref := range p.refs {
+			list, err := available(ref.id)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return exitcode.SystemError
+			}
+			results = append(results, outdated.Check(ref.id, ref.requested, ref.resolved, list, opts))
+		}
+		if filter != nil {
+			var err error
+			if results, err = filterResults(ctx, filter, feed, advisories, p.path, results); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return exitcode.SystemError
+			}
+			if len(results) == 0 {
+				continue // Only projects with a match are listed
+			}
+		}
+
+		for _, r := range results {
+			checks = append(checks, r.CICheck(p.path))
+		}
+		if !target.toStdout() {
+			fmt.Println(displayPath(p.path))
+			printOutdated(results)
+		}
+	}
+	annotate("outdated", outdated.Rules, checks, target.toStdout())
+	if target != nil {
+		return target.write("outdated", outdated.Rules, checks)
+	}
+	return exitcode.Success
+}
+
+// outdatedProject is a project whose package references are checked.
+type outdatedProject struct {
+	path string
+	refs []outdatedRef
+}
+
+// outdatedRef is a package reference to check.
+type outdatedRef struct {
+	id        string
+	requested string
+	resolved  string // "" without an assets file
+}
+
+// listOutdatedVersions lists the versions of every referenced package into versions,
+// maxConcurrentOps at a time. With draw, on a terminal, the tables are drawn on the
+// alternate screen meanwhile, with placeholders in the Latest and status columns that fill
+// in as versions arrive; the caller prints the finished tables once the terminal is given
+// back.
+func listOutdatedVersions(ctx context.Context, feed *nuget.Feed, projects []outdatedProject, opts outdated.Options, versions map[string][]string, draw bool) error {
+	ids := make(map[string]string) // Lowercase ID → ID as first referenced
+	for _, p := range projects {
+		for _, ref := range p.refs {
+			if key := strings.ToLower(ref.id); ids[key] == "" {
+				ids[key] = ref.id
+			}
+		}
+	}
+
+	var mu sync.Mutex
+	view := func(_, _ int) []string {
+		mu.Lock()
+		defer mu.Unlock()
+		var lines []string
+		for _, p := range projects {
+			lines = append(lines, displayPath(p.path))
+			rows := make([]outdatedRow, len(p.refs))
+			for i, ref := range p.refs {
+				rows[i].ref = ref
+				if list, ok := versions[strings.ToLower(ref.id)]; ok {
+					result := outdated.Check(ref.id, ref.requested, ref.resolved, list, opts)
+					rows[i].result = &result
+				}
+			}
+			lines = append(lines, outdatedLines(rows)...)
+		}
+		return lines
+	}
+
+	var s *screen.Screen
+	if draw {
+		console, restore := platform.EnableVirtualTerminal()
+		defer restore()
+		if console.AltScreen() {
+			s = screen.New(os.Stdout, view, true)
+			s.LimitFrameRate(renderFPS())
+			width, height, _ := platform.TerminalSize()
+			if err := s.Start(width, height); err != nil {
+				return err
+			}
+			defer func() { _ = s.Stop() }()
+			stopResize := platform.NewTerminalCapabilities().WatchResize(func(width, height int) {
+				_ = s.Resize(width, height)
+			})
+			defer stopResize()
+		}
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentOps())
+	for key, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+			list, err := feed.Versions(ctx, id)
+			if err != nil {
+				cancel(err)
+				return
+			}
+			mu.Lock()
+			versions[key] = list
+			mu.Unlock()
+			if s != nil {
+				_ = s.Redraw()
+			}
+		}()
+	}
+	wg.Wait()
+	return context.Cause(ctx)
+}
+
+// outdatedRow is a row of the outdated table: a reference and, once its versions are
+// known, its result.
+type outdatedRow struct {
+	ref    outdatedRef
+	result *outdated.Result // Nil while loading
+}
+
+// printOutdated prints a table of results with a status note per reference.
+func printOutdated(results []outdated.Result) {
+	rows := make([]outdatedRow, len(results))
+	for i := range results {
+		rows[i] = outdatedRow{ref: outdatedRef{id: results[i].Package, requested: results[i].Requested, resolved: results[i].Resolved}, result: &results[i]}
+	}
+	for _, line := range outdatedLines(rows) {
+		fmt.Println(line)
+	}
+}
+
+// outdatedLines returns the table of a project's references; rows still loading show
+// placeholders for the latest version and the status note.
+func outdatedLines(rows []outdatedRow) []string {
+	if len(rows) == 0 {
+		return []string{"  (no package references)"}
+	}
+
+	table := screen.Table{
+		Header:  []string{"Package", "Requested", "Resolved", "Latest", ""},
+		Indent:  "  ",
+		Unicode: platform.NewTerminalCapabilities().SupportsUnicode(),
+	}
+	for _, row := range rows {
+		r := row.result
+		if r == nil {
+			// Without an assets file, the resolved version also depends on the feed
+			resolved := screen.Text(row.ref.resolved)
+			if row.ref.resolved == "" {
+				resolved = screen.Pending()
+			}
+			table.Rows = append(table.Rows, []screen.Cell{
+				screen.Text(row.ref.id), screen.Text(row.ref.requested), resolved, screen.Pending(), screen.Pending(),
+			})
+			continue
+		}
+		var note string
+		switch r.Status {
+		case outdated.StatusCurrent:
+			note = "up to date"
+		case outdated.StatusOutdated:
+			note = "update available"
+			if r.Floating {
+				note = "update available (outside the floating range)"
+			}
+		case outdated.StatusRestore:
+			note = "restore to update (within the floating range)"
+		default:
+			note = r.Reason
+		}
+		table.Rows = append(table.Rows, []screen.Cell{
+			screen.Text(r.Package), screen.Text(r.Requested), screen.Text(r.Resolved), screen.Text(r.Latest), screen.Text(note),
+		})
+	}
+	return table.Lines()
+}
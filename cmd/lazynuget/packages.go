@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/plugin"
+	"github.com/willibrandon/lazynuget/internal/project"
+	"github.com/willibrandon/lazynuget/internal/resolver"
+	"github.com/willibrandon/lazynuget/internal/usage"
+)
+
+// runPackagesList implements `lazynuget packages list [PROJECT...]`.
+func runPackagesList(_ *cli.Command, values *cli.Values) int {
+	paths := values.Args()
+	if len(paths) == 0 {
+		var exitCode int
+		if paths, exitCode = workspaceProjects(); exitCode != exitcode.Success {
+			return exitCode
+		}
+	}
+
+	annotate, closePlugins := packageAnnotator()
+	defer closePlugins()
+	packagesDir := nuget.GlobalPackagesDir()
+	for p, err := range loadProjects(paths) {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+		path := p.Path
+
+		fmt.Println(displayPath(path))
+		if len(p.PackageReferences) == 0 && len(p.FrameworkReferences) == 0 {
+			fmt.Println("  (no package references)")
+			warnDrift(path)
+			continue
+		}
+		annotations := annotate(path, p.PackageReferences)
+
+		var runtime, development, platform []string
+		idWidth, versionWidth := 0, 0
+		for _, ref := range p.PackageReferences {
+			idWidth = max(idWidth, len(ref.ID))
+			versionWidth = max(versionWidth, len(ref.Version))
+		}
+		for _, ref := range p.FrameworkReferences {
+			idWidth = max(idWidth, len(ref.ID))
+		}
+		for _, ref := range p.PackageReferences {
+			line := fmt.Sprintf("    %-*s  %-*s", idWidth, ref.ID, versionWidth, ref.Version) + conditionNote(p, ref.Condition)
+
+			class := project.Classify(ref, packagesDir)
+			if class.Category == project.CategoryDevelopment {
+				line += "  (" + class.Reason + ")"
+			}
+			if notes := annotations[strings.ToLower(ref.ID)]; len(notes) > 0 {
+				line += "  [" + strings.Join(notes, "; ") + "]"
+			}
+			switch class.Category {
+			case project.CategoryDevelopment:
+				development = append(development, line)
+			case project.CategoryPlatform:
+				platform = append(platform, strings.TrimRight(line, " "))
+			default:
+				runtime = append(runtime, strings.TrimRight(line, " "))
+			}
+		}
+		for _, ref := range p.FrameworkReferences {
+			line := fmt.Sprintf("    %-*s  %-*s", idWidth, ref.ID, versionWidth, "") + conditionNote(p, ref.Condition)
+			platform = append(platform, line+"  (framework reference)")
+		}
+
+		printSection("Dependencies", runtime)
+		printSection("Analyzers and build tools", development)
+		printSection("Platform dependencies (provided by the .NET SDK)", platform)
+		warnDrift(path)
+	}
+	return exitcode.Success
+}
+
+// warnDrift warns when a restored project's package references no longer match its
+// assets file, so what is listed is not what builds until it is restored again.
+func warnDrift(path string) {
+	drift, err := resolver.CheckDrift(path)
+	if err != nil || len(drift) == 0 || drift[0].Kind == resolver.DriftUnrestored {
+		return
+	}
+	warnf("%s needs a restore:\n", displayPath(path))
+	for _, d := range drift {
+		infof("  %s\n", d)
+	}
+}
+
+// conditionNote describes when a reference applies: in a multi-targeting project, the
+// target frameworks its condition selects; otherwise, or when the condition cannot be
+// evaluated or selects them all, the condition itself.
+func conditionNote(p *project.Project, condition string) string {
+	if condition == "" {
+		return ""
+	}
+	if frameworks, ok := p.ConditionFrameworks(condition); ok && p.MultiTargeting() {
+		switch len(frameworks) {
+		case 0:
+			return "  (no target framework)"
+		case len(p.TargetFrameworks):
+			// Every framework; the condition tests something else
+		default:
+			return "  (" + strings.Join(frameworks, ", ") + " only)"
+		}
+	}
+	return "  when " + condition
+}
+
+// packageAnnotator returns a function that collects the annotations plugins attach to a
+// project's package references, keyed by lowercase package ID, and a function that stops
+// the plugins. Plugins start on first use; failures are reported once as warnings.
+func packageAnnotator() (func(projectPath string, refs []project.PackageReference) map[string][]string, func()) {
+	none := func(string, []project.PackageReference) map[string][]string { return nil }
+	root, exitCode := workspaceRoot()
+	if exitCode != exitcode.Success {
+		return none, func() {}
+	}
+	m, err := pluginManager(root)
+	if err != nil {
+		warnf("%v\n", err)
+		return none, func() {}
+	}
+	if len(m.Names()) == 0 {
+		return none, func() {}
+	}
+
+	warned := false
+	background := platform.DetectBackground(platform.BackgroundQueryTimeout)
+	return func(projectPath string, refs []project.PackageReference) map[string][]string {
+		packages := make([]plugin.PackageRef, len(refs))
+		for i, ref := range refs {
+			packages[i] = plugin.PackageRef{ID: ref.ID, Version: ref.Version}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		annotations, err := m.Annotate(ctx, plugin.Context{Workspace: root, Project: projectPath, Background: background}, packages)
+		if err != nil && !warned {
+			warnf("%v\n", err)
+			warned = true
+		}
+		return annotations
+	}, func() { _ = m.Close() }
+}
+
+// printSection prints a titled list of lines, or nothing when it is empty.
+func printSection(title string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Printf("  %s\n", title)
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
+
+// runPackagesUsage implements `lazynuget packages usage PACKAGE [PROJECT...]`.
+func runPackagesUsage(_ *cli.Command, values *cli.Values) int {
+	args := values.Args()
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: expected a package ID")
+		return exitcode.UserError
+	}
+	id, paths := args[0], args[1:]
+	explicit := len(paths) > 0
+	if !explicit {
+		var exitCode int
+		if paths, exitCode = workspaceProjects(); exitCode != exitcode.Success {
+			return exitCode
+		}
+	}
+
+	packagesDir := nuget.GlobalPackagesDir()
+	searched := 0
+	for p, err := range loadProjects(paths) {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+		path := p.Path
+		ref, ok := findReference(p, id)
+		if !ok && !explicit {
+			continue
+		}
+		searched++
+
+		version := ""
+		if ok {
+			version = installedVersion(path, ref, packagesDir)
+		}
+		namespaces := nuget.Namespaces(packagesDir, id, version)
+		matches, err := usage.Search(os.DirFS(filepath.Dir(path)), namespaces)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+
+		fmt.Printf("%s (namespaces: %s)\n", displayPath(path), strings.Join(namespaces, ", "))
+		if ok && project.Classify(ref, packagesDir).Category == project.CategoryDevelopment {
+			fmt.Println("  Build-only reference: source code is not expected to use it")
+		}
+		if len(matches) == 0 {
+			fmt.Printf("  No source files appear to use %s\n", id)
+			continue
+		}
+
+		files := make(map[string]bool)
+		for _, m := range matches {
+			files[m.File] = true
+			file := displayPath(filepath.Join(filepath.Dir(path), filepath.FromSlash(m.File)))
+			fmt.Printf("  %s:%d  %s\n", file, m.Line, m.Text)
+		}
+		if len(files) == 1 {
+			fmt.Printf("  Warning: 1 file likely depends on %s\n", id)
+		} else {
+			fmt.Printf("  Warning: %d files likely depend on %s\n", len(files), id)
+		}
+	}
+
+	if searched == 0 {
+		fmt.Fprintf(os.Stderr, "No project references %s\n", id)
+		return exitcode.UserError
+	}
+	return exitcode.Success
+}
+
+// findReference returns a project's reference to a package.
+func findReference(p *project.Project, id string) (project.PackageReference, bool) {
+	for _, ref := range p.PackageReferences {
+		if strings.EqualFold(ref.ID, id) {
+			return ref, true
+		}
+	}
+	return project.PackageReference{}, false
+}
+
+// installedVersion returns the restored version of a reference: the one in the assets
+// file, or the installed version matching the requested (or central) version.
+func installedVersion(projectPath string, ref project.PackageReference, packagesDir string) string {
+	if assets, err := resolver.LoadAssets(resolver.AssetsPath(projectPath)); err == nil {
+		if v := assets.ResolvedVersion(ref.ID); v != "" {
+			return v
+		}
+	}
+	requested := ref.Version
+	if requested == "" {
+		requested = project.CentralVersion(projectPath, ref.ID)
+	}
+	return nuget.InstalledVersion(packagesDir, ref.ID, requested)
+}
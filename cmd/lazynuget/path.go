@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/willibrandon/lazynuget/internal/bootstrap"
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// pathResolvers maps the `lazynuget path` subcommands to the directory they print.
+var pathResolvers = map[string]func(platform.PathResolver) (string, error){
+	"config": platform.PathResolver.ConfigDir,
+	"cache":  platform.PathResolver.CacheDir,
+	"data":   dataDir,
+	"logs":   logsDir,
+	"state":  stateDir,
+}
+
+// runPath implements `lazynuget path config|cache|data|logs|state [--open]`.
+func runPath(cmd *cli.Command, values *cli.Values) int {
+	info, err := platform.New()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	resolver, err := platform.NewPathResolver(info)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	dir, err := pathResolvers[cmd.Name](resolver)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: cannot determine the %s directory: %v\n", cmd.Name, err)
+		return exitcode.SystemError
+	}
+	fmt.Println(dir)
+
+	if !values.Bool("open") {
+		return exitcode.Success
+	}
+	// The directory may not have been written to yet
+	if err := resolver.EnsureDir(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	if err := platform.OpenInFileManager(dir); err != nil {
+		if errors.Is(err, platform.ErrNoFileManager) {
+			fmt.Fprintf(os.Stderr, "Error: %v; open the path above yourself\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		return exitcode.SystemError
+	}
+	return exitcode.Success
+}
+
+// logsDir returns the log directory: logDir from the user config, or the platform default.
+func logsDir(_ platform.PathResolver) (string, error) {
+	if cfg, err := loadUserConfig(); err == nil && cfg.LogDir != "" {
+		return cfg.LogDir, nil
+	}
+	if dir := bootstrap.DefaultLogDir(); dir != "" {
+		return dir, nil
+	}
+	return "", errors.New("no cache directory")
+}
+
+// dataDir returns the directory of notes, search history, telemetry consent, and the
+// update check, with the same temp fallback the app applies.
+func dataDir(_ platform.PathResolver) (string, error) {
+	dir := platform.AppDir(platform.DirData)
+	if dir == "" {
+		return "", errors.New("no data directory")
+	}
+	return dir, nil
+}
+
+// stateDir returns the directory of repository config trust decisions, with the same temp
+// fallback the app applies.
+func stateDir(_ platform.PathResolver) (string, error) {
+	dir := platform.AppDir(platform.DirState)
+	if dir == "" {
+		return "", errors.New("no state directory")
+	}
+	return dir, nil
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/plugin"
+	"github.com/willibrandon/lazynuget/internal/policy"
+	"github.com/willibrandon/lazynuget/internal/screen"
+)
+
+// pluginManager returns a manager for the plugins in the user config, running them in
+// root. It fails when the machine policy restricts custom commands.
+func pluginManager(root string) (*plugin.Manager, error) {
+	cfg, err := loadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+	p, err := policy.LoadMachinePolicy()
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Plugins) > 0 {
+		if err := p.Check(policy.CapabilityCustomCommands); err != nil {
+			return nil, fmt.Errorf("plugins not run: %w", err)
+		}
+	}
+	return plugin.NewManager(cfg, plugin.Options{WorkDir: root}), nil
+}
+
+// pluginContext returns the selection given by the --project, --package, and --version
+// flags, and the terminal background.
+func pluginContext(root string, values *cli.Values) plugin.Context {
+	return plugin.Context{
+		Workspace:  root,
+		Project:    values.String("project"),
+		Package:    values.String("package"),
+		Version:    values.String("version"),
+		Background: platform.DetectBackground(platform.BackgroundQueryTimeout),
+	}
+}
+
+// runPluginList implements `lazynuget plugin list`.
+func runPluginList(_ *cli.Command, _ *cli.Values) int {
+	root, exitCode := workspaceRoot()
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+	m, err := pluginManager(root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return failureCode(err, exitcode.UserError)
+	}
+	defer m.Close()
+
+	names := m.Names()
+	if len(names) == 0 {
+		fmt.Println("No plugins configured (see the plugins section of the config file)")
+		return exitcode.Success
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	failed := 0
+	for _, name := range names {
+		c, err := m.Client(ctx, name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s (%s %s)\n", name, c.Manifest.Name, c.Manifest.Version)
+		for _, command := range c.Manifest.Commands {
+			line := fmt.Sprintf("  command  %s  %s", command.ID, command.Title)
+			if command.Description != "" {
+				line += " - " + command.Description
+			}
+			fmt.Println(line)
+		}
+		for _, panel := range c.Manifest.Panels {
+			fmt.Printf("  panel    %s  %s\n", panel.ID, panel.Title)
+		}
+		if c.Manifest.Annotations {
+			fmt.Println("  annotates packages")
+		}
+	}
+	switch {
+	case failed == len(names):
+		return exitcode.SystemError
+	case failed > 0:
+		return exitcode.PartialFailure
+	}
+	return exitcode.Success
+}
+
+// runPluginRun implements `lazynuget plugin run PLUGIN COMMAND [ARG...]`.
+func runPluginRun(_ *cli.Command, values *cli.Values) int {
+	args := values.Args()
+	if len(args) < 2 {
+		fmt.Fprintln(os.Stderr, "Error: expected a plugin and one of its commands")
+		return exitcode.UserError
+	}
+	root, exitCode := workspaceRoot()
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+	m, err := pluginManager(root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return failureCode(err, exitcode.UserError)
+	}
+	defer m.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	c, err := m.Client(ctx, args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	if !c.HasCommand(args[1]) {
+		fmt.Fprintf(os.Stderr, "Error: plugin %s has no command %q (see `lazynuget plugin list`)\n", c.Name, args[1])
+		return exitcode.UserError
+	}
+	output, err := c.RunCommand(ctx, args[1], args[2:], pluginContext(root, values))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	if output != "" {
+		fmt.Println(strings.TrimRight(output, "\n"))
+	}
+	return exitcode.Success
+}
+
+// runPluginPanel implements `lazynuget plugin panel PLUGIN PANEL`.
+func runPluginPanel(_ *cli.Command, values *cli.Values) int {
+	args := values.Args()
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "Error: expected a plugin and one of its panels")
+		return exitcode.UserError
+	}
+	width, height := 80, 24
+	if w, h, err := platform.NewTerminalCapabilities().GetSize(); err == nil {
+		width, height = w, h
+	}
+	for _, flag := range []struct {
+		name  string
+		value *int
+	}{{"width", &width}, {"height", &height}} {
+		if s := values.String(flag.name); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 {
+				fmt.Fprintf(os.Stderr, "Error: --%s must be a positive number\n", flag.name)
+				return exitcode.UserError
+			}
+			*flag.value = n
+		}
+	}
+
+	root, exitCode := workspaceRoot()
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+	m, err := pluginManager(root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return failureCode(err, exitcode.UserError)
+	}
+	defer m.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	c, err := m.Client(ctx, args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	if !c.HasPanel(args[1]) {
+		fmt.Fprintf(os.Stderr, "Error: plugin %s has no panel %q (see `lazynuget plugin list`)\n", c.Name, args[1])
+		return exitcode.UserError
+	}
+	if values.Bool("live") {
+		return livePluginPanel(c, args[1], pluginContext(root, values))
+	}
+	lines, err := c.RenderPanel(ctx, args[1], pluginContext(root, values), width, height)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	return exitcode.Success
+}
+
+// livePluginPanel draws a plugin panel over the whole terminal, rendering it again whenever
+// the terminal is resized, until interrupted.
+func livePluginPanel(c *plugin.Client, panel string, pc plugin.Context) int {
+	console, restore := platform.EnableVirtualTerminal()
+	defer restore()
+	if !console.VT {
+		fmt.Fprintln(os.Stderr, "Error: --live needs a terminal that supports escape sequences")
+		return exitcode.UserError
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	s := screen.New(os.Stdout, func(width, height int) []string {
+		renderCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+		lines, err := c.RenderPanel(renderCtx, panel, pc, width, height)
+		if err != nil {
+			return []string{"Error: " + err.Error()}
+		}
+		return lines
+	}, console.AltScreen())
+	s.LimitFrameRate(renderFPS())
+
+	width, height, _ := platform.TerminalSize()
+	if err := s.Start(width, height); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	stopResize := platform.NewTerminalCapabilities().WatchResize(func(width, height int) {
+		_ = s.Resize(width, height)
+	})
+	<-ctx.Done()
+	stopResize()
+	_ = s.Stop()
+	return exitcode.Success
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/readme"
+)
+
+// runPackagesQuickstart implements `lazynuget packages quickstart [--copy] PACKAGE [VERSION]`.
+func runPackagesQuickstart(_ *cli.Command, values *cli.Values) int {
+	args := values.Args()
+	if len(args) == 0 || len(args) > 2 {
+		fmt.Fprintln(os.Stderr, "Error: expected a package ID and an optional version")
+		return exitcode.UserError
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	feed, err := sourceFeed(ctx, values.String("source"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+
+	id, version := args[0], ""
+	if len(args) == 2 {
+		version = args[1]
+	} else {
+		versions, err := feed.Versions(ctx, id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+		if version = nuget.Latest(versions, false); version == "" {
+			version = nuget.Latest(versions, true)
+		}
+		if version == "" {
+			fmt.Fprintf(os.Stderr, "Error: package %s not found\n", id)
+			return exitcode.UserError
+		}
+	}
+
+	text, err := feed.Readme(ctx, nuget.GlobalPackagesDir(), id, version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	if text == "" {
+		fmt.Fprintf(os.Stderr, "%s %s has no README\n", id, version)
+		return exitcode.UserError
+	}
+	snippet, ok := readme.Quickstart(text)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "The README of %s %s has no recognizable setup code\n", id, version)
+		return exitcode.UserError
+	}
+
+	if !values.Bool("copy") {
+		if snippet.Heading != "" {
+			fmt.Fprintf(os.Stderr, "From \"%s\" in the README of %s %s:\n", snippet.Heading, id, version)
+		}
+		fmt.Println(snippet.Code)
+		return exitcode.Success
+	}
+	err = platform.CopyToClipboard(snippet.Code + "\n")
+	switch {
+	case errors.Is(err, platform.ErrNoClipboard) && platform.IsStdoutTerminal():
+		// Without a clipboard tool (e.g., over SSH), ask the terminal
+		fmt.Print(platform.OSC52(snippet.Code + "\n"))
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	infof("Copied the quickstart of %s %s to the clipboard\n", id, version)
+	return exitcode.Success
+}
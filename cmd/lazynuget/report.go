@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/ci"
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+)
+
+// reportTarget is where --report-format and --report-out send a CI report.
+type reportTarget struct {
+	format ci.Format
+	path   string // "-" for stdout, which replaces the text output
+}
+
+// reportOptions reads --report-format and --report-out. Without a format, it is chosen
+// by the extension of the output file (.sarif or .xml). It returns nil when no report was
+// asked for.
+func reportOptions(values *cli.Values) (*reportTarget, int) {
+	format, path := values.String("report-format"), values.String("report-out")
+	if format == "" && path == "" {
+		return nil, exitcode.Success
+	}
+	if path == "" {
+		path = "-"
+	}
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".sarif":
+			format = string(ci.FormatSARIF)
+		case ".xml":
+			format = string(ci.FormatJUnit)
+		default:
+			fmt.Fprintf(os.Stderr, "Error: --report-out %s needs --report-format (sarif or junit)\n", path)
+			return nil, exitcode.UserError
+		}
+	}
+	f, err := ci.ParseFormat(format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return nil, exitcode.UserError
+	}
+	return &reportTarget{format: f, path: path}, exitcode.Success
+}
+
+// toStdout reports whether the report replaces the text output.
+func (t *reportTarget) toStdout() bool {
+	return t != nil && t.path == "-"
+}
+
+// write writes the checks of a command as a CI report, with project paths relative to
+// the workspace root.
+func (t *reportTarget) write(name string, rules []ci.Rule, checks []ci.Check) int {
+	root, exitCode := workspaceRoot()
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+	r := &ci.Report{Name: name, Version: version, Root: root, Rules: rules, Checks: checks}
+	if err := ci.WriteFile(t.path, t.format, r); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	return exitcode.Success
+}
+
+// annotate writes the findings of a command as annotations when it runs in a GitHub
+// Actions job: to stdout, or to stderr when stdout holds JSON or a report (the runner
+// reads workflow commands from both).
+func annotate(name string, rules []ci.Rule, checks []ci.Check, machineOutput bool) {
+	if !ci.InGitHubActions(os.Getenv) {
+		return
+	}
+	root, exitCode := workspaceRoot()
+	if exitCode != exitcode.Success {
+		return
+	}
+	w := os.Stdout
+	if machineOutput {
+		w = os.Stderr
+	}
+	_ = ci.WriteAnnotations(w, &ci.Report{Name: name, Version: version, Root: root, Rules: rules, Checks: checks})
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/hooks"
+	"github.com/willibrandon/lazynuget/internal/msbuild"
+	"github.com/willibrandon/lazynuget/internal/operation"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/project"
+	"github.com/willibrandon/lazynuget/internal/resolver"
+)
+
+// runResolve implements `lazynuget resolve [--apply] [PROJECT]`.
+func runResolve(_ *cli.Command, values *cli.Values) int {
+	args := values.Args()
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	runner := hookRunner()
+	conflicts, exitCode := restoreConflicts(ctx, args)
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+	if len(conflicts) == 0 {
+		_ = runHooks(runner, hooks.Operation{Event: hooks.EventPostRestore})
+		fmt.Fprintf(os.Stderr, "No package downgrades or version conflicts.\n")
+		return exitcode.Success
+	}
+
+	var fixes []resolver.Fix
+	for _, c := range conflicts {
+		printConflict(c)
+		if fix, ok := c.Propose(); ok {
+			fmt.Fprintf(os.Stderr, "  fix: %s in %s\n\n", fix.Description, fix.Project)
+			fixes = append(fixes, fix)
+		} else {
+			fmt.Fprintf(os.Stderr, "  fix: none proposed (the project or the required version is unknown)\n\n")
+		}
+	}
+
+	if !values.Bool("apply") {
+		if len(fixes) > 0 {
+			infof("Run `lazynuget resolve --apply` to apply %d fix(es).\n", len(fixes))
+		}
+		return exitcode.UserError
+	}
+
+	if exitCode := applyFixes(ctx, runner, fixes); exitCode != exitcode.Success {
+		return exitCode
+	}
+
+	remaining, exitCode := restoreConflicts(ctx, args)
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+	if len(remaining) > 0 {
+		fmt.Fprintf(os.Stderr, "%d conflict(s) remain after applying fixes; run `lazynuget resolve` again for details.\n", len(remaining))
+		return exitcode.UserError
+	}
+	fmt.Fprintf(os.Stderr, "Restore succeeded without conflicts.\n")
+	_ = runHooks(runner, hooks.Operation{Event: hooks.EventPostRestore})
+	return exitcode.Success
+}
+
+// applyFixes applies fixes with the configured operation backend, running install hooks
+// for packages a project did not reference and update hooks for those it did.
+func applyFixes(ctx context.Context, runner *hooks.Runner, fixes []resolver.Fix) int {
+	backend := fixBackend()
+	for _, fix := range fixes {
+		op := hooks.Operation{Project: fix.Project, Package: fix.Package, Version: fix.Version}
+		previous, referenced := referencedVersion(fix.Project, fix.Package)
+		if !referenced {
+			op.Event = hooks.EventPreInstall
+			if err := runHooks(runner, op); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v; %s was not added to %s\n", err, fix.Package, fix.Project)
+				return exitcode.SystemError
+			}
+		}
+
+		changed, err := backend.Set(ctx, fix.Project, fix.Package, fix.Version, fix.Reason)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+		for _, path := range changed {
+			infof("Updated %s (%s %s)\n", path, fix.Package, fix.Version)
+		}
+		if referenced && len(changed) > 0 {
+			op.Event, op.PreviousVersion = hooks.EventPostUpdate, previous
+			_ = runHooks(runner, op)
+		}
+	}
+	return exitcode.Success
+}
+
+// fixBackend returns the backend the operationBackend setting selects for applying fixes.
+// Callers restore once every fix is written, whatever the restoreMode setting, since they
+// check the restored graph.
+func fixBackend() operation.Backend {
+	name := config.GetDefaultConfig().OperationBackend
+	cfg, err := loadUserConfig()
+	if err == nil {
+		name = cfg.OperationBackend
+	}
+	backend, err := operation.NewSession(name, operation.RestoreManual, platform.NewProcessSpawner())
+	if err != nil {
+		// Validation falls back to the default, so the name is known
+		return operation.Direct{}
+	}
+	return backend
+}
+
+// referencedVersion returns the version a project references a package at, including
+// versions set centrally, and whether the project references the package at all.
+func referencedVersion(path, id string) (string, bool) {
+	p, err := project.Load(path)
+	if err != nil {
+		return "", false
+	}
+	for _, ref := range p.PackageReferences {
+		if strings.EqualFold(ref.ID, id) {
+			if ref.Version == "" {
+				return project.CentralVersion(path, ref.ID), true
+			}
+			return ref.Version, true
+		}
+	}
+	return "", false
+}
+
+// restoreConflicts runs dotnet restore and returns the reported conflicts with their
+// dependency paths. On failure it prints the error and returns a non-zero exit code.
+func restoreConflicts(ctx context.Context, args []string) ([]resolver.Conflict, int) {
+	result, err := platform.NewProcessSpawner().RunContext(ctx, "dotnet", append([]string{"restore"}, args...), "", nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return nil, exitcode.SystemError
+	}
+
+	buildErr := msbuild.NewBuildError("dotnet restore", result)
+	conflicts := resolver.FromDiagnostics(buildErr.Diagnostics)
+	if result.ExitCode != 0 && len(conflicts) == 0 {
+		// Restore failed for another reason; show what it reported
+		_ = buildErr.Render(os.Stderr)
+		return nil, exitcode.SystemError
+	}
+
+	for i := range conflicts {
+		if assets, err := resolver.LoadAssets(resolver.AssetsPath(conflicts[i].Project)); err == nil {
+			conflicts[i].AddGraphPaths(assets)
+		}
+	}
+	return conflicts, exitcode.Success
+}
+
+// printConflict describes a conflict and its conflicting edges.
+func printConflict(c resolver.Conflict) {
+	fmt.Fprintf(os.Stderr, "%s %s\n", c.Code, c.Message)
+	if c.Project != "" {
+		fmt.Fprintf(os.Stderr, "  project: %s\n", c.Project)
+	}
+
+	conflicting := c.ConflictingEdges()
+	for _, p := range c.Paths {
+		label := "requires"
+		for _, edge := range conflicting {
+			if edge.String() == p.String() {
+				label = "conflicts"
+				break
+			}
+		}
+		fmt.Fprintf(os.Stderr, "  %-9s %s\n", label+":", p)
+	}
+	if url := msbuild.DocsURL(c.Code); url != "" {
+		fmt.Fprintf(os.Stderr, "  see: %s\n", url)
+	}
+}
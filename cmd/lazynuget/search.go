@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/audit"
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/instance"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/query"
+)
+
+// maxOwners bounds the owners listed per package; --json has all of them.
+const maxOwners = 2
+
+// maxTrendMonths bounds --trends; NuGet Trends keeps about five years of history.
+const maxTrendMonths = 60
+
+// runSearch implements `lazynuget search [--sort ORDER] [--trends MONTHS] [QUERY]` and
+// `lazynuget search --history|--clear-history`.
+func runSearch(_ *cli.Command, values *cli.Values) int {
+	if values.Bool("history") || values.Bool("clear-history") {
+		return runSearchHistory(values)
+	}
+	take, err := strconv.Atoi(values.String("take"))
+	if err != nil || take < 1 || take > 1000 {
+		fmt.Fprintln(os.Stderr, "Error: --take must be a number between 1 and 1000")
+		return exitcode.UserError
+	}
+	months := 0
+	if s := values.String("trends"); s != "" {
+		if months, err = strconv.Atoi(s); err != nil || months < 1 || months > maxTrendMonths {
+			fmt.Fprintf(os.Stderr, "Error: --trends must be a number of months between 1 and %d\n", maxTrendMonths)
+			return exitcode.UserError
+		}
+	}
+	sort := values.String("sort")
+	if sort != "relevance" && sort != "downloads" {
+		fmt.Fprintln(os.Stderr, "Error: --sort must be relevance or downloads")
+		return exitcode.UserError
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	query := strings.Join(values.Args(), " ")
+	cfg, _ := loadUserConfig()
+	feed := nuget.NewFeed()
+	feed.HTTPClient = feedClient(cfg)
+	results, err := feed.Search(ctx, query, nuget.SearchOptions{Take: take, Prerelease: values.Bool("prerelease")})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	if sort == "downloads" {
+		nuget.SortByDownloads(results)
+	}
+	// Remembering the query is a convenience; a search is not failed over it
+	if history, err := openSearchHistory(); err == nil {
+		err = history.Add(query)
+		if err != nil {
+			warnf("%v\n", err)
+		}
+	}
+	if months > 0 {
+		// Trends are an extra; the results are still worth showing without them
+		if err := nuget.NewTrends().AddTrends(ctx, results, months); err != nil {
+			warnf("%v\n", err)
+		}
+	}
+
+	if values.Bool("json") {
+		if results == nil {
+			results = []nuget.SearchResult{}
+		}
+		return writeJSON(nuget.SearchKind, map[string]any{"query": query, "packages": results})
+	}
+
+	printSearch(results, months > 0, platform.NewTerminalCapabilities().SupportsUnicode())
+	return exitcode.Success
+}
+
+// printSearch prints a table of search results, with a download trend per package when
+// trends is set.
+func printSearch(results []nuget.SearchResult, trends, unicode bool) {
+	if len(results) == 0 {
+		fmt.Println("No packages found")
+		return
+	}
+
+	header := []string{"Package", "Version", "Downloads", "This version", "Owners"}
+	if trends {
+		header = append(header, "Trend")
+	}
+	rows := [][]string{header}
+	for _, r := range results {
+		id := r.ID
+		switch lookAlike := audit.LookAlike(r.ID); {
+		case r.Verified:
+			id += " (verified)"
+		case lookAlike != "":
+			id += " (looks like " + lookAlike + ")"
+		}
+		versionDownloads := "-"
+		if n := r.Downloads(r.Version); n > 0 {
+			versionDownloads = nuget.FormatCount(n)
+		}
+		owners := strings.Join(r.Owners, ", ")
+		if len(r.Owners) > maxOwners {
+			owners = fmt.Sprintf("%s +%d", strings.Join(r.Owners[:maxOwners], ", "), len(r.Owners)-maxOwners)
+		}
+		row := []string{id, r.Version, nuget.FormatCount(r.TotalDownloads), versionDownloads, owners}
+		if trends {
+			counts := make([]int64, len(r.Trend))
+			for i, week := range r.Trend {
+				counts[i] = week.Count
+			}
+			row = append(row, nuget.Sparkline(counts, unicode))
+		}
+		rows = append(rows, row)
+	}
+
+	widths := make([]int, len(header))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len([]rune(cell)))
+		}
+	}
+	for _, row := range rows {
+		var sb strings.Builder
+		for i, cell := range row {
+			fmt.Fprintf(&sb, "  %-*s", widths[i], cell)
+		}
+		fmt.Println(strings.TrimRight(sb.String(), " "))
+	}
+}
+
+// openSearchHistory opens the search history of the workspace the working directory is in.
+func openSearchHistory() (*query.History, error) {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	workspace, err := instance.WorkspaceRoot(workDir)
+	if err != nil {
+		return nil, err
+	}
+	dir := query.DefaultHistoryDir()
+	if dir == "" {
+		return nil, errors.New("no config directory to keep the search history in")
+	}
+	return query.OpenHistory(dir, workspace)
+}
+
+// runSearchHistory lists the workspace's past searches, most recent first, or forgets them.
+func runSearchHistory(values *cli.Values) int {
+	history, err := openSearchHistory()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	if values.Bool("clear-history") {
+		if err := history.Clear(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+		infof("Cleared the search history\n")
+		return exitcode.Success
+	}
+	queries := history.Queries()
+	slices.Reverse(queries)
+	if values.Bool("json") {
+		return writeJSON(query.HistoryKind, map[string]any{"queries": queries})
+	}
+	for _, q := range queries {
+		fmt.Println(q)
+	}
+	return exitcode.Success
+}
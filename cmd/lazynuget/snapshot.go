@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/hooks"
+	"github.com/willibrandon/lazynuget/internal/instance"
+	"github.com/willibrandon/lazynuget/internal/snapshot"
+)
+
+// runSnapshotCreate implements `lazynuget snapshot create [FILE]`.
+func runSnapshotCreate(_ *cli.Command, values *cli.Values) int {
+	root, exitCode := workspaceRoot()
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+	s, err := snapshot.Create(os.DirFS(root))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+
+	var buf bytes.Buffer
+	if err := s.Write(&buf, outputVersion); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+
+	args := values.Args()
+	if len(args) == 0 || args[0] == "-" {
+		if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
+			return exitcode.SystemError
+		}
+		return exitcode.Success
+	}
+	if err := os.WriteFile(args[0], buf.Bytes(), 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write %s: %v\n", args[0], err)
+		return exitcode.SystemError
+	}
+
+	count := 0
+	for _, f := range s.Files {
+		count += len(f.Packages)
+	}
+	infof("Wrote %s (%d package versions in %d files)\n", args[0], count, len(s.Files))
+	return exitcode.Success
+}
+
+// runSnapshotApply implements `lazynuget snapshot apply [--dry-run] FILE`.
+func runSnapshotApply(_ *cli.Command, values *cli.Values) int {
+	args := values.Args()
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Error: expected a snapshot file")
+		return exitcode.UserError
+	}
+	s, err := snapshot.ReadFile(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	root, exitCode := workspaceRoot()
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+
+	dryRun := values.Bool("dry-run")
+	var changes []snapshot.Change
+	apply := func() (err error) {
+		changes, err = s.Apply(root, dryRun)
+		return err
+	}
+	if dryRun {
+		err = apply()
+	} else {
+		// Not while an interactive session or the daemon is changing the same projects
+		err = instance.Hold(instance.DefaultLockDir(), root, apply)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+
+	verb := "Set"
+	if dryRun {
+		verb = "Would set"
+	}
+	changed, missing := 0, 0
+	for _, c := range changes {
+		switch {
+		case c.Missing && c.Package == "":
+			missing++
+			fmt.Printf("%s: missing (project no longer exists)\n", c.File)
+		case c.Missing:
+			missing++
+			fmt.Printf("%s: %s missing (wanted %s)\n", c.File, c.Package, c.To)
+		default:
+			changed++
+			fmt.Printf("%s: %s %s -> %s\n", c.File, c.Package, c.From, c.To)
+		}
+	}
+
+	switch {
+	case changed == 0 && missing == 0:
+		fmt.Println("Already matches the snapshot")
+	case changed > 0:
+		fmt.Printf("%s %d package versions\n", verb, changed)
+	}
+	if !dryRun {
+		runner := hookRunner()
+		for _, c := range changes {
+			if !c.Missing {
+				_ = runHooks(runner, hooks.Operation{
+					Event:           hooks.EventPostUpdate,
+					Project:         filepath.Join(root, filepath.FromSlash(c.File)),
+					Package:         c.Package,
+					Version:         c.To,
+					PreviousVersion: c.From,
+				})
+			}
+		}
+	}
+	if missing > 0 {
+		fmt.Fprintf(os.Stderr, "%d packages or projects in the snapshot no longer exist\n", missing)
+		return exitcode.PartialFailure
+	}
+	return exitcode.Success
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/statusbar"
+	"github.com/willibrandon/lazynuget/internal/theme"
+)
+
+// statusLineFeedTimeout bounds the feed checks of --check-feeds: a prompt that waits longer
+// than this is worse than one without feed health.
+const statusLineFeedTimeout = 3 * time.Second
+
+// runStatusLine implements `lazynuget status-line [--format FORMAT] [--check-feeds]
+// [--no-color] [--json]`.
+func runStatusLine(_ *cli.Command, values *cli.Values) int {
+	root, exitCode := workspaceRoot()
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+	cfg, err := loadUserConfig()
+	if err != nil {
+		cfg = config.GetDefaultConfig()
+	}
+	format := cfg.StatusBar.Format
+	if f := values.String("format"); f != "" {
+		format = f
+		if _, err := config.StatusBarFormatSegments(format); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --format: %v\n", err)
+			return exitcode.UserError
+		}
+	}
+
+	opts := statusbar.Options{Root: root}
+	if values.Bool("check-feeds") {
+		opts.Feeds = []string{"https://api.nuget.org/v3/index.json"}
+		for _, f := range cfg.Feeds {
+			if !f.Disabled {
+				opts.Feeds = append(opts.Feeds, f.URL)
+			}
+		}
+		opts.Client = &http.Client{Timeout: statusLineFeedTimeout}
+	}
+	data := statusbar.Collect(context.Background(), opts)
+	if values.Bool("json") {
+		return writeJSON(statusbar.Kind, data)
+	}
+
+	noColor := values.Bool("no-color")
+	line, err := statusbar.Render(format, cfg.StatusBar.ClockFormat, data, theme.New(cfg.ColorScheme, terminalMode(noColor)))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.UserError
+	}
+	fmt.Println(line)
+	return exitcode.Success
+}
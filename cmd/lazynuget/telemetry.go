@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/policy"
+	"github.com/willibrandon/lazynuget/internal/telemetry"
+)
+
+// openTelemetryStore opens the consent and usage store shared by the telemetry commands.
+// On failure it prints the error and returns the exit code.
+func openTelemetryStore() (*telemetry.Store, int) {
+	statePath := telemetry.DefaultStatePath()
+	if statePath == "" {
+		fmt.Fprintf(os.Stderr, "Error: cannot determine the config directory\n")
+		return nil, exitcode.SystemError
+	}
+	store, err := telemetry.Open(statePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return nil, exitcode.UserError
+	}
+	return store, exitcode.Success
+}
+
+// runTelemetryEnable implements `lazynuget telemetry enable`.
+func runTelemetryEnable(*cli.Command, *cli.Values) int {
+	store, exitCode := openTelemetryStore()
+	if store == nil {
+		return exitCode
+	}
+	if err := store.SetConsent(true); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	infof("Telemetry enabled. Thank you! Inspect the data with `lazynuget telemetry show`.\n")
+	if reason := telemetryDisabledReason(); reason != "" {
+		infof("Note: nothing is recorded while %s.\n", reason)
+	}
+	return exitcode.Success
+}
+
+// runTelemetryDisable implements `lazynuget telemetry disable`.
+func runTelemetryDisable(*cli.Command, *cli.Values) int {
+	store, exitCode := openTelemetryStore()
+	if store == nil {
+		return exitCode
+	}
+	if err := store.SetConsent(false); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	infof("Telemetry disabled. Unsent usage data was deleted.\n")
+	return exitcode.Success
+}
+
+// runTelemetryShow implements `lazynuget telemetry show`.
+// The status goes to stderr and the report JSON to stdout, so it can be piped to jq.
+func runTelemetryShow(*cli.Command, *cli.Values) int {
+	store, exitCode := openTelemetryStore()
+	if store == nil {
+		return exitCode
+	}
+
+	status := "disabled"
+	switch {
+	case !store.Decided():
+		status = "disabled (not asked yet)"
+	case store.Enabled():
+		status = "enabled"
+	}
+	if reason := telemetryDisabledReason(); reason != "" && store.Enabled() {
+		status = "enabled, but paused because " + reason
+	}
+
+	endpoint := "(none, reports are not sent)"
+	cfg, err := loadUserConfig()
+	if err == nil && cfg.Telemetry.Endpoint != "" {
+		endpoint = cfg.Telemetry.Endpoint
+	}
+
+	fmt.Fprintf(os.Stderr, "Status:   %s\n", status)
+	fmt.Fprintf(os.Stderr, "Data:     %s\n", store.Path())
+	fmt.Fprintf(os.Stderr, "Endpoint: %s\n", endpoint)
+	fmt.Fprintf(os.Stderr, "\n")
+
+	data, err := json.MarshalIndent(store.Report(version), "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to render report: %v\n", err)
+		return exitcode.SystemError
+	}
+	data = append(data, '\n')
+	if _, err := os.Stdout.Write(data); err != nil {
+		return exitcode.SystemError
+	}
+	return exitcode.Success
+}
+
+// telemetryDisabledReason explains why telemetry is off regardless of consent, or "".
+func telemetryDisabledReason() string {
+	if telemetry.DisabledByEnv() {
+		return telemetry.DisableEnvVar + " or DO_NOT_TRACK is set"
+	}
+	p, err := policy.LoadMachinePolicy()
+	if err != nil {
+		return "the machine policy cannot be read (" + err.Error() + ")"
+	}
+	if !p.Allowed(policy.CapabilityTelemetry) {
+		return "telemetry is " + policy.RestrictedLabel
+	}
+	return ""
+}
+
+// recordSubcommand counts a utility subcommand for users who opted in.
+// A non-zero exit code is counted in the command error category.
+func recordSubcommand(name string, exitCode int) {
+	if telemetryDisabledReason() != "" {
+		return
+	}
+	statePath := telemetry.DefaultStatePath()
+	if statePath == "" {
+		return
+	}
+	store, err := telemetry.Open(statePath)
+	if err != nil || !store.Enabled() {
+		return
+	}
+
+	store.RecordCommand(name)
+	if exitCode != exitcode.Success {
+		store.RecordError(telemetry.ErrorCommand)
+	}
+	_ = store.Save()
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/theme"
+)
+
+// themeList is the JSON payload of `lazynuget theme list --json`.
+type themeList struct {
+	Directory  string              `json:"directory,omitempty"` // Where theme files are looked for
+	Current    string              `json:"current"`
+	Background platform.Background `json:"background,omitempty"` // The terminal background theme auto follows
+	Themes     []config.ThemeInfo  `json:"themes"`
+}
+
+// themeConfig returns the user config, or the defaults when it cannot be loaded, and its
+// themes directory.
+func themeConfig() (cfg *config.Config, dir string) {
+	cfg, err := loadUserConfig()
+	if err != nil {
+		cfg = config.GetDefaultConfig()
+		cfg.LoadedFrom = ""
+	}
+	return cfg, config.ThemesDir(cfg.LoadedFrom)
+}
+
+// runThemeList implements `lazynuget theme list [--json]`.
+func runThemeList(_ *cli.Command, values *cli.Values) int {
+	cfg, dir := themeConfig()
+	current := cfg.Theme
+	themes, err := config.ListThemes(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+
+	background := platform.DetectBackground(platform.BackgroundQueryTimeout)
+	if values.Bool("json") {
+		return writeJSON(config.ThemesKind, themeList{Directory: dir, Current: current, Background: background, Themes: themes})
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, t := range themes {
+		marker := " "
+		if t.Name == current {
+			marker = "*"
+		}
+		description := t.Description
+		if t.Name == config.AutoTheme {
+			description += " (now " + config.AutoThemeVariant(background) + ")"
+		}
+		if t.Error != "" {
+			description = "invalid: " + t.Error
+		}
+		source := "built-in"
+		if t.Path != "" {
+			source = t.Path
+		}
+		fmt.Fprintf(tw, "%s %s\t%s\t%s\n", marker, t.Name, description, source)
+	}
+	if err := tw.Flush(); err != nil {
+		return exitcode.SystemError
+	}
+	if dir != "" {
+		infof("Add <name>.yml files to %s for more themes\n", dir)
+	}
+	return exitcode.Success
+}
+
+// runThemePreview implements `lazynuget theme preview [--no-color] [NAME]`.
+func runThemePreview(_ *cli.Command, values *cli.Values) int {
+	cfg, dir := themeConfig()
+	name := cfg.Theme
+	args := values.Args()
+	if len(args) > 0 {
+		name = args[0]
+	}
+	if name == config.AutoTheme {
+		name = config.AutoThemeVariant(platform.DetectBackground(platform.BackgroundQueryTimeout))
+		infof("Theme auto uses the %s theme on this terminal\n", name)
+	}
+	scheme, info, err := config.LoadTheme(dir, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.UserError
+	}
+	if len(args) == 0 && name == cfg.Theme {
+		scheme = cfg.ColorScheme // The theme in use, as adjusted by colorScheme (panel colors too)
+	}
+
+	header := info.Name
+	if info.Description != "" {
+		header += ": " + info.Description
+	}
+	fmt.Println(header)
+	if info.Path != "" {
+		fmt.Println(info.Path)
+	}
+	fmt.Println()
+	if err := theme.New(scheme, terminalMode(values.Bool("no-color"))).WritePreview(os.Stdout); err != nil {
+		return exitcode.SystemError
+	}
+	return exitcode.Success
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/outdated"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/tools"
+)
+
+// runToolsList implements `lazynuget tools list [--prerelease] [--offline] [--json]`.
+func runToolsList(_ *cli.Command, values *cli.Values) int {
+	interrupt, stop := interruptContext()
+	defer stop()
+	local, err := tools.LocalTools(".")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	var global []tools.Tool
+	if platform.DotnetAvailable() {
+		if global, err = tools.GlobalTools(interrupt, platform.NewProcessSpawner()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+	} else {
+		// Local tools are read from their manifests
+		warnf("the dotnet CLI was not found; global tools are not listed\n")
+	}
+
+	opts := outdated.Options{Prerelease: values.Bool("prerelease")}
+	packagesDir := nuget.GlobalPackagesDir()
+	ctx, cancel := context.WithTimeout(interrupt, 2*time.Minute)
+	defer cancel()
+	feed, err := sourceFeed(ctx, values.String("source"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+
+	entries := []tools.Entry{}
+	for _, t := range append(local, global...) {
+		var available []string
+		if values.Bool("offline") {
+			available = nuget.LocalVersions(packagesDir, t.ID)
+		} else if available, err = feed.Versions(ctx, t.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+		entries = append(entries, tools.Check(t, available, opts))
+	}
+
+	if values.Bool("json") {
+		return writeJSON(tools.Kind, entries)
+	}
+
+	manifest := "(no tool manifest)"
+	if len(local) > 0 {
+		manifest = displayPath(local[0].Manifest)
+	}
+	fmt.Printf("Local tools %s\n", manifest)
+	printTools(entries[:len(local)])
+	fmt.Println("Global tools")
+	printTools(entries[len(local):])
+	return exitcode.Success
+}
+
+// printTools prints a table of tools with a status note per tool.
+func printTools(entries []tools.Entry) {
+	if len(entries) == 0 {
+		fmt.Println("  (none)")
+		return
+	}
+
+	rows := [][]string{{"Tool", "Version", "Latest", "Commands", ""}}
+	for _, e := range entries {
+		var note string
+		switch e.Status {
+		case outdated.StatusCurrent:
+			note = "up to date"
+		case outdated.StatusOutdated:
+			note = "update available"
+		default:
+			note = e.Reason
+		}
+		rows = append(rows, []string{e.ID, e.Version, e.Latest, strings.Join(e.Commands, ", "), note})
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+	for _, row := range rows {
+		var sb strings.Builder
+		for i, cell := range row {
+			fmt.Fprintf(&sb, "  %-*s", widths[i], cell)
+		}
+		fmt.Println(strings.TrimRight(sb.String(), " "))
+	}
+}
+
+// runToolsInstall implements `lazynuget tools install [--global] PACKAGE [VERSION]`.
+func runToolsInstall(_ *cli.Command, values *cli.Values) int {
+	args := values.Args()
+	if len(args) < 1 || len(args) > 2 {
+		fmt.Fprintln(os.Stderr, "Error: expected a tool package ID and an optional version")
+		return exitcode.UserError
+	}
+	version := ""
+	if len(args) == 2 {
+		version = args[1]
+	}
+	ctx, stop := interruptContext()
+	defer stop()
+	return toolCommand(tools.Install(ctx, platform.NewProcessSpawner(), "", toolScope(values), args[0], version))
+}
+
+// runToolsUpdate implements `lazynuget tools update [--global] (--all | PACKAGE [VERSION])`.
+func runToolsUpdate(_ *cli.Command, values *cli.Values) int {
+	args := values.Args()
+	ctx, stop := interruptContext()
+	defer stop()
+	spawner := platform.NewProcessSpawner()
+	if values.Bool("all") {
+		if len(args) > 0 {
+			fmt.Fprintln(os.Stderr, "Error: --all updates every tool; omit the package ID")
+			return exitcode.UserError
+		}
+		return toolCommand(tools.UpdateAll(ctx, spawner, "", toolScope(values)))
+	}
+	if len(args) < 1 || len(args) > 2 {
+		fmt.Fprintln(os.Stderr, "Error: expected a tool package ID and an optional version, or --all")
+		return exitcode.UserError
+	}
+	version := ""
+	if len(args) == 2 {
+		version = args[1]
+	}
+	return toolCommand(tools.Update(ctx, spawner, "", toolScope(values), args[0], version))
+}
+
+// runToolsUninstall implements `lazynuget tools uninstall [--global] PACKAGE`.
+func runToolsUninstall(_ *cli.Command, values *cli.Values) int {
+	args := values.Args()
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Error: expected a tool package ID")
+		return exitcode.UserError
+	}
+	ctx, stop := interruptContext()
+	defer stop()
+	return toolCommand(tools.Uninstall(ctx, platform.NewProcessSpawner(), "", toolScope(values), args[0]))
+}
+
+// toolScope returns the scope selected by --global.
+func toolScope(values *cli.Values) tools.Scope {
+	if values.Bool("global") {
+		return tools.ScopeGlobal
+	}
+	return tools.ScopeLocal
+}
+
+// toolCommand reports the outcome of a dotnet tool command as an exit code.
+func toolCommand(err error) int {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	return exitcode.Success
+}
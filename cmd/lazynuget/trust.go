@@ -0,0 +1,362 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/operation"
+	"github.com/willibrandon/lazynuget/internal/resolver"
+)
+
+// runTrustList implements `lazynuget trust list [--json]`: the trust policy of the
+// nuget.config files that apply in the current directory.
+func runTrustList(_ *cli.Command, values *cli.Values) int {
+	paths := nuget.ConfigPaths(".")
+	policy, err := nuget.ReadTrustPolicy(paths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	if values.Bool("json") {
+		return writeJSON(nuget.TrustKind, map[string]any{"files": paths, "policy": policy})
+	}
+
+	fmt.Printf("Signature validation: %s\n", policy.Mode)
+	if len(policy.Signers) == 0 {
+		fmt.Println("No trusted signers")
+		return exitcode.Success
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tKIND\tCERTIFICATES\tOWNERS\tFILE")
+	for _, s := range policy.Signers {
+		certs := make([]string, len(s.Certificates))
+		for i, c := range s.Certificates {
+			certs[i] = c.HashAlgorithm + ":" + shortFingerprint(c.Fingerprint)
+			if c.AllowUntrustedRoot {
+				certs[i] += " (untrusted root allowed)"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.Name, s.Kind, strings.Join(certs, ", "), strings.Join(s.Owners, ";"), displayPath(s.File))
+	}
+	if err := tw.Flush(); err != nil {
+		return exitcode.SystemError
+	}
+	return exitcode.Success
+}
+
+// runTrustAdd implements `lazynuget trust add author|repository NAME`. The certificate
+// is given by fingerprint or taken from a restored package's signature; a signer the file
+// lists already keeps its certificates, so that one rotating its certificate can be
+// trusted with both.
+func runTrustAdd(_ *cli.Command, values *cli.Values) int {
+	kind, name := strings.ToLower(values.Args()[0]), values.Args()[1]
+	if kind != nuget.SignerAuthor && kind != nuget.SignerRepository {
+		fmt.Fprintf(os.Stderr, "Error: %q is not a kind of trusted signer (author or repository)\n", values.Args()[0])
+		return exitcode.UserError
+	}
+	if kind == nuget.SignerAuthor && (values.String("owners") != "" || values.String("service-index") != "") {
+		fmt.Fprintln(os.Stderr, "Error: --owners and --service-index are for repositories")
+		return exitcode.UserError
+	}
+	cert, serviceIndex, exitCode := trustedCertificate(kind, values)
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+	path, exitCode := trustConfig(values)
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+
+	signer := nuget.TrustedSigner{Kind: kind, Name: name, ServiceIndex: values.String("service-index")}
+	if signer.ServiceIndex == "" {
+		signer.ServiceIndex = serviceIndex
+	}
+	if policy, err := nuget.ReadTrustPolicy([]string{path}); err == nil {
+		for _, s := range policy.Signers {
+			if strings.EqualFold(s.Name, name) && s.Kind == kind {
+				signer.Certificates = slices.DeleteFunc(s.Certificates, func(c nuget.TrustedCertificate) bool {
+					return strings.EqualFold(c.Fingerprint, cert.Fingerprint)
+				})
+				signer.Owners = s.Owners
+				if signer.ServiceIndex == "" {
+					signer.ServiceIndex = s.ServiceIndex
+				}
+			}
+		}
+	}
+	signer.Certificates = append(signer.Certificates, cert)
+	if owners := values.String("owners"); owners != "" {
+		signer.Owners = nil
+		for _, owner := range strings.Split(owners, ";") {
+			if owner = strings.TrimSpace(owner); owner != "" {
+				signer.Owners = append(signer.Owners, owner)
+			}
+		}
+	}
+	if kind == nuget.SignerRepository && signer.ServiceIndex == "" {
+		fmt.Fprintln(os.Stderr, "Error: missing --service-index for the repository")
+		return exitcode.UserError
+	}
+
+	if err := nuget.AddTrustedSigner(path, signer); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	fmt.Printf("Trusted %s %s (%s:%s) in %s\n", kind, name, cert.HashAlgorithm, shortFingerprint(cert.Fingerprint), displayPath(path))
+	return exitcode.Success
+}
+
+// trustedCertificate returns the certificate to trust, from --fingerprint or the signature
+// of the --package, and the service index a repository signature names.
+func trustedCertificate(kind string, values *cli.Values) (nuget.TrustedCertificate, string, int) {
+	cert := nuget.TrustedCertificate{
+		Fingerprint:        strings.ToUpper(strings.ReplaceAll(values.String("fingerprint"), ":", "")),
+		HashAlgorithm:      strings.ToUpper(values.String("hash-algorithm")),
+		AllowUntrustedRoot: values.Bool("allow-untrusted-root"),
+	}
+	if !slices.Contains([]string{"SHA256", "SHA384", "SHA512"}, cert.HashAlgorithm) {
+		fmt.Fprintf(os.Stderr, "Error: unknown hash algorithm %q (SHA256, SHA384, or SHA512)\n", values.String("hash-algorithm"))
+		return cert, "", exitcode.UserError
+	}
+	pkg := values.String("package")
+	switch {
+	case (cert.Fingerprint == "") == (pkg == ""):
+		fmt.Fprintln(os.Stderr, "Error: give either --fingerprint or --package")
+		return cert, "", exitcode.UserError
+	case pkg == "":
+		return cert, "", exitcode.Success
+	}
+
+	spec, err := operation.ParseSpec(pkg)
+	if err == nil && spec.Version == "" {
+		err = fmt.Errorf("%s: give the version of the restored package (ID@VERSION)", spec.ID)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return cert, "", exitcode.UserError
+	}
+	sig, err := nuget.ReadPackageSignature(nuget.GlobalPackagesDir(), spec.ID, spec.Version)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		fmt.Fprintf(os.Stderr, "Error: %v; restore a project that references it first\n", err)
+		return cert, "", exitcode.UserError
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return cert, "", exitcode.SystemError
+	}
+	var signer *nuget.Signer
+	if sig != nil {
+		signer = sig.Author
+		if kind == nuget.SignerRepository {
+			signer = sig.Repository
+		}
+	}
+	if signer == nil {
+		fmt.Fprintf(os.Stderr, "Error: %s has no %s signature\n", spec, kind)
+		return cert, "", exitcode.UserError
+	}
+	cert.Fingerprint = signer.Fingerprint(cert.HashAlgorithm)
+	infof("Signed by %s\n", signer.Subject)
+	return cert, signer.ServiceIndex, exitcode.Success
+}
+
+// runTrustRemove implements `lazynuget trust remove NAME`.
+func runTrustRemove(_ *cli.Command, values *cli.Values) int {
+	path, exitCode := trustConfig(values)
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+	name := values.Args()[0]
+	removed, err := nuget.RemoveTrustedSigner(path, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	if !removed {
+		infof("%s does not list %s as a trusted signer\n", displayPath(path), name)
+		return exitcode.Success
+	}
+	fmt.Printf("Removed %s from %s\n", name, displayPath(path))
+	return exitcode.Success
+}
+
+// runTrustMode implements `lazynuget trust mode accept|require`.
+func runTrustMode(_ *cli.Command, values *cli.Values) int {
+	mode := strings.ToLower(values.Args()[0])
+	if mode != nuget.ValidationAccept && mode != nuget.ValidationRequire {
+		fmt.Fprintf(os.Stderr, "Error: %q is not a signature validation mode (accept or require)\n", values.Args()[0])
+		return exitcode.UserError
+	}
+	path, exitCode := trustConfig(values)
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+	if err := nuget.SetValidationMode(path, mode); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	fmt.Printf("Signature validation is %s in %s\n", mode, displayPath(path))
+	return exitcode.Success
+}
+
+// trustedPackage is a package's entry in `lazynuget trust check --json`.
+type trustedPackage struct {
+	ID        string                  `json:"id"`
+	Version   string                  `json:"version"`
+	Restored  bool                    `json:"restored"` // In the global packages folder
+	Signature *nuget.PackageSignature `json:"signature"`
+	Matches   string                  `json:"matchedSigner,omitempty"` // The trusted signer whose certificate signed the package
+	Allowed   bool                    `json:"allowed"`                 // The policy accepts the package, by certificate alone; false when not restored
+	Error     string                  `json:"error,omitempty"`
+}
+
+// trustedProject is a project's entry in `lazynuget trust check --json`.
+type trustedProject struct {
+	Project  string           `json:"project"`
+	Mode     string           `json:"signatureValidationMode"`
+	Packages []trustedPackage `json:"packages"`
+}
+
+// runTrustCheck implements `lazynuget trust check [--json] [PROJECT...]`: whether each
+// package the projects' last restore resolved was signed with the certificate of a signer
+// the project's trust policy lists. The signatures themselves are not verified; restore
+// does that.
+func runTrustCheck(_ *cli.Command, values *cli.Values) int {
+	paths := values.Args()
+	if len(paths) == 0 {
+		var exitCode int
+		if paths, exitCode = workspaceProjects(); exitCode != exitcode.Success {
+			return exitCode
+		}
+	}
+
+	packagesDir := nuget.GlobalPackagesDir()
+	projects := make([]trustedProject, 0, len(paths))
+	denied := 0
+	for _, path := range paths {
+		policy, err := nuget.ReadTrustPolicy(nuget.ConfigPaths(filepath.Dir(path)))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+		entry := trustedProject{Project: path, Mode: policy.Mode, Packages: []trustedPackage{}}
+		assets, err := resolver.LoadAssets(resolver.AssetsPath(path))
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				warnf("%v\n", err)
+			}
+			projects = append(projects, entry)
+			continue
+		}
+		for _, pkg := range assets.Packages() {
+			p := trustedPackage{ID: pkg.ID, Version: pkg.Version, Restored: true}
+			sig, err := nuget.ReadPackageSignature(packagesDir, pkg.ID, pkg.Version)
+			switch {
+			case errors.Is(err, fs.ErrNotExist):
+				p.Restored = false
+			case err != nil:
+				p.Error = err.Error()
+				_, p.Allowed = policy.Check(nil)
+			default:
+				p.Signature = sig
+				p.Matches, p.Allowed = policy.Check(sig)
+			}
+			if p.Restored && !p.Allowed {
+				denied++
+			}
+			entry.Packages = append(entry.Packages, p)
+		}
+		projects = append(projects, entry)
+	}
+
+	if values.Bool("json") {
+		if exitCode := writeJSON(nuget.TrustKind, projects); exitCode != exitcode.Success {
+			return exitCode
+		}
+	} else if exitCode := printTrustCheck(projects); exitCode != exitcode.Success {
+		return exitCode
+	}
+	if denied > 0 {
+		infof("%d packages were not signed with a trusted signer's certificate\n", denied)
+		return exitcode.PolicyViolation
+	}
+	return exitcode.Success
+}
+
+// printTrustCheck prints the packages of each project with who signed them and whether
+// a trusted signer's certificate matches.
+func printTrustCheck(projects []trustedProject) int {
+	for _, p := range projects {
+		fmt.Printf("%s (signature validation: %s)\n", displayPath(p.Project), p.Mode)
+		if len(p.Packages) == 0 {
+			fmt.Println("  (not restored)")
+			continue
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, pkg := range p.Packages {
+			signedBy := "unsigned"
+			switch {
+			case !pkg.Restored:
+				signedBy = "not in the global packages folder"
+			case pkg.Error != "":
+				signedBy = pkg.Error
+			case pkg.Signature != nil:
+				var signers []string
+				if pkg.Signature.Author != nil {
+					signers = append(signers, "author "+pkg.Signature.Author.Subject)
+				}
+				if pkg.Signature.Repository != nil {
+					signers = append(signers, "repository "+pkg.Signature.Repository.Subject)
+				}
+				signedBy = strings.Join(signers, ", ")
+			}
+			status := "allowed"
+			switch {
+			case !pkg.Restored:
+				status = "unknown"
+			case pkg.Matches != "":
+				status = "signer certificate matches (" + pkg.Matches + ")"
+			case !pkg.Allowed:
+				status = "NO MATCHING SIGNER"
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", pkg.ID, pkg.Version, signedBy, status)
+		}
+		if err := tw.Flush(); err != nil {
+			return exitcode.SystemError
+		}
+	}
+	return exitcode.Success
+}
+
+// trustConfig returns the NuGet configuration file trust add, remove, and mode change:
+// --nuget-config, or the nearest nuget.config, or the user's.
+func trustConfig(values *cli.Values) (string, int) {
+	if path := values.String("nuget-config"); path != "" {
+		return path, exitcode.Success
+	}
+	if path := nuget.FindConfig("."); path != "" {
+		return path, exitcode.Success
+	}
+	if path := nuget.UserConfigPath(); path != "" {
+		return path, exitcode.Success
+	}
+	fmt.Fprintln(os.Stderr, "Error: no nuget.config was found; use --nuget-config")
+	return "", exitcode.UserError
+}
+
+// shortFingerprint abbreviates a certificate fingerprint for tables.
+func shortFingerprint(fingerprint string) string {
+	if len(fingerprint) <= 16 {
+		return fingerprint
+	}
+	return fingerprint[:8] + "…" + fingerprint[len(fingerprint)-8:]
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/policy"
+	"github.com/willibrandon/lazynuget/internal/selfupdate"
+)
+
+// runUpdateSelf implements `lazynuget update-self`.
+func runUpdateSelf(_ *cli.Command, values *cli.Values) int {
+	checkOnly := values.Bool("check")
+	force := values.Bool("force")
+	insecure := values.Bool("insecure")
+
+	p, err := policy.LoadMachinePolicy()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	if err := p.Check(policy.CapabilitySelfUpdate); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.PolicyViolation
+	}
+
+	client, err := selfupdate.NewClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	client.Insecure = insecure
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	release, err := client.Latest(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+
+	newer, err := selfupdate.Newer(version, release.Version())
+	devBuild := errors.Is(err, selfupdate.ErrDevelopmentBuild)
+	if err != nil && !devBuild {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+
+	if checkOnly {
+		switch {
+		case devBuild:
+			fmt.Fprintf(os.Stderr, "This is a development build (%s); the latest release is %s.\n", version, release.TagName)
+		case newer:
+			fmt.Fprintf(os.Stderr, "lazynuget %s is available (current: %s): %s\n", release.TagName, version, release.HTMLURL)
+			infof("Run `lazynuget update-self` to install it.\n")
+		default:
+			fmt.Fprintf(os.Stderr, "lazynuget %s is up to date.\n", version)
+		}
+		return exitcode.Success
+	}
+
+	if !force {
+		if devBuild {
+			fmt.Fprintf(os.Stderr, "This is a development build (%s); the latest release is %s.\n", version, release.TagName)
+			infof("Run `lazynuget update-self --force` to replace it with the release.\n")
+			return exitcode.UserError
+		}
+		if !newer {
+			fmt.Fprintf(os.Stderr, "lazynuget %s is up to date.\n", version)
+			return exitcode.Success
+		}
+	}
+
+	exePath, err := os.Executable()
+	if err == nil {
+		exePath, err = filepath.EvalSymlinks(exePath)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: cannot locate the running binary: %v\n", err)
+		return exitcode.SystemError
+	}
+
+	// The new binary is written next to the old one before it replaces it
+	if asset, ok := release.Asset(selfupdate.AssetName(release.Version(), runtime.GOOS, runtime.GOARCH)); ok {
+		if err := platform.CheckFreeSpace(filepath.Dir(exePath), uint64(max(asset.Size, 0))*4); err != nil {
+			warnf("%v; the update may fail. Free up space first\n", err)
+		}
+	}
+	infof("Downloading lazynuget %s for %s/%s...\n", release.TagName, runtime.GOOS, runtime.GOARCH)
+	binary, err := client.Download(ctx, release, runtime.GOOS, runtime.GOARCH)
+	if errors.Is(err, selfupdate.ErrNoSigningKey) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		infof("Install a release build from %s, or run with --insecure to trust the checksum alone.\n", release.HTMLURL)
+		return exitcode.UserError
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	if client.PublicKey == nil {
+		warnf("this build has no release signing key; only the checksum was verified (--insecure).\n")
+	}
+
+	if err := selfupdate.Replace(exePath, binary); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	infof("Updated %s from %s to %s.\n", exePath, version, release.TagName)
+	return exitcode.Success
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/project"
+	"github.com/willibrandon/lazynuget/internal/resolver"
+)
+
+// projectWarnings is a project's entry in `lazynuget warnings list --json`.
+type projectWarnings struct {
+	Project  string                   `json:"project"`
+	Restored bool                     `json:"restored"` // The project has an assets file
+	Warnings []restoreWarning         `json:"warnings"`
+	Settings *project.WarningSettings `json:"settings"`
+}
+
+// restoreWarning is a NuGet warning or error of a project's last restore.
+type restoreWarning struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"` // warning or error, as the settings make it now
+	Message  string `json:"message"`
+	Package  string `json:"package,omitempty"`
+}
+
+// runWarningsList implements `lazynuget warnings list [--json] [PROJECT...]`.
+func runWarningsList(_ *cli.Command, values *cli.Values) int {
+	paths := values.Args()
+	if len(paths) == 0 {
+		var exitCode int
+		if paths, exitCode = workspaceProjects(); exitCode != exitcode.Success {
+			return exitCode
+		}
+	}
+
+	projects := make([]projectWarnings, 0, len(paths))
+	for _, path := range paths {
+		settings, err := project.ReadWarningSettings(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+		entry := projectWarnings{Project: path, Warnings: []restoreWarning{}, Settings: settings}
+		assets, err := resolver.LoadAssets(resolver.AssetsPath(path))
+		switch {
+		case err == nil:
+			entry.Restored = true
+			for _, log := range assets.Logs {
+				if _, ok := project.NormalizeCode(log.Code); !ok {
+					continue
+				}
+				severity := strings.ToLower(log.Level)
+				if severity == "warning" && settings.AsError(log.Code) {
+					severity = "error"
+				}
+				entry.Warnings = append(entry.Warnings, restoreWarning{Code: log.Code, Severity: severity, Message: log.Message, Package: log.LibraryID})
+			}
+		case !errors.Is(err, fs.ErrNotExist):
+			warnf("%v\n", err)
+		}
+		projects = append(projects, entry)
+	}
+
+	if values.Bool("json") {
+		return writeJSON(project.WarningsKind, projects)
+	}
+	for _, p := range projects {
+		fmt.Println(displayPath(p.Project))
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		switch {
+		case !p.Restored:
+			fmt.Fprintln(tw, "  (not restored)")
+		case len(p.Warnings) == 0:
+			fmt.Fprintln(tw, "  No NuGet warnings in the last restore")
+		default:
+			fmt.Fprintln(tw, "  Last restore")
+			for _, w := range p.Warnings {
+				fmt.Fprintf(tw, "    %s\t%s\t%s\n", w.Code, w.Severity, w.Message)
+			}
+		}
+		if len(p.Settings.Suppressions) > 0 {
+			fmt.Fprintln(tw, "  Suppressed (NoWarn)")
+			for _, s := range p.Settings.Suppressions {
+				fmt.Fprintf(tw, "    %s\t%s\t%s:%d\n", s.Code, s.Package, displayPath(s.File), s.Line)
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return exitcode.SystemError
+		}
+		if note := errorsNote(p.Settings); note != "" {
+			fmt.Println("  " + note)
+		}
+	}
+	return exitcode.Success
+}
+
+// errorsNote describes which NuGet warnings fail the build.
+func errorsNote(s *project.WarningSettings) string {
+	var note string
+	switch {
+	case s.TreatWarningsAsErrors:
+		note = "All warnings are errors (TreatWarningsAsErrors)"
+		if len(s.WarningsNotAsErrors) > 0 {
+			note += ", except " + strings.Join(s.WarningsNotAsErrors, ", ")
+		}
+	case len(s.WarningsAsErrors) > 0:
+		note = "Warnings as errors: " + strings.Join(s.WarningsAsErrors, ", ")
+	}
+	return note
+}
+
+// runWarningsSuppress implements `lazynuget warnings suppress CODE [--package ID]
+// [--reason TEXT] [--project PATH]`.
+func runWarningsSuppress(_ *cli.Command, values *cli.Values) int {
+	code, path, exitCode := warningTarget(values)
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+	id := values.String("package")
+	if settings, err := project.ReadWarningSettings(path); err == nil {
+		for _, s := range settings.Suppressions {
+			if s.Code == code && (s.Package == "" || strings.EqualFold(s.Package, id)) {
+				infof("%s is already suppressed in %s:%d\n", code, displayPath(s.File), s.Line)
+				return exitcode.Success
+			}
+		}
+	}
+
+	if id != "" {
+		if p, err := project.Load(path); err == nil {
+			if _, ok := p.Reference(id); !ok {
+				fmt.Fprintf(os.Stderr, "Error: %s does not reference %s\n", displayPath(path), id)
+				return exitcode.UserError
+			}
+		}
+	}
+	if _, err := project.SuppressWarning(path, id, code, values.String("reason")); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	if id != "" {
+		fmt.Printf("Suppressed %s for %s in %s\n", code, id, displayPath(path))
+	} else {
+		fmt.Printf("Suppressed %s in %s\n", code, displayPath(path))
+	}
+	return exitcode.Success
+}
+
+// runWarningsUnsuppress implements `lazynuget warnings unsuppress CODE [--package ID]
+// [--project PATH]`.
+func runWarningsUnsuppress(_ *cli.Command, values *cli.Values) int {
+	code, path, exitCode := warningTarget(values)
+	if exitCode != exitcode.Success {
+		return exitCode
+	}
+	id := values.String("package")
+
+	// A project-wide suppression may be in a file the project imports
+	files := []string{path}
+	if id == "" {
+		settings, err := project.ReadWarningSettings(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+		files = nil
+		for _, s := range settings.Suppressions {
+			if s.Code == code && s.Package == "" {
+				files = append(files, s.File)
+			}
+		}
+	}
+
+	removed := false
+	for _, file := range files {
+		ok, err := project.UnsuppressWarning(file, id, code)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+		if ok {
+			removed = true
+			fmt.Printf("Removed %s from %s\n", code, displayPath(file))
+		}
+	}
+	if !removed {
+		infof("%s is not suppressed\n", code)
+	}
+	return exitcode.Success
+}
+
+// warningTarget returns the code and project of suppress and unsuppress.
+func warningTarget(values *cli.Values) (string, string, int) {
+	code, ok := project.NormalizeCode(values.Args()[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: %s is not a NuGet warning code (NU followed by 4 digits)\n", values.Args()[0])
+		return "", "", exitcode.UserError
+	}
+	path, exitCode := bulkProject(values.String("project"))
+	return code, path, exitCode
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/output"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/workloads"
+)
+
+// runWorkloadsList implements `lazynuget workloads list [--json]`.
+func runWorkloadsList(_ *cli.Command, values *cli.Values) int {
+	ctx, stop := interruptContext()
+	defer stop()
+	status, err := workloads.List(ctx, platform.NewProcessSpawner())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	if values.Bool("json") {
+		return writeJSON(workloads.ListKind, status)
+	}
+
+	if len(status.Installed) == 0 {
+		fmt.Println("No workloads installed")
+	}
+	for _, w := range status.Installed {
+		fmt.Println(w)
+	}
+	if len(status.Updates) > 0 {
+		fmt.Println("\nUpdates available (run dotnet workload update):")
+		for _, u := range status.Updates {
+			fmt.Printf("  %s  %s -> %s\n", u.Workload, u.ExistingVersion, u.UpdateVersion)
+		}
+	}
+	return exitcode.Success
+}
+
+// runWorkloadsCheck implements `lazynuget workloads check [--install] [--json] [PROJECT...]`.
+func runWorkloadsCheck(_ *cli.Command, values *cli.Values) int {
+	paths := values.Args()
+	if len(paths) == 0 {
+		var exitCode int
+		if paths, exitCode = workspaceProjects(); exitCode != exitcode.Success {
+			return exitCode
+		}
+	}
+
+	var reqs []workloads.Requirement
+	for p, err := range loadProjects(paths) {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitcode.SystemError
+		}
+		reqs = append(reqs, workloads.Required(p)...)
+	}
+	if len(reqs) == 0 {
+		if values.Bool("json") {
+			return writeJSON(workloads.CheckKind, []workloads.Requirement{})
+		}
+		fmt.Println("No project needs a workload")
+		return exitcode.Success
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	spawner := platform.NewProcessSpawner()
+	status, err := workloads.List(ctx, spawner)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	reqs = workloads.Check(status, reqs)
+	missing := workloads.Missing(reqs)
+
+	if values.Bool("json") {
+		if exitCode := writeJSON(workloads.CheckKind, reqs); exitCode != exitcode.Success {
+			return exitCode
+		}
+	} else {
+		for _, r := range reqs {
+			note := "installed"
+			if !r.Installed {
+				note = "missing"
+			}
+			fmt.Printf("%s  %s  %s  %s\n", displayPath(r.Project), r.Framework, r.Workload, note)
+		}
+	}
+
+	if len(missing) == 0 {
+		return exitcode.Success
+	}
+	if !values.Bool("install") {
+		warnf("missing workloads; install them with `lazynuget workloads check --install` or `dotnet workload install %s`\n",
+			strings.Join(missing, " "))
+		return exitcode.UserError
+	}
+	infof("Installing %s...\n", strings.Join(missing, ", "))
+	if err := workloads.Install(ctx, spawner, missing); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	return exitcode.Success
+}
+
+// writeJSON writes data as a versioned JSON document, at the schema version of
+// --output-version, and returns the exit code.
+func writeJSON(kind string, data any) int {
+	writer, err := output.NewWriter(os.Stdout, outputVersion)
+	if err == nil {
+		err = writer.Write(kind, data)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitcode.SystemError
+	}
+	return exitcode.Success
+}
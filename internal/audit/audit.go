@@ -0,0 +1,121 @@
+// Package audit reports packages with known vulnerabilities, using the report of
+// `dotnet list package --vulnerable` (which needs the projects restored, and queries the
+// vulnerability data of the configured sources), or without the dotnet CLI by checking
+// the packages restore recorded against a feed's advisories.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// Kind identifies audit reports in versioned JSON output.
+const Kind = "audit"
+
+// Vulnerability is a vulnerable package resolved in a project.
+type Vulnerability struct {
+	Project         string `json:"project"`
+	Framework       string `json:"framework"`
+	Package         string `json:"package"`
+	ResolvedVersion string `json:"resolvedVersion"`
+	Transitive      bool   `json:"transitive"` // Not referenced directly
+	Severity        string `json:"severity"`   // Low, Moderate, High, or Critical
+	AdvisoryURL     string `json:"advisoryUrl"`
+}
+
+// Problem is an error or warning dotnet reported instead of results (e.g., a project that
+// has not been restored).
+type Problem struct {
+	Project string `json:"project,omitempty"`
+	Level   string `json:"level"`
+	Text    string `json:"text"`
+}
+
+// Report is the outcome of an audit.
+type Report struct {
+	Vulnerabilities []Vulnerability   `json:"vulnerabilities"`
+	Problems        []Problem         `json:"problems,omitempty"`
+	Identity        []IdentityWarning `json:"identity,omitempty"` // Possible typosquats (see CheckIdentities)
+	Projects        []string          `json:"projects,omitempty"` // Project files audited
+}
+
+// listReport is the output of `dotnet list package --format json`.
+type listReport struct {
+	Problems []Problem `json:"problems"`
+	Projects []struct {
+		Path       string `json:"path"`
+		Frameworks []struct {
+			Framework          string        `json:"framework"`
+			TopLevelPackages   []listPackage `json:"topLevelPackages"`
+			TransitivePackages []listPackage `json:"transitivePackages"`
+		} `json:"frameworks"`
+	} `json:"projects"`
+}
+
+// listPackage is a package in a `dotnet list package` report.
+type listPackage struct {
+	ID              string `json:"id"`
+	ResolvedVersion string `json:"resolvedVersion"`
+	Vulnerabilities []struct {
+		Severity    string `json:"severity"`
+		AdvisoryURL string `json:"advisoryurl"`
+	} `json:"vulnerabilities"`
+}
+
+// Parse reads the JSON output of `dotnet list package --vulnerable --format json`.
+func Parse(data []byte) (*Report, error) {
+	var list listReport
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse the vulnerability report: %w", err)
+	}
+
+	report := &Report{Vulnerabilities: []Vulnerability{}, Problems: list.Problems}
+	for _, p := range list.Projects {
+		report.Projects = append(report.Projects, p.Path)
+		for _, f := range p.Frameworks {
+			add := func(packages []listPackage, transitive bool) {
+				for _, pkg := range packages {
+					for _, v := range pkg.Vulnerabilities {
+						report.Vulnerabilities = append(report.Vulnerabilities, Vulnerability{
+							Project:         p.Path,
+							Framework:       f.Framework,
+							Package:         pkg.ID,
+							ResolvedVersion: pkg.ResolvedVersion,
+							Transitive:      transitive,
+							Severity:        v.Severity,
+							AdvisoryURL:     v.AdvisoryURL,
+						})
+					}
+				}
+			}
+			add(f.TopLevelPackages, false)
+			add(f.TransitivePackages, true)
+		}
+	}
+	return report, nil
+}
+
+// Run audits a project, solution, or directory (empty for the working directory),
+// including transitive packages.
+func Run(ctx context.Context, spawner platform.ProcessSpawner, target string) (*Report, error) {
+	args := []string{"list"}
+	if target != "" {
+		args = append(args, target)
+	}
+	args = append(args, "package", "--vulnerable", "--include-transitive", "--format", "json")
+
+	result, err := spawner.RunContext(ctx, "dotnet", args, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	// Reports with problems exit non-zero but are still JSON
+	report, err := Parse([]byte(result.Stdout))
+	if err != nil && result.ExitCode != 0 {
+		return nil, fmt.Errorf("dotnet list package failed: %s", strings.TrimSpace(result.Stdout+"\n"+result.Stderr))
+	}
+	return report, err
+}
@@ -0,0 +1,467 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/ci"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/resolver"
+)
+
+// TestParse tests reading direct and transitive vulnerabilities and problems
+func TestParse(t *testing.T) {
+	data := []byte(`{
+  "version": 1,
+  "parameters": "--vulnerable --include-transitive",
+  "problems": [
+    {"project": "/src/B/B.csproj", "level": "error", "text": "No assets file was found for ` + "`/src/B/B.csproj`" + `."}
+  ],
+  "projects": [
+    {
+      "path": "/src/A/A.csproj",
+      "frameworks": [
+        {
+          "framework": "net8.0",
+          "topLevelPackages": [
+            {"id": "Newtonsoft.Json", "requestedVersion": "12.0.1", "resolvedVersion": "12.0.1",
+             "vulnerabilities": [{"severity": "High", "advisoryurl": "https://github.com/advisories/GHSA-5crp-9r3c-p9vr"}]},
+            {"id": "Serilog", "requestedVersion": "3.0.0", "resolvedVersion": "3.0.0"}
+          ],
+          "transitivePackages": [
+            {"id": "System.Text.Encodings.Web", "resolvedVersion": "4.7.0",
+             "vulnerabilities": [{"severity": "Critical", "advisoryurl": "https://github.com/advisories/GHSA-ghhp-997w-qr28"}]}
+          ]
+        }
+      ]
+    },
+    {"path": "/src/B/B.csproj"}
+  ]
+}`)
+
+	report, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := []Vulnerability{
+		{Project: "/src/A/A.csproj", Framework: "net8.0", Package: "Newtonsoft.Json", ResolvedVersion: "12.0.1", Severity: "High", AdvisoryURL: "https://github.com/advisories/GHSA-5crp-9r3c-p9vr"},
+		{Project: "/src/A/A.csproj", Framework: "net8.0", Package: "System.Text.Encodings.Web", ResolvedVersion: "4.7.0", Transitive: true, Severity: "Critical", AdvisoryURL: "https://github.com/advisories/GHSA-ghhp-997w-qr28"},
+	}
+	if len(report.Vulnerabilities) != len(want) {
+		t.Fatalf("Vulnerabilities = %+v, want %+v", report.Vulnerabilities, want)
+	}
+	for i := range want {
+		if report.Vulnerabilities[i] != want[i] {
+			t.Errorf("Vulnerabilities[%d] = %+v, want %+v", i, report.Vulnerabilities[i], want[i])
+		}
+	}
+	if len(report.Problems) != 1 || report.Problems[0].Project != "/src/B/B.csproj" || report.Problems[0].Level != "error" {
+		t.Errorf("Problems = %+v", report.Problems)
+	}
+	if len(report.Projects) != 2 || report.Projects[1] != "/src/B/B.csproj" {
+		t.Errorf("Projects = %v", report.Projects)
+	}
+
+	if _, err := Parse([]byte("The command failed")); err == nil {
+		t.Error("Parse() error = nil for text output")
+	}
+}
+
+// fakeSource answers with fixed versions and advisories.
+type fakeSource struct {
+	versions   map[string][]string
+	advisories map[string][]nuget.Advisory
+}
+
+func (f fakeSource) Versions(_ context.Context, id string) ([]string, error) {
+	return f.versions[id], nil
+}
+
+func (f fakeSource) Advisories(_ context.Context, id string) ([]nuget.Advisory, error) {
+	return f.advisories[id], nil
+}
+
+// TestFixVersion tests choosing the lowest version that clears advisories within constraints
+func TestFixVersion(t *testing.T) {
+	available := []string{"13.0.1", "12.0.1", "12.0.3", "12.0.2", "13.0.2-beta1", "11.0.1"}
+	advisories := []nuget.Advisory{{Versions: "(, 12.0.2)"}, {Versions: "[12.0.2, 12.0.2]"}}
+
+	if v, blocked := FixVersion("12.0.1", available, advisories, nil); v != "12.0.3" || blocked != nil {
+		t.Errorf("FixVersion() = %q, %v, want 12.0.3", v, blocked)
+	}
+	// A package in the graph allows only 12.x
+	pinned := []resolver.Constraint{{Package: "A", Range: "[12.0.0, 13.0.0)"}}
+	if v, _ := FixVersion("12.0.1", available, advisories, pinned); v != "12.0.3" {
+		t.Errorf("FixVersion(pinned) = %q, want 12.0.3", v)
+	}
+	exact := []resolver.Constraint{{Package: "B", Range: "[12.0.1]"}}
+	if v, blocked := FixVersion("12.0.1", available, advisories, exact); v != "12.0.3" || len(blocked) != 1 || blocked[0].Package != "B" {
+		t.Errorf("FixVersion(exact) = %q, %v, want 12.0.3 blocked by B", v, blocked)
+	}
+	all := []nuget.Advisory{{Versions: "(, 14.0.0)"}}
+	if v, _ := FixVersion("12.0.1", available, all, nil); v != "" {
+		t.Errorf("FixVersion(unfixed) = %q, want none", v)
+	}
+}
+
+// TestSuggest tests fixes for direct and transitive vulnerabilities across frameworks
+func TestSuggest(t *testing.T) {
+	project := filepath.Join(t.TempDir(), "App.csproj")
+	report := &Report{Vulnerabilities: []Vulnerability{
+		{Project: project, Framework: "net6.0", Package: "Newtonsoft.Json", ResolvedVersion: "11.0.1"},
+		{Project: project, Framework: "net8.0", Package: "Newtonsoft.Json", ResolvedVersion: "12.0.1"},
+		{Project: project, Framework: "net8.0", Package: "System.Text.Encodings.Web", ResolvedVersion: "4.7.0", Transitive: true,
+			AdvisoryURL: "https://github.com/advisories/GHSA-ghhp-997w-qr28"},
+		{Project: project, Framework: "net8.0", Package: "Unknown", ResolvedVersion: "1.0.0"},
+	}}
+	source := fakeSource{
+		versions: map[string][]string{
+			"Newtonsoft.Json":           {"11.0.1", "12.0.1", "13.0.1"},
+			"System.Text.Encodings.Web": {"4.7.0", "4.7.2", "5.0.1"},
+		},
+		advisories: map[string][]nuget.Advisory{
+			"Newtonsoft.Json":           {{Versions: "(, 13.0.1)"}},
+			"System.Text.Encodings.Web": {{Versions: "[4.0.0, 4.5.1)"}, {Versions: "[4.6.0, 4.7.2)"}},
+		},
+	}
+
+	suggestions, err := Suggest(context.Background(), source, report)
+	if err != nil {
+		t.Fatalf("Suggest() error = %v", err)
+	}
+	if len(suggestions) != 3 {
+		t.Fatalf("Suggest() = %+v, want 3 suggestions", suggestions)
+	}
+	if s := suggestions[0]; s.ResolvedVersion != "12.0.1" || s.Version != "13.0.1" || !s.Fixable() ||
+		s.Description != "Update Newtonsoft.Json from 12.0.1 to 13.0.1" {
+		t.Errorf("direct = %+v", s)
+	}
+	if s := suggestions[1]; s.Version != "4.7.2" || !strings.HasPrefix(s.Description, "Add a top-level reference") {
+		t.Errorf("transitive = %+v", s)
+	}
+	if s := suggestions[2]; s.Fixable() || !strings.Contains(s.Description, "no advisory data") {
+		t.Errorf("no advisories = %+v", s)
+	}
+	if fix := suggestions[0].Fix(); fix.Reason != "" {
+		t.Errorf("direct Fix().Reason = %q, want none", fix.Reason)
+	}
+	if fix := suggestions[1].Fix(); fix.Project != project || fix.Package != "System.Text.Encodings.Web" || fix.Version != "4.7.2" ||
+		fix.Reason != "Pinned to override the vulnerable transitive version 4.7.0 (https://github.com/advisories/GHSA-ghhp-997w-qr28)" {
+		t.Errorf("Fix() = %+v", fix)
+	}
+}
+
+// fakeMetadata answers with fixed licenses, publish dates, and releases.
+type fakeMetadata struct {
+	licenses  map[string]string
+	published map[string]time.Time
+	releases  map[string][]nuget.Release
+}
+
+func (f fakeMetadata) Nuspec(_ context.Context, _, id, _ string) (*nuget.Nuspec, error) {
+	return &nuget.Nuspec{ID: id, License: f.licenses[id]}, nil
+}
+
+func (f fakeMetadata) Published(_ context.Context, id, _ string) (time.Time, error) {
+	return f.published[id], nil
+}
+
+func (f fakeMetadata) Releases(_ context.Context, id string) ([]nuget.Release, error) {
+	return f.releases[id], nil
+}
+
+// TestPolicy tests loading a policy file and checking an audit and its packages against it
+func TestPolicy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.yml")
+	if err := os.WriteFile(path, []byte(`maxSeverity: moderate
+bannedPackages:
+  - package: Legacy.*
+    reason: use Modern.Client instead
+licenses: [MIT, Apache-2.0]
+maxAgeDays: 365
+maxInactiveYears: 2
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := LoadPolicy(path)
+	if err != nil {
+		t.Fatalf("LoadPolicy() error = %v", err)
+	}
+	for _, invalid := range []string{"maxSeverity: severe\n", "maxAge: 30\n", "maxInactiveYears: -1\n", "bannedPackages:\n  - reason: x\n"} {
+		if err := os.WriteFile(path, []byte(invalid), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPolicy(path); err == nil {
+			t.Errorf("LoadPolicy(%q) error = nil", invalid)
+		}
+	}
+
+	project := filepath.Join(dir, "App.csproj")
+	if err := os.WriteFile(project, []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Legacy.Client" Version="1.0.0" />
+    <PackageReference Include="Newtonsoft.Json" Version="12.0.1" />
+  </ItemGroup>
+</Project>
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "obj"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "obj", "project.assets.json"), []byte(`{
+  "version": 3,
+  "targets": {
+    "net8.0": {
+      "Legacy.Client/1.0.0": {"type": "package", "dependencies": {"Old.Util": "2.0.0"}},
+      "Newtonsoft.Json/12.0.1": {"type": "package"},
+      "Old.Util/2.0.0": {"type": "package"},
+      "Microsoft.NETCore.App.Ref/8.0.0": {"type": "package"},
+      "Lib/1.0.0": {"type": "project"}
+    }
+  }
+}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	packages := Inventory([]string{project, filepath.Join(dir, "Lib", "Lib.csproj")})
+	if len(packages) != 4 || packages[0].ID != "Legacy.Client" || packages[0].Transitive || !packages[3].Transitive {
+		t.Fatalf("Inventory() = %+v", packages)
+	}
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	meta := fakeMetadata{
+		licenses:  map[string]string{"Legacy.Client": "MIT", "Newtonsoft.Json": "MIT", "Old.Util": "GPL-3.0-only OR MIT"},
+		published: map[string]time.Time{"Legacy.Client": now.AddDate(-3, 0, 0), "Newtonsoft.Json": now.AddDate(0, -2, 0)},
+		releases: map[string][]nuget.Release{
+			"Newtonsoft.Json": {{Version: "13.0.3", Published: now.AddDate(-1, 0, 0)}},
+			"Old.Util":        {{Version: "2.0.0", Published: now.AddDate(-4, 0, 0)}, {Version: "2.1.0", Published: now.AddDate(-3, 0, 0)}},
+		},
+	}
+	report := &Report{Vulnerabilities: []Vulnerability{
+		{Project: project, Framework: "net8.0", Package: "Newtonsoft.Json", ResolvedVersion: "12.0.1", Severity: "High", AdvisoryURL: "https://example.com/a"},
+		{Project: project, Framework: "net6.0", Package: "Newtonsoft.Json", ResolvedVersion: "12.0.1", Severity: "High", AdvisoryURL: "https://example.com/a"},
+		{Project: project, Framework: "net8.0", Package: "Old.Util", ResolvedVersion: "2.0.0", Transitive: true, Severity: "Low", AdvisoryURL: "https://example.com/b"},
+	}}
+	violations, err := policy.Evaluate(context.Background(), meta, "", report, packages, now)
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	var got []string
+	for _, v := range violations {
+		got = append(got, v.Rule+":"+v.Package)
+	}
+	// Old.Util's vulnerability is allowed, as is its license under OR; platform packs are exempt
+	want := []string{"max-severity:Newtonsoft.Json", "banned-package:Legacy.Client", "max-age:Legacy.Client", "stale-package:Old.Util"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Evaluate() = %v, want %v", got, want)
+	}
+	if !strings.Contains(violations[1].Message, "use Modern.Client instead") {
+		t.Errorf("banned message = %q", violations[1].Message)
+	}
+
+	checks := Checks(report, packages, violations)
+	if len(checks) != 4 || len(checks[0].Findings) != 2 || len(checks[2].Findings) != 2 || len(checks[3].Findings) != 2 ||
+		checks[3].Findings[0].Level != ci.LevelNote || checks[3].Findings[1].Rule != RuleStale || len(checks[1].Findings) != 0 {
+		t.Errorf("Checks() = %+v", checks)
+	}
+
+	data, err := SARIF(violations, dir, "1.0.0")
+	if err != nil {
+		t.Fatalf("SARIF() error = %v", err)
+	}
+	var log struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct{ Rules []struct{ ID string } }
+			}
+			Results []struct {
+				RuleID    string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+						Region           *struct{ StartLine int }
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatal(err)
+	}
+	results := log.Runs[0].Results
+	if log.Version != "2.1.0" || len(log.Runs[0].Tool.Driver.Rules) != len(PolicyRules) || len(results) != 4 {
+		t.Fatalf("SARIF() = %s", data)
+	}
+	location := results[1].Locations[0].PhysicalLocation
+	if results[1].RuleID != RuleBanned || location.ArtifactLocation.URI != "App.csproj" || location.Region == nil || location.Region.StartLine != 3 {
+		t.Errorf("banned result = %+v", results[1])
+	}
+}
+
+// TestLicenseAllowed tests matching SPDX license expressions against allowed licenses
+func TestLicenseAllowed(t *testing.T) {
+	allowed := []string{"MIT", "Apache-2.0"}
+	tests := map[string]bool{
+		"MIT":                                  true,
+		"mit":                                  true,
+		"GPL-3.0-only":                         false,
+		"GPL-3.0-only OR MIT":                  true,
+		"MIT AND BSD-3-Clause":                 false,
+		"(MIT AND Apache-2.0) OR GPL-2.0":      true,
+		"Apache-2.0 WITH LLVM-exception":       true,
+		"BSD-2-Clause or (MIT and Apache-2.0)": true,
+	}
+	for expression, want := range tests {
+		if got := LicenseAllowed(expression, allowed); got != want {
+			t.Errorf("LicenseAllowed(%q) = %v, want %v", expression, got, want)
+		}
+	}
+}
+
+// TestScan tests auditing restored and unrestored projects without dotnet
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	restored := filepath.Join(dir, "A", "A.csproj")
+	unrestored := filepath.Join(dir, "B", "B.csproj")
+	for path, refs := range map[string]string{
+		restored:   `<PackageReference Include="Serilog" Version="3.0.0" />`,
+		unrestored: `<PackageReference Include="Newtonsoft.Json" Version="12.0.1" /><PackageReference Include="Floating" Version="1.*" />`,
+	} {
+		if err := os.MkdirAll(filepath.Join(filepath.Dir(path), "obj"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(`<Project Sdk="Microsoft.NET.Sdk"><ItemGroup>`+refs+`</ItemGroup></Project>`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(resolver.AssetsPath(restored), []byte(`{
+  "version": 3,
+  "targets": {
+    "net8.0": {
+      "Serilog/3.0.0": {"type": "package", "dependencies": {"System.Text.Encodings.Web": "4.7.0"}},
+      "System.Text.Encodings.Web/4.7.0": {"type": "package"}
+    }
+  }
+}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	source := fakeSource{advisories: map[string][]nuget.Advisory{
+		"Newtonsoft.Json":           {{URL: "https://example.com/a", Severity: "High", Versions: "(, 13.0.1)"}},
+		"System.Text.Encodings.Web": {{URL: "https://example.com/b", Severity: "Critical", Versions: "[4.6.0, 4.7.2)"}},
+		"Floating":                  {{URL: "https://example.com/c", Severity: "Low", Versions: "(, 2.0.0)"}},
+	}}
+	report, err := Scan(context.Background(), source, []string{restored, unrestored})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	want := []Vulnerability{
+		{Project: restored, Package: "System.Text.Encodings.Web", ResolvedVersion: "4.7.0", Transitive: true, Severity: "Critical", AdvisoryURL: "https://example.com/b"},
+		{Project: unrestored, Package: "Newtonsoft.Json", ResolvedVersion: "12.0.1", Severity: "High", AdvisoryURL: "https://example.com/a"},
+	}
+	if len(report.Vulnerabilities) != len(want) {
+		t.Fatalf("Vulnerabilities = %+v, want %+v", report.Vulnerabilities, want)
+	}
+	for i := range want {
+		if report.Vulnerabilities[i] != want[i] {
+			t.Errorf("Vulnerabilities[%d] = %+v, want %+v", i, report.Vulnerabilities[i], want[i])
+		}
+	}
+	if len(report.Problems) != 1 || report.Problems[0].Project != unrestored || report.Problems[0].Level != "warning" {
+		t.Errorf("Problems = %+v, want a warning for the unrestored project", report.Problems)
+	}
+}
+
+// TestLookAlike tests the typosquatting heuristics
+func TestLookAlike(t *testing.T) {
+	tests := map[string]string{
+		"Newtonsoft.Json":              "",
+		"newtonsoft.json":              "",
+		"Newtonsoft.Jsno":              "Newtonsoft.Json",
+		"Newtonsof.Json":               "Newtonsoft.Json",
+		"Newtons0ft-Json":              "Newtonsoft.Json",
+		"NewtonsoftJson":               "Newtonsoft.Json",
+		"Microsoft.Extensions.Logglng": "Microsoft.Extensions.Logging",
+		"Serilog.Sinks.Seq":            "",
+		"Serilog.Sinks.Console":        "",
+		"Moqq":                         "", // Too short to judge by one edit
+		"AutoMapper.Extensions.Microsoft.DependencyInjection": "",
+		"Rnoq":           "Moq", // rn reads as m
+		"Dapper.Contrib": "",
+	}
+	for id, want := range tests {
+		if got := LookAlike(id); got != want {
+			t.Errorf("LookAlike(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+// identitySource is an IdentitySource of canned lookups.
+type identitySource struct {
+	results map[string]*nuget.SearchResult
+	lookups int
+}
+
+func (s *identitySource) Lookup(_ context.Context, id string) (*nuget.SearchResult, error) {
+	s.lookups++
+	return s.results[strings.ToLower(id)], nil
+}
+
+// TestCheckIdentities tests look-alike and unverified prefix warnings
+func TestCheckIdentities(t *testing.T) {
+	source := &identitySource{results: map[string]*nuget.SearchResult{
+		"newtonsoft.jsno":         {ID: "Newtonsoft.Jsno", Owners: []string{"mallory"}},
+		"microsoft.extensions.ai": {ID: "Microsoft.Extensions.AI", Verified: true},
+		"microsoft.contoso":       {ID: "Microsoft.Contoso", Owners: []string{"contoso"}},
+		"serilog.sinks.consol":    {ID: "Serilog.Sinks.Consol", Verified: true},
+	}}
+	packages := []Package{
+		{Project: "A.csproj", ID: "Newtonsoft.Jsno", Version: "13.0.1"},
+		{Project: "B.csproj", ID: "Newtonsoft.Jsno", Version: "13.0.1", Transitive: true},
+		{Project: "A.csproj", ID: "Microsoft.Extensions.AI", Version: "9.0.0"},
+		{Project: "A.csproj", ID: "Microsoft.Contoso", Version: "1.0.0"},
+		{Project: "A.csproj", ID: "Microsoft.Transitive", Version: "1.0.0", Transitive: true},
+		{Project: "A.csproj", ID: "Serilog.Sinks.Consol", Version: "1.0.0"},
+		{Project: "A.csproj", ID: "Serilog", Version: "4.0.0"},
+	}
+	warnings, err := CheckIdentities(context.Background(), source, packages)
+	if err != nil {
+		t.Fatalf("CheckIdentities() error = %v", err)
+	}
+	var got []string
+	for _, w := range warnings {
+		got = append(got, w.Project+" "+w.Package+" "+w.Rule)
+	}
+	want := []string{
+		"A.csproj Newtonsoft.Jsno " + RuleLookAlike,
+		"B.csproj Newtonsoft.Jsno " + RuleLookAlike,
+		"A.csproj Microsoft.Contoso " + RuleUnverifiedPrefix,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("CheckIdentities() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if w := warnings[0]; w.LooksLike != "Newtonsoft.Json" || !strings.Contains(w.Message, "owned by mallory") {
+		t.Errorf("warnings[0] = %+v", w)
+	}
+	if source.lookups != 4 {
+		t.Errorf("lookups = %d, want one per package reported or checked", source.lookups)
+	}
+
+	// Without a source, look-alikes are reported from the IDs alone
+	warnings, err = CheckIdentities(context.Background(), nil, packages[:4])
+	if err != nil || len(warnings) != 2 {
+		t.Errorf("CheckIdentities(nil) = %+v, %v, want the two look-alikes", warnings, err)
+	}
+
+	checks := Checks(&Report{Identity: warnings}, nil, nil)
+	if len(checks) != 2 || checks[0].Findings[0].Level != ci.LevelWarning {
+		t.Errorf("Checks() = %+v, want warnings", checks)
+	}
+}
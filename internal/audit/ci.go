@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/ci"
+)
+
+// RuleVulnerability is the rule of vulnerable packages in CI reports.
+const RuleVulnerability = "vulnerable-package"
+
+// PolicyRules describe the policy rules to CI systems.
+var PolicyRules = []ci.Rule{
+	{ID: RuleSeverity, Name: "VulnerabilitySeverity", Description: "A package has a vulnerability more severe than the policy allows"},
+	{ID: RuleBanned, Name: "BannedPackage", Description: "A project uses a package the policy bans"},
+	{ID: RuleLicense, Name: "DisallowedLicense", Description: "A package's license is not one the policy allows"},
+	{ID: RuleAge, Name: "PackageAge", Description: "A package version was published longer ago than the policy allows"},
+	{ID: RuleStale, Name: "StalePackage", Description: "A package has had no release for longer than the policy allows"},
+}
+
+// Rules describe the findings of audits to CI systems.
+var Rules = append([]ci.Rule{
+	{ID: RuleVulnerability, Name: "VulnerablePackage", Description: "A package has a known vulnerability"},
+	{ID: RuleLookAlike, Name: "LookAlikePackage", Description: "A package's ID resembles a popular package's and may be a typosquat"},
+	{ID: RuleUnverifiedPrefix, Name: "UnverifiedPrefix", Description: "A package uses a vendor's reserved ID prefix without being verified"},
+}, PolicyRules...)
+
+// severityLevels map advisory severities to finding levels; unknown severities are errors.
+var severityLevels = map[string]ci.Level{"low": ci.LevelNote, "moderate": ci.LevelWarning, "high": ci.LevelError, "critical": ci.LevelError}
+
+// Checks returns an audit as CI checks: one per package the projects resolve, failing
+// with the package's vulnerabilities and policy violations.
+func Checks(report *Report, packages []Package, violations []Violation) []ci.Check {
+	var checks []ci.Check
+	index := make(map[string]int) // Project, package, and version -> index in checks
+	check := func(project, id, version string) *ci.Check {
+		key := project + "|" + strings.ToLower(id) + "|" + strings.ToLower(version)
+		i, ok := index[key]
+		if !ok {
+			i = len(checks)
+			index[key] = i
+			checks = append(checks, ci.Check{Project: project, Package: id, Version: version})
+		}
+		return &checks[i]
+	}
+	add := func(c *ci.Check, f ci.Finding) {
+		if !slices.Contains(c.Findings, f) {
+			c.Findings = append(c.Findings, f)
+		}
+	}
+
+	for _, p := range packages {
+		check(p.Project, p.ID, p.Version)
+	}
+	for _, v := range report.Vulnerabilities {
+		level, ok := severityLevels[strings.ToLower(v.Severity)]
+		if !ok {
+			level = ci.LevelError
+		}
+		add(check(v.Project, v.Package, v.ResolvedVersion), ci.Finding{Rule: RuleVulnerability, Level: level,
+			Message: v.Package + " " + v.ResolvedVersion + " has a " + v.Severity + " vulnerability (" + v.AdvisoryURL + ")"})
+	}
+	for _, w := range report.Identity {
+		add(check(w.Project, w.Package, w.Version), ci.Finding{Rule: w.Rule, Level: ci.LevelWarning, Message: w.Message})
+	}
+	for _, v := range violations {
+		add(check(v.Project, v.Package, v.Version), ci.Finding{Rule: v.Rule, Level: ci.LevelError, Message: v.Message})
+	}
+	return checks
+}
+
+// SARIF returns policy violations as a SARIF 2.1.0 log for GitHub code scanning, with
+// project paths relative to the repository root.
+func SARIF(violations []Violation, root, version string) ([]byte, error) {
+	var buf bytes.Buffer
+	err := ci.WriteSARIF(&buf, &ci.Report{Name: Kind, Version: version, Root: root, Rules: PolicyRules,
+		Checks: Checks(&Report{}, nil, violations)})
+	return buf.Bytes(), err
+}
@@ -0,0 +1,188 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/resolver"
+)
+
+// Source is where fixes are looked up; *nuget.Feed implements it.
+type Source interface {
+	Versions(ctx context.Context, id string) ([]string, error)
+	Advisories(ctx context.Context, id string) ([]nuget.Advisory, error)
+}
+
+// Suggestion is the smallest upgrade of a vulnerable package that clears its advisories.
+type Suggestion struct {
+	Project         string `json:"project"`
+	Package         string `json:"package"`
+	ResolvedVersion string `json:"resolvedVersion"`
+	Transitive      bool   `json:"transitive"`
+	// Advisories are the URLs of the advisories the audit reported
+	Advisories []string `json:"advisories"`
+	// Details are the advisories affecting ResolvedVersion, with CVSS scores and references
+	// when OSV.dev knows them
+	Details []nuget.Advisory `json:"details"`
+	// Version is the lowest version no advisory affects, or "" when there is none
+	Version string `json:"version,omitempty"`
+	// BlockedBy lists the packages in the graph whose ranges exclude Version
+	BlockedBy   []resolver.Constraint `json:"blockedBy,omitempty"`
+	Description string                `json:"description"`
+}
+
+// Fixable reports whether applying the suggestion clears the vulnerability without
+// breaking another package's requirement.
+func (s Suggestion) Fixable() bool {
+	return s.Version != "" && len(s.BlockedBy) == 0
+}
+
+// Fix returns the project edit that applies the suggestion: an update of a direct
+// reference, or a top-level reference that pins a transitive package, with a comment
+// naming the advisories it clears.
+func (s Suggestion) Fix() resolver.Fix {
+	fix := resolver.Fix{Project: s.Project, Package: s.Package, Version: s.Version, Description: s.Description}
+	if s.Transitive {
+		fix.Reason = fmt.Sprintf("Pinned to override the vulnerable transitive version %s", s.ResolvedVersion)
+		if len(s.Advisories) > 0 {
+			fix.Reason += " (" + strings.Join(s.Advisories, ", ") + ")"
+		}
+	}
+	return fix
+}
+
+// FixVersion returns the lowest version above current that no advisory affects and every
+// constraint allows. Prereleases are considered only when current is one. When every
+// clearing version breaks a constraint, it returns the lowest clearing version and the
+// constraints it breaks; when no version clears the advisories, it returns "".
+func FixVersion(current string, available []string, advisories []nuget.Advisory, constraints []resolver.Constraint) (string, []resolver.Constraint) {
+	candidates := slices.Clone(available)
+	slices.SortFunc(candidates, nuget.CompareVersions)
+
+	first := ""
+	var blocked []resolver.Constraint
+	for _, v := range candidates {
+		if nuget.CompareVersions(v, current) <= 0 || (nuget.IsPrerelease(v) && !nuget.IsPrerelease(current)) {
+			continue
+		}
+		if slices.ContainsFunc(advisories, func(a nuget.Advisory) bool { return a.Affects(v) }) {
+			continue
+		}
+		broken := brokenConstraints(v, constraints)
+		if len(broken) == 0 {
+			return v, nil
+		}
+		if first == "" {
+			first, blocked = v, broken
+		}
+	}
+	return first, blocked
+}
+
+// brokenConstraints returns the constraints whose range excludes a version. Ranges that
+// do not parse are ignored.
+func brokenConstraints(version string, constraints []resolver.Constraint) []resolver.Constraint {
+	var broken []resolver.Constraint
+	for _, c := range constraints {
+		r, err := nuget.ParseVersionRange(c.Range)
+		if err == nil && !r.WithinBounds(version) {
+			broken = append(broken, c)
+		}
+	}
+	return broken
+}
+
+// Suggest proposes a fix for each vulnerable package in each project of a report, using
+// the dependency graph restore recorded to keep fixes within the ranges other packages
+// request. A package resolved at several versions (per target framework) is fixed from
+// the highest.
+func Suggest(ctx context.Context, source Source, report *Report) ([]Suggestion, error) {
+	var suggestions []Suggestion
+	index := make(map[string]int) // project + package -> suggestion
+	for _, v := range report.Vulnerabilities {
+		key := v.Project + "\x00" + strings.ToLower(v.Package)
+		i, ok := index[key]
+		if !ok {
+			i = len(suggestions)
+			index[key] = i
+			suggestions = append(suggestions, Suggestion{Project: v.Project, Package: v.Package, Transitive: true, Advisories: []string{}, Details: []nuget.Advisory{}})
+		}
+		s := &suggestions[i]
+		if nuget.CompareVersions(v.ResolvedVersion, s.ResolvedVersion) > 0 {
+			s.ResolvedVersion = v.ResolvedVersion
+		}
+		// A package referenced directly by any framework is updated in place
+		s.Transitive = s.Transitive && v.Transitive
+		if v.AdvisoryURL != "" && !slices.Contains(s.Advisories, v.AdvisoryURL) {
+			s.Advisories = append(s.Advisories, v.AdvisoryURL)
+		}
+	}
+
+	type packageData struct {
+		versions   []string
+		advisories []nuget.Advisory
+	}
+	packages := make(map[string]packageData) // Projects in a solution share vulnerable packages
+	graphs := make(map[string]*resolver.Assets)
+	for i := range suggestions {
+		s := &suggestions[i]
+		key := strings.ToLower(s.Package)
+		data, ok := packages[key]
+		if !ok {
+			var err error
+			if data.advisories, err = source.Advisories(ctx, s.Package); err != nil {
+				return nil, err
+			}
+			if data.versions, err = source.Versions(ctx, s.Package); err != nil {
+				return nil, err
+			}
+			packages[key] = data
+		}
+		for _, a := range data.advisories {
+			if a.Affects(s.ResolvedVersion) {
+				s.Details = append(s.Details, a)
+			}
+		}
+		if len(data.advisories) == 0 {
+			s.Description = fmt.Sprintf("The feed has no advisory data for %s; check the advisory for a fixed version", s.Package)
+			continue
+		}
+
+		assets, ok := graphs[s.Project]
+		if !ok {
+			assets, _ = resolver.LoadAssets(resolver.AssetsPath(s.Project))
+			graphs[s.Project] = assets
+		}
+		var constraints []resolver.Constraint
+		if assets != nil {
+			constraints = assets.Constraints(s.Package)
+		}
+
+		s.Version, s.BlockedBy = FixVersion(s.ResolvedVersion, data.versions, data.advisories, constraints)
+		s.Description = describe(s)
+	}
+	return suggestions, nil
+}
+
+// describe explains a suggestion.
+func describe(s *Suggestion) string {
+	switch {
+	case s.Version == "":
+		return fmt.Sprintf("No version of %s without known vulnerabilities is available", s.Package)
+	case len(s.BlockedBy) > 0:
+		var requirements []string
+		for _, c := range s.BlockedBy {
+			requirements = append(requirements, fmt.Sprintf("%s requires %s", c.Package, c.Range))
+		}
+		return fmt.Sprintf("%s %s clears the advisories, but %s; update those packages first",
+			s.Package, s.Version, strings.Join(requirements, ", "))
+	case s.Transitive:
+		return fmt.Sprintf("Add a top-level reference to %s %s to override the vulnerable %s",
+			s.Package, s.Version, s.ResolvedVersion)
+	default:
+		return fmt.Sprintf("Update %s from %s to %s", s.Package, s.ResolvedVersion, s.Version)
+	}
+}
@@ -0,0 +1,199 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+)
+
+// Identity rules, as reported in identity warnings and CI reports.
+const (
+	RuleLookAlike        = "look-alike-package" // An ID that resembles a popular package's
+	RuleUnverifiedPrefix = "unverified-prefix"  // A vendor's prefix on a package it does not verify
+)
+
+// PopularPackages are widely used package IDs that typosquatters imitate. A package whose
+// ID is one character away from one of these, or spelled the same with look-alike
+// characters or separators, is reported.
+var PopularPackages = []string{
+	"AutoMapper", "Azure.Core", "Azure.Identity", "Azure.Storage.Blobs", "AWSSDK.Core",
+	"BouncyCastle.Cryptography", "Castle.Core", "CsvHelper", "Dapper", "DotNetEnv", "EntityFramework",
+	"FluentAssertions", "FluentValidation", "Google.Protobuf", "Grpc.Net.Client", "Hangfire", "HtmlAgilityPack",
+	"Humanizer", "MailKit", "MediatR", "MessagePack", "Microsoft.Data.SqlClient", "Microsoft.EntityFrameworkCore",
+	"Microsoft.Extensions.DependencyInjection", "Microsoft.Extensions.Hosting", "Microsoft.Extensions.Logging",
+	"Microsoft.Identity.Client", "Microsoft.NET.Test.Sdk", "MimeKit", "Moq", "MongoDB.Driver", "MySql.Data",
+	"NLog", "NSubstitute", "NUnit", "Newtonsoft.Json", "Npgsql", "Polly", "Quartz", "RabbitMQ.Client",
+	"RestSharp", "Serilog", "Serilog.Sinks.Console", "Serilog.Sinks.File", "SharpZipLib", "SixLabors.ImageSharp",
+	"StackExchange.Redis", "Swashbuckle.AspNetCore", "System.Text.Json", "xunit", "xunit.runner.visualstudio",
+	"YamlDotNet",
+}
+
+// VendorPrefixes are ID prefixes their vendors have reserved on nuget.org. A package with
+// one of these prefixes that nuget.org does not show as verified was not published by the
+// vendor.
+var VendorPrefixes = []string{"Microsoft.", "System.", "Azure.", "AWSSDK.", "Google."}
+
+// IdentitySource looks up a package's owners and prefix reservation; *nuget.Feed
+// implements it. A nil result means the feed cannot say.
+type IdentitySource interface {
+	Lookup(ctx context.Context, id string) (*nuget.SearchResult, error)
+}
+
+// IdentityWarning is a package whose ID may not be the package the project meant to use.
+type IdentityWarning struct {
+	Rule       string   `json:"rule"`
+	Project    string   `json:"project"`
+	Package    string   `json:"package"`
+	Version    string   `json:"version"`
+	LooksLike  string   `json:"looksLike,omitempty"` // The popular package it resembles
+	Owners     []string `json:"owners,omitempty"`
+	Transitive bool     `json:"transitive"`
+	Message    string   `json:"message"`
+}
+
+// LookAlike returns the popular package an ID resembles without being it, or "".
+func LookAlike(id string) string {
+	lower := strings.ToLower(id)
+	for _, popular := range PopularPackages {
+		if strings.EqualFold(popular, id) {
+			return ""
+		}
+	}
+	for _, popular := range PopularPackages {
+		p := strings.ToLower(popular)
+		if skeleton(lower) == skeleton(p) {
+			return popular
+		}
+		// One edit away, except a package of the popular one's family (Serilog.Sinks.X)
+		if len(p) >= 6 && !strings.HasPrefix(lower, p+".") && editDistance(lower, p) == 1 {
+			return popular
+		}
+	}
+	return ""
+}
+
+// skeleton returns an ID with separators removed and look-alike characters replaced, so
+// IDs that read the same compare equal ("Newtons0ft-Json" and "Newtonsoft.Json").
+func skeleton(id string) string {
+	return strings.NewReplacer(".", "", "-", "", "_", "", "0", "o", "1", "l", "rn", "m", "vv", "w").Replace(id)
+}
+
+// editDistance returns the Damerau-Levenshtein distance (optimal string alignment) of two
+// strings: the insertions, deletions, substitutions, and swaps of adjacent characters
+// that turn one into the other.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// vendorPrefix returns the vendor prefix an ID starts with, or "".
+func vendorPrefix(id string) string {
+	for _, prefix := range VendorPrefixes {
+		if len(id) > len(prefix) && strings.EqualFold(id[:len(prefix)], prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// CheckIdentities reports packages that may be typosquats: IDs resembling a popular
+// package's, and direct references with a vendor's prefix that the feed does not show as
+// verified. The owners of the packages reported are looked up in source, once per
+// package, which may be nil to check the IDs alone; a look-alike whose prefix is
+// verified is its owner's and is not reported.
+func CheckIdentities(ctx context.Context, source IdentitySource, packages []Package) ([]IdentityWarning, error) {
+	lookups := make(map[string]*nuget.SearchResult)
+	lookup := func(id string) (*nuget.SearchResult, error) {
+		key := strings.ToLower(id)
+		if r, ok := lookups[key]; ok || source == nil {
+			return r, nil
+		}
+		r, err := source.Lookup(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up the owners of %s: %w", id, err)
+		}
+		lookups[key] = r
+		return r, nil
+	}
+
+	warnings := []IdentityWarning{}
+	for _, p := range packages {
+		looksLike := LookAlike(p.ID)
+		prefix := ""
+		if !p.Transitive {
+			prefix = vendorPrefix(p.ID)
+		}
+		if looksLike == "" && prefix == "" {
+			continue
+		}
+		info, err := lookup(p.ID)
+		if err != nil {
+			return nil, err
+		}
+		if info != nil && info.Verified {
+			continue
+		}
+		w := IdentityWarning{Project: p.Project, Package: p.ID, Version: p.Version, Transitive: p.Transitive, LooksLike: looksLike}
+		owned := ""
+		if info != nil {
+			w.Owners = info.Owners
+			if len(info.Owners) > 0 {
+				owned = " (owned by " + strings.Join(info.Owners, ", ") + ")"
+			}
+		}
+		switch {
+		case looksLike != "":
+			w.Rule = RuleLookAlike
+			w.Message = fmt.Sprintf("%s%s looks like %s; check that it is the package you meant", p.ID, owned, looksLike)
+		case info != nil:
+			w.Rule = RuleUnverifiedPrefix
+			w.Message = fmt.Sprintf("%s%s uses the reserved %s prefix but is not verified as published by the prefix's owner", p.ID, owned, strings.TrimSuffix(prefix, "."))
+		default:
+			continue // The feed cannot tell whether the prefix is verified
+		}
+		if !slices.ContainsFunc(warnings, func(o IdentityWarning) bool {
+			return o.Project == w.Project && o.Rule == w.Rule && o.Package == w.Package
+		}) {
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings, nil
+}
+
+// References returns the packages of projects to check the identities of: those restore
+// recorded, or the direct references of projects that have not been restored.
+func References(projects []string) []Package {
+	var packages []Package
+	for _, path := range projects {
+		if restored := Inventory([]string{path}); len(restored) > 0 {
+			packages = append(packages, restored...)
+			continue
+		}
+		direct, _ := directPackages(path)
+		packages = append(packages, direct...)
+	}
+	return packages
+}
@@ -0,0 +1,312 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/project"
+	"github.com/willibrandon/lazynuget/internal/resolver"
+)
+
+// Policy rules, as reported in violations and SARIF results.
+const (
+	RuleSeverity = "max-severity"   // A vulnerability above the allowed severity
+	RuleBanned   = "banned-package" // A package the policy bans
+	RuleLicense  = "license"        // A license the policy does not allow
+	RuleAge      = "max-age"        // A package version older than allowed
+	RuleStale    = "stale-package"  // A package without a release for longer than allowed
+)
+
+// severityRanks orders the severities a policy can allow; "none" allows no vulnerability.
+var severityRanks = map[string]int{"none": 0, "low": 1, "moderate": 2, "high": 3, "critical": 4}
+
+// Policy is a repository's rules for the packages its projects use, checked in CI with
+// `lazynuget audit --policy`. The zero value allows everything.
+type Policy struct {
+	// MaxSeverity is the highest vulnerability severity allowed: none, low, moderate,
+	// high, or critical; empty allows any
+	MaxSeverity string `yaml:"maxSeverity"`
+	// BannedPackages may not be used, directly or transitively
+	BannedPackages []BannedPackage `yaml:"bannedPackages"`
+	// Licenses are the SPDX license IDs allowed; empty allows any
+	Licenses []string `yaml:"licenses"`
+	// MaxAgeDays is how long ago a package version may have been published; 0 allows any
+	MaxAgeDays int `yaml:"maxAgeDays"`
+	// MaxInactiveYears is how long a package may go without a new release, whichever
+	// version is used; 0 allows any
+	MaxInactiveYears int `yaml:"maxInactiveYears"`
+}
+
+// BannedPackage is a package a policy bans.
+type BannedPackage struct {
+	Package string `yaml:"package"` // Package ID; a trailing * matches a prefix (e.g., "Microsoft.Azure.*")
+	Reason  string `yaml:"reason"`  // Shown in violations (e.g., what to use instead)
+}
+
+// LoadPolicy reads a policy file. Unknown keys and invalid values are errors: a CI gate
+// must not pass because it misread its rules.
+func LoadPolicy(path string) (*Policy, error) {
+	// #nosec G304 -- path is the policy file the user passed
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+	var p Policy
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+
+	if _, ok := severityRanks[strings.ToLower(p.MaxSeverity)]; p.MaxSeverity != "" && !ok {
+		return nil, fmt.Errorf("invalid policy file %s: maxSeverity %q must be none, low, moderate, high, or critical", path, p.MaxSeverity)
+	}
+	if p.MaxAgeDays < 0 {
+		return nil, fmt.Errorf("invalid policy file %s: maxAgeDays must not be negative", path)
+	}
+	if p.MaxInactiveYears < 0 {
+		return nil, fmt.Errorf("invalid policy file %s: maxInactiveYears must not be negative", path)
+	}
+	for _, b := range p.BannedPackages {
+		if strings.TrimSpace(b.Package) == "" {
+			return nil, fmt.Errorf("invalid policy file %s: a banned package has no package ID", path)
+		}
+	}
+	return &p, nil
+}
+
+// Package is a package a project resolves.
+type Package struct {
+	Project    string
+	ID         string
+	Version    string
+	Transitive bool
+}
+
+// Inventory returns the packages each project resolves, from the dependency graph restore
+// recorded. Projects that have not been restored are left out; audits need them restored.
+func Inventory(projects []string) []Package {
+	var packages []Package
+	for _, path := range projects {
+		assets, err := resolver.LoadAssets(resolver.AssetsPath(path))
+		if err != nil {
+			continue
+		}
+		direct := make(map[string]bool)
+		if p, err := project.Load(path); err == nil {
+			for _, ref := range p.PackageReferences {
+				direct[strings.ToLower(ref.ID)] = true
+			}
+		}
+
+		var found []Package
+		for _, libraries := range assets.Targets {
+			for key, lib := range libraries {
+				id, version, ok := strings.Cut(key, "/")
+				if !ok || (lib.Type != "" && lib.Type != "package") {
+					continue
+				}
+				pkg := Package{Project: path, ID: id, Version: version, Transitive: !direct[strings.ToLower(id)]}
+				if !slices.Contains(found, pkg) {
+					found = append(found, pkg)
+				}
+			}
+		}
+		slices.SortFunc(found, func(a, b Package) int {
+			return strings.Compare(strings.ToLower(a.ID)+"/"+a.Version, strings.ToLower(b.ID)+"/"+b.Version)
+		})
+		packages = append(packages, found...)
+	}
+	return packages
+}
+
+// Metadata is where policies read licenses, publish dates, and release histories;
+// *nuget.Feed implements it.
+type Metadata interface {
+	Nuspec(ctx context.Context, packagesDir, id, version string) (*nuget.Nuspec, error)
+	Published(ctx context.Context, id, version string) (time.Time, error)
+	Releases(ctx context.Context, id string) ([]nuget.Release, error)
+}
+
+// Violation is a package that breaks a policy rule.
+type Violation struct {
+	Rule       string `json:"rule"`
+	Project    string `json:"project"`
+	Package    string `json:"package"`
+	Version    string `json:"version"`
+	Transitive bool   `json:"transitive"`
+	Message    string `json:"message"`
+}
+
+// Evaluate checks the vulnerabilities of an audit and the packages projects resolve
+// against the policy. Licenses and publish dates are read only for the rules that need
+// them, once per package version, and release histories once per package; packages that
+// are part of the .NET platform are exempt from all three.
+func (p *Policy) Evaluate(ctx context.Context, meta Metadata, packagesDir string, report *Report, packages []Package, now time.Time) ([]Violation, error) {
+	var violations []Violation
+	add := func(v Violation) {
+		if !slices.Contains(violations, v) {
+			violations = append(violations, v)
+		}
+	}
+
+	if p.MaxSeverity != "" {
+		allowed := severityRanks[strings.ToLower(p.MaxSeverity)]
+		for _, v := range report.Vulnerabilities {
+			rank, ok := severityRanks[strings.ToLower(v.Severity)]
+			if !ok {
+				rank = severityRanks["critical"] // An unrated vulnerability is not assumed harmless
+			}
+			if rank > allowed {
+				add(Violation{Rule: RuleSeverity, Project: v.Project, Package: v.Package, Version: v.ResolvedVersion, Transitive: v.Transitive,
+					Message: fmt.Sprintf("%s %s has a %s vulnerability (%s); the policy allows at most %s",
+						v.Package, v.ResolvedVersion, v.Severity, v.AdvisoryURL, p.MaxSeverity)})
+			}
+		}
+	}
+
+	licenses := make(map[string]*nuget.Nuspec)
+	published := make(map[string]time.Time)
+	health := make(map[string]nuget.Health)
+	for _, pkg := range packages {
+		violation := Violation{Project: pkg.Project, Package: pkg.ID, Version: pkg.Version, Transitive: pkg.Transitive}
+		if b, ok := p.banned(pkg.ID); ok {
+			violation.Rule, violation.Message = RuleBanned, fmt.Sprintf("%s is banned by the policy", pkg.ID)
+			if b.Reason != "" {
+				violation.Message = fmt.Sprintf("%s is banned: %s", pkg.ID, b.Reason)
+			}
+			add(violation)
+		}
+		if nuget.IsPlatformPackage(pkg.ID) {
+			continue
+		}
+		key := strings.ToLower(pkg.ID) + "/" + strings.ToLower(pkg.Version)
+
+		if len(p.Licenses) > 0 {
+			nuspec, ok := licenses[key]
+			if !ok {
+				var err error
+				if nuspec, err = meta.Nuspec(ctx, packagesDir, pkg.ID, pkg.Version); err != nil {
+					return nil, err
+				}
+				licenses[key] = nuspec
+			}
+			if message := p.checkLicense(pkg, nuspec); message != "" {
+				violation.Rule, violation.Message = RuleLicense, message
+				add(violation)
+			}
+		}
+
+		if p.MaxAgeDays > 0 {
+			date, ok := published[key]
+			if !ok {
+				var err error
+				if date, err = meta.Published(ctx, pkg.ID, pkg.Version); err != nil {
+					return nil, err
+				}
+				published[key] = date
+			}
+			if days := int(now.Sub(date).Hours() / 24); !date.IsZero() && days > p.MaxAgeDays {
+				violation.Rule = RuleAge
+				violation.Message = fmt.Sprintf("%s %s was published %s, %d days ago; the policy allows %d",
+					pkg.ID, pkg.Version, date.Format("2006-01-02"), days, p.MaxAgeDays)
+				add(violation)
+			}
+		}
+
+		if p.MaxInactiveYears > 0 {
+			id := strings.ToLower(pkg.ID)
+			h, ok := health[id]
+			if !ok {
+				releases, err := meta.Releases(ctx, pkg.ID)
+				if err != nil {
+					return nil, err
+				}
+				h = nuget.HealthOf(releases, now, time.Duration(p.MaxInactiveYears)*365*24*time.Hour)
+				health[id] = h
+			}
+			if h.Stale {
+				violation.Rule = RuleStale
+				violation.Message = fmt.Sprintf("%s has had no release since %s %s, %s ago; the policy allows %d years",
+					pkg.ID, h.LastVersion, h.LastPublished.Format("2006-01-02"), nuget.FormatSpan(now.Sub(h.LastPublished)), p.MaxInactiveYears)
+				add(violation)
+			}
+		}
+	}
+	return violations, nil
+}
+
+// banned returns the rule banning a package, if any.
+func (p *Policy) banned(id string) (BannedPackage, bool) {
+	for _, b := range p.BannedPackages {
+		pattern := strings.TrimSpace(b.Package)
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if len(id) >= len(prefix) && strings.EqualFold(id[:len(prefix)], prefix) {
+				return b, true
+			}
+		} else if strings.EqualFold(id, pattern) {
+			return b, true
+		}
+	}
+	return BannedPackage{}, false
+}
+
+// checkLicense returns why a package's license breaks the policy, or "".
+func (p *Policy) checkLicense(pkg Package, nuspec *nuget.Nuspec) string {
+	switch {
+	case nuspec.License != "" && LicenseAllowed(nuspec.License, p.Licenses):
+		return ""
+	case nuspec.License != "":
+		return fmt.Sprintf("%s %s is licensed under %s, which the policy does not allow", pkg.ID, pkg.Version, nuspec.License)
+	case nuspec.LicenseURL != "":
+		return fmt.Sprintf("%s %s has no license expression, only a license URL (%s)", pkg.ID, pkg.Version, nuspec.LicenseURL)
+	default:
+		return fmt.Sprintf("%s %s has no license expression", pkg.ID, pkg.Version)
+	}
+}
+
+// LicenseAllowed reports whether an SPDX license expression is allowed: every license of
+// an AND, and at least one of an OR. Exceptions (Apache-2.0 WITH LLVM-exception) are judged
+// by their license.
+func LicenseAllowed(expression string, allowed []string) bool {
+	expression = strings.NewReplacer("(", " ", ")", " ").Replace(expression)
+	for _, alternative := range splitOperator(expression, "OR") {
+		all := true
+		for _, license := range splitOperator(alternative, "AND") {
+			license, _, _ = strings.Cut(strings.TrimSpace(license), " WITH ")
+			license = strings.TrimSpace(license)
+			if !slices.ContainsFunc(allowed, func(a string) bool { return strings.EqualFold(a, license) }) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+// splitOperator splits an SPDX expression on an operator written in any case.
+func splitOperator(expression, operator string) []string {
+	var parts []string
+	fields := strings.Fields(expression)
+	start := 0
+	for i, f := range fields {
+		if strings.EqualFold(f, operator) {
+			parts = append(parts, strings.Join(fields[start:i], " "))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.Join(fields[start:], " "))
+}
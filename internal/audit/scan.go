@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/project"
+	"github.com/willibrandon/lazynuget/internal/resolver"
+)
+
+// AdvisorySource is where Scan looks up advisories; *nuget.Feed implements it.
+type AdvisorySource interface {
+	Advisories(ctx context.Context, id string) ([]nuget.Advisory, error)
+}
+
+// Scan audits projects without the dotnet CLI: the packages restore recorded, or the
+// direct references of projects that have not been restored, are checked against the
+// advisories of source. The report has no frameworks; a package resolved by several is
+// reported once per project.
+func Scan(ctx context.Context, source AdvisorySource, projects []string) (*Report, error) {
+	report := &Report{Vulnerabilities: []Vulnerability{}, Projects: projects}
+
+	var packages []Package
+	for _, path := range projects {
+		if _, err := os.Stat(resolver.AssetsPath(path)); err == nil {
+			packages = append(packages, Inventory([]string{path})...)
+			continue
+		}
+		direct, err := directPackages(path)
+		if err != nil {
+			report.Problems = append(report.Problems, Problem{Project: path, Level: "error", Text: err.Error()})
+			continue
+		}
+		packages = append(packages, direct...)
+		report.Problems = append(report.Problems, Problem{
+			Project: path,
+			Level:   "warning",
+			Text:    fmt.Sprintf("%s has not been restored; only its direct references were audited", path),
+		})
+	}
+
+	advisories := make(map[string][]nuget.Advisory) // Projects share packages
+	for _, pkg := range packages {
+		key := strings.ToLower(pkg.ID)
+		list, ok := advisories[key]
+		if !ok {
+			var err error
+			if list, err = source.Advisories(ctx, pkg.ID); err != nil {
+				return nil, err
+			}
+			advisories[key] = list
+		}
+		for _, a := range list {
+			if !a.Affects(pkg.Version) {
+				continue
+			}
+			report.Vulnerabilities = append(report.Vulnerabilities, Vulnerability{
+				Project:         pkg.Project,
+				Package:         pkg.ID,
+				ResolvedVersion: pkg.Version,
+				Transitive:      pkg.Transitive,
+				Severity:        a.Severity,
+				AdvisoryURL:     a.URL,
+			})
+		}
+	}
+	return report, nil
+}
+
+// directPackages returns the packages a project references, at the version restore would
+// pick when it is available. Floating versions are left out; they resolve to whatever the
+// feed has.
+func directPackages(path string) ([]Package, error) {
+	p, err := project.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	var packages []Package
+	for _, ref := range p.PackageReferences {
+		version := ref.Version
+		if version == "" {
+			version = project.CentralVersion(path, ref.ID)
+		}
+		r, err := nuget.ParseVersionRange(version)
+		if err != nil || r.IsFloating() || r.Min == "" {
+			continue
+		}
+		packages = append(packages, Package{Project: path, ID: ref.ID, Version: r.Min})
+	}
+	return packages, nil
+}
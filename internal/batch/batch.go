@@ -0,0 +1,210 @@
+// Package batch runs one lazynuget command in many repositories, for teams that maintain
+// dozens of services. Repositories are listed one per line as local paths or git URLs;
+// remote ones are cloned into a work directory, or pulled when already cloned there.
+package batch
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// Kind identifies batch summaries in versioned JSON output.
+const Kind = "batch"
+
+// Repo is a repository of a batch.
+type Repo struct {
+	Source string `json:"source"` // Local path or git URL, as listed
+	Name   string `json:"name"`   // Unique within the batch; the clone directory of a remote repository
+}
+
+// Remote reports whether the repository is cloned rather than used in place.
+func (r Repo) Remote() bool {
+	return IsRemote(r.Source)
+}
+
+// IsRemote reports whether a source is a git URL: a URL with a scheme, or the scp-like
+// user@host:path form.
+func IsRemote(source string) bool {
+	if strings.Contains(source, "://") {
+		return true
+	}
+	at, colon := strings.Index(source, "@"), strings.Index(source, ":")
+	return at > 0 && colon > at
+}
+
+// ParseRepos reads a repository list: one local path or git URL per line, with blank lines
+// and lines starting with # ignored. Relative paths are relative to dir, usually the
+// directory of the list.
+func ParseRepos(r io.Reader, dir string) ([]Repo, error) {
+	var repos []Repo
+	seen := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		source := strings.TrimSpace(scanner.Text())
+		if source == "" || strings.HasPrefix(source, "#") {
+			continue
+		}
+		if !IsRemote(source) && !filepath.IsAbs(source) {
+			source = filepath.Join(dir, source)
+		}
+		name := repoName(source)
+		if name == "" {
+			return nil, fmt.Errorf("line %d: no repository name in %q", n, source)
+		}
+		if line, ok := seen[strings.ToLower(name)]; ok {
+			return nil, fmt.Errorf("line %d: repository %s is already listed on line %d", n, name, line)
+		}
+		seen[strings.ToLower(name)] = n
+		repos = append(repos, Repo{Source: source, Name: name})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// repoName returns the last element of a source, without a .git suffix.
+func repoName(source string) string {
+	if IsRemote(source) {
+		source = source[strings.LastIndexAny(source, ":/")+1:]
+		return strings.TrimSuffix(path.Base(source), ".git")
+	}
+	name := strings.TrimSuffix(filepath.Base(filepath.Clean(source)), ".git")
+	if name == "." || name == string(filepath.Separator) {
+		return ""
+	}
+	return name
+}
+
+// Status is the outcome of a command in one repository.
+type Status string
+
+// Batch statuses.
+const (
+	StatusOK     Status = "ok"     // The command exited with 0
+	StatusFailed Status = "failed" // The command exited with another code
+	StatusError  Status = "error"  // The repository could not be cloned, or the command not run
+)
+
+// Result is the outcome of a command in one repository.
+type Result struct {
+	Repo
+	Dir        string `json:"dir"` // Where the command ran
+	Status     Status `json:"status"`
+	ExitCode   int    `json:"exitCode"`
+	Output     string `json:"output"` // Standard output, then standard error
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"durationMs"`
+}
+
+// Runner runs a command in each repository of a batch.
+type Runner struct {
+	Spawner    platform.ProcessSpawner
+	Executable string   // The lazynuget executable
+	Args       []string // The command and its arguments
+	WorkDir    string   // Where remote repositories are cloned
+	Jobs       int      // Repositories processed at once (default 1)
+	Notify     func(Result)
+}
+
+// Run runs the command in every repository and returns the results in the order of
+// repos. Notify, when set, is called as each repository finishes, one call at a time.
+func (r *Runner) Run(repos []Repo) []Result {
+	results := make([]Result, len(repos))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for range max(r.Jobs, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = r.run(repos[i])
+				if r.Notify != nil {
+					mu.Lock()
+					r.Notify(results[i])
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range repos {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// run prepares one repository and runs the command in it.
+func (r *Runner) run(repo Repo) Result {
+	start := time.Now()
+	result := Result{Repo: repo, Status: StatusError}
+	defer func() { result.DurationMS = time.Since(start).Milliseconds() }()
+
+	dir, err := r.prepare(repo)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Dir = dir
+
+	out, err := r.Spawner.Run(r.Executable, r.Args, dir, nil)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.ExitCode = out.ExitCode
+	result.Output = out.Stdout + out.Stderr
+	result.Status = StatusOK
+	if out.ExitCode != 0 {
+		result.Status = StatusFailed
+	}
+	return result
+}
+
+// prepare returns the directory of a repository: a local one as listed, and a remote one
+// freshly cloned into the work directory, or fast-forwarded when it was cloned before.
+func (r *Runner) prepare(repo Repo) (string, error) {
+	if !repo.Remote() {
+		info, err := os.Stat(repo.Source)
+		if err != nil {
+			return "", err
+		}
+		if !info.IsDir() {
+			return "", fmt.Errorf("%s is not a directory", repo.Source)
+		}
+		return repo.Source, nil
+	}
+
+	dir := filepath.Join(r.WorkDir, repo.Name)
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return dir, r.git(dir, "pull", "--ff-only", "--quiet")
+	}
+	if err := os.MkdirAll(r.WorkDir, 0o750); err != nil {
+		return "", err
+	}
+	return dir, r.git("", "clone", "--depth", "1", "--quiet", repo.Source, dir)
+}
+
+// git runs a git command in dir, returning its error output when it fails.
+func (r *Runner) git(dir string, args ...string) error {
+	// Never wait on a credential prompt nobody can answer
+	out, err := r.Spawner.Run("git", args, dir, map[string]string{"GIT_TERMINAL_PROMPT": "0"})
+	if err != nil {
+		return err
+	}
+	if out.ExitCode != 0 {
+		return fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(out.Stderr))
+	}
+	return nil
+}
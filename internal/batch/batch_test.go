@@ -0,0 +1,123 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// fakeSpawner records the commands it is asked to run. Clones create the directory;
+// lazynuget exits with the code given for the directory's name.
+type fakeSpawner struct {
+	platform.ProcessSpawner
+	mu    sync.Mutex
+	calls []string
+	exits map[string]int
+}
+
+func (f *fakeSpawner) Run(executable string, args []string, dir string, _ map[string]string) (platform.ProcessResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, strings.Join(append([]string{filepath.Base(dir), executable}, args...), " "))
+	if executable == "git" {
+		if args[0] == "clone" {
+			if strings.Contains(args[len(args)-2], "private") {
+				return platform.ProcessResult{Stderr: "fatal: Authentication failed\n", ExitCode: 128}, nil
+			}
+			return platform.ProcessResult{}, os.MkdirAll(filepath.Join(args[len(args)-1], ".git"), 0o750)
+		}
+		return platform.ProcessResult{}, nil
+	}
+	code := f.exits[filepath.Base(dir)]
+	return platform.ProcessResult{Stdout: "ran in " + filepath.Base(dir) + "\n", ExitCode: code}, nil
+}
+
+// TestParseRepos tests reading local paths and git URLs from a repository list
+func TestParseRepos(t *testing.T) {
+	list := `# Services
+https://github.com/contoso/orders.git
+git@github.com:contoso/billing.git
+
+services/catalog
+/srv/repos/shipping/
+`
+	repos, err := ParseRepos(strings.NewReader(list), "/work")
+	if err != nil {
+		t.Fatalf("ParseRepos() error = %v", err)
+	}
+	var names []string
+	for _, r := range repos {
+		names = append(names, r.Name)
+	}
+	if !slices.Equal(names, []string{"orders", "billing", "catalog", "shipping"}) {
+		t.Errorf("names = %v", names)
+	}
+	if repos[2].Source != filepath.Join("/work", "services", "catalog") || repos[2].Remote() {
+		t.Errorf("repos[2] = %+v, want a local path relative to the list", repos[2])
+	}
+	if !repos[0].Remote() || !repos[1].Remote() || repos[3].Remote() {
+		t.Errorf("Remote() = %v %v %v", repos[0].Remote(), repos[1].Remote(), repos[3].Remote())
+	}
+
+	if _, err := ParseRepos(strings.NewReader("a/orders\nhttps://example.com/Orders.git\n"), "/work"); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("ParseRepos(duplicate names) error = %v", err)
+	}
+}
+
+// TestRunner tests cloning, pulling, and running the command in each repository
+func TestRunner(t *testing.T) {
+	root := t.TempDir()
+	local := filepath.Join(root, "local")
+	if err := os.MkdirAll(local, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	work := filepath.Join(root, "work")
+	if err := os.MkdirAll(filepath.Join(work, "cloned", ".git"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	spawner := &fakeSpawner{exits: map[string]int{"orders": 1}}
+	runner := &Runner{Spawner: spawner, Executable: "lazynuget", Args: []string{"outdated", "--json"}, WorkDir: work, Jobs: 3}
+	var notified int
+	runner.Notify = func(Result) { notified++ }
+	results := runner.Run([]Repo{
+		{Source: local, Name: "local"},
+		{Source: "https://example.com/orders.git", Name: "orders"},
+		{Source: "https://example.com/cloned.git", Name: "cloned"},
+		{Source: "https://example.com/private.git", Name: "private"},
+		{Source: filepath.Join(root, "missing"), Name: "missing"},
+	})
+
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Name+" "+string(r.Status))
+	}
+	want := []string{"local ok", "orders failed", "cloned ok", "private error", "missing error"}
+	if !slices.Equal(got, want) || notified != len(want) {
+		t.Errorf("Run() = %v (%d notified), want %v", got, notified, want)
+	}
+	if results[1].ExitCode != 1 || results[1].Output != "ran in orders\n" || results[1].Dir != filepath.Join(work, "orders") {
+		t.Errorf("results[1] = %+v", results[1])
+	}
+	if !strings.Contains(results[3].Error, "Authentication failed") {
+		t.Errorf("results[3].Error = %q", results[3].Error)
+	}
+
+	slices.Sort(spawner.calls)
+	wantCalls := []string{
+		". git clone --depth 1 --quiet https://example.com/orders.git " + filepath.Join(work, "orders"),
+		". git clone --depth 1 --quiet https://example.com/private.git " + filepath.Join(work, "private"),
+		"cloned git pull --ff-only --quiet",
+		"cloned lazynuget outdated --json",
+		"local lazynuget outdated --json",
+		"orders lazynuget outdated --json",
+	}
+	if !slices.Equal(spawner.calls, wantCalls) {
+		t.Errorf("calls = %q, want %q", spawner.calls, wantCalls)
+	}
+}
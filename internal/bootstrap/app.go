@@ -2,36 +2,60 @@ package bootstrap
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/instance"
 	"github.com/willibrandon/lazynuget/internal/lifecycle"
 	"github.com/willibrandon/lazynuget/internal/logging"
+	"github.com/willibrandon/lazynuget/internal/metrics"
+	"github.com/willibrandon/lazynuget/internal/permissions"
 	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/policy"
+	"github.com/willibrandon/lazynuget/internal/selfupdate"
+	"github.com/willibrandon/lazynuget/internal/telemetry"
+	"github.com/willibrandon/lazynuget/internal/theme"
 )
 
 // App represents the running LazyNuGet application instance.
 type App struct {
 	startTime    time.Time
+	lastErrorAt  time.Time
 	configLoader config.ConfigLoader
 	platform     platform.PlatformInfo
 	pathResolver platform.PathResolver
 	gui          any
+	lastError    error
 	ctx          context.Context
 	watcher      config.ConfigWatcher
 	logger       logging.Logger
+	redactor     *logging.Redactor
+	notices      io.Writer        // Messages for the user outside the log, such as available updates
+	telemetry    *telemetry.Store // nil unless the user opted in
+	instanceLock *instance.Lock   // nil in non-interactive mode
 	config       *config.Config
+	policy       *policy.Policy
 	cancel       context.CancelFunc
 	lifecycle    *lifecycle.Manager
 	version      VersionInfo
 	configPath   string
+	cacheDir     string // Platform cache directory, or its temp fallback; "" if unknown
+	stateDir     string // Config directory where earlier versions kept telemetry state
+	dataDir      string // Directory of telemetry state and the update check, or its temp fallback
 	phase        string
 	runMode      platform.RunMode
+	consoleMode  platform.ConsoleMode
+	themeMode    theme.Mode
+	noColor      bool // --no-color
+	serve        bool // --serve: stdin and stdout carry JSON-RPC, so logs go to stderr
 	configMu     sync.RWMutex
+	healthMu     sync.Mutex
 	guiOnce      sync.Once
 }
 
@@ -49,14 +73,23 @@ func NewApp(version, commit, date string) (*App, error) {
 		startTime: time.Now(),
 		lifecycle: lifecycleMgr,
 		phase:     "uninitialized",
+		notices:   os.Stderr,
 	}
+	lifecycleMgr.AddObserver(lifecycle.ObserverFunc(app.logStateChange))
 
 	return app, nil
 }
 
 // Bootstrap initializes all application subsystems in the correct order.
 // This method implements Layer 2 panic recovery with phase tracking.
-func (app *App) Bootstrap(flags *Flags) error {
+func (app *App) Bootstrap(flags *Flags) (err error) {
+	// Bootstrap failures are reported by Health
+	defer func() {
+		if err != nil {
+			app.setLastError(err)
+		}
+	}()
+
 	// Layer 2 panic recovery: catch panics and add phase context
 	defer func() {
 		if r := recover(); r != nil {
@@ -85,18 +118,48 @@ func (app *App) Bootstrap(flags *Flags) error {
 	// Create config loader
 	loader := config.NewLoader()
 
+	// The logger depends on the loaded config, so messages from loading (validation
+	// warnings, applied overlays) are buffered and written once the logger exists
+	loadLog := &bufferedLogger{}
+
 	// Prepare load options from flags
 	loadOpts := config.LoadOptions{
 		EnvVarPrefix: "LAZYNUGET_",
 		StrictMode:   false,
-		Logger:       nil, // Will set up logger after config is loaded
+		Logger:       loadLog,
 	}
 
+	nonInteractive := false
+	noTelemetry := false
+	metricsAddr := ""
+	forceUnlock := false
 	if flags != nil {
+		// --serve reads requests from stdin, so nothing else may; --daemon runs unattended
+		nonInteractive = flags.NonInteractive || flags.Serve || flags.Daemon
+		app.serve = flags.Serve
+		app.noColor = flags.NoColor
+		noTelemetry = flags.NoTelemetry
+		metricsAddr = flags.MetricsAddr
+		forceUnlock = flags.ForceUnlock
 		loadOpts.ConfigFilePath = flags.ConfigPath
+		loadOpts.NoProjectConfig = flags.NoRepoConfig
+		loadOpts.Profile = flags.Profile
+		loadOpts.StrictMode = flags.StrictConfig
 		loadOpts.CLIFlags = config.CLIFlags{
 			LogLevel:       flags.LogLevel,
-			NonInteractive: flags.NonInteractive,
+			NonInteractive: nonInteractive,
+			NoColor:        flags.NoColor,
+		}
+	}
+
+	// Repository overlays need an explicit trust decision before they apply
+	loadOpts.TrustProject = projectTrustFunc(config.DefaultTrustStorePath(),
+		platform.DetermineRunMode(nonInteractive).IsInteractive(), os.Stdin, os.Stderr)
+	// Theme auto asks the terminal for its background, on each reload too in case the
+	// terminal switched profiles; only a session that owns the terminal may ask
+	if platform.DetermineRunMode(nonInteractive).IsInteractive() {
+		loadOpts.DetectBackground = func() platform.Background {
+			return platform.DetectBackground(platform.BackgroundQueryTimeout)
 		}
 	}
 
@@ -109,16 +172,60 @@ func (app *App) Bootstrap(flags *Flags) error {
 	}
 	app.config = cfg
 	app.configLoader = loader
+	// Watch the file that was actually loaded (config.yml or config.toml) so
+	// hot-reload also works when the file was discovered in the default location
 	app.configPath = loadOpts.ConfigFilePath
+	if app.configPath == "" {
+		app.configPath = cfg.LoadedFrom
+	}
 
 	// Phase: Logging setup
 	app.phase = "logging"
-	// For now, log to stdout only (file logging can be added later)
-	app.logger = logging.New(app.config.LogLevel, "")
+	// Secrets from the config (decrypted values, feed API keys) are masked in every sink
+	app.redactor = logging.NewRedactor(app.config.Secrets()...)
+	// Log to stdout until the log directory has been verified
+	app.logger = logging.NewWithOptions(logging.Options{Level: app.config.LogLevel, Redactor: app.redactor, Console: app.console()})
 
 	// Phase: Directory permission checking
 	app.phase = "directory-permissions"
+	if app.config.LogDir == "" {
+		app.config.LogDir = DefaultLogDir()
+	}
+	app.cacheDir = platformDir(platform.PathResolver.CacheDir)
+	app.dataDir = platformDir(platform.PathResolver.DataDir)
+	app.stateDir = platformDir(platform.PathResolver.ConfigDir)
 	app.checkDirectoryPermissions()
+	app.checkFilePermissions()
+
+	// Switch to the configured log file, format, and per-module levels
+	app.phase = "logging"
+	logOpts := logOptions(app.config, app.redactor)
+	logOpts.Console = app.console()
+	app.logger = logging.NewWithOptions(logOpts)
+	app.lifecycle.SetLogger(logging.ForModule(app.logger, "lifecycle"))
+	loadLog.replay(logging.ForModule(app.logger, "config"))
+
+	// Phase: Machine policy (applies regardless of user and project config)
+	app.phase = "policy"
+	machinePolicy, err := policy.LoadMachinePolicy()
+	if err != nil {
+		if setErr := app.lifecycle.SetState(lifecycle.StateFailed); setErr != nil {
+			return fmt.Errorf("policy loading failed: %w (state transition error: %w)", err, setErr)
+		}
+		return fmt.Errorf("policy loading failed: %w", err)
+	}
+	app.policy = machinePolicy
+	if disabled := machinePolicy.Disabled(); len(disabled) > 0 {
+		app.logger.Info("Machine policy %s restricts: %v", machinePolicy.Source, disabled)
+	}
+
+	// Phase: Telemetry (opt-in; asks once on the first interactive run)
+	app.phase = "telemetry"
+	telemetryDisabled := noTelemetry || telemetry.DisabledByEnv() || !machinePolicy.Allowed(policy.CapabilityTelemetry)
+	telemetryPath := app.dirPath(app.dataDir, telemetry.StateFileName)
+	platform.MoveLegacy(app.dirPath(app.stateDir, telemetry.StateFileName), telemetryPath)
+	app.telemetry = openTelemetry(telemetryPath, telemetryDisabled,
+		platform.DetermineRunMode(nonInteractive).IsInteractive(), os.Stdin, os.Stderr)
 
 	// Phase: Platform detection
 	app.phase = "platform"
@@ -148,49 +255,78 @@ func (app *App) Bootstrap(flags *Flags) error {
 	// Log platform paths
 	configDir, configErr := pathResolver.ConfigDir()
 	cacheDir, cacheErr := pathResolver.CacheDir()
-	if configErr == nil && cacheErr == nil {
-		app.logger.Debug("Platform paths: Config=%s, Cache=%s", configDir, cacheDir)
+	dataDir, dataErr := pathResolver.DataDir()
+	if configErr == nil && cacheErr == nil && dataErr == nil {
+		app.logger.Debug("Platform paths: Config=%s, Cache=%s, Data=%s", configDir, cacheDir, dataDir)
 	} else {
-		app.logger.Warn("Failed to retrieve platform paths: config=%v, cache=%v", configErr, cacheErr)
+		app.logger.Warn("Failed to retrieve platform paths: config=%v, cache=%v, data=%v", configErr, cacheErr, dataErr)
+	}
+
+	// Enable escape sequences on Windows consoles before detecting what the terminal shows;
+	// legacy consoles get no colors and no alternate screen
+	consoleMode, restoreConsole := platform.EnableVirtualTerminal()
+	app.consoleMode = consoleMode
+	app.RegisterShutdownHandler("console-mode", 990, func(_ context.Context) error {
+		restoreConsole()
+		return nil
+	})
+	if consoleMode.Legacy() {
+		app.logger.Warn("This console does not support virtual terminal sequences; colors and the alternate screen are disabled")
 	}
 
 	// Detect and log terminal capabilities (T069)
 	termCaps := platform.NewTerminalCapabilities()
-	app.logger.Debug("Terminal capabilities: ColorDepth=%s, Unicode=%v, TTY=%v",
-		termCaps.GetColorDepth(), termCaps.SupportsUnicode(), termCaps.IsTTY())
+	app.themeMode = theme.DetectMode(termCaps, consoleMode, app.noColor)
+	app.logger.Debug("Terminal capabilities: ColorDepth=%s, Unicode=%v, TTY=%v, Console=%s, Theme=%s",
+		termCaps.GetColorDepth(), termCaps.SupportsUnicode(), termCaps.IsTTY(), consoleMode, app.themeMode)
+	if app.config.Theme == config.AutoTheme {
+		app.logger.Debug("Theme auto: terminal background %q, using the %s theme",
+			app.config.Background, config.AutoThemeVariant(app.config.Background))
+	}
 
 	// Check terminal dimensions and warn if below minimum (T070, FR-015)
-	width, height, err := termCaps.GetSize()
-	if err == nil {
-		const (
-			MinWidth  = 40
-			MinHeight = 10
-		)
-		if width < MinWidth || height < MinHeight {
-			app.logger.Warn("Terminal dimensions %dx%d are below recommended minimum %dx%d. "+
-				"TUI may not display correctly. Dimensions have been clamped to safe values.",
-				width, height, MinWidth, MinHeight)
-		}
+	width, height, err := platform.TerminalSize()
+	if err == nil && platform.TooSmall(width, height) {
+		app.logger.Warn("Terminal dimensions %dx%d are below the minimum %dx%d; "+
+			"a \"terminal too small\" screen is shown until the terminal is resized",
+			width, height, platform.MinTerminalWidth, platform.MinTerminalHeight)
 	}
 
 	// Phase: Determine run mode (interactive vs non-interactive)
 	app.phase = "runmode"
-	nonInteractive := false
-	if flags != nil {
-		nonInteractive = flags.NonInteractive
-	}
 	app.runMode = platform.DetermineRunMode(nonInteractive)
 	app.logger.Info("Run mode determined: %s", app.runMode)
 
+	// Phase: Single-instance lock (interactive sessions edit project files)
+	app.phase = "instance-lock"
+	if app.runMode.IsInteractive() {
+		workDir, _ := os.Getwd()
+		lock, err := acquireInstanceLock(app.dirPath(app.cacheDir, instance.LockDirName), workDir, forceUnlock, app.logger)
+		if err != nil {
+			if setErr := app.lifecycle.SetState(lifecycle.StateFailed); setErr != nil {
+				return fmt.Errorf("%w (state transition error: %w)", err, setErr)
+			}
+			return err
+		}
+		if lock != nil {
+			app.instanceLock = lock
+			app.RegisterShutdownHandlerWithTimeout("instance-lock", 950, time.Second, func(_ context.Context) error {
+				return app.instanceLock.Release()
+			})
+		}
+	}
+
 	// Phase: Dotnet CLI validation (async, non-blocking)
 	app.phase = "dotnet-validation"
 	// Launch dotnet validation in background - don't block startup
+	dotnetLogger := logging.ForModule(app.logger, "dotnet")
 	go func() {
-		if err := platform.ValidateDotnetCLI(); err != nil {
-			app.logger.Warn("Dotnet CLI validation warning: %v", err)
+		if err := platform.ValidateDotnetCLI(app.ctx); err != nil {
+			dotnetLogger.Warn("Dotnet CLI validation warning: %v", err)
+			dotnetLogger.Warn("Running read-only: restore, tool, and workload commands are unavailable")
 			// Don't fail startup - just warn the user
 		} else {
-			app.logger.Debug("Dotnet CLI validated successfully")
+			dotnetLogger.Debug("Dotnet CLI validated successfully")
 		}
 	}()
 
@@ -199,20 +335,35 @@ func (app *App) Bootstrap(flags *Flags) error {
 	if app.config.HotReload && app.configPath != "" {
 		app.logger.Info("Hot-reload enabled, starting config file watcher")
 
+		// Never prompt mid-session; overlays changed since startup are skipped until restart
+		reloadOpts := loadOpts
+		reloadOpts.ConfigFilePath = app.configPath
+		reloadOpts.TrustProject = projectTrustFunc(config.DefaultTrustStorePath(), false, nil, os.Stderr)
+
+		configLogger := logging.ForModule(app.logger, "config")
 		watcher, err := config.NewConfigWatcher(config.WatchOptions{
 			ConfigFilePath: app.configPath,
-			LoadOptions:    loadOpts,
+			RelatedFiles:   app.config.RelatedFiles(),
+			LoadOptions:    reloadOpts,
 			OnReload: func(newCfg *config.Config) {
+				app.redactor.Add(newCfg.Secrets()...)
 				app.configMu.Lock()
+				previous := app.config.Background
 				app.config = newCfg
 				app.configMu.Unlock()
-				app.logger.Info("Configuration reloaded successfully")
+				configLogger.Info("Configuration reloaded successfully")
+				if newCfg.Theme == config.AutoTheme && newCfg.Background != previous {
+					configLogger.Info("Terminal background is now %q; theme auto uses the %s theme",
+						newCfg.Background, config.AutoThemeVariant(newCfg.Background))
+				}
 			},
 			OnError: func(err error) {
-				app.logger.Error("Configuration reload failed: %v", err)
+				configLogger.Error("Configuration reload failed: %v", err)
+				app.setLastError(fmt.Errorf("configuration reload failed: %w", err))
+				app.recordError(telemetry.ErrorConfig)
 			},
 			OnFileDeleted: func() {
-				app.logger.Warn("Configuration file deleted, using previous configuration")
+				configLogger.Warn("Configuration file deleted, using previous configuration")
 			},
 		}, loader)
 
@@ -233,15 +384,15 @@ func (app *App) Bootstrap(flags *Flags) error {
 						case <-app.ctx.Done():
 							return
 						case event := <-eventCh:
-							app.logger.Debug("Config change event: type=%s, error=%v", event.Type, event.Error)
+							configLogger.Debug("Config change event: type=%s, error=%v", event.Type, event.Error)
 						case err := <-errCh:
-							app.logger.Error("Config watcher error: %v", err)
+							configLogger.Error("Config watcher error: %v", err)
 						}
 					}
 				}()
 
 				// Register shutdown handler to stop watcher
-				app.RegisterShutdownHandler("config-watcher", 100, func(_ context.Context) error {
+				app.RegisterShutdownHandlerWithTimeout("config-watcher", 100, 2*time.Second, func(_ context.Context) error {
 					if app.watcher != nil {
 						app.logger.Debug("Stopping config file watcher")
 						return app.watcher.Stop()
@@ -256,11 +407,37 @@ func (app *App) Bootstrap(flags *Flags) error {
 		app.logger.Debug("Hot-reload enabled but no config file path available (using defaults)")
 	}
 
-	// Register logger cleanup handler (runs last, after all other shutdown handlers)
-	app.RegisterShutdownHandler("logger", 999, func(_ context.Context) error {
-		app.logger.Debug("Closing logger")
-		return app.logger.Close()
-	})
+	// Debug metrics endpoint (--metrics-addr); a bad address is not fatal
+	if metricsAddr != "" {
+		server, err := metrics.Serve(metricsAddr, metrics.Default)
+		if err != nil {
+			app.logger.Warn("Metrics endpoint disabled: %v", err)
+		} else {
+			app.logger.Info("Serving metrics at %s", server.URL())
+			app.RegisterShutdownHandlerWithTimeout("metrics", 800, 2*time.Second, server.Shutdown)
+		}
+	}
+	metrics.ObserveOperation("startup", time.Since(app.startTime))
+
+	// Record this startup and send the daily usage report in the background
+	if app.telemetry != nil {
+		app.telemetry.RecordCommand(app.runMode.String())
+		app.telemetry.RecordStartup(time.Since(app.startTime))
+		if err := app.telemetry.Save(); err != nil {
+			app.logger.Debug("Failed to save telemetry counters: %v", err)
+		}
+		if endpoint := app.config.Telemetry.Endpoint; endpoint != "" && app.telemetry.Due(time.Now()) {
+			go app.sendTelemetry(endpoint)
+		}
+		app.RegisterShutdownHandlerWithTimeout("telemetry", 900, 2*time.Second, func(_ context.Context) error {
+			return app.telemetry.Save()
+		})
+	}
+
+	// Notify about new releases; installing is always an explicit `lazynuget update-self`
+	if app.config.UpdateCheck && app.runMode.IsInteractive() && machinePolicy.Allowed(policy.CapabilitySelfUpdate) {
+		go app.checkForUpdate()
+	}
 
 	// Transition to running state
 	app.phase = "ready"
@@ -272,6 +449,50 @@ func (app *App) Bootstrap(flags *Flags) error {
 	return nil
 }
 
+// recordError counts an error category for telemetry if the user opted in.
+func (app *App) recordError(category string) {
+	if app.telemetry != nil {
+		app.telemetry.RecordError(category)
+	}
+}
+
+// sendTelemetry posts the usage report. Failures are only logged at debug level;
+// unsent counters are kept for the next attempt.
+func (app *App) sendTelemetry(endpoint string) {
+	ctx, cancel := context.WithTimeout(app.ctx, 10*time.Second)
+	defer cancel()
+
+	telemetryLogger := logging.ForModule(app.logger, "telemetry")
+	if err := app.telemetry.Send(ctx, nil, endpoint, app.version.Version); err != nil {
+		telemetryLogger.Debug("Usage report not sent: %v", err)
+		return
+	}
+	telemetryLogger.Debug("Usage report sent")
+}
+
+// checkForUpdate tells the user on stderr, and logs, when a newer release exists (at most
+// one GitHub request per day). Failures are only logged at debug level.
+func (app *App) checkForUpdate() {
+	ctx, cancel := context.WithTimeout(app.ctx, 10*time.Second)
+	defer cancel()
+
+	updateLogger := logging.ForModule(app.logger, "update")
+	client, err := selfupdate.NewClient()
+	if err != nil {
+		updateLogger.Debug("Update check skipped: %v", err)
+		return
+	}
+
+	latest, newer, err := client.CheckForUpdate(ctx, app.dirPath(app.dataDir, selfupdate.StateFileName), app.version.Version, time.Now())
+	switch {
+	case err != nil:
+		updateLogger.Debug("Update check skipped: %v", err)
+	case newer:
+		updateLogger.Info("LazyNuGet %s is available (current: %s)", latest, app.version.Version)
+		fmt.Fprintf(app.notices, "LazyNuGet %s is available (current: %s). Run `lazynuget update-self` to install it.\n", latest, app.version.Version)
+	}
+}
+
 // GetConfig returns the application configuration.
 // Thread-safe: uses RLock to allow concurrent reads while hot-reload updates happen.
 func (app *App) GetConfig() *config.Config {
@@ -285,6 +506,12 @@ func (app *App) GetLogger() logging.Logger {
 	return app.logger
 }
 
+// GetPolicy returns the machine policy. Features it disables must be refused and shown
+// as restricted in the UI.
+func (app *App) GetPolicy() *policy.Policy {
+	return app.policy
+}
+
 // GetPlatform returns the platform utilities.
 func (app *App) GetPlatform() platform.PlatformInfo {
 	return app.platform
@@ -295,6 +522,17 @@ func (app *App) GetPathResolver() platform.PathResolver {
 	return app.pathResolver
 }
 
+// GetConsoleMode returns what the console attached to stdout can display.
+func (app *App) GetConsoleMode() platform.ConsoleMode {
+	return app.consoleMode
+}
+
+// GetTheme returns the styles for the current color scheme, rendered in the mode chosen from
+// the terminal, NO_COLOR, and --no-color.
+func (app *App) GetTheme() *theme.Theme {
+	return theme.New(app.GetConfig().ColorScheme, app.themeMode)
+}
+
 // GetRunMode returns the determined run mode.
 func (app *App) GetRunMode() platform.RunMode {
 	return app.runMode
@@ -356,92 +594,198 @@ func (app *App) Shutdown() error {
 	shutdownCtx := context.Background()
 
 	// Execute lifecycle shutdown with all registered handlers
-	if err := app.lifecycle.Shutdown(shutdownCtx, app.logger); err != nil {
+	err := app.lifecycle.Shutdown(shutdownCtx, app.logger)
+	if err != nil {
 		app.logger.Error("Shutdown completed with errors: %v", err)
-		// Cancel the app context even if shutdown had errors
-		app.cancel()
-		return err
+		app.setLastError(err)
+	} else {
+		app.logger.Info("Shutdown complete")
 	}
 
-	// Cancel the application context after successful shutdown
+	// Cancel the application context even if shutdown had errors
 	app.cancel()
 
-	app.logger.Info("Shutdown complete")
-	return nil
+	// Close the log file last, after the shutdown report and the messages above
+	app.logger.Debug("Closing logger")
+	if closeErr := app.logger.Close(); closeErr != nil && !errors.Is(closeErr, os.ErrClosed) && err == nil {
+		err = fmt.Errorf("failed to close logger: %w", closeErr)
+	}
+	return err
 }
 
-// RegisterShutdownHandler registers a function to be called during shutdown
+// RegisterShutdownHandler registers a function to be called during shutdown.
+// Lower priorities run first; handlers sharing a priority run in parallel.
 func (app *App) RegisterShutdownHandler(name string, priority int, handler func(context.Context) error) {
+	app.RegisterShutdownHandlerWithTimeout(name, priority, 0, handler)
+}
+
+// RegisterShutdownHandlerWithTimeout registers a shutdown handler that is abandoned
+// (and reported as timed out) if it runs longer than timeout
+func (app *App) RegisterShutdownHandlerWithTimeout(name string, priority int, timeout time.Duration, handler func(context.Context) error) {
 	app.lifecycle.RegisterShutdownHandler(lifecycle.ShutdownHandler{
 		Name:     name,
 		Priority: priority,
+		Timeout:  timeout,
 		Handler:  handler,
 	})
 }
 
-// checkDirectoryPermissions verifies that config directories are writable
-// If permissions are insufficient, warns and attempts to use temp directory fallback
+// checkDirectoryPermissions verifies that the log, cache, state, and data directories
+// are writable, creating them if needed. Each one that is not is replaced by a folder in
+// the temp directory, with a warning, so the session still starts.
 func (app *App) checkDirectoryPermissions() {
 	directories := []struct {
 		name string
-		path string
+		path *string
 	}{
-		{"log", app.config.LogDir},
+		{"log", &app.config.LogDir},
+		{"cache", &app.cacheDir},
+		{"state", &app.stateDir},
+		{"data", &app.dataDir},
 	}
 
 	for _, dir := range directories {
-		// Check if directory exists
-		info, err := os.Stat(dir.path)
-		if err != nil {
-			if !os.IsNotExist(err) {
-				app.logger.Warn("Cannot access %s directory %s: %v", dir.name, dir.path, err)
-				continue
-			}
-			// Try to create the directory (owner-only permissions for security)
-			if err := os.MkdirAll(dir.path, 0o700); err != nil {
-				app.logger.Warn("Cannot create %s directory %s: %v\nFalling back to temp directory", dir.name, dir.path, err)
-				app.useTempDirectoryFallback(dir.name)
-				continue
-			}
-			app.logger.Debug("Created %s directory: %s", dir.name, dir.path)
-		} else if !info.IsDir() {
-			app.logger.Warn("%s path %s exists but is not a directory\nFalling back to temp directory", dir.name, dir.path)
-			app.useTempDirectoryFallback(dir.name)
+		if *dir.path == "" {
+			// The platform gave no location: nothing to check, and nothing is persisted there
 			continue
 		}
 
-		// Test write permissions by creating a temp file (owner-only for security)
-		testFile := filepath.Join(dir.path, ".lazynuget-write-test")
-		if err := os.WriteFile(testFile, []byte("test"), 0o600); err != nil {
-			app.logger.Warn("Cannot write to %s directory %s: %v\nFalling back to temp directory", dir.name, dir.path, err)
-			app.useTempDirectoryFallback(dir.name)
+		if err := platform.CheckWritableDir(*dir.path); err != nil {
+			app.logger.Warn("Unusable %s directory: %v\nFalling back to temp directory", dir.name, err)
+			app.useTempDirectoryFallback(dir.name, dir.path)
 			continue
 		}
-		// Clean up test file
-		if err := os.Remove(testFile); err != nil {
-			app.logger.Debug("Failed to remove test file %s: %v (not critical)", testFile, err)
+		app.logger.Debug("%s directory verified: %s", dir.name, *dir.path)
+	}
+
+	// Downloads and clones fill the cache; warn while there is still room to act
+	if app.cacheDir != "" {
+		if err := platform.CheckFreeSpace(app.cacheDir, 0); err != nil {
+			app.logger.Warn("Cache disk is nearly full: %v; free up space or move the cache (XDG_CACHE_HOME, LOCALAPPDATA)", err)
 		}
+	}
+}
 
-		app.logger.Debug("%s directory verified: %s", dir.name, dir.path)
+// checkFilePermissions warns when lazynuget's files are not private to the user; doctor
+// reports and fixes them.
+func (app *App) checkFilePermissions() {
+	open := 0
+	example := ""
+	for _, f := range permissions.Check(permissions.DefaultTargets(app.config.LogDir), false) {
+		if f.Problem != "" {
+			open++
+			example = f.Path
+		}
+	}
+	if open > 0 {
+		app.logger.Warn("%d files or directories are not private to you (e.g., %s); run lazynuget doctor --fix", open, example)
 	}
 }
 
-// useTempDirectoryFallback updates config to use temp directory for the specified type
-func (app *App) useTempDirectoryFallback(dirType string) {
-	tempBase := os.TempDir()
-	fallbackPath := filepath.Join(tempBase, "lazynuget", dirType)
+// dirPath returns name in dir, or "" when dir is unknown so callers skip persisting.
+func (app *App) dirPath(dir, name string) string {
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, name)
+}
 
-	// Create the fallback directory (owner-only permissions for security)
-	if err := os.MkdirAll(fallbackPath, 0o700); err != nil {
-		app.logger.Error("Cannot create fallback %s directory %s: %v", dirType, fallbackPath, err)
-		return
+// bufferedLogger records log messages until a real logger is available.
+type bufferedLogger struct {
+	entries []bufferedEntry
+	mu      sync.Mutex
+}
+
+// bufferedEntry is one recorded message; log is the Logger method that writes it.
+type bufferedEntry struct {
+	log    func(config.Logger, string, ...any)
+	format string
+	args   []any
+}
+
+func (b *bufferedLogger) Debug(format string, args ...any) { b.add(config.Logger.Debug, format, args) }
+func (b *bufferedLogger) Info(format string, args ...any)  { b.add(config.Logger.Info, format, args) }
+func (b *bufferedLogger) Warn(format string, args ...any)  { b.add(config.Logger.Warn, format, args) }
+func (b *bufferedLogger) Error(format string, args ...any) { b.add(config.Logger.Error, format, args) }
+
+func (b *bufferedLogger) add(log func(config.Logger, string, ...any), format string, args []any) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries = append(b.entries, bufferedEntry{log: log, format: format, args: args})
+}
+
+// replay writes the recorded messages to logger in order and clears the buffer.
+func (b *bufferedLogger) replay(logger config.Logger) {
+	b.mu.Lock()
+	entries := b.entries
+	b.entries = nil
+	b.mu.Unlock()
+
+	for _, e := range entries {
+		e.log(logger, e.format, e.args...)
 	}
+}
 
-	// Update config with fallback path
-	switch dirType {
-	case "log":
-		app.config.LogDir = fallbackPath
+// DefaultLogDir returns the platform log directory (a "logs" folder in the cache directory).
+// Returns an empty string if the cache directory cannot be determined.
+func DefaultLogDir() string {
+	cacheDir := platformDir(platform.PathResolver.CacheDir)
+	if cacheDir == "" {
+		return ""
+	}
+	return filepath.Join(cacheDir, "logs")
+}
+
+// platformDir returns a platform directory from the path resolver, or "" if it cannot be
+// determined.
+func platformDir(get func(platform.PathResolver) (string, error)) string {
+	platformInfo, err := platform.New()
+	if err != nil {
+		return ""
+	}
+	pathResolver, err := platform.NewPathResolver(platformInfo)
+	if err != nil {
+		return ""
+	}
+	dir, err := get(pathResolver)
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// logOptions builds logger options from the logging settings.
+func logOptions(cfg *config.Config, redactor *logging.Redactor) logging.Options {
+	opts := logging.Options{
+		Level:        cfg.LogLevel,
+		Format:       cfg.LogFormat,
+		ModuleLevels: cfg.LogLevels,
+		Redactor:     redactor,
+	}
+	if cfg.LogDir != "" {
+		opts.Path = filepath.Join(cfg.LogDir, logging.DefaultLogFileName)
+	}
+	return opts
+}
+
+// console returns the writer for console log output: stdout, or stderr when stdout is
+// reserved for JSON-RPC responses.
+func (app *App) console() io.Writer {
+	if app.serve {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// useTempDirectoryFallback points path at a folder for the named directory in the temp
+// directory. path is left unchanged if the folder cannot be created either.
+func (app *App) useTempDirectoryFallback(name string, path *string) {
+	fallbackPath, err := platform.TempFallbackDir(name)
+	if err != nil {
+		app.logger.Error("%v", err)
+		return
 	}
 
-	app.logger.Info("Using fallback %s directory: %s", dirType, fallbackPath)
+	*path = fallbackPath
+	app.logger.Info("Using fallback %s directory: %s", name, fallbackPath)
 }
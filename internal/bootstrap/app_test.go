@@ -2,8 +2,18 @@ package bootstrap
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/lifecycle"
+	"github.com/willibrandon/lazynuget/internal/logging"
 )
 
 func TestNewApp(t *testing.T) {
@@ -256,6 +266,54 @@ func TestRegisterShutdownHandler(t *testing.T) {
 	}
 }
 
+// TestHealth tests the health snapshot across the lifecycle
+func TestHealth(t *testing.T) {
+	app, err := NewApp("test", "test-commit", "2025-01-01")
+	if err != nil {
+		t.Fatalf("NewApp() failed: %v", err)
+	}
+	defer app.cancel()
+
+	var states []lifecycle.State
+	app.lifecycle.AddObserver(lifecycle.ObserverFunc(func(_, to lifecycle.State) {
+		states = append(states, to)
+	}))
+
+	if h := app.Health(); h.State != lifecycle.StateUninitialized || h.Uptime != 0 || h.Healthy() {
+		t.Errorf("Health() before bootstrap = %+v, want Uninitialized with no uptime", h)
+	}
+
+	if err := app.Bootstrap(nil); err != nil {
+		t.Fatalf("Bootstrap() failed: %v", err)
+	}
+	h := app.Health()
+	if h.State != lifecycle.StateRunning || !h.Healthy() {
+		t.Errorf("Health() after bootstrap = %+v, want Running", h)
+	}
+	if h.LastError != nil || !h.LastErrorAt.IsZero() {
+		t.Errorf("Health().LastError = %v, want none", h.LastError)
+	}
+
+	app.RegisterShutdownHandler("failing-handler", 100, func(context.Context) error {
+		return errors.New("intentional failure")
+	})
+	if err := app.Shutdown(); err == nil {
+		t.Fatal("Shutdown() should report the failing handler")
+	}
+	h = app.Health()
+	if h.State != lifecycle.StateShutdownComplete || h.Healthy() {
+		t.Errorf("Health().State after shutdown = %s, want ShutdownComplete", h.State)
+	}
+	if h.LastError == nil || !strings.Contains(h.LastError.Error(), "intentional failure") || h.LastErrorAt.IsZero() {
+		t.Errorf("Health().LastError = %v, want the shutdown error", h.LastError)
+	}
+
+	want := []lifecycle.State{lifecycle.StateInitializing, lifecycle.StateRunning, lifecycle.StateShuttingDown, lifecycle.StateShutdownComplete}
+	if !slices.Equal(states, want) {
+		t.Errorf("observed states = %v, want %v", states, want)
+	}
+}
+
 func TestGetPlatform(t *testing.T) {
 	app, err := NewApp("test", "test-commit", "2025-01-01")
 	if err != nil {
@@ -273,6 +331,22 @@ func TestGetPlatform(t *testing.T) {
 	}
 }
 
+func TestGetPolicy(t *testing.T) {
+	app, err := NewApp("test", "test-commit", "2025-01-01")
+	if err != nil {
+		t.Fatalf("NewApp() failed: %v", err)
+	}
+	defer app.cancel()
+
+	if err := app.Bootstrap(nil); err != nil {
+		t.Fatalf("Bootstrap() failed: %v", err)
+	}
+
+	if app.GetPolicy() == nil {
+		t.Error("GetPolicy() returned nil")
+	}
+}
+
 func TestGetGUI(t *testing.T) {
 	app, err := NewApp("test", "test-commit", "2025-01-01")
 	if err != nil {
@@ -290,3 +364,117 @@ func TestGetGUI(t *testing.T) {
 		t.Error("GetGUI() should return nil when GUI not implemented")
 	}
 }
+
+// recordingLogger captures formatted messages with their level.
+type recordingLogger struct {
+	messages []string
+}
+
+func (r *recordingLogger) Debug(format string, args ...any) { r.record("DEBUG", format, args) }
+func (r *recordingLogger) Info(format string, args ...any)  { r.record("INFO", format, args) }
+func (r *recordingLogger) Warn(format string, args ...any)  { r.record("WARN", format, args) }
+func (r *recordingLogger) Error(format string, args ...any) { r.record("ERROR", format, args) }
+
+func (r *recordingLogger) record(level, format string, args []any) {
+	r.messages = append(r.messages, level+" "+fmt.Sprintf(format, args...))
+}
+
+// closingLogger is a recordingLogger that also records Close.
+type closingLogger struct {
+	recordingLogger
+}
+
+func (c *closingLogger) Close() error {
+	c.messages = append(c.messages, "CLOSE")
+	return nil
+}
+
+// TestShutdownClosesLoggerLast tests that the shutdown report is logged before the log
+// file is closed
+func TestShutdownClosesLoggerLast(t *testing.T) {
+	app, err := NewApp("test", "test-commit", "2025-01-01")
+	if err != nil {
+		t.Fatalf("NewApp() failed: %v", err)
+	}
+	if err := app.Bootstrap(nil); err != nil {
+		t.Fatalf("Bootstrap() failed: %v", err)
+	}
+	if err := app.logger.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	logger := &closingLogger{}
+	app.logger = logger
+
+	if err := app.Shutdown(); err != nil {
+		t.Fatalf("Shutdown() failed: %v", err)
+	}
+
+	report, closed := -1, -1
+	for i, msg := range logger.messages {
+		switch {
+		case strings.HasPrefix(msg, "INFO Shutdown report:"):
+			report = i
+		case msg == "CLOSE":
+			closed = i
+		}
+	}
+	if report < 0 || closed != len(logger.messages)-1 {
+		t.Errorf("want the shutdown report logged and Close called last, got %v", logger.messages)
+	}
+}
+
+// TestBufferedLogger tests that config loading messages are replayed in order once the logger exists
+func TestBufferedLogger(t *testing.T) {
+	var buffered bufferedLogger
+	buffered.Info("Loaded configuration from file: %s", "config.yml")
+	buffered.Warn("Config validation warning: %s", "logLevel")
+	buffered.Error("Config validation error: %s", "theme")
+
+	var recorder recordingLogger
+	buffered.replay(&recorder)
+	buffered.replay(&recorder) // Already drained
+
+	want := []string{
+		"INFO Loaded configuration from file: config.yml",
+		"WARN Config validation warning: logLevel",
+		"ERROR Config validation error: theme",
+	}
+	if !slices.Equal(recorder.messages, want) {
+		t.Errorf("replayed %v, want %v", recorder.messages, want)
+	}
+}
+
+// TestCheckDirectoryPermissions tests that unusable cache, state, and data directories
+// fall back to the temp directory while usable ones are created and kept
+func TestCheckDirectoryPermissions(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	base := t.TempDir()
+	blocker := filepath.Join(base, "file")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	app := &App{
+		logger:   logging.New("error", ""),
+		config:   &config.Config{LogDir: filepath.Join(base, "logs")},
+		cacheDir: blocker,                         // exists but is not a directory
+		stateDir: filepath.Join(blocker, "state"), // cannot be created
+		dataDir:  filepath.Join(base, "new", "data"),
+	}
+	app.checkDirectoryPermissions()
+
+	if app.config.LogDir != filepath.Join(base, "logs") {
+		t.Errorf("LogDir = %q, want it kept", app.config.LogDir)
+	}
+	if app.dataDir != filepath.Join(base, "new", "data") {
+		t.Errorf("dataDir = %q, want it created and kept", app.dataDir)
+	}
+	for name, dir := range map[string]string{"cache": app.cacheDir, "state": app.stateDir} {
+		if want := filepath.Join(os.TempDir(), "lazynuget", name); dir != want {
+			t.Errorf("%s directory = %q, want the fallback %q", name, dir, want)
+		}
+	}
+	if app.dirPath("", "telemetry.json") != "" {
+		t.Error("dirPath() of an unknown directory is not empty")
+	}
+}
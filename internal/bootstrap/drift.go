@@ -0,0 +1,136 @@
+package bootstrap
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/jsonrpc"
+	"github.com/willibrandon/lazynuget/internal/project"
+	"github.com/willibrandon/lazynuget/internal/resolver"
+)
+
+// driftDebounce is how long the daemon waits for project files to stop changing before
+// checking them: a git checkout or an editor saving several files changes them in bursts.
+const driftDebounce = 500 * time.Millisecond
+
+// driftedProject is a project's entry in the result of drift.
+type driftedProject struct {
+	Path  string           `json:"path"`
+	Drift []resolver.Drift `json:"drift"`
+}
+
+// checkDrift compares the package references of one project, or of every project in the
+// workspace, with their last restore, and returns the projects that need a restore. The
+// daemon answers from what its watcher last checked.
+func (api *scriptAPI) checkDrift(_ context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Project string `json:"project"`
+	}
+	if err := jsonrpc.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	if p.Project != "" {
+		path, err := api.projectPath(p.Project)
+		if err != nil {
+			return nil, err
+		}
+		paths = []string{path}
+	} else {
+		var err error
+		if paths, err = api.projects.Discover(api.root); err != nil {
+			return nil, err
+		}
+	}
+
+	projects := []driftedProject{}
+	for _, path := range paths {
+		api.driftMu.Lock()
+		drift, ok := api.drift[path]
+		api.driftMu.Unlock()
+		if !ok {
+			var err error
+			if drift, err = resolver.CheckDrift(path); err != nil {
+				return nil, err
+			}
+		}
+		if len(drift) > 0 {
+			projects = append(projects, driftedProject{Path: path, Drift: drift})
+		}
+	}
+	return map[string]any{"projects": projects}, nil
+}
+
+// watchDrift checks the workspace's projects for drift in the background, and again
+// whenever a project file, a Directory.Build.props, .targets, or Directory.Packages.props
+// above one, or an assets file changes, such as when a project is edited outside the
+// daemon or a branch is checked out. New drift is logged as a warning. The watcher stops
+// with ctx.
+func (api *scriptAPI) watchDrift(ctx context.Context) {
+	paths, err := api.projects.Discover(api.root)
+	if err != nil || len(paths) == 0 {
+		return
+	}
+	w, err := project.NewWatcher(api.root, paths)
+	if err != nil {
+		api.logger.Warn("Cannot watch projects for changes: %v", err)
+		return
+	}
+	api.driftMu.Lock()
+	api.drift = make(map[string][]resolver.Drift)
+	api.driftMu.Unlock()
+
+	go func() {
+		defer func() { _ = w.Close() }()
+		api.updateDrift(paths)
+		w.Run(ctx, driftDebounce, api.updateDrift, func(err error) {
+			api.logger.Warn("Project watcher error: %v", err)
+		})
+	}()
+}
+
+// updateDrift checks projects again, logging those that started to drift or drift
+// differently.
+func (api *scriptAPI) updateDrift(paths []string) {
+	for _, path := range paths {
+		drift, err := resolver.CheckDrift(path)
+		api.driftMu.Lock()
+		if err != nil {
+			delete(api.drift, path) // Checked on request; the file may be mid-save
+			api.driftMu.Unlock()
+			continue
+		}
+		previous, seen := api.drift[path]
+		api.drift[path] = drift
+		api.driftMu.Unlock()
+
+		if len(drift) > 0 && (!seen || !slices.Equal(describeDrift(previous), describeDrift(drift))) {
+			api.logger.Warn("%s needs a restore: %s", path, strings.Join(describeDrift(drift), "; "))
+		}
+	}
+}
+
+// forgetDrift drops what the watcher last checked of a project the daemon changed, or of
+// every project when path is "", so that drift checks it again before the watcher does.
+func (api *scriptAPI) forgetDrift(path string) {
+	api.driftMu.Lock()
+	defer api.driftMu.Unlock()
+	if path == "" {
+		clear(api.drift)
+	} else {
+		delete(api.drift, path)
+	}
+}
+
+// describeDrift describes each drift of a project.
+func describeDrift(drift []resolver.Drift) []string {
+	s := make([]string, len(drift))
+	for i, d := range drift {
+		s[i] = d.String()
+	}
+	return s
+}
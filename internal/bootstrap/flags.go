@@ -2,40 +2,75 @@ package bootstrap
 
 import (
 	"flag"
-	"fmt"
 	"os"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/policy"
 )
 
 // Flags holds parsed command-line flags.
 type Flags struct {
 	ConfigPath     string
+	MetricsAddr    string
 	LogLevel       string
+	Profile        string
+	Socket         string
+	OutputVersion  int
+	Verbosity      cli.Verbosity // --quiet or --verbose
 	ShowVersion    bool
 	ShowHelp       bool
 	NonInteractive bool
+	NoColor        bool
+	Serve          bool
+	Daemon         bool
+	NoRepoConfig   bool
+	StrictConfig   bool
+	NoTelemetry    bool
+	ForceUnlock    bool
 }
 
 // ParseFlags parses command-line arguments and returns the flags.
 // It returns true if the application should exit early (--version or --help).
+// --version --verbose also prints the environment, and --version --json a JSON document.
+// The flags themselves are declared once in the cli command registry.
 func (app *App) ParseFlags(args []string) (*Flags, bool, error) {
-	fs := flag.NewFlagSet("lazynuget", flag.ContinueOnError)
-	fs.Usage = func() { /* Custom usage handled by ShowHelp */ }
-
-	flags := &Flags{}
+	values, err := cli.Root().Parse(args)
+	if err != nil {
+		return nil, false, err
+	}
 
-	fs.BoolVar(&flags.ShowVersion, "version", false, "Show version information")
-	fs.BoolVar(&flags.ShowHelp, "help", false, "Show this help message")
-	fs.StringVar(&flags.ConfigPath, "config", "", "Path to configuration file")
-	fs.StringVar(&flags.LogLevel, "log-level", "info", "Set log level (debug|info|warn|error)")
-	fs.BoolVar(&flags.NonInteractive, "non-interactive", false, "Run in non-interactive mode (no TUI)")
+	flags := &Flags{
+		ShowVersion:    values.Bool("version"),
+		ShowHelp:       values.Bool("help"),
+		ConfigPath:     values.String("config"),
+		LogLevel:       values.String("log-level"),
+		Profile:        values.String("profile"),
+		NonInteractive: values.Bool("non-interactive"),
+		NoColor:        values.Bool("no-color"),
+		Serve:          values.Bool("serve"),
+		Daemon:         values.Bool("daemon"),
+		Socket:         values.String("socket"),
+		StrictConfig:   values.Bool("strict-config"),
+		NoRepoConfig:   values.Bool("no-repo-config"),
+		NoTelemetry:    values.Bool("no-telemetry"),
+		ForceUnlock:    values.Bool("force-unlock"),
+		MetricsAddr:    values.String("metrics-addr"),
+		Verbosity:      values.Verbosity(),
+	}
 
-	if err := fs.Parse(args); err != nil {
-		return nil, false, err
+	// --quiet and --verbose set the log level, overriding --log-level
+	if level := flags.Verbosity.LogLevel(); level != "" {
+		flags.LogLevel = level
 	}
 
+	// Parse has validated --output-version
+	flags.OutputVersion, _ = values.OutputVersion()
+
 	// Handle --version flag
 	if flags.ShowVersion {
-		ShowVersion(app.version)
+		if err := app.showVersion(flags, values.Bool("json")); err != nil {
+			return nil, false, err
+		}
 		return flags, true, nil
 	}
 
@@ -48,26 +83,11 @@ func (app *App) ParseFlags(args []string) (*Flags, bool, error) {
 	return flags, false, nil
 }
 
-// ShowHelp displays usage information for all available flags.
+// ShowHelp displays usage information for all available flags and commands, marking
+// the commands the machine policy disables as restricted.
 func ShowHelp() {
-	fmt.Println("LazyNuGet - Terminal UI for NuGet package management")
-	fmt.Println()
-	fmt.Println("Usage:")
-	fmt.Println("  lazynuget [options]")
-	fmt.Println()
-	fmt.Println("Options:")
-	fmt.Println("  --version           Show version information and exit")
-	fmt.Println("  --help              Show this help message and exit")
-	fmt.Println("  --config PATH       Path to configuration file")
-	fmt.Println("  --log-level LEVEL   Set log level (debug|info|warn|error)")
-	fmt.Println("  --non-interactive   Run in non-interactive mode (no TUI)")
-	fmt.Println()
-	fmt.Println("Examples:")
-	fmt.Println("  lazynuget                               # Start interactive TUI")
-	fmt.Println("  lazynuget --version                     # Show version")
-	fmt.Println("  lazynuget --config ~/.config/custom.yml # Use custom config")
-	fmt.Println("  lazynuget --log-level debug             # Enable debug logging")
-	fmt.Println()
+	p, _ := policy.LoadMachinePolicyOrRestrict()
+	_ = cli.Root().WritePolicyHelp(os.Stdout, p)
 }
 
 // init customizes the default flag error output
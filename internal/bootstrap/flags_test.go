@@ -2,6 +2,9 @@ package bootstrap
 
 import (
 	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/output"
 )
 
 // TestParseFlags tests command-line flag parsing
@@ -56,6 +59,24 @@ func TestParseFlags(t *testing.T) {
 			},
 			shouldExit: false,
 		},
+		{
+			name: "quiet",
+			args: []string{"-quiet"},
+			want: Flags{
+				LogLevel:  "error",
+				Verbosity: cli.VerbosityQuiet,
+			},
+			shouldExit: false,
+		},
+		{
+			name: "verbose overrides log level",
+			args: []string{"-log-level", "warn", "-verbose"},
+			want: Flags{
+				LogLevel:  "debug",
+				Verbosity: cli.VerbosityVerbose,
+			},
+			shouldExit: false,
+		},
 		{
 			name: "non-interactive",
 			args: []string{"-non-interactive"},
@@ -64,6 +85,79 @@ func TestParseFlags(t *testing.T) {
 			},
 			shouldExit: false,
 		},
+		{
+			name: "no color",
+			args: []string{"-no-color"},
+			want: Flags{
+				NoColor: true,
+			},
+			shouldExit: false,
+		},
+		{
+			name: "no repo config",
+			args: []string{"-no-repo-config"},
+			want: Flags{
+				NoRepoConfig: true,
+			},
+			shouldExit: false,
+		},
+		{
+			name: "profile",
+			args: []string{"-profile", "work"},
+			want: Flags{
+				Profile: "work",
+			},
+			shouldExit: false,
+		},
+		{
+			name: "strict config",
+			args: []string{"-strict-config"},
+			want: Flags{
+				StrictConfig: true,
+			},
+			shouldExit: false,
+		},
+		{
+			name: "no telemetry",
+			args: []string{"-no-telemetry"},
+			want: Flags{
+				NoTelemetry: true,
+			},
+			shouldExit: false,
+		},
+		{
+			name: "force unlock",
+			args: []string{"-force-unlock"},
+			want: Flags{
+				ForceUnlock: true,
+			},
+			shouldExit: false,
+		},
+		{
+			name: "metrics address",
+			args: []string{"-metrics-addr", "127.0.0.1:9464"},
+			want: Flags{
+				MetricsAddr: "127.0.0.1:9464",
+			},
+			shouldExit: false,
+		},
+		{
+			name: "serve",
+			args: []string{"-serve"},
+			want: Flags{
+				Serve: true,
+			},
+			shouldExit: false,
+		},
+		{
+			name: "daemon",
+			args: []string{"-daemon", "-socket", "/tmp/lazynuget.sock"},
+			want: Flags{
+				Daemon: true,
+				Socket: "/tmp/lazynuget.sock",
+			},
+			shouldExit: false,
+		},
 		{
 			name: "multiple flags",
 			args: []string{"-log-level", "warn", "-non-interactive", "-config", "/custom/config.toml"},
@@ -108,6 +202,33 @@ func TestParseFlags(t *testing.T) {
 			if flags.NonInteractive != tt.want.NonInteractive {
 				t.Errorf("NonInteractive = %v, want %v", flags.NonInteractive, tt.want.NonInteractive)
 			}
+			if flags.StrictConfig != tt.want.StrictConfig {
+				t.Errorf("StrictConfig = %v, want %v", flags.StrictConfig, tt.want.StrictConfig)
+			}
+			if flags.NoRepoConfig != tt.want.NoRepoConfig {
+				t.Errorf("NoRepoConfig = %v, want %v", flags.NoRepoConfig, tt.want.NoRepoConfig)
+			}
+			if flags.Profile != tt.want.Profile {
+				t.Errorf("Profile = %q, want %q", flags.Profile, tt.want.Profile)
+			}
+			if flags.MetricsAddr != tt.want.MetricsAddr {
+				t.Errorf("MetricsAddr = %q, want %q", flags.MetricsAddr, tt.want.MetricsAddr)
+			}
+			if flags.NoTelemetry != tt.want.NoTelemetry {
+				t.Errorf("NoTelemetry = %v, want %v", flags.NoTelemetry, tt.want.NoTelemetry)
+			}
+			if flags.ForceUnlock != tt.want.ForceUnlock {
+				t.Errorf("ForceUnlock = %v, want %v", flags.ForceUnlock, tt.want.ForceUnlock)
+			}
+			if flags.Serve != tt.want.Serve {
+				t.Errorf("Serve = %v, want %v", flags.Serve, tt.want.Serve)
+			}
+			if flags.Daemon != tt.want.Daemon || flags.Socket != tt.want.Socket {
+				t.Errorf("Daemon, Socket = %v, %q, want %v, %q", flags.Daemon, flags.Socket, tt.want.Daemon, tt.want.Socket)
+			}
+			if flags.Verbosity != tt.want.Verbosity {
+				t.Errorf("Verbosity = %v, want %v", flags.Verbosity, tt.want.Verbosity)
+			}
 		})
 	}
 }
@@ -142,6 +263,35 @@ func TestParseFlagsDefaults(t *testing.T) {
 	}
 }
 
+// TestParseFlagsOutputVersion tests --output-version parsing and validation
+func TestParseFlagsOutputVersion(t *testing.T) {
+	app, err := NewApp("test", "test-commit", "2025-01-01")
+	if err != nil {
+		t.Fatalf("NewApp() failed: %v", err)
+	}
+	defer app.cancel()
+
+	flags, _, err := app.ParseFlags([]string{})
+	if err != nil {
+		t.Fatalf("ParseFlags() error = %v", err)
+	}
+	if flags.OutputVersion != output.CurrentSchemaVersion {
+		t.Errorf("OutputVersion should default to %d, got %d", output.CurrentSchemaVersion, flags.OutputVersion)
+	}
+
+	flags, _, err = app.ParseFlags([]string{"-output-version", "v1"})
+	if err != nil {
+		t.Fatalf("ParseFlags() error = %v", err)
+	}
+	if flags.OutputVersion != 1 {
+		t.Errorf("OutputVersion = %d, want 1", flags.OutputVersion)
+	}
+
+	if _, _, err := app.ParseFlags([]string{"-output-version", "999"}); err == nil {
+		t.Error("ParseFlags() should reject unsupported output version")
+	}
+}
+
 // TestShowHelp tests the help display function
 func TestShowHelp(_ *testing.T) {
 	// ShowHelp should not panic
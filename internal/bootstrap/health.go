@@ -0,0 +1,59 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/lifecycle"
+	"github.com/willibrandon/lazynuget/internal/logging"
+)
+
+// Health is a point-in-time snapshot of the application's lifecycle, for diagnostics
+// and scripting.
+type Health struct {
+	LastErrorAt time.Time       // Zero if no error has been recorded
+	LastError   error           // Most recent bootstrap, reload, or shutdown error
+	State       lifecycle.State // Current lifecycle state
+	Uptime      time.Duration   // Time since the app entered the running state
+}
+
+// Healthy reports whether the app is running normally.
+func (h Health) Healthy() bool {
+	return h.State == lifecycle.StateRunning
+}
+
+// Health returns the current lifecycle state, uptime, and last error.
+// Safe to call from any goroutine.
+func (app *App) Health() Health {
+	app.healthMu.Lock()
+	defer app.healthMu.Unlock()
+
+	return Health{
+		State:       app.lifecycle.GetState(),
+		Uptime:      app.lifecycle.GetUptime(),
+		LastError:   app.lastError,
+		LastErrorAt: app.lastErrorAt,
+	}
+}
+
+// setLastError records an error for Health.
+func (app *App) setLastError(err error) {
+	app.healthMu.Lock()
+	defer app.healthMu.Unlock()
+
+	app.lastError = err
+	app.lastErrorAt = time.Now()
+}
+
+// logStateChange logs every lifecycle transition; failures are logged as errors.
+func (app *App) logStateChange(from, to lifecycle.State) {
+	if app.logger == nil {
+		return
+	}
+
+	lifecycleLogger := logging.ForModule(app.logger, "lifecycle")
+	if to == lifecycle.StateFailed {
+		lifecycleLogger.Error("Lifecycle state changed: %s -> %s", from, to)
+		return
+	}
+	lifecycleLogger.Debug("Lifecycle state changed: %s -> %s", from, to)
+}
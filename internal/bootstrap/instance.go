@@ -0,0 +1,41 @@
+package bootstrap
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/willibrandon/lazynuget/internal/instance"
+	"github.com/willibrandon/lazynuget/internal/logging"
+)
+
+// acquireInstanceLock locks the workspace containing workDir so a second session cannot
+// edit the same project files. A lock held by a live session is fatal (with instructions);
+// being unable to lock at all (no cache directory, read-only filesystem) only warns.
+func acquireInstanceLock(lockDir, workDir string, force bool, logger logging.Logger) (*instance.Lock, error) {
+	if lockDir == "" {
+		logger.Warn("Instance lock disabled: cache directory unavailable")
+		return nil, nil
+	}
+
+	workspace, err := instance.WorkspaceRoot(workDir)
+	if err != nil {
+		logger.Warn("Instance lock disabled: %v", err)
+		return nil, nil
+	}
+
+	lock, err := instance.Acquire(lockDir, workspace, force)
+	var held *instance.HeldError
+	if errors.As(err, &held) {
+		return nil, fmt.Errorf("%w\nClose the other instance first, or run with --force-unlock if it is no longer running", err)
+	}
+	if err != nil {
+		logger.Warn("Instance lock disabled: %v", err)
+		return nil, nil
+	}
+
+	if force {
+		logger.Warn("Took over the instance lock for %s (--force-unlock)", workspace)
+	}
+	logger.Debug("Acquired instance lock %s for %s", lock.Path(), workspace)
+	return lock, nil
+}
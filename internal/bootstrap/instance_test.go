@@ -0,0 +1,42 @@
+package bootstrap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/logging"
+)
+
+// TestAcquireInstanceLock tests the startup message for a locked workspace and --force-unlock
+func TestAcquireInstanceLock(t *testing.T) {
+	lockDir := t.TempDir()
+	workDir := t.TempDir()
+	logger := logging.New("error", "")
+
+	first, err := acquireInstanceLock(lockDir, workDir, false, logger)
+	if err != nil || first == nil {
+		t.Fatalf("acquireInstanceLock() = %v, %v; want a lock", first, err)
+	}
+	defer first.Release()
+
+	_, err = acquireInstanceLock(lockDir, workDir, false, logger)
+	if err == nil {
+		t.Fatal("second acquireInstanceLock() should fail while the first session runs")
+	}
+	for _, want := range []string{"already open in " + workDir, "--force-unlock"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %q, want it to mention %q", err, want)
+		}
+	}
+
+	forced, err := acquireInstanceLock(lockDir, workDir, true, logger)
+	if err != nil || forced == nil {
+		t.Fatalf("acquireInstanceLock(force) = %v, %v; want a lock", forced, err)
+	}
+	defer forced.Release()
+
+	// Without a lock directory, startup continues unlocked
+	if lock, err := acquireInstanceLock("", workDir, false, logger); lock != nil || err != nil {
+		t.Errorf("acquireInstanceLock(no lock dir) = %v, %v; want nil, nil", lock, err)
+	}
+}
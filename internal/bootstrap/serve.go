@@ -0,0 +1,717 @@
+package bootstrap
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/audit"
+	"github.com/willibrandon/lazynuget/internal/cache"
+	"github.com/willibrandon/lazynuget/internal/daemon"
+	"github.com/willibrandon/lazynuget/internal/hooks"
+	"github.com/willibrandon/lazynuget/internal/instance"
+	"github.com/willibrandon/lazynuget/internal/jsonrpc"
+	"github.com/willibrandon/lazynuget/internal/lifecycle"
+	"github.com/willibrandon/lazynuget/internal/logging"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/operation"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/policy"
+	"github.com/willibrandon/lazynuget/internal/prefetch"
+	"github.com/willibrandon/lazynuget/internal/project"
+	"github.com/willibrandon/lazynuget/internal/readme"
+	"github.com/willibrandon/lazynuget/internal/resolver"
+)
+
+// Serve answers JSON-RPC requests read from in, one per line, until in is closed, a
+// shutdown request arrives, or a shutdown signal is received, then shuts down. Methods
+// work on the projects of the workspace containing the current directory.
+func (app *App) Serve(in io.Reader, out io.Writer) error {
+	api, err := app.scriptAPI()
+	if err != nil {
+		return err
+	}
+
+	ctx := lifecycle.NewSignalHandler(app.lifecycle, app.logger).WaitForShutdownSignal(app.ctx)
+	app.logger.Info("Serving JSON-RPC requests on stdin for %s", api.root)
+	serveErr := api.server().Serve(ctx, in, out)
+	if err := app.Shutdown(); err != nil {
+		return err
+	}
+	return serveErr
+}
+
+// Daemon serves the same methods as Serve on a local socket until a shutdown signal is
+// received, then shuts down. An empty socketPath selects the workspace's default socket.
+func (app *App) Daemon(socketPath string) error {
+	api, err := app.scriptAPI()
+	if err != nil {
+		return err
+	}
+	if socketPath == "" {
+		socketDir := app.dirPath(app.cacheDir, daemon.SocketDirName)
+		if socketDir == "" {
+			return fmt.Errorf("cannot determine the socket directory: use --socket")
+		}
+		socketPath = daemon.SocketPath(socketDir, api.root)
+	}
+
+	d, err := daemon.Listen(socketPath, api.server())
+	if err != nil {
+		return err
+	}
+	app.RegisterShutdownHandlerWithTimeout("daemon", 50, 5*time.Second, d.Shutdown)
+	app.logger.Info("Daemon for %s listening on %s", api.root, d.Path())
+
+	ctx := lifecycle.NewSignalHandler(app.lifecycle, app.logger).WaitForShutdownSignal(app.ctx)
+	api.watchDrift(ctx)
+	<-ctx.Done()
+	return app.Shutdown()
+}
+
+// scriptAPI returns the methods of --serve and --daemon for the current workspace.
+func (app *App) scriptAPI() (*scriptAPI, error) {
+	if app.lifecycle.GetState() != lifecycle.StateRunning {
+		return nil, fmt.Errorf("cannot serve: application not in running state (current: %s)", app.lifecycle.GetState())
+	}
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	root, err := instance.WorkspaceRoot(workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Feed responses are kept between sessions; audits add OSV.dev data to the feed's
+	// advisories, cached like package icons. Request timeouts and feed credentials follow
+	// the config as it is reloaded.
+	cfg := app.GetConfig()
+	feed := nuget.NewFeed()
+	feed.HTTPClient.Transport = nuget.TimeoutTransport(feed.HTTPClient.Transport, func(req *http.Request) time.Duration {
+		return app.GetConfig().RequestTimeout(req.URL.String())
+	})
+	if dir := app.dirPath(app.cacheDir, "http"); dir != "" {
+		feed.HTTPClient = &http.Client{Transport: cache.Transport(cache.New(dir, int64(cfg.CacheSize)<<20), feed.HTTPClient.Transport)}
+	}
+	// Credentials of the configured feeds, outside the cache, which passes authenticated
+	// requests through
+	feed.HTTPClient.Transport = nuget.AuthTransport(feed.HTTPClient.Transport, func(req *http.Request) nuget.Credentials {
+		f, ok := app.GetConfig().FeedCredentials(req.URL.String())
+		if !ok {
+			return nuget.Credentials{}
+		}
+		return nuget.Credentials{APIKey: f.APIKey, Username: f.Username, Password: f.Password}
+	})
+	feed.OSV = nuget.NewOSV()
+	feed.OSV.TTL = cfg.AdvisoryCacheTTL
+	if dir := app.dirPath(app.cacheDir, "osv"); dir != "" {
+		feed.OSV.Cache = cache.New(dir, int64(cfg.CacheSize)<<20)
+	}
+
+	spawner := platform.NewProcessSpawner()
+	ops, err := operation.NewSession(cfg.OperationBackend, cfg.RestoreMode, spawner)
+	if err != nil {
+		return nil, err
+	}
+	// In batch mode, projects changed during the session are restored when it ends
+	app.RegisterShutdownHandlerWithTimeout("pending-restore", 60, cfg.Timeouts.DotnetCLI, ops.Close)
+
+	api := &scriptAPI{
+		root:        root,
+		version:     app.version.Version,
+		feed:        feed,
+		trends:      nuget.NewTrends(),
+		packagesDir: nuget.GlobalPackagesDir(),
+		projects:    project.OpenCache(project.CachePath(app.dirPath(app.cacheDir, "projects"), root)),
+		workers:     cfg.MaxConcurrentOps,
+		spawner:     spawner,
+		ops:         ops,
+		dotnet:      platform.DotnetAvailable(),
+		hooks:       &hooks.Runner{},
+		logger:      logging.ForModule(app.logger, "serve"),
+		status:      app.Health,
+		versions:    prefetch.Memo[[]string]{TTL: versionCacheTTL},
+		advisories:  prefetch.Memo[[]nuget.Advisory]{TTL: versionCacheTTL},
+	}
+	// Clients report the package under the cursor with select; its details are loaded
+	// once it has stayed there for prefetchDelay
+	api.prefetch = prefetch.New(cfg.PrefetchDelay, api.prefetchVersions, api.prefetchReadme, api.prefetchAdvisories)
+	api.prefetch.OnError = func(id, version string, err error) {
+		api.logger.Debug("Prefetching %s %s failed: %v", id, version, err)
+	}
+	app.RegisterShutdownHandlerWithTimeout("prefetch", 55, time.Second, func(_ context.Context) error {
+		api.prefetch.Close()
+		return nil
+	})
+	if err := app.policy.Check(policy.CapabilityCustomCommands); err == nil {
+		api.hooks = hooks.NewRunner(cfg)
+		api.hooks.Logger = logging.ForModule(app.logger, "hooks")
+	}
+	return api, nil
+}
+
+// ProtocolVersion is the version of the --serve and --daemon methods, returned by
+// initialize. It changes when a method or result changes incompatibly.
+const ProtocolVersion = 1
+
+// versionCacheTTL is how long the versions and advisories of a package are reused, so a
+// daemon serving an editor does not list them again on every keystroke.
+const versionCacheTTL = 5 * time.Minute
+
+// scriptAPI implements the methods of `lazynuget --serve` and `lazynuget --daemon`.
+type scriptAPI struct {
+	feed        *nuget.Feed
+	trends      *nuget.Trends
+	spawner     platform.ProcessSpawner
+	ops         *operation.Session // Changes references and restores as the settings select
+	hooks       *hooks.Runner
+	logger      logging.Logger
+	status      func() Health               // Lifecycle snapshot reported by health; nil without an app
+	prefetch    *prefetch.Prefetcher        // Loads the details of the selected package; nil without one
+	projects    *project.Cache              // Parsed projects, kept between runs; nil reads every file
+	workers     int                         // Project files parsed at once (maxConcurrentOps)
+	root        string                      // Workspace root
+	version     string                      // lazynuget's version
+	packagesDir string                      // Global packages folder, for classifying references
+	dotnet      bool                        // The dotnet CLI was found; without it audits read restore's output
+	drift       map[string][]resolver.Drift // By project path, kept by the daemon's watcher; nil without one
+	driftMu     sync.Mutex
+	editMu      sync.Mutex // Daemon clients edit projects concurrently
+
+	// Package details, shared by requests and the prefetcher
+	versions   prefetch.Memo[[]string]         // By lowercase package ID
+	readmes    prefetch.Memo[string]           // By lowercase package ID and version
+	advisories prefetch.Memo[[]nuget.Advisory] // By lowercase package ID
+}
+
+// server returns a server with the API's methods.
+func (api *scriptAPI) server() *jsonrpc.Server {
+	s := jsonrpc.NewServer()
+	s.Handle("initialize", api.initialize)
+	s.Handle("search", api.search)
+	s.Handle("versions", api.listVersions)
+	s.Handle("list", api.list)
+	s.Handle("add", api.add)
+	s.Handle("remove", api.remove)
+	s.Handle("restore", api.restore)
+	s.Handle("drift", api.checkDrift)
+	s.Handle("audit", api.audit)
+	s.Handle("quickstart", api.quickstart)
+	s.Handle("select", api.selectPackage)
+	s.Handle("details", api.details)
+	s.Handle("health", api.health)
+	return s
+}
+
+// initialize describes the server, so clients can check the protocol version and which
+// methods are available.
+func (api *scriptAPI) initialize(_ context.Context, _ json.RawMessage) (any, error) {
+	return map[string]any{
+		"name":            "lazynuget",
+		"version":         api.version,
+		"protocolVersion": ProtocolVersion,
+		"workspace":       api.root,
+		"dotnet":          api.dotnet,
+		"methods":         []string{"initialize", "search", "versions", "list", "add", "remove", "restore", "drift", "audit", "quickstart", "select", "details", "health", "shutdown"},
+	}, nil
+}
+
+// health reports the server's lifecycle state, uptime, and last error, so a client can
+// tell a daemon that is shutting down, or whose config reload failed, from a healthy one.
+func (api *scriptAPI) health(_ context.Context, _ json.RawMessage) (any, error) {
+	h := Health{State: lifecycle.StateRunning}
+	if api.status != nil {
+		h = api.status()
+	}
+	result := map[string]any{
+		"state":    h.State.String(),
+		"healthy":  h.Healthy(),
+		"uptimeMs": h.Uptime.Milliseconds(),
+	}
+	if h.LastError != nil {
+		result["lastError"] = h.LastError.Error()
+		result["lastErrorAt"] = h.LastErrorAt.UTC().Format(time.RFC3339)
+	}
+	return result, nil
+}
+
+// packageVersions returns every version of a package on nuget.org, listing them at most
+// once per versionCacheTTL.
+func (api *scriptAPI) packageVersions(ctx context.Context, id string) ([]string, error) {
+	return api.versions.Get(ctx, strings.ToLower(id), func(ctx context.Context) ([]string, error) {
+		return api.feed.Versions(ctx, id)
+	})
+}
+
+// listVersions returns the versions of a package, newest first.
+func (api *scriptAPI) listVersions(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Package    string `json:"package"`
+		Prerelease bool   `json:"prerelease"`
+	}
+	if err := jsonrpc.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Package == "" {
+		return nil, jsonrpc.InvalidParams("package is required")
+	}
+	all, err := api.packageVersions(ctx, p.Package)
+	if err != nil {
+		return nil, err
+	}
+	versions := []string{}
+	for _, v := range all {
+		if p.Prerelease || !nuget.IsPrerelease(v) {
+			versions = append(versions, v)
+		}
+	}
+	slices.SortFunc(versions, func(a, b string) int { return nuget.CompareVersions(b, a) })
+	return map[string]any{"versions": versions, "latest": nuget.Latest(versions, p.Prerelease)}, nil
+}
+
+// maxTrendMonths bounds the download history search returns per package.
+const maxTrendMonths = 60
+
+// search finds packages on nuget.org.
+func (api *scriptAPI) search(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Query      string `json:"query"`
+		Sort       string `json:"sort"`
+		Skip       int    `json:"skip"`
+		Take       int    `json:"take"`
+		Trends     int    `json:"trends"` // Months of download history per package
+		Prerelease bool   `json:"prerelease"`
+	}
+	if err := jsonrpc.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Skip < 0 || p.Take < 0 || p.Take > 1000 {
+		return nil, jsonrpc.InvalidParams("skip must not be negative and take must be between 0 and 1000")
+	}
+	if p.Sort != "" && p.Sort != "relevance" && p.Sort != "downloads" {
+		return nil, jsonrpc.InvalidParams("sort must be relevance or downloads")
+	}
+	if p.Trends < 0 || p.Trends > maxTrendMonths {
+		return nil, jsonrpc.InvalidParams("trends must be between 0 and %d months", maxTrendMonths)
+	}
+	results, err := api.feed.Search(ctx, p.Query, nuget.SearchOptions{Skip: p.Skip, Take: p.Take, Prerelease: p.Prerelease})
+	if err != nil {
+		return nil, err
+	}
+	if p.Sort == "downloads" {
+		nuget.SortByDownloads(results)
+	}
+
+	if p.Trends > 0 && api.trends != nil {
+		// Histories are an extra; a package without one is still a result
+		if err := api.trends.AddTrends(ctx, results, p.Trends); err != nil {
+			api.logger.Warn("%v", err)
+		}
+	}
+	if results == nil {
+		results = []nuget.SearchResult{}
+	}
+	return map[string]any{"packages": results}, nil
+}
+
+// listedProject is a project in the result of list.
+type listedProject struct {
+	Path                string                     `json:"path"`
+	Packages            []listedPackage            `json:"packages"`
+	FrameworkReferences []listedFrameworkReference `json:"frameworkReferences,omitempty"`
+}
+
+// listedFrameworkReference is a framework reference in the result of list.
+type listedFrameworkReference struct {
+	ID        string `json:"id"`
+	Condition string `json:"condition,omitempty"`
+}
+
+// listedPackage is a package reference in the result of list.
+type listedPackage struct {
+	ID        string `json:"id"`
+	Version   string `json:"version"`             // From Directory.Packages.props under central package management
+	Condition string `json:"condition,omitempty"` // The reference's condition (e.g., a target framework)
+	Category  string `json:"category"`            // runtime, development, or platform
+}
+
+// list returns the package references of one project, or of every project in the workspace.
+func (api *scriptAPI) list(_ context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Project string `json:"project"`
+	}
+	if err := jsonrpc.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	if p.Project != "" {
+		path, err := api.projectPath(p.Project)
+		if err != nil {
+			return nil, err
+		}
+		paths = []string{path}
+	} else {
+		var err error
+		if paths, err = api.projects.Discover(api.root); err != nil {
+			return nil, err
+		}
+	}
+
+	projects := []listedProject{}
+	for proj, err := range api.projects.LoadAll(paths, api.workers) {
+		if err != nil {
+			return nil, err
+		}
+		path := proj.Path
+		listed := listedProject{Path: path, Packages: []listedPackage{}}
+		for _, ref := range proj.FrameworkReferences {
+			listed.FrameworkReferences = append(listed.FrameworkReferences, listedFrameworkReference{ID: ref.ID, Condition: ref.Condition})
+		}
+		for _, ref := range proj.PackageReferences {
+			version := ref.Version
+			if version == "" {
+				version = project.CentralVersion(path, ref.ID)
+			}
+			listed.Packages = append(listed.Packages, listedPackage{
+				ID:        ref.ID,
+				Version:   version,
+				Condition: ref.Condition,
+				Category:  string(project.Classify(ref, api.packagesDir).Category),
+			})
+		}
+		projects = append(projects, listed)
+	}
+	if err := api.projects.Save(); err != nil {
+		api.logger.Warn("%v", err)
+	}
+	return map[string]any{"projects": projects}, nil
+}
+
+// add references a package from a project, or changes the version it references. Without
+// a version, the latest on nuget.org is used.
+func (api *scriptAPI) add(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Project    string `json:"project"`
+		Package    string `json:"package"`
+		Version    string `json:"version"`
+		Prerelease bool   `json:"prerelease"` // Consider prereleases for the latest version
+		Reason     string `json:"reason"`     // Comment written above a new reference
+		Framework  string `json:"framework"`  // Target framework the reference is scoped to
+	}
+	if err := jsonrpc.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Package == "" {
+		return nil, jsonrpc.InvalidParams("package is required")
+	}
+	path, err := api.projectPath(p.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Version == "" {
+		versions, err := api.packageVersions(ctx, p.Package)
+		if err != nil {
+			return nil, err
+		}
+		if p.Version = nuget.Latest(versions, p.Prerelease); p.Version == "" {
+			return nil, fmt.Errorf("no versions of %s found", p.Package)
+		}
+	}
+
+	api.editMu.Lock()
+	defer api.editMu.Unlock()
+
+	previous, referenced, err := operation.ReferencedVersion(path, p.Framework, p.Package)
+	if err != nil {
+		return nil, err
+	}
+
+	op := hooks.Operation{Project: path, Package: p.Package, Version: p.Version, PreviousVersion: previous}
+	if !referenced {
+		op.Event = hooks.EventPreInstall
+		if _, err := api.hooks.Run(ctx, op); err != nil {
+			return nil, err
+		}
+	}
+
+	changed, err := api.ops.SetFor(ctx, path, p.Framework, p.Package, p.Version, p.Reason)
+	if err != nil {
+		return nil, err
+	}
+	api.forgetDrift(path)
+
+	if referenced {
+		op.Event = hooks.EventPostUpdate
+		if _, err := api.hooks.Run(ctx, op); err != nil {
+			// The project was updated; the hook's failure is only reported
+			api.logger.Warn("postUpdate hook failed: %v", err)
+		}
+	}
+	if changed == nil {
+		changed = []string{}
+	}
+	return map[string]any{"version": p.Version, "previousVersion": previous, "changed": changed, "pendingRestore": api.pendingRestore()}, nil
+}
+
+// quickstart returns the setup code from a package's README, for clients to offer
+// copying after add.
+func (api *scriptAPI) quickstart(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Package string `json:"package"`
+		Version string `json:"version"`
+	}
+	if err := jsonrpc.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Package == "" || p.Version == "" {
+		return nil, jsonrpc.InvalidParams("package and version are required")
+	}
+	text, err := api.readme(ctx, p.Package, p.Version)
+	if err != nil {
+		return nil, err
+	}
+	snippet, ok := readme.Quickstart(text)
+	return map[string]any{"found": ok, "heading": snippet.Heading, "language": snippet.Language, "code": snippet.Code}, nil
+}
+
+// remove removes a project's reference to a package.
+func (api *scriptAPI) remove(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Project   string `json:"project"`
+		Package   string `json:"package"`
+		Framework string `json:"framework"` // Target framework the reference is scoped to
+	}
+	if err := jsonrpc.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Package == "" {
+		return nil, jsonrpc.InvalidParams("package is required")
+	}
+	path, err := api.projectPath(p.Project)
+	if err != nil {
+		return nil, err
+	}
+	api.editMu.Lock()
+	defer api.editMu.Unlock()
+	removed, err := api.ops.RemoveFor(ctx, path, p.Framework, p.Package)
+	if err != nil {
+		return nil, err
+	}
+	api.forgetDrift(path)
+	return map[string]any{"removed": removed, "pendingRestore": api.pendingRestore()}, nil
+}
+
+// restore restores the projects changed since their last restore, for clients using the
+// batch or manual restoreMode, and returns those still pending.
+func (api *scriptAPI) restore(ctx context.Context, _ json.RawMessage) (any, error) {
+	err := api.ops.Restore(ctx)
+	api.forgetDrift("")
+	return map[string]any{"pendingRestore": api.pendingRestore()}, err
+}
+
+// pendingRestore returns the projects changed since their last restore.
+func (api *scriptAPI) pendingRestore() []string {
+	if pending := api.ops.Pending(); pending != nil {
+		return pending
+	}
+	return []string{}
+}
+
+// audit reports vulnerable packages in a project, or in the workspace's solution or only
+// project when none is given, with the upgrade that fixes each. Projects have to be
+// restored first; without the dotnet CLI, unrestored projects are audited from their
+// direct references.
+func (api *scriptAPI) audit(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Project string `json:"project"`
+	}
+	if err := jsonrpc.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	target := api.root
+	if p.Project != "" {
+		path, err := api.projectPath(p.Project)
+		if err != nil {
+			return nil, err
+		}
+		target = path
+	}
+	var report *audit.Report
+	var err error
+	switch {
+	case api.dotnet:
+		report, err = audit.Run(ctx, api.spawner, target)
+	case p.Project != "":
+		report, err = audit.Scan(ctx, api.feed, []string{target})
+	default:
+		var paths []string
+		if paths, err = api.projects.Discover(api.root); err == nil {
+			report, err = audit.Scan(ctx, api.feed, paths)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if report.Vulnerabilities == nil {
+		report.Vulnerabilities = []audit.Vulnerability{}
+	}
+	fixes, err := audit.Suggest(ctx, api.feed, report)
+	if err != nil {
+		return nil, err
+	}
+	if fixes == nil {
+		fixes = []audit.Suggestion{}
+	}
+	return struct {
+		*audit.Report
+		Fixes []audit.Suggestion `json:"fixes"`
+	}{report, fixes}, nil
+}
+
+// projectPath resolves a project parameter: a path relative to the workspace root, or
+// absolute. Without one, the workspace must contain a single project.
+func (api *scriptAPI) projectPath(name string) (string, error) {
+	if name == "" {
+		paths, err := api.projects.Discover(api.root)
+		if err != nil {
+			return "", err
+		}
+		if len(paths) != 1 {
+			return "", jsonrpc.InvalidParams("project is required: the workspace has %d projects", len(paths))
+		}
+		return paths[0], nil
+	}
+
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(api.root, path)
+	}
+	if !project.IsProjectFile(filepath.Base(path)) {
+		return "", jsonrpc.InvalidParams("%s is not a project file", name)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", jsonrpc.InvalidParams("project %s not found", name)
+	}
+	return path, nil
+}
+
+// readme returns the README of a package version, reading it once per session: a
+// version's README does not change.
+func (api *scriptAPI) readme(ctx context.Context, id, version string) (string, error) {
+	return api.readmes.Get(ctx, strings.ToLower(id+"@"+version), func(ctx context.Context) (string, error) {
+		return api.feed.Readme(ctx, api.packagesDir, id, version)
+	})
+}
+
+// packageAdvisories returns the security advisories of a package, reading them at most
+// once per versionCacheTTL.
+func (api *scriptAPI) packageAdvisories(ctx context.Context, id string) ([]nuget.Advisory, error) {
+	return api.advisories.Get(ctx, strings.ToLower(id), func(ctx context.Context) ([]nuget.Advisory, error) {
+		return api.feed.Advisories(ctx, id)
+	})
+}
+
+// The prefetch tasks load what details returns.
+
+func (api *scriptAPI) prefetchVersions(ctx context.Context, id, _ string) error {
+	_, err := api.packageVersions(ctx, id)
+	return err
+}
+
+func (api *scriptAPI) prefetchReadme(ctx context.Context, id, version string) error {
+	if version == "" {
+		return nil
+	}
+	_, err := api.readme(ctx, id, version)
+	return err
+}
+
+func (api *scriptAPI) prefetchAdvisories(ctx context.Context, id, _ string) error {
+	_, err := api.packageAdvisories(ctx, id)
+	return err
+}
+
+// selectPackage tells the server which package the client's cursor is on, so its details
+// are loaded in the background once the cursor has stayed there for prefetchDelay. It is
+// meant to be sent as a notification on every cursor move.
+func (api *scriptAPI) selectPackage(_ context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Package string `json:"package"`
+		Version string `json:"version"`
+	}
+	if err := jsonrpc.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Package == "" {
+		return nil, jsonrpc.InvalidParams("package is required")
+	}
+	if api.prefetch != nil {
+		api.prefetch.Select(p.Package, p.Version)
+	}
+	return nil, nil
+}
+
+// details returns what a details pane shows of a package version: the package's versions,
+// the version's README, and the advisories affecting it. Prefetched details are returned
+// at once; a prefetch still running is waited for rather than repeated.
+func (api *scriptAPI) details(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Package string `json:"package"`
+		Version string `json:"version"`
+	}
+	if err := jsonrpc.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Package == "" || p.Version == "" {
+		return nil, jsonrpc.InvalidParams("package and version are required")
+	}
+
+	var versions []string
+	var text string
+	var advisories []nuget.Advisory
+	var versionsErr, readmeErr, advisoriesErr error
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		versions, versionsErr = api.packageVersions(ctx, p.Package)
+	}()
+	go func() {
+		defer wg.Done()
+		text, readmeErr = api.readme(ctx, p.Package, p.Version)
+	}()
+	go func() {
+		defer wg.Done()
+		advisories, advisoriesErr = api.packageAdvisories(ctx, p.Package)
+	}()
+	wg.Wait()
+	if err := cmp.Or(versionsErr, readmeErr, advisoriesErr); err != nil {
+		return nil, err
+	}
+
+	sorted := slices.Clone(versions)
+	slices.SortFunc(sorted, func(a, b string) int { return nuget.CompareVersions(b, a) })
+	affecting := []nuget.Advisory{}
+	for _, a := range advisories {
+		if a.Affects(p.Version) {
+			affecting = append(affecting, a)
+		}
+	}
+	if sorted == nil {
+		sorted = []string{}
+	}
+	return map[string]any{"versions": sorted, "latest": nuget.Latest(versions, nuget.IsPrerelease(p.Version)), "readme": text, "advisories": affecting}, nil
+}
@@ -0,0 +1,253 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/hooks"
+	"github.com/willibrandon/lazynuget/internal/logging"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/operation"
+	"github.com/willibrandon/lazynuget/internal/prefetch"
+)
+
+// TestScriptAPI tests the --serve methods that work on project files
+func TestScriptAPI(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "src", "App", "App.csproj")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	original := `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.0.0" />
+  </ItemGroup>
+</Project>
+`
+	if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changes take the workspace lock in the cache directory
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	ops, err := operation.NewSession(operation.BackendDirect, operation.RestoreManual, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := &scriptAPI{root: root, version: "1.0.0", ops: ops, hooks: &hooks.Runner{}, logger: logging.New("error", "")}
+	requests := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":0,"method":"initialize"}`,
+		`{"jsonrpc":"2.0","id":1,"method":"list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"add","params":{"package":"Polly","version":"8.4.0"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"add","params":{"project":"src/App/App.csproj","package":"serilog","version":"4.0.0"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"remove","params":{"package":"Polly"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"remove","params":{"package":"Polly"}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"add","params":{"version":"1.0.0"}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"list","params":{"project":"Missing.csproj"}}`,
+		`{"jsonrpc":"2.0","id":8,"method":"restore"}`,
+		`{"jsonrpc":"2.0","id":9,"method":"drift"}`,
+		`{"jsonrpc":"2.0","id":10,"method":"health"}`,
+	}, "\n")
+
+	var out strings.Builder
+	if err := api.server().Serve(context.Background(), strings.NewReader(requests), &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	want := []string{
+		`{"jsonrpc":"2.0","id":0,"result":{"dotnet":false,"methods":["initialize","search","versions","list","add","remove","restore","drift","audit","quickstart","select","details","health","shutdown"],"name":"lazynuget","protocolVersion":1,"version":"1.0.0","workspace":"` + root + `"}}`,
+		`{"jsonrpc":"2.0","id":1,"result":{"projects":[{"path":"` + path + `","packages":[{"id":"Serilog","version":"3.0.0","category":"runtime"}]}]}}`,
+		`{"jsonrpc":"2.0","id":2,"result":{"changed":["` + path + `"],"pendingRestore":["` + path + `"],"previousVersion":"","version":"8.4.0"}}`,
+		`{"jsonrpc":"2.0","id":3,"result":{"changed":["` + path + `"],"pendingRestore":["` + path + `"],"previousVersion":"3.0.0","version":"4.0.0"}}`,
+		`{"jsonrpc":"2.0","id":4,"result":{"pendingRestore":["` + path + `"],"removed":true}}`,
+		`{"jsonrpc":"2.0","id":5,"result":{"pendingRestore":["` + path + `"],"removed":false}}`,
+		`{"jsonrpc":"2.0","id":6,"error":{"code":-32602,"message":"package is required"}}`,
+		`{"jsonrpc":"2.0","id":7,"error":{"code":-32602,"message":"project Missing.csproj not found"}}`,
+		`{"jsonrpc":"2.0","id":8,"error":{"code":-32000,"message":"cannot restore: the dotnet CLI was not found in PATH"}}`,
+		`{"jsonrpc":"2.0","id":9,"result":{"projects":[{"path":"` + path + `","drift":[{"kind":"unrestored"}]}]}}`,
+		`{"jsonrpc":"2.0","id":10,"result":{"healthy":true,"state":"Running","uptimeMs":0}}`,
+	}
+	got := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(got) != len(want) {
+		t.Fatalf("got %d responses, want %d:\n%s", len(got), len(want), out.String())
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("response %d = %s\nwant %s", i+1, got[i], want[i])
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.Replace(original, "3.0.0", "4.0.0", 1); string(data) != want {
+		t.Errorf("project after edits:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+// TestScriptAPISearch tests sorting search results by downloads and adding their trends
+func TestScriptAPISearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/query":
+			fmt.Fprint(w, `{"data":[
+				{"id":"Small","version":"1.0.0","totalDownloads":10,"versions":[{"version":"1.0.0","downloads":10}]},
+				{"id":"Big","version":"2.0.0","totalDownloads":500}]}`)
+		case "/history/Big":
+			fmt.Fprint(w, `{"downloads":[{"week":"2024-01-01T00:00:00","count":400},{"week":"2024-01-08T00:00:00","count":500}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	api := &scriptAPI{
+		feed:   &nuget.Feed{SearchURL: server.URL + "/query"},
+		trends: &nuget.Trends{BaseURL: server.URL + "/history/"},
+		logger: logging.New("error", ""),
+	}
+	requests := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"search","params":{"query":"x","sort":"downloads","trends":2}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"search","params":{"sort":"name"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"search","params":{"trends":61}}`,
+	}, "\n")
+
+	var out strings.Builder
+	if err := api.server().Serve(context.Background(), strings.NewReader(requests), &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	want := []string{
+		`{"jsonrpc":"2.0","id":1,"result":{"packages":[` +
+			`{"id":"Big","version":"2.0.0","description":"","authors":null,"totalDownloads":500,"verified":false,"versions":[],` +
+			`"trend":[{"week":"2024-01-01T00:00:00Z","count":400},{"week":"2024-01-08T00:00:00Z","count":500}]},` +
+			`{"id":"Small","version":"1.0.0","description":"","authors":null,"totalDownloads":10,"verified":false,"versions":[{"version":"1.0.0","downloads":10}]}]}}`,
+		`{"jsonrpc":"2.0","id":2,"error":{"code":-32602,"message":"sort must be relevance or downloads"}}`,
+		`{"jsonrpc":"2.0","id":3,"error":{"code":-32602,"message":"trends must be between 0 and 60 months"}}`,
+	}
+	got := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(got) != len(want) {
+		t.Fatalf("got %d responses, want %d:\n%s", len(got), len(want), out.String())
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("response %d = %s\nwant %s", i+1, got[i], want[i])
+		}
+	}
+}
+
+// TestScriptAPIQuickstart tests finding the setup code in a package's README
+func TestScriptAPIQuickstart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/demo/1.0.0/readme":
+			fmt.Fprint(w, "# Demo\n\n```\ndotnet add package Demo\n```\n\n## Usage\n\n```csharp\nusing Demo;\n```\n")
+		case "/plain/1.0.0/readme":
+			fmt.Fprint(w, "# Plain\n\nNo code.")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	api := &scriptAPI{feed: &nuget.Feed{BaseURL: server.URL}, logger: logging.New("error", "")}
+	requests := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"quickstart","params":{"package":"Demo","version":"1.0.0"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"quickstart","params":{"package":"Plain","version":"1.0.0"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"quickstart","params":{"package":"Demo"}}`,
+	}, "\n")
+
+	var out strings.Builder
+	if err := api.server().Serve(context.Background(), strings.NewReader(requests), &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	want := []string{
+		`{"jsonrpc":"2.0","id":1,"result":{"code":"using Demo;","found":true,"heading":"Usage","language":"csharp"}}`,
+		`{"jsonrpc":"2.0","id":2,"result":{"code":"","found":false,"heading":"","language":""}}`,
+		`{"jsonrpc":"2.0","id":3,"error":{"code":-32602,"message":"package and version are required"}}`,
+	}
+	got := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(got) != len(want) {
+		t.Fatalf("got %d responses, want %d:\n%s", len(got), len(want), out.String())
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("response %d = %s\nwant %s", i+1, got[i], want[i])
+		}
+	}
+}
+
+// TestScriptAPIDetails tests serving the details of a selected package from its prefetch
+func TestScriptAPIDetails(t *testing.T) {
+	var requests atomic.Int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/demo/index.json":
+			fmt.Fprint(w, `{"versions":["1.0.0","1.1.0","2.0.0-beta"]}`)
+		case "/demo/1.0.0/readme":
+			fmt.Fprint(w, "# Demo")
+		case "/vulnerabilities/index.json":
+			fmt.Fprintf(w, `[{"@id":"%s/vulnerabilities/base.json"}]`, server.URL)
+		case "/vulnerabilities/base.json":
+			fmt.Fprint(w, `{"demo":[{"url":"https://github.com/advisories/GHSA-1","severity":2,"versions":"(, 1.1.0)"},`+
+				`{"url":"https://github.com/advisories/GHSA-2","severity":1,"versions":"[2.0.0-beta]"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	api := &scriptAPI{
+		feed:   &nuget.Feed{BaseURL: server.URL + "/", VulnerabilityURL: server.URL + "/vulnerabilities/index.json"},
+		logger: logging.New("error", ""),
+	}
+	api.prefetch = prefetch.New(10*time.Millisecond, api.prefetchVersions, api.prefetchReadme, api.prefetchAdvisories)
+	defer api.prefetch.Close()
+
+	var out strings.Builder
+	if err := api.server().Serve(context.Background(), strings.NewReader(`{"jsonrpc":"2.0","method":"select","params":{"package":"Demo","version":"1.0.0"}}`), &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); requests.Load() < 4 && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	lines := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"details","params":{"package":"demo","version":"1.0.0"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"details","params":{"package":"Demo"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"select","params":{}}`,
+	}, "\n")
+	if err := api.server().Serve(context.Background(), strings.NewReader(lines), &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	want := []string{
+		`{"jsonrpc":"2.0","id":1,"result":{"advisories":[{"url":"https://github.com/advisories/GHSA-1","severity":"High","versions":"(, 1.1.0)"}],` +
+			`"latest":"1.1.0","readme":"# Demo","versions":["2.0.0-beta","1.1.0","1.0.0"]}}`,
+		`{"jsonrpc":"2.0","id":2,"error":{"code":-32602,"message":"package and version are required"}}`,
+		`{"jsonrpc":"2.0","id":3,"error":{"code":-32602,"message":"package is required"}}`,
+	}
+	got := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(got) != len(want) {
+		t.Fatalf("got %d responses, want %d:\n%s", len(got), len(want), out.String())
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("response %d = %s\nwant %s", i+1, got[i], want[i])
+		}
+	}
+	if n := requests.Load(); n != 4 {
+		t.Errorf("feed got %d requests, want the 4 of the prefetch", n)
+	}
+}
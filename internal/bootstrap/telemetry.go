@@ -0,0 +1,59 @@
+package bootstrap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/telemetry"
+)
+
+// openTelemetry returns the telemetry store if the user has opted in, or nil.
+// The first interactive run asks once and remembers the answer; non-interactive runs
+// never prompt, so scripts and CI stay opted out. When disabled (--no-telemetry,
+// LAZYNUGET_NO_TELEMETRY, DO_NOT_TRACK, or machine policy) the user is not asked and
+// nothing is recorded.
+func openTelemetry(statePath string, disabled, interactive bool, in io.Reader, out io.Writer) *telemetry.Store {
+	if disabled || statePath == "" {
+		return nil
+	}
+
+	store, err := telemetry.Open(statePath)
+	if err != nil {
+		fmt.Fprintf(out, "Warning: %v\n", err)
+		return nil
+	}
+
+	if !store.Decided() {
+		if !interactive {
+			return nil
+		}
+		if err := store.SetConsent(promptTelemetry(in, out)); err != nil {
+			fmt.Fprintf(out, "Warning: telemetry decision not saved: %v\n", err)
+		}
+	}
+
+	if !store.Enabled() {
+		return nil
+	}
+	return store
+}
+
+// promptTelemetry asks whether to share anonymous usage data. Anything but "y"/"yes" declines.
+func promptTelemetry(in io.Reader, out io.Writer) bool {
+	fmt.Fprintf(out, "Help improve LazyNuGet by sharing anonymous usage statistics?\n")
+	fmt.Fprintf(out, "Only counts are collected: commands used, startup time, and error categories.\n")
+	fmt.Fprintf(out, "No package names, paths, or feed URLs. Inspect the data with `lazynuget telemetry show`;\n")
+	fmt.Fprintf(out, "change your mind with `lazynuget telemetry enable|disable`.\n")
+	fmt.Fprintf(out, "Share usage statistics? [y/N] ")
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintln(out)
+		return false
+	}
+
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,87 @@
+package bootstrap
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/telemetry"
+)
+
+// TestPromptTelemetry tests interpretation of telemetry prompt answers
+func TestPromptTelemetry(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{name: "yes", input: "y\n", want: true},
+		{name: "yes word", input: "Yes\n", want: true},
+		{name: "no", input: "n\n", want: false},
+		{name: "empty defaults to no", input: "\n", want: false},
+		{name: "eof defaults to no", input: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if got := promptTelemetry(strings.NewReader(tt.input), &out); got != tt.want {
+				t.Errorf("promptTelemetry(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			if !strings.Contains(out.String(), "lazynuget telemetry show") {
+				t.Errorf("prompt does not explain how to inspect the data:\n%s", out.String())
+			}
+		})
+	}
+}
+
+// TestOpenTelemetry tests the first-run prompt and opt-outs
+func TestOpenTelemetry(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		disabled    bool
+		interactive bool
+		wantStore   bool
+		wantPrompt  bool
+		wantDecided bool
+	}{
+		{name: "non-interactive never prompts", input: "y\n", wantStore: false},
+		{name: "disabled never prompts", input: "y\n", disabled: true, interactive: true, wantStore: false},
+		{name: "user opts in", input: "y\n", interactive: true, wantStore: true, wantPrompt: true, wantDecided: true},
+		{name: "user declines", input: "n\n", interactive: true, wantStore: false, wantPrompt: true, wantDecided: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statePath := filepath.Join(t.TempDir(), telemetry.StateFileName)
+			var out bytes.Buffer
+
+			store := openTelemetry(statePath, tt.disabled, tt.interactive, strings.NewReader(tt.input), &out)
+			if (store != nil) != tt.wantStore {
+				t.Errorf("openTelemetry() store = %v, want store %v", store, tt.wantStore)
+			}
+			if prompted := strings.Contains(out.String(), "[y/N]"); prompted != tt.wantPrompt {
+				t.Errorf("prompted = %v, want %v:\n%s", prompted, tt.wantPrompt, out.String())
+			}
+
+			saved, err := telemetry.Open(statePath)
+			if err != nil {
+				t.Fatalf("telemetry.Open() error = %v", err)
+			}
+			if saved.Decided() != tt.wantDecided {
+				t.Errorf("Decided() = %v, want %v", saved.Decided(), tt.wantDecided)
+			}
+
+			// The answer is remembered: the next run does not ask again
+			if tt.wantDecided {
+				out.Reset()
+				again := openTelemetry(statePath, false, true, strings.NewReader(""), &out)
+				if (again != nil) != tt.wantStore || out.Len() != 0 {
+					t.Errorf("second run store = %v, output %q; want remembered decision without prompt", again, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package bootstrap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+)
+
+// projectTrustFunc returns the trust gate for repository-level config overlays.
+// Known overlays follow the persisted decision. Unknown or modified overlays prompt the
+// user when interactive and are skipped otherwise, so opening an unfamiliar repository
+// never applies its config (and any commands it defines) without consent.
+func projectTrustFunc(storePath string, interactive bool, in io.Reader, out io.Writer) config.TrustFunc {
+	return func(path, fingerprint string) bool {
+		var store *config.TrustStore
+		if storePath != "" {
+			loaded, err := config.LoadTrustStore(storePath)
+			if err != nil {
+				fmt.Fprintf(out, "Warning: %v\n", err)
+			} else {
+				store = loaded
+				if trusted, decided := store.Lookup(path, fingerprint); decided {
+					return trusted
+				}
+			}
+		}
+
+		if !interactive {
+			fmt.Fprintf(out, "Warning: ignoring untrusted project config %s\n", path)
+			fmt.Fprintf(out, "Run lazynuget interactively in this repository to review it, or pass --no-repo-config.\n")
+			return false
+		}
+
+		trusted := promptTrust(in, out, path, fingerprint)
+		if store != nil {
+			if err := store.Record(path, fingerprint, trusted); err != nil {
+				fmt.Fprintf(out, "Warning: trust decision not saved: %v\n", err)
+			}
+		}
+		return trusted
+	}
+}
+
+// promptTrust asks whether to trust a project overlay. Anything but "y"/"yes" declines.
+func promptTrust(in io.Reader, out io.Writer, path, fingerprint string) bool {
+	fmt.Fprintf(out, "This repository contains a LazyNuGet config:\n")
+	fmt.Fprintf(out, "  %s\n", path)
+	fmt.Fprintf(out, "  %s\n", fingerprint)
+	fmt.Fprintf(out, "Project configs can define custom commands and hooks that run on your machine.\n")
+	fmt.Fprintf(out, "Trust this config? [y/N] ")
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintln(out)
+		return false
+	}
+
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,76 @@
+package bootstrap
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+)
+
+// TestPromptTrust tests interpretation of trust prompt answers
+func TestPromptTrust(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{name: "yes", input: "y\n", want: true},
+		{name: "yes uppercase word", input: "YES\n", want: true},
+		{name: "no", input: "n\n", want: false},
+		{name: "empty defaults to no", input: "\n", want: false},
+		{name: "eof defaults to no", input: "", want: false},
+		{name: "other answer", input: "sure\n", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			got := promptTrust(strings.NewReader(tt.input), &out, "/repo/.lazynuget.yml", "sha256:abc")
+			if got != tt.want {
+				t.Errorf("promptTrust(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			if !strings.Contains(out.String(), "/repo/.lazynuget.yml") {
+				t.Errorf("prompt does not show the config path:\n%s", out.String())
+			}
+		})
+	}
+}
+
+// TestProjectTrustFuncNonInteractive verifies unknown overlays are skipped without prompting
+func TestProjectTrustFuncNonInteractive(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), config.TrustStoreFileName)
+	var out bytes.Buffer
+
+	trust := projectTrustFunc(storePath, false, strings.NewReader("y\n"), &out)
+	if trust("/repo/.lazynuget.yml", "sha256:abc") {
+		t.Error("untrusted overlay should be skipped in non-interactive mode")
+	}
+	if !strings.Contains(out.String(), "--no-repo-config") {
+		t.Errorf("expected guidance in warning, got:\n%s", out.String())
+	}
+}
+
+// TestProjectTrustFuncPersistsDecision verifies prompt answers are remembered per fingerprint
+func TestProjectTrustFuncPersistsDecision(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), config.TrustStoreFileName)
+	var out bytes.Buffer
+
+	// First run prompts and the user accepts
+	trust := projectTrustFunc(storePath, true, strings.NewReader("y\n"), &out)
+	if !trust("/repo/.lazynuget.yml", "sha256:abc") {
+		t.Fatal("overlay should be trusted after answering yes")
+	}
+
+	// Later non-interactive runs reuse the decision
+	trust = projectTrustFunc(storePath, false, strings.NewReader(""), &out)
+	if !trust("/repo/.lazynuget.yml", "sha256:abc") {
+		t.Error("persisted trust decision was not reused")
+	}
+
+	// Modified content needs a new decision
+	if trust("/repo/.lazynuget.yml", "sha256:changed") {
+		t.Error("changed overlay should not inherit the previous decision")
+	}
+}
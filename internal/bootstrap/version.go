@@ -1,8 +1,176 @@
 package bootstrap
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/cli"
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/output"
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// VersionKind is the JSON document kind of --version --json.
+const VersionKind = "version"
+
+// dotnetVersionTimeout bounds dotnet --version, which can be slow on a first run.
+const dotnetVersionTimeout = 10 * time.Second
 
 // ShowVersion formats and prints version information.
 func ShowVersion(version VersionInfo) {
 	fmt.Println(version.String())
 }
+
+// Environment is what --version --verbose adds to the version: the context a support
+// request needs.
+type Environment struct {
+	OS         string `json:"os"`
+	OSVersion  string `json:"osVersion,omitempty"`
+	Arch       string `json:"arch"`
+	GoVersion  string `json:"goVersion"`
+	DotnetSDK  string `json:"dotnetSdk,omitempty"`   // Empty when dotnet is missing or fails
+	DotnetErr  string `json:"dotnetError,omitempty"` // Why there is no SDK version
+	ConfigFile string `json:"configFile,omitempty"`  // The user config loaded, empty for the defaults
+	RepoConfig string `json:"repoConfig,omitempty"`  // The trusted repository overlay applied
+	Profile    string `json:"profile,omitempty"`
+	ConfigErr  string `json:"configError,omitempty"` // Why the config could not be loaded
+	Feeds      int    `json:"feeds"`                 // Feeds configured (nuget.org is used when none are)
+}
+
+// versionReport is the payload of --version --json.
+type versionReport struct {
+	Version     string       `json:"version"`
+	Commit      string       `json:"commit"`
+	Date        string       `json:"date"`
+	Environment *Environment `json:"environment,omitempty"`
+}
+
+// CollectEnvironment gathers the environment for --version --verbose. It never fails:
+// what cannot be found is reported as such. The config is loaded as the UI would load
+// it, except that a repository overlay applies only when it was trusted before; nothing
+// prompts.
+func CollectEnvironment(ctx context.Context, opts config.LoadOptions) *Environment {
+	env := &Environment{
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		GoVersion: runtime.Version(),
+		Profile:   opts.Profile,
+	}
+	if info, err := platform.New(); err == nil {
+		env.OSVersion = info.Version()
+	}
+
+	dotnetCtx, cancel := context.WithTimeout(ctx, dotnetVersionTimeout)
+	defer cancel()
+	if !platform.DotnetAvailable() {
+		env.DotnetErr = "dotnet not found in PATH"
+	} else if version, err := platform.DotnetSDKVersion(dotnetCtx); err != nil {
+		env.DotnetErr = err.Error()
+	} else {
+		env.DotnetSDK = version
+	}
+
+	store, storeErr := config.LoadTrustStore(config.DefaultTrustStorePath())
+	opts.TrustProject = func(path, fingerprint string) bool {
+		if storeErr != nil {
+			return false
+		}
+		trusted, decided := store.Lookup(path, fingerprint)
+		if decided && trusted {
+			env.RepoConfig = path
+		}
+		return decided && trusted
+	}
+	cfg, err := config.NewLoader().Load(ctx, opts)
+	if err != nil {
+		env.ConfigErr = err.Error()
+		return env
+	}
+	if cfg.LoadedFrom != config.GetDefaultConfig().LoadedFrom {
+		env.ConfigFile = cfg.LoadedFrom
+	}
+	env.Feeds = len(cfg.Feeds)
+	return env
+}
+
+// WriteText writes the environment as aligned "name: value" lines.
+func (e *Environment) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	system := e.OS + "/" + e.Arch
+	if e.OSVersion != "" {
+		system += " (" + e.OSVersion + ")"
+	}
+	fmt.Fprintf(tw, "OS/Arch:\t%s\n", system)
+	fmt.Fprintf(tw, "Go:\t%s\n", e.GoVersion)
+	if e.DotnetSDK != "" {
+		fmt.Fprintf(tw, ".NET SDK:\t%s\n", e.DotnetSDK)
+	} else {
+		fmt.Fprintf(tw, ".NET SDK:\tnone (%s)\n", e.DotnetErr)
+	}
+	switch {
+	case e.ConfigErr != "":
+		fmt.Fprintf(tw, "Config file:\terror (%s)\n", e.ConfigErr)
+	case e.ConfigFile != "":
+		fmt.Fprintf(tw, "Config file:\t%s\n", e.ConfigFile)
+	default:
+		fmt.Fprintf(tw, "Config file:\tnone (defaults)\n")
+	}
+	if e.RepoConfig != "" {
+		fmt.Fprintf(tw, "Repository config:\t%s\n", e.RepoConfig)
+	}
+	if e.Profile != "" {
+		fmt.Fprintf(tw, "Profile:\t%s\n", e.Profile)
+	}
+	if e.ConfigErr == "" {
+		fmt.Fprintf(tw, "Feeds:\t%d\n", e.Feeds)
+	}
+	return tw.Flush()
+}
+
+// WriteVersion writes the version line and, when env is not nil, the environment.
+func WriteVersion(w io.Writer, version VersionInfo, env *Environment) error {
+	if _, err := fmt.Fprintln(w, version.String()); err != nil {
+		return err
+	}
+	if env == nil {
+		return nil
+	}
+	return env.WriteText(w)
+}
+
+// WriteVersionJSON writes the version and, when env is not nil, the environment as a
+// versioned JSON document.
+func WriteVersionJSON(w *output.Writer, version VersionInfo, env *Environment) error {
+	return w.Write(VersionKind, versionReport{
+		Version:     version.Version,
+		Commit:      version.Commit,
+		Date:        version.Date,
+		Environment: env,
+	})
+}
+
+// showVersion prints --version, with the environment when --verbose was given.
+func (app *App) showVersion(flags *Flags, asJSON bool) error {
+	var env *Environment
+	if flags.Verbosity == cli.VerbosityVerbose {
+		env = CollectEnvironment(app.ctx, config.LoadOptions{
+			EnvVarPrefix:    "LAZYNUGET_",
+			ConfigFilePath:  flags.ConfigPath,
+			Profile:         flags.Profile,
+			NoProjectConfig: flags.NoRepoConfig,
+		})
+	}
+	if !asJSON {
+		return WriteVersion(os.Stdout, app.version, env)
+	}
+	writer, err := output.NewWriter(os.Stdout, flags.OutputVersion)
+	if err != nil {
+		return err
+	}
+	return WriteVersionJSON(writer, app.version, env)
+}
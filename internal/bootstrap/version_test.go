@@ -1,7 +1,16 @@
 package bootstrap
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/output"
 )
 
 // TestShowVersion tests the version display function
@@ -55,3 +64,69 @@ func TestShowVersionWithRealApp(t *testing.T) {
 	// Should not panic
 	ShowVersion(app.version)
 }
+
+// TestWriteVersion tests the version line followed, with --verbose, by the environment
+func TestWriteVersion(t *testing.T) {
+	version := VersionInfo{Version: "1.2.3", Commit: "abc123", Date: "2025-01-01"}
+
+	var buf bytes.Buffer
+	if err := WriteVersion(&buf, version, nil); err != nil {
+		t.Fatalf("WriteVersion() error = %v", err)
+	}
+	if got := buf.String(); got != version.String()+"\n" {
+		t.Errorf("WriteVersion() = %q, want the version line only", got)
+	}
+
+	buf.Reset()
+	env := &Environment{OS: "linux", Arch: "amd64", GoVersion: "go1.24.0", DotnetErr: "dotnet not found in PATH", ConfigFile: "/home/u/.config/lazynuget/config.yml", Feeds: 2}
+	if err := WriteVersion(&buf, version, env); err != nil {
+		t.Fatalf("WriteVersion() error = %v", err)
+	}
+	for _, want := range []string{"OS/Arch:", "linux/amd64", ".NET SDK:", "none (dotnet not found in PATH)", "/home/u/.config/lazynuget/config.yml", "Feeds:", "2"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("WriteVersion() = %q, missing %q", buf.String(), want)
+		}
+	}
+
+	buf.Reset()
+	writer, err := output.NewWriter(&buf, output.CurrentSchemaVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteVersionJSON(writer, version, env); err != nil {
+		t.Fatalf("WriteVersionJSON() error = %v", err)
+	}
+	var doc struct {
+		Kind string        `json:"kind"`
+		Data versionReport `json:"data"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("WriteVersionJSON() wrote invalid JSON: %v", err)
+	}
+	if doc.Kind != VersionKind || doc.Data.Version != "1.2.3" || doc.Data.Environment == nil || doc.Data.Environment.Feeds != 2 {
+		t.Errorf("WriteVersionJSON() = %+v", doc)
+	}
+}
+
+// TestCollectEnvironment tests that the environment names the config file loaded and its feeds
+func TestCollectEnvironment(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.yml")
+	data := "feeds:\n  - name: internal\n    url: https://nuget.example.com/v3/index.json\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	env := CollectEnvironment(context.Background(), config.LoadOptions{ConfigFilePath: path, NoProjectConfig: true})
+	if env.ConfigFile != path || env.Feeds != 1 || env.ConfigErr != "" {
+		t.Errorf("CollectEnvironment() = %+v, want %s with one feed", env, path)
+	}
+	if env.DotnetSDK != "" || env.DotnetErr == "" {
+		t.Errorf("CollectEnvironment() without dotnet = %+v, want the reason", env)
+	}
+
+	env = CollectEnvironment(context.Background(), config.LoadOptions{ConfigFilePath: filepath.Join(t.TempDir(), "missing.yml"), NoProjectConfig: true})
+	if env.ConfigErr == "" {
+		t.Errorf("CollectEnvironment() with a missing config = %+v, want the error", env)
+	}
+}
@@ -0,0 +1,179 @@
+// Package cache stores derived data, such as converted package icons and feed responses,
+// in files under the user's cache directory. Each cache is a folder bounded by a size
+// limit (the cacheSize setting); when a write exceeds it, the least recently used entries
+// are removed. Several processes may share a folder: writes take turns on its lock file
+// and keep an index of the entries, rebuilt from the entries after a crash.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// Cache is a folder of entries keyed by arbitrary strings.
+type Cache struct {
+	dir      string
+	maxBytes int64
+	mu       sync.Mutex
+}
+
+// New returns a cache in dir holding at most maxBytes; 0 means unbounded.
+// The folder is created on the first write.
+func New(dir string, maxBytes int64) *Cache {
+	return &Cache{dir: dir, maxBytes: maxBytes}
+}
+
+// DefaultDir returns the folder of a named cache in the platform cache directory, or in
+// its temp fallback when that is unusable (see platform.AppDir).
+func DefaultDir(name string) string {
+	return platform.AppDir(platform.DirCache, name)
+}
+
+// Dir returns the cache's folder.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Get returns the entry stored under key. Reading an entry marks it as recently used.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	path := c.path(key)
+	// #nosec G304 -- path is a hashed file name inside the cache folder
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return data, true
+}
+
+// Put stores data under key, replacing any previous entry, and evicts the least recently
+// used entries while the cache is over its limit. Other processes may use the same
+// folder: writes take turns on its lock file (see locked).
+func (c *Cache) Put(key string, data []byte) error {
+	return c.locked(func(idx *index) error {
+		name := c.name(key)
+		if err := c.journal("put", name); err != nil {
+			return err
+		}
+		if err := c.writeFile(name, data); err != nil {
+			return err
+		}
+		idx.Entries[name] = int64(len(data))
+		return c.evict(idx)
+	})
+}
+
+// Delete removes the entry stored under key, if any.
+func (c *Cache) Delete(key string) error {
+	if _, err := os.Stat(c.path(key)); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return c.locked(func(idx *index) error {
+		name := c.name(key)
+		if err := c.journal("evict", name); err != nil {
+			return err
+		}
+		delete(idx.Entries, name)
+		return removeIfExists(c.path(key))
+	})
+}
+
+// writeFile replaces a file of the cache folder. The data goes to a temporary file first,
+// so readers never see a partial file.
+func (c *Cache) writeFile(name string, data []byte) error {
+	tmp, err := os.CreateTemp(c.dir, tempPrefix+"*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(c.dir, name)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// name returns the file name of an entry; keys are hashed so any string is a valid key.
+func (c *Cache) name(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// path returns the file of an entry.
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, c.name(key))
+}
+
+// evict removes the least recently used entries until the index fits the cache's limit.
+// Reads mark entries as used by their modification time, so only the entries' times are
+// read here, not the folder. The entries to remove are journaled first.
+func (c *Cache) evict(idx *index) error {
+	if c.maxBytes <= 0 {
+		return nil
+	}
+	var total int64
+	for _, size := range idx.Entries {
+		total += size
+	}
+	if total <= c.maxBytes {
+		return nil
+	}
+
+	type file struct {
+		name    string
+		size    int64
+		modTime time.Time
+	}
+	files := make([]file, 0, len(idx.Entries))
+	for name, size := range idx.Entries {
+		info, err := os.Stat(filepath.Join(c.dir, name))
+		if err != nil {
+			// Removed behind the index's back
+			delete(idx.Entries, name)
+			total -= size
+			continue
+		}
+		files = append(files, file{name, size, info.ModTime()})
+	}
+	slices.SortFunc(files, func(a, b file) int { return a.modTime.Compare(b.modTime) })
+
+	var victims []string
+	for _, f := range files {
+		if total <= c.maxBytes {
+			break
+		}
+		victims = append(victims, f.name)
+		total -= f.size
+	}
+	if len(victims) == 0 {
+		return nil
+	}
+	if err := c.journal("evict", victims...); err != nil {
+		return err
+	}
+	for _, name := range victims {
+		if err := removeIfExists(filepath.Join(c.dir, name)); err != nil {
+			return err
+		}
+		delete(idx.Entries, name)
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestCache tests storing entries and evicting the least recently used
+func TestCache(t *testing.T) {
+	c := New(t.TempDir(), 25)
+
+	if _, ok := c.Get("missing"); ok {
+		t.Error("Get() of a missing key should fail")
+	}
+
+	// Give each entry a distinct, increasing access time
+	base := time.Now().Add(-time.Hour)
+	for i, key := range []string{"a", "b"} {
+		if err := c.Put(key, []byte("0123456789")); err != nil {
+			t.Fatalf("Put(%s) error = %v", key, err)
+		}
+		at := base.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(c.path(key), at, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Reading a marks it as recently used, so b is evicted when c no longer fits
+	if data, ok := c.Get("a"); !ok || string(data) != "0123456789" {
+		t.Errorf("Get(a) = %q, %v", data, ok)
+	}
+	if err := c.Put("c", []byte("0123456789")); err != nil {
+		t.Fatalf("Put(c) error = %v", err)
+	}
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := c.Get(key); ok != want {
+			t.Errorf("Get(%s) found = %v, want %v", key, ok, want)
+		}
+	}
+
+	// Empty entries are entries too
+	if err := c.Put("empty", nil); err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := c.Get("empty"); !ok || len(data) != 0 {
+		t.Errorf("Get(empty) = %q, %v; want an empty entry", data, ok)
+	}
+}
+
+// TestTransport tests serving feed responses from the cache while fresh, revalidating
+// them once stale, and refetching damaged entries
+func TestTransport(t *testing.T) {
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		switch r.URL.Path {
+		case "/registration/index.json", "/private", "/apikey", "/compressed":
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "Accept-Encoding")
+		case "/search":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/user":
+			w.Header().Set("Cache-Control", "private, max-age=60")
+		case "/vary":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Add("Vary", "Accept-Encoding")
+			w.Header().Add("Vary", "Authorization")
+		}
+		fmt.Fprintf(w, "body of %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	c := New(t.TempDir(), 0)
+	now := time.Now()
+	transport := Transport(c, nil).(*httpTransport)
+	transport.now = func() time.Time { return now }
+	client := &http.Client{Transport: transport}
+	get := func(path string, header ...string) string {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s = %d", path, resp.StatusCode)
+		}
+		return string(data)
+	}
+
+	const registration = "/registration/index.json"
+	for range 2 {
+		if got := get(registration); got != "body of "+registration {
+			t.Errorf("GET = %q", got)
+		}
+	}
+	if requests[registration] != 1 {
+		t.Errorf("requests while fresh = %d, want 1", requests[registration])
+	}
+
+	// A new transport reads what the previous run stored; once stale, a 304 keeps it
+	now = now.Add(2 * time.Minute)
+	transport = Transport(c, nil).(*httpTransport)
+	transport.now = func() time.Time { return now }
+	client.Transport = transport
+	if got := get(registration); got != "body of "+registration || requests[registration] != 2 {
+		t.Errorf("GET stale = %q after %d requests, want the cached body after a revalidation", got, requests[registration])
+	}
+	if get(registration); requests[registration] != 2 {
+		t.Errorf("requests after the 304 = %d, want it fresh again", requests[registration])
+	}
+
+	// A damaged entry is fetched again
+	if err := os.WriteFile(c.path(server.URL+registration), []byte("{\"url\":\"x\"}\ntruncat"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := get(registration); got != "body of "+registration || requests[registration] != 3 {
+		t.Errorf("GET damaged = %q after %d requests, want a refetch", got, requests[registration])
+	}
+
+	// Vary: Accept-Encoding alone does not keep a response out of the cache
+	get("/compressed")
+	if get("/compressed"); requests["/compressed"] != 1 {
+		t.Errorf("requests of a response varying by Accept-Encoding = %d, want 1", requests["/compressed"])
+	}
+
+	for _, tt := range []struct {
+		path   string
+		header []string
+	}{
+		{"/search", nil},
+		{"/user", nil},
+		{"/vary", nil},
+		{"/private", []string{"Authorization", "Basic eDp5"}},
+		{"/apikey", []string{"X-NuGet-ApiKey", "key"}},
+	} {
+		get(tt.path, tt.header...)
+		get(tt.path, tt.header...)
+		if requests[tt.path] != 2 {
+			t.Errorf("%s was served from the cache", tt.path)
+		}
+	}
+}
+
+// TestCacheRecovery tests rebuilding the index after a crash and replaying an
+// interrupted eviction
+func TestCacheRecovery(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir, 0)
+	for _, key := range []string{"a", "b"} {
+		if err := c.Put(key, []byte("0123456789")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	readIndex := func() map[string]int64 {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, indexName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var idx index
+		if err := json.Unmarshal(data, &idx); err != nil {
+			t.Fatal(err)
+		}
+		return idx.Entries
+	}
+
+	// A damaged index and a temporary file of a write that never finished
+	if err := os.WriteFile(filepath.Join(dir, indexName), []byte(`{"version":1,"entr`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, tempPrefix+"crashed"), []byte("partial"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Put("c", []byte("01234")); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int64{c.name("a"): 10, c.name("b"): 10, c.name("c"): 5}
+	if got := readIndex(); !maps.Equal(got, want) {
+		t.Errorf("rebuilt index = %v, want %v", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, tempPrefix+"crashed")); !errors.Is(err, fs.ErrNotExist) {
+		t.Error("the temporary file of the crashed write was kept")
+	}
+
+	// A process that crashed while evicting a left its journal
+	if err := os.WriteFile(filepath.Join(dir, journalName), []byte("put "+c.name("d")+"\nevict "+c.name("a")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Delete("b"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readIndex(); !maps.Equal(got, map[string]int64{c.name("c"): 5}) {
+		t.Errorf("index after replaying the journal = %v, want c alone", got)
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Error("the entry the journal was evicting was kept")
+	}
+	if _, err := os.Stat(filepath.Join(dir, journalName)); !errors.Is(err, fs.ErrNotExist) {
+		t.Error("the journal was kept after the write")
+	}
+}
+
+// TestCacheConcurrentWriters tests caches of several processes writing one folder
+func TestCacheConcurrentWriters(t *testing.T) {
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	for writer := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Each Cache opens the lock file itself, like another process would
+			c := New(dir, 200)
+			for i := range 25 {
+				if err := c.Put(fmt.Sprintf("%d-%d", writer, i), []byte("0123456789")); err != nil {
+					t.Errorf("Put() error = %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	idx, err := New(dir, 200).recover()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var indexed int64
+	for _, size := range idx.Entries {
+		indexed += size
+	}
+	rebuilt, err := New(dir, 200).rebuild()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(idx.Entries, rebuilt.Entries) {
+		t.Errorf("index = %v, want the entries in the folder %v", idx.Entries, rebuilt.Entries)
+	}
+	if indexed > 200 || indexed == 0 {
+		t.Errorf("cache holds %d bytes, want at most 200", indexed)
+	}
+}
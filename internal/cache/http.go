@@ -0,0 +1,253 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/metrics"
+)
+
+// maxHTTPEntry bounds a cached response body; larger responses, such as package
+// downloads, pass through uncached.
+const maxHTTPEntry = 8 << 20
+
+// maxHeuristicAge bounds the freshness guessed from Last-Modified for responses that do
+// not say how long they stay fresh.
+const maxHeuristicAge = 24 * time.Hour
+
+// cachedHeaders are the response headers kept with a cached body.
+var cachedHeaders = []string{"Content-Type", "ETag", "Last-Modified", "Cache-Control"}
+
+// credentialHeaders identify the user to the server; requests carrying one are not
+// cached, since the answer may differ by user and must not be served to another.
+var credentialHeaders = []string{"Authorization", "X-NuGet-ApiKey", "Cookie"}
+
+// httpEntry is the header line of a cached response; the body follows it.
+type httpEntry struct {
+	URL    string            `json:"url"`
+	Header map[string]string `json:"header"`
+	Stored time.Time         `json:"stored"` // When the response was generated, less its Age
+	MaxAge time.Duration     `json:"maxAge"` // How long after Stored it is fresh
+	SHA256 string            `json:"sha256"` // Of the body, checked on every read
+}
+
+// fresh reports whether the entry can be served without asking the server.
+func (e *httpEntry) fresh(now time.Time) bool {
+	return now.Sub(e.Stored) < e.MaxAge
+}
+
+// httpTransport serves GET requests from a cache, see Transport.
+type httpTransport struct {
+	cache *Cache
+	next  http.RoundTripper
+	now   func() time.Time
+}
+
+// Transport wraps an http.RoundTripper to keep successful GET responses in c across runs,
+// as an HTTP cache would: a response is served from the cache while its Cache-Control
+// max-age (or Expires, or a tenth of its age since Last-Modified, up to a day) says it is
+// fresh, then revalidated with its ETag or Last-Modified. Each entry records the SHA-256
+// of its body; an entry that no longer matches, such as one cut short by a crash, is
+// removed and the response fetched again. Requests with credentials (an Authorization,
+// X-NuGet-ApiKey, or Cookie header) bypass the cache, so wrap it in the transport that
+// adds feed credentials, not the other way round. Responses marked no-store or private,
+// and responses that vary by request headers other than Accept-Encoding, are not cached.
+// Nil uses http.DefaultTransport.
+func Transport(c *Cache, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &httpTransport{cache: c, next: next, now: time.Now}
+}
+
+func (t *httpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" || hasCredentials(req.Header) {
+		return t.next.RoundTrip(req)
+	}
+	key := req.URL.String()
+	entry, body := t.load(key)
+	if entry != nil && entry.fresh(t.now()) {
+		metrics.RecordCacheLookup("http", true)
+		return entry.response(req, body), nil
+	}
+
+	if entry != nil {
+		// Ask whether the stale entry is still current
+		req = req.Clone(req.Context())
+		if etag := entry.Header["ETag"]; etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if modified := entry.Header["Last-Modified"]; modified != "" {
+			req.Header.Set("If-Modified-Since", modified)
+		}
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		metrics.RecordCacheLookup("http", true)
+		_ = resp.Body.Close()
+		// A 304 may leave out the headers that did not change
+		if cc := resp.Header.Get("Cache-Control"); cc != "" {
+			entry.Header["Cache-Control"] = cc
+		}
+		header := resp.Header.Clone()
+		header.Set("Cache-Control", entry.Header["Cache-Control"])
+		entry.Stored, entry.MaxAge = t.stored(resp.Header), freshness(header, entry.Header["Last-Modified"], t.now())
+		_ = t.cache.Put(key, encodeEntry(entry, body))
+		return entry.response(req, body), nil
+	}
+	metrics.RecordCacheLookup("http", false)
+	if resp.StatusCode != http.StatusOK || !storable(resp.Header) || resp.ContentLength > maxHTTPEntry {
+		return resp, nil
+	}
+
+	// Keep the body if it fits; otherwise hand back what was read followed by the rest
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPEntry+1))
+	if err != nil || len(data) > maxHTTPEntry {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	entry = &httpEntry{URL: key, Header: make(map[string]string), Stored: t.stored(resp.Header)}
+	for _, name := range cachedHeaders {
+		if v := resp.Header.Get(name); v != "" {
+			entry.Header[name] = v
+		}
+	}
+	entry.MaxAge = freshness(resp.Header, entry.Header["Last-Modified"], t.now())
+	if entry.MaxAge > 0 || entry.Header["ETag"] != "" || entry.Header["Last-Modified"] != "" {
+		sum := sha256.Sum256(data)
+		entry.SHA256 = hex.EncodeToString(sum[:])
+		_ = t.cache.Put(key, encodeEntry(entry, data))
+	}
+	return resp, nil
+}
+
+// hasCredentials reports whether a request carries credentials.
+func hasCredentials(header http.Header) bool {
+	for _, name := range credentialHeaders {
+		if header.Get(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// storable reports whether a response may be kept in a cache shared by every request to
+// its URL: it is not marked no-store or private, and varies by no request header other
+// than Accept-Encoding (which the HTTP client sets the same way on every request).
+func storable(header http.Header) bool {
+	for _, directive := range strings.Split(strings.ToLower(header.Get("Cache-Control")), ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if name == "no-store" || name == "private" {
+			return false
+		}
+	}
+	for _, vary := range header.Values("Vary") {
+		for _, name := range strings.Split(vary, ",") {
+			if name = strings.TrimSpace(name); name != "" && !strings.EqualFold(name, "Accept-Encoding") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// load returns the cached response to a URL, or nil when there is none or it is damaged;
+// a damaged entry is removed.
+func (t *httpTransport) load(key string) (*httpEntry, []byte) {
+	data, ok := t.cache.Get(key)
+	if !ok {
+		return nil, nil
+	}
+	line, body, found := bytes.Cut(data, []byte("\n"))
+	var entry httpEntry
+	if found && json.Unmarshal(line, &entry) == nil && entry.URL == key {
+		if sum := sha256.Sum256(body); hex.EncodeToString(sum[:]) == entry.SHA256 {
+			return &entry, body
+		}
+	}
+	_ = t.cache.Delete(key)
+	return nil, nil
+}
+
+// stored returns when a response was generated: now, less the Age a shared cache on the
+// way reported.
+func (t *httpTransport) stored(header http.Header) time.Time {
+	now := t.now()
+	if age, err := strconv.Atoi(header.Get("Age")); err == nil && age > 0 {
+		return now.Add(-time.Duration(age) * time.Second)
+	}
+	return now
+}
+
+// response returns a cached response to req.
+func (e *httpEntry) response(req *http.Request, body []byte) *http.Response {
+	header := make(http.Header, len(e.Header))
+	for name, v := range e.Header {
+		header.Set(name, v)
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// encodeEntry returns the file content of a cached response: the entry's JSON on the
+// first line, then the body.
+func encodeEntry(e *httpEntry, body []byte) []byte {
+	line, _ := json.Marshal(e)
+	return append(append(line, '\n'), body...)
+}
+
+// freshness returns how long a response stays fresh: its Cache-Control max-age, or until
+// Expires, or a tenth of the time since it was last modified. no-cache makes it stale at
+// once, so it is revalidated on every use.
+func freshness(header http.Header, lastModified string, now time.Time) time.Duration {
+	for _, directive := range strings.Split(strings.ToLower(header.Get("Cache-Control")), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch name {
+		case "no-cache":
+			return 0
+		case "max-age":
+			if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		date = now
+	}
+	if expires := header.Get("Expires"); expires != "" {
+		if t, err := http.ParseTime(expires); err == nil && t.After(date) {
+			return t.Sub(date)
+		}
+		return 0
+	}
+	if modified, err := http.ParseTime(lastModified); err == nil && modified.Before(date) {
+		return min(date.Sub(modified)/10, maxHeuristicAge)
+	}
+	return 0
+}
@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// Files of a cache folder besides its entries.
+const (
+	indexName   = "index.json"
+	journalName = "journal"
+	lockName    = ".lock"
+	tempPrefix  = ".tmp-"
+)
+
+// indexVersion is the version of the index file; an index of another version is rebuilt.
+const indexVersion = 1
+
+// index records the entries of a cache folder and their sizes, so that a write need not
+// list the folder to know whether the cache is over its limit. Only the process holding
+// the folder's lock file writes it.
+type index struct {
+	Version int              `json:"version"`
+	Entries map[string]int64 `json:"entries"` // Entry file name → size in bytes
+}
+
+// locked runs fn with the folder's index while holding its lock file, then saves the
+// index. Processes sharing the cache folder (a session and a batch run, say) write one
+// at a time; readers need no lock, as entries are replaced by renaming.
+//
+// Changes are journaled before they are made: fn records each entry it writes or removes
+// with c.journal. A journal left behind by a process that crashed midway is replayed
+// before fn runs: the entries it was evicting are removed, and the index is rebuilt
+// from the entries in the folder.
+func (c *Cache) locked(fn func(*index) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return err
+	}
+	// #nosec G304 -- the lock file is inside the cache folder
+	lock, err := os.OpenFile(filepath.Join(c.dir, lockName), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+	if err := platform.LockFile(lock); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", c.dir, err)
+	}
+	defer func() { _ = platform.UnlockFile(lock) }()
+
+	idx, err := c.recover()
+	if err != nil {
+		return err
+	}
+	if err := fn(idx); err != nil {
+		return err
+	}
+	if err := c.saveIndex(idx); err != nil {
+		return err
+	}
+	return removeIfExists(filepath.Join(c.dir, journalName))
+}
+
+// recover returns the index, replaying the journal of an interrupted write first and
+// rebuilding an index that is missing, damaged, or of another version.
+func (c *Cache) recover() (*index, error) {
+	// #nosec G304 -- the journal is inside the cache folder
+	journal, err := os.ReadFile(filepath.Join(c.dir, journalName))
+	switch {
+	case err == nil:
+		scanner := bufio.NewScanner(strings.NewReader(string(journal)))
+		for scanner.Scan() {
+			if op, name, ok := strings.Cut(scanner.Text(), " "); ok && op == "evict" && entryName(name) {
+				if err := removeIfExists(filepath.Join(c.dir, name)); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return c.rebuild()
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	}
+
+	// #nosec G304 -- the index is inside the cache folder
+	data, err := os.ReadFile(filepath.Join(c.dir, indexName))
+	var idx index
+	if err != nil || json.Unmarshal(data, &idx) != nil || idx.Version != indexVersion || idx.Entries == nil {
+		return c.rebuild()
+	}
+	return &idx, nil
+}
+
+// rebuild returns an index of the entries in the folder, removing the temporary files of
+// writes that never finished.
+func (c *Cache) rebuild() (*index, error) {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil, err
+	}
+	idx := &index{Version: indexVersion, Entries: make(map[string]int64)}
+	for _, f := range files {
+		switch name := f.Name(); {
+		case strings.HasPrefix(name, tempPrefix):
+			if err := removeIfExists(filepath.Join(c.dir, name)); err != nil {
+				return nil, err
+			}
+		case entryName(name):
+			if info, err := f.Info(); err == nil && info.Mode().IsRegular() {
+				idx.Entries[name] = info.Size()
+			}
+		}
+	}
+	return idx, nil
+}
+
+// saveIndex replaces the index file.
+func (c *Cache) saveIndex(idx *index) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	return c.writeFile(indexName, data)
+}
+
+// journal records changes about to be made to entries ("put" or "evict"), and flushes
+// them to disk before they are made.
+func (c *Cache) journal(op string, names ...string) error {
+	// #nosec G304 -- the journal is inside the cache folder
+	f, err := os.OpenFile(filepath.Join(c.dir, journalName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	for _, name := range names {
+		sb.WriteString(op + " " + name + "\n")
+	}
+	if _, err := f.WriteString(sb.String()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// entryName reports whether a file name is an entry's: a hashed key.
+func entryName(name string) bool {
+	_, err := hex.DecodeString(name)
+	return err == nil && len(name) == 64
+}
+
+// removeIfExists removes a file that may already be gone.
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
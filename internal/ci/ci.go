@@ -0,0 +1,116 @@
+// Package ci writes check results in the report formats CI systems read: SARIF for code
+// scanning (e.g., GitHub's) and JUnit XML for the test tabs of CI servers.
+package ci
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Format is a report format.
+type Format string
+
+// Report formats.
+const (
+	FormatSARIF Format = "sarif"
+	FormatJUnit Format = "junit"
+)
+
+// Formats lists the report formats, for flag values.
+var Formats = []string{string(FormatSARIF), string(FormatJUnit)}
+
+// Level is how serious a finding is, as SARIF names it.
+type Level string
+
+// Finding levels.
+const (
+	LevelError   Level = "error"
+	LevelWarning Level = "warning"
+	LevelNote    Level = "note"
+)
+
+// Rule is a kind of finding.
+type Rule struct {
+	ID          string // Stable identifier (e.g., "vulnerable-package")
+	Name        string // PascalCase name (e.g., "VulnerablePackage")
+	Description string
+	Level       Level // Level of its findings, when they do not vary; LevelError when empty
+}
+
+// Finding is a problem a check found.
+type Finding struct {
+	Rule    string
+	Level   Level
+	Message string
+}
+
+// Check is one package of a project that was checked, and what was found.
+type Check struct {
+	Project  string // Path of the project file
+	Package  string
+	Version  string
+	Skipped  string // Why the package could not be checked, or ""
+	Findings []Finding
+}
+
+// Report is the outcome of a lazynuget command as a CI report.
+type Report struct {
+	Name    string // The command (e.g., "audit"), naming the JUnit test suites
+	Version string // lazynuget's version
+	Root    string // Repository root; project paths are reported relative to it
+	Rules   []Rule
+	Checks  []Check
+}
+
+// ParseFormat parses a --report-format value.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case FormatSARIF, FormatJUnit:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown report format %q (want sarif or junit)", s)
+}
+
+// Write writes a report in a format.
+func Write(w io.Writer, format Format, r *Report) error {
+	switch format {
+	case FormatSARIF:
+		return WriteSARIF(w, r)
+	case FormatJUnit:
+		return WriteJUnit(w, r)
+	}
+	return fmt.Errorf("unknown report format %q", format)
+}
+
+// WriteFile writes a report to a file, or to stdout when the path is "-".
+func WriteFile(path string, format Format, r *Report) error {
+	if path == "-" {
+		return Write(os.Stdout, format, r)
+	}
+	// #nosec G304 -- path is the report file the user passed
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := Write(f, format, r); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return f.Close()
+}
+
+// relativePath returns a project path relative to the repository root, with forward
+// slashes, or the path itself when it is outside the root.
+func relativePath(path, root string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil || root == "" {
+		return filepath.ToSlash(path)
+	}
+	if rel, err := filepath.Rel(root, abs); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
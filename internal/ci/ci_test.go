@@ -0,0 +1,133 @@
+package ci
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testReport returns a report of two projects, one of them outside the repository root.
+func testReport(t *testing.T) *Report {
+	root := t.TempDir()
+	project := filepath.Join(root, "src", "App", "App.csproj")
+	if err := os.MkdirAll(filepath.Dir(project), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(project, []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Newtonsoft.Json" Version="12.0.1" />
+  </ItemGroup>
+</Project>
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return &Report{
+		Name:    "audit",
+		Version: "1.2.3",
+		Root:    root,
+		Rules:   []Rule{{ID: "vulnerable-package", Name: "VulnerablePackage", Description: "A package has a known vulnerability"}},
+		Checks: []Check{
+			{Project: project, Package: "Newtonsoft.Json", Version: "12.0.1", Findings: []Finding{
+				{Rule: "vulnerable-package", Level: LevelError, Message: "High vulnerability"},
+				{Rule: "vulnerable-package", Level: LevelNote, Message: "Low vulnerability"},
+			}},
+			{Project: project, Package: "Serilog", Version: "3.0.0"},
+			{Project: project, Package: "Transitive", Version: "1.0.0", Findings: []Finding{{Rule: "vulnerable-package", Level: LevelWarning, Message: "Moderate"}}},
+			{Project: "/elsewhere/Lib.csproj", Package: "Broken", Version: "[1.0", Skipped: "invalid range"},
+		},
+	}
+}
+
+// TestParseFormat tests --report-format values
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat(" SARIF"); err != nil || f != FormatSARIF {
+		t.Errorf("ParseFormat(SARIF) = %q, %v", f, err)
+	}
+	if f, err := ParseFormat("junit"); err != nil || f != FormatJUnit {
+		t.Errorf("ParseFormat(junit) = %q, %v", f, err)
+	}
+	if _, err := ParseFormat("html"); err == nil {
+		t.Error("ParseFormat(html) error = nil")
+	}
+}
+
+// TestWriteSARIF tests a result per finding located at the package reference
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatSARIF, testReport(t)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	run := log.Runs[0]
+	if log.Version != "2.1.0" || run.Tool.Driver.Version != "1.2.3" || len(run.Tool.Driver.Rules) != 1 || len(run.Results) != 3 {
+		t.Fatalf("SARIF = %s", buf.String())
+	}
+	first := run.Results[0]
+	location := first.Locations[0].PhysicalLocation
+	if first.Level != LevelError || location.ArtifactLocation.URI != "src/App/App.csproj" || location.Region == nil || location.Region.StartLine != 3 {
+		t.Errorf("first result = %+v", first)
+	}
+	// Transitive packages have no line of their own
+	if r := run.Results[2]; r.Level != LevelWarning || r.Locations[0].PhysicalLocation.Region != nil {
+		t.Errorf("transitive result = %+v", r)
+	}
+}
+
+// TestWriteJUnit tests a suite per project and a test case per package
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJUnit, testReport(t)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "<?xml") {
+		t.Errorf("JUnit has no XML header: %s", buf.String())
+	}
+	var doc junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid XML: %v", err)
+	}
+	if doc.Name != "lazynuget audit" || doc.Tests != 4 || doc.Failures != 2 || doc.Skipped != 1 || len(doc.Suites) != 2 {
+		t.Fatalf("JUnit = %s", buf.String())
+	}
+	app := doc.Suites[0]
+	if app.Name != "src/App/App.csproj" || app.Tests != 3 || app.Failures != 2 {
+		t.Errorf("App suite = %+v", app)
+	}
+	if tc := app.Cases[0]; tc.Name != "Newtonsoft.Json 12.0.1" || tc.Failure == nil || tc.Failure.Message != "High vulnerability" ||
+		!strings.Contains(tc.Failure.Text, "note: Low vulnerability") {
+		t.Errorf("failing case = %+v", tc)
+	}
+	if tc := app.Cases[1]; tc.Failure != nil || tc.Skipped != nil {
+		t.Errorf("passing case = %+v", tc)
+	}
+	if tc := doc.Suites[1].Cases[0]; doc.Suites[1].Name != "/elsewhere/Lib.csproj" || tc.Skipped == nil || tc.Skipped.Message != "invalid range" {
+		t.Errorf("skipped case = %+v", doc.Suites[1])
+	}
+}
+
+// TestWriteAnnotations tests GitHub workflow commands at the package references
+func TestWriteAnnotations(t *testing.T) {
+	if !InGitHubActions(func(string) string { return "true" }) || InGitHubActions(func(string) string { return "" }) {
+		t.Error("InGitHubActions() misread GITHUB_ACTIONS")
+	}
+	r := testReport(t)
+	r.Checks[2].Findings[0].Message = "100% bad\nreally"
+	var buf bytes.Buffer
+	if err := WriteAnnotations(&buf, r); err != nil {
+		t.Fatalf("WriteAnnotations() error = %v", err)
+	}
+	want := `::error file=src/App/App.csproj,line=3,title=VulnerablePackage::High vulnerability
+::notice file=src/App/App.csproj,line=3,title=VulnerablePackage::Low vulnerability
+::warning file=src/App/App.csproj,title=VulnerablePackage::100%25 bad%0Areally
+`
+	if buf.String() != want {
+		t.Errorf("WriteAnnotations() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
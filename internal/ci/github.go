@@ -0,0 +1,86 @@
+package ci
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// InGitHubActions reports whether lazynuget runs in a GitHub Actions job, whose runner
+// turns workflow commands in the output into annotations.
+func InGitHubActions(getenv func(string) string) bool {
+	return getenv("GITHUB_ACTIONS") == "true"
+}
+
+// annotationCommands map finding levels to GitHub workflow commands.
+var annotationCommands = map[Level]string{LevelError: "error", LevelWarning: "warning", LevelNote: "notice"}
+
+// WriteAnnotations writes a report's findings as GitHub Actions workflow commands
+// (::error file=...,line=...::message), which annotate the project file at the line
+// referencing the package, or the whole file for transitive packages.
+func WriteAnnotations(w io.Writer, r *Report) error {
+	names := make(map[string]string)
+	for _, rule := range r.Rules {
+		names[rule.ID] = rule.Name
+	}
+	lines := newLineCache()
+	for _, c := range r.Checks {
+		if len(c.Findings) == 0 {
+			continue
+		}
+		properties := "file=" + escapeProperty(relativePath(c.Project, r.Root))
+		if line := lines.reference(c.Project, c.Package); line > 0 {
+			properties += fmt.Sprintf(",line=%d", line)
+		}
+		for _, f := range c.Findings {
+			command, ok := annotationCommands[f.Level]
+			if !ok {
+				command = "error"
+			}
+			title := ""
+			if name := names[f.Rule]; name != "" {
+				title = ",title=" + escapeProperty(name)
+			}
+			if _, err := fmt.Fprintf(w, "::%s %s%s::%s\n", command, properties, title, escapeData(f.Message)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// escapeData escapes the message of a workflow command.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// lineCache finds package references in project files, reading each file once.
+type lineCache map[string][]string
+
+func newLineCache() lineCache {
+	return make(lineCache)
+}
+
+// reference returns the 1-based line of a project file that references a package, or 0.
+func (lc lineCache) reference(project, id string) int {
+	lines, ok := lc[project]
+	if !ok {
+		// #nosec G304 -- project files of the report
+		data, _ := os.ReadFile(project)
+		lines = strings.Split(string(data), "\n")
+		lc[project] = lines
+	}
+	needle := strings.ToLower(`Include="` + id + `"`)
+	for i, line := range lines {
+		if strings.Contains(strings.ToLower(line), needle) {
+			return i + 1
+		}
+	}
+	return 0
+}
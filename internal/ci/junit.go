@@ -0,0 +1,85 @@
+package ci
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes a report as JUnit XML: a test suite per project and a test case per
+// package, which fails with the package's findings. JUnit allows one failure per test
+// case, so it carries the first finding's rule and message, and every message in its text.
+func WriteJUnit(w io.Writer, r *Report) error {
+	doc := junitSuites{Name: "lazynuget " + r.Name}
+	suites := make(map[string]int) // Project -> index in doc.Suites
+	for _, c := range r.Checks {
+		project := relativePath(c.Project, r.Root)
+		i, ok := suites[project]
+		if !ok {
+			i = len(doc.Suites)
+			suites[project] = i
+			doc.Suites = append(doc.Suites, junitSuite{Name: project})
+		}
+		suite := &doc.Suites[i]
+
+		tc := junitCase{Name: strings.TrimSpace(c.Package + " " + c.Version), ClassName: project}
+		switch {
+		case len(c.Findings) > 0:
+			messages := make([]string, len(c.Findings))
+			for j, f := range c.Findings {
+				messages[j] = string(f.Level) + ": " + f.Message
+			}
+			tc.Failure = &junitFailure{Type: c.Findings[0].Rule, Message: c.Findings[0].Message, Text: strings.Join(messages, "\n")}
+			suite.Failures++
+			doc.Failures++
+		case c.Skipped != "":
+			tc.Skipped = &junitSkipped{Message: c.Skipped}
+			suite.Skipped++
+			doc.Skipped++
+		}
+		suite.Cases = append(suite.Cases, tc)
+		suite.Tests++
+		doc.Tests++
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, xml.Header+string(data)+"\n")
+	return err
+}
@@ -0,0 +1,106 @@
+package ci
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// sarifSchema is the schema of the SARIF 2.1.0 logs GitHub code scanning accepts.
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string       `json:"id"`
+	Name                 string       `json:"name"`
+	ShortDescription     sarifMessage `json:"shortDescription"`
+	DefaultConfiguration struct {
+		Level Level `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     Level           `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI       string `json:"uri"`
+			URIBaseID string `json:"uriBaseId"`
+		} `json:"artifactLocation"`
+		Region *sarifRegion `json:"region,omitempty"`
+	} `json:"physicalLocation"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// WriteSARIF writes a report as a SARIF 2.1.0 log with a result per finding. Each result
+// points at the project file, relative to the repository root, and at the line
+// referencing the package when the project references it directly.
+func WriteSARIF(w io.Writer, r *Report) error {
+	driver := sarifDriver{Name: "lazynuget", Version: r.Version, InformationURI: "https://github.com/willibrandon/lazynuget", Rules: []sarifRule{}}
+	for _, rule := range r.Rules {
+		sr := sarifRule{ID: rule.ID, Name: rule.Name, ShortDescription: sarifMessage{Text: rule.Description}}
+		sr.DefaultConfiguration.Level = rule.Level
+		if rule.Level == "" {
+			sr.DefaultConfiguration.Level = LevelError
+		}
+		driver.Rules = append(driver.Rules, sr)
+	}
+
+	results := []sarifResult{}
+	lines := newLineCache()
+	for _, c := range r.Checks {
+		if len(c.Findings) == 0 {
+			continue
+		}
+		var location sarifLocation
+		location.PhysicalLocation.ArtifactLocation.URI = relativePath(c.Project, r.Root)
+		location.PhysicalLocation.ArtifactLocation.URIBaseID = "%SRCROOT%"
+		if line := lines.reference(c.Project, c.Package); line > 0 {
+			location.PhysicalLocation.Region = &sarifRegion{StartLine: line}
+		}
+		for _, f := range c.Findings {
+			results = append(results, sarifResult{RuleID: f.Rule, Level: f.Level, Message: sarifMessage{Text: f.Message},
+				Locations: []sarifLocation{location}})
+		}
+	}
+
+	data, err := json.MarshalIndent(sarifLog{Schema: sarifSchema, Version: "2.1.0",
+		Runs: []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,355 @@
+// Package cli is the central registry of lazynuget's commands, flags, and arguments.
+//
+// Each command is described once (Root) and everything else is derived from that
+// description: flag parsing (Parse), --help text (WriteHelp), man pages (WriteMan), and
+// shell completion (Completion). The cmd/lazynuget package maps command paths to handlers.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/completion"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/output"
+	"github.com/willibrandon/lazynuget/internal/policy"
+)
+
+// Flag describes a command-line flag. Flags with a Placeholder take a value; the others
+// are booleans.
+type Flag struct {
+	Name        string   // Without dashes
+	Placeholder string   // Value name in help (e.g., PATH); empty for boolean flags
+	Usage       string   // One-line description
+	Default     string   // Default value of a value flag
+	Values      []string // Fixed choices, offered by shell completion
+	Kind        completion.Kind
+}
+
+// Arg describes a positional argument.
+type Arg struct {
+	Name     string
+	Usage    string
+	Kind     completion.Kind
+	Optional bool
+	Variadic bool // Accepts any number of values (must be last)
+}
+
+// Example is a sample invocation shown in help and man pages.
+type Example struct {
+	Command     string
+	Description string
+}
+
+// ExitCode documents an exit status.
+type ExitCode struct {
+	Meaning string
+	Code    int
+}
+
+// StandardExitCodes are the exit statuses shared by all commands.
+var StandardExitCodes = []ExitCode{
+	{Code: exitcode.Success, Meaning: "Success"},
+	{Code: exitcode.UserError, Meaning: "Usage or user error (invalid arguments or configuration)"},
+	{Code: exitcode.SystemError, Meaning: "System error (I/O, network, or unexpected failure)"},
+}
+
+// GlobalFlags are accepted by lazynuget and by every command; they set the Verbosity and
+// the OutputVersion.
+var GlobalFlags = []Flag{
+	{Name: "quiet", Usage: "Print only results and errors, and log only errors"},
+	{Name: "verbose", Usage: "Print details and debug logs"},
+	{Name: "output-version", Placeholder: "N", Usage: "Emit JSON output using schema version N (default: current)", Values: outputVersions()},
+}
+
+// Verbosity is how much is printed and logged besides results and errors.
+type Verbosity int
+
+// Verbosities, from the global flags.
+const (
+	VerbosityNormal  Verbosity = iota
+	VerbosityQuiet             // --quiet
+	VerbosityVerbose           // --verbose
+)
+
+// LogLevel returns the log level that goes with the verbosity, or "" when the configured
+// level applies.
+func (v Verbosity) LogLevel() string {
+	switch v {
+	case VerbosityQuiet:
+		return "error"
+	case VerbosityVerbose:
+		return "debug"
+	default:
+		return ""
+	}
+}
+
+// Command describes a command or command group.
+type Command struct {
+	parent      *Command
+	Name        string
+	Summary     string // One line, shown in command lists
+	Description string // Paragraphs for help and man pages; defaults to Summary
+	Flags       []Flag
+	Args        []Arg
+	Examples    []Example
+	ExitCodes   []ExitCode // Defaults to StandardExitCodes
+	Subcommands []*Command
+	Hidden      bool              // Omitted from help, man pages, and completion
+	Requires    policy.Capability // Capability the machine policy can disable; marked in help when it does
+}
+
+// Path returns the full command path (e.g., "lazynuget config schema").
+func (c *Command) Path() string {
+	if c.parent == nil {
+		return c.Name
+	}
+	return c.parent.Path() + " " + c.Name
+}
+
+// Key returns the command path without the program name (e.g., "config schema").
+func (c *Command) Key() string {
+	if c.parent == nil {
+		return ""
+	}
+	if key := c.parent.Key(); key != "" {
+		return key + " " + c.Name
+	}
+	return c.Name
+}
+
+// Parent returns the enclosing command, or nil for the root.
+func (c *Command) Parent() *Command {
+	return c.parent
+}
+
+// Subcommand returns the direct subcommand with the given name.
+func (c *Command) Subcommand(name string) *Command {
+	for _, sub := range c.Subcommands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// Find walks args along subcommand names and returns the deepest command reached and
+// the remaining arguments.
+func (c *Command) Find(args []string) (*Command, []string) {
+	cmd := c
+	for len(args) > 0 {
+		sub := cmd.Subcommand(args[0])
+		if sub == nil {
+			break
+		}
+		cmd, args = sub, args[1:]
+	}
+	return cmd, args
+}
+
+// Lookup returns the command with the given key (e.g., "config schema"), or nil.
+func (c *Command) Lookup(key string) *Command {
+	cmd, rest := c.Find(strings.Fields(key))
+	if len(rest) > 0 {
+		return nil
+	}
+	return cmd
+}
+
+// Walk calls fn for the command and all its descendants, depth first.
+func (c *Command) Walk(fn func(*Command)) {
+	fn(c)
+	for _, sub := range c.Subcommands {
+		sub.Walk(fn)
+	}
+}
+
+// exitCodes returns the documented exit codes.
+func (c *Command) exitCodes() []ExitCode {
+	if c.ExitCodes != nil {
+		return c.ExitCodes
+	}
+	return StandardExitCodes
+}
+
+// usageLines returns the synopses shown under "Usage:". The root runs on its own or
+// dispatches to a command; groups only dispatch.
+func (c *Command) usageLines() []string {
+	if c.parent == nil && len(c.Subcommands) > 0 {
+		return []string{c.Path() + " " + c.usageLine(), c.Path() + " <command> [arguments]"}
+	}
+	return []string{strings.TrimSpace(c.Path() + " " + c.usageLine())}
+}
+
+// usageLine returns the synopsis after the command path (e.g., "[options] <plaintext>").
+func (c *Command) usageLine() string {
+	var parts []string
+	if len(c.Subcommands) > 0 && c.parent != nil {
+		parts = append(parts, "<command>")
+	}
+	if len(c.Flags) > 0 {
+		parts = append(parts, "[options]")
+	}
+	for _, arg := range c.Args {
+		name := arg.Name
+		if arg.Variadic {
+			name += "..."
+		}
+		if arg.Optional {
+			parts = append(parts, "["+name+"]")
+		} else {
+			parts = append(parts, "<"+name+">")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Values holds parsed flags and positional arguments.
+type Values struct {
+	strings map[string]*string
+	bools   map[string]*bool
+	args    []string
+}
+
+// String returns a value flag ("" for unknown names).
+func (v *Values) String(name string) string {
+	if p, ok := v.strings[name]; ok {
+		return *p
+	}
+	return ""
+}
+
+// Bool returns a boolean flag (false for unknown names).
+func (v *Values) Bool(name string) bool {
+	if p, ok := v.bools[name]; ok {
+		return *p
+	}
+	return false
+}
+
+// Verbosity returns the verbosity set by the global flags.
+func (v *Values) Verbosity() Verbosity {
+	switch {
+	case v.Bool("quiet"):
+		return VerbosityQuiet
+	case v.Bool("verbose"):
+		return VerbosityVerbose
+	default:
+		return VerbosityNormal
+	}
+}
+
+// OutputVersion returns the JSON schema version set by --output-version, or the current
+// one.
+func (v *Values) OutputVersion() (int, error) {
+	return output.ParseVersion(v.String("output-version"))
+}
+
+// Args returns the positional arguments.
+func (v *Values) Args() []string {
+	return v.args
+}
+
+// Parse parses flags and positional arguments. It returns flag.ErrHelp for -h/--help
+// (unless the command defines its own help flag) and an error for unknown flags or a
+// wrong number of arguments.
+func (c *Command) Parse(args []string) (*Values, error) {
+	fs := flag.NewFlagSet(c.Path(), flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() { /* Help is rendered by WriteHelp */ }
+
+	values := &Values{strings: make(map[string]*string), bools: make(map[string]*bool)}
+	for _, f := range c.Flags {
+		if f.Placeholder != "" {
+			values.strings[f.Name] = fs.String(f.Name, f.Default, f.Usage)
+		} else {
+			values.bools[f.Name] = fs.Bool(f.Name, false, f.Usage)
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	values.args = fs.Args()
+	if values.Bool("quiet") && values.Bool("verbose") {
+		return nil, errors.New("--quiet and --verbose cannot be used together")
+	}
+	// Fail fast on unsupported versions, before a command does any work
+	if _, err := values.OutputVersion(); err != nil {
+		return nil, err
+	}
+
+	if err := c.checkArgs(values.args); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// checkArgs validates the number of positional arguments.
+func (c *Command) checkArgs(args []string) error {
+	required, maxArgs := 0, len(c.Args)
+	for _, arg := range c.Args {
+		if !arg.Optional && !arg.Variadic {
+			required++
+		}
+		if arg.Variadic {
+			maxArgs = -1
+		}
+	}
+
+	switch {
+	case len(args) < required:
+		return fmt.Errorf("missing argument <%s>", c.Args[len(args)].Name)
+	case maxArgs >= 0 && len(args) > maxArgs:
+		return fmt.Errorf("unexpected argument %q", args[maxArgs])
+	}
+	return nil
+}
+
+// IsHelp reports whether err is a request for help rather than a usage error.
+func IsHelp(err error) bool {
+	return errors.Is(err, flag.ErrHelp)
+}
+
+// Completion converts the command tree for shell completion.
+func (c *Command) Completion() *completion.Command {
+	out := &completion.Command{Name: c.Name, Description: c.Summary, Hidden: c.Hidden}
+	for _, f := range c.Flags {
+		kind := f.Kind
+		if f.Placeholder != "" && kind == completion.KindNone && len(f.Values) == 0 {
+			kind = completion.KindText
+		}
+		out.Flags = append(out.Flags, completion.Flag{Name: f.Name, Description: f.Usage, Values: f.Values, Value: kind})
+	}
+	if len(c.Args) > 0 {
+		out.Args = c.Args[0].Kind
+	}
+	for _, sub := range c.Subcommands {
+		out.Subcommands = append(out.Subcommands, *sub.Completion())
+	}
+	return out
+}
+
+// withGlobalFlags adds the GlobalFlags to the root and to every command that is not a
+// group, before the tree is linked.
+func (c *Command) withGlobalFlags() *Command {
+	c.Walk(func(cmd *Command) {
+		if cmd == c || len(cmd.Subcommands) == 0 {
+			cmd.Flags = append(cmd.Flags, GlobalFlags...)
+		}
+	})
+	return c
+}
+
+// link sets parent pointers throughout the tree.
+func (c *Command) link() *Command {
+	for _, sub := range c.Subcommands {
+		sub.parent = c
+		sub.link()
+	}
+	return c
+}
@@ -0,0 +1,330 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/completion"
+	"github.com/willibrandon/lazynuget/internal/policy"
+)
+
+// testTree is a small command tree exercising flags, arguments, and groups.
+func testTree() *Command {
+	return (&Command{
+		Name:    "app",
+		Summary: "Test application",
+		Flags:   []Flag{{Name: "verbose", Usage: "Verbose output"}},
+		Subcommands: []*Command{
+			{
+				Name:    "add",
+				Summary: "Add a package",
+				Flags: []Flag{
+					{Name: "version", Placeholder: "VERSION", Usage: "Package version", Default: "latest"},
+					{Name: "prerelease", Usage: "Allow prereleases"},
+				},
+				Args: []Arg{
+					{Name: "package", Usage: "Package ID", Kind: completion.KindPackage},
+					{Name: "project", Usage: "Project file", Optional: true},
+				},
+				Examples: []Example{{Command: "app add Serilog", Description: "Add the latest Serilog"}},
+			},
+			{
+				Name:        "config",
+				Summary:     "Config utilities",
+				Subcommands: []*Command{{Name: "schema", Summary: "Write the schema"}},
+			},
+			{Name: "hidden", Summary: "Internal", Hidden: true},
+		},
+	}).link()
+}
+
+// TestFind tests walking argument lists along subcommand names
+func TestFind(t *testing.T) {
+	root := testTree()
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantKey  string
+		wantRest []string
+	}{
+		{name: "root", args: []string{"--verbose"}, wantKey: "", wantRest: []string{"--verbose"}},
+		{name: "leaf", args: []string{"add", "Serilog"}, wantKey: "add", wantRest: []string{"Serilog"}},
+		{name: "nested", args: []string{"config", "schema", "out.json"}, wantKey: "config schema", wantRest: []string{"out.json"}},
+		{name: "unknown subcommand", args: []string{"config", "bogus"}, wantKey: "config", wantRest: []string{"bogus"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, rest := root.Find(tt.args)
+			if cmd.Key() != tt.wantKey {
+				t.Errorf("Find(%v) key = %q, want %q", tt.args, cmd.Key(), tt.wantKey)
+			}
+			if !slices.Equal(rest, tt.wantRest) {
+				t.Errorf("Find(%v) rest = %v, want %v", tt.args, rest, tt.wantRest)
+			}
+		})
+	}
+
+	if got := root.Lookup("config schema").Path(); got != "app config schema" {
+		t.Errorf("Lookup(config schema).Path() = %q, want %q", got, "app config schema")
+	}
+	if root.Lookup("config bogus") != nil {
+		t.Error("Lookup(config bogus) should be nil")
+	}
+}
+
+// TestParse tests flag and positional argument parsing from the command spec
+func TestParse(t *testing.T) {
+	add := testTree().Lookup("add")
+
+	tests := []struct {
+		name           string
+		args           []string
+		wantVersion    string
+		wantArgs       []string
+		wantPrerelease bool
+		wantErr        bool
+		wantHelp       bool
+	}{
+		{name: "defaults", args: []string{"Serilog"}, wantVersion: "latest", wantArgs: []string{"Serilog"}},
+		{name: "flags", args: []string{"--version", "3.1.0", "-prerelease", "Serilog", "App.csproj"}, wantVersion: "3.1.0", wantPrerelease: true, wantArgs: []string{"Serilog", "App.csproj"}},
+		{name: "missing argument", args: []string{}, wantErr: true},
+		{name: "extra argument", args: []string{"a", "b", "c"}, wantErr: true},
+		{name: "unknown flag", args: []string{"--bogus", "Serilog"}, wantErr: true},
+		{name: "help", args: []string{"--help"}, wantErr: true, wantHelp: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := add.Parse(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if IsHelp(err) != tt.wantHelp {
+				t.Errorf("IsHelp(%v) = %v, want %v", err, IsHelp(err), tt.wantHelp)
+			}
+			if err != nil {
+				return
+			}
+			if got := values.String("version"); got != tt.wantVersion {
+				t.Errorf("String(version) = %q, want %q", got, tt.wantVersion)
+			}
+			if got := values.Bool("prerelease"); got != tt.wantPrerelease {
+				t.Errorf("Bool(prerelease) = %v, want %v", got, tt.wantPrerelease)
+			}
+			if !slices.Equal(values.Args(), tt.wantArgs) {
+				t.Errorf("Args() = %v, want %v", values.Args(), tt.wantArgs)
+			}
+		})
+	}
+}
+
+// TestWriteHelp tests the generated --help text
+func TestWriteHelp(t *testing.T) {
+	root := testTree()
+
+	var sb strings.Builder
+	if err := root.Lookup("add").WriteHelp(&sb); err != nil {
+		t.Fatalf("WriteHelp() error = %v", err)
+	}
+	help := sb.String()
+	for _, want := range []string{
+		"Usage:\n  app add [options] <package> [project]\n",
+		"--version VERSION   Package version (default: latest)",
+		"package  Package ID",
+		"app add Serilog  # Add the latest Serilog",
+		"Exit codes:\n  0  Success",
+	} {
+		if !strings.Contains(help, want) {
+			t.Errorf("WriteHelp() missing %q in:\n%s", want, help)
+		}
+	}
+
+	sb.Reset()
+	if err := root.WriteHelp(&sb); err != nil {
+		t.Fatalf("WriteHelp() error = %v", err)
+	}
+	if !strings.Contains(sb.String(), "Commands:\n  add     Add a package\n  config  Config utilities\n") {
+		t.Errorf("root help should list visible commands, got:\n%s", sb.String())
+	}
+	if strings.Contains(sb.String(), "hidden") {
+		t.Error("root help should not list hidden commands")
+	}
+}
+
+// TestWritePolicyHelp tests that commands the machine policy disables are marked in help
+func TestWritePolicyHelp(t *testing.T) {
+	root := (&Command{
+		Name:    "app",
+		Summary: "Test application",
+		Subcommands: []*Command{
+			{Name: "push", Summary: "Push a package", Requires: policy.CapabilityPush},
+			{Name: "update-self", Summary: "Update the app", Requires: policy.CapabilitySelfUpdate},
+		},
+	}).link()
+
+	path := filepath.Join(t.TempDir(), policy.PolicyFileName)
+	if err := os.WriteFile(path, []byte("disabled: [push]\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write policy: %v", err)
+	}
+	p, err := policy.Load(path)
+	if err != nil {
+		t.Fatalf("policy.Load() error = %v", err)
+	}
+
+	var sb strings.Builder
+	if err := root.WritePolicyHelp(&sb, p); err != nil {
+		t.Fatalf("WritePolicyHelp() error = %v", err)
+	}
+	help := sb.String()
+	if !strings.Contains(help, "Push a package (restricted by policy)") {
+		t.Errorf("restricted command not marked in:\n%s", help)
+	}
+	if strings.Contains(help, "Update the app (") {
+		t.Errorf("allowed command marked in:\n%s", help)
+	}
+
+	sb.Reset()
+	if err := root.WriteHelp(&sb); err != nil {
+		t.Fatalf("WriteHelp() error = %v", err)
+	}
+	if strings.Contains(sb.String(), policy.RestrictedLabel) {
+		t.Errorf("WriteHelp() without a policy marked commands:\n%s", sb.String())
+	}
+}
+
+// TestWriteMan tests roff rendering and escaping
+func TestWriteMan(t *testing.T) {
+	root := testTree()
+	date := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	var sb strings.Builder
+	if err := root.Lookup("add").WriteMan(&sb, "1.2.3", date); err != nil {
+		t.Fatalf("WriteMan() error = %v", err)
+	}
+	page := sb.String()
+	for _, want := range []string{
+		`.TH APP-ADD 1 "2026-01-02" "lazynuget 1.2.3" "LazyNuGet Manual"`,
+		"app-add \\- Add a package",
+		`.BI \-\-version " " VERSION`,
+		".SH EXIT STATUS",
+		".BR app (1)",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("WriteMan() missing %q in:\n%s", want, page)
+		}
+	}
+
+	if got := roffText(".hidden control\n'quote"); got != "\\&.hidden control\n\\&'quote" {
+		t.Errorf("roffText() = %q, want control characters protected", got)
+	}
+
+	dir := t.TempDir()
+	written, err := root.WriteManPages(dir, "1.2.3", date)
+	if err != nil {
+		t.Fatalf("WriteManPages() error = %v", err)
+	}
+	want := []string{"app.1", "app-add.1", "app-config.1", "app-config-schema.1"}
+	if !slices.Equal(written, want) {
+		t.Errorf("WriteManPages() = %v, want %v", written, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "app-hidden.1")); !os.IsNotExist(err) {
+		t.Error("hidden commands should not get a man page")
+	}
+}
+
+// TestCompletion tests converting the tree for shell completion
+func TestCompletion(t *testing.T) {
+	tree := testTree().Completion()
+	got := completion.Complete(tree, []string{"add", ""}, completion.Sources{
+		Packages: func(string) []string { return []string{"Serilog"} },
+	})
+	if !slices.Equal(got, []string{"Serilog"}) {
+		t.Errorf("Complete(add) = %v, want [Serilog]", got)
+	}
+
+	got = completion.Complete(tree, []string{"add", "--"}, completion.Sources{})
+	if !slices.Contains(got, "--version") || !slices.Contains(got, "--prerelease") {
+		t.Errorf("Complete(add --) = %v, want the add flags", got)
+	}
+}
+
+// TestRegistry checks the lazynuget command tree for documentation gaps
+func TestRegistry(t *testing.T) {
+	Root().Walk(func(cmd *Command) {
+		if cmd.Summary == "" {
+			t.Errorf("command %q has no summary", cmd.Path())
+		}
+		seen := make(map[string]bool)
+		for _, f := range cmd.Flags {
+			if f.Usage == "" {
+				t.Errorf("flag --%s of %q has no usage", f.Name, cmd.Path())
+			}
+			if seen[f.Name] {
+				t.Errorf("flag --%s of %q is declared twice", f.Name, cmd.Path())
+			}
+			seen[f.Name] = true
+		}
+	})
+
+	for _, key := range []string{"config schema", "update-self", "docs man", "completion bash"} {
+		if Lookup(key) == nil {
+			t.Errorf("Lookup(%q) = nil", key)
+		}
+	}
+}
+
+// TestGlobalFlags tests that every command takes --quiet, --verbose, and --output-version
+func TestGlobalFlags(t *testing.T) {
+	audit := Lookup("audit")
+	for _, tt := range []struct {
+		args []string
+		want Verbosity
+	}{
+		{nil, VerbosityNormal},
+		{[]string{"--quiet"}, VerbosityQuiet},
+		{[]string{"--verbose", "--fix"}, VerbosityVerbose},
+	} {
+		values, err := audit.Parse(tt.args)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.args, err)
+		}
+		if got := values.Verbosity(); got != tt.want {
+			t.Errorf("Parse(%q).Verbosity() = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+	if _, err := audit.Parse([]string{"--quiet", "--verbose"}); err == nil {
+		t.Error("Parse(--quiet --verbose) should fail")
+	}
+	values, err := Lookup("keys list").Parse([]string{"--json", "--output-version", "1"})
+	if err != nil {
+		t.Fatalf("Parse(--output-version 1) error = %v", err)
+	}
+	if got, err := values.OutputVersion(); got != 1 || err != nil {
+		t.Errorf("OutputVersion() = %d, %v, want 1", got, err)
+	}
+	if _, err := audit.Parse([]string{"--output-version", "99"}); err == nil {
+		t.Error("Parse(--output-version 99) should fail")
+	}
+	if _, err := Root().Parse([]string{"--quiet"}); err != nil {
+		t.Errorf("Root().Parse(--quiet) error = %v", err)
+	}
+	if got := Lookup("config").Flags; len(got) != 0 {
+		t.Errorf("group flags = %v, want none", got)
+	}
+	if VerbosityQuiet.LogLevel() != "error" || VerbosityVerbose.LogLevel() != "debug" || VerbosityNormal.LogLevel() != "" {
+		t.Error("LogLevel() does not match the verbosities")
+	}
+}
+
+// TestWrap tests word wrapping of help descriptions
+func TestWrap(t *testing.T) {
+	if got := wrap("one two three four", 9); got != "one two\nthree\nfour" {
+		t.Errorf("wrap() = %q", got)
+	}
+}
@@ -0,0 +1,1627 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/willibrandon/lazynuget/internal/ci"
+	"github.com/willibrandon/lazynuget/internal/completion"
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/exitcode"
+	"github.com/willibrandon/lazynuget/internal/metrics"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/output"
+	"github.com/willibrandon/lazynuget/internal/policy"
+)
+
+// Program is the executable name used in usage lines and man pages.
+const Program = "lazynuget"
+
+// DefaultManDir is where `lazynuget docs man` writes pages when no directory is given.
+const DefaultManDir = "man"
+
+// root is built once; commands are immutable after construction.
+var root = sync.OnceValue(func() *Command {
+	return newRoot().withGlobalFlags().link()
+})
+
+// Root returns the lazynuget command tree.
+func Root() *Command {
+	return root()
+}
+
+// Lookup returns the command with the given key (e.g., "config schema"), or nil.
+func Lookup(key string) *Command {
+	return Root().Lookup(key)
+}
+
+// newRoot describes every command. Handlers are attached by key in cmd/lazynuget.
+func newRoot() *Command {
+	return &Command{
+		Name:    Program,
+		Summary: "Terminal UI for NuGet package management",
+		Description: "LazyNuGet is a terminal UI for managing NuGet packages in .NET projects.\n\n" +
+			"Run without a command to start the interactive UI in the current repository. " +
+			"Settings come from the user config, the repository's .lazynuget.yml, LAZYNUGET_* environment variables, and the options below, in increasing precedence.",
+		Flags: []Flag{
+			{Name: "version", Usage: "Show version information and exit (with --verbose: also the OS, .NET SDK, config file, and feeds)"},
+			{Name: "json", Usage: "With --version, write the version as a versioned JSON document"},
+			{Name: "help", Usage: "Show this help message and exit"},
+			{Name: "config", Placeholder: "PATH", Usage: "Path to configuration file", Kind: completion.KindFile},
+			{Name: "log-level", Placeholder: "LEVEL", Usage: "Set log level (debug|info|warn|error)", Default: "info", Values: []string{"debug", "info", "warn", "error"}},
+			{Name: "profile", Placeholder: "NAME", Usage: "Apply a named config profile (or set LAZYNUGET_PROFILE)", Kind: completion.KindProfile},
+			{Name: "non-interactive", Usage: "Run in non-interactive mode (no TUI)"},
+			{Name: "no-color", Usage: "Draw without colors, using bold, underline, and reverse video instead (or set NO_COLOR)"},
+			{Name: "serve", Usage: "Answer JSON-RPC requests on stdin (search, list, add, remove, audit) instead of starting the UI"},
+			{Name: "daemon", Usage: "Answer the same requests on a local socket for editor extensions (see docs/DAEMON_PROTOCOL.md)"},
+			{Name: "socket", Placeholder: "PATH", Usage: "Socket for --daemon (default: one per repository in the cache directory)", Kind: completion.KindFile},
+			{Name: "no-repo-config", Usage: "Ignore the repository's .lazynuget.yml (for auditing)"},
+			{Name: "strict-config", Usage: "Fail on unknown keys, invalid values, or keybinding conflicts"},
+			{Name: "no-telemetry", Usage: "Disable anonymous usage statistics (or set LAZYNUGET_NO_TELEMETRY=1)"},
+			{Name: "force-unlock", Usage: "Take over this repository's lock from another (e.g., hung) instance"},
+			{Name: "metrics-addr", Placeholder: "ADDR", Usage: "Serve debug metrics at http://ADDR/metrics (loopback only, e.g. " + metrics.DefaultAddr + ")"},
+		},
+		Examples: []Example{
+			{Command: "lazynuget", Description: "Start interactive TUI"},
+			{Command: "lazynuget --version", Description: "Show version"},
+			{Command: "lazynuget --version --verbose", Description: "Show version and environment for a bug report"},
+			{Command: "lazynuget --config ~/.config/custom.yml", Description: "Use custom config"},
+			{Command: "lazynuget --log-level debug", Description: "Enable debug logging"},
+			{Command: "lazynuget --profile work", Description: "Use the 'work' config profile"},
+			{Command: "lazynuget --strict-config", Description: "Fail on config warnings (CI)"},
+			{Command: `echo '{"jsonrpc":"2.0","id":1,"method":"list"}' | lazynuget --serve`, Description: "List package references from a script"},
+			{Command: "lazynuget --daemon --socket /tmp/lazynuget.sock", Description: "Serve an editor extension"},
+		},
+		Subcommands: []*Command{
+			{
+				Name:    "encrypt-value",
+				Summary: "Encrypt a value for use in config files",
+				Description: "Encrypts a plaintext value for use in configuration files.\n\n" +
+					"The encryption key must be stored in the platform keychain or provided via environment variable LAZYNUGET_ENCRYPTION_KEY_<KEYID>. " +
+					"The encrypted value is printed to stdout; use it as `apiKey: !encrypted <base64-output>` in config.yml or `api_key = \"enc:<base64-output>\"` in config.toml.",
+				Args: []Arg{
+					{Name: "plaintext", Usage: "The value to encrypt (e.g., API key, token, password)", Kind: completion.KindText},
+					{Name: "key-id", Usage: "Key identifier (default: 'default')", Optional: true},
+				},
+				Examples: []Example{
+					{Command: `lazynuget encrypt-value "my-secret-api-key" prod`, Description: "Encrypt with the 'prod' key"},
+				},
+				ExitCodes: []ExitCode{
+					{Code: exitcode.Success, Meaning: "Success"},
+					{Code: exitcode.UserError, Meaning: "Usage error or no encryption key available"},
+				},
+			},
+			{
+				Name:    "import-config",
+				Summary: "Translate another tool's config into a LazyNuGet config",
+				Description: "Translates settings from another tool into a LazyNuGet config. " +
+					"The generated YAML is written to stdout (or --output); settings that cannot be translated are reported on stderr.\n\n" +
+					"lazygit: keybindings and border colors. " +
+					"renovate: ignoreDeps, disabled packageRules, and allowedVersions become pinnedPackages; registryUrls become feeds. " +
+					"dependabot: nuget ignore rules become pinnedPackages; nuget-feed registries become feeds.",
+				Flags: []Flag{
+					{Name: "from", Placeholder: "TOOL", Usage: "Source tool: " + strings.Join(config.ImportSources, "|") + " (required)", Values: config.ImportSources},
+					{Name: "input", Placeholder: "PATH", Usage: "Source config file (default: the tool's usual location)", Kind: completion.KindFile},
+					{Name: "output", Placeholder: "PATH", Usage: "Write the result to PATH instead of stdout", Kind: completion.KindFile},
+					{Name: "force", Usage: "Overwrite --output if it already exists"},
+				},
+				Examples: []Example{
+					{Command: "lazynuget import-config --from dependabot >> ~/.config/lazynuget/config.yml"},
+				},
+			},
+			{
+				Name:    "config",
+				Summary: "Config utilities",
+				Subcommands: []*Command{
+					{
+						Name:    "schema",
+						Summary: "Write the config JSON Schema",
+						Description: "Writes a JSON Schema for the config file, for editor completion and validation. " +
+							"Reference it from the first line of config.yml: `# yaml-language-server: $schema=./config.schema.json`.",
+						Args: []Arg{
+							{Name: "path", Usage: "Output file (default: stdout; - for stdout)", Kind: completion.KindFile, Optional: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget config schema ~/.config/lazynuget/config.schema.json"},
+						},
+					},
+				},
+			},
+			{
+				Name:    "theme",
+				Summary: "List and preview color themes",
+				Description: "Themes are built in (default, dark, light, solarized) or YAML files in the themes directory next to " +
+					"the config file, named <name>.yml and selected with `theme: <name>`. A theme file has an optional description " +
+					"and colors keyed like colorScheme; colors it leaves out are the defaults. Colors set in colorScheme win over " +
+					"the theme's, and with hotReload, edits to the theme file apply at once. Theme auto asks the terminal for its " +
+					"background color and uses the light theme on a light background, otherwise the dark theme.",
+				Subcommands: []*Command{
+					{
+						Name:    "list",
+						Summary: "List the available themes, marking the one in use",
+						Flags: []Flag{
+							{Name: "json", Usage: "Write the themes as a versioned JSON document"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "Success"},
+							{Code: exitcode.UserError, Meaning: "Usage error"},
+							{Code: exitcode.SystemError, Meaning: "The themes directory cannot be read"},
+						},
+					},
+					{
+						Name:    "preview",
+						Summary: "Show a theme's colors with samples of what they draw",
+						Flags: []Flag{
+							{Name: "no-color", Usage: "Show the bold, faint, underline, and reverse video that stand in for colors (or set NO_COLOR)"},
+						},
+						Args: []Arg{
+							{Name: "name", Usage: "Theme (default: the one in use)", Optional: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget theme preview solarized"},
+							{Command: "lazynuget theme preview auto", Description: "The theme auto picks on this terminal"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "Success"},
+							{Code: exitcode.UserError, Meaning: "Usage error, unknown theme, or invalid theme file"},
+						},
+					},
+				},
+			},
+			{
+				Name:    "keys",
+				Summary: "List, set, and edit keybindings",
+				Description: "Keys come from keybindingProfile (default, vim, or emacs), then the keybindings setting, then the keys " +
+					"of custom commands. A binding may be a chord, keys pressed one after the other (\"g g\", \"space u a\"); " +
+					"while a chord is being typed, the {keys} status bar segment shows the keys so far. Keys are written as a " +
+					"character (g, G, ?), a named key (enter, esc, tab, space, up, pgdown, f5), or with modifiers (ctrl+r, alt+x, " +
+					"or lazygit's <c-r>). Bindings in a panel's context win over global ones in that panel.",
+				Subcommands: []*Command{
+					{
+						Name:    "list",
+						Summary: "List the keybindings in effect and their conflicts",
+						Description: "Conflicts are keys bound to several actions in one context, and keys that start a longer chord, " +
+							"which then wait for the next key.",
+						Flags: []Flag{
+							{Name: "context", Placeholder: "CONTEXT", Usage: "Only the keys that work in a panel (global|projects|packages|details)", Values: config.CustomCommandContexts},
+							{Name: "json", Usage: "Write the keybindings and conflicts as a versioned JSON document"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "The keybindings were listed"},
+							{Code: exitcode.UserError, Meaning: "Usage error or the config could not be loaded"},
+						},
+					},
+					{
+						Name:        "set",
+						Summary:     "Bind an action to keys in the config file",
+						Description: "Replaces the action's keys in the keybindings setting of the config file, keeping its comments.",
+						Flags: []Flag{
+							{Name: "context", Placeholder: "CONTEXT", Usage: "Panel the keys work in (default: the action's)", Values: config.CustomCommandContexts},
+							{Name: "unbind", Usage: "Remove the action's keys"},
+							{Name: "force", Usage: "Save keys that conflict with other bindings"},
+						},
+						Args: []Arg{
+							{Name: "action", Usage: "Action (see keys list)", Kind: completion.KindText},
+							{Name: "keys", Usage: "Key sequence, one key per argument or space-separated", Kind: completion.KindText, Optional: true, Variadic: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget keys set refresh ctrl+r"},
+							{Command: "lazynuget keys set update_all space u a", Description: "Bind a chord"},
+							{Command: "lazynuget keys set --unbind sort"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "The keybinding was saved"},
+							{Code: exitcode.UserError, Meaning: "Usage error, unknown action or key, a conflict, or the config file could not be written"},
+						},
+					},
+					{
+						Name:    "edit",
+						Summary: "Rebind an action by pressing its new keys",
+						Description: "Lists the actions to pick one (unless ACTION is given), then records the keys pressed, showing the " +
+							"conflicts of the sequence as it is typed. Enter saves, Esc cancels, and Backspace removes the last key; " +
+							"bind those keys themselves with keys set.",
+						Flags: []Flag{
+							{Name: "context", Placeholder: "CONTEXT", Usage: "Panel the keys work in (default: the action's)", Values: config.CustomCommandContexts},
+						},
+						Args: []Arg{
+							{Name: "action", Usage: "Action (default: pick from a list)", Kind: completion.KindText, Optional: true},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "The keybinding was saved, or editing was canceled"},
+							{Code: exitcode.UserError, Meaning: "Usage error, no terminal, or the keybinding was not saved"},
+							{Code: exitcode.SystemError, Meaning: "The terminal could not be read"},
+						},
+					},
+				},
+			},
+			{
+				Name:    "filters",
+				Summary: "List saved package list filters",
+				Description: "A filter is a list of terms a package reference must all match: words match the package ID, " +
+					"id: matches it with * wildcards, project: the project's file name, is: outdated, current, vulnerable, " +
+					"prerelease, or floating, update: the part of the version an update changes (major, minor, patch, or " +
+					"prerelease), and severity: advisories of that severity or worse. A leading - negates a term, and commas " +
+					"list alternatives (update:major,minor). Saved filters name one in the savedFilters setting, for the " +
+					"command palette and --filter.",
+				Subcommands: []*Command{
+					{
+						Name:    "list",
+						Summary: "List the saved filters, as the command palette does",
+						Flags: []Flag{
+							{Name: "json", Usage: "Write the saved filters as a versioned JSON document"},
+						},
+					},
+				},
+			},
+			{
+				Name:    "notes",
+				Summary: "Keep scratch notes about the workspace's packages",
+				Description: "Notes are a checklist for triaging updates: which packages need a follow-up and why. They are kept " +
+					"per workspace (the repository the working directory is in) with lazynuget's other state, not in the " +
+					"repository, and export to markdown for an issue or a PR description. Notes are numbered; the numbers " +
+					"stay the same when other notes are removed.",
+				Subcommands: []*Command{
+					{
+						Name:    "list",
+						Summary: "List the workspace's notes",
+						Flags: []Flag{
+							{Name: "package", Placeholder: "ID", Usage: "Only the notes about a package"},
+							{Name: "json", Usage: "Write the notes as a versioned JSON document"},
+						},
+					},
+					{
+						Name:    "add",
+						Summary: "Add a note",
+						Flags: []Flag{
+							{Name: "package", Placeholder: "ID", Usage: "Package the note is about"},
+						},
+						Args: []Arg{
+							{Name: "text", Usage: "The note", Kind: completion.KindText, Variadic: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget notes add --package Serilog check the sinks before moving to 4.x"},
+							{Command: "lazynuget notes add \"ask about pinning Newtonsoft.Json\""},
+						},
+					},
+					{
+						Name:    "done",
+						Summary: "Check off notes",
+						Flags: []Flag{
+							{Name: "undo", Usage: "Uncheck the notes instead"},
+						},
+						Args: []Arg{
+							{Name: "number", Usage: "Note number (see notes list)", Kind: completion.KindText, Variadic: true},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "The notes were checked off"},
+							{Code: exitcode.UserError, Meaning: "Usage error or no note with a number"},
+							{Code: exitcode.SystemError, Meaning: "The notes could not be read or saved"},
+						},
+					},
+					{
+						Name:    "remove",
+						Summary: "Delete notes",
+						Args: []Arg{
+							{Name: "number", Usage: "Note number (see notes list)", Kind: completion.KindText, Variadic: true},
+						},
+					},
+					{
+						Name:    "clear",
+						Summary: "Delete the notes that are checked off",
+						Flags: []Flag{
+							{Name: "all", Usage: "Delete every note"},
+						},
+					},
+					{
+						Name:        "export",
+						Summary:     "Write the notes as a markdown checklist",
+						Description: "General notes come first, then a section for each package.",
+						Flags: []Flag{
+							{Name: "title", Placeholder: "TEXT", Usage: "Heading of the checklist (default: Notes for <workspace>)"},
+						},
+						Args: []Arg{
+							{Name: "path", Usage: "Output file (default: stdout; - for stdout)", Kind: completion.KindFile, Optional: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget notes export | gh issue create --title \"Dependency follow-ups\" --body-file -"},
+						},
+					},
+				},
+			},
+			{
+				Name:    "add",
+				Summary: "Add package references, from arguments or stdin",
+				Description: "References each package in the project, at the version given or the latest on the feed. " +
+					"A package the project already references is moved to that version.\n\n" +
+					bulkDescription,
+				Flags: bulkFlags(
+					Flag{Name: "prerelease", Usage: "Consider prerelease versions for the latest version"},
+					Flag{Name: "source", Placeholder: "URL", Usage: "Package base address (V3 flat container) or service index URL of the feed", Default: nuget.DefaultFeedURL},
+					Flag{Name: "reason", Placeholder: "TEXT", Usage: "Comment written above each new reference"},
+				),
+				Args: bulkArgs,
+				Examples: []Example{
+					{Command: "lazynuget add Serilog Polly@8.4.0"},
+					{Command: "cat packages.txt | lazynuget add --yes --project src/App/App.csproj", Description: "One ID or ID@VERSION per line"},
+				},
+				ExitCodes: bulkExitCodes,
+			},
+			{
+				Name:    "audit",
+				Summary: "Report vulnerable packages and suggest upgrades that fix them",
+				Description: "Runs dotnet list package --vulnerable, including transitive packages, so projects must be restored. " +
+					"For each vulnerable package the suggested fix is the lowest newer version that no advisory affects and that " +
+					"every package depending on it allows (from obj/project.assets.json). A direct reference is updated; " +
+					"a transitive package is pinned with a top-level reference that overrides the vulnerable version, " +
+					"preceded by a comment naming the advisories.\n\n" +
+					"The audit also warns about packages that may be typosquats: IDs one character away from a popular " +
+					"package's or spelled the same with look-alike characters (Newtonsoft.Jsno, Newtons0ft.Json), and direct " +
+					"references with a vendor's reserved prefix (Microsoft., System., Azure., AWSSDK., Google.) that nuget.org " +
+					"does not show as verified. Packages whose prefix is verified are their owner's and are not reported; " +
+					"the warnings name the owners of the others. They do not fail the audit.\n\n" +
+					"With --fix every fix that breaks no other package's range is written to the project files " +
+					"(or Directory.Packages.props under central package management), then restore and the audit run again to confirm.\n\n" +
+					"With --policy the audit is gated on a YAML policy file instead of on any vulnerability: " +
+					"maxSeverity (none, low, moderate, high, or critical), bannedPackages (package IDs, a trailing * matching a prefix, " +
+					"each with an optional reason), licenses (the SPDX license IDs allowed), maxAgeDays (how long ago a package " +
+					"version may have been published), and maxInactiveYears (how long a package may go without a new release). Every package a project resolves is checked, including transitive ones. " +
+					"--sarif writes the violations as SARIF 2.1.0 for GitHub code scanning.\n\n" +
+					"--report-format writes every package checked as a CI report: SARIF, with a result per vulnerability " +
+					"and violation, or JUnit XML, with a test suite per project and a test case per package that fails with its findings. " +
+					"In a GitHub Actions job (GITHUB_ACTIONS=true), vulnerabilities and violations are also written as annotations " +
+					"on the line of the project file that references the package.",
+				Flags: []Flag{
+					{Name: "fix", Usage: "Apply the suggested fixes, restore, and audit again"},
+					{Name: "source", Placeholder: "URL", Usage: "Package base address (V3 flat container) or service index URL of the feed whose versions and advisories suggest fixes", Default: nuget.DefaultFeedURL},
+					{Name: "json", Usage: "Write the vulnerabilities, fixes, and policy violations as a versioned JSON document"},
+					{Name: "policy", Placeholder: "FILE", Usage: "Check packages against a policy file and fail only on its violations", Kind: completion.KindFile},
+					{Name: "sarif", Placeholder: "FILE", Usage: "Write policy violations as SARIF for code scanning (requires --policy)", Kind: completion.KindFile},
+					{Name: "report-format", Placeholder: "FORMAT", Usage: "Write every package checked as a CI report (sarif|junit)", Values: ci.Formats},
+					{Name: "report-out", Placeholder: "FILE", Usage: "File of the CI report (default: stdout, instead of the text output)", Kind: completion.KindFile},
+				},
+				Args: []Arg{
+					{Name: "project", Usage: "Project or solution to audit (default: the current directory)", Kind: completion.KindProject, Optional: true},
+				},
+				Examples: []Example{
+					{Command: "lazynuget audit", Description: "List vulnerable packages and their fixes"},
+					{Command: "lazynuget audit --fix MySolution.sln", Description: "Fix all vulnerabilities in a solution"},
+					{Command: "lazynuget audit --source https://pkgs.example.com/v3/index.json", Description: "Suggest fixes from a private feed's versions and advisories"},
+					{Command: "lazynuget audit --policy policy.yml --sarif results.sarif", Description: "Gate CI on a policy and report violations to code scanning"},
+				},
+				ExitCodes: []ExitCode{
+					{Code: exitcode.Success, Meaning: "No vulnerable packages, or all were fixed; with --policy, no violations"},
+					{Code: exitcode.UserError, Meaning: "Usage error"},
+					{Code: exitcode.SystemError, Meaning: "dotnet could not run, the feed could not be reached, a project file could not be edited, or the policy file is invalid"},
+					{Code: exitcode.PolicyViolation, Meaning: "Vulnerable packages remain; with --policy, the policy is violated"},
+				},
+			},
+			{
+				Name:    "batch",
+				Summary: "Run a command in many repositories and summarize the outcome",
+				Description: "Runs the same lazynuget command in each repository of a list and prints a summary per repository, " +
+					"for platform teams that maintain many services. The list has one local path or git URL per line; " +
+					"blank lines and lines starting with # are ignored, and relative paths are relative to the list. " +
+					"Git URLs are cloned (shallow) into the work directory, or pulled when they were cloned there before.\n\n" +
+					"The command is checked before any repository is touched. Its output is printed per repository, " +
+					"followed by the summary; with --json it is part of each repository's result.",
+				Flags: []Flag{
+					{Name: "repos", Placeholder: "FILE", Usage: "Repository list (- for stdin)", Kind: completion.KindFile},
+					{Name: "cmd", Placeholder: "COMMAND", Usage: "lazynuget command to run in each repository, quoted as one argument"},
+					{Name: "workdir", Placeholder: "DIR", Usage: "Where git URLs are cloned (default: the cache directory)", Kind: completion.KindFile},
+					{Name: "jobs", Placeholder: "N", Usage: "Repositories processed at once", Default: "4"},
+					{Name: "json", Usage: "Write the results as a versioned JSON document"},
+				},
+				Examples: []Example{
+					{Command: "lazynuget batch --repos repos.txt --cmd \"outdated\"", Description: "Check every service for updates"},
+					{Command: "lazynuget batch --repos repos.txt --cmd \"audit --fix\" --jobs 8", Description: "Fix vulnerabilities everywhere"},
+				},
+				ExitCodes: []ExitCode{
+					{Code: exitcode.Success, Meaning: "The command succeeded in every repository"},
+					{Code: exitcode.UserError, Meaning: "Usage error, or the command failed in every repository"},
+					{Code: exitcode.SystemError, Meaning: "The list could not be read, or no repository could be cloned or run"},
+					{Code: exitcode.PartialFailure, Meaning: "The command succeeded in some repositories but not all"},
+				},
+			},
+			{
+				Name:    "diff",
+				Summary: "Compare package versions between git revisions or snapshots",
+				Description: "Lists the packages added, removed, and changed in every project, with each version change " +
+					"classified by semver severity (major, minor, patch, or prerelease). Each side is a snapshot file " +
+					"(see `lazynuget snapshot create`) or a git revision; without TO, FROM is compared with the working tree.",
+				Flags: []Flag{
+					{Name: "json", Usage: "Write the diff as a versioned JSON document"},
+					{Name: "no-color", Usage: "Mark severities with bold and underline instead of colors (or set NO_COLOR)"},
+				},
+				Args: []Arg{
+					{Name: "from", Usage: "Git revision or snapshot file to compare from", Kind: completion.KindFile},
+					{Name: "to", Usage: "Git revision or snapshot file to compare to (default: the working tree)", Kind: completion.KindFile, Optional: true},
+				},
+				Examples: []Example{
+					{Command: "lazynuget diff HEAD~5", Description: "What changed in the last five commits and the working tree"},
+					{Command: "lazynuget diff main feature/upgrade"},
+					{Command: "lazynuget diff --json before-update.json"},
+				},
+				ExitCodes: []ExitCode{
+					{Code: exitcode.Success, Meaning: "The diff was written"},
+					{Code: exitcode.UserError, Meaning: "Usage error, unknown revision, or unreadable snapshot"},
+					{Code: exitcode.SystemError, Meaning: "The output could not be written"},
+				},
+			},
+			{
+				Name:    "doctor",
+				Summary: "Check lazynuget's files for problems",
+				Description: "Checks that the config, log, data, and cache directories and the files in them are private to you, " +
+					"since configs can hold feed credentials and logs can echo them. On Unix that means directories 0700 and files 0600 at most. " +
+					"On Windows each access control list is read instead: accounts other than you, SYSTEM, and Administrators that can read " +
+					"or write an entry are reported, as are entries you cannot write to. " +
+					"The cache is checked one level deep, as it holds cloned repositories. Symbolic links are not followed.\n\n" +
+					"With --fix, group and other permissions are removed on Unix, leaving owner permissions alone; on Windows the access " +
+					"control list is replaced with one granting full control to you, SYSTEM, and Administrators only.",
+				Flags: []Flag{
+					{Name: "fix", Usage: "Restrict what is reported to you"},
+					{Name: "json", Usage: "Write the directories checked and the findings as a versioned JSON document"},
+				},
+				Examples: []Example{
+					{Command: "lazynuget doctor", Description: "Report files other users can access or you cannot write"},
+					{Command: "lazynuget doctor --fix", Description: "Restrict them"},
+				},
+				ExitCodes: []ExitCode{
+					{Code: exitcode.Success, Meaning: "No problems, or all were fixed"},
+					{Code: exitcode.UserError, Meaning: "Usage error"},
+					{Code: exitcode.SystemError, Meaning: "A file could not be checked or fixed"},
+					{Code: exitcode.PolicyViolation, Meaning: "Files or directories are not private to you"},
+				},
+			},
+			{
+				Name:    "edit",
+				Summary: "Open a project file, Directory.Packages.props, or nuget.config in your editor",
+				Description: "Opens the file in the editor setting's command, or $VISUAL, or $EDITOR, and waits for the editor to exit. " +
+					"The edited file is then parsed again and any problem is reported. Graphical editors must be told to wait " +
+					"(e.g., `code --wait`).",
+				Flags: []Flag{
+					{Name: "props", Usage: "Edit the Directory.Packages.props that applies to the project"},
+					{Name: "nuget-config", Usage: "Edit the nuget.config that applies to the project"},
+				},
+				Args: []Arg{
+					{Name: "project", Usage: "Project file (default: the only project in the repository)", Kind: completion.KindProject, Optional: true},
+				},
+				Examples: []Example{
+					{Command: "lazynuget edit src/App/App.csproj"},
+					{Command: "lazynuget edit --props", Description: "Edit central package versions"},
+				},
+				ExitCodes: []ExitCode{
+					{Code: exitcode.Success, Meaning: "The editor exited and the file is valid"},
+					{Code: exitcode.UserError, Meaning: "Usage error, the file does not exist, or it no longer parses"},
+					{Code: exitcode.SystemError, Meaning: "The editor could not be run or failed"},
+				},
+			},
+			{
+				Name:    "feeds",
+				Summary: "Set up private package sources",
+				Description: "Adds a feed of a hosted registry to the user nuget.config " +
+					"(~/.nuget/NuGet/NuGet.Config, or %APPDATA%\\NuGet\\NuGet.Config on Windows). Each registry knows its URL shape " +
+					"and authentication, so a feed takes only its owner and a token. Values not given as arguments are asked for " +
+					"when stdin is a terminal, and the token is read from an environment variable or asked for without echo. " +
+					"The token is tested against the feed before the source is written.\n\n" +
+					"With --store-password-in-clear-text the token is written to nuget.config for dotnet, the only form NuGet reads " +
+					"outside Windows. The token is also printed encrypted with the key in the platform keychain, as a feed entry " +
+					"for LazyNuGet's config.",
+				Subcommands: []*Command{
+					{
+						Name:    "azure",
+						Summary: "Add an Azure Artifacts feed",
+						Description: "Builds the service index URL of an Azure Artifacts feed from its organization, project, and feed " +
+							"names and tests a personal access token (PAT) with the Packaging (Read) scope. " +
+							"Without --store-password-in-clear-text, dotnet authenticates with the Azure Artifacts Credential Provider.",
+						Flags: feedFlags("AZURE_DEVOPS_EXT_PAT",
+							Flag{Name: "project", Placeholder: "NAME", Usage: "Project of a project-scoped feed"}),
+						Args: []Arg{
+							{Name: "organization", Usage: "Azure DevOps organization", Optional: true},
+							{Name: "feed", Usage: "Feed name", Optional: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget feeds azure contoso internal", Description: "Add an organization-scoped feed"},
+							{Command: "lazynuget feeds azure --project Web contoso packages", Description: "Add a project-scoped feed"},
+						},
+						ExitCodes: feedExitCodes,
+					},
+					{
+						Name:    "github",
+						Summary: "Add a GitHub Packages feed",
+						Description: "Adds https://nuget.pkg.github.com/OWNER/index.json, the packages of a user or organization, " +
+							"and tests a personal access token (classic) with the read:packages scope. " +
+							"GitHub expects the user name of the token's owner, which defaults to OWNER.",
+						Flags: feedFlags("GITHUB_TOKEN",
+							Flag{Name: "username", Placeholder: "USER", Usage: "GitHub user that owns the token (default: OWNER)"}),
+						Args: []Arg{
+							{Name: "owner", Usage: "User or organization that owns the packages", Optional: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget feeds github contoso", Description: "Add an organization's packages"},
+							{Command: "lazynuget feeds github --username octocat --store-password-in-clear-text contoso", Description: "Use a member's token for dotnet"},
+						},
+						ExitCodes: feedExitCodes,
+					},
+					{
+						Name:    "gitlab",
+						Summary: "Add a GitLab package registry feed",
+						Description: "Adds the NuGet registry of a GitLab project, or with --group of a group and all its projects, " +
+							"on gitlab.com or a self-managed instance, and tests a personal, group, or deploy token with the " +
+							"read_package_registry (or read_api) scope. The user name is the token's owner, or the deploy token's user name.",
+						Flags: feedFlags("GITLAB_TOKEN",
+							Flag{Name: "host", Placeholder: "HOST", Usage: "GitLab instance (default: gitlab.com)"},
+							Flag{Name: "group", Usage: "PROJECT is a group, whose registry has the packages of all its projects"},
+							Flag{Name: "username", Placeholder: "USER", Usage: "User that owns the token, or deploy token user name"}),
+						Args: []Arg{
+							{Name: "project", Usage: "Project (or group) ID or path, e.g., 42 or mygroup/myproject", Optional: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget feeds gitlab --username jdoe 42", Description: "Add a project's registry on gitlab.com"},
+							{Command: "lazynuget feeds gitlab --host gitlab.example.com --group --username jdoe platform", Description: "Add a group's registry"},
+						},
+						ExitCodes: feedExitCodes,
+					},
+				},
+			},
+			{
+				Name:    "frameworks",
+				Summary: "Show target framework support status and retarget projects",
+				Subcommands: []*Command{
+					{
+						Name:    "list",
+						Summary: "List each project's target frameworks with their support status",
+						Description: "Lists the target frameworks of each project with its support status " +
+							"(e.g., net6.0 is out of support) and the suggested upgrade.",
+						Args: []Arg{
+							{Name: "project", Usage: "Project files (default: all projects in the repository)", Kind: completion.KindProject, Optional: true, Variadic: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget frameworks list"},
+						},
+					},
+					{
+						Name:    "retarget",
+						Summary: "Retarget a project to a newer framework",
+						Description: "Replaces a target framework in a project file and reports the package references " +
+							"that have no assets compatible with the new framework. " +
+							"Compatibility is checked against restored packages in the global packages folder.",
+						Flags: []Flag{
+							{Name: "from", Placeholder: "TFM", Usage: "Framework to replace (required for multi-targeting projects)"},
+							{Name: "dry-run", Usage: "Only report incompatible package references"},
+							{Name: "force", Usage: "Retarget even if some package references are incompatible"},
+						},
+						Args: []Arg{
+							{Name: "project", Usage: "Project file", Kind: completion.KindProject},
+							{Name: "framework", Usage: "New target framework (e.g., " + nuget.LatestLTS + ")"},
+						},
+						Examples: []Example{
+							{Command: "lazynuget frameworks retarget --dry-run src/App/App.csproj " + nuget.LatestLTS, Description: "Check compatibility"},
+							{Command: "lazynuget frameworks retarget --from net6.0 src/Lib/Lib.csproj net8.0", Description: "Retarget one framework of a multi-targeting project"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "Retargeted (or, with --dry-run, all references are compatible)"},
+							{Code: exitcode.UserError, Meaning: "Usage error, or incompatible references without --force"},
+							{Code: exitcode.SystemError, Meaning: "The project file could not be read or written"},
+						},
+					},
+				},
+			},
+			{
+				Name:    "imports",
+				Summary: "Show the MSBuild files a project imports, and where a package's version comes from",
+				Description: "Lists the files MSBuild evaluates for a project, in evaluation order: the SDK's Sdk.props, " +
+					"Directory.Build.props, the props restore writes to obj (App.csproj.nuget.g.props), Directory.Packages.props, " +
+					"the project itself, then the SDK's Sdk.targets, Directory.Build.targets, and restore's targets, each followed " +
+					"by the files its <Import> elements bring in. Each file is shown with the package items and version properties it defines. " +
+					"The SDK's own files are listed but not followed; import paths are resolved from the properties the chain defines, " +
+					"without evaluating conditions, and imports that cannot be resolved or found are marked.\n\n" +
+					"With --package, only the definitions that decide the package's version are shown: its PackageReference, " +
+					"PackageVersion, and GlobalPackageReference items (including Update items), and the properties their versions use. " +
+					"Later definitions override earlier ones; the version restore resolved is shown last.",
+				Flags: []Flag{
+					{Name: "package", Placeholder: "ID", Usage: "Trace where this package's version is defined", Kind: completion.KindPackage},
+					{Name: "json", Usage: "Write the import chain as a versioned JSON document"},
+				},
+				Args: []Arg{
+					{Name: "project", Usage: "Project file (default: the only project in the repository)", Kind: completion.KindProject, Optional: true},
+				},
+				Examples: []Example{
+					{Command: "lazynuget imports src/App/App.csproj"},
+					{Command: "lazynuget imports --package Serilog src/App/App.csproj", Description: "Where does its version come from?"},
+				},
+				ExitCodes: []ExitCode{
+					{Code: exitcode.Success, Meaning: "Success"},
+					{Code: exitcode.UserError, Meaning: "Usage error, or no such project"},
+					{Code: exitcode.SystemError, Meaning: "The project could not be read"},
+				},
+			},
+			{
+				Name:    "outdated",
+				Summary: "List package references with newer versions",
+				Description: "Compares each package reference with the versions on the feed. References are judged by the version " +
+					"restore resolved (from obj/project.assets.json), so ranges such as [1.0,2.0) and floating versions such as 6.0.* " +
+					"show what they resolve to.\n\n" +
+					"A floating reference whose range already includes the latest version is not outdated: " +
+					"it is marked \"restore\" because the next restore picks the new version up without editing the project.\n\n" +
+					"Versions are listed maxConcurrentOps packages at a time. On a terminal, the tables are shown while they load, " +
+					"with placeholders for the versions and notes still to come, and printed once every package is checked.\n\n" +
+					"--report-format writes the references as a CI report: SARIF, with a warning per outdated reference, " +
+					"or JUnit XML, with a test case per reference that fails when it is outdated and is skipped when it could not be checked. " +
+					"In a GitHub Actions job (GITHUB_ACTIONS=true), outdated references are also written as warning annotations " +
+					"on the line of the project file that references the package.",
+				Flags: []Flag{
+					{Name: "prerelease", Usage: "Consider prerelease versions"},
+					{Name: "offline", Usage: "Compare with the versions in the global packages folder instead of the feed"},
+					{Name: "source", Placeholder: "URL", Usage: "Package base address (V3 flat container) or service index URL of the feed", Default: nuget.DefaultFeedURL},
+					{Name: "report-format", Placeholder: "FORMAT", Usage: "Write the references checked as a CI report (sarif|junit)", Values: ci.Formats},
+					{Name: "report-out", Placeholder: "FILE", Usage: "File of the CI report (default: stdout, instead of the tables)", Kind: completion.KindFile},
+					{Name: "filter", Placeholder: "FILTER", Usage: "Only the references a filter or saved filter matches (see filters list)"},
+				},
+				Args: []Arg{
+					{Name: "project", Usage: "Project files (default: all projects in the repository)", Kind: completion.KindProject, Optional: true, Variadic: true},
+				},
+				Examples: []Example{
+					{Command: "lazynuget outdated"},
+					{Command: "lazynuget outdated --filter \"is:vulnerable update:major\"", Description: "Vulnerable packages that need a major update"},
+					{Command: "lazynuget outdated --prerelease src/App/App.csproj", Description: "Include prereleases"},
+					{Command: "lazynuget outdated --report-format junit --report-out outdated.xml", Description: "Show outdated references in the CI test tab"},
+				},
+				ExitCodes: []ExitCode{
+					{Code: exitcode.Success, Meaning: "Every reference was checked"},
+					{Code: exitcode.UserError, Meaning: "Usage error"},
+					{Code: exitcode.SystemError, Meaning: "A project could not be read or the feed could not be reached"},
+				},
+			},
+			{
+				Name:    "packages",
+				Summary: "Show package references",
+				Subcommands: []*Command{
+					{
+						Name:    "list",
+						Summary: "List each project's package references by category",
+						Description: "Lists the package references of each project in two sections: dependencies the project " +
+							"compiles or runs against, and analyzers, source generators, and build tools. " +
+							"A reference is build-only when PrivateAssets is \"all\", when IncludeAssets/ExcludeAssets leave no compile or runtime assets, " +
+							"or when the restored package contains only analyzers or is marked as a development dependency.\n\n" +
+							"Framework references (e.g., Microsoft.AspNetCore.App) and shared framework or runtime pack packages " +
+							"are listed separately as platform dependencies: the .NET SDK provides them.\n\n" +
+							"A warning follows a project whose references no longer match what its last restore recorded in " +
+							"obj/project.assets.json (a package added, removed, or changed in version, or a target framework added " +
+							"or removed), including references made in Directory.Build.props and versions in Directory.Packages.props.",
+						Args: []Arg{
+							{Name: "project", Usage: "Project files (default: all projects in the repository)", Kind: completion.KindProject, Optional: true, Variadic: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget packages list"},
+						},
+					},
+					{
+						Name:    "icon",
+						Summary: "Show a package's icon in the terminal",
+						Description: "Downloads the icon embedded in a package version and draws it with the terminal's inline image " +
+							"protocol: kitty graphics, iTerm2 inline images, or sixel. Other terminals show a colored block with the " +
+							"package's initial. The protocol is detected from the environment unless set with --protocol or the " +
+							"packageIcons setting. Converted icons are cached in the cache directory, within the cacheSize limit.",
+						Flags: []Flag{
+							{Name: "protocol", Placeholder: "NAME", Usage: "Image protocol (default: the packageIcons setting)", Values: []string{"auto", "kitty", "iterm2", "sixel", "off"}},
+							{Name: "rows", Placeholder: "N", Usage: "Icon height in terminal rows", Default: "2"},
+							{Name: "source", Placeholder: "URL", Usage: "Package base address (V3 flat container) or service index URL of the feed", Default: nuget.DefaultFeedURL},
+						},
+						Args: []Arg{
+							{Name: "package", Usage: "Package ID", Kind: completion.KindPackage},
+							{Name: "version", Usage: "Package version (default: the latest release)", Optional: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget packages icon Newtonsoft.Json"},
+							{Command: "lazynuget packages icon --protocol sixel Serilog 3.1.0"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "The icon (or the initial, when the package has none) was shown"},
+							{Code: exitcode.UserError, Meaning: "Usage error, or the package was not found"},
+							{Code: exitcode.SystemError, Meaning: "The feed could not be reached"},
+						},
+					},
+					{
+						Name:    "quickstart",
+						Summary: "Show or copy the setup code from a package's README",
+						Description: "Finds the first code block in the package's README that sets the package up: .NET code " +
+							"with using directives or dependency injection registration (services.AddX), skipping install " +
+							"commands. The README is read from the global packages folder when the version is restored, and " +
+							"from the feed otherwise.\n\n" +
+							"With --copy, the code goes to the clipboard (pbcopy, clip, wl-copy, xclip, or xsel); without a " +
+							"clipboard tool, it is sent to the terminal with OSC 52, which works over SSH in most terminals.",
+						Flags: []Flag{
+							{Name: "copy", Usage: "Copy the code to the clipboard instead of printing it"},
+							{Name: "source", Placeholder: "URL", Usage: "Package base address (V3 flat container) or service index URL of the feed", Default: nuget.DefaultFeedURL},
+						},
+						Args: []Arg{
+							{Name: "package", Usage: "Package ID", Kind: completion.KindPackage},
+							{Name: "version", Usage: "Package version (default: the latest release)", Optional: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget packages quickstart Serilog.AspNetCore"},
+							{Command: "lazynuget packages quickstart --copy Polly.Core 8.4.0", Description: "Copy after installing"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "The code was printed or copied"},
+							{Code: exitcode.UserError, Meaning: "Usage error, or the package, its README, or setup code was not found"},
+							{Code: exitcode.SystemError, Meaning: "The feed could not be reached or the clipboard tool failed"},
+						},
+					},
+					{
+						Name:    "compare",
+						Summary: "Compare two versions of a package side by side",
+						Description: "Shows two versions of a package side by side to inform an upgrade: target frameworks, " +
+							"dependencies per framework, package size, and the security advisories that affect each version, " +
+							"marking what was added, removed, changed, fixed, or introduced.\n\n" +
+							"Versions restored in the global packages folder are read from disk; others are read from the feed. " +
+							"Target frameworks are those with lib/ or ref/ assets for restored versions, and those with dependency " +
+							"groups otherwise. Advisories come from the nuget.org vulnerability index.",
+						Flags: []Flag{
+							{Name: "json", Usage: "Write the comparison as a versioned JSON document"},
+							{Name: "no-color", Usage: "Mark changes with bold and underline instead of colors (or set NO_COLOR)"},
+							{Name: "source", Placeholder: "URL", Usage: "Package base address (V3 flat container) or service index URL of the feed", Default: nuget.DefaultFeedURL},
+						},
+						Args: []Arg{
+							{Name: "package", Usage: "Package ID", Kind: completion.KindPackage},
+							{Name: "from", Usage: "Version to compare from (e.g., the one in use)"},
+							{Name: "to", Usage: "Version to compare to (default: the latest release)", Optional: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget packages compare Serilog 2.12.0", Description: "What upgrading to the latest release changes"},
+							{Command: "lazynuget packages compare --json Newtonsoft.Json 12.0.3 13.0.3"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "The comparison was shown"},
+							{Code: exitcode.UserError, Meaning: "Usage error, or a version was not found"},
+							{Code: exitcode.SystemError, Meaning: "The feed could not be reached"},
+						},
+					},
+					{
+						Name:    "health",
+						Summary: "Show how actively packages are maintained",
+						Description: "Shows each package's last release, when it was published, how often releases come (the median " +
+							"gap between the latest ten), and how many listed releases the feed has. Packages without a release in " +
+							"more than staleAfterYears years (default 2) are marked stale; set maxInactiveYears in an audit policy to " +
+							"fail CI on them.\n\n" +
+							"Without package IDs, the direct package references of every project in the repository are shown. " +
+							"Release dates are read from the feed's registration service; feeds without one show no releases.",
+						Flags: []Flag{
+							{Name: "json", Usage: "Write the packages' health as a versioned JSON document"},
+							{Name: "stale-after", Placeholder: "YEARS", Usage: "Years without a release before a package is stale (default: the staleAfterYears setting)"},
+							{Name: "source", Placeholder: "URL", Usage: "Package base address (V3 flat container) or service index URL of the feed", Default: nuget.DefaultFeedURL},
+						},
+						Args: []Arg{
+							{Name: "package", Usage: "Package IDs (default: the repository's package references)", Kind: completion.KindPackage, Optional: true, Variadic: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget packages health"},
+							{Command: "lazynuget packages health --stale-after 3 Newtonsoft.Json Serilog"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "The packages' health was shown, whether or not any is stale"},
+							{Code: exitcode.UserError, Meaning: "Usage error"},
+							{Code: exitcode.SystemError, Meaning: "A project could not be read or the feed could not be reached"},
+						},
+					},
+					{
+						Name:    "usage",
+						Summary: "Find source files that likely use a package, before removing it",
+						Description: "Searches the source files of each project referencing the package for imports of its namespaces " +
+							"(using, open, Imports, and @using) and names qualified by them. The namespaces are the names of the " +
+							"package's assemblies in the global packages folder, the package ID, and known exceptions such as " +
+							"extension-method packages. The search is a heuristic: it finds likely, not certain, usages.",
+						Args: []Arg{
+							{Name: "package", Usage: "Package ID", Kind: completion.KindPackage},
+							{Name: "project", Usage: "Project files (default: the projects in the repository that reference the package)", Kind: completion.KindProject, Optional: true, Variadic: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget packages usage Newtonsoft.Json"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "The search completed, whether or not usages were found"},
+							{Code: exitcode.UserError, Meaning: "Usage error, or no project references the package"},
+							{Code: exitcode.SystemError, Meaning: "A project or source file could not be read"},
+						},
+					},
+				},
+			},
+			{
+				Name:    "remove",
+				Summary: "Remove package references, from arguments or stdin",
+				Description: "Removes the project's reference to each package. Packages the project does not reference are left alone.\n\n" +
+					bulkDescription,
+				Flags: bulkFlags(),
+				Args:  bulkArgs,
+				Examples: []Example{
+					{Command: "lazynuget remove Newtonsoft.Json"},
+					{Command: "lazynuget packages list --json | jq -r '.data[] | select(.id | startswith(\"Legacy.\")) | .id' | lazynuget remove --yes"},
+				},
+				ExitCodes: bulkExitCodes,
+			},
+			{
+				Name:    "resolve",
+				Summary: "Explain package downgrades and version conflicts and propose fixes",
+				Description: "Runs dotnet restore and explains each package downgrade (NU1605) and version conflict (NU1107): " +
+					"the dependency paths that request different versions and the edges that conflict. " +
+					"The proposed fix is a top-level reference at the highest requested version, which overrides the transitive requests; " +
+					"a new reference is written with a comment saying why it was added.\n\n" +
+					"With --apply the fixes are written to the project files (or Directory.Packages.props under central package management) " +
+					"and restore runs again to confirm.",
+				Flags: []Flag{
+					{Name: "apply", Usage: "Apply the proposed fixes and restore again"},
+				},
+				Args: []Arg{
+					{Name: "project", Usage: "Project or solution to restore (default: the current directory)", Kind: completion.KindProject, Optional: true},
+				},
+				Examples: []Example{
+					{Command: "lazynuget resolve", Description: "Explain conflicts in the current project"},
+					{Command: "lazynuget resolve --apply src/App/App.csproj", Description: "Fix them"},
+				},
+				ExitCodes: []ExitCode{
+					{Code: exitcode.Success, Meaning: "No conflicts, or all were fixed"},
+					{Code: exitcode.UserError, Meaning: "Usage error, or conflicts remain"},
+					{Code: exitcode.SystemError, Meaning: "dotnet restore could not run, or a project file could not be edited"},
+				},
+			},
+			{
+				Name:     "plugin",
+				Summary:  "List and run plugin commands and panels",
+				Requires: policy.CapabilityCustomCommands,
+				Description: "Plugins are external programs listed in the plugins section of the config file that add commands, " +
+					"panels, and package annotations. Each speaks JSON-RPC over its stdin and stdout and runs in the sandbox " +
+					"when sandbox.enabled is set. Machine policy can restrict them along with other custom commands.",
+				Subcommands: []*Command{
+					{
+						Name:    "list",
+						Summary: "Start each configured plugin and list what it contributes",
+						Examples: []Example{
+							{Command: "lazynuget plugin list"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "Every plugin started"},
+							{Code: exitcode.UserError, Meaning: "The config could not be loaded"},
+							{Code: exitcode.SystemError, Meaning: "No plugin could be started"},
+							{Code: exitcode.PolicyViolation, Meaning: "Plugins are restricted by machine policy"},
+							{Code: exitcode.PartialFailure, Meaning: "Some plugins could not be started"},
+						},
+					},
+					{
+						Name:     "run",
+						Summary:  "Run a plugin command and print its output",
+						Requires: policy.CapabilityCustomCommands,
+						Flags: []Flag{
+							{Name: "project", Placeholder: "PATH", Usage: "Project passed to the plugin as the selection", Kind: completion.KindProject},
+							{Name: "package", Placeholder: "ID", Usage: "Package passed to the plugin as the selection", Kind: completion.KindPackage},
+							{Name: "version", Placeholder: "VERSION", Usage: "Package version passed to the plugin as the selection"},
+						},
+						Args: []Arg{
+							{Name: "plugin", Usage: "Plugin name", Kind: completion.KindText},
+							{Name: "command", Usage: "Command ID (see plugin list)", Kind: completion.KindText},
+							{Name: "args", Usage: "Arguments passed to the command", Kind: completion.KindText, Optional: true, Variadic: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget plugin run --package Serilog registry owners", Description: "Run the registry plugin's owners command"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "The command succeeded"},
+							{Code: exitcode.UserError, Meaning: "Usage error or an unknown command"},
+							{Code: exitcode.SystemError, Meaning: "The plugin could not be started or the command failed"},
+							{Code: exitcode.PolicyViolation, Meaning: "Plugins are restricted by machine policy"},
+						},
+					},
+					{
+						Name:     "panel",
+						Summary:  "Print a plugin panel",
+						Requires: policy.CapabilityCustomCommands,
+						Flags: []Flag{
+							{Name: "project", Placeholder: "PATH", Usage: "Project passed to the plugin as the selection", Kind: completion.KindProject},
+							{Name: "package", Placeholder: "ID", Usage: "Package passed to the plugin as the selection", Kind: completion.KindPackage},
+							{Name: "version", Placeholder: "VERSION", Usage: "Package version passed to the plugin as the selection"},
+							{Name: "width", Placeholder: "N", Usage: "Panel width in cells (default: the terminal width)"},
+							{Name: "height", Placeholder: "N", Usage: "Panel height in cells (default: the terminal height)"},
+							{Name: "live", Usage: "Draw the panel full screen, reflowing it on resize, until interrupted"},
+						},
+						Args: []Arg{
+							{Name: "plugin", Usage: "Plugin name", Kind: completion.KindText},
+							{Name: "panel", Usage: "Panel ID (see plugin list)", Kind: completion.KindText},
+						},
+						Examples: []Example{
+							{Command: "lazynuget plugin panel --package Serilog --version 3.1.1 registry details"},
+							{Command: "lazynuget plugin panel --live registry details"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "The panel was printed"},
+							{Code: exitcode.UserError, Meaning: "Usage error or an unknown panel"},
+							{Code: exitcode.SystemError, Meaning: "The plugin could not be started or the panel failed"},
+							{Code: exitcode.PolicyViolation, Meaning: "Plugins are restricted by machine policy"},
+						},
+					},
+				},
+			},
+			{
+				Name:     "custom",
+				Summary:  "List and run custom commands",
+				Requires: policy.CapabilityCustomCommands,
+				Description: "Custom commands are shell commands from the customCommands section of the config file, listed in the " +
+					"command palette and optionally bound to a key. {{package}}, {{project}}, and {{version}} in a command are " +
+					"replaced with the selection, and other placeholders with the answers to its prompts, each quoted for the shell. " +
+					"Machine policy can restrict them along with hooks and plugins.",
+				Subcommands: []*Command{
+					{
+						Name:    "list",
+						Summary: "List the custom commands and their keys",
+						Flags: []Flag{
+							{Name: "json", Usage: "Write the custom commands as a versioned JSON document"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "The custom commands were listed"},
+							{Code: exitcode.UserError, Meaning: "Usage error or the config could not be loaded"},
+							{Code: exitcode.PolicyViolation, Meaning: "Custom commands are restricted by machine policy"},
+						},
+					},
+					{
+						Name:     "run",
+						Summary:  "Run a custom command",
+						Requires: policy.CapabilityCustomCommands,
+						Description: "Prompts not answered on the command line are asked on the terminal, or take their default without one. " +
+							"The output is shown in $PAGER (default: less -R) when it does not fit the terminal; commands with " +
+							"output terminal get the terminal instead, and those with output none only show output when they fail.",
+						Flags: []Flag{
+							{Name: "project", Placeholder: "PATH", Usage: "Project for {{project}}", Kind: completion.KindProject},
+							{Name: "package", Placeholder: "ID", Usage: "Package for {{package}}", Kind: completion.KindPackage},
+							{Name: "version", Placeholder: "VERSION", Usage: "Package version for {{version}}"},
+						},
+						Args: []Arg{
+							{Name: "name", Usage: "Custom command name (see custom list)", Kind: completion.KindText},
+							{Name: "answers", Usage: "Prompt answers as PROMPT=ANSWER", Kind: completion.KindText, Optional: true, Variadic: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget custom run --project src/App/App.csproj --package Serilog why", Description: "Run the custom command named why"},
+							{Command: "lazynuget custom run bump message='Update Serilog'", Description: "Answer its message prompt"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "The command succeeded"},
+							{Code: exitcode.UserError, Meaning: "Usage error, an unknown command, or a missing selection or answer"},
+							{Code: exitcode.SystemError, Meaning: "The command failed, timed out, or could not be run"},
+							{Code: exitcode.PolicyViolation, Meaning: "Custom commands are restricted by machine policy"},
+						},
+					},
+				},
+			},
+			{
+				Name:    "search",
+				Summary: "Search nuget.org for packages",
+				Description: "Lists the packages matching a query with their total downloads, the downloads of the latest " +
+					"version, and their owners. Verified packages, whose ID prefix is reserved by their owner, are marked, " +
+					"and so are IDs that look like a popular package's. With --trends, each package also shows a sparkline of its weekly download totals over the last " +
+					"months, from NuGet Trends (nugettrends.com).\n\n" +
+					"Results come a page at a time; --sort downloads orders the page by total downloads.\n\n" +
+					"Queries are remembered per workspace, the last 100, for the search box to recall with the up arrow; " +
+					"--history lists them.",
+				Flags: []Flag{
+					{Name: "prerelease", Usage: "Include prerelease versions"},
+					{Name: "take", Placeholder: "N", Usage: "Number of results (at most 1000)", Default: "20"},
+					{Name: "sort", Placeholder: "ORDER", Usage: "Order of the results (relevance|downloads)", Default: "relevance", Values: []string{"relevance", "downloads"}},
+					{Name: "trends", Placeholder: "MONTHS", Usage: "Show download trends over the last MONTHS months"},
+					{Name: "history", Usage: "List the workspace's past searches, most recent first, instead of searching"},
+					{Name: "clear-history", Usage: "Forget the workspace's past searches"},
+					{Name: "json", Usage: "Write the results as a versioned JSON document"},
+				},
+				Args: []Arg{
+					{Name: "query", Usage: "Search terms (default: the most popular packages)", Kind: completion.KindText, Optional: true},
+				},
+				Examples: []Example{
+					{Command: "lazynuget search serilog"},
+					{Command: "lazynuget search --sort downloads --trends 12 json", Description: "Most downloaded first, with a year of history"},
+				},
+				ExitCodes: []ExitCode{
+					{Code: exitcode.Success, Meaning: "The results were written"},
+					{Code: exitcode.UserError, Meaning: "Usage error"},
+					{Code: exitcode.SystemError, Meaning: "The search service could not be reached"},
+				},
+			},
+			{
+				Name:    "snapshot",
+				Summary: "Save and restore the package versions of every project",
+				Description: "Snapshots capture the direct package versions of every project in the repository " +
+					"(and Directory.Packages.props under central package management) so they can be re-applied later, " +
+					"e.g., to bisect a regression introduced by a bulk update.",
+				Subcommands: []*Command{
+					{
+						Name:    "create",
+						Summary: "Write the current package versions to a snapshot file",
+						Args: []Arg{
+							{Name: "file", Usage: "Snapshot file (default: stdout; - for stdout)", Kind: completion.KindFile, Optional: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget snapshot create before-update.json"},
+						},
+					},
+					{
+						Name:    "apply",
+						Summary: "Restore the package versions from a snapshot file",
+						Description: "Sets each package version recorded in the snapshot, editing only the version text. " +
+							"Packages added since the snapshot are left alone; removed packages and projects are reported.",
+						Flags: []Flag{
+							{Name: "dry-run", Usage: "Only report the versions that would change"},
+						},
+						Args: []Arg{
+							{Name: "file", Usage: "Snapshot file", Kind: completion.KindFile},
+						},
+						Examples: []Example{
+							{Command: "lazynuget snapshot apply before-update.json", Description: "Then run dotnet restore"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "Every recorded version was applied"},
+							{Code: exitcode.UserError, Meaning: "Usage error"},
+							{Code: exitcode.SystemError, Meaning: "The snapshot could not be read or a project could not be written"},
+							{Code: exitcode.PartialFailure, Meaning: "Some packages or projects no longer exist; the other versions were applied"},
+						},
+					},
+				},
+			},
+			{
+				Name:    "status-line",
+				Summary: "Print the status bar for a shell prompt or tmux",
+				Description: "Prints the status bar of the repository on one line: the segments of the statusBar.format setting " +
+					"({mode}, {repo}, {feeds}, {pending}, {vulns}, {clock}) in its order, drawn in the status bar colors of the theme. " +
+					"Segments with nothing to report (no pending restores, no vulnerable packages) are left out with the text before them.\n\n" +
+					"Nothing is run: projects pending a restore are those changed since obj/project.assets.json was written, and " +
+					"vulnerable packages are those the last restore warned about (NuGetAudit). Feeds are only checked with --check-feeds, " +
+					"which waits up to 3 seconds for nuget.org and the enabled feeds of the config.",
+				Flags: []Flag{
+					{Name: "format", Placeholder: "FORMAT", Usage: "Segments to show (default: the statusBar.format setting)"},
+					{Name: "check-feeds", Usage: "Check that the feeds answer, for the {feeds} segment"},
+					{Name: "no-color", Usage: "Draw without colors (or set NO_COLOR)"},
+					{Name: "json", Usage: "Write the segment data as a versioned JSON document"},
+				},
+				Examples: []Example{
+					{Command: "lazynuget status-line --format '{repo} | {pending} | {vulns}'"},
+					{Command: "set -g status-right '#(lazynuget status-line --no-color)'", Description: "In ~/.tmux.conf"},
+				},
+				ExitCodes: []ExitCode{
+					{Code: exitcode.Success, Meaning: "The status line was written"},
+					{Code: exitcode.UserError, Meaning: "Usage error or unknown segment in --format"},
+					{Code: exitcode.SystemError, Meaning: "The repository root could not be found"},
+				},
+			},
+			{
+				Name:    "update",
+				Summary: "Update package references, from arguments or stdin",
+				Description: "Moves each package the project references to the version given or the latest on the feed. " +
+					"Packages the project does not reference are skipped; use add for those.\n\n" +
+					bulkDescription,
+				Flags: bulkFlags(
+					Flag{Name: "prerelease", Usage: "Consider prerelease versions for the latest version"},
+					Flag{Name: "source", Placeholder: "URL", Usage: "Package base address (V3 flat container) or service index URL of the feed", Default: nuget.DefaultFeedURL},
+				),
+				Args: bulkArgs,
+				Examples: []Example{
+					{Command: "lazynuget update Serilog"},
+					{Command: "grep '^Microsoft\\.Extensions\\.' packages.txt | lazynuget update --yes", Description: "Update a family of packages"},
+				},
+				ExitCodes: bulkExitCodes,
+			},
+			{
+				Name:    "tools",
+				Summary: "Manage .NET tools",
+				Description: "Local tools are pinned in a tool manifest (.config/dotnet-tools.json) found in the current " +
+					"directory or its parents, and restored with dotnet tool restore; global tools are installed for the user. " +
+					"Changes run dotnet tool.",
+				Subcommands: []*Command{
+					{
+						Name:    "list",
+						Summary: "List local and global tools with available updates",
+						Description: "Lists the tools of the nearest tool manifests and the global tools, comparing each " +
+							"installed version with the versions of its package on the feed.",
+						Flags: []Flag{
+							{Name: "prerelease", Usage: "Consider prerelease versions"},
+							{Name: "offline", Usage: "Compare with the versions in the global packages folder instead of the feed"},
+							{Name: "source", Placeholder: "URL", Usage: "Package base address (V3 flat container) or service index URL of the feed", Default: nuget.DefaultFeedURL},
+							{Name: "json", Usage: "Write the tools as a versioned JSON document"},
+						},
+						Examples: []Example{
+							{Command: "lazynuget tools list"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "Every tool was checked"},
+							{Code: exitcode.UserError, Meaning: "Usage error"},
+							{Code: exitcode.SystemError, Meaning: "A tool manifest could not be read, or dotnet or the feed could not be run or reached"},
+						},
+					},
+					{
+						Name:    "install",
+						Summary: "Install a tool",
+						Description: "Installs a local tool into the nearest tool manifest, creating .config/dotnet-tools.json in the " +
+							"current directory when there is none, or a global tool with --global.",
+						Flags: []Flag{
+							{Name: "global", Usage: "Install for the user instead of in the tool manifest"},
+						},
+						Args: []Arg{
+							{Name: "package", Usage: "Tool package ID", Kind: completion.KindPackage},
+							{Name: "version", Usage: "Version (default: the latest release)", Optional: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget tools install dotnet-ef"},
+							{Command: "lazynuget tools install --global dotnet-outdated-tool 4.6.4"},
+						},
+						ExitCodes: toolExitCodes,
+					},
+					{
+						Name:    "update",
+						Summary: "Update a tool, or every tool",
+						Flags: []Flag{
+							{Name: "global", Usage: "Update a global tool instead of a local one"},
+							{Name: "all", Usage: "Update every tool in the scope"},
+						},
+						Args: []Arg{
+							{Name: "package", Usage: "Tool package ID (omit with --all)", Kind: completion.KindPackage, Optional: true},
+							{Name: "version", Usage: "Version (default: the latest release)", Optional: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget tools update dotnet-ef"},
+							{Command: "lazynuget tools update --global --all"},
+						},
+						ExitCodes: toolExitCodes,
+					},
+					{
+						Name:    "uninstall",
+						Summary: "Uninstall a tool",
+						Flags: []Flag{
+							{Name: "global", Usage: "Uninstall a global tool instead of a local one"},
+						},
+						Args: []Arg{
+							{Name: "package", Usage: "Tool package ID", Kind: completion.KindPackage},
+						},
+						Examples: []Example{
+							{Command: "lazynuget tools uninstall dotnet-ef"},
+						},
+						ExitCodes: toolExitCodes,
+					},
+				},
+			},
+			{
+				Name:    "trust",
+				Summary: "Show and edit the package signature trust policy in nuget.config",
+				Description: "NuGet's trust policy decides which signed packages restore accepts: with signatureValidationMode " +
+					"require, only packages signed by a trusted signer, an author whose certificate is listed or a repository " +
+					"whose certificate is listed and, if owners are given, only for packages of those owners. With accept, " +
+					"the default, unsigned packages and packages of any signer are restored. Trusted signers are merged from " +
+					"every nuget.config at or above the directory, nearest first, and the user's. add, remove, and mode change " +
+					"--nuget-config, or the nearest nuget.config, or the user's.",
+				Subcommands: []*Command{
+					{
+						Name:        "list",
+						Summary:     "List the signature validation mode and the trusted signers",
+						Description: "Lists the trusted authors and repositories that apply in the current directory, with the fingerprints of their certificates and the file that lists each.",
+						Flags: []Flag{
+							{Name: "json", Usage: "Write the policy as a versioned JSON document"},
+						},
+						Examples: []Example{
+							{Command: "lazynuget trust list"},
+						},
+					},
+					{
+						Name:    "add",
+						Summary: "Trust an author or repository",
+						Description: "Adds a trusted signer, or another certificate to one the file lists already (e.g., when it renews " +
+							"its certificate). The certificate is given by fingerprint or, with --package, taken from the author or " +
+							"repository signature of a package in the global packages folder. A repository needs a service index, " +
+							"which --package takes from the repository signature.",
+						Flags: []Flag{
+							{Name: "fingerprint", Placeholder: "HEX", Usage: "Fingerprint of the signing certificate"},
+							{Name: "package", Placeholder: "ID@VERSION", Usage: "Trust the certificate that signed this restored package", Kind: completion.KindPackage},
+							{Name: "hash-algorithm", Placeholder: "NAME", Usage: "Hash algorithm of the fingerprint", Default: "SHA256", Values: []string{"SHA256", "SHA384", "SHA512"}},
+							{Name: "allow-untrusted-root", Usage: "Accept the certificate even when its root is not trusted on this machine"},
+							{Name: "service-index", Placeholder: "URL", Usage: "Service index of the repository"},
+							{Name: "owners", Placeholder: "LIST", Usage: "Trust the repository only for packages of these owners (semicolon-separated)"},
+							{Name: "nuget-config", Placeholder: "FILE", Usage: "NuGet configuration file to change", Kind: completion.KindFile},
+						},
+						Args: []Arg{
+							{Name: "kind", Usage: "author or repository"},
+							{Name: "name", Usage: "Name of the trusted signer"},
+						},
+						Examples: []Example{
+							{Command: "lazynuget trust add --package Newtonsoft.Json@13.0.3 repository nuget.org", Description: "Trust nuget.org's repository signature"},
+							{Command: "lazynuget trust add --package Newtonsoft.Json@13.0.3 --owners \"jamesnk;microsoft\" repository nuget.org", Description: "Only for packages of these owners"},
+							{Command: "lazynuget trust add --fingerprint 3F9001EA83C560D712C24CF213C3D312CB3BFF51EE89435D3430BD06B5D0EECE author microsoft"},
+						},
+						ExitCodes: trustExitCodes,
+					},
+					{
+						Name:    "remove",
+						Summary: "Stop trusting an author or repository",
+						Flags: []Flag{
+							{Name: "nuget-config", Placeholder: "FILE", Usage: "NuGet configuration file to change", Kind: completion.KindFile},
+						},
+						Args: []Arg{
+							{Name: "name", Usage: "Name of the trusted signer"},
+						},
+						Examples: []Example{
+							{Command: "lazynuget trust remove microsoft"},
+						},
+						ExitCodes: trustExitCodes,
+					},
+					{
+						Name:        "mode",
+						Summary:     "Set the signature validation mode",
+						Description: "Sets signatureValidationMode: require restores only packages of trusted signers, accept restores any package.",
+						Flags: []Flag{
+							{Name: "nuget-config", Placeholder: "FILE", Usage: "NuGet configuration file to change", Kind: completion.KindFile},
+						},
+						Args: []Arg{
+							{Name: "mode", Usage: "accept or require"},
+						},
+						Examples: []Example{
+							{Command: "lazynuget trust mode require"},
+						},
+						ExitCodes: trustExitCodes,
+					},
+					{
+						Name:    "check",
+						Summary: "Check the restored packages' signer certificates against the trust policy",
+						Description: "Reads who signed each package of the projects' last restore from its signature in the global " +
+							"packages folder, and whether the policy that applies to the project accepts it. Only the certificates " +
+							"are compared with the trusted signers: restore also verifies the signature and the certificate chain.",
+						Flags: []Flag{
+							{Name: "json", Usage: "Write the packages as a versioned JSON document"},
+						},
+						Args: []Arg{
+							{Name: "project", Usage: "Project files (default: all projects in the repository)", Kind: completion.KindProject, Optional: true, Variadic: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget trust check"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "Every restored package satisfies the policy"},
+							{Code: exitcode.SystemError, Meaning: "A nuget.config could not be read"},
+							{Code: exitcode.PolicyViolation, Meaning: "The mode is require and packages were not signed with a trusted signer's certificate"},
+						},
+					},
+				},
+			},
+			{
+				Name:    "warnings",
+				Summary: "Show and change how NuGet warnings (NUxxxx) are reported",
+				Subcommands: []*Command{
+					{
+						Name:    "list",
+						Summary: "List each project's NuGet warnings, suppressions, and warnings treated as errors",
+						Description: "Lists the NuGet warnings of each project's last restore (from obj/project.assets.json), marking " +
+							"those that fail the build as errors, then the NuGet codes the project suppresses, with the file and line " +
+							"of each: the NoWarn property, in the project or a file it imports such as Directory.Build.props, and the " +
+							"NoWarn metadata of package references. Warnings are errors with TreatWarningsAsErrors, except those in " +
+							"WarningsNotAsErrors, and when listed in WarningsAsErrors; SDK-style projects start with NU1605 there, as " +
+							"the .NET SDK adds it. Properties are read in import order without evaluating their conditions.",
+						Flags: []Flag{
+							{Name: "json", Usage: "Write the warnings and settings as a versioned JSON document"},
+						},
+						Args: []Arg{
+							{Name: "project", Usage: "Project files (default: all projects in the repository)", Kind: completion.KindProject, Optional: true, Variadic: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget warnings list"},
+						},
+					},
+					{
+						Name:    "suppress",
+						Summary: "Suppress a NuGet warning for a project or one of its packages",
+						Description: "Adds the code to the project's NoWarn property, or with --package to the NoWarn metadata of " +
+							"the package's reference, so that restore no longer reports it. A project without a NoWarn property " +
+							"gets <NoWarn>$(NoWarn);CODE</NoWarn>, which keeps the codes of imported files. With --reason, a " +
+							"comment above it says why (\"NU1603: reason\").",
+						Flags: []Flag{
+							{Name: "package", Placeholder: "ID", Usage: "Suppress the warning for this package's reference only", Kind: completion.KindPackage},
+							{Name: "reason", Placeholder: "TEXT", Usage: "Comment written above the suppression"},
+							{Name: "project", Placeholder: "PATH", Usage: "Project to change (default: the only project in the repository)", Kind: completion.KindProject},
+						},
+						Args: []Arg{
+							{Name: "code", Usage: "NuGet warning code (e.g., NU1603)"},
+						},
+						Examples: []Example{
+							{Command: "lazynuget warnings suppress --reason \"The feed lacks the exact dependency version\" NU1603"},
+							{Command: "lazynuget warnings suppress --package Legacy.Drawing NU1701", Description: "A .NET Framework package known to work"},
+						},
+						ExitCodes: warningExitCodes,
+					},
+					{
+						Name:    "unsuppress",
+						Summary: "Stop suppressing a NuGet warning",
+						Description: "Removes the code from the NoWarn property where the project's suppression is defined (the project " +
+							"or a file it imports), or with --package from the package reference's NoWarn metadata, along with the " +
+							"comment suppress wrote. A NoWarn property left with nothing of its own is removed.",
+						Flags: []Flag{
+							{Name: "package", Placeholder: "ID", Usage: "Remove the suppression from this package's reference", Kind: completion.KindPackage},
+							{Name: "project", Placeholder: "PATH", Usage: "Project to change (default: the only project in the repository)", Kind: completion.KindProject},
+						},
+						Args: []Arg{
+							{Name: "code", Usage: "NuGet warning code (e.g., NU1603)"},
+						},
+						Examples: []Example{
+							{Command: "lazynuget warnings unsuppress NU1603"},
+						},
+						ExitCodes: warningExitCodes,
+					},
+				},
+			},
+			{
+				Name:    "workloads",
+				Summary: "Show .NET SDK workloads",
+				Description: "Workloads add platforms to the .NET SDK: Android, iOS, Mac Catalyst, macOS, tvOS, .NET MAUI, " +
+					"and WebAssembly. Projects that target net8.0-android or similar, or set UseMaui, need the matching workload to build.",
+				Subcommands: []*Command{
+					{
+						Name:        "list",
+						Summary:     "List installed workloads and available updates",
+						Description: "Runs dotnet workload list, which checks the feed for newer workload manifests. Update with dotnet workload update.",
+						Flags: []Flag{
+							{Name: "json", Usage: "Write the workloads as a versioned JSON document"},
+						},
+						Examples: []Example{
+							{Command: "lazynuget workloads list"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "The workloads were listed"},
+							{Code: exitcode.SystemError, Meaning: "dotnet workload list failed or could not be run"},
+						},
+					},
+					{
+						Name:    "check",
+						Summary: "Report workloads that projects need but are not installed",
+						Description: "Lists the workload each platform-specific target framework needs (maui-android for " +
+							"net8.0-android in a MAUI project, android otherwise) and whether it is installed. " +
+							"With --install, missing workloads are installed with dotnet workload install, which may need " +
+							"administrator rights when the SDK is installed machine-wide.",
+						Flags: []Flag{
+							{Name: "install", Usage: "Install the missing workloads"},
+							{Name: "json", Usage: "Write the requirements as a versioned JSON document"},
+						},
+						Args: []Arg{
+							{Name: "project", Usage: "Project files (default: all projects in the repository)", Kind: completion.KindProject, Optional: true, Variadic: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget workloads check"},
+							{Command: "lazynuget workloads check --install src/App/App.csproj"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "Every required workload is installed (or was, with --install)"},
+							{Code: exitcode.UserError, Meaning: "Usage error, or required workloads are missing"},
+							{Code: exitcode.SystemError, Meaning: "A project could not be read, or dotnet workload failed or could not be run"},
+						},
+					},
+				},
+			},
+			{
+				Name:     "update-self",
+				Summary:  "Update to the latest release",
+				Requires: policy.CapabilitySelfUpdate,
+				Description: "Replaces this binary with the latest GitHub release after verifying its checksum and signature. " +
+					"Set updateCheck: true in the config to be notified of new releases at startup instead.",
+				Flags: []Flag{
+					{Name: "check", Usage: "Only report whether a newer release exists"},
+					{Name: "force", Usage: "Install the latest release even if it is not newer (e.g., over a development build)"},
+					{Name: "insecure", Usage: "Install on the checksum alone when this build has no signing key (development builds)"},
+				},
+				Examples: []Example{
+					{Command: "lazynuget update-self --check", Description: "Report whether an update is available"},
+					{Command: "lazynuget update-self", Description: "Download, verify, and install it"},
+				},
+				ExitCodes: []ExitCode{
+					{Code: exitcode.Success, Meaning: "Updated, or already up to date"},
+					{Code: exitcode.UserError, Meaning: "Usage error, a development build without --force, or a build without a signing key and without --insecure"},
+					{Code: exitcode.SystemError, Meaning: "Download, verification, or installation failed"},
+					{Code: exitcode.PolicyViolation, Meaning: "Updates are restricted by machine policy"},
+				},
+			},
+			{
+				Name:    "path",
+				Summary: "Print where lazynuget keeps its files",
+				Description: "Prints the platform directory of the config, cache, data, logs, or remembered state, " +
+					"so you need not know where each OS puts them. With --open it is also shown in the file manager.",
+				Subcommands: []*Command{
+					pathCommand("config", "Print the user config directory (config.yml or config.toml)"),
+					pathCommand("cache", "Print the cache directory (feed responses, locks, daemon sockets)"),
+					pathCommand("data", "Print the directory of notes, search history, telemetry consent, and update checks"),
+					pathCommand("logs", "Print the log directory (logDir in the config, or the platform default)"),
+					pathCommand("state", "Print the directory of trusted repository configs"),
+				},
+			},
+			{
+				Name:    "metrics",
+				Summary: "Inspect internal metrics",
+				Subcommands: []*Command{
+					{
+						Name:    "dump",
+						Summary: "Print metrics from a running instance",
+						Description: "Prints internal metrics (HTTP requests, cache hit rate, operation durations, goroutines) " +
+							"from a running instance started with --metrics-addr.",
+						Flags: []Flag{
+							{Name: "addr", Placeholder: "ADDR", Usage: "Metrics address of the running instance", Default: metrics.DefaultAddr},
+						},
+						Examples: []Example{
+							{Command: "lazynuget --metrics-addr " + metrics.DefaultAddr + " &", Description: "Start an instance with metrics"},
+							{Command: "lazynuget metrics dump", Description: "Print its metrics"},
+						},
+						ExitCodes: []ExitCode{
+							{Code: exitcode.Success, Meaning: "Success"},
+							{Code: exitcode.UserError, Meaning: "Usage error or no metrics endpoint at the address"},
+							{Code: exitcode.SystemError, Meaning: "The endpoint returned an error"},
+						},
+					},
+				},
+			},
+			{
+				Name:        "telemetry",
+				Summary:     "Inspect or change anonymous usage statistics",
+				Description: "Anonymous usage statistics are off unless you opt in.",
+				Subcommands: []*Command{
+					{Name: "show", Summary: "Print the consent status and the exact report that would be sent"},
+					{Name: "enable", Summary: "Opt in to anonymous usage statistics", Requires: policy.CapabilityTelemetry},
+					{Name: "disable", Summary: "Opt out and delete unsent usage data"},
+				},
+			},
+			{
+				Name:    "completion",
+				Summary: "Print a shell completion script",
+				Description: "Prints a completion script for subcommands, flags, config profiles, cached package IDs, " +
+					"and project files in the current repository.",
+				Examples: []Example{
+					{Command: "echo 'source <(lazynuget completion bash)' >> ~/.bashrc"},
+					{Command: `lazynuget completion zsh > "${fpath[1]}/_lazynuget"`},
+					{Command: "lazynuget completion fish > ~/.config/fish/completions/lazynuget.fish"},
+					{Command: "lazynuget completion powershell >> $PROFILE"},
+				},
+				Subcommands: shellCommands(),
+			},
+			{
+				Name:    "help",
+				Summary: "Show help for a command",
+				Args: []Arg{
+					{Name: "command", Usage: "Command path (e.g., config schema)", Optional: true, Variadic: true},
+				},
+				Examples: []Example{
+					{Command: "lazynuget help update-self"},
+				},
+			},
+			{
+				Name:    "docs",
+				Summary: "Generate documentation",
+				Subcommands: []*Command{
+					{
+						Name:    "man",
+						Summary: "Write roff man pages for every command",
+						Description: "Writes lazynuget.1 and one page per command (e.g., lazynuget-config-schema.1) into DIR. " +
+							"Install them into a man1 directory on MANPATH.",
+						Args: []Arg{
+							{Name: "dir", Usage: "Output directory (default: " + DefaultManDir + ")", Kind: completion.KindFile, Optional: true},
+						},
+						Examples: []Example{
+							{Command: "lazynuget docs man /usr/local/share/man/man1"},
+							{Command: "man ./man/lazynuget-update-self.1", Description: "Preview a page"},
+						},
+					},
+				},
+			},
+			{Name: completion.CompleteCommand, Summary: "Print completion candidates (used by completion scripts)", Hidden: true},
+		},
+	}
+}
+
+// shellCommands returns one completion subcommand per supported shell.
+func shellCommands() []*Command {
+	var commands []*Command
+	for _, shell := range completion.Shells {
+		commands = append(commands, &Command{
+			Name:    shell,
+			Summary: "Completion script for " + shell,
+		})
+	}
+	return commands
+}
+
+// outputVersions lists the supported --output-version values.
+func outputVersions() []string {
+	var versions []string
+	for v := output.MinSchemaVersion; v <= output.CurrentSchemaVersion; v++ {
+		versions = append(versions, fmt.Sprint(v))
+	}
+	return versions
+}
+
+// pathCommand describes a `lazynuget path` subcommand.
+func pathCommand(name, summary string) *Command {
+	return &Command{
+		Name:    name,
+		Summary: summary,
+		Flags: []Flag{
+			{Name: "open", Usage: "Also show the directory in the file manager, creating it if needed"},
+		},
+		Examples: []Example{
+			{Command: "lazynuget path " + name},
+			{Command: "cd \"$(lazynuget path " + name + ")\""},
+		},
+		ExitCodes: []ExitCode{
+			{Code: exitcode.Success, Meaning: "Success"},
+			{Code: exitcode.UserError, Meaning: "Usage error"},
+			{Code: exitcode.SystemError, Meaning: "The directory cannot be determined, or opened with --open"},
+		},
+	}
+}
+
+// bulkDescription is the part of the add, remove, and update descriptions they share.
+const bulkDescription = "Packages are given as arguments (ID, or ID@VERSION) or, without arguments or with -, " +
+	"read from stdin one per line (ID, ID@VERSION, or ID VERSION; blank lines and lines starting with # are ignored), " +
+	"so lists from grep or jq can be piped in. Each package is reported as it is done, and a failure does not stop the others. " +
+	"Changes go through the operationBackend setting, and projects are restored as the restoreMode setting says. " +
+	"The confirm settings decide whether to ask first (by default, before removing and before changing more than one " +
+	"package); without a terminal to ask on, --yes confirms. Before asking to add or update, the download size of the packages " +
+	"and of the new dependencies they bring is shown, with an estimate of the space they take on disk.\n\n" +
+	"In a multi-targeting project, --framework changes the references under a condition that applies to that target framework " +
+	"(Condition=\"'$(TargetFramework)' == 'net48'\" on the reference or its ItemGroup): add puts a new reference in an ItemGroup " +
+	"with the framework's condition, and update and remove leave the other frameworks' references alone. " +
+	"A package every framework references is skipped; change it without --framework."
+
+// bulkFlags returns the flags of add, remove, or update: its own, then those all three share.
+func bulkFlags(flags ...Flag) []Flag {
+	return append(flags,
+		Flag{Name: "project", Placeholder: "PATH", Usage: "Project to change (default: the only project in the repository)", Kind: completion.KindProject},
+		Flag{Name: "framework", Placeholder: "TFM", Usage: "Change only the references one target framework of a multi-targeting project has (e.g., net48)"},
+		Flag{Name: "json", Usage: "Write the outcome of each package as a versioned JSON document"},
+		Flag{Name: "yes", Usage: "Go ahead without asking, as the confirm settings would (needed to confirm without a terminal)"},
+	)
+}
+
+// bulkArgs are the arguments of add, remove, and update.
+var bulkArgs = []Arg{
+	{Name: "package", Usage: "Package ID or ID@VERSION (default: read from stdin)", Kind: completion.KindPackage, Optional: true, Variadic: true},
+}
+
+// bulkExitCodes are the exit codes of add, remove, and update.
+var bulkExitCodes = []ExitCode{
+	{Code: exitcode.Success, Meaning: "Every package was changed, or already was as asked"},
+	{Code: exitcode.UserError, Meaning: "Usage error, an invalid package, not confirmed, or no package applied (not referenced, or no versions)"},
+	{Code: exitcode.SystemError, Meaning: "No package could be changed: the project, the feed, or dotnet failed"},
+	{Code: exitcode.PartialFailure, Meaning: "Some packages were changed and others failed or were skipped"},
+}
+
+// warningExitCodes are the exit codes of the commands that change warning suppressions.
+var warningExitCodes = []ExitCode{
+	{Code: exitcode.Success, Meaning: "The suppression was changed, or already was as asked"},
+	{Code: exitcode.UserError, Meaning: "Usage error: not a NuGet code, or the package is not referenced"},
+	{Code: exitcode.SystemError, Meaning: "The project file could not be read or written"},
+}
+
+// trustExitCodes are the exit codes of the commands that change the trust policy.
+var trustExitCodes = []ExitCode{
+	{Code: exitcode.Success, Meaning: "nuget.config was changed, or already was as asked"},
+	{Code: exitcode.UserError, Meaning: "Usage error, or the package is not restored or not signed that way"},
+	{Code: exitcode.SystemError, Meaning: "nuget.config or the package signature could not be read, or nuget.config could not be written"},
+}
+
+// toolExitCodes are the exit codes of the commands that change .NET tools.
+var toolExitCodes = []ExitCode{
+	{Code: exitcode.Success, Meaning: "dotnet tool succeeded"},
+	{Code: exitcode.UserError, Meaning: "Usage error"},
+	{Code: exitcode.SystemError, Meaning: "dotnet tool failed or could not be run"},
+}
+
+// feedFlags returns the flags of a `feeds` subcommand: its own, then those every
+// registry shares.
+func feedFlags(tokenEnv string, flags ...Flag) []Flag {
+	return append(flags,
+		Flag{Name: "name", Placeholder: "NAME", Usage: "Name of the source (default: the feed name, or the registry and owner)"},
+		Flag{Name: "token-env", Placeholder: "VAR", Usage: "Environment variable holding the token", Default: tokenEnv},
+		Flag{Name: "nuget-config", Placeholder: "FILE", Usage: "NuGet configuration file to add the source to (default: the user's)", Kind: completion.KindFile},
+		Flag{Name: "store-password-in-clear-text", Usage: "Write the token to nuget.config for dotnet"},
+		Flag{Name: "no-test", Usage: "Add the source without testing the token"},
+	)
+}
+
+// feedExitCodes are the exit codes of the `feeds` subcommands.
+var feedExitCodes = []ExitCode{
+	{Code: exitcode.Success, Meaning: "The source was added"},
+	{Code: exitcode.UserError, Meaning: "Usage error, a missing value or token, or the feed rejected the token"},
+	{Code: exitcode.SystemError, Meaning: "The feed could not be reached or nuget.config could not be written"},
+}
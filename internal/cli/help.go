@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/policy"
+)
+
+// Help layout
+const (
+	helpWidth     = 80 // Descriptions are wrapped to this width
+	minHelpColumn = 18 // Keeps short option lists aligned across commands
+	maxHelpColumn = 40 // Wider first columns put the second column on the next line
+)
+
+// WriteHelp writes the command's --help text.
+func (c *Command) WriteHelp(w io.Writer) error {
+	return c.WritePolicyHelp(w, nil)
+}
+
+// WritePolicyHelp writes the command's --help text, marking the subcommands whose
+// capability p disables as restricted by policy. A nil policy restricts nothing.
+func (c *Command) WritePolicyHelp(w io.Writer, p *policy.Policy) error {
+	var sb strings.Builder
+
+	if c.parent == nil {
+		sb.WriteString("LazyNuGet - " + c.Summary + "\n\n")
+	} else {
+		for _, paragraph := range strings.Split(c.description(), "\n\n") {
+			sb.WriteString(wrap(paragraph, helpWidth) + "\n\n")
+		}
+	}
+
+	sb.WriteString("Usage:\n")
+	for _, usage := range c.usageLines() {
+		sb.WriteString("  " + usage + "\n")
+	}
+
+	if subs := c.visibleSubcommands(); len(subs) > 0 {
+		rows := make([][2]string, 0, len(subs))
+		for _, sub := range subs {
+			summary := sub.Summary
+			if sub.Requires != "" {
+				summary = p.Label(sub.Requires, summary)
+			}
+			rows = append(rows, [2]string{sub.Name, summary})
+		}
+		writeSection(&sb, "Commands", rows, 0)
+	}
+
+	if len(c.Args) > 0 {
+		rows := make([][2]string, 0, len(c.Args))
+		for _, arg := range c.Args {
+			rows = append(rows, [2]string{arg.Name, arg.Usage})
+		}
+		writeSection(&sb, "Arguments", rows, 0)
+	}
+
+	if len(c.Flags) > 0 {
+		rows := make([][2]string, 0, len(c.Flags))
+		for _, f := range c.Flags {
+			rows = append(rows, [2]string{f.synopsis(), f.helpText()})
+		}
+		writeSection(&sb, "Options", rows, minHelpColumn)
+	}
+
+	if len(c.Examples) > 0 {
+		writeExamples(&sb, c.Examples)
+	}
+
+	rows := make([][2]string, 0, len(c.exitCodes()))
+	for _, code := range c.exitCodes() {
+		rows = append(rows, [2]string{fmt.Sprint(code.Code), code.Meaning})
+	}
+	writeSection(&sb, "Exit codes", rows, 0)
+
+	if len(c.Subcommands) > 0 {
+		fmt.Fprintf(&sb, "\nRun '%s <command> --help' for details on a command.\n", c.Path())
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// description returns the long description, falling back to the summary.
+func (c *Command) description() string {
+	if c.Description != "" {
+		return c.Description
+	}
+	return c.Summary
+}
+
+// visibleSubcommands returns subcommands that are not hidden.
+func (c *Command) visibleSubcommands() []*Command {
+	var subs []*Command
+	for _, sub := range c.Subcommands {
+		if !sub.Hidden {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+// synopsis renders a flag as it is typed (e.g., "--config PATH").
+func (f *Flag) synopsis() string {
+	if f.Placeholder == "" {
+		return "--" + f.Name
+	}
+	return "--" + f.Name + " " + f.Placeholder
+}
+
+// helpText returns the flag's usage with its default.
+func (f *Flag) helpText() string {
+	text := f.Usage
+	if f.Default != "" {
+		text += fmt.Sprintf(" (default: %s)", f.Default)
+	}
+	return text
+}
+
+// writeSection writes a titled two-column list at least minWidth wide. Rows whose first
+// column is too wide put the second column on the next line.
+func writeSection(sb *strings.Builder, title string, rows [][2]string, minWidth int) {
+	width := minWidth
+	for _, row := range rows {
+		if n := len(row[0]); n > width && n <= maxHelpColumn {
+			width = n
+		}
+	}
+
+	sb.WriteString("\n" + title + ":\n")
+	for _, row := range rows {
+		switch {
+		case row[1] == "":
+			fmt.Fprintf(sb, "  %s\n", row[0])
+		case len(row[0]) > width:
+			fmt.Fprintf(sb, "  %s\n  %-*s  %s\n", row[0], width, "", row[1])
+		default:
+			fmt.Fprintf(sb, "  %-*s  %s\n", width, row[0], row[1])
+		}
+	}
+}
+
+// writeExamples writes examples with aligned comments, or with the comment on its own
+// line above commands too long to align.
+func writeExamples(sb *strings.Builder, examples []Example) {
+	width := 0
+	for _, ex := range examples {
+		if n := len(ex.Command); n > width && n <= maxHelpColumn {
+			width = n
+		}
+	}
+
+	sb.WriteString("\nExamples:\n")
+	for _, ex := range examples {
+		switch {
+		case ex.Description == "":
+			fmt.Fprintf(sb, "  %s\n", ex.Command)
+		case len(ex.Command) > width:
+			fmt.Fprintf(sb, "  # %s\n  %s\n", ex.Description, ex.Command)
+		default:
+			fmt.Fprintf(sb, "  %-*s  # %s\n", width, ex.Command, ex.Description)
+		}
+	}
+}
+
+// wrap breaks text into lines of at most width characters at spaces.
+func wrap(text string, width int) string {
+	var sb strings.Builder
+	lineLen := 0
+	for _, word := range strings.Fields(text) {
+		switch {
+		case lineLen == 0:
+		case lineLen+1+len(word) > width:
+			sb.WriteByte('\n')
+			lineLen = 0
+		default:
+			sb.WriteByte(' ')
+			lineLen++
+		}
+		sb.WriteString(word)
+		lineLen += len(word)
+	}
+	return sb.String()
+}
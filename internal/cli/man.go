@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ManPageName returns the man page file name (e.g., "lazynuget-config-schema.1").
+func (c *Command) ManPageName() string {
+	return strings.ReplaceAll(c.Path(), " ", "-") + ".1"
+}
+
+// WriteMan writes the command's man page in roff format.
+func (c *Command) WriteMan(w io.Writer, version string, date time.Time) error {
+	var sb strings.Builder
+	name := strings.ReplaceAll(c.Path(), " ", "-")
+
+	fmt.Fprintf(&sb, ".TH %s 1 \"%s\" \"lazynuget %s\" \"LazyNuGet Manual\"\n",
+		strings.ToUpper(name), date.Format("2006-01-02"), roffEscape(version))
+
+	sb.WriteString(".SH NAME\n")
+	fmt.Fprintf(&sb, "%s \\- %s\n", name, roffEscape(c.Summary))
+
+	sb.WriteString(".SH SYNOPSIS\n")
+	for i, usage := range c.usageLines() {
+		if i > 0 {
+			sb.WriteString(".br\n")
+		}
+		fmt.Fprintf(&sb, ".B %s\n", roffEscape(c.Path()))
+		if rest := strings.TrimSpace(strings.TrimPrefix(usage, c.Path())); rest != "" {
+			sb.WriteString(roffText(rest) + "\n")
+		}
+	}
+
+	sb.WriteString(".SH DESCRIPTION\n")
+	for i, paragraph := range strings.Split(c.description(), "\n\n") {
+		if i > 0 {
+			sb.WriteString(".PP\n")
+		}
+		sb.WriteString(roffText(paragraph) + "\n")
+	}
+
+	if subs := c.visibleSubcommands(); len(subs) > 0 {
+		sb.WriteString(".SH COMMANDS\n")
+		for _, sub := range subs {
+			fmt.Fprintf(&sb, ".TP\n.B %s\n%s\n", roffEscape(sub.Name), roffText(sub.Summary))
+		}
+	}
+
+	if len(c.Args) > 0 {
+		sb.WriteString(".SH ARGUMENTS\n")
+		for _, arg := range c.Args {
+			fmt.Fprintf(&sb, ".TP\n.I %s\n%s\n", roffEscape(arg.Name), roffText(arg.Usage))
+		}
+	}
+
+	if len(c.Flags) > 0 {
+		sb.WriteString(".SH OPTIONS\n")
+		for _, f := range c.Flags {
+			if f.Placeholder == "" {
+				fmt.Fprintf(&sb, ".TP\n.B \\-\\-%s\n", roffEscape(f.Name))
+			} else {
+				fmt.Fprintf(&sb, ".TP\n.BI \\-\\-%s \" \" %s\n", roffEscape(f.Name), roffEscape(f.Placeholder))
+			}
+			sb.WriteString(roffText(f.helpText()) + "\n")
+		}
+	}
+
+	if len(c.Examples) > 0 {
+		sb.WriteString(".SH EXAMPLES\n")
+		for _, ex := range c.Examples {
+			if ex.Description != "" {
+				sb.WriteString(".PP\n" + roffText(ex.Description) + ":\n")
+			}
+			fmt.Fprintf(&sb, ".PP\n.RS\n.nf\n%s\n.fi\n.RE\n", roffEscape(ex.Command))
+		}
+	}
+
+	sb.WriteString(".SH EXIT STATUS\n")
+	for _, code := range c.exitCodes() {
+		fmt.Fprintf(&sb, ".TP\n.B %d\n%s\n", code.Code, roffText(code.Meaning))
+	}
+
+	var related []string
+	if c.parent != nil {
+		related = append(related, c.parent.ManPageName())
+	}
+	for _, sub := range c.visibleSubcommands() {
+		related = append(related, sub.ManPageName())
+	}
+	if len(related) > 0 {
+		sb.WriteString(".SH SEE ALSO\n")
+		for i, page := range related {
+			sep := ","
+			if i == len(related)-1 {
+				sep = ""
+			}
+			fmt.Fprintf(&sb, ".BR %s (1)%s\n", roffEscape(strings.TrimSuffix(page, ".1")), sep)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteManPages writes a man page for the command and every visible descendant into dir
+// and returns the file names written.
+func (c *Command) WriteManPages(dir, version string, date time.Time) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	var written []string
+	var walkErr error
+	c.Walk(func(cmd *Command) {
+		if walkErr != nil || cmd.hiddenInTree() {
+			return
+		}
+		var sb strings.Builder
+		if err := cmd.WriteMan(&sb, version, date); err != nil {
+			walkErr = err
+			return
+		}
+		path := filepath.Join(dir, cmd.ManPageName())
+		if err := os.WriteFile(path, []byte(sb.String()), 0o644); err != nil {
+			walkErr = fmt.Errorf("failed to write %s: %w", path, err)
+			return
+		}
+		written = append(written, cmd.ManPageName())
+	})
+	return written, walkErr
+}
+
+// hiddenInTree reports whether the command or any ancestor is hidden.
+func (c *Command) hiddenInTree() bool {
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		if cmd.Hidden {
+			return true
+		}
+	}
+	return false
+}
+
+// roffEscape escapes backslashes and dashes for roff.
+func roffEscape(s string) string {
+	return strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+}
+
+// roffText escapes text and protects lines that would start with a roff control character.
+func roffText(s string) string {
+	lines := strings.Split(roffEscape(s), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,224 @@
+// Package compare puts two versions of a package side by side to inform an upgrade:
+// which dependencies and target frameworks change, how the package size changes, and
+// which security advisories the upgrade fixes or introduces.
+package compare
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+)
+
+// Kind identifies comparisons in versioned JSON output.
+const Kind = "compare"
+
+// Status says how an item differs between the two versions.
+type Status string
+
+// Statuses.
+const (
+	StatusAdded     Status = "added"
+	StatusRemoved   Status = "removed"
+	StatusChanged   Status = "changed"
+	StatusUnchanged Status = "unchanged"
+)
+
+// Side is one version of the package.
+type Side struct {
+	Version      string                  `json:"version"`
+	Size         int64                   `json:"size"`       // Bytes of the .nupkg; 0 when unknown
+	Frameworks   []string                `json:"frameworks"` // Short monikers, sorted
+	Dependencies []nuget.DependencyGroup `json:"dependencies"`
+	Advisories   []nuget.Advisory        `json:"advisories"` // Advisories affecting this version
+}
+
+// FrameworkChange is a target framework of either version.
+type FrameworkChange struct {
+	Framework string `json:"framework"`
+	Status    Status `json:"status"`
+}
+
+// DependencyChange is a dependency of either version, for one target framework.
+type DependencyChange struct {
+	Framework string `json:"framework"` // "" for dependencies of every framework
+	ID        string `json:"id"`
+	From      string `json:"from"` // Version range in the older version, "" when added
+	To        string `json:"to"`   // Version range in the newer version, "" when removed
+	Status    Status `json:"status"`
+}
+
+// Comparison is two versions of a package side by side.
+type Comparison struct {
+	Package      string             `json:"package"`
+	From         Side               `json:"from"`
+	To           Side               `json:"to"`
+	Frameworks   []FrameworkChange  `json:"frameworks"`
+	Dependencies []DependencyChange `json:"dependencies"`
+	SizeDelta    int64              `json:"sizeDelta"`  // Bytes; 0 when either size is unknown
+	Fixed        []nuget.Advisory   `json:"fixed"`      // Affect From but not To
+	Introduced   []nuget.Advisory   `json:"introduced"` // Affect To but not From
+	// Whether advisories were read; without them, no advisories means none are known
+	AdvisoriesChecked bool     `json:"advisoriesChecked"`
+	Warnings          []string `json:"warnings,omitempty"`
+}
+
+// Load reads two versions of a package from the global packages folder or the feed and
+// compares them. Sizes and advisories that cannot be read are left out with a warning;
+// a version whose manifest cannot be read is an error.
+func Load(ctx context.Context, feed *nuget.Feed, packagesDir, id, from, to string) (*Comparison, error) {
+	var warnings []string
+	advisories, advisoriesErr := feed.Advisories(ctx, id)
+	if advisoriesErr != nil {
+		warnings = append(warnings, advisoriesErr.Error())
+	}
+
+	sides := make([]Side, 2)
+	for i, version := range []string{from, to} {
+		nuspec, err := feed.Nuspec(ctx, packagesDir, id, version)
+		if err != nil {
+			return nil, err
+		}
+		side := Side{Version: version, Dependencies: nuspec.DependencyGroups, Advisories: []nuget.Advisory{}}
+		if side.Dependencies == nil {
+			side.Dependencies = []nuget.DependencyGroup{}
+		}
+		if side.Size, err = feed.PackageSize(ctx, packagesDir, id, version); err != nil {
+			warnings = append(warnings, err.Error())
+		}
+		side.Frameworks = frameworks(packagesDir, id, version, nuspec)
+		for _, a := range advisories {
+			if a.Affects(version) {
+				side.Advisories = append(side.Advisories, a)
+			}
+		}
+		sides[i] = side
+	}
+
+	c := Compare(id, sides[0], sides[1])
+	c.AdvisoriesChecked = advisoriesErr == nil
+	c.Warnings = warnings
+	return c, nil
+}
+
+// frameworks returns the target frameworks of a package version: those it has lib/ or
+// ref/ assets for when it is restored, and those it declares dependencies for otherwise.
+func frameworks(packagesDir, id, version string, nuspec *nuget.Nuspec) []string {
+	list := []string{}
+	if restored, ok := nuget.PackageFrameworks(packagesDir, id, version); ok && packagesDir != "" {
+		for _, f := range restored {
+			list = append(list, f.Moniker)
+		}
+	} else {
+		for _, g := range nuspec.DependencyGroups {
+			if g.Framework != "" {
+				list = append(list, g.Framework)
+			}
+		}
+	}
+	slices.Sort(list)
+	return slices.Compact(list)
+}
+
+// Compare puts two versions of a package side by side.
+func Compare(id string, from, to Side) *Comparison {
+	c := &Comparison{
+		Package:      id,
+		From:         from,
+		To:           to,
+		Frameworks:   []FrameworkChange{},
+		Dependencies: []DependencyChange{},
+		Fixed:        []nuget.Advisory{},
+		Introduced:   []nuget.Advisory{},
+	}
+	if from.Size > 0 && to.Size > 0 {
+		c.SizeDelta = to.Size - from.Size
+	}
+
+	for _, f := range union(from.Frameworks, to.Frameworks) {
+		c.Frameworks = append(c.Frameworks, FrameworkChange{
+			Framework: f,
+			Status:    status(slices.Contains(from.Frameworks, f), slices.Contains(to.Frameworks, f), true),
+		})
+	}
+
+	type key struct{ framework, id string }
+	ranges := func(groups []nuget.DependencyGroup) map[key]string {
+		m := make(map[key]string)
+		for _, g := range groups {
+			for _, d := range g.Dependencies {
+				m[key{g.Framework, strings.ToLower(d.ID)}] = d.Range
+			}
+		}
+		return m
+	}
+	names := make(map[string]string) // Lowercase ID to the ID as written, preferring the newer version
+	for _, groups := range [][]nuget.DependencyGroup{from.Dependencies, to.Dependencies} {
+		for _, g := range groups {
+			for _, d := range g.Dependencies {
+				names[strings.ToLower(d.ID)] = d.ID
+			}
+		}
+	}
+	fromRanges, toRanges := ranges(from.Dependencies), ranges(to.Dependencies)
+	keys := make(map[key]bool)
+	for k := range fromRanges {
+		keys[k] = true
+	}
+	for k := range toRanges {
+		keys[k] = true
+	}
+	for k := range keys {
+		fromRange, inFrom := fromRanges[k]
+		toRange, inTo := toRanges[k]
+		c.Dependencies = append(c.Dependencies, DependencyChange{
+			Framework: k.framework,
+			ID:        names[k.id],
+			From:      fromRange,
+			To:        toRange,
+			Status:    status(inFrom, inTo, fromRange == toRange),
+		})
+	}
+	slices.SortFunc(c.Dependencies, func(a, b DependencyChange) int {
+		return cmp.Or(cmp.Compare(a.Framework, b.Framework), cmp.Compare(strings.ToLower(a.ID), strings.ToLower(b.ID)))
+	})
+
+	affects := func(list []nuget.Advisory, a nuget.Advisory) bool {
+		return slices.ContainsFunc(list, func(b nuget.Advisory) bool { return b.URL == a.URL })
+	}
+	for _, a := range from.Advisories {
+		if !affects(to.Advisories, a) {
+			c.Fixed = append(c.Fixed, a)
+		}
+	}
+	for _, a := range to.Advisories {
+		if !affects(from.Advisories, a) {
+			c.Introduced = append(c.Introduced, a)
+		}
+	}
+	return c
+}
+
+// status returns how an item differs given whether each version has it and, when both
+// do, whether it is the same in both.
+func status(inFrom, inTo, same bool) Status {
+	switch {
+	case !inFrom:
+		return StatusAdded
+	case !inTo:
+		return StatusRemoved
+	case !same:
+		return StatusChanged
+	default:
+		return StatusUnchanged
+	}
+}
+
+// union returns the sorted items of two lists, without duplicates.
+func union(a, b []string) []string {
+	list := slices.Concat(a, b)
+	slices.Sort(list)
+	return slices.Compact(list)
+}
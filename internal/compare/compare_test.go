@@ -0,0 +1,126 @@
+package compare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+)
+
+func TestCompare(t *testing.T) {
+	fixed := nuget.Advisory{URL: "https://example.com/fixed", Severity: "High", Versions: "[, 2.0.0)"}
+	remaining := nuget.Advisory{URL: "https://example.com/remaining", Severity: "Low", Versions: "[, 3.0.0)"}
+	from := Side{
+		Version:    "1.0.0",
+		Size:       1000,
+		Frameworks: []string{"net462", "netstandard2.0"},
+		Dependencies: []nuget.DependencyGroup{
+			{Framework: "netstandard2.0", Dependencies: []nuget.Dependency{{ID: "System.Memory", Range: "4.5.4"}, {ID: "Old", Range: "1.0.0"}}},
+		},
+		Advisories: []nuget.Advisory{fixed, remaining},
+	}
+	to := Side{
+		Version:    "2.0.0",
+		Size:       1500,
+		Frameworks: []string{"net8.0", "netstandard2.0"},
+		Dependencies: []nuget.DependencyGroup{
+			{Framework: "netstandard2.0", Dependencies: []nuget.Dependency{{ID: "system.memory", Range: "4.5.5"}, {ID: "New", Range: "3.0.0"}}},
+		},
+		Advisories: []nuget.Advisory{remaining},
+	}
+
+	c := Compare("Demo", from, to)
+	if c.SizeDelta != 500 {
+		t.Errorf("SizeDelta = %d, want 500", c.SizeDelta)
+	}
+	wantFrameworks := []FrameworkChange{
+		{"net462", StatusRemoved}, {"net8.0", StatusAdded}, {"netstandard2.0", StatusUnchanged},
+	}
+	if !slices.Equal(c.Frameworks, wantFrameworks) {
+		t.Errorf("Frameworks = %v, want %v", c.Frameworks, wantFrameworks)
+	}
+	wantDependencies := []DependencyChange{
+		{Framework: "netstandard2.0", ID: "New", To: "3.0.0", Status: StatusAdded},
+		{Framework: "netstandard2.0", ID: "Old", From: "1.0.0", Status: StatusRemoved},
+		{Framework: "netstandard2.0", ID: "system.memory", From: "4.5.4", To: "4.5.5", Status: StatusChanged},
+	}
+	if !slices.Equal(c.Dependencies, wantDependencies) {
+		t.Errorf("Dependencies = %+v\nwant %+v", c.Dependencies, wantDependencies)
+	}
+	if len(c.Fixed) != 1 || !reflect.DeepEqual(c.Fixed[0], fixed) || len(c.Introduced) != 0 {
+		t.Errorf("Fixed = %v, Introduced = %v, want only %v fixed", c.Fixed, c.Introduced, fixed)
+	}
+
+	// A size that could not be read gives no delta
+	from.Size = 0
+	if c := Compare("Demo", from, to); c.SizeDelta != 0 {
+		t.Errorf("SizeDelta with an unknown size = %d, want 0", c.SizeDelta)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	nuspec := func(version, deps string) string {
+		return `<?xml version="1.0"?><package xmlns="http://schemas.microsoft.com/packaging/2013/05/nuspec.xsd">
+			<metadata><id>Demo</id><version>` + version + `</version><dependencies>` + deps + `</dependencies></metadata></package>`
+	}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/demo/1.0.0/demo.nuspec":
+			fmt.Fprint(w, nuspec("1.0.0", `<group targetFramework=".NETStandard2.0"><dependency id="A" version="1.0.0" /></group>`))
+		case "/demo/2.0.0/demo.nuspec":
+			fmt.Fprint(w, nuspec("2.0.0", `<group targetFramework=".NETStandard2.0"><dependency id="A" version="2.0.0" /></group><group targetFramework="net8.0" />`))
+		case "/demo/1.0.0/demo.1.0.0.nupkg":
+			w.Header().Set("Content-Length", "100")
+		case "/demo/2.0.0/demo.2.0.0.nupkg":
+			w.Header().Set("Content-Length", "250")
+		case "/vulnerabilities/index.json":
+			fmt.Fprintf(w, `[{"@name":"base","@id":"%[1]s/vulnerabilities/base.json"},{"@name":"update","@id":"%[1]s/vulnerabilities/update.json"}]`, server.URL)
+		case "/vulnerabilities/base.json":
+			fmt.Fprint(w, `{"demo":[{"url":"https://example.com/a","severity":3,"versions":"[, 1.5.0)"}],"other":[]}`)
+		case "/vulnerabilities/update.json":
+			fmt.Fprint(w, `{"demo":[{"url":"https://example.com/b","severity":1,"versions":"[2.0.0, 2.0.1)"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	feed := &nuget.Feed{BaseURL: server.URL, VulnerabilityURL: server.URL + "/vulnerabilities/index.json"}
+	c, err := Load(context.Background(), feed, t.TempDir(), "Demo", "1.0.0", "2.0.0")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if c.From.Size != 100 || c.To.Size != 250 || c.SizeDelta != 150 {
+		t.Errorf("sizes = %d, %d (delta %d), want 100, 250 (150)", c.From.Size, c.To.Size, c.SizeDelta)
+	}
+	if got := strings.Join(c.To.Frameworks, ","); got != "net8.0,netstandard2.0" {
+		t.Errorf("To.Frameworks = %s, want net8.0,netstandard2.0", got)
+	}
+	if len(c.Dependencies) != 1 || c.Dependencies[0].Status != StatusChanged {
+		t.Errorf("Dependencies = %+v, want A changed", c.Dependencies)
+	}
+	if !c.AdvisoriesChecked || len(c.Fixed) != 1 || c.Fixed[0].Severity != "Critical" ||
+		len(c.Introduced) != 1 || c.Introduced[0].Severity != "Moderate" {
+		t.Errorf("Fixed = %+v, Introduced = %+v", c.Fixed, c.Introduced)
+	}
+	if len(c.Warnings) != 0 {
+		t.Errorf("Warnings = %v, want none", c.Warnings)
+	}
+
+	// Missing vulnerability data is a warning; a missing version is an error
+	feed.VulnerabilityURL = server.URL + "/missing.json"
+	if c, err := Load(context.Background(), feed, "", "Demo", "1.0.0", "2.0.0"); err != nil || c.AdvisoriesChecked || len(c.Warnings) != 1 {
+		t.Errorf("Load() without vulnerability data = %+v, %v", c, err)
+	}
+	if _, err := Load(context.Background(), feed, "", "Demo", "1.0.0", "9.0.0"); !errors.Is(err, nuget.ErrVersionNotFound) {
+		t.Errorf("Load(missing version) error = %v, want ErrVersionNotFound", err)
+	}
+}
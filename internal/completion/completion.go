@@ -0,0 +1,188 @@
+// Package completion implements shell completion for the lazynuget CLI.
+//
+// The generated scripts are thin: they call `lazynuget __complete <words...>` with the words
+// typed so far (the last being the word under the cursor) and offer the printed candidates.
+// All logic lives in Complete, so every shell completes the same way, and values that depend
+// on the machine (profiles, cached package IDs, workspace projects) are computed on demand.
+package completion
+
+import (
+	"slices"
+	"strings"
+)
+
+// Kind identifies the values a flag or positional argument accepts.
+type Kind string
+
+const (
+	KindNone    Kind = ""        // Takes no value (boolean flag) or nothing to complete
+	KindText    Kind = "text"    // Free text; nothing is suggested
+	KindFile    Kind = "file"    // A path; the shell completes files
+	KindProfile Kind = "profile" // A config profile name
+	KindPackage Kind = "package" // A NuGet package ID from the local package cache
+	KindProject Kind = "project" // A project or solution file in the workspace
+)
+
+// Flag describes a command-line flag.
+type Flag struct {
+	Name        string   // Without dashes
+	Description string   // One line, shown by shells that support descriptions
+	Values      []string // Fixed choices, if any
+	Value       Kind     // KindNone for boolean flags
+}
+
+// Command describes a command, its flags, and its subcommands.
+type Command struct {
+	Name        string
+	Description string
+	Flags       []Flag
+	Subcommands []Command
+	Args        Kind // Positional arguments
+	Hidden      bool // Not offered as a candidate (e.g., __complete)
+}
+
+// Sources provide the dynamic candidates for each Kind. Nil sources offer nothing.
+type Sources struct {
+	Profiles func() []string
+	Packages func(prefix string) []string
+	Projects func(prefix string) []string
+}
+
+// Complete returns the candidates for the last element of args, given the words before it.
+// args excludes the program name; an empty last element completes a new word. An empty
+// result lets the shell fall back to file completion.
+func Complete(root *Command, args []string, sources Sources) []string {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	words, cur := args[:len(args)-1], args[len(args)-1]
+
+	cmd := root
+	var pending *Flag // Flag whose value is the next word
+	positional := 0
+	for _, word := range words {
+		if pending != nil {
+			pending = nil
+			continue
+		}
+		if word == "--" {
+			positional++
+			continue
+		}
+		if strings.HasPrefix(word, "-") {
+			name, _, hasValue := strings.Cut(strings.TrimLeft(word, "-"), "=")
+			if f := cmd.flag(name); f != nil && f.takesValue() && !hasValue {
+				pending = f
+			}
+			continue
+		}
+		if positional == 0 {
+			if sub := cmd.subcommand(word); sub != nil {
+				cmd = sub
+				continue
+			}
+		}
+		positional++
+	}
+
+	if pending != nil {
+		return filter(pending.candidates(sources, cur), cur)
+	}
+
+	if strings.HasPrefix(cur, "-") {
+		// --flag=value completes the value
+		if name, value, ok := strings.Cut(strings.TrimLeft(cur, "-"), "="); ok {
+			f := cmd.flag(name)
+			if f == nil || !f.takesValue() {
+				return nil
+			}
+			prefix := cur[:len(cur)-len(value)]
+			var out []string
+			for _, candidate := range filter(f.candidates(sources, value), value) {
+				out = append(out, prefix+candidate)
+			}
+			return out
+		}
+
+		var flags []string
+		for _, f := range cmd.Flags {
+			flags = append(flags, "--"+f.Name)
+		}
+		return filter(flags, cur)
+	}
+
+	var candidates []string
+	if positional == 0 {
+		for _, sub := range cmd.Subcommands {
+			if !sub.Hidden {
+				candidates = append(candidates, sub.Name)
+			}
+		}
+	}
+	candidates = append(candidates, kindCandidates(cmd.Args, sources, cur)...)
+	return filter(candidates, cur)
+}
+
+// flag finds a flag by name.
+func (c *Command) flag(name string) *Flag {
+	for i := range c.Flags {
+		if c.Flags[i].Name == name {
+			return &c.Flags[i]
+		}
+	}
+	return nil
+}
+
+// subcommand finds a subcommand by name.
+func (c *Command) subcommand(name string) *Command {
+	for i := range c.Subcommands {
+		if c.Subcommands[i].Name == name {
+			return &c.Subcommands[i]
+		}
+	}
+	return nil
+}
+
+// takesValue reports whether the flag consumes the next word.
+func (f *Flag) takesValue() bool {
+	return f.Value != KindNone || len(f.Values) > 0
+}
+
+// candidates returns the values the flag accepts.
+func (f *Flag) candidates(sources Sources, prefix string) []string {
+	if len(f.Values) > 0 {
+		return f.Values
+	}
+	return kindCandidates(f.Value, sources, prefix)
+}
+
+// kindCandidates returns dynamic candidates for a kind.
+func kindCandidates(kind Kind, sources Sources, prefix string) []string {
+	switch kind {
+	case KindProfile:
+		if sources.Profiles != nil {
+			return sources.Profiles()
+		}
+	case KindPackage:
+		if sources.Packages != nil {
+			return sources.Packages(prefix)
+		}
+	case KindProject:
+		if sources.Projects != nil {
+			return sources.Projects(prefix)
+		}
+	}
+	return nil
+}
+
+// filter returns the sorted, de-duplicated candidates starting with prefix.
+func filter(candidates []string, prefix string) []string {
+	var out []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			out = append(out, candidate)
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
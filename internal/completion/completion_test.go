@@ -0,0 +1,148 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testTree is a small command tree exercising every completion path.
+func testTree() *Command {
+	return &Command{
+		Name: "app",
+		Flags: []Flag{
+			{Name: "verbose"},
+			{Name: "log-level", Values: []string{"debug", "info"}},
+			{Name: "profile", Value: KindProfile},
+			{Name: "config", Value: KindFile},
+		},
+		Subcommands: []Command{
+			{
+				Name:  "add",
+				Args:  KindPackage,
+				Flags: []Flag{{Name: "project", Value: KindProject}, {Name: "prerelease"}},
+			},
+			{Name: "config", Subcommands: []Command{{Name: "schema"}, {Name: "show"}}},
+			{Name: "__complete", Hidden: true},
+		},
+	}
+}
+
+// TestComplete tests candidates for subcommands, flags, and flag values
+func TestComplete(t *testing.T) {
+	sources := Sources{
+		Profiles: func() []string { return []string{"work", "home"} },
+		Packages: func(string) []string { return []string{"Newtonsoft.Json", "NUnit", "Serilog"} },
+		Projects: func(string) []string { return []string{"src/App/App.csproj", "App.sln"} },
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "no args", args: nil, want: []string{"add", "config"}},
+		{name: "subcommand prefix", args: []string{"c"}, want: []string{"config"}},
+		{name: "flags", args: []string{"--"}, want: []string{"--config", "--log-level", "--profile", "--verbose"}},
+		{name: "flag prefix", args: []string{"--pro"}, want: []string{"--profile"}},
+		{name: "fixed flag values", args: []string{"--log-level", ""}, want: []string{"debug", "info"}},
+		{name: "inline flag value", args: []string{"--log-level=i"}, want: []string{"--log-level=info"}},
+		{name: "dynamic flag values", args: []string{"--profile", "w"}, want: []string{"work"}},
+		{name: "file flag falls back to shell", args: []string{"--config", ""}, want: nil},
+		{name: "flag value does not select subcommand", args: []string{"--profile", "add", ""}, want: []string{"add", "config"}},
+		{name: "nested subcommands", args: []string{"config", ""}, want: []string{"schema", "show"}},
+		{name: "subcommand flags", args: []string{"add", "--p"}, want: []string{"--prerelease", "--project"}},
+		{name: "package IDs", args: []string{"add", "N"}, want: []string{"NUnit", "Newtonsoft.Json"}},
+		{name: "project paths", args: []string{"add", "--project", "src/"}, want: []string{"src/App/App.csproj"}},
+		{name: "boolean flag before positional", args: []string{"add", "--prerelease", "S"}, want: []string{"Serilog"}},
+		{name: "unknown flag", args: []string{"--nope="}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Complete(testTree(), tt.args, sources)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Complete(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPackageIDs tests completion from the global packages folder
+func TestPackageIDs(t *testing.T) {
+	dir := t.TempDir()
+	for _, id := range []string{"newtonsoft.json", "nunit", "serilog"} {
+		if err := os.MkdirAll(filepath.Join(dir, id, "1.0.0"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := PackageIDs(dir, "N")
+	slices.Sort(got)
+	if want := []string{"Newtonsoft.json", "Nunit"}; !slices.Equal(got, want) {
+		t.Errorf("PackageIDs(N) = %q, want %q", got, want)
+	}
+	if got := PackageIDs(filepath.Join(dir, "missing"), ""); got != nil {
+		t.Errorf("PackageIDs(missing dir) = %q, want nil", got)
+	}
+}
+
+// TestProjectPaths tests the workspace scan for projects and solutions
+func TestProjectPaths(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{
+		"App.sln",
+		"src/App/App.csproj",
+		"src/Lib/Lib.fsproj",
+		"src/App/bin/Debug/Copy.csproj",
+		"node_modules/pkg/Other.csproj",
+		"src/App/Program.cs",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := ProjectPaths(root, filepath.Join(root, "src"))
+	slices.Sort(got)
+	want := []string{"../App.sln", "App/App.csproj", "Lib/Lib.fsproj"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ProjectPaths() = %q, want %q", got, want)
+	}
+}
+
+// TestScript tests that each shell script calls back into the program
+func TestScript(t *testing.T) {
+	for _, shell := range Shells {
+		t.Run(shell, func(t *testing.T) {
+			script, err := Script(shell, "lazy-nuget")
+			if err != nil {
+				t.Fatalf("Script(%s) error = %v", shell, err)
+			}
+			if !strings.Contains(script, "lazy-nuget "+CompleteCommand) && !strings.Contains(script, "'lazy-nuget' "+CompleteCommand) {
+				t.Errorf("Script(%s) does not call %s:\n%s", shell, CompleteCommand, script)
+			}
+			if strings.Contains(script, "{{") {
+				t.Errorf("Script(%s) has unreplaced placeholders:\n%s", shell, script)
+			}
+		})
+	}
+
+	if _, err := Script("tcsh", "lazynuget"); err == nil {
+		t.Error("Script(tcsh) should fail")
+	}
+}
+
+// TestNormalizeArgs tests the empty-word placeholder used by PowerShell
+func TestNormalizeArgs(t *testing.T) {
+	got := NormalizeArgs([]string{"config", `""`})
+	if want := []string{"config", ""}; !slices.Equal(got, want) {
+		t.Errorf("NormalizeArgs() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,123 @@
+package completion
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CompleteCommand is the hidden subcommand the scripts call for candidates.
+const CompleteCommand = "__complete"
+
+// Shells lists the shells Script supports.
+var Shells = []string{"bash", "zsh", "fish", "powershell"}
+
+// Script returns the completion script for shell. program is the command being completed.
+func Script(shell, program string) (string, error) {
+	var template string
+	switch shell {
+	case "bash":
+		template = bashScript
+	case "zsh":
+		template = zshScript
+	case "fish":
+		template = fishScript
+	case "powershell":
+		template = powershellScript
+	default:
+		return "", fmt.Errorf("unsupported shell %q (supported: %s)", shell, strings.Join(Shells, ", "))
+	}
+
+	// Shell function names cannot contain dashes
+	funcName := strings.ReplaceAll(program, "-", "_")
+	return strings.NewReplacer("{{program}}", program, "{{func}}", funcName, "{{complete}}", CompleteCommand).Replace(template), nil
+}
+
+const bashScript = `# bash completion for {{program}}
+# Install: {{program}} completion bash > /etc/bash_completion.d/{{program}}
+#      or: echo 'source <({{program}} completion bash)' >> ~/.bashrc
+
+_{{func}}() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local IFS=$'\n'
+    local candidates
+    candidates=($({{program}} {{complete}} "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+    if [ ${#candidates[@]} -eq 0 ]; then
+        COMPREPLY=($(compgen -f -- "$cur"))
+    else
+        COMPREPLY=("${candidates[@]}")
+    fi
+}
+
+complete -o filenames -F _{{func}} {{program}}
+`
+
+const zshScript = `#compdef {{program}}
+# zsh completion for {{program}}
+# Install: {{program}} completion zsh > "${fpath[1]}/_{{program}}"
+#      or: echo 'source <({{program}} completion zsh)' >> ~/.zshrc
+
+_{{func}}() {
+    local -a candidates
+    candidates=("${(@f)$({{program}} {{complete}} "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    if (( ${#candidates} == 0 )) || [[ -z "${candidates[1]}" ]]; then
+        _files
+    else
+        compadd -- "${candidates[@]}"
+    fi
+}
+
+if [[ "${zsh_eval_context[-1]}" == loadautofunc ]]; then
+    _{{func}} "$@"
+else
+    compdef _{{func}} {{program}}
+fi
+`
+
+const fishScript = `# fish completion for {{program}}
+# Install: {{program}} completion fish > ~/.config/fish/completions/{{program}}.fish
+
+function __{{func}}_complete
+    set -l tokens (commandline -opc) (commandline -ct)
+    set -l candidates ({{program}} {{complete}} $tokens[2..-1] 2>/dev/null)
+    if test (count $candidates) -eq 0
+        __fish_complete_path (commandline -ct)
+    else
+        printf '%s\n' $candidates
+    end
+end
+
+complete -c {{program}} -f -a '(__{{func}}_complete)'
+`
+
+const powershellScript = `# PowerShell completion for {{program}}
+# Install: {{program}} completion powershell >> $PROFILE
+
+Register-ArgumentCompleter -Native -CommandName '{{program}}' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $words = @($commandAst.CommandElements |
+        Where-Object { $_.Extent.EndOffset -le $cursorPosition } |
+        Select-Object -Skip 1 |
+        ForEach-Object { $_.ToString() })
+    if ($wordToComplete -eq '') {
+        # Older PowerShell versions drop empty native arguments
+        $words += '""'
+    }
+
+    $candidates = @(& '{{program}}' {{complete}} @words 2>$null)
+    if ($candidates.Count -eq 0) {
+        return # Fall back to path completion
+    }
+    $candidates | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`
+
+// NormalizeArgs undoes shell quirks in the words passed to the complete command.
+func NormalizeArgs(args []string) []string {
+	if n := len(args); n > 0 && args[n-1] == `""` {
+		args = append(args[:n-1:n-1], "")
+	}
+	return args
+}
@@ -0,0 +1,85 @@
+package completion
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/project"
+)
+
+// maxProjects bounds the workspace scan so completion stays fast in large monorepos.
+const maxProjects = 1000
+
+// projectExtensions are the files offered for KindProject.
+var projectExtensions = []string{".csproj", ".fsproj", ".vbproj", ".sln", ".slnx", ".slnf"}
+
+// GlobalPackagesDir returns NuGet's global packages folder.
+func GlobalPackagesDir() string {
+	return nuget.GlobalPackagesDir()
+}
+
+// PackageIDs returns the IDs of packages in the global packages folder that start with
+// prefix (case-insensitively). The folder stores one lowercase directory per package ID.
+func PackageIDs(packagesDir, prefix string) []string {
+	if packagesDir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(packagesDir)
+	if err != nil {
+		return nil
+	}
+
+	lowerPrefix := strings.ToLower(prefix)
+	var ids []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() && strings.HasPrefix(name, lowerPrefix) {
+			// Keep the user's casing for the part already typed so the shell accepts the match
+			ids = append(ids, prefix+name[len(prefix):])
+		}
+	}
+	return ids
+}
+
+// ProjectPaths scans root for project and solution files and returns them relative to
+// workDir, skipping build output and dependency folders.
+func ProjectPaths(root, workDir string) []string {
+	var paths []string
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && project.SkippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isProjectFile(d.Name()) {
+			return nil
+		}
+		if rel, relErr := filepath.Rel(workDir, path); relErr == nil {
+			path = rel
+		}
+		paths = append(paths, filepath.ToSlash(path))
+		if len(paths) >= maxProjects {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return paths
+}
+
+// isProjectFile reports whether name is a project or solution file.
+func isProjectFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, projectExt := range projectExtensions {
+		if ext == projectExt {
+			return true
+		}
+	}
+	return false
+}
@@ -2,10 +2,16 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/metrics"
+	"github.com/willibrandon/lazynuget/internal/platform"
 )
 
 // ConfigLoader is the primary interface for loading and managing application configuration.
@@ -21,8 +27,9 @@ type ConfigLoader interface {
 	// Sources are merged in order of increasing precedence:
 	//   1. Hardcoded defaults (lowest precedence)
 	//   2. User config file (YAML/TOML)
-	//   3. Environment variables (LAZYNUGET_* prefix)
-	//   4. CLI flags (highest precedence)
+	//   3. Project overlay (.lazynuget.yml in the repository, see FindProjectConfig)
+	//   4. Environment variables (LAZYNUGET_* prefix)
+	//   5. CLI flags (highest precedence)
 	//
 	// Returns:
 	//   - *Config: The merged and validated configuration
@@ -74,11 +81,19 @@ type ConfigLoader interface {
 // LoadOptions configures the behavior of the config loading process.
 // See: specs/002-config-management/contracts/config_loader.md
 type LoadOptions struct {
-	Logger         Logger
-	ConfigFilePath string
-	EnvVarPrefix   string
-	CLIFlags       CLIFlags
-	StrictMode     bool
+	Logger          Logger
+	TrustProject    TrustFunc // Gates the project overlay; nil applies it without asking
+	ConfigFilePath  string
+	EnvVarPrefix    string
+	WorkingDir      string // Where to start searching for a project overlay (empty = current directory)
+	Profile         string // Named profile to apply (--profile); empty falls back to <prefix>PROFILE
+	CLIFlags        CLIFlags
+	StrictMode      bool
+	NoProjectConfig bool // Skip the repository-level .lazynuget.yml overlay
+
+	// DetectBackground asks the terminal for its background when the theme is auto, on
+	// every load and reload; nil (tests, commands that draw nothing) means the dark theme
+	DetectBackground func() platform.Background
 }
 
 // CLIFlags contains command-line flag values that override other config sources.
@@ -126,9 +141,26 @@ func NewLoader() ConfigLoader {
 // Loads configuration from defaults, file, env vars, and CLI flags with proper precedence.
 // See: T027, T031, T050, FR-001, FR-002
 func (cl *configLoader) Load(ctx context.Context, opts LoadOptions) (*Config, error) {
+	defer metrics.TimeOperation("config-load")()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// Start with defaults (lowest precedence)
 	cfg := GetDefaultConfig()
 
+	// Unknown keys found in config files (reported with validation results)
+	var unknownKeys []ValidationError
+
+	// Named profile selected with --profile or <prefix>PROFILE
+	profile := selectedProfile(opts)
+	profileFound := false
+	var profilesAvailable []string
+
+	// Decrypted values, kept so the logging pipeline can redact them
+	var secrets []string
+
 	// Determine config file path
 	configFilePath := opts.ConfigFilePath
 	if configFilePath == "" {
@@ -165,8 +197,12 @@ func (cl *configLoader) Load(ctx context.Context, opts LoadOptions) (*Config, er
 				return nil, err
 			}
 
-			// Parse config file
-			fileCfg, err := parseConfigFile(configFilePath)
+			// Parse the config file and the fragments it includes
+			files, err := configFiles(configFilePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load config file %s: %w", configFilePath, err)
+			}
+			fileCfg, err := decodeConfigFiles(files)
 			if err != nil {
 				// Syntax errors are blocking (FR-010)
 				return nil, fmt.Errorf("failed to load config file %s: %w", configFilePath, err)
@@ -178,41 +214,53 @@ func (cl *configLoader) Load(ctx context.Context, opts LoadOptions) (*Config, er
 			kd := NewKeyDerivation()
 			encryptor := NewEncryptor(keychain, kd)
 
-			// Attempt to decrypt any encrypted values in the config file
-			// Path already validated by parseConfigFile above
-			fileData, err := os.ReadFile(filepath.Clean(configFilePath))
-			if err == nil {
-				_, encryptedFields, scanErr := parseYAMLWithEncryption(fileData)
-				if scanErr == nil && len(encryptedFields) > 0 {
-					// Attempt to decrypt each encrypted field
-					for fieldPath, encryptedValue := range encryptedFields {
-						_, decryptErr := encryptor.Decrypt(ctx, encryptedValue)
-						if decryptErr != nil {
-							// FR-018: Log warning but continue (fall back to default)
-							if opts.Logger != nil {
-								opts.Logger.Warn("Failed to decrypt field %s: %v (falling back to default)", fieldPath, decryptErr)
-							}
-							// Don't block loading - validation will handle fallback to default
-						} else {
-							// Successfully decrypted - apply to config
-							// For now, we log it but don't apply (since we'd need field mapping)
-							if opts.Logger != nil {
-								opts.Logger.Debug("Successfully decrypted field: %s", fieldPath)
-							}
-							// TODO: Apply decrypted value to cfg using reflection or field mapping
-							// For Phase 8, we'll handle this in integration tests with known fields
-						}
+			// Find encrypted values in each file
+			// (YAML !encrypted tags or "enc:" strings in either format)
+			// Paths already validated by decodeConfigFiles above
+			encryptedFields := make(map[string]*EncryptedValue)
+			for _, file := range files {
+				if keys, keysErr := topLevelKeys(file); keysErr == nil {
+					dropOverridden(encryptedFields, keys)
+				}
+				if fileData, readErr := os.ReadFile(filepath.Clean(file)); readErr == nil {
+					if fields, scanErr := scanConfigFileForEncryption(file, fileData); scanErr == nil {
+						maps.Copy(encryptedFields, fields)
 					}
 				}
 			}
+			baseFields, profileFields := splitProfileFields(encryptedFields, profile)
+			secrets = append(secrets, decryptConfigFields(ctx, fileCfg, baseFields, encryptor, opts.Logger)...)
+
+			// Apply the selected profile over the base settings; a fragment
+			// may define profiles too
+			if profile != "" {
+				for _, file := range files {
+					found, available, profileErr := applyProfile(fileCfg, file, profile)
+					if profileErr != nil {
+						return nil, fmt.Errorf("failed to load config file %s: %w", file, profileErr)
+					}
+					profileFound = profileFound || found
+					profilesAvailable = append(profilesAvailable, available...)
+				}
+				if profileFound {
+					secrets = append(secrets, decryptConfigFields(ctx, fileCfg, profileFields, encryptor, opts.Logger)...)
+				}
+			}
 
 			if opts.Logger != nil {
 				opts.Logger.Info("Loaded configuration from file: %s", configFilePath)
 			}
 
+			for _, file := range files {
+				if keys, err := findUnknownKeys(file); err == nil {
+					unknownKeys = append(unknownKeys, unknownKeyErrors(file, keys)...)
+				}
+			}
+
 			// Merge file config with defaults
 			cfg = mergeConfigs(cfg, fileCfg)
 			cfg.LoadedFrom = configFilePath
+			cfg.IncludedFiles = files[:len(files)-1]
 		} else if opts.ConfigFilePath != "" {
 			// If user explicitly specified a config file (via --config), it must exist
 			return nil, fmt.Errorf("specified config file not found: %s", configFilePath)
@@ -229,6 +277,57 @@ func (cl *configLoader) Load(ctx context.Context, opts LoadOptions) (*Config, er
 		}
 	}
 
+	// Apply repository-level overlay (between user file and env vars in precedence)
+	if !opts.NoProjectConfig {
+		workingDir := opts.WorkingDir
+		if workingDir == "" {
+			workingDir, _ = os.Getwd()
+		}
+		if workingDir != "" {
+			projectPath, err := FindProjectConfig(workingDir)
+			if err != nil {
+				if opts.Logger != nil {
+					opts.Logger.Warn("Failed to search for project config: %v", err)
+				}
+			} else if projectData, ok := cl.readTrustedProject(opts, projectPath); ok {
+				// Syntax errors are blocking, same as the user config file (FR-010)
+				if err := applyProjectConfig(cfg, projectPath, projectData); err != nil {
+					return nil, err
+				}
+				// The overlay may define the selected profile too (e.g., a shared "ci" profile)
+				if profile != "" {
+					found, available, err := applyProfileData(cfg, projectPath, projectData, profile)
+					if err != nil {
+						return nil, fmt.Errorf("failed to load project config %s: %w", projectPath, err)
+					}
+					profileFound = profileFound || found
+					profilesAvailable = append(profilesAvailable, available...)
+				}
+				if keys, err := unknownKeysIn(projectPath, projectData); err == nil {
+					unknownKeys = append(unknownKeys, unknownKeyErrors(projectPath, keys)...)
+				}
+				if opts.Logger != nil {
+					opts.Logger.Info("Applied project configuration overlay: %s", projectPath)
+				}
+			}
+		}
+	}
+
+	// A profile that no config file defines is a blocking error, not a silent no-op
+	if profile != "" {
+		if !profileFound {
+			slices.Sort(profilesAvailable)
+			return nil, profileNotFoundError(profile, slices.Compact(profilesAvailable))
+		}
+		cfg.Profile = profile
+		if opts.Logger != nil {
+			opts.Logger.Info("Applied configuration profile: %s", profile)
+		}
+	}
+
+	// Expand ${ENV_VAR} references in file-provided values
+	interpolationErrors := interpolateConfig(cfg, os.LookupEnv, opts.StrictMode)
+
 	// Apply environment variable overrides (Phase 5, FR-050, FR-051, FR-052)
 	if opts.EnvVarPrefix != "" {
 		envVars := parseEnvVars(opts.EnvVarPrefix)
@@ -259,46 +358,92 @@ func (cl *configLoader) Load(ctx context.Context, opts LoadOptions) (*Config, er
 		cfg.LogLevel = opts.CLIFlags.LogLevel
 	}
 
-	// Note: NonInteractive and NoColor flags are consumed by bootstrap/GUI layers
-	// They are passed through LoadOptions but don't affect the Config struct
+	// Note: NonInteractive and NoColor flags are consumed by bootstrap/GUI layers (NoColor
+	// selects the monochrome theme mode); they don't affect the Config struct
+
+	// Decryption falls back to defaults on failure; don't return a config that only
+	// lacks its secrets because the load was cancelled
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// Validate the final merged config
-	validationErrors := cl.validator.validate(cfg)
+	validationErrors := append(cl.validator.validate(ctx, cfg), unknownKeys...)
+	applyTheme(cfg, opts.DetectBackground)
+	validationErrors = append(validationErrors, interpolationErrors...)
 
-	// Handle validation errors based on StrictMode
-	hasBlockingErrors := false
+	// Log validation results; warnings have already fallen back to defaults
 	for _, ve := range validationErrors {
 		if ve.Severity == "error" {
-			hasBlockingErrors = true
 			if opts.Logger != nil {
 				opts.Logger.Error("Config validation error: %s", ve.Error())
 			}
 		} else if ve.Severity == "warning" {
 			if opts.Logger != nil {
-				opts.Logger.Warn("Config validation warning: %s (using default: %v)", ve.Error(), ve.DefaultUsed)
+				opts.Logger.Warn("Config validation warning: %s", ve.Error())
 			}
-			// Apply fallback to default for this setting
-			// This is handled by the validator returning the appropriate default
 		}
 	}
 
-	// In strict mode, blocking errors prevent startup
-	if opts.StrictMode && hasBlockingErrors {
-		return nil, fmt.Errorf("config validation failed with %d error(s)", len(validationErrors))
+	// In strict mode every finding is blocking, including warnings that would otherwise
+	// fall back to defaults (out-of-range values, unknown keys, keybinding conflicts).
+	// This lets CI pipelines fail on config drift.
+	if opts.StrictMode && len(validationErrors) > 0 {
+		return nil, strictModeError(validationErrors)
 	}
 
+	cfg.secrets = secrets
 	return cfg, nil
 }
 
+// strictModeError summarizes validation findings that failed a strict-mode load.
+func strictModeError(validationErrors []ValidationError) error {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("config validation failed with %d error(s) (strict mode):", len(validationErrors)))
+	for _, ve := range validationErrors {
+		sb.WriteString("\n  • " + ve.Key + ": " + ve.Constraint)
+		if ve.SuggestedFix != "" {
+			sb.WriteString(" (" + ve.SuggestedFix + ")")
+		}
+	}
+	return errors.New(sb.String())
+}
+
+// readTrustedProject reads the project overlay at path and reports whether it may be
+// applied. The file is read once and the trust decision covers exactly the returned
+// bytes, so an overlay swapped after the check is never applied as trusted.
+// Untrusted or unreadable overlays are skipped with a warning rather than failing startup.
+func (cl *configLoader) readTrustedProject(opts LoadOptions, path string) ([]byte, bool) {
+	if path == "" {
+		return nil, false
+	}
+
+	data, err := readConfigFile(path)
+	if err != nil {
+		if opts.Logger != nil {
+			opts.Logger.Warn("Skipping project config: %v", err)
+		}
+		return nil, false
+	}
+
+	if opts.TrustProject != nil && !opts.TrustProject(path, FingerprintData(data)) {
+		if opts.Logger != nil {
+			opts.Logger.Warn("Skipping untrusted project config: %s", path)
+		}
+		return nil, false
+	}
+	return data, true
+}
+
 // Validate implements ConfigLoader.Validate()
 // See: T030, FR-056
-func (cl *configLoader) Validate(_ context.Context, cfg *Config) ([]ValidationError, error) {
+func (cl *configLoader) Validate(ctx context.Context, cfg *Config) ([]ValidationError, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("config is nil")
 	}
 
 	// Run validation
-	validationErrors := cl.validator.validate(cfg)
+	validationErrors := cl.validator.validate(ctx, cfg)
 
 	return validationErrors, nil
 }
@@ -323,6 +468,9 @@ func (cl *configLoader) PrintConfig(cfg *Config) string {
 	} else {
 		sb.WriteString("Loaded from: defaults only\n")
 	}
+	if cfg.Profile != "" {
+		sb.WriteString(fmt.Sprintf("Profile: %s\n", cfg.Profile))
+	}
 	sb.WriteString(fmt.Sprintf("Loaded at: %s\n\n", cfg.LoadedAt.Format("2006-01-02 15:04:05")))
 
 	// UI Settings
@@ -331,7 +479,10 @@ func (cl *configLoader) PrintConfig(cfg *Config) string {
 	sb.WriteString(fmt.Sprintf("compactMode:      %v\n", cfg.CompactMode))
 	sb.WriteString(fmt.Sprintf("showHints:        %v\n", cfg.ShowHints))
 	sb.WriteString(fmt.Sprintf("showLineNumbers:  %v\n", cfg.ShowLineNumbers))
-	sb.WriteString(fmt.Sprintf("dateFormat:       %s\n\n", cfg.DateFormat))
+	sb.WriteString(fmt.Sprintf("dateFormat:       %s\n", cfg.DateFormat))
+	sb.WriteString(fmt.Sprintf("staleAfterYears:  %d\n", cfg.StaleAfterYears))
+	sb.WriteString(fmt.Sprintf("packageIcons:     %s\n", cfg.PackageIcons))
+	sb.WriteString(fmt.Sprintf("editor:           %s\n\n", cfg.Editor))
 
 	// Color Scheme
 	sb.WriteString("--- Color Scheme ---\n")
@@ -360,8 +511,11 @@ func (cl *configLoader) PrintConfig(cfg *Config) string {
 	// Performance
 	sb.WriteString("--- Performance ---\n")
 	sb.WriteString(fmt.Sprintf("maxConcurrentOps: %d\n", cfg.MaxConcurrentOps))
+	sb.WriteString(fmt.Sprintf("renderFps:        %d\n", cfg.RenderFPS))
 	sb.WriteString(fmt.Sprintf("cacheSize:        %d MB\n", cfg.CacheSize))
-	sb.WriteString(fmt.Sprintf("refreshInterval:  %s\n\n", cfg.RefreshInterval))
+	sb.WriteString(fmt.Sprintf("refreshInterval:  %s\n", cfg.RefreshInterval))
+	sb.WriteString(fmt.Sprintf("advisoryCacheTTL: %s\n", cfg.AdvisoryCacheTTL))
+	sb.WriteString(fmt.Sprintf("prefetchDelay:    %s\n\n", cfg.PrefetchDelay))
 
 	// Timeouts
 	sb.WriteString("--- Timeouts ---\n")
@@ -372,11 +526,16 @@ func (cl *configLoader) PrintConfig(cfg *Config) string {
 	// Dotnet CLI
 	sb.WriteString("--- Dotnet CLI ---\n")
 	sb.WriteString(fmt.Sprintf("dotnetPath:       %s\n", cfg.DotnetPath))
-	sb.WriteString(fmt.Sprintf("dotnetVerbosity:  %s\n\n", cfg.DotnetVerbosity))
+	sb.WriteString(fmt.Sprintf("dotnetVerbosity:  %s\n", cfg.DotnetVerbosity))
+	sb.WriteString(fmt.Sprintf("operationBackend: %s\n", cfg.OperationBackend))
+	sb.WriteString(fmt.Sprintf("restoreMode:      %s\n\n", cfg.RestoreMode))
 
 	// Logging
 	sb.WriteString("--- Logging ---\n")
 	sb.WriteString(fmt.Sprintf("logLevel:         %s\n", cfg.LogLevel))
+	for _, module := range slices.Sorted(maps.Keys(cfg.LogLevels)) {
+		sb.WriteString(fmt.Sprintf("  %-16s%s\n", module+":", cfg.LogLevels[module]))
+	}
 	sb.WriteString(fmt.Sprintf("logDir:           %s\n", cfg.LogDir))
 	sb.WriteString(fmt.Sprintf("logFormat:        %s\n", cfg.LogFormat))
 	sb.WriteString(fmt.Sprintf("maxSize:          %d MB\n", cfg.LogRotation.MaxSize))
@@ -388,5 +547,73 @@ func (cl *configLoader) PrintConfig(cfg *Config) string {
 	sb.WriteString("--- Hot Reload ---\n")
 	sb.WriteString(fmt.Sprintf("hotReload:        %v\n", cfg.HotReload))
 
+	// Updates
+	sb.WriteString("\n--- Updates ---\n")
+	sb.WriteString(fmt.Sprintf("updateCheck:      %v\n", cfg.UpdateCheck))
+
+	// Hooks
+	sb.WriteString("\n--- Hooks ---\n")
+	for _, group := range []struct {
+		name  string
+		hooks []Hook
+	}{
+		{"preInstall", cfg.Hooks.PreInstall},
+		{"postUpdate", cfg.Hooks.PostUpdate},
+		{"postRestore", cfg.Hooks.PostRestore},
+	} {
+		for _, hook := range group.hooks {
+			sb.WriteString(fmt.Sprintf("%-17s %s\n", group.name+":", hook.Command))
+		}
+	}
+
+	// Custom commands
+	sb.WriteString("\n--- Custom Commands ---\n")
+	for _, command := range cfg.CustomCommands {
+		sb.WriteString(fmt.Sprintf("%-17s %s\n", command.Name+":", command.Command))
+	}
+
+	// Saved filters
+	sb.WriteString("\n--- Saved Filters ---\n")
+	for _, filter := range cfg.SavedFilters {
+		sb.WriteString(fmt.Sprintf("%-17s %s\n", filter.Name+":", filter.Query))
+	}
+
+	// Plugins
+	sb.WriteString("\n--- Plugins ---\n")
+	for _, plugin := range cfg.Plugins {
+		sb.WriteString(fmt.Sprintf("%-17s %s\n", plugin.Name+":", strings.Join(append([]string{plugin.Command}, plugin.Args...), " ")))
+	}
+
+	// Sandbox
+	sb.WriteString("\n--- Sandbox ---\n")
+	sb.WriteString(fmt.Sprintf("enabled:          %v\n", cfg.Sandbox.Enabled))
+	sb.WriteString(fmt.Sprintf("allowNetwork:     %v\n", cfg.Sandbox.AllowNetwork))
+	sb.WriteString(fmt.Sprintf("writablePaths:    %s\n", strings.Join(cfg.Sandbox.WritablePaths, ", ")))
+
+	// Telemetry
+	sb.WriteString("\n--- Telemetry ---\n")
+	sb.WriteString(fmt.Sprintf("endpoint:         %s\n", cfg.Telemetry.Endpoint))
+
+	// Team Policy
+	sb.WriteString("\n--- Team Policy ---\n")
+	if cfg.ProjectConfigPath != "" {
+		sb.WriteString(fmt.Sprintf("projectConfig:    %s\n", cfg.ProjectConfigPath))
+	}
+	sb.WriteString(fmt.Sprintf("licenses allowed: %s\n", strings.Join(cfg.LicensePolicy.Allowed, ", ")))
+	sb.WriteString(fmt.Sprintf("licenses denied:  %s\n", strings.Join(cfg.LicensePolicy.Denied, ", ")))
+	for _, pin := range cfg.PinnedPackages {
+		sb.WriteString(fmt.Sprintf("pinned:           %s %s\n", pin.Package, pin.Version))
+	}
+	for _, feed := range cfg.Feeds {
+		if feed.Timeout > 0 {
+			sb.WriteString(fmt.Sprintf("feed:             %s (%s, timeout %s)\n", feed.Name, feed.URL, feed.Timeout))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("feed:             %s (%s)\n", feed.Name, feed.URL))
+	}
+	for _, fallback := range cfg.FeedFallbacks {
+		sb.WriteString(fmt.Sprintf("feed fallback:    %s -> %s\n", fallback.Packages, strings.Join(fallback.Feeds, ", ")))
+	}
+
 	return sb.String()
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -61,7 +62,7 @@ func TestConfigLoaderValidate(t *testing.T) {
 			name:          "valid config",
 			cfg:           GetDefaultConfig(),
 			wantErrCount:  0,
-			wantWarnCount: 1, // RefreshInterval=0
+			wantWarnCount: 0,
 		},
 		{
 			name: "invalid log level",
@@ -71,7 +72,7 @@ func TestConfigLoaderValidate(t *testing.T) {
 				return &cfg
 			}(),
 			wantErrCount:  0,
-			wantWarnCount: 2, // RefreshInterval + LogLevel
+			wantWarnCount: 1, // LogLevel
 		},
 		{
 			name: "multiple errors",
@@ -83,7 +84,7 @@ func TestConfigLoaderValidate(t *testing.T) {
 				return &cfg
 			}(),
 			wantErrCount:  0,
-			wantWarnCount: 4, // RefreshInterval + LogLevel + Theme + MaxConcurrentOps
+			wantWarnCount: 3, // LogLevel + Theme + MaxConcurrentOps
 		},
 		{
 			name:       "nil config",
@@ -456,7 +457,7 @@ maxConcurrentOps: 999
 		{
 			name:       "strict mode enabled rejects invalid config",
 			strictMode: true,
-			wantErr:    false, // Validation warnings are not blocking errors
+			wantErr:    true, // Strict mode promotes validation warnings to errors
 		},
 	}
 
@@ -504,3 +505,87 @@ func TestConfigLoaderLoadFromDefaultLocation(t *testing.T) {
 		t.Errorf("Expected default LogLevel=info, got %s", cfg.LogLevel)
 	}
 }
+
+// TestRequestTimeout tests choosing the timeout of the feed a request goes to
+func TestRequestTimeout(t *testing.T) {
+	cfg, err := parseYAML([]byte(`
+timeouts:
+  networkRequest: 30s
+feeds:
+  - name: nuget.org
+    url: https://api.nuget.org/v3/index.json
+    timeout: 15s
+  - name: corp
+    url: https://nuget.corp.example.com/v3/index.json
+    timeout: 2m
+  - name: corp-tools
+    url: https://nuget.corp.example.com/tools/v3/index.json
+    timeout: 90
+  - name: mirror
+    url: https://mirror.example.com/v3/index.json
+`))
+	if err != nil {
+		t.Fatalf("parseYAML() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		url  string
+		want time.Duration
+	}{
+		{"https://api.nuget.org/v3-flatcontainer/serilog/index.json", 15 * time.Second},
+		{"https://azuresearch-usnc.nuget.org/query?q=serilog", 15 * time.Second},
+		{"https://NuGet.Corp.Example.com/v3/package/contoso.core/index.json", 2 * time.Minute},
+		{"https://nuget.corp.example.com/tools/v3/package/contoso.cli/index.json", 90 * time.Second},
+		{"https://mirror.example.com/v3/index.json", 30 * time.Second},
+		{"https://nuget.org.example.com/v3/index.json", 30 * time.Second},
+		{"://not a url", 30 * time.Second},
+	} {
+		if got := cfg.RequestTimeout(tt.url); got != tt.want {
+			t.Errorf("RequestTimeout(%q) = %s, want %s", tt.url, got, tt.want)
+		}
+	}
+}
+
+// TestFeedCredentials tests choosing the credentials of the feed a request goes to
+func TestFeedCredentials(t *testing.T) {
+	cfg, err := parseYAML([]byte(`
+feeds:
+  - name: nuget.org
+    url: https://api.nuget.org/v3/index.json
+  - name: azure
+    url: https://pkgs.dev.azure.com/contoso/_packaging/internal/nuget/v3/index.json
+    username: contoso
+    password: pat
+  - name: corp
+    url: https://nuget.corp.example.com/v3/index.json
+    apiKey: key
+  - name: corp-old
+    url: https://nuget.corp.example.com/old/v3/index.json
+    apiKey: old-key
+    disabled: true
+`))
+	if err != nil {
+		t.Fatalf("parseYAML() error = %v", err)
+	}
+
+	for _, tt := range []struct {
+		url  string
+		want string // Feed name, "" for none
+	}{
+		{"https://pkgs.dev.azure.com/contoso/_packaging/0a1b2c/nuget/v3/flat2/contoso.core/index.json", "azure"},
+		{"https://NuGet.Corp.Example.com/v3/package/contoso.core/index.json", "corp"},
+		{"https://nuget.corp.example.com/old/v3/index.json", "corp"},
+		{"http://nuget.corp.example.com/v3/index.json", ""},
+		{"https://api.nuget.org/v3-flatcontainer/serilog/index.json", ""},
+		{"https://evil.example.com/?u=https://nuget.corp.example.com/", ""},
+		{"://not a url", ""},
+	} {
+		feed, ok := cfg.FeedCredentials(tt.url)
+		if ok != (tt.want != "") || feed.Name != tt.want {
+			t.Errorf("FeedCredentials(%q) = %q, %v, want %q", tt.url, feed.Name, ok, tt.want)
+		}
+	}
+	if secrets := cfg.Secrets(); !slices.Contains(secrets, "pat") || !slices.Contains(secrets, "key") {
+		t.Errorf("Secrets() = %v, want feed passwords and API keys", secrets)
+	}
+}
@@ -4,6 +4,12 @@ import (
 	"time"
 )
 
+// SupportedConfigVersion is the newest major config schema version this build understands.
+// Config files declare their schema in the top-level version key ("1.0").
+// Policy: settings are only added within a major version; renames and removals bump the major
+// and older majors keep loading with the removed settings ignored.
+const SupportedConfigVersion = 1
+
 // GetDefaultConfig returns a Config with all default values populated.
 // This is the base configuration used when no other sources are available.
 // See: specs/002-config-management/plan.md, FR-001
@@ -32,6 +38,19 @@ func GetDefaultConfig() *Config {
 		ShowHints:       true,
 		ShowLineNumbers: false,
 		DateFormat:      "2006-01-02",
+		StaleAfterYears: 2,
+		PackageIcons:    "auto",
+		Editor:          "", // Empty = $VISUAL, then $EDITOR
+		StatusBar: StatusBarConfig{
+			Format:      "{mode} {keys} {repo} {feeds} {pending} {vulns} {clock}",
+			ClockFormat: "15:04",
+		},
+		Confirm: ConfirmConfig{
+			Add:    "never",
+			Remove: "always",
+			Update: "never",
+			Bulk:   "always",
+		},
 
 		// Keybindings (FR-026 through FR-030)
 		Keybindings:       make(map[string]KeyBinding),
@@ -39,8 +58,11 @@ func GetDefaultConfig() *Config {
 
 		// Performance (FR-031 through FR-034)
 		MaxConcurrentOps: 4,
+		RenderFPS:        30,
 		CacheSize:        50, // MB
 		RefreshInterval:  0,  // Disabled
+		AdvisoryCacheTTL: 24 * time.Hour,
+		PrefetchDelay:    300 * time.Millisecond,
 		Timeouts: Timeouts{
 			NetworkRequest: 30 * time.Second,
 			DotnetCLI:      60 * time.Second,
@@ -51,6 +73,10 @@ func GetDefaultConfig() *Config {
 		DotnetPath:      "", // Empty = auto-detect from PATH
 		DotnetVerbosity: "minimal",
 
+		// Package operations run dotnet add/remove, restoring after every change
+		OperationBackend: "cli",
+		RestoreMode:      "always",
+
 		// Logging (FR-039 through FR-042)
 		LogLevel:  "info",
 		LogDir:    "", // Empty = platform default
@@ -64,5 +90,8 @@ func GetDefaultConfig() *Config {
 
 		// Hot-Reload (FR-043)
 		HotReload: false, // Disabled by default for safety
+
+		// Release notifications contact GitHub, so they are opt-in
+		UpdateCheck: false,
 	}
 }
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// durationType is the reflect.Type of time.Duration config fields.
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// parseConfigDuration parses a duration setting from a config file.
+// Accepts Go duration strings ("30s", "1m30s", "500ms") and bare numbers, which
+// mean seconds ("30" or 30 → 30s, 1.5 → 1.5s).
+func parseConfigDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if math.IsNaN(seconds) || math.IsInf(seconds, 0) || math.Abs(seconds) > math.MaxInt64/float64(time.Second) {
+			return 0, fmt.Errorf("invalid duration %q: out of range", value)
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: use a value such as 30s, 1m30s, or 500ms, or a number of seconds", value)
+	}
+	return d, nil
+}
+
+// normalizeYAMLDurations rewrites every scalar that decodes into a time.Duration field
+// to a canonical Go duration string, so integers mean seconds rather than nanoseconds.
+// Invalid values are reported with their key path and line number.
+func normalizeYAMLDurations(node *yaml.Node, t reflect.Type, path string) error {
+	if node == nil {
+		return nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == durationType {
+		if node.Kind != yaml.ScalarNode {
+			return fmt.Errorf("%s (line %d): expected a duration such as 30s", path, node.Line)
+		}
+		d, err := parseConfigDuration(node.Value)
+		if err != nil {
+			return fmt.Errorf("%s (line %d): %w", path, node.Line, err)
+		}
+		node.Value = d.String()
+		node.Tag = "!!str"
+		return nil
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if err := normalizeYAMLDurations(child, t, path); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := yamlFields(t)
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := node.Content[i].Value
+				if fieldType, ok := fields[key]; ok {
+					if err := normalizeYAMLDurations(node.Content[i+1], fieldType, joinKeyPath(path, key)); err != nil {
+						return err
+					}
+				}
+			}
+		case reflect.Map:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := joinKeyPath(path, node.Content[i].Value)
+				if err := normalizeYAMLDurations(node.Content[i+1], t.Elem(), key); err != nil {
+					return err
+				}
+			}
+		}
+	case yaml.SequenceNode:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, item := range node.Content {
+				if err := normalizeYAMLDurations(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// normalizeTOMLDurations rewrites values in a decoded TOML document that belong to
+// time.Duration fields to canonical Go duration strings (integers mean seconds).
+func normalizeTOMLDurations(doc map[string]any, t reflect.Type, path string) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	fields := tomlFields(t)
+	for key, value := range doc {
+		fieldType, ok := fields[key]
+		if !ok {
+			continue
+		}
+		keyPath := joinKeyPath(path, key)
+
+		normalized, err := normalizeTOMLValue(value, fieldType, keyPath)
+		if err != nil {
+			return err
+		}
+		doc[key] = normalized
+	}
+	return nil
+}
+
+// normalizeTOMLValue normalizes a single TOML value decoded into fieldType.
+func normalizeTOMLValue(value any, fieldType reflect.Type, path string) (any, error) {
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	if fieldType == durationType {
+		var d time.Duration
+		var err error
+		switch v := value.(type) {
+		case string:
+			d, err = parseConfigDuration(v)
+		case int64:
+			d, err = parseConfigDuration(strconv.FormatInt(v, 10))
+		case float64:
+			d, err = parseConfigDuration(strconv.FormatFloat(v, 'f', -1, 64))
+		default:
+			err = fmt.Errorf("expected a duration such as \"30s\", got %v", value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return d.String(), nil
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		switch fieldType.Kind() {
+		case reflect.Struct:
+			return v, normalizeTOMLDurations(v, fieldType, path)
+		case reflect.Map:
+			for key, item := range v {
+				normalized, err := normalizeTOMLValue(item, fieldType.Elem(), joinKeyPath(path, key))
+				if err != nil {
+					return nil, err
+				}
+				v[key] = normalized
+			}
+		}
+	case []map[string]any:
+		if fieldType.Kind() == reflect.Slice {
+			for i, item := range v {
+				if err := normalizeTOMLDurations(item, fieldType.Elem(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return value, nil
+}
+
+// tomlFields maps toml tag names of a struct's exported fields to their types.
+func tomlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
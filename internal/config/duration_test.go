@@ -0,0 +1,124 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseConfigDuration tests accepted duration formats
+func TestParseConfigDuration(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "30s", want: 30 * time.Second},
+		{input: "1m30s", want: 90 * time.Second},
+		{input: "500ms", want: 500 * time.Millisecond},
+		{input: "30", want: 30 * time.Second},
+		{input: "1.5", want: 1500 * time.Millisecond},
+		{input: "0", want: 0},
+		{input: " 2m ", want: 2 * time.Minute},
+		{input: "30 seconds", wantErr: true},
+		{input: "fast", wantErr: true},
+		{input: "", wantErr: true},
+		{input: "1e300", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseConfigDuration(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseConfigDuration(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseConfigDuration(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDurationDecoding tests duration settings decode identically from YAML and TOML
+func TestDurationDecoding(t *testing.T) {
+	tests := []struct {
+		name        string
+		yaml        string
+		toml        string
+		errContains string
+		wantNetwork time.Duration
+		wantRefresh time.Duration
+		wantErr     bool
+	}{
+		{
+			name:        "duration strings",
+			yaml:        "refreshInterval: 5m\ntimeouts:\n  networkRequest: 30s\n",
+			toml:        "refresh_interval = \"5m\"\n[timeouts]\nnetwork_request = \"30s\"\n",
+			wantNetwork: 30 * time.Second,
+			wantRefresh: 5 * time.Minute,
+		},
+		{
+			name:        "integers mean seconds",
+			yaml:        "refreshInterval: 300\ntimeouts:\n  networkRequest: 45\n",
+			toml:        "refresh_interval = 300\n[timeouts]\nnetwork_request = 45\n",
+			wantNetwork: 45 * time.Second,
+			wantRefresh: 5 * time.Minute,
+		},
+		{
+			name:        "quoted integer",
+			yaml:        "timeouts:\n  networkRequest: \"10\"\n",
+			toml:        "[timeouts]\nnetwork_request = \"10\"\n",
+			wantNetwork: 10 * time.Second,
+		},
+		{
+			name:        "invalid format",
+			yaml:        "timeouts:\n  networkRequest: 30 seconds\n",
+			toml:        "[timeouts]\nnetwork_request = \"30 seconds\"\n",
+			wantErr:     true,
+			errContains: "timeouts.network",
+		},
+		{
+			name:        "not a scalar",
+			yaml:        "refreshInterval: [1, 2]\n",
+			toml:        "refresh_interval = [1, 2]\n",
+			wantErr:     true,
+			errContains: "refresh",
+		},
+	}
+
+	for _, tt := range tests {
+		for format, data := range map[string]string{"yaml": tt.yaml, "toml": tt.toml} {
+			t.Run(tt.name+"/"+format, func(t *testing.T) {
+				cfg := &Config{}
+				var err error
+				if format == "yaml" {
+					err = decodeYAML([]byte(data), cfg)
+				} else {
+					err = decodeTOML([]byte(data), cfg)
+				}
+
+				if tt.wantErr {
+					if err == nil {
+						t.Fatal("Expected error but got none")
+					}
+					if !strings.Contains(err.Error(), tt.errContains) {
+						t.Errorf("Error %q should contain %q", err, tt.errContains)
+					}
+					if !strings.Contains(err.Error(), "duration") {
+						t.Errorf("Error %q should mention the expected duration format", err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if cfg.Timeouts.NetworkRequest != tt.wantNetwork {
+					t.Errorf("NetworkRequest = %v, want %v", cfg.Timeouts.NetworkRequest, tt.wantNetwork)
+				}
+				if cfg.RefreshInterval != tt.wantRefresh {
+					t.Errorf("RefreshInterval = %v, want %v", cfg.RefreshInterval, tt.wantRefresh)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EncryptedValuePrefix marks an encrypted string value in any config format.
+// TOML has no custom tags, so `api_key = "enc:<base64>"` is the TOML equivalent of
+// YAML's `apiKey: !encrypted <base64>`. YAML accepts both forms.
+// See: FR-015, FR-016
+const EncryptedValuePrefix = "enc:"
+
+// encryptedValueFromText parses the payload of an encrypted config value.
+// Accepts "<base64>" (key ID "default") and "AES256GCM:<keyID>:<base64>", the same
+// formats as Encryptor.DecryptFromString. Returns false for malformed payloads.
+func encryptedValueFromText(text string) (*EncryptedValue, bool) {
+	text = strings.TrimSpace(text)
+
+	keyID := "default"
+	if strings.HasPrefix(text, "AES256GCM:") {
+		parts := strings.SplitN(text, ":", 3)
+		if len(parts) != 3 || parts[1] == "" {
+			return nil, false
+		}
+		keyID = parts[1]
+		text = parts[2]
+	}
+
+	combined, err := base64.StdEncoding.DecodeString(text)
+	if err != nil {
+		return nil, false
+	}
+
+	// Extract nonce and ciphertext
+	const nonceSize = 12
+	if len(combined) < nonceSize {
+		return nil, false
+	}
+
+	return &EncryptedValue{
+		Ciphertext: combined[nonceSize:],
+		Nonce:      combined[:nonceSize],
+		KeyID:      keyID,
+		Algorithm:  "AES-256-GCM",
+	}, true
+}
+
+// scanConfigFileForEncryption finds encrypted values in config file content.
+// Field paths always use the YAML key names (e.g., "colorScheme.border") so both
+// formats report and apply encrypted fields identically.
+func scanConfigFileForEncryption(filePath string, data []byte) (map[string]*EncryptedValue, error) {
+	var (
+		encryptedFields map[string]*EncryptedValue
+		err             error
+	)
+	switch detectFormat(filePath) {
+	case FormatYAML:
+		_, encryptedFields, err = parseYAMLWithEncryption(data)
+	case FormatTOML:
+		_, encryptedFields, err = parseTOMLWithEncryption(data)
+	default:
+		return nil, fmt.Errorf("unsupported config file format (must be .yml, .yaml, or .toml): %s", filePath)
+	}
+	return encryptedFields, err
+}
+
+// setConfigString sets the string field at a YAML key path (e.g., "feeds[0].url").
+// Map entries (keybindings) are not addressable and are not supported.
+// Returns false if the path does not name a string field in cfg.
+func setConfigString(cfg *Config, path, value string) bool {
+	field := reflect.ValueOf(cfg).Elem()
+
+	for _, part := range strings.Split(path, ".") {
+		name, index := part, -1
+		if open := strings.IndexByte(part, '['); open >= 0 && strings.HasSuffix(part, "]") {
+			n, err := strconv.Atoi(part[open+1 : len(part)-1])
+			if err != nil {
+				return false
+			}
+			name, index = part[:open], n
+		}
+
+		if field.Kind() != reflect.Struct {
+			return false
+		}
+		next, ok := structFieldByYAMLName(field, name)
+		if !ok {
+			return false
+		}
+		field = next
+
+		if index >= 0 {
+			if field.Kind() != reflect.Slice || index >= field.Len() {
+				return false
+			}
+			field = field.Index(index)
+		}
+	}
+
+	if field.Kind() != reflect.String || !field.CanSet() {
+		return false
+	}
+	field.SetString(value)
+	return true
+}
+
+// structFieldByYAMLName returns the field of struct value v whose yaml tag is name.
+func structFieldByYAMLName(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if tag == name && t.Field(i).IsExported() {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// decryptConfigFields decrypts encrypted fields and applies the plaintext to cfg.
+// Values that cannot be decrypted are cleared so merging falls back to the default (FR-018).
+// Plaintext is never logged; it is returned so callers can register it for log redaction.
+func decryptConfigFields(ctx context.Context, cfg *Config, fields map[string]*EncryptedValue, encryptor Encryptor, logger Logger) []string {
+	var plaintexts []string
+	for fieldPath, encryptedValue := range fields {
+		plaintext, err := encryptor.Decrypt(ctx, encryptedValue)
+		if err != nil {
+			if logger != nil {
+				logger.Warn("Failed to decrypt field %s: %v (falling back to default)", fieldPath, err)
+			}
+			// Clear the ciphertext so merging keeps the default
+			setConfigString(cfg, fieldPath, "")
+			continue
+		}
+
+		if !setConfigString(cfg, fieldPath, plaintext) {
+			if logger != nil {
+				logger.Warn("Encrypted field %s is not a string setting; ignoring", fieldPath)
+			}
+			continue
+		}
+		plaintexts = append(plaintexts, plaintext)
+		if logger != nil {
+			logger.Debug("Successfully decrypted field: %s", fieldPath)
+		}
+	}
+	return plaintexts
+}
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// encryptForTest encrypts plaintext with a fixed key provided through the env var fallback
+// and returns the base64 payload used after "!encrypted " or "enc:".
+func encryptForTest(t *testing.T, plaintext string) string {
+	t.Helper()
+
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	t.Setenv("LAZYNUGET_ENCRYPTION_KEY_DEFAULT", hex.EncodeToString(key))
+
+	encryptor := NewEncryptor(NewKeychainManager(), NewKeyDerivation())
+	encrypted, err := encryptor.EncryptToString(context.Background(), plaintext, "default")
+	if err != nil {
+		t.Fatalf("EncryptToString() error = %v", err)
+	}
+	return strings.TrimPrefix(encrypted, "!encrypted ")
+}
+
+// TestEncryptedValueFromText tests parsing of encrypted value payloads
+func TestEncryptedValueFromText(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantKeyID string
+		wantOK    bool
+	}{
+		{name: "plain base64", text: "dGVzdGRhdGExMjM0NTY3ODkwMTIzNDU2", wantKeyID: "default", wantOK: true},
+		{name: "with key id", text: "AES256GCM:prod:dGVzdGRhdGExMjM0NTY3ODkwMTIzNDU2", wantKeyID: "prod", wantOK: true},
+		{name: "surrounding whitespace", text: "  dGVzdGRhdGExMjM0NTY3ODkwMTIzNDU2 ", wantKeyID: "default", wantOK: true},
+		{name: "invalid base64", text: "not-valid-base64!!!"},
+		{name: "too short", text: "dGVzdA=="},
+		{name: "missing key id", text: "AES256GCM::dGVzdGRhdGExMjM0NTY3ODkwMTIzNDU2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev, ok := encryptedValueFromText(tt.text)
+			if ok != tt.wantOK {
+				t.Fatalf("encryptedValueFromText(%q) ok = %v, want %v", tt.text, ok, tt.wantOK)
+			}
+			if ok && ev.KeyID != tt.wantKeyID {
+				t.Errorf("KeyID = %q, want %q", ev.KeyID, tt.wantKeyID)
+			}
+		})
+	}
+}
+
+// TestEncryptedFieldsFormatParity verifies YAML and TOML report the same encrypted fields
+func TestEncryptedFieldsFormatParity(t *testing.T) {
+	const payload = "dGVzdGRhdGExMjM0NTY3ODkwMTIzNDU2"
+
+	yamlContent := `
+dotnetPath: !encrypted ` + payload + `
+theme: enc:` + payload + `
+logLevel: debug
+colorScheme:
+  border: !encrypted ` + payload + `
+feeds:
+  - name: internal
+    url: enc:` + payload + `
+`
+	tomlContent := `
+dotnet_path = "enc:` + payload + `"
+theme = "enc:` + payload + `"
+log_level = "debug"
+
+[color_scheme]
+border = "enc:` + payload + `"
+
+[[feeds]]
+name = "internal"
+url = "enc:` + payload + `"
+`
+	want := []string{"colorScheme.border", "dotnetPath", "feeds[0].url", "theme"}
+
+	for name, file := range map[string]struct {
+		path    string
+		content string
+	}{
+		"yaml": {path: "config.yml", content: yamlContent},
+		"toml": {path: "config.toml", content: tomlContent},
+	} {
+		t.Run(name, func(t *testing.T) {
+			fields, err := scanConfigFileForEncryption(file.path, []byte(file.content))
+			if err != nil {
+				t.Fatalf("scanConfigFileForEncryption() error = %v", err)
+			}
+			got := make([]string, 0, len(fields))
+			for path := range fields {
+				got = append(got, path)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("encrypted fields = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// TestLoadDecryptsEncryptedValues verifies decrypted values are applied identically for both formats
+func TestLoadDecryptsEncryptedValues(t *testing.T) {
+	payload := encryptForTest(t, "/opt/dotnet/dotnet")
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		profile string
+	}{
+		{name: "yaml tag", file: "config.yml", content: "dotnetPath: !encrypted " + payload + "\n"},
+		{name: "yaml prefix", file: "config.yml", content: "dotnetPath: enc:" + payload + "\n"},
+		{name: "toml prefix", file: "config.toml", content: "dotnet_path = \"enc:" + payload + "\"\n"},
+		{name: "yaml profile", file: "config.yml", profile: "work", content: "dotnetPath: dotnet\nprofiles:\n  work:\n    dotnetPath: !encrypted " + payload + "\n"},
+		{name: "toml profile", file: "config.toml", profile: "work", content: "dotnet_path = \"dotnet\"\n[profiles.work]\ndotnet_path = \"enc:" + payload + "\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(configPath, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			cfg, err := NewLoader().Load(context.Background(), LoadOptions{ConfigFilePath: configPath, NoProjectConfig: true, Profile: tt.profile})
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.DotnetPath != "/opt/dotnet/dotnet" {
+				t.Errorf("DotnetPath = %q, want decrypted value", cfg.DotnetPath)
+			}
+			if !slices.Contains(cfg.Secrets(), "/opt/dotnet/dotnet") {
+				t.Errorf("Secrets() = %v, want decrypted value registered for redaction", cfg.Secrets())
+			}
+		})
+	}
+}
+
+// TestConfigSecrets verifies feed API keys and decrypted values are reported for log redaction
+func TestConfigSecrets(t *testing.T) {
+	payload := encryptForTest(t, "oy2-encrypted-feed-key")
+
+	content := `feeds:
+  - name: private
+    url: https://nuget.example.com/v3/index.json
+    apiKey: !encrypted ` + payload + `
+  - name: internal
+    url: https://internal.example.com/v3/index.json
+    apiKey: oy2-plain-feed-key
+  - name: public
+    url: https://api.nuget.org/v3/index.json
+`
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := NewLoader().Load(context.Background(), LoadOptions{ConfigFilePath: configPath, NoProjectConfig: true})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Feeds[0].APIKey != "oy2-encrypted-feed-key" {
+		t.Errorf("Feeds[0].APIKey = %q, want decrypted value", cfg.Feeds[0].APIKey)
+	}
+
+	secrets := cfg.Secrets()
+	for _, want := range []string{"oy2-encrypted-feed-key", "oy2-plain-feed-key"} {
+		if !slices.Contains(secrets, want) {
+			t.Errorf("Secrets() = %v, missing %q", secrets, want)
+		}
+	}
+
+	if secrets := GetDefaultConfig().Secrets(); len(secrets) != 0 {
+		t.Errorf("default Secrets() = %v, want none", secrets)
+	}
+}
+
+// TestLoadDecryptionFailureFallsBackToDefault verifies undecryptable values never leak into the config
+func TestLoadDecryptionFailureFallsBackToDefault(t *testing.T) {
+	payload := encryptForTest(t, "dark")
+
+	// A different key makes the ciphertext undecryptable
+	t.Setenv("LAZYNUGET_ENCRYPTION_KEY_DEFAULT", strings.Repeat("ff", 32))
+
+	for file, content := range map[string]string{
+		"config.yml":  "theme: enc:" + payload + "\n",
+		"config.toml": "theme = \"enc:" + payload + "\"\n",
+	} {
+		t.Run(file, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), file)
+			if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			cfg, err := NewLoader().Load(context.Background(), LoadOptions{ConfigFilePath: configPath, NoProjectConfig: true})
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if want := GetDefaultConfig().Theme; cfg.Theme != want {
+				t.Errorf("Theme = %q, want default %q", cfg.Theme, want)
+			}
+		})
+	}
+}
+
+// TestSetConfigString tests setting string fields by YAML key path
+func TestSetConfigString(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		check  func(*Config) string
+		wantOK bool
+	}{
+		{name: "top-level", path: "dotnetPath", wantOK: true, check: func(c *Config) string { return c.DotnetPath }},
+		{name: "nested", path: "colorScheme.border", wantOK: true, check: func(c *Config) string { return c.ColorScheme.Border }},
+		{name: "list item", path: "feeds[0].url", wantOK: true, check: func(c *Config) string { return c.Feeds[0].URL }},
+		{name: "map entry", path: "keybindings.quit"},
+		{name: "unknown key", path: "apiKey"},
+		{name: "not a string", path: "maxConcurrentOps"},
+		{name: "index out of range", path: "feeds[3].url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := GetDefaultConfig()
+			cfg.Feeds = []Feed{{Name: "internal"}}
+
+			ok := setConfigString(cfg, tt.path, "value")
+			if ok != tt.wantOK {
+				t.Fatalf("setConfigString(%q) = %v, want %v", tt.path, ok, tt.wantOK)
+			}
+			if ok && tt.check(cfg) != "value" {
+				t.Errorf("field at %q = %q, want %q", tt.path, tt.check(cfg), "value")
+			}
+		})
+	}
+}
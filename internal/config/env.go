@@ -4,7 +4,6 @@ import (
 	"os"
 	"strconv"
 	"strings"
-	"time"
 )
 
 // parseEnvVars scans all environment variables with the given prefix
@@ -88,6 +87,11 @@ func toCamelCaseMulti(parts []string) string {
 		"timeouts":    {"TIMEOUTS"},
 		"logRotation": {"LOG", "ROTATION"},
 		"keybindings": {"KEYBINDINGS"},
+		"logLevels":   {"LOG", "LEVELS"},
+		"sandbox":     {"SANDBOX"},
+		"telemetry":   {"TELEMETRY"},
+		"statusBar":   {"STATUS", "BAR"},
+		"confirm":     {"CONFIRM"},
 	}
 
 	// Check if we have a known nested structure at the beginning
@@ -189,24 +193,48 @@ func applyTopLevelSetting(cfg *Config, field, value string) error {
 		}
 	case "dateFormat":
 		cfg.DateFormat = value
+	case "staleAfterYears":
+		if i, err := strconv.Atoi(value); err == nil {
+			cfg.StaleAfterYears = i
+		}
+	case "packageIcons":
+		cfg.PackageIcons = value
+	case "editor":
+		cfg.Editor = value
 	case "keybindingProfile":
 		cfg.KeybindingProfile = value
 	case "maxConcurrentOps":
 		if i, err := strconv.Atoi(value); err == nil {
 			cfg.MaxConcurrentOps = i
 		}
+	case "renderFps":
+		if i, err := strconv.Atoi(value); err == nil {
+			cfg.RenderFPS = i
+		}
 	case "cacheSize":
 		if i, err := strconv.Atoi(value); err == nil {
 			cfg.CacheSize = i
 		}
 	case "refreshInterval":
-		if d, err := time.ParseDuration(value); err == nil {
+		if d, err := parseConfigDuration(value); err == nil {
 			cfg.RefreshInterval = d
 		}
+	case "advisoryCacheTtl": // LAZYNUGET_ADVISORY_CACHE_TTL
+		if d, err := parseConfigDuration(value); err == nil {
+			cfg.AdvisoryCacheTTL = d
+		}
+	case "prefetchDelay":
+		if d, err := parseConfigDuration(value); err == nil {
+			cfg.PrefetchDelay = d
+		}
 	case "dotnetPath":
 		cfg.DotnetPath = value
 	case "dotnetVerbosity":
 		cfg.DotnetVerbosity = value
+	case "operationBackend":
+		cfg.OperationBackend = value
+	case "restoreMode":
+		cfg.RestoreMode = value
 	case "logLevel":
 		cfg.LogLevel = value
 	case "logDir":
@@ -217,6 +245,10 @@ func applyTopLevelSetting(cfg *Config, field, value string) error {
 		if b, err := parseBool(value); err == nil {
 			cfg.HotReload = b
 		}
+	case "updateCheck":
+		if b, err := parseBool(value); err == nil {
+			cfg.UpdateCheck = b
+		}
 	}
 
 	return nil
@@ -247,19 +279,27 @@ func applyNestedSetting(cfg *Config, parent, field, value string) error {
 			cfg.ColorScheme.TextDim = value
 		case "borderFocus":
 			cfg.ColorScheme.BorderFocus = value
+		default:
+			// Panel colors run the panel and color together: packagesSelectedBg
+			colorFields(&cfg.ColorScheme, func(key string, color *string) {
+				panel, name, isPanel := strings.Cut(key, ".")
+				if isPanel && strings.EqualFold(field, panel+name) {
+					*color = value
+				}
+			})
 		}
 	case "timeouts":
 		switch field {
 		case "networkRequest":
-			if d, err := time.ParseDuration(value); err == nil {
+			if d, err := parseConfigDuration(value); err == nil {
 				cfg.Timeouts.NetworkRequest = d
 			}
 		case "dotnetCli":
-			if d, err := time.ParseDuration(value); err == nil {
+			if d, err := parseConfigDuration(value); err == nil {
 				cfg.Timeouts.DotnetCLI = d
 			}
 		case "fileOperation":
-			if d, err := time.ParseDuration(value); err == nil {
+			if d, err := parseConfigDuration(value); err == nil {
 				cfg.Timeouts.FileOperation = d
 			}
 		}
@@ -282,6 +322,44 @@ func applyNestedSetting(cfg *Config, parent, field, value string) error {
 				cfg.LogRotation.Compress = b
 			}
 		}
+	case "logLevels":
+		if cfg.LogLevels == nil {
+			cfg.LogLevels = make(map[string]string)
+		}
+		cfg.LogLevels[field] = strings.ToLower(value)
+	case "telemetry":
+		if field == "endpoint" {
+			cfg.Telemetry.Endpoint = value
+		}
+	case "statusBar":
+		switch field {
+		case "format":
+			cfg.StatusBar.Format = value
+		case "clockFormat":
+			cfg.StatusBar.ClockFormat = value
+		}
+	case "confirm":
+		switch field {
+		case "add":
+			cfg.Confirm.Add = strings.ToLower(value)
+		case "remove":
+			cfg.Confirm.Remove = strings.ToLower(value)
+		case "update":
+			cfg.Confirm.Update = strings.ToLower(value)
+		case "bulk":
+			cfg.Confirm.Bulk = strings.ToLower(value)
+		}
+	case "sandbox":
+		switch field {
+		case "enabled":
+			if b, err := parseBool(value); err == nil {
+				cfg.Sandbox.Enabled = b
+			}
+		case "allowNetwork":
+			if b, err := parseBool(value); err == nil {
+				cfg.Sandbox.AllowNetwork = b
+			}
+		}
 	}
 
 	return nil
@@ -79,6 +79,14 @@ func TestApplyTopLevelSetting(t *testing.T) {
 			wantField: "HotReload",
 			wantValue: true,
 		},
+		{
+			name:      "set update check",
+			parts:     []string{"updateCheck"},
+			value:     "true",
+			initial:   &Config{UpdateCheck: false},
+			wantField: "UpdateCheck",
+			wantValue: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -121,6 +129,10 @@ func TestApplyTopLevelSetting(t *testing.T) {
 				if cfg.HotReload != tt.wantValue.(bool) {
 					t.Errorf("HotReload = %v, want %v", cfg.HotReload, tt.wantValue)
 				}
+			case "UpdateCheck":
+				if cfg.UpdateCheck != tt.wantValue.(bool) {
+					t.Errorf("UpdateCheck = %v, want %v", cfg.UpdateCheck, tt.wantValue)
+				}
 			}
 		})
 	}
@@ -325,6 +337,29 @@ func TestApplyNestedSetting(t *testing.T) {
 				}
 			},
 		},
+		// Sandbox tests
+		{
+			name:    "set sandbox enabled",
+			parts:   []string{"sandbox", "enabled"},
+			value:   "yes",
+			initial: &Config{},
+			check: func(t *testing.T, cfg *Config) {
+				if !cfg.Sandbox.Enabled {
+					t.Error("Sandbox.Enabled should be true")
+				}
+			},
+		},
+		{
+			name:    "set sandbox allowNetwork",
+			parts:   []string{"sandbox", "allowNetwork"},
+			value:   "true",
+			initial: &Config{},
+			check: func(t *testing.T, cfg *Config) {
+				if !cfg.Sandbox.AllowNetwork {
+					t.Error("Sandbox.AllowNetwork should be true")
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -348,3 +383,21 @@ func TestApplyDoubleNestedSetting(t *testing.T) {
 		t.Errorf("applyDoubleNestedSetting should not error: %v", err)
 	}
 }
+
+// TestSandboxFromEnv tests that LAZYNUGET_SANDBOX_* variables reach the sandbox settings
+func TestSandboxFromEnv(t *testing.T) {
+	t.Setenv("LAZYNUGET_SANDBOX_ENABLED", "true")
+	t.Setenv("LAZYNUGET_SANDBOX_ALLOW_NETWORK", "true")
+
+	cfg := &Config{}
+	for path, value := range parseEnvVars("LAZYNUGET_") {
+		if strings.HasPrefix(path, "sandbox.") {
+			if err := applyEnvVarValue(cfg, path, value); err != nil {
+				t.Fatalf("applyEnvVarValue(%q) error = %v", path, err)
+			}
+		}
+	}
+	if !cfg.Sandbox.Enabled || !cfg.Sandbox.AllowNetwork {
+		t.Errorf("Sandbox = %+v, want enabled with network access", cfg.Sandbox)
+	}
+}
@@ -152,6 +152,22 @@ func TestParseEnvVarsIntegration(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "apply module log level",
+			envVars: map[string]string{
+				"LAZYNUGET_LOG_LEVELS_NUGET": "DEBUG",
+			},
+			prefix: "LAZYNUGET_",
+			checkFunc: func(cfg *Config) error {
+				if cfg.LogLevels["nuget"] != "debug" {
+					return &assertError{msg: "Expected LogLevels[nuget]=debug"}
+				}
+				if cfg.LogLevel != "info" {
+					return &assertError{msg: "Expected LogLevel unchanged"}
+				}
+				return nil
+			},
+		},
 		{
 			name: "apply boolean fields",
 			envVars: map[string]string{
@@ -174,6 +190,7 @@ func TestParseEnvVarsIntegration(t *testing.T) {
 			envVars: map[string]string{
 				"LAZYNUGET_MAX_CONCURRENT_OPS": "8",
 				"LAZYNUGET_CACHE_SIZE":         "256",
+				"LAZYNUGET_RENDER_FPS":         "10",
 			},
 			prefix: "LAZYNUGET_",
 			checkFunc: func(cfg *Config) error {
@@ -183,6 +200,9 @@ func TestParseEnvVarsIntegration(t *testing.T) {
 				if cfg.CacheSize != 256 {
 					return &assertError{msg: "Expected CacheSize=256"}
 				}
+				if cfg.RenderFPS != 10 {
+					return &assertError{msg: "Expected RenderFPS=10"}
+				}
 				return nil
 			},
 		},
@@ -206,7 +226,9 @@ func TestParseEnvVarsIntegration(t *testing.T) {
 		{
 			name: "apply duration fields",
 			envVars: map[string]string{
-				"LAZYNUGET_REFRESH_INTERVAL": "10m",
+				"LAZYNUGET_REFRESH_INTERVAL":   "10m",
+				"LAZYNUGET_ADVISORY_CACHE_TTL": "6h",
+				"LAZYNUGET_PREFETCH_DELAY":     "500ms",
 			},
 			prefix: "LAZYNUGET_",
 			checkFunc: func(cfg *Config) error {
@@ -215,6 +237,26 @@ func TestParseEnvVarsIntegration(t *testing.T) {
 				if cfg.RefreshInterval != expected {
 					return &assertError{msg: "Expected RefreshInterval=10m0s"}
 				}
+				if cfg.AdvisoryCacheTTL != 6*time.Hour {
+					return &assertError{msg: "Expected AdvisoryCacheTTL=6h0m0s"}
+				}
+				if cfg.PrefetchDelay != 500*time.Millisecond {
+					return &assertError{msg: "Expected PrefetchDelay=500ms"}
+				}
+				return nil
+			},
+		},
+		{
+			name: "apply duration in seconds",
+			envVars: map[string]string{
+				"LAZYNUGET_TIMEOUTS_NETWORK_REQUEST": "45",
+			},
+			prefix: "LAZYNUGET_",
+			checkFunc: func(cfg *Config) error {
+				// A bare number means seconds, matching config files
+				if cfg.Timeouts.NetworkRequest != 45*time.Second {
+					return &assertError{msg: "Expected NetworkRequest=45s"}
+				}
 				return nil
 			},
 		},
@@ -280,6 +322,12 @@ func TestParseEnvVarsDoubleNested(t *testing.T) {
 			value:    "5m",
 			expected: "timeouts.dotnetCli",
 		},
+		{
+			name:     "sandbox allow network",
+			envVar:   "LAZYNUGET_SANDBOX_ALLOW_NETWORK",
+			value:    "true",
+			expected: "sandbox.allowNetwork",
+		},
 	}
 
 	for _, tt := range tests {
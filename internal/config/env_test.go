@@ -32,6 +32,16 @@ func TestConvertEnvVarPathToDotNotation(t *testing.T) {
 			envPath: "LOG_ROTATION_MAX_SIZE",
 			want:    "logRotation.maxSize",
 		},
+		{
+			name:    "nested status bar",
+			envPath: "STATUS_BAR_CLOCK_FORMAT",
+			want:    "statusBar.clockFormat",
+		},
+		{
+			name:    "nested confirm policy",
+			envPath: "CONFIRM_BULK",
+			want:    "confirm.bulk",
+		},
 		{
 			name:    "simple field lowercase",
 			envPath: "THEME",
@@ -0,0 +1,585 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supported import sources for `lazynuget import-config --from`.
+const (
+	ImportSourceLazygit    = "lazygit"
+	ImportSourceRenovate   = "renovate"
+	ImportSourceDependabot = "dependabot"
+)
+
+// ImportSources lists the tools whose configuration can be imported.
+var ImportSources = []string{ImportSourceLazygit, ImportSourceRenovate, ImportSourceDependabot}
+
+// ImportResult holds the settings translated from another tool's configuration.
+// Only translated settings are set; everything else keeps LazyNuGet defaults.
+type ImportResult struct {
+	Keybindings       map[string]KeyBinding `yaml:"keybindings,omitempty"`
+	PinnedPackages    []PinRule             `yaml:"pinnedPackages,omitempty"`
+	Feeds             []Feed                `yaml:"feeds,omitempty"`
+	ColorScheme       *ColorScheme          `yaml:"colorScheme,omitempty"`
+	KeybindingProfile string                `yaml:"keybindingProfile,omitempty"`
+	Notes             []string              `yaml:"-"` // Settings that were skipped or approximated
+}
+
+// YAML renders the imported settings as a LazyNuGet config.yml document.
+func (r *ImportResult) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(r); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DefaultImportPaths returns the conventional locations of each tool's config file,
+// in lookup order. Relative paths are resolved against the working directory.
+func DefaultImportPaths(source string) []string {
+	switch strings.ToLower(source) {
+	case ImportSourceLazygit:
+		var paths []string
+		if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+			paths = append(paths, filepath.Join(dir, "lazygit", "config.yml"))
+		}
+		if dir, err := os.UserConfigDir(); err == nil {
+			paths = append(paths, filepath.Join(dir, "lazygit", "config.yml"))
+		}
+		if home, err := os.UserHomeDir(); err == nil {
+			paths = append(paths, filepath.Join(home, ".config", "lazygit", "config.yml"))
+		}
+		return paths
+	case ImportSourceRenovate:
+		return []string{"renovate.json", ".renovaterc", ".renovaterc.json", filepath.Join(".github", "renovate.json")}
+	case ImportSourceDependabot:
+		return []string{filepath.Join(".github", "dependabot.yml"), filepath.Join(".github", "dependabot.yaml")}
+	default:
+		return nil
+	}
+}
+
+// ImportConfig translates a configuration file from another tool into LazyNuGet settings.
+// Settings without a LazyNuGet equivalent are reported in ImportResult.Notes.
+func ImportConfig(source string, data []byte) (*ImportResult, error) {
+	switch strings.ToLower(source) {
+	case ImportSourceLazygit:
+		return importLazygit(data)
+	case ImportSourceRenovate:
+		return importRenovate(data)
+	case ImportSourceDependabot:
+		return importDependabot(data)
+	default:
+		return nil, fmt.Errorf("unsupported import source %q: must be one of %s", source, strings.Join(ImportSources, ", "))
+	}
+}
+
+// lazygitConfig is the subset of lazygit's config.yml that has a LazyNuGet equivalent.
+type lazygitConfig struct {
+	Keybinding map[string]map[string]any `yaml:"keybinding"`
+	Gui        struct {
+		Theme struct {
+			ActiveBorderColor   []string `yaml:"activeBorderColor"`
+			InactiveBorderColor []string `yaml:"inactiveBorderColor"`
+			SelectedLineBgColor []string `yaml:"selectedLineBgColor"`
+		} `yaml:"theme"`
+	} `yaml:"gui"`
+}
+
+// lazygitActions maps lazygit universal keybinding names to LazyNuGet actions.
+var lazygitActions = map[string]string{
+	"quit":          "quit",
+	"refresh":       "refresh",
+	"startSearch":   "search",
+	"optionMenu":    "help",
+	"prevItem":      "up",
+	"nextItem":      "down",
+	"prevBlock":     "left",
+	"nextBlock":     "right",
+	"prevPage":      "page_up",
+	"nextPage":      "page_down",
+	"togglePanel":   "next_panel",
+	"return":        "back",
+	"confirm":       "select",
+	"goInto":        "select",
+	"filteringMenu": "filter",
+}
+
+// ansiColors maps lazygit's named colors to hex values.
+var ansiColors = map[string]string{
+	"black":   "#000000",
+	"red":     "#FF0000",
+	"green":   "#00FF00",
+	"yellow":  "#FFFF00",
+	"blue":    "#0000FF",
+	"magenta": "#FF00FF",
+	"cyan":    "#00FFFF",
+	"white":   "#FFFFFF",
+	"default": "",
+}
+
+// importLazygit translates lazygit keybindings and theme colors.
+// lazygit navigates with vim-style keys by default, so its users start from the vim profile.
+func importLazygit(data []byte) (*ImportResult, error) {
+	var lg lazygitConfig
+	if err := yaml.Unmarshal(data, &lg); err != nil {
+		return nil, fmt.Errorf("invalid lazygit config: %w", err)
+	}
+
+	result := &ImportResult{KeybindingProfile: "vim"}
+
+	// Sort section and key names so the output is deterministic
+	sections := make([]string, 0, len(lg.Keybinding))
+	for section := range lg.Keybinding {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+
+	for _, section := range sections {
+		if section != "universal" {
+			result.Notes = append(result.Notes, fmt.Sprintf("keybinding.%s: no LazyNuGet equivalent, skipped", section))
+			continue
+		}
+
+		names := make([]string, 0, len(lg.Keybinding[section]))
+		for name := range lg.Keybinding[section] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			raw, ok := lg.Keybinding[section][name].(string)
+			action, known := lazygitActions[name]
+			if !ok || !known || raw == "" || raw == "<disabled>" {
+				result.Notes = append(result.Notes, fmt.Sprintf("keybinding.universal.%s: no LazyNuGet equivalent, skipped", name))
+				continue
+			}
+			key, ok := lazygitKey(raw)
+			if !ok {
+				result.Notes = append(result.Notes, fmt.Sprintf("keybinding.universal.%s: key %q has no LazyNuGet equivalent, skipped", name, raw))
+				continue
+			}
+			if _, exists := result.Keybindings[action]; exists {
+				continue
+			}
+			if result.Keybindings == nil {
+				result.Keybindings = make(map[string]KeyBinding)
+			}
+			result.Keybindings[action] = KeyBinding{
+				Action:      action,
+				Key:         key,
+				Context:     "global",
+				Description: "Imported from lazygit keybinding.universal." + name,
+			}
+		}
+	}
+
+	// Start from the default scheme so the emitted colorScheme is complete
+	defaults := GetDefaultConfig().ColorScheme
+	colors := defaults
+	theme := lg.Gui.Theme
+	if hex := lazygitColor(theme.ActiveBorderColor, result, "activeBorderColor"); hex != "" {
+		colors.BorderFocus = hex
+	}
+	if hex := lazygitColor(theme.InactiveBorderColor, result, "inactiveBorderColor"); hex != "" {
+		colors.Border = hex
+	}
+	if hex := lazygitColor(theme.SelectedLineBgColor, result, "selectedLineBgColor"); hex != "" {
+		colors.Highlight = hex
+	}
+	if colors != defaults {
+		result.ColorScheme = &colors
+	}
+
+	return result, nil
+}
+
+// lazygitKeyNames maps the key names lazygit writes in angle brackets to LazyNuGet key names.
+var lazygitKeyNames = map[string]string{
+	"enter": "enter", "esc": "esc", "tab": "tab", "backtab": "backtab", "space": "space",
+	"backspace": "backspace", "delete": "delete", "insert": "insert",
+	"up": "up", "down": "down", "left": "left", "right": "right",
+	"home": "home", "end": "end", "pgup": "pgup", "pgdown": "pgdown",
+	"f1": "f1", "f2": "f2", "f3": "f3", "f4": "f4", "f5": "f5", "f6": "f6",
+	"f7": "f7", "f8": "f8", "f9": "f9", "f10": "f10", "f11": "f11", "f12": "f12",
+}
+
+// lazygitKey converts a lazygit key ("q", "<enter>", "<c-r>", "<a-enter>") to LazyNuGet
+// key syntax ("q", "enter", "ctrl+r", "alt+enter"). Keys lazygit names that LazyNuGet
+// does not know are reported as not convertible.
+func lazygitKey(key string) (string, bool) {
+	if utf8.RuneCountInString(key) == 1 {
+		if key == " " {
+			return "space", true
+		}
+		return key, true
+	}
+	if len(key) < 3 || !strings.HasPrefix(key, "<") || !strings.HasSuffix(key, ">") {
+		return "", false
+	}
+
+	name := key[1 : len(key)-1]
+	prefix := ""
+	switch {
+	case strings.HasPrefix(strings.ToLower(name), "c-") && len(name) > 2:
+		prefix, name = "ctrl+", name[2:]
+	case strings.HasPrefix(strings.ToLower(name), "a-") && len(name) > 2:
+		prefix, name = "alt+", name[2:]
+	}
+
+	if utf8.RuneCountInString(name) == 1 {
+		if prefix == "ctrl+" {
+			name = strings.ToLower(name) // Terminals cannot tell ctrl+R from ctrl+r
+		}
+		return prefix + name, true
+	}
+	if named, ok := lazygitKeyNames[strings.ToLower(name)]; ok {
+		return prefix + named, true
+	}
+	return "", false
+}
+
+// lazygitColor converts a lazygit color attribute list (e.g., [green, bold]) to a hex color.
+// Attributes such as bold and underline are ignored.
+func lazygitColor(attrs []string, result *ImportResult, name string) string {
+	for _, attr := range attrs {
+		attr = strings.ToLower(strings.TrimSpace(attr))
+		if strings.HasPrefix(attr, "#") {
+			return strings.ToUpper(attr)
+		}
+		if hex, ok := ansiColors[attr]; ok {
+			return hex
+		}
+	}
+	if len(attrs) > 0 {
+		result.Notes = append(result.Notes, fmt.Sprintf("gui.theme.%s: %v has no color, skipped", name, attrs))
+	}
+	return ""
+}
+
+// renovateConfig is the subset of renovate.json that has a LazyNuGet equivalent.
+type renovateConfig struct {
+	Schedule     any                   `yaml:"schedule"`
+	IgnoreDeps   []string              `yaml:"ignoreDeps"`
+	RegistryURLs []string              `yaml:"registryUrls"`
+	PackageRules []renovatePackageRule `yaml:"packageRules"`
+}
+
+type renovatePackageRule struct {
+	Enabled              *bool    `yaml:"enabled"`
+	Schedule             any      `yaml:"schedule"`
+	AllowedVersions      string   `yaml:"allowedVersions"`
+	MatchDatasources     []string `yaml:"matchDatasources"`
+	MatchManagers        []string `yaml:"matchManagers"`
+	MatchPackageNames    []string `yaml:"matchPackageNames"`
+	MatchPackagePrefixes []string `yaml:"matchPackagePrefixes"`
+	RegistryURLs         []string `yaml:"registryUrls"`
+}
+
+// appliesToNuGet reports whether a package rule targets NuGet dependencies.
+// Rules without a datasource or manager filter apply to every ecosystem.
+func (r *renovatePackageRule) appliesToNuGet() bool {
+	if len(r.MatchDatasources) == 0 && len(r.MatchManagers) == 0 {
+		return true
+	}
+	for _, ds := range r.MatchDatasources {
+		if ds == "nuget" {
+			return true
+		}
+	}
+	for _, m := range r.MatchManagers {
+		if m == "nuget" {
+			return true
+		}
+	}
+	return false
+}
+
+// importRenovate translates Renovate ignore rules and allowed version ranges into pin rules.
+// renovate.json is plain JSON, which the YAML decoder accepts.
+func importRenovate(data []byte) (*ImportResult, error) {
+	var rc renovateConfig
+	if err := yaml.Unmarshal(data, &rc); err != nil {
+		return nil, fmt.Errorf("invalid Renovate config: %w", err)
+	}
+
+	result := &ImportResult{}
+
+	for _, dep := range rc.IgnoreDeps {
+		result.PinnedPackages = append(result.PinnedPackages, PinRule{
+			Package: dep,
+			Reason:  "Ignored by Renovate (ignoreDeps)",
+		})
+	}
+
+	if rc.Schedule != nil {
+		result.Notes = append(result.Notes, "schedule: LazyNuGet checks for updates on demand, skipped")
+	}
+	addFeeds(result, rc.RegistryURLs)
+
+	for i := range rc.PackageRules {
+		rule := &rc.PackageRules[i]
+		if !rule.appliesToNuGet() {
+			continue
+		}
+
+		addFeeds(result, rule.RegistryURLs)
+		if rule.Schedule != nil {
+			result.Notes = append(result.Notes, fmt.Sprintf("packageRules[%d].schedule: LazyNuGet checks for updates on demand, skipped", i))
+		}
+
+		disabled := rule.Enabled != nil && !*rule.Enabled
+		if !disabled && rule.AllowedVersions == "" {
+			continue
+		}
+
+		version, reason := "", "Disabled by Renovate packageRules"
+		if !disabled {
+			var ok bool
+			version, ok = semverRangeToNuGet(rule.AllowedVersions)
+			if !ok {
+				result.Notes = append(result.Notes, fmt.Sprintf("packageRules[%d].allowedVersions: %q cannot be expressed as a NuGet range, skipped", i, rule.AllowedVersions))
+				continue
+			}
+			reason = "Renovate allowedVersions " + rule.AllowedVersions
+		}
+
+		packages := append([]string{}, rule.MatchPackageNames...)
+		for _, prefix := range rule.MatchPackagePrefixes {
+			packages = append(packages, prefix+"*")
+		}
+		if len(packages) == 0 {
+			result.Notes = append(result.Notes, fmt.Sprintf("packageRules[%d]: no matchPackageNames or matchPackagePrefixes, skipped", i))
+			continue
+		}
+		for _, pkg := range packages {
+			result.PinnedPackages = append(result.PinnedPackages, PinRule{Package: pkg, Version: version, Reason: reason})
+		}
+	}
+
+	return result, nil
+}
+
+// dependabotConfig is the subset of .github/dependabot.yml that has a LazyNuGet equivalent.
+type dependabotConfig struct {
+	Registries map[string]struct {
+		Type string `yaml:"type"`
+		URL  string `yaml:"url"`
+	} `yaml:"registries"`
+	Updates []struct {
+		Ignore []struct {
+			DependencyName string   `yaml:"dependency-name"`
+			Versions       []string `yaml:"versions"`
+			UpdateTypes    []string `yaml:"update-types"`
+		} `yaml:"ignore"`
+		Schedule struct {
+			Interval string `yaml:"interval"`
+		} `yaml:"schedule"`
+		PackageEcosystem string `yaml:"package-ecosystem"`
+	} `yaml:"updates"`
+}
+
+// importDependabot translates Dependabot NuGet ignore rules into pin rules and
+// nuget-feed registries into feeds.
+func importDependabot(data []byte) (*ImportResult, error) {
+	var dc dependabotConfig
+	if err := yaml.Unmarshal(data, &dc); err != nil {
+		return nil, fmt.Errorf("invalid Dependabot config: %w", err)
+	}
+
+	result := &ImportResult{}
+
+	names := make([]string, 0, len(dc.Registries))
+	for name := range dc.Registries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		reg := dc.Registries[name]
+		if reg.Type != "nuget-feed" {
+			continue
+		}
+		result.Feeds = append(result.Feeds, Feed{Name: name, URL: reg.URL})
+	}
+
+	for i, update := range dc.Updates {
+		if update.PackageEcosystem != "nuget" {
+			continue
+		}
+		if update.Schedule.Interval != "" {
+			result.Notes = append(result.Notes, fmt.Sprintf("updates[%d].schedule: LazyNuGet checks for updates on demand, skipped", i))
+		}
+
+		for j, ignore := range update.Ignore {
+			if ignore.DependencyName == "" {
+				continue
+			}
+			if len(ignore.UpdateTypes) > 0 {
+				result.Notes = append(result.Notes, fmt.Sprintf("updates[%d].ignore[%d].update-types: pinning %s to its installed version instead", i, j, ignore.DependencyName))
+			}
+
+			version := ""
+			if len(ignore.Versions) == 1 {
+				var ok bool
+				version, ok = ignoredVersionsToNuGet(ignore.Versions[0])
+				if !ok {
+					result.Notes = append(result.Notes, fmt.Sprintf("updates[%d].ignore[%d].versions: %q cannot be expressed as a NuGet range, skipped", i, j, ignore.Versions[0]))
+					continue
+				}
+			} else if len(ignore.Versions) > 1 {
+				result.Notes = append(result.Notes, fmt.Sprintf("updates[%d].ignore[%d].versions: multiple ranges are not supported, skipped", i, j))
+				continue
+			}
+
+			result.PinnedPackages = append(result.PinnedPackages, PinRule{
+				Package: ignore.DependencyName,
+				Version: version,
+				Reason:  "Ignored by Dependabot",
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// semverRangeToNuGet converts simple Renovate allowedVersions ranges to NuGet range syntax.
+// Supported forms: "1.2.3", "<2.0", "<=2.0", ">=1.0 <2.0".
+func semverRangeToNuGet(r string) (string, bool) {
+	parts := strings.Fields(r)
+	var lower, upper string
+	lowerInclusive, upperInclusive := true, false
+
+	for _, part := range parts {
+		switch {
+		case strings.HasPrefix(part, ">="):
+			lower = part[2:]
+		case strings.HasPrefix(part, ">"):
+			lower, lowerInclusive = part[1:], false
+		case strings.HasPrefix(part, "<="):
+			upper, upperInclusive = part[2:], true
+		case strings.HasPrefix(part, "<"):
+			upper = part[1:]
+		case len(parts) == 1 && isPlainVersion(part):
+			return "[" + part + "]", true
+		default:
+			return "", false
+		}
+	}
+
+	if lower == "" && upper == "" {
+		return "", false
+	}
+	if lower == "" {
+		lowerInclusive = false
+	}
+
+	open, closeBracket := "(", ")"
+	if lowerInclusive {
+		open = "["
+	}
+	if upperInclusive {
+		closeBracket = "]"
+	}
+	return open + lower + "," + upper + closeBracket, true
+}
+
+// ignoredVersionsToNuGet converts a Dependabot ignored range into the NuGet range that
+// stays clear of it: ">= 14" becomes "(,14)" and "< 2" becomes "[2,)". Clauses are
+// separated by commas. A range bounded on both sides (">= 14, < 15") leaves allowed
+// versions on either side of it, which a single NuGet range cannot express, so it is
+// rejected along with clauses that are not comparisons.
+func ignoredVersionsToNuGet(r string) (string, bool) {
+	var lower, upper string
+	lowerInclusive, upperInclusive := false, false
+
+	for _, clause := range strings.Split(r, ",") {
+		clause = strings.TrimSpace(clause)
+		op := ""
+		for _, candidate := range []string{">=", "<=", ">", "<"} {
+			if strings.HasPrefix(clause, candidate) {
+				op = candidate
+				break
+			}
+		}
+		version := strings.TrimSpace(strings.TrimPrefix(clause, op))
+		if op == "" || version == "" || strings.ContainsAny(version, " \t<>=") {
+			return "", false
+		}
+
+		switch op {
+		case ">=", ">":
+			if lower != "" {
+				return "", false
+			}
+			lower, lowerInclusive = version, op == ">="
+		case "<=", "<":
+			if upper != "" {
+				return "", false
+			}
+			upper, upperInclusive = version, op == "<="
+		}
+	}
+
+	switch {
+	case lower != "" && upper == "":
+		if lowerInclusive {
+			return "(," + lower + ")", true
+		}
+		return "(," + lower + "]", true
+	case upper != "" && lower == "":
+		if upperInclusive {
+			return "(" + upper + ",)", true
+		}
+		return "[" + upper + ",)", true
+	default:
+		return "", false
+	}
+}
+
+// isPlainVersion reports whether s looks like a dotted numeric version.
+func isPlainVersion(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && c != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// addFeeds appends registry URLs as feeds named after their host, skipping duplicates.
+func addFeeds(result *ImportResult, urls []string) {
+	for _, raw := range urls {
+		name := raw
+		if u, err := url.Parse(raw); err == nil && u.Host != "" {
+			name = u.Host
+		}
+
+		duplicate := false
+		for _, f := range result.Feeds {
+			if f.URL == raw {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			result.Feeds = append(result.Feeds, Feed{Name: name, URL: raw})
+		}
+	}
+}
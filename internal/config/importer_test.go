@@ -0,0 +1,305 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TestImportLazygit tests translation of lazygit keybindings and theme colors
+func TestImportLazygit(t *testing.T) {
+	data := []byte(`
+gui:
+  theme:
+    activeBorderColor: [cyan, bold]
+    inactiveBorderColor: ["#444444"]
+keybinding:
+  universal:
+    quit: Q
+    refresh: R
+    scrollUpMain: <pgup>
+  files:
+    commitChanges: c
+`)
+
+	result, err := ImportConfig("lazygit", data)
+	if err != nil {
+		t.Fatalf("ImportConfig() error = %v", err)
+	}
+
+	if result.KeybindingProfile != "vim" {
+		t.Errorf("KeybindingProfile = %q, want vim", result.KeybindingProfile)
+	}
+	if kb := result.Keybindings["quit"]; kb.Key != "Q" || kb.Context != "global" {
+		t.Errorf("Keybindings[quit] = %+v, want key Q in global context", kb)
+	}
+	if kb := result.Keybindings["refresh"]; kb.Key != "R" {
+		t.Errorf("Keybindings[refresh] = %+v, want key R", kb)
+	}
+	if result.ColorScheme == nil {
+		t.Fatal("ColorScheme = nil, want imported border colors")
+	}
+	if result.ColorScheme.BorderFocus != "#00FFFF" || result.ColorScheme.Border != "#444444" {
+		t.Errorf("ColorScheme borders = %s/%s, want #00FFFF/#444444", result.ColorScheme.BorderFocus, result.ColorScheme.Border)
+	}
+	if result.ColorScheme.Text != GetDefaultConfig().ColorScheme.Text {
+		t.Errorf("ColorScheme.Text = %s, want default", result.ColorScheme.Text)
+	}
+	if len(result.Notes) != 2 {
+		t.Errorf("Notes = %v, want 2 (scrollUpMain and files section)", result.Notes)
+	}
+}
+
+// TestImportRenovate tests translation of Renovate ignore rules and version ranges
+func TestImportRenovate(t *testing.T) {
+	data := []byte(`{
+  "schedule": ["before 6am on monday"],
+  "ignoreDeps": ["Legacy.Package"],
+  "packageRules": [
+    {"matchPackageNames": ["Newtonsoft.Json"], "allowedVersions": "<13.0.3"},
+    {"matchPackagePrefixes": ["Microsoft.Extensions."], "enabled": false},
+    {"matchDatasources": ["npm"], "matchPackageNames": ["react"], "enabled": false},
+    {"matchDatasources": ["nuget"], "registryUrls": ["https://nuget.example.com/v3/index.json"]},
+    {"matchPackageNames": ["Serilog"], "allowedVersions": "/^2\\./"}
+  ]
+}`)
+
+	result, err := ImportConfig("renovate", data)
+	if err != nil {
+		t.Fatalf("ImportConfig() error = %v", err)
+	}
+
+	want := []PinRule{
+		{Package: "Legacy.Package"},
+		{Package: "Newtonsoft.Json", Version: "(,13.0.3)"},
+		{Package: "Microsoft.Extensions.*"},
+	}
+	if len(result.PinnedPackages) != len(want) {
+		t.Fatalf("PinnedPackages = %+v, want %d rules", result.PinnedPackages, len(want))
+	}
+	for i, w := range want {
+		got := result.PinnedPackages[i]
+		if got.Package != w.Package || got.Version != w.Version {
+			t.Errorf("PinnedPackages[%d] = %s@%s, want %s@%s", i, got.Package, got.Version, w.Package, w.Version)
+		}
+		if got.Reason == "" {
+			t.Errorf("PinnedPackages[%d].Reason is empty", i)
+		}
+	}
+
+	if len(result.Feeds) != 1 || result.Feeds[0].Name != "nuget.example.com" {
+		t.Errorf("Feeds = %+v, want nuget.example.com", result.Feeds)
+	}
+	// schedule and the regex allowedVersions are reported
+	if len(result.Notes) != 2 {
+		t.Errorf("Notes = %v, want 2", result.Notes)
+	}
+}
+
+// TestImportDependabot tests translation of Dependabot NuGet ignore rules and registries
+func TestImportDependabot(t *testing.T) {
+	data := []byte(`
+version: 2
+registries:
+  internal:
+    type: nuget-feed
+    url: https://nuget.example.com/v3/index.json
+  docker:
+    type: docker-registry
+    url: https://registry.example.com
+updates:
+  - package-ecosystem: nuget
+    directory: /
+    schedule:
+      interval: weekly
+    ignore:
+      - dependency-name: Newtonsoft.Json
+        versions: [">= 13"]
+      - dependency-name: Serilog
+        update-types: ["version-update:semver-major"]
+  - package-ecosystem: npm
+    directory: /web
+    ignore:
+      - dependency-name: react
+`)
+
+	result, err := ImportConfig("dependabot", data)
+	if err != nil {
+		t.Fatalf("ImportConfig() error = %v", err)
+	}
+
+	if len(result.PinnedPackages) != 2 {
+		t.Fatalf("PinnedPackages = %+v, want 2 rules", result.PinnedPackages)
+	}
+	if p := result.PinnedPackages[0]; p.Package != "Newtonsoft.Json" || p.Version != "(,13)" {
+		t.Errorf("PinnedPackages[0] = %+v, want Newtonsoft.Json (,13)", p)
+	}
+	if p := result.PinnedPackages[1]; p.Package != "Serilog" || p.Version != "" {
+		t.Errorf("PinnedPackages[1] = %+v, want Serilog pinned to installed version", p)
+	}
+	if len(result.Feeds) != 1 || result.Feeds[0].Name != "internal" {
+		t.Errorf("Feeds = %+v, want internal nuget feed only", result.Feeds)
+	}
+	if len(result.Notes) != 2 {
+		t.Errorf("Notes = %v, want 2 (schedule and update-types)", result.Notes)
+	}
+}
+
+// TestImportLazygitKeys tests translation of lazygit key syntax to LazyNuGet keys
+func TestImportLazygitKeys(t *testing.T) {
+	data := []byte(`
+keybinding:
+  universal:
+    refresh: <c-r>
+    confirm: <enter>
+    return: <esc>
+    nextPage: <pgdown>
+    togglePanel: <a-enter>
+    quit: <c-Q>
+    startSearch: <mouse-wheel>
+`)
+
+	result, err := ImportConfig("lazygit", data)
+	if err != nil {
+		t.Fatalf("ImportConfig() error = %v", err)
+	}
+
+	want := map[string]string{
+		"refresh":    "ctrl+r",
+		"select":     "enter",
+		"back":       "esc",
+		"page_down":  "pgdown",
+		"next_panel": "alt+enter",
+		"quit":       "ctrl+q",
+	}
+	for action, key := range want {
+		if got := result.Keybindings[action].Key; got != key {
+			t.Errorf("Keybindings[%s].Key = %q, want %q", action, got, key)
+		}
+	}
+	if _, ok := result.Keybindings["search"]; ok {
+		t.Errorf("Keybindings[search] = %+v, want untranslatable key skipped", result.Keybindings["search"])
+	}
+	if len(result.Notes) != 1 || !strings.Contains(result.Notes[0], "<mouse-wheel>") {
+		t.Errorf("Notes = %v, want one note for <mouse-wheel>", result.Notes)
+	}
+}
+
+// TestIgnoredVersionsToNuGet tests conversion of Dependabot ignored ranges
+func TestIgnoredVersionsToNuGet(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{">= 14", "(,14)", true},
+		{"> 14.1", "(,14.1]", true},
+		{"< 2", "[2,)", true},
+		{"<= 2.0", "(2.0,)", true},
+		{">=3.0.0", "(,3.0.0)", true},
+		{">= 14, < 15", "", false},
+		{">= 14, >= 15", "", false},
+		{"14.x", "", false},
+		{">= 14,", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ignoredVersionsToNuGet(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ignoredVersionsToNuGet(%q) = (%q, %v), want (%q, %v)", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestImportDependabotBoundedRange tests that a range ignored on both sides is reported
+// rather than written as an invalid NuGet range
+func TestImportDependabotBoundedRange(t *testing.T) {
+	data := []byte(`
+version: 2
+updates:
+  - package-ecosystem: nuget
+    directory: /
+    ignore:
+      - dependency-name: Npgsql
+        versions: [">= 14, < 15"]
+`)
+
+	result, err := ImportConfig("dependabot", data)
+	if err != nil {
+		t.Fatalf("ImportConfig() error = %v", err)
+	}
+	if len(result.PinnedPackages) != 0 {
+		t.Errorf("PinnedPackages = %+v, want none", result.PinnedPackages)
+	}
+	if len(result.Notes) != 1 || !strings.Contains(result.Notes[0], ">= 14, < 15") {
+		t.Errorf("Notes = %v, want one note for the bounded range", result.Notes)
+	}
+}
+
+// TestImportConfigUnsupportedSource tests rejection of unknown tools
+func TestImportConfigUnsupportedSource(t *testing.T) {
+	if _, err := ImportConfig("gitkraken", nil); err == nil {
+		t.Error("ImportConfig() expected error for unsupported source")
+	}
+}
+
+// TestImportResultYAMLValidates verifies imported output loads as a valid config
+func TestImportResultYAMLValidates(t *testing.T) {
+	result, err := ImportConfig("lazygit", []byte("gui:\n  theme:\n    activeBorderColor: [green]\nkeybinding:\n  universal:\n    quit: Q\n"))
+	if err != nil {
+		t.Fatalf("ImportConfig() error = %v", err)
+	}
+
+	out, err := result.YAML()
+	if err != nil {
+		t.Fatalf("YAML() error = %v", err)
+	}
+	if strings.Contains(string(out), "notes") {
+		t.Errorf("YAML() output includes notes:\n%s", out)
+	}
+
+	cfg := GetDefaultConfig()
+	if err := yaml.Unmarshal(out, cfg); err != nil {
+		t.Fatalf("imported YAML does not parse: %v\n%s", err, out)
+	}
+
+	loader := NewLoader()
+	validationErrors, err := loader.Validate(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	for _, ve := range validationErrors {
+		if strings.HasPrefix(ve.Key, "colorScheme") || strings.HasPrefix(ve.Key, "keybinding") {
+			t.Errorf("imported setting failed validation: %v", ve)
+		}
+	}
+}
+
+// TestSemverRangeToNuGet tests Renovate range conversion
+func TestSemverRangeToNuGet(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   string
+		wantOK bool
+	}{
+		{input: "<2.0", want: "(,2.0)", wantOK: true},
+		{input: "<=2.0", want: "(,2.0]", wantOK: true},
+		{input: ">=1.0 <2.0", want: "[1.0,2.0)", wantOK: true},
+		{input: ">1.0", want: "(1.0,)", wantOK: true},
+		{input: "1.2.3", want: "[1.2.3]", wantOK: true},
+		{input: "/^1\\./", wantOK: false},
+		{input: "^1.0", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := semverRangeToNuGet(tt.input)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("semverRangeToNuGet(%q) = (%q, %v), want (%q, %v)", tt.input, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
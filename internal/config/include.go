@@ -0,0 +1,126 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// configFiles returns the files that make up the config at filePath, in the order they
+// apply: each fragment listed under include (after the fragments it includes itself),
+// then the file. Include paths are relative to the file that lists them. A fragment that
+// is missing or unreadable is a blocking error, as is an include cycle.
+func configFiles(filePath string) ([]string, error) {
+	var files []string
+	if err := collectIncludes(filePath, nil, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// collectIncludes appends the fragments filePath includes, then filePath itself, to
+// files. stack holds the files including filePath, to detect cycles.
+func collectIncludes(filePath string, stack []string, files *[]string) error {
+	if slices.Contains(stack, filePath) {
+		return fmt.Errorf("include cycle: %s", strings.Join(append(stack, filePath), " -> "))
+	}
+	var cfg Config
+	if err := decodeConfigFile(filePath, &cfg); err != nil && !errors.Is(err, io.EOF) {
+		if len(stack) > 0 {
+			return fmt.Errorf("included from %s: %s: %w", stack[len(stack)-1], filePath, err)
+		}
+		return err
+	}
+	for _, include := range cfg.Include {
+		if strings.TrimSpace(include) == "" {
+			continue
+		}
+		path := include
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(filePath), path)
+		}
+		if err := collectIncludes(filepath.Clean(path), append(stack, filePath), files); err != nil {
+			return err
+		}
+	}
+	// A fragment included twice applies once, at its first place
+	if !slices.Contains(*files, filePath) {
+		*files = append(*files, filePath)
+	}
+	return nil
+}
+
+// decodeConfigFiles decodes files in order into a new config, so later files override
+// earlier ones: maps (keybindings) are merged and lists replace. Empty fragments are
+// allowed; an empty config file is a syntax error as before.
+func decodeConfigFiles(files []string) (*Config, error) {
+	var cfg Config
+	for i, file := range files {
+		err := decodeConfigFile(file, &cfg)
+		switch {
+		case err == nil:
+		case i == len(files)-1:
+			return nil, err
+		case !errors.Is(err, io.EOF):
+			return nil, fmt.Errorf("included %s: %w", file, err)
+		}
+	}
+	cfg.Include = nil // Each file's own list; meaningless once combined
+	return &cfg, nil
+}
+
+// dropOverridden removes the encrypted fields of earlier files whose top-level setting a
+// later file sets again, so a secret from a fragment never lands in a list the config
+// file replaced.
+func dropOverridden(fields map[string]*EncryptedValue, laterKeys map[string]bool) {
+	for path := range fields {
+		key, _, _ := strings.Cut(path, ".")
+		key, _, _ = strings.Cut(key, "[")
+		if laterKeys[key] {
+			delete(fields, path)
+		}
+	}
+}
+
+// topLevelKeys returns the settings a config file sets at its top level, by their YAML
+// names.
+func topLevelKeys(filePath string) (map[string]bool, error) {
+	data, err := readConfigFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool)
+	switch detectFormat(filePath) {
+	case FormatYAML:
+		var root yaml.Node
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return nil, fmt.Errorf("YAML parsing error: %w", err)
+		}
+		if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+			return keys, nil
+		}
+		doc := root.Content[0]
+		for i := 0; i+1 < len(doc.Content); i += 2 {
+			keys[doc.Content[i].Value] = true
+		}
+	case FormatTOML:
+		var raw map[string]any
+		if _, err := toml.Decode(string(data), &raw); err != nil {
+			return nil, fmt.Errorf("TOML parsing error: %w", err)
+		}
+		for key := range raw {
+			name, _ := tomlKeyToYAML(reflect.TypeOf(Config{}), key)
+			keys[name] = true
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config file format (must be .yml, .yaml, or .toml): %s", filePath)
+	}
+	return keys, nil
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes name -> content files under dir, creating subdirectories
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+// TestLoadInclude tests splitting a config into fragments of either format
+func TestLoadInclude(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yml": `include:
+  - keybindings.yml
+  - conf.d/feeds.toml
+theme: light
+keybindings:
+  refresh:
+    key: F5
+`,
+		"keybindings.yml": `theme: dark
+keybindings:
+  refresh:
+    key: r
+  search:
+    key: /
+`,
+		"conf.d/feeds.toml": `include = ["../empty.yml"]
+log_level = "debug"
+
+[[feeds]]
+name = "corp"
+url = "https://nuget.corp.example.com/v3/index.json"
+`,
+		"empty.yml": "",
+	})
+	configPath := filepath.Join(dir, "config.yml")
+
+	cfg, err := NewLoader().Load(context.Background(), LoadOptions{ConfigFilePath: configPath, NoProjectConfig: true})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// The including file wins; maps merge across files
+	if cfg.Theme != "light" {
+		t.Errorf("Theme = %q, want light from config.yml", cfg.Theme)
+	}
+	if cfg.Keybindings["refresh"].Key != "F5" || cfg.Keybindings["search"].Key != "/" {
+		t.Errorf("Keybindings = %+v, want refresh=F5 and search=/", cfg.Keybindings)
+	}
+	if cfg.LogLevel != "debug" || len(cfg.Feeds) != 1 || cfg.Feeds[0].Name != "corp" {
+		t.Errorf("LogLevel = %q, Feeds = %+v, want debug and the corp feed", cfg.LogLevel, cfg.Feeds)
+	}
+	want := []string{
+		filepath.Join(dir, "keybindings.yml"),
+		filepath.Join(dir, "empty.yml"),
+		filepath.Join(dir, "conf.d", "feeds.toml"),
+	}
+	if !slices.Equal(cfg.IncludedFiles, want) {
+		t.Errorf("IncludedFiles = %v, want %v", cfg.IncludedFiles, want)
+	}
+	if cfg.LoadedFrom != configPath {
+		t.Errorf("LoadedFrom = %q, want %q", cfg.LoadedFrom, configPath)
+	}
+}
+
+// TestLoadIncludeErrors tests that broken includes block loading
+func TestLoadIncludeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "missing fragment",
+			files:   map[string]string{"config.yml": "include: [missing.yml]\n"},
+			wantErr: "missing.yml",
+		},
+		{
+			name: "fragment syntax error",
+			files: map[string]string{
+				"config.yml": "include: [bad.yml]\n",
+				"bad.yml":    "theme: [unclosed\n",
+			},
+			wantErr: "bad.yml",
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"config.yml": "include: [a.yml]\n",
+				"a.yml":      "include: [b.yml]\n",
+				"b.yml":      "include: [a.yml]\n",
+			},
+			wantErr: "include cycle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			_, err := NewLoader().Load(context.Background(), LoadOptions{
+				ConfigFilePath:  filepath.Join(dir, "config.yml"),
+				NoProjectConfig: true,
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestLoadIncludeProfileAndUnknownKeys tests profiles and unknown-key warnings in fragments
+func TestLoadIncludeProfileAndUnknownKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yml": "include: [profiles.yml]\nlogLevel: info\n",
+		"profiles.yml": `themee: dark
+profiles:
+  work:
+    logLevel: debug
+`,
+	})
+
+	cfg, err := NewLoader().Load(context.Background(), LoadOptions{
+		ConfigFilePath:  filepath.Join(dir, "config.yml"),
+		NoProjectConfig: true,
+		Profile:         "work",
+	})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.LogLevel != "debug" {
+		t.Errorf("LogLevel = %q, want debug from the included profile", cfg.LogLevel)
+	}
+
+	_, err = NewLoader().Load(context.Background(), LoadOptions{
+		ConfigFilePath:  filepath.Join(dir, "config.yml"),
+		NoProjectConfig: true,
+		StrictMode:      true,
+	})
+	if err == nil || !strings.Contains(err.Error(), "themee") {
+		t.Errorf("strict Load() error = %v, want the unknown key from the fragment", err)
+	}
+}
+
+// TestDropOverridden tests that a later file's setting discards earlier secrets under it
+func TestDropOverridden(t *testing.T) {
+	fields := map[string]*EncryptedValue{
+		"feeds[0].apiKey":   {},
+		"hooks.env.TOKEN":   {},
+		"telemetry.apiKey":  {},
+		"feedFallbacks[0]":  {},
+		"feedsExtra.apiKey": {},
+	}
+	dropOverridden(fields, map[string]bool{"feeds": true, "hooks": true})
+	var kept []string
+	for path := range fields {
+		kept = append(kept, path)
+	}
+	slices.Sort(kept)
+	if want := []string{"feedFallbacks[0]", "feedsExtra.apiKey", "telemetry.apiKey"}; !slices.Equal(kept, want) {
+		t.Errorf("dropOverridden() kept %v, want %v", kept, want)
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// expandTag marks string settings that support ${ENV_VAR} interpolation (expand:"env").
+const expandTag = "expand"
+
+// expandEnvReferences replaces ${NAME} references in s with values from lookup.
+// "$${" escapes a literal "${"; a "$" not followed by "{" is kept as-is, so paths and
+// URLs containing "$" need no escaping. Undefined variables expand to an empty string
+// and are returned in undefined.
+func expandEnvReferences(s string, lookup func(string) (string, bool)) (expanded string, undefined []string, err error) {
+	if !strings.Contains(s, "${") {
+		return s, nil, nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "$${"):
+			sb.WriteString("${")
+			i += 3
+		case strings.HasPrefix(s[i:], "${"):
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return s, nil, fmt.Errorf("unterminated variable reference %q", s[i:])
+			}
+			name := s[i+2 : i+2+end]
+			if !isEnvVarName(name) {
+				return s, nil, fmt.Errorf("invalid variable reference ${%s}: names use letters, digits, and underscores", name)
+			}
+			value, ok := lookup(name)
+			if !ok {
+				undefined = append(undefined, name)
+			}
+			sb.WriteString(value)
+			i += 2 + end + 1
+		default:
+			sb.WriteByte(s[i])
+			i++
+		}
+	}
+	return sb.String(), undefined, nil
+}
+
+// isEnvVarName reports whether name is a valid environment variable name.
+func isEnvVarName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// interpolateConfig expands ${ENV_VAR} references in settings tagged expand:"env"
+// (dotnetPath, editor, logDir, feed URLs, plugin commands, sandbox paths).
+// Undefined variables expand to "" and malformed references are left unchanged; both are
+// reported as warnings, or as errors in strict mode so CI catches a missing variable.
+func interpolateConfig(cfg *Config, lookup func(string) (string, bool), strict bool) []ValidationError {
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	severity := "warning"
+	if strict {
+		severity = "error"
+	}
+
+	var errors []ValidationError
+	report := func(path, value, constraint string) {
+		errors = append(errors, ValidationError{
+			Key:          path,
+			Value:        value,
+			Constraint:   constraint,
+			SuggestedFix: "Set the variable or escape a literal ${ as $${",
+			Severity:     severity,
+		})
+	}
+
+	expand := func(field reflect.Value, path string) {
+		original := field.String()
+		expanded, undefined, err := expandEnvReferences(original, lookup)
+		if err != nil {
+			report(path, original, err.Error())
+			return
+		}
+		for _, name := range undefined {
+			report(path, original, fmt.Sprintf("environment variable %s is not defined", name))
+		}
+		field.SetString(expanded)
+	}
+
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if !field.IsExported() || name == "" || name == "-" {
+				continue
+			}
+			path := joinKeyPath(prefix, name)
+			value := v.Field(i)
+
+			if field.Tag.Get(expandTag) == "env" {
+				switch {
+				case value.Kind() == reflect.String:
+					expand(value, path)
+				case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.String:
+					for j := 0; j < value.Len(); j++ {
+						expand(value.Index(j), fmt.Sprintf("%s[%d]", path, j))
+					}
+				}
+				continue
+			}
+
+			switch {
+			case value.Kind() == reflect.Struct && value.Type() != reflect.TypeOf(cfg.LoadedAt):
+				walk(value, path)
+			case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Struct:
+				for j := 0; j < value.Len(); j++ {
+					walk(value.Index(j), fmt.Sprintf("%s[%d]", path, j))
+				}
+			}
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+
+	return errors
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestExpandEnvReferences tests ${VAR} expansion and escaping rules
+func TestExpandEnvReferences(t *testing.T) {
+	env := map[string]string{"HOME": "/home/dev", "FEED_HOST": "nuget.example.com", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		name          string
+		input         string
+		want          string
+		wantUndefined []string
+		wantErr       bool
+	}{
+		{name: "no references", input: "/usr/bin/dotnet", want: "/usr/bin/dotnet"},
+		{name: "single reference", input: "${HOME}/.dotnet/dotnet", want: "/home/dev/.dotnet/dotnet"},
+		{name: "reference in url", input: "https://${FEED_HOST}/v3/index.json", want: "https://nuget.example.com/v3/index.json"},
+		{name: "multiple references", input: "${HOME}:${FEED_HOST}", want: "/home/dev:nuget.example.com"},
+		{name: "defined but empty", input: "a${EMPTY}b", want: "ab"},
+		{name: "escaped reference", input: "$${HOME}/literal", want: "${HOME}/literal"},
+		{name: "bare dollar kept", input: "C:\\$Recycle.Bin\\$HOME", want: "C:\\$Recycle.Bin\\$HOME"},
+		{name: "undefined variable", input: "${MISSING}/logs", want: "/logs", wantUndefined: []string{"MISSING"}},
+		{name: "unterminated", input: "${HOME/logs", wantErr: true},
+		{name: "empty name", input: "${}", wantErr: true},
+		{name: "invalid name", input: "${HOME:-/tmp}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, undefined, err := expandEnvReferences(tt.input, lookup)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandEnvReferences(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("expandEnvReferences(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if strings.Join(undefined, ",") != strings.Join(tt.wantUndefined, ",") {
+				t.Errorf("undefined = %v, want %v", undefined, tt.wantUndefined)
+			}
+		})
+	}
+}
+
+// TestInterpolateConfig tests which settings are expanded and how problems are reported
+func TestInterpolateConfig(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		if name == "CORP" {
+			return "corp.example.com", true
+		}
+		return "", false
+	}
+
+	cfg := GetDefaultConfig()
+	cfg.DotnetPath = "/opt/${CORP}/dotnet"
+	cfg.LogDir = "${LOG_ROOT}/lazynuget"
+	cfg.Theme = "${CORP}" // Not an expandable setting
+	cfg.Feeds = []Feed{{Name: "${CORP}", URL: "https://${CORP}/v3/index.json"}}
+	cfg.Sandbox.WritablePaths = []string{"${CORP}/cache"}
+
+	errs := interpolateConfig(cfg, lookup, false)
+
+	if cfg.DotnetPath != "/opt/corp.example.com/dotnet" {
+		t.Errorf("DotnetPath = %q", cfg.DotnetPath)
+	}
+	if cfg.Feeds[0].URL != "https://corp.example.com/v3/index.json" || cfg.Feeds[0].Name != "${CORP}" {
+		t.Errorf("Feeds = %+v, want only the URL expanded", cfg.Feeds)
+	}
+	if cfg.Sandbox.WritablePaths[0] != "corp.example.com/cache" {
+		t.Errorf("WritablePaths = %v", cfg.Sandbox.WritablePaths)
+	}
+	if cfg.Theme != "${CORP}" {
+		t.Errorf("Theme = %q, settings without expand:\"env\" must not change", cfg.Theme)
+	}
+	if cfg.LogDir != "/lazynuget" {
+		t.Errorf("LogDir = %q, want undefined variable expanded to empty", cfg.LogDir)
+	}
+
+	if len(errs) != 1 || errs[0].Key != "logDir" || errs[0].Severity != "warning" || !strings.Contains(errs[0].Constraint, "LOG_ROOT") {
+		t.Fatalf("errors = %+v, want one warning for logDir naming LOG_ROOT", errs)
+	}
+
+	// Strict mode reports the same problem as an error
+	cfg.LogDir = "${LOG_ROOT}/lazynuget"
+	errs = interpolateConfig(cfg, lookup, true)
+	if len(errs) != 1 || errs[0].Severity != "error" {
+		t.Errorf("errors = %+v, want one error in strict mode", errs)
+	}
+}
+
+// TestLoadInterpolatesEnvVars tests interpolation through Load, including strict mode
+func TestLoadInterpolatesEnvVars(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	content := "dotnetPath: ${LAZYNUGET_TEST_DOTNET_ROOT}/dotnet\nfeeds:\n  - name: corp\n    url: https://${LAZYNUGET_TEST_FEED_HOST}/v3/index.json\n"
+	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("LAZYNUGET_TEST_DOTNET_ROOT", "/usr/share/dotnet")
+
+	opts := LoadOptions{ConfigFilePath: configPath, NoProjectConfig: true}
+
+	cfg, err := NewLoader().Load(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.DotnetPath != "/usr/share/dotnet/dotnet" {
+		t.Errorf("DotnetPath = %q, want expanded path", cfg.DotnetPath)
+	}
+
+	// The feed host is undefined: strict mode fails and names the variable
+	opts.StrictMode = true
+	_, err = NewLoader().Load(context.Background(), opts)
+	if err == nil || !strings.Contains(err.Error(), "LAZYNUGET_TEST_FEED_HOST") || !strings.Contains(err.Error(), "feeds[0].url") {
+		t.Errorf("Load() strict error = %v, want undefined LAZYNUGET_TEST_FEED_HOST in feeds[0].url", err)
+	}
+
+	t.Setenv("LAZYNUGET_TEST_FEED_HOST", "nuget.example.com")
+	cfg, err = NewLoader().Load(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Load() strict error = %v, want nil once the variable is set", err)
+	}
+	if cfg.Feeds[0].URL != "https://nuget.example.com/v3/index.json" {
+		t.Errorf("Feeds[0].URL = %q", cfg.Feeds[0].URL)
+	}
+}
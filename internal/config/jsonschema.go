@@ -0,0 +1,170 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// JSONSchemaDraft is the JSON Schema dialect emitted by JSONSchema.
+// Draft-07 is the newest draft supported by the common editor integrations
+// (VS Code YAML extension, JetBrains IDEs).
+const JSONSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// durationPattern matches Go duration strings ("30s", "1m30s", "500ms") and bare numbers of seconds.
+const durationPattern = `^([0-9]+(\.[0-9]+)?|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+
+// hexColorPattern matches the colors accepted by validateAndFixHexColor (#RRGGBB or #RRGGBBAA).
+const hexColorPattern = `^#([0-9A-Fa-f]{6}|[0-9A-Fa-f]{8})$`
+
+// JSONSchema renders the config schema as a JSON Schema document so editors can offer
+// completion and validation for hand-edited config.yml files.
+// Enum, range, and hex color constraints become enum, minimum/maximum, and pattern keywords;
+// constraints JSON Schema cannot express (e.g., minimum durations) stay in the description.
+func (cs *ConfigSchema) JSONSchema() ([]byte, error) {
+	root := map[string]any{
+		"$schema":              JSONSchemaDraft,
+		"title":                "LazyNuGet configuration",
+		"type":                 "object",
+		"properties":           map[string]any{},
+		"additionalProperties": false,
+	}
+
+	paths := make([]string, 0, len(cs.Settings))
+	for path := range cs.Settings {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		setting := cs.Settings[path]
+		parent := root
+		segments := strings.Split(path, ".")
+		for _, segment := range segments[:len(segments)-1] {
+			props := parent["properties"].(map[string]any)
+			child, ok := props[segment].(map[string]any)
+			if !ok {
+				child = map[string]any{
+					"type":                 "object",
+					"properties":           map[string]any{},
+					"additionalProperties": false,
+				}
+				props[segment] = child
+			}
+			parent = child
+		}
+		parent["properties"].(map[string]any)[segments[len(segments)-1]] = settingJSONSchema(&setting)
+	}
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// settingJSONSchema builds the property schema for a single setting.
+func settingJSONSchema(setting *SettingSchema) map[string]any {
+	prop := typeJSONSchema(setting.Type)
+	if setting.Description != "" {
+		description := setting.Description
+		if !setting.HotReloadable && !strings.Contains(description, "restart") {
+			description += " - requires restart"
+		}
+		prop["description"] = description
+	}
+
+	if def := jsonDefault(setting.Default); def != nil {
+		prop["default"] = def
+	}
+
+	for _, c := range setting.Constraints {
+		switch c.Type {
+		case "enum":
+			if values, ok := c.Params.([]string); ok {
+				// Enums on maps (logLevels) constrain each value
+				if entry, isMap := prop["additionalProperties"].(map[string]any); isMap {
+					entry["enum"] = values
+				} else {
+					prop["enum"] = values
+				}
+			}
+		case "range":
+			if bounds, ok := c.Params.(map[string]int); ok {
+				prop["minimum"] = bounds["min"]
+				prop["maximum"] = bounds["max"]
+			}
+		case "min":
+			// Duration minimums can't be expressed against a string; the description covers them
+			if minimum, ok := c.Params.(int); ok {
+				prop["minimum"] = minimum
+			}
+		case "hexcolor":
+			prop["pattern"] = hexColorPattern
+		}
+	}
+
+	return prop
+}
+
+// typeJSONSchema maps a Go type to its JSON Schema representation.
+// Structs use their yaml tags, matching the keys users write in config.yml.
+func typeJSONSchema(t reflect.Type) map[string]any {
+	if t == reflect.TypeOf(Config{}) {
+		// Profiles accept the same settings as the root document
+		return map[string]any{"$ref": "#"}
+	}
+	if t == reflect.TypeOf(time.Duration(0)) {
+		// Durations are Go duration strings or a number of seconds
+		return map[string]any{"type": []string{"string", "number"}, "pattern": durationPattern, "minimum": 0}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeJSONSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeJSONSchema(t.Elem())}
+	case reflect.Ptr:
+		return typeJSONSchema(t.Elem())
+	case reflect.Struct:
+		props := map[string]any{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if !field.IsExported() || name == "" || name == "-" {
+				continue
+			}
+			props[name] = typeJSONSchema(field.Type)
+		}
+		return map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+	default:
+		return map[string]any{}
+	}
+}
+
+// jsonDefault converts a schema default into its config file representation.
+// Returns nil for defaults that should not be emitted (nil slices and maps).
+func jsonDefault(v any) any {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case time.Duration:
+		if val == 0 {
+			return "0"
+		}
+		return val.String()
+	}
+
+	rv := reflect.ValueOf(v)
+	if (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map) && rv.IsNil() {
+		return nil
+	}
+	return v
+}
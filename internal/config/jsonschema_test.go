@@ -0,0 +1,109 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestJSONSchemaStructure tests the generated JSON Schema document
+func TestJSONSchemaStructure(t *testing.T) {
+	data, err := GetConfigSchema().JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema() error = %v", err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("JSONSchema() is not valid JSON: %v", err)
+	}
+	if doc["$schema"] != JSONSchemaDraft {
+		t.Errorf("$schema = %v, want %s", doc["$schema"], JSONSchemaDraft)
+	}
+
+	props := doc["properties"].(map[string]any)
+
+	logLevel := props["logLevel"].(map[string]any)
+	if !reflect.DeepEqual(logLevel["enum"], []any{"debug", "info", "warn", "error"}) {
+		t.Errorf("logLevel enum = %v", logLevel["enum"])
+	}
+	if logLevel["default"] != "info" || logLevel["description"] == "" {
+		t.Errorf("logLevel default/description = %v/%v", logLevel["default"], logLevel["description"])
+	}
+
+	maxOps := props["maxConcurrentOps"].(map[string]any)
+	if maxOps["type"] != "integer" || maxOps["minimum"] != float64(1) || maxOps["maximum"] != float64(16) {
+		t.Errorf("maxConcurrentOps = %v, want integer 1..16", maxOps)
+	}
+
+	timeouts := props["timeouts"].(map[string]any)["properties"].(map[string]any)
+	network := timeouts["networkRequest"].(map[string]any)
+	if types, _ := network["type"].([]any); len(types) != 2 || types[0] != "string" || types[1] != "number" || network["default"] != "30s" {
+		t.Errorf("timeouts.networkRequest = %v, want duration string or seconds defaulting to 30s", network)
+	}
+
+	border := props["colorScheme"].(map[string]any)["properties"].(map[string]any)["border"].(map[string]any)
+	if border["pattern"] != hexColorPattern {
+		t.Errorf("colorScheme.border pattern = %v", border["pattern"])
+	}
+
+	feeds := props["feeds"].(map[string]any)
+	items := feeds["items"].(map[string]any)["properties"].(map[string]any)
+	for _, key := range []string{"name", "url", "disabled"} {
+		if _, ok := items[key]; !ok {
+			t.Errorf("feeds items missing %q property", key)
+		}
+	}
+
+	keybinding := props["keybindings"].(map[string]any)["additionalProperties"].(map[string]any)
+	if _, ok := keybinding["properties"].(map[string]any)["key"]; !ok {
+		t.Errorf("keybindings values missing key property: %v", keybinding)
+	}
+
+	profile := props["keybindingProfile"].(map[string]any)
+	if !strings.Contains(profile["description"].(string), "restart") {
+		t.Errorf("non-hot-reloadable setting should mention restart: %v", profile["description"])
+	}
+}
+
+// TestJSONSchemaCoversConfig verifies every config file key appears in the JSON Schema,
+// so additionalProperties: false never rejects a valid config
+func TestJSONSchemaCoversConfig(t *testing.T) {
+	data, err := GetConfigSchema().JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema() error = %v", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	props := doc["properties"].(map[string]any)
+
+	configType := reflect.TypeOf(Config{})
+	for i := 0; i < configType.NumField(); i++ {
+		name, _, _ := strings.Cut(configType.Field(i).Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if _, ok := props[name]; !ok {
+			t.Errorf("config key %q missing from JSON Schema", name)
+		}
+	}
+}
+
+// TestDurationPattern verifies the duration pattern accepts Go durations and seconds
+func TestDurationPattern(t *testing.T) {
+	re := regexp.MustCompile(durationPattern)
+	for _, valid := range []string{"0", "30", "2.5", "30s", "1m30s", "500ms", "1.5h"} {
+		if !re.MatchString(valid) {
+			t.Errorf("durationPattern rejected %q", valid)
+		}
+	}
+	for _, invalid := range []string{"", "30 s", "thirty seconds", "5d"} {
+		if re.MatchString(invalid) {
+			t.Errorf("durationPattern accepted %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// keybindingsKey is the config key of the keybindings setting.
+const keybindingsKey = "keybindings"
+
+// UserConfigFile returns the config file settings are saved to: the file loaded, or
+// config.yml in the platform config directory when none was.
+func UserConfigFile(loadedFrom string) (string, error) {
+	if loadedFrom != "" && loadedFrom != GetDefaultConfig().LoadedFrom {
+		return loadedFrom, nil
+	}
+	dir := getPlatformConfigPath()
+	if dir == "" {
+		return "", errors.New("cannot determine the config directory; use --config")
+	}
+	return filepath.Join(dir, "config.yml"), nil
+}
+
+// SetKeybinding saves keybindings.<action> to a YAML config file, creating the file when
+// it does not exist. The rest of the file, comments included, is kept; only TOML files,
+// which would lose their comments, are refused.
+func SetKeybinding(path, action string, kb KeyBinding) error {
+	if detectFormat(path) != FormatYAML {
+		return fmt.Errorf("keybindings can only be saved to YAML config files; add %s.%s to %s yourself", keybindingsKey, action, path)
+	}
+
+	var root yaml.Node
+	mode := fs.FileMode(0o600)
+	// #nosec G304 -- the user's config file
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if info, statErr := os.Stat(path); statErr == nil {
+			mode = info.Mode().Perm()
+		}
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return fmt.Errorf("YAML parsing error in %s: %w", path, err)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if len(root.Content) == 0 {
+		root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s is not a mapping of settings", path)
+	}
+
+	bindings := mappingValue(doc, keybindingsKey)
+	if bindings.Kind != yaml.MappingNode {
+		*bindings = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	binding := mappingValue(bindings, action)
+	comment := binding.HeadComment
+	*binding = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", HeadComment: comment}
+	*mappingValue(binding, "key") = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: kb.Key, Style: yaml.DoubleQuotedStyle}
+	if kb.Context != "" {
+		*mappingValue(binding, "context") = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: kb.Context}
+	}
+	if kb.Description != "" {
+		*mappingValue(binding, "description") = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: kb.Description}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	// A reload triggered mid-write must never see half a file
+	if err := platform.WriteFileAtomic(path, buf.Bytes(), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// mappingValue returns the value of key in a mapping node, adding the key when missing.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	return value
+}
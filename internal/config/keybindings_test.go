@@ -0,0 +1,56 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSetKeybinding verifies keybindings are saved with the rest of the file kept
+func TestSetKeybinding(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yml")
+	original := "# My settings\ntheme: dark # the dark one\nkeybindings:\n  quit:\n    key: q\n"
+	if err := os.WriteFile(path, []byte(original), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if err := SetKeybinding(path, "refresh", KeyBinding{Key: "ctrl+r"}); err != nil {
+		t.Fatalf("SetKeybinding() error = %v", err)
+	}
+	if err := SetKeybinding(path, "quit", KeyBinding{Key: "ctrl+x ctrl+c", Context: "global"}); err != nil {
+		t.Fatalf("SetKeybinding() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	for _, want := range []string{"# My settings", "theme: dark # the dark one"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("config lost %q:\n%s", want, data)
+		}
+	}
+
+	cfg, err := NewLoader().Load(context.Background(), LoadOptions{ConfigFilePath: path, NoProjectConfig: true})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := cfg.Keybindings["quit"]; got.Key != "ctrl+x ctrl+c" || got.Context != "global" {
+		t.Errorf("quit = %+v", got)
+	}
+	if got := cfg.Keybindings["refresh"]; got.Key != "ctrl+r" {
+		t.Errorf("refresh = %+v", got)
+	}
+
+	// A missing file is created along with its directory
+	created := filepath.Join(dir, "new", "config.yml")
+	if err := SetKeybinding(created, "sort", KeyBinding{}); err != nil {
+		t.Fatalf("SetKeybinding() error = %v", err)
+	}
+	if data, _ := os.ReadFile(created); string(data) != "keybindings:\n  sort:\n    key: \"\"\n" {
+		t.Errorf("created config = %q", data)
+	}
+
+	if err := SetKeybinding(filepath.Join(dir, "config.toml"), "quit", KeyBinding{Key: "q"}); err == nil {
+		t.Error("SetKeybinding() saved to a TOML file")
+	}
+}
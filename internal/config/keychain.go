@@ -79,12 +79,15 @@ func NewKeychainManager() KeychainManager {
 
 // Store saves an encryption key to the platform keychain.
 // See: T124, FR-017
-func (km *keychainManager) Store(_ context.Context, keyID string, key []byte) error {
+func (km *keychainManager) Store(ctx context.Context, keyID string, key []byte) error {
 	// Encode key as hex for storage
 	keyHex := hex.EncodeToString(key)
 
 	// Store in platform keychain
-	if err := keyring.Set(keychainService, keyID, keyHex); err != nil {
+	_, err := keyringCall(ctx, func() (struct{}, error) {
+		return struct{}{}, keyring.Set(keychainService, keyID, keyHex)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to store key in keychain: %w", err)
 	}
 
@@ -94,9 +97,15 @@ func (km *keychainManager) Store(_ context.Context, keyID string, key []byte) er
 // Retrieve fetches an encryption key from the platform keychain.
 // Falls back to environment variable if keychain unavailable.
 // See: T125, FR-017
-func (km *keychainManager) Retrieve(_ context.Context, keyID string) ([]byte, error) {
+func (km *keychainManager) Retrieve(ctx context.Context, keyID string) ([]byte, error) {
 	// Try to retrieve from keychain first
-	keyHex, err := keyring.Get(keychainService, keyID)
+	keyHex, err := keyringCall(ctx, func() (string, error) {
+		return keyring.Get(keychainService, keyID)
+	})
+	if err != nil && ctx.Err() != nil {
+		// Cancelled rather than missing; don't fall back to the environment
+		return nil, ctx.Err()
+	}
 	if err == nil {
 		// Decode hex to bytes
 		key, err := hex.DecodeString(keyHex)
@@ -129,8 +138,11 @@ func (km *keychainManager) Retrieve(_ context.Context, keyID string) ([]byte, er
 
 // Delete removes an encryption key from the platform keychain.
 // See: T126
-func (km *keychainManager) Delete(_ context.Context, keyID string) error {
-	if err := keyring.Delete(keychainService, keyID); err != nil {
+func (km *keychainManager) Delete(ctx context.Context, keyID string) error {
+	_, err := keyringCall(ctx, func() (struct{}, error) {
+		return struct{}{}, keyring.Delete(keychainService, keyID)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to delete key from keychain: %w", err)
 	}
 	return nil
@@ -147,10 +159,12 @@ func (km *keychainManager) List(_ context.Context) ([]string, error) {
 
 // IsAvailable checks if the platform keychain is accessible.
 // See: T128
-func (km *keychainManager) IsAvailable(_ context.Context) bool {
+func (km *keychainManager) IsAvailable(ctx context.Context) bool {
 	// Try to perform a test operation (get a non-existent key)
 	// If we get an error other than "not found", keychain is unavailable
-	_, err := keyring.Get(keychainService, "test-availability-check")
+	_, err := keyringCall(ctx, func() (string, error) {
+		return keyring.Get(keychainService, "test-availability-check")
+	})
 	if err == nil {
 		// Key exists (unlikely but possible)
 		return true
@@ -166,3 +180,30 @@ func (km *keychainManager) IsAvailable(_ context.Context) bool {
 	// Keychain is unavailable
 	return false
 }
+
+// keyringCall runs a keyring operation, returning early with ctx's error when ctx is done
+// first. Platform keychains can block on an unlock prompt or a D-Bus service that never
+// answers; the operation is left to finish in the background.
+func keyringCall[T any](ctx context.Context, call func() (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := call()
+		done <- result{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
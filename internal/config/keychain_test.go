@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"os"
 	"strings"
 	"testing"
@@ -186,6 +187,24 @@ func TestKeychainEnvironmentVariableFallback(t *testing.T) {
 	}
 }
 
+// TestKeychainRetrieveCancelled tests that a cancelled context stops Retrieve instead of
+// falling back to the environment
+func TestKeychainRetrieveCancelled(t *testing.T) {
+	km := NewKeychainManager()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	keyID := "cancelled-test"
+	t.Setenv("LAZYNUGET_ENCRYPTION_KEY_"+strings.ToUpper(keyID), hex.EncodeToString([]byte("environment-variable-key-value!")))
+
+	if _, err := km.Retrieve(ctx, keyID); !errors.Is(err, context.Canceled) {
+		t.Errorf("Retrieve() error = %v, want context.Canceled", err)
+	}
+	if err := km.Store(ctx, keyID, []byte("key")); !errors.Is(err, context.Canceled) {
+		t.Errorf("Store() error = %v, want context.Canceled", err)
+	}
+}
+
 // TestKeychainBase64Fallback tests base64 encoded environment variables
 func TestKeychainBase64Fallback(t *testing.T) {
 	km := NewKeychainManager()
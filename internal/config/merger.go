@@ -2,6 +2,7 @@ package config
 
 import (
 	"maps"
+	"strings"
 	"time"
 )
 
@@ -51,6 +52,7 @@ func mergeConfigs(base, override *Config) *Config {
 	if override.ColorScheme.Info != "" && override.ColorScheme.Info != base.ColorScheme.Info {
 		merged.ColorScheme.Info = override.ColorScheme.Info
 	}
+	mergePanelColors(&merged.ColorScheme, override.ColorScheme)
 
 	// Bool fields - merge if different from base
 	merged.CompactMode = override.CompactMode
@@ -60,6 +62,15 @@ func mergeConfigs(base, override *Config) *Config {
 	if override.DateFormat != "" && override.DateFormat != base.DateFormat {
 		merged.DateFormat = override.DateFormat
 	}
+	if override.StaleAfterYears != 0 && override.StaleAfterYears != base.StaleAfterYears {
+		merged.StaleAfterYears = override.StaleAfterYears
+	}
+	if override.PackageIcons != "" && override.PackageIcons != base.PackageIcons {
+		merged.PackageIcons = override.PackageIcons
+	}
+	if override.Editor != "" && override.Editor != base.Editor {
+		merged.Editor = override.Editor
+	}
 
 	// Keybindings
 	if override.KeybindingProfile != "" && override.KeybindingProfile != base.KeybindingProfile {
@@ -73,16 +84,30 @@ func mergeConfigs(base, override *Config) *Config {
 		maps.Copy(merged.Keybindings, override.Keybindings)
 	}
 
+	// Profiles (the selected profile is already applied; kept for --print-config)
+	if override.Profiles != nil {
+		merged.Profiles = override.Profiles
+	}
+
 	// Performance
 	if override.MaxConcurrentOps != 0 && override.MaxConcurrentOps != base.MaxConcurrentOps {
 		merged.MaxConcurrentOps = override.MaxConcurrentOps
 	}
+	if override.RenderFPS != 0 && override.RenderFPS != base.RenderFPS {
+		merged.RenderFPS = override.RenderFPS
+	}
 	if override.CacheSize != 0 && override.CacheSize != base.CacheSize {
 		merged.CacheSize = override.CacheSize
 	}
 	if override.RefreshInterval != 0 && override.RefreshInterval != base.RefreshInterval {
 		merged.RefreshInterval = override.RefreshInterval
 	}
+	if override.AdvisoryCacheTTL != 0 && override.AdvisoryCacheTTL != base.AdvisoryCacheTTL {
+		merged.AdvisoryCacheTTL = override.AdvisoryCacheTTL
+	}
+	if override.PrefetchDelay != 0 && override.PrefetchDelay != base.PrefetchDelay {
+		merged.PrefetchDelay = override.PrefetchDelay
+	}
 
 	// Timeouts
 	if override.Timeouts.NetworkRequest != 0 && override.Timeouts.NetworkRequest != base.Timeouts.NetworkRequest {
@@ -102,6 +127,12 @@ func mergeConfigs(base, override *Config) *Config {
 	if override.DotnetVerbosity != "" && override.DotnetVerbosity != base.DotnetVerbosity {
 		merged.DotnetVerbosity = override.DotnetVerbosity
 	}
+	if override.OperationBackend != "" && override.OperationBackend != base.OperationBackend {
+		merged.OperationBackend = override.OperationBackend
+	}
+	if override.RestoreMode != "" && override.RestoreMode != base.RestoreMode {
+		merged.RestoreMode = override.RestoreMode
+	}
 
 	// Logging
 	if override.LogLevel != "" && override.LogLevel != base.LogLevel {
@@ -113,6 +144,14 @@ func mergeConfigs(base, override *Config) *Config {
 	if override.LogFormat != "" && override.LogFormat != base.LogFormat {
 		merged.LogFormat = override.LogFormat
 	}
+	if len(override.LogLevels) > 0 {
+		// Merge per-module levels without mutating the base map
+		merged.LogLevels = maps.Clone(base.LogLevels)
+		if merged.LogLevels == nil {
+			merged.LogLevels = make(map[string]string, len(override.LogLevels))
+		}
+		maps.Copy(merged.LogLevels, override.LogLevels)
+	}
 
 	// Log Rotation
 	if override.LogRotation.MaxSize != 0 && override.LogRotation.MaxSize != base.LogRotation.MaxSize {
@@ -129,8 +168,103 @@ func mergeConfigs(base, override *Config) *Config {
 	// Hot-Reload
 	merged.HotReload = override.HotReload
 
+	// Release notifications
+	merged.UpdateCheck = override.UpdateCheck
+
+	// Team policy - lists replace rather than append so a source can clear inherited entries
+	if override.PinnedPackages != nil {
+		merged.PinnedPackages = override.PinnedPackages
+	}
+	if override.LicensePolicy.Allowed != nil {
+		merged.LicensePolicy.Allowed = override.LicensePolicy.Allowed
+	}
+	if override.LicensePolicy.Denied != nil {
+		merged.LicensePolicy.Denied = override.LicensePolicy.Denied
+	}
+	if override.Feeds != nil {
+		merged.Feeds = override.Feeds
+	}
+	if override.FeedFallbacks != nil {
+		merged.FeedFallbacks = override.FeedFallbacks
+	}
+
+	// Status bar
+	if override.StatusBar.Format != "" {
+		merged.StatusBar.Format = override.StatusBar.Format
+	}
+	if override.StatusBar.ClockFormat != "" {
+		merged.StatusBar.ClockFormat = override.StatusBar.ClockFormat
+	}
+
+	// Confirmations
+	if override.Confirm.Add != "" {
+		merged.Confirm.Add = override.Confirm.Add
+	}
+	if override.Confirm.Remove != "" {
+		merged.Confirm.Remove = override.Confirm.Remove
+	}
+	if override.Confirm.Update != "" {
+		merged.Confirm.Update = override.Confirm.Update
+	}
+	if override.Confirm.Bulk != "" {
+		merged.Confirm.Bulk = override.Confirm.Bulk
+	}
+
+	// Telemetry
+	if override.Telemetry.Endpoint != "" {
+		merged.Telemetry.Endpoint = override.Telemetry.Endpoint
+	}
+
+	// Hooks
+	if override.Hooks.PreInstall != nil {
+		merged.Hooks.PreInstall = override.Hooks.PreInstall
+	}
+	if override.Hooks.PostUpdate != nil {
+		merged.Hooks.PostUpdate = override.Hooks.PostUpdate
+	}
+	if override.Hooks.PostRestore != nil {
+		merged.Hooks.PostRestore = override.Hooks.PostRestore
+	}
+
+	// Custom commands
+	if override.CustomCommands != nil {
+		merged.CustomCommands = override.CustomCommands
+	}
+
+	// Saved filters
+	if override.SavedFilters != nil {
+		merged.SavedFilters = override.SavedFilters
+	}
+
+	// Plugins
+	if override.Plugins != nil {
+		merged.Plugins = override.Plugins
+	}
+
+	// Sandbox
+	merged.Sandbox.Enabled = override.Sandbox.Enabled
+	merged.Sandbox.AllowNetwork = override.Sandbox.AllowNetwork
+	if override.Sandbox.WritablePaths != nil {
+		merged.Sandbox.WritablePaths = override.Sandbox.WritablePaths
+	}
+
 	// Update metadata to reflect merge
 	merged.LoadedAt = time.Now()
 
 	return &merged
 }
+
+// mergePanelColors applies the panel colors an override sets; unset ones keep the base's.
+func mergePanelColors(merged *ColorScheme, override ColorScheme) {
+	overrides := make(map[string]string)
+	colorFields(&override, func(key string, value *string) {
+		if strings.Contains(key, ".") && *value != "" {
+			overrides[key] = *value
+		}
+	})
+	colorFields(merged, func(key string, value *string) {
+		if color, ok := overrides[key]; ok {
+			*value = color
+		}
+	})
+}
@@ -267,6 +267,42 @@ func TestMergeConfigsLogRotation(t *testing.T) {
 	}
 }
 
+// TestMergeConfigsSandbox tests sandbox settings
+func TestMergeConfigsSandbox(t *testing.T) {
+	base := &Config{Sandbox: SandboxConfig{WritablePaths: []string{"/base"}}}
+	override := &Config{Sandbox: SandboxConfig{Enabled: true, AllowNetwork: true}}
+
+	merged := mergeConfigs(base, override)
+
+	if !merged.Sandbox.Enabled || !merged.Sandbox.AllowNetwork {
+		t.Errorf("Sandbox = %+v, want enabled with network", merged.Sandbox)
+	}
+	if len(merged.Sandbox.WritablePaths) != 1 || merged.Sandbox.WritablePaths[0] != "/base" {
+		t.Errorf("WritablePaths = %v, want base paths kept when override has none", merged.Sandbox.WritablePaths)
+	}
+
+	override.Sandbox.WritablePaths = []string{"/override"}
+	merged = mergeConfigs(base, override)
+	if len(merged.Sandbox.WritablePaths) != 1 || merged.Sandbox.WritablePaths[0] != "/override" {
+		t.Errorf("WritablePaths = %v, want override to replace", merged.Sandbox.WritablePaths)
+	}
+}
+
+// TestMergeConfigsLogLevels tests that module log levels merge per module
+func TestMergeConfigsLogLevels(t *testing.T) {
+	base := &Config{LogLevels: map[string]string{"nuget": "debug", "gui": "warn"}}
+	override := &Config{LogLevels: map[string]string{"gui": "error"}}
+
+	merged := mergeConfigs(base, override)
+
+	if merged.LogLevels["nuget"] != "debug" || merged.LogLevels["gui"] != "error" {
+		t.Errorf("LogLevels = %v, want nuget=debug gui=error", merged.LogLevels)
+	}
+	if base.LogLevels["gui"] != "warn" {
+		t.Errorf("base LogLevels modified: %v", base.LogLevels)
+	}
+}
+
 // TestMergeConfigsAllTimeouts tests all Timeout fields
 func TestMergeConfigsAllTimeouts(t *testing.T) {
 	base := &Config{
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// projectConfigFileNames lists the repository-level overlay file names in lookup order.
+var projectConfigFileNames = []string{".lazynuget.yml", ".lazynuget.yaml", ".lazynuget.toml"}
+
+// FindProjectConfig walks up from startDir looking for a repository-level config overlay
+// (.lazynuget.yml, .lazynuget.yaml, or .lazynuget.toml). The nearest file wins, so a
+// solution directory can override the repository root.
+//
+// The search stops after the repository root (the first directory containing .git) and
+// never considers the user's home directory, which belongs to user-level configuration.
+// Returns an empty path when no overlay exists.
+func FindProjectConfig(startDir string) (string, error) {
+	dir, err := filepath.Abs(startDir)
+	if err != nil {
+		return "", fmt.Errorf("invalid working directory: %w", err)
+	}
+
+	home, _ := os.UserHomeDir()
+
+	for {
+		if home != "" && dir == filepath.Clean(home) {
+			return "", nil
+		}
+
+		for _, name := range projectConfigFileNames {
+			candidate := filepath.Join(dir, name)
+			if info, statErr := os.Stat(candidate); statErr == nil && info.Mode().IsRegular() {
+				return candidate, nil
+			}
+		}
+
+		// Stop at the repository root
+		if _, statErr := os.Stat(filepath.Join(dir, ".git")); statErr == nil {
+			return "", nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// applyProjectConfig decodes the content of the project overlay at path on top of cfg.
+// Only keys present in the overlay change; maps (keybindings) are merged and
+// lists (pinnedPackages, feeds, licensePolicy) replace the user's lists.
+// An empty overlay file is allowed and changes nothing.
+func applyProjectConfig(cfg *Config, path string, data []byte) error {
+	if err := decodeConfigData(path, data, cfg); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return fmt.Errorf("failed to load project config %s: %w", path, err)
+	}
+
+	cfg.ProjectConfigPath = path
+	return nil
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFindProjectConfig tests overlay discovery from nested directories
+func TestFindProjectConfig(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o700); err != nil {
+		t.Fatalf("Failed to create .git: %v", err)
+	}
+	nested := filepath.Join(repo, "src", "App")
+	if err := os.MkdirAll(nested, 0o700); err != nil {
+		t.Fatalf("Failed to create nested dir: %v", err)
+	}
+
+	// No overlay anywhere
+	got, err := FindProjectConfig(nested)
+	if err != nil {
+		t.Fatalf("FindProjectConfig() error = %v", err)
+	}
+	if got != "" {
+		t.Errorf("FindProjectConfig() = %q, want empty", got)
+	}
+
+	// Overlay at the repository root is found from a nested directory
+	rootOverlay := filepath.Join(repo, ".lazynuget.yml")
+	if err := os.WriteFile(rootOverlay, []byte("theme: dark\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write overlay: %v", err)
+	}
+	got, err = FindProjectConfig(nested)
+	if err != nil {
+		t.Fatalf("FindProjectConfig() error = %v", err)
+	}
+	if got != rootOverlay {
+		t.Errorf("FindProjectConfig() = %q, want %q", got, rootOverlay)
+	}
+
+	// A nearer overlay (per-solution) wins over the repository root
+	solutionOverlay := filepath.Join(repo, "src", ".lazynuget.toml")
+	if err := os.WriteFile(solutionOverlay, []byte("theme = \"light\"\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write overlay: %v", err)
+	}
+	got, err = FindProjectConfig(nested)
+	if err != nil {
+		t.Fatalf("FindProjectConfig() error = %v", err)
+	}
+	if got != solutionOverlay {
+		t.Errorf("FindProjectConfig() = %q, want %q", got, solutionOverlay)
+	}
+}
+
+// TestFindProjectConfigStopsAtRepoRoot verifies overlays above the repository are ignored
+func TestFindProjectConfigStopsAtRepoRoot(t *testing.T) {
+	outer := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outer, ".lazynuget.yml"), []byte("theme: dark\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write overlay: %v", err)
+	}
+	repo := filepath.Join(outer, "repo")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0o700); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+
+	got, err := FindProjectConfig(repo)
+	if err != nil {
+		t.Fatalf("FindProjectConfig() error = %v", err)
+	}
+	if got != "" {
+		t.Errorf("FindProjectConfig() = %q, want empty (overlay is outside the repository)", got)
+	}
+}
+
+// TestApplyProjectConfigOnlyOverridesPresentKeys verifies overlays leave other settings untouched
+func TestApplyProjectConfigOnlyOverridesPresentKeys(t *testing.T) {
+	overlay := filepath.Join(t.TempDir(), ".lazynuget.yml")
+	content := `
+pinnedPackages:
+  - package: Newtonsoft.Json
+    version: 13.0.1
+    reason: breaking serializer changes in 13.0.3
+licensePolicy:
+  denied: [GPL-3.0-only]
+`
+	if err := os.WriteFile(overlay, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write overlay: %v", err)
+	}
+
+	cfg := GetDefaultConfig()
+	cfg.ShowHints = true
+	cfg.Theme = "dark"
+
+	if err := applyProjectConfig(cfg, overlay, []byte(content)); err != nil {
+		t.Fatalf("applyProjectConfig() error = %v", err)
+	}
+
+	if !cfg.ShowHints || cfg.Theme != "dark" {
+		t.Errorf("settings absent from overlay changed: showHints=%v theme=%s", cfg.ShowHints, cfg.Theme)
+	}
+	if len(cfg.PinnedPackages) != 1 || cfg.PinnedPackages[0].Package != "Newtonsoft.Json" {
+		t.Errorf("PinnedPackages = %+v, want Newtonsoft.Json pin", cfg.PinnedPackages)
+	}
+	if len(cfg.LicensePolicy.Denied) != 1 || cfg.LicensePolicy.Denied[0] != "GPL-3.0-only" {
+		t.Errorf("LicensePolicy.Denied = %v, want [GPL-3.0-only]", cfg.LicensePolicy.Denied)
+	}
+	if cfg.ProjectConfigPath != overlay {
+		t.Errorf("ProjectConfigPath = %q, want %q", cfg.ProjectConfigPath, overlay)
+	}
+}
+
+// TestApplyProjectConfigEmptyFile verifies an empty overlay is not an error
+func TestApplyProjectConfigEmptyFile(t *testing.T) {
+	overlay := filepath.Join(t.TempDir(), ".lazynuget.yml")
+	if err := os.WriteFile(overlay, []byte("# nothing yet\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write overlay: %v", err)
+	}
+
+	if err := applyProjectConfig(GetDefaultConfig(), overlay, []byte("# nothing yet\n")); err != nil {
+		t.Errorf("applyProjectConfig() error = %v, want nil for empty overlay", err)
+	}
+}
+
+// TestLoadProjectOverlayPrecedence verifies file < overlay < env var precedence
+func TestLoadProjectOverlayPrecedence(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "user", "config.yml")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o700); err != nil {
+		t.Fatalf("Failed to create user config dir: %v", err)
+	}
+	if err := os.WriteFile(configPath, []byte("theme: solarized\nlogLevel: warn\nmaxConcurrentOps: 2\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write user config: %v", err)
+	}
+
+	repo := filepath.Join(tmpDir, "repo")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0o700); err != nil {
+		t.Fatalf("Failed to create repo: %v", err)
+	}
+	overlay := "theme: dark\nlogLevel: debug\nfeeds:\n  - name: internal\n    url: https://nuget.example.com/v3/index.json\n"
+	if err := os.WriteFile(filepath.Join(repo, ".lazynuget.yml"), []byte(overlay), 0o600); err != nil {
+		t.Fatalf("Failed to write overlay: %v", err)
+	}
+
+	t.Setenv("LAZYNUGET_LOG_LEVEL", "error")
+
+	opts := LoadOptions{
+		ConfigFilePath: configPath,
+		EnvVarPrefix:   "LAZYNUGET_",
+		WorkingDir:     repo,
+	}
+
+	cfg, err := NewLoader().Load(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Theme != "dark" {
+		t.Errorf("Theme = %q, want overlay value %q", cfg.Theme, "dark")
+	}
+	if cfg.LogLevel != "error" {
+		t.Errorf("LogLevel = %q, want env value %q", cfg.LogLevel, "error")
+	}
+	if cfg.MaxConcurrentOps != 2 {
+		t.Errorf("MaxConcurrentOps = %d, want user file value 2", cfg.MaxConcurrentOps)
+	}
+	if len(cfg.Feeds) != 1 || cfg.Feeds[0].Name != "internal" {
+		t.Errorf("Feeds = %+v, want internal feed from overlay", cfg.Feeds)
+	}
+
+	// NoProjectConfig skips the overlay entirely
+	opts.NoProjectConfig = true
+	cfg, err = NewLoader().Load(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Theme != "solarized" {
+		t.Errorf("Theme = %q, want user file value %q when overlay disabled", cfg.Theme, "solarized")
+	}
+	if cfg.ProjectConfigPath != "" {
+		t.Errorf("ProjectConfigPath = %q, want empty when overlay disabled", cfg.ProjectConfigPath)
+	}
+}
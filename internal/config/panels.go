@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Panels are the panels whose colors colorScheme can override, by their colorScheme keys.
+var Panels = []string{"projects", "packages", "details", "statusbar"}
+
+// panelTitles name the panels in schema descriptions.
+var panelTitles = map[string]string{
+	"projects":  "the projects panel",
+	"packages":  "the packages panel",
+	"details":   "the details panel",
+	"statusbar": "the status bar",
+}
+
+// PanelColorFallbacks maps each PanelColors key to the flat colorScheme color it defaults
+// to. bg has none: a panel is drawn on the terminal's background unless it sets one.
+var PanelColorFallbacks = map[string]string{
+	"fg":          "text",
+	"bg":          "",
+	"border":      "border",
+	"borderFocus": "borderFocus",
+	"headerFg":    "text",
+	"selectedFg":  "background",
+	"selectedBg":  "highlight",
+}
+
+// panelColorDescriptions describe the PanelColors keys in the schema.
+var panelColorDescriptions = map[string]string{
+	"fg":          "Text color",
+	"bg":          "Background color",
+	"border":      "Border color",
+	"borderFocus": "Border color while focused",
+	"headerFg":    "Title color",
+	"selectedFg":  "Text color of the selected row",
+	"selectedBg":  "Background color of the selected row",
+}
+
+// Panel returns the colors of a panel, with the flat colors filled in where the panel has
+// no override. Unknown panels get the flat colors.
+func (cs ColorScheme) Panel(name string) PanelColors {
+	flat := make(map[string]string)
+	var panel PanelColors
+	colorFields(&cs, func(key string, value *string) {
+		if !strings.Contains(key, ".") {
+			flat[key] = *value
+		}
+	})
+	if p := cs.panel(name); p != nil {
+		panel = *p
+	}
+	v := reflect.ValueOf(&panel).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).String() == "" {
+			v.Field(i).SetString(flat[PanelColorFallbacks[yamlKey(v.Type().Field(i))]])
+		}
+	}
+	return panel
+}
+
+// panel returns the overrides of a panel by its colorScheme key, or nil.
+func (cs *ColorScheme) panel(name string) *PanelColors {
+	switch name {
+	case "projects":
+		return &cs.Projects
+	case "packages":
+		return &cs.Packages
+	case "details":
+		return &cs.Details
+	case "statusbar":
+		return &cs.StatusBar
+	default:
+		return nil
+	}
+}
+
+// colorFields calls fn with the key (relative to colorScheme, e.g., "packages.selectedBg")
+// and address of every color in cs, flat colors first.
+func colorFields(cs *ColorScheme, fn func(key string, value *string)) {
+	v := reflect.ValueOf(cs).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).Kind() == reflect.String {
+			fn(yamlKey(v.Type().Field(i)), v.Field(i).Addr().Interface().(*string))
+		}
+	}
+	for _, name := range Panels {
+		panel := reflect.ValueOf(cs.panel(name)).Elem()
+		for i := 0; i < panel.NumField(); i++ {
+			fn(name+"."+yamlKey(panel.Type().Field(i)), panel.Field(i).Addr().Interface().(*string))
+		}
+	}
+}
+
+// yamlKey returns the config file key of a struct field.
+func yamlKey(field reflect.StructField) string {
+	key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	return key
+}
+
+// addPanelColorSettings adds a schema entry for every panel color, so the JSON Schema and
+// the validator know the extended colorScheme.
+func addPanelColorSettings(settings map[string]SettingSchema) {
+	t := reflect.TypeOf(PanelColors{})
+	for _, panel := range Panels {
+		for i := 0; i < t.NumField(); i++ {
+			key := yamlKey(t.Field(i))
+			fallback := "the terminal's"
+			if flat := PanelColorFallbacks[key]; flat != "" {
+				fallback = "colorScheme." + flat
+			}
+			path := "colorScheme." + panel + "." + key
+			settings[path] = SettingSchema{
+				Path: path,
+				Type: reflect.TypeOf(""),
+				Constraints: []Constraint{
+					{Type: "hexcolor", Params: nil, Message: "must be valid hex color (#RRGGBB)"},
+				},
+				Default:       nil, // Unset: the fallback applies
+				HotReloadable: true,
+				Description:   fmt.Sprintf("%s in %s (default: %s)", panelColorDescriptions[key], panelTitles[panel], fallback),
+			}
+		}
+	}
+}
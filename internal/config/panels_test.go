@@ -0,0 +1,132 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestColorSchemePanel tests per-panel colors falling back to the flat colors
+func TestColorSchemePanel(t *testing.T) {
+	cs := GetDefaultConfig().ColorScheme
+	cs.Packages = PanelColors{SelectedBg: "#FF00FF", Bg: "#101010"}
+
+	packages := cs.Panel("packages")
+	want := PanelColors{
+		Fg: cs.Text, Bg: "#101010", Border: cs.Border, BorderFocus: cs.BorderFocus,
+		HeaderFg: cs.Text, SelectedFg: cs.Background, SelectedBg: "#FF00FF",
+	}
+	if packages != want {
+		t.Errorf("Panel(packages) = %+v, want %+v", packages, want)
+	}
+	want.Bg, want.SelectedBg = "", cs.Highlight
+	if details := cs.Panel("details"); details != want {
+		t.Errorf("Panel(details) = %+v, want the flat colors %+v", details, want)
+	}
+	if unknown := cs.Panel("sidebar"); unknown != want {
+		t.Errorf("Panel(sidebar) = %+v, want the flat colors", unknown)
+	}
+}
+
+// TestLoadPanelColors tests panel colors from files, environment variables, and profiles
+func TestLoadPanelColors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yml": `colorScheme:
+  highlight: "#0000FF"
+  packages:
+    selectedBg: "#FF00FF"
+  details:
+    headerFg: blue
+  statusbar:
+    bg: "#222222"
+profiles:
+  presentation:
+    colorScheme:
+      statusbar:
+        fg: "#FFFFFF"
+`,
+		"toml/config.toml": "[color_scheme.packages]\nselected_bg = \"#FF00FF\"\nborder_focus = \"#00FFFF\"\n",
+	})
+	t.Setenv("LAZYNUGET_COLOR_SCHEME_PROJECTS_SELECTED_FG", "#ABCDEF")
+
+	cfg, err := NewLoader().Load(context.Background(), LoadOptions{
+		ConfigFilePath:  filepath.Join(dir, "config.yml"),
+		EnvVarPrefix:    "LAZYNUGET_",
+		Profile:         "presentation",
+		NoProjectConfig: true,
+	})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	cs := cfg.ColorScheme
+	if cs.Packages.SelectedBg != "#FF00FF" || cs.Panel("packages").SelectedFg != cs.Background {
+		t.Errorf("packages = %+v, want selectedBg with the flat selected text", cs.Panel("packages"))
+	}
+	if cs.Panel("details").SelectedBg != "#0000FF" {
+		t.Errorf("details selectedBg = %q, want the flat highlight", cs.Panel("details").SelectedBg)
+	}
+	if cs.Details.HeaderFg != "" {
+		t.Errorf("details headerFg = %q, want the invalid color cleared", cs.Details.HeaderFg)
+	}
+	if cs.StatusBar.Bg != "#222222" || cs.StatusBar.Fg != "#FFFFFF" {
+		t.Errorf("statusbar = %+v, want bg from the file and fg from the profile", cs.StatusBar)
+	}
+	if cs.Projects.SelectedFg != "#ABCDEF" {
+		t.Errorf("projects selectedFg = %q, want the environment variable", cs.Projects.SelectedFg)
+	}
+
+	_, err = NewLoader().Load(context.Background(), LoadOptions{
+		ConfigFilePath: filepath.Join(dir, "config.yml"), NoProjectConfig: true, StrictMode: true,
+	})
+	if err == nil || !strings.Contains(err.Error(), "colorScheme.details.headerFg") {
+		t.Errorf("strict Load() error = %v, want the invalid panel color", err)
+	}
+
+	cfg, err = NewLoader().Load(context.Background(), LoadOptions{
+		ConfigFilePath: filepath.Join(dir, "toml", "config.toml"), NoProjectConfig: true, StrictMode: true,
+	})
+	if err != nil {
+		t.Fatalf("Load(toml) error = %v", err)
+	}
+	if cfg.ColorScheme.Packages.SelectedBg != "#FF00FF" || cfg.ColorScheme.Packages.BorderFocus != "#00FFFF" {
+		t.Errorf("toml packages = %+v", cfg.ColorScheme.Packages)
+	}
+}
+
+// TestPanelColorsSchema tests that the schema declares every panel color as a hex color
+func TestPanelColorsSchema(t *testing.T) {
+	schema := GetConfigSchema()
+	setting, ok := schema.Settings["colorScheme.statusbar.bg"]
+	if !ok || !setting.HotReloadable || len(setting.Constraints) == 0 || setting.Constraints[0].Type != "hexcolor" {
+		t.Fatalf("colorScheme.statusbar.bg = %+v, want a hot-reloadable hex color", setting)
+	}
+	if !strings.Contains(schema.Settings["colorScheme.packages.selectedBg"].Description, "colorScheme.highlight") {
+		t.Errorf("selectedBg description does not name its fallback")
+	}
+
+	data, err := schema.JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Properties map[string]struct {
+			Properties map[string]struct {
+				Properties           map[string]map[string]any `json:"properties"`
+				AdditionalProperties bool                      `json:"additionalProperties"`
+			} `json:"properties"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	packages := doc.Properties["colorScheme"].Properties["packages"]
+	if packages.AdditionalProperties || packages.Properties["selectedBg"]["pattern"] != hexColorPattern {
+		t.Errorf("JSON Schema colorScheme.packages = %+v, want closed hex color properties", packages)
+	}
+	if _, ok := packages.Properties["selectedBg"]["default"]; ok {
+		t.Error("unset panel colors should have no default")
+	}
+}
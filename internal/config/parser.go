@@ -107,9 +107,8 @@ func validateConfigFilePath(filePath string) error {
 	return nil
 }
 
-// parseConfigFile loads and parses a config file, handling syntax errors.
-// See: T049, FR-010
-func parseConfigFile(filePath string) (*Config, error) {
+// readConfigFile validates a config file path and size and returns its content.
+func readConfigFile(filePath string) ([]byte, error) {
 	// Validate file path for security
 	if err := validateConfigFilePath(filePath); err != nil {
 		return nil, err
@@ -126,14 +125,41 @@ func parseConfigFile(filePath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	return data, nil
+}
+
+// parseConfigFile loads and parses a config file, handling syntax errors.
+// See: T049, FR-010
+func parseConfigFile(filePath string) (*Config, error) {
+	var cfg Config
+	if err := decodeConfigFile(filePath, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// decodeConfigFile reads a config file and decodes it into cfg.
+// Only keys present in the file are written, so decoding on top of an existing
+// config acts as an overlay.
+func decodeConfigFile(filePath string, cfg *Config) error {
+	data, err := readConfigFile(filePath)
+	if err != nil {
+		return err
+	}
+	return decodeConfigData(filePath, data, cfg)
+}
+
+// decodeConfigData decodes config content already read from filePath into cfg.
+// filePath only selects the format.
+func decodeConfigData(filePath string, data []byte, cfg *Config) error {
 	// Detect format and parse
 	format := detectFormat(filePath)
 	switch format {
 	case FormatYAML:
-		return parseYAML(data)
+		return decodeYAML(data, cfg)
 	case FormatTOML:
-		return parseTOML(data)
+		return decodeTOML(data, cfg)
 	default:
-		return nil, fmt.Errorf("unsupported config file format (must be .yml, .yaml, or .toml): %s", filePath)
+		return fmt.Errorf("unsupported config file format (must be .yml, .yaml, or .toml): %s", filePath)
 	}
 }
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"reflect"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 )
@@ -10,11 +13,19 @@ import (
 // See: T045, FR-004, FR-010
 func parseTOML(data []byte) (*Config, error) {
 	var cfg Config
+	if err := decodeTOML(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
 
-	// Parse TOML with strict decoding
-	metadata, err := toml.Decode(string(data), &cfg)
-	if err != nil {
-		return nil, fmt.Errorf("TOML parsing error: %w\n\n"+
+// decodeTOML decodes TOML config content into cfg, leaving fields absent from the document untouched.
+func decodeTOML(data []byte, cfg *Config) error {
+	// Parse into a generic document first so duration settings can be normalized
+	// (integers mean seconds) before decoding into the typed struct
+	var doc map[string]any
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		return fmt.Errorf("TOML parsing error: %w\n\n"+
 			"Please check the file for syntax errors:\n"+
 			"  • Ensure proper TOML syntax (key = value)\n"+
 			"  • Check for missing quotes around strings\n"+
@@ -22,12 +33,100 @@ func parseTOML(data []byte) (*Config, error) {
 			"  • Validate TOML syntax at https://www.toml.io/", err)
 	}
 
-	// Check for undecoded keys (unknown configuration keys)
-	if undecoded := metadata.Undecoded(); len(undecoded) > 0 {
-		// Note: We'll log these as warnings, not errors (handled by validator)
-		// Just noting them here for potential future use
-		_ = undecoded
+	if err := normalizeTOMLDurations(doc, reflect.TypeOf(Config{}), ""); err != nil {
+		return fmt.Errorf("invalid duration setting: %w", err)
 	}
 
-	return &cfg, nil
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(doc); err != nil {
+		return fmt.Errorf("TOML parsing error: %w", err)
+	}
+	if _, err := toml.Decode(buf.String(), cfg); err != nil {
+		return fmt.Errorf("TOML parsing error: %w", err)
+	}
+
+	return nil
+}
+
+// parseTOMLWithEncryption parses TOML config and finds encrypted values.
+// TOML has no custom tags, so encrypted values are strings with the "enc:" prefix.
+// Field paths use YAML key names, matching parseYAMLWithEncryption.
+// See: T130, T131
+func parseTOMLWithEncryption(data []byte) (*Config, map[string]*EncryptedValue, error) {
+	var doc map[string]any
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		return nil, nil, fmt.Errorf("TOML parsing error: %w", err)
+	}
+
+	cfg, err := parseTOML(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encryptedFields := make(map[string]*EncryptedValue)
+	scanTOMLForEncryptedValues(doc, reflect.TypeOf(Config{}), "", encryptedFields)
+
+	return cfg, encryptedFields, nil
+}
+
+// scanTOMLForEncryptedValues recursively scans a decoded TOML value for "enc:" strings.
+// t is the config type the value decodes into (nil when unknown), used to translate
+// TOML keys (snake_case) to YAML key paths.
+func scanTOMLForEncryptedValues(value any, t reflect.Type, path string, encrypted map[string]*EncryptedValue) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch v := value.(type) {
+	case string:
+		if text, ok := strings.CutPrefix(v, EncryptedValuePrefix); ok {
+			if ev, ok := encryptedValueFromText(text); ok {
+				encrypted[path] = ev
+			}
+		}
+	case map[string]any:
+		for key, item := range v {
+			name, fieldType := key, reflect.Type(nil)
+			if t != nil && t.Kind() == reflect.Struct {
+				name, fieldType = tomlKeyToYAML(t, key)
+			} else if t != nil && t.Kind() == reflect.Map {
+				fieldType = t.Elem()
+			}
+			scanTOMLForEncryptedValues(item, fieldType, joinKeyPath(path, name), encrypted)
+		}
+	case []map[string]any:
+		for i, item := range v {
+			scanTOMLForEncryptedValues(item, sliceElem(t), fmt.Sprintf("%s[%d]", path, i), encrypted)
+		}
+	case []any:
+		for i, item := range v {
+			scanTOMLForEncryptedValues(item, sliceElem(t), fmt.Sprintf("%s[%d]", path, i), encrypted)
+		}
+	}
+}
+
+// tomlKeyToYAML returns the YAML key name and type of the struct field with the given toml tag.
+// Unknown keys are returned unchanged with a nil type.
+func tomlKeyToYAML(t reflect.Type, key string) (string, reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tomlName, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+		if tomlName != key || !field.IsExported() {
+			continue
+		}
+		yamlName, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if yamlName == "" {
+			yamlName = field.Name
+		}
+		return yamlName, field.Type
+	}
+	return key, nil
+}
+
+// sliceElem returns the element type of a slice type, or nil.
+func sliceElem(t reflect.Type) reflect.Type {
+	if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		return t.Elem()
+	}
+	return nil
 }
@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"reflect"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -14,23 +16,39 @@ import (
 // See: T044, FR-003, FR-010, FR-011
 func parseYAML(data []byte) (*Config, error) {
 	var cfg Config
+	if err := decodeYAML(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
 
+// decodeYAML decodes YAML config content into cfg, leaving fields absent from the document untouched.
+func decodeYAML(data []byte, cfg *Config) error {
 	// Use decoder WITHOUT strict mode - unknown fields should be ignored
 	// Per FR-011: Unknown config keys are ignored with warning
 	// Per FR-013: Unknown keys are non-blocking semantic errors
 	decoder := yaml.NewDecoder(bytes.NewReader(data))
 	decoder.KnownFields(false) // Allow unknown fields
 
-	if err := decoder.Decode(&cfg); err != nil {
+	// Decode to a node first so duration settings can be normalized (integers mean seconds)
+	var root yaml.Node
+	err := decoder.Decode(&root)
+	if err == nil {
+		if durErr := normalizeYAMLDurations(&root, reflect.TypeOf(Config{}), ""); durErr != nil {
+			return fmt.Errorf("invalid duration setting: %w", durErr)
+		}
+		err = root.Decode(cfg)
+	}
+	if err != nil {
 		// Provide helpful error message with line/column info if available
-		return nil, fmt.Errorf("YAML parsing error: %w\n\n"+
+		return fmt.Errorf("YAML parsing error: %w\n\n"+
 			"Please check the file for syntax errors:\n"+
 			"  • Ensure proper indentation (use spaces, not tabs)\n"+
 			"  • Check for missing colons or quotes\n"+
 			"  • Validate YAML syntax at https://www.yamllint.com/", err)
 	}
 
-	return &cfg, nil
+	return nil
 }
 
 // EncryptedString is a custom type that can be unmarshaled from YAML's !encrypted tag.
@@ -64,7 +82,7 @@ func (es *EncryptedString) UnmarshalYAML(node *yaml.Node) error {
 
 // DecryptValue decrypts the encrypted string using the provided encryptor.
 // Returns the decrypted plaintext or an error.
-func (es *EncryptedString) DecryptValue(encryptor Encryptor) (string, error) {
+func (es *EncryptedString) DecryptValue(ctx context.Context, encryptor Encryptor) (string, error) {
 	if !es.IsEncrypted {
 		return es.Value, nil
 	}
@@ -97,7 +115,7 @@ func (es *EncryptedString) DecryptValue(encryptor Encryptor) (string, error) {
 	}
 
 	// Decrypt
-	plaintext, err := encryptor.Decrypt(context.TODO(), encrypted)
+	plaintext, err := encryptor.Decrypt(ctx, encrypted)
 	if err != nil {
 		return "", fmt.Errorf("failed to decrypt value: %w", err)
 	}
@@ -136,30 +154,20 @@ func scanForEncryptedValues(node *yaml.Node, path string, encrypted map[string]*
 		return
 	}
 
-	// Check if this node is an encrypted value
-	if node.Tag == "!encrypted" && node.Kind == yaml.ScalarNode {
-		// Decode the encrypted value
-		combined, err := base64.StdEncoding.DecodeString(node.Value)
-		if err != nil {
-			return
+	// Check if this node is an encrypted value (!encrypted tag or "enc:" prefix)
+	if node.Kind == yaml.ScalarNode {
+		text, isEncrypted := "", false
+		if node.Tag == "!encrypted" {
+			text, isEncrypted = node.Value, true
+		} else if node.Tag == "!!str" && strings.HasPrefix(node.Value, EncryptedValuePrefix) {
+			text, isEncrypted = strings.TrimPrefix(node.Value, EncryptedValuePrefix), true
 		}
-
-		// Extract nonce and ciphertext
-		const nonceSize = 12
-		if len(combined) < nonceSize {
+		if isEncrypted {
+			if value, ok := encryptedValueFromText(text); ok {
+				encrypted[path] = value
+			}
 			return
 		}
-
-		nonce := combined[:nonceSize]
-		ciphertext := combined[nonceSize:]
-
-		encrypted[path] = &EncryptedValue{
-			Ciphertext: ciphertext,
-			Nonce:      nonce,
-			KeyID:      "default",
-			Algorithm:  "AES-256-GCM",
-		}
-		return
 	}
 
 	// Recursively scan child nodes
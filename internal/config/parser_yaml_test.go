@@ -8,6 +8,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -97,13 +98,20 @@ colorScheme:
 			yaml: `
 timeouts:
   networkRequest: 30s
-  dotnetCli: 2m
+  dotnetCLI: 2m
   fileOperation: 10s
 `,
 			wantErr: false,
-			checkFunc: func(_ *Config) error {
-				// Note: YAML parsing of durations requires them to be strings
-				// The actual duration conversion happens in mergeConfigs or elsewhere
+			checkFunc: func(cfg *Config) error {
+				if cfg.Timeouts.NetworkRequest != 30*time.Second {
+					t.Errorf("Expected NetworkRequest=30s, got %v", cfg.Timeouts.NetworkRequest)
+				}
+				if cfg.Timeouts.DotnetCLI != 2*time.Minute {
+					t.Errorf("Expected DotnetCLI=2m, got %v", cfg.Timeouts.DotnetCLI)
+				}
+				if cfg.Timeouts.FileOperation != 10*time.Second {
+					t.Errorf("Expected FileOperation=10s, got %v", cfg.Timeouts.FileOperation)
+				}
 				return nil
 			},
 		},
@@ -509,7 +517,7 @@ func TestDecryptValue(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := tt.encString.DecryptValue(enc)
+			got, err := tt.encString.DecryptValue(context.Background(), enc)
 
 			if tt.wantErr {
 				if err == nil {
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ProfileEnvVar is the environment variable suffix selecting a profile (LAZYNUGET_PROFILE).
+const ProfileEnvVar = "PROFILE"
+
+// profilesKey is the config key holding named profile blocks.
+const profilesKey = "profiles"
+
+// selectedProfile returns the profile to apply: LoadOptions.Profile (--profile) wins over
+// the <prefix>PROFILE environment variable. Empty means no profile.
+func selectedProfile(opts LoadOptions) string {
+	if opts.Profile != "" {
+		return opts.Profile
+	}
+	if opts.EnvVarPrefix != "" {
+		return strings.TrimSpace(os.Getenv(opts.EnvVarPrefix + ProfileEnvVar))
+	}
+	return ""
+}
+
+// applyProfile decodes the named profile block of a config file over cfg.
+// Like a project overlay, only keys present in the profile change; lists replace.
+// Returns whether the file defines the profile and the names of all profiles it defines.
+func applyProfile(cfg *Config, filePath, name string) (found bool, available []string, err error) {
+	data, err := readConfigFile(filePath)
+	if err != nil {
+		return false, nil, err
+	}
+	return applyProfileData(cfg, filePath, data, name)
+}
+
+// applyProfileData applies profiles.<name> from config content already read from filePath.
+func applyProfileData(cfg *Config, filePath string, data []byte, name string) (bool, []string, error) {
+	switch detectFormat(filePath) {
+	case FormatYAML:
+		return applyYAMLProfile(cfg, data, name)
+	case FormatTOML:
+		return applyTOMLProfile(cfg, data, name)
+	default:
+		return false, nil, fmt.Errorf("unsupported config file format (must be .yml, .yaml, or .toml): %s", filePath)
+	}
+}
+
+// applyYAMLProfile applies profiles.<name> from YAML content.
+func applyYAMLProfile(cfg *Config, data []byte, name string) (bool, []string, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return false, nil, fmt.Errorf("YAML parsing error: %w", err)
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return false, nil, nil
+	}
+
+	var profiles *yaml.Node
+	doc := root.Content[0]
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value == profilesKey {
+			profiles = doc.Content[i+1]
+		}
+	}
+	if profiles == nil || profiles.Kind != yaml.MappingNode {
+		return false, nil, nil
+	}
+
+	var block *yaml.Node
+	available := make([]string, 0, len(profiles.Content)/2)
+	for i := 0; i+1 < len(profiles.Content); i += 2 {
+		available = append(available, profiles.Content[i].Value)
+		if profiles.Content[i].Value == name {
+			block = profiles.Content[i+1]
+		}
+	}
+	sort.Strings(available)
+
+	if block == nil {
+		return false, available, nil
+	}
+	if block.Kind == yaml.ScalarNode && block.Tag == "!!null" {
+		return true, available, nil // Empty profile
+	}
+	if block.Kind != yaml.MappingNode {
+		return true, available, fmt.Errorf("profile %q must be a mapping of settings (line %d)", name, block.Line)
+	}
+
+	out, err := yaml.Marshal(block)
+	if err != nil {
+		return true, available, fmt.Errorf("failed to read profile %q: %w", name, err)
+	}
+	if err := decodeYAML(out, cfg); err != nil {
+		return true, available, fmt.Errorf("profile %q: %w", name, err)
+	}
+	return true, available, nil
+}
+
+// applyTOMLProfile applies [profiles.<name>] from TOML content.
+func applyTOMLProfile(cfg *Config, data []byte, name string) (bool, []string, error) {
+	var doc map[string]any
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		return false, nil, fmt.Errorf("TOML parsing error: %w", err)
+	}
+
+	profiles, ok := doc[profilesKey].(map[string]any)
+	if !ok {
+		return false, nil, nil
+	}
+
+	available := make([]string, 0, len(profiles))
+	for profile := range profiles {
+		available = append(available, profile)
+	}
+	sort.Strings(available)
+
+	raw, ok := profiles[name]
+	if !ok {
+		return false, available, nil
+	}
+	block, ok := raw.(map[string]any)
+	if !ok {
+		return true, available, fmt.Errorf("profile %q must be a table of settings", name)
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(block); err != nil {
+		return true, available, fmt.Errorf("failed to read profile %q: %w", name, err)
+	}
+	if err := decodeTOML(buf.Bytes(), cfg); err != nil {
+		return true, available, fmt.Errorf("profile %q: %w", name, err)
+	}
+	return true, available, nil
+}
+
+// profileNotFoundError reports a selected profile that no config file defines.
+func profileNotFoundError(name string, available []string) error {
+	if len(available) == 0 {
+		return fmt.Errorf("profile %q not found: no profiles are defined in the config file", name)
+	}
+	return fmt.Errorf("profile %q not found (available: %s)", name, strings.Join(available, ", "))
+}
+
+// splitProfileFields separates encrypted fields of the base config from those of the
+// named profile (paths "profiles.<name>.*", returned relative to the profile).
+// Fields of other profiles are dropped.
+func splitProfileFields(fields map[string]*EncryptedValue, name string) (base, profile map[string]*EncryptedValue) {
+	base = make(map[string]*EncryptedValue)
+	profile = make(map[string]*EncryptedValue)
+	prefix := profilesKey + "." + name + "."
+	for path, value := range fields {
+		switch {
+		case name != "" && strings.HasPrefix(path, prefix):
+			profile[strings.TrimPrefix(path, prefix)] = value
+		case strings.HasPrefix(path, profilesKey+"."):
+			// Another profile's value
+		default:
+			base[path] = value
+		}
+	}
+	return base, profile
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const profilesYAML = `
+theme: dark
+logLevel: info
+feeds:
+  - name: nuget.org
+    url: https://api.nuget.org/v3/index.json
+profiles:
+  work:
+    logLevel: debug
+    timeouts:
+      networkRequest: 90
+    feeds:
+      - name: corp
+        url: https://nuget.corp.example.com/v3/index.json
+  oss:
+    compactMode: true
+`
+
+const profilesTOML = `
+theme = "dark"
+log_level = "info"
+
+[[feeds]]
+name = "nuget.org"
+url = "https://api.nuget.org/v3/index.json"
+
+[profiles.work]
+log_level = "debug"
+
+[profiles.work.timeouts]
+network_request = 90
+
+[[profiles.work.feeds]]
+name = "corp"
+url = "https://nuget.corp.example.com/v3/index.json"
+
+[profiles.oss]
+compact_mode = true
+`
+
+// TestLoadProfile tests applying a named profile over the base config in both formats
+func TestLoadProfile(t *testing.T) {
+	for file, content := range map[string]string{"config.yml": profilesYAML, "config.toml": profilesTOML} {
+		t.Run(file, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), file)
+			if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+			opts := LoadOptions{ConfigFilePath: configPath, NoProjectConfig: true, Profile: "work"}
+
+			cfg, err := NewLoader().Load(context.Background(), opts)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if cfg.Profile != "work" {
+				t.Errorf("Profile = %q, want work", cfg.Profile)
+			}
+			if cfg.LogLevel != "debug" {
+				t.Errorf("LogLevel = %q, want profile value debug", cfg.LogLevel)
+			}
+			if cfg.Theme != "dark" {
+				t.Errorf("Theme = %q, want base value dark", cfg.Theme)
+			}
+			if cfg.Timeouts.NetworkRequest != 90*time.Second {
+				t.Errorf("NetworkRequest = %v, want 90s", cfg.Timeouts.NetworkRequest)
+			}
+			if len(cfg.Feeds) != 1 || cfg.Feeds[0].Name != "corp" {
+				t.Errorf("Feeds = %+v, want only the corp feed", cfg.Feeds)
+			}
+			if cfg.CompactMode {
+				t.Error("CompactMode = true, settings from other profiles must not apply")
+			}
+
+			// Without a profile the base config is used unchanged
+			opts.Profile = ""
+			cfg, err = NewLoader().Load(context.Background(), opts)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.LogLevel != "info" || len(cfg.Feeds) != 1 || cfg.Feeds[0].Name != "nuget.org" || cfg.Profile != "" {
+				t.Errorf("base config changed without a profile: logLevel=%s feeds=%+v profile=%q", cfg.LogLevel, cfg.Feeds, cfg.Profile)
+			}
+		})
+	}
+}
+
+// TestLoadProfileSelection tests --profile taking precedence over LAZYNUGET_PROFILE
+func TestLoadProfileSelection(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(configPath, []byte(profilesYAML), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	t.Setenv("LAZYNUGET_PROFILE", "oss")
+	opts := LoadOptions{ConfigFilePath: configPath, EnvVarPrefix: "LAZYNUGET_", NoProjectConfig: true}
+
+	cfg, err := NewLoader().Load(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Profile != "oss" || !cfg.CompactMode {
+		t.Errorf("Profile = %q compactMode = %v, want oss profile from env var", cfg.Profile, cfg.CompactMode)
+	}
+
+	opts.Profile = "work"
+	cfg, err = NewLoader().Load(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Profile != "work" || cfg.CompactMode {
+		t.Errorf("Profile = %q compactMode = %v, want work profile from flag", cfg.Profile, cfg.CompactMode)
+	}
+}
+
+// TestLoadProfileNotFound tests that an undefined profile is a blocking error
+func TestLoadProfileNotFound(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(configPath, []byte(profilesYAML), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := NewLoader().Load(context.Background(), LoadOptions{ConfigFilePath: configPath, NoProjectConfig: true, Profile: "home"})
+	if err == nil {
+		t.Fatal("Load() expected error for undefined profile")
+	}
+	if !strings.Contains(err.Error(), `"home"`) || !strings.Contains(err.Error(), "oss, work") {
+		t.Errorf("error = %q, want profile name and available profiles", err)
+	}
+
+	// No config file at all
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	_, err = NewLoader().Load(context.Background(), LoadOptions{NoProjectConfig: true, Profile: "home"})
+	if err == nil || !strings.Contains(err.Error(), "no profiles are defined") {
+		t.Errorf("error = %v, want no profiles defined", err)
+	}
+}
+
+// TestLoadProfileFromProjectOverlay tests a profile defined only by the repository overlay
+func TestLoadProfileFromProjectOverlay(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o700); err != nil {
+		t.Fatalf("Failed to create .git: %v", err)
+	}
+	overlay := "profiles:\n  ci:\n    logLevel: warn\n    showHints: false\n"
+	if err := os.WriteFile(filepath.Join(repo, ".lazynuget.yml"), []byte(overlay), 0o600); err != nil {
+		t.Fatalf("Failed to write overlay: %v", err)
+	}
+
+	cfg, err := NewLoader().Load(context.Background(), LoadOptions{WorkingDir: repo, Profile: "ci"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.LogLevel != "warn" || cfg.ShowHints {
+		t.Errorf("logLevel=%s showHints=%v, want ci profile from overlay", cfg.LogLevel, cfg.ShowHints)
+	}
+}
+
+// TestProfileUnknownKeys tests that typos inside profiles are reported with their full path
+func TestProfileUnknownKeys(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	content := "profiles:\n  work:\n    logLevle: debug\n"
+	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	keys, err := findUnknownKeys(configPath)
+	if err != nil {
+		t.Fatalf("findUnknownKeys() error = %v", err)
+	}
+	if len(keys) != 1 || keys[0] != "profiles.work.logLevle" {
+		t.Errorf("unknown keys = %v, want [profiles.work.logLevle]", keys)
+	}
+}
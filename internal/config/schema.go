@@ -2,6 +2,7 @@ package config
 
 import (
 	"reflect"
+	"slices"
 	"time"
 )
 
@@ -9,7 +10,7 @@ import (
 // This is the single source of truth for validation, defaults, and hot-reload support.
 // See: specs/002-config-management/data-model.md entity #10
 func GetConfigSchema() *ConfigSchema {
-	return &ConfigSchema{
+	schema := &ConfigSchema{
 		Settings: map[string]SettingSchema{
 			// Meta fields (not user-configurable, but included for completeness)
 			"version": {
@@ -28,13 +29,13 @@ func GetConfigSchema() *ConfigSchema {
 				Constraints: []Constraint{
 					{
 						Type:    "enum",
-						Params:  []string{"default", "dark", "light", "solarized"},
-						Message: "must be one of: default, dark, light, solarized",
+						Params:  BuiltinThemes,
+						Message: "must be one of: default, auto, dark, light, solarized, or a theme file name",
 					},
 				},
 				Default:       "default",
 				HotReloadable: true,
-				Description:   "UI theme (default, dark, light, solarized)",
+				Description:   "UI theme (default, dark, light, solarized, or auto for light or dark following the terminal background), or the name of a <name>.yml file in the themes directory next to the config file",
 			},
 
 			// ColorScheme nested fields
@@ -173,6 +174,44 @@ func GetConfigSchema() *ConfigSchema {
 				HotReloadable: true,
 				Description:   "Date format string (Go time layout)",
 			},
+			"staleAfterYears": {
+				Path: "staleAfterYears",
+				Type: reflect.TypeOf(0),
+				Constraints: []Constraint{
+					{
+						Type:    "min",
+						Params:  1,
+						Message: "must be at least 1",
+					},
+				},
+				Default:       2,
+				HotReloadable: true,
+				Description:   "Years since a package's last release after which it is flagged as stale",
+			},
+
+			"packageIcons": {
+				Path: "packageIcons",
+				Type: reflect.TypeOf(""),
+				Constraints: []Constraint{
+					{
+						Type:    "enum",
+						Params:  []string{"auto", "kitty", "iterm2", "sixel", "off"},
+						Message: "must be one of: auto, kitty, iterm2, sixel, off",
+					},
+				},
+				Default:       "auto",
+				HotReloadable: true,
+				Description:   "Inline image protocol for package icons (auto detects the terminal; off shows a colored initial)",
+			},
+
+			"editor": {
+				Path:          "editor",
+				Type:          reflect.TypeOf(""),
+				Constraints:   []Constraint{},
+				Default:       "",
+				HotReloadable: true,
+				Description:   "Command that edits files, such as \"code --wait\" (empty = $VISUAL, then $EDITOR)",
+			},
 
 			// Keybindings (FR-026 through FR-030)
 			"keybindingProfile": {
@@ -190,6 +229,35 @@ func GetConfigSchema() *ConfigSchema {
 				Description:   "Keybinding profile (default, vim, emacs) - requires restart",
 			},
 
+			"keybindings": {
+				Path:          "keybindings",
+				Type:          reflect.TypeOf(map[string]KeyBinding{}),
+				Constraints:   []Constraint{},
+				Default:       map[string]KeyBinding(nil),
+				HotReloadable: true,
+				Description:   "Custom keybindings keyed by action name, applied on top of the profile",
+			},
+
+			// Named profiles
+			"profiles": {
+				Path:          "profiles",
+				Type:          reflect.TypeOf(map[string]Config{}),
+				Constraints:   []Constraint{},
+				Default:       map[string]Config(nil),
+				HotReloadable: false,
+				Description:   "Named setting overrides applied over the base config with --profile or LAZYNUGET_PROFILE",
+			},
+
+			// Included config fragments
+			"include": {
+				Path:          "include",
+				Type:          reflect.TypeOf([]string{}),
+				Constraints:   []Constraint{},
+				Default:       []string(nil),
+				HotReloadable: true,
+				Description:   "Files applied before this one (e.g., keybindings.yml, feeds.toml), relative to it; this file's own settings win",
+			},
+
 			// Performance (FR-031 through FR-034)
 			"maxConcurrentOps": {
 				Path: "maxConcurrentOps",
@@ -203,7 +271,21 @@ func GetConfigSchema() *ConfigSchema {
 				},
 				Default:       4,
 				HotReloadable: true,
-				Description:   "Maximum number of concurrent operations (1-16)",
+				Description:   "Maximum number of concurrent operations, such as project files parsed at once (1-16)",
+			},
+			"renderFps": {
+				Path: "renderFps",
+				Type: reflect.TypeOf(0),
+				Constraints: []Constraint{
+					{
+						Type:    "range",
+						Params:  map[string]int{"min": 1, "max": 120},
+						Message: "must be between 1 and 120",
+					},
+				},
+				Default:       30,
+				HotReloadable: false,
+				Description:   "Most frames drawn per second; updates that come faster are drawn together (1-120, lower over slow SSH links)",
 			},
 			"cacheSize": {
 				Path: "cacheSize",
@@ -233,6 +315,34 @@ func GetConfigSchema() *ConfigSchema {
 				HotReloadable: true,
 				Description:   "Auto-refresh interval (0 = disabled)",
 			},
+			"advisoryCacheTTL": {
+				Path: "advisoryCacheTTL",
+				Type: reflect.TypeOf(time.Duration(0)),
+				Constraints: []Constraint{
+					{
+						Type:    "min",
+						Params:  time.Minute,
+						Message: "must be at least 1m",
+					},
+				},
+				Default:       24 * time.Hour,
+				HotReloadable: true,
+				Description:   "How long vulnerability data from OSV.dev is cached",
+			},
+			"prefetchDelay": {
+				Path: "prefetchDelay",
+				Type: reflect.TypeOf(time.Duration(0)),
+				Constraints: []Constraint{
+					{
+						Type:    "min",
+						Params:  50 * time.Millisecond,
+						Message: "must be at least 50ms",
+					},
+				},
+				Default:       300 * time.Millisecond,
+				HotReloadable: false,
+				Description:   "How long a package stays selected before its README, versions, and vulnerabilities are prefetched",
+			},
 
 			// Timeouts nested fields
 			"timeouts.networkRequest": {
@@ -302,6 +412,36 @@ func GetConfigSchema() *ConfigSchema {
 				Description:   "Dotnet CLI verbosity level",
 			},
 
+			"operationBackend": {
+				Path: "operationBackend",
+				Type: reflect.TypeOf(""),
+				Constraints: []Constraint{
+					{
+						Type:    "enum",
+						Params:  []string{"cli", "direct"},
+						Message: "must be one of: cli, direct",
+					},
+				},
+				Default:       "cli",
+				HotReloadable: false,
+				Description:   "How package references are added, updated, and removed: cli runs dotnet add/remove package; direct edits the project files, needing no dotnet until a restore",
+			},
+
+			"restoreMode": {
+				Path: "restoreMode",
+				Type: reflect.TypeOf(""),
+				Constraints: []Constraint{
+					{
+						Type:    "enum",
+						Params:  []string{"always", "batch", "manual"},
+						Message: "must be one of: always, batch, manual",
+					},
+				},
+				Default:       "always",
+				HotReloadable: false,
+				Description:   "When projects are restored after their package references change: always (after every change), batch (once, when a session ends), or manual (only when asked)",
+			},
+
 			// Logging (FR-039 through FR-042)
 			"logLevel": {
 				Path: "logLevel",
@@ -317,6 +457,20 @@ func GetConfigSchema() *ConfigSchema {
 				HotReloadable: true,
 				Description:   "Logging level",
 			},
+			"logLevels": {
+				Path: "logLevels",
+				Type: reflect.TypeOf(map[string]string{}),
+				Constraints: []Constraint{
+					{
+						Type:    "enum",
+						Params:  []string{"debug", "info", "warn", "error"},
+						Message: "must be one of: debug, info, warn, error",
+					},
+				},
+				Default:       map[string]string(nil),
+				HotReloadable: false,
+				Description:   "Per-module log levels overriding logLevel (e.g., nuget: debug, gui: warn)",
+			},
 			"logDir": {
 				Path:          "logDir",
 				Type:          reflect.TypeOf(""),
@@ -401,8 +555,249 @@ func GetConfigSchema() *ConfigSchema {
 				HotReloadable: false,
 				Description:   "Enable hot-reload of configuration file changes - requires restart to enable",
 			},
+
+			// Release notifications (never installs; see `lazynuget update-self`)
+			"updateCheck": {
+				Path:          "updateCheck",
+				Type:          reflect.TypeOf(false),
+				Constraints:   []Constraint{},
+				Default:       false,
+				HotReloadable: false,
+				Description:   "Check GitHub for a newer release at startup and notify without installing - requires restart",
+			},
+
+			// Hooks
+			"hooks.preInstall": {
+				Path:          "hooks.preInstall",
+				Type:          reflect.TypeOf([]Hook{}),
+				Constraints:   []Constraint{},
+				Default:       []Hook(nil),
+				HotReloadable: true,
+				Description:   "Shell commands run before a package is added; a failing command cancels the install",
+			},
+			"hooks.postUpdate": {
+				Path:          "hooks.postUpdate",
+				Type:          reflect.TypeOf([]Hook{}),
+				Constraints:   []Constraint{},
+				Default:       []Hook(nil),
+				HotReloadable: true,
+				Description:   "Shell commands run after a package version changes",
+			},
+			"hooks.postRestore": {
+				Path:          "hooks.postRestore",
+				Type:          reflect.TypeOf([]Hook{}),
+				Constraints:   []Constraint{},
+				Default:       []Hook(nil),
+				HotReloadable: true,
+				Description:   "Shell commands run after dotnet restore succeeds",
+			},
+
+			// Custom commands
+			"customCommands": {
+				Path:          "customCommands",
+				Type:          reflect.TypeOf([]CustomCommand{}),
+				Constraints:   []Constraint{},
+				Default:       []CustomCommand(nil),
+				HotReloadable: true,
+				Description:   "Shell commands added to the command palette and bound to keys, with {{package}}, {{project}}, {{version}}, and prompt placeholders",
+			},
+
+			// Saved filters
+			"savedFilters": {
+				Path:          "savedFilters",
+				Type:          reflect.TypeOf([]SavedFilter{}),
+				Constraints:   []Constraint{},
+				Default:       []SavedFilter(nil),
+				HotReloadable: true,
+				Description:   "Named package list filters (e.g., is:vulnerable update:major) for the command palette and --filter",
+			},
+
+			// Plugins
+			"plugins": {
+				Path:          "plugins",
+				Type:          reflect.TypeOf([]Plugin{}),
+				Constraints:   []Constraint{},
+				Default:       []Plugin(nil),
+				HotReloadable: false,
+				Description:   "External programs that add commands, panels, and package annotations - requires restart",
+			},
+
+			// Sandbox for hooks and custom commands
+			"sandbox.enabled": {
+				Path:          "sandbox.enabled",
+				Type:          reflect.TypeOf(false),
+				Constraints:   []Constraint{},
+				Default:       false,
+				HotReloadable: true,
+				Description:   "Run hooks and custom commands in a sandbox by default (Linux: bwrap, macOS: sandbox-exec)",
+			},
+			"sandbox.allowNetwork": {
+				Path:          "sandbox.allowNetwork",
+				Type:          reflect.TypeOf(false),
+				Constraints:   []Constraint{},
+				Default:       false,
+				HotReloadable: true,
+				Description:   "Allow network access inside the sandbox",
+			},
+			"sandbox.writablePaths": {
+				Path:          "sandbox.writablePaths",
+				Type:          reflect.TypeOf([]string{}),
+				Constraints:   []Constraint{},
+				Default:       []string(nil),
+				HotReloadable: true,
+				Description:   "Directories writable inside the sandbox in addition to the working directory",
+			},
+
+			// Telemetry (opt-in; consent is stored outside the config)
+			"statusBar.format": {
+				Path:          "statusBar.format",
+				Type:          reflect.TypeOf(""),
+				Constraints:   []Constraint{},
+				Default:       "{mode} {keys} {repo} {feeds} {pending} {vulns} {clock}",
+				HotReloadable: true,
+				Description:   "Status bar segments in order as {name} ({mode}, {keys}, {repo}, {feeds}, {pending}, {vulns}, {clock}), with the text between them",
+			},
+			"statusBar.clockFormat": {
+				Path:          "statusBar.clockFormat",
+				Type:          reflect.TypeOf(""),
+				Constraints:   []Constraint{},
+				Default:       "15:04",
+				HotReloadable: true,
+				Description:   "Go time layout of the {clock} segment (e.g., 15:04, 3:04PM)",
+			},
+			"confirm.add": {
+				Path: "confirm.add",
+				Type: reflect.TypeOf(""),
+				Constraints: []Constraint{
+					{
+						Type:    "enum",
+						Params:  ConfirmPolicies,
+						Message: "must be one of: always, never",
+					},
+				},
+				Default:       "never",
+				HotReloadable: true,
+				Description:   "Whether adding a package asks first (always, never)",
+			},
+			"confirm.remove": {
+				Path: "confirm.remove",
+				Type: reflect.TypeOf(""),
+				Constraints: []Constraint{
+					{
+						Type:    "enum",
+						Params:  ConfirmPolicies,
+						Message: "must be one of: always, never",
+					},
+				},
+				Default:       "always",
+				HotReloadable: true,
+				Description:   "Whether removing a package asks first (always, never)",
+			},
+			"confirm.update": {
+				Path: "confirm.update",
+				Type: reflect.TypeOf(""),
+				Constraints: []Constraint{
+					{
+						Type:    "enum",
+						Params:  ConfirmPolicies,
+						Message: "must be one of: always, never",
+					},
+				},
+				Default:       "never",
+				HotReloadable: true,
+				Description:   "Whether updating a package asks first (always, never)",
+			},
+			"confirm.bulk": {
+				Path: "confirm.bulk",
+				Type: reflect.TypeOf(""),
+				Constraints: []Constraint{
+					{
+						Type:    "enum",
+						Params:  ConfirmPolicies,
+						Message: "must be one of: always, never",
+					},
+				},
+				Default:       "always",
+				HotReloadable: true,
+				Description:   "Whether operations on more than one package ask first (always, never), whatever the operation's own setting",
+			},
+			"telemetry.endpoint": {
+				Path:          "telemetry.endpoint",
+				Type:          reflect.TypeOf(""),
+				Constraints:   []Constraint{},
+				Default:       "",
+				HotReloadable: true,
+				Description:   "HTTPS URL that receives anonymous usage reports once you opt in (empty = never sent)",
+			},
+
+			// Team policy (usually shared via a repository .lazynuget.yml)
+			"pinnedPackages": {
+				Path:          "pinnedPackages",
+				Type:          reflect.TypeOf([]PinRule{}),
+				Constraints:   []Constraint{},
+				Default:       []PinRule(nil),
+				HotReloadable: true,
+				Description:   "Packages held at a version so updates skip them",
+			},
+			"licensePolicy.allowed": {
+				Path:          "licensePolicy.allowed",
+				Type:          reflect.TypeOf([]string{}),
+				Constraints:   []Constraint{},
+				Default:       []string(nil),
+				HotReloadable: true,
+				Description:   "SPDX license identifiers allowed for installed packages (empty = any not denied)",
+			},
+			"licensePolicy.denied": {
+				Path:          "licensePolicy.denied",
+				Type:          reflect.TypeOf([]string{}),
+				Constraints:   []Constraint{},
+				Default:       []string(nil),
+				HotReloadable: true,
+				Description:   "SPDX license identifiers that may not be installed",
+			},
+			"feeds": {
+				Path: "feeds",
+				Type: reflect.TypeOf([]Feed{}),
+				Constraints: []Constraint{
+					{Type: "feedurl", Params: nil, Message: "must be an http(s) URL or local folder path"},
+				},
+				Default:       []Feed(nil),
+				HotReloadable: true,
+				Description:   "Additional NuGet package sources, each with an optional request timeout overriding timeouts.networkRequest",
+			},
+			"feedFallbacks": {
+				Path:          "feedFallbacks",
+				Type:          reflect.TypeOf([]FeedFallback{}),
+				Constraints:   []Constraint{},
+				Default:       []FeedFallback(nil),
+				HotReloadable: true,
+				Description:   "Mirrors that serve package reads while a feed is down",
+			},
 		},
 	}
+	addPanelColorSettings(schema.Settings)
+	return schema
+}
+
+// AddEnumValues appends values to the enum constraint of a setting, such as the theme
+// files found next to the config file.
+func (cs *ConfigSchema) AddEnumValues(path string, values ...string) {
+	setting, ok := cs.Settings[path]
+	if !ok {
+		return
+	}
+	for i, c := range setting.Constraints {
+		if existing, isEnum := c.Params.([]string); c.Type == "enum" && isEnum {
+			merged := slices.Clone(existing)
+			for _, value := range values {
+				if !slices.Contains(merged, value) {
+					merged = append(merged, value)
+				}
+			}
+			setting.Constraints[i].Params = merged
+		}
+	}
+	cs.Settings[path] = setting
 }
 
 // IsHotReloadable returns whether a setting can be reloaded without restart.
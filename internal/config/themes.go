@@ -0,0 +1,286 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// ThemesKind is the JSON document kind of `lazynuget theme list --json`.
+const ThemesKind = "themes"
+
+// ThemesDirName is the directory next to the config file that holds theme files.
+const ThemesDirName = "themes"
+
+// AutoTheme is the theme that follows the terminal background: the light theme on a light
+// background, otherwise the dark theme (either may be a theme file).
+const AutoTheme = "auto"
+
+// BuiltinThemes lists the themes that need no file, in display order.
+var BuiltinThemes = []string{"default", AutoTheme, "dark", "light", "solarized"}
+
+// builtinPalettes holds the colors of the built-in themes other than default, which is
+// the colorScheme defaults.
+var builtinPalettes = map[string]ColorScheme{
+	"dark": {
+		Border: "#444444", BorderFocus: "#5FAFFF", Text: "#D0D0D0", TextDim: "#767676", Background: "#1C1C1C",
+		Highlight: "#5FAFFF", Error: "#FF5F5F", Warning: "#FFAF5F", Success: "#87D787", Info: "#5FD7FF",
+	},
+	"light": {
+		Border: "#BCBCBC", BorderFocus: "#005FAF", Text: "#262626", TextDim: "#6C6C6C", Background: "#FFFFFF",
+		Highlight: "#005FAF", Error: "#D70000", Warning: "#AF5F00", Success: "#008700", Info: "#0087AF",
+	},
+	"solarized": {
+		Border: "#586E75", BorderFocus: "#268BD2", Text: "#839496", TextDim: "#586E75", Background: "#002B36",
+		Highlight: "#268BD2", Error: "#DC322F", Warning: "#B58900", Success: "#859900", Info: "#2AA198",
+	},
+}
+
+// builtinDescriptions describe the built-in themes in `lazynuget theme list`.
+var builtinDescriptions = map[string]string{
+	"default":   "The colorScheme setting as configured",
+	AutoTheme:   "Light or dark, following the terminal background",
+	"dark":      "Muted colors for dark terminals",
+	"light":     "Dark text for light terminals",
+	"solarized": "Solarized dark",
+}
+
+// ThemeInfo describes an available theme.
+type ThemeInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Path        string `json:"path,omitempty"`  // The theme file; empty for built-in themes
+	Error       string `json:"error,omitempty"` // Why the theme file cannot be used
+}
+
+// themeFile is the content of a theme file: colorScheme keys under colors. Colors left
+// out are the colorScheme defaults.
+type themeFile struct {
+	Description string      `yaml:"description"`
+	Colors      ColorScheme `yaml:"colors"`
+}
+
+// hexColorRegex matches the colors a theme file may use, as validateAndFixHexColor does.
+var hexColorRegex = regexp.MustCompile(hexColorPattern)
+
+// ThemesDir returns the themes directory next to a config file, or in the platform config
+// directory when no config file was loaded. Empty when neither is known.
+func ThemesDir(configFile string) string {
+	if configFile != "" && configFile != GetDefaultConfig().LoadedFrom {
+		return filepath.Join(filepath.Dir(configFile), ThemesDirName)
+	}
+	if dir := getPlatformConfigPath(); dir != "" {
+		return filepath.Join(dir, ThemesDirName)
+	}
+	return ""
+}
+
+// ListThemes returns the built-in themes, then those in dir (<name>.yml or <name>.yaml)
+// sorted by name. A file named after a built-in theme replaces it. A missing dir has no
+// themes; a theme file that cannot be used is listed with its error.
+func ListThemes(dir string) ([]ThemeInfo, error) {
+	themes := make([]ThemeInfo, 0, len(BuiltinThemes))
+	for _, name := range BuiltinThemes {
+		themes = append(themes, ThemeInfo{Name: name, Description: builtinDescriptions[name]})
+	}
+	files, err := themeFiles(dir)
+	if err != nil {
+		return themes, err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		info := ThemeInfo{Name: name, Path: files[name]}
+		if theme, err := readThemeFile(files[name]); err != nil {
+			info.Error = err.Error()
+		} else {
+			info.Description = theme.Description
+		}
+		if i := slices.IndexFunc(themes, func(t ThemeInfo) bool { return t.Name == name }); i >= 0 {
+			themes[i] = info
+		} else {
+			themes = append(themes, info)
+		}
+	}
+	return themes, nil
+}
+
+// AutoThemeVariant returns the theme auto stands for on a terminal background.
+func AutoThemeVariant(background platform.Background) string {
+	if background == platform.BackgroundLight {
+		return "light"
+	}
+	return "dark"
+}
+
+// LoadTheme returns the colors of a theme: a file in dir, or a built-in theme. The
+// default theme's colors are the colorScheme defaults. Callers that know the terminal
+// background resolve auto with AutoThemeVariant first; otherwise it is the dark theme.
+func LoadTheme(dir, name string) (ColorScheme, ThemeInfo, error) {
+	if name == AutoTheme {
+		name = AutoThemeVariant(platform.BackgroundUnknown)
+	}
+	files, err := themeFiles(dir)
+	if err != nil {
+		return ColorScheme{}, ThemeInfo{}, err
+	}
+	if path, ok := files[name]; ok {
+		theme, err := readThemeFile(path)
+		if err != nil {
+			return ColorScheme{}, ThemeInfo{}, fmt.Errorf("theme %s: %w", name, err)
+		}
+		return theme.Colors, ThemeInfo{Name: name, Description: theme.Description, Path: path}, nil
+	}
+	if !slices.Contains(BuiltinThemes, name) {
+		return ColorScheme{}, ThemeInfo{}, fmt.Errorf("unknown theme %q", name)
+	}
+	colors, ok := builtinPalettes[name]
+	if !ok {
+		colors = GetDefaultConfig().ColorScheme
+	}
+	return colors, ThemeInfo{Name: name, Description: builtinDescriptions[name]}, nil
+}
+
+// themeFiles maps the theme names in dir to their files.
+func themeFiles(dir string) (map[string]string, error) {
+	files := make(map[string]string)
+	if dir == "" {
+		return files, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return files, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read themes directory: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := filepath.Ext(name)
+		if entry.IsDir() || strings.HasPrefix(name, ".") || detectFormat(name) != FormatYAML {
+			continue
+		}
+		if theme := strings.TrimSuffix(name, ext); theme != AutoTheme { // auto picks a theme; it has none of its own
+			files[theme] = filepath.Join(dir, name)
+		}
+	}
+	return files, nil
+}
+
+// readThemeFile parses a theme file. Unknown keys and invalid colors are errors, so a
+// typo is not silently drawn in the default color.
+func readThemeFile(path string) (*themeFile, error) {
+	data, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	theme := themeFile{Colors: GetDefaultConfig().ColorScheme}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&theme); err != nil && !errors.Is(err, io.EOF) {
+		// One line per problem would break up `theme list`
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			return nil, errors.New(strings.Join(typeErr.Errors, "; "))
+		}
+		return nil, fmt.Errorf("YAML parsing error: %w", err)
+	}
+
+	var invalid error
+	colorFields(&theme.Colors, func(key string, color *string) {
+		// Panel colors are optional
+		if invalid == nil && !hexColorRegex.MatchString(*color) && (*color != "" || !strings.Contains(key, ".")) {
+			invalid = fmt.Errorf("colors.%s: %q is not a hex color (#RRGGBB)", key, *color)
+		}
+	})
+	if invalid != nil {
+		return nil, invalid
+	}
+	return &theme, nil
+}
+
+// validateTheme checks that the theme is built in or has a usable file in the themes
+// directory, falling back to defaultValue when it does not.
+func validateTheme(cfg *Config, defaultValue string) *ValidationError {
+	if _, _, err := LoadTheme(ThemesDir(cfg.LoadedFrom), cfg.Theme); err == nil {
+		return nil
+	}
+
+	originalValue := cfg.Theme
+	cfg.Theme = defaultValue
+	themes, _ := ListThemes(ThemesDir(cfg.LoadedFrom))
+	names := make([]string, 0, len(themes))
+	for _, theme := range themes {
+		if theme.Name == originalValue && theme.Error != "" {
+			return &ValidationError{
+				Key:          "theme",
+				Value:        originalValue,
+				Constraint:   "theme file " + theme.Path + " is invalid: " + theme.Error,
+				SuggestedFix: "Fix the theme file (see `lazynuget theme preview " + originalValue + "`)",
+				Severity:     "warning",
+				DefaultUsed:  defaultValue,
+			}
+		}
+		if theme.Error == "" {
+			names = append(names, theme.Name)
+		}
+	}
+	return &ValidationError{
+		Key:          "theme",
+		Value:        originalValue,
+		Constraint:   fmt.Sprintf("must be one of: %s", strings.Join(names, ", ")),
+		SuggestedFix: "Set theme to a built-in theme or add " + originalValue + ".yml to the themes directory",
+		Severity:     "warning",
+		DefaultUsed:  defaultValue,
+	}
+}
+
+// applyTheme sets the color scheme to the theme's colors. Colors set in colorScheme (those
+// that differ from the defaults) are kept, so a theme can be tweaked without copying it.
+// The theme file, if any, is recorded for hot-reload. For theme auto, detect reports the
+// terminal background (nil when it cannot be asked), recorded in cfg.Background.
+func applyTheme(cfg *Config, detect func() platform.Background) {
+	cfg.ThemeFile = ""
+	cfg.Background = platform.BackgroundUnknown
+	name := cfg.Theme
+	if name == "" {
+		return
+	}
+	if name == AutoTheme {
+		if detect != nil {
+			cfg.Background = detect()
+		}
+		name = AutoThemeVariant(cfg.Background)
+	}
+	colors, info, err := LoadTheme(ThemesDir(cfg.LoadedFrom), name)
+	if err != nil {
+		return // Reported by validateTheme
+	}
+	cfg.ThemeFile = info.Path
+
+	themeColors := make(map[string]string)
+	colorFields(&colors, func(key string, color *string) { themeColors[key] = *color })
+	defaults := GetDefaultConfig().ColorScheme
+	defaultColors := make(map[string]string)
+	colorFields(&defaults, func(key string, color *string) { defaultColors[key] = *color })
+	colorFields(&cfg.ColorScheme, func(key string, color *string) {
+		if *color == defaultColors[key] {
+			*color = themeColors[key]
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+const nordTheme = `description: Arctic palette
+colors:
+  border: "#4C566A"
+  background: "#2E3440"
+`
+
+// TestListThemes tests listing built-in themes and theme files
+func TestListThemes(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"nord.yml":     nordTheme,
+		"dark.yaml":    "description: My dark\n",
+		"broken.yml":   "colors:\n  bordr: \"#FFFFFF\"\n",
+		"notes.txt":    "not a theme",
+		".hidden.yml":  nordTheme,
+		"sub/deep.yml": nordTheme,
+	})
+
+	themes, err := ListThemes(dir)
+	if err != nil {
+		t.Fatalf("ListThemes() error = %v", err)
+	}
+	var names []string
+	for _, theme := range themes {
+		names = append(names, theme.Name)
+	}
+	if want := []string{"default", "auto", "dark", "light", "solarized", "broken", "nord"}; !slices.Equal(names, want) {
+		t.Errorf("ListThemes() names = %v, want %v", names, want)
+	}
+	if themes[2].Path != filepath.Join(dir, "dark.yaml") || themes[2].Description != "My dark" {
+		t.Errorf("dark = %+v, want the file replacing the built-in theme", themes[2])
+	}
+	if !strings.Contains(themes[5].Error, "bordr") || strings.Contains(themes[5].Error, "\n") {
+		t.Errorf("broken error = %q, want one line naming the unknown key", themes[5].Error)
+	}
+	if themes[6].Description != "Arctic palette" || themes[6].Error != "" {
+		t.Errorf("nord = %+v", themes[6])
+	}
+
+	if themes, err := ListThemes(filepath.Join(dir, "missing")); err != nil || len(themes) != len(BuiltinThemes) {
+		t.Errorf("ListThemes(missing) = %v, %v, want the built-in themes", themes, err)
+	}
+}
+
+// TestLoadTheme tests resolving theme colors from files and built-in palettes
+func TestLoadTheme(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"nord.yml":  nordTheme,
+		"empty.yml": "",
+		"bad.yml":   "colors:\n  border: blue\n",
+	})
+	defaults := GetDefaultConfig().ColorScheme
+
+	colors, info, err := LoadTheme(dir, "nord")
+	if err != nil {
+		t.Fatalf("LoadTheme(nord) error = %v", err)
+	}
+	if colors.Border != "#4C566A" || colors.Error != defaults.Error || info.Path != filepath.Join(dir, "nord.yml") {
+		t.Errorf("LoadTheme(nord) = %+v, %+v, want its colors over the defaults", colors, info)
+	}
+	if colors, _, err := LoadTheme(dir, "empty"); err != nil || colors != defaults {
+		t.Errorf("LoadTheme(empty) = %+v, %v, want the defaults", colors, err)
+	}
+	if colors, _, err := LoadTheme(dir, "solarized"); err != nil || colors.Background != "#002B36" {
+		t.Errorf("LoadTheme(solarized) = %+v, %v", colors, err)
+	}
+	if _, _, err := LoadTheme(dir, "bad"); err == nil || !strings.Contains(err.Error(), "colors.border") {
+		t.Errorf("LoadTheme(bad) error = %v, want the invalid color", err)
+	}
+	if _, _, err := LoadTheme(dir, "missing"); err == nil {
+		t.Error("LoadTheme(missing) succeeded, want an unknown theme error")
+	}
+}
+
+// TestLoadThemeFromConfig tests applying a theme file named in the config
+func TestLoadThemeFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yml":      "theme: nord\ncolorScheme:\n  error: \"#123456\"\n",
+		"themes/nord.yml": nordTheme,
+	})
+	configPath := filepath.Join(dir, "config.yml")
+
+	cfg, err := NewLoader().Load(context.Background(), LoadOptions{ConfigFilePath: configPath, NoProjectConfig: true})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Theme != "nord" || cfg.ColorScheme.Border != "#4C566A" {
+		t.Errorf("Theme = %q, border = %q, want nord's colors", cfg.Theme, cfg.ColorScheme.Border)
+	}
+	if cfg.ColorScheme.Error != "#123456" {
+		t.Errorf("ColorScheme.Error = %q, want the colorScheme setting over the theme", cfg.ColorScheme.Error)
+	}
+	themeFile := filepath.Join(dir, "themes", "nord.yml")
+	if cfg.ThemeFile != themeFile || !slices.Contains(cfg.RelatedFiles(), themeFile) {
+		t.Errorf("ThemeFile = %q, RelatedFiles() = %v, want %s", cfg.ThemeFile, cfg.RelatedFiles(), themeFile)
+	}
+
+	// An invalid theme file falls back to the default theme with a warning
+	if err := os.WriteFile(themeFile, []byte("colors:\n  border: blue\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewLoader().Load(context.Background(), LoadOptions{ConfigFilePath: configPath, NoProjectConfig: true, StrictMode: true})
+	if err == nil || !strings.Contains(err.Error(), "theme") {
+		t.Errorf("strict Load() error = %v, want the invalid theme file", err)
+	}
+	cfg, err = NewLoader().Load(context.Background(), LoadOptions{ConfigFilePath: configPath, NoProjectConfig: true})
+	if err != nil || cfg.Theme != "default" || cfg.ThemeFile != "" {
+		t.Errorf("Load() = theme %q file %q, %v, want the default theme", cfg.Theme, cfg.ThemeFile, err)
+	}
+}
+
+// TestLoadAutoTheme tests theme auto picking the light or dark theme by the background
+func TestLoadAutoTheme(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yml":       "theme: auto\n",
+		"themes/light.yml": "colors:\n  background: \"#FDF6E3\"\n",
+		"themes/auto.yml":  nordTheme,
+	})
+	configPath := filepath.Join(dir, "config.yml")
+
+	tests := []struct {
+		name       string
+		detect     func() platform.Background
+		background string
+		themeFile  string
+	}{
+		{"light terminal", func() platform.Background { return platform.BackgroundLight }, "#FDF6E3", filepath.Join(dir, "themes", "light.yml")},
+		{"dark terminal", func() platform.Background { return platform.BackgroundDark }, "#1C1C1C", ""},
+		{"unknown background", func() platform.Background { return platform.BackgroundUnknown }, "#1C1C1C", ""},
+		{"not detected", nil, "#1C1C1C", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := NewLoader().Load(context.Background(), LoadOptions{
+				ConfigFilePath:   configPath,
+				NoProjectConfig:  true,
+				StrictMode:       true,
+				DetectBackground: tt.detect,
+			})
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.Theme != AutoTheme {
+				t.Errorf("Theme = %q, want auto kept", cfg.Theme)
+			}
+			if cfg.ColorScheme.Background != tt.background || cfg.ThemeFile != tt.themeFile {
+				t.Errorf("background = %q, ThemeFile = %q, want %q, %q",
+					cfg.ColorScheme.Background, cfg.ThemeFile, tt.background, tt.themeFile)
+			}
+			if tt.detect != nil && cfg.Background != tt.detect() {
+				t.Errorf("Background = %q, want %q", cfg.Background, tt.detect())
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// TrustStoreFileName is the name of the persisted trust decisions file in the state directory.
+const TrustStoreFileName = "trusted-projects.json"
+
+// TrustFunc decides whether a project overlay may be applied.
+// path is the absolute overlay path and fingerprint its content hash (see FingerprintConfig).
+type TrustFunc func(path, fingerprint string) bool
+
+// TrustDecision records whether the user trusted a project overlay with a given fingerprint.
+type TrustDecision struct {
+	DecidedAt   time.Time `json:"decidedAt"`
+	Fingerprint string    `json:"fingerprint"`
+	Trusted     bool      `json:"trusted"`
+}
+
+// TrustStore persists trust decisions for repository-level config overlays.
+// Project overlays can define settings that run code (custom commands and hooks), so an
+// overlay is only applied once the user has trusted its exact content. Any edit changes
+// the fingerprint and requires a new decision.
+type TrustStore struct {
+	decisions map[string]TrustDecision // overlay path -> decision
+	path      string
+	mu        sync.Mutex
+}
+
+// FingerprintConfig returns the SHA-256 fingerprint of a config file ("sha256:<hex>").
+func FingerprintConfig(path string) (string, error) {
+	// #nosec G304 -- path comes from FindProjectConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return FingerprintData(data), nil
+}
+
+// FingerprintData returns the SHA-256 fingerprint of config content ("sha256:<hex>").
+// Callers that go on to apply the content fingerprint the bytes they decode, so a file
+// replaced after the trust check is never applied under the old decision.
+func FingerprintData(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// DefaultTrustStorePath returns the trust store location in the platform state directory,
+// with the same temp fallback the app applies. Returns an empty string if no directory is usable.
+func DefaultTrustStorePath() string {
+	return platform.AppDir(platform.DirState, TrustStoreFileName)
+}
+
+// LoadTrustStore reads trust decisions from path. A missing file yields an empty store.
+func LoadTrustStore(path string) (*TrustStore, error) {
+	store := &TrustStore{path: path, decisions: make(map[string]TrustDecision)}
+
+	// #nosec G304 -- path is the trust store in the user's state directory
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trust store: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &store.decisions); err != nil {
+		return nil, fmt.Errorf("invalid trust store %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// Lookup returns the recorded decision for an overlay.
+// decided is false when the overlay is unknown or its content changed since the decision.
+func (ts *TrustStore) Lookup(configPath, fingerprint string) (trusted, decided bool) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	decision, ok := ts.decisions[configPath]
+	if !ok || decision.Fingerprint != fingerprint {
+		return false, false
+	}
+	return decision.Trusted, true
+}
+
+// Record stores a decision for an overlay fingerprint and persists the store.
+func (ts *TrustStore) Record(configPath, fingerprint string, trusted bool) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	ts.decisions[configPath] = TrustDecision{
+		Fingerprint: fingerprint,
+		Trusted:     trusted,
+		DecidedAt:   time.Now().UTC(),
+	}
+
+	data, err := json.MarshalIndent(ts.decisions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode trust store: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(ts.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create trust store directory: %w", err)
+	}
+
+	if err := platform.WriteFileAtomic(ts.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write trust store: %w", err)
+	}
+	return nil
+}
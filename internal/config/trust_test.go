@@ -0,0 +1,165 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFingerprintConfig verifies fingerprints track file content
+func TestFingerprintConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".lazynuget.yml")
+	if err := os.WriteFile(path, []byte("theme: dark\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	first, err := FingerprintConfig(path)
+	if err != nil {
+		t.Fatalf("FingerprintConfig() error = %v", err)
+	}
+	again, _ := FingerprintConfig(path)
+	if first != again {
+		t.Errorf("fingerprint not stable: %s vs %s", first, again)
+	}
+
+	if err := os.WriteFile(path, []byte("theme: light\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	changed, _ := FingerprintConfig(path)
+	if changed == first {
+		t.Error("fingerprint did not change with content")
+	}
+
+	if _, err := FingerprintConfig(filepath.Join(t.TempDir(), "missing.yml")); err == nil {
+		t.Error("FingerprintConfig() expected error for missing file")
+	}
+}
+
+// TestTrustStoreRoundTrip verifies decisions persist across loads
+func TestTrustStoreRoundTrip(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "nested", TrustStoreFileName)
+
+	store, err := LoadTrustStore(storePath)
+	if err != nil {
+		t.Fatalf("LoadTrustStore() on missing file error = %v", err)
+	}
+	if _, decided := store.Lookup("/repo/.lazynuget.yml", "sha256:abc"); decided {
+		t.Error("empty store should have no decisions")
+	}
+
+	if err := store.Record("/repo/.lazynuget.yml", "sha256:abc", true); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := store.Record("/other/.lazynuget.yml", "sha256:def", false); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	reloaded, err := LoadTrustStore(storePath)
+	if err != nil {
+		t.Fatalf("LoadTrustStore() error = %v", err)
+	}
+	if trusted, decided := reloaded.Lookup("/repo/.lazynuget.yml", "sha256:abc"); !decided || !trusted {
+		t.Errorf("Lookup(trusted) = (%v, %v), want (true, true)", trusted, decided)
+	}
+	if trusted, decided := reloaded.Lookup("/other/.lazynuget.yml", "sha256:def"); !decided || trusted {
+		t.Errorf("Lookup(denied) = (%v, %v), want (false, true)", trusted, decided)
+	}
+	if _, decided := reloaded.Lookup("/repo/.lazynuget.yml", "sha256:changed"); decided {
+		t.Error("decision should not apply to a different fingerprint")
+	}
+
+	info, err := os.Stat(storePath)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if perm := info.Mode().Perm(); perm&0o077 != 0 && os.PathSeparator == '/' {
+		t.Errorf("trust store permissions = %o, want owner-only", perm)
+	}
+}
+
+// TestLoadTrustStoreInvalid verifies a corrupt store is reported
+func TestLoadTrustStoreInvalid(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), TrustStoreFileName)
+	if err := os.WriteFile(storePath, []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("Failed to write store: %v", err)
+	}
+	if _, err := LoadTrustStore(storePath); err == nil {
+		t.Error("LoadTrustStore() expected error for invalid JSON")
+	}
+}
+
+// TestLoadSkipsUntrustedProjectConfig verifies the trust gate controls overlay application
+func TestLoadSkipsUntrustedProjectConfig(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o700); err != nil {
+		t.Fatalf("Failed to create .git: %v", err)
+	}
+	overlay := filepath.Join(repo, ".lazynuget.yml")
+	if err := os.WriteFile(overlay, []byte("theme: dark\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write overlay: %v", err)
+	}
+	wantFingerprint, _ := FingerprintConfig(overlay)
+
+	var gotPath, gotFingerprint string
+	opts := LoadOptions{
+		WorkingDir: repo,
+		TrustProject: func(path, fingerprint string) bool {
+			gotPath, gotFingerprint = path, fingerprint
+			return false
+		},
+	}
+
+	cfg, err := NewLoader().Load(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if gotPath != overlay || gotFingerprint != wantFingerprint {
+		t.Errorf("trust gate called with (%q, %q), want (%q, %q)", gotPath, gotFingerprint, overlay, wantFingerprint)
+	}
+	if cfg.Theme != "default" || cfg.ProjectConfigPath != "" {
+		t.Errorf("untrusted overlay was applied: theme=%s projectConfig=%s", cfg.Theme, cfg.ProjectConfigPath)
+	}
+
+	opts.TrustProject = func(string, string) bool { return true }
+	cfg, err = NewLoader().Load(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Theme != "dark" {
+		t.Errorf("trusted overlay was not applied: theme=%s", cfg.Theme)
+	}
+}
+
+// TestLoadAppliesOnlyTrustedBytes verifies an overlay replaced after the trust check is
+// not applied under the decision made for the original content
+func TestLoadAppliesOnlyTrustedBytes(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o700); err != nil {
+		t.Fatalf("Failed to create .git: %v", err)
+	}
+	overlay := filepath.Join(repo, ".lazynuget.yml")
+	if err := os.WriteFile(overlay, []byte("theme: dark\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write overlay: %v", err)
+	}
+	trusted, _ := FingerprintConfig(overlay)
+
+	opts := LoadOptions{
+		WorkingDir: repo,
+		TrustProject: func(_, fingerprint string) bool {
+			// Swap the file once the decision has been made
+			if err := os.WriteFile(overlay, []byte("theme: light\n"), 0o600); err != nil {
+				t.Fatalf("Failed to swap overlay: %v", err)
+			}
+			return fingerprint == trusted
+		},
+	}
+
+	cfg, err := NewLoader().Load(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Theme != "dark" {
+		t.Errorf("theme = %s, want dark from the content that was trusted", cfg.Theme)
+	}
+}
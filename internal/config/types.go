@@ -4,8 +4,14 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"reflect"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
 )
 
 // Config is the root configuration object containing all application settings.
@@ -13,26 +19,147 @@ import (
 type Config struct {
 	LoadedAt          time.Time             `yaml:"-" toml:"-"`
 	Keybindings       map[string]KeyBinding `yaml:"keybindings" toml:"keybindings"`
+	Profiles          map[string]Config     `yaml:"profiles" toml:"profiles"`    // Named overrides selected with --profile
+	Include           []string              `yaml:"include" toml:"include"`      // Fragment files applied before this file, relative to it
+	IncludedFiles     []string              `yaml:"-" toml:"-"`                  // The fragments loaded, in order, for hot-reload
+	ThemeFile         string                `yaml:"-" toml:"-"`                  // The file of the theme applied; empty for built-in themes
+	Background        platform.Background   `yaml:"-" toml:"-"`                  // The terminal background detected for theme auto
+	LogLevels         map[string]string     `yaml:"logLevels" toml:"log_levels"` // Per-module overrides of logLevel (e.g., nuget: debug)
+	PinnedPackages    []PinRule             `yaml:"pinnedPackages" toml:"pinned_packages"`
+	Feeds             []Feed                `yaml:"feeds" toml:"feeds"`
+	FeedFallbacks     []FeedFallback        `yaml:"feedFallbacks" toml:"feed_fallbacks"` // Mirrors read while a feed is down
+	secrets           []string              // Decrypted values, for log redaction (see Secrets)
+	LicensePolicy     LicensePolicy         `yaml:"licensePolicy" toml:"license_policy"`
+	Sandbox           SandboxConfig         `yaml:"sandbox" toml:"sandbox"`
+	Hooks             Hooks                 `yaml:"hooks" toml:"hooks"`
+	CustomCommands    []CustomCommand       `yaml:"customCommands" toml:"custom_commands"`
+	SavedFilters      []SavedFilter         `yaml:"savedFilters" toml:"saved_filters"` // Named package list filters, in the command palette
+	Plugins           []Plugin              `yaml:"plugins" toml:"plugins"`
+	Telemetry         TelemetryConfig       `yaml:"telemetry" toml:"telemetry"`
 	ColorScheme       ColorScheme           `yaml:"colorScheme" toml:"color_scheme"`
-	DotnetPath        string                `yaml:"dotnetPath" toml:"dotnet_path" default:""`
+	StatusBar         StatusBarConfig       `yaml:"statusBar" toml:"status_bar"`
+	Confirm           ConfirmConfig         `yaml:"confirm" toml:"confirm"`
+	DotnetPath        string                `yaml:"dotnetPath" toml:"dotnet_path" default:"" expand:"env"`
 	DotnetVerbosity   string                `yaml:"dotnetVerbosity" toml:"dotnet_verbosity" validate:"oneof=quiet minimal normal detailed diagnostic" default:"minimal"`
+	OperationBackend  string                `yaml:"operationBackend" toml:"operation_backend" validate:"oneof=cli direct" default:"cli"`   // How package references are changed: dotnet add/remove, or editing project files
+	RestoreMode       string                `yaml:"restoreMode" toml:"restore_mode" validate:"oneof=always batch manual" default:"always"` // When changed projects are restored
 	LogFormat         string                `yaml:"logFormat" toml:"log_format" validate:"oneof=text json" default:"text"`
-	LogDir            string                `yaml:"logDir" toml:"log_dir" default:""`
+	LogDir            string                `yaml:"logDir" toml:"log_dir" default:"" expand:"env"`
 	LogLevel          string                `yaml:"logLevel" toml:"log_level" validate:"oneof=debug info warn error" default:"info"`
 	DateFormat        string                `yaml:"dateFormat" toml:"date_format" validate:"dateformat" default:"2006-01-02"`
+	Editor            string                `yaml:"editor" toml:"editor" default:"" expand:"env"` // Command to edit files (e.g., "code --wait"); empty uses $VISUAL or $EDITOR
 	LoadedFrom        string                `yaml:"-" toml:"-"`
+	ProjectConfigPath string                `yaml:"-" toml:"-"`
+	Profile           string                `yaml:"-" toml:"-"` // Active profile, if any
 	KeybindingProfile string                `yaml:"keybindingProfile" toml:"keybinding_profile" validate:"oneof=default vim emacs" default:"default"`
 	Theme             string                `yaml:"theme" toml:"theme" validate:"oneof=default dark light solarized" default:"default"`
+	PackageIcons      string                `yaml:"packageIcons" toml:"package_icons" validate:"oneof=auto kitty iterm2 sixel off" default:"auto"` // Inline image protocol for package icons
 	Version           string                `yaml:"version" toml:"version"`
 	LogRotation       LogRotation           `yaml:"logRotation" toml:"log_rotation"`
 	Timeouts          Timeouts              `yaml:"timeouts" toml:"timeouts"`
 	RefreshInterval   time.Duration         `yaml:"refreshInterval" toml:"refresh_interval" validate:"min=0" default:"0"`
+	AdvisoryCacheTTL  time.Duration         `yaml:"advisoryCacheTTL" toml:"advisory_cache_ttl" validate:"min=1m" default:"24h"` // How long OSV.dev advisories are reused
+	PrefetchDelay     time.Duration         `yaml:"prefetchDelay" toml:"prefetch_delay" validate:"min=50ms" default:"300ms"`    // How long a package stays selected before its details are prefetched
 	CacheSize         int                   `yaml:"cacheSize" toml:"cache_size" validate:"min=0" default:"50"`
 	MaxConcurrentOps  int                   `yaml:"maxConcurrentOps" toml:"max_concurrent_ops" validate:"min=1,max=16" default:"4"`
+	RenderFPS         int                   `yaml:"renderFps" toml:"render_fps" validate:"min=1,max=120" default:"30"`     // Most frames drawn per second; faster updates are coalesced
+	StaleAfterYears   int                   `yaml:"staleAfterYears" toml:"stale_after_years" validate:"min=1" default:"2"` // Packages unpublished this long are flagged as stale
 	ShowLineNumbers   bool                  `yaml:"showLineNumbers" toml:"show_line_numbers" default:"false"`
 	ShowHints         bool                  `yaml:"showHints" toml:"show_hints" default:"true"`
 	CompactMode       bool                  `yaml:"compactMode" toml:"compact_mode" default:"false"`
 	HotReload         bool                  `yaml:"hotReload" toml:"hot_reload" default:"false"`
+	UpdateCheck       bool                  `yaml:"updateCheck" toml:"update_check" default:"false"` // Notify about new releases at startup
+}
+
+// RelatedFiles returns the files besides the config file whose changes change the
+// config: included fragments and the theme file.
+func (c *Config) RelatedFiles() []string {
+	files := slices.Clone(c.IncludedFiles)
+	if c.ThemeFile != "" {
+		files = append(files, c.ThemeFile)
+	}
+	return files
+}
+
+// Secrets returns setting values that must never appear in logs: values decrypted
+// from the config file and feed API keys and passwords. Register them with the logger's
+// redactor.
+func (c *Config) Secrets() []string {
+	secrets := slices.Clone(c.secrets)
+	for _, feed := range c.Feeds {
+		for _, secret := range []string{feed.APIKey, feed.Password} {
+			if secret != "" {
+				secrets = append(secrets, secret)
+			}
+		}
+	}
+	return secrets
+}
+
+// FeedCredentials returns the feed whose credentials (apiKey, or username and password)
+// authenticate a request to rawURL: an enabled feed with the same scheme and host. Where
+// several share a host, the one whose URL shares the longest path with rawURL wins.
+// Feeds that serve resources from other hosts must list each host as a feed.
+func (c *Config) FeedCredentials(rawURL string) (Feed, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return Feed{}, false
+	}
+	var found Feed
+	best := -1
+	for _, feed := range c.Feeds {
+		f, err := url.Parse(feed.URL)
+		if feed.Disabled || (feed.APIKey == "" && feed.Password == "") || err != nil ||
+			!strings.EqualFold(f.Scheme, u.Scheme) || !strings.EqualFold(f.Host, u.Host) {
+			continue
+		}
+		shared := 0
+		for shared < min(len(f.Path), len(u.Path)) && f.Path[shared] == u.Path[shared] {
+			shared++
+		}
+		if shared > best {
+			found, best = feed, shared
+		}
+	}
+	return found, best >= 0
+}
+
+// RequestTimeout returns how long a request to rawURL may take: the timeout of the feed
+// on the same host, or timeouts.networkRequest. Where several feeds share a host, the
+// one whose URL shares the longest path with rawURL wins. A feed on nuget.org covers
+// every nuget.org host, as its search service is served from other hosts than its index.
+func (c *Config) RequestTimeout(rawURL string) time.Duration {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return c.Timeouts.NetworkRequest
+	}
+	timeout, best := c.Timeouts.NetworkRequest, -1
+	for _, feed := range c.Feeds {
+		f, err := url.Parse(feed.URL)
+		if feed.Timeout <= 0 || feed.Disabled || err != nil || !sameFeedHost(f.Hostname(), u.Hostname()) {
+			continue
+		}
+		shared := 0
+		for shared < min(len(f.Path), len(u.Path)) && f.Path[shared] == u.Path[shared] {
+			shared++
+		}
+		if shared > best {
+			timeout, best = feed.Timeout, shared
+		}
+	}
+	return timeout
+}
+
+// sameFeedHost reports whether requests to host are requests to the feed on feedHost.
+func sameFeedHost(feedHost, host string) bool {
+	feedHost, host = strings.ToLower(feedHost), strings.ToLower(host)
+	if feedHost == "" || host == "" {
+		return false
+	}
+	if feedHost == "nuget.org" || strings.HasSuffix(feedHost, ".nuget.org") {
+		return host == "nuget.org" || strings.HasSuffix(host, ".nuget.org")
+	}
+	return feedHost == host
 }
 
 // ColorScheme defines customizable colors for UI elements.
@@ -48,6 +175,62 @@ type ColorScheme struct {
 	Warning     string `yaml:"warning" toml:"warning" validate:"hexcolor" default:"#FFA500"`
 	Success     string `yaml:"success" toml:"success" validate:"hexcolor" default:"#00FF00"`
 	Info        string `yaml:"info" toml:"info" validate:"hexcolor" default:"#00FFFF"`
+
+	// Per-panel overrides of the colors above
+	Projects  PanelColors `yaml:"projects,omitempty" toml:"projects,omitempty"`
+	Packages  PanelColors `yaml:"packages,omitempty" toml:"packages,omitempty"`
+	Details   PanelColors `yaml:"details,omitempty" toml:"details,omitempty"`
+	StatusBar PanelColors `yaml:"statusbar,omitempty" toml:"statusbar,omitempty"`
+}
+
+// PanelColors overrides the colorScheme colors in one panel. Empty fields use the flat
+// colorScheme color named in PanelColorFallbacks, so configs without overrides look as before.
+type PanelColors struct {
+	Fg          string `yaml:"fg,omitempty" toml:"fg,omitempty"`
+	Bg          string `yaml:"bg,omitempty" toml:"bg,omitempty"`
+	Border      string `yaml:"border,omitempty" toml:"border,omitempty"`
+	BorderFocus string `yaml:"borderFocus,omitempty" toml:"border_focus,omitempty"`
+	HeaderFg    string `yaml:"headerFg,omitempty" toml:"header_fg,omitempty"`
+	SelectedFg  string `yaml:"selectedFg,omitempty" toml:"selected_fg,omitempty"`
+	SelectedBg  string `yaml:"selectedBg,omitempty" toml:"selected_bg,omitempty"`
+}
+
+// StatusBarConfig composes the status bar from segments.
+type StatusBarConfig struct {
+	Format      string `yaml:"format" toml:"format" default:"{mode} {keys} {repo} {feeds} {pending} {vulns} {clock}"` // Segments as {name}, with the text between them
+	ClockFormat string `yaml:"clockFormat" toml:"clock_format" validate:"dateformat" default:"15:04"`                 // Go time layout of the clock segment
+}
+
+// ConfirmConfig sets which package operations ask before they change projects: always,
+// or never. Bulk covers any operation on more than one package and asks even when the
+// operation alone would not.
+type ConfirmConfig struct {
+	Add    string `yaml:"add" toml:"add" validate:"oneof=always never" default:"never"`
+	Remove string `yaml:"remove" toml:"remove" validate:"oneof=always never" default:"always"`
+	Update string `yaml:"update" toml:"update" validate:"oneof=always never" default:"never"`
+	Bulk   string `yaml:"bulk" toml:"bulk" validate:"oneof=always never" default:"always"`
+}
+
+// ConfirmPolicies are the values of the confirm settings.
+var ConfirmPolicies = []string{"always", "never"}
+
+// StatusBarSegments are the segments a status bar format can name.
+var StatusBarSegments = []string{"mode", "keys", "repo", "feeds", "pending", "vulns", "clock"}
+
+// statusBarSegmentRegex matches a segment in a status bar format.
+var statusBarSegmentRegex = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// StatusBarFormatSegments returns the segments a format names, in order, or an error
+// naming the first unknown one.
+func StatusBarFormatSegments(format string) ([]string, error) {
+	var segments []string
+	for _, m := range statusBarSegmentRegex.FindAllStringSubmatch(format, -1) {
+		if !slices.Contains(StatusBarSegments, m[1]) {
+			return nil, fmt.Errorf("unknown segment {%s} (want %s)", m[1], strings.Join(StatusBarSegments, ", "))
+		}
+		segments = append(segments, m[1])
+	}
+	return segments, nil
 }
 
 // KeyBinding maps an action to a key combination.
@@ -76,6 +259,142 @@ type LogRotation struct {
 	Compress   bool `yaml:"compress" toml:"compress" default:"true"`
 }
 
+// PinRule holds a package at a version so updates skip it.
+// Typically shared with the team through a repository-level .lazynuget.yml.
+type PinRule struct {
+	Package string `yaml:"package" toml:"package"`                     // Package ID; a trailing * matches a prefix (e.g., "Microsoft.Extensions.*")
+	Version string `yaml:"version,omitempty" toml:"version,omitempty"` // Version or NuGet range to stay on; empty pins the installed version
+	Reason  string `yaml:"reason,omitempty" toml:"reason,omitempty"`   // Shown in the UI when an update is skipped
+}
+
+// FeedFallback names the mirrors that serve reads of matching packages while the feed
+// they come from is unavailable (unreachable, or failing with 5xx statuses).
+type FeedFallback struct {
+	Packages string   `yaml:"packages" toml:"packages"` // Package ID; a trailing * matches a prefix, and "*" every package
+	Feeds    []string `yaml:"feeds" toml:"feeds"`       // Feed names, "nuget.org", or service index URLs, tried in order
+}
+
+// LicensePolicy restricts which package licenses may be installed.
+// License identifiers are SPDX expressions (e.g., "MIT", "Apache-2.0").
+type LicensePolicy struct {
+	Allowed []string `yaml:"allowed" toml:"allowed"` // Empty = any license not explicitly denied
+	Denied  []string `yaml:"denied" toml:"denied"`
+}
+
+// Feed describes a NuGet package source in addition to those from nuget.config.
+type Feed struct {
+	Name     string        `yaml:"name" toml:"name"`
+	URL      string        `yaml:"url" toml:"url" expand:"env"`               // V3 service index URL or local folder path
+	APIKey   string        `yaml:"apiKey,omitempty" toml:"api_key,omitempty"` // Sent as X-NuGet-ApiKey to the feed's host; store it encrypted
+	Username string        `yaml:"username,omitempty" toml:"username,omitempty"`
+	Password string        `yaml:"password,omitempty" toml:"password,omitempty"` // With Username, sent as basic auth to the feed's host (e.g., an Azure DevOps PAT); store it encrypted
+	Timeout  time.Duration `yaml:"timeout,omitempty" toml:"timeout,omitempty"`   // Per request to the feed's host; 0 uses timeouts.networkRequest
+	Disabled bool          `yaml:"disabled,omitempty" toml:"disabled,omitempty"`
+}
+
+// SandboxConfig restricts hooks and custom commands to a sandbox (bwrap on Linux,
+// sandbox-exec on macOS). These are the defaults; individual hooks and commands may
+// override them. Commands that require a sandbox refuse to run where none is available.
+type SandboxConfig struct {
+	WritablePaths []string `yaml:"writablePaths" toml:"writable_paths" expand:"env"` // Writable in addition to the working directory
+	Enabled       bool     `yaml:"enabled" toml:"enabled" default:"false"`
+	AllowNetwork  bool     `yaml:"allowNetwork" toml:"allow_network" default:"false"`
+}
+
+// Hooks are shell commands run around package operations. Each command runs in the
+// project's directory with the operation described in LAZYNUGET_* environment variables
+// (see package hooks), and its output goes to the log.
+type Hooks struct {
+	PreInstall  []Hook `yaml:"preInstall" toml:"pre_install"`   // Before a package is added; a failure cancels the install
+	PostUpdate  []Hook `yaml:"postUpdate" toml:"post_update"`   // After a package version changes
+	PostRestore []Hook `yaml:"postRestore" toml:"post_restore"` // After dotnet restore succeeds
+}
+
+// Hook is one hook command.
+type Hook struct {
+	Command string        `yaml:"command" toml:"command"`                     // Run with sh -c (cmd /C on Windows)
+	Timeout time.Duration `yaml:"timeout,omitempty" toml:"timeout,omitempty"` // Default 1m; the command is killed when it expires
+	Sandbox *bool         `yaml:"sandbox,omitempty" toml:"sandbox,omitempty"` // Overrides sandbox.enabled for this hook
+}
+
+// CustomCommand is a shell command added to the UI, bound to a key and listed in the
+// command palette, like lazygit's customCommands. {{package}}, {{project}}, and {{version}}
+// in the command are replaced with the selection, and {{name}} with the answer to the
+// prompt of that name. Values are quoted for the shell, so placeholders go unquoted.
+type CustomCommand struct {
+	Name        string         `yaml:"name" toml:"name"`                                   // Palette entry, and the name for `lazynuget custom run`
+	Key         string         `yaml:"key,omitempty" toml:"key,omitempty"`                 // Optional key that runs it
+	Context     string         `yaml:"context,omitempty" toml:"context,omitempty"`         // Panel the key works in (default: global)
+	Command     string         `yaml:"command" toml:"command"`                             // Run with sh -c (cmd /C on Windows) in the workspace root
+	Description string         `yaml:"description,omitempty" toml:"description,omitempty"` // Shown next to the name in the palette
+	Prompts     []CustomPrompt `yaml:"prompts,omitempty" toml:"prompts,omitempty"`         // Asked in order before the command runs
+	Output      string         `yaml:"output,omitempty" toml:"output,omitempty"`           // pager (default), terminal, or none
+	Timeout     time.Duration  `yaml:"timeout,omitempty" toml:"timeout,omitempty"`         // Default 10m; not applied to output terminal
+	Sandbox     *bool          `yaml:"sandbox,omitempty" toml:"sandbox,omitempty"`         // Overrides sandbox.enabled for this command
+}
+
+// SavedFilter names a package list filter (e.g., "is:vulnerable update:major"), listed in
+// the command palette and usable as `--filter NAME`.
+type SavedFilter struct {
+	Name        string `yaml:"name" toml:"name"`
+	Query       string `yaml:"query" toml:"query"`
+	Description string `yaml:"description,omitempty" toml:"description,omitempty"` // Shown next to the name in the palette
+}
+
+// CustomPrompt asks for a value of a custom command.
+type CustomPrompt struct {
+	Name    string   `yaml:"name" toml:"name"`                           // Placeholder the answer replaces ({{name}})
+	Title   string   `yaml:"title,omitempty" toml:"title,omitempty"`     // Question asked (default: the name)
+	Default string   `yaml:"default,omitempty" toml:"default,omitempty"` // Answer when none is given; may use the selection placeholders
+	Options []string `yaml:"options,omitempty" toml:"options,omitempty"` // When set, the answer is picked from these
+}
+
+// Custom command placeholders filled from the selection, and values of Context and Output.
+var (
+	CustomCommandPlaceholders = []string{"package", "project", "version"}
+	CustomCommandContexts     = []string{"global", "projects", "packages", "details"}
+	CustomCommandOutputs      = []string{"pager", "terminal", "none"}
+)
+
+// placeholderRegex matches a placeholder in a custom command: {{name}}, spaces allowed
+// inside the braces.
+var placeholderRegex = regexp.MustCompile(`\{\{\s*([A-Za-z][A-Za-z0-9_-]*)\s*\}\}`)
+
+// Placeholders returns the names of the placeholders in a custom command template, in
+// order of first use.
+func Placeholders(template string) []string {
+	var names []string
+	for _, m := range placeholderRegex.FindAllStringSubmatch(template, -1) {
+		if !slices.Contains(names, m[1]) {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// ReplacePlaceholders replaces each placeholder in template with replace(name).
+func ReplacePlaceholders(template string, replace func(name string) string) string {
+	return placeholderRegex.ReplaceAllStringFunc(template, func(m string) string {
+		return replace(placeholderRegex.FindStringSubmatch(m)[1])
+	})
+}
+
+// Plugin is an external program that adds commands, panels, and package annotations.
+// It is started on demand and speaks JSON-RPC over its stdin and stdout (see package plugin).
+type Plugin struct {
+	Name    string        `yaml:"name" toml:"name"`
+	Command string        `yaml:"command" toml:"command" expand:"env"`        // Executable to start
+	Args    []string      `yaml:"args,omitempty" toml:"args,omitempty"`       // Arguments passed to the executable
+	Timeout time.Duration `yaml:"timeout,omitempty" toml:"timeout,omitempty"` // Per request; default 10s
+	Sandbox *bool         `yaml:"sandbox,omitempty" toml:"sandbox,omitempty"` // Overrides sandbox.enabled for this plugin
+}
+
+// TelemetryConfig configures where anonymous usage reports are sent. Telemetry itself is
+// opt-in: consent is recorded separately (see `lazynuget telemetry`), never in config files.
+type TelemetryConfig struct {
+	Endpoint string `yaml:"endpoint" toml:"endpoint" default:"" expand:"env"` // Reports are only sent when set
+}
+
 // ConfigSource represents one of the four configuration sources.
 // See: specs/002-config-management/data-model.md entity #6
 type ConfigSource struct {
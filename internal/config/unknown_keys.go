@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// findUnknownKeys returns the dotted paths of keys in a config file that do not map to
+// any Config setting (typos such as "logLevle" or settings from a newer version).
+func findUnknownKeys(filePath string) ([]string, error) {
+	data, err := readConfigFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return unknownKeysIn(filePath, data)
+}
+
+// unknownKeysIn returns the unknown keys in config content already read from filePath.
+func unknownKeysIn(filePath string, data []byte) ([]string, error) {
+	switch detectFormat(filePath) {
+	case FormatYAML:
+		var root yaml.Node
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return nil, fmt.Errorf("YAML parsing error: %w", err)
+		}
+		var unknown []string
+		unknownYAMLKeys(&root, reflect.TypeOf(Config{}), "", &unknown)
+		return unknown, nil
+	case FormatTOML:
+		var cfg Config
+		metadata, err := toml.Decode(string(data), &cfg)
+		if err != nil {
+			return nil, fmt.Errorf("TOML parsing error: %w", err)
+		}
+		var unknown []string
+		for _, key := range metadata.Undecoded() {
+			unknown = append(unknown, key.String())
+		}
+		return unknown, nil
+	default:
+		return nil, fmt.Errorf("unsupported config file format (must be .yml, .yaml, or .toml): %s", filePath)
+	}
+}
+
+// unknownYAMLKeys walks a YAML node alongside the Go type it decodes into and records
+// mapping keys that have no matching yaml tag.
+func unknownYAMLKeys(node *yaml.Node, t reflect.Type, prefix string, unknown *[]string) {
+	if node == nil {
+		return
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			unknownYAMLKeys(child, t, prefix, unknown)
+		}
+	case yaml.MappingNode:
+		switch {
+		case t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{}):
+			fields := yamlFields(t)
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := node.Content[i].Value
+				path := joinKeyPath(prefix, key)
+				fieldType, ok := fields[key]
+				if !ok {
+					*unknown = append(*unknown, path)
+					continue
+				}
+				unknownYAMLKeys(node.Content[i+1], fieldType, path, unknown)
+			}
+		case t.Kind() == reflect.Map:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				path := joinKeyPath(prefix, node.Content[i].Value)
+				unknownYAMLKeys(node.Content[i+1], t.Elem(), path, unknown)
+			}
+		}
+	case yaml.SequenceNode:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, item := range node.Content {
+				unknownYAMLKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", prefix, i), unknown)
+			}
+		}
+	}
+}
+
+// yamlFields maps yaml tag names of a struct's exported fields to their types.
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// joinKeyPath joins a parent key path and a child key with a dot.
+func joinKeyPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// unknownKeyErrors converts unknown keys into validation warnings.
+func unknownKeyErrors(source string, keys []string) []ValidationError {
+	errors := make([]ValidationError, 0, len(keys))
+	for _, key := range keys {
+		errors = append(errors, ValidationError{
+			Key:          key,
+			Constraint:   "unknown setting in " + source,
+			SuggestedFix: "Remove it or check the spelling (see `lazynuget config schema`)",
+			Severity:     "warning",
+			DefaultUsed:  "ignored",
+		})
+	}
+	return errors
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestFindUnknownKeys tests detection of settings that don't map to the Config struct
+func TestFindUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		content  string
+		want     []string
+	}{
+		{
+			name:     "valid yaml",
+			fileName: "config.yml",
+			content:  "logLevel: debug\ntimeouts:\n  networkRequest: 10s\nkeybindings:\n  quit:\n    key: q\n",
+			want:     nil,
+		},
+		{
+			name:     "yaml typos at every level",
+			fileName: "config.yml",
+			content: `logLevle: debug
+timeouts:
+  networkRequests: 10s
+keybindings:
+  quit:
+    keys: q
+feeds:
+  - name: internal
+    uri: https://example.com
+`,
+			want: []string{"logLevle", "timeouts.networkRequests", "keybindings.quit.keys", "feeds[0].uri"},
+		},
+		{
+			name:     "valid toml",
+			fileName: "config.toml",
+			content:  "log_level = \"debug\"\n[timeouts]\nnetwork_request = \"10s\"\n",
+			want:     nil,
+		},
+		{
+			name:     "toml typos",
+			fileName: "config.toml",
+			content:  "log_levle = \"debug\"\n[timeouts]\nnetwork = \"10s\"\n",
+			want:     []string{"log_levle", "timeouts.network"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.fileName)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			got, err := findUnknownKeys(path)
+			if err != nil {
+				t.Fatalf("findUnknownKeys() error = %v", err)
+			}
+			slices.Sort(got)
+			want := slices.Clone(tt.want)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("findUnknownKeys() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// TestLoadStrictMode tests that strict mode blocks on any validation finding
+func TestLoadStrictMode(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantErr    string
+		strictMode bool
+	}{
+		{
+			name:       "valid config passes strict mode",
+			content:    "logLevel: debug\nrefreshInterval: 30s\n",
+			strictMode: true,
+		},
+		{
+			name:       "unknown key warns without strict mode",
+			content:    "logLevle: debug\n",
+			strictMode: false,
+		},
+		{
+			name:       "unknown key fails strict mode",
+			content:    "logLevle: debug\n",
+			strictMode: true,
+			wantErr:    "logLevle",
+		},
+		{
+			name:       "out-of-range value fails strict mode",
+			content:    "maxConcurrentOps: 64\n",
+			strictMode: true,
+			wantErr:    "maxConcurrentOps",
+		},
+		{
+			name: "keybinding conflict fails strict mode",
+			content: `keybindings:
+  quit:
+    action: quit
+    key: q
+    context: global
+  queue:
+    action: queue
+    key: q
+    context: global
+`,
+			strictMode: true,
+			wantErr:    "keybindings",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			_, err := NewLoader().Load(context.Background(), LoadOptions{
+				ConfigFilePath:  path,
+				StrictMode:      tt.strictMode,
+				NoProjectConfig: true,
+			})
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Load() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Load() expected strict mode error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), "strict mode") {
+				t.Errorf("Load() error = %v, want mention of %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,13 +1,18 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"maps"
+	"net/url"
 	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/query"
 )
 
 // validator provides validation for Config struct fields.
@@ -27,13 +32,19 @@ func newValidator(schema *ConfigSchema) *validator {
 // validate performs comprehensive validation on a Config struct and applies fallback defaults.
 // Returns a slice of ValidationErrors (both blocking and non-blocking).
 // Mutates cfg to apply fallback defaults for invalid values.
+// Checks that need platform detection are skipped once ctx is done.
 // See: T052-T056, FR-011, FR-012, FR-013
-func (v *validator) validate(cfg *Config) []ValidationError {
+func (v *validator) validate(ctx context.Context, cfg *Config) []ValidationError {
 	var errors []ValidationError
 	defaults := GetDefaultConfig()
 
+	// Validate config schema version (newer majors may contain settings we don't understand)
+	if err := v.validateVersion(cfg); err != nil {
+		errors = append(errors, *err)
+	}
+
 	// Validate theme (T052)
-	if err := v.validateEnum(&cfg.Theme, []string{"default", "dark", "light", "solarized"}, "theme", defaults.Theme); err != nil {
+	if err := validateTheme(cfg, defaults.Theme); err != nil {
 		errors = append(errors, *err)
 	}
 
@@ -48,6 +59,28 @@ func (v *validator) validate(cfg *Config) []ValidationError {
 	v.validateAndFixHexColor(&cfg.ColorScheme.Warning, "colorScheme.warning", defaults.ColorScheme.Warning, &errors)
 	v.validateAndFixHexColor(&cfg.ColorScheme.Success, "colorScheme.success", defaults.ColorScheme.Success, &errors)
 	v.validateAndFixHexColor(&cfg.ColorScheme.Info, "colorScheme.info", defaults.ColorScheme.Info, &errors)
+	v.validatePanelColors(&cfg.ColorScheme, &errors)
+
+	// Validate the package icon protocol
+	if err := v.validateEnum(&cfg.PackageIcons, []string{"auto", "kitty", "iterm2", "sixel", "off"}, "packageIcons", defaults.PackageIcons); err != nil {
+		errors = append(errors, *err)
+	}
+
+	// Validate the confirmation policies
+	for _, policy := range []struct {
+		value        *string
+		field        string
+		defaultValue string
+	}{
+		{&cfg.Confirm.Add, "confirm.add", defaults.Confirm.Add},
+		{&cfg.Confirm.Remove, "confirm.remove", defaults.Confirm.Remove},
+		{&cfg.Confirm.Update, "confirm.update", defaults.Confirm.Update},
+		{&cfg.Confirm.Bulk, "confirm.bulk", defaults.Confirm.Bulk},
+	} {
+		if err := v.validateEnum(policy.value, ConfirmPolicies, policy.field, policy.defaultValue); err != nil {
+			errors = append(errors, *err)
+		}
+	}
 
 	// Validate keybinding profile (T052)
 	if err := v.validateEnum(&cfg.KeybindingProfile, []string{"default", "vim", "emacs"}, "keybindingProfile", defaults.KeybindingProfile); err != nil {
@@ -72,6 +105,19 @@ func (v *validator) validate(cfg *Config) []ValidationError {
 		cfg.MaxConcurrentOps = defaults.MaxConcurrentOps // Apply fallback (T056)
 	}
 
+	// Validate renderFps; above 120 frames a second no terminal shows the difference
+	if cfg.RenderFPS < 1 || cfg.RenderFPS > 120 {
+		errors = append(errors, ValidationError{
+			Key:          "renderFps",
+			Value:        cfg.RenderFPS,
+			Constraint:   "must be between 1 and 120",
+			SuggestedFix: "Set renderFps to a value between 1 and 120 (e.g., 10 over a slow SSH connection)",
+			Severity:     "warning",
+			DefaultUsed:  defaults.RenderFPS,
+		})
+		cfg.RenderFPS = defaults.RenderFPS
+	}
+
 	// Validate cacheSize (T052)
 	if cfg.CacheSize < 0 {
 		errors = append(errors, ValidationError{
@@ -85,19 +131,45 @@ func (v *validator) validate(cfg *Config) []ValidationError {
 		cfg.CacheSize = defaults.CacheSize // Apply fallback (T056)
 	}
 
-	// Validate refreshInterval (T052, T053)
-	if cfg.RefreshInterval < 5*time.Second {
+	// Validate refreshInterval (T052, T053); 0 disables auto-refresh
+	if cfg.RefreshInterval != 0 && cfg.RefreshInterval < 5*time.Second {
 		errors = append(errors, ValidationError{
 			Key:          "refreshInterval",
 			Value:        cfg.RefreshInterval,
-			Constraint:   "must be at least 5 seconds",
-			SuggestedFix: "Set refreshInterval to at least 5s",
+			Constraint:   "must be 0 (disabled) or at least 5 seconds",
+			SuggestedFix: "Set refreshInterval to 0 or at least 5s",
 			Severity:     "warning",
 			DefaultUsed:  defaults.RefreshInterval,
 		})
 		cfg.RefreshInterval = defaults.RefreshInterval // Apply fallback (T056)
 	}
 
+	// Validate advisoryCacheTTL; shorter caches query OSV.dev on nearly every check
+	if cfg.AdvisoryCacheTTL < time.Minute {
+		errors = append(errors, ValidationError{
+			Key:          "advisoryCacheTTL",
+			Value:        cfg.AdvisoryCacheTTL,
+			Constraint:   "must be at least 1 minute",
+			SuggestedFix: "Set advisoryCacheTTL to 1m or longer (e.g., 24h)",
+			Severity:     "warning",
+			DefaultUsed:  defaults.AdvisoryCacheTTL,
+		})
+		cfg.AdvisoryCacheTTL = defaults.AdvisoryCacheTTL
+	}
+
+	// Validate prefetchDelay; shorter delays prefetch every row scrolled past
+	if cfg.PrefetchDelay < 50*time.Millisecond {
+		errors = append(errors, ValidationError{
+			Key:          "prefetchDelay",
+			Value:        cfg.PrefetchDelay,
+			Constraint:   "must be at least 50 milliseconds",
+			SuggestedFix: "Set prefetchDelay to 50ms or longer (e.g., 300ms)",
+			Severity:     "warning",
+			DefaultUsed:  defaults.PrefetchDelay,
+		})
+		cfg.PrefetchDelay = defaults.PrefetchDelay
+	}
+
 	// Validate timeouts (T052, T053)
 	if cfg.Timeouts.NetworkRequest < 1*time.Second {
 		errors = append(errors, ValidationError{
@@ -138,11 +210,36 @@ func (v *validator) validate(cfg *Config) []ValidationError {
 		errors = append(errors, *err)
 	}
 
+	if err := v.validateEnum(&cfg.OperationBackend, []string{"cli", "direct"}, "operationBackend", defaults.OperationBackend); err != nil {
+		errors = append(errors, *err)
+	}
+	if err := v.validateEnum(&cfg.RestoreMode, []string{"always", "batch", "manual"}, "restoreMode", defaults.RestoreMode); err != nil {
+		errors = append(errors, *err)
+	}
+
 	// Validate log level (T052)
 	if err := v.validateEnum(&cfg.LogLevel, []string{"debug", "info", "warn", "error"}, "logLevel", defaults.LogLevel); err != nil {
 		errors = append(errors, *err)
 	}
 
+	// Validate per-module log levels; invalid entries are dropped so the module uses logLevel
+	modules := slices.Sorted(maps.Keys(cfg.LogLevels))
+	for _, module := range modules {
+		level := cfg.LogLevels[module]
+		if slices.Contains([]string{"debug", "info", "warn", "error"}, level) {
+			continue
+		}
+		delete(cfg.LogLevels, module)
+		errors = append(errors, ValidationError{
+			Key:          "logLevels." + module,
+			Value:        level,
+			Constraint:   "must be one of: debug, info, warn, error",
+			SuggestedFix: "Set logLevels." + module + " to one of the allowed values",
+			Severity:     "warning",
+			DefaultUsed:  cfg.LogLevel,
+		})
+	}
+
 	// Validate log format (T052)
 	if err := v.validateEnum(&cfg.LogFormat, []string{"text", "json"}, "logFormat", defaults.LogFormat); err != nil {
 		errors = append(errors, *err)
@@ -154,6 +251,36 @@ func (v *validator) validate(cfg *Config) []ValidationError {
 		cfg.DateFormat = defaults.DateFormat // Apply fallback (T056)
 	}
 
+	// Validate staleAfterYears
+	if cfg.StaleAfterYears < 1 {
+		errors = append(errors, ValidationError{
+			Key:          "staleAfterYears",
+			Value:        cfg.StaleAfterYears,
+			Constraint:   "must be at least 1",
+			SuggestedFix: "Set staleAfterYears to 1 or more",
+			Severity:     "warning",
+			DefaultUsed:  defaults.StaleAfterYears,
+		})
+		cfg.StaleAfterYears = defaults.StaleAfterYears
+	}
+
+	// Validate the status bar
+	if _, err := StatusBarFormatSegments(cfg.StatusBar.Format); err != nil {
+		errors = append(errors, ValidationError{
+			Key:          "statusBar.format",
+			Value:        cfg.StatusBar.Format,
+			Constraint:   err.Error(),
+			SuggestedFix: "Name segments as {mode}, {repo}, {feeds}, {pending}, {vulns}, or {clock}",
+			Severity:     "warning",
+			DefaultUsed:  defaults.StatusBar.Format,
+		})
+		cfg.StatusBar.Format = defaults.StatusBar.Format
+	}
+	if err := v.validateDateFormat(cfg.StatusBar.ClockFormat, "statusBar.clockFormat"); err != nil {
+		errors = append(errors, *err)
+		cfg.StatusBar.ClockFormat = defaults.StatusBar.ClockFormat
+	}
+
 	// Validate log rotation (T052)
 	if cfg.LogRotation.MaxSize < 1 {
 		errors = append(errors, ValidationError{
@@ -189,10 +316,34 @@ func (v *validator) validate(cfg *Config) []ValidationError {
 		cfg.LogRotation.MaxBackups = defaults.LogRotation.MaxBackups // Apply fallback (T056)
 	}
 
+	// Validate team policy entries (pinned packages, feeds)
+	errors = append(errors, v.validatePinnedPackages(cfg)...)
+	errors = append(errors, v.validateFeeds(cfg)...)
+	errors = append(errors, v.validateFeedFallbacks(cfg)...)
+	errors = append(errors, v.validateHooks(cfg)...)
+	errors = append(errors, v.validatePlugins(cfg)...)
+	errors = append(errors, v.validateCustomCommands(cfg)...)
+	errors = append(errors, v.validateSavedFilters(cfg)...)
+
+	// Usage reports may only go to an HTTPS endpoint (plain HTTP to localhost is allowed for testing)
+	if cfg.Telemetry.Endpoint != "" {
+		if err := validateTelemetryEndpoint(cfg.Telemetry.Endpoint); err != nil {
+			errors = append(errors, ValidationError{
+				Key:          "telemetry.endpoint",
+				Value:        cfg.Telemetry.Endpoint,
+				Constraint:   err.Error(),
+				SuggestedFix: "Use an https:// URL, or remove telemetry.endpoint",
+				Severity:     "warning",
+				DefaultUsed:  "no endpoint (reports are not sent)",
+			})
+			cfg.Telemetry.Endpoint = ""
+		}
+	}
+
 	// Validate and normalize paths (T052, T053)
 	if cfg.LogDir != "" {
 		// Get platform-specific path resolver
-		platformInfo, err := platform.New()
+		platformInfo, err := platform.NewContext(ctx)
 		if err == nil {
 			pathResolver, err := platform.NewPathResolver(platformInfo)
 			if err == nil {
@@ -218,6 +369,33 @@ func (v *validator) validate(cfg *Config) []ValidationError {
 	return errors
 }
 
+// validateVersion checks that the config file's schema version is one this build understands.
+// Older 1.x files are always accepted; a newer major version is a warning because unknown
+// settings will be ignored and renamed settings silently fall back to defaults.
+func (v *validator) validateVersion(cfg *Config) *ValidationError {
+	if cfg.Version == "" {
+		return nil
+	}
+
+	majorStr, _, _ := strings.Cut(strings.TrimPrefix(cfg.Version, "v"), ".")
+	major, err := strconv.Atoi(majorStr)
+	if err == nil && major >= 1 && major <= SupportedConfigVersion {
+		return nil
+	}
+
+	originalValue := cfg.Version
+	cfg.Version = GetDefaultConfig().Version
+
+	return &ValidationError{
+		Key:          "version",
+		Value:        originalValue,
+		Constraint:   fmt.Sprintf("must be a config schema version between 1 and %d", SupportedConfigVersion),
+		SuggestedFix: "Upgrade LazyNuGet or set version to \"1.0\"",
+		Severity:     "warning",
+		DefaultUsed:  cfg.Version,
+	}
+}
+
 // validateEnum checks if a value is in the allowed list and applies fallback default if invalid.
 // See: T053, T056, FR-012
 func (v *validator) validateEnum(value *string, allowed []string, field, defaultValue string) *ValidationError {
@@ -261,6 +439,36 @@ func (v *validator) validateAndFixHexColor(value *string, field, defaultValue st
 	})
 }
 
+// validatePanelColors checks the per-panel colors the schema declares. An invalid one is
+// cleared, so the panel falls back to the flat color it overrides.
+func (v *validator) validatePanelColors(cs *ColorScheme, errors *[]ValidationError) {
+	colorFields(cs, func(key string, value *string) {
+		panel, field, isPanel := strings.Cut(key, ".")
+		setting, ok := v.schema.Settings["colorScheme."+key]
+		if !isPanel || !ok || *value == "" {
+			return
+		}
+		for _, c := range setting.Constraints {
+			if c.Type != "hexcolor" || hexColorRegex.MatchString(*value) {
+				continue
+			}
+			fallback := "the terminal's"
+			if flat := PanelColorFallbacks[field]; flat != "" {
+				fallback = "colorScheme." + flat
+			}
+			*errors = append(*errors, ValidationError{
+				Key:          setting.Path,
+				Value:        *value,
+				Constraint:   "must be valid hex color (#RRGGBB or #RRGGBBAA)",
+				SuggestedFix: fmt.Sprintf("Set %s to a valid hex color, or remove it to use %s in the %s panel", setting.Path, fallback, panel),
+				Severity:     "warning",
+				DefaultUsed:  fallback,
+			})
+			*value = ""
+		}
+	})
+}
+
 // validateDateFormat validates a Go time format string.
 // See: T053, FR-012
 func (v *validator) validateDateFormat(format, field string) *ValidationError {
@@ -283,6 +491,487 @@ func (v *validator) validateDateFormat(format, field string) *ValidationError {
 	}
 }
 
+// validatePinnedPackages drops pin rules without a package ID.
+func (v *validator) validatePinnedPackages(cfg *Config) []ValidationError {
+	var errors []ValidationError
+
+	valid := cfg.PinnedPackages[:0:0]
+	for i, pin := range cfg.PinnedPackages {
+		if strings.TrimSpace(pin.Package) == "" {
+			errors = append(errors, ValidationError{
+				Key:          fmt.Sprintf("pinnedPackages[%d].package", i),
+				Value:        pin.Package,
+				Constraint:   "must not be empty",
+				SuggestedFix: "Set package to a package ID such as \"Newtonsoft.Json\"",
+				Severity:     "warning",
+				DefaultUsed:  "pin rule ignored",
+			})
+			continue
+		}
+		valid = append(valid, pin)
+	}
+
+	if cfg.PinnedPackages != nil {
+		cfg.PinnedPackages = valid
+	}
+	return errors
+}
+
+// validateFeeds drops feeds without a name or with an unusable URL, and duplicate feed names,
+// and resets feed timeouts under a second to timeouts.networkRequest.
+// Feed URLs may be http(s) service index URLs or local folder paths.
+func (v *validator) validateFeeds(cfg *Config) []ValidationError {
+	var errors []ValidationError
+
+	seen := make(map[string]bool)
+	valid := cfg.Feeds[:0:0]
+	for i, feed := range cfg.Feeds {
+		key := fmt.Sprintf("feeds[%d]", i)
+
+		switch {
+		case strings.TrimSpace(feed.Name) == "":
+			errors = append(errors, ValidationError{
+				Key:          key + ".name",
+				Value:        feed.Name,
+				Constraint:   "must not be empty",
+				SuggestedFix: "Give the feed a unique name",
+				Severity:     "warning",
+				DefaultUsed:  "feed ignored",
+			})
+			continue
+		case seen[strings.ToLower(feed.Name)]:
+			errors = append(errors, ValidationError{
+				Key:          key + ".name",
+				Value:        feed.Name,
+				Constraint:   "must be unique (feed names are case-insensitive)",
+				SuggestedFix: fmt.Sprintf("Rename or remove the duplicate feed %q", feed.Name),
+				Severity:     "warning",
+				DefaultUsed:  "duplicate feed ignored",
+			})
+			continue
+		}
+
+		if err := validateFeedURL(feed.URL); err != nil {
+			errors = append(errors, ValidationError{
+				Key:          key + ".url",
+				Value:        feed.URL,
+				Constraint:   err.Error(),
+				SuggestedFix: "Use an https:// service index URL (e.g., https://api.nuget.org/v3/index.json) or a local folder path",
+				Severity:     "warning",
+				DefaultUsed:  "feed ignored",
+			})
+			continue
+		}
+
+		if feed.Timeout < 0 || (feed.Timeout > 0 && feed.Timeout < time.Second) {
+			errors = append(errors, ValidationError{
+				Key:          key + ".timeout",
+				Value:        feed.Timeout,
+				Constraint:   "must be at least 1 second",
+				SuggestedFix: "Use a timeout such as 2m, or remove it to use timeouts.networkRequest",
+				Severity:     "warning",
+				DefaultUsed:  cfg.Timeouts.NetworkRequest,
+			})
+			feed.Timeout = 0
+		}
+
+		seen[strings.ToLower(feed.Name)] = true
+		valid = append(valid, feed)
+	}
+
+	if cfg.Feeds != nil {
+		cfg.Feeds = valid
+	}
+	return errors
+}
+
+// validateFeedFallbacks drops fallbacks without a package pattern, and mirrors that are
+// neither a configured feed, "nuget.org", nor an http(s) URL. Runs after validateFeeds.
+func (v *validator) validateFeedFallbacks(cfg *Config) []ValidationError {
+	var errors []ValidationError
+
+	names := make(map[string]bool)
+	for _, feed := range cfg.Feeds {
+		names[strings.ToLower(feed.Name)] = true
+	}
+	valid := cfg.FeedFallbacks[:0:0]
+	for i, fallback := range cfg.FeedFallbacks {
+		key := fmt.Sprintf("feedFallbacks[%d]", i)
+		if strings.TrimSpace(fallback.Packages) == "" {
+			errors = append(errors, ValidationError{
+				Key:          key + ".packages",
+				Value:        fallback.Packages,
+				Constraint:   "must not be empty",
+				SuggestedFix: "Set packages to a package ID or pattern such as \"Contoso.*\", or \"*\" for every package",
+				Severity:     "warning",
+				DefaultUsed:  "fallback ignored",
+			})
+			continue
+		}
+
+		feeds := fallback.Feeds[:0:0]
+		for j, name := range fallback.Feeds {
+			known := names[strings.ToLower(name)] || strings.EqualFold(name, "nuget.org")
+			if !known && (!strings.Contains(name, "://") || validateFeedURL(name) != nil) {
+				errors = append(errors, ValidationError{
+					Key:          fmt.Sprintf("%s.feeds[%d]", key, j),
+					Value:        name,
+					Constraint:   "must be the name of a feed, nuget.org, or an http(s) URL",
+					SuggestedFix: "Add the mirror to feeds, or give its service index URL",
+					Severity:     "warning",
+					DefaultUsed:  "mirror ignored",
+				})
+				continue
+			}
+			feeds = append(feeds, name)
+		}
+		if len(feeds) > 0 {
+			fallback.Feeds = feeds
+			valid = append(valid, fallback)
+		}
+	}
+
+	if cfg.FeedFallbacks != nil {
+		cfg.FeedFallbacks = valid
+	}
+	return errors
+}
+
+// validateHooks drops hooks without a command or with a negative timeout.
+func (v *validator) validateHooks(cfg *Config) []ValidationError {
+	var errors []ValidationError
+
+	validate := func(name string, hooks []Hook) []Hook {
+		valid := hooks[:0:0]
+		for i, hook := range hooks {
+			key := fmt.Sprintf("hooks.%s[%d]", name, i)
+			switch {
+			case strings.TrimSpace(hook.Command) == "":
+				errors = append(errors, ValidationError{
+					Key:          key + ".command",
+					Value:        hook.Command,
+					Constraint:   "must not be empty",
+					SuggestedFix: "Set command to a shell command such as \"dotnet format\"",
+					Severity:     "warning",
+					DefaultUsed:  "hook ignored",
+				})
+			case hook.Timeout < 0:
+				errors = append(errors, ValidationError{
+					Key:          key + ".timeout",
+					Value:        hook.Timeout,
+					Constraint:   "must not be negative",
+					SuggestedFix: "Use a duration such as 30s, or omit timeout for the default",
+					Severity:     "warning",
+					DefaultUsed:  "hook ignored",
+				})
+			default:
+				valid = append(valid, hook)
+			}
+		}
+		if hooks == nil {
+			return nil
+		}
+		return valid
+	}
+
+	cfg.Hooks.PreInstall = validate("preInstall", cfg.Hooks.PreInstall)
+	cfg.Hooks.PostUpdate = validate("postUpdate", cfg.Hooks.PostUpdate)
+	cfg.Hooks.PostRestore = validate("postRestore", cfg.Hooks.PostRestore)
+	return errors
+}
+
+// validatePlugins drops plugins without a unique name or a command, or with a negative timeout.
+func (v *validator) validatePlugins(cfg *Config) []ValidationError {
+	var errors []ValidationError
+
+	seen := make(map[string]bool)
+	valid := cfg.Plugins[:0:0]
+	for i, plugin := range cfg.Plugins {
+		key := fmt.Sprintf("plugins[%d]", i)
+
+		switch {
+		case strings.TrimSpace(plugin.Name) == "":
+			errors = append(errors, ValidationError{
+				Key:          key + ".name",
+				Value:        plugin.Name,
+				Constraint:   "must not be empty",
+				SuggestedFix: "Give the plugin a unique name",
+				Severity:     "warning",
+				DefaultUsed:  "plugin ignored",
+			})
+		case seen[strings.ToLower(plugin.Name)]:
+			errors = append(errors, ValidationError{
+				Key:          key + ".name",
+				Value:        plugin.Name,
+				Constraint:   "must be unique (plugin names are case-insensitive)",
+				SuggestedFix: fmt.Sprintf("Rename or remove the duplicate plugin %q", plugin.Name),
+				Severity:     "warning",
+				DefaultUsed:  "duplicate plugin ignored",
+			})
+		case strings.TrimSpace(plugin.Command) == "":
+			errors = append(errors, ValidationError{
+				Key:          key + ".command",
+				Value:        plugin.Command,
+				Constraint:   "must not be empty",
+				SuggestedFix: "Set command to the plugin executable",
+				Severity:     "warning",
+				DefaultUsed:  "plugin ignored",
+			})
+		case plugin.Timeout < 0:
+			errors = append(errors, ValidationError{
+				Key:          key + ".timeout",
+				Value:        plugin.Timeout,
+				Constraint:   "must not be negative",
+				SuggestedFix: "Use a duration such as 30s, or omit timeout for the default",
+				Severity:     "warning",
+				DefaultUsed:  "plugin ignored",
+			})
+		default:
+			seen[strings.ToLower(plugin.Name)] = true
+			valid = append(valid, plugin)
+		}
+	}
+	if cfg.Plugins != nil {
+		cfg.Plugins = valid
+	}
+	return errors
+}
+
+// validateCustomCommands drops custom commands without a unique name or a command, with a
+// negative timeout, or with placeholders that are neither the selection nor a prompt. An
+// unknown context or output falls back to its default, and a key already bound in the
+// same context is unbound, leaving the command in the palette.
+func (v *validator) validateCustomCommands(cfg *Config) []ValidationError {
+	var errors []ValidationError
+	ignored := func(key string, value any, constraint, fix string) {
+		errors = append(errors, ValidationError{
+			Key:          key,
+			Value:        value,
+			Constraint:   constraint,
+			SuggestedFix: fix,
+			Severity:     "warning",
+			DefaultUsed:  "custom command ignored",
+		})
+	}
+
+	// Keys taken by keybindings, by context
+	bound := make(map[string]map[string]string) // context -> key -> what it runs
+	bind := func(context, key, what string) {
+		if bound[context] == nil {
+			bound[context] = make(map[string]string)
+		}
+		bound[context][key] = what
+	}
+	for action, binding := range cfg.Keybindings {
+		context := binding.Context
+		if context == "" {
+			context = "global"
+		}
+		if binding.Key != "" {
+			bind(context, binding.Key, "action '"+action+"'")
+		}
+	}
+
+	seen := make(map[string]bool)
+	valid := cfg.CustomCommands[:0:0]
+	for i, command := range cfg.CustomCommands {
+		key := fmt.Sprintf("customCommands[%d]", i)
+		if field, constraint, fix := customCommandProblem(command); constraint != "" {
+			ignored(key+field, command.Name, constraint, fix)
+			continue
+		}
+		if seen[strings.ToLower(command.Name)] {
+			ignored(key+".name", command.Name, "must be unique (custom command names are case-insensitive)",
+				fmt.Sprintf("Rename or remove the duplicate custom command %q", command.Name))
+			continue
+		}
+		seen[strings.ToLower(command.Name)] = true
+
+		if command.Context != "" && !slices.Contains(CustomCommandContexts, command.Context) {
+			errors = append(errors, ValidationError{
+				Key:          key + ".context",
+				Value:        command.Context,
+				Constraint:   "must be one of: " + strings.Join(CustomCommandContexts, ", "),
+				SuggestedFix: "Use a panel name, or omit context for a key that works everywhere",
+				Severity:     "warning",
+				DefaultUsed:  "global",
+			})
+			command.Context = ""
+		}
+		if command.Output != "" && !slices.Contains(CustomCommandOutputs, command.Output) {
+			errors = append(errors, ValidationError{
+				Key:          key + ".output",
+				Value:        command.Output,
+				Constraint:   "must be one of: " + strings.Join(CustomCommandOutputs, ", "),
+				SuggestedFix: "Use terminal for interactive commands, none for silent ones",
+				Severity:     "warning",
+				DefaultUsed:  "pager",
+			})
+			command.Output = ""
+		}
+
+		if command.Key != "" {
+			context := command.Context
+			if context == "" {
+				context = "global"
+			}
+			// A global key conflicts with the same key in any panel, and a panel key with a global one
+			what, taken := bound[context][command.Key]
+			if !taken && context != "global" {
+				what, taken = bound["global"][command.Key]
+			}
+			if !taken && context == "global" {
+				for _, keys := range bound {
+					if what, taken = keys[command.Key]; taken {
+						break
+					}
+				}
+			}
+			if taken {
+				errors = append(errors, ValidationError{
+					Key:          key + ".key",
+					Value:        fmt.Sprintf("key '%s' in context '%s'", command.Key, context),
+					Constraint:   "key already assigned to " + what,
+					SuggestedFix: fmt.Sprintf("Assign a different key to %q, or run it from the command palette", command.Name),
+					Severity:     "warning",
+					DefaultUsed:  "key unbound",
+				})
+				command.Key = ""
+			} else {
+				bind(context, command.Key, fmt.Sprintf("custom command %q", command.Name))
+			}
+		}
+		valid = append(valid, command)
+	}
+	if cfg.CustomCommands != nil {
+		cfg.CustomCommands = valid
+	}
+	return errors
+}
+
+// validateSavedFilters drops saved filters without a unique name or with a query that does
+// not parse.
+func (v *validator) validateSavedFilters(cfg *Config) []ValidationError {
+	var errors []ValidationError
+	ignored := func(key string, value any, constraint, fix string) {
+		errors = append(errors, ValidationError{
+			Key:          key,
+			Value:        value,
+			Constraint:   constraint,
+			SuggestedFix: fix,
+			Severity:     "warning",
+			DefaultUsed:  "saved filter ignored",
+		})
+	}
+
+	seen := make(map[string]bool)
+	valid := cfg.SavedFilters[:0:0]
+	for i, filter := range cfg.SavedFilters {
+		key := fmt.Sprintf("savedFilters[%d]", i)
+		name := strings.ToLower(strings.TrimSpace(filter.Name))
+		switch _, err := query.Parse(filter.Query); {
+		case name == "":
+			ignored(key+".name", filter.Name, "must not be empty", "Give the saved filter a unique name")
+		case seen[name]:
+			ignored(key+".name", filter.Name, "must be unique (saved filter names are case-insensitive)",
+				fmt.Sprintf("Rename or remove the duplicate saved filter %q", filter.Name))
+		case strings.TrimSpace(filter.Query) == "":
+			ignored(key+".query", filter.Query, "must not be empty", "Set query to a filter such as \"is:vulnerable update:major\"")
+		case err != nil:
+			ignored(key+".query", filter.Query, err.Error(), "Use words, id:, project:, is:, update:, and severity: terms")
+		default:
+			seen[name] = true
+			valid = append(valid, filter)
+		}
+	}
+	if cfg.SavedFilters != nil {
+		cfg.SavedFilters = valid
+	}
+	return errors
+}
+
+// customCommandProblem returns the field of a custom command that makes it unusable, the
+// constraint it breaks, and a fix; the constraint is empty when the command is usable.
+func customCommandProblem(command CustomCommand) (field, constraint, fix string) {
+	if strings.TrimSpace(command.Name) == "" {
+		return ".name", "must not be empty", "Give the custom command a unique name"
+	}
+	if strings.TrimSpace(command.Command) == "" {
+		return ".command", "must not be empty", "Set command to a shell command such as \"dotnet build {{project}}\""
+	}
+	if command.Timeout < 0 {
+		return ".timeout", "must not be negative", "Use a duration such as 30s, or omit timeout for the default"
+	}
+	prompts := make([]string, 0, len(command.Prompts))
+	for j, prompt := range command.Prompts {
+		switch {
+		case !slices.Equal(Placeholders("{{"+prompt.Name+"}}"), []string{prompt.Name}):
+			return fmt.Sprintf(".prompts[%d].name", j), "must be a letter followed by letters, digits, - or _", "Name the prompt after the placeholder it fills"
+		case slices.Contains(CustomCommandPlaceholders, prompt.Name) || slices.Contains(prompts, prompt.Name):
+			return fmt.Sprintf(".prompts[%d].name", j), "must not repeat a placeholder", "Rename the prompt; package, project, and version are filled from the selection"
+		}
+		for _, name := range Placeholders(prompt.Default) {
+			if !slices.Contains(CustomCommandPlaceholders, name) {
+				return fmt.Sprintf(".prompts[%d].default", j), "may only use {{package}}, {{project}}, and {{version}}", "Remove {{" + name + "}} from the default"
+			}
+		}
+		prompts = append(prompts, prompt.Name)
+	}
+	for _, name := range Placeholders(command.Command) {
+		if !slices.Contains(CustomCommandPlaceholders, name) && !slices.Contains(prompts, name) {
+			return ".command", "has no value for {{" + name + "}}", "Add a prompt named " + name + ", or use {{package}}, {{project}}, or {{version}}"
+		}
+	}
+	return "", "", ""
+}
+
+// validateFeedURL checks that a feed source is an http(s) URL with a host or a local path.
+func validateFeedURL(source string) error {
+	if strings.TrimSpace(source) == "" {
+		return fmt.Errorf("must not be empty")
+	}
+
+	if !strings.Contains(source, "://") {
+		// Local folder feed
+		return nil
+	}
+
+	u, err := url.Parse(source)
+	if err != nil {
+		return fmt.Errorf("must be a valid URL: %w", err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return fmt.Errorf("URL scheme must be http or https, got %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("URL must include a host")
+	}
+	return nil
+}
+
+// validateTelemetryEndpoint checks that the telemetry endpoint is an https URL with a host.
+// Plain http is accepted only for loopback hosts.
+func validateTelemetryEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("must be a valid URL: %w", err)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("URL must include a host")
+	}
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if host := u.Hostname(); host == "localhost" || host == "127.0.0.1" || host == "::1" {
+			return nil
+		}
+		return fmt.Errorf("URL must use https (http is only allowed for localhost)")
+	default:
+		return fmt.Errorf("URL scheme must be https, got %q", u.Scheme)
+	}
+}
+
 // validateKeybindingConflicts detects duplicate key assignments in keybindings.
 // See: T057, FR-028
 func (v *validator) validateKeybindingConflicts(cfg *Config) []ValidationError {
@@ -294,6 +983,9 @@ func (v *validator) validateKeybindingConflicts(cfg *Config) []ValidationError {
 	for action, binding := range cfg.Keybindings {
 		context := binding.Context
 		key := binding.Key
+		if key == "" {
+			continue // An empty key unbinds the action
+		}
 
 		// Initialize context map if needed
 		if keysByContext[context] == nil {
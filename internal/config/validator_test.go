@@ -1,6 +1,9 @@
 package config
 
 import (
+	"context"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -30,7 +33,24 @@ func TestValidatorValidateConfig(t *testing.T) {
 			name:          "valid default config",
 			cfg:           GetDefaultConfig(),
 			wantErrCount:  0,
-			wantWarnCount: 1, // RefreshInterval=0 fails validation (should be >= 5s)
+			wantWarnCount: 0, // RefreshInterval=0 means disabled
+		},
+		{
+			name: "newer config major version",
+			cfg: copyWithOverride(func(c *Config) {
+				c.Version = "2.0"
+			}),
+			wantErrCount:  0,
+			wantWarnCount: 1, // version
+			checkErrors:   []string{"version"},
+		},
+		{
+			name: "older minor config version accepted",
+			cfg: copyWithOverride(func(c *Config) {
+				c.Version = "1.3"
+			}),
+			wantErrCount:  0,
+			wantWarnCount: 0,
 		},
 		{
 			name: "invalid maxConcurrentOps too low",
@@ -38,7 +58,7 @@ func TestValidatorValidateConfig(t *testing.T) {
 				c.MaxConcurrentOps = 0
 			}),
 			wantErrCount:  0, // Falls back to default
-			wantWarnCount: 2, // maxConcurrentOps + refreshInterval
+			wantWarnCount: 1, // maxConcurrentOps
 			checkErrors:   []string{"maxConcurrentOps"},
 		},
 		{
@@ -47,7 +67,7 @@ func TestValidatorValidateConfig(t *testing.T) {
 				c.MaxConcurrentOps = 999
 			}),
 			wantErrCount:  0,
-			wantWarnCount: 2, // maxConcurrentOps + refreshInterval
+			wantWarnCount: 1, // maxConcurrentOps
 			checkErrors:   []string{"maxConcurrentOps"},
 		},
 		{
@@ -56,16 +76,25 @@ func TestValidatorValidateConfig(t *testing.T) {
 				c.CacheSize = -1
 			}),
 			wantErrCount:  0,
-			wantWarnCount: 2, // cacheSize + refreshInterval
+			wantWarnCount: 1, // cacheSize
 			checkErrors:   []string{"cacheSize"},
 		},
+		{
+			name: "invalid staleAfterYears too low",
+			cfg: copyWithOverride(func(c *Config) {
+				c.StaleAfterYears = 0
+			}),
+			wantErrCount:  0,
+			wantWarnCount: 1, // staleAfterYears
+			checkErrors:   []string{"staleAfterYears"},
+		},
 		{
 			name: "invalid log level",
 			cfg: copyWithOverride(func(c *Config) {
 				c.LogLevel = "invalid"
 			}),
 			wantErrCount:  0,
-			wantWarnCount: 2, // logLevel + refreshInterval
+			wantWarnCount: 1, // logLevel
 			checkErrors:   []string{"logLevel"},
 		},
 		{
@@ -74,7 +103,7 @@ func TestValidatorValidateConfig(t *testing.T) {
 				c.Theme = "nonexistent"
 			}),
 			wantErrCount:  0,
-			wantWarnCount: 2, // theme + refreshInterval
+			wantWarnCount: 1, // theme
 			checkErrors:   []string{"theme"},
 		},
 		{
@@ -84,7 +113,7 @@ func TestValidatorValidateConfig(t *testing.T) {
 				c.ColorScheme.Error = "GGGGGG"
 			}),
 			wantErrCount:  0,
-			wantWarnCount: 3, // 2 colors + refreshInterval
+			wantWarnCount: 2, // 2 colors
 			checkErrors:   []string{"colorScheme.border", "colorScheme.error"},
 		},
 		{
@@ -95,9 +124,52 @@ func TestValidatorValidateConfig(t *testing.T) {
 				c.Timeouts.FileOperation = 50 * time.Millisecond
 			}),
 			wantErrCount:  0,
-			wantWarnCount: 4, // 3 timeouts + refreshInterval
+			wantWarnCount: 3, // 3 timeouts
 			checkErrors:   []string{"timeouts.networkRequest", "timeouts.dotnetCLI", "timeouts.fileOperation"},
 		},
+		{
+			name: "refreshInterval too short",
+			cfg: copyWithOverride(func(c *Config) {
+				c.RefreshInterval = 2 * time.Second
+			}),
+			wantErrCount:  0,
+			wantWarnCount: 1,
+			checkErrors:   []string{"refreshInterval"},
+		},
+		{
+			name: "valid module log levels",
+			cfg: copyWithOverride(func(c *Config) {
+				c.LogLevels = map[string]string{"nuget": "debug", "gui": "warn"}
+			}),
+			wantErrCount:  0,
+			wantWarnCount: 0,
+		},
+		{
+			name: "invalid module log level",
+			cfg: copyWithOverride(func(c *Config) {
+				c.LogLevels = map[string]string{"nuget": "verbose", "gui": "warn"}
+			}),
+			wantErrCount:  0,
+			wantWarnCount: 1,
+			checkErrors:   []string{"logLevels.nuget"},
+		},
+		{
+			name: "https telemetry endpoint",
+			cfg: copyWithOverride(func(c *Config) {
+				c.Telemetry.Endpoint = "https://telemetry.example.com/v1/usage"
+			}),
+			wantErrCount:  0,
+			wantWarnCount: 0,
+		},
+		{
+			name: "plain http telemetry endpoint",
+			cfg: copyWithOverride(func(c *Config) {
+				c.Telemetry.Endpoint = "http://telemetry.example.com/v1/usage"
+			}),
+			wantErrCount:  0,
+			wantWarnCount: 1,
+			checkErrors:   []string{"telemetry.endpoint"},
+		},
 		{
 			name: "multiple validation errors",
 			cfg: copyWithOverride(func(c *Config) {
@@ -106,13 +178,13 @@ func TestValidatorValidateConfig(t *testing.T) {
 				c.CacheSize = -1
 			}),
 			wantErrCount:  0,
-			wantWarnCount: 4, // 3 invalid fields + refreshInterval
+			wantWarnCount: 3, // 3 invalid fields
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			errors := v.validate(tt.cfg)
+			errors := v.validate(context.Background(), tt.cfg)
 
 			// Count errors vs warnings
 			errCount := 0
@@ -195,6 +267,30 @@ func TestValidatorFallbackDefaults(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "unknown status bar segment falls back",
+			cfg: &Config{
+				StatusBar: StatusBarConfig{Format: "{repo} {branch}", ClockFormat: "15:04"},
+			},
+			checkFunc: func(cfg *Config) error {
+				if cfg.StatusBar.Format != defaults.StatusBar.Format {
+					t.Errorf("Expected fallback to %s, got %s", defaults.StatusBar.Format, cfg.StatusBar.Format)
+				}
+				return nil
+			},
+		},
+		{
+			name: "invalid confirm policy falls back",
+			cfg: &Config{
+				Confirm: ConfirmConfig{Add: "never", Remove: "sometimes", Update: "never", Bulk: "always"},
+			},
+			checkFunc: func(cfg *Config) error {
+				if cfg.Confirm.Remove != defaults.Confirm.Remove {
+					t.Errorf("Expected fallback to %s, got %s", defaults.Confirm.Remove, cfg.Confirm.Remove)
+				}
+				return nil
+			},
+		},
 		{
 			name: "invalid color falls back",
 			cfg: &Config{
@@ -213,10 +309,227 @@ func TestValidatorFallbackDefaults(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(_ *testing.T) {
-			_ = v.validate(tt.cfg)
+			_ = v.validate(context.Background(), tt.cfg)
 			if tt.checkFunc != nil {
 				_ = tt.checkFunc(tt.cfg)
 			}
 		})
 	}
 }
+
+// TestValidatorTeamPolicy tests validation of pinned packages and feeds
+func TestValidatorTeamPolicy(t *testing.T) {
+	v := newValidator(GetConfigSchema())
+
+	cfg := GetDefaultConfig()
+	cfg.RefreshInterval = 5 * time.Second
+	cfg.PinnedPackages = []PinRule{
+		{Package: "Serilog", Version: "3.1.1"},
+		{Package: "", Version: "1.0.0"},
+	}
+	cfg.Feeds = []Feed{
+		{Name: "nuget.org", URL: "https://api.nuget.org/v3/index.json"},
+		{Name: "local", URL: "/srv/packages"},
+		{Name: "NuGet.org", URL: "https://duplicate.example.com/v3/index.json"},
+		{Name: "ftp", URL: "ftp://example.com/feed"},
+		{Name: "", URL: "https://example.com/v3/index.json"},
+		{Name: "slow", URL: "https://slow.example.com/v3/index.json", Timeout: 500 * time.Millisecond},
+	}
+
+	errs := v.validate(context.Background(), cfg)
+
+	keys := make(map[string]bool)
+	for _, e := range errs {
+		keys[e.Key] = true
+	}
+	for _, want := range []string{"pinnedPackages[1].package", "feeds[2].name", "feeds[3].url", "feeds[4].name", "feeds[5].timeout"} {
+		if !keys[want] {
+			t.Errorf("expected validation warning for %s, got %v", want, errs)
+		}
+	}
+
+	if len(cfg.PinnedPackages) != 1 || cfg.PinnedPackages[0].Package != "Serilog" {
+		t.Errorf("invalid pin rules not dropped: %+v", cfg.PinnedPackages)
+	}
+	if len(cfg.Feeds) != 3 || cfg.Feeds[0].Name != "nuget.org" || cfg.Feeds[1].Name != "local" {
+		t.Errorf("invalid feeds not dropped: %+v", cfg.Feeds)
+	} else if cfg.Feeds[2].Timeout != 0 {
+		t.Errorf("feed timeout under a second = %s, want 0 (timeouts.networkRequest)", cfg.Feeds[2].Timeout)
+	}
+}
+
+// TestValidatorFeedFallbacks tests dropping fallbacks without a package pattern and
+// mirrors that are not feeds or URLs
+func TestValidatorFeedFallbacks(t *testing.T) {
+	v := newValidator(GetConfigSchema())
+
+	cfg := GetDefaultConfig()
+	cfg.Feeds = []Feed{
+		{Name: "mirror", URL: "https://mirror.example.com/v3/index.json"},
+		{Name: "ftp", URL: "ftp://example.com/feed"},
+	}
+	cfg.FeedFallbacks = []FeedFallback{
+		{Packages: "Contoso.*", Feeds: []string{"Mirror", "ftp", "nuget.org"}},
+		{Packages: " ", Feeds: []string{"mirror"}},
+		{Packages: "*", Feeds: []string{"https://backup.example.com/v3/index.json", "missing"}},
+		{Packages: "Other", Feeds: []string{"missing"}},
+	}
+
+	errs := v.validate(context.Background(), cfg)
+
+	keys := make(map[string]bool)
+	for _, e := range errs {
+		keys[e.Key] = true
+	}
+	for _, want := range []string{"feedFallbacks[0].feeds[1]", "feedFallbacks[1].packages", "feedFallbacks[2].feeds[1]", "feedFallbacks[3].feeds[0]"} {
+		if !keys[want] {
+			t.Errorf("expected validation warning for %s, got %v", want, errs)
+		}
+	}
+	want := []FeedFallback{
+		{Packages: "Contoso.*", Feeds: []string{"Mirror", "nuget.org"}},
+		{Packages: "*", Feeds: []string{"https://backup.example.com/v3/index.json"}},
+	}
+	if !reflect.DeepEqual(cfg.FeedFallbacks, want) {
+		t.Errorf("FeedFallbacks = %+v, want %+v", cfg.FeedFallbacks, want)
+	}
+}
+
+// TestValidatorHooks tests dropping hooks without a command or with a negative timeout
+func TestValidatorHooks(t *testing.T) {
+	v := newValidator(GetConfigSchema())
+
+	cfg := GetDefaultConfig()
+	cfg.Hooks.PostRestore = []Hook{
+		{Command: "dotnet format --verify-no-changes"},
+		{Command: "  "},
+		{Command: "./notify.sh", Timeout: -time.Second},
+	}
+
+	errs := v.validate(context.Background(), cfg)
+
+	keys := make(map[string]bool)
+	for _, e := range errs {
+		keys[e.Key] = true
+	}
+	for _, want := range []string{"hooks.postRestore[1].command", "hooks.postRestore[2].timeout"} {
+		if !keys[want] {
+			t.Errorf("expected validation warning for %s, got %v", want, errs)
+		}
+	}
+	if len(cfg.Hooks.PostRestore) != 1 || cfg.Hooks.PostRestore[0].Command != "dotnet format --verify-no-changes" {
+		t.Errorf("invalid hooks not dropped: %+v", cfg.Hooks.PostRestore)
+	}
+	if cfg.Hooks.PreInstall != nil {
+		t.Errorf("PreInstall = %+v, want nil", cfg.Hooks.PreInstall)
+	}
+}
+
+// TestValidatorPlugins tests dropping plugins without a unique name or a command
+func TestValidatorPlugins(t *testing.T) {
+	v := newValidator(GetConfigSchema())
+
+	cfg := GetDefaultConfig()
+	cfg.Plugins = []Plugin{
+		{Name: "registry", Command: "lazynuget-registry"},
+		{Name: "Registry", Command: "other"},
+		{Name: "", Command: "unnamed"},
+		{Name: "empty", Command: " "},
+		{Name: "slow", Command: "slow", Timeout: -time.Second},
+	}
+
+	errs := v.validate(context.Background(), cfg)
+
+	keys := make(map[string]bool)
+	for _, e := range errs {
+		keys[e.Key] = true
+	}
+	for _, want := range []string{"plugins[1].name", "plugins[2].name", "plugins[3].command", "plugins[4].timeout"} {
+		if !keys[want] {
+			t.Errorf("expected validation warning for %s, got %v", want, errs)
+		}
+	}
+	if len(cfg.Plugins) != 1 || cfg.Plugins[0].Command != "lazynuget-registry" {
+		t.Errorf("invalid plugins not dropped: %+v", cfg.Plugins)
+	}
+}
+
+// TestValidatorCustomCommands tests dropping unusable custom commands and unbinding keys
+// that are already taken
+// TestValidatorSavedFilters tests that saved filters without a unique name or a valid
+// query are dropped
+func TestValidatorSavedFilters(t *testing.T) {
+	v := newValidator(GetConfigSchema())
+
+	cfg := GetDefaultConfig()
+	cfg.SavedFilters = []SavedFilter{
+		{Name: "vulnerable majors", Query: "is:vulnerable update:major"},
+		{Name: "Vulnerable Majors", Query: "is:outdated"},
+		{Name: "", Query: "serilog"},
+		{Name: "empty", Query: " "},
+		{Name: "typo", Query: "is:vulnerabel"},
+		{Name: "serilog", Query: "id:serilog*"},
+	}
+
+	errs := v.validate(context.Background(), cfg)
+
+	keys := make(map[string]bool)
+	for _, e := range errs {
+		keys[e.Key] = true
+	}
+	for _, want := range []string{"savedFilters[1].name", "savedFilters[2].name", "savedFilters[3].query", "savedFilters[4].query"} {
+		if !keys[want] {
+			t.Errorf("expected validation warning for %s, got %v", want, errs)
+		}
+	}
+	if len(cfg.SavedFilters) != 2 || cfg.SavedFilters[0].Name != "vulnerable majors" || cfg.SavedFilters[1].Name != "serilog" {
+		t.Errorf("saved filters = %+v, want the unusable ones dropped", cfg.SavedFilters)
+	}
+}
+
+func TestValidatorCustomCommands(t *testing.T) {
+	v := newValidator(GetConfigSchema())
+
+	cfg := GetDefaultConfig()
+	cfg.Keybindings = map[string]KeyBinding{"refresh": {Action: "refresh", Key: "r", Context: "global"}}
+	cfg.CustomCommands = []CustomCommand{
+		{Name: "why", Key: "w", Context: "packages", Command: "dotnet nuget why {{project}} {{package}}"},
+		{Name: "WHY", Command: "echo duplicate"},
+		{Name: "reload", Key: "r", Context: "projects", Command: "dotnet restore {{project}}"},
+		{Name: "also-w", Key: "w", Command: "echo"},
+		{Name: "bump", Command: "git commit -m {{message}}", Prompts: []CustomPrompt{{Name: "message", Default: "Update {{package}}"}}},
+		{Name: "unknown", Command: "echo {{branch}}"},
+		{Name: "shadow", Command: "echo {{package}}", Prompts: []CustomPrompt{{Name: "package"}}},
+		{Name: "style", Command: "echo", Context: "sidebar", Output: "popup"},
+	}
+
+	errs := v.validate(context.Background(), cfg)
+
+	keys := make(map[string]bool)
+	for _, e := range errs {
+		keys[e.Key] = true
+	}
+	for _, want := range []string{
+		"customCommands[1].name", "customCommands[2].key", "customCommands[3].key",
+		"customCommands[5].command", "customCommands[6].prompts[0].name",
+		"customCommands[7].context", "customCommands[7].output",
+	} {
+		if !keys[want] {
+			t.Errorf("expected validation warning for %s, got %v", want, errs)
+		}
+	}
+
+	var names []string
+	for _, c := range cfg.CustomCommands {
+		names = append(names, c.Name)
+	}
+	if strings.Join(names, " ") != "why reload also-w bump style" {
+		t.Fatalf("custom commands = %v, want the unusable ones dropped", names)
+	}
+	if cfg.CustomCommands[0].Key != "w" || cfg.CustomCommands[1].Key != "" || cfg.CustomCommands[2].Key != "" {
+		t.Errorf("keys = %q %q %q, want only the first w bound", cfg.CustomCommands[0].Key, cfg.CustomCommands[1].Key, cfg.CustomCommands[2].Key)
+	}
+	if style := cfg.CustomCommands[4]; style.Context != "" || style.Output != "" {
+		t.Errorf("style = %+v, want the default context and output", style)
+	}
+}
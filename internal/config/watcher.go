@@ -3,6 +3,7 @@ package config
 import (
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
 	"sync"
 	"time"
@@ -51,11 +52,19 @@ type WatchOptions struct {
 	OnError        func(error)
 	OnFileDeleted  func()
 	ConfigFilePath string
+	RelatedFiles   []string // Fragments and the theme file (Config.RelatedFiles); followed across reloads
 	LoadOptions    LoadOptions
 	DebounceDelay  time.Duration
 }
 
 // configWatcher implements ConfigWatcher using fsnotify.
+//
+// It watches the directories of the config file and its related files (included
+// fragments, the theme file) and, when a path is a symbolic link, of its target, rather
+// than the files themselves. Editors such as vim and VS Code save by writing a new file
+// and renaming it over the old one (or renaming the old one away first), which replaces
+// the file and silently drops a watch on it; a directory watch survives and sees the new
+// file arrive.
 type configWatcher struct {
 	loader         ConfigLoader
 	watchCtx       context.Context
@@ -64,9 +73,14 @@ type configWatcher struct {
 	watchCtxCancel context.CancelFunc
 	stopCh         chan struct{}
 	stoppedCh      chan struct{}
+	dirs           map[string]bool   // Directories watched; guarded by pathsMu
+	targets        map[string]string // Watched files with symbolic links resolved; guarded by pathsMu
+	related        []string          // Related files of the last config loaded; guarded by pathsMu
 	opts           WatchOptions
 	callbacksWg    sync.WaitGroup
 	mu             sync.Mutex
+	pathsMu        sync.Mutex // Never held while acquiring mu
+	exists         bool       // Whether the config file existed at the last event; guarded by mu
 }
 
 // NewConfigWatcher creates a new config file watcher.
@@ -94,15 +108,8 @@ func NewConfigWatcher(opts WatchOptions, loader ConfigLoader) (ConfigWatcher, er
 		return nil, fmt.Errorf("failed to create file watcher: %w", err)
 	}
 
-	// Add file to watch
-	if err := fsWatcher.Add(absPath); err != nil {
-		// fsWatcher will be garbage collected (calling Close here complicates error handling)
-		return nil, fmt.Errorf("failed to watch config file: %w", err)
-	}
-
 	watchCtx, watchCtxCancel := context.WithCancel(context.Background())
-
-	return &configWatcher{
+	cw := &configWatcher{
 		opts:           opts,
 		loader:         loader,
 		watcher:        fsWatcher,
@@ -110,7 +117,107 @@ func NewConfigWatcher(opts WatchOptions, loader ConfigLoader) (ConfigWatcher, er
 		stoppedCh:      make(chan struct{}),
 		watchCtx:       watchCtx,
 		watchCtxCancel: watchCtxCancel,
-	}, nil
+		dirs:           make(map[string]bool),
+		targets:        make(map[string]string),
+		related:        opts.RelatedFiles,
+		exists:         true,
+	}
+
+	// The file must exist to be watched, though only its directories are
+	if _, err := os.Stat(absPath); err != nil {
+		watchCtxCancel()
+		_ = fsWatcher.Close()
+		return nil, fmt.Errorf("failed to watch config file: %w", err)
+	}
+	cw.pathsMu.Lock()
+	_, err = cw.rewatch()
+	cw.pathsMu.Unlock()
+	if err != nil {
+		watchCtxCancel()
+		_ = fsWatcher.Close()
+		return nil, fmt.Errorf("failed to watch config file: %w", err)
+	}
+	return cw, nil
+}
+
+// rewatch resolves symbolic links in the watched paths again and watches the directories
+// of the paths and their targets, dropping directories no longer involved. It reports
+// whether a target changed, as when a link is pointed at another file. While a path
+// cannot be resolved (mid-save, or deleted) its previous target is kept. The caller holds
+// pathsMu.
+func (cw *configWatcher) rewatch() (bool, error) {
+	paths := append([]string{cw.opts.ConfigFilePath}, cw.related...)
+	want := make(map[string]bool)
+	targets := make(map[string]string, len(paths))
+	changed := false
+	for _, path := range paths {
+		target, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			target = cw.targets[path]
+			if target == "" {
+				target = path
+			}
+		}
+		if previous, ok := cw.targets[path]; ok && previous != target {
+			changed = true
+		}
+		targets[path] = target
+		want[filepath.Dir(path)] = true
+		want[filepath.Dir(target)] = true
+	}
+
+	for dir := range want {
+		if cw.dirs[dir] {
+			continue
+		}
+		if err := cw.watcher.Add(dir); err != nil {
+			return changed, err
+		}
+		cw.dirs[dir] = true
+	}
+	for dir := range cw.dirs {
+		if !want[dir] {
+			_ = cw.watcher.Remove(dir) // Already gone when the directory was removed
+			delete(cw.dirs, dir)
+		}
+	}
+	cw.targets = targets
+	return changed, nil
+}
+
+// relevant reports whether a directory event may have changed the config: it names the
+// config path, a related file, or the target of either, or it retargeted a symbolic
+// link on one of them.
+func (cw *configWatcher) relevant(event fsnotify.Event, errCh chan<- error) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	cw.pathsMu.Lock()
+	defer cw.pathsMu.Unlock()
+	changed, err := cw.rewatch()
+	if err != nil {
+		errCh <- fmt.Errorf("file watcher error: %w", err)
+	}
+	if changed {
+		return true
+	}
+	for path, target := range cw.targets {
+		if event.Name == path || event.Name == target {
+			return true
+		}
+	}
+	return false
+}
+
+// followRelated watches the related files of a newly loaded config in place of the
+// previous ones.
+func (cw *configWatcher) followRelated(related []string, errCh chan<- error) {
+	cw.pathsMu.Lock()
+	defer cw.pathsMu.Unlock()
+	cw.related = related
+	if _, err := cw.rewatch(); err != nil {
+		errCh <- fmt.Errorf("file watcher error: %w", err)
+	}
 }
 
 // Watch implements ConfigWatcher.Watch() (T100)
@@ -150,14 +257,17 @@ func (cw *configWatcher) watchLoop(ctx context.Context, eventCh chan<- ConfigCha
 			if !ok {
 				return
 			}
+			if !cw.relevant(event, errCh) {
+				continue
+			}
 
 			// Debounce: wait for DebounceDelay after last event (T102)
 			if debounceTimer != nil {
 				debounceTimer.Stop()
 			}
 
-			// Capture event for closure to avoid data race
-			eventToHandle := event
+			// The state of the file after the last event decides what happened: an atomic
+			// save removes or renames it and then creates it again
 			debounceTimer = time.AfterFunc(cw.opts.DebounceDelay, func() {
 				// Track this callback
 				cw.callbacksWg.Add(1)
@@ -168,7 +278,7 @@ func (cw *configWatcher) watchLoop(ctx context.Context, eventCh chan<- ConfigCha
 				case <-cw.watchCtx.Done():
 					return // Watcher stopped, don't send to closed channels
 				default:
-					cw.handleFileEvent(ctx, eventToHandle, eventCh, errCh)
+					cw.handleFileEvent(ctx, eventCh, errCh)
 				}
 			})
 
@@ -181,18 +291,25 @@ func (cw *configWatcher) watchLoop(ctx context.Context, eventCh chan<- ConfigCha
 	}
 }
 
-// handleFileEvent processes a debounced file system event (T101)
-func (cw *configWatcher) handleFileEvent(ctx context.Context, event fsnotify.Event, eventCh chan<- ConfigChangeEvent, _ chan<- error) {
+// handleFileEvent processes debounced file system events (T101) by checking whether the
+// config file is there now, and reloading it when it is.
+func (cw *configWatcher) handleFileEvent(ctx context.Context, eventCh chan<- ConfigChangeEvent, errCh chan<- error) {
 	cw.mu.Lock()
 	defer cw.mu.Unlock()
 
 	changeEvent := ConfigChangeEvent{
-		FilePath:  event.Name,
+		FilePath:  cw.opts.ConfigFilePath,
 		Timestamp: time.Now(),
 	}
 
 	// Determine change type (T101)
-	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+	existed := cw.exists
+	_, err := os.Stat(cw.opts.ConfigFilePath)
+	cw.exists = err == nil
+	switch {
+	case !cw.exists && !existed:
+		return // Still gone; already reported
+	case !cw.exists:
 		changeEvent.Type = ConfigDeleted
 		changeEvent.Error = fmt.Errorf("config file deleted or renamed")
 
@@ -203,41 +320,38 @@ func (cw *configWatcher) handleFileEvent(ctx context.Context, event fsnotify.Eve
 
 		eventCh <- changeEvent
 		return
+	case !existed:
+		changeEvent.Type = ConfigCreated
+	default:
+		changeEvent.Type = ConfigUpdated
 	}
 
-	if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) {
-		if event.Has(fsnotify.Create) {
-			changeEvent.Type = ConfigCreated
-		} else {
-			changeEvent.Type = ConfigUpdated
-		}
-
-		// Attempt to reload config (T103: reload validation)
-		newConfig, err := cw.loader.Load(ctx, cw.opts.LoadOptions)
-		if err != nil {
-			// Reload failed - keep previous config (Per FR-047)
-			changeEvent.Error = fmt.Errorf("config reload failed: %w", err)
-
-			// Trigger OnError callback (T104)
-			if cw.opts.OnError != nil {
-				go cw.opts.OnError(changeEvent.Error)
-			}
+	// Attempt to reload config (T103: reload validation)
+	newConfig, err := cw.loader.Load(ctx, cw.opts.LoadOptions)
+	if err != nil {
+		// Reload failed - keep previous config (Per FR-047)
+		changeEvent.Error = fmt.Errorf("config reload failed: %w", err)
 
-			eventCh <- changeEvent
-			return
+		// Trigger OnError callback (T104)
+		if cw.opts.OnError != nil {
+			go cw.opts.OnError(changeEvent.Error)
 		}
 
-		// Reload succeeded
-		changeEvent.NewConfig = newConfig
-		cw.lastConfig = newConfig
+		eventCh <- changeEvent
+		return
+	}
 
-		// Trigger OnReload callback (T104)
-		if cw.opts.OnReload != nil {
-			go cw.opts.OnReload(newConfig)
-		}
+	// Reload succeeded
+	changeEvent.NewConfig = newConfig
+	cw.lastConfig = newConfig
+	cw.followRelated(newConfig.RelatedFiles(), errCh)
 
-		eventCh <- changeEvent
+	// Trigger OnReload callback (T104)
+	if cw.opts.OnReload != nil {
+		go cw.opts.OnReload(newConfig)
 	}
+
+	eventCh <- changeEvent
 }
 
 // Stop implements ConfigWatcher.Stop() (T105)
@@ -0,0 +1,116 @@
+// Package confirm decides whether a package operation asks before it runs, and asks.
+//
+// Every operation that changes projects goes through a Service, so the confirm settings
+// apply the same in the UI and on the command line: each operation has a policy (always
+// or never), and bulk covers operations on more than one package. Without a terminal to
+// ask on, an operation that needs confirming fails unless --yes answered in advance.
+package confirm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+)
+
+// Operations with a confirm setting.
+const (
+	Add    = "add"
+	Remove = "remove"
+	Update = "update"
+)
+
+// ErrDeclined is returned when the user answers no.
+var ErrDeclined = errors.New("not confirmed")
+
+// NeedsYesError is returned when an operation needs confirming and there is no terminal
+// to ask on.
+type NeedsYesError struct {
+	Operation string
+	Setting   string // The setting that asked for confirmation (e.g., confirm.remove)
+}
+
+func (e *NeedsYesError) Error() string {
+	return fmt.Sprintf("%s needs confirmation (%s: always); pass --yes to confirm without a terminal", e.Operation, e.Setting)
+}
+
+// Request describes an operation to confirm.
+type Request struct {
+	Operation string   // Add, Remove, or Update
+	Count     int      // Packages it changes
+	Summary   string   // What it does, as the question ("Remove Serilog from App.csproj")
+	Details   []string // Lines shown before the question (e.g., what the change downloads)
+}
+
+// Service asks for confirmations the way the confirm settings say.
+type Service struct {
+	Policy      config.ConfirmConfig
+	Yes         bool // Confirm everything without asking (--yes)
+	Interactive bool // In is a terminal the user can answer on
+	In          *bufio.Reader
+	Out         io.Writer
+}
+
+// New returns a service for the confirm settings of cfg, asking on in and out when
+// interactive.
+func New(cfg *config.Config, yes, interactive bool, in io.Reader, out io.Writer) *Service {
+	return &Service{Policy: cfg.Confirm, Yes: yes, Interactive: interactive, In: bufio.NewReader(in), Out: out}
+}
+
+// Required returns the setting that makes an operation on count packages ask first, or
+// "" when none does.
+func (s *Service) Required(operation string, count int) string {
+	if count > 1 && s.Policy.Bulk == "always" {
+		return "confirm.bulk"
+	}
+	var policy string
+	switch operation {
+	case Add:
+		policy = s.Policy.Add
+	case Remove:
+		policy = s.Policy.Remove
+	case Update:
+		policy = s.Policy.Update
+	}
+	if policy == "always" {
+		return "confirm." + operation
+	}
+	return ""
+}
+
+// Asks reports whether Confirm would ask the user about an operation, so that details
+// that are costly to gather (such as download sizes) are only gathered for the question.
+func (s *Service) Asks(r Request) bool {
+	return s.Required(r.Operation, r.Count) != "" && !s.Yes && s.Interactive
+}
+
+// Confirm returns nil when the operation may go ahead: it needs no confirmation, --yes
+// was given, or the user answered yes. Otherwise it returns ErrDeclined, or a
+// *NeedsYesError without a terminal.
+func (s *Service) Confirm(r Request) error {
+	setting := s.Required(r.Operation, r.Count)
+	if setting == "" || s.Yes {
+		return nil
+	}
+	if !s.Interactive {
+		return &NeedsYesError{Operation: r.Operation, Setting: setting}
+	}
+	for _, line := range r.Details {
+		fmt.Fprintln(s.Out, line)
+	}
+	fmt.Fprintf(s.Out, "%s? [y/N] ", r.Summary)
+	line, err := s.In.ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintln(s.Out)
+		return ErrDeclined
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return nil
+	default:
+		return ErrDeclined
+	}
+}
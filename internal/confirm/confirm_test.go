@@ -0,0 +1,83 @@
+package confirm
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+)
+
+// TestRequired tests which settings make an operation ask
+func TestRequired(t *testing.T) {
+	s := &Service{Policy: config.GetDefaultConfig().Confirm}
+	tests := []struct {
+		operation string
+		count     int
+		want      string
+	}{
+		{Remove, 1, "confirm.remove"},
+		{Update, 1, ""},
+		{Add, 1, ""},
+		{Update, 3, "confirm.bulk"},
+		{Remove, 2, "confirm.bulk"},
+	}
+	for _, tt := range tests {
+		if got := s.Required(tt.operation, tt.count); got != tt.want {
+			t.Errorf("Required(%s, %d) = %q, want %q", tt.operation, tt.count, got, tt.want)
+		}
+	}
+
+	s.Policy.Bulk = "never"
+	if got := s.Required(Remove, 5); got != "confirm.remove" {
+		t.Errorf("Required(remove, 5) with bulk never = %q", got)
+	}
+	if got := s.Required(Update, 5); got != "" {
+		t.Errorf("Required(update, 5) with bulk never = %q", got)
+	}
+}
+
+// TestConfirm tests asking, --yes, and running without a terminal
+func TestConfirm(t *testing.T) {
+	remove := Request{Operation: Remove, Count: 1, Summary: "Remove Serilog from App.csproj"}
+	ask := func(answer string) (string, error) {
+		var out bytes.Buffer
+		s := &Service{Policy: config.GetDefaultConfig().Confirm, Interactive: true, In: bufio.NewReader(strings.NewReader(answer)), Out: &out}
+		err := s.Confirm(remove)
+		return out.String(), err
+	}
+
+	if out, err := ask("y\n"); err != nil || out != "Remove Serilog from App.csproj? [y/N] " {
+		t.Errorf("y = %v, prompt %q", err, out)
+	}
+	for _, answer := range []string{"\n", "no\n", ""} {
+		if _, err := ask(answer); !errors.Is(err, ErrDeclined) {
+			t.Errorf("%q = %v, want declined", answer, err)
+		}
+	}
+
+	s := New(config.GetDefaultConfig(), false, false, strings.NewReader(""), &bytes.Buffer{})
+	var needsYes *NeedsYesError
+	if err := s.Confirm(remove); !errors.As(err, &needsYes) || needsYes.Setting != "confirm.remove" {
+		t.Errorf("without a terminal = %v, want a NeedsYesError", err)
+	}
+	if err := s.Confirm(Request{Operation: Update, Count: 1}); err != nil {
+		t.Errorf("update without a terminal = %v", err)
+	}
+	if s.Asks(remove) {
+		t.Error("Asks() without a terminal = true")
+	}
+	s.Yes = true
+	if err := s.Confirm(remove); err != nil {
+		t.Errorf("with --yes = %v", err)
+	}
+
+	add := Request{Operation: Add, Count: 1, Summary: "Add Serilog to App.csproj", Details: []string{"Downloads 1.2 MB"}}
+	var out bytes.Buffer
+	s = &Service{Policy: config.ConfirmConfig{Add: "always"}, Interactive: true, In: bufio.NewReader(strings.NewReader("y\n")), Out: &out}
+	if !s.Asks(add) || s.Confirm(add) != nil || out.String() != "Downloads 1.2 MB\nAdd Serilog to App.csproj? [y/N] " {
+		t.Errorf("with details, prompt %q", out.String())
+	}
+}
@@ -0,0 +1,280 @@
+// Package custom runs the user's custom commands (see config.CustomCommand).
+//
+// A custom command is a shell command line with placeholders: {{package}}, {{project}},
+// and {{version}} are filled from the selection, and other names from the answers to the
+// command's prompts. Every value is quoted for the platform shell, so an answer with
+// spaces or shell syntax stays one word. The command runs in the workspace root with the
+// selection also in environment variables:
+//
+//	LAZYNUGET_CUSTOM_COMMAND  Name of the custom command
+//	LAZYNUGET_PROJECT         Selected project file (empty when none is)
+//	LAZYNUGET_PACKAGE         Selected package ID
+//	LAZYNUGET_VERSION         Selected package version
+//
+// Its output is collected for a pager, shown only when it fails (output none), or, for
+// interactive commands (output terminal), the command gets the terminal.
+package custom
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/editor"
+	"github.com/willibrandon/lazynuget/internal/hooks"
+)
+
+// Kind is the JSON document kind of `lazynuget custom list --json`.
+const Kind = "custom-commands"
+
+// DefaultTimeout bounds custom commands that do not set a timeout.
+const DefaultTimeout = 10 * time.Minute
+
+// Output modes of a custom command.
+const (
+	OutputPager    = "pager"
+	OutputTerminal = "terminal"
+	OutputNone     = "none"
+)
+
+// Selection is what the custom command runs on.
+type Selection struct {
+	Project string
+	Package string
+	Version string
+}
+
+// value returns the selection a placeholder names, and whether it names one.
+func (s Selection) value(name string) (string, bool) {
+	switch name {
+	case "project":
+		return s.Project, true
+	case "package":
+		return s.Package, true
+	case "version":
+		return s.Version, true
+	default:
+		return "", false
+	}
+}
+
+// Info describes a custom command in `lazynuget custom list --json`.
+type Info struct {
+	Name        string   `json:"name"`
+	Key         string   `json:"key,omitempty"`
+	Context     string   `json:"context"`
+	Description string   `json:"description,omitempty"`
+	Command     string   `json:"command"`
+	Prompts     []string `json:"prompts,omitempty"` // Prompt names, in the order asked
+	Output      string   `json:"output"`
+}
+
+// List describes the custom commands, as the command palette lists them.
+func List(commands []config.CustomCommand) []Info {
+	infos := make([]Info, 0, len(commands))
+	for _, c := range commands {
+		info := Info{Name: c.Name, Key: c.Key, Context: c.Context, Description: c.Description, Command: c.Command, Output: OutputOf(c)}
+		if info.Context == "" {
+			info.Context = "global"
+		}
+		for _, p := range c.Prompts {
+			info.Prompts = append(info.Prompts, p.Name)
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// Find returns the custom command of a name; names are case-insensitive.
+func Find(commands []config.CustomCommand, name string) (config.CustomCommand, bool) {
+	for _, c := range commands {
+		if strings.EqualFold(c.Name, name) {
+			return c, true
+		}
+	}
+	return config.CustomCommand{}, false
+}
+
+// OutputOf returns the output mode of a custom command.
+func OutputOf(c config.CustomCommand) string {
+	if c.Output == "" {
+		return OutputPager
+	}
+	return c.Output
+}
+
+// Default returns the default answer of a prompt, with the selection filled in as is
+// (the answer is quoted when it replaces its own placeholder).
+func Default(p config.CustomPrompt, sel Selection) string {
+	return config.ReplacePlaceholders(p.Default, func(name string) string {
+		value, _ := sel.value(name)
+		return value
+	})
+}
+
+// Expand returns the command line of a custom command with its placeholders replaced by
+// quoted values. It fails when the command uses a selection that is empty (e.g.,
+// {{package}} with no package selected) or a prompt that was not answered.
+func Expand(c config.CustomCommand, sel Selection, answers map[string]string) (string, error) {
+	for _, name := range config.Placeholders(c.Command) {
+		value, selection := sel.value(name)
+		if !selection {
+			var answered bool
+			value, answered = answers[name]
+			if !answered {
+				return "", fmt.Errorf("%s: no answer for {{%s}}", c.Name, name)
+			}
+			continue
+		}
+		if value == "" {
+			return "", fmt.Errorf("%s needs a %s selected", c.Name, name)
+		}
+	}
+	return config.ReplacePlaceholders(c.Command, func(name string) string {
+		if value, ok := sel.value(name); ok {
+			return Quote(value)
+		}
+		return Quote(answers[name])
+	}), nil
+}
+
+// safeWordRegex matches words no shell needs quoted.
+var safeWordRegex = regexp.MustCompile(`^[A-Za-z0-9_./:@%+=,-]+$`)
+
+// Quote returns s as one word of the platform shell: sh -c, or cmd /C on Windows.
+func Quote(s string) string {
+	return quote(runtime.GOOS, s)
+}
+
+// quote quotes s for the shell of goos. sh words are single-quoted, which leaves nothing
+// special inside; cmd words are double-quoted with quotes doubled, which protects spaces
+// and &|<> but not %VAR%, which cmd expands even in quotes.
+func quote(goos, s string) string {
+	if goos == "windows" {
+		if safeWordRegex.MatchString(s) && !strings.Contains(s, "%") {
+			return s
+		}
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	if safeWordRegex.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Result is the outcome of a custom command.
+type Result struct {
+	Command  string // The command line run
+	Output   string // Combined stdout and stderr; empty for output terminal
+	ExitCode int
+	Duration time.Duration
+	Err      error // Non-nil when the command failed, timed out, or could not run
+}
+
+// Runner runs custom commands.
+type Runner struct {
+	Sandbox config.SandboxConfig
+	Dir     string // Where commands run: the workspace root
+
+	// For output terminal: the terminal the command gets, and an optional Suspender that
+	// releases it from the UI while the command runs.
+	Stdin     io.Reader
+	Stdout    io.Writer
+	Stderr    io.Writer
+	Suspender editor.Suspender
+}
+
+// NewRunner returns a runner for the sandbox settings of cfg, running commands in dir.
+func NewRunner(cfg *config.Config, dir string) *Runner {
+	return &Runner{Sandbox: cfg.Sandbox, Dir: dir}
+}
+
+// Run runs a custom command line (see Expand).
+func (r *Runner) Run(ctx context.Context, c config.CustomCommand, line string, sel Selection) (result Result) {
+	result = Result{Command: line, ExitCode: -1}
+	terminal := OutputOf(c) == OutputTerminal
+
+	// Interactive commands take as long as the user does
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if !terminal {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	sandboxed := r.Sandbox.Enabled
+	if c.Sandbox != nil {
+		sandboxed = *c.Sandbox
+	}
+	cmd, err := hooks.Command(ctx, line, r.Dir, r.Sandbox, sandboxed)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	cmd.Env = append(cmd.Env,
+		"LAZYNUGET_CUSTOM_COMMAND="+c.Name,
+		"LAZYNUGET_PROJECT="+sel.Project,
+		"LAZYNUGET_PACKAGE="+sel.Package,
+		"LAZYNUGET_VERSION="+sel.Version,
+	)
+
+	var output bytes.Buffer
+	if terminal {
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = r.Stdin, r.Stdout, r.Stderr
+		if r.Suspender != nil {
+			if err := r.Suspender.Suspend(); err != nil {
+				result.Err = err
+				return result
+			}
+			defer func() {
+				if err := r.Suspender.Resume(); err != nil && result.Err == nil {
+					result.Err = err
+				}
+			}()
+		}
+	} else {
+		cmd.Stdout, cmd.Stderr = &output, &output
+		cmd.WaitDelay = time.Second
+	}
+
+	start := time.Now()
+	err = cmd.Run()
+	result.Duration = time.Since(start)
+	result.Output = output.String()
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.Err = fmt.Errorf("timed out after %s", timeout)
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+		result.Err = fmt.Errorf("exit status %d", result.ExitCode)
+	case err != nil:
+		result.Err = err
+	default:
+		result.ExitCode = 0
+	}
+	return result
+}
+
+// Pager returns the pager command line: $PAGER, then less -R (more on Windows).
+func Pager(getenv func(string) string) ([]string, error) {
+	if pager := strings.TrimSpace(getenv("PAGER")); pager != "" {
+		return editor.Split(pager)
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"more"}, nil
+	}
+	return []string{"less", "-R"}, nil
+}
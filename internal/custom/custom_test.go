@@ -0,0 +1,144 @@
+package custom
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+)
+
+// TestQuote tests quoting for sh and cmd
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		goos string
+		in   string
+		want string
+	}{
+		{"linux", "Serilog", "Serilog"},
+		{"linux", "src/App/App.csproj", "src/App/App.csproj"},
+		{"linux", "", "''"},
+		{"linux", "Update Serilog; rm -rf /", "'Update Serilog; rm -rf /'"},
+		{"linux", "it's $HOME", `'it'\''s $HOME'`},
+		{"windows", `src\App\App.csproj`, `"src\App\App.csproj"`},
+		{"windows", `say "hi" & exit`, `"say ""hi"" & exit"`},
+		{"windows", "1.0.0", "1.0.0"},
+	}
+	for _, tt := range tests {
+		if got := quote(tt.goos, tt.in); got != tt.want {
+			t.Errorf("quote(%s, %q) = %q, want %q", tt.goos, tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestExpand tests replacing placeholders with the selection and prompt answers
+func TestExpand(t *testing.T) {
+	c := config.CustomCommand{
+		Name:    "bump",
+		Command: "git commit -m {{ message }} {{project}} && echo {{package}}@{{version}}",
+		Prompts: []config.CustomPrompt{{Name: "message", Default: "Update {{package}} to {{version}}"}},
+	}
+	sel := Selection{Project: "src/App/App.csproj", Package: "Serilog", Version: "3.1.1"}
+
+	if got := Default(c.Prompts[0], sel); got != "Update Serilog to 3.1.1" {
+		t.Errorf("Default = %q", got)
+	}
+	if _, err := Expand(c, sel, nil); err == nil {
+		t.Error("Expand succeeded without an answer for {{message}}")
+	}
+	got, err := Expand(c, sel, map[string]string{"message": "Update Serilog"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "git commit -m " + Quote("Update Serilog") + " src/App/App.csproj && echo Serilog@3.1.1"
+	if got != want {
+		t.Errorf("Expand = %q, want %q", got, want)
+	}
+
+	_, err = Expand(c, Selection{Project: "src/App/App.csproj"}, map[string]string{"message": "x"})
+	if err == nil || !strings.Contains(err.Error(), "needs a package selected") {
+		t.Errorf("Expand without a package = %v, want a missing selection error", err)
+	}
+}
+
+// TestList tests the palette listing with defaults filled in
+func TestList(t *testing.T) {
+	infos := List([]config.CustomCommand{
+		{Name: "why", Key: "w", Context: "packages", Command: "dotnet nuget why {{package}}"},
+		{Name: "bump", Command: "echo {{message}}", Output: OutputNone, Prompts: []config.CustomPrompt{{Name: "message"}}},
+	})
+	if len(infos) != 2 || infos[0].Output != OutputPager || infos[1].Context != "global" || strings.Join(infos[1].Prompts, ",") != "message" {
+		t.Errorf("List = %+v", infos)
+	}
+	if c, ok := Find([]config.CustomCommand{{Name: "Why"}}, "why"); !ok || c.Name != "Why" {
+		t.Errorf("Find(why) = %+v, %v", c, ok)
+	}
+}
+
+// TestRun tests running a custom command: environment, directory, output, and failures
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("custom commands in this test use sh")
+	}
+	dir := t.TempDir()
+	r := &Runner{Dir: dir}
+	sel := Selection{Package: "Serilog", Version: "3.1.1"}
+
+	c := config.CustomCommand{Name: "env", Command: `echo "$LAZYNUGET_CUSTOM_COMMAND $LAZYNUGET_PACKAGE $LAZYNUGET_VERSION"; basename "$PWD"`}
+	result := r.Run(context.Background(), c, c.Command, sel)
+	if result.Err != nil || result.Output != "env Serilog 3.1.1\n"+filepath.Base(dir)+"\n" {
+		t.Errorf("Run = %+v", result)
+	}
+
+	c = config.CustomCommand{Name: "fail", Command: "echo oops; exit 3", Output: OutputNone}
+	if result := r.Run(context.Background(), c, c.Command, sel); result.ExitCode != 3 || result.Err == nil || result.Output != "oops\n" {
+		t.Errorf("Run(fail) = %+v, want exit status 3 with its output", result)
+	}
+
+	c = config.CustomCommand{Name: "slow", Command: "sleep 5", Timeout: 50 * time.Millisecond}
+	if result := r.Run(context.Background(), c, c.Command, sel); result.Err == nil || !strings.Contains(result.Err.Error(), "timed out") {
+		t.Errorf("Run(slow) = %+v, want a timeout", result)
+	}
+
+	// Output terminal hands over the terminal, suspending the UI around the command
+	var stdout bytes.Buffer
+	suspender := &recordingSuspender{}
+	r = &Runner{Dir: dir, Stdin: strings.NewReader("typed\n"), Stdout: &stdout, Suspender: suspender}
+	c = config.CustomCommand{Name: "interactive", Command: "read answer; echo got $answer", Output: OutputTerminal}
+	if result := r.Run(context.Background(), c, c.Command, sel); result.Err != nil || result.Output != "" {
+		t.Errorf("Run(interactive) = %+v", result)
+	}
+	if stdout.String() != "got typed\n" || suspender.calls != "suspend resume" {
+		t.Errorf("terminal output %q, suspender calls %q", stdout.String(), suspender.calls)
+	}
+}
+
+// recordingSuspender records Suspend and Resume calls.
+type recordingSuspender struct {
+	calls string
+}
+
+func (s *recordingSuspender) Suspend() error {
+	s.calls = strings.TrimSpace(s.calls + " suspend")
+	return nil
+}
+
+func (s *recordingSuspender) Resume() error {
+	s.calls = strings.TrimSpace(s.calls + " resume")
+	return nil
+}
+
+// TestPager tests the pager command line
+func TestPager(t *testing.T) {
+	pager, err := Pager(func(string) string { return "bat --paging=always" })
+	if err != nil || strings.Join(pager, " ") != "bat --paging=always" {
+		t.Errorf("Pager with $PAGER = %v, %v", pager, err)
+	}
+	if pager, _ := Pager(func(string) string { return "" }); len(pager) == 0 {
+		t.Error("Pager without $PAGER is empty")
+	}
+}
@@ -0,0 +1,157 @@
+// Package daemon serves lazynuget's JSON-RPC methods over a local socket, so editor
+// extensions reuse one long-running process's configuration, caches, and project parsing
+// instead of starting lazynuget for every request. docs/DAEMON_PROTOCOL.md describes the
+// protocol.
+//
+// Each workspace has its own socket in the cache directory. The socket is only accessible
+// to the user who started the daemon.
+package daemon
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/jsonrpc"
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// SocketDirName is the directory under the cache directory that holds daemon sockets.
+const SocketDirName = "daemon"
+
+// DefaultSocketDir returns the platform socket directory (<cache dir>/daemon), with the
+// cache directory's temp fallback applied (see platform.AppDir).
+func DefaultSocketDir() string {
+	return platform.AppDir(platform.DirCache, SocketDirName)
+}
+
+// SocketPath returns the socket of the daemon for a workspace.
+func SocketPath(socketDir, workspace string) string {
+	sum := sha256.Sum256([]byte(filepath.Clean(workspace)))
+	return filepath.Join(socketDir, hex.EncodeToString(sum[:8])+".sock")
+}
+
+// Daemon is a running daemon.
+type Daemon struct {
+	listener net.Listener
+	server   *jsonrpc.Server
+	ctx      context.Context
+	cancel   context.CancelFunc
+	conns    map[net.Conn]struct{}
+	path     string
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+}
+
+// Listen starts serving server's methods on the socket at path. Each connection is a
+// separate JSON-RPC session; a shutdown request ends the session, not the daemon. A socket
+// left behind by a daemon that is no longer running is replaced.
+func Listen(path string, server *jsonrpc.Server) (*Daemon, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("a daemon is already listening on %s", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("failed to restrict access to %s: %w", path, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Daemon{
+		listener: listener,
+		server:   server,
+		ctx:      ctx,
+		cancel:   cancel,
+		conns:    make(map[net.Conn]struct{}),
+		path:     path,
+	}
+	d.wg.Add(1)
+	go d.accept()
+	return d, nil
+}
+
+// Path returns the socket's path.
+func (d *Daemon) Path() string {
+	return d.path
+}
+
+// accept serves connections until the listener is closed.
+func (d *Daemon) accept() {
+	defer d.wg.Done()
+	for {
+		conn, err := d.listener.Accept()
+		if err != nil {
+			return
+		}
+		d.mu.Lock()
+		if d.ctx.Err() != nil {
+			d.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		d.conns[conn] = struct{}{}
+		d.wg.Add(1)
+		d.mu.Unlock()
+
+		go func() {
+			defer d.wg.Done()
+			_ = d.server.Serve(d.ctx, conn, conn)
+			d.mu.Lock()
+			delete(d.conns, conn)
+			d.mu.Unlock()
+			_ = conn.Close()
+		}()
+	}
+}
+
+// Shutdown stops accepting connections, cancels requests in progress, closes open
+// sessions, and removes the socket. It waits for sessions to end until ctx is done.
+func (d *Daemon) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	d.cancel()
+	err := d.listener.Close()
+	for conn := range d.conns {
+		_ = conn.Close()
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	// Closing a unix listener removes its socket file; this covers platforms where it does not
+	if err := os.Remove(d.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
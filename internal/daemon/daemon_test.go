@@ -0,0 +1,124 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/jsonrpc"
+)
+
+// TestSocketPath tests that each workspace gets its own socket
+func TestSocketPath(t *testing.T) {
+	a := SocketPath("/cache/daemon", "/src/one")
+	if a != SocketPath("/cache/daemon", "/src/one/") {
+		t.Error("SocketPath() should not depend on a trailing separator")
+	}
+	if a == SocketPath("/cache/daemon", "/src/two") {
+		t.Error("SocketPath() should differ between workspaces")
+	}
+	if filepath.Dir(a) != filepath.Clean("/cache/daemon") || !strings.HasSuffix(a, ".sock") {
+		t.Errorf("SocketPath() = %q, want a .sock file in the socket directory", a)
+	}
+}
+
+// TestDaemon tests sessions, a second daemon on the same socket, and shutdown
+func TestDaemon(t *testing.T) {
+	// Socket paths are limited to about 100 bytes, which t.TempDir() may exceed
+	dir, err := os.MkdirTemp("", "lnd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sub", "test.sock")
+
+	server := jsonrpc.NewServer()
+	server.Handle("echo", func(_ context.Context, params json.RawMessage) (any, error) {
+		return params, nil
+	})
+	d, err := Listen(path, server)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("socket mode = %v, %v; want 0600", info.Mode().Perm(), err)
+	}
+	if _, err := Listen(path, server); err == nil || !strings.Contains(err.Error(), "already listening") {
+		t.Errorf("second Listen() error = %v, want already listening", err)
+	}
+
+	// Two sessions at once; shutdown ends only the first
+	first, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+	second, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+
+	call := func(conn net.Conn, request string) string {
+		t.Helper()
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if _, err := conn.Write([]byte(request + "\n")); err != nil {
+			t.Fatal(err)
+		}
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(line)
+	}
+	if got, want := call(first, `{"jsonrpc":"2.0","id":1,"method":"echo","params":[1]}`), `{"jsonrpc":"2.0","id":1,"result":[1]}`; got != want {
+		t.Errorf("echo = %s, want %s", got, want)
+	}
+	if got, want := call(first, `{"jsonrpc":"2.0","id":2,"method":"shutdown"}`), `{"jsonrpc":"2.0","id":2,"result":null}`; got != want {
+		t.Errorf("shutdown = %s, want %s", got, want)
+	}
+	if got, want := call(second, `{"jsonrpc":"2.0","id":3,"method":"echo","params":[3]}`), `{"jsonrpc":"2.0","id":3,"result":[3]}`; got != want {
+		t.Errorf("echo after another session's shutdown = %s, want %s", got, want)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("socket still exists after Shutdown(): %v", err)
+	}
+	_ = second.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := second.Read(make([]byte, 1)); err == nil {
+		t.Error("open session should be closed by Shutdown()")
+	}
+}
+
+// TestListenStaleSocket tests that a socket nobody listens on is replaced
+func TestListenStaleSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "lnd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "stale.sock")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := Listen(path, jsonrpc.NewServer())
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	if err := d.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+}
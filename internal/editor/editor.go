@@ -0,0 +1,144 @@
+// Package editor opens files in the user's editor.
+//
+// The editor command is the editor setting, then $VISUAL, then $EDITOR, then a platform
+// default. Terminal editors share the terminal with LazyNuGet, so callers that own the
+// terminal (the TUI) pass a Suspender that releases it while the editor runs. Edit reports
+// whether the file changed so callers reload only what was edited.
+package editor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Suspender releases the terminal while the editor runs (e.g., leaving the alternate
+// screen and raw mode) and takes it back when the editor exits.
+type Suspender interface {
+	Suspend() error
+	Resume() error
+}
+
+// Command returns the editor command line: the configured command, then $VISUAL, then
+// $EDITOR, then vi (notepad on Windows).
+func Command(configured string, getenv func(string) string) ([]string, error) {
+	for _, candidate := range []string{configured, getenv("VISUAL"), getenv("EDITOR")} {
+		if strings.TrimSpace(candidate) != "" {
+			return Split(candidate)
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}, nil
+	}
+	return []string{"vi"}, nil
+}
+
+// Split splits a command line into words the way a POSIX shell does for simple commands:
+// on unquoted whitespace, with single quotes, double quotes, and backslash escapes, so
+// editor paths with spaces can be quoted.
+func Split(command string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	for _, c := range command {
+		switch {
+		case escaped:
+			word.WriteRune(c)
+			escaped = false
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(c)
+			}
+		case c == '\\' && runtime.GOOS != "windows":
+			// Backslashes are path separators on Windows
+			escaped, inWord = true, true
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote, inWord = c, true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("invalid editor command %q: unterminated quote or escape", command)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	if len(words) == 0 {
+		return nil, errors.New("empty editor command")
+	}
+	return words, nil
+}
+
+// Edit opens path in the editor and waits for it to exit, suspending the terminal owner
+// (if any) meanwhile. It reports whether the file's content changed.
+func Edit(ctx context.Context, command []string, path string, suspender Suspender) (bool, error) {
+	before, err := digest(path)
+	if err != nil {
+		return false, err
+	}
+
+	if suspender != nil {
+		if err := suspender.Suspend(); err != nil {
+			return false, fmt.Errorf("failed to release the terminal: %w", err)
+		}
+	}
+
+	// #nosec G204 -- the editor command is chosen by the user
+	cmd := exec.CommandContext(ctx, command[0], append(command[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	runErr := cmd.Run()
+
+	if suspender != nil {
+		if err := suspender.Resume(); err != nil {
+			return false, fmt.Errorf("failed to restore the terminal: %w", err)
+		}
+	}
+	if runErr != nil {
+		return false, fmt.Errorf("editor %s failed: %w", command[0], runErr)
+	}
+
+	after, err := digest(path)
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(before, after), nil
+}
+
+// digest returns a hash of a file's content, or nil when it does not exist.
+func digest(path string) ([]byte, error) {
+	// #nosec G304 -- path is the file the user chose to edit
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	return sum[:], nil
+}
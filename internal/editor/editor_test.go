@@ -0,0 +1,112 @@
+package editor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+// TestCommand tests the precedence of the editor setting and environment variables
+func TestCommand(t *testing.T) {
+	env := map[string]string{"VISUAL": "code --wait", "EDITOR": "nano"}
+	getenv := func(k string) string { return env[k] }
+
+	tests := []struct {
+		configured string
+		env        map[string]string
+		want       []string
+	}{
+		{`"/opt/Sublime Text/subl" -w`, env, []string{"/opt/Sublime Text/subl", "-w"}},
+		{"", env, []string{"code", "--wait"}},
+		{"  ", map[string]string{"EDITOR": "nano"}, []string{"nano"}},
+	}
+	for _, tt := range tests {
+		env = tt.env
+		got, err := Command(tt.configured, getenv)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("Command(%q) = %q, %v; want %q", tt.configured, got, err, tt.want)
+		}
+	}
+
+	env = nil
+	if got, _ := Command("", getenv); len(got) != 1 || (got[0] != "vi" && got[0] != "notepad") {
+		t.Errorf("Command() default = %q, want vi or notepad", got)
+	}
+}
+
+// TestSplit tests splitting editor command lines
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"vim", []string{"vim"}},
+		{"  emacsclient   -t  ", []string{"emacsclient", "-t"}},
+		{`code --wait`, []string{"code", "--wait"}},
+		{`'my editor' "-a b" c''d`, []string{"my editor", "-a b", "cd"}},
+		{`""`, []string{""}},
+	}
+	for _, tt := range tests {
+		got, err := Split(tt.command)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("Split(%q) = %q, %v; want %q", tt.command, got, err, tt.want)
+		}
+	}
+
+	for _, command := range []string{"", "   ", `vim "unterminated`} {
+		if _, err := Split(command); err == nil {
+			t.Errorf("Split(%q) should fail", command)
+		}
+	}
+}
+
+// recordingSuspender records Suspend and Resume calls.
+type recordingSuspender struct {
+	calls []string
+}
+
+func (s *recordingSuspender) Suspend() error {
+	s.calls = append(s.calls, "suspend")
+	return nil
+}
+
+func (s *recordingSuspender) Resume() error {
+	s.calls = append(s.calls, "resume")
+	return nil
+}
+
+// TestEdit tests running the editor, suspending the terminal owner, and detecting changes
+func TestEdit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh as the editor")
+	}
+	path := filepath.Join(t.TempDir(), "App.csproj")
+	if err := os.WriteFile(path, []byte("<Project />\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	s := &recordingSuspender{}
+	changed, err := Edit(ctx, []string{"sh", "-c", `echo "<!-- edited -->" >> "$1"`, "editor"}, path, s)
+	if err != nil || !changed {
+		t.Errorf("Edit() = %v, %v; want changed", changed, err)
+	}
+	if !slices.Equal(s.calls, []string{"suspend", "resume"}) {
+		t.Errorf("suspender calls = %v, want suspend then resume", s.calls)
+	}
+
+	if changed, err := Edit(ctx, []string{"true"}, path, nil); err != nil || changed {
+		t.Errorf("Edit() without changes = %v, %v; want unchanged", changed, err)
+	}
+
+	s = &recordingSuspender{}
+	if _, err := Edit(ctx, []string{"false"}, path, s); err == nil {
+		t.Error("Edit() should report a failing editor")
+	}
+	if !slices.Equal(s.calls, []string{"suspend", "resume"}) {
+		t.Errorf("suspender calls = %v, want the terminal restored after a failure", s.calls)
+	}
+}
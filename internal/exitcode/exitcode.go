@@ -0,0 +1,27 @@
+// Package exitcode defines the exit statuses of lazynuget and its commands, so scripts can
+// tell a bad invocation from a broken environment, a failed check, or a job that only
+// partly succeeded.
+package exitcode
+
+// Exit statuses. Commands document which they use in the cli registry.
+const (
+	// Success means the command did everything it was asked to.
+	Success = 0
+
+	// UserError means the invocation or its inputs were wrong: unknown flags, missing
+	// arguments, invalid configuration, or files and packages that do not exist.
+	UserError = 1
+
+	// SystemError means the command could not do its work: I/O, network, or dotnet
+	// failures, and unexpected errors.
+	SystemError = 2
+
+	// PolicyViolation means the command ran but what it checked breaks a rule: an audit
+	// found vulnerable packages or policy violations, or the machine policy forbids the
+	// command.
+	PolicyViolation = 3
+
+	// PartialFailure means some of the command's items succeeded and others did not, such
+	// as repositories in a batch or versions in a snapshot.
+	PartialFailure = 4
+)
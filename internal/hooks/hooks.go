@@ -0,0 +1,233 @@
+// Package hooks runs the user's hook commands around package operations.
+//
+// Hooks are configured in the hooks section of the config file (see config.Hooks). Each
+// command runs through the shell in the project's directory, with the operation described
+// in environment variables:
+//
+//	LAZYNUGET_HOOK              preInstall, postUpdate, or postRestore
+//	LAZYNUGET_PROJECT           Project file path (empty when the operation spans projects)
+//	LAZYNUGET_PACKAGE           Package ID (empty for restore)
+//	LAZYNUGET_VERSION           Version installed or updated to
+//	LAZYNUGET_PREVIOUS_VERSION  Version updated from
+//
+// Hooks run in the sandbox when sandbox.enabled is set or the hook asks for it, and
+// refuse to run where no sandbox is available.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// Events are the operations hooks run around.
+const (
+	EventPreInstall  = "preInstall"
+	EventPostUpdate  = "postUpdate"
+	EventPostRestore = "postRestore"
+)
+
+// DefaultTimeout bounds hooks that do not set a timeout.
+const DefaultTimeout = time.Minute
+
+// maxLoggedOutput bounds the hook output kept for the log and notifications.
+const maxLoggedOutput = 16 << 10
+
+// Operation describes what a hook runs around.
+type Operation struct {
+	Event           string
+	Project         string
+	Package         string
+	Version         string
+	PreviousVersion string
+}
+
+// Env returns the environment variables describing the operation.
+func (o Operation) Env() map[string]string {
+	return map[string]string{
+		"LAZYNUGET_HOOK":             o.Event,
+		"LAZYNUGET_PROJECT":          o.Project,
+		"LAZYNUGET_PACKAGE":          o.Package,
+		"LAZYNUGET_VERSION":          o.Version,
+		"LAZYNUGET_PREVIOUS_VERSION": o.PreviousVersion,
+	}
+}
+
+// Pre reports whether the hooks run before the operation, and can cancel it by failing.
+func (o Operation) Pre() bool {
+	return o.Event == EventPreInstall
+}
+
+// Result is the outcome of one hook command.
+type Result struct {
+	Command  string
+	Output   string // Combined stdout and stderr, truncated to the last 16 KiB
+	ExitCode int
+	Duration time.Duration
+	Err      error // Non-nil when the command failed, timed out, or could not run
+}
+
+// Runner runs the configured hooks.
+type Runner struct {
+	Hooks   config.Hooks
+	Sandbox config.SandboxConfig
+	Logger  config.Logger  // Optional; receives each hook's output
+	Notify  func(r Result) // Optional; called after each hook, e.g., to show failures in the UI
+}
+
+// NewRunner returns a runner for the hooks and sandbox settings of cfg.
+func NewRunner(cfg *config.Config) *Runner {
+	return &Runner{Hooks: cfg.Hooks, Sandbox: cfg.Sandbox}
+}
+
+// Run runs the hooks for the operation's event in order. Pre-operation hooks stop at the
+// first failure, whose error is returned so the caller cancels the operation; post-
+// operation hooks all run, and the first failure is returned for the caller to report.
+func (r *Runner) Run(ctx context.Context, op Operation) ([]Result, error) {
+	var hooks []config.Hook
+	switch op.Event {
+	case EventPreInstall:
+		hooks = r.Hooks.PreInstall
+	case EventPostUpdate:
+		hooks = r.Hooks.PostUpdate
+	case EventPostRestore:
+		hooks = r.Hooks.PostRestore
+	default:
+		return nil, fmt.Errorf("unknown hook event %q", op.Event)
+	}
+
+	var results []Result
+	var firstErr error
+	for _, hook := range hooks {
+		result := r.run(ctx, hook, op)
+		results = append(results, result)
+		r.report(op, result)
+
+		if result.Err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s hook %q failed: %w", op.Event, hook.Command, result.Err)
+			if op.Pre() {
+				break
+			}
+		}
+	}
+	return results, firstErr
+}
+
+// run runs one hook command.
+func (r *Runner) run(ctx context.Context, hook config.Hook, op Operation) Result {
+	result := Result{Command: hook.Command, ExitCode: -1}
+
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	dir, err := os.Getwd()
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if op.Project != "" {
+		dir = filepath.Dir(op.Project)
+	}
+
+	sandboxed := r.Sandbox.Enabled
+	if hook.Sandbox != nil {
+		sandboxed = *hook.Sandbox
+	}
+	cmd, err := Command(ctx, hook.Command, dir, r.Sandbox, sandboxed)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	for key, value := range op.Env() {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	var output bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &output, &output
+	// Do not wait for background processes the hook leaves holding the output open
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err = cmd.Run()
+	result.Duration = time.Since(start)
+	result.Output = tail(output.String(), maxLoggedOutput)
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.Err = fmt.Errorf("timed out after %s", timeout)
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+		result.Err = fmt.Errorf("exit status %d", result.ExitCode)
+	case err != nil:
+		result.Err = err
+	default:
+		result.ExitCode = 0
+	}
+	return result
+}
+
+// Command returns a command that runs a shell command line in dir through the platform
+// shell, inside the sandbox when sandboxed. It fails when no sandbox is available.
+func Command(ctx context.Context, command, dir string, sandbox config.SandboxConfig, sandboxed bool) (*exec.Cmd, error) {
+	executable, args := shell(command)
+	if sandboxed {
+		var err error
+		executable, args, err = platform.SandboxCommand(executable, args, platform.SandboxOptions{
+			WorkingDir:    dir,
+			WritablePaths: sandbox.WritablePaths,
+			AllowNetwork:  sandbox.AllowNetwork,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// #nosec G204 -- hook and custom commands are configured by the user
+	cmd := exec.CommandContext(ctx, executable, args...)
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+	return cmd, nil
+}
+
+// report logs a hook's result and passes it to Notify.
+func (r *Runner) report(op Operation, result Result) {
+	if r.Logger != nil {
+		if result.Err != nil {
+			r.Logger.Warn("%s hook %q failed after %s: %v\n%s", op.Event, result.Command, result.Duration.Round(time.Millisecond), result.Err, result.Output)
+		} else {
+			r.Logger.Info("%s hook %q finished in %s\n%s", op.Event, result.Command, result.Duration.Round(time.Millisecond), result.Output)
+		}
+	}
+	if r.Notify != nil {
+		r.Notify(result)
+	}
+}
+
+// shell returns the command line that runs command through the platform shell.
+func shell(command string) (string, []string) {
+	if runtime.GOOS == "windows" {
+		return "cmd", []string{"/C", command}
+	}
+	return "sh", []string{"-c", command}
+}
+
+// tail returns the last n bytes of s, marking the truncation.
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return "[...]" + s[len(s)-n:]
+}
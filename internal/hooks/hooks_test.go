@@ -0,0 +1,173 @@
+package hooks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// TestOperationEnv tests the environment variables describing an operation.
+func TestOperationEnv(t *testing.T) {
+	op := Operation{Event: EventPostUpdate, Project: "a/A.csproj", Package: "Serilog", Version: "3.1.0", PreviousVersion: "3.0.0"}
+	want := map[string]string{
+		"LAZYNUGET_HOOK":             "postUpdate",
+		"LAZYNUGET_PROJECT":          "a/A.csproj",
+		"LAZYNUGET_PACKAGE":          "Serilog",
+		"LAZYNUGET_VERSION":          "3.1.0",
+		"LAZYNUGET_PREVIOUS_VERSION": "3.0.0",
+	}
+	env := op.Env()
+	for key, value := range want {
+		if env[key] != value {
+			t.Errorf("%s = %q, want %q", key, env[key], value)
+		}
+	}
+}
+
+// TestRun tests running hooks: environment, working directory, output capture, timeouts,
+// and which failures stop the remaining hooks.
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands in this test use sh")
+	}
+
+	dir := t.TempDir()
+	projectPath := filepath.Join(dir, "A.csproj")
+
+	tests := []struct {
+		name      string
+		event     string
+		hooks     []config.Hook
+		wantRuns  int
+		wantErr   bool
+		wantCodes []int
+		wantOut   []string
+	}{
+		{
+			name:      "environment and working directory",
+			event:     EventPostUpdate,
+			hooks:     []config.Hook{{Command: `echo "$LAZYNUGET_HOOK $LAZYNUGET_PACKAGE $LAZYNUGET_PREVIOUS_VERSION->$LAZYNUGET_VERSION"; basename "$PWD"`}},
+			wantRuns:  1,
+			wantCodes: []int{0},
+			wantOut:   []string{"postUpdate Serilog 3.0.0->3.1.0\n" + filepath.Base(dir) + "\n"},
+		},
+		{
+			name:      "stderr is captured",
+			event:     EventPostUpdate,
+			hooks:     []config.Hook{{Command: "echo oops >&2; exit 3"}},
+			wantRuns:  1,
+			wantErr:   true,
+			wantCodes: []int{3},
+			wantOut:   []string{"oops\n"},
+		},
+		{
+			name:      "post hooks all run after a failure",
+			event:     EventPostUpdate,
+			hooks:     []config.Hook{{Command: "exit 1"}, {Command: "echo second"}},
+			wantRuns:  2,
+			wantErr:   true,
+			wantCodes: []int{1, 0},
+			wantOut:   []string{"", "second\n"},
+		},
+		{
+			name:      "pre hooks stop at the first failure",
+			event:     EventPreInstall,
+			hooks:     []config.Hook{{Command: "exit 1"}, {Command: "echo second"}},
+			wantRuns:  1,
+			wantErr:   true,
+			wantCodes: []int{1},
+			wantOut:   []string{""},
+		},
+		{
+			name:      "timeout",
+			event:     EventPostRestore,
+			hooks:     []config.Hook{{Command: "sleep 10", Timeout: 100 * time.Millisecond}},
+			wantRuns:  1,
+			wantErr:   true,
+			wantCodes: []int{-1},
+			wantOut:   []string{""},
+		},
+		{
+			name:     "no hooks",
+			event:    EventPostRestore,
+			wantRuns: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var notified int
+			r := &Runner{Notify: func(Result) { notified++ }}
+			switch tt.event {
+			case EventPreInstall:
+				r.Hooks.PreInstall = tt.hooks
+			case EventPostUpdate:
+				r.Hooks.PostUpdate = tt.hooks
+			case EventPostRestore:
+				r.Hooks.PostRestore = tt.hooks
+			}
+
+			start := time.Now()
+			results, err := r.Run(context.Background(), Operation{
+				Event: tt.event, Project: projectPath, Package: "Serilog", Version: "3.1.0", PreviousVersion: "3.0.0",
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if time.Since(start) > 5*time.Second {
+				t.Errorf("Run() took %s", time.Since(start))
+			}
+			if len(results) != tt.wantRuns || notified != tt.wantRuns {
+				t.Fatalf("Run() ran %d hooks and notified %d, want %d", len(results), notified, tt.wantRuns)
+			}
+			for i, result := range results {
+				if result.ExitCode != tt.wantCodes[i] {
+					t.Errorf("hook %d exit code = %d, want %d", i, result.ExitCode, tt.wantCodes[i])
+				}
+				if result.Output != tt.wantOut[i] {
+					t.Errorf("hook %d output = %q, want %q", i, result.Output, tt.wantOut[i])
+				}
+			}
+		})
+	}
+}
+
+// TestRunUnknownEvent tests that an unknown event is an error.
+func TestRunUnknownEvent(t *testing.T) {
+	if _, err := (&Runner{}).Run(context.Background(), Operation{Event: "preRemove"}); err == nil {
+		t.Error("Run() error = nil, want an error for an unknown event")
+	}
+}
+
+// TestRunSandboxUnsupported tests that sandboxed hooks do not run unsandboxed.
+func TestRunSandboxUnsupported(t *testing.T) {
+	if platform.SandboxAvailable() {
+		t.Skip("sandboxing is available on this machine")
+	}
+	marker := filepath.Join(t.TempDir(), "ran")
+	enabled := true
+	r := &Runner{Hooks: config.Hooks{PostRestore: []config.Hook{{Command: "echo x > " + marker, Sandbox: &enabled}}}}
+	if _, err := r.Run(context.Background(), Operation{Event: EventPostRestore}); err == nil {
+		t.Error("Run() error = nil, want the sandbox error")
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("sandboxed hook ran without a sandbox")
+	}
+}
+
+// TestTail tests truncating captured output.
+func TestTail(t *testing.T) {
+	if got := tail("short", 10); got != "short" {
+		t.Errorf("tail() = %q, want %q", got, "short")
+	}
+	if got := tail(strings.Repeat("a", 5)+"bcd", 3); got != "[...]bcd" {
+		t.Errorf("tail() = %q, want %q", got, "[...]bcd")
+	}
+}
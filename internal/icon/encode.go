@@ -0,0 +1,159 @@
+package icon
+
+import (
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"strings"
+)
+
+// kittyChunkSize is the largest base64 payload per kitty graphics escape sequence.
+const kittyChunkSize = 4096
+
+// kitty returns a kitty graphics protocol command that transmits and displays a PNG
+// scaled to rows cells tall, without moving the cursor below the image.
+func kitty(data []byte, rows int) string {
+	payload := base64.StdEncoding.EncodeToString(data)
+	var sb strings.Builder
+	for i := 0; i < len(payload); i += kittyChunkSize {
+		chunk := payload[i:min(i+kittyChunkSize, len(payload))]
+		more := 0
+		if i+kittyChunkSize < len(payload) {
+			more = 1
+		}
+		if i == 0 {
+			fmt.Fprintf(&sb, "\x1b_Ga=T,f=100,q=2,c=%d,r=%d,m=%d;%s\x1b\\", rows*2, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&sb, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return sb.String()
+}
+
+// iterm2 returns an iTerm2 inline image sequence showing a PNG rows cells tall.
+func iterm2(data []byte, rows int) string {
+	return fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=1:%s\a",
+		len(data), rows*2, rows, base64.StdEncoding.EncodeToString(data))
+}
+
+// sixel returns a sixel image of img, dithered to the web-safe palette. Transparent
+// pixels are left unpainted so the terminal background shows through.
+func sixel(img image.Image) string {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	pal := color.Palette(palette.WebSafe)
+	paletted := image.NewPaletted(image.Rect(0, 0, width, height), pal)
+	draw.FloydSteinberg.Draw(paletted, paletted.Bounds(), img, bounds.Min)
+
+	opaque := func(x, y int) bool {
+		_, _, _, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+		return a >= 0x8000
+	}
+
+	var sb strings.Builder
+	// P2=1: pixels that are not painted keep the background color
+	fmt.Fprintf(&sb, "\x1bP0;1;0q\"1;1;%d;%d", width, height)
+	used := make([]bool, len(pal))
+	for y := range height {
+		for x := range width {
+			if opaque(x, y) {
+				used[paletted.ColorIndexAt(x, y)] = true
+			}
+		}
+	}
+	for i, c := range pal {
+		if !used[i] {
+			continue
+		}
+		r, g, b, _ := c.RGBA()
+		fmt.Fprintf(&sb, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, b*100/0xffff)
+	}
+
+	for top := 0; top < height; top += 6 {
+		first := true
+		for i := range pal {
+			if !used[i] {
+				continue
+			}
+			// The sixel characters of this color in the band: bit n is row top+n
+			row := make([]byte, width)
+			painted := false
+			for x := range width {
+				var bits byte
+				for n := 0; n < 6 && top+n < height; n++ {
+					if opaque(x, top+n) && int(paletted.ColorIndexAt(x, top+n)) == i {
+						bits |= 1 << n
+					}
+				}
+				row[x] = '?' + bits
+				painted = painted || bits != 0
+			}
+			if !painted {
+				continue
+			}
+			if !first {
+				sb.WriteByte('$') // Back to the start of the band for the next color
+			}
+			first = false
+			fmt.Fprintf(&sb, "#%d", i)
+			writeRuns(&sb, row)
+		}
+		sb.WriteByte('-')
+	}
+	sb.WriteString("\x1b\\")
+	return sb.String()
+}
+
+// writeRuns writes sixel characters, run-length encoding repeats.
+func writeRuns(sb *strings.Builder, row []byte) {
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		if n := j - i; n > 3 {
+			fmt.Fprintf(sb, "!%d%c", n, row[i])
+		} else {
+			sb.Write(row[i:j])
+		}
+		i = j
+	}
+}
+
+// scale returns img resized to fit in a size×size square, averaging the source pixels
+// that fall in each destination pixel. Images already small enough are returned as is.
+func scale(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+	dw, dh := size, size
+	if w > h {
+		dh = max(1, h*size/w)
+	} else if h > w {
+		dw = max(1, w*size/h)
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := range dh {
+		y0, y1 := bounds.Min.Y+y*h/dh, bounds.Min.Y+max((y+1)*h/dh, y*h/dh+1)
+		for x := range dw {
+			x0, x1 := bounds.Min.X+x*w/dw, bounds.Min.X+max((x+1)*w/dw, x*w/dw+1)
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, b, a, n = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			// RGBA() values are premultiplied; Set converts them to the NRGBA model
+			c := color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)}
+			dst.Set(x, y, c)
+		}
+	}
+	return dst
+}
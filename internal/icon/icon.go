@@ -0,0 +1,183 @@
+// Package icon renders package icons in the terminal.
+//
+// Terminals that support an inline image protocol (kitty graphics, iTerm2 inline images,
+// or sixel) show the icon itself; elsewhere the icon is a colored block with the package's
+// initial. Converted images are kept in a cache (see package cache) so each icon is
+// downloaded and converted once.
+package icon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"image"
+	_ "image/gif" // Register decoders for the formats nuget.org accepts
+	_ "image/jpeg"
+	"image/png"
+	"strings"
+	"unicode"
+
+	"github.com/willibrandon/lazynuget/internal/cache"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// Protocol is a terminal inline image protocol.
+type Protocol string
+
+const (
+	ProtocolNone   Protocol = "off"    // No images; icons are colored initials
+	ProtocolKitty  Protocol = "kitty"  // kitty graphics protocol (kitty, Ghostty, WezTerm)
+	ProtocolITerm2 Protocol = "iterm2" // iTerm2 inline images (iTerm2, WezTerm, mintty)
+	ProtocolSixel  Protocol = "sixel"  // DEC sixel graphics (foot, mlterm, xterm -ti vt340)
+)
+
+// cellPixels is the assumed height of a terminal cell, for protocols that draw pixels
+// rather than scaling the image to a number of cells.
+const cellPixels = 16
+
+// ParseProtocol returns the protocol for the packageIcons setting: "auto" detects the
+// terminal from the environment.
+func ParseProtocol(setting string, getenv func(string) string) Protocol {
+	switch p := Protocol(strings.ToLower(setting)); p {
+	case ProtocolNone, ProtocolKitty, ProtocolITerm2, ProtocolSixel:
+		return p
+	default:
+		return Detect(getenv)
+	}
+}
+
+// Detect returns the inline image protocol the terminal supports, judged by the
+// environment variables terminals set, or ProtocolNone when it is unknown.
+func Detect(getenv func(string) string) Protocol {
+	term := strings.ToLower(getenv("TERM"))
+	program := getenv("TERM_PROGRAM")
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || term == "xterm-ghostty" || program == "ghostty":
+		return ProtocolKitty
+	case program == "iTerm.app" || program == "WezTerm" || program == "mintty" || getenv("LC_TERMINAL") == "iTerm2":
+		return ProtocolITerm2
+	case strings.Contains(term, "sixel") || strings.HasPrefix(term, "foot") || strings.HasPrefix(term, "mlterm"):
+		return ProtocolSixel
+	default:
+		return ProtocolNone
+	}
+}
+
+// Renderer turns package icons into text to print at the cursor.
+type Renderer struct {
+	Feed     *nuget.Feed
+	Cache    *cache.Cache // Optional
+	Protocol Protocol
+	Depth    platform.ColorDepth // For the colored initial
+	Rows     int                 // Icon height in cells; icons are twice as wide, as cells are about half as wide as tall
+}
+
+// Render returns an icon for a package version: the image in the renderer's protocol,
+// or the colored initial when the protocol is ProtocolNone or the package has no icon
+// the terminal can show. Download errors are returned along with the initial.
+func (r *Renderer) Render(ctx context.Context, id, version string) (string, error) {
+	if r.Protocol == ProtocolNone {
+		return Initial(id, r.Rows, r.Depth), nil
+	}
+
+	key := fmt.Sprintf("%s/%s/%s/%d", strings.ToLower(id), strings.ToLower(version), r.Protocol, r.Rows)
+	if r.Cache != nil {
+		if data, ok := r.Cache.Get(key); ok {
+			if len(data) == 0 {
+				return Initial(id, r.Rows, r.Depth), nil
+			}
+			return string(data), nil
+		}
+	}
+
+	data, err := r.Feed.Icon(ctx, id, version)
+	if err != nil {
+		return Initial(id, r.Rows, r.Depth), err
+	}
+	var encoded string
+	if img, _, decodeErr := image.Decode(bytes.NewReader(data)); decodeErr == nil {
+		encoded = Encode(img, r.Protocol, r.Rows)
+	}
+	// An empty entry records that the package has no usable icon
+	if r.Cache != nil {
+		_ = r.Cache.Put(key, []byte(encoded))
+	}
+	if encoded == "" {
+		return Initial(id, r.Rows, r.Depth), nil
+	}
+	return encoded, nil
+}
+
+// Encode returns the escape sequence that draws img in the protocol, rows cells tall.
+func Encode(img image.Image, protocol Protocol, rows int) string {
+	rows = max(rows, 1)
+	// Icons are square; downscale large ones so the escape sequence stays small
+	img = scale(img, rows*cellPixels*2)
+
+	switch protocol {
+	case ProtocolKitty:
+		return kitty(encodePNG(img), rows)
+	case ProtocolITerm2:
+		return iterm2(encodePNG(img), rows)
+	case ProtocolSixel:
+		return sixel(scale(img, rows*cellPixels))
+	default:
+		return ""
+	}
+}
+
+// encodePNG encodes an image as PNG, which kitty and iTerm2 both accept.
+func encodePNG(img image.Image) []byte {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+// Initial returns a block rows cells tall and twice as wide, colored by the package ID,
+// with the ID's first letter in its center.
+func Initial(id string, rows int, depth platform.ColorDepth) string {
+	rows = max(rows, 1)
+	width := rows * 2
+
+	letter := "?"
+	for _, c := range id {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			letter = string(unicode.ToUpper(c))
+			break
+		}
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strings.ToLower(id)))
+	sum := h.Sum32()
+	// A dark background from the hash, so the white letter stays readable
+	red, green, blue := 40+sum%120, 40+(sum>>8)%120, 40+(sum>>16)%120
+
+	var start, end string
+	switch depth {
+	case platform.ColorTrueColor:
+		start, end = fmt.Sprintf("\x1b[48;2;%d;%d;%dm\x1b[97m", red, green, blue), "\x1b[0m"
+	case platform.ColorExtended256:
+		start, end = fmt.Sprintf("\x1b[48;5;%dm\x1b[97m", 16+36*(red*6/256)+6*(green*6/256)+blue*6/256), "\x1b[0m"
+	case platform.ColorBasic16:
+		start, end = fmt.Sprintf("\x1b[%dm\x1b[97m", 41+sum%6), "\x1b[0m"
+	default:
+		// Without color, the block is only the letter in brackets
+		return "[" + letter + "]"
+	}
+
+	var sb strings.Builder
+	for row := range rows {
+		if row > 0 {
+			sb.WriteString("\n")
+		}
+		line := strings.Repeat(" ", width)
+		if row == (rows-1)/2 {
+			line = strings.Repeat(" ", (width-1)/2) + letter + strings.Repeat(" ", width/2)
+		}
+		sb.WriteString(start + line + end)
+	}
+	return sb.String()
+}
@@ -0,0 +1,169 @@
+package icon
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/cache"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// TestDetect tests choosing the image protocol from the terminal's environment
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want Protocol
+	}{
+		{map[string]string{"TERM": "xterm-kitty"}, ProtocolKitty},
+		{map[string]string{"TERM": "xterm-256color", "KITTY_WINDOW_ID": "1"}, ProtocolKitty},
+		{map[string]string{"TERM_PROGRAM": "ghostty"}, ProtocolKitty},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app"}, ProtocolITerm2},
+		{map[string]string{"TERM_PROGRAM": "WezTerm"}, ProtocolITerm2},
+		{map[string]string{"TERM": "tmux-256color", "LC_TERMINAL": "iTerm2"}, ProtocolITerm2},
+		{map[string]string{"TERM": "foot"}, ProtocolSixel},
+		{map[string]string{"TERM": "xterm-sixel"}, ProtocolSixel},
+		{map[string]string{"TERM": "xterm-256color"}, ProtocolNone},
+		{map[string]string{}, ProtocolNone},
+	}
+	for _, tt := range tests {
+		if got := Detect(func(k string) string { return tt.env[k] }); got != tt.want {
+			t.Errorf("Detect(%v) = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+
+	kittyEnv := func(k string) string { return map[string]string{"TERM": "xterm-kitty"}[k] }
+	if got := ParseProtocol("auto", kittyEnv); got != ProtocolKitty {
+		t.Errorf("ParseProtocol(auto) = %q, want kitty", got)
+	}
+	if got := ParseProtocol("off", kittyEnv); got != ProtocolNone {
+		t.Errorf("ParseProtocol(off) = %q, want off", got)
+	}
+	if got := ParseProtocol("Sixel", kittyEnv); got != ProtocolSixel {
+		t.Errorf("ParseProtocol(Sixel) = %q, want sixel", got)
+	}
+}
+
+// TestInitial tests the colored initial shown without image support
+func TestInitial(t *testing.T) {
+	if got := Initial("newtonsoft.json", 1, platform.ColorNone); got != "[N]" {
+		t.Errorf("Initial() without color = %q, want [N]", got)
+	}
+	if got := Initial(".NET", 1, platform.ColorNone); got != "[N]" {
+		t.Errorf("Initial() = %q, want the first letter", got)
+	}
+
+	got := Initial("Serilog", 2, platform.ColorTrueColor)
+	lines := strings.Split(got, "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "\x1b[48;2;") || !strings.Contains(lines[0], " S  ") {
+		t.Errorf("Initial() = %q, want two colored lines with S on the first", got)
+	}
+	if Initial("Serilog", 1, platform.ColorTrueColor) == Initial("Dapper", 1, platform.ColorTrueColor) {
+		t.Error("Initial() should color packages differently")
+	}
+	if got := Initial("Serilog", 1, platform.ColorExtended256); !strings.HasPrefix(got, "\x1b[48;5;") {
+		t.Errorf("Initial() with 256 colors = %q", got)
+	}
+}
+
+// testIcon returns a PNG icon: a red square on a transparent background.
+func testIcon(t *testing.T, size int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for y := size / 4; y < size*3/4; y++ {
+		for x := size / 4; x < size*3/4; x++ {
+			img.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestEncode tests the escape sequences of each protocol
+func TestEncode(t *testing.T) {
+	img, _, err := image.Decode(bytes.NewReader(testIcon(t, 128)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kitty := Encode(img, ProtocolKitty, 2)
+	if !strings.HasPrefix(kitty, "\x1b_Ga=T,f=100,q=2,c=4,r=2,") || !strings.HasSuffix(kitty, "\x1b\\") {
+		t.Errorf("kitty = %.40q", kitty)
+	}
+
+	iterm := Encode(img, ProtocolITerm2, 2)
+	if !strings.HasPrefix(iterm, "\x1b]1337;File=inline=1;") || !strings.Contains(iterm, "width=4;height=2") || !strings.HasSuffix(iterm, "\a") {
+		t.Errorf("iterm2 = %.60q", iterm)
+	}
+
+	sixel := Encode(img, ProtocolSixel, 2)
+	if !strings.HasPrefix(sixel, "\x1bP0;1;0q\"1;1;32;32#") || !strings.HasSuffix(sixel, "-\x1b\\") {
+		t.Errorf("sixel = %.60q", sixel)
+	}
+	// Only red is painted; the transparent border is not
+	if strings.Count(sixel, ";2;") != 1 || strings.Count(sixel, "-") != 6 {
+		t.Errorf("sixel = %q, want one color in six bands", sixel)
+	}
+
+	if got := Encode(img, ProtocolNone, 2); got != "" {
+		t.Errorf("Encode(off) = %q, want nothing", got)
+	}
+}
+
+// TestRender tests downloading, caching, and falling back for packages without icons
+func TestRender(t *testing.T) {
+	icon := testIcon(t, 64)
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/serilog/3.1.0/icon":
+			_, _ = w.Write(icon)
+		case "/broken/1.0.0/icon":
+			_, _ = w.Write([]byte("<svg/>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	r := &Renderer{
+		Feed:     &nuget.Feed{BaseURL: server.URL},
+		Cache:    cache.New(t.TempDir(), 0),
+		Protocol: ProtocolKitty,
+		Depth:    platform.ColorNone,
+		Rows:     1,
+	}
+	ctx := context.Background()
+
+	for range 2 {
+		got, err := r.Render(ctx, "Serilog", "3.1.0")
+		if err != nil || !strings.HasPrefix(got, "\x1b_G") {
+			t.Errorf("Render(Serilog) = %.20q, %v; want a kitty image", got, err)
+		}
+		for _, id := range []string{"Missing", "Broken"} {
+			if got, err := r.Render(ctx, id, "1.0.0"); err != nil || got != "["+id[:1]+"]" {
+				t.Errorf("Render(%s) = %q, %v; want the initial", id, got, err)
+			}
+		}
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("server saw %d requests, want 3 (one per package, then cached)", n)
+	}
+
+	r.Protocol = ProtocolNone
+	if got, _ := r.Render(ctx, "Serilog", "3.1.0"); got != "[S]" {
+		t.Errorf("Render() with images off = %q, want [S]", got)
+	}
+}
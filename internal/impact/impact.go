@@ -0,0 +1,196 @@
+// Package impact estimates what adding or updating packages costs a project before the
+// change is made: the packages restore would newly bring in with them, how much it
+// downloads, and how much space they take in the global packages folder.
+package impact
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+)
+
+// extractRatio estimates the space a package's extracted files take in the global
+// packages folder, beside its .nupkg, as a multiple of the .nupkg's size. Assemblies
+// compress to between a third and a half of their size.
+const extractRatio = 2.5
+
+// maxPackages bounds the packages an estimate walks, so that estimating a package with a
+// large dependency graph does not read hundreds of manifests before a prompt.
+const maxPackages = 200
+
+// Source reads the manifests, sizes, and versions of packages; *nuget.Feed implements it.
+type Source interface {
+	Nuspec(ctx context.Context, packagesDir, id, version string) (*nuget.Nuspec, error)
+	PackageSize(ctx context.Context, packagesDir, id, version string) (int64, error)
+	Versions(ctx context.Context, id string) ([]string, error)
+}
+
+// Request is a package version to add or update to.
+type Request struct {
+	ID      string
+	Version string
+}
+
+// Package is a package version the change brings into the project.
+type Package struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+	Size    int64  `json:"size"`          // Bytes of the .nupkg; 0 when unknown
+	Cached  bool   `json:"cached"`        // Already in the global packages folder, so not downloaded
+	Via     string `json:"via,omitempty"` // The package that depends on it; "" for a requested package
+}
+
+// Impact is the estimated cost of a change.
+type Impact struct {
+	Packages  []Package `json:"packages"`
+	Download  int64     `json:"download"`  // Bytes of the packages not cached
+	Disk      int64     `json:"disk"`      // Estimated bytes the packages not cached take once extracted
+	Unknown   int       `json:"unknown"`   // Packages whose size could not be read
+	Truncated bool      `json:"truncated"` // The walk stopped at maxPackages; the estimate is a lower bound
+}
+
+// New returns the number of packages not cached, which restore downloads.
+func (i *Impact) New() int {
+	n := 0
+	for _, p := range i.Packages {
+		if !p.Cached {
+			n++
+		}
+	}
+	return n
+}
+
+// Estimate walks the dependencies of the requested package versions for the project's
+// target frameworks, as restore would, picking the lowest version each dependency
+// allows. resolved holds the versions the project already uses, by lowercase package ID:
+// a dependency already resolved to a version it allows adds nothing. A requested package
+// that cannot be found is an error; sizes that cannot be read are counted as unknown.
+func Estimate(ctx context.Context, src Source, packagesDir string, frameworks []string, resolved map[string]string, requests []Request) (*Impact, error) {
+	var targets []nuget.Framework
+	for _, moniker := range frameworks {
+		if f, err := nuget.ParseFramework(moniker); err == nil {
+			targets = append(targets, f)
+		}
+	}
+
+	impact := &Impact{Packages: []Package{}}
+	seen := make(map[string]bool)
+	queue := make([]Package, 0, len(requests))
+	for _, r := range requests {
+		queue = append(queue, Package{ID: r.ID, Version: r.Version})
+		seen[strings.ToLower(r.ID)] = true
+	}
+	for len(queue) > 0 {
+		if len(impact.Packages) == maxPackages {
+			impact.Truncated = true
+			break
+		}
+		p := queue[0]
+		queue = queue[1:]
+
+		nuspec, err := src.Nuspec(ctx, packagesDir, p.ID, p.Version)
+		if err != nil {
+			if p.Via == "" {
+				return nil, err
+			}
+			// Restore will report it; the estimate goes on without its dependencies
+		}
+		impact.add(ctx, src, packagesDir, p)
+		if nuspec == nil {
+			continue
+		}
+
+		for _, d := range dependencies(nuspec, targets) {
+			key := strings.ToLower(d.ID)
+			if seen[key] || nuget.IsPlatformPackage(d.ID) {
+				continue
+			}
+			seen[key] = true
+			version, ok := lowestAllowed(ctx, src, d)
+			if !ok {
+				continue
+			}
+			if current, ok := resolved[key]; ok && allows(d.Range, current) {
+				continue
+			}
+			queue = append(queue, Package{ID: d.ID, Version: version, Via: p.ID})
+		}
+	}
+	return impact, nil
+}
+
+// add records a package and its size.
+func (i *Impact) add(ctx context.Context, src Source, packagesDir string, p Package) {
+	nupkg := strings.ToLower(p.ID) + "." + strings.ToLower(p.Version) + ".nupkg"
+	if packagesDir != "" {
+		_, err := os.Stat(filepath.Join(nuget.PackageDir(packagesDir, p.ID, p.Version), nupkg))
+		p.Cached = err == nil
+	}
+	size, err := src.PackageSize(ctx, packagesDir, p.ID, p.Version)
+	switch {
+	case err != nil || size <= 0:
+		if !p.Cached {
+			i.Unknown++
+		}
+	case !p.Cached:
+		p.Size = size
+		i.Download += size
+		i.Disk += size + int64(float64(size)*extractRatio)
+	default:
+		p.Size = size
+	}
+	i.Packages = append(i.Packages, p)
+}
+
+// dependencies returns the dependencies of a package for any of the target frameworks,
+// each once; without targets, those of every group.
+func dependencies(nuspec *nuget.Nuspec, targets []nuget.Framework) []nuget.Dependency {
+	var groups [][]nuget.Dependency
+	if len(targets) == 0 {
+		for _, g := range nuspec.DependencyGroups {
+			groups = append(groups, g.Dependencies)
+		}
+	}
+	for _, t := range targets {
+		groups = append(groups, nuspec.DependenciesFor(t))
+	}
+
+	var deps []nuget.Dependency
+	seen := make(map[string]bool)
+	for _, group := range groups {
+		for _, d := range group {
+			if key := strings.ToLower(d.ID); !seen[key] {
+				seen[key] = true
+				deps = append(deps, d)
+			}
+		}
+	}
+	return deps
+}
+
+// lowestAllowed returns the version restore picks for a dependency: its inclusive minimum,
+// or the lowest version on the feed that its range allows.
+func lowestAllowed(ctx context.Context, src Source, d nuget.Dependency) (string, bool) {
+	r, err := nuget.ParseVersionRange(d.Range)
+	if err != nil {
+		r = nuget.VersionRange{} // No range allows any version
+	}
+	if r.Min != "" && r.MinInclusive && !r.IsFloating() {
+		return r.Min, true
+	}
+	versions, err := src.Versions(ctx, d.ID)
+	if err != nil {
+		return "", false
+	}
+	return r.Resolve(versions)
+}
+
+// allows reports whether a dependency's range allows a version the project resolves; an
+// empty or invalid range allows any.
+func allows(dependencyRange, version string) bool {
+	r, err := nuget.ParseVersionRange(dependencyRange)
+	return err != nil || r.WithinBounds(version)
+}
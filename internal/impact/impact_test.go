@@ -0,0 +1,91 @@
+package impact
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+)
+
+// fakeSource serves fixed manifests, sizes, and versions.
+type fakeSource struct {
+	nuspecs  map[string]*nuget.Nuspec // By "id/version"
+	sizes    map[string]int64
+	versions map[string][]string
+}
+
+func (f fakeSource) Nuspec(_ context.Context, _, id, version string) (*nuget.Nuspec, error) {
+	if n, ok := f.nuspecs[id+"/"+version]; ok {
+		return n, nil
+	}
+	return nil, nuget.ErrVersionNotFound
+}
+
+func (f fakeSource) PackageSize(_ context.Context, _, id, version string) (int64, error) {
+	return f.sizes[id+"/"+version], nil
+}
+
+func (f fakeSource) Versions(_ context.Context, id string) ([]string, error) {
+	return f.versions[id], nil
+}
+
+// TestEstimate tests walking the new dependencies of a package and adding up their sizes
+func TestEstimate(t *testing.T) {
+	group := func(framework string, deps ...nuget.Dependency) nuget.DependencyGroup {
+		return nuget.DependencyGroup{Framework: framework, Dependencies: deps}
+	}
+	src := fakeSource{
+		nuspecs: map[string]*nuget.Nuspec{
+			"Web.Client/2.0.0": {DependencyGroups: []nuget.DependencyGroup{
+				group("net8.0", nuget.Dependency{ID: "Web.Core", Range: "[2.0.0, )"}, nuget.Dependency{ID: "Json", Range: "13.0.1"},
+					nuget.Dependency{ID: "Microsoft.NETCore.App", Range: "8.0.0"}),
+				group("netstandard2.0", nuget.Dependency{ID: "Legacy.Shim", Range: "1.0.0"}),
+			}},
+			"Web.Core/2.0.0": {DependencyGroups: []nuget.DependencyGroup{
+				group("", nuget.Dependency{ID: "Logging", Range: "(1.0.0, 2.0.0)"}, nuget.Dependency{ID: "Json", Range: "12.0.0"}),
+			}},
+			"Logging/1.2.0": {},
+		},
+		sizes:    map[string]int64{"Web.Client/2.0.0": 100, "Web.Core/2.0.0": 200, "Logging/1.2.0": 300, "Json/13.0.1": 999},
+		versions: map[string][]string{"Logging": {"1.0.0", "1.2.0", "1.5.0", "2.0.0"}},
+	}
+	packagesDir := t.TempDir()
+	cached := nuget.PackageDir(packagesDir, "Logging", "1.2.0")
+	if err := os.MkdirAll(cached, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cached, "logging.1.2.0.nupkg"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The project already resolves Json 13.0.3, which both ranges allow
+	got, err := Estimate(context.Background(), src, packagesDir, []string{"net8.0"}, map[string]string{"json": "13.0.3"},
+		[]Request{{ID: "Web.Client", Version: "2.0.0"}})
+	if err != nil {
+		t.Fatalf("Estimate() error = %v", err)
+	}
+	var packages []string
+	for _, p := range got.Packages {
+		packages = append(packages, p.ID+"/"+p.Version+"<"+p.Via)
+	}
+	if want := "Web.Client/2.0.0< Web.Core/2.0.0<Web.Client Logging/1.2.0<Web.Core"; strings.Join(packages, " ") != want {
+		t.Errorf("Packages = %v, want %s", packages, want)
+	}
+	if got.Download != 300 || got.Disk != 300+750 || got.New() != 2 || got.Unknown != 0 || got.Truncated {
+		t.Errorf("Estimate() = %+v", got)
+	}
+
+	// Without Json resolved, it is new; its size is unknown when the feed has none
+	src.sizes["Json/13.0.1"] = 0
+	got, err = Estimate(context.Background(), src, "", []string{"net8.0"}, nil, []Request{{ID: "Web.Client", Version: "2.0.0"}})
+	if err != nil || len(got.Packages) != 4 || got.Unknown != 1 || got.Download != 600 {
+		t.Errorf("Estimate() without resolved versions = %+v, %v", got, err)
+	}
+
+	if _, err := Estimate(context.Background(), src, "", nil, nil, []Request{{ID: "Missing", Version: "1.0.0"}}); err == nil {
+		t.Error("Estimate() of a missing package succeeded")
+	}
+}
@@ -0,0 +1,383 @@
+// Package instance keeps two LazyNuGet sessions from editing the same workspace at once.
+//
+// A session takes a lock file for its workspace (the enclosing repository) at startup.
+// The file records the owner's PID, host, and a timestamp refreshed while the session
+// runs, so a lock left behind by a crashed session is detected as stale and replaced.
+package instance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+const (
+	// LockDirName is the directory under the cache directory that holds workspace locks.
+	LockDirName = "locks"
+
+	// RefreshInterval is how often a running session updates its lock's timestamp.
+	RefreshInterval = 30 * time.Second
+
+	// StaleAfter is how old a lock's timestamp may get before the lock is considered
+	// abandoned, even if its PID is in use (PIDs are reused, and locks from other hosts
+	// on a shared filesystem cannot be checked by PID).
+	StaleAfter = 2 * time.Minute
+)
+
+// Info describes the session holding a workspace lock. It is the lock file's content.
+type Info struct {
+	StartedAt time.Time `json:"startedAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Workspace string    `json:"workspace"`
+	Hostname  string    `json:"hostname"`
+	PID       int       `json:"pid"`
+}
+
+// Stale reports whether the lock's owner is gone: its process no longer runs on this
+// host, or it has not refreshed the lock within StaleAfter.
+func (i Info) Stale(now time.Time) bool {
+	if now.Sub(i.UpdatedAt) > StaleAfter {
+		return true
+	}
+	if hostname, _ := os.Hostname(); i.Hostname == hostname {
+		return !processAlive(i.PID)
+	}
+	return false
+}
+
+// HeldError is returned by Acquire when a live session already holds the workspace lock.
+type HeldError struct {
+	Path   string
+	Holder Info
+}
+
+func (e *HeldError) Error() string {
+	return fmt.Sprintf("another lazynuget instance (PID %d on %s, started %s) is already open in %s",
+		e.Holder.PID, e.Holder.Hostname, e.Holder.StartedAt.Local().Format(time.DateTime), e.Holder.Workspace)
+}
+
+// Lock is a held workspace lock. Release it on shutdown.
+type Lock struct {
+	stop        chan struct{}
+	done        chan struct{}
+	path        string
+	info        Info
+	releaseOnce sync.Once
+	mu          sync.Mutex
+}
+
+// WorkspaceRoot returns the workspace containing dir: the nearest ancestor with a .git
+// entry, or dir itself when it is not inside a repository.
+func WorkspaceRoot(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("invalid working directory: %w", err)
+	}
+
+	for candidate := abs; ; {
+		if _, statErr := os.Stat(filepath.Join(candidate, ".git")); statErr == nil {
+			return candidate, nil
+		}
+		parent := filepath.Dir(candidate)
+		if parent == candidate {
+			return abs, nil
+		}
+		candidate = parent
+	}
+}
+
+// DefaultLockDir returns the platform lock directory (<cache dir>/locks), with the cache
+// directory's temp fallback applied (see platform.AppDir).
+func DefaultLockDir() string {
+	return platform.AppDir(platform.DirCache, LockDirName)
+}
+
+// LockPath returns the lock file for a workspace. Locks live outside the workspace so
+// they never show up in the repository.
+func LockPath(lockDir, workspace string) string {
+	sum := sha256.Sum256([]byte(filepath.Clean(workspace)))
+	return filepath.Join(lockDir, hex.EncodeToString(sum[:8])+".lock")
+}
+
+// Acquire takes the lock for workspace. Stale locks are replaced. If a live session
+// holds the lock, Acquire returns a *HeldError unless force is set, in which case the
+// lock is taken over (the escape hatch for a lock Acquire cannot prove stale).
+func Acquire(lockDir, workspace string, force bool) (*Lock, error) {
+	if err := os.MkdirAll(lockDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+	now := time.Now()
+	l := &Lock{
+		path: LockPath(lockDir, workspace),
+		info: Info{
+			PID:       os.Getpid(),
+			Hostname:  hostname,
+			Workspace: workspace,
+			StartedAt: now,
+			UpdatedAt: now,
+		},
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	// Lock files are only created, replaced, and removed under the guard, so two sessions
+	// finding the same stale lock cannot both replace it
+	unlock, err := l.guard()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Two attempts: the second follows removing a stale or forcibly released lock
+	for range 2 {
+		err := l.create()
+		if err == nil {
+			go l.refresh()
+			return l, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+
+		holder, readErr := Read(l.path)
+		if readErr == nil && !force && !holder.Stale(time.Now()) {
+			return nil, &HeldError{Path: l.path, Holder: holder}
+		}
+
+		// Stale, unreadable (locks are written atomically, so this is garbage), or forced
+		if err := os.Remove(l.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove stale lock %s: %w", l.path, err)
+		}
+	}
+
+	return nil, fmt.Errorf("failed to acquire lock %s: another instance is starting in %s", l.path, workspace)
+}
+
+// holds counts the Hold calls running in this process, by lock path.
+var holds = struct {
+	m  map[string]*hold
+	mu sync.Mutex
+}{m: map[string]*hold{}}
+
+type hold struct {
+	lock *Lock // nil when the lock was already this process's (an interactive session)
+	n    int
+}
+
+// Hold runs fn while holding the lock of workspace, so edits made by subcommands and the
+// daemon cannot interleave with an interactive session's or each other's. The lock is
+// shared by concurrent Hold calls and reused when this process already holds it. Returns
+// a *HeldError, without running fn, if another live session holds it. An empty lockDir
+// runs fn unlocked.
+func Hold(lockDir, workspace string, fn func() error) error {
+	if lockDir == "" {
+		return fn()
+	}
+	path := LockPath(lockDir, workspace)
+
+	holds.mu.Lock()
+	h := holds.m[path]
+	if h == nil {
+		h = &hold{}
+		if holder, err := Read(path); err != nil || !holder.thisProcess() {
+			lock, err := Acquire(lockDir, workspace, false)
+			if err != nil {
+				holds.mu.Unlock()
+				return err
+			}
+			h.lock = lock
+		}
+		holds.m[path] = h
+	}
+	h.n++
+	holds.mu.Unlock()
+
+	err := fn()
+
+	holds.mu.Lock()
+	defer holds.mu.Unlock()
+	if h.n--; h.n == 0 {
+		delete(holds.m, path)
+		if h.lock != nil {
+			if releaseErr := h.lock.Release(); releaseErr != nil && err == nil {
+				err = releaseErr
+			}
+		}
+	}
+	return err
+}
+
+// thisProcess reports whether the lock is held by the calling process.
+func (i Info) thisProcess() bool {
+	hostname, _ := os.Hostname()
+	return i.PID == os.Getpid() && i.Hostname == hostname
+}
+
+// Read returns the holder recorded in a lock file.
+func Read(path string) (Info, error) {
+	// #nosec G304 -- path is a lock file in the user's cache directory
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Info{}, err
+	}
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return Info{}, fmt.Errorf("invalid lock file %s: %w", path, err)
+	}
+	return info, nil
+}
+
+// Path returns the lock file path.
+func (l *Lock) Path() string {
+	return l.path
+}
+
+// Release stops refreshing the lock and removes it, unless another session has since
+// taken it over with --force-unlock. Safe to call more than once.
+func (l *Lock) Release() error {
+	var err error
+	l.releaseOnce.Do(func() {
+		close(l.stop)
+		<-l.done
+
+		unlock, guardErr := l.guard()
+		if guardErr != nil {
+			err = guardErr
+			return
+		}
+		defer unlock()
+		if !l.owned() {
+			return
+		}
+		if removeErr := os.Remove(l.path); removeErr != nil && !errors.Is(removeErr, fs.ErrNotExist) {
+			err = fmt.Errorf("failed to remove lock %s: %w", l.path, removeErr)
+		}
+	})
+	return err
+}
+
+// create writes the lock file atomically: the content goes to a temporary file that is
+// then hard-linked into place, which fails if the lock already exists.
+func (l *Lock) create() error {
+	tmp, err := l.writeTemp()
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	if err := os.Link(tmp, l.path); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return fs.ErrExist
+		}
+		return fmt.Errorf("failed to create lock %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// refresh updates the lock's timestamp every RefreshInterval until Release.
+func (l *Lock) refresh() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			if !l.touch() {
+				return // Taken over with --force-unlock
+			}
+		}
+	}
+}
+
+// touch rewrites the lock with a fresh timestamp unless another session has taken it
+// over, and reports whether the lock is still this session's.
+func (l *Lock) touch() bool {
+	unlock, err := l.guard()
+	if err != nil {
+		return true // Try again on the next tick
+	}
+	defer unlock()
+	if !l.owned() {
+		return false
+	}
+	l.mu.Lock()
+	l.info.UpdatedAt = time.Now()
+	l.mu.Unlock()
+	if tmp, err := l.writeTemp(); err == nil {
+		if err := os.Rename(tmp, l.path); err != nil {
+			_ = os.Remove(tmp)
+		}
+	}
+	return true
+}
+
+// guard takes an OS file lock on <lock>.guard, held while the lock file is created,
+// replaced, or removed, and returns the function that releases it. The OS drops the file
+// lock when the process exits, so a crash never leaves the guard held; the guard file
+// itself stays in place.
+func (l *Lock) guard() (func(), error) {
+	// #nosec G304 -- path is next to a lock file in the user's cache directory
+	f, err := os.OpenFile(l.path+".guard", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock guard: %w", err)
+	}
+	if err := platform.LockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", f.Name(), err)
+	}
+	return func() {
+		_ = platform.UnlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// owned reports whether the lock file still belongs to this session.
+func (l *Lock) owned() bool {
+	holder, err := Read(l.path)
+	if err != nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return holder.PID == l.info.PID && holder.Hostname == l.info.Hostname && holder.StartedAt.Equal(l.info.StartedAt)
+}
+
+// writeTemp writes the lock's current info to a temporary file next to the lock.
+func (l *Lock) writeTemp() (string, error) {
+	l.mu.Lock()
+	data, err := json.MarshalIndent(l.info, "", "  ")
+	l.mu.Unlock()
+	if err != nil {
+		return "", fmt.Errorf("failed to encode lock: %w", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(l.path), ".lock-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to write lock: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write lock: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write lock: %w", err)
+	}
+	return f.Name(), nil
+}
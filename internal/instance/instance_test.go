@@ -0,0 +1,269 @@
+package instance
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeLock writes a lock file for workspace with the given holder.
+func writeLock(t *testing.T, lockDir, workspace string, holder Info) string {
+	t.Helper()
+	path := LockPath(lockDir, workspace)
+	data, err := json.Marshal(holder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// exitedPID returns the PID of a process that has already exited.
+func exitedPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to run helper process: %v", err)
+	}
+	return cmd.Process.Pid
+}
+
+// TestAcquireRelease tests that a second session is refused until the first releases
+func TestAcquireRelease(t *testing.T) {
+	lockDir := t.TempDir()
+	workspace := t.TempDir()
+
+	lock, err := Acquire(lockDir, workspace, false)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	holder, err := Read(lock.Path())
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if holder.PID != os.Getpid() || holder.Workspace != workspace || holder.StartedAt.IsZero() {
+		t.Errorf("lock holder = %+v, want this process in %s", holder, workspace)
+	}
+
+	_, err = Acquire(lockDir, workspace, false)
+	var held *HeldError
+	if !errors.As(err, &held) {
+		t.Fatalf("second Acquire() error = %v, want *HeldError", err)
+	}
+	if held.Holder.PID != os.Getpid() {
+		t.Errorf("HeldError.Holder.PID = %d, want %d", held.Holder.PID, os.Getpid())
+	}
+
+	// Other workspaces are independent
+	other, err := Acquire(lockDir, t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("Acquire(other workspace) error = %v", err)
+	}
+	defer other.Release()
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Errorf("second Release() error = %v", err)
+	}
+	if _, err := os.Stat(lock.Path()); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("lock file still exists after Release(): %v", err)
+	}
+
+	again, err := Acquire(lockDir, workspace, false)
+	if err != nil {
+		t.Fatalf("Acquire() after Release() error = %v", err)
+	}
+	again.Release()
+}
+
+// TestAcquireStaleLock tests that abandoned locks are replaced
+func TestAcquireStaleLock(t *testing.T) {
+	hostname, _ := os.Hostname()
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		holder  Info
+		raw     string
+		wantErr bool
+	}{
+		{
+			name:   "dead PID on this host",
+			holder: Info{PID: exitedPID(t), Hostname: hostname, StartedAt: now, UpdatedAt: now},
+		},
+		{
+			name:   "timestamp too old",
+			holder: Info{PID: os.Getpid(), Hostname: hostname, StartedAt: now.Add(-time.Hour), UpdatedAt: now.Add(-StaleAfter - time.Minute)},
+		},
+		{
+			name: "corrupt lock file",
+			raw:  "not json",
+		},
+		{
+			name:    "live PID on this host",
+			holder:  Info{PID: os.Getpid(), Hostname: hostname, StartedAt: now, UpdatedAt: now},
+			wantErr: true,
+		},
+		{
+			name:    "recent lock from another host",
+			holder:  Info{PID: exitedPID(t), Hostname: hostname + "-other", StartedAt: now, UpdatedAt: now},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lockDir := t.TempDir()
+			workspace := t.TempDir()
+			if tt.raw != "" {
+				if err := os.WriteFile(LockPath(lockDir, workspace), []byte(tt.raw), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				writeLock(t, lockDir, workspace, tt.holder)
+			}
+
+			lock, err := Acquire(lockDir, workspace, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Acquire() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if lock != nil {
+				lock.Release()
+			}
+		})
+	}
+}
+
+// TestForceUnlock tests taking over a live lock, after which the old owner leaves it alone
+func TestForceUnlock(t *testing.T) {
+	lockDir := t.TempDir()
+	workspace := t.TempDir()
+
+	first, err := Acquire(lockDir, workspace, false)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	// Distinguish the two sessions of this process
+	time.Sleep(10 * time.Millisecond)
+
+	second, err := Acquire(lockDir, workspace, true)
+	if err != nil {
+		t.Fatalf("Acquire(force) error = %v", err)
+	}
+	defer second.Release()
+
+	if err := first.Release(); err != nil {
+		t.Fatalf("Release() of replaced lock error = %v", err)
+	}
+	if _, err := os.Stat(second.Path()); err != nil {
+		t.Errorf("forced lock was removed by its previous owner: %v", err)
+	}
+}
+
+// TestWorkspaceRoot tests that the workspace is the enclosing repository
+func TestWorkspaceRoot(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	nested := filepath.Join(repo, "src", "App")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := WorkspaceRoot(nested); err != nil || got != repo {
+		t.Errorf("WorkspaceRoot(nested) = %q, %v; want %q", got, err, repo)
+	}
+
+	if LockPath("locks", repo) != LockPath("locks", repo+string(filepath.Separator)) {
+		t.Error("LockPath() should not depend on a trailing separator")
+	}
+}
+
+// TestAcquireStaleLockOnce tests that only one of several sessions replacing the same
+// stale lock gets it
+func TestAcquireStaleLockOnce(t *testing.T) {
+	lockDir := t.TempDir()
+	workspace := t.TempDir()
+	hostname, _ := os.Hostname()
+	now := time.Now()
+	writeLock(t, lockDir, workspace, Info{PID: exitedPID(t), Hostname: hostname, StartedAt: now, UpdatedAt: now})
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		locks []*Lock
+	)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if lock, err := Acquire(lockDir, workspace, false); err == nil {
+				mu.Lock()
+				locks = append(locks, lock)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(locks) != 1 {
+		t.Fatalf("Acquire() of a stale lock succeeded %d times, want 1", len(locks))
+	}
+	locks[0].Release()
+}
+
+// TestHold tests that Hold locks the workspace around fn, reuses this process's lock, and
+// refuses while another session holds it
+func TestHold(t *testing.T) {
+	lockDir := t.TempDir()
+	workspace := t.TempDir()
+	path := LockPath(lockDir, workspace)
+
+	err := Hold(lockDir, workspace, func() error {
+		if _, err := Read(path); err != nil {
+			t.Errorf("lock not held inside Hold: %v", err)
+		}
+		// Nested and concurrent holds share the lock
+		return Hold(lockDir, workspace, func() error { return nil })
+	})
+	if err != nil {
+		t.Fatalf("Hold() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("lock still exists after Hold returned")
+	}
+
+	// An interactive session of this process already holds it
+	lock, err := Acquire(lockDir, workspace, false)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if err := Hold(lockDir, workspace, func() error { return nil }); err != nil {
+		t.Errorf("Hold() under this process's lock error = %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Hold() removed the session's lock: %v", err)
+	}
+	lock.Release()
+
+	hostname, _ := os.Hostname()
+	now := time.Now()
+	writeLock(t, lockDir, workspace, Info{PID: 1, Hostname: hostname + "-other", StartedAt: now, UpdatedAt: now})
+	ran := false
+	err = Hold(lockDir, workspace, func() error { ran = true; return nil })
+	var held *HeldError
+	if !errors.As(err, &held) || ran {
+		t.Errorf("Hold() under another session's lock = %v (ran %v), want *HeldError without running", err, ran)
+	}
+}
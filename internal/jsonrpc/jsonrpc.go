@@ -0,0 +1,183 @@
+// Package jsonrpc implements JSON-RPC 2.0 over a stream with one message per line, as used
+// by plugins (package plugin) and by `lazynuget --serve`.
+package jsonrpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Version is the value of the jsonrpc member of every message.
+const Version = "2.0"
+
+// MaxMessageSize bounds one message.
+const MaxMessageSize = 4 << 20
+
+// Error codes defined by JSON-RPC 2.0, and the one used for failed operations.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	CodeServerError    = -32000 // The method ran and failed (e.g., a project could not be written)
+)
+
+// Message is a request, response, or notification (a request without an ID).
+type Message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int            `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is an error response.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// Error implements the error interface for Error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// InvalidParams returns an error for parameters a method cannot accept.
+func InvalidParams(format string, args ...any) *Error {
+	return &Error{Code: CodeInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// DecodeParams decodes a request's parameters into v. Missing parameters leave v unchanged.
+func DecodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return InvalidParams("invalid params: %v", err)
+	}
+	return nil
+}
+
+// HandlerFunc handles a request and returns its result. Returning an *Error sets the
+// response's code; other errors are reported as CodeServerError.
+type HandlerFunc func(ctx context.Context, params json.RawMessage) (any, error)
+
+// Server dispatches requests read from a stream to handlers, one at a time.
+type Server struct {
+	handlers map[string]HandlerFunc
+}
+
+// NewServer returns a server without methods. Every server answers shutdown, which ends
+// Serve after the response.
+func NewServer() *Server {
+	return &Server{handlers: make(map[string]HandlerFunc)}
+}
+
+// Handle registers the handler for a method.
+func (s *Server) Handle(method string, h HandlerFunc) {
+	s.handlers[method] = h
+}
+
+// Serve reads requests from r and writes responses to w until r ends, a shutdown request
+// is answered, or ctx is done. Requests are handled in order, so a client may pipeline them.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64<<10), MaxMessageSize)
+		for scanner.Scan() {
+			line := append([]byte(nil), scanner.Bytes()...)
+			select {
+			case lines <- line:
+			case <-done:
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	write := func(msg Message) error {
+		msg.JSONRPC = Version
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}
+
+	for {
+		var line []byte
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			return err
+		case line = <-lines:
+		}
+		if len(line) == 0 {
+			continue
+		}
+
+		var req Message
+		if err := json.Unmarshal(line, &req); err != nil {
+			if err := write(Message{Error: &Error{Code: CodeParseError, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "" {
+			if err := write(Message{ID: req.ID, Error: &Error{Code: CodeInvalidRequest, Message: "missing method"}}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if req.Method == "shutdown" {
+			if req.ID != nil {
+				return write(Message{ID: req.ID, Result: json.RawMessage("null")})
+			}
+			return nil
+		}
+
+		resp := s.call(ctx, req)
+		if req.ID == nil {
+			continue // Notifications get no response
+		}
+		resp.ID = req.ID
+		if err := write(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// call runs the handler for a request.
+func (s *Server) call(ctx context.Context, req Message) Message {
+	h, ok := s.handlers[req.Method]
+	if !ok {
+		return Message{Error: &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("unknown method %q", req.Method)}}
+	}
+	result, err := h(ctx, req.Params)
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeServerError, Message: err.Error()}
+		}
+		return Message{Error: rpcErr}
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return Message{Error: &Error{Code: CodeInternalError, Message: err.Error()}}
+	}
+	return Message{Result: data}
+}
@@ -0,0 +1,88 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// TestServe tests responses to requests, notifications, and malformed input, and that
+// shutdown ends the session
+func TestServe(t *testing.T) {
+	s := NewServer()
+	notified := 0
+	s.Handle("add", func(_ context.Context, params json.RawMessage) (any, error) {
+		var p struct{ A, B int }
+		if err := DecodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if p.A < 0 {
+			return nil, InvalidParams("a must not be negative")
+		}
+		return p.A + p.B, nil
+	})
+	s.Handle("notify", func(context.Context, json.RawMessage) (any, error) {
+		notified++
+		return nil, nil
+	})
+	s.Handle("fail", func(context.Context, json.RawMessage) (any, error) {
+		return nil, errors.New("disk full")
+	})
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"add","params":{"a":2,"b":3}}`,
+		`{"jsonrpc":"2.0","method":"notify"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"add","params":{"a":-1}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"add","params":"text"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"fail"}`,
+		`{"jsonrpc":"2.0","id":5,"method":"search"}`,
+		`not json`,
+		``,
+		`{"jsonrpc":"2.0","id":6}`,
+		`{"jsonrpc":"2.0","id":7,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","id":8,"method":"add","params":{"a":1,"b":1}}`,
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := s.Serve(context.Background(), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	want := []string{
+		`{"jsonrpc":"2.0","id":1,"result":5}`,
+		`{"jsonrpc":"2.0","id":2,"error":{"code":-32602,"message":"a must not be negative"}}`,
+		`{"jsonrpc":"2.0","id":3,"error":{"code":-32602,"message":"invalid params: json: cannot unmarshal string into Go value of type struct { A int; B int }"}}`,
+		`{"jsonrpc":"2.0","id":4,"error":{"code":-32000,"message":"disk full"}}`,
+		`{"jsonrpc":"2.0","id":5,"error":{"code":-32601,"message":"unknown method \"search\""}}`,
+		`{"jsonrpc":"2.0","error":{"code":-32700,"message":"invalid character 'o' in literal null (expecting 'u')"}}`,
+		`{"jsonrpc":"2.0","id":6,"error":{"code":-32600,"message":"missing method"}}`,
+		`{"jsonrpc":"2.0","id":7,"result":null}`,
+	}
+	got := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(got) != len(want) {
+		t.Fatalf("responses =\n%s\nwant\n%s", out.String(), strings.Join(want, "\n"))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("response %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+	if notified != 1 {
+		t.Errorf("notify handled %d times, want 1", notified)
+	}
+}
+
+// TestServeCancel tests that Serve returns when its context is done
+func TestServeCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r, w := io.Pipe()
+	defer w.Close()
+	if err := NewServer().Serve(ctx, r, io.Discard); !errors.Is(err, context.Canceled) {
+		t.Errorf("Serve() error = %v, want context.Canceled", err)
+	}
+}
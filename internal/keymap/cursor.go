@@ -0,0 +1,73 @@
+package keymap
+
+// Cursor is the selected row of a list panel and, in visual mode, where the selection
+// started.
+type Cursor struct {
+	Index  int
+	Anchor int // Row visual mode started on; -1 outside visual mode
+}
+
+// NewCursor returns a cursor on the first row, outside visual mode.
+func NewCursor() Cursor {
+	return Cursor{Anchor: -1}
+}
+
+// Apply moves the cursor for a navigation command in a list of length rows, height of
+// which fit the panel, and reports whether the command was one. Counts repeat moves;
+// with top and bottom they pick the row, as 5gg and 5G do in vim. visual starts or ends
+// the selection.
+func (c *Cursor) Apply(cmd Command, length, height int) bool {
+	height = max(height, 1)
+	switch cmd.Action {
+	case "up":
+		c.Index -= cmd.Times()
+	case "down":
+		c.Index += cmd.Times()
+	case "page_up":
+		c.Index -= cmd.Times() * height
+	case "page_down":
+		c.Index += cmd.Times() * height
+	case "half_page_up":
+		c.Index -= cmd.Times() * max(height/2, 1)
+	case "half_page_down":
+		c.Index += cmd.Times() * max(height/2, 1)
+	case "top":
+		c.Index = max(cmd.Count, 1) - 1
+	case "bottom":
+		c.Index = length - 1
+		if cmd.Count > 0 {
+			c.Index = cmd.Count - 1
+		}
+	case "visual":
+		if c.Anchor < 0 {
+			c.Anchor = c.Index
+		} else {
+			c.Anchor = -1
+		}
+	default:
+		return false
+	}
+	c.Index = max(min(c.Index, length-1), 0)
+	if c.Anchor >= length {
+		c.Anchor = max(length-1, 0)
+	}
+	return true
+}
+
+// Selection returns the first and last rows selected: the rows between the anchor and
+// the cursor in visual mode, and the cursor's row outside it.
+func (c Cursor) Selection() (first, last int) {
+	if c.Anchor < 0 {
+		return c.Index, c.Index
+	}
+	return min(c.Anchor, c.Index), max(c.Anchor, c.Index)
+}
+
+// Mark is a place set_mark remembered: a panel and its selected row.
+type Mark struct {
+	Panel string
+	Index int
+}
+
+// Marks holds the marks set, by letter. Like vim's, they last until the program exits.
+type Marks map[string]Mark
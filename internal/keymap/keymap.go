@@ -0,0 +1,322 @@
+// Package keymap maps keys to UI actions.
+//
+// The keymap starts from the keybindingProfile (default, vim, or emacs), then applies the
+// keybindings setting and the keys of custom commands. A binding is a key or a sequence of
+// keys pressed one after the other, a chord ("g g", "space u a"); while a chord is under
+// way the Matcher holds the keys typed so far, for the status bar to show. Bindings in a
+// panel's context take precedence over global ones.
+package keymap
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+)
+
+// Kind is the JSON document kind of `lazynuget keys list --json`.
+const Kind = "keys"
+
+// Global is the context of bindings that work in every panel.
+const Global = "global"
+
+// CustomActionPrefix starts the action of a custom command's key ("custom:why").
+const CustomActionPrefix = "custom:"
+
+// Action is something a key can do.
+type Action struct {
+	Name        string
+	Context     string // Where its default keys work
+	Description string
+}
+
+// Actions are the built-in actions, in the order help lists them.
+var Actions = []Action{
+	{"help", Global, "Show keybindings"},
+	{"quit", Global, "Quit"},
+	{"force_quit", Global, "Quit without confirming"},
+	{"refresh", Global, "Refresh all"},
+	{"search", Global, "Search packages"},
+	{"filter", Global, "Filter the list"},
+	{"next_panel", Global, "Focus the next panel"},
+	{"prev_panel", Global, "Focus the previous panel"},
+	{"back", Global, "Close the dialog or go back"},
+	{"up", Global, "Move up"},
+	{"down", Global, "Move down"},
+	{"left", Global, "Collapse or previous tab"},
+	{"right", Global, "Expand or next tab"},
+	{"top", Global, "Go to the top"},
+	{"bottom", Global, "Go to the bottom"},
+	{"page_up", Global, "Page up"},
+	{"page_down", Global, "Page down"},
+	{"half_page_up", Global, "Scroll up half a page"},
+	{"half_page_down", Global, "Scroll down half a page"},
+	{"visual", Global, "Start or end a visual selection"},
+	{"set_mark", Global, "Mark the panel and row (then a letter)"},
+	{"jump_mark", Global, "Go to a mark (then its letter)"},
+	{"record_macro", Global, "Start or stop recording a macro"},
+	{"replay_macro", Global, "Replay a macro"},
+	{"notes", Global, "Show the workspace's notes"},
+	{"select", Global, "Open the selection"},
+	{"toggle", "projects", "Expand or collapse a folder"},
+	{"add", "packages", "Add a package"},
+	{"update", "packages", "Update the package"},
+	{"update_all", "packages", "Update every outdated package in the project"},
+	{"remove", "packages", "Remove the package"},
+	{"install_version", "packages", "Install a specific version"},
+	{"versions", "packages", "Show the versions"},
+	{"why", "packages", "Show why the package is referenced"},
+	{"sort", "packages", "Cycle the sort order"},
+}
+
+// profiles holds the default keys of each keybinding profile. vim and emacs change only
+// the actions listed; the rest keep the default profile's keys.
+var profiles = map[string]map[string][]string{
+	"default": {
+		"help":            {"?"},
+		"quit":            {"q"},
+		"force_quit":      {"ctrl+c"},
+		"refresh":         {"r"},
+		"search":          {"/"},
+		"filter":          {"f"},
+		"next_panel":      {"tab"},
+		"prev_panel":      {"backtab"},
+		"back":            {"esc"},
+		"up":              {"up", "k"},
+		"down":            {"down", "j"},
+		"left":            {"left", "h"},
+		"right":           {"right", "l"},
+		"top":             {"home", "g"},
+		"bottom":          {"end", "G"},
+		"page_up":         {"pgup", "ctrl+u"},
+		"page_down":       {"pgdown", "ctrl+d"},
+		"notes":           {"N"},
+		"select":          {"enter"},
+		"toggle":          {"space"},
+		"add":             {"a"},
+		"update":          {"u"},
+		"update_all":      {"U"},
+		"remove":          {"d"},
+		"install_version": {"i"},
+		"versions":        {"v"},
+		"why":             {"w"},
+		"sort":            {"s"},
+		"record_macro":    {"Q"},
+		"replay_macro":    {"@"},
+	},
+	"vim": {
+		"top":            {"home", "g g"},
+		"remove":         {"d d"},
+		"quit":           {": q enter", "Z Z"},
+		"page_up":        {"pgup", "ctrl+b"},
+		"page_down":      {"pgdown", "ctrl+f"},
+		"half_page_up":   {"ctrl+u"},
+		"half_page_down": {"ctrl+d"},
+		"visual":         {"V"},
+		"set_mark":       {"m"},
+		"jump_mark":      {"'", "`"},
+		"record_macro":   {"q"},
+	},
+	"emacs": {
+		"quit":         {"ctrl+x ctrl+c"},
+		"search":       {"ctrl+s"},
+		"back":         {"esc", "ctrl+g"},
+		"up":           {"up", "ctrl+p"},
+		"down":         {"down", "ctrl+n"},
+		"left":         {"left", "ctrl+b"},
+		"right":        {"right", "ctrl+f"},
+		"top":          {"home", "alt+<"},
+		"bottom":       {"end", "alt+>"},
+		"page_up":      {"pgup", "alt+v"},
+		"page_down":    {"pgdown", "ctrl+v"},
+		"record_macro": {"ctrl+x (", "ctrl+x )"},
+		"replay_macro": {"ctrl+x e"},
+	},
+}
+
+// Binding is a key sequence bound to an action.
+type Binding struct {
+	Keys    string `json:"keys"` // Canonical key sequence (see ParseSequence)
+	Action  string `json:"action"`
+	Context string `json:"context"`
+	Source  string `json:"source"` // The profile, "keybindings", or "customCommands"
+}
+
+// Conflict is a key sequence that cannot do what its bindings say: bound to several
+// actions in one context, or the start of a longer chord, which makes the shorter one
+// wait for the next key.
+type Conflict struct {
+	Keys     string    `json:"keys"`
+	Context  string    `json:"context"`
+	Bindings []Binding `json:"bindings"`
+	Prefix   bool      `json:"prefix,omitempty"` // Keys start a longer chord
+}
+
+// String describes the conflict for warnings.
+func (c Conflict) String() string {
+	actions := make([]string, 0, len(c.Bindings))
+	for _, b := range c.Bindings {
+		actions = append(actions, fmt.Sprintf("%s (%s)", b.Action, b.Source))
+	}
+	if c.Prefix {
+		return fmt.Sprintf("%q in %s starts %q, so %s waits for another key", c.Keys, c.Context, c.Bindings[1].Keys, actions[0])
+	}
+	return fmt.Sprintf("%q in %s is bound to %s", c.Keys, c.Context, strings.Join(actions, " and "))
+}
+
+// Keymap holds the bindings in effect.
+type Keymap struct {
+	Profile  string
+	Bindings []Binding
+}
+
+// New returns the keymap of a profile with the keybindings setting and the keys of
+// custom commands applied. A keybinding replaces every default key of its action; an
+// empty key unbinds it. Keybindings whose keys do not parse are left out and returned
+// as errors.
+func New(profile string, keybindings map[string]config.KeyBinding, commands []config.CustomCommand) (*Keymap, []error) {
+	if _, ok := profiles[profile]; !ok {
+		profile = "default"
+	}
+	km := &Keymap{Profile: profile}
+	var errs []error
+
+	for _, action := range Actions {
+		keys, source := profiles["default"][action.Name], "default"
+		if override, ok := profiles[profile][action.Name]; ok {
+			keys, source = override, profile
+		}
+		if kb, ok := keybindings[action.Name]; ok {
+			keys, source = nil, "keybindings"
+			if kb.Key != "" {
+				keys = []string{kb.Key}
+			}
+		}
+		context := action.Context
+		if kb, ok := keybindings[action.Name]; ok && kb.Context != "" {
+			context = kb.Context
+		}
+		for _, spec := range keys {
+			seq, err := ParseSequence(spec)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("keybindings.%s: %w", action.Name, err))
+				continue
+			}
+			km.Bindings = append(km.Bindings, Binding{Keys: seq, Action: action.Name, Context: context, Source: source})
+		}
+	}
+
+	// Keybindings for actions the UI does not have are kept for plugins to use
+	names := make([]string, 0, len(keybindings))
+	for name := range keybindings {
+		if !slices.ContainsFunc(Actions, func(a Action) bool { return a.Name == name }) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		kb := keybindings[name]
+		if kb.Key == "" {
+			continue
+		}
+		seq, err := ParseSequence(kb.Key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("keybindings.%s: %w", name, err))
+			continue
+		}
+		context := kb.Context
+		if context == "" {
+			context = Global
+		}
+		km.Bindings = append(km.Bindings, Binding{Keys: seq, Action: name, Context: context, Source: "keybindings"})
+	}
+
+	for _, c := range commands {
+		if c.Key == "" {
+			continue
+		}
+		seq, err := ParseSequence(c.Key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("customCommands %s: %w", c.Name, err))
+			continue
+		}
+		context := c.Context
+		if context == "" {
+			context = Global
+		}
+		km.Bindings = append(km.Bindings, Binding{Keys: seq, Action: CustomActionPrefix + c.Name, Context: context, Source: "customCommands"})
+	}
+	return km, errs
+}
+
+// Keys returns the key sequences bound to an action, in order.
+func (km *Keymap) Keys(action string) []string {
+	var keys []string
+	for _, b := range km.Bindings {
+		if b.Action == action {
+			keys = append(keys, b.Keys)
+		}
+	}
+	return keys
+}
+
+// Conflicts returns the conflicts between the bindings: a key sequence bound to several
+// actions in the same context, and sequences that start a longer chord where both work.
+// A panel binding with the keys of a global one is not a conflict; it wins in its panel.
+func (km *Keymap) Conflicts() []Conflict {
+	return conflicts(km.Bindings)
+}
+
+// ConflictsWith returns the conflicts binding keys to an action in a context would have,
+// ignoring the action's current keys: what an editor shows while a key is typed.
+func (km *Keymap) ConflictsWith(action, context, keys string) []Conflict {
+	candidate := Binding{Keys: keys, Action: action, Context: context, Source: "keybindings"}
+	others := slices.DeleteFunc(slices.Clone(km.Bindings), func(b Binding) bool { return b.Action == action })
+	var found []Conflict
+	for _, c := range conflicts(append(others, candidate)) {
+		if slices.Contains(c.Bindings, candidate) {
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+// conflicts finds the conflicts between bindings, in binding order.
+func conflicts(bindings []Binding) []Conflict {
+	overlap := func(a, b Binding) bool {
+		return a.Context == b.Context || a.Context == Global || b.Context == Global
+	}
+	var found []Conflict
+	reported := make(map[int]bool)
+	for i, a := range bindings {
+		if reported[i] {
+			continue
+		}
+		conflict := Conflict{Keys: a.Keys, Context: a.Context, Bindings: []Binding{a}}
+		for j := i + 1; j < len(bindings); j++ {
+			b := bindings[j]
+			if b.Keys == a.Keys && b.Action != a.Action && a.Context == b.Context {
+				conflict.Bindings = append(conflict.Bindings, b)
+				reported[j] = true
+			}
+		}
+		if len(conflict.Bindings) > 1 {
+			found = append(found, conflict)
+		}
+	}
+	for _, a := range bindings {
+		for _, b := range bindings {
+			if overlap(a, b) && strings.HasPrefix(b.Keys, a.Keys+" ") && a.Action != b.Action {
+				context := a.Context
+				if context == Global {
+					context = b.Context
+				}
+				found = append(found, Conflict{Keys: a.Keys, Context: context, Bindings: []Binding{a, b}, Prefix: true})
+				break
+			}
+		}
+	}
+	return found
+}
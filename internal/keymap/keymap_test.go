@@ -0,0 +1,338 @@
+package keymap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+)
+
+// TestParseSequence tests the canonical form of keys written in different ways
+func TestParseSequence(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"g", "g", false},
+		{"G", "G", false},
+		{"shift+g", "G", false},
+		{"<c-r>", "ctrl+r", false},
+		{"C-R", "ctrl+r", false},
+		{"Ctrl+Alt+Up", "ctrl+alt+up", false},
+		{"alt-shift-tab", "alt+backtab", false},
+		{"ctrl++", "ctrl++", false},
+		{"-", "-", false},
+		{"PageDown", "pgdown", false},
+		{" ", "space", false},
+		{"g g", "g g", false},
+		{"<space> u  a", "space u a", false},
+		{"ctrl+x ctrl+c", "ctrl+x ctrl+c", false},
+		{"hyper+x", "", true},
+		{"bogus", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSequence(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseSequence(%q) = %q, %v; want %q (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// TestDecode tests decoding raw terminal input into keys
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		n    int
+	}{
+		{"g", "g", 1},
+		{"\r", "enter", 1},
+		{"\x12", "ctrl+r", 1},
+		{"\x00", "ctrl+space", 1},
+		{"\x7f", "backspace", 1},
+		{"\x1b", "esc", 1},
+		{"\x1bx", "alt+x", 2},
+		{"\x1b[A", "up", 3},
+		{"\x1bOP", "f1", 3},
+		{"\x1b[1;5C", "ctrl+right", 6},
+		{"\x1b[6~", "pgdown", 4},
+		{"\x1b[15;2~", "shift+f5", 7},
+		{"\x1b[Z", "backtab", 3},
+		{"\x1b[1;5", "", 0},
+		{"é!", "é", 2},
+		{"\xc3", "", 0},
+	}
+	for _, tt := range tests {
+		if got, n := Decode([]byte(tt.in)); got != tt.want || n != tt.n {
+			t.Errorf("Decode(%q) = %q, %d; want %q, %d", tt.in, got, n, tt.want, tt.n)
+		}
+	}
+	// Decoded keys are canonical, so they match bindings written by hand
+	for _, raw := range []string{"\x1b[1;5C", "\x1bx", "\x12"} {
+		key, _ := Decode([]byte(raw))
+		if parsed, err := ParseKey(key); err != nil || parsed != key {
+			t.Errorf("ParseKey(%q) = %q, %v", key, parsed, err)
+		}
+	}
+}
+
+// TestNew tests profiles, keybindings, and custom command keys
+func TestNew(t *testing.T) {
+	km, errs := New("vim", map[string]config.KeyBinding{
+		"refresh": {Key: "<c-r>"},
+		"sort":    {Key: ""},
+		"update":  {Key: "ctrl+nope"},
+	}, []config.CustomCommand{{Name: "why", Key: "space w", Context: "packages"}})
+
+	if len(errs) != 1 {
+		t.Errorf("errors = %v, want one for update", errs)
+	}
+	checks := map[string]string{
+		"top":        "home,g g",
+		"refresh":    "ctrl+r",
+		"sort":       "",
+		"update":     "",
+		"quit":       ": q enter,Z Z",
+		"custom:why": "space w",
+	}
+	for action, want := range checks {
+		if got := strings.Join(km.Keys(action), ","); got != want {
+			t.Errorf("Keys(%s) = %q, want %q", action, got, want)
+		}
+	}
+	if km, _ := New("bogus", nil, nil); km.Profile != "default" {
+		t.Errorf("unknown profile = %s, want default", km.Profile)
+	}
+}
+
+// TestConflicts tests duplicate keys, chord prefixes, and panel overrides
+func TestConflicts(t *testing.T) {
+	km := &Keymap{Bindings: []Binding{
+		{Keys: "g", Action: "top", Context: Global},
+		{Keys: "g g", Action: "bottom", Context: "packages"},
+		{Keys: "u", Action: "update", Context: "packages"},
+		{Keys: "u", Action: "custom:bump", Context: "packages"},
+		{Keys: "r", Action: "refresh", Context: Global},
+		{Keys: "r", Action: "restore", Context: "projects"}, // Overrides r in its panel
+	}}
+	conflicts := km.Conflicts()
+	if len(conflicts) != 2 {
+		t.Fatalf("Conflicts = %v, want 2", conflicts)
+	}
+	if c := conflicts[0]; c.Prefix || c.Keys != "u" || len(c.Bindings) != 2 {
+		t.Errorf("duplicate = %+v", c)
+	}
+	if c := conflicts[1]; !c.Prefix || c.Keys != "g" || c.Context != "packages" {
+		t.Errorf("prefix = %+v", c)
+	}
+
+	// An action's own keys do not conflict with its new ones
+	if got := km.ConflictsWith("update", "packages", "u"); len(got) != 1 || got[0].Bindings[0].Action != "custom:bump" {
+		t.Errorf("ConflictsWith(update, u) = %v", got)
+	}
+	if got := km.ConflictsWith("top", Global, "t"); len(got) != 0 {
+		t.Errorf("ConflictsWith(top, t) = %v", got)
+	}
+}
+
+// TestMatcher tests chords, pending keys, timeouts, and context precedence
+func TestMatcher(t *testing.T) {
+	km := &Keymap{Bindings: []Binding{
+		{Keys: "g", Action: "top", Context: Global},
+		{Keys: "g g", Action: "bottom", Context: Global},
+		{Keys: "space u a", Action: "update_all", Context: "packages"},
+		{Keys: "j", Action: "down", Context: Global},
+		{Keys: "g", Action: "group", Context: "projects"},
+	}}
+	m := NewMatcher(km)
+
+	feed := func(context string, keys ...string) (string, bool) {
+		var action string
+		var pending bool
+		for _, key := range keys {
+			action, pending = m.Feed(context, key)
+		}
+		return action, pending
+	}
+
+	if action, pending := feed("packages", "g"); action != "" || !pending || m.Pending() != "g" {
+		t.Errorf("g = %q, %v (pending %q)", action, pending, m.Pending())
+	}
+	if action, _ := feed("packages", "g"); action != "bottom" || m.Pending() != "" {
+		t.Errorf("g g = %q", action)
+	}
+	if _, pending := feed("packages", "g"); !pending || m.Timeout() != "top" {
+		t.Error("g then a timeout did not go to the top")
+	}
+	if action, _ := feed("packages", "space", "u", "a"); action != "update_all" {
+		t.Errorf("space u a = %q", action)
+	}
+	// A key that ends no chord starts over
+	if action, _ := feed("packages", "space", "j"); action != "down" || m.Pending() != "" {
+		t.Errorf("space j = %q (pending %q)", action, m.Pending())
+	}
+	// The panel's g shadows the global g and the chords it starts
+	if action, pending := feed("projects", "g"); action != "group" || pending {
+		t.Errorf("g in projects = %q, %v", action, pending)
+	}
+	if action, pending := feed("packages", "x"); action != "" || pending {
+		t.Errorf("x = %q, %v", action, pending)
+	}
+}
+
+// TestModal tests counts, marks, and visual mode with the vim profile
+func TestModal(t *testing.T) {
+	km, _ := New("vim", nil, nil)
+	m := NewModal(km)
+	feed := func(keys ...string) (Command, bool) {
+		var cmd Command
+		var ok bool
+		for _, key := range keys {
+			cmd, ok = m.Feed("packages", key)
+		}
+		return cmd, ok
+	}
+
+	if m.Mode() != ModeNormal {
+		t.Errorf("Mode = %q, want NORMAL", m.Mode())
+	}
+	if _, ok := feed("1", "2"); ok || m.Pending() != "12" {
+		t.Errorf("12 completed a command, or pending = %q", m.Pending())
+	}
+	if cmd, ok := feed("j"); !ok || cmd != (Command{Action: "down", Count: 12}) {
+		t.Errorf("12j = %+v, %v", cmd, ok)
+	}
+	if cmd, _ := feed("5", "g"); m.Pending() != "5 g" || cmd.Action != "" {
+		t.Errorf("5g pending = %q", m.Pending())
+	}
+	if cmd, _ := feed("g"); cmd != (Command{Action: "top", Count: 5}) {
+		t.Errorf("5gg = %+v", cmd)
+	}
+	if cmd, _ := feed("ctrl+d"); cmd.Action != "half_page_down" || cmd.Times() != 1 {
+		t.Errorf("ctrl+d = %+v", cmd)
+	}
+
+	if _, ok := feed("m"); ok || m.Pending() != "m" {
+		t.Errorf("m pending = %q", m.Pending())
+	}
+	if cmd, _ := feed("a"); cmd != (Command{Action: "set_mark", Letter: "a"}) {
+		t.Errorf("ma = %+v", cmd)
+	}
+	if cmd, ok := feed("'", "esc"); ok || m.Pending() != "" {
+		t.Errorf("' esc = %+v, pending %q; want it canceled", cmd, m.Pending())
+	}
+	if cmd, _ := feed("`", "a"); cmd != (Command{Action: "jump_mark", Letter: "a"}) {
+		t.Errorf("`a = %+v", cmd)
+	}
+
+	if cmd, _ := feed("V"); cmd.Action != "visual" || m.Mode() != ModeVisual {
+		t.Errorf("V = %+v in %s", cmd, m.Mode())
+	}
+	if cmd, _ := feed("esc"); cmd.Action != "visual" || m.Mode() != ModeNormal {
+		t.Errorf("esc in visual mode = %+v in %s", cmd, m.Mode())
+	}
+	if cmd, _ := feed("esc"); cmd.Action != "back" {
+		t.Errorf("esc = %+v", cmd)
+	}
+
+	// Other profiles have no counts or modes
+	m = NewModal(&Keymap{Profile: "default", Bindings: []Binding{{Keys: "5", Action: "five", Context: Global}}})
+	if cmd, ok := feed("5"); !ok || cmd.Action != "five" || m.Mode() != "" {
+		t.Errorf("5 with the default profile = %+v, %v", cmd, ok)
+	}
+}
+
+// TestCursor tests moving the cursor with counts and selecting rows in visual mode
+func TestCursor(t *testing.T) {
+	c := NewCursor()
+	steps := []struct {
+		cmd  Command
+		want int
+	}{
+		{Command{Action: "down", Count: 5}, 5},
+		{Command{Action: "half_page_down"}, 10},
+		{Command{Action: "page_down", Count: 3}, 39},
+		{Command{Action: "up"}, 38},
+		{Command{Action: "top", Count: 7}, 6},
+		{Command{Action: "bottom"}, 39},
+		{Command{Action: "bottom", Count: 3}, 2},
+		{Command{Action: "half_page_up", Count: 2}, 0},
+	}
+	for _, step := range steps {
+		if !c.Apply(step.cmd, 40, 10) || c.Index != step.want {
+			t.Errorf("%+v moved to %d, want %d", step.cmd, c.Index, step.want)
+		}
+	}
+	if c.Apply(Command{Action: "refresh"}, 40, 10) {
+		t.Error("Apply handled refresh")
+	}
+
+	c.Apply(Command{Action: "down", Count: 4}, 40, 10)
+	c.Apply(Command{Action: "visual"}, 40, 10)
+	c.Apply(Command{Action: "up", Count: 2}, 40, 10)
+	if first, last := c.Selection(); first != 2 || last != 4 {
+		t.Errorf("Selection = %d..%d, want 2..4", first, last)
+	}
+	c.Apply(Command{Action: "visual"}, 40, 10)
+	if first, last := c.Selection(); first != 2 || last != 2 {
+		t.Errorf("Selection after visual ended = %d..%d", first, last)
+	}
+}
+
+// TestMacros tests recording macros into registers and replaying them
+func TestMacros(t *testing.T) {
+	km, _ := New("vim", nil, nil)
+	m := NewModal(km)
+	run := func(keys ...string) []Command {
+		var cmds []Command
+		for _, key := range keys {
+			if cmd, ok := m.Feed("packages", key); ok {
+				cmds = append(cmds, cmd)
+				m.Macros.Record(Step{Command: cmd})
+			}
+		}
+		return cmds
+	}
+
+	run("q", "a")
+	if m.Macros.Recording() != "a" || m.Mode() != "NORMAL recording @a" {
+		t.Errorf("recording %q, mode %q", m.Macros.Recording(), m.Mode())
+	}
+	run("enter")
+	m.Macros.Record(Step{Command: Command{Action: "install_version"}, Input: "3.1.1"})
+	run("j", "q")
+	if m.Macros.Recording() != "" {
+		t.Error("q did not stop recording")
+	}
+
+	cmds := run("3", "@", "a")
+	if len(cmds) != 1 || cmds[0] != (Command{Action: "replay_macro", Count: 3, Letter: "a"}) {
+		t.Fatalf("3@a = %+v", cmds)
+	}
+	steps, err := m.Macros.Replay(cmds[0].Letter, cmds[0].Times())
+	if err != nil || len(steps) != 9 || steps[1].Input != "3.1.1" || steps[2].Command.Action != "down" {
+		t.Errorf("Replay = %+v, %v", steps, err)
+	}
+	if steps, err := m.Macros.Replay(LastRegister, 1); err != nil || len(steps) != 3 {
+		t.Errorf("Replay(@@) = %+v, %v", steps, err)
+	}
+	if _, err := m.Macros.Replay("b", 1); err == nil {
+		t.Error("Replay of an empty register succeeded")
+	}
+
+	// Profiles without registers record into the unnamed one
+	m = NewModal(&Keymap{Bindings: []Binding{
+		{Keys: "Q", Action: "record_macro", Context: Global},
+		{Keys: "@", Action: "replay_macro", Context: Global},
+		{Keys: "u", Action: "update", Context: Global},
+	}})
+	run("Q", "u", "Q")
+	if cmds := run("@"); len(cmds) != 1 || cmds[0].Letter != UnnamedRegister {
+		t.Fatalf("@ = %+v", cmds)
+	}
+	if steps, _ := m.Macros.Replay(UnnamedRegister, 1); len(steps) != 1 || steps[0].Command.Action != "update" {
+		t.Errorf("Replay = %+v", steps)
+	}
+}
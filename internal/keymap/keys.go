@@ -0,0 +1,216 @@
+package keymap
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// namedKeys maps the names a key may be written with to its canonical name.
+var namedKeys = map[string]string{
+	"enter": "enter", "return": "enter", "cr": "enter",
+	"esc": "esc", "escape": "esc",
+	"tab": "tab", "backtab": "backtab",
+	"space": "space", "spc": "space",
+	"backspace": "backspace", "bs": "backspace",
+	"delete": "delete", "del": "delete",
+	"insert": "insert", "ins": "insert",
+	"up": "up", "down": "down", "left": "left", "right": "right",
+	"home": "home", "end": "end",
+	"pgup": "pgup", "pageup": "pgup", "page_up": "pgup",
+	"pgdown": "pgdown", "pgdn": "pgdown", "pagedown": "pgdown", "page_down": "pgdown",
+}
+
+func init() {
+	for i := 1; i <= 12; i++ {
+		name := fmt.Sprintf("f%d", i)
+		namedKeys[name] = name
+	}
+}
+
+// modifierNames maps the modifier prefixes keys may be written with (ctrl+r, C-r, and
+// lazygit's <c-r>) to canonical modifiers.
+var modifierNames = map[string]string{
+	"ctrl": "ctrl", "control": "ctrl", "c": "ctrl",
+	"alt": "alt", "meta": "alt", "m": "alt", "a": "alt",
+	"shift": "shift", "s": "shift",
+}
+
+// ParseKey returns the canonical name of a key: a character ("g", "G", "?"), or a named
+// key with modifiers in the order ctrl, alt, shift ("ctrl+r", "alt+enter", "f5").
+// Modifiers may be written ctrl+, C-, or in lazygit's <c-r> form, case-insensitively;
+// shift with a letter is the capital letter.
+func ParseKey(s string) (string, error) {
+	key := s
+	if len(key) > 2 && strings.HasPrefix(key, "<") && strings.HasSuffix(key, ">") {
+		key = key[1 : len(key)-1]
+	}
+	if key == "" {
+		return "", fmt.Errorf("empty key")
+	}
+
+	mods := make(map[string]bool)
+	for {
+		// The last character is the key even when it is a separator ("ctrl++", "-")
+		i := strings.IndexAny(key[:len(key)-1], "+-")
+		if i <= 0 {
+			break
+		}
+		mod, ok := modifierNames[strings.ToLower(key[:i])]
+		if !ok {
+			break
+		}
+		mods[mod] = true
+		key = key[i+1:]
+	}
+
+	if r, size := utf8.DecodeRuneInString(key); size == len(key) {
+		switch {
+		case r == ' ':
+			key = "space"
+		case mods["shift"] && unicode.IsLetter(r):
+			key = string(unicode.ToUpper(r))
+			delete(mods, "shift")
+		case mods["ctrl"]:
+			key = string(unicode.ToLower(r)) // Terminals cannot tell ctrl+R from ctrl+r
+		}
+	} else {
+		name, ok := namedKeys[strings.ToLower(key)]
+		if !ok {
+			return "", fmt.Errorf("unknown key %q", s)
+		}
+		key = name
+	}
+	if key == "tab" && mods["shift"] {
+		key = "backtab"
+		delete(mods, "shift")
+	}
+
+	var b strings.Builder
+	for _, mod := range []string{"ctrl", "alt", "shift"} {
+		if mods[mod] {
+			b.WriteString(mod + "+")
+		}
+	}
+	b.WriteString(key)
+	return b.String(), nil
+}
+
+// ParseSequence returns the canonical form of a key sequence: keys separated by spaces,
+// pressed one after the other ("g g", "space u a"). A single key is a sequence of one.
+func ParseSequence(s string) (string, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		if s != "" {
+			return "space", nil // " " is the space key
+		}
+		return "", fmt.Errorf("no keys")
+	}
+	keys := make([]string, 0, len(fields))
+	for _, field := range fields {
+		key, err := ParseKey(field)
+		if err != nil {
+			return "", err
+		}
+		keys = append(keys, key)
+	}
+	return strings.Join(keys, " "), nil
+}
+
+// csiKeys maps the final byte of CSI and SS3 sequences to keys.
+var csiKeys = map[byte]string{
+	'A': "up", 'B': "down", 'C': "right", 'D': "left", 'H': "home", 'F': "end", 'Z': "backtab",
+	'P': "f1", 'Q': "f2", 'R': "f3", 'S': "f4",
+}
+
+// tildeKeys maps the parameter of CSI n ~ sequences to keys.
+var tildeKeys = map[string]string{
+	"1": "home", "2": "insert", "3": "delete", "4": "end", "5": "pgup", "6": "pgdown", "7": "home", "8": "end",
+	"11": "f1", "12": "f2", "13": "f3", "14": "f4", "15": "f5", "17": "f6", "18": "f7", "19": "f8",
+	"20": "f9", "21": "f10", "23": "f11", "24": "f12",
+}
+
+// Decode returns the first key in terminal input read in raw mode, and the number of
+// bytes it took. A lone ESC is the escape key; ESC before another key is alt. Sequences
+// cut off at the end of input return 0 bytes, to be decoded with more input.
+func Decode(b []byte) (string, int) {
+	if len(b) == 0 {
+		return "", 0
+	}
+	switch c := b[0]; {
+	case c == 0x1b:
+		if len(b) == 1 {
+			return "esc", 1
+		}
+		if b[1] == '[' || b[1] == 'O' {
+			return decodeCSI(b)
+		}
+		key, n := Decode(b[1:])
+		if n == 0 || strings.HasPrefix(key, "alt+") {
+			return "esc", 1
+		}
+		return "alt+" + key, n + 1
+	case c == '\r' || c == '\n':
+		return "enter", 1
+	case c == '\t':
+		return "tab", 1
+	case c == 0x7f || c == 0x08:
+		return "backspace", 1
+	case c == ' ':
+		return "space", 1
+	case c == 0:
+		return "ctrl+space", 1
+	case c < 0x20:
+		return "ctrl+" + strings.ToLower(string(rune('@'+c))), 1
+	}
+	r, size := utf8.DecodeRune(b)
+	if r == utf8.RuneError && size <= 1 {
+		if !utf8.FullRune(b) {
+			return "", 0
+		}
+		return "", 1 // Invalid byte: skip it
+	}
+	return string(r), size
+}
+
+// decodeCSI decodes an ESC [ or ESC O sequence, with xterm modifier parameters
+// (ESC [ 1 ; 5 A is ctrl+up).
+func decodeCSI(b []byte) (string, int) {
+	end := 2
+	for end < len(b) && (b[end] >= '0' && b[end] <= '9' || b[end] == ';') {
+		end++
+	}
+	if end == len(b) {
+		return "", 0
+	}
+	params := strings.Split(string(b[2:end]), ";")
+	final := b[end]
+	var key string
+	if final == '~' {
+		key = tildeKeys[params[0]]
+	} else {
+		key = csiKeys[final]
+	}
+	if key == "" {
+		return "", end + 1 // Unknown sequence: skip it
+	}
+	if len(params) == 2 {
+		var mod int
+		if _, err := fmt.Sscanf(params[1], "%d", &mod); err == nil && mod > 1 {
+			mod--
+			prefix := ""
+			if mod&4 != 0 {
+				prefix += "ctrl+"
+			}
+			if mod&2 != 0 {
+				prefix += "alt+"
+			}
+			if mod&1 != 0 {
+				prefix += "shift+"
+			}
+			key = prefix + key
+		}
+	}
+	return key, end + 1
+}
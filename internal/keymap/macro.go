@@ -0,0 +1,107 @@
+package keymap
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Macro registers with special meanings.
+const (
+	UnnamedRegister = `"` // The register of profiles without registers
+	LastRegister    = "@" // Replays the register replayed last ("@@")
+)
+
+// Step is an action of a macro: the command, and the answer typed into the dialog it
+// opened (the version picked, the search query), if any. Macros record actions rather
+// than keys, so they replay the same whatever row the cursor is on and whatever keys
+// are bound.
+type Step struct {
+	Command Command `json:"command"`
+	Input   string  `json:"input,omitempty"`
+}
+
+// Macros holds recorded macros by register, and the one being recorded.
+type Macros struct {
+	registers map[string][]Step
+	recording string
+	steps     []Step
+	last      string
+}
+
+// NewMacros returns an empty set of macros.
+func NewMacros() *Macros {
+	return &Macros{registers: make(map[string][]Step)}
+}
+
+// Start starts recording into a register, ending a recording under way.
+func (m *Macros) Start(register string) {
+	m.Stop()
+	m.recording, m.steps = register, nil
+}
+
+// Stop ends the recording under way, replacing the register's macro. A recording with no
+// steps leaves the register empty, as in vim.
+func (m *Macros) Stop() {
+	if m.recording == "" {
+		return
+	}
+	if len(m.steps) == 0 {
+		delete(m.registers, m.recording)
+	} else {
+		m.registers[m.recording] = m.steps
+	}
+	m.recording, m.steps = "", nil
+}
+
+// Recording returns the register being recorded into, or "" when none is.
+func (m *Macros) Recording() string {
+	return m.recording
+}
+
+// Record adds a step to the recording under way, if any. The UI records each command it
+// runs, including those a replay runs; record_macro and replay_macro themselves are not
+// steps.
+func (m *Macros) Record(step Step) {
+	if m.recording == "" {
+		return
+	}
+	switch step.Command.Action {
+	case "record_macro", "replay_macro":
+		return
+	}
+	m.steps = append(m.steps, step)
+}
+
+// Replay returns the steps of a register's macro, repeated times times. LastRegister
+// replays the register replayed before.
+func (m *Macros) Replay(register string, times int) ([]Step, error) {
+	if register == LastRegister {
+		if m.last == "" {
+			return nil, fmt.Errorf("no macro replayed yet")
+		}
+		register = m.last
+	}
+	macro, ok := m.registers[register]
+	if !ok {
+		if register == UnnamedRegister {
+			return nil, fmt.Errorf("no macro recorded")
+		}
+		return nil, fmt.Errorf("no macro recorded in @%s", register)
+	}
+	m.last = register
+	steps := make([]Step, 0, len(macro)*max(times, 1))
+	for range max(times, 1) {
+		steps = append(steps, macro...)
+	}
+	return steps, nil
+}
+
+// Registers returns the registers with a macro, sorted.
+func (m *Macros) Registers() []string {
+	registers := make([]string, 0, len(m.registers))
+	for register := range m.registers {
+		registers = append(registers, register)
+	}
+	sort.Strings(registers)
+	return registers
+}
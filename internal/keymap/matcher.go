@@ -0,0 +1,85 @@
+package keymap
+
+import "strings"
+
+// Matcher turns keys pressed one at a time into actions, waiting while they start a chord.
+type Matcher struct {
+	keymap  *Keymap
+	pending []string // Keys of the chord under way
+	context string   // Context the chord started in
+	exact   string   // Action of the pending keys themselves, for Timeout
+}
+
+// NewMatcher returns a matcher for a keymap.
+func NewMatcher(km *Keymap) *Matcher {
+	return &Matcher{keymap: km}
+}
+
+// Feed adds a key pressed in a context (a panel, or Global) and returns the action it
+// completes. pending is true while the keys so far start a longer chord; the UI shows
+// them (see Pending) and calls Timeout when no key follows soon enough. A key that ends
+// no chord starts over as the first key of a new one.
+func (m *Matcher) Feed(context, key string) (action string, pending bool) {
+	if len(m.pending) > 0 && context != m.context {
+		m.Reset()
+	}
+	chord := len(m.pending) > 0
+	action, pending, matched := m.match(context, append(m.pending, key))
+	if !matched && chord {
+		action, pending, _ = m.match(context, []string{key})
+	}
+	return action, pending
+}
+
+// match looks keys up in a context, updating the pending chord.
+func (m *Matcher) match(context string, keys []string) (action string, pending, matched bool) {
+	seq := strings.Join(keys, " ")
+	var exact, exactGlobal string
+	longer, longerGlobal := false, false
+	for _, b := range m.keymap.Bindings {
+		local := b.Context == context && context != Global
+		if !local && b.Context != Global {
+			continue
+		}
+		switch {
+		case b.Keys == seq && local && exact == "":
+			exact = b.Action
+		case b.Keys == seq && !local && exactGlobal == "":
+			exactGlobal = b.Action
+		case strings.HasPrefix(b.Keys, seq+" ") && local:
+			longer = true
+		case strings.HasPrefix(b.Keys, seq+" "):
+			longerGlobal = true
+		}
+	}
+	// The panel's bindings shadow global ones with the same keys, and the chords those start
+	if exact == "" {
+		exact = exactGlobal
+		longer = longer || longerGlobal
+	}
+
+	if longer {
+		m.pending, m.context, m.exact = keys, context, exact
+		return "", true, true
+	}
+	m.Reset()
+	return exact, false, exact != ""
+}
+
+// Timeout ends a pending chord, returning the action of the keys typed so far, if any
+// ("g" when "g" and "g g" are both bound).
+func (m *Matcher) Timeout() string {
+	action := m.exact
+	m.Reset()
+	return action
+}
+
+// Pending returns the keys of the chord under way ("g", "space u"), or "" when none is.
+func (m *Matcher) Pending() string {
+	return strings.Join(m.pending, " ")
+}
+
+// Reset drops the chord under way.
+func (m *Matcher) Reset() {
+	m.pending, m.context, m.exact = nil, "", ""
+}
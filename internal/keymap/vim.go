@@ -0,0 +1,162 @@
+package keymap
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Modes of the vim profile, shown by the {mode} status bar segment.
+const (
+	ModeNormal = "NORMAL"
+	ModeVisual = "VISUAL"
+)
+
+// maxCount caps counts, so a held digit key cannot overflow them.
+const maxCount = 9999
+
+// Command is an action to run, with what the vim profile adds to it.
+type Command struct {
+	Action string
+	Count  int    // Count typed before the keys ("5j"); 0 when none was
+	Letter string // Mark of set_mark and jump_mark, register of record_macro and replay_macro
+}
+
+// Times returns how many times to repeat the command: its count, or once.
+func (c Command) Times() int {
+	return max(c.Count, 1)
+}
+
+// letterActions take the letter pressed after their keys with the vim profile.
+var letterActions = map[string]bool{"set_mark": true, "jump_mark": true, "record_macro": true, "replay_macro": true}
+
+// Modal reads keys the way the keymap's profile does. With the vim profile, digits before
+// an action are its count, marks and macros take the letter pressed next ("ma", "qa",
+// "@a"), and visual toggles visual mode, which Esc also ends. Other profiles pass keys to
+// a Matcher as is, and record macros in the unnamed register. Starting and stopping a
+// recording is done here; the UI records the steps it runs in Macros.
+type Modal struct {
+	Macros *Macros
+
+	matcher  *Matcher
+	vim      bool
+	visual   bool
+	count    string
+	letter   string // Action waiting for its letter
+	letterOf string // Keys that started it, for Pending
+}
+
+// NewModal returns a modal reader of keys for a keymap.
+func NewModal(km *Keymap) *Modal {
+	return &Modal{Macros: NewMacros(), matcher: NewMatcher(km), vim: km.Profile == "vim"}
+}
+
+// Feed adds a key pressed in a context and returns the command it completes, if any.
+func (m *Modal) Feed(context, key string) (Command, bool) {
+	if m.letter != "" {
+		action := m.letter
+		count, _ := strconv.Atoi(m.count)
+		m.letter, m.letterOf, m.count = "", "", ""
+		letter := len(key) == 1 && (key[0] >= 'a' && key[0] <= 'z' || key[0] >= 'A' && key[0] <= 'Z')
+		if !letter && !(action == "replay_macro" && key == LastRegister) {
+			return Command{}, false // Anything but a letter cancels, as in vim
+		}
+		if action == "record_macro" {
+			m.Macros.Start(key)
+		}
+		return Command{Action: action, Count: count, Letter: key}, true
+	}
+	if m.vim && m.matcher.Pending() == "" && len(key) == 1 && key[0] >= '0' && key[0] <= '9' && (key != "0" || m.count != "") {
+		if n, _ := strconv.Atoi(m.count + key); n <= maxCount {
+			m.count += key
+		}
+		return Command{}, false
+	}
+	action, pending := m.matcher.Feed(context, key)
+	if pending {
+		return Command{}, false
+	}
+	return m.complete(action, key)
+}
+
+// Timeout ends a pending chord, returning the command of the keys typed so far, if any.
+// The count typed before the chord stays for the next keys when there is none.
+func (m *Modal) Timeout() (Command, bool) {
+	if m.matcher.Pending() == "" {
+		return Command{}, false
+	}
+	return m.complete(m.matcher.Timeout(), "")
+}
+
+// complete turns the action a Matcher found into a command.
+func (m *Modal) complete(action, key string) (Command, bool) {
+	if action == "" {
+		return Command{}, false
+	}
+	count, _ := strconv.Atoi(m.count)
+	m.count = ""
+	switch {
+	case action == "record_macro" && m.Macros.Recording() != "":
+		m.Macros.Stop()
+	case !m.vim:
+		switch action {
+		case "record_macro":
+			m.Macros.Start(UnnamedRegister)
+			return Command{Action: action, Letter: UnnamedRegister}, true
+		case "replay_macro":
+			return Command{Action: action, Letter: UnnamedRegister}, true
+		}
+		return Command{Action: action}, true
+	case letterActions[action]:
+		m.letter, m.letterOf = action, key
+		if count > 0 {
+			m.count = strconv.Itoa(count)
+		}
+		return Command{}, false
+	case action == "visual":
+		m.visual = !m.visual
+	case action == "back" && m.visual:
+		m.visual = false
+		action = "visual"
+	}
+	return Command{Action: action, Count: count}, true
+}
+
+// Mode returns the input mode for the {mode} segment: NORMAL or VISUAL with the vim
+// profile, and "" with others, which have no modes. While a macro is recorded, the mode
+// says so ("NORMAL recording @a").
+func (m *Modal) Mode() string {
+	mode := ModeNormal
+	switch {
+	case !m.vim:
+		if m.Macros.Recording() != "" {
+			return "RECORDING"
+		}
+		return ""
+	case m.visual:
+		mode = ModeVisual
+	}
+	if register := m.Macros.Recording(); register != "" {
+		mode += " recording @" + register
+	}
+	return mode
+}
+
+// Pending returns what has been typed toward the next command, for the {keys} segment:
+// a count, the keys of a chord, or keys waiting for a letter ("5", "5 g", "m").
+func (m *Modal) Pending() string {
+	var parts []string
+	for _, part := range []string{m.count, m.matcher.Pending(), m.letterOf} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Reset drops what has been typed toward the next command and leaves visual mode. A
+// macro being recorded goes on.
+func (m *Modal) Reset() {
+	m.matcher.Reset()
+	m.visual = false
+	m.count, m.letter, m.letterOf = "", "", ""
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -251,6 +252,220 @@ func TestShutdownHandlerPanic(t *testing.T) {
 	}
 }
 
+// TestShutdownHandlerTimeout tests that a hung handler is abandoned after its own timeout
+func TestShutdownHandlerTimeout(t *testing.T) {
+	mgr := NewManager(30 * time.Second)
+	logger := &mockLogger{}
+
+	block := make(chan struct{})
+	defer close(block)
+	mgr.RegisterShutdownHandler(ShutdownHandler{
+		Name:     "hung-handler",
+		Priority: 100,
+		Timeout:  50 * time.Millisecond,
+		Handler: func(context.Context) error {
+			<-block // Ignores its context
+			return nil
+		},
+	})
+
+	laterCalled := false
+	mgr.RegisterShutdownHandler(ShutdownHandler{
+		Name:     "later-handler",
+		Priority: 200,
+		Handler: func(context.Context) error {
+			laterCalled = true
+			return nil
+		},
+	})
+
+	mgr.SetState(StateInitializing)
+	mgr.SetState(StateRunning)
+
+	start := time.Now()
+	err := mgr.Shutdown(context.Background(), logger)
+	elapsed := time.Since(start)
+
+	if err == nil || !strings.Contains(err.Error(), "hung-handler") {
+		t.Errorf("expected error naming hung-handler, got: %v", err)
+	}
+	if elapsed > 5*time.Second {
+		t.Errorf("shutdown took %s, want the handler abandoned after its 50ms timeout", elapsed)
+	}
+	if !laterCalled {
+		t.Error("later handler was not called after earlier handler timed out")
+	}
+
+	report := mgr.LastShutdownReport()
+	if report == nil {
+		t.Fatal("LastShutdownReport() = nil after shutdown")
+	}
+	if got := report.Handlers[0].Status; got != HandlerTimedOut {
+		t.Errorf("hung-handler status = %q, want %q", got, HandlerTimedOut)
+	}
+	if got := report.Handlers[1].Status; got != HandlerCompleted {
+		t.Errorf("later-handler status = %q, want %q", got, HandlerCompleted)
+	}
+}
+
+// TestShutdownSamePriorityParallel tests that handlers sharing a priority run concurrently
+func TestShutdownSamePriorityParallel(t *testing.T) {
+	mgr := NewManager(5 * time.Second)
+	logger := &mockLogger{}
+
+	// Each handler waits until all of them have started, which only succeeds in parallel
+	const count = 3
+	var started sync.WaitGroup
+	started.Add(count)
+	for i := range count {
+		mgr.RegisterShutdownHandler(ShutdownHandler{
+			Name:     fmt.Sprintf("handler-%d", i),
+			Priority: 100,
+			Timeout:  time.Second,
+			Handler: func(ctx context.Context) error {
+				started.Done()
+				allStarted := make(chan struct{})
+				go func() {
+					started.Wait()
+					close(allStarted)
+				}()
+				select {
+				case <-allStarted:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			},
+		})
+	}
+
+	mgr.SetState(StateInitializing)
+	mgr.SetState(StateRunning)
+
+	if err := mgr.Shutdown(context.Background(), logger); err != nil {
+		t.Fatalf("Shutdown failed (handlers did not run in parallel): %v", err)
+	}
+
+	report := mgr.LastShutdownReport()
+	for i, h := range report.Handlers {
+		if want := fmt.Sprintf("handler-%d", i); h.Name != want {
+			t.Errorf("report handler %d = %s, want %s (registration order)", i, h.Name, want)
+		}
+	}
+}
+
+// TestShutdownReport tests the report and log lines for each handler outcome
+func TestShutdownReport(t *testing.T) {
+	mgr := NewManager(200 * time.Millisecond)
+	logger := &mockLogger{}
+
+	mgr.RegisterShutdownHandler(ShutdownHandler{
+		Name:     "ok-handler",
+		Priority: 10,
+		Handler:  func(context.Context) error { return nil },
+	})
+	mgr.RegisterShutdownHandler(ShutdownHandler{
+		Name:     "failing-handler",
+		Priority: 20,
+		Handler:  func(context.Context) error { return errors.New("intentional failure") },
+	})
+	mgr.RegisterShutdownHandler(ShutdownHandler{
+		Name:     "budget-handler",
+		Priority: 30,
+		Handler: func(ctx context.Context) error {
+			<-ctx.Done() // Uses up the whole shutdown budget
+			return ctx.Err()
+		},
+	})
+	mgr.RegisterShutdownHandler(ShutdownHandler{
+		Name:     "skipped-handler",
+		Priority: 40,
+		Handler:  func(context.Context) error { return nil },
+	})
+
+	if mgr.LastShutdownReport() != nil {
+		t.Error("LastShutdownReport() should be nil before shutdown")
+	}
+
+	mgr.SetState(StateInitializing)
+	mgr.SetState(StateRunning)
+	_ = mgr.Shutdown(context.Background(), logger)
+
+	report := mgr.LastShutdownReport()
+	want := []struct {
+		name   string
+		status HandlerStatus
+	}{
+		{"ok-handler", HandlerCompleted},
+		{"failing-handler", HandlerFailed},
+		{"budget-handler", HandlerTimedOut},
+		{"skipped-handler", HandlerSkipped},
+	}
+	if len(report.Handlers) != len(want) {
+		t.Fatalf("report has %d handlers, want %d", len(report.Handlers), len(want))
+	}
+	for i, w := range want {
+		if h := report.Handlers[i]; h.Name != w.name || h.Status != w.status {
+			t.Errorf("report handler %d = %s %q, want %s %q", i, h.Name, h.Status, w.name, w.status)
+		}
+	}
+	if report.Handlers[2].Duration < 150*time.Millisecond {
+		t.Errorf("budget-handler duration = %s, want about 200ms", report.Handlers[2].Duration)
+	}
+	if got := report.Count(HandlerTimedOut); got != 1 {
+		t.Errorf("Count(HandlerTimedOut) = %d, want 1", got)
+	}
+
+	logs := strings.Join(logger.logs, "\n")
+	for _, want := range []string{
+		"Shutdown handler ok-handler (priority 10): completed",
+		"WARN: Shutdown handler budget-handler (priority 30): timed out",
+		"WARN: Shutdown handler skipped-handler (priority 40): skipped",
+		"Shutdown report: 4 handlers",
+	} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("logs missing %q:\n%s", want, logs)
+		}
+	}
+}
+
+// TestObservers tests that observers see every successful transition, in order
+func TestObservers(t *testing.T) {
+	mgr := NewManager(30 * time.Second)
+	logger := &mockLogger{}
+	mgr.SetLogger(logger)
+
+	var transitions []string
+	mgr.AddObserver(ObserverFunc(func(from, to State) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+	}))
+	// A panicking observer must not break the state machine or later observers
+	mgr.AddObserver(ObserverFunc(func(State, State) {
+		panic("intentional panic")
+	}))
+	var stateSeen State
+	mgr.AddObserver(ObserverFunc(func(_, to State) {
+		stateSeen = mgr.GetState() // Observers run outside the lock
+	}))
+
+	mgr.SetState(StateInitializing)
+	mgr.SetState(StateRunning)
+	if err := mgr.SetState(StateInitializing); err == nil {
+		t.Error("expected invalid transition to fail")
+	}
+
+	want := []string{"Uninitialized->Initializing", "Initializing->Running"}
+	if strings.Join(transitions, ",") != strings.Join(want, ",") {
+		t.Errorf("transitions = %v, want %v", transitions, want)
+	}
+	if stateSeen != StateRunning {
+		t.Errorf("observer saw state %s, want Running", stateSeen)
+	}
+	if len(logger.logs) != 2 || !strings.HasPrefix(logger.logs[0], "ERROR: PANIC in lifecycle observer (Uninitialized -> Initializing): intentional panic") {
+		t.Errorf("logs = %v, want both observer panics logged", logger.logs)
+	}
+}
+
 func TestGetState(t *testing.T) {
 	mgr := NewManager(30 * time.Second)
 
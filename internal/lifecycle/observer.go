@@ -0,0 +1,60 @@
+package lifecycle
+
+import (
+	"runtime/debug"
+
+	"github.com/willibrandon/lazynuget/internal/logging"
+)
+
+// Observer receives notifications about lifecycle state changes.
+// Useful for monitoring, health checks, and coordinating dependent systems
+// (e.g., the GUI status bar).
+//
+// OnStateChange is called synchronously after each successful transition, outside the
+// manager's lock, so it may query the manager. It must return quickly and must not block.
+type Observer interface {
+	OnStateChange(from, to State)
+}
+
+// ObserverFunc adapts an ordinary function to the Observer interface
+type ObserverFunc func(from, to State)
+
+// OnStateChange calls f(from, to)
+func (f ObserverFunc) OnStateChange(from, to State) {
+	f(from, to)
+}
+
+// AddObserver registers an observer for all future state transitions
+func (m *Manager) AddObserver(observer Observer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.observers = append(m.observers, observer)
+}
+
+// SetLogger sets the logger that records observer panics
+func (m *Manager) SetLogger(logger logging.Logger) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logger = logger
+}
+
+// notifyObservers delivers a transition to every observer in registration order.
+// A panicking observer is logged and skipped so it cannot break the state machine.
+func (m *Manager) notifyObservers(from, to State) {
+	m.mu.RLock()
+	observers := make([]Observer, len(m.observers))
+	copy(observers, m.observers)
+	logger := m.logger
+	m.mu.RUnlock()
+
+	for _, observer := range observers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil && logger != nil {
+					logger.Error("PANIC in lifecycle observer (%s -> %s): %v\nStack: %s", from, to, r, debug.Stack())
+				}
+			}()
+			observer.OnStateChange(from, to)
+		}()
+	}
+}
@@ -2,14 +2,56 @@ package lifecycle
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime/debug"
 	"sort"
+	"sync"
+	"time"
 
 	"github.com/willibrandon/lazynuget/internal/logging"
 )
 
-// Shutdown executes all registered shutdown handlers with timeout
+// HandlerStatus describes how a shutdown handler finished
+type HandlerStatus string
+
+const (
+	HandlerCompleted HandlerStatus = "completed" // Returned nil
+	HandlerFailed    HandlerStatus = "failed"    // Returned an error or panicked
+	HandlerTimedOut  HandlerStatus = "timed out" // Exceeded its own timeout or the shutdown budget
+	HandlerSkipped   HandlerStatus = "skipped"   // Never started because the shutdown budget ran out
+)
+
+// HandlerResult records the outcome of a single shutdown handler
+type HandlerResult struct {
+	Err      error
+	Name     string
+	Status   HandlerStatus
+	Duration time.Duration
+	Priority int
+}
+
+// ShutdownReport summarizes a shutdown: which handlers ran, how long they took, and which timed out
+type ShutdownReport struct {
+	Handlers []HandlerResult // In execution order (by priority, then registration order)
+	Duration time.Duration
+}
+
+// Count returns the number of handlers that finished with the given status
+func (r *ShutdownReport) Count(status HandlerStatus) int {
+	n := 0
+	for _, h := range r.Handlers {
+		if h.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Shutdown executes all registered shutdown handlers with timeout.
+// Handlers run in priority order (lower numbers first); handlers sharing a priority
+// run in parallel. Each handler is bounded by its own Timeout (if set) and by the
+// overall shutdown timeout, so a hung handler cannot block the rest of shutdown.
 func (m *Manager) Shutdown(ctx context.Context, logger logging.Logger) error {
 	// Layer 5 panic recovery: Protect shutdown process
 	defer func() {
@@ -34,35 +76,56 @@ func (m *Manager) Shutdown(ctx context.Context, logger logging.Logger) error {
 	shutdownCtx, cancel := context.WithTimeout(ctx, m.shutdownTimeout)
 	defer cancel()
 
-	// Sort handlers by priority (lower numbers first)
-	handlers := m.getSortedHandlers()
-
-	// Execute handlers sequentially
+	start := time.Now()
+	report := &ShutdownReport{}
 	var shutdownErrors []error
-	for _, handler := range handlers {
-		if logger != nil {
-			logger.Debug("Running shutdown handler: %s (priority: %d)", handler.Name, handler.Priority)
+
+	for _, group := range groupByPriority(m.getSortedHandlers()) {
+		// Once the budget is spent, record the remaining handlers without running them
+		if shutdownCtx.Err() != nil {
+			for _, handler := range group {
+				result := HandlerResult{Name: handler.Name, Priority: handler.Priority, Status: HandlerSkipped}
+				report.Handlers = append(report.Handlers, result)
+				logHandlerResult(result, logger)
+			}
+			continue
 		}
 
-		// Wrap handler execution with panic recovery
-		err := m.executeHandlerSafely(shutdownCtx, handler, logger)
-		if err != nil {
-			shutdownErrors = append(shutdownErrors, fmt.Errorf("%s: %w", handler.Name, err))
-			if logger != nil {
-				logger.Warn("Shutdown handler failed: %s: %v", handler.Name, err)
+		if logger != nil {
+			for _, handler := range group {
+				logger.Debug("Running shutdown handler: %s (priority: %d)", handler.Name, handler.Priority)
 			}
 		}
 
-		// Check if context expired
-		if shutdownCtx.Err() != nil {
-			shutdownErrors = append(shutdownErrors, fmt.Errorf("shutdown timeout exceeded"))
-			if logger != nil {
-				logger.Error("Shutdown timeout exceeded")
+		// Log each group as it finishes, so results are on record before later groups run
+		for _, result := range m.runHandlerGroup(shutdownCtx, group, logger) {
+			report.Handlers = append(report.Handlers, result)
+			if result.Err != nil {
+				shutdownErrors = append(shutdownErrors, fmt.Errorf("%s: %w", result.Name, result.Err))
 			}
-			break
+			logHandlerResult(result, logger)
+		}
+	}
+
+	// Check if context expired
+	if shutdownCtx.Err() != nil {
+		shutdownErrors = append(shutdownErrors, fmt.Errorf("shutdown timeout exceeded"))
+		if logger != nil {
+			logger.Error("Shutdown timeout exceeded")
 		}
 	}
 
+	report.Duration = time.Since(start)
+	m.mu.Lock()
+	m.lastReport = report
+	m.mu.Unlock()
+	if logger != nil {
+		logger.Info("Shutdown report: %d handlers in %s (%d completed, %d failed, %d timed out, %d skipped)",
+			len(report.Handlers), report.Duration.Round(time.Millisecond),
+			report.Count(HandlerCompleted), report.Count(HandlerFailed),
+			report.Count(HandlerTimedOut), report.Count(HandlerSkipped))
+	}
+
 	// Transition to shutdown complete
 	if err := m.SetState(StateShutdownComplete); err != nil {
 		shutdownErrors = append(shutdownErrors, err)
@@ -81,6 +144,70 @@ func (m *Manager) Shutdown(ctx context.Context, logger logging.Logger) error {
 	return nil
 }
 
+// LastShutdownReport returns the report of the most recent Shutdown, or nil if none has run
+func (m *Manager) LastShutdownReport() *ShutdownReport {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastReport
+}
+
+// runHandlerGroup runs handlers of equal priority in parallel and returns their results
+// in registration order. It returns once every handler has finished or timed out.
+func (m *Manager) runHandlerGroup(ctx context.Context, group []ShutdownHandler, logger logging.Logger) []HandlerResult {
+	results := make([]HandlerResult, len(group))
+
+	var wg sync.WaitGroup
+	for i, handler := range group {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = m.runHandler(ctx, handler, logger)
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// runHandler runs one handler under its timeout. A handler that ignores its context is
+// abandoned when the timeout fires; its goroutine is left to finish on its own.
+func (m *Manager) runHandler(ctx context.Context, handler ShutdownHandler, logger logging.Logger) HandlerResult {
+	handlerCtx, cancel := context.WithCancel(ctx)
+	if handler.Timeout > 0 {
+		handlerCtx, cancel = context.WithTimeout(ctx, handler.Timeout)
+	}
+	defer cancel()
+
+	result := HandlerResult{Name: handler.Name, Priority: handler.Priority}
+	start := time.Now()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- m.executeHandlerSafely(handlerCtx, handler, logger)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-handlerCtx.Done():
+		err = handlerCtx.Err()
+	}
+	result.Duration = time.Since(start)
+
+	switch {
+	case err == nil:
+		result.Status = HandlerCompleted
+	case handlerCtx.Err() != nil && (errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)):
+		result.Status = HandlerTimedOut
+		result.Err = fmt.Errorf("timeout after %s: %w", result.Duration.Round(time.Millisecond), err)
+	default:
+		result.Status = HandlerFailed
+		result.Err = err
+	}
+
+	return result
+}
+
 // executeHandlerSafely runs a shutdown handler with panic recovery
 func (m *Manager) executeHandlerSafely(ctx context.Context, handler ShutdownHandler, logger logging.Logger) (err error) {
 	defer func() {
@@ -95,6 +222,22 @@ func (m *Manager) executeHandlerSafely(ctx context.Context, handler ShutdownHand
 	return handler.Handler(ctx)
 }
 
+// logHandlerResult logs the outcome of one handler; failures and timeouts are warnings
+func logHandlerResult(result HandlerResult, logger logging.Logger) {
+	if logger == nil {
+		return
+	}
+
+	switch result.Status {
+	case HandlerCompleted:
+		logger.Info("Shutdown handler %s (priority %d): %s in %s", result.Name, result.Priority, result.Status, result.Duration.Round(time.Millisecond))
+	case HandlerSkipped:
+		logger.Warn("Shutdown handler %s (priority %d): %s, shutdown timeout exceeded", result.Name, result.Priority, result.Status)
+	default:
+		logger.Warn("Shutdown handler %s (priority %d): %s in %s: %v", result.Name, result.Priority, result.Status, result.Duration.Round(time.Millisecond), result.Err)
+	}
+}
+
 // getSortedHandlers returns handlers sorted by priority
 func (m *Manager) getSortedHandlers() []ShutdownHandler {
 	m.mu.RLock()
@@ -104,10 +247,22 @@ func (m *Manager) getSortedHandlers() []ShutdownHandler {
 	handlers := make([]ShutdownHandler, len(m.shutdownHandlers))
 	copy(handlers, m.shutdownHandlers)
 
-	// Sort by priority (lower numbers first)
-	sort.Slice(handlers, func(i, j int) bool {
+	// Sort by priority (lower numbers first), keeping registration order within a priority
+	sort.SliceStable(handlers, func(i, j int) bool {
 		return handlers[i].Priority < handlers[j].Priority
 	})
 
 	return handlers
 }
+
+// groupByPriority splits sorted handlers into runs of equal priority
+func groupByPriority(handlers []ShutdownHandler) [][]ShutdownHandler {
+	var groups [][]ShutdownHandler
+	for i, handler := range handlers {
+		if i == 0 || handler.Priority != handlers[i-1].Priority {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], handler)
+	}
+	return groups
+}
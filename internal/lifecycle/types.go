@@ -7,6 +7,8 @@ import (
 	"slices"
 	"sync"
 	"time"
+
+	"github.com/willibrandon/lazynuget/internal/logging"
 )
 
 // State represents the application lifecycle state
@@ -43,17 +45,22 @@ func (s State) String() string {
 // Manager manages the application lifecycle state machine
 type Manager struct {
 	startTime        time.Time
+	lastReport       *ShutdownReport
 	shutdownHandlers []ShutdownHandler
+	observers        []Observer
+	logger           logging.Logger // Receives observer panics; nil discards them
 	state            State
 	shutdownTimeout  time.Duration
 	mu               sync.RWMutex
 }
 
-// ShutdownHandler is a function called during graceful shutdown
+// ShutdownHandler is a function called during graceful shutdown.
+// Handlers with the same Priority run in parallel.
 type ShutdownHandler struct {
 	Handler  func(context.Context) error
 	Name     string
 	Priority int
+	Timeout  time.Duration // Per-handler limit; zero means only the overall shutdown timeout applies
 }
 
 // NewManager creates a new lifecycle manager
@@ -72,23 +79,26 @@ func (m *Manager) GetState() State {
 	return m.state
 }
 
-// SetState transitions to a new state with validation
+// SetState transitions to a new state with validation and notifies observers
 func (m *Manager) SetState(newState State) error {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
 	// Validate state transition
 	if !m.isValidTransition(m.state, newState) {
+		m.mu.Unlock()
 		return fmt.Errorf("invalid state transition from %s to %s", m.state, newState)
 	}
 
+	oldState := m.state
 	m.state = newState
 
 	// Record start time when entering running state
 	if newState == StateRunning {
 		m.startTime = time.Now()
 	}
+	m.mu.Unlock()
 
+	m.notifyObservers(oldState, newState)
 	return nil
 }
 
@@ -2,6 +2,7 @@
 package logging
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
@@ -28,26 +29,62 @@ type Logger interface {
 	Close() error
 }
 
+// DefaultLogFileName is the log file created inside the configured log directory.
+const DefaultLogFileName = "lazynuget.log"
+
+// Options configures a logger created with NewWithOptions.
+type Options struct {
+	// ModuleLevels overrides Level for named subsystems (e.g., "nuget": "debug", "gui": "warn").
+	// Loggers obtained with ForModule use their module's level.
+	ModuleLevels map[string]string
+
+	// Redactor masks secrets in every record before it is written. Nil creates a
+	// redactor that only masks credential-shaped text (Authorization headers, API keys).
+	Redactor *Redactor
+
+	// Level is the default minimum level (debug, info, warn, error). Unknown values mean info.
+	Level string
+
+	// Path is the log file. Empty logs to the console only; otherwise logs go to the console and the file.
+	Path string
+
+	// Console is where logs are written besides the file. Nil is stdout.
+	Console io.Writer
+
+	// Format is "text" (default) or "json".
+	Format string
+}
+
 // slogLogger wraps slog.Logger to implement our Logger interface
 type slogLogger struct {
-	logger  *slog.Logger
-	logFile *os.File // nil if logging to stdout only
+	logger       *slog.Logger
+	logFile      *os.File // nil if logging to stdout only
+	moduleLevels map[string]slog.Level
+	level        slog.Level // Effective level for this logger's module
 }
 
 func (l *slogLogger) Debug(format string, args ...any) {
-	l.logger.Debug(fmt.Sprintf(format, args...))
+	l.log(slog.LevelDebug, format, args...)
 }
 
 func (l *slogLogger) Info(format string, args ...any) {
-	l.logger.Info(fmt.Sprintf(format, args...))
+	l.log(slog.LevelInfo, format, args...)
 }
 
 func (l *slogLogger) Warn(format string, args ...any) {
-	l.logger.Warn(fmt.Sprintf(format, args...))
+	l.log(slog.LevelWarn, format, args...)
 }
 
 func (l *slogLogger) Error(format string, args ...any) {
-	l.logger.Error(fmt.Sprintf(format, args...))
+	l.log(slog.LevelError, format, args...)
+}
+
+// log filters by the module's level before formatting the message.
+func (l *slogLogger) log(level slog.Level, format string, args ...any) {
+	if level < l.level {
+		return
+	}
+	l.logger.Log(context.Background(), level, fmt.Sprintf(format, args...))
 }
 
 func (l *slogLogger) Close() error {
@@ -57,38 +94,66 @@ func (l *slogLogger) Close() error {
 	return nil
 }
 
+// Module returns a logger for a named subsystem. Its records carry a module attribute
+// and use the module's level override, if any. The returned logger shares the output
+// of its parent; closing it is a no-op, so close the parent instead.
+func (l *slogLogger) Module(name string) Logger {
+	level := l.level
+	if moduleLevel, ok := l.moduleLevels[strings.ToLower(name)]; ok {
+		level = moduleLevel
+	}
+	return &slogLogger{
+		logger:       l.logger.With("module", name),
+		moduleLevels: l.moduleLevels,
+		level:        level,
+	}
+}
+
+// ForModule returns a logger for a named subsystem (see Options.ModuleLevels).
+// Loggers that don't support modules are returned unchanged.
+func ForModule(l Logger, name string) Logger {
+	if m, ok := l.(interface{ Module(string) Logger }); ok {
+		return m.Module(name)
+	}
+	return l
+}
+
 // New creates a new logger instance with the specified level and output path.
 // If logPath is empty, logs go to stdout only.
 // If logPath is specified, logs go to both stdout and the file.
 func New(level, logPath string) Logger {
-	// Parse log level
-	var slogLevel slog.Level
-	switch strings.ToLower(level) {
-	case "debug":
-		slogLevel = slog.LevelDebug
-	case "info":
-		slogLevel = slog.LevelInfo
-	case "warn":
-		slogLevel = slog.LevelWarn
-	case "error":
-		slogLevel = slog.LevelError
-	default:
-		slogLevel = slog.LevelInfo
+	return NewWithOptions(Options{Level: level, Path: logPath})
+}
+
+// NewWithOptions creates a logger with a file, format, and per-module levels.
+// If the log file cannot be opened, a warning is printed and logs go to stdout only.
+func NewWithOptions(options Options) Logger {
+	slogLevel := parseLevel(options.Level)
+
+	moduleLevels := make(map[string]slog.Level, len(options.ModuleLevels))
+	minLevel := slogLevel
+	for module, level := range options.ModuleLevels {
+		moduleLevel := parseLevel(level)
+		moduleLevels[strings.ToLower(module)] = moduleLevel
+		minLevel = min(minLevel, moduleLevel)
 	}
 
-	// Create handler options
+	// The handler admits the most verbose configured level; each logger filters by its module
 	opts := &slog.HandlerOptions{
-		Level: slogLevel,
+		Level: minLevel,
 	}
 
 	// Determine output writer
 	var writer io.Writer = os.Stdout
+	if options.Console != nil {
+		writer = options.Console
+	}
 	var logFile *os.File
 
 	// If log path is specified, create multiwriter for both stdout and file
-	if logPath != "" {
+	if options.Path != "" {
 		// Validate and clean log path (security: prevent path traversal)
-		cleanLogPath := filepath.Clean(logPath)
+		cleanLogPath := filepath.Clean(options.Path)
 
 		// Ensure log directory exists (owner-only permissions for security)
 		logDir := filepath.Dir(cleanLogPath)
@@ -101,19 +166,47 @@ func New(level, logPath string) Logger {
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: failed to open log file %s: %v\n", cleanLogPath, err)
 			} else {
-				// Write to both stdout and file
-				writer = io.MultiWriter(os.Stdout, file)
+				// Write to both the console and the file
+				writer = io.MultiWriter(writer, file)
 				logFile = file // Store file handle for later closing
 			}
 		}
 	}
 
-	// Create text handler for human-readable output
-	handler := slog.NewTextHandler(writer, opts)
+	// Text handler for human-readable output; JSON for log shippers
+	var handler slog.Handler
+	if strings.EqualFold(options.Format, "json") {
+		handler = slog.NewJSONHandler(writer, opts)
+	} else {
+		handler = slog.NewTextHandler(writer, opts)
+	}
+
+	// Redact before any sink sees the record
+	redactor := options.Redactor
+	if redactor == nil {
+		redactor = NewRedactor()
+	}
+	handler = &redactingHandler{next: handler, redactor: redactor}
 
 	// Create and return logger
 	return &slogLogger{
-		logger:  slog.New(handler),
-		logFile: logFile,
+		logger:       slog.New(handler),
+		logFile:      logFile,
+		moduleLevels: moduleLevels,
+		level:        slogLevel,
+	}
+}
+
+// parseLevel converts a level name to a slog level. Unknown names mean info.
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
 	}
 }
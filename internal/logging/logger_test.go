@@ -436,3 +436,128 @@ func TestMultipleLogMessages(t *testing.T) {
 		}
 	}
 }
+
+// TestNewWithOptionsJSONFormat verifies the json format writes one JSON object per record
+func TestNewWithOptionsJSONFormat(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "test.log")
+
+	logger := NewWithOptions(Options{Level: "info", Path: logPath, Format: "json"})
+	logger.Info("json message %d", 1)
+	logger.Close()
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+
+	output := strings.TrimSpace(string(content))
+	if !strings.HasPrefix(output, "{") || !strings.Contains(output, `"msg":"json message 1"`) {
+		t.Errorf("Expected JSON record with msg, got: %s", output)
+	}
+}
+
+// TestModuleLevels verifies per-module level overrides
+func TestModuleLevels(t *testing.T) {
+	tests := []struct {
+		name      string
+		module    string
+		message   string
+		logDebug  bool
+		wantInLog bool
+	}{
+		{
+			name:      "module raised to debug logs debug",
+			module:    "nuget",
+			message:   "nuget debug message",
+			logDebug:  true,
+			wantInLog: true,
+		},
+		{
+			name:      "module override matches case-insensitively",
+			module:    "NuGet",
+			message:   "nuget mixed case message",
+			logDebug:  true,
+			wantInLog: true,
+		},
+		{
+			name:      "module lowered to error drops warnings",
+			module:    "gui",
+			message:   "gui warning message",
+			wantInLog: false,
+		},
+		{
+			name:      "module without override uses default level",
+			module:    "config",
+			message:   "config debug message",
+			logDebug:  true,
+			wantInLog: false,
+		},
+		{
+			name:      "module without override logs at default level",
+			module:    "config",
+			message:   "config warning message",
+			wantInLog: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logPath := filepath.Join(t.TempDir(), "test.log")
+
+			logger := NewWithOptions(Options{
+				Level:        "warn",
+				Path:         logPath,
+				ModuleLevels: map[string]string{"nuget": "debug", "gui": "error"},
+			})
+			defer logger.Close()
+
+			moduleLogger := ForModule(logger, tt.module)
+			if tt.logDebug {
+				moduleLogger.Debug(tt.message)
+			} else {
+				moduleLogger.Warn(tt.message)
+			}
+
+			// The default level still applies to the root logger
+			logger.Debug("root debug message")
+
+			content, err := os.ReadFile(logPath)
+			if err != nil {
+				t.Fatalf("Failed to read log file: %v", err)
+			}
+			output := string(content)
+
+			if got := strings.Contains(output, tt.message); got != tt.wantInLog {
+				t.Errorf("message logged = %v, want %v; output: %s", got, tt.wantInLog, output)
+			}
+			if tt.wantInLog && !strings.Contains(output, "module="+tt.module) {
+				t.Errorf("Expected module attribute %q, got: %s", tt.module, output)
+			}
+			if strings.Contains(output, "root debug message") {
+				t.Errorf("Root logger should filter debug at warn level, got: %s", output)
+			}
+		})
+	}
+}
+
+// TestForModuleCloseIsNoOp verifies closing a module logger leaves the parent's file open
+func TestForModuleCloseIsNoOp(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "test.log")
+
+	logger := New("info", logPath)
+	defer logger.Close()
+
+	moduleLogger := ForModule(logger, "dotnet")
+	if err := moduleLogger.Close(); err != nil {
+		t.Fatalf("module Close() error = %v", err)
+	}
+	logger.Info("after module close")
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "after module close") {
+		t.Errorf("Expected parent logger to keep writing after module Close, got: %s", content)
+	}
+}
@@ -0,0 +1,195 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCounter tests labeled counters and their exposition format
+func TestCounter(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("test_requests_total", "Requests.", "method", "code")
+
+	c.Inc("GET", "200")
+	c.Inc("GET", "200")
+	c.Add(3, "POST", "500")
+	c.Add(-1, "GET", "200") // Counters never decrease
+
+	if got := c.Value("GET", "200"); got != 2 {
+		t.Errorf("Value(GET, 200) = %v, want 2", got)
+	}
+
+	var buf bytes.Buffer
+	if err := r.WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus() error = %v", err)
+	}
+	want := `# HELP test_requests_total Requests.
+# TYPE test_requests_total counter
+test_requests_total{method="GET",code="200"} 2
+test_requests_total{method="POST",code="500"} 3
+`
+	if buf.String() != want {
+		t.Errorf("WritePrometheus() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+// TestHistogram tests cumulative buckets, sum, and count
+func TestHistogram(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogram("test_duration_seconds", "Durations.", []float64{1, 0.1}, "op")
+
+	h.Observe(0.05, "load")
+	h.Observe(0.1, "load") // Upper bounds are inclusive
+	h.ObserveDuration(500*time.Millisecond, "load")
+	h.Observe(5, "load")
+
+	if got := h.Count("load"); got != 4 {
+		t.Errorf("Count(load) = %d, want 4", got)
+	}
+
+	var buf bytes.Buffer
+	if err := r.WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus() error = %v", err)
+	}
+	want := `# HELP test_duration_seconds Durations.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{op="load",le="0.1"} 2
+test_duration_seconds_bucket{op="load",le="1"} 3
+test_duration_seconds_bucket{op="load",le="+Inf"} 4
+test_duration_seconds_sum{op="load"} 5.65
+test_duration_seconds_count{op="load"} 4
+`
+	if buf.String() != want {
+		t.Errorf("WritePrometheus() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+// TestGaugeFunc tests gauges read at render time
+func TestGaugeFunc(t *testing.T) {
+	r := NewRegistry()
+	value := 1.0
+	r.NewGaugeFunc("test_gauge", "A gauge.", func() float64 { return value })
+	value = 42
+
+	var buf bytes.Buffer
+	if err := r.WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "# TYPE test_gauge gauge\ntest_gauge 42\n") {
+		t.Errorf("WritePrometheus() = %s, want test_gauge 42", buf.String())
+	}
+}
+
+// TestDuplicateMetricPanics verifies registering a name twice is rejected
+func TestDuplicateMetricPanics(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("test_total", "First.")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for duplicate metric name")
+		}
+	}()
+	r.NewCounter("test_total", "Second.")
+}
+
+// TestStandardMetrics tests the helpers that feed the default registry
+func TestStandardMetrics(t *testing.T) {
+	RecordCacheLookup("test-cache", true)
+	RecordCacheLookup("test-cache", false)
+	RecordCacheLookup("test-cache", true)
+	stop := TimeOperation("test-operation")
+	stop()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: Transport(nil)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if got := CacheRequests.Value("test-cache", "hit"); got != 2 {
+		t.Errorf("cache hits = %v, want 2", got)
+	}
+	if got := CacheRequests.Value("test-cache", "miss"); got != 1 {
+		t.Errorf("cache misses = %v, want 1", got)
+	}
+	if got := OperationDuration.Count("test-operation"); got != 1 {
+		t.Errorf("operation observations = %d, want 1", got)
+	}
+	if got := HTTPRequests.Value("GET", "404"); got < 1 {
+		t.Errorf("HTTP requests GET 404 = %v, want at least 1", got)
+	}
+
+	var buf bytes.Buffer
+	if err := Default.WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus() error = %v", err)
+	}
+	for _, name := range []string{"lazynuget_goroutines ", "lazynuget_heap_alloc_bytes ", "lazynuget_uptime_seconds ", "lazynuget_http_request_duration_seconds_count"} {
+		if !strings.Contains(buf.String(), name) {
+			t.Errorf("default registry output missing %q", name)
+		}
+	}
+}
+
+// TestServe tests the loopback metrics endpoint
+func TestServe(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("test_served_total", "Served.").Inc()
+
+	server, err := Serve("127.0.0.1:0", r)
+	if err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	defer server.Shutdown(context.Background())
+
+	resp, err := http.Get(server.URL())
+	if err != nil {
+		t.Fatalf("Get(%s) error = %v", server.URL(), err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+		t.Errorf("Content-Type = %q, want text/plain", resp.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(string(body), "test_served_total 1") {
+		t.Errorf("body = %s, want test_served_total 1", body)
+	}
+}
+
+// TestServeRejectsNonLoopback verifies metrics are never exposed beyond the local machine
+func TestServeRejectsNonLoopback(t *testing.T) {
+	tests := []struct {
+		addr    string
+		wantErr bool
+	}{
+		{addr: "127.0.0.1:0", wantErr: false},
+		{addr: "localhost:0", wantErr: false},
+		{addr: "[::1]:0", wantErr: false},
+		{addr: "0.0.0.0:0", wantErr: true},
+		{addr: ":0", wantErr: true},
+		{addr: "192.168.1.10:9464", wantErr: true},
+		{addr: "127.0.0.1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			err := checkLoopback(tt.addr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkLoopback(%q) error = %v, wantErr %v", tt.addr, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Path is the URL path of the metrics endpoint.
+const Path = "/metrics"
+
+// DefaultAddr is the address suggested for --metrics-addr and used by `lazynuget metrics dump`.
+const DefaultAddr = "127.0.0.1:9464"
+
+// Handler serves the registry in the Prometheus text exposition format.
+func Handler(r *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.WritePrometheus(w)
+	})
+}
+
+// Server is a running metrics endpoint.
+type Server struct {
+	server   *http.Server
+	listener net.Listener
+}
+
+// Serve starts the metrics endpoint on addr, which must be a loopback address
+// (e.g., 127.0.0.1:9464 or localhost:0 for a random port). Metrics reveal details
+// about the machine's activity, so they are never exposed on other interfaces.
+func Serve(addr string, r *Registry) (*Server, error) {
+	if err := checkLoopback(addr); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(Path, Handler(r))
+	s := &Server{
+		listener: listener,
+		server:   &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second},
+	}
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			_ = listener.Close()
+		}
+	}()
+	return s, nil
+}
+
+// URL returns the full URL of the metrics endpoint.
+func (s *Server) URL() string {
+	return "http://" + s.listener.Addr().String() + Path
+}
+
+// Shutdown stops the endpoint, waiting for in-flight scrapes until ctx is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// checkLoopback rejects addresses that would listen beyond the local machine.
+func checkLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid metrics address %q: %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("metrics address %q must be a loopback address (e.g., 127.0.0.1:9464)", addr)
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"net/http"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// Default is the process-wide registry with LazyNuGet's standard metrics.
+var Default = NewRegistry()
+
+// startTime is used for the uptime gauge.
+var startTime = time.Now()
+
+// Standard metrics recorded by instrumented subsystems.
+var (
+	// HTTPRequests counts outgoing HTTP requests by method and status code ("error" when no response).
+	HTTPRequests = Default.NewCounter("lazynuget_http_requests_total",
+		"Outgoing HTTP requests by method and status code.", "method", "code")
+
+	// HTTPDuration records outgoing HTTP request durations by host.
+	HTTPDuration = Default.NewHistogram("lazynuget_http_request_duration_seconds",
+		"Outgoing HTTP request duration in seconds by host.", nil, "host")
+
+	// CacheRequests counts cache lookups by cache name and result (hit or miss).
+	CacheRequests = Default.NewCounter("lazynuget_cache_requests_total",
+		"Cache lookups by cache and result (hit or miss). Hit rate = hit / (hit + miss).", "cache", "result")
+
+	// OperationDuration records durations of internal operations (startup, config load, process runs).
+	OperationDuration = Default.NewHistogram("lazynuget_operation_duration_seconds",
+		"Duration of internal operations in seconds.", nil, "operation")
+)
+
+func init() {
+	Default.NewGaugeFunc("lazynuget_goroutines", "Number of goroutines.", func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+	Default.NewGaugeFunc("lazynuget_heap_alloc_bytes", "Bytes of allocated heap objects.", func() float64 {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		return float64(stats.HeapAlloc)
+	})
+	Default.NewGaugeFunc("lazynuget_uptime_seconds", "Seconds since the process started.", func() float64 {
+		return time.Since(startTime).Seconds()
+	})
+}
+
+// ObserveOperation records the duration of a named operation.
+func ObserveOperation(operation string, d time.Duration) {
+	OperationDuration.ObserveDuration(d, operation)
+}
+
+// TimeOperation starts timing an operation; call the returned function when it completes.
+//
+//	defer metrics.TimeOperation("config-load")()
+func TimeOperation(operation string) func() {
+	start := time.Now()
+	return func() {
+		ObserveOperation(operation, time.Since(start))
+	}
+}
+
+// RecordCacheLookup counts a cache hit or miss for a named cache.
+func RecordCacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	CacheRequests.Inc(cache, result)
+}
+
+// Transport wraps an http.RoundTripper to record request counts and durations.
+// Nil uses http.DefaultTransport.
+func Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &instrumentedTransport{next: next}
+}
+
+// instrumentedTransport records HTTPRequests and HTTPDuration for every round trip.
+type instrumentedTransport struct {
+	next http.RoundTripper
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	HTTPDuration.ObserveDuration(time.Since(start), req.URL.Host)
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	HTTPRequests.Inc(req.Method, code)
+	return resp, err
+}
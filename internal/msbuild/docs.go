@@ -0,0 +1,35 @@
+package msbuild
+
+import (
+	"strings"
+)
+
+// docsPrefixes maps diagnostic code prefixes to their documentation index. Longer
+// prefixes are listed first so NETSDK is not mistaken for another code family.
+var docsPrefixes = []struct {
+	prefix string
+	url    string
+}{
+	{prefix: "NETSDK", url: "https://learn.microsoft.com/dotnet/core/tools/sdk-errors/"},
+	{prefix: "MSB", url: "https://learn.microsoft.com/visualstudio/msbuild/errors/"},
+	{prefix: "NU", url: "https://learn.microsoft.com/nuget/reference/errors-and-warnings/"},
+	{prefix: "CS", url: "https://learn.microsoft.com/dotnet/csharp/language-reference/compiler-messages/"},
+	{prefix: "FS", url: "https://learn.microsoft.com/dotnet/fsharp/language-reference/compiler-messages/"},
+	{prefix: "BC", url: "https://learn.microsoft.com/dotnet/visual-basic/misc/"},
+	{prefix: "CA", url: "https://learn.microsoft.com/dotnet/fundamentals/code-analysis/quality-rules/"},
+	{prefix: "IDE", url: "https://learn.microsoft.com/dotnet/fundamentals/code-analysis/style-rules/"},
+}
+
+// DocsURL returns the documentation page for a diagnostic code (e.g., NU1605), or ""
+// for codes without a known documentation site.
+func DocsURL(code string) string {
+	code = strings.ToUpper(code)
+	for _, p := range docsPrefixes {
+		number, ok := strings.CutPrefix(code, p.prefix)
+		if !ok || number == "" || strings.Trim(number, "0123456789") != "" {
+			continue
+		}
+		return p.url + strings.ToLower(code)
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package msbuild
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// BuildError reports a failed dotnet command with the diagnostics parsed from its output.
+// The UI shows Diagnostics in the error modal; Output is kept for "show raw output".
+type BuildError struct {
+	Command     string
+	Output      string
+	Diagnostics []Diagnostic
+	ExitCode    int
+}
+
+// NewBuildError parses the output of a failed dotnet command.
+// dotnet writes build diagnostics to stdout and usage errors to stderr, so both are parsed.
+func NewBuildError(command string, result platform.ProcessResult) *BuildError {
+	combined := strings.TrimSpace(strings.TrimSpace(result.Stdout) + "\n" + strings.TrimSpace(result.Stderr))
+	return &BuildError{
+		Command:     command,
+		ExitCode:    result.ExitCode,
+		Output:      combined,
+		Diagnostics: Parse(combined),
+	}
+}
+
+// Error summarizes the failure with its first error diagnostic.
+func (e *BuildError) Error() string {
+	errs := Errors(e.Diagnostics)
+	switch len(errs) {
+	case 0:
+		return fmt.Sprintf("%s failed (exit code %d)", e.Command, e.ExitCode)
+	case 1:
+		return fmt.Sprintf("%s failed: %s", e.Command, errs[0])
+	default:
+		return fmt.Sprintf("%s failed: %s (and %d more errors)", e.Command, errs[0], len(errs)-1)
+	}
+}
+
+// Render writes the diagnostics as a readable list: errors first, each with its location,
+// details, and documentation link. Without diagnostics, the raw output is written.
+func (e *BuildError) Render(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s failed (exit code %d)\n", e.Command, e.ExitCode)
+
+	if len(e.Diagnostics) == 0 {
+		if e.Output != "" {
+			sb.WriteString("\n" + e.Output + "\n")
+		}
+		_, err := io.WriteString(w, sb.String())
+		return err
+	}
+
+	for _, severity := range []Severity{SeverityError, SeverityWarning, SeverityInfo} {
+		for _, d := range e.Diagnostics {
+			if d.Severity != severity {
+				continue
+			}
+			sb.WriteString("\n" + string(d.Severity))
+			if d.Code != "" {
+				sb.WriteString(" " + d.Code)
+			}
+			sb.WriteString(": " + d.Message + "\n")
+			for _, detail := range d.Details {
+				sb.WriteString("    " + detail + "\n")
+			}
+			if loc := d.Location(); loc != "" {
+				sb.WriteString("  at " + loc + "\n")
+			}
+			if d.Project != "" && d.Project != d.File {
+				sb.WriteString("  in " + d.Project + "\n")
+			}
+			if url := d.DocsURL(); url != "" {
+				sb.WriteString("  see " + url + "\n")
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
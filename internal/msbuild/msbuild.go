@@ -0,0 +1,196 @@
+// Package msbuild parses the diagnostics that dotnet, MSBuild, NuGet restore, and the
+// compilers print (e.g., "App.csproj : error NU1605: Detected package downgrade ...")
+// into structured values, so failures can be shown as a list of errors with codes,
+// locations, and documentation links instead of raw output.
+package msbuild
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Severity is the category of a diagnostic.
+type Severity string
+
+// Diagnostic severities, in decreasing order of importance.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Diagnostic is one error, warning, or message reported by a dotnet command.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code,omitempty"`    // e.g., NU1605, CS0246, MSB3644, NETSDK1045
+	Message  string   `json:"message"`           // First line of the message
+	Details  []string `json:"details,omitempty"` // Continuation lines (e.g., NU1605 dependency paths)
+	File     string   `json:"file,omitempty"`    // Source or project file, when reported
+	Tool     string   `json:"tool,omitempty"`    // Reporting tool when there is no file (e.g., MSBUILD, CSC)
+	Project  string   `json:"project,omitempty"` // Project being built (the trailing [path])
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+}
+
+// DocsURL returns the documentation page for the diagnostic's code, or "".
+func (d Diagnostic) DocsURL() string {
+	return DocsURL(d.Code)
+}
+
+// Location renders the file and position (e.g., "Program.cs(10,5)"), the tool, or "".
+func (d Diagnostic) Location() string {
+	switch {
+	case d.File == "":
+		return d.Tool
+	case d.Line > 0 && d.Column > 0:
+		return fmt.Sprintf("%s(%d,%d)", d.File, d.Line, d.Column)
+	case d.Line > 0:
+		return fmt.Sprintf("%s(%d)", d.File, d.Line)
+	default:
+		return d.File
+	}
+}
+
+// String renders the diagnostic in MSBuild's canonical format.
+func (d Diagnostic) String() string {
+	var sb strings.Builder
+	if loc := d.Location(); loc != "" {
+		sb.WriteString(loc + ": ")
+	}
+	sb.WriteString(string(d.Severity))
+	if d.Code != "" {
+		sb.WriteString(" " + d.Code)
+	}
+	sb.WriteString(": " + d.Message)
+	return sb.String()
+}
+
+var (
+	// canonicalPattern matches MSBuild's canonical error format:
+	//   origin : [subcategory] category code : text [project]
+	// where origin is a file with an optional (line,col) position or a tool name.
+	canonicalPattern = regexp.MustCompile(`^(?:(.*?)\s*:\s+)?(?:[^:]*?\s+)?(error|warning|info|message)(?:\s+([A-Za-z]+\d+))?\s*:\s*(.*)$`)
+
+	// nugetPattern matches the NuGet CLI format printed by `dotnet add package` and
+	// `dotnet list package` (e.g., "error: NU1102: Unable to find package ...").
+	nugetPattern = regexp.MustCompile(`^(error|warn|warning|info):\s+(?:([A-Za-z]+\d+):\s+)?(.*)$`)
+
+	// nugetContinuation matches indented follow-up lines of a NuGet CLI diagnostic
+	// (e.g., "error:   - Found 3 version(s) in nuget.org [ Nearest version: 13.0.3 ]").
+	nugetContinuation = regexp.MustCompile(`^(?:error|warn|warning|info):\s{2,}(\S.*)$`)
+
+	// projectSuffix matches the trailing " [path/to/App.csproj]" MSBuild appends.
+	projectSuffix = regexp.MustCompile(`\s+\[([^\[\]]+)\]$`)
+
+	// positionPattern splits "file(line,col)" and "file(line,col,endLine,endCol)".
+	positionPattern = regexp.MustCompile(`^(.*)\((\d+)(?:,(\d+))?(?:,\d+,\d+)?\)$`)
+
+	// summaryPattern matches build summary lines that must not be taken as details.
+	summaryPattern = regexp.MustCompile(`^(\d+ (Warning|Error)\(s\)|Time Elapsed .*|Build (FAILED|succeeded)\.?.*)$`)
+)
+
+// Parse extracts diagnostics from the combined output of a dotnet command. Diagnostics
+// repeated in the build summary are reported once, in order of first appearance.
+func Parse(output string) []Diagnostic {
+	var diagnostics []Diagnostic
+	seen := make(map[string]bool)
+	last := -1 // Index of the diagnostic that indented lines continue, or -1
+
+	for _, raw := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			last = -1
+			continue
+		}
+
+		if m := nugetContinuation.FindStringSubmatch(line); m != nil && last >= 0 {
+			diagnostics[last].Details = append(diagnostics[last].Details, m[1])
+			continue
+		}
+
+		d, ok := parseLine(line)
+		if !ok {
+			if last >= 0 && raw != line && !summaryPattern.MatchString(line) {
+				diagnostics[last].Details = append(diagnostics[last].Details, line)
+			} else {
+				last = -1
+			}
+			continue
+		}
+
+		key := d.String() + "\x00" + d.Project
+		if seen[key] {
+			last = -1
+			continue
+		}
+		seen[key] = true
+		diagnostics = append(diagnostics, d)
+		last = len(diagnostics) - 1
+	}
+
+	return diagnostics
+}
+
+// parseLine parses a single diagnostic line.
+func parseLine(line string) (Diagnostic, bool) {
+	if m := nugetPattern.FindStringSubmatch(line); m != nil {
+		return Diagnostic{Severity: parseSeverity(m[1]), Code: strings.ToUpper(m[2]), Message: m[3]}, true
+	}
+
+	m := canonicalPattern.FindStringSubmatch(line)
+	if m == nil || (m[1] == "" && m[3] == "") {
+		// Without an origin or code, "error: ..." is ordinary text (e.g., a log line)
+		return Diagnostic{}, false
+	}
+
+	d := Diagnostic{Severity: parseSeverity(m[2]), Code: strings.ToUpper(m[3]), Message: m[4]}
+	if pm := projectSuffix.FindStringSubmatch(d.Message); pm != nil {
+		d.Project = pm[1]
+		d.Message = strings.TrimSpace(strings.TrimSuffix(d.Message, pm[0]))
+	}
+	parseOrigin(&d, m[1])
+	return d, true
+}
+
+// parseOrigin fills the file, position, or tool from the origin part of a diagnostic.
+func parseOrigin(d *Diagnostic, origin string) {
+	if origin == "" {
+		return
+	}
+	if pm := positionPattern.FindStringSubmatch(origin); pm != nil {
+		d.File = pm[1]
+		d.Line, _ = strconv.Atoi(pm[2])
+		d.Column, _ = strconv.Atoi(pm[3])
+		return
+	}
+	if strings.ContainsAny(origin, `/\.`) {
+		d.File = origin
+		return
+	}
+	d.Tool = origin
+}
+
+// parseSeverity maps MSBuild and NuGet category names to a Severity.
+func parseSeverity(category string) Severity {
+	switch strings.ToLower(category) {
+	case "error":
+		return SeverityError
+	case "warn", "warning":
+		return SeverityWarning
+	default:
+		return SeverityInfo
+	}
+}
+
+// Errors returns the diagnostics with error severity.
+func Errors(diagnostics []Diagnostic) []Diagnostic {
+	var errs []Diagnostic
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			errs = append(errs, d)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,167 @@
+package msbuild
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// TestParse tests extracting diagnostics from MSBuild, compiler, and NuGet output
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []Diagnostic
+	}{
+		{
+			name:   "restore error with project",
+			output: "/src/App/App.csproj : error NU1605: Warning As Error: Detected package downgrade: Serilog from 3.1.0 to 3.0.0. [/src/App/App.csproj]",
+			want: []Diagnostic{{
+				Severity: SeverityError, Code: "NU1605", File: "/src/App/App.csproj", Project: "/src/App/App.csproj",
+				Message: "Warning As Error: Detected package downgrade: Serilog from 3.1.0 to 3.0.0.",
+			}},
+		},
+		{
+			name:   "compiler error with position",
+			output: "  Program.cs(10,5): error CS0246: The type or namespace name 'Foo' could not be found [/src/App/App.csproj]",
+			want: []Diagnostic{{
+				Severity: SeverityError, Code: "CS0246", File: "Program.cs", Line: 10, Column: 5, Project: "/src/App/App.csproj",
+				Message: "The type or namespace name 'Foo' could not be found",
+			}},
+		},
+		{
+			name:   "windows path with range",
+			output: `C:\src\App\Program.cs(3,1,3,9): warning CS8600: Converting null literal. [C:\src\App\App.csproj]`,
+			want: []Diagnostic{{
+				Severity: SeverityWarning, Code: "CS8600", File: `C:\src\App\Program.cs`, Line: 3, Column: 1, Project: `C:\src\App\App.csproj`,
+				Message: "Converting null literal.",
+			}},
+		},
+		{
+			name:   "tool origin",
+			output: "MSBUILD : error MSB1009: Project file does not exist.",
+			want:   []Diagnostic{{Severity: SeverityError, Code: "MSB1009", Tool: "MSBUILD", Message: "Project file does not exist."}},
+		},
+		{
+			name: "nuget cli with continuation",
+			output: "info : Adding PackageReference for package 'Foo' into project 'App.csproj'.\n" +
+				"error: NU1102: Unable to find package Foo with version (>= 99.0.0)\n" +
+				"error:   - Found 12 version(s) in nuget.org [ Nearest version: 13.0.3 ]\n" +
+				"error: Package 'Foo' is incompatible with 'all' frameworks in project 'App.csproj'.",
+			want: []Diagnostic{
+				{
+					Severity: SeverityError, Code: "NU1102", Message: "Unable to find package Foo with version (>= 99.0.0)",
+					Details: []string{"- Found 12 version(s) in nuget.org [ Nearest version: 13.0.3 ]"},
+				},
+				{Severity: SeverityError, Message: "Package 'Foo' is incompatible with 'all' frameworks in project 'App.csproj'."},
+			},
+		},
+		{
+			name: "indented details and summary dedup",
+			output: "/src/App/App.csproj : warning NU1605: Detected package downgrade: Serilog from 3.1.0 to 3.0.0.\n" +
+				"   App -> Serilog.Sinks.File 5.0.0 -> Serilog (>= 3.1.0)\n" +
+				"   App -> Serilog (>= 3.0.0)\n" +
+				"\n" +
+				"Build succeeded.\n" +
+				"\n" +
+				"/src/App/App.csproj : warning NU1605: Detected package downgrade: Serilog from 3.1.0 to 3.0.0.\n" +
+				"    1 Warning(s)\n" +
+				"    0 Error(s)\n",
+			want: []Diagnostic{{
+				Severity: SeverityWarning, Code: "NU1605", File: "/src/App/App.csproj",
+				Message: "Detected package downgrade: Serilog from 3.1.0 to 3.0.0.",
+				Details: []string{"App -> Serilog.Sinks.File 5.0.0 -> Serilog (>= 3.1.0)", "App -> Serilog (>= 3.0.0)"},
+			}},
+		},
+		{
+			name:   "ordinary output",
+			output: "  Determining projects to restore...\n  All projects are up-to-date for restore.\nerror handling is configured\n",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.output)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Parse() returned %d diagnostics, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if !equalDiagnostic(got[i], tt.want[i]) {
+					t.Errorf("Parse()[%d] =\n  %+v\nwant\n  %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// equalDiagnostic compares diagnostics field by field.
+func equalDiagnostic(a, b Diagnostic) bool {
+	return a.Severity == b.Severity && a.Code == b.Code && a.Message == b.Message &&
+		a.File == b.File && a.Tool == b.Tool && a.Project == b.Project &&
+		a.Line == b.Line && a.Column == b.Column && slices.Equal(a.Details, b.Details)
+}
+
+// TestDocsURL tests documentation links per code family
+func TestDocsURL(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{code: "NU1605", want: "https://learn.microsoft.com/nuget/reference/errors-and-warnings/nu1605"},
+		{code: "cs0246", want: "https://learn.microsoft.com/dotnet/csharp/language-reference/compiler-messages/cs0246"},
+		{code: "MSB3644", want: "https://learn.microsoft.com/visualstudio/msbuild/errors/msb3644"},
+		{code: "NETSDK1045", want: "https://learn.microsoft.com/dotnet/core/tools/sdk-errors/netsdk1045"},
+		{code: "XYZ123", want: ""},
+		{code: "NU", want: ""},
+		{code: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := DocsURL(tt.code); got != tt.want {
+				t.Errorf("DocsURL(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestBuildError tests the error summary and rendered diagnostic list
+func TestBuildError(t *testing.T) {
+	err := NewBuildError("dotnet build", platform.ProcessResult{
+		ExitCode: 1,
+		Stdout: "Program.cs(1,1): warning CS8600: Converting null literal. [/src/App.csproj]\n" +
+			"Program.cs(10,5): error CS0246: The type 'Foo' could not be found [/src/App.csproj]\n" +
+			"/src/App.csproj : error NU1605: Detected package downgrade [/src/App.csproj]\n",
+	})
+
+	if got := err.Error(); got != "dotnet build failed: Program.cs(10,5): error CS0246: The type 'Foo' could not be found (and 1 more errors)" {
+		t.Errorf("Error() = %q", got)
+	}
+
+	var sb strings.Builder
+	if renderErr := err.Render(&sb); renderErr != nil {
+		t.Fatalf("Render() error = %v", renderErr)
+	}
+	out := sb.String()
+	if strings.Index(out, "error CS0246") > strings.Index(out, "warning CS8600") {
+		t.Errorf("Render() should list errors before warnings:\n%s", out)
+	}
+	for _, want := range []string{"  at Program.cs(10,5)\n  in /src/App.csproj\n", "  see https://learn.microsoft.com/nuget/reference/errors-and-warnings/nu1605\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Render() missing %q in:\n%s", want, out)
+		}
+	}
+
+	raw := NewBuildError("dotnet restore", platform.ProcessResult{ExitCode: 2, Stderr: "Something unexpected happened"})
+	if got := raw.Error(); got != "dotnet restore failed (exit code 2)" {
+		t.Errorf("Error() = %q", got)
+	}
+	sb.Reset()
+	_ = raw.Render(&sb)
+	if !strings.Contains(sb.String(), "Something unexpected happened") {
+		t.Errorf("Render() without diagnostics should include the raw output, got:\n%s", sb.String())
+	}
+}
@@ -0,0 +1,226 @@
+// Package notes keeps scratch notes per workspace.
+//
+// While triaging updates it helps to jot down which packages need a follow-up ("Serilog 4
+// drops netstandard2.0, check the analyzers project"). Notes are kept with the rest of
+// lazynuget's state, in a file per workspace, so they are there on the next run in the
+// same repository and never end up in it. They export to a markdown checklist for an
+// issue or a PR description.
+package notes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// Kind is the JSON document kind of `lazynuget notes list --json`.
+const Kind = "notes"
+
+// DirName is the directory under the state directory that holds the notes files.
+const DirName = "notes"
+
+// Note is a scratch note, optionally about a package.
+type Note struct {
+	Created time.Time `json:"created"`
+	Text    string    `json:"text"`
+	Package string    `json:"package,omitempty"` // Package ID the note is about
+	ID      int       `json:"id"`                // Stable number, never reused in a workspace
+	Done    bool      `json:"done,omitempty"`
+}
+
+// file is the JSON file of a workspace's notes.
+type file struct {
+	Workspace string `json:"workspace"` // For people looking in the directory; files are named by hash
+	Notes     []Note `json:"notes"`
+	NextID    int    `json:"nextId"`
+}
+
+// Store holds the notes of a workspace.
+type Store struct {
+	path string
+	file file
+	mu   sync.Mutex
+}
+
+// DefaultDir returns the platform notes directory (<data dir>/notes), with its temp
+// fallback applied and notes from the config directory moved over (see platform.DataPath).
+func DefaultDir() string {
+	return platform.DataPath(platform.DirState, DirName)
+}
+
+// Path returns the notes file of a workspace in dir.
+func Path(dir, workspace string) string {
+	sum := sha256.Sum256([]byte(filepath.Clean(workspace)))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// Open reads the notes of a workspace from dir. A workspace without notes yields an empty
+// store, and no file is written until a note is.
+func Open(dir, workspace string) (*Store, error) {
+	s := &Store{path: Path(dir, workspace), file: file{Workspace: workspace, NextID: 1}}
+
+	// #nosec G304 -- path is a notes file in the user's config directory
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notes: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.file); err != nil {
+		return nil, fmt.Errorf("invalid notes file %s: %w", s.path, err)
+	}
+	for _, n := range s.file.Notes {
+		s.file.NextID = max(s.file.NextID, n.ID+1)
+	}
+	return s, nil
+}
+
+// Path returns the notes file location.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Notes returns the notes in the order they were added.
+func (s *Store) Notes() []Note {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.file.Notes)
+}
+
+// Add adds a note and saves the store.
+func (s *Store) Add(text, pkg string) (Note, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Note{}, errors.New("empty note")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := Note{ID: s.file.NextID, Text: text, Package: strings.TrimSpace(pkg), Created: time.Now().UTC()}
+	s.file.NextID++
+	s.file.Notes = append(s.file.Notes, n)
+	return n, s.saveLocked()
+}
+
+// SetDone checks or unchecks a note and saves the store.
+func (s *Store) SetDone(id int, done bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, err := s.indexLocked(id)
+	if err != nil {
+		return err
+	}
+	s.file.Notes[i].Done = done
+	return s.saveLocked()
+}
+
+// Remove deletes a note and saves the store.
+func (s *Store) Remove(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, err := s.indexLocked(id)
+	if err != nil {
+		return err
+	}
+	s.file.Notes = slices.Delete(s.file.Notes, i, i+1)
+	return s.saveLocked()
+}
+
+// Clear deletes the notes that are done, or all of them, and saves the store. It returns
+// how many it deleted.
+func (s *Store) Clear(all bool) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.file.Notes)
+	s.file.Notes = slices.DeleteFunc(s.file.Notes, func(n Note) bool { return all || n.Done })
+	removed := before - len(s.file.Notes)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, s.saveLocked()
+}
+
+// indexLocked returns the index of the note with an ID.
+func (s *Store) indexLocked(id int) (int, error) {
+	i := slices.IndexFunc(s.file.Notes, func(n Note) bool { return n.ID == id })
+	if i < 0 {
+		return 0, fmt.Errorf("no note %d", id)
+	}
+	return i, nil
+}
+
+// saveLocked writes the store, removing the file when no notes are left.
+func (s *Store) saveLocked() error {
+	if len(s.file.Notes) == 0 {
+		if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove notes: %w", err)
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(s.file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode notes: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create notes directory: %w", err)
+	}
+
+	if err := platform.WriteFileAtomic(s.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write notes: %w", err)
+	}
+	return nil
+}
+
+// WriteMarkdown writes notes as a markdown checklist under a heading: general notes
+// first, then a section per package in the order the packages were first noted.
+func WriteMarkdown(w io.Writer, title string, notes []Note) error {
+	var packages []string
+	byPackage := make(map[string][]Note)
+	for _, n := range notes {
+		if _, ok := byPackage[n.Package]; !ok && n.Package != "" {
+			packages = append(packages, n.Package)
+		}
+		byPackage[n.Package] = append(byPackage[n.Package], n)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", title)
+	if len(notes) == 0 {
+		b.WriteString("\nNo notes.\n")
+	}
+	writeItems := func(items []Note) {
+		b.WriteString("\n")
+		for _, n := range items {
+			check := " "
+			if n.Done {
+				check = "x"
+			}
+			// Continuation lines are indented so a multi-line note stays one item
+			fmt.Fprintf(&b, "- [%s] %s\n", check, strings.ReplaceAll(n.Text, "\n", "\n  "))
+		}
+	}
+	if general := byPackage[""]; len(general) > 0 {
+		writeItems(general)
+	}
+	for _, pkg := range packages {
+		fmt.Fprintf(&b, "\n## %s\n", pkg)
+		writeItems(byPackage[pkg])
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,105 @@
+package notes
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestStore tests adding, checking, and removing notes, and that they persist per workspace
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(dir, "/src/app")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if _, err := os.Stat(store.Path()); !os.IsNotExist(err) {
+		t.Errorf("Open() created %s before any note was added", store.Path())
+	}
+	if _, err := store.Add("  ", ""); err == nil {
+		t.Error("Add() of an empty note succeeded")
+	}
+
+	first, err := store.Add("check the analyzers project", "Serilog")
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	second, err := store.Add("ask about the audit", "")
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if first.ID != 1 || second.ID != 2 {
+		t.Errorf("IDs = %d, %d, want 1, 2", first.ID, second.ID)
+	}
+	if err := store.SetDone(first.ID, true); err != nil {
+		t.Fatalf("SetDone() error = %v", err)
+	}
+	if err := store.SetDone(7, true); err == nil {
+		t.Error("SetDone() of a missing note succeeded")
+	}
+
+	reopened, err := Open(dir, "/src/app/")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	got := reopened.Notes()
+	if len(got) != 2 || !got[0].Done || got[0].Package != "Serilog" || got[1].Text != "ask about the audit" {
+		t.Errorf("reopened Notes() = %+v", got)
+	}
+
+	// IDs are not reused after a removal
+	if err := reopened.Remove(second.ID); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	third, err := reopened.Add("pin Newtonsoft.Json", "")
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if third.ID != 3 {
+		t.Errorf("ID after a removal = %d, want 3", third.ID)
+	}
+
+	other, err := Open(dir, "/src/other")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if len(other.Notes()) != 0 {
+		t.Errorf("another workspace has notes %+v", other.Notes())
+	}
+
+	if n, err := reopened.Clear(false); err != nil || n != 1 {
+		t.Errorf("Clear(false) = %d, %v, want 1 done note removed", n, err)
+	}
+	if n, err := reopened.Clear(true); err != nil || n != 1 {
+		t.Errorf("Clear(true) = %d, %v, want 1", n, err)
+	}
+	if _, err := os.Stat(reopened.Path()); !os.IsNotExist(err) {
+		t.Errorf("notes file left after clearing every note: %v", err)
+	}
+}
+
+// TestWriteMarkdown tests the checklist export
+func TestWriteMarkdown(t *testing.T) {
+	notes := []Note{
+		{ID: 1, Text: "check the analyzers project", Package: "Serilog", Done: true},
+		{ID: 2, Text: "ask about the audit\nbefore Friday"},
+		{ID: 3, Text: "wait for 13.0.4", Package: "Newtonsoft.Json"},
+		{ID: 4, Text: "update the sinks too", Package: "Serilog"},
+	}
+	var b strings.Builder
+	if err := WriteMarkdown(&b, "Notes for app", notes); err != nil {
+		t.Fatalf("WriteMarkdown() error = %v", err)
+	}
+	want := "# Notes for app\n\n" +
+		"- [ ] ask about the audit\n  before Friday\n\n" +
+		"## Serilog\n\n- [x] check the analyzers project\n- [ ] update the sinks too\n\n" +
+		"## Newtonsoft.Json\n\n- [ ] wait for 13.0.4\n"
+	if b.String() != want {
+		t.Errorf("WriteMarkdown() =\n%s\nwant\n%s", b.String(), want)
+	}
+
+	b.Reset()
+	if err := WriteMarkdown(&b, "Notes", nil); err != nil || b.String() != "# Notes\n\nNo notes.\n" {
+		t.Errorf("WriteMarkdown() of no notes = %q, %v", b.String(), err)
+	}
+}
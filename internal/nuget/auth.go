@@ -0,0 +1,53 @@
+package nuget
+
+import "net/http"
+
+// APIKeyHeader carries a feed API key, as NuGet clients send it.
+const APIKeyHeader = "X-NuGet-ApiKey"
+
+// Credentials authenticate requests to a feed. Username and Password are sent as basic
+// authentication (e.g., an Azure DevOps PAT), APIKey in the X-NuGet-ApiKey header.
+type Credentials struct {
+	APIKey   string
+	Username string
+	Password string
+}
+
+// Empty reports whether c authenticates nothing.
+func (c Credentials) Empty() bool {
+	return c.APIKey == "" && c.Password == ""
+}
+
+// authTransport adds the credentials looked up for each request, see AuthTransport.
+type authTransport struct {
+	next        http.RoundTripper
+	credentials func(*http.Request) Credentials
+}
+
+// AuthTransport wraps an http.RoundTripper to authenticate each request with the
+// credentials returns for it: those of the configured feed on the request's host, so a
+// redirect to another host never carries them. Credentials are looked up for every
+// request, so a changed setting applies to the next one. Requests that already carry an
+// Authorization header keep it. Nil uses http.DefaultTransport.
+func AuthTransport(next http.RoundTripper, credentials func(*http.Request) Credentials) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &authTransport{next: next, credentials: credentials}
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	creds := t.credentials(req)
+	if creds.Empty() {
+		return t.next.RoundTrip(req)
+	}
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(req.Context())
+	if creds.Password != "" && req.Header.Get("Authorization") == "" {
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
+	if creds.APIKey != "" && req.Header.Get(APIKeyHeader) == "" {
+		req.Header.Set(APIKeyHeader, creds.APIKey)
+	}
+	return t.next.RoundTrip(req)
+}
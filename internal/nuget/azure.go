@@ -0,0 +1,78 @@
+package nuget
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+)
+
+// ErrUnauthorized is returned when a feed rejects the credentials (or asks for some).
+var ErrUnauthorized = errors.New("the feed rejected the credentials")
+
+// azureName matches organization, project, and feed names of Azure DevOps. Names may have
+// spaces and most punctuation, but never a path separator.
+var azureName = regexp.MustCompile(`^[^/\\?#%]+$`)
+
+// AzureArtifactsURL returns the service index of an Azure Artifacts feed. The project is
+// "" for feeds scoped to the organization.
+func AzureArtifactsURL(organization, project, feed string) (string, error) {
+	for _, part := range []struct{ kind, name string }{{"organization", organization}, {"feed", feed}} {
+		if !azureName.MatchString(part.name) {
+			return "", fmt.Errorf("invalid Azure DevOps %s name %q", part.kind, part.name)
+		}
+	}
+	path := url.PathEscape(organization)
+	if project != "" {
+		if !azureName.MatchString(project) {
+			return "", fmt.Errorf("invalid Azure DevOps project name %q", project)
+		}
+		path += "/" + url.PathEscape(project)
+	}
+	return "https://pkgs.dev.azure.com/" + path + "/_packaging/" + url.PathEscape(feed) + "/nuget/v3/index.json", nil
+}
+
+// CheckSource reads a V3 service index with basic authentication, to test a source and
+// its credentials before they are saved. It returns ErrUnauthorized when the feed
+// rejects the credentials.
+func CheckSource(ctx context.Context, client *http.Client, indexURL, username, password string) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, indexURL, nil)
+	if err != nil {
+		return err
+	}
+	if password != "" {
+		req.SetBasicAuth(username, password)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", indexURL, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNonAuthoritativeInfo:
+		// Azure DevOps answers some unauthenticated requests with a 203 sign-in page
+		return ErrUnauthorized
+	case http.StatusNotFound:
+		return fmt.Errorf("%s not found; check the organization, project, and feed names", indexURL)
+	default:
+		return fmt.Errorf("failed to read %s: %s", indexURL, resp.Status)
+	}
+
+	var index struct {
+		Version   string `json:"version"`
+		Resources []any  `json:"resources"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxIndexSize)).Decode(&index); err != nil || len(index.Resources) == 0 {
+		return fmt.Errorf("%s is not a NuGet V3 service index", indexURL)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package nuget
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrNotSupported is returned for operations a feed has no resource for.
+var ErrNotSupported = errors.New("not supported by the feed")
+
+// Resource types of a V3 service index, most preferred first. Private servers often
+// advertise only older versions of a resource, or leave some out altogether.
+var (
+	flatContainerTypes = []string{"PackageBaseAddress/3.0.0"}
+	searchTypes        = []string{"SearchQueryService/3.5.0", "SearchQueryService/3.0.0-rc", "SearchQueryService/3.0.0-beta", "SearchQueryService"}
+	registrationTypes  = []string{
+		"RegistrationsBaseUrl/3.6.0", "RegistrationsBaseUrl/Versioned", "RegistrationsBaseUrl/3.4.0",
+		"RegistrationsBaseUrl/3.0.0-rc", "RegistrationsBaseUrl/3.0.0-beta", "RegistrationsBaseUrl",
+	}
+	vulnerabilityTypes = []string{"VulnerabilityInfo/6.7.0"}
+)
+
+// serverHeaders identify private NuGet servers by a response header and what it contains.
+var serverHeaders = []struct{ header, contains, server string }{
+	{"X-Artifactory-Id", "", "Artifactory"},
+	{"Server", "artifactory", "Artifactory"},
+	{"Server", "nexus", "Nexus"},
+	{"X-ProGet-Version", "", "ProGet"},
+	{"Server", "proget", "ProGet"},
+}
+
+// ProbeFeed reads a V3 service index and returns a feed with the resources the server
+// advertises. Resources it leaves out stay empty, and the feed works around them: versions
+// come from the registration service when there is no flat container, and search falls
+// back to an exact package ID lookup.
+func ProbeFeed(ctx context.Context, client *http.Client, indexURL string) (*Feed, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, indexURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the service index %s: %w", indexURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to read the service index: %w", &StatusError{URL: indexURL, Status: resp.Status, StatusCode: resp.StatusCode})
+	}
+
+	var index struct {
+		Resources []struct {
+			ID   string          `json:"@id"`
+			Type json.RawMessage `json:"@type"` // A string, or a list of aliases on some servers
+		} `json:"resources"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxIndexSize)).Decode(&index); err != nil {
+		return nil, fmt.Errorf("%s is not a NuGet V3 service index: %w", indexURL, err)
+	}
+	urls := make(map[string]string) // Resource type -> first URL
+	for _, r := range index.Resources {
+		var types []string
+		if json.Unmarshal(r.Type, &types) != nil {
+			var t string
+			_ = json.Unmarshal(r.Type, &t)
+			types = []string{t}
+		}
+		for _, t := range types {
+			if _, ok := urls[t]; !ok && r.ID != "" {
+				urls[t] = r.ID
+			}
+		}
+	}
+	resource := func(types []string) string {
+		for _, t := range types {
+			if u, ok := urls[t]; ok {
+				return u
+			}
+		}
+		return ""
+	}
+
+	f := &Feed{
+		HTTPClient:       client,
+		BaseURL:          resource(flatContainerTypes),
+		SearchURL:        resource(searchTypes),
+		RegistrationURL:  resource(registrationTypes),
+		VulnerabilityURL: resource(vulnerabilityTypes),
+	}
+	for _, h := range serverHeaders {
+		if v := resp.Header.Get(h.header); v != "" && strings.Contains(strings.ToLower(v), h.contains) {
+			f.Server = h.server
+			break
+		}
+	}
+	if f.BaseURL == "" && f.RegistrationURL == "" {
+		return nil, fmt.Errorf("the service index %s has neither a package base address nor a registration service", indexURL)
+	}
+	return f, nil
+}
+
+// registrationEntry is a package version in a registration index.
+type registrationEntry struct {
+	Version   string    `json:"version"`
+	Published time.Time `json:"published"`
+	Listed    *bool     `json:"listed"`
+}
+
+// maxRegistrationPages bounds the pages of a registration index read for one package.
+const maxRegistrationPages = 50
+
+// registrationEntries returns every version of a package in its registration index.
+// Pages the index does not inline are fetched, as the protocol allows; some servers
+// never inline them. A package the feed does not have has no entries.
+func (f *Feed) registrationEntries(ctx context.Context, id string) ([]registrationEntry, error) {
+	if f.RegistrationURL == "" {
+		return nil, fmt.Errorf("%w: the feed has no registration service", ErrNotSupported)
+	}
+	type page struct {
+		ID    string `json:"@id"`
+		Items []struct {
+			CatalogEntry registrationEntry `json:"catalogEntry"`
+		} `json:"items"`
+	}
+	var index struct {
+		Items []page `json:"items"`
+	}
+	url := strings.TrimSuffix(f.RegistrationURL, "/") + "/" + strings.ToLower(id) + "/index.json"
+	if found, err := f.getRegistration(ctx, url, &index); err != nil || !found {
+		return nil, err
+	}
+
+	var entries []registrationEntry
+	for i, p := range index.Items {
+		if p.Items == nil && p.ID != "" {
+			if i >= maxRegistrationPages {
+				break
+			}
+			if _, err := f.getRegistration(ctx, p.ID, &p); err != nil {
+				return nil, err
+			}
+		}
+		for _, item := range p.Items {
+			entries = append(entries, item.CatalogEntry)
+		}
+	}
+	return entries, nil
+}
+
+// publishedFromIndex finds when a version was published in the package's registration
+// index. It returns the zero time when the index does not have the version.
+func (f *Feed) publishedFromIndex(ctx context.Context, id, version string) (time.Time, error) {
+	entries, err := f.registrationEntries(ctx, id)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read the metadata of %s %s: %w", id, version, err)
+	}
+	for _, e := range entries {
+		if strings.EqualFold(e.Version, version) && e.Published.Year() > 1900 {
+			return e.Published, nil
+		}
+	}
+	return time.Time{}, nil
+}
+
+// getRegistration decodes a registration resource. It returns false when the feed does
+// not have it.
+func (f *Feed) getRegistration(ctx context.Context, url string, v any) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := f.client().Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to read package metadata: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to read package metadata: %w", &StatusError{URL: url, Status: resp.Status, StatusCode: resp.StatusCode})
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxIndexSize)).Decode(v); err != nil {
+		return false, fmt.Errorf("failed to parse package metadata from %s: %w", url, err)
+	}
+	return true, nil
+}
+
+// searchByID stands in for search on feeds without a search service: a query that is a
+// package ID finds that package, at its latest version.
+func (f *Feed) searchByID(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	id := strings.TrimSpace(query)
+	if id == "" || strings.ContainsAny(id, " \t:") || opts.Skip > 0 {
+		return nil, nil
+	}
+	versions, err := f.Versions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	latest := Latest(versions, opts.Prerelease)
+	if latest == "" {
+		return nil, nil
+	}
+	return []SearchResult{{ID: id, Version: latest, Versions: []VersionDownloads{}}}, nil
+}
+
+// pageResults applies paging and the prerelease filter to search results from servers
+// that ignore them: a page longer than asked for means the server returned every match.
+func pageResults(results []SearchResult, opts SearchOptions) []SearchResult {
+	if !opts.Prerelease {
+		stable := results[:0]
+		for _, r := range results {
+			if !IsPrerelease(r.Version) {
+				stable = append(stable, r)
+				continue
+			}
+			// Show the latest stable version, if the server listed the versions
+			versions := make([]string, len(r.Versions))
+			for i, v := range r.Versions {
+				versions[i] = v.Version
+			}
+			if latest := Latest(versions, false); latest != "" {
+				r.Version = latest
+				stable = append(stable, r)
+			}
+		}
+		results = stable
+	}
+	if len(results) > opts.Take {
+		results = results[min(opts.Skip, len(results)):]
+		results = results[:min(opts.Take, len(results))]
+	}
+	return results
+}
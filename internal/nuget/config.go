@@ -0,0 +1,219 @@
+package nuget
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// ConfigFile is the usual spelling of the NuGet configuration file name.
+const ConfigFile = "nuget.config"
+
+// FindConfig returns the nearest nuget.config at or above dir, or "". NuGet matches the
+// file name case-insensitively (NuGet.Config and nuget.config are both common).
+func FindConfig(dir string) string {
+	for {
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.EqualFold(entry.Name(), ConfigFile) {
+				return filepath.Join(dir, entry.Name())
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// ConfigPaths returns the NuGet configuration files that apply in dir, the way NuGet
+// merges them: every nuget.config at or above dir, nearest first, then the user's. Files
+// that do not exist are left out.
+func ConfigPaths(dir string) []string {
+	var paths []string
+	for dir, _ = filepath.Abs(dir); ; {
+		path := FindConfig(dir)
+		if path == "" {
+			break
+		}
+		paths = append(paths, path)
+		parent := filepath.Dir(filepath.Dir(path))
+		if parent == filepath.Dir(path) {
+			break
+		}
+		dir = parent
+	}
+	if user := UserConfigPath(); user != "" && !slices.Contains(paths, user) {
+		if _, err := os.Stat(user); err == nil {
+			paths = append(paths, user)
+		}
+	}
+	return paths
+}
+
+// UserConfigPath returns the user-wide NuGet configuration file: %APPDATA%\NuGet\NuGet.Config
+// on Windows, ~/.nuget/NuGet/NuGet.Config elsewhere. It returns "" when the home folder
+// is unknown.
+func UserConfigPath() string {
+	if runtime.GOOS == "windows" {
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			return filepath.Join(appData, "NuGet", "NuGet.Config")
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".nuget", "NuGet", "NuGet.Config")
+}
+
+// Source is a package source in a NuGet configuration file.
+type Source struct {
+	Name     string
+	URL      string // V3 service index URL
+	Username string // With Password, written to packageSourceCredentials; "" for none
+	Password string // Written as ClearTextPassword, the only form NuGet reads outside Windows
+}
+
+// emptyConfig is the NuGet configuration file AddSource creates.
+const emptyConfig = `<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+</configuration>
+`
+
+// AddSource adds a package source to a NuGet configuration file, creating the file when
+// it does not exist. A source with the same name is replaced, credentials included. The
+// rest of the file keeps its formatting.
+func AddSource(path string, s Source) error {
+	text, err := readConfig(path)
+	if err != nil {
+		return err
+	}
+
+	key := regexp.QuoteMeta(escapeXML(s.Name))
+	text = editSection(text, "packageSources", regexp.MustCompile(`(?i)[ \t]*<add\s+key="`+key+`"[^>]*/>[ \t]*\r?\n?`),
+		[]string{fmt.Sprintf(`<add key="%s" value="%s" protocolVersion="3" />`, escapeXML(s.Name), escapeXML(s.URL))})
+
+	element := regexp.QuoteMeta(encodeXMLName(s.Name))
+	var credentials []string
+	if s.Username != "" && s.Password != "" {
+		credentials = []string{
+			"<" + encodeXMLName(s.Name) + ">",
+			fmt.Sprintf(`  <add key="Username" value="%s" />`, escapeXML(s.Username)),
+			fmt.Sprintf(`  <add key="ClearTextPassword" value="%s" />`, escapeXML(s.Password)),
+			"</" + encodeXMLName(s.Name) + ">",
+		}
+	}
+	text = editSection(text, "packageSourceCredentials",
+		regexp.MustCompile(`(?is)[ \t]*<`+element+`>.*?</`+element+`>[ \t]*\r?\n?`), credentials)
+
+	return writeConfig(path, text)
+}
+
+// readConfig reads a NuGet configuration file to edit it; a file that does not exist
+// reads as an empty configuration.
+func readConfig(path string) (string, error) {
+	// #nosec G304 -- path is the NuGet configuration file being edited
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		data = []byte(emptyConfig)
+	} else if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	text := string(data)
+	if !strings.Contains(text, "</configuration>") {
+		return "", fmt.Errorf("%s is not a NuGet configuration file", path)
+	}
+	return text, nil
+}
+
+// writeConfig writes an edited NuGet configuration file.
+func writeConfig(path, text string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	// Credentials are in clear text, so only the user may read a new file
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// editSection removes what old matches from a section of a configuration file, then
+// appends lines to it. The section is created at the end of the file when lines are to be
+// added and it does not exist.
+func editSection(text, section string, old *regexp.Regexp, lines []string) string {
+	text = regexp.MustCompile(`<`+section+`\s*/>`).ReplaceAllString(text, "<"+section+"></"+section+">")
+	openTag := regexp.MustCompile(`<` + section + `\s*>`)
+	closeTag := "</" + section + ">"
+	newline := "\n"
+	if strings.Contains(text, "\r\n") {
+		newline = "\r\n"
+	}
+
+	loc := openTag.FindStringIndex(text)
+	end := -1
+	if loc != nil {
+		end = strings.Index(text[loc[1]:], closeTag)
+	}
+	if loc == nil || end < 0 {
+		if len(lines) == 0 {
+			return text
+		}
+		// Add the section at the end of <configuration>
+		at := strings.LastIndex(text, "</configuration>")
+		var sb strings.Builder
+		sb.WriteString("  <" + section + ">" + newline)
+		for _, line := range lines {
+			sb.WriteString("    " + line + newline)
+		}
+		sb.WriteString("  " + closeTag + newline)
+		return text[:at] + sb.String() + text[at:]
+	}
+
+	start, end := loc[1], loc[1]+end
+	body := old.ReplaceAllString(text[start:end], "")
+	if len(lines) > 0 {
+		// Entries go one level deeper than the closing tag, on lines of their own
+		indent := "  "
+		if i := strings.LastIndex(body, "\n"); i >= 0 && strings.TrimSpace(body[i+1:]) == "" {
+			indent = body[i+1:]
+			body = body[:i+1]
+		} else {
+			body = strings.TrimRight(body, " \t") + newline
+		}
+		for _, line := range lines {
+			body += indent + "  " + line + newline
+		}
+		body += indent
+	}
+	return text[:start] + body + text[end:]
+}
+
+// escapeXML escapes text for an XML attribute value.
+func escapeXML(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
+
+// encodeXMLName encodes a source name as an XML element name the way NuGet does
+// (XmlConvert.EncodeLocalName): characters a name cannot contain become _xHHHH_.
+func encodeXMLName(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		valid := r == '_' || unicode.IsLetter(r) || (i > 0 && (unicode.IsDigit(r) || r == '.' || r == '-'))
+		if valid {
+			sb.WriteRune(r)
+		} else {
+			fmt.Fprintf(&sb, "_x%04X_", r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,76 @@
+package nuget
+
+import (
+	"math"
+	"strings"
+)
+
+// cvssWeights are the CVSS 3.x base metric values, by metric and value.
+var cvssWeights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// CVSSScore computes the base score of a CVSS 3.0 or 3.1 vector (e.g.,
+// "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H" scores 9.8). It returns false for other
+// versions and incomplete vectors.
+func CVSSScore(vector string) (float64, bool) {
+	parts := strings.Split(vector, "/")
+	if len(parts) == 0 || (parts[0] != "CVSS:3.0" && parts[0] != "CVSS:3.1") {
+		return 0, false
+	}
+	metrics := make(map[string]string)
+	for _, p := range parts[1:] {
+		if name, value, ok := strings.Cut(p, ":"); ok {
+			metrics[name] = value
+		}
+	}
+
+	changed := metrics["S"] == "C"
+	if !changed && metrics["S"] != "U" {
+		return 0, false
+	}
+	w := make(map[string]float64)
+	for name, values := range cvssWeights {
+		v, ok := values[metrics[name]]
+		if !ok {
+			return 0, false
+		}
+		w[name] = v
+	}
+	// Privileges matter less when the impact reaches beyond the vulnerable component
+	if changed && metrics["PR"] == "L" {
+		w["PR"] = 0.68
+	} else if changed && metrics["PR"] == "H" {
+		w["PR"] = 0.5
+	}
+
+	iss := 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, true
+	}
+	exploitability := 8.22 * w["AV"] * w["AC"] * w["PR"] * w["UI"]
+	if changed {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), true
+}
+
+// roundUp rounds up to one decimal the way the CVSS 3.1 specification does, avoiding
+// floating-point artifacts (e.g., 4.000001 is 4.0, not 4.1).
+func roundUp(x float64) float64 {
+	n := int64(math.Round(x * 100000))
+	if n%10000 == 0 {
+		return float64(n) / 100000
+	}
+	return float64(n/10000+1) / 10
+}
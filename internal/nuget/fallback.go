@@ -0,0 +1,105 @@
+package nuget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// StatusError is an unexpected HTTP status from a feed.
+type StatusError struct {
+	URL        string
+	Status     string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned %s", e.URL, e.Status)
+}
+
+// Unavailable reports whether an error means the feed is down, rather than that it
+// answered: the server could not be reached, timed out, or failed with a 5xx status or
+// a rate limit.
+func Unavailable(err error) bool {
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.StatusCode >= http.StatusInternalServerError || status.StatusCode == http.StatusTooManyRequests
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// MatchPackage reports whether a package ID matches a pattern: an ID, a prefix ending in
+// "*" (e.g., "Contoso.*"), or "*" for every package. IDs are case-insensitive.
+func MatchPackage(pattern, id string) bool {
+	pattern, id = strings.ToLower(pattern), strings.ToLower(id)
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(id, prefix)
+	}
+	return pattern == id
+}
+
+// Fallback is a mirror that serves reads of matching packages while the feed it backs
+// is unavailable. Fallbacks are only read from; nothing is ever published to them.
+type Fallback struct {
+	Name     string // Shown when the fallback is used
+	Packages string // Package ID pattern (see MatchPackage)
+	URL      string // Service index URL, or package base address
+
+	once sync.Once
+	feed *Feed
+	err  error
+}
+
+// open probes the fallback the first time it is needed, with the client of the feed it backs.
+func (fb *Fallback) open(ctx context.Context, client *http.Client) (*Feed, error) {
+	fb.once.Do(func() {
+		fb.feed, fb.err = OpenFeed(ctx, client, fb.URL)
+	})
+	return fb.feed, fb.err
+}
+
+// OpenFeed returns the feed at a URL. A service index URL is probed for the resources the
+// server offers; any other URL is the package base address of the feed, whose other
+// resources are nuget.org's.
+func OpenFeed(ctx context.Context, client *http.Client, source string) (*Feed, error) {
+	if strings.HasSuffix(strings.ToLower(source), "/index.json") {
+		return ProbeFeed(ctx, client, source)
+	}
+	feed := NewFeed()
+	if client != nil {
+		feed.HTTPClient = client
+	}
+	feed.BaseURL = source
+	return feed, nil
+}
+
+// withFallback reads a package from a feed and, when the feed is unavailable, from the
+// first of its fallbacks for the package that can serve it. OnFallback is told which one
+// did; when none can, the feed's error is returned.
+func withFallback[T any](ctx context.Context, f *Feed, id string, read func(*Feed) (T, error)) (T, error) {
+	v, err := read(f)
+	if err == nil || !Unavailable(err) || ctx.Err() != nil {
+		return v, err
+	}
+	for _, fb := range f.Fallbacks {
+		if !MatchPackage(fb.Packages, id) {
+			continue
+		}
+		feed, openErr := fb.open(ctx, f.client())
+		if openErr != nil {
+			continue
+		}
+		if fv, fbErr := read(feed); fbErr == nil {
+			if f.OnFallback != nil {
+				f.OnFallback(id, fb, err)
+			}
+			return fv, nil
+		}
+	}
+	return v, err
+}
@@ -0,0 +1,355 @@
+package nuget
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/metrics"
+)
+
+// DefaultFeedURL is the package base address (flat container) of nuget.org.
+const DefaultFeedURL = "https://api.nuget.org/v3-flatcontainer/"
+
+// DefaultSearchURL is the search service (SearchQueryService) of nuget.org.
+const DefaultSearchURL = "https://azuresearch-usnc.nuget.org/query"
+
+// DefaultRegistrationURL is the package metadata service (RegistrationsBaseUrl) of
+// nuget.org, including SemVer 2.0.0 packages.
+const DefaultRegistrationURL = "https://api.nuget.org/v3/registration5-gz-semver2/"
+
+// maxIndexSize bounds a package's version index; the largest on nuget.org are well below it.
+const maxIndexSize = 4 << 20
+
+// Feed lists package versions from a NuGet V3 package base address and searches its
+// search service.
+type Feed struct {
+	HTTPClient       *http.Client
+	BaseURL          string
+	SearchURL        string // Empty when the feed cannot be searched
+	VulnerabilityURL string // Empty when the feed has no vulnerability data
+	RegistrationURL  string // Empty when the feed has no package metadata service
+	OSV              *OSV   // Adds OSV.dev data to advisories; nil to use the feed's alone
+	Server           string // Server product detected by ProbeFeed (e.g., "Artifactory"), or ""
+	// Mirrors for package reads while the feed is unavailable, tried in order
+	Fallbacks []*Fallback
+	// Called when a fallback served a read, with the error of the feed
+	OnFallback func(id string, fallback *Fallback, err error)
+}
+
+// NewFeed returns a feed for nuget.org.
+func NewFeed() *Feed {
+	return &Feed{
+		HTTPClient:       &http.Client{Transport: metrics.Transport(nil)},
+		BaseURL:          DefaultFeedURL,
+		SearchURL:        DefaultSearchURL,
+		VulnerabilityURL: DefaultVulnerabilityURL,
+		RegistrationURL:  DefaultRegistrationURL,
+	}
+}
+
+// Versions returns every version of a package on the feed, including unlisted and
+// prerelease versions. A package the feed does not have has no versions. Feeds without a
+// package base address list versions in their registration service.
+func (f *Feed) Versions(ctx context.Context, id string) ([]string, error) {
+	return withFallback(ctx, f, id, func(feed *Feed) ([]string, error) {
+		return feed.versions(ctx, id)
+	})
+}
+
+// versions lists the versions on this feed, without its fallbacks.
+func (f *Feed) versions(ctx context.Context, id string) ([]string, error) {
+	if f.BaseURL == "" {
+		entries, err := f.registrationEntries(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list versions of %s: %w", id, err)
+		}
+		versions := make([]string, len(entries))
+		for i, e := range entries {
+			versions[i] = e.Version
+		}
+		return versions, nil
+	}
+	url := strings.TrimSuffix(f.BaseURL, "/") + "/" + strings.ToLower(id) + "/index.json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := f.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions of %s: %w", id, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("failed to list versions of %s: %w", id, &StatusError{URL: url, Status: resp.Status, StatusCode: resp.StatusCode})
+	}
+
+	var index struct {
+		Versions []string `json:"versions"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxIndexSize)).Decode(&index); err != nil {
+		return nil, fmt.Errorf("failed to parse the version index of %s: %w", id, err)
+	}
+	return index.Versions, nil
+}
+
+// LocalVersions returns the versions of a package in the global packages folder, for
+// working offline.
+func LocalVersions(packagesDir, id string) []string {
+	entries, err := os.ReadDir(PackageDir(packagesDir, id, ""))
+	if err != nil {
+		return nil
+	}
+	var versions []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			versions = append(versions, entry.Name())
+		}
+	}
+	return versions
+}
+
+// maxIconSize bounds a package icon; nuget.org rejects icons over 1 MB.
+const maxIconSize = 1 << 20
+
+// Icon returns the icon embedded in a package version, or nil when the package has no
+// embedded icon. Icons are PNG or JPEG images.
+func (f *Feed) Icon(ctx context.Context, id, version string) ([]byte, error) {
+	return withFallback(ctx, f, id, func(feed *Feed) ([]byte, error) {
+		return feed.icon(ctx, id, version)
+	})
+}
+
+// icon downloads an icon from this feed.
+func (f *Feed) icon(ctx context.Context, id, version string) ([]byte, error) {
+	url := strings.TrimSuffix(f.BaseURL, "/") + "/" + strings.ToLower(id) + "/" + strings.ToLower(version) + "/icon"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := f.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the icon of %s: %w", id, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("failed to download the icon of %s: %w", id, &StatusError{URL: url, Status: resp.Status, StatusCode: resp.StatusCode})
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxIconSize))
+}
+
+// maxSearchSize bounds a page of search results.
+const maxSearchSize = 8 << 20
+
+// SearchResult is a package found by Search, at its latest matching version.
+type SearchResult struct {
+	ID             string   `json:"id"`
+	Version        string   `json:"version"`
+	Description    string   `json:"description"`
+	Authors        []string `json:"authors"`
+	TotalDownloads int64    `json:"totalDownloads"`
+	Verified       bool     `json:"verified"`         // The ID prefix is reserved by its owner
+	Owners         []string `json:"owners,omitempty"` // nuget.org accounts that own the package
+	// Downloads per version, oldest first (empty when the feed does not report them)
+	Versions []VersionDownloads `json:"versions"`
+	// Weekly download totals, oldest first, when requested from a Trends service
+	Trend []WeeklyDownloads `json:"trend,omitempty"`
+}
+
+// SearchKind identifies search results in versioned JSON output.
+const SearchKind = "search"
+
+// Downloads returns the download count of one version, or 0 when the feed did not report it.
+func (r SearchResult) Downloads(version string) int64 {
+	for _, v := range r.Versions {
+		if strings.EqualFold(v.Version, version) {
+			return v.Downloads
+		}
+	}
+	return 0
+}
+
+// VersionDownloads is the download count of one version of a package.
+type VersionDownloads struct {
+	Version   string `json:"version"`
+	Downloads int64  `json:"downloads"`
+}
+
+// SortByDownloads orders search results by total downloads, most downloaded first. Search
+// results come a page at a time, so this orders the page, not the whole feed.
+func SortByDownloads(results []SearchResult) {
+	slices.SortStableFunc(results, func(a, b SearchResult) int {
+		return cmp.Compare(b.TotalDownloads, a.TotalDownloads)
+	})
+}
+
+// SearchOptions select a page of search results.
+type SearchOptions struct {
+	Skip       int
+	Take       int // Default 20
+	Prerelease bool
+}
+
+// Search returns the packages matching a query, most relevant first. On feeds without a
+// search service, a query that is a package ID finds that package.
+func (f *Feed) Search(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	if opts.Take <= 0 {
+		opts.Take = 20
+	}
+	if f.SearchURL == "" {
+		return f.searchByID(ctx, query, opts)
+	}
+	params := url.Values{
+		"q":           {query},
+		"skip":        {strconv.Itoa(opts.Skip)},
+		"take":        {strconv.Itoa(opts.Take)},
+		"prerelease":  {strconv.FormatBool(opts.Prerelease)},
+		"semVerLevel": {"2.0.0"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.SearchURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := f.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for %q: %w", query, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to search for %q: %s returned %s", query, f.SearchURL, resp.Status)
+	}
+
+	var page struct {
+		Data []struct {
+			SearchResult
+			// A list on nuget.org, but a single string on some other feeds
+			Authors json.RawMessage `json:"authors"`
+			Owners  json.RawMessage `json:"owners"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxSearchSize)).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to parse search results: %w", err)
+	}
+	results := make([]SearchResult, len(page.Data))
+	for i, d := range page.Data {
+		results[i] = d.SearchResult
+		if results[i].Versions == nil {
+			results[i].Versions = []VersionDownloads{}
+		}
+		results[i].Authors = stringList(d.Authors)
+		results[i].Owners = stringList(d.Owners)
+	}
+	return pageResults(results, opts), nil
+}
+
+// stringList decodes a list of strings that some feeds send as a single string.
+func stringList(raw json.RawMessage) []string {
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		return list
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil && s != "" {
+		return []string{s}
+	}
+	return nil
+}
+
+// Lookup returns the search entry of a package, with its owners and whether its prefix is
+// reserved, or nil when the feed has no such package or no search service.
+func (f *Feed) Lookup(ctx context.Context, id string) (*SearchResult, error) {
+	if f.SearchURL == "" {
+		return nil, nil
+	}
+	results, err := f.Search(ctx, "packageid:"+id, SearchOptions{Take: 1, Prerelease: true})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range results {
+		if strings.EqualFold(r.ID, id) {
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
+
+// Published returns when a package version was published to the feed, from its
+// registration leaf. It returns the zero time for versions the feed does not have, for
+// unlisted versions (which nuget.org dates 1900-01-01), and for feeds without a
+// registration service.
+func (f *Feed) Published(ctx context.Context, id, version string) (time.Time, error) {
+	return withFallback(ctx, f, id, func(feed *Feed) (time.Time, error) {
+		return feed.published(ctx, id, version)
+	})
+}
+
+// published reads a publish date from this feed.
+func (f *Feed) published(ctx context.Context, id, version string) (time.Time, error) {
+	if f.RegistrationURL == "" {
+		return time.Time{}, nil
+	}
+	url := strings.TrimSuffix(f.RegistrationURL, "/") + "/" + strings.ToLower(id) + "/" + strings.ToLower(version) + ".json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	resp, err := f.client().Do(req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read the metadata of %s %s: %w", id, version, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// Some servers only serve the registration index, not a leaf per version
+		return f.publishedFromIndex(ctx, id, version)
+	default:
+		return time.Time{}, fmt.Errorf("failed to read the metadata of %s %s: %w", id, version, &StatusError{URL: url, Status: resp.Status, StatusCode: resp.StatusCode})
+	}
+
+	var leaf struct {
+		Published time.Time `json:"published"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxIndexSize)).Decode(&leaf); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse the metadata of %s %s: %w", id, version, err)
+	}
+	if leaf.Published.Year() <= 1900 {
+		return time.Time{}, nil
+	}
+	return leaf.Published, nil
+}
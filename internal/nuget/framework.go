@@ -0,0 +1,230 @@
+// Package nuget implements the parts of NuGet's client model LazyNuGet needs locally:
+// target framework monikers and their compatibility rules, framework support lifecycles,
+// and the global packages folder.
+package nuget
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Family is a target framework family.
+type Family string
+
+// Framework families. .NET 5 and later ("net8.0") is a separate family from .NET Core
+// ("netcoreapp3.1") and .NET Framework ("net48"), matching NuGet's own distinction.
+const (
+	FamilyNet          Family = ".NET"
+	FamilyNetCoreApp   Family = ".NET Core"
+	FamilyNetStandard  Family = ".NET Standard"
+	FamilyNetFramework Family = ".NET Framework"
+	FamilyUnknown      Family = "unknown"
+)
+
+// Framework is a parsed target framework moniker (TFM).
+type Framework struct {
+	Moniker  string // As written, lowercased (e.g., "net8.0-windows")
+	Family   Family
+	Platform string // OS-specific suffix without its version (e.g., "windows"), or ""
+	Major    int
+	Minor    int
+	Patch    int
+}
+
+// ParseFramework parses a TFM such as net8.0, net8.0-android, netcoreapp3.1,
+// netstandard2.0, or net472. Unrecognized monikers parse to FamilyUnknown.
+func ParseFramework(moniker string) (Framework, error) {
+	moniker = strings.ToLower(strings.TrimSpace(moniker))
+	if moniker == "" {
+		return Framework{}, fmt.Errorf("empty target framework")
+	}
+
+	f := Framework{Moniker: moniker, Family: FamilyUnknown}
+	name, platform, _ := strings.Cut(moniker, "-")
+
+	var version string
+	switch {
+	case strings.HasPrefix(name, "netcoreapp"):
+		f.Family, version = FamilyNetCoreApp, strings.TrimPrefix(name, "netcoreapp")
+	case strings.HasPrefix(name, "netstandard"):
+		f.Family, version = FamilyNetStandard, strings.TrimPrefix(name, "netstandard")
+	case strings.HasPrefix(name, "net"):
+		version = strings.TrimPrefix(name, "net")
+		if strings.Contains(version, ".") {
+			f.Family = FamilyNet
+		} else {
+			// Compact .NET Framework form: net48 is 4.8, net472 is 4.7.2
+			f.Family = FamilyNetFramework
+			version = strings.Join(strings.Split(version, ""), ".")
+		}
+	default:
+		return f, nil
+	}
+
+	parts := strings.Split(version, ".")
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || i >= len(numbers) {
+			return Framework{Moniker: moniker, Family: FamilyUnknown}, nil
+		}
+		numbers[i] = n
+	}
+	f.Major, f.Minor, f.Patch = numbers[0], numbers[1], numbers[2]
+	f.Platform = strings.TrimRight(platform, "0123456789.")
+
+	// "net4.8" style and anything below 5 with a dot is .NET Framework, not .NET
+	if f.Family == FamilyNet && f.Major < 5 {
+		f.Family = FamilyNetFramework
+	}
+	return f, nil
+}
+
+// MustParseFramework parses a TFM known to be valid (e.g., a constant).
+func MustParseFramework(moniker string) Framework {
+	f, err := ParseFramework(moniker)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// String returns the moniker.
+func (f Framework) String() string {
+	return f.Moniker
+}
+
+// compareVersion compares the numeric versions of two frameworks.
+func (f Framework) compareVersion(other Framework) int {
+	for _, d := range []int{f.Major - other.Major, f.Minor - other.Minor, f.Patch - other.Patch} {
+		switch {
+		case d < 0:
+			return -1
+		case d > 0:
+			return 1
+		}
+	}
+	return 0
+}
+
+// atMost reports whether f's version is less than or equal to major.minor.
+func (f Framework) atMost(major, minor int) bool {
+	return f.Major < major || (f.Major == major && f.Minor <= minor)
+}
+
+// Compatible reports whether a project targeting target can use assets built for lib,
+// following NuGet's compatibility rules for the common families.
+func Compatible(target, lib Framework) bool {
+	if lib.Platform != "" && lib.Platform != target.Platform {
+		return false
+	}
+
+	switch target.Family {
+	case FamilyNet:
+		switch lib.Family {
+		case FamilyNet:
+			return lib.compareVersion(target) <= 0
+		case FamilyNetCoreApp:
+			return true
+		case FamilyNetStandard:
+			return lib.atMost(2, 1)
+		}
+	case FamilyNetCoreApp:
+		switch lib.Family {
+		case FamilyNetCoreApp:
+			return lib.compareVersion(target) <= 0
+		case FamilyNetStandard:
+			return lib.atMost(netStandardFor(target))
+		}
+	case FamilyNetFramework:
+		switch lib.Family {
+		case FamilyNetFramework:
+			return lib.compareVersion(target) <= 0
+		case FamilyNetStandard:
+			return lib.atMost(netStandardFor(target))
+		}
+	case FamilyNetStandard:
+		return lib.Family == FamilyNetStandard && lib.compareVersion(target) <= 0
+	case FamilyUnknown:
+		return lib.Moniker == target.Moniker
+	}
+	return false
+}
+
+// netStandardFor returns the highest .NET Standard version a .NET Core or .NET Framework
+// target implements.
+func netStandardFor(target Framework) (int, int) {
+	if target.Family == FamilyNetCoreApp {
+		switch {
+		case target.Major >= 3:
+			return 2, 1
+		case target.Major == 2:
+			return 2, 0
+		default:
+			return 1, 6
+		}
+	}
+
+	// .NET Framework: 4.6.1+ implements 2.0 (via shims), 4.6 implements 1.3,
+	// 4.5.1/4.5.2 implement 1.2, and 4.5 implements 1.1
+	v := target.Major*100 + target.Minor*10 + target.Patch
+	switch {
+	case v >= 461:
+		return 2, 0
+	case v >= 460:
+		return 1, 3
+	case v >= 451:
+		return 1, 2
+	case v >= 450:
+		return 1, 1
+	default:
+		return 0, 0
+	}
+}
+
+// AnyCompatible reports whether target can use at least one of libs. A package without
+// framework-specific assets (e.g., analyzers or build-only packages) is always compatible.
+func AnyCompatible(target Framework, libs []Framework) bool {
+	if len(libs) == 0 {
+		return true
+	}
+	for _, lib := range libs {
+		if Compatible(target, lib) {
+			return true
+		}
+	}
+	return false
+}
+
+// Nearest returns the lib framework NuGet picks for target among those compatible with
+// it: the target's own family first, then .NET Core for .NET and .NET Standard last,
+// the highest version within a family, and a platform-specific one over a neutral one.
+func Nearest(target Framework, libs []Framework) (Framework, bool) {
+	rank := func(lib Framework) int {
+		switch {
+		case lib.Family == target.Family:
+			return 0
+		case lib.Family == FamilyNetStandard:
+			return 2
+		default:
+			return 1
+		}
+	}
+	var best Framework
+	found := false
+	for _, lib := range libs {
+		if !Compatible(target, lib) {
+			continue
+		}
+		better := !found || rank(lib) < rank(best)
+		if found && rank(lib) == rank(best) {
+			c := lib.compareVersion(best)
+			better = c > 0 || c == 0 && lib.Platform != "" && best.Platform == ""
+		}
+		if better {
+			best, found = lib, true
+		}
+	}
+	return best, found
+}
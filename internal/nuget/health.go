@@ -0,0 +1,116 @@
+package nuget
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// HealthKind identifies package health in versioned JSON output.
+const HealthKind = "health"
+
+// cadenceReleases is how many of the latest releases the release cadence is taken over,
+// so a package's early history does not hide how it is maintained now.
+const cadenceReleases = 10
+
+// Release is a listed version of a package and when it was published.
+type Release struct {
+	Published time.Time `json:"published"`
+	Version   string    `json:"version"`
+}
+
+// Releases returns the listed versions of a package with their publish dates, oldest
+// first. Versions without a date (nuget.org dates unlisted ones 1900-01-01) are left out;
+// a feed without a registration service has none.
+func (f *Feed) Releases(ctx context.Context, id string) ([]Release, error) {
+	return withFallback(ctx, f, id, func(feed *Feed) ([]Release, error) {
+		if feed.RegistrationURL == "" {
+			return nil, nil
+		}
+		entries, err := feed.registrationEntries(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the releases of %s: %w", id, err)
+		}
+		var releases []Release
+		for _, e := range entries {
+			if (e.Listed == nil || *e.Listed) && e.Published.Year() > 1900 {
+				releases = append(releases, Release{Version: e.Version, Published: e.Published})
+			}
+		}
+		slices.SortStableFunc(releases, func(a, b Release) int { return a.Published.Compare(b.Published) })
+		return releases, nil
+	})
+}
+
+// Health is how actively a package is maintained, judged by its releases.
+type Health struct {
+	LastPublished time.Time `json:"lastPublished"` // Zero when no release has a date
+	LastVersion   string    `json:"lastVersion,omitempty"`
+	Releases      int       `json:"releases"`
+	CadenceDays   int       `json:"cadenceDays"` // Median days between the latest releases; 0 with fewer than two
+	Stale         bool      `json:"stale"`       // Nothing published for longer than the staleness limit
+}
+
+// HealthOf judges a package by its releases, oldest first: stale when the last one is
+// older than staleAfter (0 never judges a package stale).
+func HealthOf(releases []Release, now time.Time, staleAfter time.Duration) Health {
+	h := Health{Releases: len(releases)}
+	if len(releases) == 0 {
+		return h
+	}
+	last := releases[len(releases)-1]
+	h.LastPublished, h.LastVersion = last.Published, last.Version
+	h.Stale = staleAfter > 0 && now.Sub(last.Published) > staleAfter
+
+	recent := releases[max(len(releases)-cadenceReleases, 0):]
+	if len(recent) > 1 {
+		gaps := make([]time.Duration, 0, len(recent)-1)
+		for i := 1; i < len(recent); i++ {
+			gaps = append(gaps, recent[i].Published.Sub(recent[i-1].Published))
+		}
+		slices.Sort(gaps)
+		h.CadenceDays = int(gaps[len(gaps)/2].Hours() / 24)
+	}
+	return h
+}
+
+// Summary describes the health in a line ("last release 2.1.0 on 2023-04-01, 2 years ago;
+// about every 3 months").
+func (h Health) Summary(now time.Time, dateFormat string) string {
+	if h.LastPublished.IsZero() {
+		return "no release dates"
+	}
+	s := fmt.Sprintf("last release %s on %s, %s ago", h.LastVersion, h.LastPublished.Format(dateFormat), FormatSpan(now.Sub(h.LastPublished)))
+	switch {
+	case h.CadenceDays > 0:
+		s += "; about every " + FormatSpan(time.Duration(h.CadenceDays)*24*time.Hour)
+	case h.Releases > 1:
+		s += "; several releases a day"
+	default:
+		s += "; the only release"
+	}
+	if h.Stale {
+		s += " (stale)"
+	}
+	return s
+}
+
+// FormatSpan rounds a span of time to the largest whole unit that fits: days, weeks,
+// months, or years ("1 day", "3 months", "2 years").
+func FormatSpan(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	unit, n := "day", days
+	switch {
+	case days >= 365:
+		unit, n = "year", days/365
+	case days >= 30:
+		unit, n = "month", days/30
+	case days >= 14:
+		unit, n = "week", days/7
+	}
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,69 @@
+package nuget
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// knownNamespaces lists the namespaces of packages whose public API lives outside a
+// namespace named after the package or its assemblies, typically extension methods
+// placed in the namespace of the type they extend.
+var knownNamespaces = map[string][]string{
+	"autofac.extensions.dependencyinjection":              {"Autofac"},
+	"automapper.extensions.microsoft.dependencyinjection": {"AutoMapper"},
+	"mediatr.extensions.microsoft.dependencyinjection":    {"MediatR"},
+	"microsoft.entityframeworkcore.inmemory":              {"Microsoft.EntityFrameworkCore"},
+	"microsoft.entityframeworkcore.sqlite":                {"Microsoft.EntityFrameworkCore"},
+	"microsoft.entityframeworkcore.sqlserver":             {"Microsoft.EntityFrameworkCore"},
+	"npgsql.entityframeworkcore.postgresql":               {"Microsoft.EntityFrameworkCore"},
+	"pomelo.entityframeworkcore.mysql":                    {"Microsoft.EntityFrameworkCore"},
+	"serilog.aspnetcore":                                  {"Serilog"},
+	"serilog.extensions.hosting":                          {"Serilog"},
+	"serilog.settings.configuration":                      {"Serilog"},
+	"serilog.sinks.console":                               {"Serilog"},
+	"serilog.sinks.file":                                  {"Serilog"},
+	"swashbuckle.aspnetcore":                              {"Microsoft.OpenApi.Models"},
+	"xunit":                                               {"Xunit"},
+}
+
+// Namespaces returns the namespaces code using a package likely imports: the package ID,
+// the names of the assemblies in its lib/ and ref/ folders (by convention each assembly's
+// root namespace), and known exceptions. Without the package in the global packages
+// folder (version "" or not restored), only the ID and known exceptions are returned.
+func Namespaces(packagesDir, id, version string) []string {
+	namespaces := slices.Clone(knownNamespaces[strings.ToLower(id)])
+
+	if version != "" {
+		dir := PackageDir(packagesDir, id, version)
+		for _, folder := range assetFolders {
+			// lib/<tfm>/<assembly>.dll; satellite assemblies are one level deeper
+			matches, _ := filepath.Glob(filepath.Join(dir, folder, "*", "*.dll"))
+			for _, match := range matches {
+				namespaces = append(namespaces, strings.TrimSuffix(filepath.Base(match), filepath.Ext(match)))
+			}
+		}
+	}
+
+	// The ID goes last so that, of names differing only in case, the assembly's spelling
+	// (e.g., Xunit rather than xunit) is kept
+	namespaces = append(namespaces, id)
+	slices.SortStableFunc(namespaces, func(a, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) })
+	return slices.CompactFunc(namespaces, strings.EqualFold)
+}
+
+// InstalledVersion returns the version of a package in the global packages folder that
+// best matches a requested version or range: the exact version when it is installed,
+// otherwise the one restore would resolve from the installed versions. It returns ""
+// when no installed version matches.
+func InstalledVersion(packagesDir, id, requested string) string {
+	if requested != "" && isDir(PackageDir(packagesDir, id, requested)) {
+		return requested
+	}
+	r, err := ParseVersionRange(requested)
+	if err != nil {
+		return ""
+	}
+	v, _ := r.Resolve(LocalVersions(packagesDir, id))
+	return v
+}
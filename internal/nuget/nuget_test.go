@@ -0,0 +1,1424 @@
+package nuget
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/cache"
+)
+
+// TestParseFramework tests parsing target framework monikers
+func TestParseFramework(t *testing.T) {
+	tests := []struct {
+		moniker  string
+		family   Family
+		platform string
+		major    int
+		minor    int
+		patch    int
+	}{
+		{moniker: "net8.0", family: FamilyNet, major: 8},
+		{moniker: "net10.0", family: FamilyNet, major: 10},
+		{moniker: "net8.0-windows10.0.19041", family: FamilyNet, platform: "windows", major: 8},
+		{moniker: "netcoreapp3.1", family: FamilyNetCoreApp, major: 3, minor: 1},
+		{moniker: "netstandard2.0", family: FamilyNetStandard, major: 2},
+		{moniker: "net472", family: FamilyNetFramework, major: 4, minor: 7, patch: 2},
+		{moniker: "net48", family: FamilyNetFramework, major: 4, minor: 8},
+		{moniker: "net4.8", family: FamilyNetFramework, major: 4, minor: 8},
+		{moniker: "NET6.0", family: FamilyNet, major: 6},
+		{moniker: "portable-net45+win8", family: FamilyUnknown},
+		{moniker: "uap10.0", family: FamilyUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.moniker, func(t *testing.T) {
+			f, err := ParseFramework(tt.moniker)
+			if err != nil {
+				t.Fatalf("ParseFramework() error = %v", err)
+			}
+			if f.Family != tt.family || f.Platform != tt.platform {
+				t.Errorf("ParseFramework() = %+v, want family %q platform %q", f, tt.family, tt.platform)
+			}
+			if tt.family != FamilyUnknown && (f.Major != tt.major || f.Minor != tt.minor || f.Patch != tt.patch) {
+				t.Errorf("ParseFramework() version = %d.%d.%d, want %d.%d.%d", f.Major, f.Minor, f.Patch, tt.major, tt.minor, tt.patch)
+			}
+		})
+	}
+
+	if _, err := ParseFramework(" "); err == nil {
+		t.Error("ParseFramework() should reject an empty moniker")
+	}
+}
+
+// TestCompatible tests NuGet's framework compatibility rules
+func TestCompatible(t *testing.T) {
+	tests := []struct {
+		target string
+		lib    string
+		want   bool
+	}{
+		{target: "net8.0", lib: "net6.0", want: true},
+		{target: "net6.0", lib: "net8.0", want: false},
+		{target: "net8.0", lib: "netstandard2.1", want: true},
+		{target: "net8.0", lib: "netcoreapp3.1", want: true},
+		{target: "net8.0", lib: "net472", want: false},
+		{target: "net8.0-windows", lib: "net8.0", want: true},
+		{target: "net8.0", lib: "net8.0-windows", want: false},
+		{target: "netcoreapp2.1", lib: "netstandard2.1", want: false},
+		{target: "netcoreapp2.1", lib: "netstandard2.0", want: true},
+		{target: "net472", lib: "netstandard2.0", want: true},
+		{target: "net46", lib: "netstandard2.0", want: false},
+		{target: "net48", lib: "net45", want: true},
+		{target: "netstandard2.0", lib: "netstandard1.6", want: true},
+		{target: "netstandard2.0", lib: "net6.0", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target+"/"+tt.lib, func(t *testing.T) {
+			if got := Compatible(MustParseFramework(tt.target), MustParseFramework(tt.lib)); got != tt.want {
+				t.Errorf("Compatible(%s, %s) = %v, want %v", tt.target, tt.lib, got, tt.want)
+			}
+		})
+	}
+
+	if !AnyCompatible(MustParseFramework("net8.0"), nil) {
+		t.Error("AnyCompatible() should accept packages without framework-specific assets")
+	}
+}
+
+// TestSupportFor tests framework lifecycle status and upgrade suggestions
+func TestSupportFor(t *testing.T) {
+	now := time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		moniker string
+		status  SupportStatus
+		upgrade string
+	}{
+		{moniker: "net6.0", status: StatusOutOfSupport, upgrade: LatestLTS},
+		{moniker: "net8.0", status: StatusEndingSoon, upgrade: LatestLTS},
+		{moniker: "net10.0", status: StatusSupported, upgrade: ""},
+		{moniker: "net6.0-android", status: StatusOutOfSupport, upgrade: LatestLTS + "-android"},
+		{moniker: "netcoreapp3.1", status: StatusOutOfSupport, upgrade: LatestLTS},
+		{moniker: "net461", status: StatusOutOfSupport, upgrade: LatestNetFramework},
+		{moniker: "net48", status: StatusSupported, upgrade: LatestNetFramework},
+		{moniker: "netstandard2.0", status: StatusNotApplicable, upgrade: ""},
+		{moniker: "net99.0", status: StatusUnknown, upgrade: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.moniker, func(t *testing.T) {
+			f := MustParseFramework(tt.moniker)
+			if got := SupportFor(f, now).Status; got != tt.status {
+				t.Errorf("SupportFor(%s).Status = %q, want %q", tt.moniker, got, tt.status)
+			}
+			if got := SuggestedUpgrade(f); got != tt.upgrade {
+				t.Errorf("SuggestedUpgrade(%s) = %q, want %q", tt.moniker, got, tt.upgrade)
+			}
+		})
+	}
+}
+
+// TestDependenciesFor tests picking the nearest dependency group for a target framework
+func TestDependenciesFor(t *testing.T) {
+	n := &Nuspec{DependencyGroups: []DependencyGroup{
+		{Framework: "netstandard2.0", Dependencies: []Dependency{{ID: "Standard"}}},
+		{Framework: "net6.0", Dependencies: []Dependency{{ID: "Net6"}}},
+		{Framework: "net8.0", Dependencies: []Dependency{{ID: "Net8"}}},
+		{Framework: "net8.0-windows", Dependencies: []Dependency{{ID: "Windows"}}},
+		{Framework: "net462", Dependencies: []Dependency{{ID: "Framework"}}},
+	}}
+	tests := map[string]string{
+		"net9.0":         "Net8",
+		"net7.0":         "Net6",
+		"net8.0-windows": "Windows",
+		"netcoreapp3.1":  "Standard",
+		"net48":          "Framework",
+		"net45":          "",
+	}
+	for target, want := range tests {
+		got := ""
+		if deps := n.DependenciesFor(MustParseFramework(target)); len(deps) > 0 {
+			got = deps[0].ID
+		}
+		if got != want {
+			t.Errorf("DependenciesFor(%s) = %q, want %q", target, got, want)
+		}
+	}
+	neutral := &Nuspec{DependencyGroups: []DependencyGroup{{Dependencies: []Dependency{{ID: "Any"}}}, {Framework: "net8.0"}}}
+	if deps := neutral.DependenciesFor(MustParseFramework("net48")); len(deps) != 1 || deps[0].ID != "Any" {
+		t.Errorf("DependenciesFor() without a compatible group = %v", deps)
+	}
+}
+
+// TestPackageFrameworks tests reading asset frameworks from the global packages folder
+func TestPackageFrameworks(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"lib/net8.0", "lib/netstandard2.0", "ref/net8.0", "build"} {
+		if err := os.MkdirAll(filepath.Join(PackageDir(dir, "Serilog", "3.1.0"), sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	frameworks, ok := PackageFrameworks(dir, "Serilog", "3.1.0")
+	if !ok {
+		t.Fatal("PackageFrameworks() should find the package")
+	}
+	if len(frameworks) != 2 || frameworks[0].Moniker != "net8.0" || frameworks[1].Moniker != "netstandard2.0" {
+		t.Errorf("PackageFrameworks() = %v, want [net8.0 netstandard2.0]", frameworks)
+	}
+
+	if _, ok := PackageFrameworks(dir, "Missing", "1.0.0"); ok {
+		t.Error("PackageFrameworks() should report packages that are not restored")
+	}
+}
+
+// TestNamespaces tests deriving likely namespaces from assembly names and known exceptions
+func TestNamespaces(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{
+		"lib/net8.0/xunit.assert.dll",
+		"lib/net8.0/de/xunit.assert.resources.dll",
+		"lib/netstandard2.0/xunit.assert.dll",
+		"ref/net8.0/Xunit.Abstractions.dll",
+		"lib/net8.0/xunit.assert.xml",
+	} {
+		path := filepath.Join(PackageDir(dir, "xunit", "2.6.0"), filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		id, version string
+		want        []string
+	}{
+		{"xunit", "2.6.0", []string{"Xunit", "Xunit.Abstractions", "xunit.assert"}},
+		{"xunit", "", []string{"Xunit"}},
+		{"Serilog.Sinks.Console", "5.0.0", []string{"Serilog", "Serilog.Sinks.Console"}},
+		{"Newtonsoft.Json", "13.0.3", []string{"Newtonsoft.Json"}},
+	}
+	for _, tt := range tests {
+		if got := Namespaces(dir, tt.id, tt.version); !slices.Equal(got, tt.want) {
+			t.Errorf("Namespaces(%s, %q) = %v, want %v", tt.id, tt.version, got, tt.want)
+		}
+	}
+
+	if got := InstalledVersion(dir, "xunit", "2.*"); got != "2.6.0" {
+		t.Errorf("InstalledVersion(2.*) = %q, want 2.6.0", got)
+	}
+	if got := InstalledVersion(dir, "xunit", "3.0.0"); got != "" {
+		t.Errorf("InstalledVersion(3.0.0) = %q, want none", got)
+	}
+}
+
+// TestFindConfig tests finding the nearest nuget.config regardless of case
+func TestFindConfig(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "src", "App")
+	if err := os.MkdirAll(project, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := FindConfig(project); got != "" && strings.HasPrefix(got, root) {
+		t.Errorf("FindConfig() = %q, want none under the root", got)
+	}
+
+	config := filepath.Join(root, "NuGet.Config")
+	if err := os.WriteFile(config, []byte("<configuration />"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := FindConfig(project); got != config {
+		t.Errorf("FindConfig() = %q, want %q", got, config)
+	}
+}
+
+// TestCompareVersions tests NuGet version ordering
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "1.0.0", b: "1.0", want: 0},
+		{a: "1.10.0", b: "1.9.0", want: 1},
+		{a: "2.0.0-beta.2", b: "2.0.0-beta.10", want: -1},
+		{a: "2.0.0-rc.1", b: "2.0.0", want: -1},
+		{a: "1.0.0.1", b: "1.0.0", want: 1},
+		{a: "1.0.0+build", b: "1.0.0", want: 0},
+		{a: "", b: "0.1", want: -1},
+	}
+
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// TestVersionRange tests version range and floating version semantics
+func TestVersionRange(t *testing.T) {
+	available := []string{"5.0.0", "6.0.0", "6.0.1", "6.0.2-preview.1", "6.1.0", "7.0.0-rc.1", "7.0.0", "7.0.1-beta.2", "8.0.0-preview.3"}
+
+	tests := []struct {
+		rng      string
+		floating bool
+		contains []string
+		excludes []string
+		resolves string
+	}{
+		{rng: "6.0.0", contains: []string{"6.0.0", "7.0.0"}, excludes: []string{"5.0.0", "7.0.0-rc.1"}, resolves: "6.0.0"},
+		{rng: "[6.0,7.0)", contains: []string{"6.0.0", "6.1.0"}, excludes: []string{"7.0.0", "5.0.0"}, resolves: "6.0.0"},
+		{rng: "(6.0.0,]", contains: []string{"6.0.1", "7.0.0"}, excludes: []string{"6.0.0"}, resolves: "6.0.1"},
+		{rng: "(,6.0.1]", contains: []string{"5.0.0", "6.0.1"}, excludes: []string{"6.1.0"}, resolves: "5.0.0"},
+		{rng: "[6.0.1]", contains: []string{"6.0.1"}, excludes: []string{"6.1.0", "6.0.0"}, resolves: "6.0.1"},
+		{rng: "[7.0.0-rc.1, 8.0)", contains: []string{"7.0.0-rc.1", "7.0.0", "7.0.1-beta.2"}, excludes: []string{"6.1.0"}, resolves: "7.0.0-rc.1"},
+		{rng: "6.0.*", floating: true, contains: []string{"6.0.0", "6.0.1"}, excludes: []string{"6.1.0", "6.0.2-preview.1"}, resolves: "6.0.1"},
+		{rng: "6.*", floating: true, contains: []string{"6.1.0"}, excludes: []string{"7.0.0"}, resolves: "6.1.0"},
+		{rng: "*", floating: true, contains: []string{"7.0.0"}, excludes: []string{"8.0.0-preview.3"}, resolves: "7.0.0"},
+		{rng: "*-*", floating: true, contains: []string{"8.0.0-preview.3", "5.0.0"}, resolves: "8.0.0-preview.3"},
+		{rng: "7.0.1-*", floating: true, contains: []string{"7.0.1-beta.2", "7.0.1"}, excludes: []string{"7.0.0", "8.0.0-preview.3"}, resolves: "7.0.1-beta.2"},
+		{rng: "6.0.2-preview.*", floating: true, contains: []string{"6.0.2-preview.1"}, excludes: []string{"6.0.2-rc.1"}, resolves: "6.0.2-preview.1"},
+		{rng: "[6.*, 7.0)", floating: true, contains: []string{"6.1.0"}, excludes: []string{"7.0.0"}, resolves: "6.1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rng, func(t *testing.T) {
+			r, err := ParseVersionRange(tt.rng)
+			if err != nil {
+				t.Fatalf("ParseVersionRange() error = %v", err)
+			}
+			if r.IsFloating() != tt.floating {
+				t.Errorf("IsFloating() = %v, want %v", r.IsFloating(), tt.floating)
+			}
+			for _, v := range tt.contains {
+				if !r.Contains(v) {
+					t.Errorf("Contains(%s) = false, want true", v)
+				}
+			}
+			for _, v := range tt.excludes {
+				if r.Contains(v) {
+					t.Errorf("Contains(%s) = true, want false", v)
+				}
+			}
+			if got, _ := r.Resolve(available); got != tt.resolves {
+				t.Errorf("Resolve() = %q, want %q", got, tt.resolves)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"", "[1.0", "(1.0)", "[2.0, 1.0]", "1.*.0", "[1.0, 2.*]", "1.0-beta*.*"} {
+		if _, err := ParseVersionRange(invalid); err == nil {
+			t.Errorf("ParseVersionRange(%q) should fail", invalid)
+		}
+	}
+
+	if got := Latest(available, false); got != "7.0.0" {
+		t.Errorf("Latest(stable) = %q, want 7.0.0", got)
+	}
+	if got := Latest(available, true); got != "8.0.0-preview.3" {
+		t.Errorf("Latest(prerelease) = %q, want 8.0.0-preview.3", got)
+	}
+}
+
+// TestSearch tests the search request and author lists in either form
+func TestSearch(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		fmt.Fprint(w, `{"totalHits":2,"data":[
+			{"id":"Serilog","version":"4.0.0","description":"Structured logging","authors":["Serilog Contributors"],"owners":["serilog"],"totalDownloads":100,"verified":true,
+			 "versions":[{"version":"3.0.0","downloads":70,"@id":"x"},{"version":"4.0.0","downloads":30,"@id":"y"}]},
+			{"id":"Serilog.Sinks.Foo","version":"1.0.0-beta","authors":"Someone"}]}`)
+	}))
+	defer server.Close()
+
+	feed := &Feed{SearchURL: server.URL}
+	results, err := feed.Search(context.Background(), "serilog", SearchOptions{Skip: 10, Prerelease: true})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if query.Get("q") != "serilog" || query.Get("skip") != "10" || query.Get("take") != "20" || query.Get("prerelease") != "true" {
+		t.Errorf("query = %v", query)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Search() = %+v, want 2 results", results)
+	}
+	if r := results[0]; r.ID != "Serilog" || r.Version != "4.0.0" || !r.Verified || r.TotalDownloads != 100 || strings.Join(r.Authors, ",") != "Serilog Contributors" {
+		t.Errorf("results[0] = %+v", r)
+	}
+	if r := results[1]; strings.Join(r.Authors, ",") != "Someone" || r.Owners != nil {
+		t.Errorf("results[1] authors = %v, owners = %v, want [Someone] and none", r.Authors, r.Owners)
+	}
+	if strings.Join(results[0].Owners, ",") != "serilog" {
+		t.Errorf("results[0].Owners = %v, want [serilog]", results[0].Owners)
+	}
+	if got := results[0].Downloads("4.0.0"); got != 30 {
+		t.Errorf("Downloads(4.0.0) = %d, want 30", got)
+	}
+	if got := results[1].Downloads("1.0.0-beta"); got != 0 || results[1].Versions == nil {
+		t.Errorf("Downloads() without versions = %d, versions %v", got, results[1].Versions)
+	}
+
+	results = []SearchResult{{ID: "A", TotalDownloads: 5}, {ID: "B", TotalDownloads: 50}, {ID: "C", TotalDownloads: 5}}
+	SortByDownloads(results)
+	if ids := []string{results[0].ID, results[1].ID, results[2].ID}; strings.Join(ids, ",") != "B,A,C" {
+		t.Errorf("SortByDownloads() = %v, want B,A,C", ids)
+	}
+
+	if _, err := (&Feed{BaseURL: "https://example.com/v3/"}).Search(context.Background(), "x", SearchOptions{}); err == nil {
+		t.Error("Search() error = nil for a feed without a search service")
+	}
+}
+
+// TestLookup tests looking up one package's search entry
+func TestLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") != "packageid:newtonsoft.json" {
+			fmt.Fprint(w, `{"data":[]}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":[{"id":"Newtonsoft.Json","version":"13.0.3","owners":"dotnetfoundation","verified":true}]}`)
+	}))
+	defer server.Close()
+
+	feed := &Feed{SearchURL: server.URL}
+	r, err := feed.Lookup(context.Background(), "newtonsoft.json")
+	if err != nil || r == nil || !r.Verified || strings.Join(r.Owners, ",") != "dotnetfoundation" {
+		t.Errorf("Lookup() = %+v, %v", r, err)
+	}
+	if r, err := feed.Lookup(context.Background(), "Missing"); r != nil || err != nil {
+		t.Errorf("Lookup() of a missing package = %+v, %v, want nil", r, err)
+	}
+	if r, err := (&Feed{BaseURL: server.URL}).Lookup(context.Background(), "x"); r != nil || err != nil {
+		t.Errorf("Lookup() without a search service = %+v, %v, want nil", r, err)
+	}
+}
+
+// TestTrends tests reading download histories from a NuGet Trends service
+func TestTrends(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/package/history/Serilog":
+			if r.URL.Query().Get("months") != "3" {
+				t.Errorf("months = %q, want 3", r.URL.Query().Get("months"))
+			}
+			fmt.Fprint(w, `{"id":"Serilog","downloads":[
+				{"week":"2024-01-01T00:00:00","count":null},
+				{"week":"2024-01-08T00:00:00","count":100},
+				{"week":"2024-01-15T00:00:00","count":250}]}`)
+		case "/api/package/history/Broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	trends := &Trends{BaseURL: server.URL + "/api/package/history/"}
+	history, err := trends.History(context.Background(), "Serilog", 3)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	want := []WeeklyDownloads{
+		{Week: time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), Count: 100},
+		{Week: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), Count: 250},
+	}
+	if !slices.Equal(history, want) {
+		t.Errorf("History() = %v, want %v", history, want)
+	}
+	if history, err := trends.History(context.Background(), "Unknown", 3); err != nil || history != nil {
+		t.Errorf("History(unknown) = %v, %v, want no history", history, err)
+	}
+
+	results := []SearchResult{{ID: "Serilog"}, {ID: "Broken"}}
+	if err := trends.AddTrends(context.Background(), results, 3); err == nil || !strings.Contains(err.Error(), "Broken") {
+		t.Errorf("AddTrends() error = %v, want the failure for Broken", err)
+	}
+	if len(results[0].Trend) != 2 || results[1].Trend != nil {
+		t.Errorf("AddTrends() trends = %v, %v", results[0].Trend, results[1].Trend)
+	}
+}
+
+// TestSparkline tests scaling counts to sparkline levels
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		counts  []int64
+		unicode bool
+		want    string
+	}{
+		{nil, true, ""},
+		{[]int64{0, 7, 14}, true, "▁▄█"},
+		{[]int64{5, 5, 5}, true, "▁▁▁"},
+		{[]int64{0, 3, 6}, false, "_=#"},
+	}
+	for _, tt := range tests {
+		if got := Sparkline(tt.counts, tt.unicode); got != tt.want {
+			t.Errorf("Sparkline(%v, %v) = %q, want %q", tt.counts, tt.unicode, got, tt.want)
+		}
+	}
+}
+
+// TestFormatCount tests abbreviating download counts
+func TestFormatCount(t *testing.T) {
+	tests := map[int64]string{0: "0", 950: "950", 12_345: "12.3K", 4_500_000: "4.5M", 1_234_567_890: "1.2B"}
+	for n, want := range tests {
+		if got := FormatCount(n); got != want {
+			t.Errorf("FormatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+// TestParseNuspec tests reading dependency groups from a package manifest
+func TestParseNuspec(t *testing.T) {
+	n, err := ParseNuspec([]byte(`<?xml version="1.0"?>
+<package xmlns="http://schemas.microsoft.com/packaging/2013/05/nuspec.xsd">
+  <metadata>
+    <id>Demo</id>
+    <version>1.0.0</version>
+    <license type="expression">MIT OR Apache-2.0</license>
+    <licenseUrl>https://licenses.nuget.org/MIT%20OR%20Apache-2.0</licenseUrl>
+    <dependencies>
+      <dependency id="Everywhere" version="1.0.0" />
+      <group targetFramework=".NETStandard2.0">
+        <dependency id="System.Memory" version="[4.5.4, )" />
+      </group>
+      <group targetFramework=".NETFramework4.6.2" />
+    </dependencies>
+  </metadata>
+</package>`))
+	if err != nil {
+		t.Fatalf("ParseNuspec() error = %v", err)
+	}
+	if n.ID != "Demo" || n.Version != "1.0.0" || len(n.DependencyGroups) != 3 {
+		t.Fatalf("ParseNuspec() = %+v", n)
+	}
+	if n.License != "MIT OR Apache-2.0" || n.LicenseURL == "" {
+		t.Errorf("License = %q, LicenseURL = %q", n.License, n.LicenseURL)
+	}
+	if n, _ := ParseNuspec([]byte(`<package><metadata><license type="file">LICENSE.txt</license></metadata></package>`)); n.License != "" {
+		t.Errorf("License = %q for a license file", n.License)
+	}
+	if g := n.DependencyGroups[0]; g.Framework != "" || len(g.Dependencies) != 1 || g.Dependencies[0].ID != "Everywhere" {
+		t.Errorf("ungrouped dependencies = %+v", g)
+	}
+	if g := n.DependencyGroups[1]; g.Framework != "netstandard2.0" || g.Dependencies[0] != (Dependency{ID: "System.Memory", Range: "[4.5.4, )"}) {
+		t.Errorf("netstandard2.0 group = %+v", g)
+	}
+	if g := n.DependencyGroups[2]; g.Framework != "net462" || len(g.Dependencies) != 0 {
+		t.Errorf("net462 group = %+v", g)
+	}
+
+	if _, err := ParseNuspec([]byte("not xml")); err == nil {
+		t.Error("ParseNuspec() error = nil for invalid XML")
+	}
+}
+
+// TestShortFrameworkName tests converting nuspec framework names to short monikers
+func TestShortFrameworkName(t *testing.T) {
+	tests := map[string]string{
+		".NETStandard2.0":          "netstandard2.0",
+		".NETFramework4.6.2":       "net462",
+		".NETCoreApp,Version=v3.1": "netcoreapp3.1",
+		"net8.0":                   "net8.0",
+		"netstandard2.1":           "netstandard2.1",
+		"":                         "",
+	}
+	for in, want := range tests {
+		if got := ShortFrameworkName(in); got != want {
+			t.Errorf("ShortFrameworkName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// TestAdvisoryAffects tests matching versions, including prereleases, against advisory ranges
+func TestAdvisoryAffects(t *testing.T) {
+	a := Advisory{Versions: "[, 13.0.1)"}
+	for version, want := range map[string]bool{"12.0.3": true, "13.0.1-beta1": true, "13.0.1": false, "13.0.3": false} {
+		if got := a.Affects(version); got != want {
+			t.Errorf("Affects(%s) = %v, want %v", version, got, want)
+		}
+	}
+	if (Advisory{Versions: "not a range"}).Affects("1.0.0") {
+		t.Error("Affects() = true for an invalid range")
+	}
+}
+
+// TestIsPlatformPackage tests recognizing shared frameworks and runtime packs
+func TestIsPlatformPackage(t *testing.T) {
+	for id, want := range map[string]bool{
+		"Microsoft.AspNetCore.App":                      true,
+		"microsoft.netcore.app.runtime.linux-x64":       true,
+		"Microsoft.NETCore.App.Ref":                     true,
+		"Microsoft.DotNet.ILCompiler":                   true,
+		"runtime.linux-x64.Microsoft.DotNet.ILCompiler": true,
+		"Microsoft.AspNetCore.Authentication.JwtBearer": false,
+		"Microsoft.Extensions.Hosting":                  false,
+		"Serilog":                                       false,
+	} {
+		if got := IsPlatformPackage(id); got != want {
+			t.Errorf("IsPlatformPackage(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+// TestReadme tests reading embedded READMEs from the global packages folder and the feed
+func TestReadme(t *testing.T) {
+	packages := t.TempDir()
+	dir := PackageDir(packages, "Local", "1.0.0")
+	if err := os.MkdirAll(filepath.Join(dir, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	nuspec := `<package><metadata><id>Local</id><version>1.0.0</version><readme>docs\README.md</readme></metadata></package>`
+	if err := os.WriteFile(filepath.Join(dir, "local.nuspec"), []byte(nuspec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "docs", "README.md"), []byte("# Local"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/remote/2.0.0/readme" {
+			fmt.Fprint(w, "# Remote")
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	feed := &Feed{BaseURL: server.URL}
+
+	tests := []struct{ id, version, want string }{
+		{"Local", "1.0.0", "# Local"},
+		{"Remote", "2.0.0", "# Remote"},
+		{"Remote", "1.0.0", ""}, // No README
+	}
+	for _, tt := range tests {
+		got, err := feed.Readme(context.Background(), packages, tt.id, tt.version)
+		if err != nil || got != tt.want {
+			t.Errorf("Readme(%s %s) = %q, %v, want %q", tt.id, tt.version, got, err, tt.want)
+		}
+	}
+
+	// A README path may not leave the package folder
+	escape := strings.Replace(nuspec, `docs\README.md`, "../../other/README.md", 1)
+	if err := os.WriteFile(filepath.Join(dir, "local.nuspec"), []byte(escape), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := feed.Readme(context.Background(), packages, "Local", "1.0.0"); err == nil {
+		t.Error("Readme() error = nil for a README outside the package")
+	}
+}
+
+// TestCVSSScore tests computing CVSS 3.x base scores from vectors
+func TestCVSSScore(t *testing.T) {
+	tests := []struct {
+		vector string
+		want   float64
+		ok     bool
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H", 7.5, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N", 6.1, true},
+		{"CVSS:3.0/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N", 1.8, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", 0, true},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", 0, false},
+		{"CVSS:3.1/AV:N/AC:L", 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := CVSSScore(tt.vector); got != tt.want || ok != tt.ok {
+			t.Errorf("CVSSScore(%s) = %v, %v, want %v, %v", tt.vector, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestOSVAdvisories tests merging OSV.dev vulnerabilities into the feed's advisories and caching them
+func TestOSVAdvisories(t *testing.T) {
+	queries := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/vulnerabilities/index.json":
+			fmt.Fprintf(w, `[{"@id": "http://%s/vulnerabilities/base.json"}]`, r.Host)
+		case "/vulnerabilities/base.json":
+			fmt.Fprint(w, `{"newtonsoft.json": [{"url": "https://github.com/advisories/GHSA-5crp-9r3c-p9vr", "severity": 2, "versions": "(, 13.0.1)"}]}`)
+		case "/osv/query":
+			queries++
+			if r.Method != http.MethodPost {
+				t.Errorf("OSV query method = %s", r.Method)
+			}
+			fmt.Fprint(w, `{"vulns": [
+  {"id": "GHSA-5crp-9r3c-p9vr", "aliases": ["CVE-2024-21907"], "summary": "Improper handling of exceptional conditions",
+   "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H"}],
+   "references": [{"type": "ADVISORY", "url": "https://nvd.nist.gov/vuln/detail/CVE-2024-21907"}]},
+  {"id": "OSV-2024-1", "summary": "Only on OSV",
+   "affected": [{"package": {"ecosystem": "NuGet", "name": "Newtonsoft.Json"},
+     "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "9.0.1"}, {"introduced": "11.0.0"}, {"last_affected": "11.0.2"}]}]}],
+   "database_specific": {"severity": "MODERATE"}}
+]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	osv := &OSV{BaseURL: server.URL + "/osv", Cache: cache.New(t.TempDir(), 0), TTL: time.Hour}
+	feed := &Feed{VulnerabilityURL: server.URL + "/vulnerabilities/index.json", OSV: osv}
+	advisories, err := feed.Advisories(context.Background(), "Newtonsoft.Json")
+	if err != nil {
+		t.Fatalf("Advisories() error = %v", err)
+	}
+	if len(advisories) != 3 {
+		t.Fatalf("Advisories() = %+v, want 3", advisories)
+	}
+	merged := advisories[0]
+	if merged.Severity != "High" || merged.Score != 7.5 || merged.Rating() != "High (CVSS 7.5)" ||
+		!slices.Equal(merged.Aliases, []string{"CVE-2024-21907"}) || len(merged.References) != 1 {
+		t.Errorf("merged advisory = %+v", merged)
+	}
+	var ranges []string
+	for _, a := range advisories[1:] {
+		if a.ID != "OSV-2024-1" || a.Severity != "Moderate" || a.URL != "https://osv.dev/vulnerability/OSV-2024-1" {
+			t.Errorf("OSV-only advisory = %+v", a)
+		}
+		ranges = append(ranges, a.Versions)
+	}
+	if !slices.Equal(ranges, []string{"(, 9.0.1)", "[11.0.0, 11.0.2]"}) {
+		t.Errorf("OSV-only ranges = %v", ranges)
+	}
+
+	// Cached within the TTL, queried again after it
+	if _, err := feed.Advisories(context.Background(), "newtonsoft.json"); err != nil || queries != 1 {
+		t.Errorf("second Advisories() made %d queries, error %v; want 1 (cached)", queries, err)
+	}
+	osv.TTL = 0
+	if _, err := feed.Advisories(context.Background(), "Newtonsoft.Json"); err != nil || queries != 2 {
+		t.Errorf("expired Advisories() made %d queries, error %v; want 2", queries, err)
+	}
+
+	// OSV.dev supplements the index; when it fails, the index's advisories remain
+	osv.BaseURL, osv.Cache = server.URL+"/missing", nil
+	if advisories, err := feed.Advisories(context.Background(), "Newtonsoft.Json"); err != nil || len(advisories) != 1 {
+		t.Errorf("Advisories() without OSV.dev = %+v, %v, want the index's advisory", advisories, err)
+	}
+}
+
+// TestPublished tests reading publish dates from registration leaves
+func TestPublished(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/demo/1.0.0.json":
+			fmt.Fprint(w, `{"published": "2021-03-04T05:06:07.89+00:00"}`)
+		case "/demo/0.9.0.json":
+			fmt.Fprint(w, `{"published": "1900-01-01T00:00:00+00:00"}`)
+		case "/broken/1.0.0.json":
+			http.Error(w, "boom", http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	feed := NewFeed()
+	feed.RegistrationURL = server.URL + "/"
+
+	ctx := context.Background()
+	if date, err := feed.Published(ctx, "Demo", "1.0.0"); err != nil || date.Format("2006-01-02") != "2021-03-04" {
+		t.Errorf("Published() = %v, %v", date, err)
+	}
+	for _, version := range []string{"0.9.0", "2.0.0"} {
+		if date, err := feed.Published(ctx, "Demo", version); err != nil || !date.IsZero() {
+			t.Errorf("Published(%s) = %v, %v, want zero time", version, date, err)
+		}
+	}
+	if _, err := feed.Published(ctx, "Broken", "1.0.0"); err == nil {
+		t.Error("Published() error = nil for a server error")
+	}
+}
+
+// TestReleases tests reading listed, dated releases from the registration index
+func TestReleases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/demo/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"items": [{"items": [
+			{"catalogEntry": {"version": "2.0.0", "published": "2023-06-01T00:00:00Z"}},
+			{"catalogEntry": {"version": "1.0.0", "published": "2021-01-01T00:00:00Z"}},
+			{"catalogEntry": {"version": "1.5.0", "published": "1900-01-01T00:00:00Z"}},
+			{"catalogEntry": {"version": "1.1.0", "published": "2022-01-01T00:00:00Z", "listed": false}}
+		]}]}`)
+	}))
+	defer server.Close()
+	feed := NewFeed()
+	feed.RegistrationURL = server.URL + "/"
+
+	releases, err := feed.Releases(context.Background(), "Demo")
+	if err != nil {
+		t.Fatalf("Releases() error = %v", err)
+	}
+	var versions []string
+	for _, r := range releases {
+		versions = append(versions, r.Version)
+	}
+	if want := []string{"1.0.0", "2.0.0"}; !slices.Equal(versions, want) {
+		t.Errorf("Releases() = %v, want %v", versions, want)
+	}
+	if releases, err := feed.Releases(context.Background(), "Missing"); err != nil || len(releases) != 0 {
+		t.Errorf("Releases(Missing) = %v, %v, want none", releases, err)
+	}
+}
+
+// TestHealthOf tests release cadence and staleness
+func TestHealthOf(t *testing.T) {
+	day := 24 * time.Hour
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var releases []Release
+	for i, gap := range []int{0, 30, 30, 90, 30} {
+		start = start.Add(time.Duration(gap) * day)
+		releases = append(releases, Release{Version: fmt.Sprintf("1.%d.0", i), Published: start})
+	}
+	now := start.Add(800 * day)
+
+	h := HealthOf(releases, now, 2*365*day)
+	if h.LastVersion != "1.4.0" || h.Releases != 5 || h.CadenceDays != 30 || !h.Stale {
+		t.Errorf("HealthOf() = %+v", h)
+	}
+	if HealthOf(releases, now, 3*365*day).Stale || HealthOf(releases, now, 0).Stale {
+		t.Error("HealthOf() stale within the limit or without one")
+	}
+	if got := h.Summary(now, "2006-01-02"); got != "last release 1.4.0 on 2020-06-29, 2 years ago; about every 1 month (stale)" {
+		t.Errorf("Summary() = %q", got)
+	}
+	if got := HealthOf(releases[:1], now, 0).Summary(now, "2006-01-02"); !strings.HasSuffix(got, "the only release") {
+		t.Errorf("Summary() of one release = %q", got)
+	}
+	if got := HealthOf(nil, now, 0); got.Releases != 0 || !got.LastPublished.IsZero() {
+		t.Errorf("HealthOf(nil) = %+v", got)
+	}
+	for _, tt := range []struct {
+		days int
+		want string
+	}{{0, "0 days"}, {1, "1 day"}, {20, "2 weeks"}, {45, "1 month"}, {90, "3 months"}, {400, "1 year"}, {1100, "3 years"}} {
+		if got := FormatSpan(time.Duration(tt.days) * day); got != tt.want {
+			t.Errorf("FormatSpan(%d days) = %q, want %q", tt.days, got, tt.want)
+		}
+	}
+}
+
+// TestAzureArtifactsURL tests service index URLs of organization and project feeds
+func TestAzureArtifactsURL(t *testing.T) {
+	if got, err := AzureArtifactsURL("contoso", "", "internal"); err != nil || got != "https://pkgs.dev.azure.com/contoso/_packaging/internal/nuget/v3/index.json" {
+		t.Errorf("AzureArtifactsURL(organization feed) = %q, %v", got, err)
+	}
+	if got, err := AzureArtifactsURL("contoso", "Web Site", "packages"); err != nil || got != "https://pkgs.dev.azure.com/contoso/Web%20Site/_packaging/packages/nuget/v3/index.json" {
+		t.Errorf("AzureArtifactsURL(project feed) = %q, %v", got, err)
+	}
+	for _, args := range [][3]string{{"", "", "feed"}, {"contoso", "a/b", "feed"}, {"contoso", "", "feed?x"}} {
+		if _, err := AzureArtifactsURL(args[0], args[1], args[2]); err == nil {
+			t.Errorf("AzureArtifactsURL(%q) error = nil", args)
+		}
+	}
+}
+
+// TestCheckSource tests credentials against a service index
+func TestCheckSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, password, ok := r.BasicAuth(); !ok || password != "pat" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/index.json" {
+			fmt.Fprint(w, `{"version": "3.0.0", "resources": [{"@id": "https://example.com/flat/", "@type": "PackageBaseAddress/3.0.0"}]}`)
+			return
+		}
+		fmt.Fprint(w, "<html>Sign in</html>")
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	if err := CheckSource(ctx, nil, server.URL+"/index.json", "az", "pat"); err != nil {
+		t.Errorf("CheckSource() error = %v", err)
+	}
+	if err := CheckSource(ctx, nil, server.URL+"/index.json", "az", "wrong"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("CheckSource(wrong PAT) error = %v, want ErrUnauthorized", err)
+	}
+	if err := CheckSource(ctx, nil, server.URL+"/signin", "az", "pat"); err == nil || errors.Is(err, ErrUnauthorized) {
+		t.Errorf("CheckSource(not an index) error = %v", err)
+	}
+}
+
+// TestAddSource tests adding and replacing sources in a nuget.config
+func TestAddSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "NuGet", "NuGet.Config")
+	if err := AddSource(path, Source{Name: "first", URL: "https://example.com/first/index.json"}); err != nil {
+		t.Fatalf("AddSource() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte(`<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <packageSources>
+    <clear />
+    <add key="nuget.org" value="https://api.nuget.org/v3/index.json" protocolVersion="3" />
+    <add key="My Feed" value="https://example.com/old/index.json" />
+  </packageSources>
+  <packageSourceCredentials>
+    <My_x0020_Feed>
+      <add key="Username" value="old" />
+      <add key="ClearTextPassword" value="old" />
+    </My_x0020_Feed>
+  </packageSourceCredentials>
+  <disabledPackageSources />
+</configuration>
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddSource(path, Source{Name: "My Feed", URL: "https://example.com/new/index.json?a=1&b=2", Username: "az", Password: "pat"}); err != nil {
+		t.Fatalf("AddSource() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	want := `<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <packageSources>
+    <clear />
+    <add key="nuget.org" value="https://api.nuget.org/v3/index.json" protocolVersion="3" />
+    <add key="My Feed" value="https://example.com/new/index.json?a=1&amp;b=2" protocolVersion="3" />
+  </packageSources>
+  <packageSourceCredentials>
+    <My_x0020_Feed>
+      <add key="Username" value="az" />
+      <add key="ClearTextPassword" value="pat" />
+    </My_x0020_Feed>
+  </packageSourceCredentials>
+  <disabledPackageSources />
+</configuration>
+`
+	if string(data) != want {
+		t.Errorf("AddSource() wrote\n%s\nwant\n%s", data, want)
+	}
+
+	if err := os.WriteFile(path, []byte("<settings />"), 0o600); err == nil {
+		if err := AddSource(path, Source{Name: "x", URL: "https://example.com/index.json"}); err == nil {
+			t.Error("AddSource() error = nil for a file that is not a NuGet configuration")
+		}
+	}
+}
+
+// TestPresets tests the service index URLs and user names of registry presets
+func TestPresets(t *testing.T) {
+	tests := []struct {
+		preset   string
+		values   map[string]string
+		url      string
+		username string
+	}{
+		{"azure", map[string]string{"organization": "contoso", "feed": "internal"}, "https://pkgs.dev.azure.com/contoso/_packaging/internal/nuget/v3/index.json", "az"},
+		{"GitHub", map[string]string{"owner": "contoso"}, "https://nuget.pkg.github.com/contoso/index.json", "contoso"},
+		{"gitlab", map[string]string{"project": "42"}, "https://gitlab.com/api/v4/projects/42/packages/nuget/index.json", ""},
+		{"gitlab", map[string]string{"project": "mygroup/sub", "group": "true", "host": "gitlab.example.com/"},
+			"https://gitlab.example.com/api/v4/groups/mygroup%2Fsub/-/packages/nuget/index.json", ""},
+		{"gitlab", map[string]string{"project": "42", "host": "http://localhost:8080"}, "http://localhost:8080/api/v4/projects/42/packages/nuget/index.json", ""},
+	}
+	for _, tt := range tests {
+		p, ok := FindPreset(tt.preset)
+		if !ok {
+			t.Fatalf("FindPreset(%q) not found", tt.preset)
+		}
+		if got, err := p.URL(tt.values); err != nil || got != tt.url {
+			t.Errorf("%s URL(%v) = %q, %v, want %q", tt.preset, tt.values, got, err, tt.url)
+		}
+		if got := p.Username(tt.values); got != tt.username {
+			t.Errorf("%s Username() = %q, want %q", tt.preset, got, tt.username)
+		}
+	}
+
+	github, _ := FindPreset("github")
+	gitlab, _ := FindPreset("gitlab")
+	for _, invalid := range []func() error{
+		func() error { _, err := github.URL(map[string]string{"owner": "a/b"}); return err },
+		func() error { _, err := gitlab.URL(map[string]string{"project": "../x"}); return err },
+		func() error { _, err := gitlab.URL(map[string]string{"project": "1", "host": "://"}); return err },
+	} {
+		if invalid() == nil {
+			t.Error("URL() error = nil for an invalid value")
+		}
+	}
+	if _, ok := FindPreset("bitbucket"); ok {
+		t.Error("FindPreset(bitbucket) found a preset")
+	}
+}
+
+// TestProbeFeed tests the resources read from a service index and the fallbacks for the
+// ones a server leaves out
+func TestProbeFeed(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "Artifactory/7.77.3")
+		switch r.URL.Path {
+		case "/index.json":
+			fmt.Fprintf(w, `{"version": "3.0.0", "resources": [
+				{"@id": "%[1]s/registration/", "@type": ["RegistrationsBaseUrl", "RegistrationsBaseUrl/3.0.0-beta"]},
+				{"@id": "%[1]s/query", "@type": "SearchQueryService/3.0.0-rc"}]}`, server.URL)
+		case "/empty/index.json":
+			fmt.Fprint(w, `{"version": "3.0.0", "resources": []}`)
+		case "/registration/demo/index.json":
+			fmt.Fprintf(w, `{"items": [
+				{"@id": "%s/registration/demo/page1.json"},
+				{"items": [{"catalogEntry": {"version": "2.0.0-beta", "published": "2022-01-01T00:00:00Z"}}]}]}`, server.URL)
+		case "/registration/demo/page1.json":
+			fmt.Fprint(w, `{"items": [{"catalogEntry": {"version": "1.0.0", "published": "2021-03-04T05:06:07Z"}}]}`)
+		case "/query":
+			// Paging and the prerelease filter are ignored, as some servers do
+			fmt.Fprint(w, `{"data": [
+				{"id": "A", "version": "1.0.0"},
+				{"id": "B", "version": "2.0.0-beta", "versions": [{"version": "1.5.0"}, {"version": "2.0.0-beta"}]},
+				{"id": "C", "version": "1.0.0-rc"},
+				{"id": "D", "version": "3.0.0"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	feed, err := ProbeFeed(ctx, server.Client(), server.URL+"/index.json")
+	if err != nil {
+		t.Fatalf("ProbeFeed() error = %v", err)
+	}
+	if feed.BaseURL != "" || feed.SearchURL != server.URL+"/query" || feed.RegistrationURL != server.URL+"/registration/" || feed.Server != "Artifactory" {
+		t.Errorf("ProbeFeed() = %+v", feed)
+	}
+
+	if versions, err := feed.Versions(ctx, "Demo"); err != nil || !slices.Equal(versions, []string{"1.0.0", "2.0.0-beta"}) {
+		t.Errorf("Versions() = %v, %v", versions, err)
+	}
+	if versions, err := feed.Versions(ctx, "Missing"); err != nil || len(versions) != 0 {
+		t.Errorf("Versions(missing) = %v, %v", versions, err)
+	}
+	if date, err := feed.Published(ctx, "Demo", "1.0.0"); err != nil || date.Format("2006-01-02") != "2021-03-04" {
+		t.Errorf("Published() = %v, %v", date, err)
+	}
+
+	results, err := feed.Search(ctx, "x", SearchOptions{Skip: 1, Take: 2})
+	var got []string
+	for _, r := range results {
+		got = append(got, r.ID+" "+r.Version)
+	}
+	if err != nil || !slices.Equal(got, []string{"B 1.5.0", "D 3.0.0"}) {
+		t.Errorf("Search() = %v, %v", got, err)
+	}
+
+	feed.SearchURL = ""
+	if results, err := feed.Search(ctx, "Demo", SearchOptions{Prerelease: true}); err != nil || len(results) != 1 || results[0].Version != "2.0.0-beta" {
+		t.Errorf("Search(without a search service) = %+v, %v", results, err)
+	}
+	if results, err := feed.Search(ctx, "json parser", SearchOptions{}); err != nil || len(results) != 0 {
+		t.Errorf("Search(without a search service, not an ID) = %+v, %v", results, err)
+	}
+
+	if _, err := ProbeFeed(ctx, server.Client(), server.URL+"/empty/index.json"); err == nil {
+		t.Error("ProbeFeed() error = nil for an index without packages")
+	}
+	if _, err := ProbeFeed(ctx, server.Client(), server.URL+"/missing/index.json"); err == nil {
+		t.Error("ProbeFeed() error = nil for a missing index")
+	}
+}
+
+// TestFallback tests reading matching packages from a mirror while the feed is down
+func TestFallback(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/public.package/") {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"versions": ["1.0.0", "1.1.0"]}`)
+	}))
+	defer mirror.Close()
+
+	feed := NewFeed()
+	feed.BaseURL = primary.URL + "/"
+	feed.Fallbacks = []*Fallback{{Name: "mirror", Packages: "Contoso.*", URL: mirror.URL + "/"}}
+	var used []string
+	feed.OnFallback = func(id string, fallback *Fallback, err error) {
+		if !Unavailable(err) {
+			t.Errorf("OnFallback() error = %v, want an unavailable feed", err)
+		}
+		used = append(used, id+" "+fallback.Name)
+	}
+
+	ctx := context.Background()
+	if versions, err := feed.Versions(ctx, "Contoso.Core"); err != nil || !slices.Equal(versions, []string{"1.0.0", "1.1.0"}) {
+		t.Errorf("Versions(matching) = %v, %v", versions, err)
+	}
+	if _, err := feed.Versions(ctx, "Other"); !Unavailable(err) {
+		t.Errorf("Versions(not matching) error = %v, want the feed's", err)
+	}
+	if versions, err := feed.Versions(ctx, "Public.Package"); err != nil || versions != nil {
+		t.Errorf("Versions(not found) = %v, %v, want no fallback", versions, err)
+	}
+	if !slices.Equal(used, []string{"Contoso.Core mirror"}) {
+		t.Errorf("OnFallback() calls = %v", used)
+	}
+
+	for _, tt := range []struct {
+		pattern, id string
+		want        bool
+	}{
+		{"*", "Anything", true},
+		{"Contoso.*", "contoso.core", true},
+		{"Contoso.*", "ContosoCore", false},
+		{"Newtonsoft.Json", "newtonsoft.json", true},
+		{"Newtonsoft.Json", "Newtonsoft.Json.Bson", false},
+	} {
+		if got := MatchPackage(tt.pattern, tt.id); got != tt.want {
+			t.Errorf("MatchPackage(%q, %q) = %v, want %v", tt.pattern, tt.id, got, tt.want)
+		}
+	}
+}
+
+// TestTimeoutTransport tests bounding requests by the timeout of their host
+func TestTimeoutTransport(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"versions": ["1.0.0"]}`)
+	}))
+	defer fast.Close()
+
+	timeouts := map[string]time.Duration{
+		slow.Listener.Addr().String(): 50 * time.Millisecond,
+		fast.Listener.Addr().String(): time.Minute,
+	}
+	client := &http.Client{Transport: TimeoutTransport(nil, func(req *http.Request) time.Duration {
+		return timeouts[req.URL.Host]
+	})}
+
+	start := time.Now()
+	if _, err := client.Get(slow.URL); err == nil || !strings.Contains(err.Error(), "did not respond within 50ms") {
+		t.Errorf("Get(slow) error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Get(slow) took %s", elapsed)
+	}
+
+	// The body of a response within its timeout can be read after the round trip returns
+	resp, err := client.Get(fast.URL)
+	if err != nil {
+		t.Fatalf("Get(fast) error = %v", err)
+	}
+	defer resp.Body.Close()
+	if body, err := io.ReadAll(resp.Body); err != nil || !strings.Contains(string(body), "1.0.0") {
+		t.Errorf("Get(fast) body = %q, %v", body, err)
+	}
+}
+
+// TestAuthTransport tests sending each feed's credentials only to its own host
+func TestAuthTransport(t *testing.T) {
+	type seen struct{ auth, apiKey string }
+	var mu sync.Mutex
+	got := make(map[string]seen)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		got[r.Host] = seen{r.Header.Get("Authorization"), r.Header.Get(APIKeyHeader)}
+		mu.Unlock()
+	})
+	basic, apiKey, other := httptest.NewServer(handler), httptest.NewServer(handler), httptest.NewServer(handler)
+	defer basic.Close()
+	defer apiKey.Close()
+	defer other.Close()
+
+	creds := map[string]Credentials{
+		basic.Listener.Addr().String():  {Username: "contoso", Password: "pat"},
+		apiKey.Listener.Addr().String(): {APIKey: "key"},
+	}
+	client := &http.Client{Transport: AuthTransport(nil, func(req *http.Request) Credentials {
+		return creds[req.URL.Host]
+	})}
+	for _, server := range []*httptest.Server{basic, apiKey, other} {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Get(%s) error = %v", server.URL, err)
+		}
+		resp.Body.Close()
+		if req.Header.Get("Authorization") != "" || req.Header.Get(APIKeyHeader) != "" {
+			t.Errorf("AuthTransport modified the caller's request: %v", req.Header)
+		}
+	}
+
+	if s := got[basic.Listener.Addr().String()]; s.auth != "Basic Y29udG9zbzpwYXQ=" || s.apiKey != "" {
+		t.Errorf("basic feed received %+v, want basic authentication", s)
+	}
+	if s := got[apiKey.Listener.Addr().String()]; s.auth != "" || s.apiKey != "key" {
+		t.Errorf("API key feed received %+v, want the X-NuGet-ApiKey header", s)
+	}
+	if s := got[other.Listener.Addr().String()]; s != (seen{}) {
+		t.Errorf("other host received %+v, want no credentials", s)
+	}
+}
+
+// TestTrustPolicy tests reading, merging, and editing trusted signers
+func TestTrustPolicy(t *testing.T) {
+	root := t.TempDir()
+	repoConfig := filepath.Join(root, "nuget.config")
+	userConfig := filepath.Join(root, "user", "NuGet.Config")
+	if err := os.MkdirAll(filepath.Dir(userConfig), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(userConfig, []byte(`<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <config>
+    <add key="signatureValidationMode" value="accept" />
+  </config>
+  <trustedSigners>
+    <author name="contoso">
+      <certificate fingerprint="aa11" hashAlgorithm="SHA256" allowUntrustedRoot="true" />
+    </author>
+    <repository name="nuget.org" serviceIndex="https://old.example.com/index.json">
+      <certificate fingerprint="CC33" hashAlgorithm="SHA512" allowUntrustedRoot="false" />
+    </repository>
+  </trustedSigners>
+</configuration>
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetValidationMode(repoConfig, ValidationRequire); err != nil {
+		t.Fatalf("SetValidationMode() error = %v", err)
+	}
+	err := AddTrustedSigner(repoConfig, TrustedSigner{
+		Kind:         SignerRepository,
+		Name:         "nuget.org",
+		ServiceIndex: "https://api.nuget.org/v3/index.json",
+		Certificates: []TrustedCertificate{{Fingerprint: "bb22"}},
+		Owners:       []string{"microsoft", "jamesnk"},
+	})
+	if err != nil {
+		t.Fatalf("AddTrustedSigner() error = %v", err)
+	}
+	data, _ := os.ReadFile(repoConfig)
+	want := `<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <config>
+    <add key="signatureValidationMode" value="require" />
+  </config>
+  <trustedSigners>
+    <repository name="nuget.org" serviceIndex="https://api.nuget.org/v3/index.json">
+      <certificate fingerprint="BB22" hashAlgorithm="SHA256" allowUntrustedRoot="false" />
+      <owners>microsoft;jamesnk</owners>
+    </repository>
+  </trustedSigners>
+</configuration>
+`
+	if string(data) != want {
+		t.Errorf("AddTrustedSigner() wrote\n%s\nwant\n%s", data, want)
+	}
+
+	policy, err := ReadTrustPolicy([]string{repoConfig, userConfig})
+	if err != nil {
+		t.Fatalf("ReadTrustPolicy() error = %v", err)
+	}
+	if policy.Mode != ValidationRequire {
+		t.Errorf("Mode = %q, want the nearest file's", policy.Mode)
+	}
+	var names []string
+	for _, s := range policy.Signers {
+		names = append(names, s.Kind+" "+s.Name+" "+s.Certificates[0].Fingerprint+" "+filepath.Base(s.File))
+	}
+	if want := []string{"repository nuget.org BB22 nuget.config", "author contoso AA11 NuGet.Config"}; !slices.Equal(names, want) {
+		t.Errorf("Signers = %q, want %q", names, want)
+	}
+	if !policy.Signers[1].Certificates[0].AllowUntrustedRoot || !slices.Equal(policy.Signers[0].Owners, []string{"microsoft", "jamesnk"}) {
+		t.Errorf("Signers = %+v", policy.Signers)
+	}
+
+	if removed, err := RemoveTrustedSigner(repoConfig, "NuGet.org"); err != nil || !removed {
+		t.Errorf("RemoveTrustedSigner() = %v, %v, want true", removed, err)
+	}
+	if removed, err := RemoveTrustedSigner(repoConfig, "nuget.org"); err != nil || removed {
+		t.Errorf("RemoveTrustedSigner(again) = %v, %v, want false", removed, err)
+	}
+	if data, _ := os.ReadFile(repoConfig); strings.Contains(string(data), "<repository") {
+		t.Errorf("RemoveTrustedSigner() left\n%s", data)
+	}
+}
+
+// TestPackageSignature tests reading signers from a package signature and checking them
+// against a trust policy
+func TestPackageSignature(t *testing.T) {
+	author, authorCert := testSigner(t, 1, "Contoso", oidProofOfOrigin)
+	repository, repositoryCert := testSigner(t, 2, "NuGet.org Repository by Microsoft", oidProofOfReceipt,
+		testAttribute(t, oidNuGetServiceIndex, "https://api.nuget.org/v3/index.json", "ia5"),
+		testAttribute(t, oidNuGetPackageOwners, []string{"contoso", "jamesnk"}, ""))
+	counter, err := asn1.Marshal(repository)
+	if err != nil {
+		t.Fatal(err)
+	}
+	author.UnsignedAttrs = []attribute{{Type: oidCountersignature, Values: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: counter}}}
+
+	data := testSignedData(t, author, authorCert, repositoryCert)
+	sig, err := ParsePackageSignature(data)
+	if err != nil {
+		t.Fatalf("ParsePackageSignature() error = %v", err)
+	}
+	if sig.Author == nil || sig.Author.Subject != "Contoso" {
+		t.Fatalf("Author = %+v", sig.Author)
+	}
+	if r := sig.Repository; r == nil || r.ServiceIndex != "https://api.nuget.org/v3/index.json" || !slices.Equal(r.Owners, []string{"contoso", "jamesnk"}) {
+		t.Fatalf("Repository = %+v", r)
+	}
+
+	dir := PackageDir(t.TempDir(), "Contoso.Core", "1.0.0")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	packagesDir := filepath.Dir(filepath.Dir(dir))
+	if sig, err := ReadPackageSignature(packagesDir, "Contoso.Core", "1.0.0"); sig != nil || err != nil {
+		t.Errorf("ReadPackageSignature(unsigned) = %v, %v, want nil", sig, err)
+	}
+	if _, err := ReadPackageSignature(packagesDir, "Contoso.Core", "2.0.0"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ReadPackageSignature(not restored) error = %v, want not exist", err)
+	}
+
+	repositorySigned := testSignedData(t, repository, repositoryCert)
+	if err := os.WriteFile(filepath.Join(dir, SignatureFile), repositorySigned, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	onlyRepository, err := ReadPackageSignature(packagesDir, "Contoso.Core", "1.0.0")
+	if err != nil || onlyRepository.Author != nil || onlyRepository.Repository == nil {
+		t.Fatalf("ReadPackageSignature(repository signed) = %+v, %v", onlyRepository, err)
+	}
+
+	trusted := func(kind string, cert string, owners ...string) TrustedSigner {
+		return TrustedSigner{Kind: kind, Name: kind, Certificates: []TrustedCertificate{{Fingerprint: cert, HashAlgorithm: "SHA384"}}, Owners: owners}
+	}
+	authorFingerprint, repositoryFingerprint := sig.Author.Fingerprint("SHA384"), sig.Repository.Fingerprint("SHA384")
+	tests := []struct {
+		name        string
+		mode        string
+		signer      TrustedSigner
+		sig         *PackageSignature
+		wantMatch   string
+		wantAllowed bool
+	}{
+		{"trusted author", ValidationRequire, trusted(SignerAuthor, authorFingerprint), sig, SignerAuthor, true},
+		{"author certificate as a repository", ValidationRequire, trusted(SignerRepository, authorFingerprint), sig, "", false},
+		{"countersigning repository", ValidationRequire, trusted(SignerRepository, repositoryFingerprint), sig, SignerRepository, true},
+		{"repository of an owner", ValidationRequire, trusted(SignerRepository, repositoryFingerprint, "JamesNK"), onlyRepository, SignerRepository, true},
+		{"repository of other owners", ValidationRequire, trusted(SignerRepository, repositoryFingerprint, "fabrikam"), onlyRepository, "", false},
+		{"unsigned", ValidationRequire, trusted(SignerAuthor, authorFingerprint), nil, "", false},
+		{"untrusted signer accepted", ValidationAccept, trusted(SignerAuthor, "00"), sig, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &TrustPolicy{Mode: tt.mode, Signers: []TrustedSigner{tt.signer}}
+			if matched, allowed := policy.Check(tt.sig); matched != tt.wantMatch || allowed != tt.wantAllowed {
+				t.Errorf("Check() = %q, %v, want %q, %v", matched, allowed, tt.wantMatch, tt.wantAllowed)
+			}
+		})
+	}
+}
+
+// testSigner returns a SignerInfo with a commitment type and attributes, and its
+// self-signed certificate. The signature value is not a real one.
+func testSigner(t *testing.T, serial int64, name string, commitment asn1.ObjectIdentifier, attrs ...attribute) (signerInfo, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sid, err := asn1.Marshal(issuerAndSerial{Issuer: asn1.RawValue{FullBytes: cert.RawIssuer}, Serial: cert.SerialNumber})
+	if err != nil {
+		t.Fatal(err)
+	}
+	indication := struct{ ID asn1.ObjectIdentifier }{commitment}
+	return signerInfo{
+		Version:            1,
+		SID:                asn1.RawValue{FullBytes: sid},
+		DigestAlgorithm:    pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}},
+		SignedAttrs:        append([]attribute{testAttribute(t, oidCommitmentType, indication, "")}, attrs...),
+		SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}},
+		Signature:          []byte{0},
+	}, der
+}
+
+// testAttribute returns a CMS attribute with one value.
+func testAttribute(t *testing.T, oid asn1.ObjectIdentifier, value any, params string) attribute {
+	t.Helper()
+	der, err := asn1.MarshalWithParams(value, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return attribute{Type: oid, Values: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: der}}
+}
+
+// testSignedData returns a package signature with a primary signature and certificates.
+func testSignedData(t *testing.T, primary signerInfo, certs ...[]byte) []byte {
+	t.Helper()
+	certSet, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: bytes.Join(certs, nil)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := asn1.Marshal(struct{ Type asn1.ObjectIdentifier }{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd, err := asn1.Marshal(signedData{
+		Version:          1,
+		DigestAlgorithms: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true},
+		EncapContentInfo: asn1.RawValue{FullBytes: content},
+		Certificates:     rawCertificates{Raw: certSet},
+		SignerInfos:      []signerInfo{primary},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := asn1.Marshal(contentInfo{ContentType: oidSignedData, Content: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sd}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -0,0 +1,241 @@
+package nuget
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrVersionNotFound is returned for a package version the feed does not have.
+var ErrVersionNotFound = errors.New("package version not found")
+
+// maxNuspecSize bounds a package manifest.
+const maxNuspecSize = 4 << 20
+
+// Dependency is a package a package version depends on.
+type Dependency struct {
+	ID    string `json:"id"`
+	Range string `json:"range"` // Version range as written; empty means any version
+}
+
+// DependencyGroup lists the dependencies of a package for one target framework.
+type DependencyGroup struct {
+	Framework    string       `json:"framework"` // Short moniker (e.g., "netstandard2.0"), or "" for every framework
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+// Nuspec is the part of a package manifest (.nuspec) LazyNuGet reads.
+type Nuspec struct {
+	ID               string
+	Version          string
+	Readme           string // Path of the embedded README in the package, or ""
+	License          string // SPDX license expression, or "" (e.g., when the package has a license file)
+	LicenseURL       string // Deprecated by NuGet in favor of License, but still common
+	DependencyGroups []DependencyGroup
+}
+
+// ParseNuspec parses a package manifest. Dependencies outside a group apply to every
+// framework, and group frameworks are converted to short monikers.
+func ParseNuspec(data []byte) (*Nuspec, error) {
+	type dependency struct {
+		ID      string `xml:"id,attr"`
+		Version string `xml:"version,attr"`
+	}
+	var doc struct {
+		Metadata struct {
+			ID      string `xml:"id"`
+			Version string `xml:"version"`
+			Readme  string `xml:"readme"`
+			License struct {
+				Type  string `xml:"type,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"license"`
+			LicenseURL   string `xml:"licenseUrl"`
+			Dependencies struct {
+				Dependencies []dependency `xml:"dependency"`
+				Groups       []struct {
+					TargetFramework string       `xml:"targetFramework,attr"`
+					Dependencies    []dependency `xml:"dependency"`
+				} `xml:"group"`
+			} `xml:"dependencies"`
+		} `xml:"metadata"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse nuspec: %w", err)
+	}
+
+	n := &Nuspec{
+		ID:         doc.Metadata.ID,
+		Version:    doc.Metadata.Version,
+		Readme:     strings.TrimSpace(doc.Metadata.Readme),
+		LicenseURL: strings.TrimSpace(doc.Metadata.LicenseURL),
+	}
+	if strings.EqualFold(doc.Metadata.License.Type, "expression") {
+		n.License = strings.TrimSpace(doc.Metadata.License.Value)
+	}
+	convert := func(deps []dependency) []Dependency {
+		list := make([]Dependency, len(deps))
+		for i, d := range deps {
+			list[i] = Dependency{ID: d.ID, Range: d.Version}
+		}
+		return list
+	}
+	if deps := doc.Metadata.Dependencies.Dependencies; len(deps) > 0 {
+		n.DependencyGroups = append(n.DependencyGroups, DependencyGroup{Dependencies: convert(deps)})
+	}
+	for _, g := range doc.Metadata.Dependencies.Groups {
+		n.DependencyGroups = append(n.DependencyGroups, DependencyGroup{
+			Framework:    ShortFrameworkName(g.TargetFramework),
+			Dependencies: convert(g.Dependencies),
+		})
+	}
+	return n, nil
+}
+
+// nuspecFrameworks maps the long framework names of nuspec files to short monikers.
+var nuspecFrameworks = []struct{ long, short string }{
+	{".netstandard", "netstandard"},
+	{".netcoreapp", "netcoreapp"},
+	{".netframework", "net"},
+	{"netframework", "net"},
+}
+
+// ShortFrameworkName converts a framework as nuspec files write it (".NETStandard2.0",
+// ".NETFramework4.6.2", ".NETCoreApp,Version=v3.1", "net8.0") to its short moniker
+// (netstandard2.0, net462, netcoreapp3.1, net8.0).
+func ShortFrameworkName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.Replace(name, ",version=v", "", 1)
+	for _, f := range nuspecFrameworks {
+		if version, ok := strings.CutPrefix(name, f.long); ok {
+			if f.short == "net" {
+				// .NET Framework short monikers drop the dots: 4.6.2 is net462
+				version = strings.ReplaceAll(version, ".", "")
+			}
+			return f.short + version
+		}
+	}
+	return name
+}
+
+// DependenciesFor returns the dependencies a project targeting target gets: those of the
+// nearest group, or of the group for every framework when no group is compatible.
+func (n *Nuspec) DependenciesFor(target Framework) []Dependency {
+	var frameworks []Framework
+	for _, g := range n.DependencyGroups {
+		if g.Framework != "" {
+			if f, err := ParseFramework(g.Framework); err == nil {
+				frameworks = append(frameworks, f)
+			}
+		}
+	}
+	want := ""
+	if nearest, ok := Nearest(target, frameworks); ok {
+		want = nearest.Moniker
+	}
+	for _, g := range n.DependencyGroups {
+		if g.Framework == want {
+			return g.Dependencies
+		}
+	}
+	return nil
+}
+
+// Nuspec returns the manifest of a package version, from the global packages folder when
+// the version is restored, and from the feed otherwise.
+func (f *Feed) Nuspec(ctx context.Context, packagesDir, id, version string) (*Nuspec, error) {
+	return withFallback(ctx, f, id, func(feed *Feed) (*Nuspec, error) {
+		return feed.nuspec(ctx, packagesDir, id, version)
+	})
+}
+
+// nuspec reads a manifest locally or from this feed.
+func (f *Feed) nuspec(ctx context.Context, packagesDir, id, version string) (*Nuspec, error) {
+	if packagesDir != "" {
+		local := filepath.Join(PackageDir(packagesDir, id, version), strings.ToLower(id)+".nuspec")
+		// #nosec G304 -- path is inside the global packages folder
+		if data, err := os.ReadFile(local); err == nil {
+			return ParseNuspec(data)
+		}
+	}
+
+	url := f.packageURL(id, version) + strings.ToLower(id) + ".nuspec"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the manifest of %s %s: %w", id, version, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s %s", ErrVersionNotFound, id, version)
+	default:
+		return nil, fmt.Errorf("failed to read the manifest of %s %s: %w", id, version, &StatusError{URL: url, Status: resp.Status, StatusCode: resp.StatusCode})
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxNuspecSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the manifest of %s %s: %w", id, version, err)
+	}
+	return ParseNuspec(data)
+}
+
+// PackageSize returns the size of a package version's .nupkg in bytes, from the global
+// packages folder when the version is restored, and from the feed otherwise.
+func (f *Feed) PackageSize(ctx context.Context, packagesDir, id, version string) (int64, error) {
+	return withFallback(ctx, f, id, func(feed *Feed) (int64, error) {
+		return feed.packageSize(ctx, packagesDir, id, version)
+	})
+}
+
+// packageSize reads a package size locally or from this feed.
+func (f *Feed) packageSize(ctx context.Context, packagesDir, id, version string) (int64, error) {
+	name := strings.ToLower(id) + "." + strings.ToLower(version) + ".nupkg"
+	if packagesDir != "" {
+		if info, err := os.Stat(filepath.Join(PackageDir(packagesDir, id, version), name)); err == nil {
+			return info.Size(), nil
+		}
+	}
+
+	url := f.packageURL(id, version) + name
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := f.client().Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the size of %s %s: %w", id, version, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to read the size of %s %s: %w", id, version, &StatusError{URL: url, Status: resp.Status, StatusCode: resp.StatusCode})
+	}
+	if resp.ContentLength < 0 {
+		return 0, fmt.Errorf("failed to read the size of %s %s: %s has no Content-Length", id, version, url)
+	}
+	return resp.ContentLength, nil
+}
+
+// packageURL returns the flat container folder of a package version, ending in a slash.
+func (f *Feed) packageURL(id, version string) string {
+	return strings.TrimSuffix(f.BaseURL, "/") + "/" + strings.ToLower(id) + "/" + strings.ToLower(version) + "/"
+}
+
+// client returns the feed's HTTP client.
+func (f *Feed) client() *http.Client {
+	if f.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return f.HTTPClient
+}
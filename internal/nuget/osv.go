@@ -0,0 +1,311 @@
+package nuget
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/cache"
+	"github.com/willibrandon/lazynuget/internal/metrics"
+)
+
+// DefaultOSVURL is the API of OSV.dev, which aggregates advisories from the GitHub
+// Advisory Database and other sources, with CVSS vectors and references.
+const DefaultOSVURL = "https://api.osv.dev/v1"
+
+// DefaultOSVCacheTTL is how long OSV.dev results are reused when no TTL is configured.
+const DefaultOSVCacheTTL = 24 * time.Hour
+
+// maxOSVResponseSize bounds a page of OSV.dev results.
+const maxOSVResponseSize = 16 << 20
+
+// maxOSVPages bounds the pages read for one package.
+const maxOSVPages = 10
+
+// OSV queries OSV.dev for the vulnerabilities of NuGet packages.
+type OSV struct {
+	HTTPClient *http.Client
+	BaseURL    string
+	Cache      *cache.Cache  // Optional; results are cached per package
+	TTL        time.Duration // How long cached results are used
+}
+
+// NewOSV returns a client for OSV.dev.
+func NewOSV() *OSV {
+	return &OSV{
+		HTTPClient: &http.Client{Transport: metrics.Transport(nil)},
+		BaseURL:    DefaultOSVURL,
+		TTL:        DefaultOSVCacheTTL,
+	}
+}
+
+// OSVVulnerability is a vulnerability in the OSV schema (https://ossf.github.io/osv-schema/),
+// reduced to what advisories use.
+type OSVVulnerability struct {
+	ID       string   `json:"id"`
+	Aliases  []string `json:"aliases"`
+	Summary  string   `json:"summary"`
+	Severity []struct {
+		Type  string `json:"type"`  // CVSS_V3 or CVSS_V4
+		Score string `json:"score"` // The CVSS vector
+	} `json:"severity"`
+	References []struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	} `json:"references"`
+	Affected []struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+		} `json:"package"`
+		Ranges []struct {
+			Type   string `json:"type"`
+			Events []struct {
+				Introduced   string `json:"introduced"`
+				Fixed        string `json:"fixed"`
+				LastAffected string `json:"last_affected"`
+			} `json:"events"`
+		} `json:"ranges"`
+		Versions []string `json:"versions"`
+	} `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"` // e.g., MODERATE (GitHub advisories)
+	} `json:"database_specific"`
+}
+
+// osvCacheEntry is a package's results as stored in the cache.
+type osvCacheEntry struct {
+	Fetched time.Time          `json:"fetched"`
+	Vulns   []OSVVulnerability `json:"vulns"`
+}
+
+// Query returns the vulnerabilities OSV.dev knows for a NuGet package, from the cache
+// when they were fetched within the TTL.
+func (o *OSV) Query(ctx context.Context, id string) ([]OSVVulnerability, error) {
+	key := "osv:" + strings.ToLower(id)
+	if o.Cache != nil {
+		var entry osvCacheEntry
+		if data, ok := o.Cache.Get(key); ok && json.Unmarshal(data, &entry) == nil && time.Since(entry.Fetched) < o.TTL {
+			return entry.Vulns, nil
+		}
+	}
+
+	client := o.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var vulns []OSVVulnerability
+	token := ""
+	for range maxOSVPages {
+		query := map[string]any{"package": map[string]string{"name": id, "ecosystem": "NuGet"}}
+		if token != "" {
+			query["page_token"] = token
+		}
+		body, err := json.Marshal(query)
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(o.BaseURL, "/")+"/query", bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query OSV.dev for %s: %w", id, err)
+		}
+		var page struct {
+			Vulns         []OSVVulnerability `json:"vulns"`
+			NextPageToken string             `json:"next_page_token"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to query OSV.dev for %s: %s", id, resp.Status)
+		}
+		err = json.NewDecoder(io.LimitReader(resp.Body, maxOSVResponseSize)).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse OSV.dev results for %s: %w", id, err)
+		}
+		vulns = append(vulns, page.Vulns...)
+		if token = page.NextPageToken; token == "" {
+			break
+		}
+	}
+
+	if o.Cache != nil {
+		if data, err := json.Marshal(osvCacheEntry{Fetched: time.Now(), Vulns: vulns}); err == nil {
+			_ = o.Cache.Put(key, data)
+		}
+	}
+	return vulns, nil
+}
+
+// MergeAdvisories adds OSV.dev data to a package's advisories. Advisories that OSV.dev also
+// knows, by GHSA or CVE ID, gain its ID, aliases, summary, CVSS score, and references;
+// vulnerabilities only OSV.dev knows are added, one advisory per affected range.
+func MergeAdvisories(id string, advisories []Advisory, vulns []OSVVulnerability) []Advisory {
+	merged := slices.Clone(advisories)
+	matched := make([]bool, len(vulns))
+	for i := range merged {
+		a := &merged[i]
+		for j, v := range vulns {
+			if !v.names(a.Identifier()) {
+				continue
+			}
+			matched[j] = true
+			a.ID, a.Aliases, a.Summary, a.References = v.ID, v.Aliases, v.Summary, v.references()
+			a.Vector, a.Score = v.cvss()
+			break
+		}
+	}
+	for j, v := range vulns {
+		if !matched[j] {
+			merged = append(merged, v.advisories(id)...)
+		}
+	}
+	return merged
+}
+
+// names reports whether the vulnerability has an ID or alias.
+func (v OSVVulnerability) names(id string) bool {
+	return id != "" && (strings.EqualFold(v.ID, id) || slices.ContainsFunc(v.Aliases, func(a string) bool {
+		return strings.EqualFold(a, id)
+	}))
+}
+
+// cvss returns the CVSS vector of the vulnerability, preferring version 3 (which has a
+// computable base score), and its base score.
+func (v OSVVulnerability) cvss() (string, float64) {
+	vector := ""
+	for _, s := range v.Severity {
+		if s.Type == "CVSS_V3" {
+			score, _ := CVSSScore(s.Score)
+			return s.Score, score
+		}
+		if vector == "" {
+			vector = s.Score
+		}
+	}
+	return vector, 0
+}
+
+// references returns the URLs of the vulnerability's references.
+func (v OSVVulnerability) references() []string {
+	var urls []string
+	for _, r := range v.References {
+		if r.URL != "" && !slices.Contains(urls, r.URL) {
+			urls = append(urls, r.URL)
+		}
+	}
+	return urls
+}
+
+// url returns the page of the vulnerability: the GitHub advisory for GHSA IDs, which is
+// also the URL nuget.org's vulnerability index uses, and the OSV.dev page otherwise.
+func (v OSVVulnerability) url() string {
+	if strings.HasPrefix(v.ID, "GHSA-") {
+		return "https://github.com/advisories/" + v.ID
+	}
+	return "https://osv.dev/vulnerability/" + v.ID
+}
+
+// severity returns the severity in nuget.org's terms: from the CVSS score, or from the
+// database's own rating.
+func (v OSVVulnerability) severity(score float64) string {
+	switch {
+	case score >= 9:
+		return "Critical"
+	case score >= 7:
+		return "High"
+	case score >= 4:
+		return "Moderate"
+	case score > 0:
+		return "Low"
+	}
+	if s := strings.ToLower(v.DatabaseSpecific.Severity); s != "" {
+		return strings.ToUpper(s[:1]) + s[1:]
+	}
+	return "Unknown"
+}
+
+// advisories converts the vulnerability to advisories of a package, one per affected
+// version range.
+func (v OSVVulnerability) advisories(id string) []Advisory {
+	vector, score := v.cvss()
+	base := Advisory{
+		URL:        v.url(),
+		Severity:   v.severity(score),
+		ID:         v.ID,
+		Aliases:    v.Aliases,
+		Summary:    v.Summary,
+		Score:      score,
+		Vector:     vector,
+		References: v.references(),
+	}
+
+	var advisories []Advisory
+	add := func(versions string) {
+		a := base
+		a.Versions = versions
+		advisories = append(advisories, a)
+	}
+	for _, affected := range v.Affected {
+		if !strings.EqualFold(affected.Package.Ecosystem, "NuGet") || !strings.EqualFold(affected.Package.Name, id) {
+			continue
+		}
+		ranged := false
+		for _, r := range affected.Ranges {
+			if r.Type != "ECOSYSTEM" && r.Type != "SEMVER" {
+				continue
+			}
+			introduced := ""
+			open := false
+			for _, e := range r.Events {
+				switch {
+				case e.Introduced != "":
+					introduced, open = e.Introduced, true
+				case e.Fixed != "" && open:
+					add(osvRange(introduced, e.Fixed, false))
+					open, ranged = false, true
+				case e.LastAffected != "" && open:
+					add(osvRange(introduced, e.LastAffected, true))
+					open, ranged = false, true
+				}
+			}
+			if open {
+				add(osvRange(introduced, "", false))
+				ranged = true
+			}
+		}
+		if !ranged {
+			for _, version := range affected.Versions {
+				add("[" + version + "]")
+			}
+		}
+	}
+	return advisories
+}
+
+// osvRange writes an OSV range as a NuGet version range. An introduced version of "0"
+// means every earlier version.
+func osvRange(introduced, end string, inclusive bool) string {
+	lower := "[" + introduced
+	if introduced == "0" || introduced == "" {
+		lower = "("
+	}
+	switch {
+	case end == "":
+		return lower + ", )"
+	case inclusive:
+		return lower + ", " + end + "]"
+	default:
+		return lower + ", " + end + ")"
+	}
+}
@@ -0,0 +1,100 @@
+package nuget
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// assetFolders are the package folders whose subfolders are named after target frameworks.
+var assetFolders = []string{"lib", "ref"}
+
+// GlobalPackagesDir returns NuGet's global packages folder: $NUGET_PACKAGES or
+// ~/.nuget/packages.
+func GlobalPackagesDir() string {
+	if dir := os.Getenv("NUGET_PACKAGES"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".nuget", "packages")
+}
+
+// PackageDir returns the folder of a package version in the global packages folder,
+// which stores IDs and versions in lowercase.
+func PackageDir(packagesDir, id, version string) string {
+	return filepath.Join(packagesDir, strings.ToLower(id), strings.ToLower(version))
+}
+
+// PackageFrameworks returns the target frameworks a package version has lib/ or ref/
+// assets for. It returns false when the package is not in the global packages folder
+// (not restored yet). An empty list means the package has no framework-specific assets.
+func PackageFrameworks(packagesDir, id, version string) ([]Framework, bool) {
+	dir := PackageDir(packagesDir, id, version)
+	if _, err := os.Stat(dir); err != nil {
+		return nil, false
+	}
+
+	var frameworks []Framework
+	seen := make(map[string]bool)
+	for _, folder := range assetFolders {
+		entries, err := os.ReadDir(filepath.Join(dir, folder))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := strings.ToLower(entry.Name())
+			if !entry.IsDir() || seen[name] {
+				continue
+			}
+			seen[name] = true
+			if f, err := ParseFramework(name); err == nil {
+				frameworks = append(frameworks, f)
+			}
+		}
+	}
+	slices.SortFunc(frameworks, func(a, b Framework) int { return strings.Compare(a.Moniker, b.Moniker) })
+	return frameworks, true
+}
+
+// AnalyzerOnly reports whether a restored package ships analyzers or source generators
+// (an analyzers/ folder) and nothing to compile or run against (no lib/ or ref/ folder).
+// It returns false when the package is not in the global packages folder.
+func AnalyzerOnly(packagesDir, id, version string) bool {
+	dir := PackageDir(packagesDir, id, version)
+	if !isDir(filepath.Join(dir, "analyzers")) {
+		return false
+	}
+	for _, folder := range assetFolders {
+		if isDir(filepath.Join(dir, folder)) {
+			return false
+		}
+	}
+	return true
+}
+
+// developmentDependencyPattern matches the nuspec flag that marks build-time-only packages.
+var developmentDependencyPattern = regexp.MustCompile(`(?i)<developmentDependency>\s*true\s*</developmentDependency>`)
+
+// DevelopmentDependency reports whether a restored package's nuspec declares
+// <developmentDependency>true</developmentDependency>, which NuGet uses to add
+// PrivateAssets="all" when the package is installed.
+func DevelopmentDependency(packagesDir, id, version string) bool {
+	path := filepath.Join(PackageDir(packagesDir, id, version), strings.ToLower(id)+".nuspec")
+	// #nosec G304 -- path is inside the global packages folder
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return developmentDependencyPattern.Match(data)
+}
+
+// isDir reports whether path exists and is a directory.
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
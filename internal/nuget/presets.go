@@ -0,0 +1,119 @@
+package nuget
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Preset knows the service index URL and authentication of a hosted NuGet registry, so
+// setting up a private feed takes only its owner and a token.
+type Preset struct {
+	Name        string        // Subcommand of `lazynuget feeds` (e.g., "github")
+	Title       string        // Name of the registry (e.g., "GitHub Packages")
+	Fields      []PresetField // What identifies a feed, in order
+	TokenEnv    string        // Environment variable that usually holds the token
+	TokenScopes string        // What the token needs to read packages
+	URL         func(values map[string]string) (string, error)
+	// Username returns the user name sent with the token; "" means the user must enter one
+	Username func(values map[string]string) string
+}
+
+// PresetField is a value that identifies a feed of a registry.
+type PresetField struct {
+	Name     string // Key in the values map, and the flag or argument name
+	Prompt   string // Asked when the value was not given
+	Optional bool
+}
+
+// presetName matches owner, group, and project names of GitHub and GitLab.
+var presetName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Presets are the hosted registries LazyNuGet can set up feeds for.
+var Presets = []Preset{
+	{
+		Name:  "azure",
+		Title: "Azure Artifacts",
+		Fields: []PresetField{
+			{Name: "organization", Prompt: "Azure DevOps organization"},
+			{Name: "feed", Prompt: "Feed name"},
+			{Name: "project", Prompt: "Project (empty for an organization-scoped feed)", Optional: true},
+		},
+		TokenEnv:    "AZURE_DEVOPS_EXT_PAT",
+		TokenScopes: "a personal access token with the Packaging (Read) scope",
+		URL: func(v map[string]string) (string, error) {
+			return AzureArtifactsURL(v["organization"], v["project"], v["feed"])
+		},
+		// Azure DevOps ignores the user name of PATs, but NuGet needs one
+		Username: func(map[string]string) string { return "az" },
+	},
+	{
+		Name:  "github",
+		Title: "GitHub Packages",
+		Fields: []PresetField{
+			{Name: "owner", Prompt: "User or organization that owns the packages"},
+		},
+		TokenEnv:    "GITHUB_TOKEN",
+		TokenScopes: "a personal access token (classic) with the read:packages scope",
+		URL: func(v map[string]string) (string, error) {
+			if !presetName.MatchString(v["owner"]) {
+				return "", fmt.Errorf("invalid GitHub owner %q", v["owner"])
+			}
+			return "https://nuget.pkg.github.com/" + v["owner"] + "/index.json", nil
+		},
+		// The user name must be the token's owner, most often the owner of the packages
+		Username: func(v map[string]string) string { return v["owner"] },
+	},
+	{
+		Name:  "gitlab",
+		Title: "GitLab package registry",
+		Fields: []PresetField{
+			{Name: "project", Prompt: "Project ID or path (e.g., 42 or mygroup/myproject)"},
+		},
+		TokenEnv:    "GITLAB_TOKEN",
+		TokenScopes: "a personal, group, or deploy token with the read_package_registry (or read_api) scope",
+		URL: func(v map[string]string) (string, error) {
+			return GitLabURL(v["host"], v["project"], v["group"] != "")
+		},
+		// GitLab checks the user name against the token's owner
+		Username: func(map[string]string) string { return "" },
+	},
+}
+
+// FindPreset returns the preset of a registry by name.
+func FindPreset(name string) (Preset, bool) {
+	for _, p := range Presets {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+// GitLabURL returns the service index of a GitLab project's package registry, or of a
+// group's, which has the packages of all its projects. host is "" for gitlab.com; id is a
+// numeric ID or a full path (e.g., mygroup/myproject), which GitLab takes URL-encoded.
+func GitLabURL(host, id string, group bool) (string, error) {
+	if host == "" {
+		host = "gitlab.com"
+	}
+	host = strings.TrimSuffix(host, "/")
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	base, err := url.Parse(host)
+	if err != nil || base.Hostname() == "" {
+		return "", fmt.Errorf("invalid GitLab host %q", host)
+	}
+	for _, part := range strings.Split(id, "/") {
+		if !presetName.MatchString(part) {
+			return "", fmt.Errorf("invalid GitLab project or group %q", id)
+		}
+	}
+	escaped := url.PathEscape(id)
+	if group {
+		return base.String() + "/api/v4/groups/" + escaped + "/-/packages/nuget/index.json", nil
+	}
+	return base.String() + "/api/v4/projects/" + escaped + "/packages/nuget/index.json", nil
+}
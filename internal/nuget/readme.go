@@ -0,0 +1,74 @@
+package nuget
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxReadmeSize bounds a package README; nuget.org rejects larger ones.
+const maxReadmeSize = 1 << 20
+
+// Readme returns the README embedded in a package version (Markdown), or "" when the
+// package has none. Restored versions are read from the global packages folder; others
+// from the feed.
+func (f *Feed) Readme(ctx context.Context, packagesDir, id, version string) (string, error) {
+	return withFallback(ctx, f, id, func(feed *Feed) (string, error) {
+		return feed.readme(ctx, packagesDir, id, version)
+	})
+}
+
+// readme reads a README locally or from this feed.
+func (f *Feed) readme(ctx context.Context, packagesDir, id, version string) (string, error) {
+	if packagesDir != "" {
+		dir := PackageDir(packagesDir, id, version)
+		// #nosec G304 -- path is inside the global packages folder
+		if data, err := os.ReadFile(filepath.Join(dir, strings.ToLower(id)+".nuspec")); err == nil {
+			nuspec, err := ParseNuspec(data)
+			if err != nil {
+				return "", err
+			}
+			if nuspec.Readme == "" {
+				return "", nil
+			}
+			path := filepath.Join(dir, filepath.FromSlash(strings.ReplaceAll(nuspec.Readme, "\\", "/")))
+			if !strings.HasPrefix(path, dir+string(filepath.Separator)) {
+				return "", fmt.Errorf("the README of %s %s is outside the package", id, version)
+			}
+			// #nosec G304 -- path was checked to be inside the package folder
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", fmt.Errorf("failed to read the README of %s %s: %w", id, version, err)
+			}
+			return string(data), nil
+		}
+	}
+
+	url := f.packageURL(id, version) + "readme"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := f.client().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download the README of %s %s: %w", id, version, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", nil
+	default:
+		return "", fmt.Errorf("failed to download the README of %s %s: %w", id, version, &StatusError{URL: url, Status: resp.Status, StatusCode: resp.StatusCode})
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxReadmeSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to download the README of %s %s: %w", id, version, err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,49 @@
+package nuget
+
+import "strings"
+
+// SharedFrameworks are the frameworks a project references with <FrameworkReference>;
+// the SDK provides them, so they never come from a feed.
+var SharedFrameworks = []string{
+	"Microsoft.NETCore.App",
+	"Microsoft.AspNetCore.App",
+	"Microsoft.WindowsDesktop.App",
+	"Microsoft.WindowsDesktop.App.WPF",
+	"Microsoft.WindowsDesktop.App.WindowsForms",
+}
+
+// platformPrefixes start the IDs of the targeting, runtime, host, and compiler packs the
+// SDK downloads for a shared framework (e.g., Microsoft.NETCore.App.Runtime.linux-x64).
+var platformPrefixes = []string{
+	"Microsoft.NETCore.App.",
+	"Microsoft.AspNetCore.App.",
+	"Microsoft.WindowsDesktop.App.",
+	"Microsoft.DotNet.ILCompiler",
+	"Microsoft.NET.ILLink.Tasks",
+	"Microsoft.Android.Runtime.",
+	"Microsoft.iOS.Runtime.",
+	"Microsoft.MacCatalyst.Runtime.",
+	"Microsoft.macOS.Runtime.",
+}
+
+// IsPlatformPackage reports whether a package is part of the .NET platform rather than a
+// library: a shared framework (e.g., Microsoft.AspNetCore.App, which ASP.NET Core 2.x
+// projects referenced as a package without a version), or a targeting or runtime pack.
+func IsPlatformPackage(id string) bool {
+	for _, f := range SharedFrameworks {
+		if strings.EqualFold(id, f) {
+			return true
+		}
+	}
+	if strings.EqualFold(id, "Microsoft.AspNetCore.All") {
+		return true
+	}
+	lower := strings.ToLower(id)
+	for _, prefix := range platformPrefixes {
+		if strings.HasPrefix(lower, strings.ToLower(prefix)) {
+			return true
+		}
+	}
+	// Native AOT compiler packs for other runtimes: runtime.linux-x64.Microsoft.DotNet.ILCompiler
+	return strings.HasPrefix(lower, "runtime.") && strings.HasSuffix(lower, ".microsoft.dotnet.ilcompiler")
+}
@@ -0,0 +1,245 @@
+package nuget
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SignatureFile is the package signature, a CMS SignedData, that restore extracts into the
+// package's folder in the global packages folder.
+const SignatureFile = ".signature.p7s"
+
+// Object identifiers of the CMS structures and attributes in NuGet package signatures.
+var (
+	oidSignedData         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidCountersignature   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 6}
+	oidCommitmentType     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 16}
+	oidProofOfOrigin      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 6, 1} // Author signature
+	oidProofOfReceipt     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 6, 2} // Repository signature
+	oidNuGetServiceIndex  = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 84, 2, 1, 1, 1}
+	oidNuGetPackageOwners = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 84, 2, 1, 1, 2}
+)
+
+// PackageSignature is who signed a package: its author, a repository, or both when the
+// repository countersigned an author signature.
+type PackageSignature struct {
+	Author     *Signer `json:"author,omitempty"`
+	Repository *Signer `json:"repository,omitempty"`
+}
+
+// Signer is the certificate of an author or repository signature.
+type Signer struct {
+	Subject      string            `json:"subject"`
+	Certificate  *x509.Certificate `json:"-"`
+	ServiceIndex string            `json:"serviceIndex,omitempty"` // Repository signatures only
+	Owners       []string          `json:"owners,omitempty"`       // Repository signatures only; the package's owners on the repository
+}
+
+// Fingerprint returns the fingerprint of the signing certificate with a hash algorithm
+// of trustedSigners (SHA256, SHA384, or SHA512), in upper-case hex, or "" for another
+// algorithm.
+func (s *Signer) Fingerprint(algorithm string) string {
+	var sum []byte
+	switch hashAlgorithm(algorithm) {
+	case "SHA256":
+		h := sha256.Sum256(s.Certificate.Raw)
+		sum = h[:]
+	case "SHA384":
+		h := sha512.Sum384(s.Certificate.Raw)
+		sum = h[:]
+	case "SHA512":
+		h := sha512.Sum512(s.Certificate.Raw)
+		sum = h[:]
+	default:
+		return ""
+	}
+	return strings.ToUpper(hex.EncodeToString(sum))
+}
+
+// ReadPackageSignature reads the signature of a restored package. It returns nil for an
+// unsigned package, and an error wrapping fs.ErrNotExist when the package is not in the
+// global packages folder.
+func ReadPackageSignature(packagesDir, id, version string) (*PackageSignature, error) {
+	dir := PackageDir(packagesDir, id, version)
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("%s %s is not in the global packages folder: %w", id, version, err)
+	}
+	// #nosec G304 -- path is inside the global packages folder
+	data, err := os.ReadFile(filepath.Join(dir, SignatureFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	sig, err := ParsePackageSignature(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the signature of %s %s: %w", id, version, err)
+	}
+	return sig, nil
+}
+
+// contentInfo is a CMS ContentInfo.
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+// signedData is a CMS SignedData. Its content (the package's hash) is not read.
+type signedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo asn1.RawValue
+	Certificates     rawCertificates `asn1:"optional,tag:0"`
+	CRLs             []asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      []signerInfo    `asn1:"set"`
+}
+
+// rawCertificates is the certificate set of a SignedData, with its tag.
+type rawCertificates struct {
+	Raw asn1.RawContent
+}
+
+// signerInfo is a CMS SignerInfo.
+type signerInfo struct {
+	Version            int
+	SID                asn1.RawValue // IssuerAndSerialNumber, or [0] SubjectKeyIdentifier
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        []attribute `asn1:"optional,omitempty,tag:0"`
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      []attribute `asn1:"optional,omitempty,tag:1"`
+}
+
+// attribute is a CMS Attribute; Values holds the encoded values of the set.
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue `asn1:"set"`
+}
+
+// issuerAndSerial identifies a certificate by its issuer and serial number.
+type issuerAndSerial struct {
+	Issuer asn1.RawValue
+	Serial *big.Int
+}
+
+// ParsePackageSignature reads who signed a package from its signature file. The
+// signature is not verified.
+func ParsePackageSignature(data []byte) (*PackageSignature, error) {
+	var info contentInfo
+	if _, err := asn1.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	if !info.ContentType.Equal(oidSignedData) {
+		return nil, errors.New("not a CMS signed-data")
+	}
+	var sd signedData
+	if _, err := asn1.Unmarshal(info.Content.Bytes, &sd); err != nil {
+		return nil, err
+	}
+	var certs []*x509.Certificate
+	if len(sd.Certificates.Raw) > 0 {
+		var set asn1.RawValue
+		if _, err := asn1.Unmarshal(sd.Certificates.Raw, &set); err != nil {
+			return nil, err
+		}
+		var err error
+		if certs, err = x509.ParseCertificates(set.Bytes); err != nil {
+			return nil, err
+		}
+	}
+	if len(sd.SignerInfos) != 1 {
+		return nil, fmt.Errorf("%d primary signatures, want 1", len(sd.SignerInfos))
+	}
+
+	sig := &PackageSignature{}
+	primary := sd.SignerInfos[0]
+	switch signer := newSigner(primary, certs); {
+	case signer == nil:
+		return nil, errors.New("the signing certificate is missing")
+	case commitmentType(primary).Equal(oidProofOfOrigin):
+		sig.Author = signer
+	case commitmentType(primary).Equal(oidProofOfReceipt):
+		sig.Repository = signer
+	default:
+		return nil, errors.New("the primary signature is neither an author nor a repository signature")
+	}
+	for _, attr := range primary.UnsignedAttrs {
+		if !attr.Type.Equal(oidCountersignature) {
+			continue
+		}
+		var counter signerInfo
+		if _, err := asn1.Unmarshal(attr.Values.Bytes, &counter); err != nil {
+			return nil, err
+		}
+		if commitmentType(counter).Equal(oidProofOfReceipt) {
+			sig.Repository = newSigner(counter, certs)
+		}
+	}
+	return sig, nil
+}
+
+// newSigner returns the signer of a SignerInfo, or nil when its certificate is not in
+// certs.
+func newSigner(si signerInfo, certs []*x509.Certificate) *Signer {
+	var cert *x509.Certificate
+	var id issuerAndSerial
+	if _, err := asn1.Unmarshal(si.SID.FullBytes, &id); err == nil {
+		for _, c := range certs {
+			if bytes.Equal(c.RawIssuer, id.Issuer.FullBytes) && c.SerialNumber.Cmp(id.Serial) == 0 {
+				cert = c
+			}
+		}
+	} else if si.SID.Class == asn1.ClassContextSpecific && si.SID.Tag == 0 {
+		for _, c := range certs {
+			if bytes.Equal(c.SubjectKeyId, si.SID.Bytes) {
+				cert = c
+			}
+		}
+	}
+	if cert == nil {
+		return nil
+	}
+
+	s := &Signer{Subject: cert.Subject.CommonName, Certificate: cert}
+	if s.Subject == "" {
+		s.Subject = cert.Subject.String()
+	}
+	for _, attr := range si.SignedAttrs {
+		switch {
+		case attr.Type.Equal(oidNuGetServiceIndex):
+			_, _ = asn1.Unmarshal(attr.Values.Bytes, &s.ServiceIndex)
+		case attr.Type.Equal(oidNuGetPackageOwners):
+			_, _ = asn1.Unmarshal(attr.Values.Bytes, &s.Owners)
+		}
+	}
+	return s
+}
+
+// commitmentType returns the commitment type a signature indicates: proof of origin for
+// authors, proof of receipt for repositories.
+func commitmentType(si signerInfo) asn1.ObjectIdentifier {
+	for _, attr := range si.SignedAttrs {
+		if attr.Type.Equal(oidCommitmentType) {
+			var indication struct {
+				ID         asn1.ObjectIdentifier
+				Qualifiers asn1.RawValue `asn1:"optional"`
+			}
+			if _, err := asn1.Unmarshal(attr.Values.Bytes, &indication); err == nil {
+				return indication.ID
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package nuget
+
+import (
+	"fmt"
+	"time"
+)
+
+// SupportStatus describes where a target framework is in its support lifecycle.
+type SupportStatus string
+
+// Support statuses.
+const (
+	StatusSupported     SupportStatus = "supported"
+	StatusEndingSoon    SupportStatus = "ending soon"
+	StatusOutOfSupport  SupportStatus = "out of support"
+	StatusNotApplicable SupportStatus = "n/a" // .NET Standard is a specification, not a runtime
+	StatusUnknown       SupportStatus = "unknown"
+)
+
+// EndingSoonWindow is how long before end of support a framework is flagged.
+const EndingSoonWindow = 180 * 24 * time.Hour
+
+// LatestLTS is the newest long-term support release, suggested for upgrades.
+const LatestLTS = "net10.0"
+
+// LatestNetFramework is the newest .NET Framework, suggested where moving to .NET is a port.
+const LatestNetFramework = "net481"
+
+// lifecycle records a release's end of support. A zero End means no announced date.
+type lifecycle struct {
+	End time.Time
+	LTS bool
+}
+
+// date builds a UTC date for the lifecycle tables.
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// netLifecycles are the .NET and .NET Core releases, keyed by major.minor.
+var netLifecycles = map[string]lifecycle{
+	"1.0":  {End: date(2019, time.June, 27), LTS: true},
+	"1.1":  {End: date(2019, time.June, 27)},
+	"2.0":  {End: date(2018, time.October, 1)},
+	"2.1":  {End: date(2021, time.August, 21), LTS: true},
+	"2.2":  {End: date(2019, time.December, 23)},
+	"3.0":  {End: date(2020, time.March, 3)},
+	"3.1":  {End: date(2022, time.December, 13), LTS: true},
+	"5.0":  {End: date(2022, time.May, 10)},
+	"6.0":  {End: date(2024, time.November, 12), LTS: true},
+	"7.0":  {End: date(2024, time.May, 14)},
+	"8.0":  {End: date(2026, time.November, 10), LTS: true},
+	"9.0":  {End: date(2026, time.November, 10)},
+	"10.0": {End: date(2028, time.November, 14), LTS: true},
+}
+
+// Support describes a framework's lifecycle at a point in time.
+type Support struct {
+	EndOfSupport time.Time // Zero when no end date is announced
+	Status       SupportStatus
+	LTS          bool
+}
+
+// SupportFor returns the support status of a framework at now.
+func SupportFor(f Framework, now time.Time) Support {
+	switch f.Family {
+	case FamilyNetStandard:
+		return Support{Status: StatusNotApplicable}
+	case FamilyNet, FamilyNetCoreApp:
+		l, ok := netLifecycles[fmt.Sprintf("%d.%d", f.Major, f.Minor)]
+		if !ok {
+			return Support{Status: StatusUnknown}
+		}
+		return Support{EndOfSupport: l.End, LTS: l.LTS, Status: statusAt(l.End, now)}
+	case FamilyNetFramework:
+		end := netFrameworkEnd(f)
+		return Support{EndOfSupport: end, Status: statusAt(end, now)}
+	}
+	return Support{Status: StatusUnknown}
+}
+
+// netFrameworkEnd returns the end of support of a .NET Framework version. 4.6.2 and later
+// follow the lifecycle of the Windows version they ship with and have no fixed date.
+func netFrameworkEnd(f Framework) time.Time {
+	v := f.Major*100 + f.Minor*10 + f.Patch
+	switch {
+	case v >= 462:
+		return time.Time{}
+	case v >= 452:
+		return date(2022, time.April, 26)
+	case v >= 400:
+		return date(2016, time.January, 12)
+	case v >= 350:
+		return date(2029, time.January, 9)
+	default:
+		return date(2011, time.July, 12)
+	}
+}
+
+// statusAt classifies an end-of-support date relative to now.
+func statusAt(end, now time.Time) SupportStatus {
+	switch {
+	case end.IsZero():
+		return StatusSupported
+	case !now.Before(end):
+		return StatusOutOfSupport
+	case end.Sub(now) <= EndingSoonWindow:
+		return StatusEndingSoon
+	}
+	return StatusSupported
+}
+
+// SuggestedUpgrade returns the framework to retarget to, keeping any platform suffix, or
+// "" when the framework is current or has no natural successor (e.g., .NET Standard).
+func SuggestedUpgrade(f Framework) string {
+	switch f.Family {
+	case FamilyNet, FamilyNetCoreApp:
+		latest := MustParseFramework(LatestLTS)
+		if f.Family == FamilyNet && f.compareVersion(latest) >= 0 {
+			return ""
+		}
+		if f.Platform != "" {
+			return LatestLTS + "-" + f.Platform
+		}
+		return LatestLTS
+	case FamilyNetFramework:
+		if f.compareVersion(MustParseFramework(LatestNetFramework)) >= 0 {
+			return ""
+		}
+		return LatestNetFramework
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package nuget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// timeoutTransport bounds each request by a timeout looked up for it, see
+// TimeoutTransport.
+type timeoutTransport struct {
+	next    http.RoundTripper
+	timeout func(*http.Request) time.Duration
+}
+
+// TimeoutTransport wraps an http.RoundTripper to give up on a request, reading its body
+// included, once the duration timeout returns for it has passed. The timeout is looked
+// up for every request, so a feed that is known to be slow can be given longer than the
+// rest, and a changed setting applies to the next request. A timeout of 0 leaves the
+// request unbounded. Nil uses http.DefaultTransport.
+func TimeoutTransport(next http.RoundTripper, timeout func(*http.Request) time.Duration) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &timeoutTransport{next: next, timeout: timeout}
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	d := t.timeout(req)
+	if d <= 0 {
+		return t.next.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), d)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && req.Context().Err() == nil {
+			return nil, fmt.Errorf("%s did not respond within %s: %w", req.URL.Host, d, err)
+		}
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelBody releases the timeout of a request once its body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
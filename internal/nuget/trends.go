@@ -0,0 +1,188 @@
+package nuget
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/metrics"
+)
+
+// DefaultTrendsURL is the download history service of NuGet Trends (nugettrends.com), which
+// records nuget.org download counts weekly.
+const DefaultTrendsURL = "https://nugettrends.com/api/package/history/"
+
+// maxHistorySize bounds a package's download history.
+const maxHistorySize = 1 << 20
+
+// WeeklyDownloads is a package's total download count at the start of a week.
+type WeeklyDownloads struct {
+	Week  time.Time `json:"week"`
+	Count int64     `json:"count"`
+}
+
+// Trends reads download histories from a NuGet Trends service.
+type Trends struct {
+	HTTPClient *http.Client
+	BaseURL    string
+}
+
+// NewTrends returns a client for nugettrends.com.
+func NewTrends() *Trends {
+	return &Trends{
+		HTTPClient: &http.Client{Transport: metrics.Transport(nil)},
+		BaseURL:    DefaultTrendsURL,
+	}
+}
+
+// History returns a package's weekly download counts over the last months, oldest first.
+// A package the service does not track has no history.
+func (t *Trends) History(ctx context.Context, id string, months int) ([]WeeklyDownloads, error) {
+	u := strings.TrimSuffix(t.BaseURL, "/") + "/" + url.PathEscape(id) + "?months=" + strconv.Itoa(months)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := t.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the download history of %s: %w", id, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("failed to read the download history of %s: %s returned %s", id, u, resp.Status)
+	}
+
+	var history struct {
+		Downloads []struct {
+			Week  string `json:"week"`
+			Count *int64 `json:"count"` // Null for weeks before the package was tracked
+		} `json:"downloads"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxHistorySize)).Decode(&history); err != nil {
+		return nil, fmt.Errorf("failed to parse the download history of %s: %w", id, err)
+	}
+	weeks := make([]WeeklyDownloads, 0, len(history.Downloads))
+	for _, d := range history.Downloads {
+		if d.Count == nil {
+			continue
+		}
+		// Weeks are dates without a time zone
+		week, err := time.Parse("2006-01-02T15:04:05", strings.TrimSuffix(d.Week, "Z"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the download history of %s: invalid week %q", id, d.Week)
+		}
+		weeks = append(weeks, WeeklyDownloads{Week: week, Count: *d.Count})
+	}
+	return weeks, nil
+}
+
+// maxConcurrentHistories bounds the requests Histories makes at once.
+const maxConcurrentHistories = 8
+
+// Histories returns the download histories of several packages, keyed by package ID, over
+// the last months. Packages whose history cannot be read are missing from the map, and
+// their errors are joined.
+func (t *Trends) Histories(ctx context.Context, ids []string, months int) (map[string][]WeeklyDownloads, error) {
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		errs      []error
+		histories = make(map[string][]WeeklyDownloads, len(ids))
+		slots     = make(chan struct{}, maxConcurrentHistories)
+	)
+	for _, id := range ids {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer func() { <-slots; wg.Done() }()
+			history, err := t.History(ctx, id, months)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			histories[id] = history
+		}()
+	}
+	wg.Wait()
+	return histories, errors.Join(errs...)
+}
+
+// AddTrends sets the Trend of each search result to its download history over the last
+// months. Results whose history cannot be read keep no trend, and the errors are joined.
+func (t *Trends) AddTrends(ctx context.Context, results []SearchResult, months int) error {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	histories, err := t.Histories(ctx, ids, months)
+	for i := range results {
+		results[i].Trend = histories[results[i].ID]
+	}
+	return err
+}
+
+// sparkBlocks and sparkASCII are the levels of a sparkline, lowest first.
+var (
+	sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+	sparkASCII  = []rune("_.-=+*#")
+)
+
+// Sparkline draws counts as one character each, scaled between the smallest and largest
+// count. Without unicode, ASCII characters stand in for the block elements.
+func Sparkline(counts []int64, unicode bool) string {
+	if len(counts) == 0 {
+		return ""
+	}
+	levels := sparkBlocks
+	if !unicode {
+		levels = sparkASCII
+	}
+	lo, hi := counts[0], counts[0]
+	for _, c := range counts {
+		lo, hi = min(lo, c), max(hi, c)
+	}
+
+	line := make([]rune, len(counts))
+	for i, c := range counts {
+		level := 0
+		if hi > lo {
+			level = int((c - lo) * int64(len(levels)-1) / (hi - lo))
+		}
+		line[i] = levels[level]
+	}
+	return string(line)
+}
+
+// FormatCount abbreviates a download count: 950, 12.3K, 4.5M, 1.2B.
+func FormatCount(n int64) string {
+	switch {
+	case n >= 1_000_000_000:
+		return strconv.FormatFloat(float64(n)/1e9, 'f', 1, 64) + "B"
+	case n >= 1_000_000:
+		return strconv.FormatFloat(float64(n)/1e6, 'f', 1, 64) + "M"
+	case n >= 1_000:
+		return strconv.FormatFloat(float64(n)/1e3, 'f', 1, 64) + "K"
+	default:
+		return strconv.FormatInt(n, 10)
+	}
+}
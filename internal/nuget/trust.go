@@ -0,0 +1,261 @@
+package nuget
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// TrustKind identifies the trust policy and the packages checked against it in versioned
+// JSON output.
+const TrustKind = "trust"
+
+// Kinds of trusted signer.
+const (
+	SignerAuthor     = "author"     // Trusts packages the holder of a certificate signed as their author
+	SignerRepository = "repository" // Trusts packages a repository signed, optionally only those of some owners
+)
+
+// Signature validation modes of a NuGet configuration (signatureValidationMode).
+const (
+	ValidationAccept  = "accept"  // Unsigned packages and packages of any signer are restored
+	ValidationRequire = "require" // Only packages a trusted signer signed are restored
+)
+
+// TrustedSigner is an author or repository in a NuGet configuration's trustedSigners.
+type TrustedSigner struct {
+	Kind         string               `json:"kind"` // SignerAuthor or SignerRepository
+	Name         string               `json:"name"`
+	ServiceIndex string               `json:"serviceIndex,omitempty"` // Repositories only
+	Certificates []TrustedCertificate `json:"certificates"`
+	Owners       []string             `json:"owners,omitempty"` // Repositories only; none trusts every owner
+	File         string               `json:"file"`             // The configuration file that lists it
+}
+
+// TrustedCertificate is the fingerprint of a certificate a trusted signer signs with.
+type TrustedCertificate struct {
+	Fingerprint        string `json:"fingerprint"`
+	HashAlgorithm      string `json:"hashAlgorithm"` // SHA256, SHA384, or SHA512
+	AllowUntrustedRoot bool   `json:"allowUntrustedRoot"`
+}
+
+// TrustPolicy is the signature policy restore applies: the validation mode and the
+// trusted signers, merged from the configuration files that apply.
+type TrustPolicy struct {
+	Mode    string          `json:"signatureValidationMode"`
+	Signers []TrustedSigner `json:"trustedSigners"`
+}
+
+// configFile is the part of a NuGet configuration file the trust policy is read from.
+type configFile struct {
+	Config         []configEntry `xml:"config>add"`
+	TrustedSigners struct {
+		Clear   *struct{}       `xml:"clear"`
+		Signers []signerElement `xml:",any"`
+	} `xml:"trustedSigners"`
+}
+
+// configEntry is an <add key="..." value="..." /> setting.
+type configEntry struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// signerElement is an <author> or <repository> of trustedSigners.
+type signerElement struct {
+	XMLName      xml.Name
+	Name         string `xml:"name,attr"`
+	ServiceIndex string `xml:"serviceIndex,attr"`
+	Certificates []struct {
+		Fingerprint        string `xml:"fingerprint,attr"`
+		HashAlgorithm      string `xml:"hashAlgorithm,attr"`
+		AllowUntrustedRoot string `xml:"allowUntrustedRoot,attr"`
+	} `xml:"certificate"`
+	Owners string `xml:"owners"`
+}
+
+// ReadTrustPolicy reads the trust policy of NuGet configuration files, nearest first (see
+// ConfigPaths). A signer listed in a nearer file hides one of the same name further away,
+// and <clear /> hides those of every file after it. The mode is accept unless a file sets
+// it.
+func ReadTrustPolicy(paths []string) (*TrustPolicy, error) {
+	policy := &TrustPolicy{Signers: []TrustedSigner{}}
+	seen := make(map[string]bool)
+	cleared := false
+	for _, path := range paths {
+		// #nosec G304 -- path is a NuGet configuration file that applies to the workspace
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		var cfg configFile
+		if err := xml.Unmarshal(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		for _, entry := range cfg.Config {
+			if strings.EqualFold(entry.Key, "signatureValidationMode") && policy.Mode == "" {
+				policy.Mode = strings.ToLower(entry.Value)
+			}
+		}
+		if cleared {
+			continue
+		}
+		for _, e := range cfg.TrustedSigners.Signers {
+			kind := strings.ToLower(e.XMLName.Local)
+			if (kind != SignerAuthor && kind != SignerRepository) || seen[strings.ToLower(e.Name)] {
+				continue
+			}
+			seen[strings.ToLower(e.Name)] = true
+			signer := TrustedSigner{Kind: kind, Name: e.Name, ServiceIndex: e.ServiceIndex, Certificates: []TrustedCertificate{}, File: path}
+			for _, c := range e.Certificates {
+				signer.Certificates = append(signer.Certificates, TrustedCertificate{
+					Fingerprint:        strings.ToUpper(c.Fingerprint),
+					HashAlgorithm:      hashAlgorithm(c.HashAlgorithm),
+					AllowUntrustedRoot: strings.EqualFold(c.AllowUntrustedRoot, "true"),
+				})
+			}
+			for _, owner := range strings.Split(e.Owners, ";") {
+				if owner = strings.TrimSpace(owner); owner != "" {
+					signer.Owners = append(signer.Owners, owner)
+				}
+			}
+			policy.Signers = append(policy.Signers, signer)
+		}
+		cleared = cfg.TrustedSigners.Clear != nil
+	}
+	if policy.Mode == "" {
+		policy.Mode = ValidationAccept
+	}
+	return policy, nil
+}
+
+// Check returns the trusted signer whose certificate (and owners) a package's signature
+// (nil when unsigned) matches, or "", and whether the policy accepts the package. Only the
+// certificates are compared: that the signature is valid and the chain trusted is left to
+// restore, so a match does not mean restore will trust the package.
+func (p *TrustPolicy) Check(sig *PackageSignature) (string, bool) {
+	for _, s := range p.Signers {
+		if s.satisfiedBy(sig) {
+			return s.Name, true
+		}
+	}
+	return "", p.Mode != ValidationRequire
+}
+
+// satisfiedBy reports whether a signature is one the signer is trusted for: the author
+// signature for authors, the repository signature, of one of the owners if any are
+// listed, for repositories.
+func (s TrustedSigner) satisfiedBy(sig *PackageSignature) bool {
+	if sig == nil {
+		return false
+	}
+	signer := sig.Author
+	if s.Kind == SignerRepository {
+		signer = sig.Repository
+	}
+	if signer == nil {
+		return false
+	}
+	matches := false
+	for _, c := range s.Certificates {
+		if strings.EqualFold(signer.Fingerprint(c.HashAlgorithm), c.Fingerprint) {
+			matches = true
+			break
+		}
+	}
+	if !matches || s.Kind == SignerAuthor || len(s.Owners) == 0 {
+		return matches
+	}
+	for _, owner := range s.Owners {
+		for _, o := range signer.Owners {
+			if strings.EqualFold(owner, o) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AddTrustedSigner adds a trusted signer to a NuGet configuration file, creating the file
+// when it does not exist. A signer with the same name is replaced. The rest of the file
+// keeps its formatting.
+func AddTrustedSigner(path string, s TrustedSigner) error {
+	if s.Kind != SignerAuthor && s.Kind != SignerRepository {
+		return fmt.Errorf("unknown kind of trusted signer %q", s.Kind)
+	}
+	text, err := readConfig(path)
+	if err != nil {
+		return err
+	}
+
+	open := fmt.Sprintf(`<%s name="%s">`, s.Kind, escapeXML(s.Name))
+	if s.ServiceIndex != "" {
+		open = fmt.Sprintf(`<%s name="%s" serviceIndex="%s">`, s.Kind, escapeXML(s.Name), escapeXML(s.ServiceIndex))
+	}
+	lines := []string{open}
+	for _, c := range s.Certificates {
+		lines = append(lines, fmt.Sprintf(`  <certificate fingerprint="%s" hashAlgorithm="%s" allowUntrustedRoot="%t" />`,
+			escapeXML(strings.ToUpper(c.Fingerprint)), hashAlgorithm(c.HashAlgorithm), c.AllowUntrustedRoot))
+	}
+	if len(s.Owners) > 0 {
+		lines = append(lines, "  <owners>"+escapeXML(strings.Join(s.Owners, ";"))+"</owners>")
+	}
+	lines = append(lines, "</"+s.Kind+">")
+	return writeConfig(path, editSection(text, "trustedSigners", signerPattern(s.Name), lines))
+}
+
+// RemoveTrustedSigner removes a trusted signer from a NuGet configuration file. It reports
+// whether the file listed it.
+func RemoveTrustedSigner(path, name string) (bool, error) {
+	// #nosec G304 -- path is the NuGet configuration file being edited
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	pattern := signerPattern(name)
+	if !pattern.Match(data) {
+		return false, nil
+	}
+	return true, writeConfig(path, editSection(string(data), "trustedSigners", pattern, nil))
+}
+
+// SetValidationMode sets signatureValidationMode in a NuGet configuration file, creating
+// the file when it does not exist.
+func SetValidationMode(path, mode string) error {
+	if mode != ValidationAccept && mode != ValidationRequire {
+		return fmt.Errorf("unknown signature validation mode %q (accept or require)", mode)
+	}
+	text, err := readConfig(path)
+	if err != nil {
+		return err
+	}
+	text = editSection(text, "config", regexp.MustCompile(`(?i)[ \t]*<add\s+key="signatureValidationMode"[^>]*/>[ \t]*\r?\n?`),
+		[]string{fmt.Sprintf(`<add key="signatureValidationMode" value="%s" />`, mode)})
+	return writeConfig(path, text)
+}
+
+// signerPattern matches the <author> or <repository> element of a trusted signer, with
+// the line it is on.
+func signerPattern(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(?is)[ \t]*<(?:author|repository)\s[^>]*?name="` + regexp.QuoteMeta(escapeXML(name)) +
+		`"[^>]*?(?:/>|>.*?</(?:author|repository)>)[ \t]*\r?\n?`)
+}
+
+// hashAlgorithm returns the name NuGet writes for a fingerprint's hash algorithm; SHA256
+// when none is given.
+func hashAlgorithm(name string) string {
+	if name == "" {
+		return "SHA256"
+	}
+	return strings.ToUpper(strings.ReplaceAll(name, "-", ""))
+}
@@ -0,0 +1,82 @@
+package nuget
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares NuGet versions, returning -1, 0, or 1. Numeric parts are
+// compared numerically (missing parts are zero), a release sorts after its prereleases,
+// and build metadata is ignored. An empty version sorts first.
+func CompareVersions(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return -1
+	case b == "":
+		return 1
+	}
+
+	aCore, aPre := splitVersion(a)
+	bCore, bPre := splitVersion(b)
+
+	aParts, bParts := strings.Split(aCore, "."), strings.Split(bCore, ".")
+	for i := 0; i < max(len(aParts), len(bParts)); i++ {
+		if c := compareNumeric(part(aParts, i), part(bParts, i)); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return comparePrerelease(aPre, bPre)
+}
+
+// splitVersion separates the numeric core from the prerelease label, dropping build metadata.
+func splitVersion(v string) (string, string) {
+	v, _, _ = strings.Cut(strings.TrimPrefix(strings.TrimSpace(v), "v"), "+")
+	core, pre, _ := strings.Cut(v, "-")
+	return core, pre
+}
+
+// part returns the i-th dot-separated part, or "0" when missing.
+func part(parts []string, i int) string {
+	if i < len(parts) {
+		return parts[i]
+	}
+	return "0"
+}
+
+// compareNumeric compares two version parts numerically, falling back to text.
+func compareNumeric(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	if aErr != nil || bErr != nil {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	}
+	switch {
+	case an < bn:
+		return -1
+	case an > bn:
+		return 1
+	}
+	return 0
+}
+
+// comparePrerelease compares dot-separated prerelease labels (e.g., "beta.2" < "beta.10").
+func comparePrerelease(a, b string) int {
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < min(len(aParts), len(bParts)); i++ {
+		if c := compareNumeric(aParts[i], bParts[i]); c != 0 {
+			return c
+		}
+	}
+	return compareNumeric(strconv.Itoa(len(aParts)), strconv.Itoa(len(bParts)))
+}
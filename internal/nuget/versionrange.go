@@ -0,0 +1,215 @@
+package nuget
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// VersionRange is a NuGet version range as written in a PackageReference: a minimum
+// version ("1.0", meaning >= 1.0), interval notation ("[1.0,2.0)", "(,2.0]", "[1.0]"),
+// or a floating version ("6.0.*", "*", "1.0.0-beta.*", "6.*-*") that restore resolves to
+// the highest matching version available.
+type VersionRange struct {
+	Original     string
+	Min          string // Empty when there is no lower bound
+	Max          string // Empty when there is no upper bound
+	MinInclusive bool
+	MaxInclusive bool
+
+	// Floating ranges: versions whose numeric parts start with floatCore (or equal it,
+	// unless floatNumeric) and, when floatPrerelease is set, whose prerelease label
+	// starts with floatPre
+	floatCore       []string
+	floatPre        string
+	floatNumeric    bool
+	floatPrerelease bool
+}
+
+// ParseVersionRange parses a version range or floating version.
+func ParseVersionRange(s string) (VersionRange, error) {
+	r := VersionRange{Original: s}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return r, fmt.Errorf("empty version range")
+	}
+
+	if s[0] != '[' && s[0] != '(' {
+		// A bare version is a minimum version
+		if err := r.setMin(s); err != nil {
+			return r, err
+		}
+		r.MinInclusive = true
+		return r, nil
+	}
+
+	last := s[len(s)-1]
+	if last != ']' && last != ')' {
+		return r, fmt.Errorf("invalid version range %q: missing closing bracket", s)
+	}
+	r.MinInclusive, r.MaxInclusive = s[0] == '[', last == ']'
+	body := s[1 : len(s)-1]
+
+	lower, upper, interval := strings.Cut(body, ",")
+	lower, upper = strings.TrimSpace(lower), strings.TrimSpace(upper)
+	if !interval {
+		// [1.0] is an exact version
+		if !r.MinInclusive || !r.MaxInclusive || lower == "" || strings.Contains(lower, "*") {
+			return r, fmt.Errorf("invalid version range %q", s)
+		}
+		r.Min, r.Max = lower, lower
+		return r, nil
+	}
+	if strings.Contains(upper, ",") || strings.Contains(upper, "*") {
+		return r, fmt.Errorf("invalid version range %q", s)
+	}
+	if lower != "" {
+		if err := r.setMin(lower); err != nil {
+			return r, err
+		}
+	}
+	r.Max = upper
+	if r.Min != "" && r.Max != "" && CompareVersions(r.Min, r.Max) > 0 {
+		return r, fmt.Errorf("invalid version range %q: minimum is above maximum", s)
+	}
+	return r, nil
+}
+
+// setMin sets the lower bound, which may be a floating version.
+func (r *VersionRange) setMin(v string) error {
+	if !strings.Contains(v, "*") {
+		r.Min = v
+		return nil
+	}
+
+	core, pre, hasPre := strings.Cut(v, "-")
+	if hasPre {
+		// 1.0.0-beta.* or 6.*-*: float on the prerelease label
+		r.floatPre = strings.TrimSuffix(pre, "*")
+		if !strings.HasSuffix(pre, "*") || strings.Contains(r.floatPre, "*") {
+			return fmt.Errorf("invalid floating version %q", v)
+		}
+		r.floatPrerelease = true
+	}
+
+	parts := strings.Split(core, ".")
+	for i, p := range parts {
+		switch {
+		case p == "*" && i == len(parts)-1:
+			r.floatNumeric = true
+		case p == "*" || p == "":
+			return fmt.Errorf("invalid floating version %q", v)
+		default:
+			r.floatCore = append(r.floatCore, p)
+		}
+	}
+
+	min := slices.Clone(r.floatCore)
+	for len(min) < 3 {
+		min = append(min, "0")
+	}
+	r.Min = strings.Join(min, ".")
+	if r.floatPrerelease {
+		// The lowest prerelease that matches
+		r.Min += "-" + r.floatPre
+		if r.floatPre == "" {
+			r.Min += "0"
+		}
+	}
+	return nil
+}
+
+// IsFloating reports whether the range floats (contains '*').
+func (r VersionRange) IsFloating() bool {
+	return r.floatNumeric || r.floatPrerelease
+}
+
+// Contains reports whether v satisfies the range's bounds and, for floating ranges,
+// matches the floating pattern. Prerelease versions only match floating ranges that
+// float on the prerelease label, or bounds that are themselves prereleases.
+func (r VersionRange) Contains(v string) bool {
+	if !r.WithinBounds(v) {
+		return false
+	}
+	if !r.IsFloating() {
+		return !IsPrerelease(v) || IsPrerelease(r.Min) || IsPrerelease(r.Max)
+	}
+
+	core, pre := splitVersion(v)
+	parts := strings.Split(core, ".")
+	n := len(r.floatCore)
+	if !r.floatNumeric {
+		// 1.0.0-beta.* only matches 1.0.0 and its prereleases
+		n = max(len(parts), len(r.floatCore))
+	}
+	for i := 0; i < n; i++ {
+		if compareNumeric(part(parts, i), part(r.floatCore, i)) != 0 {
+			return false
+		}
+	}
+	if pre == "" {
+		return true
+	}
+	return r.floatPrerelease && strings.HasPrefix(strings.ToLower(pre), strings.ToLower(r.floatPre))
+}
+
+// WithinBounds reports whether v is between the range's bounds, regardless of prerelease
+// labels and floating patterns; advisories, for example, affect every version in their range.
+func (r VersionRange) WithinBounds(v string) bool {
+	if r.Min != "" {
+		c := CompareVersions(v, r.Min)
+		if c < 0 || (c == 0 && !r.MinInclusive) {
+			return false
+		}
+	}
+	if r.Max != "" {
+		c := CompareVersions(v, r.Max)
+		if c > 0 || (c == 0 && !r.MaxInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// Resolve returns the version restore would pick from the available versions: the
+// highest match for floating ranges, and the lowest satisfying version otherwise.
+func (r VersionRange) Resolve(available []string) (string, bool) {
+	floating := r.IsFloating()
+	var best string
+	for _, v := range available {
+		if !r.Contains(v) {
+			continue
+		}
+		switch {
+		case best == "":
+			best = v
+		case floating && CompareVersions(v, best) > 0:
+			best = v
+		case !floating && CompareVersions(v, best) < 0:
+			best = v
+		}
+	}
+	return best, best != ""
+}
+
+// String returns the range as written.
+func (r VersionRange) String() string {
+	return r.Original
+}
+
+// IsPrerelease reports whether v has a prerelease label.
+func IsPrerelease(v string) bool {
+	_, pre := splitVersion(v)
+	return pre != ""
+}
+
+// Latest returns the highest version, ignoring prereleases unless prerelease is set.
+func Latest(versions []string, prerelease bool) string {
+	latest := ""
+	for _, v := range versions {
+		if (prerelease || !IsPrerelease(v)) && CompareVersions(v, latest) > 0 {
+			latest = v
+		}
+	}
+	return latest
+}
@@ -0,0 +1,132 @@
+package nuget
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// DefaultVulnerabilityURL is the vulnerability index (VulnerabilityInfo resource) of nuget.org.
+const DefaultVulnerabilityURL = "https://api.nuget.org/v3/vulnerabilities/index.json"
+
+// maxVulnerabilityPageSize bounds a page of the vulnerability index; the base page of
+// nuget.org lists every known advisory.
+const maxVulnerabilityPageSize = 64 << 20
+
+// advisorySeverities names the severities of the vulnerability index, by number.
+var advisorySeverities = []string{"Low", "Moderate", "High", "Critical"}
+
+// Advisory is a security advisory for a range of versions of a package. The details
+// after Versions come from OSV.dev, when the feed is set to query it.
+type Advisory struct {
+	URL        string   `json:"url"`
+	Severity   string   `json:"severity"`          // Low, Moderate, High, or Critical
+	Versions   string   `json:"versions"`          // Affected version range
+	ID         string   `json:"id,omitempty"`      // e.g., GHSA-5crp-9r3c-p9vr
+	Aliases    []string `json:"aliases,omitempty"` // Other IDs of the same vulnerability (e.g., a CVE)
+	Summary    string   `json:"summary,omitempty"`
+	Score      float64  `json:"score,omitempty"`  // CVSS base score; 0 when unknown
+	Vector     string   `json:"vector,omitempty"` // CVSS vector
+	References []string `json:"references,omitempty"`
+}
+
+// Identifier returns the advisory's ID, or the last segment of its URL, which is the
+// GHSA ID for the GitHub advisories nuget.org lists.
+func (a Advisory) Identifier() string {
+	if a.ID != "" {
+		return a.ID
+	}
+	return a.URL[strings.LastIndexByte(a.URL, '/')+1:]
+}
+
+// Affects reports whether the advisory applies to a version.
+func (a Advisory) Affects(version string) bool {
+	r, err := ParseVersionRange(a.Versions)
+	return err == nil && r.WithinBounds(version)
+}
+
+// Rating returns the severity with the CVSS score when known (e.g., "High (CVSS 7.5)").
+func (a Advisory) Rating() string {
+	if a.Score == 0 {
+		return a.Severity
+	}
+	return fmt.Sprintf("%s (CVSS %.1f)", a.Severity, a.Score)
+}
+
+// Advisories returns the security advisories of a package from the feed's vulnerability
+// index, merged with OSV.dev's when the feed has an OSV client. A package without
+// advisories has none; so does a feed without an index or OSV client. OSV.dev supplements
+// the index: when it cannot be reached, the index's advisories are returned alone.
+func (f *Feed) Advisories(ctx context.Context, id string) ([]Advisory, error) {
+	advisories, err := f.indexAdvisories(ctx, id)
+	if err != nil || f.OSV == nil {
+		return advisories, err
+	}
+	vulns, err := f.OSV.Query(ctx, id)
+	if err != nil {
+		return advisories, nil
+	}
+	return MergeAdvisories(id, advisories, vulns), nil
+}
+
+// indexAdvisories returns the security advisories of a package from the feed's
+// vulnerability index.
+func (f *Feed) indexAdvisories(ctx context.Context, id string) ([]Advisory, error) {
+	if f.VulnerabilityURL == "" {
+		return nil, nil
+	}
+	var index []struct {
+		URL string `json:"@id"`
+	}
+	if err := f.getJSON(ctx, f.VulnerabilityURL, &index); err != nil {
+		return nil, err
+	}
+
+	// The index has a base page and an update page with recent changes
+	var advisories []Advisory
+	for _, page := range index {
+		var entries map[string][]struct {
+			URL      string `json:"url"`
+			Severity int    `json:"severity"`
+			Versions string `json:"versions"`
+		}
+		if err := f.getJSON(ctx, page.URL, &entries); err != nil {
+			return nil, err
+		}
+		for _, e := range entries[strings.ToLower(id)] {
+			severity := "Unknown"
+			if e.Severity >= 0 && e.Severity < len(advisorySeverities) {
+				severity = advisorySeverities[e.Severity]
+			}
+			a := Advisory{URL: e.URL, Severity: severity, Versions: e.Versions}
+			if !slices.ContainsFunc(advisories, func(b Advisory) bool { return b.URL == a.URL }) {
+				advisories = append(advisories, a)
+			}
+		}
+	}
+	return advisories, nil
+}
+
+// getJSON decodes a JSON document from a feed resource.
+func (f *Feed) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := f.client().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to read vulnerability data: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to read vulnerability data: %s returned %s", url, resp.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxVulnerabilityPageSize)).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse vulnerability data from %s: %w", url, err)
+	}
+	return nil
+}
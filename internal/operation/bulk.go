@@ -0,0 +1,203 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/hooks"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/project"
+)
+
+// Kind is the JSON document kind of bulk add, remove, and update results.
+const Kind = "operations"
+
+// Action is what a bulk operation does with each package spec.
+type Action string
+
+// Bulk actions.
+const (
+	ActionAdd    Action = "add"    // Reference the package, at the latest version unless one is given
+	ActionRemove Action = "remove" // Remove the reference, if there is one
+	ActionUpdate Action = "update" // Move an existing reference to the version given, or the latest
+)
+
+// Status is the outcome of one package spec.
+type Status string
+
+// Bulk statuses.
+const (
+	StatusChanged   Status = "changed"   // The project was changed
+	StatusUnchanged Status = "unchanged" // The project already was as asked
+	StatusSkipped   Status = "skipped"   // The spec does not apply: no such reference, or no version
+	StatusFailed    Status = "failed"    // The change could not be made
+)
+
+// Result is the outcome of one package spec of a bulk operation.
+type Result struct {
+	Package         string   `json:"package"`
+	Version         string   `json:"version,omitempty"`         // The version referenced afterwards
+	PreviousVersion string   `json:"previousVersion,omitempty"` // Empty for new references
+	Status          Status   `json:"status"`
+	Error           string   `json:"error,omitempty"`
+	Changed         []string `json:"changed,omitempty"` // Files written
+}
+
+// Bulk applies one action to a list of package specs in a project, in order, carrying on
+// past specs that fail.
+type Bulk struct {
+	Backend Backend
+
+	// Versions lists the versions of a package, for specs without one.
+	Versions func(ctx context.Context, id string) ([]string, error)
+
+	// Hooks runs the preInstall hooks before a new reference, which cancel it when they
+	// fail, and the postUpdate hooks after a version change. Nil runs none.
+	Hooks *hooks.Runner
+
+	Prerelease bool   // Consider prereleases for the latest version
+	Reason     string // Comment written above new references
+
+	// Framework scopes the references to one target framework of a multi-targeting
+	// project (see Backend.SetFor); "" changes the first reference to each package,
+	// whatever its condition.
+	Framework string
+
+	// Notify, when set, is called after each spec.
+	Notify func(Result)
+}
+
+// Run applies action to each spec in the project at path and returns a result per spec.
+func (b *Bulk) Run(ctx context.Context, action Action, path string, specs []Spec) []Result {
+	results := make([]Result, 0, len(specs))
+	for _, spec := range specs {
+		var r Result
+		if err := ctx.Err(); err != nil {
+			r = Result{Package: spec.ID, Status: StatusFailed, Error: err.Error()}
+		} else {
+			r = b.apply(ctx, action, path, spec)
+		}
+		results = append(results, r)
+		if b.Notify != nil {
+			b.Notify(r)
+		}
+	}
+	return results
+}
+
+// skipped is an error meaning the spec does not apply, rather than that the change failed.
+type skipped string
+
+func (s skipped) Error() string {
+	return string(s)
+}
+
+// apply applies action to one spec.
+func (b *Bulk) apply(ctx context.Context, action Action, path string, spec Spec) Result {
+	r := Result{Package: spec.ID}
+	previous, referenced, err := ReferencedVersion(path, b.Framework, spec.ID)
+	if err == nil {
+		r.PreviousVersion = previous
+		err = b.change(ctx, action, path, spec, referenced, &r)
+	}
+	var skip skipped
+	switch {
+	case errors.As(err, &skip):
+		r.Status, r.Error = StatusSkipped, skip.Error()
+	case err != nil:
+		r.Status, r.Error = StatusFailed, err.Error()
+	case len(r.Changed) > 0:
+		r.Status = StatusChanged
+	default:
+		r.Status = StatusUnchanged
+	}
+	return r
+}
+
+// change makes the change for one spec, recording the version and the files written in r.
+func (b *Bulk) change(ctx context.Context, action Action, path string, spec Spec, referenced bool, r *Result) error {
+	if action == ActionRemove {
+		if !referenced {
+			return nil
+		}
+		removed, err := b.Backend.RemoveFor(ctx, path, b.Framework, spec.ID)
+		if removed {
+			r.Changed = []string{path}
+		}
+		return err
+	}
+
+	if action == ActionUpdate && !referenced {
+		if b.Framework != "" {
+			return skipped(spec.ID + " is not referenced for " + b.Framework + "; add it instead")
+		}
+		return skipped(spec.ID + " is not referenced; add it instead")
+	}
+	r.Version = spec.Version
+	if r.Version == "" {
+		if b.Versions == nil {
+			return skipped("no version given for " + spec.ID)
+		}
+		versions, err := b.Versions(ctx, spec.ID)
+		if err != nil {
+			return err
+		}
+		if r.Version = nuget.Latest(versions, b.Prerelease); r.Version == "" {
+			return skipped("no versions of " + spec.ID + " found")
+		}
+	}
+	if referenced && strings.EqualFold(r.Version, r.PreviousVersion) {
+		return nil
+	}
+
+	op := hooks.Operation{Project: path, Package: spec.ID, Version: r.Version, PreviousVersion: r.PreviousVersion}
+	if !referenced && b.Hooks != nil {
+		op.Event = hooks.EventPreInstall
+		if _, err := b.Hooks.Run(ctx, op); err != nil {
+			return err
+		}
+	}
+	changed, err := b.Backend.SetFor(ctx, path, b.Framework, spec.ID, r.Version, b.Reason)
+	if err != nil {
+		return err
+	}
+	r.Changed = changed
+	if referenced && len(changed) > 0 && b.Hooks != nil {
+		// The project was updated; the runner reports a failing hook
+		op.Event = hooks.EventPostUpdate
+		_, _ = b.Hooks.Run(ctx, op)
+	}
+	return nil
+}
+
+// ReferencedVersion returns the version a project references a package at, including a
+// version set centrally, and whether it references the package at all. With a framework,
+// only a reference under a condition applying to it counts, and a reference every
+// framework has is an error, since it cannot be changed for one framework alone.
+func ReferencedVersion(path, framework, id string) (string, bool, error) {
+	p, err := project.Load(path)
+	if err != nil {
+		return "", false, err
+	}
+	ref, ok := p.Reference(id)
+	if framework != "" {
+		if !p.HasFramework(framework) {
+			return "", false, fmt.Errorf("%s does not target %s", path, framework)
+		}
+		for _, r := range p.PackageReferences {
+			if strings.EqualFold(r.ID, id) && r.Condition == "" {
+				return "", false, skipped(id + " is referenced for every target framework; change it without a framework")
+			}
+		}
+		ref, ok = p.ReferenceFor(id, framework)
+	}
+	if !ok {
+		return "", false, nil
+	}
+	if ref.Version == "" {
+		return project.CentralVersion(path, ref.ID), true, nil
+	}
+	return ref.Version, true, nil
+}
@@ -0,0 +1,188 @@
+// Package operation adds, updates, and removes package references, either through the
+// dotnet CLI (dotnet add/remove package) or by editing the PackageReference items in the
+// project files directly, which needs no dotnet at all until a restore. Sessions restore
+// the changed projects after every change, once at the end, or only when asked.
+package operation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/project"
+)
+
+// Backend names, the values of the operationBackend setting.
+const (
+	BackendCLI    = "cli"
+	BackendDirect = "direct"
+)
+
+// Backend changes the package references of projects.
+type Backend interface {
+	// Set makes the project reference the package at version, adding the reference when
+	// it has none. A new reference is preceded by a comment giving reason, when there is
+	// one. It returns the files changed.
+	Set(ctx context.Context, path, id, version, reason string) ([]string, error)
+
+	// Remove removes the project's reference to a package and reports whether there was one.
+	Remove(ctx context.Context, path, id string) (bool, error)
+
+	// SetFor is Set for one target framework of a multi-targeting project: it changes the
+	// reference under a condition that applies to the framework, or adds one under the
+	// framework's own condition. With framework "" it is Set.
+	SetFor(ctx context.Context, path, framework, id, version, reason string) ([]string, error)
+
+	// RemoveFor removes the reference under a condition that applies to a target
+	// framework and reports whether there was one. With framework "" it is Remove.
+	RemoveFor(ctx context.Context, path, framework, id string) (bool, error)
+}
+
+// Direct edits project files, keeping everything but the changed items byte for byte.
+// It never restores; a Session restores the projects it changed.
+type Direct struct{}
+
+// Set implements Backend.
+func (Direct) Set(_ context.Context, path, id, version, reason string) ([]string, error) {
+	return project.PinPackage(path, id, version, reason)
+}
+
+// Remove implements Backend.
+func (Direct) Remove(_ context.Context, path, id string) (bool, error) {
+	return project.RemovePackage(path, id)
+}
+
+// SetFor implements Backend.
+func (Direct) SetFor(_ context.Context, path, framework, id, version, reason string) ([]string, error) {
+	return project.PinPackageFor(path, framework, id, version, reason)
+}
+
+// RemoveFor implements Backend.
+func (Direct) RemoveFor(_ context.Context, path, framework, id string) (bool, error) {
+	return project.RemovePackageFor(path, framework, id)
+}
+
+// CLI changes references with dotnet add package and dotnet remove package, which handle
+// central package management themselves.
+type CLI struct {
+	Spawner   platform.ProcessSpawner
+	NoRestore bool // Pass --no-restore to dotnet add package
+}
+
+// Set implements Backend.
+func (c CLI) Set(ctx context.Context, path, id, version, reason string) ([]string, error) {
+	return c.SetFor(ctx, path, "", id, version, reason)
+}
+
+// SetFor implements Backend with dotnet add package --framework.
+func (c CLI) SetFor(ctx context.Context, path, framework, id, version, reason string) ([]string, error) {
+	referenced, err := references(path, framework, id)
+	if err != nil {
+		return nil, err
+	}
+	files := watch(path)
+
+	args := []string{"add", path, "package", id, "--version", version}
+	if framework != "" {
+		args = append(args, "--framework", framework)
+	}
+	if c.NoRestore {
+		args = append(args, "--no-restore")
+	}
+	if err := dotnet(ctx, c.Spawner, "dotnet add package", args...); err != nil {
+		return nil, err
+	}
+	if !referenced && reason != "" {
+		err := project.EditFile(path, func(e *project.Editor) error {
+			e.ForFramework(framework)
+			e.CommentItem("PackageReference", id, reason)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return changedFiles(files), nil
+}
+
+// Remove implements Backend.
+func (c CLI) Remove(ctx context.Context, path, id string) (bool, error) {
+	referenced, err := references(path, "", id)
+	if err != nil || !referenced {
+		return false, err
+	}
+	return true, dotnet(ctx, c.Spawner, "dotnet remove package", "remove", path, "package", id)
+}
+
+// RemoveFor implements Backend. dotnet remove package removes a reference for every
+// framework, so a reference scoped to one is removed by editing the project file.
+func (c CLI) RemoveFor(ctx context.Context, path, framework, id string) (bool, error) {
+	if framework == "" {
+		return c.Remove(ctx, path, id)
+	}
+	return project.RemovePackageFor(path, framework, id)
+}
+
+// dotnet runs a dotnet command and turns a failure into an error with its output, naming
+// the command as command.
+func dotnet(ctx context.Context, spawner platform.ProcessSpawner, command string, args ...string) error {
+	result, err := spawner.RunContext(ctx, "dotnet", args, "", nil)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("%s failed: %s", command, strings.TrimSpace(result.Stdout+"\n"+result.Stderr))
+	}
+	return nil
+}
+
+// references reports whether a project references a package, under a condition that
+// applies to framework unless it is "".
+func references(path, framework, id string) (bool, error) {
+	// #nosec G304 -- path is a project file in the user's workspace
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	e := project.NewEditor(string(data))
+	e.ForFramework(framework)
+	return e.HasItem("PackageReference", id), nil
+}
+
+// watchedFile is a file dotnet add package may change, with its contents beforehand.
+type watchedFile struct {
+	path string
+	data []byte
+}
+
+// watch records the contents of a project and of the Directory.Packages.props that
+// applies to it.
+func watch(path string) []watchedFile {
+	var files []watchedFile
+	for _, name := range []string{path, project.FindPackagesProps(filepath.Dir(path))} {
+		if name == "" {
+			continue
+		}
+		// #nosec G304 -- the project and its props file are in the user's workspace
+		data, _ := os.ReadFile(name)
+		files = append(files, watchedFile{name, data})
+	}
+	return files
+}
+
+// changedFiles returns the watched files whose contents differ now.
+func changedFiles(files []watchedFile) []string {
+	var changed []string
+	for _, f := range files {
+		// #nosec G304 -- see watch
+		data, _ := os.ReadFile(f.path)
+		if !bytes.Equal(f.data, data) {
+			changed = append(changed, f.path)
+		}
+	}
+	return changed
+}
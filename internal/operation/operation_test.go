@@ -0,0 +1,338 @@
+package operation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/instance"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/project"
+)
+
+// fakeDotnet records dotnet commands; add and remove edit the project like dotnet does.
+type fakeDotnet struct {
+	platform.ProcessSpawner
+	calls    []string
+	exitCode int
+}
+
+func (f *fakeDotnet) RunContext(_ context.Context, _ string, args []string, _ string, _ map[string]string) (platform.ProcessResult, error) {
+	f.calls = append(f.calls, strings.Join(args, " "))
+	if f.exitCode != 0 {
+		return platform.ProcessResult{Stderr: "error: NU1101: Unable to find package", ExitCode: f.exitCode}, nil
+	}
+	switch args[0] {
+	case "add":
+		if _, err := project.SetPackageVersion(args[1], args[3], args[5]); err != nil {
+			return platform.ProcessResult{}, err
+		}
+	case "remove":
+		if _, err := project.RemovePackage(args[1], args[3]); err != nil {
+			return platform.ProcessResult{}, err
+		}
+	}
+	return platform.ProcessResult{}, nil
+}
+
+// writeProject writes a project referencing Serilog and returns its path.
+func writeProject(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "App.csproj")
+	text := "<Project Sdk=\"Microsoft.NET.Sdk\">\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\n  </ItemGroup>\n</Project>\n"
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestCLI tests changing references with dotnet add and remove package
+func TestCLI(t *testing.T) {
+	ctx := context.Background()
+	path := writeProject(t)
+	spawner := &fakeDotnet{}
+	backend := CLI{Spawner: spawner, NoRestore: true}
+
+	changed, err := backend.Set(ctx, path, "Polly", "8.4.0", "Pinned by lazynuget audit")
+	if err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if !slices.Equal(changed, []string{path}) {
+		t.Errorf("Set() = %v, want %v", changed, []string{path})
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "<!-- Pinned by lazynuget audit -->") || !strings.Contains(string(data), `Include="Polly" Version="8.4.0"`) {
+		t.Errorf("project after Set() =\n%s", data)
+	}
+
+	if removed, err := backend.Remove(ctx, path, "Polly"); err != nil || !removed {
+		t.Errorf("Remove() = %v, %v, want true", removed, err)
+	}
+	// Nothing to remove: dotnet is not run
+	if removed, err := backend.Remove(ctx, path, "Polly"); err != nil || removed {
+		t.Errorf("Remove(again) = %v, %v, want false", removed, err)
+	}
+	want := []string{
+		"add " + path + " package Polly --version 8.4.0 --no-restore",
+		"remove " + path + " package Polly",
+	}
+	if !slices.Equal(spawner.calls, want) {
+		t.Errorf("dotnet calls = %q, want %q", spawner.calls, want)
+	}
+
+	spawner.exitCode = 1
+	if _, err := backend.Set(ctx, path, "Missing", "1.0.0", ""); err == nil || !strings.Contains(err.Error(), "dotnet add package failed: error: NU1101") {
+		t.Errorf("Set(missing) error = %v, want dotnet's message", err)
+	}
+}
+
+// TestDirect tests editing project files
+func TestDirect(t *testing.T) {
+	ctx := context.Background()
+	path := writeProject(t)
+
+	if changed, err := (Direct{}).Set(ctx, path, "Serilog", "4.0.0", ""); err != nil || !slices.Equal(changed, []string{path}) {
+		t.Fatalf("Set() = %v, %v", changed, err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `Include="Serilog" Version="4.0.0"`) {
+		t.Errorf("project after Set() =\n%s", data)
+	}
+	if removed, err := (Direct{}).Remove(ctx, path, "Serilog"); err != nil || !removed {
+		t.Fatalf("Remove() = %v, %v, want true", removed, err)
+	}
+	if removed, err := (Direct{}).Remove(ctx, path, "Serilog"); err != nil || removed {
+		t.Errorf("Remove(again) = %v, %v, want false", removed, err)
+	}
+}
+
+// TestSession tests restoring changed projects in each restore mode
+func TestSession(t *testing.T) {
+	ctx := context.Background()
+
+	// Always: every change is restored
+	path := writeProject(t)
+	spawner := &fakeDotnet{}
+	s := &Session{backend: Direct{}, spawner: spawner, mode: RestoreAlways}
+	if _, err := s.Set(ctx, path, "Polly", "8.4.0", ""); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if _, err := s.Remove(ctx, path, "Polly"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if want := []string{"restore " + path, "restore " + path}; !slices.Equal(spawner.calls, want) || len(s.Pending()) != 0 {
+		t.Errorf("always: dotnet calls = %q, pending = %v, want %q and none", spawner.calls, s.Pending(), want)
+	}
+
+	// Batch: changed projects are restored once, when the session closes
+	other := writeProject(t)
+	spawner = &fakeDotnet{}
+	s = &Session{backend: Direct{}, spawner: spawner, mode: RestoreBatch}
+	for _, p := range []string{path, other, path} {
+		if _, err := s.Set(ctx, p, "Serilog", "4.0.0", ""); err != nil {
+			t.Fatalf("Set(%s) error = %v", p, err)
+		}
+	}
+	if want := []string{path, other}; !slices.Equal(s.Pending(), want) || len(spawner.calls) != 0 {
+		t.Fatalf("batch: pending = %v, dotnet calls = %q, want %v and none", s.Pending(), spawner.calls, want)
+	}
+	if err := s.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if want := []string{"restore " + path, "restore " + other}; !slices.Equal(spawner.calls, want) || len(s.Pending()) != 0 {
+		t.Errorf("batch: dotnet calls = %q, pending = %v, want %q and none", spawner.calls, s.Pending(), want)
+	}
+
+	// Manual: projects that fail to restore stay pending; closing does not restore
+	spawner = &fakeDotnet{}
+	s = &Session{backend: Direct{}, spawner: spawner, mode: RestoreManual}
+	if _, err := s.Remove(ctx, path, "Serilog"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if err := s.Close(ctx); err != nil || len(spawner.calls) != 0 {
+		t.Errorf("manual: Close() = %v with dotnet calls %q, want nothing run", err, spawner.calls)
+	}
+	spawner.exitCode = 1
+	if err := s.Restore(ctx); err == nil || !slices.Equal(s.Pending(), []string{path}) {
+		t.Errorf("manual: Restore() = %v, pending = %v, want an error and %s pending", err, s.Pending(), path)
+	}
+	spawner.exitCode = 0
+	if err := s.Restore(ctx); err != nil || len(s.Pending()) != 0 {
+		t.Errorf("manual: Restore() = %v, pending = %v, want none", err, s.Pending())
+	}
+
+	// Without dotnet, changes stay pending
+	s = &Session{backend: Direct{}, mode: RestoreAlways}
+	if _, err := s.Set(ctx, path, "Polly", "8.4.0", ""); err != nil || !slices.Equal(s.Pending(), []string{path}) {
+		t.Errorf("no dotnet: Set() = %v, pending = %v, want %s pending", err, s.Pending(), path)
+	}
+	if err := s.Restore(ctx); err == nil {
+		t.Error("no dotnet: Restore() error = nil")
+	}
+}
+
+// TestSessionWorkspaceLock tests that changes are refused while another session holds
+// the workspace lock
+func TestSessionWorkspaceLock(t *testing.T) {
+	ctx := context.Background()
+	path := writeProject(t)
+	lockDir := t.TempDir()
+	root, err := instance.WorkspaceRoot(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostname, _ := os.Hostname()
+	now := time.Now()
+	data, _ := json.Marshal(instance.Info{PID: 1, Hostname: hostname + "-other", Workspace: root, StartedAt: now, UpdatedAt: now})
+	if err := os.MkdirAll(lockDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(instance.LockPath(lockDir, root), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Session{backend: Direct{}, mode: RestoreManual, lockDir: lockDir}
+	var held *instance.HeldError
+	if _, err := s.Set(ctx, path, "Polly", "8.4.0", ""); !errors.As(err, &held) {
+		t.Errorf("Set() under another session's lock error = %v, want *instance.HeldError", err)
+	}
+	if len(s.Pending()) != 0 {
+		t.Errorf("Pending() = %v after a refused change, want none", s.Pending())
+	}
+
+	if err := os.Remove(instance.LockPath(lockDir, root)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Set(ctx, path, "Polly", "8.4.0", ""); err != nil {
+		t.Errorf("Set() after the lock was released error = %v", err)
+	}
+}
+
+// TestReadSpecs tests reading package specs one per line
+func TestReadSpecs(t *testing.T) {
+	specs, err := ReadSpecs(strings.NewReader("# Logging\nSerilog\n\nPolly@8.4.0\n  Newtonsoft.Json 13.0.3  \n"))
+	if err != nil {
+		t.Fatalf("ReadSpecs() error = %v", err)
+	}
+	want := []Spec{{ID: "Serilog"}, {ID: "Polly", Version: "8.4.0"}, {ID: "Newtonsoft.Json", Version: "13.0.3"}}
+	if !slices.Equal(specs, want) {
+		t.Errorf("ReadSpecs() = %v, want %v", specs, want)
+	}
+
+	for _, text := range []string{"Serilog\nBad/Id\n", "Serilog 1.0 extra\n", "Polly@not-a-version\n"} {
+		if _, err := ReadSpecs(strings.NewReader(text)); err == nil || !strings.Contains(err.Error(), "line ") {
+			t.Errorf("ReadSpecs(%q) error = %v, want one naming the line", text, err)
+		}
+	}
+}
+
+// TestBulk tests applying an action to several packages, carrying on past failures
+func TestBulk(t *testing.T) {
+	ctx := context.Background()
+	path := writeProject(t)
+	bulk := &Bulk{
+		Backend: Direct{},
+		Versions: func(_ context.Context, id string) ([]string, error) {
+			if id == "Missing" {
+				return nil, nil
+			}
+			return []string{"1.0.0", "2.0.0", "3.0.0-beta"}, nil
+		},
+	}
+	var notified []string
+	bulk.Notify = func(r Result) { notified = append(notified, r.Package) }
+
+	status := func(results []Result) []Status {
+		var statuses []Status
+		for _, r := range results {
+			statuses = append(statuses, r.Status)
+		}
+		return statuses
+	}
+
+	results := bulk.Run(ctx, ActionAdd, path, []Spec{{ID: "Polly"}, {ID: "Serilog", Version: "3.0.0"}, {ID: "Missing"}})
+	if want := []Status{StatusChanged, StatusUnchanged, StatusSkipped}; !slices.Equal(status(results), want) {
+		t.Fatalf("add statuses = %v, want %v (%+v)", status(results), want, results)
+	}
+	if results[0].Version != "2.0.0" || results[0].PreviousVersion != "" {
+		t.Errorf("add Polly = %+v, want the latest stable version", results[0])
+	}
+	if !slices.Equal(notified, []string{"Polly", "Serilog", "Missing"}) {
+		t.Errorf("notified = %v", notified)
+	}
+
+	results = bulk.Run(ctx, ActionUpdate, path, []Spec{{ID: "Serilog"}, {ID: "Autofac"}})
+	if want := []Status{StatusChanged, StatusSkipped}; !slices.Equal(status(results), want) {
+		t.Fatalf("update statuses = %v, want %v (%+v)", status(results), want, results)
+	}
+	if results[0].PreviousVersion != "3.0.0" || results[0].Version != "2.0.0" {
+		t.Errorf("update Serilog = %+v", results[0])
+	}
+
+	results = bulk.Run(ctx, ActionRemove, path, []Spec{{ID: "polly"}, {ID: "Autofac"}})
+	if want := []Status{StatusChanged, StatusUnchanged}; !slices.Equal(status(results), want) {
+		t.Fatalf("remove statuses = %v, want %v (%+v)", status(results), want, results)
+	}
+
+	results = bulk.Run(ctx, ActionAdd, filepath.Join(t.TempDir(), "Gone.csproj"), []Spec{{ID: "Polly", Version: "1.0.0"}})
+	if results[0].Status != StatusFailed || results[0].Error == "" {
+		t.Errorf("add to a missing project = %+v, want failed", results[0])
+	}
+}
+
+// TestBulkFramework tests changing the references of one target framework
+func TestBulkFramework(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "Lib.csproj")
+	text := "<Project Sdk=\"Microsoft.NET.Sdk\">\n  <PropertyGroup>\n    <TargetFrameworks>net8.0;net48</TargetFrameworks>\n  </PropertyGroup>\n" +
+		"  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\n  </ItemGroup>\n</Project>\n"
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bulk := &Bulk{Backend: Direct{}, Framework: "net48"}
+
+	results := bulk.Run(ctx, ActionAdd, path, []Spec{{ID: "System.Memory", Version: "4.5.5"}, {ID: "Serilog", Version: "3.1.0"}})
+	if results[0].Status != StatusChanged || results[1].Status != StatusSkipped {
+		t.Fatalf("add = %+v, want System.Memory added and Serilog skipped", results)
+	}
+	p, err := project.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref, ok := p.ReferenceFor("System.Memory", "net48"); !ok || ref.Condition != project.FrameworkCondition("net48") {
+		t.Errorf("System.Memory = %+v, %v, want a reference for net48", ref, ok)
+	}
+
+	bulk.Framework = "net8.0"
+	if results := bulk.Run(ctx, ActionUpdate, path, []Spec{{ID: "System.Memory", Version: "4.6.0"}}); results[0].Status != StatusSkipped {
+		t.Errorf("update for another framework = %+v, want skipped", results[0])
+	}
+	bulk.Framework = "net48"
+	if results := bulk.Run(ctx, ActionUpdate, path, []Spec{{ID: "System.Memory", Version: "4.6.0"}}); results[0].Status != StatusChanged || results[0].PreviousVersion != "4.5.5" {
+		t.Errorf("update = %+v, want 4.5.5 changed", results[0])
+	}
+	if results := bulk.Run(ctx, ActionRemove, path, []Spec{{ID: "System.Memory"}}); results[0].Status != StatusChanged {
+		t.Errorf("remove = %+v, want changed", results[0])
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != text {
+		t.Errorf("after adding and removing, the project is\n%s\nwant\n%s", data, text)
+	}
+
+	spawner := &fakeDotnet{}
+	if _, err := (CLI{Spawner: spawner}).SetFor(ctx, path, "net48", "System.Memory", "4.5.5", ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(spawner.calls) != 1 || !strings.HasSuffix(spawner.calls[0], "--framework net48") {
+		t.Errorf("dotnet calls = %v, want add package --framework net48", spawner.calls)
+	}
+}
@@ -0,0 +1,188 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/willibrandon/lazynuget/internal/instance"
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// Restore modes, the values of the restoreMode setting.
+const (
+	RestoreAlways = "always" // After every change
+	RestoreBatch  = "batch"  // Once, when the session is closed
+	RestoreManual = "manual" // Only when Restore is called
+)
+
+// Session changes package references with a backend and restores the projects it changed
+// as its restore mode asks. Projects changed since their last restore are pending; a
+// Session is safe for concurrent use. Each change holds the lock of the project's
+// workspace (see instance.Hold), so it cannot interleave with another session's.
+type Session struct {
+	backend  Backend
+	spawner  platform.ProcessSpawner // Runs dotnet restore; nil without the dotnet CLI
+	mode     string
+	restores bool   // The backend restores each project it changes (dotnet add package)
+	lockDir  string // Workspace locks; "" changes projects unlocked
+
+	mu      sync.Mutex
+	pending []string // Changed projects not restored since, in the order they changed
+}
+
+// NewSession returns a session using the backend named by the operationBackend setting
+// and the restoreMode setting. Without the dotnet CLI the direct backend is used whatever
+// the setting, and changed projects stay pending.
+func NewSession(backend, mode string, spawner platform.ProcessSpawner) (*Session, error) {
+	switch mode {
+	case RestoreAlways, RestoreBatch, RestoreManual:
+	case "":
+		mode = RestoreAlways
+	default:
+		return nil, fmt.Errorf("unknown restore mode %q (want %s, %s, or %s)", mode, RestoreAlways, RestoreBatch, RestoreManual)
+	}
+
+	s := &Session{backend: Direct{}, mode: mode, lockDir: instance.DefaultLockDir()}
+	if platform.DotnetAvailable() {
+		s.spawner = spawner
+	}
+	switch backend {
+	case BackendCLI, "":
+		if s.spawner != nil {
+			// dotnet add package checks compatibility when it restores, so let it
+			s.restores = mode == RestoreAlways
+			s.backend = CLI{Spawner: spawner, NoRestore: !s.restores}
+		}
+	case BackendDirect:
+	default:
+		return nil, fmt.Errorf("unknown operation backend %q (want %s or %s)", backend, BackendCLI, BackendDirect)
+	}
+	return s, nil
+}
+
+// Mode returns the session's restore mode.
+func (s *Session) Mode() string {
+	return s.mode
+}
+
+// Set implements Backend.
+func (s *Session) Set(ctx context.Context, path, id, version, reason string) ([]string, error) {
+	var changed []string
+	err := s.hold(path, func() (err error) {
+		changed, err = s.backend.Set(ctx, path, id, version, reason)
+		return err
+	})
+	if err != nil || len(changed) == 0 {
+		return changed, err
+	}
+	return changed, s.changed(ctx, path)
+}
+
+// Remove implements Backend. Removing the last reference to a package still leaves its
+// assets in the project until it is restored.
+func (s *Session) Remove(ctx context.Context, path, id string) (bool, error) {
+	var removed bool
+	err := s.hold(path, func() (err error) {
+		removed, err = s.backend.Remove(ctx, path, id)
+		return err
+	})
+	if err != nil || !removed {
+		return removed, err
+	}
+	return true, s.changed(ctx, path)
+}
+
+// SetFor implements Backend.
+func (s *Session) SetFor(ctx context.Context, path, framework, id, version, reason string) ([]string, error) {
+	var changed []string
+	err := s.hold(path, func() (err error) {
+		changed, err = s.backend.SetFor(ctx, path, framework, id, version, reason)
+		return err
+	})
+	if err != nil || len(changed) == 0 {
+		return changed, err
+	}
+	return changed, s.changed(ctx, path)
+}
+
+// RemoveFor implements Backend.
+func (s *Session) RemoveFor(ctx context.Context, path, framework, id string) (bool, error) {
+	var removed bool
+	err := s.hold(path, func() (err error) {
+		removed, err = s.backend.RemoveFor(ctx, path, framework, id)
+		return err
+	})
+	if err != nil || !removed {
+		return removed, err
+	}
+	return true, s.changed(ctx, path)
+}
+
+// hold runs fn, which changes the project at path, under its workspace lock.
+func (s *Session) hold(path string, fn func() error) error {
+	if s.lockDir == "" {
+		return fn()
+	}
+	root, err := instance.WorkspaceRoot(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	return instance.Hold(s.lockDir, root, fn)
+}
+
+// changed records that a project changed and restores it when the mode asks to.
+func (s *Session) changed(ctx context.Context, path string) error {
+	if s.restores {
+		return nil
+	}
+	s.mu.Lock()
+	if !slices.Contains(s.pending, path) {
+		s.pending = append(s.pending, path)
+	}
+	s.mu.Unlock()
+	if s.mode != RestoreAlways || s.spawner == nil {
+		return nil
+	}
+	return s.Restore(ctx)
+}
+
+// Pending returns the projects changed since they were last restored.
+func (s *Session) Pending() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.pending)
+}
+
+// Restore restores the pending projects. Projects that fail to restore stay pending.
+func (s *Session) Restore(ctx context.Context) error {
+	if s.spawner == nil {
+		if len(s.Pending()) == 0 {
+			return nil
+		}
+		return fmt.Errorf("cannot restore: the dotnet CLI was not found in PATH")
+	}
+
+	var errs []error
+	for _, path := range s.Pending() {
+		if err := dotnet(ctx, s.spawner, "dotnet restore", "restore", path); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		s.mu.Lock()
+		s.pending = slices.DeleteFunc(s.pending, func(p string) bool { return p == path })
+		s.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// Close ends the session, restoring the pending projects in batch mode.
+func (s *Session) Close(ctx context.Context) error {
+	if s.mode != RestoreBatch {
+		return nil
+	}
+	return s.Restore(ctx)
+}
@@ -0,0 +1,83 @@
+package operation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+)
+
+// Spec names a package and, optionally, a version for add, remove, and update.
+type Spec struct {
+	ID      string
+	Version string // Empty for the latest version, or when removing
+}
+
+// String returns the spec as ID@VERSION, or the ID alone.
+func (s Spec) String() string {
+	if s.Version == "" {
+		return s.ID
+	}
+	return s.ID + "@" + s.Version
+}
+
+// ParseSpec parses "ID", "ID@VERSION", or "ID VERSION", the forms grep, awk, and jq
+// readily produce.
+func ParseSpec(text string) (Spec, error) {
+	fields := strings.Fields(strings.Replace(text, "@", " ", 1))
+	if len(fields) == 0 || len(fields) > 2 {
+		return Spec{}, fmt.Errorf("%q is not a package spec (want ID, ID@VERSION, or ID VERSION)", text)
+	}
+	spec := Spec{ID: fields[0]}
+	if !validID(spec.ID) {
+		return Spec{}, fmt.Errorf("%q is not a valid package ID", spec.ID)
+	}
+	if len(fields) == 2 {
+		spec.Version = fields[1]
+		if !strings.ContainsAny(spec.Version[:1], "0123456789[(*") {
+			return Spec{}, fmt.Errorf("%s: %q is not a version", spec.ID, spec.Version)
+		}
+		if _, err := nuget.ParseVersionRange(spec.Version); err != nil {
+			return Spec{}, fmt.Errorf("%s: %w", spec.ID, err)
+		}
+	}
+	return spec, nil
+}
+
+// ReadSpecs reads one package spec per line, skipping blank lines and lines starting
+// with #. Errors give the line number.
+func ReadSpecs(r io.Reader) ([]Spec, error) {
+	var specs []Spec
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		spec, err := ParseSpec(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		specs = append(specs, spec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return specs, nil
+}
+
+// validID reports whether id can be a NuGet package ID: letters, digits, dots,
+// underscores, and hyphens, at most 100 of them.
+func validID(id string) bool {
+	if id == "" || len(id) > 100 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+// Package outdated decides which package references have newer versions available.
+//
+// A reference is compared by the version restore actually resolved (from
+// obj/project.assets.json) rather than the version text in the project file, so version
+// ranges and floating versions are judged by what they resolve to. A floating reference
+// whose range already includes the latest version is never reported as needing an edit:
+// the next restore picks the new version up.
+package outdated
+
+import (
+	"fmt"
+
+	"github.com/willibrandon/lazynuget/internal/ci"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+)
+
+// Status is the outcome of checking one package reference.
+type Status string
+
+const (
+	// StatusCurrent means the resolved version is the latest.
+	StatusCurrent Status = "current"
+	// StatusOutdated means the reference has to change to get the latest version.
+	StatusOutdated Status = "outdated"
+	// StatusRestore means the reference's floating range already includes the latest
+	// version; restoring again picks it up without editing the project.
+	StatusRestore Status = "restore"
+	// StatusUnknown means the latest version or the requested range is unknown.
+	StatusUnknown Status = "unknown"
+)
+
+// Result is the outcome of checking one package reference.
+type Result struct {
+	Package   string
+	Requested string // The version or range as written in the project
+	Resolved  string // The version restore resolved, or the one it would resolve
+	Latest    string // The highest available version ("" when none are known)
+	Status    Status
+	Floating  bool   // The requested range floats (e.g., 6.0.*)
+	Reason    string // Why the status is unknown
+}
+
+// Options control which versions count as the latest.
+type Options struct {
+	Prerelease bool // Consider prerelease versions even for stable references
+}
+
+// Check compares a package reference against the available versions. resolved is the
+// version from the assets file, or "" when the project has not been restored, in which
+// case the version restore would pick from available is used.
+func Check(id, requested, resolved string, available []string, opts Options) Result {
+	result := Result{Package: id, Requested: requested, Resolved: resolved, Status: StatusUnknown}
+
+	r, err := nuget.ParseVersionRange(requested)
+	if err != nil {
+		result.Reason = err.Error()
+		return result
+	}
+	result.Floating = r.IsFloating()
+	if result.Resolved == "" {
+		result.Resolved, _ = r.Resolve(available)
+	}
+
+	// Prerelease references (e.g., 2.0.0-beta.1 or 8.*-*) follow prereleases
+	prerelease := opts.Prerelease || nuget.IsPrerelease(r.Min) || nuget.IsPrerelease(result.Resolved)
+	result.Latest = nuget.Latest(available, prerelease)
+
+	switch {
+	case result.Latest == "":
+		result.Reason = fmt.Sprintf("no versions of %s found", id)
+	case result.Resolved == "":
+		result.Reason = fmt.Sprintf("no available version satisfies %s", requested)
+	case nuget.CompareVersions(result.Resolved, result.Latest) >= 0:
+		result.Status = StatusCurrent
+	case result.Floating && r.Contains(result.Latest):
+		result.Status = StatusRestore
+	default:
+		result.Status = StatusOutdated
+	}
+	return result
+}
+
+// RuleOutdated is the rule of outdated references in CI reports.
+const RuleOutdated = "outdated-package"
+
+// Rules describe the findings of outdated checks to CI systems.
+var Rules = []ci.Rule{{ID: RuleOutdated, Name: "OutdatedPackage", Description: "A package reference has a newer version", Level: ci.LevelWarning}}
+
+// CICheck returns a result as a CI check of a project: an outdated reference is a warning,
+// and a reference whose status is unknown is skipped.
+func (r Result) CICheck(project string) ci.Check {
+	version := r.Resolved
+	if version == "" {
+		version = r.Requested
+	}
+	c := ci.Check{Project: project, Package: r.Package, Version: version}
+	switch r.Status {
+	case StatusOutdated:
+		message := fmt.Sprintf("%s %s can be updated to %s", r.Package, version, r.Latest)
+		if r.Floating {
+			message += fmt.Sprintf(" (outside the floating range %s)", r.Requested)
+		}
+		c.Findings = []ci.Finding{{Rule: RuleOutdated, Level: ci.LevelWarning, Message: message}}
+	case StatusUnknown:
+		c.Skipped = r.Reason
+	}
+	return c
+}
@@ -0,0 +1,41 @@
+package outdated
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// BenchmarkCheck measures checking a reference against a long version history
+// Target: <5ms, so checking a 500-project repository is dominated by feed requests
+func BenchmarkCheck(b *testing.B) {
+	// About as many versions as the most published packages on nuget.org
+	var available []string
+	for major := range 20 {
+		for minor := range 20 {
+			available = append(available, fmt.Sprintf("%d.%d.0", major, minor), fmt.Sprintf("%d.%d.1-preview.%d", major, minor, minor))
+		}
+	}
+	tests := []struct {
+		name      string
+		requested string
+	}{
+		{"exact", "3.1.0"},
+		{"floating", "3.*"},
+		{"range", "[2.0.0, 5.0.0)"},
+	}
+
+	for _, tt := range tests {
+		b.Run(tt.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				_ = Check("Contoso.Core", tt.requested, "", available, Options{})
+			}
+
+			avgTime := b.Elapsed() / time.Duration(b.N)
+			if avgTime > 5*time.Millisecond {
+				b.Errorf("Check time %v exceeds 5ms target", avgTime)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package outdated
+
+import "testing"
+
+// TestCheck tests outdated decisions for exact, ranged, and floating references
+func TestCheck(t *testing.T) {
+	available := []string{"6.0.0", "6.0.1", "6.1.0", "7.0.0", "8.0.0-preview.1"}
+
+	tests := []struct {
+		name      string
+		requested string
+		resolved  string
+		opts      Options
+		status    Status
+		latest    string
+		wantRes   string
+	}{
+		{name: "exact version behind", requested: "6.0.0", resolved: "6.0.0", status: StatusOutdated, latest: "7.0.0", wantRes: "6.0.0"},
+		{name: "exact version current", requested: "7.0.0", resolved: "7.0.0", status: StatusCurrent, latest: "7.0.0", wantRes: "7.0.0"},
+		{name: "range resolves to its lowest version", requested: "[6.0, 8.0)", status: StatusOutdated, latest: "7.0.0", wantRes: "6.0.0"},
+		{name: "floating range includes latest", requested: "*", resolved: "6.1.0", status: StatusRestore, latest: "7.0.0", wantRes: "6.1.0"},
+		{name: "floating range resolved to latest", requested: "7.*", resolved: "7.0.0", status: StatusCurrent, latest: "7.0.0", wantRes: "7.0.0"},
+		{name: "floating range excludes latest", requested: "6.0.*", resolved: "6.0.1", status: StatusOutdated, latest: "7.0.0", wantRes: "6.0.1"},
+		{name: "floating range without assets", requested: "6.*", status: StatusOutdated, latest: "7.0.0", wantRes: "6.1.0"},
+		{name: "prerelease float follows prereleases", requested: "*-*", resolved: "7.0.0", status: StatusRestore, latest: "8.0.0-preview.1", wantRes: "7.0.0"},
+		{name: "prerelease option", requested: "7.0.0", resolved: "7.0.0", opts: Options{Prerelease: true}, status: StatusOutdated, latest: "8.0.0-preview.1", wantRes: "7.0.0"},
+		{name: "nothing satisfies range", requested: "[9.0,)", status: StatusUnknown, latest: "7.0.0"},
+		{name: "invalid range", requested: "[1.0", status: StatusUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Check("Pkg", tt.requested, tt.resolved, available, tt.opts)
+			if got.Status != tt.status || got.Latest != tt.latest || got.Resolved != tt.wantRes {
+				t.Errorf("Check() = %+v, want status %s latest %q resolved %q", got, tt.status, tt.latest, tt.wantRes)
+			}
+			if got.Status == StatusUnknown && got.Reason == "" {
+				t.Error("unknown results should explain why")
+			}
+		})
+	}
+
+	if got := Check("Pkg", "1.0.0", "", nil, Options{}); got.Status != StatusUnknown {
+		t.Errorf("Check() without versions = %s, want unknown", got.Status)
+	}
+}
+
+// TestCICheck tests results as CI checks: outdated references warn, unknown ones are skipped
+func TestCICheck(t *testing.T) {
+	available := []string{"6.0.0", "6.0.1", "7.0.0"}
+	if c := Check("A", "6.0.*", "6.0.1", available, Options{}).CICheck("App.csproj"); len(c.Findings) != 1 ||
+		c.Version != "6.0.1" || c.Findings[0].Message != "A 6.0.1 can be updated to 7.0.0 (outside the floating range 6.0.*)" {
+		t.Errorf("outdated = %+v", c)
+	}
+	if c := Check("A", "7.0.0", "7.0.0", available, Options{}).CICheck("App.csproj"); len(c.Findings) != 0 || c.Skipped != "" {
+		t.Errorf("current = %+v", c)
+	}
+	if c := Check("A", "[1.0", "", available, Options{}).CICheck("App.csproj"); c.Skipped == "" || c.Version != "[1.0" {
+		t.Errorf("unknown = %+v", c)
+	}
+}
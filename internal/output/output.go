@@ -0,0 +1,147 @@
+// Package output provides versioned JSON output for scripts and tools built on LazyNuGet.
+//
+// Every JSON document written by LazyNuGet (CLI subcommands and the RPC API alike) is
+// wrapped in an Envelope carrying an explicit schema version. The schema version is a
+// single major number: additive changes (new fields) keep the version, while removals,
+// renames, and type changes bump it. Consumers pin the version they were written
+// against with --output-version, and a per-kind downgrader chain converts the current
+// payload into the shape of the requested older major version.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// CurrentSchemaVersion is the schema version emitted when no version is requested.
+	CurrentSchemaVersion = 1
+
+	// MinSchemaVersion is the oldest schema version that can still be emitted.
+	// Policy: the previous major version stays available for at least one release cycle.
+	MinSchemaVersion = 1
+)
+
+// Envelope wraps every JSON document emitted by LazyNuGet.
+type Envelope struct {
+	Data          any    `json:"data"`
+	Kind          string `json:"kind"`
+	SchemaVersion int    `json:"schemaVersion"`
+}
+
+// Downgrader converts a payload of a given kind from schema version N to N-1.
+type Downgrader func(data any) (any, error)
+
+var (
+	downgraders   = make(map[string]map[int]Downgrader) // kind -> fromVersion -> fn
+	downgradersMu sync.RWMutex
+)
+
+// RegisterDowngrader registers the conversion of kind from fromVersion to fromVersion-1.
+// Kinds that never changed shape need no downgrader; their payload is emitted as-is.
+func RegisterDowngrader(kind string, fromVersion int, fn Downgrader) {
+	downgradersMu.Lock()
+	defer downgradersMu.Unlock()
+
+	if downgraders[kind] == nil {
+		downgraders[kind] = make(map[int]Downgrader)
+	}
+	downgraders[kind][fromVersion] = fn
+}
+
+// ParseVersion parses a --output-version value.
+// Accepts "", "1", "v1", and "1.0" style values. Empty means CurrentSchemaVersion.
+func ParseVersion(s string) (int, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(strings.ToLower(s)), "v")
+	if s == "" {
+		return CurrentSchemaVersion, nil
+	}
+
+	major, _, _ := strings.Cut(s, ".")
+	version, err := strconv.Atoi(major)
+	if err != nil {
+		return 0, fmt.Errorf("invalid output version %q: must be a major version number such as %d", s, CurrentSchemaVersion)
+	}
+
+	if version < MinSchemaVersion || version > CurrentSchemaVersion {
+		return 0, fmt.Errorf("unsupported output version %d: supported versions are %d through %d",
+			version, MinSchemaVersion, CurrentSchemaVersion)
+	}
+
+	return version, nil
+}
+
+// Writer emits enveloped JSON documents at a fixed schema version.
+type Writer struct {
+	w       io.Writer
+	version int
+}
+
+// NewWriter creates a Writer emitting the given schema version.
+// A version of 0 selects CurrentSchemaVersion.
+func NewWriter(w io.Writer, version int) (*Writer, error) {
+	if version == 0 {
+		version = CurrentSchemaVersion
+	}
+	if version < MinSchemaVersion || version > CurrentSchemaVersion {
+		return nil, fmt.Errorf("unsupported output version %d: supported versions are %d through %d",
+			version, MinSchemaVersion, CurrentSchemaVersion)
+	}
+
+	return &Writer{w: w, version: version}, nil
+}
+
+// Version returns the schema version this writer emits.
+func (w *Writer) Version() int {
+	return w.version
+}
+
+// Write converts data (always built against CurrentSchemaVersion) to the writer's
+// schema version and writes it as an indented JSON envelope followed by a newline.
+func (w *Writer) Write(kind string, data any) error {
+	converted, err := Convert(kind, data, w.version)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(Envelope{
+		SchemaVersion: w.version,
+		Kind:          kind,
+		Data:          converted,
+	})
+}
+
+// Convert downgrades a current-version payload of the given kind to the target version
+// by applying registered downgraders from CurrentSchemaVersion down to target.
+func Convert(kind string, data any, target int) (any, error) {
+	return convertFrom(kind, data, CurrentSchemaVersion, target)
+}
+
+// convertFrom applies the downgrader chain for kind from version current down to target.
+func convertFrom(kind string, data any, current, target int) (any, error) {
+	downgradersMu.RLock()
+	chain := downgraders[kind]
+	downgradersMu.RUnlock()
+
+	for from := current; from > target; from-- {
+		fn, ok := chain[from]
+		if !ok {
+			// Shape did not change between these versions
+			continue
+		}
+
+		var err error
+		data, err = fn(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s output from schema version %d to %d: %w", kind, from, from-1, err)
+		}
+	}
+
+	return data, nil
+}
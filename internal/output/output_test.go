@@ -0,0 +1,126 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// TestParseVersion tests --output-version parsing
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{name: "empty selects current", input: "", want: CurrentSchemaVersion},
+		{name: "plain major", input: "1", want: 1},
+		{name: "v prefix", input: "v1", want: 1},
+		{name: "major.minor", input: "1.0", want: 1},
+		{name: "future version", input: "99", wantErr: true},
+		{name: "zero", input: "0", wantErr: true},
+		{name: "garbage", input: "latest", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseVersion(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseVersion(%q) expected error, got %d", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseVersion(%q) unexpected error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseVersion(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestWriterEnvelope verifies documents are wrapped with kind and schema version
+func TestWriterEnvelope(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, 0)
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	if w.Version() != CurrentSchemaVersion {
+		t.Errorf("Version() = %d, want %d", w.Version(), CurrentSchemaVersion)
+	}
+
+	if err := w.Write("test", map[string]string{"hello": "world"}); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	var env struct {
+		Data          map[string]string `json:"data"`
+		Kind          string            `json:"kind"`
+		SchemaVersion int               `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &env); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if env.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("schemaVersion = %d, want %d", env.SchemaVersion, CurrentSchemaVersion)
+	}
+	if env.Kind != "test" {
+		t.Errorf("kind = %q, want %q", env.Kind, "test")
+	}
+	if env.Data["hello"] != "world" {
+		t.Errorf("data not preserved: %v", env.Data)
+	}
+}
+
+// TestNewWriterRejectsUnsupportedVersion verifies out-of-range versions fail early
+func TestNewWriterRejectsUnsupportedVersion(t *testing.T) {
+	if _, err := NewWriter(&bytes.Buffer{}, CurrentSchemaVersion+1); err == nil {
+		t.Error("NewWriter() expected error for future version")
+	}
+}
+
+// TestConvertDowngraderChain verifies downgraders are applied newest to oldest
+func TestConvertDowngraderChain(t *testing.T) {
+	const kind = "chain-test"
+	RegisterDowngrader(kind, 3, func(data any) (any, error) {
+		return data.(string) + ">v2", nil
+	})
+	RegisterDowngrader(kind, 2, func(data any) (any, error) {
+		return data.(string) + ">v1", nil
+	})
+
+	got, err := convertFrom(kind, "v3", 3, 1)
+	if err != nil {
+		t.Fatalf("convertFrom() failed: %v", err)
+	}
+	if got != "v3>v2>v1" {
+		t.Errorf("convertFrom() = %q, want %q", got, "v3>v2>v1")
+	}
+
+	// Converting to the current version applies nothing
+	got, err = convertFrom(kind, "v3", 3, 3)
+	if err != nil {
+		t.Fatalf("convertFrom() failed: %v", err)
+	}
+	if got != "v3" {
+		t.Errorf("convertFrom() to current = %q, want %q", got, "v3")
+	}
+}
+
+// TestConvertDowngraderError verifies conversion errors are surfaced with context
+func TestConvertDowngraderError(t *testing.T) {
+	const kind = "error-test"
+	sentinel := errors.New("boom")
+	RegisterDowngrader(kind, 2, func(any) (any, error) {
+		return nil, sentinel
+	})
+
+	if _, err := convertFrom(kind, "data", 2, 1); !errors.Is(err, sentinel) {
+		t.Errorf("convertFrom() error = %v, want wrapped %v", err, sentinel)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build !windows
+
+package permissions
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// inspect records on f the mode of an entry that group or other users have any
+// permission on.
+func inspect(f *Finding, info fs.FileInfo) (bool, error) {
+	perm := info.Mode().Perm()
+	if perm&0o077 == 0 {
+		return false, nil
+	}
+	f.Mode = fmt.Sprintf("%04o", perm)
+	f.Want = fmt.Sprintf("%04o", perm&^0o077)
+	f.Problem = fmt.Sprintf("mode is %s, should be %s", f.Mode, f.Want)
+	return true, nil
+}
+
+// restrict removes group and other permissions from an entry.
+func restrict(path string, info fs.FileInfo) error {
+	return os.Chmod(path, info.Mode().Perm()&^0o077)
+}
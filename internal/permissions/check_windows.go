@@ -0,0 +1,170 @@
+//go:build windows
+
+package permissions
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Access rights that let an account read an entry, or change it or its permissions.
+const (
+	readRights  = windows.FILE_READ_DATA | windows.GENERIC_READ | windows.GENERIC_ALL
+	writeRights = windows.FILE_WRITE_DATA | windows.FILE_APPEND_DATA | windows.GENERIC_WRITE |
+		windows.GENERIC_ALL | windows.WRITE_DAC | windows.WRITE_OWNER
+)
+
+// inspect reads the access control list of an entry and records on f the accounts other
+// than the user, SYSTEM, and Administrators that it lets read or write, and whether the
+// user is left without write access. Unix modes mean nothing here: Go derives them from
+// the read-only attribute alone.
+func inspect(f *Finding, _ fs.FileInfo) (bool, error) {
+	sd, err := windows.GetNamedSecurityInfo(f.Path, windows.SE_FILE_OBJECT, windows.DACL_SECURITY_INFORMATION)
+	if err != nil {
+		return false, fmt.Errorf("reading access control list: %w", err)
+	}
+	dacl, _, err := sd.DACL()
+	if err != nil {
+		return false, fmt.Errorf("reading access control list: %w", err)
+	}
+	if dacl == nil {
+		f.Problem = "it has no access control list, so everyone can read and write it"
+		return true, nil
+	}
+	trusted, err := trustedSIDs()
+	if err != nil {
+		return false, err
+	}
+	user := trusted[0]
+
+	var problems []string
+	// Access control entries apply in order, and the first to decide a right wins
+	userWrite, userDenied := false, false
+	for i := uint32(0); i < uint32(dacl.AceCount); i++ {
+		var ace *windows.ACCESS_ALLOWED_ACE
+		if err := windows.GetAce(dacl, i, &ace); err != nil {
+			return false, fmt.Errorf("reading access control list: %w", err)
+		}
+		if ace.Header.AceFlags&windows.INHERIT_ONLY_ACE != 0 {
+			continue // Applies to what is created inside, not to the entry itself
+		}
+		sid := (*windows.SID)(unsafe.Pointer(&ace.SidStart)) // #nosec G103 -- the SID follows the mask in an ACE
+		applies := sid.Equals(user)
+		if !applies {
+			applies, _ = windows.Token(0).IsMember(sid)
+		}
+		mask := uint32(ace.Mask)
+
+		switch ace.Header.AceType {
+		case windows.ACCESS_DENIED_ACE_TYPE:
+			if applies && mask&writeRights != 0 && !userWrite {
+				userDenied = true
+			}
+		case windows.ACCESS_ALLOWED_ACE_TYPE:
+			if applies && mask&writeRights != 0 && !userDenied {
+				userWrite = true
+			}
+			if isTrusted(sid, trusted) {
+				continue
+			}
+			switch read, write := mask&readRights != 0, mask&writeRights != 0; {
+			case read && write:
+				problems = append(problems, accountName(sid)+" can read and write it")
+			case read:
+				problems = append(problems, accountName(sid)+" can read it")
+			case write:
+				problems = append(problems, accountName(sid)+" can write it")
+			}
+		}
+	}
+	if !userWrite {
+		problems = append(problems, "you cannot write to it")
+	}
+	if len(problems) == 0 {
+		return false, nil
+	}
+	f.Problem = strings.Join(problems, "; ")
+	return true, nil
+}
+
+// restrict replaces the access control list of an entry with one granting full control
+// to the user, SYSTEM, and Administrators, and not inheriting from the parent. On a
+// directory the entries are inherited by its contents.
+func restrict(path string, info fs.FileInfo) error {
+	trusted, err := trustedSIDs()
+	if err != nil {
+		return err
+	}
+	inheritance := uint32(windows.NO_INHERITANCE)
+	if info.IsDir() {
+		inheritance = windows.SUB_CONTAINERS_AND_OBJECTS_INHERIT
+	}
+	entries := make([]windows.EXPLICIT_ACCESS, 0, len(trusted))
+	for _, sid := range trusted {
+		entries = append(entries, windows.EXPLICIT_ACCESS{
+			AccessPermissions: windows.GENERIC_ALL,
+			AccessMode:        windows.GRANT_ACCESS,
+			Inheritance:       inheritance,
+			Trustee: windows.TRUSTEE{
+				TrusteeForm:  windows.TRUSTEE_IS_SID,
+				TrusteeValue: windows.TrusteeValueFromSID(sid),
+			},
+		})
+	}
+	acl, err := windows.ACLFromEntries(entries, nil)
+	if err != nil {
+		return fmt.Errorf("building access control list: %w", err)
+	}
+	err = windows.SetNamedSecurityInfo(path, windows.SE_FILE_OBJECT,
+		windows.DACL_SECURITY_INFORMATION|windows.PROTECTED_DACL_SECURITY_INFORMATION, nil, nil, acl, nil)
+	if err != nil {
+		return fmt.Errorf("setting access control list: %w", err)
+	}
+	return nil
+}
+
+// trustedSIDs returns the accounts that may access lazynuget's files: the current user
+// first, then SYSTEM and Administrators.
+func trustedSIDs() ([]*windows.SID, error) {
+	tokenUser, err := windows.GetCurrentProcessToken().GetTokenUser()
+	if err != nil {
+		return nil, fmt.Errorf("identifying the current user: %w", err)
+	}
+	sids := []*windows.SID{tokenUser.User.Sid}
+	for _, known := range []windows.WELL_KNOWN_SID_TYPE{windows.WinLocalSystemSid, windows.WinBuiltinAdministratorsSid} {
+		sid, err := windows.CreateWellKnownSid(known)
+		if err != nil {
+			return nil, err
+		}
+		sids = append(sids, sid)
+	}
+	return sids, nil
+}
+
+// isTrusted reports whether sid is one of trusted.
+func isTrusted(sid *windows.SID, trusted []*windows.SID) bool {
+	for _, t := range trusted {
+		if sid.Equals(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// accountName returns the DOMAIN\name of an account, or its SID string when it cannot be
+// looked up (e.g., a deleted account).
+func accountName(sid *windows.SID) string {
+	account, domain, _, err := sid.LookupAccount("")
+	switch {
+	case err != nil:
+		return sid.String()
+	case domain == "":
+		return account
+	default:
+		return domain + `\` + account
+	}
+}
@@ -0,0 +1,141 @@
+// Package permissions verifies that the files lazynuget keeps are private to the user.
+// Configs can hold feed credentials and logs can echo them, so anything other users can
+// read is reported, and fixed on request. On Unix that means directories 0700 and files
+// 0600 at most; on Windows, where modes reflect only the read-only attribute, it means
+// access control lists that grant access to no one but the user, the owner, SYSTEM, and
+// Administrators, and that let the user write.
+package permissions
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// Kind is the JSON document kind of permission findings.
+const Kind = "permissions"
+
+// Target is a directory whose permissions are checked along with its contents.
+type Target struct {
+	Name string `json:"name"` // config, logs, cache, or data
+	Path string `json:"path"`
+
+	// Shallow checks only the directory and its direct entries, for directories holding
+	// third-party trees such as cloned repositories
+	Shallow bool `json:"shallow,omitempty"`
+}
+
+// Finding is a file or directory that other users can access, or that the user cannot
+// write to.
+type Finding struct {
+	Target  string `json:"target"`
+	Path    string `json:"path"`
+	Problem string `json:"problem,omitempty"` // e.g., "Everyone can read it"; empty when it could not be checked
+	Mode    string `json:"mode,omitempty"`    // Unix only, e.g., 0644
+	Want    string `json:"want,omitempty"`    // Unix only: the mode without group and other permissions
+	Fixed   bool   `json:"fixed,omitempty"`
+	Error   string `json:"error,omitempty"` // Why it could not be checked or fixed
+}
+
+// Report is the JSON payload of a permission check.
+type Report struct {
+	Targets  []Target  `json:"targets"`
+	Findings []Finding `json:"findings"`
+}
+
+// Check returns the entries of the targets that are not private to the user and, when fix
+// is set, restricts them: on Unix group and other permissions are removed and owner
+// permissions left alone; on Windows the access control list is replaced with one
+// granting full control to the user, SYSTEM, and Administrators only.
+// Targets that do not exist are skipped, and symbolic links are not followed. An entry
+// in more than one target (the default log directory is in the cache) is reported once.
+func Check(targets []Target, fix bool) []Finding {
+	var findings []Finding
+	seen := make(map[string]bool)
+	for _, target := range targets {
+		if target.Path == "" {
+			continue
+		}
+		err := filepath.WalkDir(target.Path, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) && path == target.Path {
+					return fs.SkipAll
+				}
+				findings = append(findings, Finding{Target: target.Name, Path: path, Error: err.Error()})
+				return nil
+			}
+			if entry.Type()&fs.ModeSymlink != 0 {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				findings = append(findings, Finding{Target: target.Name, Path: path, Error: err.Error()})
+				return nil
+			}
+			if !seen[path] {
+				seen[path] = true
+				if f, ok := check(target.Name, path, info, fix); ok {
+					findings = append(findings, f)
+				}
+			}
+			if entry.IsDir() && target.Shallow && path != target.Path {
+				return fs.SkipDir
+			}
+			return nil
+		})
+		if err != nil {
+			findings = append(findings, Finding{Target: target.Name, Path: target.Path, Error: err.Error()})
+		}
+	}
+	return findings
+}
+
+// check returns the finding for one entry, if it is not private to the user.
+func check(target, path string, info fs.FileInfo, fix bool) (Finding, bool) {
+	f := Finding{Target: target, Path: path}
+	found, err := inspect(&f, info)
+	switch {
+	case err != nil:
+		f.Error = err.Error()
+		return f, true
+	case !found:
+		return Finding{}, false
+	}
+	if fix {
+		if err := restrict(path, info); err != nil {
+			f.Error = err.Error()
+		} else {
+			f.Fixed = true
+		}
+	}
+	return f, true
+}
+
+// DefaultTargets returns the directories lazynuget writes: the user config directory
+// (config, trusted repository configs), logDir, the data directory (notes, history,
+// telemetry consent), and the cache, checked shallowly since it holds cloned repositories. Directories the
+// platform cannot determine are left out.
+func DefaultTargets(logDir string) []Target {
+	var targets []Target
+	info, err := platform.New()
+	if err != nil {
+		return []Target{{Name: "logs", Path: logDir}}
+	}
+	resolver, err := platform.NewPathResolver(info)
+	if err != nil {
+		return []Target{{Name: "logs", Path: logDir}}
+	}
+	if dir, err := resolver.ConfigDir(); err == nil {
+		targets = append(targets, Target{Name: "config", Path: dir})
+	}
+	targets = append(targets, Target{Name: "logs", Path: logDir})
+	if dir, err := resolver.DataDir(); err == nil {
+		targets = append(targets, Target{Name: "data", Path: dir})
+	}
+	if dir, err := resolver.CacheDir(); err == nil {
+		targets = append(targets, Target{Name: "cache", Path: dir, Shallow: true})
+	}
+	return targets
+}
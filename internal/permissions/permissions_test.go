@@ -0,0 +1,75 @@
+//go:build !windows
+
+package permissions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCheck tests reporting and fixing entries other users can access
+func TestCheck(t *testing.T) {
+	root := t.TempDir()
+	config := filepath.Join(root, "config")
+	cache := filepath.Join(root, "cache")
+	logs := filepath.Join(cache, "logs")
+	clone := filepath.Join(cache, "repos", "app")
+	for _, dir := range []string{config, logs, clone} {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(path string, mode os.FileMode) {
+		if err := os.WriteFile(path, nil, mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(config, "config.yml"), 0o644)
+	write(filepath.Join(config, "telemetry.json"), 0o600)
+	write(filepath.Join(logs, "lazynuget.log"), 0o640)
+	write(filepath.Join(clone, "README.md"), 0o644) // Too deep for the shallow cache check
+	if err := os.Chmod(logs, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("/etc/passwd", filepath.Join(config, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	targets := []Target{
+		{Name: "config", Path: config},
+		{Name: "logs", Path: logs},
+		{Name: "cache", Path: cache, Shallow: true},
+		{Name: "data", Path: filepath.Join(root, "missing")},
+	}
+	findings := Check(targets, false)
+	want := map[string]string{
+		filepath.Join(config, "config.yml"):  "0644>0600",
+		logs:                                 "0755>0700",
+		filepath.Join(logs, "lazynuget.log"): "0640>0600",
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("Check() = %+v, want %d findings", findings, len(want))
+	}
+	for _, f := range findings {
+		if got := f.Mode + ">" + f.Want; want[f.Path] != got || f.Problem == "" || f.Fixed || f.Error != "" {
+			t.Errorf("Check() finding %+v, want %s", f, want[f.Path])
+		}
+	}
+
+	for _, f := range Check(targets, true) {
+		if !f.Fixed {
+			t.Errorf("Check(fix) finding %+v not fixed", f)
+		}
+	}
+	if findings := Check(targets, false); len(findings) != 0 {
+		t.Errorf("Check() after fixing = %+v, want none", findings)
+	}
+	info, err := os.Stat(filepath.Join(config, "config.yml"))
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("config.yml mode after fixing = %v, %v, want 0600", info.Mode().Perm(), err)
+	}
+}
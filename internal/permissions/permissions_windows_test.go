@@ -0,0 +1,60 @@
+//go:build windows
+
+package permissions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/windows"
+)
+
+// TestCheck_Windows tests reporting and fixing an access control list that lets
+// everyone read a file
+func TestCheck_Windows(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yml")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	everyone, err := windows.CreateWellKnownSid(windows.WinWorldSid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acl, err := windows.ACLFromEntries([]windows.EXPLICIT_ACCESS{{
+		AccessPermissions: windows.GENERIC_READ,
+		AccessMode:        windows.GRANT_ACCESS,
+		Trustee: windows.TRUSTEE{
+			TrusteeForm:  windows.TRUSTEE_IS_SID,
+			TrusteeValue: windows.TrusteeValueFromSID(everyone),
+		},
+	}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := windows.SetNamedSecurityInfo(path, windows.SE_FILE_OBJECT,
+		windows.DACL_SECURITY_INFORMATION|windows.PROTECTED_DACL_SECURITY_INFORMATION, nil, nil, acl, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	targets := []Target{{Name: "config", Path: dir}}
+	findings := Check(targets, false)
+	if len(findings) != 1 || findings[0].Path != path {
+		t.Fatalf("Check() = %+v, want one finding for %s", findings, path)
+	}
+	problem := findings[0].Problem
+	if !strings.Contains(problem, "can read it") || !strings.Contains(problem, "you cannot write to it") {
+		t.Errorf("Check() problem = %q, want Everyone reading and no write access", problem)
+	}
+
+	for _, f := range Check(targets, true) {
+		if !f.Fixed {
+			t.Errorf("Check(fix) finding %+v not fixed", f)
+		}
+	}
+	if findings := Check(targets, false); len(findings) != 0 {
+		t.Errorf("Check() after fixing = %+v, want none", findings)
+	}
+}
@@ -0,0 +1,124 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Directory kinds resolved by AppDir. Each is also the name of the folder under the
+// system temp directory that replaces an unusable platform directory.
+const (
+	DirCache = "cache" // CacheDir: disposable files (feed responses, locks, sockets)
+	DirState = "state" // ConfigDir: decisions kept next to the config (repository trust)
+	DirData  = "data"  // DataDir: data kept between runs (notes, history, telemetry, update checks)
+)
+
+// AppDir returns the platform directory of kind (DirCache, DirState, or DirData) joined
+// with elem. When the platform gives no location, or the location cannot be created or
+// written, <temp>/lazynuget/<kind> is used instead, the same fallback the app applies at
+// startup, so subcommands and the running app agree on where files live.
+// Returns "" only when the fallback cannot be created either.
+func AppDir(kind string, elem ...string) string {
+	dir := platformDir(kind)
+	if dir == "" || CheckWritableDir(dir) != nil {
+		var err error
+		if dir, err = TempFallbackDir(kind); err != nil {
+			return ""
+		}
+	}
+	return filepath.Join(append([]string{dir}, elem...)...)
+}
+
+// DataPath returns AppDir(DirData, elem...). Files written by earlier versions under the
+// legacy directory kind are moved there first, so upgrading keeps notes, history, and
+// telemetry consent.
+func DataPath(legacyKind string, elem ...string) string {
+	path := AppDir(DirData, elem...)
+	if path == "" {
+		return ""
+	}
+	if legacy := platformDir(legacyKind); legacy != "" {
+		MoveLegacy(filepath.Join(append([]string{legacy}, elem...)...), path)
+	}
+	return path
+}
+
+// MoveLegacy renames from to to when from exists and to does not. Errors are ignored:
+// the caller then starts over at to, as on a first run.
+func MoveLegacy(from, to string) {
+	if from == "" || to == "" || from == to {
+		return
+	}
+	if _, err := os.Lstat(to); !os.IsNotExist(err) {
+		return
+	}
+	if _, err := os.Lstat(from); err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0o700); err != nil {
+		return
+	}
+	_ = os.Rename(from, to)
+}
+
+// platformDir returns the platform directory of kind, or "" if it cannot be determined.
+func platformDir(kind string) string {
+	info, err := New()
+	if err != nil {
+		return ""
+	}
+	resolver, err := NewPathResolver(info)
+	if err != nil {
+		return ""
+	}
+
+	var dir string
+	switch kind {
+	case DirCache:
+		dir, err = resolver.CacheDir()
+	case DirState:
+		dir, err = resolver.ConfigDir()
+	case DirData:
+		dir, err = resolver.DataDir()
+	default:
+		return ""
+	}
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// CheckWritableDir creates dir with owner-only permissions if it does not exist, and
+// verifies that files can be written in it.
+func CheckWritableDir(dir string) error {
+	info, err := os.Stat(dir)
+	switch {
+	case os.IsNotExist(err):
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("cannot create %s: %w", dir, err)
+		}
+	case err != nil:
+		return fmt.Errorf("cannot access %s: %w", dir, err)
+	case !info.IsDir():
+		return fmt.Errorf("%s exists but is not a directory", dir)
+	}
+
+	testFile := filepath.Join(dir, ".lazynuget-write-test")
+	if err := os.WriteFile(testFile, []byte("test"), 0o600); err != nil {
+		return fmt.Errorf("cannot write to %s: %w", dir, err)
+	}
+	_ = os.Remove(testFile)
+	return nil
+}
+
+// TempFallbackDir creates and returns <temp>/lazynuget/<name>, the directory that replaces
+// an unusable platform directory.
+func TempFallbackDir(name string) (string, error) {
+	dir := filepath.Join(os.TempDir(), "lazynuget", name)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("cannot create fallback %s directory %s: %w", name, dir, err)
+	}
+	return dir, nil
+}
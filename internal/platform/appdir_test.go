@@ -0,0 +1,104 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// TestAppDir tests that an unusable platform directory falls back to the temp directory
+func TestAppDir(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG_CACHE_HOME only selects the cache directory on Linux")
+	}
+	t.Setenv("TMPDIR", t.TempDir())
+
+	usable := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", usable)
+	if got, want := AppDir(DirCache, "http"), filepath.Join(usable, "lazynuget", "http"); got != want {
+		t.Errorf("AppDir(cache) = %q, want %q", got, want)
+	}
+
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CACHE_HOME", blocker)
+	if got, want := AppDir(DirCache, "http"), filepath.Join(os.TempDir(), "lazynuget", "cache", "http"); got != want {
+		t.Errorf("AppDir(cache) under a file = %q, want the fallback %q", got, want)
+	}
+
+	if got := AppDir("unknown"); got != filepath.Join(os.TempDir(), "lazynuget", "unknown") {
+		t.Errorf("AppDir(unknown) = %q, want the fallback", got)
+	}
+}
+
+// TestCheckWritableDir tests creating a missing directory and rejecting files
+func TestCheckWritableDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "new", "dir")
+	if err := CheckWritableDir(dir); err != nil {
+		t.Fatalf("CheckWritableDir() error = %v", err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Errorf("CheckWritableDir() did not create %s", dir)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("CheckWritableDir() left %d files behind", len(entries))
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckWritableDir(file); err == nil {
+		t.Error("CheckWritableDir() of a file = nil, want an error")
+	}
+}
+
+// TestDataPath tests that files from the legacy config directory move to the data directory
+func TestDataPath(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG_CONFIG_HOME and XDG_DATA_HOME only apply on Linux")
+	}
+	configHome, dataHome := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_DATA_HOME", dataHome)
+
+	legacy := filepath.Join(configHome, "lazynuget", "telemetry.json")
+	if err := os.MkdirAll(filepath.Dir(legacy), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacy, []byte(`{"enabled":true}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	want := filepath.Join(dataHome, "lazynuget", "telemetry.json")
+	if got := DataPath(DirState, "telemetry.json"); got != want {
+		t.Fatalf("DataPath() = %q, want %q", got, want)
+	}
+	if data, err := os.ReadFile(want); err != nil || string(data) != `{"enabled":true}` {
+		t.Errorf("DataPath() did not move the legacy file: %q, %v", data, err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("legacy file still exists after the move")
+	}
+}
+
+// TestMoveLegacy verifies that an existing destination is never overwritten
+func TestMoveLegacy(t *testing.T) {
+	dir := t.TempDir()
+	from, to := filepath.Join(dir, "old"), filepath.Join(dir, "new")
+	for path, data := range map[string]string{from: "old", to: "new"} {
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	MoveLegacy(from, to)
+	if data, _ := os.ReadFile(to); string(data) != "new" {
+		t.Errorf("MoveLegacy() overwrote the destination with %q", data)
+	}
+	if _, err := os.Stat(from); err != nil {
+		t.Errorf("MoveLegacy() removed the source although the destination existed")
+	}
+}
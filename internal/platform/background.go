@@ -0,0 +1,98 @@
+package platform
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Background is whether the terminal draws on a dark or a light background.
+type Background string
+
+// Terminal backgrounds. BackgroundUnknown means the terminal did not say.
+const (
+	BackgroundUnknown Background = ""
+	BackgroundDark    Background = "dark"
+	BackgroundLight   Background = "light"
+)
+
+// BackgroundQueryTimeout bounds how long DetectBackground waits for the terminal to answer.
+// Local terminals answer in a few milliseconds; the margin is for SSH sessions.
+const BackgroundQueryTimeout = 250 * time.Millisecond
+
+// backgroundQuery asks for the background color (OSC 11), then for the device attributes
+// (DA1). Every terminal answers DA1, and answers in order, so its reply ends the wait
+// even when the terminal ignores OSC 11.
+const backgroundQuery = "\x1b]11;?\x1b\\" + "\x1b[c"
+
+var (
+	// backgroundReplyRegex matches the OSC 11 reply: rgb:RRRR/GGGG/BBBB with 1-4 hex digits
+	// per channel, terminated by BEL or ST.
+	backgroundReplyRegex = regexp.MustCompile(`\x1b\]11;rgba?:([0-9a-fA-F]{1,4})/([0-9a-fA-F]{1,4})/([0-9a-fA-F]{1,4})`)
+
+	// deviceAttributesRegex matches the DA1 reply.
+	deviceAttributesRegex = regexp.MustCompile(`\x1b\[\?[0-9;]*c`)
+)
+
+// DetectBackground asks the terminal for its background color (OSC 11) and reports whether
+// it is dark or light. When the terminal cannot be asked (no terminal, Windows consoles) or
+// does not answer within timeout, it falls back to COLORFGBG, which rxvt, Konsole, and
+// others set. Each call asks again, so a terminal switched to a light profile is noticed.
+func DetectBackground(timeout time.Duration) Background {
+	if IsTTY() && !isDumbTerminal() {
+		if reply, err := queryBackground(timeout); err == nil {
+			if background := parseBackgroundReply(reply); background != BackgroundUnknown {
+				return background
+			}
+		}
+	}
+	return backgroundFromEnv(os.Getenv("COLORFGBG"))
+}
+
+// parseBackgroundReply returns the background of an OSC 11 reply by the relative luminance
+// of the color (ITU-R BT.709), or BackgroundUnknown when there is no reply.
+func parseBackgroundReply(reply []byte) Background {
+	m := backgroundReplyRegex.FindSubmatch(reply)
+	if m == nil {
+		return BackgroundUnknown
+	}
+	var channels [3]float64
+	for i, hex := range m[1:] {
+		value, err := strconv.ParseUint(string(hex), 16, 16)
+		if err != nil {
+			return BackgroundUnknown
+		}
+		// Scale to 0-1 by the digits given: "f" and "ffff" are both full intensity
+		channels[i] = float64(value) / float64(uint64(1)<<(4*len(hex))-1)
+	}
+	luminance := 0.2126*channels[0] + 0.7152*channels[1] + 0.0722*channels[2]
+	if luminance < 0.5 {
+		return BackgroundDark
+	}
+	return BackgroundLight
+}
+
+// backgroundFromEnv returns the background of a COLORFGBG value ("fg;bg" or
+// "fg;default;bg"), where bg is an ANSI color: 0-6 and 8 are dark, 7 and 9-15 light.
+func backgroundFromEnv(value string) Background {
+	if value == "" {
+		return BackgroundUnknown
+	}
+	fields := strings.Split(value, ";")
+	bg, err := strconv.Atoi(fields[len(fields)-1])
+	switch {
+	case err != nil || bg < 0 || bg > 15:
+		return BackgroundUnknown
+	case bg <= 6 || bg == 8:
+		return BackgroundDark
+	default:
+		return BackgroundLight
+	}
+}
+
+// repliesComplete reports whether the terminal has answered the query: DA1 comes last.
+func repliesComplete(reply []byte) bool {
+	return deviceAttributesRegex.Match(reply)
+}
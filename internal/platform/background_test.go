@@ -0,0 +1,57 @@
+package platform
+
+import "testing"
+
+// TestParseBackgroundReply tests telling dark from light backgrounds in OSC 11 replies
+func TestParseBackgroundReply(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		want  Background
+	}{
+		{"black with ST", "\x1b]11;rgb:0000/0000/0000\x1b\\\x1b[?62;22c", BackgroundDark},
+		{"white with BEL", "\x1b]11;rgb:ffff/ffff/ffff\a", BackgroundLight},
+		{"solarized dark", "\x1b]11;rgb:0000/2b2b/3636\x1b\\", BackgroundDark},
+		{"solarized light", "\x1b]11;rgb:fdfd/f6f6/e3e3\x1b\\", BackgroundLight},
+		{"two digits", "\x1b]11;rgb:ee/ee/ee\a", BackgroundLight},
+		{"rgba", "\x1b]11;rgba:1e1e/1e1e/1e1e/ffff\a", BackgroundDark},
+		{"only DA1", "\x1b[?1;2c", BackgroundUnknown},
+		{"empty", "", BackgroundUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseBackgroundReply([]byte(tt.reply)); got != tt.want {
+				t.Errorf("parseBackgroundReply(%q) = %q, want %q", tt.reply, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestBackgroundFromEnv tests reading the background from COLORFGBG
+func TestBackgroundFromEnv(t *testing.T) {
+	tests := map[string]Background{
+		"15;0":         BackgroundDark,
+		"0;15":         BackgroundLight,
+		"7;default;8":  BackgroundDark,
+		"0;default;7":  BackgroundLight,
+		"":             BackgroundUnknown,
+		"15;default":   BackgroundUnknown,
+		"15;42":        BackgroundUnknown,
+		"not a number": BackgroundUnknown,
+	}
+	for value, want := range tests {
+		if got := backgroundFromEnv(value); got != want {
+			t.Errorf("backgroundFromEnv(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+// TestRepliesComplete tests waiting for the DA1 reply that ends the query
+func TestRepliesComplete(t *testing.T) {
+	if repliesComplete([]byte("\x1b]11;rgb:0000/0000/0000\x1b\\")) {
+		t.Error("repliesComplete() = true before the DA1 reply")
+	}
+	if !repliesComplete([]byte("\x1b]11;rgb:0000/0000/0000\x1b\\\x1b[?65;1;9c")) {
+		t.Error("repliesComplete() = false after the DA1 reply")
+	}
+}
@@ -0,0 +1,81 @@
+//go:build !windows
+
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+// queryBackground writes the background query to the controlling terminal in raw mode and
+// returns what it answers within timeout. The reply is read with select, which works on
+// terminals on every Unix (kqueue and poll do not on macOS).
+func queryBackground(timeout time.Duration) ([]byte, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer tty.Close()
+	conn, err := tty.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+
+	var reply []byte
+	var queryErr error
+	err = conn.Control(func(fd uintptr) {
+		reply, queryErr = queryTerminal(int(fd), timeout)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reply, queryErr
+}
+
+// queryTerminal sends backgroundQuery on fd and reads until the DA1 reply or the deadline.
+func queryTerminal(fd int, timeout time.Duration) ([]byte, error) {
+	// Raw mode keeps the reply from being echoed and hands it over without waiting for Enter
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = term.Restore(fd, state) }()
+
+	if _, err := unix.Write(fd, []byte(backgroundQuery)); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	var reply []byte
+	buf := make([]byte, 256)
+	for !repliesComplete(reply) {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return reply, errors.New("terminal did not answer the background query")
+		}
+		var readable unix.FdSet
+		readable.Set(fd)
+		tv := unix.NsecToTimeval(remaining.Nanoseconds())
+		n, err := unix.Select(fd+1, &readable, nil, nil, &tv)
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		if err != nil {
+			return reply, fmt.Errorf("waiting for the terminal: %w", err)
+		}
+		if n == 0 {
+			continue // Timed out; the loop ends on the deadline check
+		}
+		n, err = unix.Read(fd, buf)
+		if err != nil && !errors.Is(err, unix.EAGAIN) && !errors.Is(err, unix.EINTR) {
+			return reply, err
+		}
+		reply = append(reply, buf[:max(n, 0)]...)
+	}
+	return reply, nil
+}
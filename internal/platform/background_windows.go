@@ -0,0 +1,14 @@
+//go:build windows
+
+package platform
+
+import (
+	"errors"
+	"time"
+)
+
+// queryBackground is not supported on Windows: console input arrives as key events rather
+// than bytes, so DetectBackground relies on COLORFGBG there.
+func queryBackground(_ time.Duration) ([]byte, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,63 @@
+package platform
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrNoClipboard is returned when no clipboard tool is available (e.g., over SSH or in a
+// container); terminals that support OSC 52 can still take the text (see OSC52).
+var ErrNoClipboard = errors.New("no clipboard tool found (pbcopy, clip, wl-copy, xclip, or xsel)")
+
+// clipboardCommands returns the commands that copy their stdin to the clipboard on an
+// OS, in order of preference.
+func clipboardCommands(goos string, getenv func(string) string) [][]string {
+	switch goos {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		// clip.exe reads the console code page; PowerShell keeps Unicode intact
+		return [][]string{
+			{"powershell", "-NoProfile", "-NonInteractive", "-Command", "[Console]::InputEncoding = [Text.Encoding]::UTF8; Set-Clipboard -Value ([Console]::In.ReadToEnd())"},
+			{"clip"},
+		}
+	}
+	var commands [][]string
+	if getenv("WAYLAND_DISPLAY") != "" {
+		commands = append(commands, []string{"wl-copy"})
+	}
+	if getenv("DISPLAY") != "" {
+		commands = append(commands, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--input"})
+	}
+	// WSL shares the Windows clipboard
+	return append(commands, []string{"clip.exe"})
+}
+
+// CopyToClipboard puts text on the system clipboard with the first available clipboard
+// tool. It returns ErrNoClipboard when none is installed.
+func CopyToClipboard(text string) error {
+	for _, command := range clipboardCommands(runtime.GOOS, os.Getenv) {
+		path, err := exec.LookPath(command[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, command[1:]...) // #nosec G204 -- fixed clipboard tools
+		cmd.Stdin = strings.NewReader(text)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s failed: %w: %s", command[0], err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+	return ErrNoClipboard
+}
+
+// OSC52 returns the escape sequence that asks the terminal to put text on the clipboard.
+// It works over SSH, but terminals may ignore it or limit its length.
+func OSC52(text string) string {
+	return "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+}
@@ -0,0 +1,43 @@
+package platform
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestClipboardCommands tests the clipboard tools tried on each OS
+func TestClipboardCommands(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+	names := func(commands [][]string) string {
+		var list []string
+		for _, c := range commands {
+			list = append(list, c[0])
+		}
+		return strings.Join(list, ",")
+	}
+
+	tests := []struct {
+		goos string
+		env  map[string]string
+		want string
+	}{
+		{"darwin", nil, "pbcopy"},
+		{"windows", nil, "powershell,clip"},
+		{"linux", map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"}, "wl-copy,xclip,xsel,clip.exe"},
+		{"linux", nil, "clip.exe"},
+	}
+	for _, tt := range tests {
+		if got := names(clipboardCommands(tt.goos, env(tt.env))); got != tt.want {
+			t.Errorf("clipboardCommands(%s, %v) = %s, want %s", tt.goos, tt.env, got, tt.want)
+		}
+	}
+}
+
+// TestOSC52 tests the terminal clipboard escape sequence
+func TestOSC52(t *testing.T) {
+	if got := OSC52("hi"); got != "\x1b]52;c;aGk=\a" {
+		t.Errorf("OSC52(hi) = %q", got)
+	}
+}
@@ -0,0 +1,43 @@
+package platform
+
+// ConsoleMode describes how the console attached to stdout handles escape sequences.
+//
+// Unix terminals always interpret them. Windows consoles do once virtual terminal (VT)
+// processing is enabled, which EnableVirtualTerminal does on Windows 10 and later; the
+// legacy console host (conhost) of older Windows versions cannot, so the UI degrades there:
+// no colors (escape sequences would be printed literally) and no alternate screen.
+type ConsoleMode struct {
+	Console bool // Stdout is a console rather than a file or pipe
+	VT      bool // The console interprets VT escape sequences
+}
+
+// Legacy reports whether stdout is a console that cannot interpret escape sequences.
+func (m ConsoleMode) Legacy() bool {
+	return m.Console && !m.VT
+}
+
+// AltScreen reports whether the UI can switch to the alternate screen, restoring the
+// console's contents on exit.
+func (m ConsoleMode) AltScreen() bool {
+	return m.Console && m.VT
+}
+
+// ClampColorDepth limits a detected color depth to what the console can display.
+func (m ConsoleMode) ClampColorDepth(depth ColorDepth) ColorDepth {
+	if m.Legacy() {
+		return ColorNone
+	}
+	return depth
+}
+
+// String returns a description for logs.
+func (m ConsoleMode) String() string {
+	switch {
+	case !m.Console:
+		return "not a console"
+	case m.VT:
+		return "vt"
+	default:
+		return "legacy"
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package platform
+
+import "os"
+
+// CurrentConsoleMode returns the mode of the terminal attached to stdout. Unix terminals
+// always interpret escape sequences.
+func CurrentConsoleMode() ConsoleMode {
+	console := IsTerminal(int(os.Stdout.Fd()))
+	return ConsoleMode{Console: console, VT: console}
+}
+
+// EnableVirtualTerminal returns the mode of the terminal attached to stdout; there is
+// nothing to enable outside Windows.
+func EnableVirtualTerminal() (ConsoleMode, func()) {
+	return CurrentConsoleMode(), func() {}
+}
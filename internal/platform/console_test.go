@@ -0,0 +1,34 @@
+package platform
+
+import "testing"
+
+// TestConsoleMode tests what each console mode allows
+func TestConsoleMode(t *testing.T) {
+	tests := []struct {
+		mode      ConsoleMode
+		legacy    bool
+		altScreen bool
+		depth     ColorDepth
+		str       string
+	}{
+		{ConsoleMode{}, false, false, ColorTrueColor, "not a console"},
+		{ConsoleMode{Console: true, VT: true}, false, true, ColorTrueColor, "vt"},
+		{ConsoleMode{Console: true}, true, false, ColorNone, "legacy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			if got := tt.mode.Legacy(); got != tt.legacy {
+				t.Errorf("Legacy() = %v, want %v", got, tt.legacy)
+			}
+			if got := tt.mode.AltScreen(); got != tt.altScreen {
+				t.Errorf("AltScreen() = %v, want %v", got, tt.altScreen)
+			}
+			if got := tt.mode.ClampColorDepth(ColorTrueColor); got != tt.depth {
+				t.Errorf("ClampColorDepth(ColorTrueColor) = %v, want %v", got, tt.depth)
+			}
+			if got := tt.mode.String(); got != tt.str {
+				t.Errorf("String() = %q, want %q", got, tt.str)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+//go:build windows
+
+package platform
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// CurrentConsoleMode returns the mode of the console attached to stdout without changing it.
+func CurrentConsoleMode() ConsoleMode {
+	return consoleMode(windows.Handle(os.Stdout.Fd()))
+}
+
+// consoleMode returns the mode of a console output handle.
+func consoleMode(h windows.Handle) ConsoleMode {
+	var mode uint32
+	if err := windows.GetConsoleMode(h, &mode); err != nil {
+		return ConsoleMode{}
+	}
+	return ConsoleMode{Console: true, VT: mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0}
+}
+
+// EnableVirtualTerminal turns on VT processing for the console attached to stdout, and VT
+// input for the one attached to stdin, so escape sequences are interpreted and keys arrive
+// as sequences. It returns the resulting mode and a function that restores the previous
+// modes. On a legacy console the modes are left alone and the mode reports no VT support.
+func EnableVirtualTerminal() (ConsoleMode, func()) {
+	out := windows.Handle(os.Stdout.Fd())
+	in := windows.Handle(os.Stdin.Fd())
+	return enableVirtualTerminal(out, in)
+}
+
+// enableVirtualTerminal enables VT processing on an output and input console handle.
+func enableVirtualTerminal(out, in windows.Handle) (ConsoleMode, func()) {
+	var outMode uint32
+	if err := windows.GetConsoleMode(out, &outMode); err != nil {
+		return ConsoleMode{}, func() {}
+	}
+
+	var restore []func()
+	if outMode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING == 0 {
+		// Legacy conhost rejects the flag; the console keeps working without it
+		if err := windows.SetConsoleMode(out, outMode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING); err != nil {
+			return ConsoleMode{Console: true}, func() {}
+		}
+		restore = append(restore, func() { _ = windows.SetConsoleMode(out, outMode) })
+	}
+
+	var inMode uint32
+	if err := windows.GetConsoleMode(in, &inMode); err == nil && inMode&windows.ENABLE_VIRTUAL_TERMINAL_INPUT == 0 {
+		if windows.SetConsoleMode(in, inMode|windows.ENABLE_VIRTUAL_TERMINAL_INPUT) == nil {
+			restore = append(restore, func() { _ = windows.SetConsoleMode(in, inMode) })
+		}
+	}
+
+	return ConsoleMode{Console: true, VT: true}, func() {
+		for _, r := range restore {
+			r()
+		}
+	}
+}
@@ -0,0 +1,90 @@
+//go:build windows
+
+package platform
+
+import (
+	"os"
+	"testing"
+
+	"golang.org/x/sys/windows"
+)
+
+// openConsole opens the process's console buffer (CONOUT$ or CONIN$), skipping the test
+// when the process has no console (e.g., some CI agents).
+func openConsole(t *testing.T, name string) windows.Handle {
+	t.Helper()
+	path, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := windows.CreateFile(path, windows.GENERIC_READ|windows.GENERIC_WRITE,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE, nil, windows.OPEN_EXISTING, 0, 0)
+	if err != nil {
+		t.Skipf("no console: %v", err)
+	}
+	t.Cleanup(func() { _ = windows.CloseHandle(h) })
+	return h
+}
+
+// TestEnableVirtualTerminal tests enabling VT processing and restoring the previous modes
+func TestEnableVirtualTerminal(t *testing.T) {
+	out := openConsole(t, "CONOUT$")
+	in := openConsole(t, "CONIN$")
+
+	var outBefore, inBefore uint32
+	if err := windows.GetConsoleMode(out, &outBefore); err != nil {
+		t.Fatal(err)
+	}
+	if err := windows.GetConsoleMode(in, &inBefore); err != nil {
+		t.Fatal(err)
+	}
+	// Start without VT so enabling and restoring are both observable
+	if err := windows.SetConsoleMode(out, outBefore&^windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = windows.SetConsoleMode(out, outBefore) }()
+	withoutVT := outBefore &^ windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING
+
+	mode, restore := enableVirtualTerminal(out, in)
+	if !mode.Console {
+		t.Fatalf("enableVirtualTerminal() = %+v, want a console", mode)
+	}
+	if !mode.VT {
+		t.Skip("legacy console: VT processing is not supported")
+	}
+	if got := consoleMode(out); !got.VT {
+		t.Errorf("consoleMode() after enabling = %+v, want VT", got)
+	}
+	var inMode uint32
+	if err := windows.GetConsoleMode(in, &inMode); err != nil || inMode&windows.ENABLE_VIRTUAL_TERMINAL_INPUT == 0 {
+		t.Errorf("input mode = %#x, %v; want ENABLE_VIRTUAL_TERMINAL_INPUT", inMode, err)
+	}
+
+	restore()
+	var outAfter uint32
+	if err := windows.GetConsoleMode(out, &outAfter); err != nil || outAfter != withoutVT {
+		t.Errorf("output mode after restore = %#x, %v; want %#x", outAfter, err, withoutVT)
+	}
+	if err := windows.GetConsoleMode(in, &inMode); err != nil || inMode != inBefore {
+		t.Errorf("input mode after restore = %#x, %v; want %#x", inMode, err, inBefore)
+	}
+}
+
+// TestEnableVirtualTerminalNotConsole tests handles that are files rather than consoles
+func TestEnableVirtualTerminalNotConsole(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	h := windows.Handle(f.Fd())
+
+	mode, restore := enableVirtualTerminal(h, h)
+	restore()
+	if mode != (ConsoleMode{}) {
+		t.Errorf("enableVirtualTerminal(file) = %+v, want no console", mode)
+	}
+	if got := consoleMode(h); got != (ConsoleMode{}) {
+		t.Errorf("consoleMode(file) = %+v, want no console", got)
+	}
+}
@@ -0,0 +1,54 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LowDiskSpace is the free space below which a partition counts as nearly full before a
+// large download.
+const LowDiskSpace = 512 << 20
+
+// DiskSpaceError reports a partition with less free space than a download needs.
+type DiskSpaceError struct {
+	Path string // The directory the download goes to
+	Free uint64
+	Need uint64
+}
+
+func (e *DiskSpaceError) Error() string {
+	return fmt.Sprintf("only %d MB free on the disk holding %s (about %d MB needed)", e.Free>>20, e.Path, e.Need>>20)
+}
+
+// FreeSpace returns the bytes available to the current user on the partition holding
+// path. The path need not exist yet; its nearest existing parent is measured.
+func FreeSpace(path string) (uint64, error) {
+	dir := filepath.Clean(path)
+	for {
+		_, err := os.Stat(dir)
+		if err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return 0, err
+		}
+		dir = parent
+	}
+	return freeSpace(dir)
+}
+
+// CheckFreeSpace returns a *DiskSpaceError when the partition holding path has less than
+// need bytes free, or less than LowDiskSpace when need is 0. Free space that cannot be
+// measured is not reported: the download then fails on its own if the disk fills up.
+func CheckFreeSpace(path string, need uint64) error {
+	if need == 0 {
+		need = LowDiskSpace
+	}
+	free, err := FreeSpace(path)
+	if err != nil || free >= need {
+		return nil
+	}
+	return &DiskSpaceError{Path: path, Free: free, Need: need}
+}
@@ -0,0 +1,34 @@
+package platform
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+// TestFreeSpace tests measuring a directory that does not exist yet through its parent
+func TestFreeSpace(t *testing.T) {
+	dir := t.TempDir()
+	free, err := FreeSpace(filepath.Join(dir, "not", "yet", "created"))
+	if err != nil {
+		t.Fatalf("FreeSpace() error = %v", err)
+	}
+	if free == 0 {
+		t.Error("FreeSpace() = 0, want the space of the temp directory's partition")
+	}
+}
+
+// TestCheckFreeSpace tests reporting a partition with less space than needed
+func TestCheckFreeSpace(t *testing.T) {
+	dir := t.TempDir()
+	if err := CheckFreeSpace(dir, 1); err != nil {
+		t.Errorf("CheckFreeSpace(1 byte) = %v, want nil", err)
+	}
+
+	err := CheckFreeSpace(dir, math.MaxUint64)
+	var spaceErr *DiskSpaceError
+	if !errors.As(err, &spaceErr) || spaceErr.Path != dir || spaceErr.Need != math.MaxUint64 {
+		t.Fatalf("CheckFreeSpace(max) = %v, want a DiskSpaceError for %s", err, dir)
+	}
+}
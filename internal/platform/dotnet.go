@@ -1,18 +1,28 @@
 package platform
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 )
 
+// DotnetAvailable reports whether the dotnet CLI is in PATH. Without it lazynuget is
+// read-only: projects are still read and edited directly and feeds queried over HTTP, but
+// actions that run dotnet (restore, tools, workloads) are unavailable.
+func DotnetAvailable() bool {
+	_, err := resolveExecutable("dotnet")
+	return err == nil
+}
+
 // ValidateDotnetCLI checks if the dotnet CLI is available and functional.
 // Returns an error with helpful installation instructions if dotnet is not found or not working.
 // See: T091, FR-031
-func ValidateDotnetCLI() error {
+func ValidateDotnetCLI(ctx context.Context) error {
 	spawner := NewProcessSpawner()
 
 	// Try to run dotnet --version
-	result, err := spawner.Run("dotnet", []string{"--version"}, "", nil)
+	result, err := spawner.RunContext(ctx, "dotnet", []string{"--version"}, "", nil)
 	if err != nil {
 		// dotnet not found or failed to execute
 		return fmt.Errorf("dotnet CLI not found in PATH\n\n"+
@@ -45,3 +55,43 @@ func ValidateDotnetCLI() error {
 	// Success - dotnet is available and working
 	return nil
 }
+
+// DotnetSDKVersion returns the version of the .NET SDK that dotnet resolves in the
+// current directory, as printed by dotnet --version (a global.json may pin it).
+func DotnetSDKVersion(ctx context.Context) (string, error) {
+	result, err := NewProcessSpawner().RunContext(ctx, "dotnet", []string{"--version"}, "", nil)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("dotnet --version exited with %d: %s", result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+	return strings.TrimSpace(result.Stdout), nil
+}
+
+// DotnetSDKDir returns the directory of the .NET SDK that dotnet resolves in dir (e.g.,
+// /usr/share/dotnet/sdk/8.0.400), from dotnet --version there and dotnet --list-sdks.
+func DotnetSDKDir(ctx context.Context, dir string) (string, error) {
+	spawner := NewProcessSpawner()
+	result, err := spawner.RunContext(ctx, "dotnet", []string{"--version"}, dir, nil)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("dotnet --version exited with %d: %s", result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+	version := strings.TrimSpace(result.Stdout)
+
+	result, err = spawner.RunContext(ctx, "dotnet", []string{"--list-sdks"}, dir, nil)
+	if err != nil {
+		return "", err
+	}
+	// Lines are "8.0.400 [/usr/share/dotnet/sdk]"
+	for _, line := range strings.Split(result.Stdout, "\n") {
+		v, base, ok := strings.Cut(strings.TrimSpace(line), " [")
+		if ok && v == version {
+			return filepath.Join(strings.TrimSuffix(base, "]"), version), nil
+		}
+	}
+	return "", fmt.Errorf("dotnet --list-sdks does not list SDK %s", version)
+}
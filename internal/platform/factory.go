@@ -1,6 +1,9 @@
 package platform
 
-import "sync"
+import (
+	"context"
+	"sync"
+)
 
 var (
 	// Singleton instance
@@ -18,3 +21,25 @@ func New() (PlatformInfo, error) {
 
 	return instance, initErr
 }
+
+// NewContext is New, but stops waiting when ctx is done
+// Detection runs external commands (uname, sw_vers) the first time; if ctx ends first,
+// it keeps running in the background and later calls get its result
+func NewContext(ctx context.Context) (PlatformInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = New()
+	}()
+
+	select {
+	case <-done:
+		return instance, initErr
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
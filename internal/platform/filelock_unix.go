@@ -0,0 +1,19 @@
+//go:build !windows
+
+package platform
+
+import (
+	"os"
+	"syscall"
+)
+
+// LockFile blocks until it holds an exclusive lock on f. The lock is released when f is
+// closed or the process exits, so a crashed process never leaves the file locked.
+func LockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
+
+// UnlockFile releases the lock LockFile took.
+func UnlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,51 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrNoFileManager is returned when no tool to open a folder is available (e.g., over SSH
+// or in a container).
+var ErrNoFileManager = errors.New("no file manager launcher found (open, explorer, xdg-open, or wslview)")
+
+// fileManagerCommands returns the commands that open a folder in the file manager on an
+// OS, in order of preference.
+func fileManagerCommands(goos string, getenv func(string) string) [][]string {
+	switch goos {
+	case "darwin":
+		return [][]string{{"open"}}
+	case "windows":
+		return [][]string{{"explorer"}}
+	}
+	var commands [][]string
+	if getenv("DISPLAY") != "" || getenv("WAYLAND_DISPLAY") != "" {
+		commands = append(commands, []string{"xdg-open"})
+	}
+	// WSL opens folders in Windows Explorer
+	return append(commands, []string{"wslview"})
+}
+
+// OpenInFileManager shows the folder at path in the desktop file manager with the first
+// available launcher. It returns ErrNoFileManager when none is installed.
+func OpenInFileManager(path string) error {
+	for _, command := range fileManagerCommands(runtime.GOOS, os.Getenv) {
+		executable, err := exec.LookPath(command[0])
+		if err != nil {
+			continue
+		}
+		args := append(command[1:], path)
+		cmd := exec.Command(executable, args...) // #nosec G204 -- fixed launchers; path is an argument
+		output, err := cmd.CombinedOutput()
+		// explorer exits with 1 even when it opened the folder
+		if err != nil && command[0] != "explorer" {
+			return fmt.Errorf("%s failed: %w: %s", command[0], err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+	return ErrNoFileManager
+}
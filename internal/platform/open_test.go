@@ -0,0 +1,30 @@
+package platform
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestFileManagerCommands tests the folder launchers tried on each OS
+func TestFileManagerCommands(t *testing.T) {
+	tests := []struct {
+		goos string
+		env  map[string]string
+		want []string
+	}{
+		{"darwin", nil, []string{"open"}},
+		{"windows", nil, []string{"explorer"}},
+		{"linux", map[string]string{"DISPLAY": ":0"}, []string{"xdg-open", "wslview"}},
+		{"freebsd", map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, []string{"xdg-open", "wslview"}},
+		{"linux", nil, []string{"wslview"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, c := range fileManagerCommands(tt.goos, func(key string) string { return tt.env[key] }) {
+			got = append(got, c[0])
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("fileManagerCommands(%s, %v) = %v, want %v", tt.goos, tt.env, got, tt.want)
+		}
+	}
+}
@@ -20,6 +20,13 @@ type PathResolver interface {
 	// Linux: $XDG_CACHE_HOME/lazynuget or ~/.cache/lazynuget
 	CacheDir() (string, error)
 
+	// DataDir returns the platform-appropriate directory for data lazynuget keeps
+	// between runs that is neither settings nor disposable (e.g., stores and logs)
+	// Windows: %APPDATA%\lazynuget\data
+	// macOS: ~/Library/Application Support/lazynuget/data
+	// Linux: $XDG_DATA_HOME/lazynuget or ~/.local/share/lazynuget
+	DataDir() (string, error)
+
 	// Normalize converts path to platform-native format
 	// - Windows: backslashes, drive letters uppercase
 	// - Unix: forward slashes
@@ -69,6 +76,11 @@ func (p *pathResolver) CacheDir() (string, error) {
 	return getCacheDir()
 }
 
+// DataDir returns the platform-appropriate data directory
+func (p *pathResolver) DataDir() (string, error) {
+	return getDataDir()
+}
+
 // Normalize converts path to platform-native format
 func (p *pathResolver) Normalize(path string) string {
 	return normalize(path)
@@ -34,3 +34,18 @@ func getCacheDir() (string, error) {
 
 	return filepath.Join(homeDir, "Library", "Caches", "lazynuget"), nil
 }
+
+// getDataDir returns the macOS data directory: ~/Library/Application Support/lazynuget/data
+// Application Support holds both settings and data on macOS, so data gets its own folder
+func getDataDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", &PathError{
+			Op:   "DataDir",
+			Path: "~",
+			Err:  "failed to get home directory: " + err.Error(),
+		}
+	}
+
+	return filepath.Join(homeDir, "Library", "Application Support", "lazynuget", "data"), nil
+}
@@ -0,0 +1,25 @@
+//go:build darwin
+
+package platform
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestGetDataDir_Darwin tests that data lives in its own folder under Application Support
+// and ignores XDG_DATA_HOME
+func TestGetDataDir_Darwin(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "/srv/data")
+
+	got, err := getDataDir()
+	if err != nil {
+		t.Fatalf("getDataDir() error = %v", err)
+	}
+	want := filepath.Join(home, "Library", "Application Support", "lazynuget", "data")
+	if got != want {
+		t.Errorf("getDataDir() = %q, want %q", got, want)
+	}
+}
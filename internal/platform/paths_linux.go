@@ -48,3 +48,24 @@ func getCacheDir() (string, error) {
 
 	return filepath.Join(homeDir, ".cache", "lazynuget"), nil
 }
+
+// getDataDir returns the Linux data directory following XDG Base Directory Specification
+// Returns $XDG_DATA_HOME/lazynuget or ~/.local/share/lazynuget
+func getDataDir() (string, error) {
+	// Check XDG_DATA_HOME first; the spec says to ignore relative paths
+	if xdgData := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(xdgData) {
+		return filepath.Join(xdgData, "lazynuget"), nil
+	}
+
+	// Fall back to ~/.local/share
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", &PathError{
+			Op:   "DataDir",
+			Path: "~",
+			Err:  "failed to get home directory: " + err.Error(),
+		}
+	}
+
+	return filepath.Join(homeDir, ".local", "share", "lazynuget"), nil
+}
@@ -0,0 +1,37 @@
+//go:build linux
+
+package platform
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestGetDataDir_Linux tests that XDG_DATA_HOME is honored when absolute and that
+// ~/.local/share is used otherwise
+func TestGetDataDir_Linux(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := []struct {
+		name    string
+		xdgData string
+		want    string
+	}{
+		{"XDG_DATA_HOME set", "/srv/data", "/srv/data/lazynuget"},
+		{"XDG_DATA_HOME unset", "", filepath.Join(home, ".local", "share", "lazynuget")},
+		{"XDG_DATA_HOME relative", "data", filepath.Join(home, ".local", "share", "lazynuget")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_DATA_HOME", tt.xdgData)
+			got, err := getDataDir()
+			if err != nil {
+				t.Fatalf("getDataDir() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("getDataDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
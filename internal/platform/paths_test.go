@@ -1,6 +1,8 @@
 package platform
 
 import (
+	"os"
+	"runtime"
 	"testing"
 )
 
@@ -112,6 +114,46 @@ func TestConfigDirAndCacheDirAreDifferent(t *testing.T) {
 	}
 }
 
+// TestDataDir tests DataDir() and that EnsureDir creates it owner-only
+func TestDataDir(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("APPDATA", t.TempDir())
+
+	platformInfo, err := New()
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	pathResolver, err := NewPathResolver(platformInfo)
+	if err != nil {
+		t.Fatalf("NewPathResolver() failed: %v", err)
+	}
+
+	dataDir, err := pathResolver.DataDir()
+	if err != nil {
+		t.Fatalf("DataDir() failed: %v", err)
+	}
+	if !pathResolver.IsAbsolute(dataDir) || !contains(pathResolver.Normalize(dataDir), "lazynuget") {
+		t.Errorf("DataDir() = %q, want an absolute path containing 'lazynuget'", dataDir)
+	}
+	configDir, _ := pathResolver.ConfigDir()
+	cacheDir, _ := pathResolver.CacheDir()
+	if dataDir == configDir || dataDir == cacheDir {
+		t.Errorf("DataDir() = %q, want it apart from the config (%q) and cache (%q) directories", dataDir, configDir, cacheDir)
+	}
+
+	if err := pathResolver.EnsureDir(dataDir); err != nil {
+		t.Fatalf("EnsureDir(DataDir()) failed: %v", err)
+	}
+	info, err := os.Stat(dataDir)
+	if err != nil || !info.IsDir() {
+		t.Fatalf("EnsureDir(DataDir()) left no directory: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0o700 {
+		t.Errorf("DataDir() permissions = %o, want 700", info.Mode().Perm())
+	}
+}
+
 // contains is a simple substring check helper
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
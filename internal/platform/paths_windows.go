@@ -37,6 +37,22 @@ func getCacheDir() (string, error) {
 	return filepath.Join(localAppData, "lazynuget"), nil
 }
 
+// getDataDir returns the Windows data directory: %APPDATA%\lazynuget\data
+// Data roams with the profile like the config; %LOCALAPPDATA%\lazynuget is the
+// cache, which users may clear
+func getDataDir() (string, error) {
+	appData := os.Getenv("APPDATA")
+	if appData == "" {
+		return "", &PathError{
+			Op:   "DataDir",
+			Path: "%APPDATA%",
+			Err:  "APPDATA environment variable not set",
+		}
+	}
+
+	return filepath.Join(appData, "lazynuget", "data"), nil
+}
+
 // normalize converts path to Windows-native format:
 // - Converts forward slashes to backslashes
 // - Uppercases drive letters
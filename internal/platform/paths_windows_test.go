@@ -442,3 +442,22 @@ func TestEnvVarPrecedence_Windows(t *testing.T) {
 		t.Errorf("CacheDir() = %q incorrectly used XDG_CACHE_HOME instead of LOCALAPPDATA", cacheDir)
 	}
 }
+
+// TestGetDataDir_Windows tests that data lives under APPDATA, not XDG_DATA_HOME
+func TestGetDataDir_Windows(t *testing.T) {
+	t.Setenv("APPDATA", "C:\\Users\\Test\\AppData\\Roaming")
+	t.Setenv("XDG_DATA_HOME", "/home/user/.local/share")
+
+	got, err := getDataDir()
+	if err != nil {
+		t.Fatalf("getDataDir() error = %v", err)
+	}
+	if want := "C:\\Users\\Test\\AppData\\Roaming\\lazynuget\\data"; got != want {
+		t.Errorf("getDataDir() = %q, want %q", got, want)
+	}
+
+	t.Setenv("APPDATA", "")
+	if _, err := getDataDir(); err == nil {
+		t.Error("getDataDir() without APPDATA succeeded, want an error")
+	}
+}
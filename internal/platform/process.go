@@ -2,11 +2,16 @@ package platform
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/metrics"
 )
 
 // ProcessResult contains the output and exit status of a process
@@ -28,6 +33,10 @@ type ProcessSpawner interface {
 	// - Exit code extraction
 	Run(executable string, args []string, workingDir string, env map[string]string) (ProcessResult, error)
 
+	// RunContext is Run, but kills the process when ctx is done and returns ctx's error
+	// along with whatever output was captured
+	RunContext(ctx context.Context, executable string, args []string, workingDir string, env map[string]string) (ProcessResult, error)
+
 	// SetEncoding overrides automatic encoding detection
 	// Use "utf-8", "windows-1252", "iso-8859-1", etc.
 	// Pass empty string to re-enable auto-detection
@@ -54,9 +63,22 @@ func (p *processSpawner) SetEncoding(encoding string) {
 	p.encoding = encoding
 }
 
+// waitDelay bounds how long a cancelled process may hold its output pipes open; dotnet
+// leaves msbuild node processes running that would otherwise keep Wait from returning.
+const waitDelay = 5 * time.Second
+
 // Run executes a process and waits for completion
 // See: T084, T086, FR-030, FR-031
 func (p *processSpawner) Run(executable string, args []string, workingDir string, env map[string]string) (ProcessResult, error) {
+	return p.RunContext(context.Background(), executable, args, workingDir, env)
+}
+
+// RunContext executes a process and waits for completion or for ctx to be done
+func (p *processSpawner) RunContext(ctx context.Context, executable string, args []string, workingDir string, env map[string]string) (ProcessResult, error) {
+	if err := ctx.Err(); err != nil {
+		return ProcessResult{}, err
+	}
+
 	// Validate inputs
 	if executable == "" {
 		return ProcessResult{}, fmt.Errorf("executable cannot be empty")
@@ -77,7 +99,8 @@ func (p *processSpawner) Run(executable string, args []string, workingDir string
 
 	// Create command
 	// G204: This is safe - execPath comes from resolveExecutable which validates the path
-	cmd := exec.Command(execPath, args...) // #nosec G204
+	cmd := exec.CommandContext(ctx, execPath, args...) // #nosec G204
+	cmd.WaitDelay = waitDelay
 
 	// Set working directory if specified
 	if workingDir != "" {
@@ -119,7 +142,9 @@ func (p *processSpawner) Run(executable string, args []string, workingDir string
 	cmd.Stderr = &stderrBuf
 
 	// Execute command
+	start := time.Now()
 	execErr := cmd.Run()
+	metrics.ObserveOperation("process:"+filepath.Base(executable), time.Since(start))
 
 	// Determine encoding to use
 	encoding := p.encoding
@@ -132,6 +157,11 @@ func (p *processSpawner) Run(executable string, args []string, workingDir string
 	stdout := decodeBytes(stdoutBuf.Bytes(), encoding)
 	stderr := decodeBytes(stderrBuf.Bytes(), encoding)
 
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ProcessResult{Stdout: stdout, Stderr: stderr, ExitCode: -1},
+			fmt.Errorf("%s was stopped: %w", filepath.Base(executable), ctxErr)
+	}
+
 	// Extract exit code
 	exitCode := 0
 	if execErr != nil {
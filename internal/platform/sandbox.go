@@ -0,0 +1,69 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrSandboxUnsupported is returned when the platform has no usable sandbox launcher.
+var ErrSandboxUnsupported = errors.New("sandboxed execution is not supported on this platform")
+
+// SandboxOptions restricts what a sandboxed hook or custom command may do.
+// The filesystem is readable everywhere but writable only under WorkingDir and WritablePaths.
+type SandboxOptions struct {
+	WorkingDir    string   // Always writable (typically the project or solution directory)
+	WritablePaths []string // Additional writable directories (e.g., the NuGet package cache)
+	AllowNetwork  bool     // Network access is denied unless set
+}
+
+// SandboxCommand wraps executable and args in the platform sandbox launcher
+// (bwrap on Linux, sandbox-exec on macOS). The returned command line is passed to
+// ProcessSpawner.Run unchanged.
+// Returns ErrSandboxUnsupported when no launcher is available, so callers can refuse to
+// run rather than silently running unrestricted.
+func SandboxCommand(executable string, args []string, opts SandboxOptions) (string, []string, error) {
+	if executable == "" {
+		return "", nil, fmt.Errorf("executable cannot be empty")
+	}
+
+	writable, err := absolutePaths(append([]string{opts.WorkingDir}, opts.WritablePaths...))
+	if err != nil {
+		return "", nil, err
+	}
+	opts.WritablePaths = writable
+	opts.WorkingDir = ""
+
+	return sandboxCommandPlatform(executable, args, opts)
+}
+
+// SandboxAvailable reports whether SandboxCommand can wrap commands on this machine.
+func SandboxAvailable() bool {
+	_, err := sandboxLauncher()
+	return err == nil
+}
+
+// absolutePaths cleans and absolutizes paths, expanding a leading ~ and dropping empty entries.
+func absolutePaths(paths []string) ([]string, error) {
+	result := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		if p == "~" || strings.HasPrefix(p, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, fmt.Errorf("cannot expand %q: %w", p, err)
+			}
+			p = filepath.Join(home, strings.TrimPrefix(p, "~"))
+		}
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sandbox path %q: %w", p, err)
+		}
+		result = append(result, abs)
+	}
+	return result, nil
+}
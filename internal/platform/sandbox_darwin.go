@@ -0,0 +1,46 @@
+//go:build darwin
+
+package platform
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// sandboxLauncher locates sandbox-exec.
+func sandboxLauncher() (string, error) {
+	path, err := exec.LookPath("sandbox-exec")
+	if err != nil {
+		return "", fmt.Errorf("%w: sandbox-exec not found", ErrSandboxUnsupported)
+	}
+	return path, nil
+}
+
+// sandboxCommandPlatform builds a sandbox-exec command line with an inline Seatbelt profile.
+func sandboxCommandPlatform(executable string, args []string, opts SandboxOptions) (string, []string, error) {
+	launcher, err := sandboxLauncher()
+	if err != nil {
+		return "", nil, err
+	}
+
+	seatbeltArgs := []string{"-p", seatbeltProfile(opts), executable}
+	seatbeltArgs = append(seatbeltArgs, args...)
+	return launcher, seatbeltArgs, nil
+}
+
+// seatbeltProfile returns a Seatbelt profile that denies writes outside the writable
+// paths (plus temp and device files) and denies network access unless allowed.
+func seatbeltProfile(opts SandboxOptions) string {
+	var sb strings.Builder
+	sb.WriteString("(version 1)\n(allow default)\n(deny file-write*)\n")
+	sb.WriteString("(allow file-write* (subpath \"/private/tmp\") (subpath \"/private/var/folders\") (subpath \"/dev\"))\n")
+	for _, p := range opts.WritablePaths {
+		sb.WriteString("(allow file-write* (subpath " + strconv.Quote(p) + "))\n")
+	}
+	if !opts.AllowNetwork {
+		sb.WriteString("(deny network*)\n")
+	}
+	return sb.String()
+}
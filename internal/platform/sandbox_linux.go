@@ -0,0 +1,51 @@
+//go:build linux
+
+package platform
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// sandboxLauncher locates bubblewrap.
+func sandboxLauncher() (string, error) {
+	path, err := exec.LookPath("bwrap")
+	if err != nil {
+		return "", fmt.Errorf("%w: bwrap (bubblewrap) not found in PATH", ErrSandboxUnsupported)
+	}
+	return path, nil
+}
+
+// sandboxCommandPlatform builds a bubblewrap command line: the root filesystem is mounted
+// read-only, writable paths are bind-mounted read-write, and the network namespace is
+// unshared unless network access is allowed.
+func sandboxCommandPlatform(executable string, args []string, opts SandboxOptions) (string, []string, error) {
+	launcher, err := sandboxLauncher()
+	if err != nil {
+		return "", nil, err
+	}
+
+	bwrapArgs := bwrapArguments(opts)
+	bwrapArgs = append(bwrapArgs, "--", executable)
+	bwrapArgs = append(bwrapArgs, args...)
+	return launcher, bwrapArgs, nil
+}
+
+// bwrapArguments returns the bubblewrap options for opts (without the command).
+func bwrapArguments(opts SandboxOptions) []string {
+	bwrapArgs := []string{
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+		"--die-with-parent",
+		"--new-session",
+	}
+	for _, p := range opts.WritablePaths {
+		bwrapArgs = append(bwrapArgs, "--bind", p, p)
+	}
+	if !opts.AllowNetwork {
+		bwrapArgs = append(bwrapArgs, "--unshare-net")
+	}
+	return bwrapArgs
+}
@@ -0,0 +1,30 @@
+//go:build linux
+
+package platform
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestBwrapArguments tests bubblewrap option generation
+func TestBwrapArguments(t *testing.T) {
+	args := bwrapArguments(SandboxOptions{WritablePaths: []string{"/work"}})
+	joined := strings.Join(args, " ")
+
+	if !strings.Contains(joined, "--ro-bind / /") {
+		t.Errorf("root filesystem should be read-only: %v", args)
+	}
+	if !strings.Contains(joined, "--bind /work /work") {
+		t.Errorf("writable path should be bind-mounted: %v", args)
+	}
+	if !slices.Contains(args, "--unshare-net") {
+		t.Errorf("network should be unshared by default: %v", args)
+	}
+
+	args = bwrapArguments(SandboxOptions{AllowNetwork: true})
+	if slices.Contains(args, "--unshare-net") {
+		t.Errorf("network should be shared when allowed: %v", args)
+	}
+}
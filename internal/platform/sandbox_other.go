@@ -0,0 +1,15 @@
+//go:build !linux && !darwin
+
+package platform
+
+// sandboxLauncher reports that no sandbox launcher is available.
+// Windows AppContainer isolation requires launching through the Win32 API rather than a
+// wrapper executable and is not implemented yet.
+func sandboxLauncher() (string, error) {
+	return "", ErrSandboxUnsupported
+}
+
+// sandboxCommandPlatform always fails on platforms without a sandbox launcher.
+func sandboxCommandPlatform(_ string, _ []string, _ SandboxOptions) (string, []string, error) {
+	return "", nil, ErrSandboxUnsupported
+}
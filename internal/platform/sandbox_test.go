@@ -0,0 +1,57 @@
+package platform
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSandboxCommand tests wrapping a command in the platform sandbox
+func TestSandboxCommand(t *testing.T) {
+	workDir := t.TempDir()
+
+	launcher, args, err := SandboxCommand("dotnet", []string{"restore"}, SandboxOptions{WorkingDir: workDir})
+	if !SandboxAvailable() {
+		if !errors.Is(err, ErrSandboxUnsupported) {
+			t.Errorf("SandboxCommand() error = %v, want ErrSandboxUnsupported", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("SandboxCommand() error = %v", err)
+	}
+
+	if launcher == "" || launcher == "dotnet" {
+		t.Errorf("launcher = %q, want sandbox launcher", launcher)
+	}
+	if n := len(args); n < 2 || args[n-2] != "dotnet" || args[n-1] != "restore" {
+		t.Errorf("args = %v, want wrapped command at the end", args)
+	}
+}
+
+// TestSandboxCommandEmptyExecutable tests input validation
+func TestSandboxCommandEmptyExecutable(t *testing.T) {
+	if _, _, err := SandboxCommand("", nil, SandboxOptions{}); err == nil {
+		t.Error("SandboxCommand() expected error for empty executable")
+	}
+}
+
+// TestAbsolutePaths tests sandbox path normalization
+func TestAbsolutePaths(t *testing.T) {
+	got, err := absolutePaths([]string{"", "relative", filepath.Join(t.TempDir(), "x", ".."), "~/cache"})
+	if err != nil {
+		t.Fatalf("absolutePaths() error = %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("absolutePaths() = %v, want 3 entries (empty dropped)", got)
+	}
+	if home, err := os.UserHomeDir(); err == nil && got[2] != filepath.Join(home, "cache") {
+		t.Errorf("~ not expanded: %q", got[2])
+	}
+	for _, p := range got {
+		if !filepath.IsAbs(p) || filepath.Clean(p) != p {
+			t.Errorf("path %q is not clean and absolute", p)
+		}
+	}
+}
@@ -3,7 +3,10 @@ package platform
 import (
 	"fmt"
 	"os"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 
 	"golang.org/x/term"
 )
@@ -22,8 +25,9 @@ type TerminalCapabilities interface {
 	// IsTTY returns true if stdout is connected to an interactive terminal
 	IsTTY() bool
 
-	// WatchResize registers a callback for terminal resize events
-	// Returns a stop function to unregister the watcher
+	// WatchResize registers a callback for terminal resize events, called with the size
+	// from TerminalSize (sizes below the minimum are reported as they are)
+	// Returns a stop function to unregister the callback
 	WatchResize(callback func(width, height int)) (stop func())
 }
 
@@ -51,41 +55,68 @@ func (t *terminalCapabilities) SupportsUnicode() bool {
 	return t.supportsUnicode
 }
 
+// Terminal size limits. Below the minimum the UI cannot lay out its panels and shows a
+// "terminal too small" screen instead; the maximum bounds buffer sizes (T063, T064, FR-015).
+const (
+	MinTerminalWidth  = 40
+	MinTerminalHeight = 10
+	MaxTerminalWidth  = 500
+	MaxTerminalHeight = 200
+)
+
 // GetSize returns terminal dimensions (width, height in characters)
+// When stdout is not a terminal (e.g., output captured by a render test), COLUMNS and
+// LINES give the size if both are set.
 // Validates and clamps dimensions to safe ranges:
 // - Minimum: 40x10 (below this, TUI is unusable)
 // - Maximum: 500x200 (prevents buffer overflow issues)
+// Use TerminalSize to tell whether the terminal is below the minimum.
 // See: T063, T064, FR-015
 func (t *terminalCapabilities) GetSize() (width, height int, err error) {
+	width, height, err = TerminalSize()
+	if err != nil {
+		return width, height, err
+	}
+	return max(width, MinTerminalWidth), max(height, MinTerminalHeight), nil
+}
+
+// TerminalSize returns the size of the terminal attached to stdout, limited to the maximum
+// but not raised to the minimum, so callers can show a "terminal too small" screen. It
+// falls back to COLUMNS and LINES like GetSize, and to 80x24 with an error.
+func TerminalSize() (width, height int, err error) {
 	// Try to get size from stdout
 	width, height, err = term.GetSize(int(os.Stdout.Fd()))
 	if err != nil {
-		// Fall back to default size if detection fails
-		return 80, 24, fmt.Errorf("failed to get terminal size: %w (using defaults)", err)
+		var envErr error
+		if width, height, envErr = sizeFromEnv(); envErr != nil {
+			// Fall back to default size if detection fails
+			return 80, 24, fmt.Errorf("failed to get terminal size: %w (using defaults)", err)
+		}
 	}
+	width, height = limitSize(width, height)
+	return width, height, nil
+}
+
+// TooSmall reports whether a terminal size is below the minimum the UI needs.
+func TooSmall(width, height int) bool {
+	return width < MinTerminalWidth || height < MinTerminalHeight
+}
 
-	// Validate and clamp dimensions (T063, T064)
-	const (
-		MinWidth  = 40
-		MinHeight = 10
-		MaxWidth  = 500
-		MaxHeight = 200
-	)
+// limitSize clamps a size to the maximum, and to at least one cell.
+func limitSize(width, height int) (int, int) {
+	return min(max(width, 1), MaxTerminalWidth), min(max(height, 1), MaxTerminalHeight)
+}
 
-	// Clamp width to valid range
-	if width < MinWidth {
-		width = MinWidth
-	} else if width > MaxWidth {
-		width = MaxWidth
+// sizeFromEnv returns the size set in COLUMNS and LINES.
+func sizeFromEnv() (width, height int, err error) {
+	width, err = strconv.Atoi(os.Getenv("COLUMNS"))
+	if err != nil {
+		return 0, 0, err
 	}
-
-	// Clamp height to valid range
-	if height < MinHeight {
-		height = MinHeight
-	} else if height > MaxHeight {
-		height = MaxHeight
+	height, err = strconv.Atoi(os.Getenv("LINES"))
+	if err != nil {
+		return 0, 0, err
 	}
-
 	return width, height, nil
 }
 
@@ -98,12 +129,66 @@ func (t *terminalCapabilities) IsTTY() bool {
 // Platform-specific implementation:
 // - Unix: Uses SIGWINCH signal to detect resize events
 // - Windows: Polls terminal size every 500ms
-// Returns a stop function to unregister the watcher
+// Returns a stop function to unregister the callback; the watcher stops with the last one
 // See: T067, FR-016
 func (t *terminalCapabilities) WatchResize(callback func(width, height int)) (stop func()) {
 	return watchResize(callback)
 }
 
+// resize holds the callbacks of WatchResize and the platform watcher that serves them,
+// which runs while there is at least one callback.
+var resize struct {
+	watcher   *resizeWatcher
+	callbacks map[int]func(width, height int)
+	next      int
+	mu        sync.Mutex
+}
+
+// watchResize registers a callback, starting the platform watcher for the first one.
+func watchResize(callback func(width, height int)) (stop func()) {
+	resize.mu.Lock()
+	defer resize.mu.Unlock()
+
+	if resize.callbacks == nil {
+		resize.callbacks = make(map[int]func(width, height int))
+	}
+	id := resize.next
+	resize.next++
+	resize.callbacks[id] = callback
+	if resize.watcher == nil {
+		resize.watcher = newResizeWatcher(notifyResize)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			resize.mu.Lock()
+			defer resize.mu.Unlock()
+			delete(resize.callbacks, id)
+			if len(resize.callbacks) == 0 && resize.watcher != nil {
+				resize.watcher.stop()
+				resize.watcher = nil
+			}
+		})
+	}
+}
+
+// notifyResize calls every registered callback with a new size.
+func notifyResize(width, height int) {
+	resize.mu.Lock()
+	callbacks := make([]func(int, int), 0, len(resize.callbacks))
+	for _, callback := range resize.callbacks {
+		callbacks = append(callbacks, callback)
+	}
+	resize.mu.Unlock()
+
+	for _, callback := range callbacks {
+		if callback != nil {
+			callback(width, height)
+		}
+	}
+}
+
 // detectColorDepth detects terminal color support level
 func detectColorDepth() ColorDepth {
 	// Check NO_COLOR environment variable (https://no-color.org/)
@@ -116,6 +201,11 @@ func detectColorDepth() ColorDepth {
 		return ColorNone
 	}
 
+	// Legacy Windows consoles would print escape sequences literally
+	if CurrentConsoleMode().Legacy() {
+		return ColorNone
+	}
+
 	// Get TERM environment variable
 	term := os.Getenv("TERM")
 
@@ -134,6 +224,14 @@ func detectColorDepth() ColorDepth {
 		return ColorBasic16
 	}
 
+	// Windows consoles do not set TERM; Windows Terminal sets WT_SESSION
+	if term == "" && runtime.GOOS == "windows" {
+		if os.Getenv("WT_SESSION") != "" {
+			return ColorTrueColor
+		}
+		return ColorExtended256
+	}
+
 	// Check for dumb terminal
 	if term == "dumb" || term == "" {
 		return ColorNone
@@ -220,6 +220,31 @@ func TestGetSize(t *testing.T) {
 	t.Logf("Terminal size: %dx%d", width, height)
 }
 
+// TestGetSizeFromEnv tests COLUMNS and LINES when stdout is not a terminal
+func TestGetSizeFromEnv(t *testing.T) {
+	if IsTerminal(int(os.Stdout.Fd())) {
+		t.Skip("stdout is a terminal")
+	}
+
+	t.Setenv("COLUMNS", "100")
+	t.Setenv("LINES", "30")
+	width, height, err := NewTerminalCapabilities().GetSize()
+	if err != nil || width != 100 || height != 30 {
+		t.Errorf("GetSize() = (%d, %d, %v), want (100, 30, nil)", width, height, err)
+	}
+
+	t.Setenv("COLUMNS", "10")
+	width, height, err = NewTerminalCapabilities().GetSize()
+	if err != nil || width != 40 || height != 30 {
+		t.Errorf("GetSize() with COLUMNS=10 = (%d, %d, %v), want (40, 30, nil)", width, height, err)
+	}
+
+	t.Setenv("LINES", "")
+	if width, height, err = NewTerminalCapabilities().GetSize(); err == nil || width != 80 || height != 24 {
+		t.Errorf("GetSize() without LINES = (%d, %d, %v), want the defaults and an error", width, height, err)
+	}
+}
+
 // TestGetSize_Clamping tests dimension validation and clamping (T063, T064)
 func TestGetSize_Clamping(t *testing.T) {
 	// Note: This test verifies the clamping logic, but can't easily test with actual
@@ -278,3 +303,83 @@ func TestWatchResize(t *testing.T) {
 	// This is expected behavior for now
 	t.Logf("Resize callback called: %v (expected: false for stub)", called)
 }
+
+// TestTooSmall tests the minimum terminal size check
+func TestTooSmall(t *testing.T) {
+	tests := []struct {
+		width, height int
+		want          bool
+	}{
+		{MinTerminalWidth, MinTerminalHeight, false},
+		{120, 40, false},
+		{MinTerminalWidth - 1, MinTerminalHeight, true},
+		{MinTerminalWidth, MinTerminalHeight - 1, true},
+		{1, 1, true},
+	}
+	for _, tt := range tests {
+		if got := TooSmall(tt.width, tt.height); got != tt.want {
+			t.Errorf("TooSmall(%d, %d) = %v, want %v", tt.width, tt.height, got, tt.want)
+		}
+	}
+}
+
+// TestLimitSize tests that sizes are limited to the maximum but not raised to the minimum
+func TestLimitSize(t *testing.T) {
+	tests := []struct {
+		width, height int
+		wantW, wantH  int
+	}{
+		{30, 8, 30, 8},
+		{0, -1, 1, 1},
+		{1000, 500, MaxTerminalWidth, MaxTerminalHeight},
+	}
+	for _, tt := range tests {
+		w, h := limitSize(tt.width, tt.height)
+		if w != tt.wantW || h != tt.wantH {
+			t.Errorf("limitSize(%d, %d) = %dx%d, want %dx%d", tt.width, tt.height, w, h, tt.wantW, tt.wantH)
+		}
+	}
+}
+
+// TestWatchResizeCallbacks tests that every registered callback receives resize events and
+// that the shared watcher stops with the last callback
+func TestWatchResizeCallbacks(t *testing.T) {
+	var first, second [][2]int
+	stopFirst := watchResize(func(w, h int) { first = append(first, [2]int{w, h}) })
+	stopSecond := watchResize(func(w, h int) { second = append(second, [2]int{w, h}) })
+
+	notifyResize(30, 8)
+	if len(first) != 1 || len(second) != 1 || first[0] != [2]int{30, 8} {
+		t.Fatalf("after resize: first = %v, second = %v, want one 30x8 event each", first, second)
+	}
+
+	stopFirst()
+	stopFirst() // stopping twice is harmless
+	notifyResize(100, 30)
+	if len(first) != 1 || len(second) != 2 {
+		t.Errorf("after stopping first: first = %v, second = %v", first, second)
+	}
+	resize.mu.Lock()
+	running := resize.watcher != nil
+	resize.mu.Unlock()
+	if !running {
+		t.Error("watcher stopped while a callback is still registered")
+	}
+
+	stopSecond()
+	resize.mu.Lock()
+	running = resize.watcher != nil
+	resize.mu.Unlock()
+	if running {
+		t.Error("watcher still running after the last callback stopped")
+	}
+
+	// The watcher restarts for new callbacks
+	var third int
+	stopThird := watchResize(func(_, _ int) { third++ })
+	defer stopThird()
+	notifyResize(80, 24)
+	if third != 1 {
+		t.Errorf("callback registered after restart got %d events, want 1", third)
+	}
+}
@@ -10,26 +10,26 @@ import (
 	"golang.org/x/term"
 )
 
-// resizeWatcher manages terminal resize event notifications on Windows
+// resizeWatcher detects terminal resize events on Windows
 // Uses polling to detect terminal resize events since Windows doesn't have SIGWINCH
 // See: T066, T067, T068, FR-016
 type resizeWatcher struct {
-	callbacks  []func(width, height int)
-	mu         sync.RWMutex
 	stopChan   chan struct{}
-	stopped    bool
+	notify     func(width, height int)
 	lastWidth  int
 	lastHeight int
+	stopOnce   sync.Once
 }
 
-// newResizeWatcher creates a new resize watcher for Windows
-func newResizeWatcher() *resizeWatcher {
+// newResizeWatcher starts a resize watcher that calls notify with each new size
+func newResizeWatcher(notify func(width, height int)) *resizeWatcher {
 	// Get initial size
 	width, height, _ := term.GetSize(int(os.Stdout.Fd()))
+	width, height = limitSize(width, height)
 
 	w := &resizeWatcher{
-		callbacks:  make([]func(width, height int), 0),
 		stopChan:   make(chan struct{}),
+		notify:     notify,
 		lastWidth:  width,
 		lastHeight: height,
 	}
@@ -40,7 +40,7 @@ func newResizeWatcher() *resizeWatcher {
 	return w
 }
 
-// pollForResize polls terminal size and notifies callbacks on change
+// pollForResize polls terminal size and reports changes
 func (w *resizeWatcher) pollForResize() {
 	ticker := time.NewTicker(500 * time.Millisecond) // Poll every 500ms
 	defer ticker.Stop()
@@ -53,49 +53,12 @@ func (w *resizeWatcher) pollForResize() {
 			if err != nil {
 				continue
 			}
+			width, height = limitSize(width, height)
 
-			// Apply clamping (same as GetSize method)
-			const (
-				MinWidth  = 40
-				MinHeight = 10
-				MaxWidth  = 500
-				MaxHeight = 200
-			)
-
-			if width < MinWidth {
-				width = MinWidth
-			} else if width > MaxWidth {
-				width = MaxWidth
-			}
-
-			if height < MinHeight {
-				height = MinHeight
-			} else if height > MaxHeight {
-				height = MaxHeight
-			}
-
-			// Check if size changed
-			w.mu.RLock()
-			changed := width != w.lastWidth || height != w.lastHeight
-			w.mu.RUnlock()
-
-			if changed {
-				// Update last known size
-				w.mu.Lock()
-				w.lastWidth = width
-				w.lastHeight = height
-
-				// Copy callbacks while holding lock
-				callbacks := make([]func(int, int), len(w.callbacks))
-				copy(callbacks, w.callbacks)
-				w.mu.Unlock()
-
-				// Notify all registered callbacks
-				for _, callback := range callbacks {
-					if callback != nil {
-						callback(width, height)
-					}
-				}
+			// Only the polling goroutine reads and writes the last size
+			if width != w.lastWidth || height != w.lastHeight {
+				w.lastWidth, w.lastHeight = width, height
+				w.notify(width, height)
 			}
 
 		case <-w.stopChan:
@@ -104,50 +67,9 @@ func (w *resizeWatcher) pollForResize() {
 	}
 }
 
-// addCallback registers a callback for resize events
-func (w *resizeWatcher) addCallback(callback func(width, height int)) {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-
-	w.callbacks = append(w.callbacks, callback)
-}
-
 // stop stops the resize watcher and cleans up resources
 func (w *resizeWatcher) stop() {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-
-	if !w.stopped {
-		w.stopped = true
+	w.stopOnce.Do(func() {
 		close(w.stopChan)
-	}
-}
-
-// Global resize watcher instance (singleton)
-var (
-	globalWatcher     *resizeWatcher
-	globalWatcherOnce sync.Once
-	globalWatcherMu   sync.Mutex
-)
-
-// watchResize implements the platform-specific resize watching for Windows
-// Returns a stop function that unregisters the callback
-func watchResize(callback func(width, height int)) (stop func()) {
-	globalWatcherMu.Lock()
-	defer globalWatcherMu.Unlock()
-
-	// Initialize global watcher once
-	globalWatcherOnce.Do(func() {
-		globalWatcher = newResizeWatcher()
 	})
-
-	// Add callback to watcher
-	globalWatcher.addCallback(callback)
-
-	// Return stop function
-	return func() {
-		// For now, stopping removes the entire watcher
-		// A more sophisticated implementation would remove just this callback
-		globalWatcher.stop()
-	}
 }
@@ -0,0 +1,91 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+)
+
+// Manager starts the configured plugins on first use and keeps them running until Close.
+type Manager struct {
+	plugins []config.Plugin
+	opts    Options
+	clients map[string]*Client
+}
+
+// NewManager returns a manager for the plugins and sandbox settings of cfg.
+func NewManager(cfg *config.Config, opts Options) *Manager {
+	opts.Sandbox = cfg.Sandbox
+	return &Manager{plugins: cfg.Plugins, opts: opts, clients: make(map[string]*Client)}
+}
+
+// Names returns the configured plugin names in config order.
+func (m *Manager) Names() []string {
+	names := make([]string, len(m.plugins))
+	for i, p := range m.plugins {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// Client returns the running plugin with the given name (case-insensitive), starting it
+// if needed.
+func (m *Manager) Client(ctx context.Context, name string) (*Client, error) {
+	key := strings.ToLower(name)
+	if c, ok := m.clients[key]; ok {
+		return c, nil
+	}
+	for _, p := range m.plugins {
+		if strings.ToLower(p.Name) == key {
+			c, err := Start(ctx, p, m.opts)
+			if err != nil {
+				return nil, err
+			}
+			m.clients[key] = c
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("no plugin named %q is configured", name)
+}
+
+// Annotate collects the annotations of every plugin that annotates packages, keyed by
+// lowercase package ID, in the order the plugins are configured. Plugins that fail are
+// skipped; their errors are returned together.
+func (m *Manager) Annotate(ctx context.Context, pc Context, packages []PackageRef) (map[string][]string, error) {
+	annotations := make(map[string][]string)
+	var errs []error
+	for _, p := range m.plugins {
+		c, err := m.Client(ctx, p.Name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		list, err := c.Annotate(ctx, pc, packages)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, a := range list {
+			if a.Text != "" {
+				key := strings.ToLower(a.ID)
+				annotations[key] = append(annotations[key], a.Text)
+			}
+		}
+	}
+	return annotations, errors.Join(errs...)
+}
+
+// Close shuts down the running plugins.
+func (m *Manager) Close() error {
+	var errs []error
+	for key, c := range m.clients {
+		if err := c.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s: %w", c.Name, err))
+		}
+		delete(m.clients, key)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,396 @@
+// Package plugin runs external programs that extend LazyNuGet with commands, panels, and
+// package annotations, so third parties (e.g., for an internal registry) need not fork it.
+//
+// A plugin is any executable listed in the plugins section of the config file. It is
+// started on first use and speaks JSON-RPC 2.0 over its stdin and stdout, one message per
+// line; what it writes to stderr goes to the log. After starting a plugin, LazyNuGet sends:
+//
+//	initialize         {protocolVersion}                  -> Manifest
+//	command/run        {command, args, context}           -> {output}
+//	panel/render       {panel, context, width, height}    -> {lines}
+//	packages/annotate  {context, packages: [{id, version}]} -> {annotations: [{id, text}]}
+//	shutdown           (notification, then stdin is closed)
+//
+// The manifest declares what the plugin contributes; only declared commands and panels are
+// requested, and packages/annotate only when the manifest sets annotations. A plugin may
+// send {"method": "log", "params": {"level", "message"}} notifications at any time.
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/jsonrpc"
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// ProtocolVersion is the plugin protocol version sent in initialize. Plugins that do not
+// support it should fail initialize with an error naming the versions they support.
+const ProtocolVersion = 1
+
+// DefaultTimeout bounds requests to plugins that do not set a timeout.
+const DefaultTimeout = 10 * time.Second
+
+// Manifest is what a plugin contributes, returned by initialize.
+type Manifest struct {
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	Commands    []Command `json:"commands"`
+	Panels      []Panel   `json:"panels"`
+	Annotations bool      `json:"annotations"` // The plugin annotates packages in package lists
+}
+
+// Command is a plugin command, shown as a menu entry.
+type Command struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// Panel is a plugin panel: text the plugin renders for the current selection.
+type Panel struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// Context is the selection a request applies to. Fields are empty when nothing of that
+// kind is selected.
+type Context struct {
+	Workspace  string              `json:"workspace"`         // Repository or working directory
+	Project    string              `json:"project,omitempty"` // Project file path
+	Package    string              `json:"package,omitempty"`
+	Version    string              `json:"version,omitempty"`
+	Background platform.Background `json:"background,omitempty"` // Terminal background (dark or light), to match colors to; empty when unknown
+}
+
+// PackageRef is a package in a list to annotate.
+type PackageRef struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+}
+
+// Annotation is a short note a plugin attaches to a package (e.g., registry metadata).
+type Annotation struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// Client is a running plugin.
+type Client struct {
+	Name     string
+	Manifest Manifest
+
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	responses chan jsonrpc.Message
+	done      chan struct{} // Closed when the plugin's stdout closes
+	timeout   time.Duration
+	logger    config.Logger
+
+	mu     sync.Mutex // Serializes requests
+	nextID int
+	errMu  sync.Mutex // Guards stderr
+	stderr []byte
+}
+
+// Options configure how plugins are started.
+type Options struct {
+	Sandbox config.SandboxConfig
+	Logger  config.Logger // Optional; receives plugin stderr and log notifications
+	WorkDir string        // Where plugins run (the workspace root)
+}
+
+// Start starts a plugin and initializes it.
+func Start(ctx context.Context, p config.Plugin, opts Options) (*Client, error) {
+	executable, args := p.Command, p.Args
+	sandboxed := opts.Sandbox.Enabled
+	if p.Sandbox != nil {
+		sandboxed = *p.Sandbox
+	}
+	if sandboxed {
+		var err error
+		executable, args, err = platform.SandboxCommand(executable, args, platform.SandboxOptions{
+			WorkingDir:    opts.WorkDir,
+			WritablePaths: opts.Sandbox.WritablePaths,
+			AllowNetwork:  opts.Sandbox.AllowNetwork,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", p.Name, err)
+		}
+	}
+
+	c := &Client{
+		Name:      p.Name,
+		responses: make(chan jsonrpc.Message, 1),
+		done:      make(chan struct{}),
+		timeout:   p.Timeout,
+		logger:    opts.Logger,
+	}
+	if c.timeout <= 0 {
+		c.timeout = DefaultTimeout
+	}
+
+	// #nosec G204 -- plugins are configured by the user
+	c.cmd = exec.Command(executable, args...)
+	c.cmd.Dir = opts.WorkDir
+	c.cmd.Stderr = &stderrWriter{c: c}
+	stdin, err := c.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := c.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start plugin %s: %w", p.Name, err)
+	}
+	c.stdin = stdin
+	go c.read(stdout)
+
+	if err := c.call(ctx, "initialize", map[string]int{"protocolVersion": ProtocolVersion}, &c.Manifest); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// read dispatches messages from the plugin until its stdout closes.
+func (c *Client) read(stdout io.Reader) {
+	defer close(c.done)
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64<<10), jsonrpc.MaxMessageSize)
+	for scanner.Scan() {
+		var msg jsonrpc.Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			c.log("warn", fmt.Sprintf("invalid message: %v", err))
+			continue
+		}
+		if msg.ID == nil {
+			c.notification(msg)
+			continue
+		}
+		c.responses <- msg
+	}
+}
+
+// notification handles a notification from the plugin.
+func (c *Client) notification(msg jsonrpc.Message) {
+	if msg.Method != "log" {
+		return
+	}
+	var params struct {
+		Level   string `json:"level"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(msg.Params, &params) == nil {
+		c.log(params.Level, params.Message)
+	}
+}
+
+// log passes a message from the plugin to the logger.
+func (c *Client) log(level, msg string) {
+	if c.logger == nil {
+		return
+	}
+	switch level {
+	case "debug":
+		c.logger.Debug("plugin %s: %s", c.Name, msg)
+	case "warn":
+		c.logger.Warn("plugin %s: %s", c.Name, msg)
+	case "error":
+		c.logger.Error("plugin %s: %s", c.Name, msg)
+	default:
+		c.logger.Info("plugin %s: %s", c.Name, msg)
+	}
+}
+
+// call sends a request and decodes the result. A plugin that does not answer within its
+// timeout is stopped, since a late response would be taken for the next request's.
+func (c *Client) call(ctx context.Context, method string, params, result any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	id := c.nextID
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	request, err := json.Marshal(jsonrpc.Message{JSONRPC: jsonrpc.Version, ID: &id, Method: method, Params: data})
+	if err != nil {
+		return err
+	}
+	if _, err := c.stdin.Write(append(request, '\n')); err != nil {
+		return fmt.Errorf("plugin %s: %s: %w%s", c.Name, method, err, c.stderrTail())
+	}
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case msg := <-c.responses:
+			if *msg.ID != id {
+				continue // A response to a request that timed out
+			}
+			if msg.Error != nil {
+				return fmt.Errorf("plugin %s: %s: %w", c.Name, method, msg.Error)
+			}
+			if result == nil {
+				return nil
+			}
+			if err := json.Unmarshal(msg.Result, result); err != nil {
+				return fmt.Errorf("plugin %s: %s: invalid result: %w", c.Name, method, err)
+			}
+			return nil
+		case <-c.done:
+			return fmt.Errorf("plugin %s exited during %s%s", c.Name, method, c.stderrTail())
+		case <-timer.C:
+			_ = c.cmd.Process.Kill()
+			return fmt.Errorf("plugin %s did not answer %s within %s and was stopped", c.Name, method, c.timeout)
+		case <-ctx.Done():
+			_ = c.cmd.Process.Kill()
+			return ctx.Err()
+		}
+	}
+}
+
+// stderrTail returns the end of the plugin's stderr, for errors.
+func (c *Client) stderrTail() string {
+	c.errMu.Lock()
+	defer c.errMu.Unlock()
+	s := bytes.TrimSpace(c.stderr)
+	if len(s) == 0 {
+		return ""
+	}
+	return ": " + string(s)
+}
+
+// HasCommand reports whether the plugin declared a command.
+func (c *Client) HasCommand(id string) bool {
+	for _, command := range c.Manifest.Commands {
+		if command.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPanel reports whether the plugin declared a panel.
+func (c *Client) HasPanel(id string) bool {
+	for _, panel := range c.Manifest.Panels {
+		if panel.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// RunCommand runs a command the plugin declared and returns the text it output.
+func (c *Client) RunCommand(ctx context.Context, command string, args []string, pc Context) (string, error) {
+	if !c.HasCommand(command) {
+		return "", fmt.Errorf("plugin %s has no command %q", c.Name, command)
+	}
+	var result struct {
+		Output string `json:"output"`
+	}
+	params := map[string]any{"command": command, "args": args, "context": pc}
+	if err := c.call(ctx, "command/run", params, &result); err != nil {
+		return "", err
+	}
+	return result.Output, nil
+}
+
+// RenderPanel returns the lines of a panel the plugin declared, for a panel of the given
+// size in cells.
+func (c *Client) RenderPanel(ctx context.Context, panel string, pc Context, width, height int) ([]string, error) {
+	if !c.HasPanel(panel) {
+		return nil, fmt.Errorf("plugin %s has no panel %q", c.Name, panel)
+	}
+	var result struct {
+		Lines []string `json:"lines"`
+	}
+	params := map[string]any{"panel": panel, "context": pc, "width": width, "height": height}
+	if err := c.call(ctx, "panel/render", params, &result); err != nil {
+		return nil, err
+	}
+	return result.Lines, nil
+}
+
+// Annotate returns the plugin's annotations for packages, or none when the plugin does not
+// annotate packages.
+func (c *Client) Annotate(ctx context.Context, pc Context, packages []PackageRef) ([]Annotation, error) {
+	if !c.Manifest.Annotations || len(packages) == 0 {
+		return nil, nil
+	}
+	var result struct {
+		Annotations []Annotation `json:"annotations"`
+	}
+	params := map[string]any{"context": pc, "packages": packages}
+	if err := c.call(ctx, "packages/annotate", params, &result); err != nil {
+		return nil, err
+	}
+	return result.Annotations, nil
+}
+
+// Close asks the plugin to shut down and waits briefly for it to exit before stopping it.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	shutdown, _ := json.Marshal(jsonrpc.Message{JSONRPC: jsonrpc.Version, Method: "shutdown"})
+	_, _ = c.stdin.Write(append(shutdown, '\n'))
+	_ = c.stdin.Close()
+	c.mu.Unlock()
+
+	select {
+	case <-c.done:
+	case <-time.After(2 * time.Second):
+		_ = c.cmd.Process.Kill()
+	}
+	err := c.cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && !exitErr.Exited() {
+		return nil // Stopped by a signal (killed above, or after a timeout)
+	}
+	return err
+}
+
+// maxStderr bounds the plugin stderr kept for error messages.
+const maxStderr = 4 << 10
+
+// stderrWriter logs a plugin's stderr line by line and keeps its end for error messages.
+type stderrWriter struct {
+	c       *Client
+	partial []byte
+}
+
+// Write implements io.Writer.
+func (w *stderrWriter) Write(p []byte) (int, error) {
+	w.c.errMu.Lock()
+	defer w.c.errMu.Unlock()
+
+	w.c.stderr = append(w.c.stderr, p...)
+	if len(w.c.stderr) > maxStderr {
+		w.c.stderr = w.c.stderr[len(w.c.stderr)-maxStderr:]
+	}
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		if line := bytes.TrimSpace(w.partial[:i]); len(line) > 0 {
+			w.c.log("info", string(line))
+		}
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
@@ -0,0 +1,218 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/jsonrpc"
+)
+
+// helperEnv makes the test binary act as a plugin (see TestHelperPlugin).
+const helperEnv = "LAZYNUGET_TEST_PLUGIN"
+
+// TestHelperPlugin is not a test: it is the plugin the other tests start, by running the
+// test binary with helperEnv set.
+func TestHelperPlugin(t *testing.T) {
+	if os.Getenv(helperEnv) == "" {
+		t.Skip("helper process")
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	respond := func(id *int, result any, rpcErr *jsonrpc.Error) {
+		data, _ := json.Marshal(result)
+		out, _ := json.Marshal(jsonrpc.Message{JSONRPC: jsonrpc.Version, ID: id, Result: data, Error: rpcErr})
+		fmt.Println(string(out))
+	}
+	fmt.Fprintln(os.Stderr, "helper plugin started")
+	for scanner.Scan() {
+		var msg jsonrpc.Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			os.Exit(2)
+		}
+		var params struct {
+			Command  string       `json:"command"`
+			Args     []string     `json:"args"`
+			Context  Context      `json:"context"`
+			Width    int          `json:"width"`
+			Packages []PackageRef `json:"packages"`
+		}
+		_ = json.Unmarshal(msg.Params, &params)
+
+		switch msg.Method {
+		case "initialize":
+			fmt.Println(`{"jsonrpc":"2.0","method":"log","params":{"level":"info","message":"initializing"}}`)
+			respond(msg.ID, Manifest{
+				Name:        "helper",
+				Version:     "1.0.0",
+				Commands:    []Command{{ID: "echo", Title: "Echo"}, {ID: "fail", Title: "Fail"}, {ID: "hang", Title: "Hang"}},
+				Panels:      []Panel{{ID: "info", Title: "Info"}},
+				Annotations: true,
+			}, nil)
+		case "command/run":
+			switch params.Command {
+			case "echo":
+				respond(msg.ID, map[string]string{"output": params.Context.Package + ": " + strings.Join(params.Args, " ")}, nil)
+			case "fail":
+				respond(msg.ID, nil, &jsonrpc.Error{Code: 1, Message: "not allowed"})
+			case "hang":
+				time.Sleep(time.Minute)
+			}
+		case "panel/render":
+			respond(msg.ID, map[string][]string{"lines": {strings.Repeat("-", params.Width), params.Context.Package}}, nil)
+		case "packages/annotate":
+			var annotations []Annotation
+			for _, p := range params.Packages {
+				if p.ID == "Serilog" {
+					annotations = append(annotations, Annotation{ID: p.ID, Text: "approved " + p.Version})
+				}
+			}
+			respond(msg.ID, map[string][]Annotation{"annotations": annotations}, nil)
+		case "shutdown":
+			os.Exit(0)
+		}
+	}
+	os.Exit(0)
+}
+
+// helperPlugin returns the config of the helper plugin.
+func helperPlugin(t *testing.T, name string) config.Plugin {
+	t.Helper()
+	t.Setenv(helperEnv, "1")
+	return config.Plugin{Name: name, Command: os.Args[0], Args: []string{"-test.run=^TestHelperPlugin$"}, Timeout: 5 * time.Second}
+}
+
+// testLogger records log messages.
+type testLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *testLogger) Debug(msg string, args ...any) { l.add(msg, args) }
+func (l *testLogger) Info(msg string, args ...any)  { l.add(msg, args) }
+func (l *testLogger) Warn(msg string, args ...any)  { l.add(msg, args) }
+func (l *testLogger) Error(msg string, args ...any) { l.add(msg, args) }
+
+func (l *testLogger) add(msg string, args []any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf(msg, args...))
+}
+
+// TestClient tests initializing a plugin and each request.
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	c, err := Start(ctx, helperPlugin(t, "helper"), Options{WorkDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer c.Close()
+
+	if c.Manifest.Name != "helper" || len(c.Manifest.Commands) != 3 || !c.HasPanel("info") {
+		t.Errorf("Manifest = %+v", c.Manifest)
+	}
+
+	pc := Context{Package: "Serilog", Version: "3.0.0"}
+	output, err := c.RunCommand(ctx, "echo", []string{"a", "b"}, pc)
+	if err != nil || output != "Serilog: a b" {
+		t.Errorf("RunCommand(echo) = %q, %v", output, err)
+	}
+	if _, err := c.RunCommand(ctx, "fail", nil, pc); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("RunCommand(fail) error = %v, want the plugin's error", err)
+	}
+	if _, err := c.RunCommand(ctx, "missing", nil, pc); err == nil {
+		t.Error("RunCommand(missing) error = nil, want an error for an undeclared command")
+	}
+
+	lines, err := c.RenderPanel(ctx, "info", pc, 3, 10)
+	if err != nil || strings.Join(lines, "|") != "---|Serilog" {
+		t.Errorf("RenderPanel() = %q, %v", lines, err)
+	}
+
+	annotations, err := c.Annotate(ctx, pc, []PackageRef{{ID: "Serilog", Version: "3.0.0"}, {ID: "Polly", Version: "8.0.0"}})
+	if err != nil || len(annotations) != 1 || annotations[0].Text != "approved 3.0.0" {
+		t.Errorf("Annotate() = %+v, %v", annotations, err)
+	}
+}
+
+// TestClientTimeout tests that a plugin that does not answer is stopped.
+func TestClientTimeout(t *testing.T) {
+	p := helperPlugin(t, "helper")
+	p.Timeout = 200 * time.Millisecond
+	c, err := Start(context.Background(), p, Options{WorkDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer c.Close()
+
+	start := time.Now()
+	if _, err := c.RunCommand(context.Background(), "hang", nil, Context{}); err == nil || !strings.Contains(err.Error(), "did not answer") {
+		t.Errorf("RunCommand(hang) error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("RunCommand(hang) took %s", elapsed)
+	}
+	if _, err := c.RunCommand(context.Background(), "echo", nil, Context{}); err == nil {
+		t.Error("RunCommand() after a timeout error = nil, want an error from the stopped plugin")
+	}
+}
+
+// TestStartFailure tests the error for a plugin that cannot start or exits at once.
+func TestStartFailure(t *testing.T) {
+	if _, err := Start(context.Background(), config.Plugin{Name: "missing", Command: "lazynuget-no-such-plugin"}, Options{}); err == nil {
+		t.Error("Start() error = nil for a missing executable")
+	}
+
+	p := helperPlugin(t, "exits")
+	p.Args = []string{"-test.run=^$"}
+	if _, err := Start(context.Background(), p, Options{WorkDir: t.TempDir()}); err == nil || !strings.Contains(err.Error(), "exited") {
+		t.Errorf("Start() error = %v, want an error for a plugin that exits", err)
+	}
+}
+
+// TestManager tests starting plugins on demand, annotations from every plugin, and logging.
+func TestManager(t *testing.T) {
+	logger := &testLogger{}
+	cfg := config.GetDefaultConfig()
+	cfg.Plugins = []config.Plugin{helperPlugin(t, "first"), helperPlugin(t, "second")}
+	m := NewManager(cfg, Options{Logger: logger, WorkDir: t.TempDir()})
+	defer m.Close()
+
+	if _, err := m.Client(context.Background(), "third"); err == nil {
+		t.Error("Client(third) error = nil for an unconfigured plugin")
+	}
+	first, err := m.Client(context.Background(), "FIRST")
+	if err != nil {
+		t.Fatalf("Client(FIRST) error = %v", err)
+	}
+	if again, _ := m.Client(context.Background(), "first"); again != first {
+		t.Error("Client() started a plugin twice")
+	}
+
+	annotations, err := m.Annotate(context.Background(), Context{}, []PackageRef{{ID: "Serilog", Version: "3.0.0"}})
+	if err != nil {
+		t.Fatalf("Annotate() error = %v", err)
+	}
+	if got := strings.Join(annotations["serilog"], ", "); got != "approved 3.0.0, approved 3.0.0" {
+		t.Errorf("Annotate() = %q, want one annotation per plugin", got)
+	}
+
+	if err := m.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logged := strings.Join(logger.messages, "\n")
+	for _, want := range []string{"plugin first: initializing", "plugin second: helper plugin started"} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("log missing %q:\n%s", want, logged)
+		}
+	}
+}
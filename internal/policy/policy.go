@@ -0,0 +1,215 @@
+// Package policy enforces machine-level feature restrictions for managed deployments.
+//
+// Administrators place a policy file at a machine-wide location (see MachinePolicyPath)
+// that disables specific capabilities. The policy is applied regardless of user or project
+// configuration, and disabled features are shown in the UI as "restricted by policy".
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Capability identifies a feature that a policy can disable.
+type Capability string
+
+// Capabilities that can be restricted by policy.
+const (
+	CapabilityPush           Capability = "push"           // Publishing packages to a feed
+	CapabilitySelfUpdate     Capability = "selfUpdate"     // Updating the LazyNuGet binary
+	CapabilityCustomCommands Capability = "customCommands" // User-defined commands and hooks
+	CapabilityTelemetry      Capability = "telemetry"      // Sending usage data
+)
+
+// KnownCapabilities lists every capability a policy can disable.
+var KnownCapabilities = []Capability{
+	CapabilityPush,
+	CapabilitySelfUpdate,
+	CapabilityCustomCommands,
+	CapabilityTelemetry,
+}
+
+// RestrictedLabel is the marker shown next to features disabled by policy.
+const RestrictedLabel = "restricted by policy"
+
+// PolicyFileName is the name of the machine-level policy file.
+const PolicyFileName = "policy.yml"
+
+// RestrictedError is returned when an operation needs a capability the policy disables.
+type RestrictedError struct {
+	Capability Capability
+	Message    string // Administrator-provided contact or explanation
+}
+
+// Error implements the error interface for RestrictedError.
+func (e *RestrictedError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s is %s: %s", e.Capability, RestrictedLabel, e.Message)
+	}
+	return fmt.Sprintf("%s is %s", e.Capability, RestrictedLabel)
+}
+
+// Policy is the parsed machine policy. The zero value allows everything.
+type Policy struct {
+	disabled map[Capability]bool
+	Message  string // Shown alongside restricted features (e.g., who to contact)
+	Source   string // Path the policy was loaded from (empty = no policy)
+}
+
+// policyFile is the on-disk policy format.
+type policyFile struct {
+	Message  string   `yaml:"message"`
+	Disabled []string `yaml:"disabled"`
+}
+
+// MachinePolicyPath returns the platform-specific machine-level policy location.
+//
+//   - Linux/BSD: /etc/lazynuget/policy.yml
+//   - macOS: /Library/Application Support/lazynuget/policy.yml
+//   - Windows: %ProgramData%\lazynuget\policy.yml
+//
+// The location is writable only by administrators, so it is not overridable by users.
+func MachinePolicyPath() string {
+	switch runtime.GOOS {
+	case "windows":
+		programData := os.Getenv("ProgramData")
+		if programData == "" {
+			programData = `C:\ProgramData`
+		}
+		return filepath.Join(programData, "lazynuget", PolicyFileName)
+	case "darwin":
+		return filepath.Join("/Library", "Application Support", "lazynuget", PolicyFileName)
+	default:
+		return filepath.Join("/etc", "lazynuget", PolicyFileName)
+	}
+}
+
+// LoadMachinePolicy loads the policy from MachinePolicyPath.
+// A missing file yields an empty policy that allows everything.
+func LoadMachinePolicy() (*Policy, error) {
+	return Load(MachinePolicyPath())
+}
+
+// LoadMachinePolicyOrRestrict loads the policy from MachinePolicyPath and fails closed:
+// when the policy cannot be read or understood, the returned policy disables every
+// capability and err says why.
+func LoadMachinePolicyOrRestrict() (*Policy, error) {
+	p, err := LoadMachinePolicy()
+	if err != nil {
+		return restrictAll(MachinePolicyPath()), err
+	}
+	return p, nil
+}
+
+// restrictAll returns a policy that disables every known capability.
+func restrictAll(source string) *Policy {
+	p := &Policy{
+		disabled: make(map[Capability]bool, len(KnownCapabilities)),
+		Message:  "the machine policy could not be read",
+		Source:   source,
+	}
+	for _, c := range KnownCapabilities {
+		p.disabled[c] = true
+	}
+	return p
+}
+
+// Load reads a policy file. A missing file yields an empty policy that allows everything.
+// A malformed file or an unknown capability is an error: a policy that cannot be
+// understood must not be silently ignored.
+func Load(path string) (*Policy, error) {
+	// #nosec G304 -- path is the administrator-controlled policy location
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Policy{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file %s: %w", path, err)
+	}
+
+	var pf policyFile
+	if err := yaml.Unmarshal(data, &pf); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+
+	p := &Policy{
+		disabled: make(map[Capability]bool),
+		Message:  pf.Message,
+		Source:   path,
+	}
+	for _, name := range pf.Disabled {
+		c, ok := parseCapability(name)
+		if !ok {
+			return nil, fmt.Errorf("invalid policy file %s: unknown capability %q (known: %s)",
+				path, name, capabilityNames())
+		}
+		p.disabled[c] = true
+	}
+
+	return p, nil
+}
+
+// Allowed reports whether the policy permits a capability.
+func (p *Policy) Allowed(c Capability) bool {
+	if p == nil {
+		return true
+	}
+	return !p.disabled[c]
+}
+
+// Check returns a *RestrictedError if the policy disables c.
+func (p *Policy) Check(c Capability) error {
+	if p.Allowed(c) {
+		return nil
+	}
+	return &RestrictedError{Capability: c, Message: p.Message}
+}
+
+// Disabled returns the disabled capabilities in a stable order.
+func (p *Policy) Disabled() []Capability {
+	if p == nil {
+		return nil
+	}
+	result := make([]Capability, 0, len(p.disabled))
+	for c := range p.disabled {
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// Label decorates a feature label for display, appending RestrictedLabel when disabled.
+func (p *Policy) Label(c Capability, label string) string {
+	if p.Allowed(c) {
+		return label
+	}
+	return label + " (" + RestrictedLabel + ")"
+}
+
+// parseCapability matches a capability name case-insensitively, also accepting
+// kebab-case spellings (e.g., "self-update").
+func parseCapability(name string) (Capability, bool) {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "-", ""))
+	for _, c := range KnownCapabilities {
+		if strings.ToLower(string(c)) == normalized {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// capabilityNames returns the known capability names as a comma-separated list.
+func capabilityNames() string {
+	names := make([]string, len(KnownCapabilities))
+	for i, c := range KnownCapabilities {
+		names[i] = string(c)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,129 @@
+package policy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLoadMissingFile verifies the absence of a policy allows everything
+func TestLoadMissingFile(t *testing.T) {
+	p, err := Load(filepath.Join(t.TempDir(), PolicyFileName))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	for _, c := range KnownCapabilities {
+		if !p.Allowed(c) {
+			t.Errorf("Allowed(%s) = false with no policy file", c)
+		}
+	}
+	if p.Source != "" {
+		t.Errorf("Source = %q, want empty", p.Source)
+	}
+}
+
+// TestLoadPolicy tests parsing and enforcement of disabled capabilities
+func TestLoadPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), PolicyFileName)
+	content := "message: Contact IT at it@example.com\ndisabled: [push, self-update, Telemetry]\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write policy: %v", err)
+	}
+
+	p, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if p.Source != path {
+		t.Errorf("Source = %q, want %q", p.Source, path)
+	}
+
+	tests := []struct {
+		capability Capability
+		allowed    bool
+	}{
+		{CapabilityPush, false},
+		{CapabilitySelfUpdate, false},
+		{CapabilityTelemetry, false},
+		{CapabilityCustomCommands, true},
+	}
+	for _, tt := range tests {
+		if got := p.Allowed(tt.capability); got != tt.allowed {
+			t.Errorf("Allowed(%s) = %v, want %v", tt.capability, got, tt.allowed)
+		}
+	}
+
+	err = p.Check(CapabilityPush)
+	var restricted *RestrictedError
+	if !errors.As(err, &restricted) || restricted.Capability != CapabilityPush {
+		t.Fatalf("Check(push) = %v, want RestrictedError", err)
+	}
+	if !strings.Contains(err.Error(), RestrictedLabel) || !strings.Contains(err.Error(), "it@example.com") {
+		t.Errorf("error message %q should mention restriction and admin message", err.Error())
+	}
+
+	if got := p.Label(CapabilityPush, "Push package"); got != "Push package (restricted by policy)" {
+		t.Errorf("Label() = %q", got)
+	}
+	if got := p.Label(CapabilityCustomCommands, "Custom commands"); got != "Custom commands" {
+		t.Errorf("Label() for allowed capability = %q", got)
+	}
+
+	if got := len(p.Disabled()); got != 3 {
+		t.Errorf("Disabled() returned %d capabilities, want 3", got)
+	}
+}
+
+// TestRestrictAll verifies the fail-closed policy disables every capability
+func TestRestrictAll(t *testing.T) {
+	p := restrictAll("/etc/lazynuget/policy.yml")
+	for _, c := range KnownCapabilities {
+		if p.Allowed(c) {
+			t.Errorf("Allowed(%s) = true, want false", c)
+		}
+	}
+	if got := p.Label(CapabilityTelemetry, "Telemetry"); got != "Telemetry (restricted by policy)" {
+		t.Errorf("Label() = %q", got)
+	}
+}
+
+// TestLoadInvalidPolicy verifies malformed policies are rejected rather than ignored
+func TestLoadInvalidPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "unknown capability", content: "disabled: [teleport]\n"},
+		{name: "malformed yaml", content: "disabled: [push\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), PolicyFileName)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("Failed to write policy: %v", err)
+			}
+			if _, err := Load(path); err == nil {
+				t.Error("Load() expected error")
+			}
+		})
+	}
+}
+
+// TestNilPolicyAllowsEverything verifies a nil policy is safe to query
+func TestNilPolicyAllowsEverything(t *testing.T) {
+	var p *Policy
+	if !p.Allowed(CapabilityPush) || p.Check(CapabilityPush) != nil {
+		t.Error("nil policy should allow everything")
+	}
+}
+
+// TestMachinePolicyPath verifies the machine path is absolute
+func TestMachinePolicyPath(t *testing.T) {
+	path := MachinePolicyPath()
+	if !filepath.IsAbs(path) || filepath.Base(path) != PolicyFileName {
+		t.Errorf("MachinePolicyPath() = %q, want absolute path to %s", path, PolicyFileName)
+	}
+}
@@ -0,0 +1,149 @@
+// Package prefetch loads the details of the package a list's cursor rests on before they
+// are asked for, so opening them does not wait on the network. A Prefetcher waits until
+// the selection has stayed on a row for a while, so scrolling through a list does not
+// load every row it passes; its tasks fill Memos that the details view reads from.
+package prefetch
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Task loads something about a package version into a cache, such as its README.
+type Task func(ctx context.Context, id, version string) error
+
+// Prefetcher runs its tasks for the selected package once the selection has stayed on it
+// for the delay.
+type Prefetcher struct {
+	// Called with the error of a task; the details view loads again and reports it
+	OnError  func(id, version string, err error)
+	ctx      context.Context
+	cancel   context.CancelFunc
+	timer    *time.Timer
+	tasks    []Task
+	selected string // Key of the pending or last prefetched selection
+	delay    time.Duration
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	closed   bool
+}
+
+// New returns a prefetcher that runs tasks, concurrently, for a selection that has not
+// moved for delay.
+func New(delay time.Duration, tasks ...Task) *Prefetcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Prefetcher{ctx: ctx, cancel: cancel, tasks: tasks, delay: delay}
+}
+
+// Select moves the selection to a package version (version may be empty), dropping the
+// prefetch of the previous selection unless it has started. Selecting the row already
+// selected changes nothing, so callers can report the selection on every redraw.
+func (p *Prefetcher) Select(id, version string) {
+	key := strings.ToLower(id) + "@" + strings.ToLower(version)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || key == p.selected {
+		return
+	}
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.selected = key
+	p.timer = time.AfterFunc(p.delay, func() { p.run(key, id, version) })
+}
+
+// run runs the tasks for a selection, unless it moved while the timer fired.
+func (p *Prefetcher) run(key, id, version string) {
+	p.mu.Lock()
+	if p.closed || key != p.selected {
+		p.mu.Unlock()
+		return
+	}
+	p.wg.Add(len(p.tasks))
+	p.mu.Unlock()
+
+	for _, task := range p.tasks {
+		go func() {
+			defer p.wg.Done()
+			if err := task(p.ctx, id, version); err != nil && p.OnError != nil && p.ctx.Err() == nil {
+				p.OnError(id, version, err)
+			}
+		}()
+	}
+}
+
+// Close drops a pending prefetch, cancels those running, and waits for them to return.
+func (p *Prefetcher) Close() {
+	p.mu.Lock()
+	p.closed = true
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.mu.Unlock()
+	p.cancel()
+	p.wg.Wait()
+}
+
+// Memo keeps the results of loads by key. A caller asking for a key being loaded waits
+// for that load instead of starting another, so opening the details of a package while
+// its prefetch is still running costs no second request. Failed loads are not kept. The
+// zero Memo keeps results until the program exits.
+type Memo[T any] struct {
+	entries map[string]*memoEntry[T]
+	TTL     time.Duration // How long a result is reused; 0 for as long as the program runs
+	mu      sync.Mutex
+}
+
+// memoEntry is a result of a Memo, or a load in progress while done is open.
+type memoEntry[T any] struct {
+	loaded time.Time
+	done   chan struct{}
+	value  T
+	err    error
+}
+
+// Get returns the result for key, calling load when there is none or it expired.
+func (m *Memo[T]) Get(ctx context.Context, key string, load func(context.Context) (T, error)) (T, error) {
+	m.mu.Lock()
+	if e, ok := m.entries[key]; ok {
+		select {
+		case <-e.done:
+			if e.err == nil && (m.TTL == 0 || time.Since(e.loaded) < m.TTL) {
+				m.mu.Unlock()
+				return e.value, nil
+			}
+		default:
+			m.mu.Unlock()
+			select {
+			case <-e.done:
+			case <-ctx.Done():
+				var zero T
+				return zero, ctx.Err()
+			}
+			if e.err == nil {
+				return e.value, nil
+			}
+			// The load may have failed for its own caller only, such as a canceled prefetch
+			return m.Get(ctx, key, load)
+		}
+	}
+
+	e := &memoEntry[T]{done: make(chan struct{})}
+	if m.entries == nil {
+		m.entries = make(map[string]*memoEntry[T])
+	}
+	m.entries[key] = e
+	m.mu.Unlock()
+
+	value, err := load(ctx)
+	m.mu.Lock()
+	e.value, e.err, e.loaded = value, err, time.Now()
+	if err != nil && m.entries[key] == e {
+		delete(m.entries, key)
+	}
+	close(e.done)
+	m.mu.Unlock()
+	return value, err
+}
@@ -0,0 +1,108 @@
+package prefetch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestPrefetcher tests that only a selection that stays put is prefetched
+func TestPrefetcher(t *testing.T) {
+	var mu sync.Mutex
+	var loaded []string
+	fetched := make(chan struct{}, 10)
+	p := New(50*time.Millisecond, func(_ context.Context, id, version string) error {
+		mu.Lock()
+		loaded = append(loaded, id+" "+version)
+		mu.Unlock()
+		fetched <- struct{}{}
+		return nil
+	})
+	defer p.Close()
+
+	// Scrolling past rows loads none of them
+	for _, id := range []string{"A", "B", "C"} {
+		p.Select(id, "1.0.0")
+		time.Sleep(5 * time.Millisecond)
+	}
+	<-fetched
+	// Reselecting the prefetched row, as a redraw does, loads nothing more
+	p.Select("c", "1.0.0")
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(loaded) != 1 || loaded[0] != "C 1.0.0" {
+		t.Errorf("loaded %v, want only C 1.0.0", loaded)
+	}
+}
+
+// TestPrefetcherClose tests that closing cancels running tasks and drops pending ones
+func TestPrefetcherClose(t *testing.T) {
+	started := make(chan struct{})
+	var errs atomic.Int32
+	p := New(time.Millisecond, func(ctx context.Context, _, _ string) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	p.OnError = func(string, string, error) { errs.Add(1) }
+	p.Select("A", "")
+	<-started
+	p.Close()
+	if errs.Load() != 0 {
+		t.Error("the error of a canceled prefetch was reported")
+	}
+	p.Select("B", "") // Ignored after Close
+}
+
+// TestMemo tests sharing loads in progress and keeping only successful results
+func TestMemo(t *testing.T) {
+	var m Memo[string]
+	var calls atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) (string, error) {
+		calls.Add(1)
+		<-release
+		return "readme", nil
+	}
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := m.Get(context.Background(), "demo@1.0.0", load); err != nil || v != "readme" {
+				t.Errorf("Get() = %q, %v", v, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls.Load() != 1 {
+		t.Errorf("loaded %d times, want once", calls.Load())
+	}
+
+	// A failed load is tried again
+	fail := errors.New("offline")
+	if _, err := m.Get(context.Background(), "other", func(context.Context) (string, error) { return "", fail }); !errors.Is(err, fail) {
+		t.Fatalf("Get() error = %v, want %v", err, fail)
+	}
+	if v, err := m.Get(context.Background(), "other", func(context.Context) (string, error) { return "ok", nil }); err != nil || v != "ok" {
+		t.Errorf("Get() after a failure = %q, %v", v, err)
+	}
+
+	// Expired results are loaded again
+	expiring := Memo[int]{TTL: time.Millisecond}
+	n := 0
+	count := func(context.Context) (int, error) { n++; return n, nil }
+	_, _ = expiring.Get(context.Background(), "k", count)
+	time.Sleep(5 * time.Millisecond)
+	if v, _ := expiring.Get(context.Background(), "k", count); v != 2 {
+		t.Errorf("Get() of an expired result = %d, want 2", v)
+	}
+}
@@ -0,0 +1,243 @@
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// cacheVersion changes whenever the cache format or Project does; older caches are ignored.
+const cacheVersion = 2
+
+// racyWindow is how recent a modification time must be for the file to be hashed on
+// every load: within it, a second edit could keep both the size and the time.
+const racyWindow = 2 * time.Second
+
+// Cache keeps the projects of a repository between runs, so reopening a large repository
+// only reparses the project files that changed. Discovery reuses the last scan while no
+// directory it walked was modified; a project file is reparsed when its size or
+// modification time changed and so did its SHA-256 hash. A nil Cache reads every file.
+type Cache struct {
+	path string
+
+	mu     sync.Mutex
+	data   cacheData
+	dirty  bool
+	parsed int // Files parsed since the cache was opened
+}
+
+// cacheData is the content of a cache file.
+type cacheData struct {
+	Version int                   `json:"version"`
+	Root    string                `json:"root"`
+	Dirs    map[string]int64      `json:"dirs"`  // Directories scanned -> trustedModTime
+	Paths   []string              `json:"paths"` // Project files found
+	Files   map[string]cachedFile `json:"files"` // By path
+}
+
+// cachedFile is a parsed project file and what identifies its content.
+type cachedFile struct {
+	Size    int64    `json:"size"`
+	ModTime int64    `json:"modTime"` // See trustedModTime
+	Hash    string   `json:"hash"`
+	Project *Project `json:"project"`
+}
+
+// CachePath returns the cache file of the repository at root in dir, or "" without a dir.
+func CachePath(dir, root string) string {
+	if dir == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(filepath.Clean(root)))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// OpenCache reads a cache file. A missing, unreadable, or outdated file gives an empty
+// cache; with an empty path the cache is never saved.
+func OpenCache(path string) *Cache {
+	c := &Cache{path: path}
+	if path != "" {
+		// #nosec G304 -- path is in the user's cache directory
+		if data, err := os.ReadFile(path); err == nil {
+			if json.Unmarshal(data, &c.data) != nil || c.data.Version != cacheVersion {
+				c.data = cacheData{}
+			}
+		}
+	}
+	c.data.Version = cacheVersion
+	if c.data.Files == nil {
+		c.data.Files = make(map[string]cachedFile)
+	}
+	return c
+}
+
+// Parsed returns the number of project files parsed since the cache was opened; the
+// others came from the cache.
+func (c *Cache) Parsed() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.parsed
+}
+
+// Discover returns the project files under root like the package-level Discover, reusing
+// the last scan of root when none of its directories changed since.
+func (c *Cache) Discover(root string) ([]string, error) {
+	if c == nil {
+		return Discover(root)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.data.Root == root && len(c.data.Dirs) > 0 && c.unchangedDirs() {
+		return slices.Clone(c.data.Paths), nil
+	}
+
+	dirs := make(map[string]int64)
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && SkippedDirs[d.Name()] {
+				return fs.SkipDir
+			}
+			if info, err := d.Info(); err == nil {
+				dirs[path] = trustedModTime(info)
+			}
+			return nil
+		}
+		if IsProjectFile(d.Name()) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s for projects: %w", root, err)
+	}
+	slices.Sort(paths)
+
+	// Forget projects that are gone
+	for path := range c.data.Files {
+		if _, found := slices.BinarySearch(paths, path); !found {
+			delete(c.data.Files, path)
+		}
+	}
+	c.data.Root, c.data.Dirs, c.data.Paths = root, dirs, paths
+	c.dirty = true
+	return slices.Clone(paths), nil
+}
+
+// unchangedDirs reports whether every directory of the last scan still has the same
+// modification time: no file or directory was added, removed, or renamed in it.
+func (c *Cache) unchangedDirs() bool {
+	for dir, modTime := range c.data.Dirs {
+		info, err := os.Stat(dir)
+		if err != nil || trustedModTime(info) != modTime || modTime == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Load reads a project file like the package-level Load, from the cache when the file
+// has not changed since it was last parsed.
+func (c *Cache) Load(path string) (*Project, error) {
+	if c == nil {
+		return Load(path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	c.mu.Lock()
+	cached, ok := c.data.Files[path]
+	c.mu.Unlock()
+	if ok && cached.ModTime != 0 && cached.ModTime == info.ModTime().UnixNano() && cached.Size == info.Size() {
+		return cached.Project.clone(), nil
+	}
+
+	// #nosec G304 -- path is a project file in the user's workspace
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	p := cached.Project
+	if !ok || cached.Hash != hash {
+		if p, err = Parse(data); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		p.Path = path
+		c.mu.Lock()
+		c.parsed++
+		c.mu.Unlock()
+	}
+
+	c.mu.Lock()
+	c.data.Files[path] = cachedFile{Size: int64(len(data)), ModTime: trustedModTime(info), Hash: hash, Project: p}
+	c.dirty = true
+	c.mu.Unlock()
+	return p.clone(), nil
+}
+
+// trustedModTime returns the modification time of a file in Unix ns, or 0 when it is
+// too recent to tell a later change apart.
+func trustedModTime(info fs.FileInfo) int64 {
+	if time.Since(info.ModTime()) < racyWindow {
+		return 0
+	}
+	return info.ModTime().UnixNano()
+}
+
+// Save writes the cache file when anything changed since it was opened.
+func (c *Cache) Save() error {
+	if c == nil || c.path == "" {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	data, err := json.Marshal(c.data)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("failed to save the project cache: %w", err)
+	}
+	// A concurrent run never reads half a cache
+	if err := platform.WriteFileAtomic(c.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to save the project cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
+
+// clone returns a copy of the project that shares nothing with it.
+func (p *Project) clone() *Project {
+	q := *p
+	q.TargetFrameworks = slices.Clone(p.TargetFrameworks)
+	q.PackageReferences = slices.Clone(p.PackageReferences)
+	q.PackageVersions = slices.Clone(p.PackageVersions)
+	q.FrameworkReferences = slices.Clone(p.FrameworkReferences)
+	return &q
+}
@@ -0,0 +1,141 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// writeAged writes a file and dates it, and its directories up to root, in the past, as
+// if they were written before the last run.
+func writeAged(t testing.TB, root, path, content string, age time.Duration) {
+	t.Helper()
+	full := filepath.Join(root, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	then := time.Now().Add(-age)
+	for p := full; len(p) >= len(root); p = filepath.Dir(p) {
+		if err := os.Chtimes(p, then, then); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestCache tests that a reopened cache reparses only the project files that changed
+func TestCache(t *testing.T) {
+	root := t.TempDir()
+	writeAged(t, root, "src/App/App.csproj", sampleProject, time.Hour)
+	writeAged(t, root, "src/Lib/Lib.csproj", "<Project />", time.Hour)
+	writeAged(t, root, "src/Lib/obj/Generated.csproj", "<Project />", time.Hour)
+	cachePath := CachePath(t.TempDir(), root)
+
+	open := func() (*Cache, []*Project) {
+		t.Helper()
+		c := OpenCache(cachePath)
+		paths, err := c.Discover(root)
+		if err != nil {
+			t.Fatalf("Discover() error = %v", err)
+		}
+		var projects []*Project
+		for _, path := range paths {
+			p, err := c.Load(path)
+			if err != nil {
+				t.Fatalf("Load(%s) error = %v", path, err)
+			}
+			projects = append(projects, p)
+		}
+		if err := c.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		return c, projects
+	}
+
+	c, projects := open()
+	if c.Parsed() != 2 || len(projects) != 2 {
+		t.Fatalf("cold open parsed %d of %d projects, want 2 of 2", c.Parsed(), len(projects))
+	}
+	c, warm := open()
+	if c.Parsed() != 0 || len(warm) != 2 || warm[0].Path != projects[0].Path || len(warm[0].PackageReferences) != len(projects[0].PackageReferences) {
+		t.Errorf("warm open parsed %d, projects = %+v", c.Parsed(), warm)
+	}
+
+	// Same content with a new time is hashed, not parsed; new content is parsed
+	writeAged(t, root, "src/Lib/Lib.csproj", "<Project />", 30*time.Minute)
+	writeAged(t, root, "src/App/App.csproj", `<Project><PropertyGroup><TargetFramework>net9.0</TargetFramework></PropertyGroup></Project>`, 30*time.Minute)
+	c, projects = open()
+	if c.Parsed() != 1 || !slices.Equal(projects[0].TargetFrameworks, []string{"net9.0"}) {
+		t.Errorf("after edits parsed %d, App targets %v", c.Parsed(), projects[0].TargetFrameworks)
+	}
+
+	// Projects added or removed since the last scan are found
+	writeAged(t, root, "tests/App.Tests/App.Tests.csproj", "<Project />", 10*time.Minute)
+	if err := os.Remove(filepath.Join(root, "src", "Lib", "Lib.csproj")); err != nil {
+		t.Fatal(err)
+	}
+	c, projects = open()
+	var paths []string
+	for _, p := range projects {
+		paths = append(paths, p.Path)
+	}
+	want := []string{filepath.Join(root, "src", "App", "App.csproj"), filepath.Join(root, "tests", "App.Tests", "App.Tests.csproj")}
+	if !slices.Equal(paths, want) || c.Parsed() != 1 {
+		t.Errorf("after adding and removing paths = %v (parsed %d), want %v", paths, c.Parsed(), want)
+	}
+
+	// Projects handed out are copies
+	projects[0].TargetFrameworks[0] = "changed"
+	if p, _ := c.Load(want[0]); p.TargetFrameworks[0] != "net9.0" {
+		t.Errorf("Load() after modifying a loaded project = %v", p.TargetFrameworks)
+	}
+
+	var nilCache *Cache
+	if paths, err := nilCache.Discover(root); err != nil || !slices.Equal(paths, want) {
+		t.Errorf("nil Discover() = %v, %v", paths, err)
+	}
+}
+
+// TestLoadAll tests that projects read concurrently are yielded in order, with errors in
+// place, and that stopping early works
+func TestLoadAll(t *testing.T) {
+	root := t.TempDir()
+	var paths []string
+	for i := range 40 {
+		path := fmt.Sprintf("src/P%02d/P%02d.csproj", i, i)
+		writeAged(t, root, path, "<Project />", time.Hour)
+		paths = append(paths, filepath.Join(root, filepath.FromSlash(path)))
+	}
+	paths[7] = filepath.Join(root, "Missing.csproj")
+
+	for _, c := range []*Cache{nil, OpenCache("")} {
+		var got []string
+		for p, err := range c.LoadAll(paths, 8) {
+			if err != nil {
+				got = append(got, "error")
+				continue
+			}
+			got = append(got, p.Path)
+		}
+		want := slices.Clone(paths)
+		want[7] = "error"
+		if !slices.Equal(got, want) {
+			t.Errorf("LoadAll() = %v, want %v", got, want)
+		}
+	}
+
+	read := 0
+	for range OpenCache("").LoadAll(paths, 4) {
+		if read++; read == 3 {
+			break
+		}
+	}
+	if read != 3 {
+		t.Errorf("LoadAll() yielded %d projects after stopping at 3", read)
+	}
+}
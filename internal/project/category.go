@@ -0,0 +1,94 @@
+package project
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+)
+
+// Category separates packages the project runs with from packages that only take part
+// in the build (analyzers, source generators, build tasks) and from parts of the .NET
+// platform itself.
+type Category string
+
+const (
+	// CategoryRuntime is a dependency the project compiles or runs against.
+	CategoryRuntime Category = "runtime"
+	// CategoryDevelopment is an analyzer, source generator, or build-only package.
+	CategoryDevelopment Category = "development"
+	// CategoryPlatform is a shared framework or runtime pack the SDK provides.
+	CategoryPlatform Category = "platform"
+)
+
+// Assets are the asset types NuGet recognizes in IncludeAssets, ExcludeAssets, and
+// PrivateAssets ("all" and "none" expand to all or none of them).
+var Assets = []string{"compile", "runtime", "contentfiles", "build", "buildmultitargeting", "buildtransitive", "analyzers", "native"}
+
+// Classification is the category of a package reference and why it was chosen.
+type Classification struct {
+	Category Category
+	Reason   string // Empty for runtime dependencies
+}
+
+// Classify categorizes a package reference from its asset metadata and, when packagesDir
+// is not empty, the restored package's contents.
+func Classify(ref PackageReference, packagesDir string) Classification {
+	if nuget.IsPlatformPackage(ref.ID) {
+		return Classification{Category: CategoryPlatform, Reason: "part of the .NET platform"}
+	}
+
+	// Nothing the project compiles or runs against flows to its consumers
+	private := assetSet(ref.PrivateAssets, nil)
+	if slices.Contains(private, "compile") && slices.Contains(private, "runtime") {
+		return Classification{Category: CategoryDevelopment, Reason: "PrivateAssets=" + strings.TrimSpace(ref.PrivateAssets)}
+	}
+
+	included := ref.Assets()
+	if !slices.Contains(included, "compile") && !slices.Contains(included, "runtime") && !slices.Contains(included, "native") {
+		return Classification{Category: CategoryDevelopment, Reason: "no compile or runtime assets"}
+	}
+
+	if packagesDir != "" && ref.Version != "" {
+		if nuget.AnalyzerOnly(packagesDir, ref.ID, ref.Version) {
+			return Classification{Category: CategoryDevelopment, Reason: "analyzers only"}
+		}
+		if nuget.DevelopmentDependency(packagesDir, ref.ID, ref.Version) {
+			return Classification{Category: CategoryDevelopment, Reason: "development dependency"}
+		}
+	}
+	return Classification{Category: CategoryRuntime}
+}
+
+// Assets returns the asset types the project consumes: IncludeAssets (default all)
+// minus ExcludeAssets.
+func (r PackageReference) Assets() []string {
+	included := assetSet(r.IncludeAssets, Assets)
+	excluded := assetSet(r.ExcludeAssets, nil)
+	return slices.DeleteFunc(included, func(asset string) bool {
+		return slices.Contains(excluded, asset)
+	})
+}
+
+// assetSet expands an asset list ("runtime; build", "all", "none") into asset types,
+// returning def when the list is empty.
+func assetSet(list string, def []string) []string {
+	items := splitList(strings.ToLower(list))
+	if len(items) == 0 {
+		return slices.Clone(def)
+	}
+
+	var assets []string
+	for _, item := range items {
+		switch item {
+		case "all":
+			return slices.Clone(Assets)
+		case "none":
+		default:
+			if !slices.Contains(assets, item) {
+				assets = append(assets, item)
+			}
+		}
+	}
+	return assets
+}
@@ -0,0 +1,416 @@
+package project
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+)
+
+// FrameworkCondition returns the condition that scopes items to one target framework, as
+// dotnet add package --framework writes it.
+func FrameworkCondition(framework string) string {
+	return fmt.Sprintf("'$(TargetFramework)' == '%s'", framework)
+}
+
+// ConditionFrameworks returns the project's target frameworks an item with condition
+// applies to: all of them when the condition is empty. ok is false when the condition
+// cannot be evaluated (see EvaluateCondition).
+func (p *Project) ConditionFrameworks(condition string) ([]string, bool) {
+	if strings.TrimSpace(condition) == "" {
+		return p.TargetFrameworks, true
+	}
+	var frameworks []string
+	for _, tfm := range p.TargetFrameworks {
+		applies, ok := EvaluateCondition(condition, tfm)
+		if !ok {
+			return nil, false
+		}
+		if applies {
+			frameworks = append(frameworks, tfm)
+		}
+	}
+	return frameworks, true
+}
+
+// ReferenceFor returns the reference to a package that only some target frameworks
+// have, under a condition applying to framework.
+func (p *Project) ReferenceFor(id, framework string) (PackageReference, bool) {
+	for _, ref := range p.PackageReferences {
+		if !strings.EqualFold(ref.ID, id) || ref.Condition == "" {
+			continue
+		}
+		if applies, _ := EvaluateCondition(ref.Condition, framework); applies {
+			return ref, true
+		}
+	}
+	return PackageReference{}, false
+}
+
+// HasFramework reports whether the project targets a framework.
+func (p *Project) HasFramework(framework string) bool {
+	return slices.ContainsFunc(p.TargetFrameworks, func(tfm string) bool { return strings.EqualFold(tfm, framework) })
+}
+
+// EvaluateCondition evaluates an MSBuild condition for one target framework. It knows the
+// TargetFramework, TargetFrameworkIdentifier, and TargetFrameworkVersion properties;
+// string comparisons, and, or, !, and parentheses; the StartsWith, EndsWith, and Contains
+// property functions; and the [MSBuild]:: target framework functions. ok is false for
+// anything else, such as other properties, whose values the project file alone does not
+// give.
+func EvaluateCondition(condition, framework string) (result, ok bool) {
+	f, _ := nuget.ParseFramework(framework)
+	c := &conditionParser{s: condition, framework: f}
+	result = c.or()
+	c.skipSpace()
+	if c.pos < len(c.s) {
+		c.fail()
+	}
+	return result, !c.failed
+}
+
+// conditionParser evaluates a condition as it parses it, by recursive descent.
+type conditionParser struct {
+	s         string
+	pos       int
+	framework nuget.Framework
+	failed    bool // Something was not understood; the result means nothing
+}
+
+func (c *conditionParser) fail() {
+	c.failed = true
+	c.pos = len(c.s)
+}
+
+func (c *conditionParser) skipSpace() {
+	for c.pos < len(c.s) && isSpace(c.s[c.pos]) {
+		c.pos++
+	}
+}
+
+// keyword consumes a case-insensitive keyword (and, or) followed by a non-word character.
+func (c *conditionParser) keyword(word string) bool {
+	c.skipSpace()
+	end := c.pos + len(word)
+	if end > len(c.s) || !strings.EqualFold(c.s[c.pos:end], word) || (end < len(c.s) && isWordByte(c.s[end])) {
+		return false
+	}
+	c.pos = end
+	return true
+}
+
+// symbol consumes an operator or parenthesis.
+func (c *conditionParser) symbol(s string) bool {
+	c.skipSpace()
+	if !strings.HasPrefix(c.s[c.pos:], s) {
+		return false
+	}
+	c.pos += len(s)
+	return true
+}
+
+func (c *conditionParser) or() bool {
+	v := c.and()
+	for c.keyword("or") {
+		right := c.and()
+		v = v || right
+	}
+	return v
+}
+
+func (c *conditionParser) and() bool {
+	v := c.unary()
+	for c.keyword("and") {
+		right := c.unary()
+		v = v && right
+	}
+	return v
+}
+
+func (c *conditionParser) unary() bool {
+	switch {
+	case c.symbol("!"):
+		return !c.unary()
+	case c.symbol("("):
+		v := c.or()
+		if !c.symbol(")") {
+			c.fail()
+		}
+		return v
+	}
+	return c.comparison()
+}
+
+func (c *conditionParser) comparison() bool {
+	left := c.value()
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if !c.symbol(op) {
+			continue
+		}
+		right := c.value()
+		switch op {
+		case "==":
+			return strings.EqualFold(left, right)
+		case "!=":
+			return !strings.EqualFold(left, right)
+		}
+		l, errL := strconv.ParseFloat(left, 64)
+		r, errR := strconv.ParseFloat(right, 64)
+		if errL != nil || errR != nil {
+			c.fail()
+			return false
+		}
+		switch op {
+		case "<=":
+			return l <= r
+		case ">=":
+			return l >= r
+		case "<":
+			return l < r
+		default:
+			return l > r
+		}
+	}
+	return c.boolean(left)
+}
+
+// boolean converts a value standing alone to a boolean.
+func (c *conditionParser) boolean(v string) bool {
+	switch strings.ToLower(v) {
+	case "true", "on", "yes":
+		return true
+	case "false", "off", "no":
+		return false
+	}
+	c.fail()
+	return false
+}
+
+// value parses a quoted string, a property expression, or a bare word, and returns its
+// expanded value.
+func (c *conditionParser) value() string {
+	c.skipSpace()
+	rest := c.s[c.pos:]
+	switch {
+	case strings.HasPrefix(rest, "'"):
+		end := quoteEnd(rest)
+		if end < 0 {
+			c.fail()
+			return ""
+		}
+		c.pos += end + 1
+		return c.expand(rest[1:end])
+	case strings.HasPrefix(rest, "$("):
+		end := parenEnd(rest, 1)
+		if end < 0 {
+			c.fail()
+			return ""
+		}
+		c.pos += end + 1
+		return c.property(rest[2:end])
+	}
+	n := 0
+	for n < len(rest) && isWordByte(rest[n]) {
+		n++
+	}
+	if n == 0 {
+		c.fail()
+	}
+	c.pos += n
+	return rest[:n]
+}
+
+// expand expands the property expressions in a string.
+func (c *conditionParser) expand(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if strings.HasPrefix(s[i:], "@(") || strings.HasPrefix(s[i:], "%(") {
+			c.fail() // Item lists and metadata
+			return ""
+		}
+		if !strings.HasPrefix(s[i:], "$(") {
+			b.WriteByte(s[i])
+			continue
+		}
+		end := parenEnd(s[i:], 1)
+		if end < 0 {
+			c.fail()
+			return ""
+		}
+		b.WriteString(c.property(s[i+2 : i+end]))
+		i += end
+	}
+	return b.String()
+}
+
+// property evaluates the inside of $(...): a property, a string function called on one
+// (TargetFramework.StartsWith('net4')), or an [MSBuild]:: function.
+func (c *conditionParser) property(expr string) string {
+	expr = strings.TrimSpace(expr)
+	if name, ok := strings.CutPrefix(expr, "[MSBuild]::"); ok {
+		name, args, ok := c.call(name)
+		if !ok || len(args) == 0 {
+			c.fail()
+			return ""
+		}
+		f, _ := nuget.ParseFramework(args[0])
+		switch strings.ToLower(name) {
+		case "istargetframeworkcompatible":
+			if len(args) != 2 {
+				break
+			}
+			lib, _ := nuget.ParseFramework(args[1])
+			return strconv.FormatBool(nuget.Compatible(f, lib))
+		case "gettargetframeworkidentifier":
+			return frameworkIdentifier(f)
+		case "gettargetframeworkversion":
+			return fmt.Sprintf("%d.%d", f.Major, f.Minor)
+		case "gettargetplatformidentifier":
+			return f.Platform
+		}
+		c.fail()
+		return ""
+	}
+
+	name, method, hasMethod := strings.Cut(expr, ".")
+	var value string
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "targetframework":
+		value = c.framework.Moniker
+	case "targetframeworkidentifier":
+		value = frameworkIdentifier(c.framework)
+	case "targetframeworkversion":
+		value = fmt.Sprintf("v%d.%d", c.framework.Major, c.framework.Minor)
+		if c.framework.Patch > 0 {
+			value += fmt.Sprintf(".%d", c.framework.Patch)
+		}
+	default:
+		c.fail()
+		return ""
+	}
+	if !hasMethod {
+		return value
+	}
+	method, args, ok := c.call(method)
+	if !ok || len(args) != 1 {
+		c.fail()
+		return ""
+	}
+	value, arg := strings.ToLower(value), strings.ToLower(args[0])
+	switch strings.ToLower(method) {
+	case "startswith":
+		return strconv.FormatBool(strings.HasPrefix(value, arg))
+	case "endswith":
+		return strconv.FormatBool(strings.HasSuffix(value, arg))
+	case "contains":
+		return strconv.FormatBool(strings.Contains(value, arg))
+	}
+	c.fail()
+	return ""
+}
+
+// call splits a function call, Name('a', $(B)), into its name and expanded arguments.
+func (c *conditionParser) call(s string) (name string, args []string, ok bool) {
+	name, args, ok = splitCall(s)
+	for i, arg := range args {
+		args[i] = c.expand(arg)
+	}
+	return name, args, ok && !c.failed
+}
+
+// splitCall splits a function call, Name('a', $(B)), into its name and its arguments,
+// unquoted but not expanded.
+func splitCall(s string) (name string, args []string, ok bool) {
+	open := strings.IndexByte(s, '(')
+	if open < 0 || parenEnd(s, open) != len(s)-1 {
+		return "", nil, false
+	}
+	name, inner := strings.TrimSpace(s[:open]), s[open+1:len(s)-1]
+	depth, start := 0, 0
+	for i := 0; i <= len(inner); i++ {
+		if i < len(inner) {
+			switch inner[i] {
+			case '(':
+				depth++
+				continue
+			case ')':
+				depth--
+				continue
+			case '\'':
+				if end := quoteEnd(inner[i:]); end > 0 {
+					i += end
+				}
+				continue
+			case ',':
+				if depth > 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		arg := strings.TrimSpace(inner[start:i])
+		if unquoted, ok := strings.CutPrefix(arg, "'"); ok {
+			arg = strings.TrimSuffix(unquoted, "'")
+		}
+		if arg != "" || i < len(inner) {
+			args = append(args, arg)
+		}
+		start = i + 1
+	}
+	return name, args, true
+}
+
+// frameworkIdentifier returns the TargetFrameworkIdentifier MSBuild gives a framework.
+func frameworkIdentifier(f nuget.Framework) string {
+	switch f.Family {
+	case nuget.FamilyNet, nuget.FamilyNetCoreApp:
+		return ".NETCoreApp"
+	case nuget.FamilyNetStandard:
+		return ".NETStandard"
+	case nuget.FamilyNetFramework:
+		return ".NETFramework"
+	}
+	return ""
+}
+
+// quoteEnd returns the index of the quote closing the string s starts with, skipping
+// property expressions, which may contain quotes of their own; -1 when there is none.
+func quoteEnd(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "$("):
+			end := parenEnd(s[i:], 1)
+			if end < 0 {
+				return -1
+			}
+			i += end
+		case s[i] == '\'':
+			return i
+		}
+	}
+	return -1
+}
+
+// parenEnd returns the index of the parenthesis closing s[open], or -1.
+func parenEnd(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// isWordByte reports whether b can be part of a bare word in a condition.
+func isWordByte(b byte) bool {
+	return b == '.' || b == '_' || b == '-' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
@@ -0,0 +1,572 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Editor applies surgical edits to the text of a project file. Each edit replaces only
+// the bytes of the value it changes, so attributes and child elements it does not touch
+// (Condition, Aliases, GeneratePathProperty, PrivateAssets, ...), comments, whitespace,
+// and line endings are kept byte for byte.
+type Editor struct {
+	text      string
+	framework string // See ForFramework
+}
+
+// NewEditor creates an editor for project file text.
+func NewEditor(text string) *Editor {
+	return &Editor{text: text}
+}
+
+// String returns the edited text.
+func (e *Editor) String() string {
+	return e.text
+}
+
+// ForFramework scopes the item edits to one target framework of a multi-targeting
+// project: items are found only when they have a condition, of their own or their
+// ItemGroup's, that applies to the framework, and AddItem adds items under the
+// framework's condition. "" removes the scope, so that items are found whatever their
+// condition.
+func (e *Editor) ForFramework(framework string) {
+	e.framework = framework
+}
+
+// HasProperty reports whether the file defines the property in a PropertyGroup.
+func (e *Editor) HasProperty(name string) bool {
+	_, _, ok := e.property(name)
+	return ok
+}
+
+// Property returns the value of the first definition of a property.
+func (e *Editor) Property(name string) (string, bool) {
+	start, end, ok := e.property(name)
+	if !ok {
+		return "", false
+	}
+	return unescapeText(e.text[start:end]), true
+}
+
+// SetProperty replaces the value of the first definition of a property, keeping its
+// surrounding whitespace. It returns false when the property is not defined.
+func (e *Editor) SetProperty(name, value string) bool {
+	start, end, ok := e.property(name)
+	if !ok {
+		return false
+	}
+	e.replace(start, end, escapeText(value))
+	return true
+}
+
+// AddProperty adds <name>value</name> at the end of the first PropertyGroup without a
+// condition, or of a new PropertyGroup at the start of the project, matching the
+// indentation of the group's last property.
+func (e *Editor) AddProperty(name, value string) {
+	line := fmt.Sprintf("<%s>%s</%s>", name, escapeText(value), name)
+	newline := e.newline()
+	elems := elements(scanTags(e.text))
+	for i, el := range elems {
+		if el.parent != 0 || !strings.EqualFold(el.open.name, "PropertyGroup") || el.close == nil {
+			continue
+		}
+		if _, ok := el.open.attr("Condition"); ok {
+			continue
+		}
+		if last := lastChild(elems, i); last >= 0 {
+			end := elems[last].open.end
+			if elems[last].close != nil {
+				end = elems[last].close.end
+			}
+			e.replace(end, end, newline+e.indentation(elems[last].open.start)+line)
+			return
+		}
+		start := el.close.start
+		e.replace(start, start, "  "+line+newline+e.indentation(el.open.start))
+		return
+	}
+
+	if len(elems) == 0 || elems[0].close == nil {
+		e.text += newline + line + newline
+		return
+	}
+	at := elems[0].open.end
+	e.replace(at, at, newline+"  <PropertyGroup>"+newline+"    "+line+newline+"  </PropertyGroup>"+newline)
+}
+
+// CommentProperty writes <!-- text --> on its own line above the first definition of a
+// property, like CommentItem. It returns false when the property is not defined.
+func (e *Editor) CommentProperty(name, text string) bool {
+	elems := elements(scanTags(e.text))
+	pos := e.propertyElement(elems, name)
+	if pos < 0 {
+		return false
+	}
+	e.comment(elems[pos].open.start, text)
+	return true
+}
+
+// RemoveProperty removes the first definition of a property, with its line when nothing
+// else is on it. It returns false when the property is not defined.
+func (e *Editor) RemoveProperty(name string) bool {
+	elems := elements(scanTags(e.text))
+	pos := e.propertyElement(elems, name)
+	if pos < 0 {
+		return false
+	}
+	el := elems[pos]
+	end := el.open.end
+	if el.close != nil {
+		end = el.close.end
+	}
+	e.removeLine(el.open.start, end)
+	return true
+}
+
+// UncommentProperty removes the first of the comments on the lines right above the first
+// definition of a property whose text starts with prefix, as CommentProperty wrote it. It
+// returns false when there is none.
+func (e *Editor) UncommentProperty(name, prefix string) bool {
+	elems := elements(scanTags(e.text))
+	pos := e.propertyElement(elems, name)
+	return pos >= 0 && e.uncomment(elems[pos].open.start, prefix)
+}
+
+// UncommentItem is UncommentProperty for the first item of kind that includes id.
+func (e *Editor) UncommentItem(kind, id, prefix string) bool {
+	elems := elements(scanTags(e.text))
+	pos := e.item(elems, kind, id)
+	return pos >= 0 && e.uncomment(elems[pos].open.start, prefix)
+}
+
+// RemoveItemMetadata removes a metadata attribute or child element from the first item of
+// kind that includes id. It returns false when the item does not have it.
+func (e *Editor) RemoveItemMetadata(kind, id, name string) bool {
+	elems := elements(scanTags(e.text))
+	pos := e.item(elems, kind, id)
+	if pos < 0 {
+		return false
+	}
+	if a, ok := elems[pos].open.attr(name); ok {
+		// The attribute with the whitespace before it
+		start := strings.LastIndex(e.text[:a.valueStart], a.name)
+		start = strings.LastIndexFunc(e.text[:start], func(r rune) bool { return !isSpace(byte(r)) }) + 1
+		e.replace(start, a.valueEnd+1, "")
+		return true
+	}
+	for i := pos + 1; i < len(elems); i++ {
+		if elems[i].parent == pos && strings.EqualFold(elems[i].open.name, name) {
+			end := elems[i].open.end
+			if elems[i].close != nil {
+				end = elems[i].close.end
+			}
+			e.removeLine(elems[i].open.start, end)
+			return true
+		}
+	}
+	return false
+}
+
+// propertyElement returns the position of the first definition of a property, or -1.
+func (e *Editor) propertyElement(elems []element, name string) int {
+	for i, el := range elems {
+		if el.parent >= 0 && strings.EqualFold(elems[el.parent].open.name, "PropertyGroup") && strings.EqualFold(el.open.name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// comment writes <!-- text --> on its own line above offset, at its indentation. Double
+// hyphens, which comments cannot contain, are shortened.
+func (e *Editor) comment(offset int, text string) {
+	for strings.Contains(text, "--") {
+		text = strings.ReplaceAll(text, "--", "-")
+	}
+	text = strings.TrimRight(text, "-")
+	e.replace(offset, offset, "<!-- "+text+" -->"+e.newline()+e.indentation(offset))
+}
+
+// removeLine removes text[start:end], with its line when nothing else is on it.
+func (e *Editor) removeLine(start, end int) {
+	lineStart := strings.LastIndexByte(e.text[:start], '\n') + 1
+	lineEnd := len(e.text)
+	if i := strings.IndexByte(e.text[end:], '\n'); i >= 0 {
+		lineEnd = end + i + 1
+	}
+	if strings.TrimSpace(e.text[lineStart:start]) == "" && strings.TrimSpace(e.text[end:lineEnd]) == "" {
+		start, end = lineStart, lineEnd
+	}
+	e.replace(start, end, "")
+}
+
+// uncomment removes the first comment whose text starts with prefix among those alone on
+// the lines right above offset.
+func (e *Editor) uncomment(offset int, prefix string) bool {
+	end := strings.LastIndexByte(e.text[:offset], '\n') + 1 // Start of the element's line
+	for end > 0 {
+		start := strings.LastIndexByte(e.text[:end-1], '\n') + 1
+		line := strings.TrimSpace(e.text[start:end])
+		text, ok := strings.CutPrefix(line, "<!--")
+		if !ok || !strings.HasSuffix(text, "-->") || strings.Contains(strings.TrimSuffix(text, "-->"), "-->") {
+			return false
+		}
+		if strings.HasPrefix(strings.TrimSpace(text), prefix) {
+			e.replace(start, end, "")
+			return true
+		}
+		end = start
+	}
+	return false
+}
+
+// property returns the span of a property's trimmed value.
+func (e *Editor) property(name string) (start, end int, ok bool) {
+	elems := elements(scanTags(e.text))
+	for _, el := range elems {
+		if el.parent < 0 || !strings.EqualFold(elems[el.parent].open.name, "PropertyGroup") || !strings.EqualFold(el.open.name, name) {
+			continue
+		}
+		if start, end, ok := e.content(el); ok {
+			return start, end, true
+		}
+	}
+	return 0, 0, false
+}
+
+// HasItem reports whether the file has an item of the given kind (e.g., PackageReference)
+// that includes id (case-insensitively).
+func (e *Editor) HasItem(kind, id string) bool {
+	elems := elements(scanTags(e.text))
+	return e.item(elems, kind, id) >= 0
+}
+
+// ItemMetadata returns an item's metadata value, written either as an attribute
+// (Version="1.0.0") or as a child element (<Version>1.0.0</Version>).
+func (e *Editor) ItemMetadata(kind, id, name string) (string, bool) {
+	elems := elements(scanTags(e.text))
+	pos := e.item(elems, kind, id)
+	if pos < 0 {
+		return "", false
+	}
+	start, end, ok := e.metadata(elems, pos, name)
+	if !ok {
+		return "", false
+	}
+	return unescapeText(e.text[start:end]), true
+}
+
+// SetItemMetadata sets an item's metadata value where it is already written. Metadata the
+// item does not have yet is added as a child element when the item already uses child
+// elements, and as an attribute otherwise. It returns false when there is no such item.
+func (e *Editor) SetItemMetadata(kind, id, name, value string) bool {
+	elems := elements(scanTags(e.text))
+	pos := e.item(elems, kind, id)
+	if pos < 0 {
+		return false
+	}
+	item := elems[pos]
+
+	if a, ok := item.open.attr(name); ok {
+		e.replace(a.valueStart, a.valueEnd, escapeAttr(value, e.text[a.valueStart-1]))
+		return true
+	}
+	if start, end, ok := e.metadata(elems, pos, name); ok {
+		e.replace(start, end, escapeText(value))
+		return true
+	}
+
+	if last := lastChild(elems, pos); last >= 0 {
+		child := elems[last]
+		end := child.open.end
+		if child.close != nil {
+			end = child.close.end
+		}
+		line := fmt.Sprintf("<%s>%s</%s>", name, escapeText(value), name)
+		e.replace(end, end, e.newline()+e.indentation(child.open.start)+line)
+		return true
+	}
+
+	// After the last attribute, so whitespace before "/>" or ">" is kept
+	at := item.open.nameEnd
+	if n := len(item.open.attrs); n > 0 {
+		at = item.open.attrs[n-1].valueEnd + 1
+	}
+	e.replace(at, at, fmt.Sprintf(` %s="%s"`, name, escapeAttr(value, '"')))
+	return true
+}
+
+// AddItem adds <kind Include="id" Version="version" /> (without Version when version is
+// empty) after the last item of the same kind, matching its indentation, or in a new
+// ItemGroup at the end of the project. Items in an ItemGroup with a condition are passed
+// over, so that the new item is not made conditional; scoped to a framework, the item is
+// added to an ItemGroup with that framework's condition instead.
+func (e *Editor) AddItem(kind, id, version string) {
+	line := fmt.Sprintf(`<%s Include="%s"`, kind, escapeAttr(id, '"'))
+	if version != "" {
+		line += fmt.Sprintf(` Version="%s"`, escapeAttr(version, '"'))
+	}
+	line += " />"
+
+	newline := e.newline()
+	elems := elements(scanTags(e.text))
+	condition := ""
+	if e.framework != "" {
+		condition = FrameworkCondition(e.framework)
+	}
+	for i := len(elems) - 1; i >= 0; i-- {
+		if !strings.EqualFold(elems[i].open.name, kind) || elems[i].parent < 0 {
+			continue
+		}
+		group, _ := elems[elems[i].parent].open.attr("Condition")
+		if !sameCondition(e.text[group.valueStart:group.valueEnd], condition) {
+			continue
+		}
+		end := elems[i].open.end
+		if elems[i].close != nil {
+			end = elems[i].close.end
+		}
+		e.replace(end, end, newline+e.indentation(elems[i].open.start)+line)
+		return
+	}
+
+	if len(elems) == 0 || elems[0].close == nil {
+		e.text += newline + line + newline
+		return
+	}
+	end := elems[0].close.start
+	open := "<ItemGroup>"
+	if condition != "" {
+		open = fmt.Sprintf(`<ItemGroup Condition="%s">`, escapeAttr(condition, '"'))
+	}
+	group := "  " + open + newline + "    " + line + newline + "  </ItemGroup>" + newline
+	// Keep a blank line between the new group and the previous element
+	prefix := strings.TrimRight(e.text[:end], " \t")
+	if !strings.HasSuffix(prefix, newline+newline) {
+		group = newline + group
+	}
+	e.text = prefix + group + e.text[end:]
+}
+
+// CommentItem writes <!-- text --> on its own line above the first item of kind that
+// includes id, at the item's indentation. It returns false when there is no such item.
+func (e *Editor) CommentItem(kind, id, text string) bool {
+	elems := elements(scanTags(e.text))
+	pos := e.item(elems, kind, id)
+	if pos < 0 {
+		return false
+	}
+	e.comment(elems[pos].open.start, text)
+	return true
+}
+
+// RemoveItem removes the first item of kind that includes id, with its line when nothing
+// else is on it, and the enclosing ItemGroup when the item was its only element (with the
+// blank line before it, when it was the last element of the project). It returns false
+// when there is no such item.
+func (e *Editor) RemoveItem(kind, id string) bool {
+	elems := elements(scanTags(e.text))
+	pos := e.item(elems, kind, id)
+	if pos < 0 {
+		return false
+	}
+	group := false
+	if parent := elems[pos].parent; parent >= 0 && strings.EqualFold(elems[parent].open.name, "ItemGroup") &&
+		elems[parent].close != nil && lastChild(elems, parent) == pos && firstChild(elems, parent) == pos {
+		pos, group = parent, true
+	}
+
+	el := elems[pos]
+	start, end := el.open.start, el.open.end
+	if el.close != nil {
+		end = el.close.end
+	}
+	// Take the whole line when the element is alone on it
+	lineStart := strings.LastIndexByte(e.text[:start], '\n') + 1
+	lineEnd := len(e.text)
+	if i := strings.IndexByte(e.text[end:], '\n'); i >= 0 {
+		lineEnd = end + i + 1
+	}
+	if strings.TrimSpace(e.text[lineStart:start]) == "" && strings.TrimSpace(e.text[end:lineEnd]) == "" {
+		start, end = lineStart, lineEnd
+		// A group that was last goes with the blank line AddItem puts before one
+		if previous := strings.LastIndexByte(e.text[:max(start-1, 0)], '\n') + 1; group && previous < start &&
+			strings.TrimSpace(e.text[previous:start]) == "" && strings.HasPrefix(strings.TrimSpace(e.text[end:]), "</") {
+			start = previous
+		}
+	}
+	e.replace(start, end, "")
+	return true
+}
+
+// item returns the position of the first element of kind that includes id, within the
+// editor's framework scope, or -1.
+func (e *Editor) item(elems []element, kind, id string) int {
+	for i, el := range elems {
+		if !strings.EqualFold(el.open.name, kind) {
+			continue
+		}
+		if a, ok := el.open.attr("Include"); ok && strings.EqualFold(strings.TrimSpace(unescapeText(e.text[a.valueStart:a.valueEnd])), id) && e.inScope(elems, i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// inScope reports whether elems[pos] is within the editor's framework scope.
+func (e *Editor) inScope(elems []element, pos int) bool {
+	if e.framework == "" {
+		return true
+	}
+	condition := ""
+	if a, ok := elems[pos].open.attr("Condition"); ok {
+		condition = unescapeText(e.text[a.valueStart:a.valueEnd])
+	} else if parent := elems[pos].parent; parent >= 0 {
+		if a, ok := elems[parent].open.attr("Condition"); ok {
+			condition = unescapeText(e.text[a.valueStart:a.valueEnd])
+		}
+	}
+	if strings.TrimSpace(condition) == "" {
+		return false
+	}
+	applies, _ := EvaluateCondition(condition, e.framework)
+	return applies
+}
+
+// sameCondition reports whether two conditions are written alike, but for whitespace,
+// case, and quotes.
+func sameCondition(a, b string) bool {
+	normalize := func(s string) string {
+		s = strings.ReplaceAll(unescapeText(s), `"`, "'")
+		return strings.ToLower(strings.Join(strings.Fields(s), ""))
+	}
+	return normalize(a) == normalize(b)
+}
+
+// metadata returns the span of an item's metadata value, from an attribute or a child element.
+func (e *Editor) metadata(elems []element, pos int, name string) (start, end int, ok bool) {
+	if a, ok := elems[pos].open.attr(name); ok {
+		return a.valueStart, a.valueEnd, true
+	}
+	for i := pos + 1; i < len(elems); i++ {
+		if elems[i].parent == pos && strings.EqualFold(elems[i].open.name, name) {
+			return e.content(elems[i])
+		}
+	}
+	return 0, 0, false
+}
+
+// content returns the span of an element's text with surrounding whitespace trimmed.
+// Self-closing elements have no content.
+func (e *Editor) content(el element) (start, end int, ok bool) {
+	if el.close == nil {
+		return 0, 0, false
+	}
+	start, end = el.open.end, el.close.start
+	for start < end && isSpace(e.text[start]) {
+		start++
+	}
+	for end > start && isSpace(e.text[end-1]) {
+		end--
+	}
+	return start, end, true
+}
+
+// lastChild returns the position of the last child element of elems[pos], or -1.
+func lastChild(elems []element, pos int) int {
+	last := -1
+	for i := pos + 1; i < len(elems); i++ {
+		if elems[i].parent == pos {
+			last = i
+		}
+	}
+	return last
+}
+
+// firstChild returns the position of the first child element of elems[pos], or -1.
+func firstChild(elems []element, pos int) int {
+	for i := pos + 1; i < len(elems); i++ {
+		if elems[i].parent == pos {
+			return i
+		}
+	}
+	return -1
+}
+
+// replace replaces text[start:end] with s.
+func (e *Editor) replace(start, end int, s string) {
+	e.text = e.text[:start] + s + e.text[end:]
+}
+
+// newline returns the file's line ending.
+func (e *Editor) newline() string {
+	if strings.Contains(e.text, "\r\n") {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// indentation returns the whitespace before offset on its line, or "" when the line has
+// other content before it.
+func (e *Editor) indentation(offset int) string {
+	lineStart := strings.LastIndexByte(e.text[:offset], '\n') + 1
+	indent := e.text[lineStart:offset]
+	if strings.TrimLeft(indent, " \t") != "" {
+		return ""
+	}
+	return indent
+}
+
+// escapeText escapes characters that are not allowed in XML text.
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// escapeAttr escapes an attribute value delimited by quote.
+func escapeAttr(s string, quote byte) string {
+	s = textEscaper.Replace(s)
+	if quote == '\'' {
+		return strings.ReplaceAll(s, "'", "&apos;")
+	}
+	return strings.ReplaceAll(s, `"`, "&quot;")
+}
+
+// unescapeText decodes the predefined XML entities.
+func unescapeText(s string) string {
+	return textUnescaper.Replace(s)
+}
+
+var (
+	// textEscaper escapes XML text content.
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	// textUnescaper decodes the predefined XML entities.
+	textUnescaper = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&amp;", "&")
+)
+
+// EditFile applies fn to a project file's text and writes the result back, keeping the
+// file's permissions. The file is not written when fn returns an error or changes nothing.
+func EditFile(path string, fn func(*Editor) error) error {
+	// #nosec G304 -- path is a project file in the user's workspace
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	editor := NewEditor(string(data))
+	if err := fn(editor); err != nil {
+		return err
+	}
+	if editor.String() == string(data) {
+		return nil
+	}
+
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(path, []byte(editor.String()), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
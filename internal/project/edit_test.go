@@ -0,0 +1,313 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestEditorRoundTrip tests that changing every package version in real-world project
+// files replaces only the version text and leaves every other byte in place
+func TestEditorRoundTrip(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*proj"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no test projects: %v", err)
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			original := string(data)
+			p, err := Parse(data)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(p.PackageReferences) == 0 {
+				t.Fatal("test project has no package references")
+			}
+
+			if got := NewEditor(original).String(); got != original {
+				t.Error("an editor without edits must return the text unchanged")
+			}
+
+			for _, ref := range p.PackageReferences {
+				editor := NewEditor(original)
+				if got, ok := editor.ItemMetadata("PackageReference", ref.ID, "Version"); !ok || got != ref.Version {
+					t.Errorf("ItemMetadata(%s, Version) = %q, %v, want %q", ref.ID, got, ok, ref.Version)
+				}
+				if !editor.SetItemMetadata("PackageReference", ref.ID, "Version", "99.0.0") {
+					t.Fatalf("SetItemMetadata(%s) found no reference", ref.ID)
+				}
+
+				if !replacedOnce(original, editor.String(), ref.Version, "99.0.0") {
+					t.Errorf("%s: edit changed more than the version:\n%s", ref.ID, editor.String())
+				}
+
+				edited, err := Parse([]byte(editor.String()))
+				if err != nil {
+					t.Fatalf("Parse(edited) error = %v", err)
+				}
+				for i, got := range edited.PackageReferences {
+					want := p.PackageReferences[i]
+					if want.ID == ref.ID {
+						want.Version = "99.0.0"
+					}
+					if got != want {
+						t.Errorf("after editing %s, reference %d = %+v, want %+v", ref.ID, i, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+// replacedOnce reports whether edited is original with one occurrence of old replaced by replacement.
+func replacedOnce(original, edited, old, replacement string) bool {
+	for i := 0; i+len(old) <= len(original); i++ {
+		if original[i:i+len(old)] == old && original[:i]+replacement+original[i+len(old):] == edited {
+			return true
+		}
+	}
+	return false
+}
+
+// TestEditorItems tests finding, updating, and adding items
+func TestEditorItems(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		edit func(*Editor) bool
+		want string
+	}{
+		{
+			name: "commented-out reference is not edited",
+			text: "<Project>\n  <!-- <PackageReference Include=\"Serilog\" Version=\"2.0.0\" /> -->\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\n  </ItemGroup>\n</Project>\n",
+			edit: func(e *Editor) bool { return e.SetItemMetadata("PackageReference", "Serilog", "Version", "3.1.0") },
+			want: "<Project>\n  <!-- <PackageReference Include=\"Serilog\" Version=\"2.0.0\" /> -->\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.1.0\" />\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name: "condition containing a greater-than sign",
+			text: "<Project>\n  <ItemGroup>\n    <PackageReference Condition=\"'$(LangVersion)' > '9'\" Include=\"Serilog\" Version=\"3.0.0\" />\n  </ItemGroup>\n</Project>\n",
+			edit: func(e *Editor) bool { return e.SetItemMetadata("PackageReference", "Serilog", "Version", "3.1.0") },
+			want: "<Project>\n  <ItemGroup>\n    <PackageReference Condition=\"'$(LangVersion)' > '9'\" Include=\"Serilog\" Version=\"3.1.0\" />\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name: "single-quoted attribute",
+			text: "<Project><ItemGroup><PackageReference Include='Serilog' Version='3.0.0'/></ItemGroup></Project>",
+			edit: func(e *Editor) bool {
+				return e.SetItemMetadata("PackageReference", "serilog", "Version", "[3.1.0, 4.0)")
+			},
+			want: "<Project><ItemGroup><PackageReference Include='Serilog' Version='[3.1.0, 4.0)'/></ItemGroup></Project>",
+		},
+		{
+			name: "missing metadata is added as an attribute",
+			text: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\"/>\n  </ItemGroup>\n</Project>\n",
+			edit: func(e *Editor) bool { return e.SetItemMetadata("PackageReference", "Serilog", "PrivateAssets", "all") },
+			want: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" PrivateAssets=\"all\"/>\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name: "missing metadata follows child element style",
+			text: "<Project>\r\n  <ItemGroup>\r\n    <PackageReference Include=\"Serilog\">\r\n      <PrivateAssets>all</PrivateAssets>\r\n    </PackageReference>\r\n  </ItemGroup>\r\n</Project>\r\n",
+			edit: func(e *Editor) bool { return e.SetItemMetadata("PackageReference", "Serilog", "Version", "3.1.0") },
+			want: "<Project>\r\n  <ItemGroup>\r\n    <PackageReference Include=\"Serilog\">\r\n      <PrivateAssets>all</PrivateAssets>\r\n      <Version>3.1.0</Version>\r\n    </PackageReference>\r\n  </ItemGroup>\r\n</Project>\r\n",
+		},
+		{
+			name: "unknown item",
+			text: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\n  </ItemGroup>\n</Project>\n",
+			edit: func(e *Editor) bool { return !e.SetItemMetadata("PackageReference", "Polly", "Version", "8.0.0") },
+			want: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name: "add after the last item of the same kind",
+			text: "<Project>\n\t<ItemGroup>\n\t\t<PackageReference Include=\"Serilog\">\n\t\t\t<Version>3.0.0</Version>\n\t\t</PackageReference>\n\t</ItemGroup>\n</Project>\n",
+			edit: func(e *Editor) bool { e.AddItem("PackageReference", "Polly", "8.3.1"); return true },
+			want: "<Project>\n\t<ItemGroup>\n\t\t<PackageReference Include=\"Serilog\">\n\t\t\t<Version>3.0.0</Version>\n\t\t</PackageReference>\n\t\t<PackageReference Include=\"Polly\" Version=\"8.3.1\" />\n\t</ItemGroup>\n</Project>\n",
+		},
+		{
+			name: "add escapes values",
+			text: "<Project>\n</Project>\n",
+			edit: func(e *Editor) bool { e.AddItem("PackageVersion", "A&B", ""); return true },
+			want: "<Project>\n\n  <ItemGroup>\n    <PackageVersion Include=\"A&amp;B\" />\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name: "add passes over a conditional group",
+			text: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\n  </ItemGroup>\n  <ItemGroup Condition=\"'$(TargetFramework)' == 'net48'\">\n    <PackageReference Include=\"System.ValueTuple\" Version=\"4.5.0\" />\n  </ItemGroup>\n</Project>\n",
+			edit: func(e *Editor) bool { e.AddItem("PackageReference", "Polly", "8.3.1"); return true },
+			want: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\n    <PackageReference Include=\"Polly\" Version=\"8.3.1\" />\n  </ItemGroup>\n  <ItemGroup Condition=\"'$(TargetFramework)' == 'net48'\">\n    <PackageReference Include=\"System.ValueTuple\" Version=\"4.5.0\" />\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name: "add to the framework's group",
+			text: "<Project>\n  <ItemGroup Condition=\" '$(TargetFramework)'=='net48' \">\n    <PackageReference Include=\"System.ValueTuple\" Version=\"4.5.0\" />\n  </ItemGroup>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\n  </ItemGroup>\n</Project>\n",
+			edit: func(e *Editor) bool {
+				e.ForFramework("net48")
+				e.AddItem("PackageReference", "System.Memory", "4.5.5")
+				return true
+			},
+			want: "<Project>\n  <ItemGroup Condition=\" '$(TargetFramework)'=='net48' \">\n    <PackageReference Include=\"System.ValueTuple\" Version=\"4.5.0\" />\n    <PackageReference Include=\"System.Memory\" Version=\"4.5.5\" />\n  </ItemGroup>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name: "add in a new group for the framework",
+			text: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\n  </ItemGroup>\n</Project>\n",
+			edit: func(e *Editor) bool {
+				e.ForFramework("net48")
+				e.AddItem("PackageReference", "System.Memory", "4.5.5")
+				return true
+			},
+			want: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\n  </ItemGroup>\n\n  <ItemGroup Condition=\"'$(TargetFramework)' == 'net48'\">\n    <PackageReference Include=\"System.Memory\" Version=\"4.5.5\" />\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name: "scoped edits find the framework's reference only",
+			text: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Polly\" Version=\"8.3.1\" />\n    <PackageReference Include=\"Serilog\" Version=\"2.12.0\" Condition=\"$(TargetFramework.StartsWith('net4'))\" />\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" Condition=\"'$(TargetFramework)' == 'net8.0'\" />\n  </ItemGroup>\n</Project>\n",
+			edit: func(e *Editor) bool {
+				e.ForFramework("net8.0")
+				return !e.HasItem("PackageReference", "Polly") && e.SetItemMetadata("PackageReference", "Serilog", "Version", "3.1.0")
+			},
+			want: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Polly\" Version=\"8.3.1\" />\n    <PackageReference Include=\"Serilog\" Version=\"2.12.0\" Condition=\"$(TargetFramework.StartsWith('net4'))\" />\n    <PackageReference Include=\"Serilog\" Version=\"3.1.0\" Condition=\"'$(TargetFramework)' == 'net8.0'\" />\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name: "scoped remove",
+			text: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\n  </ItemGroup>\n  <ItemGroup Condition=\"'$(TargetFramework)' == 'net48'\">\n    <PackageReference Include=\"Serilog\" Version=\"2.12.0\" />\n  </ItemGroup>\n</Project>\n",
+			edit: func(e *Editor) bool {
+				e.ForFramework("net48")
+				return e.RemoveItem("PackageReference", "Serilog")
+			},
+			want: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name: "comment above an item",
+			text: "<Project>\r\n  <ItemGroup>\r\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\r\n  </ItemGroup>\r\n</Project>\r\n",
+			edit: func(e *Editor) bool {
+				return e.CommentItem("PackageReference", "serilog", "Pinned -- see https://example.com/a--b-")
+			},
+			want: "<Project>\r\n  <ItemGroup>\r\n    <!-- Pinned - see https://example.com/a-b -->\r\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\r\n  </ItemGroup>\r\n</Project>\r\n",
+		},
+		{
+			name: "remove an item and its line",
+			text: "<Project>\r\n  <ItemGroup>\r\n    <PackageReference Include=\"Serilog\">\r\n      <Version>3.0.0</Version>\r\n    </PackageReference>\r\n    <PackageReference Include=\"Polly\" Version=\"8.3.1\" />\r\n  </ItemGroup>\r\n</Project>\r\n",
+			edit: func(e *Editor) bool { return e.RemoveItem("PackageReference", "serilog") },
+			want: "<Project>\r\n  <ItemGroup>\r\n    <PackageReference Include=\"Polly\" Version=\"8.3.1\" />\r\n  </ItemGroup>\r\n</Project>\r\n",
+		},
+		{
+			name: "remove the only item of a group",
+			text: "<Project>\n  <PropertyGroup>\n    <TargetFramework>net8.0</TargetFramework>\n  </PropertyGroup>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\n  </ItemGroup>\n</Project>\n",
+			edit: func(e *Editor) bool { return e.RemoveItem("PackageReference", "Serilog") },
+			want: "<Project>\n  <PropertyGroup>\n    <TargetFramework>net8.0</TargetFramework>\n  </PropertyGroup>\n</Project>\n",
+		},
+		{
+			name: "remove an item sharing its line",
+			text: "<Project><ItemGroup><PackageReference Include='A' /><PackageReference Include='B' /></ItemGroup></Project>",
+			edit: func(e *Editor) bool { return e.RemoveItem("PackageReference", "A") },
+			want: "<Project><ItemGroup><PackageReference Include='B' /></ItemGroup></Project>",
+		},
+		{
+			name: "remove an unknown item",
+			text: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\n  </ItemGroup>\n</Project>\n",
+			edit: func(e *Editor) bool { return !e.RemoveItem("PackageReference", "Polly") },
+			want: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\n  </ItemGroup>\n</Project>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			editor := NewEditor(tt.text)
+			if !tt.edit(editor) {
+				t.Fatal("edit reported failure")
+			}
+			if got := editor.String(); got != tt.want {
+				t.Errorf("edited =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestEditorProperties tests reading and replacing properties
+func TestEditorProperties(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "Directory.Packages.props"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	editor := NewEditor(string(data))
+
+	if value, ok := editor.Property("managePackageVersionsCentrally"); !ok || value != "true" {
+		t.Errorf("Property(ManagePackageVersionsCentrally) = %q, %v, want true", value, ok)
+	}
+	if editor.HasProperty("PackageVersion") {
+		t.Error("items are not properties")
+	}
+	if value, ok := editor.ItemMetadata("PackageVersion", "Polly", "Version"); !ok || value != "[8.3.1]" {
+		t.Errorf("ItemMetadata(Polly) = %q, %v", value, ok)
+	}
+
+	if !editor.SetProperty("CentralPackageTransitivePinningEnabled", "false") {
+		t.Fatal("SetProperty() found no property")
+	}
+	if !replacedOnce(string(data), editor.String(), "<CentralPackageTransitivePinningEnabled>true", "<CentralPackageTransitivePinningEnabled>false") {
+		t.Errorf("SetProperty() changed more than the value:\n%s", editor.String())
+	}
+}
+
+// TestSetPackageVersionCentral tests that central package management is resolved from
+// the project, then Directory.Build.props, then Directory.Packages.props
+func TestSetPackageVersionCentral(t *testing.T) {
+	const packagesProps = "<Project>\n  <ItemGroup>\n    <PackageVersion Include=\"Polly\" Version=\"8.3.1\" />\n  </ItemGroup>\n</Project>\n"
+	tests := []struct {
+		name        string
+		buildProps  string
+		project     string
+		wantProject string
+		wantProps   string
+	}{
+		{
+			name:        "enabled in Directory.Build.props",
+			buildProps:  "<Project>\n  <PropertyGroup>\n    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>\n  </PropertyGroup>\n</Project>\n",
+			project:     "<Project Sdk=\"Microsoft.NET.Sdk\">\n  <ItemGroup>\n    <PackageReference Include=\"Polly\" />\n  </ItemGroup>\n</Project>\n",
+			wantProject: "<PackageReference Include=\"Serilog\" />",
+			wantProps:   "<PackageVersion Include=\"Serilog\" Version=\"3.1.1\" />",
+		},
+		{
+			name:        "project opts out",
+			buildProps:  "<Project>\n  <PropertyGroup>\n    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>\n  </PropertyGroup>\n</Project>\n",
+			project:     "<Project Sdk=\"Microsoft.NET.Sdk\">\n  <PropertyGroup>\n    <ManagePackageVersionsCentrally>false</ManagePackageVersionsCentrally>\n  </PropertyGroup>\n  <ItemGroup>\n    <PackageReference Include=\"Polly\" Version=\"8.3.1\" />\n  </ItemGroup>\n</Project>\n",
+			wantProject: "<PackageReference Include=\"Serilog\" Version=\"3.1.1\" />",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			path := filepath.Join(root, "src", "App", "App.csproj")
+			files := map[string]string{
+				filepath.Join(root, "Directory.Build.props"): tt.buildProps,
+				filepath.Join(root, PackagesPropsFile):       packagesProps,
+				path:                                         tt.project,
+			}
+			for name, text := range files {
+				if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(name, []byte(text), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if _, err := SetPackageVersion(path, "Serilog", "3.1.1"); err != nil {
+				t.Fatal(err)
+			}
+			project, _ := os.ReadFile(path)
+			if !strings.Contains(string(project), tt.wantProject) {
+				t.Errorf("project =\n%s\nwant %s", project, tt.wantProject)
+			}
+			props, _ := os.ReadFile(filepath.Join(root, PackagesPropsFile))
+			switch {
+			case tt.wantProps == "" && string(props) != packagesProps:
+				t.Errorf("Directory.Packages.props changed:\n%s", props)
+			case tt.wantProps != "" && !strings.Contains(string(props), tt.wantProps):
+				t.Errorf("Directory.Packages.props =\n%s\nwant %s", props, tt.wantProps)
+			}
+		})
+	}
+}
@@ -0,0 +1,456 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+)
+
+// ImportsKind identifies a project's import chain in versioned JSON output.
+const ImportsKind = "imports"
+
+// Import kinds: how a file comes into a project's evaluation.
+const (
+	ImportSDK       = "sdk"       // An MSBuild SDK's Sdk.props or Sdk.targets
+	ImportDirectory = "directory" // Directory.Build.props or Directory.Build.targets, found above the project
+	ImportRestore   = "restore"   // Written to obj by restore (App.csproj.nuget.g.props and .targets)
+	ImportCentral   = "central"   // Directory.Packages.props, the central package versions
+	ImportProject   = "project"   // The project file itself
+	ImportExplicit  = "import"    // An <Import> element of another file in the chain
+)
+
+// Import is a file in a project's import chain.
+type Import struct {
+	Path        string       `json:"path"` // "" when the path could not be worked out
+	Kind        string       `json:"kind"`
+	Depth       int          `json:"depth"`               // How many <Import> elements deep it is
+	Project     string       `json:"project,omitempty"`   // The Project attribute of an <Import>, or the SDK, as written
+	Condition   string       `json:"condition,omitempty"` // The <Import>'s condition, not evaluated
+	Missing     bool         `json:"missing,omitempty"`   // No such file, or the path could not be worked out
+	Duplicate   bool         `json:"duplicate,omitempty"` // Imported earlier in the chain, so MSBuild skips it (warning MSB4011)
+	Definitions []Definition `json:"definitions,omitempty"`
+
+	properties []Definition // Every property the file defines, for Trace
+}
+
+// Definition is a package item or a version property that a file in an import chain
+// defines.
+type Definition struct {
+	Kind      string `json:"kind"`             // The item type (PackageReference, PackageVersion, GlobalPackageReference), or "property"
+	Name      string `json:"name"`             // Package ID or property name
+	Value     string `json:"value"`            // The version or property value, as written
+	Update    bool   `json:"update,omitempty"` // An Update item, which changes items included before it
+	Condition string `json:"condition,omitempty"`
+	Line      int    `json:"line"`
+}
+
+// packageItems are the item types that reference or version packages.
+var packageItems = []string{"PackageReference", "PackageVersion", "GlobalPackageReference"}
+
+// ImportChain returns the files MSBuild evaluates for a project, in evaluation order,
+// as far as the project files themselves tell: the SDK's Sdk.props, Directory.Build.props,
+// the props restore writes to obj, Directory.Packages.props, the project, then the SDK's
+// Sdk.targets, Directory.Build.targets, and restore's targets, each followed by the files
+// it imports. The SDK's own files are listed but not followed. sdkDir is the .NET SDK's
+// directory (e.g., /usr/share/dotnet/sdk/8.0.400), or "" when it is not known.
+//
+// Properties are tracked as they are defined, ignoring conditions, to resolve import
+// paths; an import whose path uses a property the chain does not define is listed as
+// missing.
+func ImportChain(path, sdkDir string) ([]Import, error) {
+	// #nosec G304 -- path is a project file in the user's workspace
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	path, _ = filepath.Abs(path)
+	dir := filepath.Dir(path)
+	file := filepath.Base(path)
+	c := &importChain{
+		sdkDir: sdkDir,
+		seen:   make(map[string]bool),
+		properties: map[string]string{
+			"msbuildprojectdirectory":    dir,
+			"msbuildprojectfile":         file,
+			"msbuildprojectname":         strings.TrimSuffix(file, filepath.Ext(file)),
+			"msbuildprojectfullpath":     path,
+			"msbuildprojectextension":    filepath.Ext(file),
+			"baseintermediateoutputpath": "obj" + string(filepath.Separator),
+		},
+	}
+
+	sdks := projectSdks(string(data))
+	for _, sdk := range sdks {
+		c.sdk(sdk, "Sdk.props")
+	}
+	if len(sdks) > 0 {
+		if props := findAbove(dir, "Directory.Build.props"); props != "" {
+			c.visit(props, ImportDirectory, 0, "", "")
+		}
+		c.restore(dir, file, ".props")
+		if props := FindPackagesProps(dir); props != "" {
+			c.visit(props, ImportCentral, 0, "", "")
+		}
+	}
+	c.visit(path, ImportProject, 0, "", "")
+	if len(sdks) > 0 {
+		for _, sdk := range sdks {
+			c.sdk(sdk, "Sdk.targets")
+		}
+		if targets := findAbove(dir, "Directory.Build.targets"); targets != "" {
+			c.visit(targets, ImportDirectory, 0, "", "")
+		}
+		c.restore(dir, file, ".targets")
+	}
+	return c.imports, nil
+}
+
+// importChain collects an import chain.
+type importChain struct {
+	sdkDir     string
+	imports    []Import
+	seen       map[string]bool
+	properties map[string]string // By lowercase name; the last definition wins
+}
+
+// sdkRef is an MSBuild SDK a project uses.
+type sdkRef struct {
+	name    string
+	version string // From Name/Version; "" for the SDKs the .NET SDK carries
+}
+
+// projectSdks returns the SDKs of the Project element's Sdk attribute and its <Sdk>
+// elements.
+func projectSdks(text string) []sdkRef {
+	elems := elements(scanTags(text))
+	if len(elems) == 0 {
+		return nil
+	}
+	var sdks []sdkRef
+	if a, ok := elems[0].open.attr("Sdk"); ok {
+		for _, s := range strings.Split(unescapeText(text[a.valueStart:a.valueEnd]), ";") {
+			name, version, _ := strings.Cut(strings.TrimSpace(s), "/")
+			if name != "" {
+				sdks = append(sdks, sdkRef{name, version})
+			}
+		}
+	}
+	for _, el := range elems {
+		if el.parent == 0 && strings.EqualFold(el.open.name, "Sdk") {
+			name, _ := el.open.attr("Name")
+			version, _ := el.open.attr("Version")
+			sdks = append(sdks, sdkRef{text[name.valueStart:name.valueEnd], text[version.valueStart:version.valueEnd]})
+		}
+	}
+	return sdks
+}
+
+// sdk lists an SDK's Sdk.props or Sdk.targets: from the global packages folder when the
+// SDK has a version, and from the .NET SDK otherwise.
+func (c *importChain) sdk(sdk sdkRef, name string) {
+	imp := Import{Kind: ImportSDK, Project: sdk.name}
+	switch {
+	case sdk.version != "":
+		imp.Project += "/" + sdk.version
+		imp.Path = filepath.Join(nuget.PackageDir(nuget.GlobalPackagesDir(), sdk.name, sdk.version), "Sdk", name)
+	case c.sdkDir != "":
+		imp.Path = filepath.Join(c.sdkDir, "Sdks", sdk.name, "Sdk", name)
+	}
+	if _, err := os.Stat(imp.Path); imp.Path == "" || err != nil {
+		imp.Missing = true
+	}
+	c.imports = append(c.imports, imp)
+}
+
+// restore visits the props or targets restore writes for a project
+// (obj/App.csproj.nuget.g.props, ...), in name order as MSBuild imports them.
+func (c *importChain) restore(dir, file, ext string) {
+	obj := c.properties["msbuildprojectextensionspath"]
+	if obj == "" {
+		obj = c.properties["baseintermediateoutputpath"]
+	}
+	obj = nativePath(obj)
+	if !filepath.IsAbs(obj) {
+		obj = filepath.Join(dir, obj)
+	}
+	matches, _ := filepath.Glob(filepath.Join(obj, file+".*"+ext))
+	slices.Sort(matches)
+	for _, m := range matches {
+		c.visit(m, ImportRestore, 0, "", "")
+	}
+}
+
+// visit adds a file to the chain, with its definitions, followed by the files it imports.
+func (c *importChain) visit(path, kind string, depth int, project, condition string) {
+	imp := Import{Path: path, Kind: kind, Depth: depth, Project: project, Condition: condition}
+	key := strings.ToLower(path)
+	if c.seen[key] {
+		imp.Duplicate = true
+		c.imports = append(c.imports, imp)
+		return
+	}
+	c.seen[key] = true
+	// #nosec G304 -- imports of a project in the user's workspace
+	data, err := os.ReadFile(path)
+	if err != nil {
+		imp.Missing = true
+		c.imports = append(c.imports, imp)
+		return
+	}
+	c.imports = append(c.imports, imp)
+	pos := len(c.imports) - 1
+
+	text := string(data)
+	thisDir := filepath.Dir(path) + string(filepath.Separator)
+	this := map[string]string{
+		"msbuildthisfiledirectory": thisDir,
+		"msbuildthisfile":          filepath.Base(path),
+		"msbuildthisfilename":      strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		"msbuildthisfilefullpath":  path,
+	}
+	e := NewEditor(text)
+	elems := elements(scanTags(text))
+	for i, el := range elems {
+		if el.parent < 0 {
+			continue
+		}
+		parent := elems[el.parent].open.name
+		line := strings.Count(text[:el.open.start], "\n") + 1
+		switch {
+		case strings.EqualFold(parent, "PropertyGroup"):
+			start, end, ok := e.content(el)
+			if !ok {
+				continue
+			}
+			value := unescapeText(text[start:end])
+			if expanded, ok := c.expand(value, this); ok {
+				c.properties[strings.ToLower(el.open.name)] = expanded
+			}
+			d := Definition{Kind: "property", Name: el.open.name, Value: value, Condition: e.condition(elems, i), Line: line}
+			c.imports[pos].properties = append(c.imports[pos].properties, d)
+			if strings.Contains(strings.ToLower(el.open.name), "version") {
+				c.imports[pos].Definitions = append(c.imports[pos].Definitions, d)
+			}
+		case strings.EqualFold(parent, "ItemGroup") && slices.ContainsFunc(packageItems, func(k string) bool { return strings.EqualFold(k, el.open.name) }):
+			d := Definition{Kind: el.open.name, Condition: e.condition(elems, i), Line: line}
+			if a, ok := el.open.attr("Include"); ok {
+				d.Name = unescapeText(text[a.valueStart:a.valueEnd])
+			} else if a, ok := el.open.attr("Update"); ok {
+				d.Name, d.Update = unescapeText(text[a.valueStart:a.valueEnd]), true
+			}
+			for _, name := range []string{"Version", "VersionOverride"} {
+				if start, end, ok := e.metadata(elems, i, name); ok {
+					d.Value = unescapeText(text[start:end])
+					break
+				}
+			}
+			if d.Name != "" {
+				c.imports[pos].Definitions = append(c.imports[pos].Definitions, d)
+			}
+		case strings.EqualFold(el.open.name, "Import") && (el.parent == 0 || strings.EqualFold(parent, "ImportGroup")):
+			c.importElement(e, elems, i, depth+1, this)
+		}
+	}
+}
+
+// importElement visits the files an <Import> element imports.
+func (c *importChain) importElement(e *Editor, elems []element, pos, depth int, this map[string]string) {
+	el := elems[pos]
+	a, _ := el.open.attr("Project")
+	project := unescapeText(e.text[a.valueStart:a.valueEnd])
+	condition := e.condition(elems, pos)
+	if sdk, ok := el.open.attr("Sdk"); ok {
+		name := strings.TrimSpace(e.text[sdk.valueStart:sdk.valueEnd])
+		ref := sdkRef{}
+		ref.name, ref.version, _ = strings.Cut(name, "/")
+		c.sdk(ref, filepath.Base(nativePath(project)))
+		c.imports[len(c.imports)-1].Depth = depth
+		return
+	}
+
+	resolved, ok := c.expand(project, this)
+	if !ok || strings.TrimSpace(resolved) == "" {
+		c.imports = append(c.imports, Import{Kind: ImportExplicit, Depth: depth, Project: project, Condition: condition, Missing: true})
+		return
+	}
+	resolved = nativePath(strings.TrimSpace(resolved))
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(this["msbuildthisfiledirectory"], resolved)
+	}
+	if strings.ContainsAny(resolved, "*?") {
+		matches, _ := filepath.Glob(resolved)
+		slices.Sort(matches)
+		for _, m := range matches {
+			c.visit(m, ImportExplicit, depth, project, condition)
+		}
+		return
+	}
+	c.visit(filepath.Clean(resolved), ImportExplicit, depth, project, condition)
+}
+
+// expand expands the properties in a value: those of this file, those the chain has
+// defined so far, and the [MSBuild]::GetPathOfFileAbove and GetDirectoryNameOfFileAbove
+// functions. ok is false when the value uses anything else.
+func (c *importChain) expand(value string, this map[string]string) (string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if !strings.HasPrefix(value[i:], "$(") {
+			b.WriteByte(value[i])
+			continue
+		}
+		end := parenEnd(value[i:], 1)
+		if end < 0 {
+			return "", false
+		}
+		expr := strings.TrimSpace(value[i+2 : i+end])
+		i += end
+
+		if function, ok := strings.CutPrefix(expr, "[MSBuild]::"); ok {
+			name, args, ok := splitCall(function)
+			if !ok || len(args) == 0 {
+				return "", false
+			}
+			for j, arg := range args {
+				if args[j], ok = c.expand(arg, this); !ok {
+					return "", false
+				}
+			}
+			switch strings.ToLower(name) {
+			case "getpathoffileabove":
+				start := this["msbuildthisfiledirectory"]
+				if len(args) > 1 {
+					start = args[1]
+				}
+				b.WriteString(findAbove(filepath.Clean(nativePath(start)), args[0]))
+			case "getdirectorynameoffileabove":
+				if len(args) != 2 {
+					return "", false
+				}
+				b.WriteString(filepath.Dir(findAbove(filepath.Clean(nativePath(args[0])), args[1])))
+			default:
+				return "", false
+			}
+			continue
+		}
+
+		key := strings.ToLower(expr)
+		v, ok := this[key]
+		if !ok {
+			v, ok = c.properties[key]
+		}
+		if !ok {
+			return "", false
+		}
+		b.WriteString(v)
+	}
+	return b.String(), true
+}
+
+// condition returns the condition of an element, or of its parent group.
+func (e *Editor) condition(elems []element, pos int) string {
+	if a, ok := elems[pos].open.attr("Condition"); ok {
+		return unescapeText(e.text[a.valueStart:a.valueEnd])
+	}
+	if parent := elems[pos].parent; parent > 0 {
+		if a, ok := elems[parent].open.attr("Condition"); ok {
+			return unescapeText(e.text[a.valueStart:a.valueEnd])
+		}
+	}
+	return ""
+}
+
+// findAbove returns the path of a file in dir or the nearest of its parents that has it,
+// or "".
+func findAbove(dir, name string) string {
+	for {
+		candidate := filepath.Join(dir, name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// nativePath converts the backslashes MSBuild files use on every platform to the
+// platform's separator.
+func nativePath(p string) string {
+	return filepath.FromSlash(strings.ReplaceAll(p, `\`, "/"))
+}
+
+// Trace returns the files of an import chain that decide a package's version, with only
+// the definitions that do: the package's items, and the properties their versions use,
+// directly or through other properties. Later definitions override earlier ones.
+func Trace(chain []Import, id string) []Import {
+	used := make(map[string]bool)
+	for _, imp := range chain {
+		for _, d := range imp.Definitions {
+			if d.Kind != "property" && strings.EqualFold(d.Name, id) {
+				for _, name := range propertyRefs(d.Value) {
+					used[name] = true
+				}
+			}
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, imp := range chain {
+			for _, d := range imp.properties {
+				if !used[strings.ToLower(d.Name)] {
+					continue
+				}
+				for _, name := range propertyRefs(d.Value) {
+					if !used[name] {
+						used[name], changed = true, true
+					}
+				}
+			}
+		}
+	}
+
+	var traced []Import
+	for _, imp := range chain {
+		var defs []Definition
+		for _, d := range imp.Definitions {
+			if d.Kind != "property" && strings.EqualFold(d.Name, id) {
+				defs = append(defs, d)
+			}
+		}
+		for _, d := range imp.properties {
+			if used[strings.ToLower(d.Name)] {
+				defs = append(defs, d)
+			}
+		}
+		if len(defs) > 0 {
+			slices.SortStableFunc(defs, func(a, b Definition) int { return a.Line - b.Line })
+			imp.Definitions = defs
+			traced = append(traced, imp)
+		}
+	}
+	return traced
+}
+
+// propertyRefs returns the lowercase names of the properties a value uses as $(Name).
+func propertyRefs(value string) []string {
+	var names []string
+	for {
+		start := strings.Index(value, "$(")
+		if start < 0 {
+			return names
+		}
+		value = value[start+2:]
+		n := 0
+		for n < len(value) && (isWordByte(value[n]) && value[n] != '.' && value[n] != '-') {
+			n++
+		}
+		if n > 0 && n < len(value) && value[n] == ')' {
+			names = append(names, strings.ToLower(value[:n]))
+		}
+	}
+}
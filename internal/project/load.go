@@ -0,0 +1,49 @@
+package project
+
+import "iter"
+
+// LoadAll reads project files through the cache (a nil Cache reads every file) with up
+// to workers at a time, and yields each in the order of paths as soon as it and those
+// before it are read, so callers can show the first projects of a large solution while
+// the rest are parsed. Stopping the iteration stops the workers.
+func (c *Cache) LoadAll(paths []string, workers int) iter.Seq2[*Project, error] {
+	return func(yield func(*Project, error) bool) {
+		type loaded struct {
+			p   *Project
+			err error
+		}
+		results := make([]chan loaded, len(paths))
+		for i := range results {
+			results[i] = make(chan loaded, 1)
+		}
+
+		jobs := make(chan int)
+		stop := make(chan struct{})
+		defer close(stop)
+		go func() {
+			defer close(jobs)
+			for i := range paths {
+				select {
+				case jobs <- i:
+				case <-stop:
+					return
+				}
+			}
+		}()
+		for range min(max(workers, 1), len(paths)) {
+			go func() {
+				for i := range jobs {
+					p, err := c.Load(paths[i])
+					results[i] <- loaded{p, err}
+				}
+			}()
+		}
+
+		for i := range paths {
+			r := <-results[i]
+			if !yield(r.p, r.err) {
+				return
+			}
+		}
+	}
+}
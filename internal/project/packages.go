@@ -0,0 +1,130 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SetPackageVersion makes the project reference the package at version, adding the
+// reference when it has none and keeping the rest of the file byte for byte. Under central
+// package management the version is written to Directory.Packages.props instead, unless the
+// reference sets its own version. It returns the files changed.
+func SetPackageVersion(path, id, version string) ([]string, error) {
+	return SetPackageVersionFor(path, "", id, version)
+}
+
+// SetPackageVersionFor is SetPackageVersion for the reference only one target framework
+// of a multi-targeting project has, scoped as Editor.ForFramework scopes edits: a new
+// reference is added under the framework's condition. "" is every framework.
+func SetPackageVersionFor(path, framework, id, version string) ([]string, error) {
+	central, err := CentralManagement(path)
+	if err != nil {
+		return nil, err
+	}
+	props := FindPackagesProps(filepath.Dir(path))
+	if central && props == "" {
+		return nil, fmt.Errorf("central package management is enabled for %s but there is no %s above it", path, PackagesPropsFile)
+	}
+
+	var changed []string
+	projectChanged := true
+	err = EditFile(path, func(e *Editor) error {
+		e.ForFramework(framework)
+		_, hasVersion := e.ItemMetadata("PackageReference", id, "Version")
+		_, hasOverride := e.ItemMetadata("PackageReference", id, "VersionOverride")
+
+		switch {
+		case !e.HasItem("PackageReference", id) && central:
+			e.AddItem("PackageReference", id, "")
+		case !e.HasItem("PackageReference", id):
+			e.AddItem("PackageReference", id, version)
+		case hasOverride && central:
+			// VersionOverride takes precedence over the central version
+			e.SetItemMetadata("PackageReference", id, "VersionOverride", version)
+			central = false
+		case hasVersion:
+			// A version on the reference itself takes precedence over the central one
+			e.SetItemMetadata("PackageReference", id, "Version", version)
+			central = false
+		case central:
+			projectChanged = false
+		default:
+			return fmt.Errorf("the reference to %s in %s has no version and central package management is not enabled", id, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if projectChanged {
+		changed = append(changed, path)
+	}
+
+	if central {
+		err := EditFile(props, func(e *Editor) error {
+			if e.HasItem("PackageVersion", id) {
+				e.SetItemMetadata("PackageVersion", id, "Version", version)
+			} else {
+				e.AddItem("PackageVersion", id, version)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		changed = append(changed, props)
+	}
+
+	return changed, nil
+}
+
+// PinPackage makes the project reference the package at version, like SetPackageVersion.
+// A top-level reference takes precedence over the versions dependencies request, so this
+// is how a transitive package is pinned; when the reference is new, reason is written as a
+// comment above it so the next reader knows why the project references a package it does
+// not use directly. It returns the files changed.
+func PinPackage(path, id, version, reason string) ([]string, error) {
+	return PinPackageFor(path, "", id, version, reason)
+}
+
+// PinPackageFor is PinPackage scoped to one target framework, like SetPackageVersionFor.
+func PinPackageFor(path, framework, id, version, reason string) ([]string, error) {
+	// #nosec G304 -- path is a project file in the user's workspace
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	editor := NewEditor(string(data))
+	editor.ForFramework(framework)
+	referenced := editor.HasItem("PackageReference", id)
+
+	changed, err := SetPackageVersionFor(path, framework, id, version)
+	if err != nil || referenced || reason == "" {
+		return changed, err
+	}
+	err = EditFile(path, func(e *Editor) error {
+		e.ForFramework(framework)
+		e.CommentItem("PackageReference", id, reason)
+		return nil
+	})
+	return changed, err
+}
+
+// RemovePackage removes the project's reference to a package. Its central PackageVersion is
+// left in place, since other projects may use it. It reports whether there was a reference.
+func RemovePackage(path, id string) (bool, error) {
+	return RemovePackageFor(path, "", id)
+}
+
+// RemovePackageFor is RemovePackage for the reference under a condition that applies to
+// one target framework; a reference without a condition is kept.
+func RemovePackageFor(path, framework, id string) (bool, error) {
+	removed := false
+	err := EditFile(path, func(e *Editor) error {
+		e.ForFramework(framework)
+		removed = e.RemoveItem("PackageReference", id)
+		return nil
+	})
+	return removed, err
+}
@@ -0,0 +1,382 @@
+// Package project reads and edits MSBuild project files (.csproj, .fsproj, .vbproj).
+//
+// Reading uses encoding/xml. Editing never re-serializes the document: changes are
+// applied as surgical text replacements so formatting, comments, and elements LazyNuGet
+// does not understand survive byte for byte.
+package project
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// PackagesPropsFile is the file that holds versions under central package management.
+const PackagesPropsFile = "Directory.Packages.props"
+
+// Extensions are the project file extensions LazyNuGet manages.
+var Extensions = []string{".csproj", ".fsproj", ".vbproj"}
+
+// SkippedDirs are never scanned for projects (build output, VCS, and dependency folders).
+var SkippedDirs = map[string]bool{
+	".git": true, ".vs": true, ".idea": true, "bin": true, "obj": true,
+	"node_modules": true, "packages": true, "artifacts": true,
+}
+
+// Project is the part of a project file LazyNuGet works with.
+type Project struct {
+	Path              string
+	Sdk               string
+	TargetFrameworks  []string // From TargetFrameworks, or the single TargetFramework
+	UseMaui           bool     // <UseMaui>true</UseMaui>: a .NET MAUI app or library
+	PackageReferences []PackageReference
+	PackageVersions   []PackageReference // <PackageVersion> items of a Directory.Packages.props
+	// Shared frameworks beyond the SDK's own (e.g., Microsoft.AspNetCore.App in a worker)
+	FrameworkReferences []FrameworkReference
+}
+
+// FrameworkReference is a <FrameworkReference Include="..."> item: a shared framework the
+// SDK provides, never restored from a feed.
+type FrameworkReference struct {
+	ID        string
+	Condition string // The item's condition, or its ItemGroup's
+}
+
+// PackageReference is a <PackageReference Include="..."> item.
+type PackageReference struct {
+	ID            string
+	Version       string // Version attribute or child element; empty under central package management
+	Condition     string // The item's condition, or its ItemGroup's
+	PrivateAssets string // Assets that do not flow to consuming projects (e.g., "all")
+	IncludeAssets string // Assets the project consumes (default: all)
+	ExcludeAssets string // Assets the project ignores
+	NoWarn        string // Warning codes not reported for this package (e.g., "NU1701")
+}
+
+// metadata returns the field for a metadata name (case-insensitively), or nil.
+func (r *PackageReference) metadata(name string) *string {
+	switch strings.ToLower(name) {
+	case "version":
+		return &r.Version
+	case "privateassets":
+		return &r.PrivateAssets
+	case "includeassets":
+		return &r.IncludeAssets
+	case "excludeassets":
+		return &r.ExcludeAssets
+	case "nowarn":
+		return &r.NoWarn
+	}
+	return nil
+}
+
+// Name returns the project name (the file name without extension).
+func (p *Project) Name() string {
+	return strings.TrimSuffix(filepath.Base(p.Path), filepath.Ext(p.Path))
+}
+
+// MultiTargeting reports whether the project uses <TargetFrameworks>.
+func (p *Project) MultiTargeting() bool {
+	return len(p.TargetFrameworks) > 1
+}
+
+// Reference returns the package reference with the given ID (case-insensitively).
+func (p *Project) Reference(id string) (PackageReference, bool) {
+	for _, ref := range p.PackageReferences {
+		if strings.EqualFold(ref.ID, id) {
+			return ref, true
+		}
+	}
+	return PackageReference{}, false
+}
+
+// IsProjectFile reports whether name has a project file extension.
+func IsProjectFile(name string) bool {
+	return slices.Contains(Extensions, strings.ToLower(filepath.Ext(name)))
+}
+
+// Load reads and parses a project file.
+func Load(path string) (*Project, error) {
+	// #nosec G304 -- path is a project file in the user's workspace
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	p.Path = path
+	return p, nil
+}
+
+// Parse parses project file content.
+func Parse(data []byte) (*Project, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	p := &Project{}
+	var (
+		stack            []string // Element names from the root
+		itemCondition    string   // Condition of the enclosing ItemGroup
+		current          *PackageReference
+		targetFramework  string
+		targetFrameworks string
+	)
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := t.Name.Local
+			parent := ""
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+			stack = append(stack, name)
+
+			switch {
+			case strings.EqualFold(name, "Project") && len(stack) == 1:
+				p.Sdk = attr(t, "Sdk")
+			case strings.EqualFold(name, "ItemGroup"):
+				itemCondition = attr(t, "Condition")
+			case (strings.EqualFold(name, "PackageReference") || strings.EqualFold(name, "PackageVersion")) && strings.EqualFold(parent, "ItemGroup"):
+				if id := attr(t, "Include"); id != "" {
+					condition := attr(t, "Condition")
+					if condition == "" {
+						condition = itemCondition
+					}
+					items := &p.PackageReferences
+					if strings.EqualFold(name, "PackageVersion") {
+						items = &p.PackageVersions
+					}
+					*items = append(*items, PackageReference{
+						ID:            id,
+						Version:       attr(t, "Version"),
+						Condition:     condition,
+						PrivateAssets: attr(t, "PrivateAssets"),
+						IncludeAssets: attr(t, "IncludeAssets"),
+						ExcludeAssets: attr(t, "ExcludeAssets"),
+						NoWarn:        attr(t, "NoWarn"),
+					})
+					current = &(*items)[len(*items)-1]
+				}
+			case strings.EqualFold(name, "FrameworkReference") && strings.EqualFold(parent, "ItemGroup"):
+				if id := attr(t, "Include"); id != "" {
+					condition := attr(t, "Condition")
+					if condition == "" {
+						condition = itemCondition
+					}
+					p.FrameworkReferences = append(p.FrameworkReferences, FrameworkReference{ID: id, Condition: condition})
+				}
+			case strings.EqualFold(parent, "PropertyGroup") && strings.EqualFold(name, "TargetFramework"):
+				if text, err := elementText(decoder); err == nil && targetFramework == "" {
+					targetFramework = text
+				}
+				stack = stack[:len(stack)-1]
+			case strings.EqualFold(parent, "PropertyGroup") && strings.EqualFold(name, "TargetFrameworks"):
+				if text, err := elementText(decoder); err == nil && targetFrameworks == "" {
+					targetFrameworks = text
+				}
+				stack = stack[:len(stack)-1]
+			case strings.EqualFold(parent, "PropertyGroup") && strings.EqualFold(name, "UseMaui"):
+				if text, err := elementText(decoder); err == nil {
+					p.UseMaui = p.UseMaui || strings.EqualFold(strings.TrimSpace(text), "true")
+				}
+				stack = stack[:len(stack)-1]
+			case (strings.EqualFold(parent, "PackageReference") || strings.EqualFold(parent, "PackageVersion")) && current != nil:
+				// Metadata may also be written as child elements (<Version>, <PrivateAssets>, ...)
+				if field := current.metadata(name); field != nil {
+					if text, err := elementText(decoder); err == nil && *field == "" {
+						*field = text
+					}
+					stack = stack[:len(stack)-1]
+				}
+			}
+
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			switch {
+			case strings.EqualFold(t.Name.Local, "PackageReference"), strings.EqualFold(t.Name.Local, "PackageVersion"):
+				current = nil
+			case strings.EqualFold(t.Name.Local, "ItemGroup"):
+				itemCondition = ""
+			}
+		}
+	}
+
+	switch {
+	case targetFrameworks != "":
+		p.TargetFrameworks = splitList(targetFrameworks)
+	case targetFramework != "":
+		p.TargetFrameworks = []string{targetFramework}
+	}
+	return p, nil
+}
+
+// attr returns the value of an attribute (case-insensitively), or "".
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// elementText reads the text content of the current element, consuming its end tag.
+func elementText(decoder *xml.Decoder) (string, error) {
+	var sb strings.Builder
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", err
+		}
+		switch t := token.(type) {
+		case xml.CharData:
+			if depth == 0 {
+				sb.Write(t)
+			}
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				return strings.TrimSpace(sb.String()), nil
+			}
+			depth--
+		}
+	}
+}
+
+// splitList splits an MSBuild list ("net8.0;net48") into its non-empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// FindPackagesProps returns the nearest Directory.Packages.props at or above dir, or "".
+func FindPackagesProps(dir string) string {
+	return findAbove(dir, PackagesPropsFile)
+}
+
+// CentralVersion returns the version of a package in the Directory.Packages.props that
+// applies to a project, or "" when central package management is not enabled or the
+// package has no PackageVersion.
+func CentralVersion(projectPath, id string) string {
+	props := FindPackagesProps(filepath.Dir(projectPath))
+	if props == "" {
+		return ""
+	}
+	// #nosec G304 -- props is a Directory.Packages.props in the user's workspace
+	data, err := os.ReadFile(props)
+	if err != nil {
+		return ""
+	}
+	if central, _ := CentralManagement(projectPath); !central {
+		return ""
+	}
+	version, _ := NewEditor(string(data)).ItemMetadata("PackageVersion", id, "Version")
+	return version
+}
+
+// CentralManagement reports whether central package management is enabled for a project.
+// ManagePackageVersionsCentrally is read from the project, then the Directory.Build.props
+// above it, then Directory.Packages.props; the first file that sets it decides, so a
+// project can opt out with false.
+func CentralManagement(projectPath string) (bool, error) {
+	dir := filepath.Dir(projectPath)
+	for _, path := range []string{projectPath, findAbove(dir, "Directory.Build.props"), FindPackagesProps(dir)} {
+		if path == "" {
+			continue
+		}
+		// #nosec G304 -- path is a project or props file in the user's workspace
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return false, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if value, ok := NewEditor(string(data)).Property("ManagePackageVersionsCentrally"); ok {
+			return strings.EqualFold(strings.TrimSpace(value), "true"), nil
+		}
+	}
+	return false, nil
+}
+
+// Discover returns the project files under root, sorted, skipping build output and
+// dependency folders.
+func Discover(root string) ([]string, error) {
+	paths, err := Find(os.DirFS(root), IsProjectFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s for projects: %w", root, err)
+	}
+	for i, path := range paths {
+		paths[i] = filepath.Join(root, filepath.FromSlash(path))
+	}
+	return paths, nil
+}
+
+// Find returns the slash-separated paths of files in fsys whose names match, sorted,
+// skipping build output and dependency folders.
+func Find(fsys fs.FS, match func(name string) bool) ([]string, error) {
+	var paths []string
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == "." {
+				return err
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != "." && SkippedDirs[d.Name()] {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if match(d.Name()) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(paths)
+	return paths, nil
+}
+
+// Filter returns the slash-separated paths (e.g., from a git tree) whose file names match,
+// sorted, skipping the folders Find skips.
+func Filter(paths []string, match func(name string) bool) []string {
+	var matched []string
+	for _, p := range paths {
+		dirs := strings.Split(p, "/")
+		name := dirs[len(dirs)-1]
+		if !match(name) || slices.ContainsFunc(dirs[:len(dirs)-1], func(d string) bool { return SkippedDirs[d] }) {
+			continue
+		}
+		matched = append(matched, p)
+	}
+	slices.Sort(matched)
+	return matched
+}
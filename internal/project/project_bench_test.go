@@ -0,0 +1,69 @@
+package project
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// BenchmarkParse measures parsing a typical SDK-style project file
+// Target: <1ms, so a cold open of a 500-project repository stays well under a second
+func BenchmarkParse(b *testing.B) {
+	data := []byte(sampleProject)
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := Parse(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	avgTime := b.Elapsed() / time.Duration(b.N)
+	b.Logf("Average parse time: %v", avgTime)
+	if avgTime > time.Millisecond {
+		b.Errorf("Parse time %v exceeds 1ms target", avgTime)
+	}
+}
+
+// BenchmarkCacheWarmOpen measures reopening a repository of 500 projects with a warm cache
+func BenchmarkCacheWarmOpen(b *testing.B) {
+	root := b.TempDir()
+	for i := range 500 {
+		writeAged(b, root, fmt.Sprintf("src/Service%d/Service%d.csproj", i, i), sampleProject, time.Hour)
+	}
+	cachePath := CachePath(b.TempDir(), root)
+	c := OpenCache(cachePath)
+	paths, err := c.Discover(root)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, path := range paths {
+		if _, err := c.Load(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := c.Save(); err != nil {
+		b.Fatal(err)
+	}
+
+	for b.Loop() {
+		c := OpenCache(cachePath)
+		paths, err := c.Discover(root)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, path := range paths {
+			if _, err := c.Load(path); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if c.Parsed() != 0 {
+			b.Fatalf("warm open parsed %d projects", c.Parsed())
+		}
+	}
+
+	avgTime := b.Elapsed() / time.Duration(b.N)
+	b.Logf("Average warm open time: %v", avgTime)
+	if avgTime > time.Second {
+		b.Errorf("Warm open time %v exceeds 1s target for 500 projects", avgTime)
+	}
+}
@@ -0,0 +1,465 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+)
+
+// sampleProject is a typical SDK-style project with conditional references.
+const sampleProject = `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFrameworks>net8.0;net48</TargetFrameworks>
+    <Nullable>enable</Nullable>
+  </PropertyGroup>
+  <!-- Logging -->
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.1.0" />
+    <PackageReference Include="Newtonsoft.Json">
+      <Version>13.0.3</Version>
+    </PackageReference>
+  </ItemGroup>
+  <ItemGroup Condition="'$(TargetFramework)' == 'net48'">
+    <PackageReference Include="System.Memory" Version="4.5.5" />
+  </ItemGroup>
+  <ItemGroup Condition="'$(TargetFramework)' == 'net8.0'">
+    <FrameworkReference Include="Microsoft.AspNetCore.App" />
+  </ItemGroup>
+</Project>
+`
+
+// TestParse tests reading target frameworks and package references
+func TestParse(t *testing.T) {
+	p, err := Parse([]byte(sampleProject))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if p.Sdk != "Microsoft.NET.Sdk" {
+		t.Errorf("Sdk = %q", p.Sdk)
+	}
+	if !slices.Equal(p.TargetFrameworks, []string{"net8.0", "net48"}) || !p.MultiTargeting() {
+		t.Errorf("TargetFrameworks = %v", p.TargetFrameworks)
+	}
+
+	want := []PackageReference{
+		{ID: "Serilog", Version: "3.1.0"},
+		{ID: "Newtonsoft.Json", Version: "13.0.3"},
+		{ID: "System.Memory", Version: "4.5.5", Condition: "'$(TargetFramework)' == 'net48'"},
+	}
+	if !slices.Equal(p.PackageReferences, want) {
+		t.Errorf("PackageReferences = %+v, want %+v", p.PackageReferences, want)
+	}
+
+	if ref, ok := p.Reference("serilog"); !ok || ref.Version != "3.1.0" {
+		t.Errorf("Reference(serilog) = %+v, %v", ref, ok)
+	}
+	wantFrameworks := []FrameworkReference{{ID: "Microsoft.AspNetCore.App", Condition: "'$(TargetFramework)' == 'net8.0'"}}
+	if !slices.Equal(p.FrameworkReferences, wantFrameworks) {
+		t.Errorf("FrameworkReferences = %+v, want %+v", p.FrameworkReferences, wantFrameworks)
+	}
+	if p.UseMaui {
+		t.Error("UseMaui = true for a class library")
+	}
+
+	maui, err := Parse([]byte(`<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><UseMaui> True </UseMaui></PropertyGroup></Project>`))
+	if err != nil || !maui.UseMaui {
+		t.Errorf("Parse(MAUI project) = %+v, %v, want UseMaui", maui, err)
+	}
+}
+
+// TestEvaluateCondition tests evaluating the conditions that scope items to target frameworks
+func TestEvaluateCondition(t *testing.T) {
+	tests := []struct {
+		condition string
+		framework string
+		want      bool
+		ok        bool
+	}{
+		{"'$(TargetFramework)' == 'net48'", "net48", true, true},
+		{" '$(TargetFramework)'=='NET48' ", "net48", true, true},
+		{"'$(TargetFramework)' != 'net48'", "net8.0", true, true},
+		{"$(TargetFramework.StartsWith('net4'))", "net472", true, true},
+		{"!$(TargetFramework.StartsWith('net4'))", "net472", false, true},
+		{"'$(TargetFramework)' == 'net48' or '$(TargetFramework)' == 'net472'", "net472", true, true},
+		{"('$(TargetFramework)' == 'net8.0' Or '$(TargetFramework)' == 'net9.0') and '$(TargetFrameworkIdentifier)' == '.NETCoreApp'", "net9.0", true, true},
+		{"'$(TargetFrameworkIdentifier)' == '.NETFramework'", "net8.0", false, true},
+		{"'$(TargetFrameworkVersion)' == 'v4.7.2'", "net472", true, true},
+		{"'$([MSBuild]::GetTargetFrameworkIdentifier('$(TargetFramework)'))' == '.NETStandard'", "netstandard2.0", true, true},
+		{"$([MSBuild]::IsTargetFrameworkCompatible('$(TargetFramework)', 'net6.0'))", "net8.0", true, true},
+		{"$([MSBuild]::IsTargetFrameworkCompatible('$(TargetFramework)', 'net6.0'))", "net48", false, true},
+		{"'$(Configuration)' == 'Debug'", "net8.0", false, false},
+		{"'$(TargetFramework)' == 'net48' and", "net48", false, false},
+		{"'@(Compile)' != ''", "net48", false, false},
+	}
+	for _, tt := range tests {
+		got, ok := EvaluateCondition(tt.condition, tt.framework)
+		if ok != tt.ok || ok && got != tt.want {
+			t.Errorf("EvaluateCondition(%q, %s) = %v, %v, want %v, %v", tt.condition, tt.framework, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	p, err := Parse([]byte(sampleProject))
+	if err != nil {
+		t.Fatal(err)
+	}
+	memory, _ := p.Reference("System.Memory")
+	if frameworks, ok := p.ConditionFrameworks(memory.Condition); !ok || !slices.Equal(frameworks, []string{"net48"}) {
+		t.Errorf("ConditionFrameworks(%q) = %v, %v, want [net48]", memory.Condition, frameworks, ok)
+	}
+	if _, ok := p.ReferenceFor("System.Memory", "net8.0"); ok {
+		t.Error("ReferenceFor(System.Memory, net8.0) found the net48 reference")
+	}
+	if _, ok := p.ReferenceFor("Serilog", "net48"); ok {
+		t.Error("ReferenceFor(Serilog, net48) found a reference every framework has")
+	}
+	if !p.HasFramework("NET48") || p.HasFramework("net472") {
+		t.Error("HasFramework() does not match the target frameworks")
+	}
+}
+
+// TestDiscover tests finding project files while skipping build output
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	for _, path := range []string{"src/App/App.csproj", "src/Lib/Lib.fsproj", "src/App/obj/Generated.csproj", "App.sln"} {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte("<Project />"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths, err := Discover(root)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	want := []string{filepath.Join(root, "src", "App", "App.csproj"), filepath.Join(root, "src", "Lib", "Lib.fsproj")}
+	if !slices.Equal(paths, want) {
+		t.Errorf("Discover() = %v, want %v", paths, want)
+	}
+}
+
+// TestRetarget tests replacing target frameworks without disturbing the rest of the file
+func TestRetarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		from    string
+		to      string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "single target",
+			content: "<Project>\n  <PropertyGroup>\n    <TargetFramework>net6.0</TargetFramework>\n  </PropertyGroup>\n</Project>\n",
+			to:      "net10.0",
+			want:    "<Project>\n  <PropertyGroup>\n    <TargetFramework>net10.0</TargetFramework>\n  </PropertyGroup>\n</Project>\n",
+		},
+		{
+			name:    "multi-targeting",
+			content: sampleProject,
+			from:    "net48",
+			to:      "net481",
+			want:    strings.Replace(sampleProject, "net8.0;net48<", "net8.0;net481<", 1),
+		},
+		{
+			name:    "multi-targeting requires from",
+			content: sampleProject,
+			to:      "net10.0",
+			wantErr: true,
+		},
+		{
+			name:    "framework not targeted",
+			content: sampleProject,
+			from:    "net6.0",
+			to:      "net10.0",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "App.csproj")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			p, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			err = Retarget(p, tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Retarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got, _ := os.ReadFile(path); string(got) != tt.want {
+				t.Errorf("project =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCheckRetarget tests package compatibility against a new framework
+func TestCheckRetarget(t *testing.T) {
+	packages := t.TempDir()
+	for _, dir := range []string{
+		nuget.PackageDir(packages, "Serilog", "3.1.0") + "/lib/netstandard2.0",
+		nuget.PackageDir(packages, "Newtonsoft.Json", "13.0.3") + "/lib/net45",
+	} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p, err := Parse([]byte(sampleProject))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]Compatibility)
+	for _, check := range CheckRetarget(p, nuget.MustParseFramework("net8.0"), packages) {
+		got[check.Reference.ID] = check.Result
+	}
+	want := map[string]Compatibility{"Serilog": Compatible, "Newtonsoft.Json": Incompatible, "System.Memory": Unknown}
+	for id, result := range want {
+		if got[id] != result {
+			t.Errorf("CheckRetarget()[%s] = %q, want %q", id, got[id], result)
+		}
+	}
+}
+
+// TestParseAssetMetadata tests reading asset metadata from attributes and child elements
+func TestParseAssetMetadata(t *testing.T) {
+	p, err := Parse([]byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="StyleCop.Analyzers" Version="1.1.118" PrivateAssets="all" IncludeAssets="runtime; build; native; contentfiles; analyzers" />
+    <PackageReference Include="Microsoft.SourceLink.GitHub" Version="8.0.0">
+      <PrivateAssets>all</PrivateAssets>
+      <ExcludeAssets>runtime</ExcludeAssets>
+    </PackageReference>
+  </ItemGroup>
+</Project>`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []PackageReference{
+		{ID: "StyleCop.Analyzers", Version: "1.1.118", PrivateAssets: "all", IncludeAssets: "runtime; build; native; contentfiles; analyzers"},
+		{ID: "Microsoft.SourceLink.GitHub", Version: "8.0.0", PrivateAssets: "all", ExcludeAssets: "runtime"},
+	}
+	if !slices.Equal(p.PackageReferences, want) {
+		t.Errorf("PackageReferences = %+v, want %+v", p.PackageReferences, want)
+	}
+}
+
+// TestClassify tests separating analyzers and build tools from runtime dependencies
+func TestClassify(t *testing.T) {
+	packages := t.TempDir()
+	for _, dir := range []string{
+		"Roslynator.Analyzers/4.12.0/analyzers/dotnet/cs",
+		"Microsoft.Extensions.Logging.Generators/8.0.0/analyzers/dotnet/cs",
+		"Microsoft.Extensions.Logging.Generators/8.0.0/lib/net8.0",
+		"GitVersion.MsBuild/5.12.0/build",
+		"Serilog/3.1.0/lib/net8.0",
+	} {
+		parts := strings.SplitN(dir, "/", 3)
+		if err := os.MkdirAll(filepath.Join(nuget.PackageDir(packages, parts[0], parts[1]), parts[2]), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	nuspec := filepath.Join(nuget.PackageDir(packages, "GitVersion.MsBuild", "5.12.0"), "gitversion.msbuild.nuspec")
+	if err := os.WriteFile(nuspec, []byte("<package><metadata><developmentDependency>true</developmentDependency></metadata></package>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ref    PackageReference
+		want   Category
+		reason string
+	}{
+		{ref: PackageReference{ID: "Serilog", Version: "3.1.0"}, want: CategoryRuntime},
+		{ref: PackageReference{ID: "StyleCop.Analyzers", Version: "1.1.118", PrivateAssets: "All"}, want: CategoryDevelopment, reason: "PrivateAssets=All"},
+		{ref: PackageReference{ID: "Serilog", Version: "3.1.0", PrivateAssets: "contentfiles; analyzers"}, want: CategoryRuntime},
+		{ref: PackageReference{ID: "Coverlet.Collector", Version: "6.0.0", IncludeAssets: "build; analyzers"}, want: CategoryDevelopment, reason: "no compile or runtime assets"},
+		{ref: PackageReference{ID: "Coverlet.Collector", Version: "6.0.0", ExcludeAssets: "compile; runtime; native"}, want: CategoryDevelopment, reason: "no compile or runtime assets"},
+		{ref: PackageReference{ID: "Roslynator.Analyzers", Version: "4.12.0"}, want: CategoryDevelopment, reason: "analyzers only"},
+		{ref: PackageReference{ID: "Microsoft.Extensions.Logging.Generators", Version: "8.0.0"}, want: CategoryRuntime},
+		{ref: PackageReference{ID: "GitVersion.MsBuild", Version: "5.12.0"}, want: CategoryDevelopment, reason: "development dependency"},
+		{ref: PackageReference{ID: "Missing", Version: "1.0.0"}, want: CategoryRuntime},
+		{ref: PackageReference{ID: "Microsoft.AspNetCore.App"}, want: CategoryPlatform, reason: "part of the .NET platform"},
+		{ref: PackageReference{ID: "Microsoft.NETCore.App.Runtime.linux-x64", Version: "8.0.8"}, want: CategoryPlatform, reason: "part of the .NET platform"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref.ID, func(t *testing.T) {
+			got := Classify(tt.ref, packages)
+			if got.Category != tt.want || got.Reason != tt.reason {
+				t.Errorf("Classify(%+v) = %+v, want %s (%s)", tt.ref, got, tt.want, tt.reason)
+			}
+		})
+	}
+}
+
+// TestImportChain tests following a project's imports and tracing a package's version
+func TestImportChain(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"Directory.Build.props":                `<Project><Import Project="$(MSBuildThisFileDirectory)build\Versions.props" /></Project>`,
+		"build/Versions.props":                 `<Project><PropertyGroup><SerilogMajor>3</SerilogMajor><SerilogVersion>$(SerilogMajor).1.0</SerilogVersion></PropertyGroup></Project>`,
+		"Directory.Packages.props":             `<Project><ItemGroup><PackageVersion Include="Serilog" Version="$(SerilogVersion)" /></ItemGroup></Project>`,
+		"Directory.Build.targets":              "<Project>\n  <ItemGroup>\n    <PackageReference Update=\"Serilog\" Version=\"3.1.1\" />\n  </ItemGroup>\n  <Import Project=\"$(Undefined)/x.targets\" />\n</Project>",
+		"src/App/obj/App.csproj.nuget.g.props": `<Project />`,
+		"src/App/App.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Serilog" />
+    <PackageReference Include="Polly" Version="8.4.0" />
+  </ItemGroup>
+  <Import Project="../../build/Versions.props" />
+</Project>`,
+	}
+	for name, text := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	chain, err := ImportChain(filepath.Join(root, "src", "App", "App.csproj"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, imp := range chain {
+		name := imp.Project
+		if imp.Path != "" {
+			rel, _ := filepath.Rel(root, imp.Path)
+			name = filepath.ToSlash(rel)
+		}
+		entry := strings.Repeat(" ", imp.Depth) + imp.Kind + " " + name
+		if imp.Missing {
+			entry += " (missing)"
+		}
+		if imp.Duplicate {
+			entry += " (duplicate)"
+		}
+		got = append(got, entry)
+	}
+	want := []string{
+		"sdk Microsoft.NET.Sdk (missing)",
+		"directory Directory.Build.props",
+		" import build/Versions.props",
+		"restore src/App/obj/App.csproj.nuget.g.props",
+		"central Directory.Packages.props",
+		"project src/App/App.csproj",
+		" import build/Versions.props (duplicate)",
+		"sdk Microsoft.NET.Sdk (missing)",
+		"directory Directory.Build.targets",
+		" import $(Undefined)/x.targets (missing)",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("ImportChain() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	got = nil
+	for _, imp := range Trace(chain, "serilog") {
+		for _, d := range imp.Definitions {
+			got = append(got, filepath.Base(imp.Path)+" "+d.Name+"="+d.Value)
+		}
+	}
+	want = []string{
+		"Versions.props SerilogMajor=3",
+		"Versions.props SerilogVersion=$(SerilogMajor).1.0",
+		"Directory.Packages.props Serilog=$(SerilogVersion)",
+		"App.csproj Serilog=",
+		"Directory.Build.targets Serilog=3.1.1",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Trace(serilog) = %v, want %v", got, want)
+	}
+}
+
+// TestWarningSettings tests reading NuGet warning settings and adding and removing
+// suppressions
+func TestWarningSettings(t *testing.T) {
+	root := t.TempDir()
+	props := filepath.Join(root, "Directory.Build.props")
+	path := filepath.Join(root, "App.csproj")
+	original := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <WarningsNotAsErrors>$(WarningsNotAsErrors);NU1902</WarningsNotAsErrors>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Legacy" Version="1.0.0" />
+  </ItemGroup>
+</Project>
+`
+	files := map[string]string{
+		props: "<Project>\n  <PropertyGroup>\n    <NoWarn>CS1591;NU1701</NoWarn>\n    <TreatWarningsAsErrors>true</TreatWarningsAsErrors>\n  </PropertyGroup>\n</Project>\n",
+		path:  original,
+	}
+	for name, text := range files {
+		if err := os.WriteFile(name, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := ReadWarningSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Suppression{{Code: "NU1701", File: props, Line: 3}}; !slices.Equal(s.Suppressions, want) {
+		t.Errorf("Suppressions = %+v, want %+v", s.Suppressions, want)
+	}
+	if !s.Suppressed("nu1701", "Any") || s.Suppressed("NU1603", "Any") {
+		t.Error("Suppressed() should report the NoWarn codes only")
+	}
+	if !s.AsError("NU1603") || s.AsError("NU1902") || !slices.Equal(s.WarningsAsErrors, []string{"NU1605"}) {
+		t.Errorf("AsError() with TreatWarningsAsErrors and WarningsNotAsErrors NU1902: %+v", s)
+	}
+
+	if changed, err := SuppressWarning(path, "", "NU1603", "C 1.0.0 is not on our feed"); err != nil || !changed {
+		t.Fatalf("SuppressWarning() = %v, %v", changed, err)
+	}
+	if changed, err := SuppressWarning(path, "Legacy", "NU1701", ""); err != nil || !changed {
+		t.Fatalf("SuppressWarning(Legacy) = %v, %v", changed, err)
+	}
+	if changed, _ := SuppressWarning(path, "", "NU1603", ""); changed {
+		t.Error("SuppressWarning() should not add a code twice")
+	}
+	data, _ := os.ReadFile(path)
+	want := strings.Replace(strings.Replace(original, `NU1902</WarningsNotAsErrors>`,
+		"NU1902</WarningsNotAsErrors>\n    <!-- NU1603: C 1.0.0 is not on our feed -->\n    <NoWarn>$(NoWarn);NU1603</NoWarn>", 1),
+		`Version="1.0.0" />`, `Version="1.0.0" NoWarn="NU1701" />`, 1)
+	if string(data) != want {
+		t.Errorf("after SuppressWarning():\n%s\nwant:\n%s", data, want)
+	}
+	if s, _ := ReadWarningSettings(path); len(s.Suppressions) != 3 || s.Suppressions[2].Package != "Legacy" {
+		t.Errorf("Suppressions after SuppressWarning() = %+v", s.Suppressions)
+	}
+
+	for _, id := range []string{"", "Legacy"} {
+		code := "NU1603"
+		if id != "" {
+			code = "NU1701"
+		}
+		if removed, err := UnsuppressWarning(path, id, code); err != nil || !removed {
+			t.Fatalf("UnsuppressWarning(%q, %s) = %v, %v", id, code, removed, err)
+		}
+	}
+	if data, _ := os.ReadFile(path); string(data) != original {
+		t.Errorf("after UnsuppressWarning():\n%s\nwant:\n%s", data, original)
+	}
+}
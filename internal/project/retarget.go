@@ -0,0 +1,83 @@
+package project
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+)
+
+// Compatibility is the result of checking a package reference against a target framework.
+type Compatibility string
+
+// Compatibility results.
+const (
+	Compatible   Compatibility = "compatible"
+	Incompatible Compatibility = "incompatible"
+	Unknown      Compatibility = "unknown" // Not in the global packages folder (restore first)
+)
+
+// ReferenceCheck is the compatibility of one package reference with a new target framework.
+type ReferenceCheck struct {
+	Reference  PackageReference
+	Result     Compatibility
+	Frameworks []nuget.Framework // Frameworks the package has assets for
+}
+
+// CheckRetarget checks every package reference against target using the package assets
+// in the global packages folder. Platform packages are left out: the SDK provides the
+// right ones for each target framework.
+func CheckRetarget(p *Project, target nuget.Framework, packagesDir string) []ReferenceCheck {
+	checks := make([]ReferenceCheck, 0, len(p.PackageReferences))
+	for _, ref := range p.PackageReferences {
+		if nuget.IsPlatformPackage(ref.ID) {
+			continue
+		}
+		check := ReferenceCheck{Reference: ref, Result: Unknown}
+		if frameworks, ok := nuget.PackageFrameworks(packagesDir, ref.ID, ref.Version); ok && ref.Version != "" {
+			check.Frameworks = frameworks
+			check.Result = Incompatible
+			if nuget.AnyCompatible(target, frameworks) {
+				check.Result = Compatible
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// Retarget replaces the from framework with to in a project's TargetFramework or
+// TargetFrameworks property. An empty from is allowed for single-target projects.
+func Retarget(p *Project, from, to string) error {
+	switch {
+	case from == "" && len(p.TargetFrameworks) == 1:
+		from = p.TargetFrameworks[0]
+	case from == "":
+		return fmt.Errorf("%s targets %s; choose the framework to replace", p.Name(), strings.Join(p.TargetFrameworks, ", "))
+	}
+
+	i := slices.IndexFunc(p.TargetFrameworks, func(tfm string) bool { return strings.EqualFold(tfm, from) })
+	if i < 0 {
+		return fmt.Errorf("%s does not target %s", p.Name(), from)
+	}
+	frameworks := slices.Clone(p.TargetFrameworks)
+	frameworks[i] = to
+
+	err := EditFile(p.Path, func(e *Editor) error {
+		switch {
+		case e.HasProperty("TargetFrameworks"):
+			e.SetProperty("TargetFrameworks", strings.Join(frameworks, ";"))
+		case e.HasProperty("TargetFramework"):
+			e.SetProperty("TargetFramework", to)
+		default:
+			return fmt.Errorf("%s does not set TargetFramework itself (it may come from Directory.Build.props)", p.Path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	p.TargetFrameworks = frameworks
+	return nil
+}
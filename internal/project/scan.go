@@ -0,0 +1,181 @@
+package project
+
+import "strings"
+
+// tag is a start or end tag located in project file text by byte offsets, so edits can
+// replace exactly the bytes they change.
+type tag struct {
+	name        string
+	attrs       []attribute
+	start       int  // Offset of '<'
+	end         int  // Offset just past '>'
+	nameEnd     int  // Offset just past the element name
+	closing     bool // </name>
+	selfClosing bool // <name ... />
+}
+
+// attribute is an attribute of a start tag; the value span excludes the quotes.
+type attribute struct {
+	name       string
+	valueStart int
+	valueEnd   int
+}
+
+// element is a start tag with its matching end tag (nil when self-closing).
+type element struct {
+	open   tag
+	close  *tag
+	parent int // Position of the parent in the element list, or -1 for the root
+}
+
+// attr returns the attribute with the given name (case-insensitively).
+func (t tag) attr(name string) (attribute, bool) {
+	for _, a := range t.attrs {
+		if strings.EqualFold(a.name, name) {
+			return a, true
+		}
+	}
+	return attribute{}, false
+}
+
+// scanTags returns the tags in text in document order. Comments, CDATA sections,
+// processing instructions, and declarations are skipped, so commented-out elements are
+// never edited. Quoted attribute values may contain '>' (e.g., in conditions).
+func scanTags(text string) []tag {
+	var tags []tag
+	for i := 0; i < len(text); {
+		lt := strings.IndexByte(text[i:], '<')
+		if lt < 0 {
+			break
+		}
+		i += lt
+
+		rest := text[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			i = skipPast(text, i, "-->")
+		case strings.HasPrefix(rest, "<![CDATA["):
+			i = skipPast(text, i, "]]>")
+		case strings.HasPrefix(rest, "<?"):
+			i = skipPast(text, i, "?>")
+		case strings.HasPrefix(rest, "<!"):
+			i = skipPast(text, i, ">")
+		default:
+			t, ok := parseTag(text, i)
+			if !ok {
+				i++
+				continue
+			}
+			tags = append(tags, t)
+			i = t.end
+		}
+	}
+	return tags
+}
+
+// skipPast returns the offset just past the first marker at or after i, or len(text).
+func skipPast(text string, i int, marker string) int {
+	end := strings.Index(text[i:], marker)
+	if end < 0 {
+		return len(text)
+	}
+	return i + end + len(marker)
+}
+
+// parseTag parses the tag starting at text[i] == '<'.
+func parseTag(text string, i int) (tag, bool) {
+	t := tag{start: i}
+	j := i + 1
+	if j < len(text) && text[j] == '/' {
+		t.closing = true
+		j++
+	}
+
+	nameStart := j
+	for j < len(text) && !isSpace(text[j]) && text[j] != '/' && text[j] != '>' {
+		j++
+	}
+	if j == nameStart {
+		return tag{}, false
+	}
+	t.name, t.nameEnd = text[nameStart:j], j
+
+	for {
+		for j < len(text) && isSpace(text[j]) {
+			j++
+		}
+		switch {
+		case j >= len(text):
+			return tag{}, false
+		case text[j] == '>':
+			t.end = j + 1
+			return t, true
+		case strings.HasPrefix(text[j:], "/>"):
+			t.selfClosing = true
+			t.end = j + 2
+			return t, true
+		}
+
+		attrStart := j
+		for j < len(text) && !isSpace(text[j]) && text[j] != '=' && text[j] != '>' && text[j] != '/' {
+			j++
+		}
+		a := attribute{name: text[attrStart:j]}
+		for j < len(text) && isSpace(text[j]) {
+			j++
+		}
+		if a.name == "" || j >= len(text) || text[j] != '=' {
+			return tag{}, false
+		}
+		j++
+		for j < len(text) && isSpace(text[j]) {
+			j++
+		}
+		if j >= len(text) || (text[j] != '"' && text[j] != '\'') {
+			return tag{}, false
+		}
+		quote := text[j]
+		closeQuote := strings.IndexByte(text[j+1:], quote)
+		if closeQuote < 0 {
+			return tag{}, false
+		}
+		a.valueStart, a.valueEnd = j+1, j+1+closeQuote
+		t.attrs = append(t.attrs, a)
+		j = a.valueEnd + 1
+	}
+}
+
+// isSpace reports whether c is XML whitespace.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// elements returns the elements in tags in document order, pairing each start tag with
+// its end tag.
+func elements(tags []tag) []element {
+	var elems []element
+	var stack []int // Positions in elems of open elements
+	for i, t := range tags {
+		if t.closing {
+			// Close the innermost open element with this name; unmatched end tags are ignored
+			for k := len(stack) - 1; k >= 0; k-- {
+				if strings.EqualFold(elems[stack[k]].open.name, t.name) {
+					elems[stack[k]].close = &tags[i]
+					stack = stack[:k]
+					break
+				}
+			}
+			continue
+		}
+
+		parent := -1
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+		}
+		elems = append(elems, element{open: t, parent: parent})
+		if !t.selfClosing {
+			stack = append(stack, len(elems)-1)
+		}
+	}
+	return elems
+}
@@ -0,0 +1,265 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// WarningsKind identifies the warning settings of projects in versioned JSON output.
+const WarningsKind = "warnings"
+
+// nugetCode matches a NuGet warning code (NU1603).
+var nugetCode = regexp.MustCompile(`^NU\d{4}$`)
+
+// NormalizeCode returns a NuGet warning code in upper case, and whether it is one.
+func NormalizeCode(code string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return code, nugetCode.MatchString(code)
+}
+
+// WarningSettings are the settings that decide how NuGet's warnings (NUxxxx) are reported
+// for a project: those NoWarn suppresses, for the project or one package, and those that
+// fail the build as errors. Codes of other tools (CS0618, ...) are left out.
+type WarningSettings struct {
+	Suppressions          []Suppression `json:"suppressions"`
+	TreatWarningsAsErrors bool          `json:"treatWarningsAsErrors"`
+	WarningsAsErrors      []string      `json:"warningsAsErrors"`    // Including NU1605, which the .NET SDK adds
+	WarningsNotAsErrors   []string      `json:"warningsNotAsErrors"` // Kept as warnings despite TreatWarningsAsErrors
+}
+
+// Suppression is a NuGet warning code in a NoWarn property or package metadata.
+type Suppression struct {
+	Code    string `json:"code"`
+	Package string `json:"package,omitempty"` // The PackageReference whose NoWarn has it; "" for the project's NoWarn
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+}
+
+// Suppressed reports whether a warning is suppressed, for the project or for the package
+// restore reports it about.
+func (s *WarningSettings) Suppressed(code, id string) bool {
+	return slices.ContainsFunc(s.Suppressions, func(sup Suppression) bool {
+		return strings.EqualFold(sup.Code, code) && (sup.Package == "" || strings.EqualFold(sup.Package, id))
+	})
+}
+
+// AsError reports whether a warning fails the build: WarningsNotAsErrors keeps it a
+// warning, TreatWarningsAsErrors or WarningsAsErrors makes it an error.
+func (s *WarningSettings) AsError(code string) bool {
+	switch {
+	case containsCode(s.WarningsNotAsErrors, code):
+		return false
+	case s.TreatWarningsAsErrors:
+		return true
+	}
+	return containsCode(s.WarningsAsErrors, code)
+}
+
+// ReadWarningSettings reads a project's warning settings from the project file and the
+// files it imports. Properties are evaluated in import order, ignoring their conditions;
+// a value that does not include the property itself ($(NoWarn)) replaces the codes before
+// it. The .NET SDK's own files are not read: for SDK-style projects, NU1605 starts out in
+// WarningsAsErrors, as the SDK puts it there.
+func ReadWarningSettings(path string) (*WarningSettings, error) {
+	p, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := ImportChain(path, "")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &WarningSettings{Suppressions: []Suppression{}, WarningsAsErrors: []string{}, WarningsNotAsErrors: []string{}}
+	if p.Sdk != "" {
+		s.WarningsAsErrors = append(s.WarningsAsErrors, "NU1605")
+	}
+	for _, imp := range chain {
+		for _, d := range imp.properties {
+			switch strings.ToLower(d.Name) {
+			case "nowarn":
+				var kept []Suppression
+				if mentions(d.Value, "NoWarn") {
+					kept = s.Suppressions
+				}
+				for _, code := range codes(d.Value) {
+					kept = append(kept, Suppression{Code: code, File: imp.Path, Line: d.Line})
+				}
+				s.Suppressions = append([]Suppression{}, kept...)
+			case "warningsaserrors":
+				s.WarningsAsErrors = evaluateCodes(s.WarningsAsErrors, d.Value, "WarningsAsErrors")
+			case "warningsnotaserrors":
+				s.WarningsNotAsErrors = evaluateCodes(s.WarningsNotAsErrors, d.Value, "WarningsNotAsErrors")
+			case "treatwarningsaserrors":
+				s.TreatWarningsAsErrors = strings.EqualFold(strings.TrimSpace(d.Value), "true")
+			}
+		}
+	}
+
+	// #nosec G304 -- path is a project file in the user's workspace
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	text := string(data)
+	e := NewEditor(text)
+	elems := elements(scanTags(text))
+	for _, ref := range p.PackageReferences {
+		line := 0
+		if pos := e.item(elems, "PackageReference", ref.ID); pos >= 0 {
+			line = strings.Count(text[:elems[pos].open.start], "\n") + 1
+		}
+		for _, code := range codes(ref.NoWarn) {
+			s.Suppressions = append(s.Suppressions, Suppression{Code: code, Package: ref.ID, File: path, Line: line})
+		}
+	}
+	return s, nil
+}
+
+// SuppressWarning adds a NuGet warning code to the project's NoWarn property, or to a
+// package reference's NoWarn metadata when id is not "", with reason as a comment above
+// it ("NU1603: reason"). A project without a NoWarn property gets
+// <NoWarn>$(NoWarn);NU1603</NoWarn>, so the codes of imported files still apply. It
+// reports whether the file changed: false when the code is there already.
+func SuppressWarning(path, id, code, reason string) (bool, error) {
+	changed := false
+	err := EditFile(path, func(e *Editor) error {
+		comment := code
+		if reason != "" {
+			comment += ": " + reason
+		}
+		if id != "" {
+			value, _ := e.ItemMetadata("PackageReference", id, "NoWarn")
+			if containsCode(codes(value), code) {
+				return nil
+			}
+			if !e.SetItemMetadata("PackageReference", id, "NoWarn", appendCode(value, code)) {
+				return fmt.Errorf("%s does not reference %s", filepath.Base(path), id)
+			}
+			changed = true
+			if reason != "" {
+				e.CommentItem("PackageReference", id, comment)
+			}
+			return nil
+		}
+
+		value, ok := e.Property("NoWarn")
+		if containsCode(codes(value), code) {
+			return nil
+		}
+		if ok {
+			e.SetProperty("NoWarn", appendCode(value, code))
+		} else {
+			e.AddProperty("NoWarn", "$(NoWarn);"+code)
+		}
+		changed = true
+		if reason != "" {
+			e.CommentProperty("NoWarn", comment)
+		}
+		return nil
+	})
+	return changed, err
+}
+
+// UnsuppressWarning removes a NuGet warning code from the NoWarn property of a file (the
+// project, or a file it imports), or from a package reference's NoWarn metadata when id
+// is not "", with the comment SuppressWarning wrote for it. A NoWarn left with nothing of
+// its own is removed. It reports whether the code was there.
+func UnsuppressWarning(path, id, code string) (bool, error) {
+	removed := false
+	err := EditFile(path, func(e *Editor) error {
+		if id != "" {
+			value, ok := e.ItemMetadata("PackageReference", id, "NoWarn")
+			if !ok || !containsCode(codes(value), code) {
+				return nil
+			}
+			removed = true
+			e.UncommentItem("PackageReference", id, code)
+			if rest := removeCode(value, code); strings.TrimSpace(rest) != "" {
+				e.SetItemMetadata("PackageReference", id, "NoWarn", rest)
+			} else {
+				e.RemoveItemMetadata("PackageReference", id, "NoWarn")
+			}
+			return nil
+		}
+
+		value, ok := e.Property("NoWarn")
+		if !ok || !containsCode(codes(value), code) {
+			return nil
+		}
+		removed = true
+		e.UncommentProperty("NoWarn", code)
+		rest := removeCode(value, code)
+		if own := strings.Trim(strings.ReplaceAll(strings.ToLower(rest), "$(nowarn)", ""), "; \t"); own == "" {
+			e.RemoveProperty("NoWarn")
+		} else {
+			e.SetProperty("NoWarn", rest)
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// codes returns the NuGet codes in a list of warning codes, in upper case.
+func codes(value string) []string {
+	var list []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == ',' }) {
+		if code, ok := NormalizeCode(item); ok && !slices.Contains(list, code) {
+			list = append(list, code)
+		}
+	}
+	return list
+}
+
+// evaluateCodes returns the codes of a property after a definition: those of the value,
+// after the previous ones when the value includes the property itself.
+func evaluateCodes(previous []string, value, property string) []string {
+	var list []string
+	if mentions(value, property) {
+		list = slices.Clone(previous)
+	}
+	for _, code := range codes(value) {
+		if !slices.Contains(list, code) {
+			list = append(list, code)
+		}
+	}
+	if list == nil {
+		list = []string{}
+	}
+	return list
+}
+
+// mentions reports whether a value includes $(property).
+func mentions(value, property string) bool {
+	return strings.Contains(strings.ToLower(value), "$("+strings.ToLower(property)+")")
+}
+
+// containsCode reports whether a list has a code, case-insensitively.
+func containsCode(list []string, code string) bool {
+	return slices.ContainsFunc(list, func(c string) bool { return strings.EqualFold(c, code) })
+}
+
+// appendCode appends a code to a semicolon-separated list.
+func appendCode(value, code string) string {
+	value = strings.TrimRight(strings.TrimSpace(value), ";")
+	if value == "" {
+		return code
+	}
+	return value + ";" + code
+}
+
+// removeCode removes a code from a semicolon-separated list, keeping the other entries
+// as written.
+func removeCode(value, code string) string {
+	var kept []string
+	for _, item := range strings.Split(value, ";") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" && !strings.EqualFold(trimmed, code) {
+			kept = append(kept, trimmed)
+		}
+	}
+	return strings.Join(kept, ";")
+}
@@ -0,0 +1,156 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// sharedFiles are the files above projects that every project below them imports.
+var sharedFiles = []string{"directory.build.props", "directory.build.targets", strings.ToLower(PackagesPropsFile)}
+
+// Watcher reports changes to the files that decide which packages projects reference and
+// what their last restore resolved: the project files, the Directory.Build.props,
+// Directory.Build.targets, and Directory.Packages.props above them, and the assets files
+// restore writes to obj.
+//
+// Like the config watcher, it watches directories rather than files, so that editors
+// saving by renaming a new file over the old one are seen.
+type Watcher struct {
+	watcher  *fsnotify.Watcher
+	projects []string // Absolute paths
+	root     string
+	mu       sync.Mutex
+	dirs     map[string]bool
+}
+
+// NewWatcher returns a watcher for projects under root (the workspace root). Shared files
+// are looked for in the project directories and every directory up to root.
+func NewWatcher(root string, projects []string) (*Watcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	root, _ = filepath.Abs(root)
+	w := &Watcher{watcher: fsWatcher, root: root, dirs: make(map[string]bool)}
+	for _, p := range projects {
+		p, _ = filepath.Abs(p)
+		w.projects = append(w.projects, p)
+		for dir := filepath.Dir(p); ; dir = filepath.Dir(dir) {
+			w.watch(dir)
+			if dir == root || !strings.HasPrefix(dir, root) || filepath.Dir(dir) == dir {
+				break
+			}
+		}
+		w.watch(filepath.Join(filepath.Dir(p), "obj"))
+	}
+	if len(w.dirs) == 0 {
+		_ = fsWatcher.Close()
+		return nil, os.ErrNotExist
+	}
+	return w, nil
+}
+
+// watch adds a directory, if it exists and is not watched yet.
+func (w *Watcher) watch(dir string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.dirs[dir] {
+		return
+	}
+	if err := w.watcher.Add(dir); err == nil {
+		w.dirs[dir] = true
+	}
+}
+
+// Run calls changed with the projects whose files changed, once the changes have stopped
+// for debounce, until ctx is done or the watcher is closed. Errors of the underlying
+// watcher are passed to onError, which may be nil.
+func (w *Watcher) Run(ctx context.Context, debounce time.Duration, changed func(projects []string), onError func(error)) {
+	var (
+		timer   *time.Timer
+		pending = make(map[string]bool)
+		fire    = make(chan struct{}, 1)
+	)
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			// restore creates obj after the watch started
+			if event.Op.Has(fsnotify.Create) && strings.EqualFold(filepath.Base(event.Name), "obj") {
+				w.watch(event.Name)
+			}
+			affected := w.affected(event.Name)
+			if len(affected) == 0 {
+				continue
+			}
+			for _, p := range affected {
+				pending[p] = true
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(debounce, func() {
+				select {
+				case fire <- struct{}{}:
+				default:
+				}
+			})
+		case <-fire:
+			projects := make([]string, 0, len(pending))
+			for _, p := range w.projects {
+				if pending[p] {
+					projects = append(projects, p)
+				}
+			}
+			clear(pending)
+			changed(projects)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			if onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// affected returns the projects a change to path concerns.
+func (w *Watcher) affected(path string) []string {
+	name := strings.ToLower(filepath.Base(path))
+	dir := filepath.Dir(path)
+	var affected []string
+	for _, p := range w.projects {
+		switch {
+		case path == p,
+			name == "project.assets.json" && dir == filepath.Join(filepath.Dir(p), "obj"),
+			slices.Contains(sharedFiles, name) && (dir == filepath.Dir(p) || strings.HasPrefix(p, dir+string(filepath.Separator))):
+			affected = append(affected, p)
+		}
+	}
+	return affected
+}
+
+// Close stops the watcher; Run returns.
+func (w *Watcher) Close() error {
+	return w.watcher.Close()
+}
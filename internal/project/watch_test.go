@@ -0,0 +1,68 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestWatcher tests which projects a change to a watched file concerns
+func TestWatcher(t *testing.T) {
+	root := t.TempDir()
+	app := filepath.Join(root, "src", "App", "App.csproj")
+	lib := filepath.Join(root, "src", "Lib", "Lib.csproj")
+	for _, path := range []string{app, lib} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("<Project />"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w, err := NewWatcher(root, []string{app, lib})
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+	defer func() { _ = w.Close() }()
+	changes := make(chan []string, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx, 50*time.Millisecond, func(projects []string) { changes <- projects }, nil)
+
+	tests := []struct {
+		name string
+		file string
+		want []string
+	}{
+		{"project file", app, []string{app}},
+		{"central versions above both", filepath.Join(root, "Directory.Packages.props"), []string{app, lib}},
+		{"assets file in an obj created later", filepath.Join(filepath.Dir(lib), "obj", "project.assets.json"), []string{lib}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.MkdirAll(filepath.Dir(tt.file), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(50 * time.Millisecond) // Let the watcher add a new obj directory
+			if err := os.WriteFile(tt.file, []byte("<Project />"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case got := <-changes:
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("changed %v, want %v", got, tt.want)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("no change reported")
+			}
+		})
+	}
+
+	if got := w.affected(filepath.Join(root, "src", "App", "Program.cs")); len(got) != 0 {
+		t.Errorf("affected(Program.cs) = %v, want none", got)
+	}
+}
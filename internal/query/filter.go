@@ -0,0 +1,187 @@
+// Package query parses the filters of the package list and keeps the search history.
+//
+// A filter is a list of terms that must all match a package reference: words match the
+// package ID, and qualifiers match what is known about the reference, GitHub style:
+//
+//	is:vulnerable update:major          vulnerable packages with a new major version
+//	serilog -is:current project:Api     outdated Serilog packages in the Api project
+//
+// Saved filters (the savedFilters setting) give a filter a name, for the command palette
+// and --filter.
+package query
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Values of the is: qualifier.
+var States = []string{"outdated", "current", "vulnerable", "prerelease", "floating"}
+
+// Values of the update: qualifier, the most significant part of the version an update
+// changes.
+var Updates = []string{"major", "minor", "patch", "prerelease"}
+
+// Severities are the advisory severities, least severe first.
+var Severities = []string{"low", "moderate", "high", "critical"}
+
+// Row is what a filter knows about a package reference.
+type Row struct {
+	Package  string
+	Project  string // Project file name
+	Version  string // Resolved version
+	Update   string // One of Updates when a newer version is available, else ""
+	Severity string // Highest advisory severity of the resolved version; "" when none
+	Floating bool
+}
+
+// term is one condition of a filter.
+type term struct {
+	qualifier string // "" for a word matching the package ID
+	values    []string
+	negate    bool
+}
+
+// Filter is a parsed filter.
+type Filter struct {
+	text  string
+	terms []term
+}
+
+// Parse parses a filter. Values of a qualifier may be listed with commas
+// (update:major,minor); a leading - negates a term.
+func Parse(text string) (*Filter, error) {
+	f := &Filter{text: strings.Join(strings.Fields(text), " ")}
+	for _, field := range strings.Fields(text) {
+		t := term{}
+		if rest, ok := strings.CutPrefix(field, "-"); ok && rest != "" {
+			t.negate, field = true, rest
+		}
+		qualifier, value, ok := strings.Cut(field, ":")
+		if !ok {
+			t.values = []string{strings.ToLower(field)}
+			f.terms = append(f.terms, t)
+			continue
+		}
+		t.qualifier = strings.ToLower(qualifier)
+		if value == "" {
+			return nil, fmt.Errorf("%s: needs a value", field)
+		}
+		for _, v := range strings.Split(strings.ToLower(value), ",") {
+			var allowed []string
+			switch t.qualifier {
+			case "is":
+				allowed = States
+			case "update":
+				allowed = Updates
+			case "severity":
+				allowed = Severities
+			case "id", "project":
+			default:
+				return nil, fmt.Errorf("unknown qualifier %q (use id, project, is, update, or severity)", qualifier)
+			}
+			if allowed != nil && !slices.Contains(allowed, v) {
+				return nil, fmt.Errorf("%s: %q is not one of %s", t.qualifier, v, strings.Join(allowed, ", "))
+			}
+			if t.qualifier == "id" {
+				if _, err := path.Match(v, ""); err != nil {
+					return nil, fmt.Errorf("id:%s: invalid pattern", v)
+				}
+			}
+			t.values = append(t.values, v)
+		}
+		f.terms = append(f.terms, t)
+	}
+	return f, nil
+}
+
+// String returns the filter's text, with the spacing normalized.
+func (f *Filter) String() string {
+	return f.text
+}
+
+// NeedsAdvisories reports whether matching the filter needs the advisories of the
+// packages: it tests is:vulnerable or severity.
+func (f *Filter) NeedsAdvisories() bool {
+	return slices.ContainsFunc(f.terms, func(t term) bool {
+		return t.qualifier == "severity" || t.qualifier == "is" && slices.Contains(t.values, "vulnerable")
+	})
+}
+
+// Match reports whether a row matches every term of the filter.
+func (f *Filter) Match(r Row) bool {
+	for _, t := range f.terms {
+		if t.match(r) == t.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// match reports whether a row matches a term, ignoring negation: any of its values.
+func (t term) match(r Row) bool {
+	id := strings.ToLower(r.Package)
+	for _, v := range t.values {
+		var ok bool
+		switch t.qualifier {
+		case "":
+			ok = strings.Contains(id, v)
+		case "id":
+			ok, _ = path.Match(v, id)
+		case "project":
+			ok = strings.Contains(strings.ToLower(r.Project), v)
+		case "is":
+			ok = r.is(v)
+		case "update":
+			ok = r.Update == v
+		case "severity":
+			// A severity matches it and anything more severe
+			ok = r.Severity != "" && slices.Index(Severities, strings.ToLower(r.Severity)) >= slices.Index(Severities, v)
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// is reports whether a row is in a state of the is: qualifier.
+func (r Row) is(state string) bool {
+	switch state {
+	case "outdated":
+		return r.Update != ""
+	case "current":
+		return r.Update == ""
+	case "vulnerable":
+		return r.Severity != ""
+	case "prerelease":
+		return strings.Contains(r.Version, "-")
+	case "floating":
+		return r.Floating
+	}
+	return false
+}
+
+// Saved is a named filter, as the command palette lists it.
+type Saved struct {
+	Name        string `json:"name"`
+	Query       string `json:"query"`
+	Description string `json:"description,omitempty"`
+}
+
+// Resolve returns the filter a --filter value means: the saved filter of that name
+// (names are case-insensitive), or else the value parsed as a filter.
+func Resolve(saved []Saved, value string) (*Filter, error) {
+	for _, s := range saved {
+		if strings.EqualFold(s.Name, strings.TrimSpace(value)) {
+			f, err := Parse(s.Query)
+			if err != nil {
+				return nil, fmt.Errorf("saved filter %q: %w", s.Name, err)
+			}
+			return f, nil
+		}
+	}
+	return Parse(value)
+}
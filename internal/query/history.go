@@ -0,0 +1,164 @@
+package query
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// HistoryKind is the JSON document kind of `lazynuget search --history --json`.
+const HistoryKind = "searchHistory"
+
+// HistoryDirName is the directory under the state directory that holds search histories.
+const HistoryDirName = "history"
+
+// HistorySize is how many queries a workspace's history keeps.
+const HistorySize = 100
+
+// historyFile is the JSON file of a workspace's search history.
+type historyFile struct {
+	Workspace string   `json:"workspace"`
+	Queries   []string `json:"queries"` // Oldest first
+}
+
+// History is the search history of a workspace: the queries searched, each once, in the
+// order last searched.
+type History struct {
+	path string
+	file historyFile
+	mu   sync.Mutex
+}
+
+// DefaultHistoryDir returns the platform directory of search histories (<data
+// dir>/history), with its temp fallback applied and histories from the config directory
+// moved over (see platform.DataPath).
+func DefaultHistoryDir() string {
+	return platform.DataPath(platform.DirState, HistoryDirName)
+}
+
+// HistoryPath returns the history file of a workspace in dir.
+func HistoryPath(dir, workspace string) string {
+	sum := sha256.Sum256([]byte(filepath.Clean(workspace)))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// OpenHistory reads the search history of a workspace from dir. A workspace that has not
+// searched yet has an empty history.
+func OpenHistory(dir, workspace string) (*History, error) {
+	h := &History{path: HistoryPath(dir, workspace), file: historyFile{Workspace: workspace}}
+
+	// #nosec G304 -- path is a history file in the user's config directory
+	data, err := os.ReadFile(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search history: %w", err)
+	}
+	if err := json.Unmarshal(data, &h.file); err != nil {
+		return nil, fmt.Errorf("invalid search history %s: %w", h.path, err)
+	}
+	return h, nil
+}
+
+// Queries returns the queries, oldest first.
+func (h *History) Queries() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return slices.Clone(h.file.Queries)
+}
+
+// Add records a query and saves the history. Searching for a query again moves it to
+// the end; blank queries are not recorded.
+func (h *History) Add(query string) error {
+	query = strings.Join(strings.Fields(query), " ")
+	if query == "" {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	queries := slices.DeleteFunc(h.file.Queries, func(q string) bool { return q == query })
+	queries = append(queries, query)
+	if len(queries) > HistorySize {
+		queries = queries[len(queries)-HistorySize:]
+	}
+	h.file.Queries = queries
+	return h.saveLocked()
+}
+
+// Clear forgets every query.
+func (h *History) Clear() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.file.Queries = nil
+	if err := os.Remove(h.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to clear search history: %w", err)
+	}
+	return nil
+}
+
+// saveLocked writes the history.
+func (h *History) saveLocked() error {
+	data, err := json.MarshalIndent(h.file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode search history: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create search history directory: %w", err)
+	}
+	if err := platform.WriteFileAtomic(h.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write search history: %w", err)
+	}
+	return nil
+}
+
+// Recall steps through the history from a search box, the way a shell does with the up
+// and down arrows: up goes back a query, down forward, and down past the newest query
+// brings back what was being typed.
+type Recall struct {
+	queries []string
+	draft   string
+	index   int // Position in queries; len(queries) is the draft
+}
+
+// NewRecall returns a recall over queries, oldest first, positioned on the draft.
+func NewRecall(queries []string) *Recall {
+	return &Recall{queries: queries, index: len(queries)}
+}
+
+// Prev returns the query before the current one, remembering current as the draft when
+// leaving it. At the oldest query it stays there.
+func (r *Recall) Prev(current string) string {
+	if r.index == len(r.queries) {
+		r.draft = current
+	}
+	if r.index > 0 {
+		r.index--
+	}
+	if r.index == len(r.queries) {
+		return r.draft
+	}
+	return r.queries[r.index]
+}
+
+// Next returns the query after the current one, or the draft after the newest.
+func (r *Recall) Next() string {
+	if r.index < len(r.queries) {
+		r.index++
+	}
+	if r.index == len(r.queries) {
+		return r.draft
+	}
+	return r.queries[r.index]
+}
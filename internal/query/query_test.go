@@ -0,0 +1,153 @@
+package query
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestParse tests filter syntax errors
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text    string
+		wantErr bool
+	}{
+		{"", false},
+		{"serilog -is:current", false},
+		{"update:major,minor severity:high project:Api id:Microsoft.*", false},
+		{"IS:Vulnerable", false},
+		{"is:", true},
+		{"is:broken", true},
+		{"update:huge", true},
+		{"owner:me", true},
+		{"id:[", true},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.text)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+		}
+	}
+}
+
+// TestMatch tests which rows filters match
+func TestMatch(t *testing.T) {
+	rows := []Row{
+		{Package: "Serilog", Project: "Api.csproj", Version: "2.12.0", Update: "major", Severity: "High"},
+		{Package: "Serilog.Sinks.Console", Project: "Api.csproj", Version: "5.0.0"},
+		{Package: "Newtonsoft.Json", Project: "Worker.csproj", Version: "12.0.1", Update: "major", Severity: "Low"},
+		{Package: "Microsoft.Extensions.Http", Project: "Worker.csproj", Version: "9.0.0-rc.1", Update: "patch", Floating: true},
+	}
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{"", []string{"Serilog", "Serilog.Sinks.Console", "Newtonsoft.Json", "Microsoft.Extensions.Http"}},
+		{"is:vulnerable update:major", []string{"Serilog", "Newtonsoft.Json"}},
+		{"severity:moderate", []string{"Serilog"}},
+		{"serilog -is:outdated", []string{"Serilog.Sinks.Console"}},
+		{"update:minor,patch", []string{"Microsoft.Extensions.Http"}},
+		{"id:microsoft.*", []string{"Microsoft.Extensions.Http"}},
+		{"id:serilog", []string{"Serilog"}},
+		{"project:worker is:prerelease", []string{"Microsoft.Extensions.Http"}},
+		{"is:floating,current", []string{"Serilog.Sinks.Console", "Microsoft.Extensions.Http"}},
+	}
+	for _, tt := range tests {
+		f, err := Parse(tt.filter)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.filter, err)
+		}
+		var got []string
+		for _, r := range rows {
+			if f.Match(r) {
+				got = append(got, r.Package)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%q matched %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+// TestResolve tests that saved filter names take precedence over filter text
+func TestResolve(t *testing.T) {
+	saved := []Saved{{Name: "majors", Query: "update:major"}, {Name: "broken", Query: "is:nope"}}
+	f, err := Resolve(saved, "Majors")
+	if err != nil || f.String() != "update:major" {
+		t.Errorf("Resolve(Majors) = %v, %v, want the saved filter", f, err)
+	}
+	if f, err := Resolve(saved, "serilog  is:outdated"); err != nil || f.String() != "serilog is:outdated" {
+		t.Errorf("Resolve(text) = %v, %v", f, err)
+	}
+	if _, err := Resolve(saved, "broken"); err == nil {
+		t.Error("Resolve() of an invalid saved filter succeeded")
+	}
+	if f, _ := Parse("is:vulnerable"); !f.NeedsAdvisories() {
+		t.Error("NeedsAdvisories() = false for is:vulnerable")
+	}
+}
+
+// TestHistory tests recording and recalling queries
+func TestHistory(t *testing.T) {
+	dir := t.TempDir()
+	h, err := OpenHistory(dir, "/src/app")
+	if err != nil {
+		t.Fatalf("OpenHistory() error = %v", err)
+	}
+	for _, q := range []string{"serilog", "  ", "json", "serilog", "http  client"} {
+		if err := h.Add(q); err != nil {
+			t.Fatalf("Add(%q) error = %v", q, err)
+		}
+	}
+	reopened, err := OpenHistory(dir, "/src/app")
+	if err != nil {
+		t.Fatalf("OpenHistory() error = %v", err)
+	}
+	want := []string{"json", "serilog", "http client"}
+	if got := reopened.Queries(); !slices.Equal(got, want) {
+		t.Errorf("Queries() = %v, want %v", got, want)
+	}
+	if other, _ := OpenHistory(dir, "/src/other"); len(other.Queries()) != 0 {
+		t.Errorf("another workspace has history %v", other.Queries())
+	}
+
+	for i := range HistorySize + 5 {
+		_ = h.Add(string(rune('a'+i%26)) + string(rune('0'+i/26)))
+	}
+	if got := len(h.Queries()); got != HistorySize {
+		t.Errorf("len(Queries()) = %d, want %d", got, HistorySize)
+	}
+	if err := h.Clear(); err != nil || len(h.Queries()) != 0 {
+		t.Errorf("Clear() = %v, Queries() = %v", err, h.Queries())
+	}
+}
+
+// TestRecall tests stepping through the history with the arrow keys
+func TestRecall(t *testing.T) {
+	r := NewRecall([]string{"json", "serilog"})
+	steps := []struct {
+		up   bool
+		want string
+	}{
+		{true, "serilog"},
+		{true, "json"},
+		{true, "json"}, // Stays on the oldest
+		{false, "serilog"},
+		{false, "ht"}, // Back to the draft
+		{false, "ht"},
+		{true, "serilog"},
+	}
+	for i, s := range steps {
+		var got string
+		if s.up {
+			got = r.Prev("ht")
+		} else {
+			got = r.Next()
+		}
+		if got != s.want {
+			t.Errorf("step %d = %q, want %q", i, got, s.want)
+		}
+	}
+	if got := NewRecall(nil).Prev("draft"); got != "draft" {
+		t.Errorf("Prev() with no history = %q, want the draft", got)
+	}
+}
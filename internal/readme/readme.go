@@ -0,0 +1,163 @@
+// Package readme reads package READMEs: Markdown split into headings, text, and code
+// blocks, and the setup snippet ("quickstart") a README shows for using the package.
+package readme
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Kind is the type of a block.
+type Kind string
+
+// Block kinds.
+const (
+	KindHeading Kind = "heading"
+	KindText    Kind = "text"
+	KindCode    Kind = "code"
+)
+
+// Block is a top-level part of a Markdown document.
+type Block struct {
+	Kind     Kind
+	Level    int    // Heading level, 1 to 6
+	Language string // Info string of a fenced code block, lowercased (e.g., "csharp"), or ""
+	Text     string // Heading or paragraph text, or the code without its fences
+}
+
+// Parse splits Markdown into blocks. It understands ATX headings (# Title), fenced code
+// blocks (``` and ~~~), and paragraphs; other syntax stays in the text of paragraphs.
+func Parse(markdown string) []Block {
+	var (
+		blocks    []Block
+		paragraph []string
+		code      []string
+		fence     string // Opening fence of the code block being read
+		language  string
+	)
+	flush := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, Block{Kind: KindText, Text: strings.Join(paragraph, "\n")})
+			paragraph = nil
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				blocks = append(blocks, Block{Kind: KindCode, Language: language, Text: strings.Join(code, "\n")})
+				fence, code = "", nil
+				continue
+			}
+			code = append(code, line)
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			flush()
+			marker := trimmed[:1]
+			info := strings.TrimLeft(trimmed, marker)
+			fence = strings.Repeat(marker, len(trimmed)-len(info))
+			language = ""
+			if fields := strings.Fields(info); len(fields) > 0 {
+				language = strings.ToLower(fields[0])
+			}
+		case headingLevel(trimmed) > 0:
+			flush()
+			level := headingLevel(trimmed)
+			text := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(trimmed[level:]), "#"))
+			blocks = append(blocks, Block{Kind: KindHeading, Level: level, Text: text})
+		case trimmed == "":
+			flush()
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+	if fence != "" {
+		// An unclosed fence runs to the end of the document
+		blocks = append(blocks, Block{Kind: KindCode, Language: language, Text: strings.Join(code, "\n")})
+	}
+	return blocks
+}
+
+// headingLevel returns the level of an ATX heading line, or 0.
+func headingLevel(line string) int {
+	level := len(line) - len(strings.TrimLeft(line, "#"))
+	if level < 1 || level > 6 || (len(line) > level && line[level] != ' ') {
+		return 0
+	}
+	return level
+}
+
+// Snippet is a README's setup code.
+type Snippet struct {
+	Heading  string `json:"heading,omitempty"` // Nearest heading above the code
+	Language string `json:"language,omitempty"`
+	Code     string `json:"code"`
+}
+
+// codeLanguages are the info strings of .NET code; unlabeled blocks are also considered.
+var codeLanguages = map[string]bool{
+	"": true, "csharp": true, "cs": true, "c#": true, "fsharp": true, "fs": true, "f#": true, "vb": true, "vbnet": true,
+}
+
+// setupPatterns recognize setup code: namespace imports and dependency injection
+// registration.
+var setupPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?m)^\s*(global\s+)?using\s+(static\s+)?[A-Z][\w.]*\s*;`),            // C# usings
+	regexp.MustCompile(`(?m)^\s*open\s+[A-Z][\w.]*\s*$`),                                     // F# opens
+	regexp.MustCompile(`(?m)^\s*Imports\s+[A-Z][\w.]*\s*$`),                                  // VB imports
+	regexp.MustCompile(`\b[Ss]ervices\s*\.\s*Add\w+`),                                        // services.AddX(...)
+	regexp.MustCompile(`\.\s*(AddSingleton|AddScoped|AddTransient|AddHostedService)\s*[<(]`), // Service lifetimes
+}
+
+// installPatterns recognize instructions for installing the package, which the user
+// has just done.
+var installPatterns = regexp.MustCompile(`(?i)dotnet\s+add\s|Install-Package\s|<PackageReference\s|paket\s+add\s|#r\s+"nuget:`)
+
+// Quickstart returns the first code block of a README that sets the package up: .NET
+// code with usings or dependency injection registration, other than install commands.
+func Quickstart(markdown string) (Snippet, bool) {
+	heading := ""
+	for _, b := range Parse(markdown) {
+		switch {
+		case b.Kind == KindHeading:
+			heading = b.Text
+		case b.Kind != KindCode || !codeLanguages[b.Language] || installPatterns.MatchString(b.Text):
+		default:
+			for _, p := range setupPatterns {
+				if p.MatchString(b.Text) {
+					return Snippet{Heading: heading, Language: b.Language, Code: dedent(b.Text)}, true
+				}
+			}
+		}
+	}
+	return Snippet{}, false
+}
+
+// dedent removes the indentation every non-blank line of code shares, and surrounding
+// blank lines.
+func dedent(code string) string {
+	lines := strings.Split(strings.Trim(code, "\n"), "\n")
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			lines[i] = line[indent:]
+		}
+		lines[i] = strings.TrimRight(lines[i], " \t")
+	}
+	return strings.Join(lines, "\n")
+}
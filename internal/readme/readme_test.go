@@ -0,0 +1,64 @@
+package readme
+
+import "testing"
+
+const sample = "# Polly\n\nPolly is a resilience library.\n\n" +
+	"## Installing\n\n```powershell\ndotnet add package Polly.Core\n```\n\n" +
+	"```xml\n<PackageReference Include=\"Polly.Core\" Version=\"8.4.0\" />\n```\n\n" +
+	"## Quick start\n\nBuild a pipeline:\n\n" +
+	"````csharp\n    using Polly;\n\n    var pipeline = new ResiliencePipelineBuilder().Build();\n````\n\n" +
+	"~~~\nservices.AddResiliencePipeline(\"default\", builder => { });\n~~~\n"
+
+// TestParse tests splitting Markdown into headings, text, and code blocks
+func TestParse(t *testing.T) {
+	blocks := Parse(sample)
+	kinds := ""
+	for _, b := range blocks {
+		kinds += string(b.Kind[0])
+	}
+	if kinds != "hthcchtcc" {
+		t.Fatalf("block kinds = %s, want hthcchtcc", kinds)
+	}
+	if b := blocks[3]; b.Language != "powershell" || b.Text != "dotnet add package Polly.Core" {
+		t.Errorf("blocks[3] = %+v", b)
+	}
+	if b := blocks[5]; b.Level != 2 || b.Text != "Quick start" {
+		t.Errorf("blocks[5] = %+v", b)
+	}
+	if b := blocks[8]; b.Language != "" || b.Kind != KindCode {
+		t.Errorf("blocks[8] = %+v, want an unlabeled code block", b)
+	}
+
+	// Unclosed fences run to the end; #hashtags are not headings
+	if blocks := Parse("#hashtag\n```cs\nusing X;"); len(blocks) != 2 || blocks[0].Kind != KindText || blocks[1].Text != "using X;" {
+		t.Errorf("Parse(unclosed) = %+v", blocks)
+	}
+}
+
+// TestQuickstart tests finding setup code and skipping install commands and other code
+func TestQuickstart(t *testing.T) {
+	s, ok := Quickstart(sample)
+	if !ok {
+		t.Fatal("Quickstart() found no snippet")
+	}
+	want := "using Polly;\n\nvar pipeline = new ResiliencePipelineBuilder().Build();"
+	if s.Heading != "Quick start" || s.Language != "csharp" || s.Code != want {
+		t.Errorf("Quickstart() = %+v, want %q under Quick start", s, want)
+	}
+
+	di := "```cs\nbuilder.Services.AddSerilog();\n```"
+	if s, ok := Quickstart(di); !ok || s.Code != "builder.Services.AddSerilog();" {
+		t.Errorf("Quickstart(DI) = %+v, %v", s, ok)
+	}
+
+	for _, markdown := range []string{
+		"No code here.",
+		"```js\nimport x from 'y';\n```",
+		"```csharp\nvar x = 1;\n```",
+		"```\ndotnet add package X\nusing X;\n```",
+	} {
+		if s, ok := Quickstart(markdown); ok {
+			t.Errorf("Quickstart(%q) = %+v, want none", markdown, s)
+		}
+	}
+}
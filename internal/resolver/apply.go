@@ -0,0 +1,14 @@
+package resolver
+
+import "github.com/willibrandon/lazynuget/internal/project"
+
+// PackagesPropsFile is the file that holds versions under central package management.
+const PackagesPropsFile = project.PackagesPropsFile
+
+// Apply edits the project so it references the package at the fix version, keeping the
+// rest of the file byte for byte. Under central package management the version is written
+// to Directory.Packages.props instead, and a new reference is preceded by a comment giving
+// the fix's reason. It returns the files changed.
+func (f Fix) Apply() ([]string, error) {
+	return project.PinPackage(f.Project, f.Package, f.Version, f.Reason)
+}
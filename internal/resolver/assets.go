@@ -0,0 +1,231 @@
+package resolver
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+)
+
+// maxGraphPaths bounds the paths reported per package; large graphs can have thousands.
+const maxGraphPaths = 20
+
+// Assets is the dependency graph restore records in obj/project.assets.json.
+type Assets struct {
+	Targets map[string]map[string]assetsLibrary `json:"targets"`
+	Logs    []AssetsLog                         `json:"logs"`
+	Project struct {
+		Restore struct {
+			ProjectName string `json:"projectName"`
+			ProjectPath string `json:"projectPath"`
+		} `json:"restore"`
+		Frameworks map[string]struct {
+			Dependencies map[string]struct {
+				Version        string `json:"version"`
+				AutoReferenced bool   `json:"autoReferenced"` // Added by the SDK (e.g., NETStandard.Library)
+			} `json:"dependencies"`
+		} `json:"frameworks"`
+	} `json:"project"`
+}
+
+// AssetsLog is a warning or error the last restore reported, which build reports again
+// without restoring.
+type AssetsLog struct {
+	Code         string   `json:"code"`
+	Level        string   `json:"level"` // Warning, Error, ...
+	Message      string   `json:"message"`
+	LibraryID    string   `json:"libraryId,omitempty"`
+	TargetGraphs []string `json:"targetGraphs,omitempty"`
+}
+
+// assetsLibrary is a resolved package ("Id/Version") in a target framework.
+type assetsLibrary struct {
+	Dependencies map[string]string `json:"dependencies"`
+	Type         string            `json:"type"`
+}
+
+// AssetsPath returns the assets file restore writes for a project.
+func AssetsPath(project string) string {
+	return filepath.Join(filepath.Dir(project), "obj", "project.assets.json")
+}
+
+// LoadAssets reads a project.assets.json file.
+func LoadAssets(path string) (*Assets, error) {
+	// #nosec G304 -- path is derived from the project being restored
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var assets Assets
+	if err := json.Unmarshal(data, &assets); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &assets, nil
+}
+
+// Paths returns the dependency paths from the project to pkg across all target frameworks,
+// with the version range each step requests.
+func (a *Assets) Paths(pkg string) []Path {
+	root := a.Project.Restore.ProjectName
+	if root == "" {
+		root = strings.TrimSuffix(filepath.Base(a.Project.Restore.ProjectPath), filepath.Ext(a.Project.Restore.ProjectPath))
+	}
+
+	var paths []Path
+	seen := make(map[string]bool)
+	for _, framework := range slices.Sorted(maps.Keys(a.Project.Frameworks)) {
+		resolved := a.resolved(framework)
+		deps := a.Project.Frameworks[framework].Dependencies
+		for _, id := range slices.Sorted(maps.Keys(deps)) {
+			start := Path{{ID: root}, {ID: id, Range: printRange(deps[id].Version)}}
+			walkPaths(start, pkg, resolved, func(p Path) bool {
+				if key := p.String(); !seen[key] {
+					seen[key] = true
+					paths = append(paths, p)
+				}
+				return len(paths) < maxGraphPaths
+			})
+		}
+	}
+	return paths
+}
+
+// ResolvedVersion returns the version restore resolved a package to, or "" when it is not
+// in the graph. When target frameworks resolve different versions, the highest is returned.
+func (a *Assets) ResolvedVersion(id string) string {
+	version := ""
+	for _, libraries := range a.Targets {
+		for key, lib := range libraries {
+			name, v, _ := strings.Cut(key, "/")
+			if strings.EqualFold(name, id) && lib.Type != "project" && nuget.CompareVersions(v, version) > 0 {
+				version = v
+			}
+		}
+	}
+	return version
+}
+
+// ResolvedPackage is a package version restore resolved.
+type ResolvedPackage struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+}
+
+// Packages returns the package versions restore resolved for any target framework, by ID
+// then version. Projects the project references are left out.
+func (a *Assets) Packages() []ResolvedPackage {
+	seen := make(map[string]bool)
+	var packages []ResolvedPackage
+	for _, libraries := range a.Targets {
+		for key, lib := range libraries {
+			id, version, _ := strings.Cut(key, "/")
+			if lib.Type == "project" || seen[strings.ToLower(key)] {
+				continue
+			}
+			seen[strings.ToLower(key)] = true
+			packages = append(packages, ResolvedPackage{ID: id, Version: version})
+		}
+	}
+	slices.SortFunc(packages, func(a, b ResolvedPackage) int {
+		if c := strings.Compare(strings.ToLower(a.ID), strings.ToLower(b.ID)); c != 0 {
+			return c
+		}
+		return nuget.CompareVersions(a.Version, b.Version)
+	})
+	return packages
+}
+
+// resolved maps lowercase package IDs to their dependencies for a target framework.
+// Target keys may carry a runtime identifier ("net8.0/linux-x64"), which is ignored.
+func (a *Assets) resolved(framework string) map[string]map[string]string {
+	resolved := make(map[string]map[string]string)
+	for target, libraries := range a.Targets {
+		if tfm, _, _ := strings.Cut(target, "/"); !strings.EqualFold(tfm, framework) {
+			continue
+		}
+		for key, lib := range libraries {
+			id, _, _ := strings.Cut(key, "/")
+			resolved[strings.ToLower(id)] = lib.Dependencies
+		}
+	}
+	return resolved
+}
+
+// walkPaths extends path depth first until it reaches pkg, calling emit for each complete
+// path. Cycles are skipped. It stops when emit returns false.
+func walkPaths(path Path, pkg string, resolved map[string]map[string]string, emit func(Path) bool) bool {
+	last := path[len(path)-1].ID
+	if strings.EqualFold(last, pkg) {
+		return emit(slices.Clone(path))
+	}
+
+	deps := resolved[strings.ToLower(last)]
+	for _, id := range slices.Sorted(maps.Keys(deps)) {
+		if slices.ContainsFunc(path, func(e Edge) bool { return strings.EqualFold(e.ID, id) }) {
+			continue
+		}
+		if !walkPaths(append(path, Edge{ID: id, Range: printRange(deps[id])}), pkg, resolved, emit) {
+			return false
+		}
+	}
+	return true
+}
+
+// printRange converts interval notation to the form NuGet prints in diagnostics
+// ("[1.0.0, )" and "1.0.0" become ">= 1.0.0"; "[1.0.0]" becomes "= 1.0.0").
+func printRange(r string) string {
+	r = strings.TrimSpace(r)
+	switch {
+	case r == "":
+		return ""
+	case strings.HasPrefix(r, "[") && strings.HasSuffix(r, "]") && !strings.Contains(r, ","):
+		return "= " + strings.Trim(r, "[]")
+	case strings.HasPrefix(r, "[") && strings.HasSuffix(r, ", )"):
+		return ">= " + strings.TrimSuffix(strings.TrimPrefix(r, "["), ", )")
+	case !strings.ContainsAny(r, "[](),"):
+		return ">= " + r
+	}
+	return r
+}
+
+// AddGraphPaths fills in the conflict's paths from the assets graph when the diagnostic
+// did not include them.
+func (c *Conflict) AddGraphPaths(a *Assets) {
+	if len(c.Paths) > 0 || a == nil {
+		return
+	}
+	c.Paths = a.Paths(c.Package)
+}
+
+// Constraint is a version range a package in the graph requests for a dependency.
+type Constraint struct {
+	Package string `json:"package"` // The requesting package
+	Range   string `json:"range"`   // As written in the assets file (e.g., "4.3.0" or "[1.0.0, 2.0.0)")
+}
+
+// Constraints returns the version ranges packages in the graph request for pkg, across
+// target frameworks, sorted by requesting package. The project's own reference is not
+// included: it is what a fix changes.
+func (a *Assets) Constraints(pkg string) []Constraint {
+	var constraints []Constraint
+	for _, libraries := range a.Targets {
+		for key, lib := range libraries {
+			id, _, _ := strings.Cut(key, "/")
+			for dep, r := range lib.Dependencies {
+				c := Constraint{Package: id, Range: r}
+				if strings.EqualFold(dep, pkg) && !slices.Contains(constraints, c) {
+					constraints = append(constraints, c)
+				}
+			}
+		}
+	}
+	slices.SortFunc(constraints, func(x, y Constraint) int {
+		return strings.Compare(x.Package+" "+x.Range, y.Package+" "+y.Range)
+	})
+	return constraints
+}
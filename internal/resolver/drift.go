@@ -0,0 +1,257 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/project"
+)
+
+// Drift kinds: how the declared package references of a project differ from what its
+// last restore recorded.
+const (
+	DriftAdded            = "added"             // Referenced, but not restored
+	DriftRemoved          = "removed"           // Restored, but no longer referenced
+	DriftChanged          = "changed"           // Restored with another version
+	DriftFrameworkAdded   = "framework-added"   // Targeted, but not restored
+	DriftFrameworkRemoved = "framework-removed" // Restored, but no longer targeted
+	DriftUnrestored       = "unrestored"        // The project has no assets file
+)
+
+// Drift is a difference between a project's package references and its assets file,
+// which a restore resolves.
+type Drift struct {
+	Kind       string   `json:"kind"`
+	Package    string   `json:"package,omitempty"`    // "" for a framework or an unrestored project
+	Frameworks []string `json:"frameworks,omitempty"` // The target frameworks it applies to; none when it applies to all
+	Declared   string   `json:"declared,omitempty"`   // The version or framework the project files declare
+	Restored   string   `json:"restored,omitempty"`   // The version range or framework in the assets file
+}
+
+// String describes a drift in a sentence.
+func (d Drift) String() string {
+	var s string
+	switch d.Kind {
+	case DriftAdded:
+		s = strings.TrimSpace(d.Package+" "+d.Declared) + " is referenced but not restored"
+	case DriftRemoved:
+		s = d.Package + " is restored but no longer referenced"
+	case DriftChanged:
+		s = fmt.Sprintf("%s is %s but was restored as %s", d.Package, d.Declared, d.Restored)
+	case DriftFrameworkAdded:
+		s = d.Declared + " is targeted but not restored"
+	case DriftFrameworkRemoved:
+		s = d.Restored + " is restored but no longer targeted"
+	default:
+		return "not restored"
+	}
+	if len(d.Frameworks) > 0 {
+		s += " (" + strings.Join(d.Frameworks, ", ") + ")"
+	}
+	return s
+}
+
+// CheckDrift compares the package references a project declares, in the project file
+// and the files it imports (Directory.Build.props, Directory.Packages.props, ...), with
+// those its last restore recorded in obj/project.assets.json. A project without an assets
+// file drifts as unrestored.
+//
+// References and versions are compared per target framework. A reference whose condition
+// cannot be evaluated, or whose version uses a property, counts as declared but its version
+// is not compared, so drift is only reported when the files say for certain that restore
+// has something to do.
+func CheckDrift(path string) ([]Drift, error) {
+	p, err := project.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	assets, err := LoadAssets(AssetsPath(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Drift{{Kind: DriftUnrestored}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	chain, err := project.ImportChain(path, "")
+	if err != nil {
+		return nil, err
+	}
+	return assets.drift(p.TargetFrameworks, declaredItems(chain)), nil
+}
+
+// declaredItem is a package reference found in an import chain.
+type declaredItem struct {
+	id        string
+	version   string // The Version or VersionOverride, or the central PackageVersion
+	condition string
+}
+
+// declaredItems returns the package references of an import chain with the versions
+// they resolve to, applying Update items and central PackageVersions. The files restore
+// wrote and the SDK's are left out.
+func declaredItems(chain []project.Import) []declaredItem {
+	var items []declaredItem
+	central := make(map[string]string)
+	for _, imp := range chain {
+		if imp.Kind == project.ImportRestore || imp.Kind == project.ImportSDK {
+			continue
+		}
+		for _, d := range imp.Definitions {
+			switch {
+			case d.Kind == "property":
+			case strings.EqualFold(d.Kind, "PackageVersion"):
+				central[strings.ToLower(d.Name)] = d.Value
+			case d.Update:
+				for i := range items {
+					if strings.EqualFold(items[i].id, d.Name) && d.Value != "" {
+						items[i].version = d.Value
+					}
+				}
+			default:
+				items = append(items, declaredItem{id: d.Name, version: d.Value, condition: d.Condition})
+			}
+		}
+	}
+	for i := range items {
+		if items[i].version == "" {
+			items[i].version = central[strings.ToLower(items[i].id)]
+		}
+	}
+	return items
+}
+
+// drift compares declared package references with the assets file, framework by
+// framework, and merges the drifts every framework shares.
+func (a *Assets) drift(frameworks []string, items []declaredItem) []Drift {
+	if len(frameworks) == 0 || slices.ContainsFunc(frameworks, func(f string) bool { return strings.Contains(f, "$(") }) {
+		frameworks = slices.Sorted(maps.Keys(a.Project.Frameworks)) // The project does not say; trust restore
+	}
+
+	var drifts []Drift
+	restoredKeys := make(map[string]bool)
+	for _, framework := range frameworks {
+		key, ok := a.frameworkKey(framework)
+		if !ok {
+			drifts = append(drifts, Drift{Kind: DriftFrameworkAdded, Declared: framework})
+			continue
+		}
+		restoredKeys[key] = true
+
+		declared := make(map[string]declaredItem)
+		uncertain := make(map[string]bool) // Referenced under a condition that cannot be evaluated
+		for _, item := range items {
+			lower := strings.ToLower(item.id)
+			if item.condition != "" {
+				applies, ok := project.EvaluateCondition(item.condition, framework)
+				if !ok {
+					uncertain[lower] = true
+					continue
+				}
+				if !applies {
+					continue
+				}
+			}
+			declared[lower] = item
+		}
+
+		restored := make(map[string]string)
+		ids := make(map[string]string)
+		for id, dep := range a.Project.Frameworks[key].Dependencies {
+			if !dep.AutoReferenced {
+				restored[strings.ToLower(id)] = dep.Version
+				ids[strings.ToLower(id)] = id
+			}
+		}
+
+		for _, lower := range slices.Sorted(maps.Keys(declared)) {
+			item := declared[lower]
+			version, ok := restored[lower]
+			switch {
+			case !ok:
+				drifts = append(drifts, Drift{Kind: DriftAdded, Package: item.id, Frameworks: []string{framework}, Declared: item.version})
+			case item.version != "" && !strings.Contains(item.version, "$(") && !sameRange(item.version, version):
+				drifts = append(drifts, Drift{Kind: DriftChanged, Package: item.id, Frameworks: []string{framework}, Declared: item.version, Restored: version})
+			}
+		}
+		for _, lower := range slices.Sorted(maps.Keys(restored)) {
+			if _, ok := declared[lower]; !ok && !uncertain[lower] {
+				drifts = append(drifts, Drift{Kind: DriftRemoved, Package: ids[lower], Frameworks: []string{framework}, Restored: restored[lower]})
+			}
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(a.Project.Frameworks)) {
+		if !restoredKeys[key] {
+			drifts = append(drifts, Drift{Kind: DriftFrameworkRemoved, Restored: key})
+		}
+	}
+	return mergeDrifts(drifts, len(frameworks))
+}
+
+// frameworkKey returns the key of a target framework in the assets file: the framework as
+// the project writes it, or another moniker for the same framework (net8.0, net80).
+func (a *Assets) frameworkKey(framework string) (string, bool) {
+	want, err := nuget.ParseFramework(framework)
+	for key := range a.Project.Frameworks {
+		if strings.EqualFold(key, framework) {
+			return key, true
+		}
+		if f, keyErr := nuget.ParseFramework(key); err == nil && keyErr == nil && want.Family != nuget.FamilyUnknown &&
+			f.Family == want.Family && f.Platform == want.Platform && f.Major == want.Major && f.Minor == want.Minor && f.Patch == want.Patch {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// mergeDrifts combines the drifts of the same package in several frameworks into one,
+// without frameworks when all of them have it.
+func mergeDrifts(drifts []Drift, frameworks int) []Drift {
+	var merged []Drift
+	index := make(map[string]int)
+	for _, d := range drifts {
+		if d.Package == "" {
+			merged = append(merged, d)
+			continue
+		}
+		key := strings.ToLower(d.Kind + "\x00" + d.Package + "\x00" + d.Declared + "\x00" + d.Restored)
+		if i, ok := index[key]; ok {
+			merged[i].Frameworks = append(merged[i].Frameworks, d.Frameworks...)
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, d)
+	}
+	for i := range merged {
+		if len(merged[i].Frameworks) == frameworks {
+			merged[i].Frameworks = nil
+		}
+	}
+	return merged
+}
+
+// sameRange reports whether a declared version and the range restore recorded for it
+// (e.g., "13.0.3" and "[13.0.3, )") allow the same versions.
+func sameRange(declared, restored string) bool {
+	d, errD := nuget.ParseVersionRange(declared)
+	r, errR := nuget.ParseVersionRange(restored)
+	if errD != nil || errR != nil || d.IsFloating() || r.IsFloating() {
+		return normalizeRange(declared) == normalizeRange(restored)
+	}
+	return nuget.CompareVersions(d.Min, r.Min) == 0 && nuget.CompareVersions(d.Max, r.Max) == 0 &&
+		d.MinInclusive == r.MinInclusive && (d.Max == "" || d.MaxInclusive == r.MaxInclusive)
+}
+
+// normalizeRange reduces a range to a comparable form: lowercase, without spaces, and with
+// a lone inclusive minimum written as the version alone.
+func normalizeRange(s string) string {
+	s = strings.ToLower(strings.ReplaceAll(s, " ", ""))
+	if inner, ok := strings.CutPrefix(s, "["); ok && strings.HasSuffix(inner, ",)") {
+		return strings.TrimSuffix(inner, ",)")
+	}
+	return s
+}
@@ -0,0 +1,244 @@
+// Package resolver explains NuGet package downgrades (NU1605) and version conflicts
+// (NU1107) and proposes fixes.
+//
+// Both happen when two dependency paths from a project request different versions of the
+// same package. The resolver reconstructs those paths from the restore diagnostics (and
+// from obj/project.assets.json when available), identifies the conflicting edges, and
+// proposes the fix NuGet itself recommends: a top-level PackageReference to the highest
+// requested version, which takes precedence over every transitive request.
+package resolver
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/msbuild"
+	"github.com/willibrandon/lazynuget/internal/nuget"
+)
+
+// Diagnostic codes handled by the resolver.
+const (
+	CodeDowngrade = "NU1605" // Detected package downgrade
+	CodeConflict  = "NU1107" // Version conflict detected
+)
+
+// Edge is one step in a dependency path: a package (or the project itself, with an empty
+// Range) and the version range requested by the previous step.
+type Edge struct {
+	ID    string
+	Range string // e.g., ">= 3.1.0" or "= 1.0.0"; empty for the root project
+}
+
+// Path is a chain of dependencies from a project to the conflicting package.
+type Path []Edge
+
+// String renders the path the way NuGet prints it (e.g., "App -> B 1.0.0 -> X (>= 2.0.0)").
+func (p Path) String() string {
+	parts := make([]string, 0, len(p))
+	for i, edge := range p {
+		switch {
+		case i == 0 || edge.Range == "":
+			parts = append(parts, edge.ID)
+		case i == len(p)-1:
+			parts = append(parts, fmt.Sprintf("%s (%s)", edge.ID, edge.Range))
+		default:
+			parts = append(parts, edge.ID+" "+strings.TrimLeft(edge.Range, ">=< "))
+		}
+	}
+	return strings.Join(parts, " -> ")
+}
+
+// Requested returns the version range the path requests for its last package.
+func (p Path) Requested() string {
+	if len(p) == 0 {
+		return ""
+	}
+	return p[len(p)-1].Range
+}
+
+// Direct reports whether the path is a top-level reference from the project.
+func (p Path) Direct() bool {
+	return len(p) == 2
+}
+
+// Conflict is a package requested at incompatible versions by different paths.
+type Conflict struct {
+	Code       string
+	Package    string
+	Project    string // Project file the diagnostic was reported for
+	Message    string
+	Paths      []Path
+	Diagnostic msbuild.Diagnostic
+}
+
+// Fix is a proposed change that resolves a conflict.
+type Fix struct {
+	Project     string // Project file to edit
+	Package     string
+	Version     string
+	Description string
+	// Reason is written as a comment above a reference the fix adds (e.g., a top-level
+	// reference that pins a transitive package)
+	Reason string
+}
+
+var (
+	// downgradePattern matches "Detected package downgrade: X from 2.0.0 to 1.0.0".
+	downgradePattern = regexp.MustCompile(`package downgrade: (\S+) from (\S+) to (\S+?)\.?(?:\s|$)`)
+
+	// conflictPattern matches "Version conflict detected for X."
+	conflictPattern = regexp.MustCompile(`[Vv]ersion conflict detected for (\S+?)\.?(?:\s|$)`)
+
+	// pathStepPattern matches one step of a printed path: "B 1.0.0" or "X (>= 2.0.0)".
+	pathStepPattern = regexp.MustCompile(`^(\S+)(?:\s+\(([^)]*)\)|\s+(\S+))?$`)
+)
+
+// FromDiagnostics returns the conflicts reported by restore diagnostics.
+func FromDiagnostics(diagnostics []msbuild.Diagnostic) []Conflict {
+	var conflicts []Conflict
+	for _, d := range diagnostics {
+		var pkg string
+		switch d.Code {
+		case CodeDowngrade:
+			m := downgradePattern.FindStringSubmatch(d.Message)
+			if m == nil {
+				continue
+			}
+			pkg = m[1]
+		case CodeConflict:
+			m := conflictPattern.FindStringSubmatch(d.Message)
+			if m == nil {
+				continue
+			}
+			pkg = m[1]
+		default:
+			continue
+		}
+
+		project := d.Project
+		if project == "" && strings.HasSuffix(strings.ToLower(d.File), "proj") {
+			project = d.File
+		}
+
+		c := Conflict{Code: d.Code, Package: pkg, Project: project, Message: d.Message, Diagnostic: d}
+		for _, detail := range append(messagePaths(d.Message), d.Details...) {
+			if path, ok := ParsePath(detail); ok && strings.EqualFold(path[len(path)-1].ID, pkg) {
+				c.Paths = append(c.Paths, path)
+			}
+		}
+		conflicts = append(conflicts, c)
+	}
+	return conflicts
+}
+
+// messagePaths returns paths embedded in a single-line message, which MSBuild produces by
+// joining NuGet's multi-line message with spaces ("... App -> B 1.0 -> X (>= 2.0) App -> X (>= 1.0)").
+func messagePaths(message string) []string {
+	var paths []string
+	fields := strings.Fields(message)
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i+1] != "->" || (i > 0 && fields[i-1] == "->") {
+			continue
+		}
+		// Collect "A -> B v -> X (range)" until the closing parenthesis of the last step
+		var sb strings.Builder
+		j := i
+		for ; j < len(fields); j++ {
+			sb.WriteString(fields[j] + " ")
+			if strings.HasSuffix(fields[j], ")") && (j+1 == len(fields) || fields[j+1] != "->") {
+				break
+			}
+		}
+		paths = append(paths, strings.TrimSpace(sb.String()))
+		i = j
+	}
+	return paths
+}
+
+// ParsePath parses a printed dependency path (e.g., "App -> B 1.0.0 -> X (>= 2.0.0)").
+func ParsePath(s string) (Path, bool) {
+	steps := strings.Split(strings.TrimSpace(s), " -> ")
+	if len(steps) < 2 {
+		return nil, false
+	}
+
+	path := make(Path, 0, len(steps))
+	for i, step := range steps {
+		m := pathStepPattern.FindStringSubmatch(strings.TrimSpace(step))
+		if m == nil {
+			return nil, false
+		}
+		edge := Edge{ID: m[1]}
+		switch {
+		case m[2] != "":
+			edge.Range = strings.TrimSpace(m[2])
+		case m[3] != "" && i > 0:
+			edge.Range = ">= " + m[3]
+		}
+		path = append(path, edge)
+	}
+	return path, true
+}
+
+// ConflictingEdges returns the paths whose requested minimum version is below the highest
+// one requested; those are the edges a fix has to override.
+func (c Conflict) ConflictingEdges() []Path {
+	target := c.targetVersion()
+	var edges []Path
+	for _, p := range c.Paths {
+		if nuget.CompareVersions(MinVersion(p.Requested()), target) < 0 {
+			edges = append(edges, p)
+		}
+	}
+	return edges
+}
+
+// targetVersion returns the highest minimum version requested by any path.
+func (c Conflict) targetVersion() string {
+	target := ""
+	if c.Code == CodeDowngrade {
+		if m := downgradePattern.FindStringSubmatch(c.Message); m != nil {
+			target = m[2]
+		}
+	}
+	for _, p := range c.Paths {
+		if v := MinVersion(p.Requested()); nuget.CompareVersions(v, target) > 0 {
+			target = v
+		}
+	}
+	return target
+}
+
+// Propose returns the fix for the conflict: reference the package directly from the project
+// at the highest requested version. It returns false when the version or project is unknown.
+func (c Conflict) Propose() (Fix, bool) {
+	version := c.targetVersion()
+	if version == "" || c.Project == "" {
+		return Fix{}, false
+	}
+
+	fix := Fix{
+		Project:     c.Project,
+		Package:     c.Package,
+		Version:     version,
+		Description: fmt.Sprintf("Add a top-level reference to %s %s", c.Package, version),
+		Reason:      fmt.Sprintf("Pinned to override transitive versions and fix %s (%s)", c.Code, c.kind()),
+	}
+	for _, p := range c.Paths {
+		if p.Direct() {
+			fix.Description = fmt.Sprintf("Update the reference to %s to %s", c.Package, version)
+			fix.Reason = ""
+			break
+		}
+	}
+	return fix, true
+}
+
+// kind names the diagnostic a conflict was reported with.
+func (c Conflict) kind() string {
+	if c.Code == CodeDowngrade {
+		return "package downgrade"
+	}
+	return "version conflict"
+}
@@ -0,0 +1,320 @@
+package resolver
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/msbuild"
+)
+
+// TestFromDiagnostics tests building conflicts from NU1605 and NU1107 diagnostics
+func TestFromDiagnostics(t *testing.T) {
+	output := "/src/App/App.csproj : error NU1605: Warning As Error: Detected package downgrade: Serilog from 3.1.0 to 3.0.0. " +
+		"Reference the package directly from the project to select a different version.  " +
+		"App -> Serilog.Sinks.File 5.0.0 -> Serilog (>= 3.1.0)  App -> Serilog (>= 3.0.0) [/src/App/App.csproj]\n" +
+		"/src/App/App.csproj : error NU1107: Version conflict detected for Newtonsoft.Json. Install/reference Newtonsoft.Json 13.0.1 directly to project App to resolve this issue. [/src/App/App.csproj]\n" +
+		"   App -> A 1.0.0 -> Newtonsoft.Json (>= 13.0.1)\n" +
+		"   App -> B 2.0.0 -> Newtonsoft.Json (= 12.0.3)\n" +
+		"/src/App/App.csproj : warning NU1603: A 1.0.0 depends on C (>= 1.0.0) but C 1.0.0 was not found. [/src/App/App.csproj]\n"
+
+	conflicts := FromDiagnostics(msbuild.Parse(output))
+	if len(conflicts) != 2 {
+		t.Fatalf("FromDiagnostics() returned %d conflicts, want 2: %+v", len(conflicts), conflicts)
+	}
+
+	downgrade := conflicts[0]
+	if downgrade.Code != CodeDowngrade || downgrade.Package != "Serilog" || downgrade.Project != "/src/App/App.csproj" {
+		t.Errorf("downgrade = %+v", downgrade)
+	}
+	if len(downgrade.Paths) != 2 {
+		t.Fatalf("downgrade paths = %v, want 2", downgrade.Paths)
+	}
+	edges := downgrade.ConflictingEdges()
+	if len(edges) != 1 || edges[0].String() != "App -> Serilog (>= 3.0.0)" {
+		t.Errorf("ConflictingEdges() = %v, want the direct 3.0.0 reference", edges)
+	}
+	fix, ok := downgrade.Propose()
+	if !ok || fix.Version != "3.1.0" || fix.Description != "Update the reference to Serilog to 3.1.0" || fix.Reason != "" {
+		t.Errorf("Propose() = %+v, %v", fix, ok)
+	}
+
+	conflict := conflicts[1]
+	if conflict.Package != "Newtonsoft.Json" || len(conflict.Paths) != 2 {
+		t.Fatalf("conflict = %+v", conflict)
+	}
+	fix, ok = conflict.Propose()
+	if !ok || fix.Version != "13.0.1" || fix.Description != "Add a top-level reference to Newtonsoft.Json 13.0.1" ||
+		fix.Reason != "Pinned to override transitive versions and fix NU1107 (version conflict)" {
+		t.Errorf("Propose() = %+v, %v", fix, ok)
+	}
+}
+
+// TestParsePath tests parsing printed dependency paths
+func TestParsePath(t *testing.T) {
+	path, ok := ParsePath("App -> B 1.0.0 -> X (>= 2.0.0)")
+	if !ok {
+		t.Fatal("ParsePath() failed")
+	}
+	want := Path{{ID: "App"}, {ID: "B", Range: ">= 1.0.0"}, {ID: "X", Range: ">= 2.0.0"}}
+	if !slices.Equal(path, want) {
+		t.Errorf("ParsePath() = %+v, want %+v", path, want)
+	}
+	if path.String() != "App -> B 1.0.0 -> X (>= 2.0.0)" {
+		t.Errorf("String() = %q", path.String())
+	}
+
+	if _, ok := ParsePath("Restore completed in 1.2 sec"); ok {
+		t.Error("ParsePath() should reject text without a path")
+	}
+}
+
+// TestMinVersion tests reading the lower bound of printed version ranges
+func TestMinVersion(t *testing.T) {
+	for r, want := range map[string]string{">= 1.2.0": "1.2.0", "= 3.0.0": "3.0.0", "[1.0.0, 2.0.0)": "1.0.0", "(>= 1.0 && < 2.0)": "1.0"} {
+		if got := MinVersion(r); got != want {
+			t.Errorf("MinVersion(%q) = %q, want %q", r, got, want)
+		}
+	}
+}
+
+// TestAssetsPaths tests finding dependency paths in project.assets.json
+func TestAssetsPaths(t *testing.T) {
+	dir := t.TempDir()
+	project := filepath.Join(dir, "App.csproj")
+	assetsJSON := `{
+  "version": 3,
+  "targets": {
+    "net8.0": {
+      "A/1.0.0": {"type": "package", "dependencies": {"Newtonsoft.Json": "13.0.1"}},
+      "B/2.0.0": {"type": "package", "dependencies": {"Newtonsoft.Json": "[12.0.3]", "A": "1.0.0"}},
+      "Newtonsoft.Json/13.0.1": {"type": "package"}
+    }
+  },
+  "project": {
+    "restore": {"projectName": "App", "projectPath": "/src/App/App.csproj"},
+    "frameworks": {"net8.0": {"dependencies": {"A": {"version": "[1.0.0, )"}, "B": {"version": "[2.0.0, )"}}}}
+  }
+}`
+	if err := os.MkdirAll(filepath.Dir(AssetsPath(project)), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(AssetsPath(project), []byte(assetsJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	assets, err := LoadAssets(AssetsPath(project))
+	if err != nil {
+		t.Fatalf("LoadAssets() error = %v", err)
+	}
+
+	var got []string
+	for _, p := range assets.Paths("newtonsoft.json") {
+		got = append(got, p.String())
+	}
+	want := []string{
+		"App -> A 1.0.0 -> Newtonsoft.Json (>= 13.0.1)",
+		"App -> B 2.0.0 -> A 1.0.0 -> Newtonsoft.Json (>= 13.0.1)",
+		"App -> B 2.0.0 -> Newtonsoft.Json (= 12.0.3)",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Paths() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if got := assets.ResolvedVersion("newtonsoft.json"); got != "13.0.1" {
+		t.Errorf("ResolvedVersion() = %q, want 13.0.1", got)
+	}
+	if got := assets.ResolvedVersion("Missing"); got != "" {
+		t.Errorf("ResolvedVersion(Missing) = %q, want empty", got)
+	}
+
+	constraints := assets.Constraints("newtonsoft.json")
+	wantConstraints := []Constraint{{Package: "A", Range: "13.0.1"}, {Package: "B", Range: "[12.0.3]"}}
+	if !slices.Equal(constraints, wantConstraints) {
+		t.Errorf("Constraints() = %v, want %v", constraints, wantConstraints)
+	}
+
+	c := Conflict{Code: CodeConflict, Package: "Newtonsoft.Json", Project: project}
+	c.AddGraphPaths(assets)
+	if edges := c.ConflictingEdges(); len(edges) != 1 || edges[0].Requested() != "= 12.0.3" {
+		t.Errorf("ConflictingEdges() = %v, want the exact 12.0.3 request", edges)
+	}
+}
+
+// TestCheckDrift tests comparing declared package references with the assets file
+func TestCheckDrift(t *testing.T) {
+	dir := t.TempDir()
+	project := filepath.Join(dir, "App.csproj")
+	files := map[string]string{
+		"Directory.Build.props": `<Project><ItemGroup><PackageReference Include="StyleCop.Analyzers" Version="1.1.118" /></ItemGroup></Project>`,
+		"App.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup><TargetFrameworks>net8.0;net48</TargetFrameworks></PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.1.1" />
+    <PackageReference Include="Polly" Version="$(PollyVersion)" Condition="'$(TargetFramework)' == 'net48'" />
+  </ItemGroup>
+</Project>`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if drift, err := CheckDrift(project); err != nil || len(drift) != 1 || drift[0].Kind != DriftUnrestored {
+		t.Errorf("CheckDrift() before restore = %v, %v, want unrestored", drift, err)
+	}
+
+	assetsJSON := `{"version": 3, "targets": {}, "project": {"frameworks": {
+  "net8.0": {"dependencies": {
+    "Serilog": {"version": "[3.0.0, )"},
+    "StyleCop.Analyzers": {"version": "[1.1.118, )"},
+    "NETStandard.Library": {"version": "[2.0.3, )", "autoReferenced": true},
+    "Old": {"version": "[1.0.0, )"}}},
+  "net6.0": {"dependencies": {}}}}}`
+	if err := os.MkdirAll(filepath.Dir(AssetsPath(project)), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(AssetsPath(project), []byte(assetsJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	drift, err := CheckDrift(project)
+	if err != nil {
+		t.Fatalf("CheckDrift() error = %v", err)
+	}
+	var got []string
+	for _, d := range drift {
+		got = append(got, d.String())
+	}
+	want := []string{
+		"Serilog is 3.1.1 but was restored as [3.0.0, ) (net8.0)",
+		"Old is restored but no longer referenced (net8.0)",
+		"net48 is targeted but not restored",
+		"net6.0 is restored but no longer targeted",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("CheckDrift() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	for declared, restored := range map[string]string{"13.0.3": "[13.0.3, )", "[1.0, 2.0)": "[1.0.0, 2.0.0)", "6.0.*": "[6.0.*, )"} {
+		if !sameRange(declared, restored) {
+			t.Errorf("sameRange(%q, %q) = false, want true", declared, restored)
+		}
+	}
+	if sameRange("[1.0]", "[1.0.0, )") {
+		t.Error("sameRange() should tell an exact version from a minimum")
+	}
+}
+
+// TestApply tests editing project files to apply fixes
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name        string
+		project     string
+		props       string
+		reason      string
+		wantProject string
+		wantProps   string
+	}{
+		{
+			name:        "update attribute",
+			project:     "<Project Sdk=\"Microsoft.NET.Sdk\">\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\n  </ItemGroup>\n</Project>\n",
+			wantProject: "<Project Sdk=\"Microsoft.NET.Sdk\">\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.1.0\" />\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name:        "update child element",
+			project:     "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"serilog\">\n      <Version>3.0.0</Version>\n    </PackageReference>\n  </ItemGroup>\n</Project>\n",
+			wantProject: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"serilog\">\n      <Version>3.1.0</Version>\n    </PackageReference>\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name:        "update keeps asset metadata",
+			project:     "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" PrivateAssets=\"all\" Version=\"3.0.0\" IncludeAssets=\"runtime; build\" />\n    <PackageReference Include=\"Other\">\n      <Version>1.0.0</Version>\n    </PackageReference>\n  </ItemGroup>\n</Project>\n",
+			wantProject: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" PrivateAssets=\"all\" Version=\"3.1.0\" IncludeAssets=\"runtime; build\" />\n    <PackageReference Include=\"Other\">\n      <Version>1.0.0</Version>\n    </PackageReference>\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name:        "update child element keeps asset metadata",
+			project:     "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\">\n      <PrivateAssets>all</PrivateAssets>\n      <Version>3.0.0</Version>\n      <ExcludeAssets>analyzers</ExcludeAssets>\n    </PackageReference>\n  </ItemGroup>\n</Project>\n",
+			wantProject: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\">\n      <PrivateAssets>all</PrivateAssets>\n      <Version>3.1.0</Version>\n      <ExcludeAssets>analyzers</ExcludeAssets>\n    </PackageReference>\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name:        "add after existing references",
+			project:     "<Project>\n\t<ItemGroup>\n\t\t<PackageReference Include=\"Other\" Version=\"1.0.0\" />\n\t</ItemGroup>\n</Project>\n",
+			wantProject: "<Project>\n\t<ItemGroup>\n\t\t<PackageReference Include=\"Other\" Version=\"1.0.0\" />\n\t\t<PackageReference Include=\"Serilog\" Version=\"3.1.0\" />\n\t</ItemGroup>\n</Project>\n",
+		},
+		{
+			name:        "add new item group",
+			project:     "<Project>\n  <PropertyGroup>\n    <TargetFramework>net8.0</TargetFramework>\n  </PropertyGroup>\n</Project>\n",
+			wantProject: "<Project>\n  <PropertyGroup>\n    <TargetFramework>net8.0</TargetFramework>\n  </PropertyGroup>\n\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.1.0\" />\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name:        "pin with a reason",
+			project:     "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Other\" Version=\"1.0.0\" />\n  </ItemGroup>\n</Project>\n",
+			reason:      "Pinned to fix NU1605",
+			wantProject: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Other\" Version=\"1.0.0\" />\n    <!-- Pinned to fix NU1605 -->\n    <PackageReference Include=\"Serilog\" Version=\"3.1.0\" />\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name:        "reason is not written for an existing reference",
+			project:     "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.0.0\" />\n  </ItemGroup>\n</Project>\n",
+			reason:      "Pinned to fix NU1605",
+			wantProject: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"3.1.0\" />\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name:        "pin under central package management",
+			project:     "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Other\" />\n  </ItemGroup>\n</Project>\n",
+			props:       "<Project>\n  <PropertyGroup>\n    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>\n  </PropertyGroup>\n  <ItemGroup>\n    <PackageVersion Include=\"Other\" Version=\"1.0.0\" />\n  </ItemGroup>\n</Project>\n",
+			reason:      "Pinned to fix NU1605",
+			wantProject: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Other\" />\n    <!-- Pinned to fix NU1605 -->\n    <PackageReference Include=\"Serilog\" />\n  </ItemGroup>\n</Project>\n",
+			wantProps:   "<Project>\n  <PropertyGroup>\n    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>\n  </PropertyGroup>\n  <ItemGroup>\n    <PackageVersion Include=\"Other\" Version=\"1.0.0\" />\n    <PackageVersion Include=\"Serilog\" Version=\"3.1.0\" />\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name:        "central package management",
+			project:     "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" />\n  </ItemGroup>\n</Project>\n",
+			props:       "<Project>\n  <PropertyGroup>\n    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>\n  </PropertyGroup>\n  <ItemGroup>\n    <PackageVersion Include=\"Serilog\" Version=\"3.0.0\" />\n  </ItemGroup>\n</Project>\n",
+			wantProject: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" />\n  </ItemGroup>\n</Project>\n",
+			wantProps:   "<Project>\n  <PropertyGroup>\n    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>\n  </PropertyGroup>\n  <ItemGroup>\n    <PackageVersion Include=\"Serilog\" Version=\"3.1.0\" />\n  </ItemGroup>\n</Project>\n",
+		},
+		{
+			name:        "central package management with version override",
+			project:     "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" VersionOverride=\"3.0.0\" Aliases=\"Log\" />\n  </ItemGroup>\n</Project>\n",
+			props:       "<Project>\n  <PropertyGroup>\n    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>\n  </PropertyGroup>\n  <ItemGroup>\n    <PackageVersion Include=\"Serilog\" Version=\"2.0.0\" />\n  </ItemGroup>\n</Project>\n",
+			wantProject: "<Project>\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" VersionOverride=\"3.1.0\" Aliases=\"Log\" />\n  </ItemGroup>\n</Project>\n",
+			wantProps:   "<Project>\n  <PropertyGroup>\n    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>\n  </PropertyGroup>\n  <ItemGroup>\n    <PackageVersion Include=\"Serilog\" Version=\"2.0.0\" />\n  </ItemGroup>\n</Project>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			project := filepath.Join(dir, "src", "App.csproj")
+			if err := os.MkdirAll(filepath.Dir(project), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(project, []byte(tt.project), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			props := filepath.Join(dir, PackagesPropsFile)
+			if tt.props != "" {
+				if err := os.WriteFile(props, []byte(tt.props), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			fix := Fix{Project: project, Package: "Serilog", Version: "3.1.0", Reason: tt.reason}
+			if _, err := fix.Apply(); err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+
+			if got, _ := os.ReadFile(project); string(got) != tt.wantProject {
+				t.Errorf("project =\n%s\nwant\n%s", got, tt.wantProject)
+			}
+			if tt.props != "" {
+				if got, _ := os.ReadFile(props); string(got) != tt.wantProps {
+					t.Errorf("props =\n%s\nwant\n%s", got, tt.wantProps)
+				}
+			}
+		})
+	}
+}
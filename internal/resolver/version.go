@@ -0,0 +1,17 @@
+package resolver
+
+import "strings"
+
+// MinVersion returns the lower bound of a version range in NuGet's printed form
+// (">= 1.0.0", "= 1.0.0", "1.0.0") or interval notation ("[1.0.0, 2.0.0)").
+func MinVersion(r string) string {
+	r = strings.TrimSpace(r)
+	r = strings.TrimLeft(r, "[(>=~^ ")
+	if lower, _, found := strings.Cut(r, ","); found {
+		r = lower
+	}
+	if lower, _, found := strings.Cut(r, "&&"); found {
+		r = lower
+	}
+	return strings.TrimRight(strings.TrimSpace(r), "])")
+}
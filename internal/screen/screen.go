@@ -0,0 +1,233 @@
+// Package screen draws full-screen views that reflow when the terminal is resized. Below
+// the minimum terminal size (platform.MinTerminalWidth x platform.MinTerminalHeight), a
+// "terminal too small" screen with the current and required dimensions is drawn instead,
+// so views never have to lay themselves out in a space they cannot fit. Views list data
+// that loads in the background with Table, whose cells show placeholders until it arrives.
+//
+// Screens write VT escape sequences; on Windows, enable them first with
+// platform.EnableVirtualTerminal.
+package screen
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// Escape sequences for drawing.
+const (
+	enterAltScreen = "\x1b[?1049h"
+	leaveAltScreen = "\x1b[?1049l"
+	hideCursor     = "\x1b[?25l"
+	showCursor     = "\x1b[?25h"
+	home           = "\x1b[H"
+	clearScreen    = "\x1b[2J"
+)
+
+// View renders a view's lines (plain text) for a terminal size. Lines longer than width
+// are cut, and lines past height are dropped.
+type View func(width, height int) []string
+
+// Screen draws a view over the whole terminal.
+type Screen struct {
+	out       io.Writer
+	view      View
+	lastDraw  time.Time
+	pending   *time.Timer   // Draws a frame held back by the frame rate; nil when none is
+	interval  time.Duration // Shortest time between frames; 0 for no limit
+	width     int
+	height    int
+	altScreen bool
+	started   bool
+	mu        sync.Mutex
+}
+
+// New returns a screen that draws view to out. With altScreen, the screen switches to the
+// terminal's alternate screen, restoring the previous contents when it stops (see
+// platform.ConsoleMode.AltScreen).
+func New(out io.Writer, view View, altScreen bool) *Screen {
+	return &Screen{out: out, view: view, altScreen: altScreen}
+}
+
+// Start takes over the terminal and draws the view at the given size.
+func (s *Screen) Start(width, height int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefix := hideCursor
+	if s.altScreen {
+		prefix = enterAltScreen + prefix
+	}
+	if _, err := io.WriteString(s.out, prefix); err != nil {
+		return err
+	}
+	s.started = true
+	return s.draw(width, height)
+}
+
+// LimitFrameRate draws at most fps frames a second (the renderFps setting). Redraws and
+// resizes that come faster are coalesced into one frame of the latest content, drawn
+// once the interval has passed, so hundreds of packages resolving at once do not make the
+// terminal flicker or keep a remote session busy. fps <= 0 draws every frame at once.
+func (s *Screen) LimitFrameRate(fps int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.interval = 0
+	if fps > 0 {
+		s.interval = time.Second / time.Duration(fps)
+	}
+}
+
+// Resize draws the view again at a new size. Call it from a platform resize watcher.
+func (s *Screen) Resize(width, height int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.started {
+		return nil
+	}
+	s.width, s.height = width, height
+	return s.frame()
+}
+
+// Redraw draws the view again at the current size, e.g., after its content changed.
+func (s *Screen) Redraw() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.started {
+		return nil
+	}
+	return s.frame()
+}
+
+// frame draws the view now, or schedules it when the last frame is too recent for the
+// frame rate. A frame already scheduled draws the content as it is by then, so further
+// requests need nothing. The caller holds s.mu.
+func (s *Screen) frame() error {
+	if s.pending != nil {
+		return nil
+	}
+	wait := s.interval - time.Since(s.lastDraw)
+	if wait <= 0 {
+		return s.draw(s.width, s.height)
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(wait, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.pending != timer {
+			return // Stopped, or drawn by Stop
+		}
+		s.pending = nil
+		_ = s.draw(s.width, s.height)
+	})
+	s.pending = timer
+	return nil
+}
+
+// Stop gives the terminal back: the cursor is shown again and, with the alternate screen,
+// the previous contents are restored.
+func (s *Screen) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.started {
+		return nil
+	}
+	s.started = false
+	if s.pending != nil {
+		// The last frame shows the latest content
+		s.pending.Stop()
+		s.pending = nil
+		if err := s.draw(s.width, s.height); err != nil {
+			return err
+		}
+	}
+
+	suffix := showCursor
+	if s.altScreen {
+		suffix += leaveAltScreen
+	} else {
+		// The last frame stays; continue below it
+		suffix = "\r\n" + suffix
+	}
+	_, err := io.WriteString(s.out, suffix)
+	return err
+}
+
+// draw writes one frame. The caller holds s.mu.
+func (s *Screen) draw(width, height int) error {
+	s.width, s.height = width, height
+	s.lastDraw = time.Now()
+	var lines []string
+	if platform.TooSmall(width, height) {
+		lines = TooSmall(width, height)
+	} else {
+		lines = s.view(width, height)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(home + clearScreen)
+	for i, line := range Fit(lines, width, height) {
+		if i > 0 {
+			sb.WriteString("\r\n")
+		}
+		sb.WriteString(line)
+	}
+	_, err := io.WriteString(s.out, sb.String())
+	return err
+}
+
+// TooSmall returns the screen shown instead of a view when the terminal is below the
+// minimum size: the current and required dimensions, centered. When even that does not
+// fit, the dimensions are shortened to a single line.
+func TooSmall(width, height int) []string {
+	current := fmt.Sprintf("%dx%d", width, height)
+	required := fmt.Sprintf("%dx%d", platform.MinTerminalWidth, platform.MinTerminalHeight)
+	message := []string{
+		"Terminal too small",
+		"",
+		"Current:  " + current,
+		"Required: " + required,
+	}
+	if height < len(message) || width < len(message[0]) {
+		message = []string{current + " < " + required}
+	}
+
+	lines := make([]string, (height-len(message))/2)
+	for _, text := range message {
+		pad := max((width-utf8.RuneCountInString(text))/2, 0)
+		lines = append(lines, strings.Repeat(" ", pad)+text)
+	}
+	return lines
+}
+
+// Fit cuts lines to width columns and height lines. Lines are not padded, since the
+// screen is cleared before each frame.
+func Fit(lines []string, width, height int) []string {
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	fitted := make([]string, len(lines))
+	for i, line := range lines {
+		fitted[i] = cut(line, width)
+	}
+	return fitted
+}
+
+// cut returns the first n runes of s.
+func cut(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+	return s
+}
@@ -0,0 +1,35 @@
+package screen
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// BenchmarkRedraw measures drawing a full frame of a large terminal
+// Target: <16ms, one frame at 60 Hz, so resizing and live updates never stutter
+func BenchmarkRedraw(b *testing.B) {
+	lines := make([]string, 500)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%4d  Microsoft.Extensions.DependencyInjection.Abstractions  8.0.%d  %s", i, i, strings.Repeat("é", 200))
+	}
+	s := New(io.Discard, func(width, height int) []string { return lines }, true)
+	if err := s.Start(300, 100); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := s.Redraw(); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	avgTime := b.Elapsed() / time.Duration(b.N)
+	b.Logf("Average frame time: %v", avgTime)
+	if avgTime > 16*time.Millisecond {
+		b.Errorf("Frame time %v exceeds 16ms target", avgTime)
+	}
+}
@@ -0,0 +1,209 @@
+package screen
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTooSmall(t *testing.T) {
+	lines := TooSmall(30, 8)
+	if len(lines) != 6 {
+		t.Fatalf("TooSmall(30, 8) = %d lines, want 6 (2 blank, 4 message): %q", len(lines), lines)
+	}
+	text := strings.Join(lines, "\n")
+	for _, want := range []string{"Terminal too small", "Current:  30x8", "Required: 40x10"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("TooSmall(30, 8) missing %q:\n%s", want, text)
+		}
+	}
+	if lines[2] != "      Terminal too small" {
+		t.Errorf("title not centered: %q", lines[2])
+	}
+
+	// Too small for the full message: one line, still within bounds
+	for _, size := range [][2]int{{12, 3}, {39, 2}, {1, 1}} {
+		lines := TooSmall(size[0], size[1])
+		fitted := Fit(lines, size[0], size[1])
+		if len(fitted) == 0 || !strings.Contains(lines[len(lines)-1], "< 40x10") {
+			t.Errorf("TooSmall(%d, %d) = %q, want the short form", size[0], size[1], lines)
+		}
+		if len(lines) > size[1] {
+			t.Errorf("TooSmall(%d, %d) = %d lines, taller than the terminal", size[0], size[1], len(lines))
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	got := Fit([]string{"abcdef", "äöü", "x", "dropped"}, 2, 3)
+	want := []string{"ab", "äö", "x"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Fit() = %q, want %q", got, want)
+	}
+}
+
+func TestScreen(t *testing.T) {
+	var out strings.Builder
+	var sizes [][2]int
+	s := New(&out, func(width, height int) []string {
+		sizes = append(sizes, [2]int{width, height})
+		return []string{"header", strings.Repeat("=", 100)}
+	}, true)
+
+	if err := s.Resize(80, 24); err != nil || out.Len() != 0 {
+		t.Fatalf("Resize() before Start wrote %q, err %v", out.String(), err)
+	}
+
+	if err := s.Start(80, 24); err != nil {
+		t.Fatal(err)
+	}
+	frame := out.String()
+	if !strings.HasPrefix(frame, enterAltScreen+hideCursor+home+clearScreen) {
+		t.Errorf("Start() frame = %q, want alt screen, hidden cursor and clear", frame)
+	}
+	if !strings.HasSuffix(frame, "header\r\n"+strings.Repeat("=", 80)) {
+		t.Errorf("Start() frame not cut to width: %q", frame)
+	}
+
+	// Shrinking below the minimum shows the too-small screen without calling the view
+	out.Reset()
+	if err := s.Resize(30, 8); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Terminal too small") || strings.Contains(out.String(), "header") {
+		t.Errorf("Resize(30, 8) = %q, want the too-small screen", out.String())
+	}
+
+	// Growing again reflows the view
+	out.Reset()
+	if err := s.Resize(50, 12); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(out.String(), strings.Repeat("=", 50)) {
+		t.Errorf("Resize(50, 12) = %q, want the view at 50 columns", out.String())
+	}
+	out.Reset()
+	if err := s.Redraw(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sizes) != 3 || sizes[2] != [2]int{50, 12} {
+		t.Errorf("view sizes = %v, want 80x24, 50x12, 50x12", sizes)
+	}
+
+	out.Reset()
+	if err := s.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != showCursor+leaveAltScreen {
+		t.Errorf("Stop() = %q, want cursor shown and alt screen left", out.String())
+	}
+	if err := s.Stop(); err != nil || out.String() != showCursor+leaveAltScreen {
+		t.Errorf("second Stop() wrote again: %q", out.String())
+	}
+}
+
+// TestTable tests drawing placeholders for cells that are still loading
+func TestTable(t *testing.T) {
+	table := Table{
+		Header: []string{"Package", "Latest", "Vulnerable", ""},
+		Rows: [][]Cell{
+			{Text("Newtonsoft.Json"), Text("13.0.3"), Pending(), Pending()},
+			{Text("Serilog"), Pending(), Unavailable(""), Text("a note longer than any placeholder")},
+		},
+		Indent: "  ",
+	}
+	want := []string{
+		"  Package          Latest  Vulnerable",
+		"  Newtonsoft.Json  13.0.3  ..........  ............",
+		"  Serilog          ......  ?           a note longer than any placeholder",
+	}
+	if got := table.Lines(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Lines() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if !table.Loading() {
+		t.Error("Loading() = false with pending cells")
+	}
+
+	table.Unicode = true
+	if got := table.Lines()[2]; !strings.Contains(got, "░░░░░░  ?") {
+		t.Errorf("Unicode placeholder = %q", got)
+	}
+	table.Rows = table.Rows[:0]
+	if table.Loading() {
+		t.Error("Loading() = true without rows")
+	}
+}
+
+// syncBuilder is a strings.Builder safe to write from the timer drawing held back frames.
+type syncBuilder struct {
+	sb strings.Builder
+	mu sync.Mutex
+}
+
+func (b *syncBuilder) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sb.Write(p)
+}
+
+func (b *syncBuilder) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sb.String()
+}
+
+// TestScreenFrameRate tests coalescing redraws that come faster than the frame rate
+func TestScreenFrameRate(t *testing.T) {
+	var out syncBuilder
+	var resolved atomic.Int32
+	s := New(&out, func(_, _ int) []string {
+		return []string{fmt.Sprintf("%d resolved", resolved.Load())}
+	}, false)
+	s.LimitFrameRate(10)
+	if err := s.Start(80, 24); err != nil {
+		t.Fatal(err)
+	}
+
+	// A burst of updates draws one more frame, with the latest content
+	for range 200 {
+		resolved.Add(1)
+		if err := s.Redraw(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if frames := strings.Count(out.String(), clearScreen); frames != 1 {
+		t.Errorf("%d frames right after the burst, want only Start's", frames)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if frames := strings.Count(out.String(), clearScreen); frames != 2 {
+		t.Errorf("%d frames after the interval, want 2", frames)
+	}
+	if !strings.HasSuffix(out.String(), "200 resolved") {
+		t.Errorf("coalesced frame = %q, want the latest content", out.String())
+	}
+
+	// Once the interval has passed, a redraw draws at once; stopping draws the frame held
+	// back after it first, so the frame left on screen is current
+	_ = s.Redraw()
+	resolved.Add(1)
+	_ = s.Resize(100, 30)
+	if frames := strings.Count(out.String(), clearScreen); frames != 3 {
+		t.Errorf("%d frames before Stop, want 3", frames)
+	}
+	if err := s.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if frames := strings.Count(out.String(), clearScreen); frames != 4 {
+		t.Errorf("%d frames after Stop, want 4", frames)
+	}
+	if !strings.HasSuffix(out.String(), "201 resolved\r\n"+showCursor) {
+		t.Errorf("last frame = %q, want the latest content", out.String())
+	}
+	time.Sleep(150 * time.Millisecond)
+	if frames := strings.Count(out.String(), clearScreen); frames != 4 {
+		t.Errorf("a frame was drawn after Stop (%d frames)", frames)
+	}
+}
@@ -0,0 +1,123 @@
+package screen
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// LoadState is whether the value of a cell is known yet. Views draw their rows at once and
+// fill in the columns that wait on the network (latest version, vulnerability status) as
+// the data arrives, rather than holding back the whole list or leaving cells empty.
+type LoadState int
+
+const (
+	Loaded  LoadState = iota // The cell shows its text
+	Loading                  // The cell shows a placeholder the width of its column
+	Failed                   // The data could not be loaded; the cell shows its text, or "?"
+)
+
+// Cell is a table cell whose value may still be loading.
+type Cell struct {
+	Text  string
+	State LoadState
+}
+
+// Text returns a loaded cell.
+func Text(s string) Cell {
+	return Cell{Text: s}
+}
+
+// Pending returns a cell whose value is loading.
+func Pending() Cell {
+	return Cell{State: Loading}
+}
+
+// Unavailable returns a cell whose value could not be loaded, with a short reason ("" to
+// show "?").
+func Unavailable(reason string) Cell {
+	return Cell{Text: reason, State: Failed}
+}
+
+// Placeholder widths: the narrowest, for a column with nothing loaded yet and a short
+// header, and the widest, so a column of long notes does not fill with bars.
+const (
+	minSkeleton = 3
+	maxSkeleton = 12
+)
+
+// Table lays out rows of cells in columns two spaces apart. Loading cells are drawn as
+// skeletons: a bar as wide as the column's widest loaded cell, or its header, so the
+// layout moves little as values arrive.
+type Table struct {
+	Header  []string
+	Rows    [][]Cell
+	Indent  string // Written before every line
+	Unicode bool   // Draw skeletons with block characters rather than dots
+}
+
+// Loading reports whether any cell is still loading.
+func (t *Table) Loading() bool {
+	for _, row := range t.Rows {
+		for _, c := range row {
+			if c.State == Loading {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Lines returns the table's lines, with trailing spaces trimmed.
+func (t *Table) Lines() []string {
+	widths := make([]int, len(t.Header))
+	for i, h := range t.Header {
+		widths[i] = utf8.RuneCountInString(h)
+	}
+	for _, row := range t.Rows {
+		for i, c := range row {
+			if c.State != Loading && i < len(widths) {
+				widths[i] = max(widths[i], utf8.RuneCountInString(c.display()))
+			}
+		}
+	}
+
+	bar := "."
+	if t.Unicode {
+		bar = "░"
+	}
+	lines := make([]string, 0, len(t.Rows)+1)
+	line := func(cells []string) {
+		var sb strings.Builder
+		sb.WriteString(t.Indent)
+		for i, text := range cells {
+			if i > 0 {
+				sb.WriteString("  ")
+			}
+			sb.WriteString(text)
+			if i < len(widths) {
+				sb.WriteString(strings.Repeat(" ", max(widths[i]-utf8.RuneCountInString(text), 0)))
+			}
+		}
+		lines = append(lines, strings.TrimRight(sb.String(), " "))
+	}
+	line(t.Header)
+	for _, row := range t.Rows {
+		cells := make([]string, len(row))
+		for i, c := range row {
+			cells[i] = c.display()
+			if c.State == Loading && i < len(widths) {
+				cells[i] = strings.Repeat(bar, min(max(widths[i], minSkeleton), maxSkeleton))
+			}
+		}
+		line(cells)
+	}
+	return lines
+}
+
+// display returns the text a loaded or failed cell shows.
+func (c Cell) display() string {
+	if c.State == Failed && c.Text == "" {
+		return "?"
+	}
+	return c.Text
+}
@@ -0,0 +1,92 @@
+package selfupdate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+const (
+	// StateFileName stores the last startup check in the data directory.
+	StateFileName = "update-check.json"
+
+	// CheckInterval is how often the startup check contacts GitHub.
+	CheckInterval = 24 * time.Hour
+)
+
+// checkState is the cached result of the last startup check.
+type checkState struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	Latest    string    `json:"latest"`
+}
+
+// DefaultStatePath returns the platform path of the startup check cache in the data
+// directory, with its temp fallback applied (see platform.DataPath).
+func DefaultStatePath() string {
+	return platform.DataPath(platform.DirCache, StateFileName)
+}
+
+// CheckForUpdate returns the latest release version and whether it is newer than
+// current. GitHub is contacted at most once per CheckInterval; in between, the answer
+// cached in statePath is reused. An empty statePath disables caching.
+func (c *Client) CheckForUpdate(ctx context.Context, statePath, current string, now time.Time) (string, bool, error) {
+	state, err := loadCheckState(statePath)
+	if err != nil || state.Latest == "" || now.Sub(state.CheckedAt) >= CheckInterval {
+		release, err := c.Latest(ctx)
+		if err != nil {
+			return "", false, err
+		}
+		state = checkState{CheckedAt: now, Latest: release.Version()}
+		if err := saveCheckState(statePath, state); err != nil {
+			return "", false, err
+		}
+	}
+
+	newer, err := Newer(current, state.Latest)
+	return state.Latest, newer, err
+}
+
+// loadCheckState reads the cached check; a missing file yields an empty state.
+func loadCheckState(path string) (checkState, error) {
+	var state checkState
+	if path == "" {
+		return state, nil
+	}
+	// #nosec G304 -- path is the update check state in the user's data directory
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return checkState{}, fmt.Errorf("invalid update check cache %s: %w", path, err)
+	}
+	return state, nil
+}
+
+// saveCheckState writes the cached check.
+func saveCheckState(path string, state checkState) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to save update check: %w", err)
+	}
+	return nil
+}
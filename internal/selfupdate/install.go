@@ -0,0 +1,128 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+)
+
+// extractBinary returns the lazynuget executable from a release archive.
+func extractBinary(archive []byte, name, goos string) ([]byte, error) {
+	binaryName := "lazynuget"
+	if goos == "windows" {
+		binaryName += ".exe"
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if filepath.Ext(name) == ".zip" {
+		data, err = extractFromZip(archive, binaryName)
+	} else {
+		data, err = extractFromTarGz(archive, binaryName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract %s from %s: %w", binaryName, name, err)
+	}
+	return data, nil
+}
+
+// extractFromTarGz reads the first regular file named binaryName from a .tar.gz archive.
+func extractFromTarGz(archive []byte, binaryName string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("executable not found in archive")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeReg && path.Base(header.Name) == binaryName {
+			return io.ReadAll(io.LimitReader(tr, maxDownloadSize))
+		}
+	}
+}
+
+// extractFromZip reads the first file named binaryName from a .zip archive.
+func extractFromZip(archive []byte, binaryName string) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() || path.Base(file.Name) != binaryName {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(io.LimitReader(rc, maxDownloadSize))
+	}
+	return nil, errors.New("executable not found in archive")
+}
+
+// Replace atomically swaps the executable at exePath for binary, keeping its permissions.
+// The new binary is written next to the old one and renamed over it, so an interrupted
+// update never leaves a partial executable. On Windows, where a running executable cannot
+// be overwritten, the old binary is first moved aside to exePath + ".old".
+func Replace(exePath string, binary []byte) error {
+	info, err := os.Stat(exePath)
+	if err != nil {
+		return fmt.Errorf("cannot update %s: %w", exePath, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(exePath), ".lazynuget-update-*")
+	if err != nil {
+		return fmt.Errorf("cannot write to %s (install with the same permissions, e.g. sudo, or use your package manager): %w",
+			filepath.Dir(exePath), err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // No-op after a successful rename
+
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write new binary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write new binary: %w", err)
+	}
+	if err := os.Chmod(tmpPath, info.Mode().Perm()|0o100); err != nil {
+		return fmt.Errorf("failed to make new binary executable: %w", err)
+	}
+
+	if runtime.GOOS == "windows" {
+		oldPath := exePath + ".old"
+		_ = os.Remove(oldPath)
+		if err := os.Rename(exePath, oldPath); err != nil {
+			return fmt.Errorf("failed to move current binary aside: %w", err)
+		}
+		if err := os.Rename(tmpPath, exePath); err != nil {
+			_ = os.Rename(oldPath, exePath)
+			return fmt.Errorf("failed to install new binary: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.Rename(tmpPath, exePath); err != nil {
+		return fmt.Errorf("failed to install new binary: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+// Package selfupdate checks GitHub releases for newer LazyNuGet versions and replaces the
+// running binary with a verified release asset.
+//
+// Each release publishes one archive per platform plus signed checksums:
+//
+//	lazynuget_<version>_<os>_<arch>.tar.gz   (.zip on Windows)
+//	checksums.txt                            sha256sum format, one line per archive
+//	checksums.txt.sig                        base64 ed25519 signature of checksums.txt
+package selfupdate
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/metrics"
+)
+
+const (
+	// Repository is the GitHub repository that publishes releases.
+	Repository = "willibrandon/lazynuget"
+
+	// DefaultAPIURL is the GitHub REST API base URL.
+	DefaultAPIURL = "https://api.github.com"
+
+	// ChecksumsAsset lists the SHA-256 of every archive in a release.
+	ChecksumsAsset = "checksums.txt"
+
+	// SignatureAsset is the ed25519 signature of ChecksumsAsset.
+	SignatureAsset = "checksums.txt.sig"
+
+	// maxDownloadSize bounds every download (archives are a few MB).
+	maxDownloadSize = 200 << 20
+)
+
+// publicKey is the base64 ed25519 key that signs release checksums. Release builds inject it:
+//
+//	-ldflags "-X github.com/willibrandon/lazynuget/internal/selfupdate.publicKey=..."
+var publicKey string
+
+// ErrUnsigned is returned when a signing key is configured but the release has no signature.
+var ErrUnsigned = errors.New("release checksums are not signed")
+
+// ErrNoSigningKey is returned by Download when this binary has no signing key to verify
+// releases with and the client is not Insecure.
+var ErrNoSigningKey = errors.New("this build has no release signing key, so the download cannot be verified")
+
+// Release is a published GitHub release.
+type Release struct {
+	TagName string  `json:"tag_name"`
+	HTMLURL string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+}
+
+// Version returns the release version without the "v" prefix.
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// Asset returns the asset with the given name.
+func (r *Release) Asset(name string) (Asset, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// AssetName returns the archive name for a version and platform.
+func AssetName(version, goos, goarch string) string {
+	ext := ".tar.gz"
+	if goos == "windows" {
+		ext = ".zip"
+	}
+	return fmt.Sprintf("lazynuget_%s_%s_%s%s", strings.TrimPrefix(version, "v"), goos, goarch, ext)
+}
+
+// Client talks to the GitHub releases API and downloads release assets.
+type Client struct {
+	HTTPClient *http.Client
+	APIURL     string
+	PublicKey  ed25519.PublicKey // Nil in development builds, which cannot verify signatures
+	Insecure   bool              // Without PublicKey, install on the checksum alone (update-self --insecure)
+}
+
+// NewClient returns a client for the public GitHub API using the signing key built into
+// this binary.
+func NewClient() (*Client, error) {
+	key, err := embeddedPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		HTTPClient: &http.Client{Transport: metrics.Transport(nil)},
+		APIURL:     DefaultAPIURL,
+		PublicKey:  key,
+	}, nil
+}
+
+// embeddedPublicKey decodes the signing key injected at build time, if any.
+func embeddedPublicKey() (ed25519.PublicKey, error) {
+	if publicKey == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid release signing key built into this binary")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// Latest returns the most recent published (non-draft, non-prerelease) release.
+func (c *Client) Latest(ctx context.Context) (*Release, error) {
+	url := strings.TrimSuffix(c.APIURL, "/") + "/repos/" + Repository + "/releases/latest"
+	data, err := c.get(ctx, url, "application/vnd.github+json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for releases: %w", err)
+	}
+
+	var release Release
+	if err := json.Unmarshal(data, &release); err != nil {
+		return nil, fmt.Errorf("invalid release data: %w", err)
+	}
+	if release.TagName == "" {
+		return nil, fmt.Errorf("invalid release data: missing tag name")
+	}
+	return &release, nil
+}
+
+// Download fetches the archive for goos/goarch, verifies it against the release's signed
+// checksums, and returns the lazynuget executable it contains. Without a PublicKey it
+// returns ErrNoSigningKey, unless the client is Insecure.
+func (c *Client) Download(ctx context.Context, release *Release, goos, goarch string) ([]byte, error) {
+	if c.PublicKey == nil && !c.Insecure {
+		return nil, ErrNoSigningKey
+	}
+	name := AssetName(release.Version(), goos, goarch)
+	archiveAsset, ok := release.Asset(name)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no build for %s/%s", release.TagName, goos, goarch)
+	}
+	checksumsAsset, ok := release.Asset(ChecksumsAsset)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s", release.TagName, ChecksumsAsset)
+	}
+
+	checksums, err := c.get(ctx, checksumsAsset.URL, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", ChecksumsAsset, err)
+	}
+
+	if c.PublicKey != nil {
+		sigAsset, ok := release.Asset(SignatureAsset)
+		if !ok {
+			return nil, fmt.Errorf("release %s: %w", release.TagName, ErrUnsigned)
+		}
+		sig, err := c.get(ctx, sigAsset.URL, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", SignatureAsset, err)
+		}
+		if err := verifySignature(c.PublicKey, checksums, sig); err != nil {
+			return nil, err
+		}
+	}
+
+	want, err := findChecksum(checksums, name)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := c.get(ctx, archiveAsset.URL, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	sum := sha256.Sum256(archive)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return nil, fmt.Errorf("checksum mismatch for %s: got %s, want %s", name, got, want)
+	}
+
+	return extractBinary(archive, name, goos)
+}
+
+// get performs a GET request and returns the body, failing on non-200 responses.
+func (c *Client) get(ctx context.Context, url, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	req.Header.Set("User-Agent", "lazynuget-update")
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDownloadSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", url, maxDownloadSize)
+	}
+	return data, nil
+}
+
+// verifySignature checks a base64 ed25519 signature over data.
+func verifySignature(key ed25519.PublicKey, data, sig []byte) error {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", SignatureAsset, err)
+	}
+	if !ed25519.Verify(key, data, decoded) {
+		return fmt.Errorf("signature verification failed for %s", ChecksumsAsset)
+	}
+	return nil
+}
+
+// findChecksum returns the hex SHA-256 listed for name in sha256sum-format checksums.
+func findChecksum(checksums []byte, name string) (string, error) {
+	for line := range strings.Lines(string(checksums)) {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s does not list %s", ChecksumsAsset, name)
+}
@@ -0,0 +1,263 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestNewer tests version comparison, including prereleases and development builds
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		current string
+		latest  string
+		want    bool
+		wantErr error
+	}{
+		{current: "1.0.0", latest: "v1.0.1", want: true},
+		{current: "v1.2.0", latest: "v1.10.0", want: true},
+		{current: "1.0.0", latest: "1.0.0", want: false},
+		{current: "2.0.0", latest: "1.9.9", want: false},
+		{current: "1.0.0-rc.1", latest: "1.0.0", want: true},
+		{current: "1.0.0-rc.2", latest: "1.0.0-rc.10", want: true},
+		{current: "1.0.0", latest: "1.0.0-rc.1", want: false},
+		{current: "dev", latest: "1.0.0", wantErr: ErrDevelopmentBuild},
+		{current: "v0.1.0-5-gabc1234", latest: "0.2.0", wantErr: ErrDevelopmentBuild},
+		{current: "v0.1.0-dirty", latest: "0.2.0", wantErr: ErrDevelopmentBuild},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current+"->"+tt.latest, func(t *testing.T) {
+			got, err := Newer(tt.current, tt.latest)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Newer() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Newer(%q, %q) = %v, want %v", tt.current, tt.latest, got, tt.want)
+			}
+		})
+	}
+}
+
+// testRelease serves a fake GitHub release with a tar.gz archive for linux/amd64.
+type testRelease struct {
+	server    *httptest.Server
+	files     map[string][]byte
+	apiCalls  atomic.Int32
+	binary    []byte
+	publicKey ed25519.PublicKey
+}
+
+func newTestRelease(t *testing.T, version string) *testRelease {
+	t.Helper()
+
+	r := &testRelease{files: make(map[string][]byte), binary: []byte("#!/bin/sh\necho new lazynuget\n")}
+	archiveName := AssetName(version, "linux", "amd64")
+	r.files[archiveName] = tarGz(t, map[string][]byte{"README.md": []byte("docs"), "lazynuget": r.binary})
+
+	sum := sha256.Sum256(r.files[archiveName])
+	r.files[ChecksumsAsset] = fmt.Appendf(nil, "%s  %s\n", hex.EncodeToString(sum[:]), archiveName)
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.publicKey = pub
+	r.files[SignatureAsset] = []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, r.files[ChecksumsAsset])))
+
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/repos/"+Repository+"/releases/latest" {
+			r.apiCalls.Add(1)
+			release := Release{TagName: "v" + version}
+			for name := range r.files {
+				release.Assets = append(release.Assets, Asset{Name: name, URL: r.server.URL + "/download/" + name})
+			}
+			_ = json.NewEncoder(w).Encode(release)
+			return
+		}
+		data, ok := r.files[strings.TrimPrefix(req.URL.Path, "/download/")]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *testRelease) client(key ed25519.PublicKey) *Client {
+	return &Client{HTTPClient: r.server.Client(), APIURL: r.server.URL, PublicKey: key}
+}
+
+// tarGz builds a .tar.gz archive from file names and contents.
+func tarGz(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, data := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestDownload tests checksum and signature verification of release archives
+func TestDownload(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(r *testRelease)
+		useKey   bool
+		insecure bool
+		wantErr  string
+	}{
+		{name: "signed release", useKey: true},
+		{name: "refused without key", wantErr: ErrNoSigningKey.Error()},
+		{name: "checksum only without key when insecure", insecure: true},
+		{
+			name: "tampered archive",
+			modify: func(r *testRelease) {
+				r.files[AssetName("1.2.0", "linux", "amd64")] = tarGz(t, map[string][]byte{"lazynuget": []byte("evil")})
+			},
+			useKey:  true,
+			wantErr: "checksum mismatch",
+		},
+		{
+			name: "tampered archive when insecure",
+			modify: func(r *testRelease) {
+				r.files[AssetName("1.2.0", "linux", "amd64")] = tarGz(t, map[string][]byte{"lazynuget": []byte("evil")})
+			},
+			insecure: true,
+			wantErr:  "checksum mismatch",
+		},
+		{
+			name:    "tampered checksums",
+			modify:  func(r *testRelease) { r.files[ChecksumsAsset] = append(r.files[ChecksumsAsset], '\n') },
+			useKey:  true,
+			wantErr: "signature verification failed",
+		},
+		{
+			name:    "missing signature",
+			modify:  func(r *testRelease) { delete(r.files, SignatureAsset) },
+			useKey:  true,
+			wantErr: ErrUnsigned.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRelease(t, "1.2.0")
+			if tt.modify != nil {
+				tt.modify(r)
+			}
+			var key ed25519.PublicKey
+			if tt.useKey {
+				key = r.publicKey
+			}
+			client := r.client(key)
+			client.Insecure = tt.insecure
+
+			release, err := client.Latest(context.Background())
+			if err != nil {
+				t.Fatalf("Latest() error = %v", err)
+			}
+			if release.Version() != "1.2.0" {
+				t.Errorf("Version() = %q, want 1.2.0", release.Version())
+			}
+
+			binary, err := client.Download(context.Background(), release, "linux", "amd64")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Download() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Download() error = %v", err)
+			}
+			if !bytes.Equal(binary, r.binary) {
+				t.Errorf("Download() = %q, want %q", binary, r.binary)
+			}
+
+			if _, err := client.Download(context.Background(), release, "plan9", "386"); err == nil {
+				t.Error("Download() should fail for a platform without a build")
+			}
+		})
+	}
+}
+
+// TestReplace tests that the binary is swapped in place with its permissions
+func TestReplace(t *testing.T) {
+	exePath := filepath.Join(t.TempDir(), "lazynuget")
+	if err := os.WriteFile(exePath, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Replace(exePath, []byte("new")); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+
+	data, err := os.ReadFile(exePath)
+	if err != nil || string(data) != "new" {
+		t.Errorf("binary = %q, %v; want new", data, err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(exePath))
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries after Replace(), want only the binary", len(entries))
+	}
+}
+
+// TestCheckForUpdate tests the startup check and its daily cache
+func TestCheckForUpdate(t *testing.T) {
+	r := newTestRelease(t, "1.2.0")
+	client := r.client(nil)
+	statePath := filepath.Join(t.TempDir(), StateFileName)
+	now := time.Now()
+
+	latest, newer, err := client.CheckForUpdate(context.Background(), statePath, "1.1.0", now)
+	if err != nil || latest != "1.2.0" || !newer {
+		t.Fatalf("CheckForUpdate() = %q, %v, %v; want 1.2.0, true, nil", latest, newer, err)
+	}
+
+	// Within the interval the cached answer is used
+	if _, newer, _ := client.CheckForUpdate(context.Background(), statePath, "1.2.0", now.Add(time.Hour)); newer {
+		t.Error("CheckForUpdate() reported 1.2.0 as newer than itself")
+	}
+	if got := r.apiCalls.Load(); got != 1 {
+		t.Errorf("API called %d times within the check interval, want 1", got)
+	}
+
+	if _, _, err := client.CheckForUpdate(context.Background(), statePath, "1.1.0", now.Add(CheckInterval)); err != nil {
+		t.Fatalf("CheckForUpdate() after interval error = %v", err)
+	}
+	if got := r.apiCalls.Load(); got != 2 {
+		t.Errorf("API called %d times after the check interval, want 2", got)
+	}
+}
@@ -0,0 +1,116 @@
+package selfupdate
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrDevelopmentBuild is returned when the running version is not a release version
+// (e.g., "dev" or a `git describe` string), so it cannot be compared with releases.
+var ErrDevelopmentBuild = errors.New("not a release build")
+
+// gitDescribeSuffix matches the "-<commits>-g<sha>" suffix of `git describe` versions.
+var gitDescribeSuffix = regexp.MustCompile(`-\d+-g[0-9a-f]+`)
+
+// semver is a parsed MAJOR.MINOR.PATCH[-PRERELEASE] version.
+type semver struct {
+	prerelease []string
+	core       [3]int
+}
+
+// Newer reports whether latest is a newer version than current. It returns
+// ErrDevelopmentBuild if current is not a release version.
+func Newer(current, latest string) (bool, error) {
+	cur, err := parseVersion(current)
+	if err != nil || gitDescribeSuffix.MatchString(current) || strings.HasSuffix(current, "-dirty") {
+		return false, ErrDevelopmentBuild
+	}
+	lat, err := parseVersion(latest)
+	if err != nil {
+		return false, err
+	}
+	return compareVersions(lat, cur) > 0, nil
+}
+
+// parseVersion parses "v1.2.3", "1.2.3", or "1.2.3-rc.1" (build metadata is ignored).
+func parseVersion(v string) (semver, error) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	v, _, _ = strings.Cut(v, "+")
+	core, pre, hasPre := strings.Cut(v, "-")
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return semver{}, errors.New("invalid version " + strconv.Quote(v))
+	}
+
+	var s semver
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return semver{}, errors.New("invalid version " + strconv.Quote(v))
+		}
+		s.core[i] = n
+	}
+	if hasPre {
+		s.prerelease = strings.Split(pre, ".")
+	}
+	return s, nil
+}
+
+// compareVersions returns -1, 0, or 1 following semver precedence: a prerelease sorts
+// before its release, and prerelease identifiers compare numerically when both are numbers.
+func compareVersions(a, b semver) int {
+	for i := range a.core {
+		if a.core[i] != b.core[i] {
+			if a.core[i] < b.core[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case len(a.prerelease) == 0 && len(b.prerelease) == 0:
+		return 0
+	case len(a.prerelease) == 0:
+		return 1
+	case len(b.prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(a.prerelease) && i < len(b.prerelease); i++ {
+		if c := compareIdentifiers(a.prerelease[i], b.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(a.prerelease) < len(b.prerelease):
+		return -1
+	case len(a.prerelease) > len(b.prerelease):
+		return 1
+	}
+	return 0
+}
+
+// compareIdentifiers compares prerelease identifiers; numeric ones sort before others.
+func compareIdentifiers(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		switch {
+		case an < bn:
+			return -1
+		case an > bn:
+			return 1
+		}
+		return 0
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,190 @@
+package snapshot
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+)
+
+// DiffKind is the output kind of diff documents.
+const DiffKind = "diff"
+
+// Status is how a file or package differs between two snapshots.
+type Status string
+
+const (
+	// StatusAdded means the file or package only exists in the newer snapshot.
+	StatusAdded Status = "added"
+	// StatusRemoved means the file or package only exists in the older snapshot.
+	StatusRemoved Status = "removed"
+	// StatusChanged means the file's packages or the package's version changed.
+	StatusChanged Status = "changed"
+)
+
+// Severity is the most significant part of a version that changed, following semver.
+type Severity string
+
+const (
+	SeverityMajor      Severity = "major"
+	SeverityMinor      Severity = "minor"
+	SeverityPatch      Severity = "patch"      // Patch or revision
+	SeverityPrerelease Severity = "prerelease" // Only the prerelease label
+	SeverityRange      Severity = "range"      // Only the range changed (e.g., 1.0 to [1.0,2.0)), or it has no lower bound
+)
+
+// Diff is the difference between two snapshots.
+type Diff struct {
+	From  string     `json:"from"` // Source of the older snapshot; empty for the working tree
+	To    string     `json:"to"`
+	Files []FileDiff `json:"files"`
+}
+
+// FileDiff is the difference in one file. Files whose versions are unchanged are omitted.
+type FileDiff struct {
+	Path     string        `json:"path"`
+	Status   Status        `json:"status"`
+	Packages []PackageDiff `json:"packages"`
+}
+
+// PackageDiff is the difference in one package's version.
+type PackageDiff struct {
+	ID        string   `json:"id"`
+	Status    Status   `json:"status"`
+	From      string   `json:"from,omitempty"`
+	To        string   `json:"to,omitempty"`
+	Severity  Severity `json:"severity,omitempty"`  // Changed packages only
+	Downgrade bool     `json:"downgrade,omitempty"` // The version went down
+}
+
+// Compare returns the packages added, removed, and changed from one snapshot to another,
+// per file, with files and packages sorted.
+func Compare(from, to *Snapshot) *Diff {
+	d := &Diff{From: from.Source, To: to.Source, Files: []FileDiff{}}
+
+	oldFiles, newFiles := filesByPath(from), filesByPath(to)
+	var paths []string
+	for p := range oldFiles {
+		paths = append(paths, p)
+	}
+	for p := range newFiles {
+		if _, ok := oldFiles[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	slices.Sort(paths)
+
+	for _, p := range paths {
+		oldFile, inOld := oldFiles[p]
+		newFile, inNew := newFiles[p]
+		file := FileDiff{Path: p, Status: StatusChanged}
+		switch {
+		case !inOld:
+			file.Status = StatusAdded
+		case !inNew:
+			file.Status = StatusRemoved
+		}
+		file.Packages = comparePackages(oldFile.Packages, newFile.Packages)
+		if len(file.Packages) > 0 {
+			d.Files = append(d.Files, file)
+		}
+	}
+	return d
+}
+
+// filesByPath indexes a snapshot's files.
+func filesByPath(s *Snapshot) map[string]File {
+	files := make(map[string]File, len(s.Files))
+	for _, f := range s.Files {
+		files[f.Path] = f
+	}
+	return files
+}
+
+// comparePackages returns the differences between two package lists, sorted by ID.
+func comparePackages(from, to []Package) []PackageDiff {
+	oldVersions := make(map[string]Package, len(from))
+	for _, p := range from {
+		oldVersions[strings.ToLower(p.ID)] = p
+	}
+
+	var diffs []PackageDiff
+	seen := make(map[string]bool, len(to))
+	for _, p := range to {
+		key := strings.ToLower(p.ID)
+		seen[key] = true
+		old, ok := oldVersions[key]
+		switch {
+		case !ok:
+			diffs = append(diffs, PackageDiff{ID: p.ID, Status: StatusAdded, To: p.Version})
+		case old.Version != p.Version:
+			diffs = append(diffs, PackageDiff{
+				ID:        p.ID,
+				Status:    StatusChanged,
+				From:      old.Version,
+				To:        p.Version,
+				Severity:  VersionSeverity(old.Version, p.Version),
+				Downgrade: nuget.CompareVersions(lowerBound(old.Version), lowerBound(p.Version)) > 0,
+			})
+		}
+	}
+	for _, p := range from {
+		if !seen[strings.ToLower(p.ID)] {
+			diffs = append(diffs, PackageDiff{ID: p.ID, Status: StatusRemoved, From: p.Version})
+		}
+	}
+
+	slices.SortFunc(diffs, func(a, b PackageDiff) int {
+		return strings.Compare(strings.ToLower(a.ID), strings.ToLower(b.ID))
+	})
+	return diffs
+}
+
+// VersionSeverity returns the most significant part that differs between two versions.
+// Ranges and floating versions are compared by their lower bounds.
+func VersionSeverity(from, to string) Severity {
+	a, b := lowerBound(from), lowerBound(to)
+	if a == "" || b == "" {
+		return SeverityRange
+	}
+
+	aCore, aPre, _ := strings.Cut(a, "-")
+	bCore, bPre, _ := strings.Cut(b, "-")
+	aParts, bParts := strings.Split(aCore, "."), strings.Split(bCore, ".")
+	for i := 0; i < max(len(aParts), len(bParts)); i++ {
+		if nuget.CompareVersions(part(aParts, i), part(bParts, i)) == 0 {
+			continue
+		}
+		switch i {
+		case 0:
+			return SeverityMajor
+		case 1:
+			return SeverityMinor
+		default:
+			return SeverityPatch
+		}
+	}
+	if !strings.EqualFold(aPre, bPre) {
+		return SeverityPrerelease
+	}
+	return SeverityRange
+}
+
+// lowerBound returns a version range's minimum version, or "" when it has none.
+func lowerBound(v string) string {
+	r, err := nuget.ParseVersionRange(v)
+	if err != nil {
+		return ""
+	}
+	// Strip build metadata, which never affects precedence
+	min, _, _ := strings.Cut(r.Min, "+")
+	return min
+}
+
+// part returns the i-th numeric part of a version, or "0" when it has fewer parts.
+func part(parts []string, i int) string {
+	if i < len(parts) {
+		return parts[i]
+	}
+	return "0"
+}
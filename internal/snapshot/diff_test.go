@@ -0,0 +1,79 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestCompare tests added, removed, and changed packages and files
+func TestCompare(t *testing.T) {
+	from := &Snapshot{Source: "HEAD~5", Files: []File{
+		{Path: "Directory.Packages.props", Packages: []Package{{ID: "Polly", Version: "7.2.4"}}},
+		{Path: "src/App/App.csproj", Packages: []Package{
+			{ID: "Serilog", Version: "3.1.0"},
+			{ID: "Dapper", Version: "2.1.28"},
+			{ID: "xunit", Version: "2.6.0"},
+		}},
+		{Path: "src/Old/Old.csproj", Packages: []Package{{ID: "Moq", Version: "4.20.0"}}},
+	}}
+	to := &Snapshot{Files: []File{
+		{Path: "Directory.Packages.props", Packages: []Package{{ID: "Polly", Version: "8.3.1"}}},
+		{Path: "src/App/App.csproj", Packages: []Package{
+			{ID: "serilog", Version: "3.1.0"},
+			{ID: "Dapper", Version: "2.1.24"},
+			{ID: "AutoMapper", Version: "13.0.1"},
+		}},
+		{Path: "src/New/New.csproj", Packages: []Package{{ID: "Moq", Version: "4.20.70"}}},
+	}}
+
+	want := &Diff{From: "HEAD~5", Files: []FileDiff{
+		{Path: "Directory.Packages.props", Status: StatusChanged, Packages: []PackageDiff{
+			{ID: "Polly", Status: StatusChanged, From: "7.2.4", To: "8.3.1", Severity: SeverityMajor},
+		}},
+		{Path: "src/App/App.csproj", Status: StatusChanged, Packages: []PackageDiff{
+			{ID: "AutoMapper", Status: StatusAdded, To: "13.0.1"},
+			{ID: "Dapper", Status: StatusChanged, From: "2.1.28", To: "2.1.24", Severity: SeverityPatch, Downgrade: true},
+			{ID: "xunit", Status: StatusRemoved, From: "2.6.0"},
+		}},
+		{Path: "src/New/New.csproj", Status: StatusAdded, Packages: []PackageDiff{
+			{ID: "Moq", Status: StatusAdded, To: "4.20.70"},
+		}},
+		{Path: "src/Old/Old.csproj", Status: StatusRemoved, Packages: []PackageDiff{
+			{ID: "Moq", Status: StatusRemoved, From: "4.20.0"},
+		}},
+	}}
+	if got := Compare(from, to); !reflect.DeepEqual(got, want) {
+		t.Errorf("Compare() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if got := Compare(to, to); len(got.Files) != 0 {
+		t.Errorf("Compare() of identical snapshots = %+v, want no files", got.Files)
+	}
+}
+
+// TestVersionSeverity tests classifying version changes
+func TestVersionSeverity(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     Severity
+	}{
+		{"7.2.4", "8.0.0", SeverityMajor},
+		{"8.0.0", "7.2.4", SeverityMajor},
+		{"1.2.0", "1.3.0", SeverityMinor},
+		{"1.2", "1.3.0", SeverityMinor},
+		{"1.2.3", "1.2.4", SeverityPatch},
+		{"1.2.3.0", "1.2.3.1", SeverityPatch},
+		{"2.0.0-beta.1", "2.0.0-beta.2", SeverityPrerelease},
+		{"2.0.0-rc.1", "2.0.0", SeverityPrerelease},
+		{"1.0.0+abc", "1.0.0+def", SeverityRange},
+		{"6.0.*", "8.0.*", SeverityMajor},
+		{"[1.0,2.0)", "[1.5,2.0)", SeverityMinor},
+		{"1.0.0", "[1.0.0,2.0.0)", SeverityRange},
+		{"(,2.0]", "2.0", SeverityRange},
+	}
+	for _, tt := range tests {
+		if got := VersionSeverity(tt.from, tt.to); got != tt.want {
+			t.Errorf("VersionSeverity(%q, %q) = %q, want %q", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/project"
+)
+
+// CreateFromGit captures the versions committed at a git revision (e.g., HEAD~5 or a
+// branch name) of the repository at root, without touching the working tree.
+func CreateFromGit(root, rev string) (*Snapshot, error) {
+	commit, err := git(root, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("unknown git revision %q", rev)
+	}
+	commitID := strings.TrimSpace(string(commit))
+
+	out, err := git(root, "ls-tree", "-r", "-z", "--full-tree", "--name-only", commitID)
+	if err != nil {
+		return nil, err
+	}
+	names := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+
+	s, err := capture(project.Filter(names, isVersionFile), func(p string) ([]byte, error) {
+		return git(root, "cat-file", "blob", commitID+":"+p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.Source = rev
+	return s, nil
+}
+
+// git runs a git command in dir and returns its output.
+func git(dir string, args ...string) ([]byte, error) {
+	// #nosec G204 -- args are fixed subcommands and a revision passed as a single argument
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			return nil, fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
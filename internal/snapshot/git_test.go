@@ -0,0 +1,57 @@
+package snapshot
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestCreateFromGit tests capturing versions from committed files only
+func TestCreateFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	root := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", root, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(path, content string) {
+		t.Helper()
+		full := filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q")
+	write("src/App/App.csproj", `<Project><ItemGroup><PackageReference Include="Serilog" Version="3.1.0" /></ItemGroup></Project>`)
+	write("packages/Vendored/Vendored.csproj", `<Project><ItemGroup><PackageReference Include="Old" Version="1.0.0" /></ItemGroup></Project>`)
+	run("add", "-A")
+	run("commit", "-q", "-m", "first")
+	write("src/App/App.csproj", `<Project><ItemGroup><PackageReference Include="Serilog" Version="4.0.0" /></ItemGroup></Project>`)
+	run("commit", "-q", "-am", "second")
+	write("src/App/App.csproj", `<Project><ItemGroup><PackageReference Include="Serilog" Version="5.0.0" /></ItemGroup></Project>`)
+
+	for rev, want := range map[string]string{"HEAD~1": "3.1.0", "HEAD": "4.0.0"} {
+		s, err := CreateFromGit(root, rev)
+		if err != nil {
+			t.Fatalf("CreateFromGit(%s) error = %v", rev, err)
+		}
+		if s.Source != rev || len(s.Files) != 1 || s.Files[0].Path != "src/App/App.csproj" || s.Files[0].Packages[0].Version != want {
+			t.Errorf("CreateFromGit(%s) = %+v, want Serilog %s in src/App/App.csproj", rev, s, want)
+		}
+	}
+
+	if _, err := CreateFromGit(root, "no-such-branch"); err == nil {
+		t.Error("CreateFromGit() of an unknown revision should fail")
+	}
+}
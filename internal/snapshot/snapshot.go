@@ -0,0 +1,208 @@
+// Package snapshot records the direct package versions of every project in a repository
+// and re-applies them later, e.g., to bisect a regression introduced by a bulk update.
+//
+// A snapshot lists, per file, the versions written in it: PackageReference versions in
+// project files and PackageVersion versions in Directory.Packages.props under central
+// package management. Snapshot files are versioned JSON documents (see package output).
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/output"
+	"github.com/willibrandon/lazynuget/internal/project"
+)
+
+// Kind is the output kind of snapshot documents.
+const Kind = "snapshot"
+
+// Snapshot is the direct package versions of a repository at one point in time.
+type Snapshot struct {
+	Created time.Time `json:"created"`
+	Source  string    `json:"source,omitempty"` // What was captured (e.g., a git ref), when not the working tree
+	Files   []File    `json:"files"`
+}
+
+// File is a project file or Directory.Packages.props and the versions written in it.
+type File struct {
+	Path     string    `json:"path"` // Slash-separated, relative to the repository root
+	Packages []Package `json:"packages"`
+}
+
+// Package is one versioned package item.
+type Package struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+}
+
+// Central reports whether the file is a Directory.Packages.props, whose items are
+// PackageVersion rather than PackageReference.
+func (f File) Central() bool {
+	return strings.EqualFold(path.Base(f.Path), project.PackagesPropsFile)
+}
+
+// itemKind returns the item type that holds the file's versions.
+func (f File) itemKind() string {
+	if f.Central() {
+		return "PackageVersion"
+	}
+	return "PackageReference"
+}
+
+// Create captures the versions in every project file and Directory.Packages.props in fsys.
+// References without a version (versioned centrally) are captured from the props file.
+// When a package is listed more than once in a file (e.g., under different conditions),
+// only the first item is captured, because that is the one Apply edits.
+func Create(fsys fs.FS) (*Snapshot, error) {
+	paths, err := project.Find(fsys, isVersionFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan for projects: %w", err)
+	}
+	return capture(paths, func(p string) ([]byte, error) { return fs.ReadFile(fsys, p) })
+}
+
+// isVersionFile reports whether a file name is one whose versions snapshots capture.
+func isVersionFile(name string) bool {
+	return project.IsProjectFile(name) || strings.EqualFold(name, project.PackagesPropsFile)
+}
+
+// capture reads and parses the files at paths.
+func capture(paths []string, read func(path string) ([]byte, error)) (*Snapshot, error) {
+	s := &Snapshot{Created: time.Now().UTC()}
+	for _, p := range paths {
+		data, err := read(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", p, err)
+		}
+		parsed, err := project.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", p, err)
+		}
+
+		file := File{Path: p}
+		items := parsed.PackageReferences
+		if file.Central() {
+			items = parsed.PackageVersions
+		}
+		seen := make(map[string]bool)
+		for _, item := range items {
+			key := strings.ToLower(item.ID)
+			if item.Version == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			file.Packages = append(file.Packages, Package{ID: item.ID, Version: item.Version})
+		}
+		if len(file.Packages) > 0 {
+			s.Files = append(s.Files, file)
+		}
+	}
+	return s, nil
+}
+
+// Write writes the snapshot as a versioned JSON document at a schema version.
+func (s *Snapshot) Write(w io.Writer, version int) error {
+	writer, err := output.NewWriter(w, version)
+	if err != nil {
+		return err
+	}
+	return writer.Write(Kind, s)
+}
+
+// Read reads a snapshot document written by Write.
+func Read(r io.Reader) (*Snapshot, error) {
+	var envelope struct {
+		Data          json.RawMessage `json:"data"`
+		Kind          string          `json:"kind"`
+		SchemaVersion int             `json:"schemaVersion"`
+	}
+	if err := json.NewDecoder(r).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("not a snapshot file: %w", err)
+	}
+	if envelope.Kind != Kind {
+		return nil, fmt.Errorf("not a snapshot file (kind %q)", envelope.Kind)
+	}
+	if envelope.SchemaVersion < output.MinSchemaVersion || envelope.SchemaVersion > output.CurrentSchemaVersion {
+		return nil, fmt.Errorf("unsupported snapshot schema version %d: upgrade lazynuget to read it", envelope.SchemaVersion)
+	}
+
+	var s Snapshot
+	if err := json.Unmarshal(envelope.Data, &s); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	return &s, nil
+}
+
+// ReadFile reads a snapshot file.
+func ReadFile(path string) (*Snapshot, error) {
+	// #nosec G304 -- path is a snapshot file chosen by the user
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Change is one version Apply set, or could not set.
+type Change struct {
+	File    string // Slash-separated, relative to the repository root
+	Package string // Empty when the whole file is missing
+	From    string
+	To      string
+	Missing bool // The file, or the package's version in it, no longer exists
+}
+
+// errDryRun stops EditFile from writing during a dry run.
+var errDryRun = errors.New("dry run")
+
+// Apply writes the snapshot's versions back to the files under root, editing only the
+// version text. Packages added since the snapshot are left alone; packages and files that
+// were removed, and references that no longer carry a version, are reported as missing.
+// With dryRun, nothing is written.
+func (s *Snapshot) Apply(root string, dryRun bool) ([]Change, error) {
+	var changes []Change
+	for _, file := range s.Files {
+		full := filepath.Join(root, filepath.FromSlash(file.Path))
+		if _, err := os.Stat(full); errors.Is(err, fs.ErrNotExist) {
+			changes = append(changes, Change{File: file.Path, Missing: true})
+			continue
+		}
+
+		err := project.EditFile(full, func(e *project.Editor) error {
+			for _, pkg := range file.Packages {
+				current, ok := e.ItemMetadata(file.itemKind(), pkg.ID, "Version")
+				switch {
+				case !ok:
+					// Removed, or now versioned elsewhere (e.g., moved to central package management)
+					changes = append(changes, Change{File: file.Path, Package: pkg.ID, To: pkg.Version, Missing: true})
+				case current == pkg.Version:
+				default:
+					e.SetItemMetadata(file.itemKind(), pkg.ID, "Version", pkg.Version)
+					changes = append(changes, Change{File: file.Path, Package: pkg.ID, From: current, To: pkg.Version})
+				}
+			}
+			if dryRun {
+				return errDryRun
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errDryRun) {
+			return changes, err
+		}
+	}
+	return changes, nil
+}
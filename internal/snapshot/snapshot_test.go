@@ -0,0 +1,117 @@
+package snapshot
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/willibrandon/lazynuget/internal/output"
+)
+
+// testRepo is a repository with a central package management props file.
+var testRepo = fstest.MapFS{
+	"Directory.Packages.props": {Data: []byte(`<Project>
+  <ItemGroup>
+    <PackageVersion Include="Polly" Version="8.3.1" />
+  </ItemGroup>
+</Project>`)},
+	"src/App/App.csproj": {Data: []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.1.0" />
+    <PackageReference Include="Polly" />
+    <PackageReference Include="Serilog" Version="2.0.0" Condition="'$(TargetFramework)' == 'net48'" />
+  </ItemGroup>
+</Project>`)},
+	"src/App/bin/Debug/Copy.csproj": {Data: []byte(`<Project><ItemGroup><PackageReference Include="Old" Version="1.0.0" /></ItemGroup></Project>`)},
+	"src/Empty/Empty.csproj":        {Data: []byte(`<Project Sdk="Microsoft.NET.Sdk" />`)},
+}
+
+// TestCreate tests capturing versions from project files and Directory.Packages.props
+func TestCreate(t *testing.T) {
+	s, err := Create(testRepo)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	want := []File{
+		{Path: "Directory.Packages.props", Packages: []Package{{ID: "Polly", Version: "8.3.1"}}},
+		{Path: "src/App/App.csproj", Packages: []Package{{ID: "Serilog", Version: "3.1.0"}}},
+	}
+	if !slices.EqualFunc(s.Files, want, func(a, b File) bool { return a.Path == b.Path && slices.Equal(a.Packages, b.Packages) }) {
+		t.Errorf("Files = %+v, want %+v", s.Files, want)
+	}
+
+	var buf bytes.Buffer
+	if err := s.Write(&buf, output.CurrentSchemaVersion); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	read, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(read.Files) != 2 || !read.Created.Equal(s.Created) || !read.Files[0].Central() {
+		t.Errorf("Read() = %+v, want the written snapshot", read)
+	}
+}
+
+// TestRead tests rejecting documents that are not snapshots
+func TestRead(t *testing.T) {
+	for _, doc := range []string{
+		`not json`,
+		`{"schemaVersion": 1, "kind": "diff", "data": {}}`,
+		`{"schemaVersion": 99, "kind": "snapshot", "data": {}}`,
+	} {
+		if _, err := Read(strings.NewReader(doc)); err == nil {
+			t.Errorf("Read(%s) should fail", doc)
+		}
+	}
+}
+
+// TestApply tests restoring versions, reporting missing items, and dry runs
+func TestApply(t *testing.T) {
+	root := t.TempDir()
+	for path, file := range testRepo {
+		full := filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, file.Data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := &Snapshot{Files: []File{
+		{Path: "Directory.Packages.props", Packages: []Package{{ID: "Polly", Version: "8.0.0"}}},
+		{Path: "src/App/App.csproj", Packages: []Package{{ID: "Serilog", Version: "3.1.0"}, {ID: "Removed", Version: "1.0.0"}, {ID: "Polly", Version: "7.0.0"}}},
+		{Path: "src/Gone/Gone.csproj", Packages: []Package{{ID: "Serilog", Version: "3.0.0"}}},
+	}}
+	want := []Change{
+		{File: "Directory.Packages.props", Package: "Polly", From: "8.3.1", To: "8.0.0"},
+		{File: "src/App/App.csproj", Package: "Removed", To: "1.0.0", Missing: true},
+		{File: "src/App/App.csproj", Package: "Polly", To: "7.0.0", Missing: true},
+		{File: "src/Gone/Gone.csproj", Missing: true},
+	}
+
+	props := filepath.Join(root, "Directory.Packages.props")
+	changes, err := s.Apply(root, true)
+	if err != nil {
+		t.Fatalf("Apply(dry run) error = %v", err)
+	}
+	if !slices.Equal(changes, want) {
+		t.Errorf("Apply(dry run) = %+v, want %+v", changes, want)
+	}
+	if got, _ := os.ReadFile(props); !bytes.Contains(got, []byte(`Version="8.3.1"`)) {
+		t.Error("a dry run must not write files")
+	}
+
+	if changes, err = s.Apply(root, false); err != nil || !slices.Equal(changes, want) {
+		t.Fatalf("Apply() = %+v, %v, want %+v", changes, err, want)
+	}
+	if got, _ := os.ReadFile(props); !bytes.Contains(got, []byte(`<PackageVersion Include="Polly" Version="8.0.0" />`)) {
+		t.Errorf("props =\n%s\nwant Polly 8.0.0", got)
+	}
+}
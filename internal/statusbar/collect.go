@@ -0,0 +1,181 @@
+package statusbar
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/project"
+	"github.com/willibrandon/lazynuget/internal/resolver"
+)
+
+// auditCodes are the warnings restore records for vulnerable packages (NuGetAudit), from
+// low to critical severity.
+var auditCodes = map[string]bool{"NU1901": true, "NU1902": true, "NU1903": true, "NU1904": true}
+
+// Options configure Collect.
+type Options struct {
+	Root   string       // Workspace root
+	Feeds  []string     // Feed URLs or folders to check; none leaves {feeds} out
+	Client *http.Client // For checking feeds (nil: http.DefaultClient)
+}
+
+// Collect gathers the data of the segments from the workspace, without running dotnet:
+// projects need a restore when obj/project.assets.json is missing or older than the
+// project (or its Directory.Packages.props), and vulnerable packages are those NuGetAudit
+// warned about in the last restore. The mode is the caller's to set.
+func Collect(ctx context.Context, opts Options) Data {
+	d := Data{
+		Repo:            filepath.Base(opts.Root),
+		Branch:          gitBranch(opts.Root),
+		Vulnerabilities: -1,
+		Now:             time.Now(),
+	}
+
+	projects, _ := project.Discover(opts.Root)
+	vulnerable := make(map[string]bool)
+	audited := false
+	for _, path := range projects {
+		assetsPath := resolver.AssetsPath(path)
+		if needsRestore(path, assetsPath) {
+			d.Pending++
+			continue
+		}
+		ids, err := auditWarnings(assetsPath)
+		if err != nil {
+			continue
+		}
+		audited = true
+		for _, id := range ids {
+			vulnerable[strings.ToLower(id)] = true
+		}
+	}
+	if audited {
+		d.Vulnerabilities = len(vulnerable)
+	}
+
+	if len(opts.Feeds) > 0 {
+		d.Feeds = checkFeeds(ctx, opts.Client, opts.Feeds)
+	}
+	return d
+}
+
+// needsRestore reports whether a project changed since its last restore.
+func needsRestore(projectPath, assetsPath string) bool {
+	assets, err := os.Stat(assetsPath)
+	if err != nil {
+		return true
+	}
+	inputs := []string{projectPath}
+	if props := project.FindPackagesProps(filepath.Dir(projectPath)); props != "" {
+		inputs = append(inputs, props)
+	}
+	for _, input := range inputs {
+		if info, err := os.Stat(input); err == nil && info.ModTime().After(assets.ModTime()) {
+			return true
+		}
+	}
+	return false
+}
+
+// auditWarnings returns the packages restore warned are vulnerable in an assets file.
+func auditWarnings(assetsPath string) ([]string, error) {
+	// #nosec G304 -- the assets file of a project in the workspace
+	data, err := os.ReadFile(assetsPath)
+	if err != nil {
+		return nil, err
+	}
+	var assets struct {
+		Logs []struct {
+			Code      string `json:"code"`
+			LibraryID string `json:"libraryId"`
+		} `json:"logs"`
+	}
+	if err := json.Unmarshal(data, &assets); err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, log := range assets.Logs {
+		if auditCodes[log.Code] && log.LibraryID != "" {
+			ids = append(ids, log.LibraryID)
+		}
+	}
+	return ids, nil
+}
+
+// checkFeeds asks each feed for its service index at once; a feed is down when it cannot
+// be reached, times out, or fails with a server error. Feeds that answer with any other
+// error (e.g., 401 without credentials) are up. Folders are up when they exist.
+func checkFeeds(ctx context.Context, client *http.Client, feeds []string) *FeedHealth {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var health FeedHealth
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, source := range feeds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+				_, err = nuget.OpenFeed(ctx, client, source)
+				if err != nil && !nuget.Unavailable(err) {
+					err = nil
+				}
+			} else {
+				_, err = os.Stat(source)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				health.Down++
+			} else {
+				health.Up++
+			}
+		}()
+	}
+	wg.Wait()
+	return &health
+}
+
+// gitBranch returns the branch checked out in the repository at root, the short commit
+// when HEAD is detached, or "" outside a repository. Worktrees, whose .git is a file
+// pointing at the git directory, are followed.
+func gitBranch(root string) string {
+	gitDir := filepath.Join(root, ".git")
+	if info, err := os.Stat(gitDir); err == nil && !info.IsDir() {
+		// #nosec G304 -- the .git file of the workspace
+		data, err := os.ReadFile(gitDir)
+		if err != nil {
+			return ""
+		}
+		dir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+		if !ok {
+			return ""
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(root, dir)
+		}
+		gitDir = dir
+	}
+	// #nosec G304 -- HEAD of the workspace repository
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	ref := strings.TrimSpace(string(head))
+	if branch, ok := strings.CutPrefix(ref, "ref: refs/heads/"); ok {
+		return branch
+	}
+	if len(ref) >= 7 {
+		return ref[:7]
+	}
+	return ""
+}
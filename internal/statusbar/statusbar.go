@@ -0,0 +1,134 @@
+// Package statusbar composes the status bar from segments (mode, chord keys, repository,
+// feed health, pending restores, vulnerabilities, clock) in the order the statusBar.format setting
+// gives, the way shell prompt frameworks compose a prompt. Segments with nothing to show
+// are left out along with the text before them.
+package statusbar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/theme"
+)
+
+// Kind is the JSON document kind of `lazynuget status-line --json`.
+const Kind = "status"
+
+// FeedHealth counts the configured feeds that answered and those that are down.
+type FeedHealth struct {
+	Up   int `json:"up"`
+	Down int `json:"down"`
+}
+
+// Data is what the segments show.
+type Data struct {
+	Mode            string      `json:"mode,omitempty"` // The UI's input mode (e.g., NORMAL); empty hides {mode}
+	Keys            string      `json:"keys,omitempty"` // Keys of a chord under way (see keymap.Matcher)
+	Repo            string      `json:"repo,omitempty"` // Workspace name
+	Branch          string      `json:"branch,omitempty"`
+	Feeds           *FeedHealth `json:"feeds,omitempty"` // Nil when the feeds were not checked
+	Pending         int         `json:"pending"`         // Projects that need a restore
+	Vulnerabilities int         `json:"vulnerabilities"` // Vulnerable packages; -1 when unknown
+	Now             time.Time   `json:"-"`
+}
+
+// segment is one part of a format: the text before a segment, and its name ("" for text
+// after the last segment).
+type segment struct {
+	prefix string
+	name   string
+}
+
+// parse splits a format into segments.
+func parse(format string) ([]segment, error) {
+	if _, err := config.StatusBarFormatSegments(format); err != nil {
+		return nil, err
+	}
+	var segments []segment
+	for {
+		start := strings.Index(format, "{")
+		end := strings.Index(format, "}")
+		if start < 0 || end < start {
+			return append(segments, segment{prefix: format}), nil
+		}
+		segments = append(segments, segment{prefix: format[:start], name: format[start+1 : end]})
+		format = format[end+1:]
+	}
+}
+
+// Render returns the status bar for a format (see config.StatusBarSegments), drawn in the
+// theme's status bar styles. The text before a segment appears only when the segment does
+// and something came before it, so separators never dangle; text before the first segment
+// and after the last always appears.
+func Render(format, clockFormat string, d Data, th *theme.Theme) (string, error) {
+	segments, err := parse(format)
+	if err != nil {
+		return "", err
+	}
+	bar := th.Panel("statusbar")
+	var b strings.Builder
+	rendered := false
+	for i, s := range segments {
+		if s.name == "" || i == 0 {
+			b.WriteString(th.Render(bar.Text, s.prefix))
+		}
+		if s.name == "" {
+			continue
+		}
+		text, style := d.segment(s.name, clockFormat, th, bar)
+		if text == "" {
+			continue
+		}
+		if rendered {
+			b.WriteString(th.Render(bar.Text, s.prefix))
+		}
+		b.WriteString(th.Render(style, text))
+		rendered = true
+	}
+	return b.String(), nil
+}
+
+// segment returns the text of a segment and its style, or "" when it has nothing to show.
+// Problems (feeds down, pending restores, vulnerabilities) take the warning and error
+// colors on the status bar's background.
+func (d Data) segment(name, clockFormat string, th *theme.Theme, bar theme.PanelStyles) (string, theme.Style) {
+	onBar := func(s theme.Style) theme.Style {
+		s.Bg = bar.Text.Bg
+		return s
+	}
+	switch name {
+	case "mode":
+		return d.Mode, bar.Header
+	case "keys":
+		return d.Keys, bar.Header
+	case "repo":
+		if d.Branch != "" && d.Repo != "" {
+			return d.Repo + " (" + d.Branch + ")", bar.Text
+		}
+		return d.Repo, bar.Text
+	case "feeds":
+		switch {
+		case d.Feeds == nil:
+			return "", bar.Text
+		case d.Feeds.Down > 0:
+			return fmt.Sprintf("feeds %d/%d down", d.Feeds.Down, d.Feeds.Up+d.Feeds.Down), onBar(th.Warning)
+		default:
+			return fmt.Sprintf("feeds %d up", d.Feeds.Up), bar.Text
+		}
+	case "pending":
+		if d.Pending > 0 {
+			return fmt.Sprintf("%d to restore", d.Pending), onBar(th.Warning)
+		}
+	case "vulns":
+		if d.Vulnerabilities > 0 {
+			return fmt.Sprintf("%d vulnerable", d.Vulnerabilities), onBar(th.Error)
+		}
+	case "clock":
+		if !d.Now.IsZero() {
+			return d.Now.Format(clockFormat), bar.Text
+		}
+	}
+	return "", bar.Text
+}
@@ -0,0 +1,150 @@
+package statusbar
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+	"github.com/willibrandon/lazynuget/internal/theme"
+)
+
+// TestRender tests segment order and the text dropped with empty segments
+func TestRender(t *testing.T) {
+	th := theme.New(config.GetDefaultConfig().ColorScheme, theme.ModeNone)
+	d := Data{
+		Repo:            "shop",
+		Branch:          "main",
+		Feeds:           &FeedHealth{Up: 2, Down: 1},
+		Vulnerabilities: 3,
+		Now:             time.Date(2026, 1, 2, 9, 5, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"{mode} {repo} {feeds} {pending} {vulns} {clock}", "shop (main) feeds 1/3 down 3 vulnerable 09:05"},
+		{"{clock} | {vulns} | {repo}", "09:05 | 3 vulnerable | shop (main)"},
+		{"{pending} | {repo} | {pending}", "shop (main)"},
+		{"nuget: {pending} | {repo}!", "nuget: shop (main)!"},
+		{"no segments", "no segments"},
+	}
+	for _, tt := range tests {
+		got, err := Render(tt.format, "15:04", d, th)
+		if err != nil {
+			t.Fatalf("Render(%q): %v", tt.format, err)
+		}
+		if got != tt.want {
+			t.Errorf("Render(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+
+	d.Mode = "NORMAL"
+	d.Keys = "g"
+	d.Pending = 2
+	d.Feeds = &FeedHealth{Up: 3}
+	d.Vulnerabilities = -1
+	if got, _ := Render("{mode} {keys} {feeds} {pending} {vulns}", "15:04", d, th); got != "NORMAL g feeds 3 up 2 to restore" {
+		t.Errorf("Render = %q", got)
+	}
+
+	if _, err := Render("{repo} {bogus}", "15:04", d, th); err == nil {
+		t.Error("Render accepted an unknown segment")
+	}
+
+	colored, _ := Render("{repo} {vulns}", "15:04", d, theme.New(config.GetDefaultConfig().ColorScheme, theme.ModeTrueColor))
+	if !strings.Contains(colored, "\x1b[") {
+		t.Errorf("Render in truecolor = %q, want escape sequences", colored)
+	}
+}
+
+// TestCollect tests pending restores, audit warnings, and the branch read from a workspace
+func TestCollect(t *testing.T) {
+	root := t.TempDir()
+	write := func(path, content string, modTime time.Time) {
+		t.Helper()
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old, recent := time.Now().Add(-time.Hour), time.Now()
+	project := `<Project Sdk="Microsoft.NET.Sdk"></Project>`
+	audit := `{"logs": [{"code": "NU1903", "libraryId": "Newtonsoft.Json"}, {"code": "NU1603", "libraryId": "Serilog"}]}`
+
+	// Restored after its last change, with a vulnerable package
+	write("src/Api/Api.csproj", project, old)
+	write("src/Api/obj/project.assets.json", audit, recent)
+	// Changed since its restore
+	write("src/Web/Web.csproj", project, recent)
+	write("src/Web/obj/project.assets.json", `{"logs": []}`, old)
+	// Never restored
+	write("tests/Tests/Tests.csproj", project, old)
+	write(".git/HEAD", "ref: refs/heads/feature/cache\n", old)
+
+	d := Collect(context.Background(), Options{Root: root})
+	if d.Repo != filepath.Base(root) || d.Branch != "feature/cache" {
+		t.Errorf("repo = %q (%q), want %q (feature/cache)", d.Repo, d.Branch, filepath.Base(root))
+	}
+	if d.Pending != 2 {
+		t.Errorf("Pending = %d, want 2", d.Pending)
+	}
+	if d.Vulnerabilities != 1 {
+		t.Errorf("Vulnerabilities = %d, want 1", d.Vulnerabilities)
+	}
+	if d.Feeds != nil {
+		t.Errorf("Feeds = %+v without feeds to check", d.Feeds)
+	}
+}
+
+// TestCollectFeeds tests that feeds failing with server errors are down
+func TestCollectFeeds(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"version": "3.0.0", "resources": []}`))
+	}))
+	defer up.Close()
+	unauthorized := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer unauthorized.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	feeds := []string{up.URL + "/index.json", unauthorized.URL + "/index.json", down.URL + "/index.json", t.TempDir(), filepath.Join(t.TempDir(), "missing")}
+	d := Collect(context.Background(), Options{Root: t.TempDir(), Feeds: feeds, Client: up.Client()})
+	if d.Feeds == nil || *d.Feeds != (FeedHealth{Up: 3, Down: 2}) {
+		t.Errorf("Feeds = %+v, want 3 up and 2 down", d.Feeds)
+	}
+	if d.Branch != "" || d.Vulnerabilities != -1 {
+		t.Errorf("branch %q, vulnerabilities %d outside a repository without projects", d.Branch, d.Vulnerabilities)
+	}
+}
+
+// TestGitBranch tests worktrees and detached HEADs
+func TestGitBranch(t *testing.T) {
+	gitDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("0123456789abcdef0123456789abcdef01234567\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".git"), []byte("gitdir: "+gitDir+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := gitBranch(root); got != "0123456" {
+		t.Errorf("gitBranch = %q, want the short commit", got)
+	}
+}
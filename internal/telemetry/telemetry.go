@@ -0,0 +1,469 @@
+// Package telemetry records anonymous usage counters for users who opt in.
+//
+// Telemetry is off until the user agrees (a first-run prompt, or `lazynuget telemetry enable`).
+// Only aggregate counters are kept: which commands ran, how long startup took, and coarse
+// error categories. No package names, paths, feed URLs, or arguments are recorded. The
+// exact payload can be inspected at any time with `lazynuget telemetry show`.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/willibrandon/lazynuget/internal/metrics"
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// StateFileName is the telemetry consent and counter file in the data directory.
+const StateFileName = "telemetry.json"
+
+// DisableEnvVar turns telemetry off regardless of consent. DO_NOT_TRACK=1 is honored too.
+const DisableEnvVar = "LAZYNUGET_NO_TELEMETRY"
+
+// SendInterval is the minimum time between reports sent to the endpoint.
+const SendInterval = 24 * time.Hour
+
+// Error categories. Only these coarse categories are recorded, never error messages.
+const (
+	ErrorConfig  = "config"  // Config load or reload failure
+	ErrorCommand = "command" // A subcommand exited with an error
+	ErrorStartup = "startup" // Bootstrap failed after telemetry was initialized
+)
+
+// Counters are the anonymous usage counters collected since the last report.
+type Counters struct {
+	PeriodStart time.Time      `json:"periodStart,omitzero"`
+	Commands    map[string]int `json:"commands,omitempty"`
+	Errors      map[string]int `json:"errors,omitempty"`
+	Startup     StartupStats   `json:"startup"`
+}
+
+// StartupStats summarizes startup durations without keeping individual samples.
+type StartupStats struct {
+	Count   int   `json:"count"`
+	TotalMs int64 `json:"totalMs"`
+	MaxMs   int64 `json:"maxMs"`
+}
+
+// Report is the payload sent to the telemetry endpoint.
+type Report struct {
+	Counters
+	InstallID string `json:"installId"` // Random, generated on opt-in, discarded on opt-out
+	Version   string `json:"version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+}
+
+// state is the on-disk format of the telemetry file.
+type state struct {
+	DecidedAt time.Time `json:"decidedAt,omitzero"`
+	LastSent  time.Time `json:"lastSent,omitzero"`
+	Counters  Counters  `json:"counters"`
+	InstallID string    `json:"installId,omitempty"`
+	Decided   bool      `json:"decided"`
+	Enabled   bool      `json:"enabled"`
+}
+
+// Store persists the user's telemetry decision and the counters collected since the last report.
+// Recording methods are no-ops unless the user opted in.
+//
+// Several processes may share the file (an interactive session and subcommands run next to
+// it), so every write re-reads it under a file lock: counts recorded by this process are
+// added to the file's, and the decision on disk wins over the one read at Open.
+type Store struct {
+	path    string
+	state   state    // As last read from or written to the file
+	pending Counters // Recorded since the last write, not yet in the file
+	mu      sync.Mutex
+}
+
+// DefaultStatePath returns the telemetry file location in the platform data directory,
+// with its temp fallback applied and a file from the config directory moved over (see
+// platform.DataPath).
+func DefaultStatePath() string {
+	return platform.DataPath(platform.DirState, StateFileName)
+}
+
+// DisabledByEnv reports whether the environment opts out of telemetry
+// (LAZYNUGET_NO_TELEMETRY or the DO_NOT_TRACK convention).
+func DisabledByEnv() bool {
+	for _, name := range []string{DisableEnvVar, "DO_NOT_TRACK"} {
+		switch strings.ToLower(strings.TrimSpace(os.Getenv(name))) {
+		case "", "0", "false", "no":
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// Open reads the telemetry file at path. A missing file yields an undecided, disabled store.
+func Open(path string) (*Store, error) {
+	st, err := readState(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{path: path, state: st}, nil
+}
+
+// readState reads the telemetry file at path; a missing file is an undecided state.
+func readState(path string) (state, error) {
+	var st state
+	// #nosec G304 -- path is the telemetry file in the user's data directory
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return st, fmt.Errorf("failed to read telemetry state: %w", err)
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return st, fmt.Errorf("invalid telemetry state %s: %w", path, err)
+	}
+	return st, nil
+}
+
+// Path returns the telemetry file location.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Decided reports whether the user has answered the opt-in prompt.
+func (s *Store) Decided() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.Decided
+}
+
+// Enabled reports whether the user opted in.
+func (s *Store) Enabled() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.Decided && s.state.Enabled
+}
+
+// SetConsent records the user's decision and persists it. Opting in creates a random
+// install ID; opting out discards the ID and any unsent counters.
+func (s *Store) SetConsent(enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !enabled {
+		s.pending = Counters{}
+	}
+	return s.updateLocked(func(st *state) error {
+		st.Decided = true
+		st.DecidedAt = time.Now().UTC()
+		st.Enabled = enabled
+		if !enabled {
+			st.InstallID = ""
+			st.Counters = Counters{}
+			st.LastSent = time.Time{}
+			return nil
+		}
+		if st.InstallID == "" {
+			id, err := newInstallID()
+			if err != nil {
+				return err
+			}
+			st.InstallID = id
+		}
+		return nil
+	})
+}
+
+// RecordCommand counts a use of a named command. Only fixed command names are
+// passed here, never arguments.
+func (s *Store) RecordCommand(name string) {
+	s.record(func(c *Counters) {
+		if c.Commands == nil {
+			c.Commands = make(map[string]int)
+		}
+		c.Commands[name]++
+	})
+}
+
+// RecordError counts an error in one of the Error* categories.
+func (s *Store) RecordError(category string) {
+	s.record(func(c *Counters) {
+		if c.Errors == nil {
+			c.Errors = make(map[string]int)
+		}
+		c.Errors[category]++
+	})
+}
+
+// RecordStartup adds a startup duration sample.
+func (s *Store) RecordStartup(d time.Duration) {
+	s.record(func(c *Counters) {
+		ms := d.Milliseconds()
+		c.Startup.Count++
+		c.Startup.TotalMs += ms
+		c.Startup.MaxMs = max(c.Startup.MaxMs, ms)
+	})
+}
+
+// record applies fn to the counters if the user opted in.
+func (s *Store) record(fn func(*Counters)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.state.Decided || !s.state.Enabled {
+		return
+	}
+	if s.pending.PeriodStart.IsZero() {
+		s.pending.PeriodStart = time.Now().UTC()
+	}
+	fn(&s.pending)
+}
+
+// Report returns the payload that would be sent next.
+func (s *Store) Report(version string) Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reportLocked(version)
+}
+
+func (s *Store) reportLocked(version string) Report {
+	return Report{
+		Counters:  addCounters(s.state.Counters, s.pending),
+		InstallID: s.state.InstallID,
+		Version:   version,
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+}
+
+// Due reports whether a report should be sent: the user opted in, something was
+// recorded, and SendInterval has passed since the last report (or since the first
+// count, before anything was sent).
+func (s *Store) Due(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	periodStart := addCounters(s.state.Counters, s.pending).PeriodStart
+	if !s.state.Decided || !s.state.Enabled || periodStart.IsZero() {
+		return false
+	}
+	since := s.state.LastSent
+	if since.IsZero() {
+		since = periodStart
+	}
+	return now.Sub(since) >= SendInterval
+}
+
+// Send posts the current report to endpoint as JSON. On success the counters are reset
+// and the send time is persisted. Does nothing when the user has not opted in or no
+// endpoint is configured.
+func (s *Store) Send(ctx context.Context, client *http.Client, endpoint, version string) error {
+	if endpoint == "" || !s.Enabled() {
+		return nil
+	}
+
+	// Report what the file holds, counts from other processes included
+	s.mu.Lock()
+	err := s.updateLocked(nil)
+	report := s.reportLocked(version)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if !report.Counters.recorded() {
+		return nil // Opted out in the meantime, or another process sent them
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode telemetry report: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid telemetry endpoint: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "lazynuget/"+version)
+
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second, Transport: metrics.Transport(nil)}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send telemetry: %w", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("telemetry endpoint returned %s", resp.Status)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Counters recorded while the request was in flight are kept for the next report
+	return s.updateLocked(func(st *state) error {
+		st.Counters = subtractCounters(st.Counters, report.Counters)
+		st.LastSent = time.Now().UTC()
+		return nil
+	})
+}
+
+// Save adds the counts recorded since the last write to the file's.
+func (s *Store) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updateLocked(nil)
+}
+
+// updateLocked re-reads the file under its lock, adds the pending counts (if the file
+// still records consent), applies fn, and writes the result back. Without a path the
+// state is kept in memory only.
+func (s *Store) updateLocked(fn func(*state) error) error {
+	if s.path == "" {
+		return s.apply(&s.state, fn)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create telemetry directory: %w", err)
+	}
+	// #nosec G304 -- path is next to the telemetry file in the user's data directory
+	lock, err := os.OpenFile(s.path+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to lock telemetry state: %w", err)
+	}
+	defer lock.Close()
+	if err := platform.LockFile(lock); err != nil {
+		return fmt.Errorf("failed to lock telemetry state: %w", err)
+	}
+	defer func() { _ = platform.UnlockFile(lock) }()
+
+	st, err := readState(s.path)
+	if err != nil {
+		return err
+	}
+	if err := s.apply(&st, fn); err != nil {
+		return err
+	}
+	if err := writeState(s.path, st); err != nil {
+		return err
+	}
+	s.state = st
+	return nil
+}
+
+// apply adds the pending counts to st when it records consent, then applies fn.
+func (s *Store) apply(st *state, fn func(*state) error) error {
+	next := *st
+	if next.Decided && next.Enabled {
+		next.Counters = addCounters(next.Counters, s.pending)
+	}
+	if fn != nil {
+		if err := fn(&next); err != nil {
+			return err
+		}
+	}
+	*st = next
+	s.pending = Counters{}
+	return nil
+}
+
+// writeState writes st to path.
+func writeState(path string, st state) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode telemetry state: %w", err)
+	}
+
+	if err := platform.WriteFileAtomic(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write telemetry state: %w", err)
+	}
+	return nil
+}
+
+// recorded reports whether c counts anything.
+func (c Counters) recorded() bool {
+	return len(c.Commands) > 0 || len(c.Errors) > 0 || c.Startup.Count > 0
+}
+
+// addCounters returns the sum of a and b, sharing no maps with either.
+func addCounters(a, b Counters) Counters {
+	result := Counters{
+		PeriodStart: a.PeriodStart,
+		Startup: StartupStats{
+			Count:   a.Startup.Count + b.Startup.Count,
+			TotalMs: a.Startup.TotalMs + b.Startup.TotalMs,
+			MaxMs:   max(a.Startup.MaxMs, b.Startup.MaxMs),
+		},
+	}
+	if result.PeriodStart.IsZero() || (!b.PeriodStart.IsZero() && b.PeriodStart.Before(result.PeriodStart)) {
+		result.PeriodStart = b.PeriodStart
+	}
+	for _, src := range []Counters{a, b} {
+		for name, n := range src.Commands {
+			if result.Commands == nil {
+				result.Commands = make(map[string]int)
+			}
+			result.Commands[name] += n
+		}
+		for category, n := range src.Errors {
+			if result.Errors == nil {
+				result.Errors = make(map[string]int)
+			}
+			result.Errors[category] += n
+		}
+	}
+	return result
+}
+
+// subtractCounters removes the counts in sent from current.
+func subtractCounters(current, sent Counters) Counters {
+	result := Counters{}
+	for name, n := range current.Commands {
+		if left := n - sent.Commands[name]; left > 0 {
+			if result.Commands == nil {
+				result.Commands = make(map[string]int)
+			}
+			result.Commands[name] = left
+		}
+	}
+	for category, n := range current.Errors {
+		if left := n - sent.Errors[category]; left > 0 {
+			if result.Errors == nil {
+				result.Errors = make(map[string]int)
+			}
+			result.Errors[category] = left
+		}
+	}
+	result.Startup = StartupStats{
+		Count:   current.Startup.Count - sent.Startup.Count,
+		TotalMs: current.Startup.TotalMs - sent.Startup.TotalMs,
+	}
+	if result.Startup.Count > 0 {
+		result.Startup.MaxMs = current.Startup.MaxMs
+	} else {
+		result.Startup = StartupStats{} // Sent by another process as well
+	}
+	if result.recorded() {
+		result.PeriodStart = time.Now().UTC()
+	}
+	return result
+}
+
+// newInstallID returns a random identifier that is not derived from the machine or user.
+func newInstallID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate install ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,326 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestOpenMissingFile verifies a missing state file is undecided and disabled
+func TestOpenMissingFile(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), StateFileName))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if store.Decided() || store.Enabled() {
+		t.Errorf("Decided() = %v, Enabled() = %v, want false for a new install", store.Decided(), store.Enabled())
+	}
+
+	// Nothing is recorded before the user opts in
+	store.RecordCommand("interactive")
+	store.RecordError(ErrorConfig)
+	store.RecordStartup(time.Second)
+	report := store.Report("1.0.0")
+	if len(report.Commands) != 0 || len(report.Errors) != 0 || report.Startup.Count != 0 {
+		t.Errorf("Report() = %+v, want no counters without consent", report)
+	}
+	if store.Due(time.Now().Add(48 * time.Hour)) {
+		t.Error("Due() = true without consent")
+	}
+}
+
+// TestConsentRoundTrip tests recording, persisting, and opting out
+func TestConsentRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", StateFileName)
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := store.SetConsent(true); err != nil {
+		t.Fatalf("SetConsent(true) error = %v", err)
+	}
+
+	store.RecordCommand("interactive")
+	store.RecordCommand("interactive")
+	store.RecordCommand("import-config")
+	store.RecordError(ErrorCommand)
+	store.RecordStartup(100 * time.Millisecond)
+	store.RecordStartup(300 * time.Millisecond)
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if !reopened.Enabled() {
+		t.Fatal("Enabled() = false after opting in")
+	}
+
+	report := reopened.Report("1.2.3")
+	if report.Commands["interactive"] != 2 || report.Commands["import-config"] != 1 {
+		t.Errorf("Commands = %v, want interactive=2 import-config=1", report.Commands)
+	}
+	if report.Errors[ErrorCommand] != 1 {
+		t.Errorf("Errors = %v, want command=1", report.Errors)
+	}
+	if report.Startup != (StartupStats{Count: 2, TotalMs: 400, MaxMs: 300}) {
+		t.Errorf("Startup = %+v, want count=2 total=400 max=300", report.Startup)
+	}
+	if len(report.InstallID) != 32 || report.Version != "1.2.3" || report.OS == "" || report.Arch == "" {
+		t.Errorf("Report identity = %+v, want random install ID, version, os, and arch", report)
+	}
+
+	// Opting out forgets the install ID and unsent counters
+	if err := reopened.SetConsent(false); err != nil {
+		t.Fatalf("SetConsent(false) error = %v", err)
+	}
+	reopened, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if !reopened.Decided() || reopened.Enabled() {
+		t.Errorf("Decided() = %v, Enabled() = %v, want decided and disabled", reopened.Decided(), reopened.Enabled())
+	}
+	report = reopened.Report("1.2.3")
+	if report.InstallID != "" || len(report.Commands) != 0 || report.Startup.Count != 0 {
+		t.Errorf("Report() = %+v, want empty report after opting out", report)
+	}
+}
+
+// TestSaveMergesProcesses tests that stores sharing a file add their counts instead of
+// overwriting each other's
+func TestSaveMergesProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), StateFileName)
+	first, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := first.SetConsent(true); err != nil {
+		t.Fatalf("SetConsent() error = %v", err)
+	}
+	second, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	first.RecordCommand("interactive")
+	second.RecordCommand("interactive")
+	second.RecordCommand("audit")
+	second.RecordStartup(200 * time.Millisecond)
+	for _, store := range []*Store{first, second, first} {
+		if err := store.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	report := reopened.Report("1.0.0")
+	if report.Commands["interactive"] != 2 || report.Commands["audit"] != 1 || report.Startup.Count != 1 {
+		t.Errorf("Report() = %+v, want both stores' counts once", report)
+	}
+	if report.InstallID != first.Report("1.0.0").InstallID {
+		t.Errorf("InstallID = %q, want the one created on opt-in", report.InstallID)
+	}
+}
+
+// TestSaveKeepsOptOut verifies that a store opened before another process opted out
+// never re-enables telemetry or writes its counts
+func TestSaveKeepsOptOut(t *testing.T) {
+	path := filepath.Join(t.TempDir(), StateFileName)
+	session, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := session.SetConsent(true); err != nil {
+		t.Fatalf("SetConsent() error = %v", err)
+	}
+	session.RecordCommand("interactive")
+
+	cli, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := cli.SetConsent(false); err != nil {
+		t.Fatalf("SetConsent(false) error = %v", err)
+	}
+
+	if err := session.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if session.Enabled() {
+		t.Error("Enabled() = true after saving over an opt-out")
+	}
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	report := reopened.Report("1.0.0")
+	if reopened.Enabled() || report.InstallID != "" || len(report.Commands) != 0 {
+		t.Errorf("after Save: Enabled() = %v, Report() = %+v, want the opt-out kept", reopened.Enabled(), report)
+	}
+}
+
+// TestDue tests the daily send schedule
+func TestDue(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), StateFileName))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := store.SetConsent(true); err != nil {
+		t.Fatalf("SetConsent() error = %v", err)
+	}
+
+	now := time.Now()
+	if store.Due(now.Add(48 * time.Hour)) {
+		t.Error("Due() = true with nothing recorded")
+	}
+
+	store.RecordCommand("interactive")
+	if store.Due(now) {
+		t.Error("Due() = true immediately after the first count")
+	}
+	if !store.Due(now.Add(SendInterval + time.Minute)) {
+		t.Error("Due() = false after SendInterval")
+	}
+}
+
+// TestSend tests posting the report and resetting counters
+func TestSend(t *testing.T) {
+	var received Report
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Errorf("invalid report body: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), StateFileName)
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := store.SetConsent(true); err != nil {
+		t.Fatalf("SetConsent() error = %v", err)
+	}
+	store.RecordCommand("interactive")
+	store.RecordStartup(250 * time.Millisecond)
+
+	if err := store.Send(context.Background(), server.Client(), server.URL, "1.0.0"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if contentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", contentType)
+	}
+	if received.Commands["interactive"] != 1 || received.Startup.MaxMs != 250 || received.Version != "1.0.0" {
+		t.Errorf("received report = %+v", received)
+	}
+
+	// Sent counters are cleared and the send time persisted
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if report := reopened.Report("1.0.0"); len(report.Commands) != 0 || report.Startup.Count != 0 {
+		t.Errorf("Report() after Send = %+v, want empty counters", report)
+	}
+	if reopened.Due(time.Now().Add(SendInterval + time.Minute)) {
+		t.Error("Due() = true with nothing recorded since the last send")
+	}
+}
+
+// TestSendFailureKeepsCounters verifies counters survive a failed send
+func TestSendFailureKeepsCounters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	store, err := Open(filepath.Join(t.TempDir(), StateFileName))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := store.SetConsent(true); err != nil {
+		t.Fatalf("SetConsent() error = %v", err)
+	}
+	store.RecordCommand("interactive")
+
+	err = store.Send(context.Background(), server.Client(), server.URL, "1.0.0")
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("Send() error = %v, want 503 error", err)
+	}
+	if report := store.Report("1.0.0"); report.Commands["interactive"] != 1 {
+		t.Errorf("Commands = %v, want counters kept after failed send", report.Commands)
+	}
+}
+
+// TestSendWithoutConsentOrEndpoint verifies nothing is sent unless opted in with an endpoint
+func TestSendWithoutConsentOrEndpoint(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	store, err := Open(filepath.Join(t.TempDir(), StateFileName))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	if err := store.Send(context.Background(), server.Client(), server.URL, "1.0.0"); err != nil {
+		t.Errorf("Send() without consent error = %v", err)
+	}
+	if err := store.SetConsent(true); err != nil {
+		t.Fatalf("SetConsent() error = %v", err)
+	}
+	if err := store.Send(context.Background(), server.Client(), "", "1.0.0"); err != nil {
+		t.Errorf("Send() without endpoint error = %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("endpoint received %d requests, want 0", requests)
+	}
+}
+
+// TestDisabledByEnv tests the environment opt-outs
+func TestDisabledByEnv(t *testing.T) {
+	tests := []struct {
+		name        string
+		noTelemetry string
+		doNotTrack  string
+		want        bool
+	}{
+		{name: "unset", want: false},
+		{name: "no telemetry set", noTelemetry: "1", want: true},
+		{name: "no telemetry true", noTelemetry: "true", want: true},
+		{name: "no telemetry false", noTelemetry: "false", want: false},
+		{name: "do not track", doNotTrack: "1", want: true},
+		{name: "do not track zero", doNotTrack: "0", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(DisableEnvVar, tt.noTelemetry)
+			t.Setenv("DO_NOT_TRACK", tt.doNotTrack)
+			if got := DisabledByEnv(); got != tt.want {
+				t.Errorf("DisabledByEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
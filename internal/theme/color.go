@@ -0,0 +1,86 @@
+package theme
+
+import "strconv"
+
+// rgb is a 24-bit color.
+type rgb struct{ r, g, b uint8 }
+
+// parseHex parses #RRGGBB or #RGB.
+func parseHex(s string) (rgb, bool) {
+	if len(s) == 4 && s[0] == '#' {
+		s = "#" + string([]byte{s[1], s[1], s[2], s[2], s[3], s[3]})
+	}
+	if len(s) != 7 || s[0] != '#' {
+		return rgb{}, false
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return rgb{}, false
+	}
+	return rgb{uint8(v >> 16), uint8(v >> 8), uint8(v)}, true
+}
+
+// ansi16 is the xterm default palette of the 16 ANSI colors.
+var ansi16 = [16]rgb{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// cubeLevels are the channel values of the 6x6x6 cube in the 256-color palette.
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// nearest16 returns the index of the closest ANSI color.
+func nearest16(c rgb) int {
+	best, bestDist := 0, -1
+	for i, p := range ansi16 {
+		if d := distance(c, p); bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// nearest256 returns the closest entry of the 256-color palette, from the color cube
+// (16-231) or the grayscale ramp (232-255).
+func nearest256(c rgb) int {
+	ri, gi, bi := cubeIndex(c.r), cubeIndex(c.g), cubeIndex(c.b)
+	cube := rgb{uint8(cubeLevels[ri]), uint8(cubeLevels[gi]), uint8(cubeLevels[bi])}
+	cubeColor := 16 + 36*ri + 6*gi + bi
+
+	// Grays run from 8 to 238 in steps of 10
+	avg := (int(c.r) + int(c.g) + int(c.b)) / 3
+	grayIndex := min(max((avg-8+5)/10, 0), 23)
+	level := uint8(8 + 10*grayIndex)
+	gray := rgb{level, level, level}
+
+	if distance(c, gray) < distance(c, cube) {
+		return 232 + grayIndex
+	}
+	return cubeColor
+}
+
+// cubeIndex returns the closest cube level for a channel value.
+func cubeIndex(v uint8) int {
+	best := 0
+	for i, level := range cubeLevels {
+		if abs(int(v)-level) < abs(int(v)-cubeLevels[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// distance returns the squared distance between two colors.
+func distance(a, b rgb) int {
+	dr, dg, db := int(a.r)-int(b.r), int(a.g)-int(b.g), int(a.b)-int(b.b)
+	return dr*dr + dg*dg + db*db
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
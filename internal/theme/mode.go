@@ -0,0 +1,90 @@
+package theme
+
+import (
+	"os"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// Mode is how styles are rendered: with as many colors as the terminal shows, with text
+// attributes only, or not at all.
+type Mode int
+
+const (
+	// ModeNone writes text without escape sequences, for pipes, files, TERM=dumb, and
+	// legacy Windows consoles.
+	ModeNone Mode = iota
+	// ModeMono uses text attributes (bold, faint, underline, reverse) but no colors, for
+	// NO_COLOR and --no-color, and terminals without color support.
+	ModeMono
+	// Mode16 maps colors to the nearest of the 16 ANSI colors.
+	Mode16
+	// Mode256 maps colors to the nearest entry of the xterm 256-color palette.
+	Mode256
+	// ModeTrueColor writes 24-bit colors as configured.
+	ModeTrueColor
+)
+
+// String returns the mode name used in logs.
+func (m Mode) String() string {
+	switch m {
+	case ModeNone:
+		return "none"
+	case ModeMono:
+		return "mono"
+	case Mode16:
+		return "16"
+	case Mode256:
+		return "256"
+	case ModeTrueColor:
+		return "truecolor"
+	default:
+		return "unknown"
+	}
+}
+
+// Terminal describes the output a mode is selected for.
+type Terminal struct {
+	TTY     bool                // Output is a terminal
+	Escapes bool                // The terminal interprets escape sequences (not TERM=dumb or a legacy console)
+	NoColor bool                // Colors are turned off by NO_COLOR or --no-color
+	Depth   platform.ColorDepth // Detected color support
+}
+
+// SelectMode picks the rendering mode for a terminal:
+//
+//	output                         mode
+//	not a terminal, no escapes     none
+//	NO_COLOR or --no-color         mono
+//	no color support               mono
+//	16 / 256 / 24-bit colors       16 / 256 / truecolor
+func SelectMode(t Terminal) Mode {
+	switch {
+	case !t.TTY || !t.Escapes:
+		return ModeNone
+	case t.NoColor:
+		return ModeMono
+	}
+	switch {
+	case t.Depth >= platform.ColorTrueColor:
+		return ModeTrueColor
+	case t.Depth >= platform.ColorExtended256:
+		return Mode256
+	case t.Depth >= platform.ColorBasic16:
+		return Mode16
+	default:
+		return ModeMono
+	}
+}
+
+// DetectMode picks the rendering mode for stdout. noColor is the --no-color flag; the
+// NO_COLOR environment variable (https://no-color.org/) has the same effect.
+func DetectMode(caps platform.TerminalCapabilities, console platform.ConsoleMode, noColor bool) Mode {
+	_, noColorEnv := os.LookupEnv("NO_COLOR")
+	return SelectMode(Terminal{
+		TTY:     caps.IsTTY(),
+		Escapes: !console.Legacy() && os.Getenv("TERM") != "dumb",
+		NoColor: noColor || noColorEnv,
+		Depth:   console.ClampColorDepth(caps.GetColorDepth()),
+	})
+}
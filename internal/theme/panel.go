@@ -0,0 +1,67 @@
+package theme
+
+import "github.com/willibrandon/lazynuget/internal/config"
+
+// PanelStyles are the styles of one panel, from its colorScheme overrides and the flat
+// colors they fall back to.
+type PanelStyles struct {
+	Text        Style
+	Border      Style
+	BorderFocus Style
+	Header      Style // The panel title
+	Selected    Style // The selected row
+}
+
+// newPanelStyles returns the styles of a panel's resolved colors.
+func newPanelStyles(c config.PanelColors) PanelStyles {
+	return PanelStyles{
+		Text:        Style{Fg: c.Fg, Bg: c.Bg},
+		Border:      Style{Fg: c.Border, Bg: c.Bg},
+		BorderFocus: Style{Fg: c.BorderFocus, Bg: c.Bg, Mono: Bold},
+		Header:      Style{Fg: c.HeaderFg, Bg: c.Bg},
+		Selected:    Style{Fg: c.SelectedFg, Bg: c.SelectedBg, Mono: Reverse},
+	}
+}
+
+// Frame returns the style of the panel's border: the focus color, bold in every mode, when
+// the panel has focus, so focus never rests on color alone.
+func (p PanelStyles) Frame(focused bool) Style {
+	if focused {
+		s := p.BorderFocus
+		s.Attrs |= Bold
+		return s
+	}
+	return p.Border
+}
+
+// Title returns the style of the panel's title: bold and in the focus color while the
+// panel has focus.
+func (p PanelStyles) Title(focused bool) Style {
+	if focused {
+		s := p.Header
+		s.Fg = p.BorderFocus.Fg
+		s.Attrs |= Bold
+		return s
+	}
+	return p.Header
+}
+
+// Panel returns the styles of a panel by its colorScheme key (see config.Panels). Panels
+// without overrides, and unknown names, get the theme's own styles.
+func (t *Theme) Panel(name string) PanelStyles {
+	if p, ok := t.Panels[name]; ok {
+		return p
+	}
+	return t.flatPanel()
+}
+
+// flatPanel returns the styles a panel without overrides has.
+func (t *Theme) flatPanel() PanelStyles {
+	return PanelStyles{
+		Text:        t.Text,
+		Border:      t.Border,
+		BorderFocus: t.BorderFocus,
+		Header:      t.Text,
+		Selected:    t.Highlight,
+	}
+}
@@ -0,0 +1,62 @@
+package theme
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+)
+
+// previewRow is one style in a preview, with the colorScheme key it comes from and text
+// like what the UI draws in it.
+type previewRow struct {
+	key    string
+	style  Style
+	sample string
+}
+
+// rows returns the styles of the theme in the order of the colorScheme keys, then those of
+// the panels with overrides.
+func (t *Theme) rows() []previewRow {
+	rows := []previewRow{
+		{"border", t.Border, "+-- Projects ----------+"},
+		{"borderFocus", t.BorderFocus, "+== Packages ==========+"},
+		{"text", t.Text, "Newtonsoft.Json 13.0.3"},
+		{"textDim", t.TextDim, "Popular high-performance JSON framework"},
+		{"highlight", t.Highlight, " > Serilog 4.0.0 (selected) "},
+		{"error", t.Error, "Restore failed: NU1101"},
+		{"warning", t.Warning, "2 vulnerable packages"},
+		{"success", t.Success, "Added Polly 8.4.1"},
+		{"info", t.Info, "Checking nuget.org"},
+	}
+	for _, name := range config.Panels {
+		p := t.Panel(name)
+		if p == t.flatPanel() {
+			continue
+		}
+		rows = append(rows,
+			previewRow{name + " title", p.Title(false), name},
+			previewRow{name + " title (focused)", p.Title(true), name},
+			previewRow{name + " border (focused)", p.Frame(true), "+======================+"},
+			previewRow{name + " text", p.Text, "Newtonsoft.Json 13.0.3"},
+			previewRow{name + " selected", p.Selected, " > Serilog 4.0.0 (selected) "},
+		)
+	}
+	return rows
+}
+
+// WritePreview writes each style of the theme with its colors and a sample drawn in it,
+// for `lazynuget theme preview`. Without colors (ModeNone) the samples are plain text.
+func (t *Theme) WritePreview(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, row := range t.rows() {
+		colors := row.style.Fg
+		if row.style.Bg != "" {
+			colors += " on " + row.style.Bg
+		}
+		// The sample is last: its escape sequences would throw off the column widths
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", row.key, colors, t.Render(row.style, row.sample))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,146 @@
+// Package theme turns the configured color scheme into styled text for the terminal's
+// rendering mode (see SelectMode). Every style pairs colors with a monochrome fallback of
+// text attributes, so errors, focus, and selection stay distinguishable without color.
+package theme
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+)
+
+// Attr is a set of text attributes.
+type Attr uint8
+
+// Text attributes.
+const (
+	Bold Attr = 1 << iota
+	Faint
+	Underline
+	Reverse
+)
+
+// codes returns the SGR parameters of a set of attributes.
+func (a Attr) codes() []string {
+	var codes []string
+	for _, attr := range []struct {
+		attr Attr
+		code string
+	}{{Bold, "1"}, {Faint, "2"}, {Underline, "4"}, {Reverse, "7"}} {
+		if a&attr.attr != 0 {
+			codes = append(codes, attr.code)
+		}
+	}
+	return codes
+}
+
+// Style is how one kind of text is drawn.
+type Style struct {
+	Fg    string // Foreground color, as #RRGGBB or #RGB (empty: the terminal's)
+	Bg    string // Background color (empty: the terminal's)
+	Attrs Attr   // Attributes in every mode
+	Mono  Attr   // Attributes that stand in for the colors in ModeMono
+}
+
+// Theme holds the styles of the UI.
+type Theme struct {
+	Mode        Mode
+	Border      Style
+	BorderFocus Style
+	Text        Style
+	TextDim     Style
+	Highlight   Style // Selected rows
+	Error       Style
+	Warning     Style
+	Success     Style
+	Info        Style
+	Panels      map[string]PanelStyles // By colorScheme key; see Panel
+}
+
+// New returns the theme for a color scheme, rendered in mode.
+func New(scheme config.ColorScheme, mode Mode) *Theme {
+	t := &Theme{
+		Mode:        mode,
+		Border:      Style{Fg: scheme.Border},
+		BorderFocus: Style{Fg: scheme.BorderFocus, Mono: Bold},
+		Text:        Style{Fg: scheme.Text},
+		TextDim:     Style{Fg: scheme.TextDim, Mono: Faint},
+		Highlight:   Style{Fg: scheme.Background, Bg: scheme.Highlight, Mono: Reverse},
+		Error:       Style{Fg: scheme.Error, Mono: Bold},
+		Warning:     Style{Fg: scheme.Warning, Mono: Underline},
+		Success:     Style{Fg: scheme.Success},
+		Info:        Style{Fg: scheme.Info},
+		Panels:      make(map[string]PanelStyles, len(config.Panels)),
+	}
+	for _, name := range config.Panels {
+		t.Panels[name] = newPanelStyles(scheme.Panel(name))
+	}
+	return t
+}
+
+// Default returns the theme for the default color scheme.
+func Default(mode Mode) *Theme {
+	return New(config.GetDefaultConfig().ColorScheme, mode)
+}
+
+// Render returns text drawn in a style.
+func (t *Theme) Render(s Style, text string) string {
+	seq := t.sequence(s)
+	if seq == "" {
+		return text
+	}
+	return seq + text + "\x1b[0m"
+}
+
+// sequence returns the escape sequence that starts a style, or "" when it changes nothing.
+func (t *Theme) sequence(s Style) string {
+	var codes []string
+	switch t.Mode {
+	case ModeNone:
+		return ""
+	case ModeMono:
+		codes = (s.Attrs | s.Mono).codes()
+	default:
+		codes = s.Attrs.codes()
+		if c, ok := parseHex(s.Fg); ok {
+			codes = append(codes, t.color(c, false))
+		}
+		if c, ok := parseHex(s.Bg); ok {
+			codes = append(codes, t.color(c, true))
+		}
+	}
+	if len(codes) == 0 {
+		return ""
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
+
+// color returns the SGR parameters of a foreground or background color in the theme's mode.
+func (t *Theme) color(c rgb, background bool) string {
+	switch t.Mode {
+	case ModeTrueColor:
+		prefix := "38;2;"
+		if background {
+			prefix = "48;2;"
+		}
+		return prefix + strconv.Itoa(int(c.r)) + ";" + strconv.Itoa(int(c.g)) + ";" + strconv.Itoa(int(c.b))
+	case Mode256:
+		prefix := "38;5;"
+		if background {
+			prefix = "48;5;"
+		}
+		return prefix + strconv.Itoa(nearest256(c))
+	default:
+		code := nearest16(c)
+		// 0-7 map to 30-37, 8-15 (bright) to 90-97; backgrounds are 10 higher
+		base := 30 + code
+		if code >= 8 {
+			base = 90 + code - 8
+		}
+		if background {
+			base += 10
+		}
+		return strconv.Itoa(base)
+	}
+}
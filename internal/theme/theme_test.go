@@ -0,0 +1,174 @@
+package theme
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/config"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+func TestSelectMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		terminal Terminal
+		want     Mode
+	}{
+		{"pipe", Terminal{TTY: false, Escapes: true, Depth: platform.ColorTrueColor}, ModeNone},
+		{"dumb or legacy console", Terminal{TTY: true, Escapes: false, Depth: platform.ColorBasic16}, ModeNone},
+		{"no color", Terminal{TTY: true, Escapes: true, NoColor: true, Depth: platform.ColorTrueColor}, ModeMono},
+		{"no color support", Terminal{TTY: true, Escapes: true, Depth: platform.ColorNone}, ModeMono},
+		{"16 colors", Terminal{TTY: true, Escapes: true, Depth: platform.ColorBasic16}, Mode16},
+		{"256 colors", Terminal{TTY: true, Escapes: true, Depth: platform.ColorExtended256}, Mode256},
+		{"truecolor", Terminal{TTY: true, Escapes: true, Depth: platform.ColorTrueColor}, ModeTrueColor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SelectMode(tt.terminal); got != tt.want {
+				t.Errorf("SelectMode(%+v) = %s, want %s", tt.terminal, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		mode  Mode
+		style func(*Theme) Style
+		want  string
+	}{
+		{ModeNone, func(th *Theme) Style { return th.Error }, "x"},
+		{ModeMono, func(th *Theme) Style { return th.Error }, "\x1b[1mx\x1b[0m"},
+		{ModeMono, func(th *Theme) Style { return th.Highlight }, "\x1b[7mx\x1b[0m"},
+		{ModeMono, func(th *Theme) Style { return th.Success }, "x"},
+		{Mode16, func(th *Theme) Style { return th.Error }, "\x1b[91mx\x1b[0m"},
+		{Mode16, func(th *Theme) Style { return th.Highlight }, "\x1b[30;103mx\x1b[0m"},
+		{Mode256, func(th *Theme) Style { return th.Warning }, "\x1b[38;5;214mx\x1b[0m"},
+		{Mode256, func(th *Theme) Style { return th.TextDim }, "\x1b[38;5;244mx\x1b[0m"},
+		{ModeTrueColor, func(th *Theme) Style { return th.Warning }, "\x1b[38;2;255;165;0mx\x1b[0m"},
+	}
+	for _, tt := range tests {
+		th := Default(tt.mode)
+		if got := th.Render(tt.style(th), "x"); got != tt.want {
+			t.Errorf("%s: Render() = %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+}
+
+// TestMonoFallback checks that the styles that carry meaning stay distinguishable without color.
+func TestMonoFallback(t *testing.T) {
+	th := Default(ModeMono)
+	for name, s := range map[string]Style{
+		"borderFocus": th.BorderFocus,
+		"textDim":     th.TextDim,
+		"highlight":   th.Highlight,
+		"error":       th.Error,
+		"warning":     th.Warning,
+	} {
+		if th.Render(s, "x") == "x" {
+			t.Errorf("%s has no monochrome fallback", name)
+		}
+	}
+}
+
+func TestParseHex(t *testing.T) {
+	tests := []struct {
+		in   string
+		want rgb
+		ok   bool
+	}{
+		{"#FFA500", rgb{255, 165, 0}, true},
+		{"#0f0", rgb{0, 255, 0}, true},
+		{"", rgb{}, false},
+		{"FFA500", rgb{}, false},
+		{"#GGGGGG", rgb{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseHex(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseHex(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestNearest(t *testing.T) {
+	if got := nearest256(rgb{0, 0, 0}); got != 16 {
+		t.Errorf("nearest256(black) = %d, want 16", got)
+	}
+	if got := nearest256(rgb{255, 255, 255}); got != 231 {
+		t.Errorf("nearest256(white) = %d, want 231", got)
+	}
+	if got := nearest256(rgb{128, 128, 128}); got != 244 {
+		t.Errorf("nearest256(gray) = %d, want 244", got)
+	}
+	if got := nearest16(rgb{0, 255, 255}); got != 14 {
+		t.Errorf("nearest16(cyan) = %d, want 14", got)
+	}
+}
+
+// TestWritePreview tests previewing a theme's colors with and without escapes
+func TestWritePreview(t *testing.T) {
+	var plain strings.Builder
+	if err := Default(ModeNone).WritePreview(&plain); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(plain.String(), "\x1b[") {
+		t.Errorf("ModeNone preview has escape sequences:\n%q", plain.String())
+	}
+	for _, want := range []string{"border", "#FFFFFF", "highlight    #000000 on #FFFF00", "Restore failed"} {
+		if !strings.Contains(plain.String(), want) {
+			t.Errorf("preview missing %q:\n%s", want, plain.String())
+		}
+	}
+
+	var colored strings.Builder
+	if err := Default(ModeTrueColor).WritePreview(&colored); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(colored.String(), "\x1b[38;2;255;0;0mRestore failed") {
+		t.Errorf("truecolor preview does not draw error in red:\n%q", colored.String())
+	}
+}
+
+// TestPanelStyles tests per-panel styles and focus styling
+func TestPanelStyles(t *testing.T) {
+	scheme := config.GetDefaultConfig().ColorScheme
+	scheme.Packages.SelectedBg = "#FF00FF"
+	th := New(scheme, ModeTrueColor)
+
+	packages := th.Panel("packages")
+	if packages.Selected.Bg != "#FF00FF" || packages.Selected.Fg != scheme.Background {
+		t.Errorf("packages selected = %+v, want the override on the flat text color", packages.Selected)
+	}
+	if details := th.Panel("details"); details != th.flatPanel() || details.Selected != th.Highlight {
+		t.Errorf("details = %+v, want the theme's own styles", details)
+	}
+	if unknown := th.Panel("sidebar"); unknown != th.flatPanel() {
+		t.Errorf("Panel(sidebar) = %+v, want the theme's own styles", unknown)
+	}
+
+	if frame := packages.Frame(true); frame.Fg != scheme.BorderFocus || frame.Attrs&Bold == 0 {
+		t.Errorf("Frame(true) = %+v, want the focus color in bold", frame)
+	}
+	if frame := packages.Frame(false); frame != packages.Border {
+		t.Errorf("Frame(false) = %+v, want the border", frame)
+	}
+	if title := packages.Title(true); title.Fg != scheme.BorderFocus || title.Attrs&Bold == 0 {
+		t.Errorf("Title(true) = %+v, want the focus color in bold", title)
+	}
+
+	// Focus stays visible without colors
+	mono := New(scheme, ModeMono)
+	if got := mono.Render(mono.Panel("packages").Frame(true), "x"); got != "\x1b[1m"+"x\x1b[0m" {
+		t.Errorf("focused frame in ModeMono = %q, want bold", got)
+	}
+
+	var preview strings.Builder
+	if err := New(scheme, ModeNone).WritePreview(&preview); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(preview.String(), "packages selected") || strings.Contains(preview.String(), "details") {
+		t.Errorf("preview should list only the overridden panel:\n%s", preview.String())
+	}
+}
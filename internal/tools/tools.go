@@ -0,0 +1,238 @@
+// Package tools manages .NET tools: local tools, pinned in a repository's tool manifest
+// (.config/dotnet-tools.json) and restored with `dotnet tool restore`, and global tools,
+// installed for the user. Updates are checked like package references (see package
+// outdated); installs, updates, and removals run `dotnet tool`.
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/outdated"
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// Kind identifies tool lists in versioned JSON output.
+const Kind = "tools"
+
+// Scope says where a tool is installed.
+type Scope string
+
+// Tool scopes.
+const (
+	ScopeLocal  Scope = "local"  // Pinned in a tool manifest
+	ScopeGlobal Scope = "global" // Installed for the user
+)
+
+// Tool is an installed .NET tool.
+type Tool struct {
+	ID       string   `json:"id"`
+	Version  string   `json:"version"`
+	Commands []string `json:"commands"`
+	Scope    Scope    `json:"scope"`
+	Manifest string   `json:"manifest,omitempty"` // Tool manifest of a local tool
+}
+
+// Entry is an installed tool with the latest version of its package.
+type Entry struct {
+	Tool
+	Latest string          `json:"latest"`
+	Status outdated.Status `json:"status"`
+	Reason string          `json:"reason,omitempty"` // Why the tool could not be checked
+}
+
+// manifestNames are where dotnet looks for a tool manifest in each directory, in order.
+var manifestNames = []string{filepath.Join(".config", "dotnet-tools.json"), "dotnet-tools.json"}
+
+// manifest is the content of a tool manifest.
+type manifest struct {
+	Version int  `json:"version"`
+	IsRoot  bool `json:"isRoot"` // Manifests in parent directories are not consulted
+	Tools   map[string]struct {
+		Version  string   `json:"version"`
+		Commands []string `json:"commands"`
+	} `json:"tools"`
+}
+
+// FindManifests returns the tool manifests that apply in dir, nearest first: dotnet
+// searches dir and its parents, stopping after a manifest marked isRoot.
+func FindManifests(dir string) ([]string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for {
+		for _, name := range manifestNames {
+			path := filepath.Join(dir, name)
+			m, err := readManifest(path)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, path)
+			if m.IsRoot {
+				return paths, nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return paths, nil
+		}
+		dir = parent
+	}
+}
+
+// readManifest parses a tool manifest.
+func readManifest(path string) (*manifest, error) {
+	// #nosec G304 -- path is a tool manifest found by FindManifests
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse tool manifest %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// LocalTools returns the local tools available in dir, sorted by ID. A tool in a nearer
+// manifest hides the same tool in a manifest further up.
+func LocalTools(dir string) ([]Tool, error) {
+	paths, err := FindManifests(dir)
+	if err != nil {
+		return nil, err
+	}
+	var tools []Tool
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		m, err := readManifest(path)
+		if err != nil {
+			return nil, err
+		}
+		for id, t := range m.Tools {
+			key := strings.ToLower(id)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			tools = append(tools, Tool{ID: id, Version: t.Version, Commands: t.Commands, Scope: ScopeLocal, Manifest: path})
+		}
+	}
+	sortTools(tools)
+	return tools, nil
+}
+
+// GlobalTools returns the global tools of the current user, from `dotnet tool list --global`.
+func GlobalTools(ctx context.Context, spawner platform.ProcessSpawner) ([]Tool, error) {
+	result, err := spawner.RunContext(ctx, "dotnet", []string{"tool", "list", "--global"}, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("dotnet tool list failed: %s", strings.TrimSpace(result.Stdout+"\n"+result.Stderr))
+	}
+	return ParseList(result.Stdout, ScopeGlobal), nil
+}
+
+// ParseList parses the table `dotnet tool list` prints:
+//
+//	Package Id      Version      Commands
+//	-------------------------------------
+//	dotnetsay       2.1.7        dotnetsay
+func ParseList(output string, scope Scope) []Tool {
+	var tools []Tool
+	rows := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "---") {
+			rows = true
+			continue
+		}
+		fields := strings.Fields(line)
+		if !rows || len(fields) < 2 {
+			continue
+		}
+		t := Tool{ID: fields[0], Version: fields[1], Scope: scope}
+		if len(fields) > 2 {
+			// Commands are comma-separated when a tool has several
+			t.Commands = strings.Split(strings.Join(fields[2:], ""), ",")
+		}
+		tools = append(tools, t)
+	}
+	sortTools(tools)
+	return tools
+}
+
+// Check compares an installed tool with the available versions of its package.
+func Check(t Tool, available []string, opts outdated.Options) Entry {
+	r := outdated.Check(t.ID, t.Version, t.Version, available, opts)
+	return Entry{Tool: t, Latest: r.Latest, Status: r.Status, Reason: r.Reason}
+}
+
+// Install installs a tool in a scope, at version or the latest release. Local installs run
+// in dir and create a tool manifest there when none applies.
+func Install(ctx context.Context, spawner platform.ProcessSpawner, dir string, scope Scope, id, version string) error {
+	args := []string{"tool", "install", scopeFlag(scope), id}
+	if scope == ScopeLocal {
+		args = append(args, "--create-manifest-if-needed")
+	}
+	if version != "" {
+		args = append(args, "--version", version)
+	}
+	return run(ctx, spawner, dir, args)
+}
+
+// Update updates a tool to version or the latest release.
+func Update(ctx context.Context, spawner platform.ProcessSpawner, dir string, scope Scope, id, version string) error {
+	args := []string{"tool", "update", scopeFlag(scope), id}
+	if version != "" {
+		args = append(args, "--version", version, "--allow-downgrade")
+	}
+	return run(ctx, spawner, dir, args)
+}
+
+// UpdateAll updates every tool in a scope to its latest release.
+func UpdateAll(ctx context.Context, spawner platform.ProcessSpawner, dir string, scope Scope) error {
+	return run(ctx, spawner, dir, []string{"tool", "update", scopeFlag(scope), "--all"})
+}
+
+// Uninstall removes a tool; a local tool is removed from its manifest.
+func Uninstall(ctx context.Context, spawner platform.ProcessSpawner, dir string, scope Scope, id string) error {
+	return run(ctx, spawner, dir, []string{"tool", "uninstall", scopeFlag(scope), id})
+}
+
+// scopeFlag returns the `dotnet tool` option for a scope.
+func scopeFlag(scope Scope) string {
+	if scope == ScopeGlobal {
+		return "--global"
+	}
+	return "--local"
+}
+
+// run runs dotnet and turns a failure into an error with its output.
+func run(ctx context.Context, spawner platform.ProcessSpawner, dir string, args []string) error {
+	result, err := spawner.RunContext(ctx, "dotnet", args, dir, nil)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("dotnet %s failed: %s", strings.Join(args[:2], " "), strings.TrimSpace(result.Stdout+"\n"+result.Stderr))
+	}
+	return nil
+}
+
+// sortTools orders tools by ID.
+func sortTools(tools []Tool) {
+	slices.SortFunc(tools, func(a, b Tool) int {
+		return strings.Compare(strings.ToLower(a.ID), strings.ToLower(b.ID))
+	})
+}
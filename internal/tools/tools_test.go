@@ -0,0 +1,144 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/outdated"
+	"github.com/willibrandon/lazynuget/internal/platform"
+)
+
+// fakeSpawner records the dotnet commands it is asked to run.
+type fakeSpawner struct {
+	platform.ProcessSpawner
+	calls  [][]string
+	result platform.ProcessResult
+}
+
+func (f *fakeSpawner) RunContext(_ context.Context, executable string, args []string, _ string, _ map[string]string) (platform.ProcessResult, error) {
+	f.calls = append(f.calls, append([]string{executable}, args...))
+	return f.result, nil
+}
+
+// TestParseList tests reading the table of dotnet tool list
+func TestParseList(t *testing.T) {
+	output := `Package Id                   Version      Commands
+----------------------------------------------------------
+dotnet-ef                    8.0.8        dotnet-ef
+Cake.Tool                    4.0.0        dotnet-cake, dotnet-cake-alt
+`
+	tools := ParseList(output, ScopeGlobal)
+	if len(tools) != 2 {
+		t.Fatalf("ParseList() = %+v, want 2 tools", tools)
+	}
+	if tools[0].ID != "Cake.Tool" || tools[0].Version != "4.0.0" || tools[0].Scope != ScopeGlobal ||
+		!slices.Equal(tools[0].Commands, []string{"dotnet-cake", "dotnet-cake-alt"}) {
+		t.Errorf("tools[0] = %+v", tools[0])
+	}
+	if tools[1].ID != "dotnet-ef" || !slices.Equal(tools[1].Commands, []string{"dotnet-ef"}) {
+		t.Errorf("tools[1] = %+v", tools[1])
+	}
+
+	if tools := ParseList("No tools installed.\n", ScopeGlobal); len(tools) != 0 {
+		t.Errorf("ParseList(no table) = %+v, want none", tools)
+	}
+}
+
+// TestLocalTools tests reading tools from nested tool manifests
+func TestLocalTools(t *testing.T) {
+	root := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(root, ".config", "dotnet-tools.json"),
+		`{"version":1,"isRoot":true,"tools":{"dotnet-ef":{"version":"8.0.0","commands":["dotnet-ef"]},"csharpier":{"version":"0.28.0","commands":["dotnet-csharpier"]}}}`)
+	sub := filepath.Join(root, "src", "App")
+	write(filepath.Join(sub, "dotnet-tools.json"),
+		`{"version":1,"isRoot":false,"tools":{"DOTNET-EF":{"version":"8.0.8","commands":["dotnet-ef"]}}}`)
+
+	manifests, err := FindManifests(sub)
+	if err != nil || len(manifests) != 2 {
+		t.Fatalf("FindManifests() = %v, %v, want 2 manifests", manifests, err)
+	}
+
+	tools, err := LocalTools(sub)
+	if err != nil {
+		t.Fatalf("LocalTools() error = %v", err)
+	}
+	if len(tools) != 2 {
+		t.Fatalf("LocalTools() = %+v, want 2 tools", tools)
+	}
+	// The nearer manifest wins
+	if tools[1].ID != "DOTNET-EF" || tools[1].Version != "8.0.8" || tools[1].Manifest != manifests[0] {
+		t.Errorf("tools[1] = %+v, want DOTNET-EF 8.0.8 from %s", tools[1], manifests[0])
+	}
+	if tools[0].ID != "csharpier" || tools[0].Scope != ScopeLocal {
+		t.Errorf("tools[0] = %+v", tools[0])
+	}
+
+	// Manifests above a root manifest are not consulted
+	write(filepath.Join(sub, "dotnet-tools.json"), `{"version":1,"isRoot":true,"tools":{}}`)
+	if tools, err := LocalTools(sub); err != nil || len(tools) != 0 {
+		t.Errorf("LocalTools(root manifest) = %+v, %v, want none", tools, err)
+	}
+
+	write(filepath.Join(sub, "dotnet-tools.json"), `{`)
+	if _, err := LocalTools(sub); err == nil {
+		t.Error("LocalTools(invalid manifest) error = nil")
+	}
+}
+
+// TestCheck tests checking tools for updates
+func TestCheck(t *testing.T) {
+	tool := Tool{ID: "dotnet-ef", Version: "8.0.0"}
+	r := Check(tool, []string{"7.0.0", "8.0.0", "8.0.8", "9.0.0-rc.1"}, outdated.Options{})
+	if r.Status != outdated.StatusOutdated || r.Latest != "8.0.8" {
+		t.Errorf("Check() = %+v, want outdated with latest 8.0.8", r)
+	}
+	if r := Check(tool, []string{"8.0.0"}, outdated.Options{}); r.Status != outdated.StatusCurrent {
+		t.Errorf("Check(current) = %+v, want current", r)
+	}
+}
+
+// TestCommands tests the dotnet commands that change tools
+func TestCommands(t *testing.T) {
+	spawner := &fakeSpawner{}
+	if err := Install(context.Background(), spawner, "", ScopeLocal, "dotnet-ef", "8.0.8"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Update(context.Background(), spawner, "", ScopeGlobal, "dotnet-ef", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateAll(context.Background(), spawner, "", ScopeLocal); err != nil {
+		t.Fatal(err)
+	}
+	if err := Uninstall(context.Background(), spawner, "", ScopeGlobal, "dotnet-ef"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"dotnet tool install --local dotnet-ef --create-manifest-if-needed --version 8.0.8",
+		"dotnet tool update --global dotnet-ef",
+		"dotnet tool update --local --all",
+		"dotnet tool uninstall --global dotnet-ef",
+	}
+	for i, call := range spawner.calls {
+		if got := strings.Join(call, " "); got != want[i] {
+			t.Errorf("call %d = %q, want %q", i, got, want[i])
+		}
+	}
+
+	spawner.result = platform.ProcessResult{ExitCode: 1, Stderr: "Tool 'x' is not currently installed."}
+	if err := Uninstall(context.Background(), spawner, "", ScopeLocal, "x"); err == nil || !strings.Contains(err.Error(), "not currently installed") {
+		t.Errorf("Uninstall() error = %v, want dotnet's message", err)
+	}
+}
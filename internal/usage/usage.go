@@ -0,0 +1,147 @@
+// Package usage finds source files that likely depend on a package, so removing the
+// package does not silently break the build.
+//
+// The search is a heuristic: it looks for imports of a package's namespaces (C# using
+// directives, F# open declarations, VB Imports statements, and Razor @using directives)
+// and fully qualified names starting with one of them. It does not resolve types, so
+// namespaces shared by several packages match every one of them.
+package usage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/project"
+)
+
+// sourceExtensions are the source files searched.
+var sourceExtensions = map[string]bool{
+	".cs": true, ".fs": true, ".fsi": true, ".vb": true, ".razor": true, ".cshtml": true,
+}
+
+// importPatterns match an import and capture the imported namespace.
+var importPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^\s*(?:global\s+)?using\s+(?:static\s+)?(?:\w+\s*=\s*)?(?:global::)?([\w.]+)\s*;`), // C#
+	regexp.MustCompile(`^\s*open\s+(?:type\s+)?([\w.]+)`),                                                  // F#
+	regexp.MustCompile(`(?i)^\s*Imports\s+(?:\w+\s*=\s*)?([\w.]+)`),                                        // VB
+	regexp.MustCompile(`^\s*@using\s+(?:static\s+)?([\w.]+)`),                                              // Razor
+}
+
+// Match is a line that likely uses a package.
+type Match struct {
+	File      string // Slash-separated, relative to the searched folder
+	Line      int    // 1-based
+	Namespace string // The package namespace that matched
+	Text      string // The line, trimmed
+	Import    bool   // The line imports the namespace; otherwise it names a type qualified by it
+}
+
+// IsSourceFile reports whether a file name is a source file the search reads.
+func IsSourceFile(name string) bool {
+	return sourceExtensions[strings.ToLower(path.Ext(name))]
+}
+
+// Search returns the lines of source files in fsys that import one of the namespaces or
+// qualify a name with one, skipping build output folders.
+func Search(fsys fs.FS, namespaces []string) ([]Match, error) {
+	if len(namespaces) == 0 {
+		return nil, nil
+	}
+	qualified := qualifiedPattern(namespaces)
+
+	paths, err := project.Find(fsys, IsSourceFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan for source files: %w", err)
+	}
+
+	var matches []Match
+	for _, p := range paths {
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", p, err)
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(nil, 1<<20)
+		for line := 1; scanner.Scan(); line++ {
+			text := scanner.Text()
+			trimmed := strings.TrimSpace(text)
+			if trimmed == "" || strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "'") {
+				continue
+			}
+
+			if ns, ok := importedNamespace(text, namespaces); ok {
+				matches = append(matches, Match{File: p, Line: line, Namespace: ns, Text: trimmed, Import: true})
+				continue
+			}
+			if isNamespaceDeclaration(trimmed) {
+				continue
+			}
+			if m := qualified.FindStringSubmatch(text); m != nil {
+				matches = append(matches, Match{File: p, Line: line, Namespace: canonical(m[1], namespaces), Text: trimmed})
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", p, err)
+		}
+	}
+	return matches, nil
+}
+
+// importedNamespace returns the package namespace a line imports, if any. Importing a
+// nested namespace (e.g., Newtonsoft.Json.Linq) counts as importing the package's.
+func importedNamespace(line string, namespaces []string) (string, bool) {
+	for _, pattern := range importPatterns {
+		m := pattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		for _, ns := range namespaces {
+			if strings.EqualFold(m[1], ns) || strings.HasPrefix(strings.ToLower(m[1]), strings.ToLower(ns)+".") {
+				return ns, true
+			}
+		}
+		return "", false
+	}
+	return "", false
+}
+
+// isNamespaceDeclaration reports whether a line declares a namespace, whose name may share
+// a prefix with a package's without using it.
+func isNamespaceDeclaration(line string) bool {
+	for _, keyword := range []string{"namespace ", "module ", "Namespace "} {
+		if strings.HasPrefix(line, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// qualifiedPattern matches a name qualified by one of the namespaces (e.g.,
+// Newtonsoft.Json.JsonConvert), capturing the namespace.
+func qualifiedPattern(namespaces []string) *regexp.Regexp {
+	// Longest first, so the most specific namespace is reported
+	sorted := slices.Clone(namespaces)
+	slices.SortFunc(sorted, func(a, b string) int { return len(b) - len(a) })
+	quoted := make([]string, len(sorted))
+	for i, ns := range sorted {
+		quoted[i] = regexp.QuoteMeta(ns)
+	}
+	return regexp.MustCompile(`(?:^|[^\w.])(` + strings.Join(quoted, "|") + `)\.[A-Za-z_]`)
+}
+
+// canonical returns the namespace as given by the caller.
+func canonical(ns string, namespaces []string) string {
+	for _, candidate := range namespaces {
+		if strings.EqualFold(ns, candidate) {
+			return candidate
+		}
+	}
+	return ns
+}
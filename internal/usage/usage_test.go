@@ -0,0 +1,62 @@
+package usage
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+// TestSearch tests finding imports and qualified names in each source language
+func TestSearch(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Program.cs": {Data: []byte(`using System;
+using Newtonsoft.Json.Linq;
+global using static Newtonsoft.Json.JsonConvert;
+using J = Newtonsoft.Json;
+
+namespace Newtonsoft.JsonHelpers;
+
+// Newtonsoft.Json.JsonConvert is only mentioned in a comment
+var s = Newtonsoft.Json.JsonConvert.SerializeObject(1);
+var t = MyNewtonsoft.Json.Value;
+`)},
+		"Module.fs":                 {Data: []byte("module App.Module\nopen Newtonsoft.Json\n")},
+		"Legacy.vb":                 {Data: []byte("Imports Newtonsoft.Json\n' Imports Newtonsoft.Json\n")},
+		"Pages/_Imports.razor":      {Data: []byte("@using Newtonsoft.Json\n")},
+		"Other.cs":                  {Data: []byte("using System.Text.Json;\n")},
+		"obj/Debug/Generated.cs":    {Data: []byte("using Newtonsoft.Json;\n")},
+		"appsettings.Newtonsoft.cs": {Data: []byte("")},
+		"README.md":                 {Data: []byte("using Newtonsoft.Json;\n")},
+	}
+
+	matches, err := Search(fsys, []string{"Newtonsoft.Json"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	type hit struct {
+		file     string
+		line     int
+		imported bool
+	}
+	want := []hit{
+		{"Legacy.vb", 1, true},
+		{"Module.fs", 2, true},
+		{"Pages/_Imports.razor", 1, true},
+		{"Program.cs", 2, true},
+		{"Program.cs", 3, true},
+		{"Program.cs", 4, true},
+		{"Program.cs", 9, false},
+	}
+	if len(matches) != len(want) {
+		t.Fatalf("Search() = %+v, want %d matches", matches, len(want))
+	}
+	for i, m := range matches {
+		if got := (hit{m.File, m.Line, m.Import}); got != want[i] || m.Namespace != "Newtonsoft.Json" {
+			t.Errorf("match %d = %+v, want %+v", i, m, want[i])
+		}
+	}
+
+	if matches, _ := Search(fsys, nil); matches != nil {
+		t.Errorf("Search() without namespaces = %v, want none", matches)
+	}
+}
@@ -0,0 +1,177 @@
+// Package workloads reports .NET SDK workloads: which are installed and have updates
+// (from `dotnet workload list`), and which a project needs for its platform-specific
+// target frameworks, such as net8.0-android or a .NET MAUI app.
+package workloads
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/project"
+)
+
+// Kinds identify workload reports in versioned JSON output.
+const (
+	ListKind  = "workloads"
+	CheckKind = "workloads-check"
+)
+
+// Markers around the JSON document of `dotnet workload list --machine-readable`.
+const (
+	jsonStart = "==workloadListJsonOutputStart=="
+	jsonEnd   = "==workloadListJsonOutputEnd=="
+)
+
+// Update is an installed workload with a newer manifest available.
+type Update struct {
+	Workload        string `json:"workloadId"`
+	Description     string `json:"description"`
+	ExistingVersion string `json:"existingManifestVersion"`
+	UpdateVersion   string `json:"availableUpdateManifestVersion"`
+}
+
+// Status is the installed workloads and their available updates.
+type Status struct {
+	Installed []string `json:"installed"`
+	Updates   []Update `json:"updateAvailable"`
+}
+
+// Requirement is a workload a project needs for one of its target frameworks.
+type Requirement struct {
+	Project   string `json:"project"`
+	Framework string `json:"framework"`
+	Workload  string `json:"workload"`
+	Installed bool   `json:"installed"`
+}
+
+// platformWorkloads maps target framework platforms to the workload that builds them.
+var platformWorkloads = map[string]string{
+	"android":     "android",
+	"ios":         "ios",
+	"maccatalyst": "maccatalyst",
+	"macos":       "macos",
+	"tvos":        "tvos",
+	"browser":     "wasm-experimental",
+}
+
+// mauiWorkloads maps target framework platforms to the .NET MAUI workload for them.
+var mauiWorkloads = map[string]string{
+	"android":     "maui-android",
+	"ios":         "maui-ios",
+	"maccatalyst": "maui-maccatalyst",
+	"tizen":       "maui-tizen",
+	"windows":     "maui-windows",
+}
+
+// includes lists the workloads that install others; dotnet lists only the workloads
+// installed by name.
+var includes = map[string][]string{
+	"maui":             {"maui-android", "maui-ios", "maui-maccatalyst", "maui-windows", "android", "ios", "maccatalyst"},
+	"maui-mobile":      {"maui-android", "maui-ios", "android", "ios"},
+	"maui-desktop":     {"maui-maccatalyst", "maui-windows", "maccatalyst"},
+	"maui-android":     {"android"},
+	"maui-ios":         {"ios"},
+	"maui-maccatalyst": {"maccatalyst"},
+}
+
+// List runs `dotnet workload list`, which also checks for workload updates.
+func List(ctx context.Context, spawner platform.ProcessSpawner) (*Status, error) {
+	result, err := spawner.RunContext(ctx, "dotnet", []string{"workload", "list", "--machine-readable"}, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("dotnet workload list failed: %s", strings.TrimSpace(result.Stdout+"\n"+result.Stderr))
+	}
+	return Parse(result.Stdout)
+}
+
+// Parse reads the output of `dotnet workload list --machine-readable`: a JSON document
+// between marker lines, among progress and warning messages.
+func Parse(output string) (*Status, error) {
+	_, rest, ok := strings.Cut(output, jsonStart)
+	document, _, ok2 := strings.Cut(rest, jsonEnd)
+	if !ok || !ok2 {
+		return nil, fmt.Errorf("dotnet workload list printed no workload list")
+	}
+	var s Status
+	if err := json.Unmarshal([]byte(document), &s); err != nil {
+		return nil, fmt.Errorf("failed to parse the workload list: %w", err)
+	}
+	if s.Installed == nil {
+		s.Installed = []string{}
+	}
+	if s.Updates == nil {
+		s.Updates = []Update{}
+	}
+	slices.Sort(s.Installed)
+	return &s, nil
+}
+
+// Has reports whether a workload is installed, by name or as part of another.
+func (s *Status) Has(workload string) bool {
+	for _, w := range s.Installed {
+		if strings.EqualFold(w, workload) || slices.Contains(includes[strings.ToLower(w)], strings.ToLower(workload)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Required returns the workloads a project needs, one per target framework that needs one.
+func Required(p *project.Project) []Requirement {
+	var reqs []Requirement
+	for _, tfm := range p.TargetFrameworks {
+		f, err := nuget.ParseFramework(tfm)
+		if err != nil || f.Family != nuget.FamilyNet || f.Platform == "" {
+			continue
+		}
+		workload := platformWorkloads[f.Platform]
+		if p.UseMaui {
+			workload = mauiWorkloads[f.Platform]
+		}
+		if workload != "" {
+			reqs = append(reqs, Requirement{Project: p.Path, Framework: tfm, Workload: workload})
+		}
+	}
+	return reqs
+}
+
+// Check marks which requirements are installed.
+func Check(s *Status, reqs []Requirement) []Requirement {
+	for i := range reqs {
+		reqs[i].Installed = s.Has(reqs[i].Workload)
+	}
+	return reqs
+}
+
+// Missing returns the workloads of the requirements that are not installed, sorted and
+// without duplicates.
+func Missing(reqs []Requirement) []string {
+	var missing []string
+	for _, r := range reqs {
+		if !r.Installed {
+			missing = append(missing, r.Workload)
+		}
+	}
+	slices.Sort(missing)
+	return slices.Compact(missing)
+}
+
+// Install installs workloads with `dotnet workload install`, which may need elevation
+// for SDKs installed machine-wide.
+func Install(ctx context.Context, spawner platform.ProcessSpawner, workloads []string) error {
+	result, err := spawner.RunContext(ctx, "dotnet", append([]string{"workload", "install"}, workloads...), "", nil)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("dotnet workload install failed: %s", strings.TrimSpace(result.Stdout+"\n"+result.Stderr))
+	}
+	return nil
+}
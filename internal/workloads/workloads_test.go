@@ -0,0 +1,95 @@
+package workloads
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/platform"
+	"github.com/willibrandon/lazynuget/internal/project"
+)
+
+// fakeSpawner answers every command with one result and records the commands.
+type fakeSpawner struct {
+	platform.ProcessSpawner
+	calls  []string
+	result platform.ProcessResult
+}
+
+func (f *fakeSpawner) RunContext(_ context.Context, executable string, args []string, _ string, _ map[string]string) (platform.ProcessResult, error) {
+	f.calls = append(f.calls, executable+" "+strings.Join(args, " "))
+	return f.result, nil
+}
+
+// TestList tests reading installed workloads and updates from dotnet
+func TestList(t *testing.T) {
+	spawner := &fakeSpawner{result: platform.ProcessResult{Stdout: `Updating advertising manifests...
+==workloadListJsonOutputStart==
+{"installed":["wasm-tools","maui"],"updateAvailable":[{"existingManifestVersion":"8.0.7","availableUpdateManifestVersion":"8.0.8","description":".NET MAUI SDK","workloadId":"maui"}]}
+==workloadListJsonOutputEnd==
+`}}
+	s, err := List(context.Background(), spawner)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if !slices.Equal(s.Installed, []string{"maui", "wasm-tools"}) {
+		t.Errorf("Installed = %v", s.Installed)
+	}
+	if len(s.Updates) != 1 || s.Updates[0].Workload != "maui" || s.Updates[0].UpdateVersion != "8.0.8" {
+		t.Errorf("Updates = %+v", s.Updates)
+	}
+	if spawner.calls[0] != "dotnet workload list --machine-readable" {
+		t.Errorf("ran %q", spawner.calls[0])
+	}
+
+	if _, err := Parse("Installed Workload Id      Manifest Version\n"); err == nil {
+		t.Error("Parse(table) error = nil")
+	}
+	spawner.result = platform.ProcessResult{ExitCode: 1, Stderr: "Workload manifest not found"}
+	if _, err := List(context.Background(), spawner); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("List() error = %v, want dotnet's message", err)
+	}
+}
+
+// TestRequired tests the workloads target frameworks need and which installed workloads provide them
+func TestRequired(t *testing.T) {
+	app := &project.Project{Path: "App.csproj", UseMaui: true,
+		TargetFrameworks: []string{"net8.0-android", "net8.0-ios", "net8.0-windows10.0.19041.0", "net8.0"}}
+	lib := &project.Project{Path: "Lib.csproj", TargetFrameworks: []string{"net8.0-android34.0", "net8.0-windows", "netstandard2.0"}}
+
+	var got []string
+	for _, r := range append(Required(app), Required(lib)...) {
+		got = append(got, r.Project+":"+r.Framework+":"+r.Workload)
+	}
+	want := []string{
+		"App.csproj:net8.0-android:maui-android",
+		"App.csproj:net8.0-ios:maui-ios",
+		"App.csproj:net8.0-windows10.0.19041.0:maui-windows",
+		"Lib.csproj:net8.0-android34.0:android",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Required() = %v, want %v", got, want)
+	}
+
+	// maui-mobile installs the Android and iOS workloads, but not Windows
+	s := &Status{Installed: []string{"maui-mobile"}}
+	reqs := Check(s, append(Required(app), Required(lib)...))
+	if missing := Missing(reqs); !slices.Equal(missing, []string{"maui-windows"}) {
+		t.Errorf("Missing() = %v, want [maui-windows]", missing)
+	}
+	if s := (&Status{Installed: []string{"MAUI"}}); !s.Has("android") || s.Has("tvos") {
+		t.Error("maui should include android but not tvos")
+	}
+}
+
+// TestInstall tests the dotnet command that installs workloads
+func TestInstall(t *testing.T) {
+	spawner := &fakeSpawner{}
+	if err := Install(context.Background(), spawner, []string{"android", "ios"}); err != nil {
+		t.Fatal(err)
+	}
+	if spawner.calls[0] != "dotnet workload install android ios" {
+		t.Errorf("ran %q", spawner.calls[0])
+	}
+}
@@ -0,0 +1,93 @@
+// Command signchecksums signs a release's checksums.txt for `lazynuget update-self`.
+//
+//	go run ./scripts/signchecksums -keygen          print a new key pair
+//	go run ./scripts/signchecksums dist/checksums.txt
+//
+// The private key is read from UPDATE_SIGNING_KEY (base64 ed25519 seed or private key) and
+// the signature is written next to the file as <file>.sig. When UPDATE_PUBLIC_KEY is set,
+// the signature is checked against it, so a release is never signed with a key the
+// binaries it ships cannot verify.
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	keygen := flag.Bool("keygen", false, "Print a new base64 key pair instead of signing")
+	flag.Parse()
+
+	if err := run(*keygen, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(keygen bool, args []string) error {
+	if keygen {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("UPDATE_PUBLIC_KEY=%s\n", base64.StdEncoding.EncodeToString(pub))
+		fmt.Printf("UPDATE_SIGNING_KEY=%s\n", base64.StdEncoding.EncodeToString(priv.Seed()))
+		return nil
+	}
+	if len(args) != 1 {
+		return errors.New("usage: signchecksums [-keygen] CHECKSUMS_FILE")
+	}
+
+	key, err := signingKey(os.Getenv("UPDATE_SIGNING_KEY"))
+	if err != nil {
+		return err
+	}
+	// #nosec G304 -- the file to sign is named by the release build
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	sig := ed25519.Sign(key, data)
+
+	if encoded := os.Getenv("UPDATE_PUBLIC_KEY"); encoded != "" {
+		pub, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(pub) != ed25519.PublicKeySize {
+			return errors.New("UPDATE_PUBLIC_KEY is not a base64 ed25519 public key")
+		}
+		if !ed25519.Verify(pub, data, sig) {
+			return errors.New("UPDATE_SIGNING_KEY does not match UPDATE_PUBLIC_KEY")
+		}
+	}
+
+	out := args[0] + ".sig"
+	if err := os.WriteFile(out, []byte(base64.StdEncoding.EncodeToString(sig)+"\n"), 0o644); err != nil { // #nosec G306 -- published with the release
+		return err
+	}
+	fmt.Printf("Signed %s: %s\n", args[0], out)
+	return nil
+}
+
+// signingKey decodes a base64 ed25519 seed or private key.
+func signingKey(encoded string) (ed25519.PrivateKey, error) {
+	if encoded == "" {
+		return nil, errors.New("UPDATE_SIGNING_KEY is not set")
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("UPDATE_SIGNING_KEY is not base64: %w", err)
+	}
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), nil
+	default:
+		return nil, fmt.Errorf("UPDATE_SIGNING_KEY has %d bytes, want an ed25519 seed (%d) or private key (%d)",
+			len(raw), ed25519.SeedSize, ed25519.PrivateKeySize)
+	}
+}
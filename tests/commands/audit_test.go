@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/tests/harness"
+)
+
+const apiProject = "src/Api/Api.csproj"
+
+// TestAuditFix verifies the versions audit --fix writes to the project file, and the exit
+// codes of the audit
+func TestAuditFix(t *testing.T) {
+	legacy := `<PackageReference Include="Contoso.Legacy" Version="1.0.0" />`
+	tests := []struct {
+		name       string
+		references []string // Replace the fixture's references when set
+		fix        bool
+		restore    string // LAZYNUGET_FAKE_RESTORE
+		exitCode   int
+		output     string   // Text the output contains
+		want       []string // Lines the project file contains afterwards
+		notWant    []string // Text it does not contain
+	}{
+		{
+			name:     "report without fixing",
+			exitCode: 3,
+			output:   "Run `lazynuget audit --fix` to apply 2 fix(es).",
+			want: []string{
+				`<PackageReference Include="Contoso.Json" Version="1.1.0" />`,
+				`<PackageReference Include="Contoso.Http" Version="1.0.0" />`,
+			},
+			notWant: []string{"Contoso.Crypto"},
+		},
+		{
+			name:     "fix",
+			fix:      true,
+			exitCode: 0,
+			output:   "No vulnerable packages remain.",
+			want: []string{
+				`<PackageReference Include="Contoso.Json" Version="2.0.0" />`,
+				`<PackageReference Include="Contoso.Http" Version="1.0.0" />`,
+				`<PackageReference Include="Contoso.Crypto" Version="1.2.0" />`,
+				"GHSA-c0n7-0s0c-rypt",
+			},
+			notWant: []string{`Version="1.1.0"`},
+		},
+		{
+			name: "fix with a package that has no fix",
+			references: []string{
+				`<PackageReference Include="Contoso.Json" Version="1.0.0" />`,
+				legacy,
+			},
+			fix:      true,
+			exitCode: 3,
+			output:   "Vulnerable packages remain after applying fixes",
+			want:     []string{`<PackageReference Include="Contoso.Json" Version="2.0.0" />`, legacy},
+		},
+		{
+			name:       "no fix can be applied",
+			references: []string{legacy},
+			fix:        true,
+			exitCode:   3,
+			output:     "No fix can be applied automatically.",
+			want:       []string{legacy},
+		},
+		{
+			name:     "restore fails",
+			fix:      true,
+			restore:  "fail",
+			exitCode: 2,
+			output:   "Fixes applied, but dotnet restore failed",
+			want: []string{
+				`<PackageReference Include="Contoso.Json" Version="2.0.0" />`,
+				`<PackageReference Include="Contoso.Crypto" Version="1.2.0" />`,
+			},
+		},
+		{
+			name:       "nothing vulnerable",
+			references: []string{`<PackageReference Include="Contoso.Json" Version="2.1.0" />`},
+			fix:        true,
+			exitCode:   0,
+			want:       []string{`<PackageReference Include="Contoso.Json" Version="2.1.0" />`},
+			notWant:    []string{"Contoso.Crypto"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := harness.New(t, harness.Options{
+				Workspace: "vulnerable",
+				Config:    directConfig,
+				Env:       []string{useFakeDotnet(t), fakeRestoreEnv + "=" + tt.restore},
+				Width:     200,
+			})
+			feed := harness.NewFeed(t, "vulnerable")
+			if tt.references != nil {
+				h.WriteFile(apiProject, projectWith(tt.references))
+				h.WriteFile("src/Api/obj/project.assets.json", `{"version": 3, "targets": {"net8.0": {}}}`)
+			}
+			original := h.ReadFile(apiProject)
+
+			args := []string{"audit", "--source", feed.IndexURL}
+			if tt.fix {
+				args = append(args, "--fix")
+			}
+			frame := h.Run(append(args, apiProject)...)
+			if frame.ExitCode != tt.exitCode {
+				t.Errorf("lazynuget %s: exit %d, want %d\n%s", strings.Join(args, " "), frame.ExitCode, tt.exitCode, frame)
+			}
+			if tt.output != "" && !frame.Contains(tt.output) {
+				t.Errorf("output does not contain %q:\n%s", tt.output, frame)
+			}
+
+			got := h.ReadFile(apiProject)
+			for _, line := range tt.want {
+				if !strings.Contains(got, line) {
+					t.Errorf("%s does not contain %s:\n%s", apiProject, line, got)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("%s contains %s:\n%s", apiProject, s, got)
+				}
+			}
+			if !tt.fix && got != original {
+				t.Errorf("%s changed without --fix:\n%s", apiProject, got)
+			}
+		})
+	}
+}
+
+// projectWith returns a project file referencing packages.
+func projectWith(references []string) string {
+	return `<Project Sdk="Microsoft.NET.Sdk">
+
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+
+  <ItemGroup>
+    ` + strings.Join(references, "\n    ") + `
+  </ItemGroup>
+
+</Project>
+`
+}
@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/tests/harness"
+)
+
+const appProject = "src/App/App.csproj"
+
+// TestBulk verifies the references add, remove, and update write to the project file,
+// and their exit codes
+func TestBulk(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		exitCode int
+		want     []string // Lines the project file contains afterwards
+		notWant  []string // Lines it no longer contains
+	}{
+		{
+			name:     "add at a version",
+			args:     []string{"add", "Polly@8.0.0"},
+			exitCode: 0,
+			want:     []string{`<PackageReference Include="Polly" Version="8.0.0" />`},
+		},
+		{
+			name:     "add the latest version",
+			args:     []string{"add", "Polly"},
+			exitCode: 0,
+			want:     []string{`<PackageReference Include="Polly" Version="8.4.0" />`},
+		},
+		{
+			name:     "add a referenced package moves it",
+			args:     []string{"add", "Newtonsoft.Json@13.0.1"},
+			exitCode: 0,
+			want:     []string{`<PackageReference Include="Newtonsoft.Json" Version="13.0.1" />`},
+			notWant:  []string{`Version="12.0.1"`},
+		},
+		{
+			name:     "add a package the feed does not have",
+			args:     []string{"add", "Missing.Package"},
+			exitCode: 1,
+			notWant:  []string{"Missing.Package"},
+		},
+		{
+			name:     "add with one package missing",
+			args:     []string{"add", "Polly@8.4.0", "Missing.Package"},
+			exitCode: 4,
+			want:     []string{`<PackageReference Include="Polly" Version="8.4.0" />`},
+			notWant:  []string{"Missing.Package"},
+		},
+		{
+			name:     "update to the latest release",
+			args:     []string{"update", "Newtonsoft.Json"},
+			exitCode: 0,
+			want:     []string{`<PackageReference Include="Newtonsoft.Json" Version="13.0.3" />`},
+		},
+		{
+			name:     "update to a prerelease",
+			args:     []string{"update", "--prerelease", "Newtonsoft.Json"},
+			exitCode: 0,
+			want:     []string{`<PackageReference Include="Newtonsoft.Json" Version="14.0.1-beta1" />`},
+		},
+		{
+			name:     "update keeps other attributes",
+			args:     []string{"update", "StyleCop.Analyzers@1.2.0-beta.556"},
+			exitCode: 0,
+			want:     []string{`<PackageReference Include="StyleCop.Analyzers" Version="1.2.0-beta.556" PrivateAssets="all" />`},
+		},
+		{
+			name:     "update skips an unreferenced package",
+			args:     []string{"update", "Polly"},
+			exitCode: 1,
+			notWant:  []string{"Polly"},
+		},
+		{
+			name:     "update with one package unreferenced",
+			args:     []string{"update", "Serilog@4.0.0", "Polly"},
+			exitCode: 4,
+			want:     []string{`<PackageReference Include="Serilog" Version="4.0.0" />`},
+			notWant:  []string{"Polly"},
+		},
+		{
+			name:     "remove",
+			args:     []string{"remove", "StyleCop.Analyzers", "Serilog"},
+			exitCode: 0,
+			want:     []string{`<PackageReference Include="Newtonsoft.Json" Version="12.0.1" />`},
+			notWant:  []string{"StyleCop.Analyzers", "Serilog"},
+		},
+		{
+			name:     "remove an unreferenced package",
+			args:     []string{"remove", "Polly"},
+			exitCode: 0,
+			want:     []string{`<PackageReference Include="Serilog" Version="3.*" />`},
+		},
+		{
+			name:     "remove with a version",
+			args:     []string{"remove", "Serilog@3.1.1"},
+			exitCode: 1,
+			want:     []string{`<PackageReference Include="Serilog" Version="3.*" />`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkspace(t)
+			feed := harness.NewFeed(t, "basic")
+			h.UseFeed(feed)
+			original := h.ReadFile(appProject)
+
+			args := []string{tt.args[0], "--yes", "--project", appProject}
+			if tt.args[0] != "remove" {
+				args = append(args, "--source", feed.URL)
+			}
+			args = append(args, tt.args[1:]...)
+			frame := h.Run(args...)
+			if frame.ExitCode != tt.exitCode {
+				t.Errorf("lazynuget %s: exit %d, want %d\n%s", strings.Join(tt.args, " "), frame.ExitCode, tt.exitCode, frame)
+			}
+
+			got := h.ReadFile(appProject)
+			for _, line := range tt.want {
+				if !strings.Contains(got, line) {
+					t.Errorf("%s does not contain %s:\n%s", appProject, line, got)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("%s contains %s:\n%s", appProject, s, got)
+				}
+			}
+			if len(tt.want) == 0 && got != original {
+				t.Errorf("%s changed:\n%s", appProject, got)
+			}
+			if lib := h.ReadFile("src/Lib/Lib.csproj"); !strings.Contains(lib, `<PackageReference Include="Polly" Version="[7.0,8.0)" />`) {
+				t.Errorf("src/Lib/Lib.csproj changed:\n%s", lib)
+			}
+		})
+	}
+}
+
+// TestBulkFeedError verifies that nothing changes when the feed cannot be reached
+func TestBulkFeedError(t *testing.T) {
+	h := newWorkspace(t)
+	original := h.ReadFile(appProject)
+	frame := h.Run("update", "--yes", "--project", appProject, "--source", "http://127.0.0.1:1/v3-flatcontainer/", "Newtonsoft.Json", "Serilog")
+	if frame.ExitCode != 2 {
+		t.Errorf("exit %d, want 2\n%s", frame.ExitCode, frame)
+	}
+	if got := h.ReadFile(appProject); got != original {
+		t.Errorf("%s changed:\n%s", appProject, got)
+	}
+}
+
+// TestBulkStdin verifies that specs are read from stdin one per line
+func TestBulkStdin(t *testing.T) {
+	h := newWorkspace(t)
+	feed := harness.NewFeed(t, "basic")
+	frame := h.RunInput("Newtonsoft.Json@13.0.2\n\nSerilog@4.0.0\n", "update", "--yes", "--project", appProject, "--source", feed.URL)
+	if frame.ExitCode != 0 {
+		t.Fatalf("exit %d, want 0\n%s", frame.ExitCode, frame)
+	}
+	got := h.ReadFile(appProject)
+	for _, line := range []string{
+		`<PackageReference Include="Newtonsoft.Json" Version="13.0.2" />`,
+		`<PackageReference Include="Serilog" Version="4.0.0" />`,
+	} {
+		if !strings.Contains(got, line) {
+			t.Errorf("%s does not contain %s:\n%s", appProject, line, got)
+		}
+	}
+}
+
+// TestBulkProject verifies that a workspace with several projects needs --project
+func TestBulkProject(t *testing.T) {
+	h := newWorkspace(t)
+	feed := harness.NewFeed(t, "basic")
+	original := h.ReadFile(appProject)
+	frame := h.Run("add", "--yes", "--source", feed.URL, "Polly@8.4.0")
+	if frame.ExitCode != 1 || !frame.Contains("pass one with --project") {
+		t.Errorf("frame:\n%s\nwant exit 1 asking for --project", frame)
+	}
+	if got := h.ReadFile(appProject); got != original {
+		t.Errorf("%s changed:\n%s", appProject, got)
+	}
+}
@@ -0,0 +1,27 @@
+// Package commands tests commands that change the workspace: the files they write and
+// the exit codes they return, run in a copy of a fixture workspace against the fake feed.
+package commands
+
+import (
+	"os"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/tests/harness"
+)
+
+// directConfig edits project files directly and leaves restore to the user, so that
+// commands change only the files under test and do not need the dotnet CLI.
+const directConfig = "operationBackend: direct\nrestoreMode: manual\n"
+
+func TestMain(m *testing.M) {
+	if isFakeDotnet() {
+		os.Exit(fakeDotnet(os.Args[1:]))
+	}
+	os.Exit(harness.Main(m))
+}
+
+// newWorkspace returns a harness on a copy of the basic workspace, editing files directly.
+func newWorkspace(t *testing.T) *harness.Harness {
+	t.Helper()
+	return harness.New(t, harness.Options{Workspace: "basic", Config: directConfig})
+}
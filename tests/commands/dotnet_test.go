@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/nuget"
+	"github.com/willibrandon/lazynuget/internal/resolver"
+	"github.com/willibrandon/lazynuget/tests/harness"
+)
+
+// fakeRestoreEnv makes restore fail in the fake dotnet CLI when set to "fail".
+const fakeRestoreEnv = "LAZYNUGET_FAKE_RESTORE"
+
+// isFakeDotnet reports whether the test binary was run as the fake dotnet CLI.
+func isFakeDotnet() bool {
+	return strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe") == "dotnet"
+}
+
+// useFakeDotnet puts the fake dotnet CLI, a copy of the test binary, first on the PATH
+// lazynuget runs with, and returns the environment variable that does it.
+func useFakeDotnet(t *testing.T) string {
+	t.Helper()
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	name := "dotnet"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	src, err := os.Open(executable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_WRONLY, 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return "PATH=" + dir + string(os.PathListSeparator) + os.Getenv("PATH")
+}
+
+// fakeDotnet is the dotnet CLI of the audit tests. list package --vulnerable reports the
+// packages a project references, and the other packages of its assets file, that the
+// advisories of the vulnerable feed fixtures affect; restore succeeds without doing
+// anything, or fails with LAZYNUGET_FAKE_RESTORE=fail.
+func fakeDotnet(args []string) int {
+	switch {
+	case len(args) > 0 && args[0] == "--version":
+		fmt.Println("8.0.100")
+		return 0
+	case len(args) > 0 && args[0] == "restore":
+		if os.Getenv(fakeRestoreEnv) == "fail" {
+			fmt.Fprintln(os.Stderr, "error NU1301: Unable to load the service index")
+			return 1
+		}
+		return 0
+	case len(args) > 2 && args[0] == "list" && slices.Contains(args, "--vulnerable"):
+		if err := fakeListVulnerable(args[1]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+	fmt.Fprintf(os.Stderr, "fake dotnet: unexpected arguments %q\n", args)
+	return 1
+}
+
+// packageReference matches a package reference with its version.
+var packageReference = regexp.MustCompile(`<PackageReference\s+Include="([^"]+)"\s+Version="([^"]+)"`)
+
+// listPackage is a package in the JSON output of dotnet list package.
+type listPackage struct {
+	ID              string         `json:"id"`
+	ResolvedVersion string         `json:"resolvedVersion"`
+	Vulnerabilities []listAdvisory `json:"vulnerabilities"`
+}
+
+// listAdvisory is an advisory of a package in the JSON output of dotnet list package.
+type listAdvisory struct {
+	Severity    string `json:"severity"`
+	AdvisoryURL string `json:"advisoryurl"`
+}
+
+// fakeListVulnerable writes the vulnerable packages of a project as dotnet list package
+// --vulnerable --include-transitive --format json does.
+func fakeListVulnerable(project string) error {
+	data, err := os.ReadFile(project)
+	if err != nil {
+		return err
+	}
+	advisories, err := fixtureAdvisories()
+	if err != nil {
+		return err
+	}
+	vulnerable := func(id, version string) []listAdvisory {
+		var found []listAdvisory
+		for _, a := range advisories[strings.ToLower(id)] {
+			if r, err := nuget.ParseVersionRange(a.Versions); err == nil && r.WithinBounds(version) {
+				found = append(found, listAdvisory{Severity: []string{"Low", "Moderate", "High", "Critical"}[a.Severity], AdvisoryURL: a.URL})
+			}
+		}
+		return found
+	}
+
+	topLevel, transitive := []listPackage{}, []listPackage{}
+	direct := make(map[string]bool)
+	for _, m := range packageReference.FindAllStringSubmatch(string(data), -1) {
+		direct[strings.ToLower(m[1])] = true
+		if v := vulnerable(m[1], m[2]); len(v) > 0 {
+			topLevel = append(topLevel, listPackage{ID: m[1], ResolvedVersion: m[2], Vulnerabilities: v})
+		}
+	}
+	if assets, err := resolver.LoadAssets(resolver.AssetsPath(project)); err == nil {
+		for _, p := range assets.Packages() {
+			if v := vulnerable(p.ID, p.Version); len(v) > 0 && !direct[strings.ToLower(p.ID)] {
+				transitive = append(transitive, listPackage{ID: p.ID, ResolvedVersion: p.Version, Vulnerabilities: v})
+			}
+		}
+	}
+
+	path, err := filepath.Abs(project)
+	if err != nil {
+		return err
+	}
+	report := map[string]any{
+		"version": 1,
+		"projects": []map[string]any{{
+			"path": path,
+			"frameworks": []map[string]any{{
+				"framework":          "net8.0",
+				"topLevelPackages":   topLevel,
+				"transitivePackages": transitive,
+			}},
+		}},
+	}
+	return json.NewEncoder(os.Stdout).Encode(report)
+}
+
+// fixtureAdvisory is an advisory in the vulnerability index of a feed fixture.
+type fixtureAdvisory struct {
+	URL      string `json:"url"`
+	Severity int    `json:"severity"`
+	Versions string `json:"versions"`
+}
+
+// fixtureAdvisories returns the advisories of the vulnerable feed fixtures, by lowercase
+// package ID.
+func fixtureAdvisories() (map[string][]fixtureAdvisory, error) {
+	data, err := os.ReadFile(filepath.Join(harness.FixturesDir(), "nuget", "vulnerable", "vulnerabilities.json"))
+	if err != nil {
+		return nil, err
+	}
+	var advisories map[string][]fixtureAdvisory
+	return advisories, json.Unmarshal(data, &advisories)
+}
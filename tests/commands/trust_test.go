@@ -0,0 +1,225 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/tests/harness"
+)
+
+const (
+	fingerprint      = "3F9001EA83C560D712C24CF213C3D312CB3BFF51EE89435D3430BD06B5D0EECE"
+	otherFingerprint = "0E5F38F57DC1BCC806D8494F4F90FBCEDD988B46760709CBEEC6F4219AA6157D"
+)
+
+// nugetConfig is the workspace's nuget.config before each test.
+const nugetConfig = `<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <packageSources>
+    <add key="nuget.org" value="https://api.nuget.org/v3/index.json" />
+  </packageSources>
+</configuration>
+`
+
+// TestTrust verifies what trust add, remove, and mode write to the nearest nuget.config,
+// and their exit codes
+func TestTrust(t *testing.T) {
+	tests := []struct {
+		name     string
+		runs     [][]string // Commands run in order; the exit code is the last one's
+		exitCode int
+		want     []string // Lines nuget.config contains afterwards
+		notWant  []string // Text it does not contain
+	}{
+		{
+			name:     "add an author",
+			runs:     [][]string{{"trust", "add", "--fingerprint", strings.ToLower(fingerprint), "author", "microsoft"}},
+			exitCode: 0,
+			want: []string{
+				`<author name="microsoft">`,
+				`<certificate fingerprint="` + fingerprint + `" hashAlgorithm="SHA256" allowUntrustedRoot="false" />`,
+				`<add key="nuget.org" value="https://api.nuget.org/v3/index.json" />`,
+			},
+		},
+		{
+			name: "add a second certificate",
+			runs: [][]string{
+				{"trust", "add", "--fingerprint", fingerprint, "author", "microsoft"},
+				{"trust", "add", "--fingerprint", otherFingerprint, "--hash-algorithm", "sha384", "--allow-untrusted-root", "author", "microsoft"},
+			},
+			exitCode: 0,
+			want: []string{
+				`<certificate fingerprint="` + fingerprint + `" hashAlgorithm="SHA256" allowUntrustedRoot="false" />`,
+				`<certificate fingerprint="` + otherFingerprint + `" hashAlgorithm="SHA384" allowUntrustedRoot="true" />`,
+			},
+		},
+		{
+			name:     "add a repository",
+			runs:     [][]string{{"trust", "add", "--fingerprint", fingerprint, "--service-index", "https://api.nuget.org/v3/index.json", "--owners", "jamesnk; microsoft", "repository", "nuget.org"}},
+			exitCode: 0,
+			want: []string{
+				`<repository name="nuget.org" serviceIndex="https://api.nuget.org/v3/index.json">`,
+				`<owners>jamesnk;microsoft</owners>`,
+			},
+		},
+		{
+			name:     "add a repository without a service index",
+			runs:     [][]string{{"trust", "add", "--fingerprint", fingerprint, "repository", "nuget.org"}},
+			exitCode: 1,
+			notWant:  []string{"trustedSigners"},
+		},
+		{
+			name:     "add owners to an author",
+			runs:     [][]string{{"trust", "add", "--fingerprint", fingerprint, "--owners", "microsoft", "author", "microsoft"}},
+			exitCode: 1,
+			notWant:  []string{"trustedSigners"},
+		},
+		{
+			name:     "add an unknown kind",
+			runs:     [][]string{{"trust", "add", "--fingerprint", fingerprint, "publisher", "microsoft"}},
+			exitCode: 1,
+			notWant:  []string{"trustedSigners"},
+		},
+		{
+			name:     "add without a certificate",
+			runs:     [][]string{{"trust", "add", "author", "microsoft"}},
+			exitCode: 1,
+			notWant:  []string{"trustedSigners"},
+		},
+		{
+			name: "remove",
+			runs: [][]string{
+				{"trust", "add", "--fingerprint", fingerprint, "author", "microsoft"},
+				{"trust", "add", "--fingerprint", otherFingerprint, "author", "contoso"},
+				{"trust", "remove", "microsoft"},
+			},
+			exitCode: 0,
+			want:     []string{`<author name="contoso">`},
+			notWant:  []string{"microsoft", fingerprint},
+		},
+		{
+			name:     "remove a signer the file does not list",
+			runs:     [][]string{{"trust", "remove", "microsoft"}},
+			exitCode: 0,
+			notWant:  []string{"trustedSigners"},
+		},
+		{
+			name: "mode",
+			runs: [][]string{
+				{"trust", "mode", "accept"},
+				{"trust", "mode", "Require"},
+			},
+			exitCode: 0,
+			want:     []string{`<add key="signatureValidationMode" value="require" />`},
+			notWant:  []string{`value="accept"`},
+		},
+		{
+			name:     "unknown mode",
+			runs:     [][]string{{"trust", "mode", "strict"}},
+			exitCode: 1,
+			notWant:  []string{"signatureValidationMode"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkspace(t)
+			h.WriteFile("nuget.config", nugetConfig)
+
+			var frame harness.Frame
+			for _, args := range tt.runs {
+				frame = h.Run(args...)
+			}
+			if frame.ExitCode != tt.exitCode {
+				t.Errorf("lazynuget %s: exit %d, want %d\n%s", strings.Join(tt.runs[len(tt.runs)-1], " "), frame.ExitCode, tt.exitCode, frame)
+			}
+
+			got := h.ReadFile("nuget.config")
+			for _, line := range tt.want {
+				if !strings.Contains(got, line) {
+					t.Errorf("nuget.config does not contain %s:\n%s", line, got)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("nuget.config contains %s:\n%s", s, got)
+				}
+			}
+		})
+	}
+}
+
+// TestTrustConfigFlag verifies that --nuget-config names the file to change
+func TestTrustConfigFlag(t *testing.T) {
+	h := newWorkspace(t)
+	h.WriteFile("nuget.config", nugetConfig)
+	frame := h.Run("trust", "mode", "--nuget-config", "src/App/nuget.config", "require")
+	if frame.ExitCode != 0 {
+		t.Fatalf("exit %d, want 0\n%s", frame.ExitCode, frame)
+	}
+	if got := h.ReadFile("src/App/nuget.config"); !strings.Contains(got, `<add key="signatureValidationMode" value="require" />`) {
+		t.Errorf("src/App/nuget.config:\n%s", got)
+	}
+	if got := h.ReadFile("nuget.config"); got != nugetConfig {
+		t.Errorf("nuget.config changed:\n%s", got)
+	}
+}
+
+// assetsJSON is an obj/project.assets.json resolving a signed and an unsigned package,
+// and one that is not in the global packages folder.
+const assetsJSON = `{
+  "version": 3,
+  "targets": {
+    "net8.0": {
+      "Contoso.Core/1.0.0": {"type": "package"},
+      "Fabrikam.Utils/2.0.0": {"type": "package"},
+      "Missing.Package/1.0.0": {"type": "package"}
+    }
+  },
+  "project": {"restore": {"projectName": "App"}}
+}
+`
+
+// TestTrustCheck verifies that trust check reports whose certificate signed each restored
+// package, and fails in require mode when a trusted signer's certificate does not match
+func TestTrustCheck(t *testing.T) {
+	h := harness.New(t, harness.Options{Workspace: "basic", Config: directConfig, Packages: "signed", Width: 160})
+	h.WriteFile("nuget.config", nugetConfig)
+	h.WriteFile("src/App/obj/project.assets.json", assetsJSON)
+
+	frame := h.Run("trust", "add", "--package", "Contoso.Core@1.0.0", "author", "contoso")
+	if frame.ExitCode != 0 || !frame.Contains("Signed by Contoso") {
+		t.Fatalf("trust add --package: frame:\n%s\nwant exit 0 and the signer", frame)
+	}
+	if frame := h.Run("trust", "add", "--package", "Fabrikam.Utils@2.0.0", "author", "fabrikam"); frame.ExitCode != 1 || !frame.Contains("has no author signature") {
+		t.Errorf("trust add --package of an unsigned package: frame:\n%s\nwant exit 1", frame)
+	}
+	if frame := h.Run("trust", "add", "--package", "Contoso.Core@9.0.0", "author", "contoso"); frame.ExitCode != 1 || !frame.Contains("is not in the global packages folder") {
+		t.Errorf("trust add --package of a package not restored: frame:\n%s\nwant exit 1", frame)
+	}
+
+	frame = h.Run("trust", "check", appProject)
+	if frame.ExitCode != 0 {
+		t.Errorf("trust check (accept): exit %d, want 0\n%s", frame.ExitCode, frame)
+	}
+	for _, want := range []string{"signer certificate matches (contoso)", "Fabrikam.Utils", "allowed", "unknown"} {
+		if !frame.Contains(want) {
+			t.Errorf("trust check (accept) does not show %q:\n%s", want, frame)
+		}
+	}
+
+	h.Run("trust", "mode", "require")
+	frame = h.Run("trust", "check", appProject)
+	if frame.ExitCode != 3 {
+		t.Errorf("trust check (require): exit %d, want 3\n%s", frame.ExitCode, frame)
+	}
+	for _, want := range []string{"signer certificate matches (contoso)", "NO MATCHING SIGNER", "1 packages were not signed with a trusted signer's certificate"} {
+		if !frame.Contains(want) {
+			t.Errorf("trust check (require) does not show %q:\n%s", want, frame)
+		}
+	}
+
+	h.Run("trust", "remove", "contoso")
+	if frame := h.Run("trust", "check", appProject); frame.ExitCode != 3 || frame.Contains("signer certificate matches") {
+		t.Errorf("trust check without trusted signers: frame:\n%s\nwant exit 3 and no match", frame)
+	}
+}
@@ -0,0 +1,87 @@
+package harness
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Feed is a fake NuGet feed serving fixtures from tests/fixtures/nuget/<name>:
+//
+//	<id>/index.json           the version index of a package (lowercase ID)
+//	vulnerabilities.json      the advisories of packages, by lowercase ID (optional)
+//
+// Packages without fixtures are not found. URL is the package base address (a V3 flat
+// container), as passed to --source; IndexURL is the service index, which also lists the
+// vulnerability index when the fixtures have advisories.
+type Feed struct {
+	server   *httptest.Server
+	URL      string
+	IndexURL string
+	requests []string
+	mu       sync.Mutex
+}
+
+// NewFeed starts a fake feed serving the fixture set name. It stops when the test ends.
+func NewFeed(t *testing.T, name string) *Feed {
+	t.Helper()
+	dir := filepath.Join(FixturesDir(), "nuget", name)
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("harness: no feed fixtures %s: %v", name, err)
+	}
+
+	f := &Feed{}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.requests = append(f.requests, r.URL.Path)
+		f.mu.Unlock()
+
+		var data []byte
+		var err error
+		switch p := path.Clean(r.URL.Path); {
+		case p == "/v3/index.json":
+			data = f.serviceIndex(dir)
+		case p == "/v3/vulnerabilities/index.json":
+			data = []byte(`[{"@id": "` + f.server.URL + `/v3/vulnerabilities/base.json", "@type": "base"}]`)
+		case p == "/v3/vulnerabilities/base.json":
+			data, err = os.ReadFile(filepath.Join(dir, "vulnerabilities.json"))
+		case strings.HasPrefix(p, "/v3-flatcontainer/") && strings.HasSuffix(p, "/index.json"):
+			data, err = os.ReadFile(filepath.Join(dir, strings.ToLower(path.Base(path.Dir(p))), "index.json"))
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(f.server.Close)
+	f.URL = f.server.URL + "/v3-flatcontainer/"
+	f.IndexURL = f.server.URL + "/v3/index.json"
+	return f
+}
+
+// serviceIndex returns the service index of the feed: the package base address and,
+// when the fixtures in dir have advisories, the vulnerability index.
+func (f *Feed) serviceIndex(dir string) []byte {
+	resources := `{"@id": "` + f.URL + `", "@type": "PackageBaseAddress/3.0.0"}`
+	if _, err := os.Stat(filepath.Join(dir, "vulnerabilities.json")); err == nil {
+		resources += `, {"@id": "` + f.server.URL + `/v3/vulnerabilities/index.json", "@type": "VulnerabilityInfo/6.7.0"}`
+	}
+	return []byte(`{"version": "3.0.0", "resources": [` + resources + `]}`)
+}
+
+// Requests returns the paths requested so far.
+func (f *Feed) Requests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.requests...)
+}
@@ -0,0 +1,117 @@
+package harness
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Frame is the output of a command as a terminal of a given width shows it: tabs expanded,
+// long lines wrapped, trailing spaces and carriage returns dropped.
+type Frame struct {
+	Lines    []string
+	Width    int
+	ExitCode int
+}
+
+// NewFrame lays out text on a terminal width columns wide.
+func NewFrame(text string, width, exitCode int) Frame {
+	f := Frame{Width: width, ExitCode: exitCode}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		line = strings.TrimRight(expandTabs(line), " \r")
+		for utf8.RuneCountInString(line) > width {
+			cut := byteOffset(line, width)
+			f.Lines = append(f.Lines, strings.TrimRight(line[:cut], " "))
+			line = line[cut:]
+		}
+		f.Lines = append(f.Lines, line)
+	}
+	if len(f.Lines) == 1 && f.Lines[0] == "" {
+		f.Lines = nil
+	}
+	return f
+}
+
+// String renders the frame with a ruler marking the terminal width and the exit code, as
+// stored in golden files.
+func (f Frame) String() string {
+	var sb strings.Builder
+	ruler := "+" + strings.Repeat("-", f.Width) + "+"
+	sb.WriteString(ruler + "\n")
+	for _, line := range f.Lines {
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString(ruler + "\n")
+	fmt.Fprintf(&sb, "exit %d\n", f.ExitCode)
+	return sb.String()
+}
+
+// Contains reports whether any line contains s.
+func (f Frame) Contains(s string) bool {
+	for _, line := range f.Lines {
+		if strings.Contains(line, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Golden compares the frame with testdata/<name>.golden, or rewrites the file with -update.
+func Golden(t *testing.T, name string, f Frame) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	got := f.String()
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("missing golden file (run go test with -update to create it): %v", err)
+	}
+	if got != strings.ReplaceAll(string(want), "\r\n", "\n") {
+		t.Errorf("frame differs from %s (run go test with -update to accept it)\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// expandTabs replaces tabs with spaces up to the next multiple of 8 columns.
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var sb strings.Builder
+	col := 0
+	for _, r := range line {
+		if r == '\t' {
+			n := 8 - col%8
+			sb.WriteString(strings.Repeat(" ", n))
+			col += n
+			continue
+		}
+		sb.WriteRune(r)
+		col++
+	}
+	return sb.String()
+}
+
+// byteOffset returns the byte offset of the rune at index n.
+func byteOffset(s string, n int) int {
+	for i := range s {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return len(s)
+}
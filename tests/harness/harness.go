@@ -0,0 +1,237 @@
+// Package harness drives the lazynuget binary headlessly for render tests. Each test runs
+// commands in a copy of a fixture workspace, on a fake terminal of fixed size, with an
+// isolated home, config, and cache, and against a fake NuGet feed, then compares the
+// rendered frames with golden files in the test package's testdata directory.
+//
+// Rendering is deterministic: colors are off (NO_COLOR, TERM=dumb), and the workspace path
+// and feed URL are replaced with $WORKSPACE and $FEED in frames.
+//
+// A test package using the harness builds the binary once in TestMain:
+//
+//	func TestMain(m *testing.M) { os.Exit(harness.Main(m)) }
+//
+// Run `go test ./tests/... -update` to rewrite golden files after an intended change, and
+// review the diff like any other change.
+package harness
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the current output")
+
+// binary is the lazynuget binary built by Main.
+var binary string
+
+// Main builds the lazynuget binary, runs the tests, and removes the binary. It returns
+// the exit code for os.Exit.
+func Main(m *testing.M) int {
+	dir, err := os.MkdirTemp("", "lazynuget-harness")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "harness: %v\n", err)
+		return 2
+	}
+	defer os.RemoveAll(dir)
+
+	binary = filepath.Join(dir, "lazynuget")
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	build := exec.Command("go", "build", "-o", binary, "github.com/willibrandon/lazynuget/cmd/lazynuget")
+	if output, err := build.CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "harness: failed to build lazynuget: %v\n%s", err, output)
+		return 2
+	}
+	return m.Run()
+}
+
+// Options configure a harness.
+type Options struct {
+	Workspace string   // Fixture directory under tests/fixtures/workspaces; empty for an empty workspace
+	Packages  string   // Fixture directory under tests/fixtures/packages copied as the global packages folder
+	Config    string   // Content of the user config.yml
+	Env       []string // Additional environment variables (KEY=value)
+	Width     int      // Terminal columns (default 80)
+	Height    int      // Terminal rows (default 24)
+}
+
+// Harness runs lazynuget in an isolated environment.
+type Harness struct {
+	t         *testing.T
+	env       []string
+	feedURL   string
+	Workspace string // The workspace copy commands run in
+	Width     int
+	Height    int
+}
+
+// New returns a harness with a fresh copy of the workspace fixture.
+func New(t *testing.T, opts Options) *Harness {
+	t.Helper()
+	if binary == "" {
+		t.Fatal("harness: the binary is not built; call harness.Main from TestMain")
+	}
+	if opts.Width == 0 {
+		opts.Width = 80
+	}
+	if opts.Height == 0 {
+		opts.Height = 24
+	}
+
+	home := t.TempDir()
+	workspace := filepath.Join(t.TempDir(), "workspace")
+	if opts.Workspace != "" {
+		if err := copyDir(filepath.Join(FixturesDir(), "workspaces", opts.Workspace), workspace); err != nil {
+			t.Fatalf("harness: failed to copy workspace %s: %v", opts.Workspace, err)
+		}
+	}
+	// Mark the copy as a repository so commands do not look above it for the workspace root
+	if err := os.MkdirAll(filepath.Join(workspace, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	packagesDir := filepath.Join(home, "packages")
+	if opts.Packages != "" {
+		if err := copyDir(filepath.Join(FixturesDir(), "packages", opts.Packages), packagesDir); err != nil {
+			t.Fatalf("harness: failed to copy packages %s: %v", opts.Packages, err)
+		}
+	}
+
+	configDir := filepath.Join(home, "config")
+	if opts.Config != "" {
+		if err := os.MkdirAll(filepath.Join(configDir, "lazynuget"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(configDir, "lazynuget", "config.yml"), []byte(opts.Config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	env := []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + home,
+		"USERPROFILE=" + home,
+		"APPDATA=" + configDir,
+		"LOCALAPPDATA=" + filepath.Join(home, "cache"),
+		"XDG_CONFIG_HOME=" + configDir,
+		"XDG_CACHE_HOME=" + filepath.Join(home, "cache"),
+		"XDG_DATA_HOME=" + filepath.Join(home, "data"),
+		"XDG_STATE_HOME=" + filepath.Join(home, "state"),
+		"NUGET_PACKAGES=" + packagesDir,
+		"LAZYNUGET_NO_TELEMETRY=1",
+		"NO_COLOR=1",
+		"TERM=dumb",
+		fmt.Sprintf("COLUMNS=%d", opts.Width),
+		fmt.Sprintf("LINES=%d", opts.Height),
+	}
+	if runtime.GOOS == "windows" {
+		env = append(env, "SystemRoot="+os.Getenv("SystemRoot"))
+	}
+
+	return &Harness{
+		t:         t,
+		env:       append(env, opts.Env...),
+		Workspace: workspace,
+		Width:     opts.Width,
+		Height:    opts.Height,
+	}
+}
+
+// UseFeed makes $FEED stand for the feed's URL in frames.
+func (h *Harness) UseFeed(feed *Feed) {
+	h.feedURL = feed.URL
+}
+
+// Run runs lazynuget with args in the workspace and returns what it rendered. Output on
+// stdout and stderr is interleaved in the frame as a terminal would show it.
+func (h *Harness) Run(args ...string) Frame {
+	h.t.Helper()
+	return h.RunInput("", args...)
+}
+
+// RunInput is like Run, with input on stdin.
+func (h *Harness) RunInput(input string, args ...string) Frame {
+	h.t.Helper()
+	// #nosec G204 -- the binary was built by Main, args come from the test
+	cmd := exec.Command(binary, args...)
+	cmd.Dir = h.Workspace
+	cmd.Env = h.env
+	cmd.Stdin = strings.NewReader(input)
+	output, err := cmd.CombinedOutput()
+
+	exitCode := 0
+	if err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			h.t.Fatalf("harness: failed to run lazynuget %s: %v", strings.Join(args, " "), err)
+		}
+		exitCode = exitErr.ExitCode()
+	}
+
+	text := string(output)
+	text = strings.ReplaceAll(text, h.Workspace, "$WORKSPACE")
+	if h.feedURL != "" {
+		text = strings.ReplaceAll(text, h.feedURL, "$FEED")
+	}
+	return NewFrame(text, h.Width, exitCode)
+}
+
+// ReadFile returns the content of a file in the workspace, given by its slash-separated
+// path relative to the workspace, with line endings normalized.
+func (h *Harness) ReadFile(name string) string {
+	h.t.Helper()
+	data, err := os.ReadFile(filepath.Join(h.Workspace, filepath.FromSlash(name)))
+	if err != nil {
+		h.t.Fatalf("harness: %v", err)
+	}
+	return strings.ReplaceAll(string(data), "\r\n", "\n")
+}
+
+// WriteFile writes a file in the workspace, given by its slash-separated path relative to
+// the workspace, creating its directory.
+func (h *Harness) WriteFile(name, content string) {
+	h.t.Helper()
+	path := filepath.Join(h.Workspace, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		h.t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		h.t.Fatal(err)
+	}
+}
+
+// FixturesDir returns the tests/fixtures directory.
+func FixturesDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "fixtures")
+}
+
+// copyDir copies a directory tree.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0o644)
+	})
+}
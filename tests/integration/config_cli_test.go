@@ -3,6 +3,7 @@ package integration
 import (
 	"context"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -300,15 +301,21 @@ maxConcurrentOps: 999
 		t.Fatalf("Load() failed: %v", err)
 	}
 
-	// Validate config (should report errors)
-	validationErrors, err := loader.Validate(ctx, cfg)
-	if err != nil {
+	// Load already applied fallbacks, so the loaded config itself is valid
+	if _, err := loader.Validate(ctx, cfg); err != nil {
 		t.Fatalf("Validate() system error: %v", err)
 	}
 
-	// Verify validation caught the errors
-	if len(validationErrors) == 0 {
-		t.Error("Expected validation errors for invalid config, got none")
+	// Strict mode (--strict-config) reports the invalid settings instead of falling back
+	opts.StrictMode = true
+	_, err = loader.Load(ctx, opts)
+	if err == nil {
+		t.Fatal("Expected validation errors for invalid config in strict mode, got none")
+	}
+	for _, key := range []string{"logLevel", "maxConcurrentOps"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("strict mode error does not mention %s: %v", key, err)
+		}
 	}
 }
 
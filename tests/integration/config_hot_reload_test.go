@@ -446,3 +446,333 @@ drainEvents:
 		t.Errorf("Expected 1 debounced event, got %d", eventCount)
 	}
 }
+
+// Test that hot-reload behaves identically for config.yml and config.toml
+func TestHotReloadFormatParity(t *testing.T) {
+	tests := []struct {
+		file    string
+		updated string
+		invalid string
+	}{
+		{file: "config.yml", updated: "logLevel: debug\nhotReload: true\n", invalid: "logLevel: [unclosed\n"},
+		{file: "config.toml", updated: "log_level = \"debug\"\nhot_reload = true\n", invalid: "log_level = \"unclosed\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(configPath, []byte(""), 0o644); err != nil {
+				t.Fatalf("Failed to write initial config: %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			watcher, err := config.NewConfigWatcher(config.WatchOptions{
+				ConfigFilePath: configPath,
+				LoadOptions:    config.LoadOptions{ConfigFilePath: configPath, NoProjectConfig: true},
+			}, config.NewLoader())
+			if err != nil {
+				t.Fatalf("NewConfigWatcher() failed: %v", err)
+			}
+			defer watcher.Stop()
+
+			eventCh, _, err := watcher.Watch(ctx)
+			if err != nil {
+				t.Fatalf("Watch() failed: %v", err)
+			}
+
+			// A valid edit reloads the new values
+			time.Sleep(200 * time.Millisecond)
+			if err := os.WriteFile(configPath, []byte(tt.updated), 0o644); err != nil {
+				t.Fatalf("Failed to update config: %v", err)
+			}
+			select {
+			case event := <-eventCh:
+				if event.Error != nil {
+					t.Fatalf("Expected successful reload, got error: %v", event.Error)
+				}
+				if event.NewConfig.LogLevel != "debug" || !event.NewConfig.HotReload {
+					t.Errorf("Reloaded logLevel=%s hotReload=%v, want debug/true", event.NewConfig.LogLevel, event.NewConfig.HotReload)
+				}
+			case <-time.After(3 * time.Second):
+				t.Fatal("Config change not detected within 3 seconds")
+			}
+
+			// A syntax error is reported and the previous config is kept
+			if err := os.WriteFile(configPath, []byte(tt.invalid), 0o644); err != nil {
+				t.Fatalf("Failed to write invalid config: %v", err)
+			}
+			select {
+			case event := <-eventCh:
+				if event.Error == nil || event.NewConfig != nil {
+					t.Errorf("Expected reload error without new config, got error=%v config=%v", event.Error, event.NewConfig)
+				}
+			case <-time.After(3 * time.Second):
+				t.Fatal("Invalid config change not detected within 3 seconds")
+			}
+		})
+	}
+}
+
+// Test that editors saving by rename keep hot-reload working across several saves
+func TestHotReloadEditorSaves(t *testing.T) {
+	saves := []struct {
+		name string
+		save func(t *testing.T, path, content string)
+	}{
+		{
+			// VS Code, JetBrains, and most libraries: write a temp file, rename it over
+			name: "rename over",
+			save: func(t *testing.T, path, content string) {
+				tmp := path + ".tmp"
+				if err := os.WriteFile(tmp, []byte(content), 0o644); err != nil {
+					t.Fatalf("Failed to write temp file: %v", err)
+				}
+				if err := os.Rename(tmp, path); err != nil {
+					t.Fatalf("Failed to rename temp file: %v", err)
+				}
+			},
+		},
+		{
+			// vim with backupcopy=no: move the original to a backup, write a new file
+			name: "backup and recreate",
+			save: func(t *testing.T, path, content string) {
+				if err := os.Rename(path, path+"~"); err != nil {
+					t.Fatalf("Failed to move config to backup: %v", err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatalf("Failed to write config: %v", err)
+				}
+				if err := os.Remove(path + "~"); err != nil {
+					t.Fatalf("Failed to remove backup: %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range saves {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yml")
+			if err := os.WriteFile(configPath, []byte("logLevel: info\n"), 0o644); err != nil {
+				t.Fatalf("Failed to write initial config: %v", err)
+			}
+			eventCh := startWatcher(t, configPath)
+
+			// A second and third save prove the watch survived the first
+			for _, level := range []string{"debug", "warn", "error"} {
+				tt.save(t, configPath, "logLevel: "+level+"\n")
+				expectReload(t, eventCh, level)
+			}
+		})
+	}
+}
+
+// Test that hot-reload follows a symbolic link to the config, through saves of the
+// target and through pointing the link elsewhere
+func TestHotReloadSymlinkedConfig(t *testing.T) {
+	dotfiles := t.TempDir()
+	target := filepath.Join(dotfiles, "lazynuget.yml")
+	other := filepath.Join(dotfiles, "lazynuget-work.yml")
+	if err := os.WriteFile(target, []byte("logLevel: info\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := os.WriteFile(other, []byte("logLevel: warn\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.Symlink(target, configPath); err != nil {
+		t.Skipf("Symbolic links unavailable: %v", err)
+	}
+	eventCh := startWatcher(t, configPath)
+
+	// An atomic save of the target, in a directory other than the link's
+	tmp := target + ".tmp"
+	if err := os.WriteFile(tmp, []byte("logLevel: debug\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		t.Fatalf("Failed to rename temp file: %v", err)
+	}
+	expectReload(t, eventCh, "debug")
+
+	// Pointing the link at another file, as dotfile managers do
+	link := configPath + ".new"
+	if err := os.Symlink(other, link); err != nil {
+		t.Fatalf("Failed to create link: %v", err)
+	}
+	if err := os.Rename(link, configPath); err != nil {
+		t.Fatalf("Failed to replace link: %v", err)
+	}
+	expectReload(t, eventCh, "warn")
+
+	// Edits to the new target are seen; the old one is no longer watched
+	if err := os.WriteFile(other, []byte("logLevel: error\n"), 0o644); err != nil {
+		t.Fatalf("Failed to update config: %v", err)
+	}
+	expectReload(t, eventCh, "error")
+}
+
+// startWatcher watches configPath until the test ends and returns its events
+func startWatcher(t *testing.T, configPath string) <-chan config.ConfigChangeEvent {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	t.Cleanup(cancel)
+
+	watcher, err := config.NewConfigWatcher(config.WatchOptions{
+		ConfigFilePath: configPath,
+		LoadOptions:    config.LoadOptions{ConfigFilePath: configPath, NoProjectConfig: true},
+	}, config.NewLoader())
+	if err != nil {
+		t.Fatalf("NewConfigWatcher() failed: %v", err)
+	}
+	t.Cleanup(func() { _ = watcher.Stop() })
+
+	eventCh, _, err := watcher.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() failed: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond) // Let watcher initialize
+	return eventCh
+}
+
+// expectReload waits for a successful reload as an update with the given log level
+func expectReload(t *testing.T, eventCh <-chan config.ConfigChangeEvent, logLevel string) {
+	t.Helper()
+	select {
+	case event := <-eventCh:
+		if event.Error != nil {
+			t.Fatalf("Expected successful reload, got error: %v", event.Error)
+		}
+		if event.Type != config.ConfigUpdated {
+			t.Errorf("Expected ConfigUpdated, got %s", event.Type)
+		}
+		if event.NewConfig.LogLevel != logLevel {
+			t.Errorf("Reloaded logLevel=%s, want %s", event.NewConfig.LogLevel, logLevel)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("Save setting logLevel=%s not detected within 3 seconds", logLevel)
+	}
+}
+
+// Test that hot-reload follows included fragments, including ones added by a reload
+func TestHotReloadIncludedFragments(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yml")
+	fragments := filepath.Join(t.TempDir(), "fragments")
+	if err := os.MkdirAll(fragments, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	levels := filepath.Join(fragments, "levels.yml")
+	theme := filepath.Join(fragments, "theme.yml")
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	write(configPath, "include: ["+levels+"]\n")
+	write(levels, "logLevel: info\n")
+	write(theme, "theme: dark\n")
+
+	opts := config.LoadOptions{ConfigFilePath: configPath, NoProjectConfig: true}
+	loader := config.NewLoader()
+	cfg, err := loader.Load(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	watcher, err := config.NewConfigWatcher(config.WatchOptions{
+		ConfigFilePath: configPath,
+		RelatedFiles:   cfg.RelatedFiles(),
+		LoadOptions:    opts,
+	}, loader)
+	if err != nil {
+		t.Fatalf("NewConfigWatcher() failed: %v", err)
+	}
+	defer watcher.Stop()
+	eventCh, _, err := watcher.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() failed: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond) // Let watcher initialize
+
+	// A fragment in another directory changes
+	write(levels, "logLevel: debug\n")
+	expectReload(t, eventCh, "debug")
+
+	// A new fragment is included, then changed on its own
+	write(configPath, "include: ["+levels+", "+theme+"]\n")
+	expectReload(t, eventCh, "debug")
+	write(theme, "theme: light\n")
+	select {
+	case event := <-eventCh:
+		if event.Error != nil || event.NewConfig.Theme != "light" {
+			t.Errorf("Expected reload with theme=light, got error=%v config=%+v", event.Error, event.NewConfig)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Change to newly included fragment not detected within 3 seconds")
+	}
+
+	// Unrelated files in the watched directories are ignored
+	write(filepath.Join(fragments, "notes.txt"), "scratch\n")
+	select {
+	case event := <-eventCh:
+		t.Errorf("Unexpected event for an unrelated file: %+v", event)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+// Test that editing the current theme's file reloads its colors
+func TestHotReloadThemeFile(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yml")
+	themePath := filepath.Join(dir, "themes", "nord.yml")
+	if err := os.MkdirAll(filepath.Dir(themePath), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configPath, []byte("theme: nord\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(themePath, []byte("colors:\n  border: \"#4C566A\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := config.LoadOptions{ConfigFilePath: configPath, NoProjectConfig: true}
+	loader := config.NewLoader()
+	cfg, err := loader.Load(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	watcher, err := config.NewConfigWatcher(config.WatchOptions{
+		ConfigFilePath: configPath,
+		RelatedFiles:   cfg.RelatedFiles(),
+		LoadOptions:    opts,
+	}, loader)
+	if err != nil {
+		t.Fatalf("NewConfigWatcher() failed: %v", err)
+	}
+	defer watcher.Stop()
+	eventCh, _, err := watcher.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() failed: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond) // Let watcher initialize
+
+	if err := os.WriteFile(themePath, []byte("colors:\n  border: \"#88C0D0\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-eventCh:
+		if event.Error != nil || event.NewConfig.ColorScheme.Border != "#88C0D0" {
+			t.Errorf("Expected reload with border=#88C0D0, got error=%v config=%+v", event.Error, event.NewConfig)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Change to theme file not detected within 3 seconds")
+	}
+}
@@ -1,9 +1,16 @@
 package integration
 
 import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/willibrandon/lazynuget/internal/platform"
 )
@@ -196,3 +203,70 @@ func TestProcessSpawner_ExecutableNotFound(t *testing.T) {
 
 	t.Logf("Error (expected): %v", err)
 }
+
+// TestProcessSpawner_CancelRestore tests that cancelling the context stops a dotnet restore
+// that is stuck waiting on a feed
+func TestProcessSpawner_CancelRestore(t *testing.T) {
+	if _, err := exec.LookPath("dotnet"); err != nil {
+		t.Skip("dotnet not found in PATH")
+	}
+
+	// A feed that accepts connections and never answers keeps restore waiting
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		var held []net.Conn
+		defer func() {
+			for _, conn := range held {
+				_ = conn.Close()
+			}
+		}()
+		for {
+			conn, acceptErr := listener.Accept()
+			if acceptErr != nil {
+				return
+			}
+			held = append(held, conn)
+		}
+	}()
+
+	dir := t.TempDir()
+	csproj := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup>
+  <ItemGroup><PackageReference Include="Newtonsoft.Json" Version="13.0.3" /></ItemGroup>
+</Project>`
+	if err := os.WriteFile(filepath.Join(dir, "app.csproj"), []byte(csproj), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	args := []string{
+		"restore", "app.csproj",
+		"--source", "http://" + listener.Addr().String() + "/v3/index.json",
+		"--packages", filepath.Join(dir, "packages"),
+		"--no-cache",
+	}
+	env := map[string]string{
+		"DOTNET_CLI_TELEMETRY_OPTOUT":       "1",
+		"DOTNET_SKIP_FIRST_TIME_EXPERIENCE": "1",
+		"DOTNET_NOLOGO":                     "1",
+	}
+
+	start := time.Now()
+	result, err := platform.NewProcessSpawner().RunContext(ctx, "dotnet", args, dir, env)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("RunContext() error = %v (exit code %d), want context.DeadlineExceeded\nStdout: %s",
+			err, result.ExitCode, result.Stdout)
+	}
+	// Restore retries for minutes on its own; cancellation plus the pipe wait delay is far less
+	if elapsed > 20*time.Second {
+		t.Errorf("RunContext() returned after %s, want shortly after cancellation", elapsed)
+	}
+	t.Logf("Restore stopped after %s", elapsed)
+}
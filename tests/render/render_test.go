@@ -0,0 +1,107 @@
+// Package render tests what lazynuget draws, frame by frame, against golden files in
+// testdata. Every command or panel that renders for the user gets a test here; run
+// `go test ./tests/render -update` to accept an intended change to its output.
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/lazynuget/internal/jsonrpc"
+	"github.com/willibrandon/lazynuget/internal/plugin"
+	"github.com/willibrandon/lazynuget/tests/harness"
+)
+
+// pluginEnv makes the test binary act as the panel plugin of TestPluginPanel.
+const pluginEnv = "LAZYNUGET_RENDER_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(pluginEnv) == "1" {
+		servePlugin()
+		os.Exit(0)
+	}
+	os.Exit(harness.Main(m))
+}
+
+// TestPackagesList renders the package references of every project
+func TestPackagesList(t *testing.T) {
+	h := harness.New(t, harness.Options{Workspace: "basic"})
+	harness.Golden(t, "packages-list", h.Run("packages", "list"))
+}
+
+// TestOutdated renders outdated references against the fake feed, at two widths
+func TestOutdated(t *testing.T) {
+	for _, width := range []int{80, 40} {
+		t.Run(fmt.Sprint(width), func(t *testing.T) {
+			h := harness.New(t, harness.Options{Workspace: "basic", Width: width})
+			feed := harness.NewFeed(t, "basic")
+			h.UseFeed(feed)
+			harness.Golden(t, fmt.Sprintf("outdated-%d", width), h.Run("outdated", "--source", feed.URL))
+		})
+	}
+}
+
+// TestOutdatedFeedError renders a feed that cannot be reached
+func TestOutdatedFeedError(t *testing.T) {
+	h := harness.New(t, harness.Options{Workspace: "basic"})
+	frame := h.Run("outdated", "--source", "http://127.0.0.1:1/v3-flatcontainer/", "src/Lib/Lib.csproj")
+	if frame.ExitCode != 2 || !frame.Contains("Error: failed to list versions of Polly") {
+		t.Errorf("frame:\n%s\nwant exit 2 and the failed request", frame)
+	}
+}
+
+// TestPluginPanel renders a plugin panel sized to the fake terminal
+func TestPluginPanel(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := json.Marshal(map[string]any{
+		"plugins": []map[string]any{{"name": "fake", "command": executable}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := harness.New(t, harness.Options{
+		Workspace: "basic",
+		Config:    string(config), // JSON is YAML
+		Env:       []string{pluginEnv + "=1"},
+		Width:     48,
+		Height:    12,
+	})
+	harness.Golden(t, "plugin-panel", h.Run("plugin", "panel", "--package", "Serilog", "--version", "3.1.1", "fake", "details"))
+}
+
+// servePlugin is a plugin with one panel, which draws a box filling the size it is given.
+func servePlugin() {
+	server := jsonrpc.NewServer()
+	server.Handle("initialize", func(context.Context, json.RawMessage) (any, error) {
+		return plugin.Manifest{Name: "fake", Version: "1.0.0", Panels: []plugin.Panel{{ID: "details", Title: "Details"}}}, nil
+	})
+	server.Handle("panel/render", func(_ context.Context, params json.RawMessage) (any, error) {
+		var p struct {
+			Context plugin.Context `json:"context"`
+			Width   int            `json:"width"`
+			Height  int            `json:"height"`
+		}
+		if err := jsonrpc.DecodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		inner := p.Width - 2
+		lines := []string{"+" + strings.Repeat("-", inner) + "+"}
+		for _, text := range []string{p.Context.Package + " " + p.Context.Version, fmt.Sprintf("%dx%d", p.Width, p.Height)} {
+			lines = append(lines, fmt.Sprintf("|%-*.*s|", inner, inner, " "+text))
+		}
+		for len(lines) < p.Height-1 {
+			lines = append(lines, "|"+strings.Repeat(" ", inner)+"|")
+		}
+		lines = append(lines, lines[0])
+		return map[string]any{"lines": lines}, nil
+	})
+	_ = server.Serve(context.Background(), os.Stdin, os.Stdout)
+}